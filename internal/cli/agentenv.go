@@ -0,0 +1,98 @@
+// Package cli implements discovery of agent identity from the environment.
+package cli
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// agentEnviron returns the environment agent identity is discovered from. It
+// is a variable so tests can supply a fixed environment.
+var agentEnviron = os.Environ
+
+// claudeCodeProgram is the program recorded for sessions discovered from
+// Claude Code's environment.
+const claudeCodeProgram = "claude-code"
+
+// Environment variables consulted for each identity field, highest
+// precedence first. Explicit flags always win over all of them.
+var (
+	sessionIDEnvVars = []string{"SLB_SESSION_ID"}
+	agentNameEnvVars = []string{"SLB_AGENT_NAME", "AGENT_NAME"}
+	programEnvVars   = []string{"SLB_PROGRAM"}
+	modelEnvVars     = []string{"SLB_MODEL", "CLAUDE_MODEL", "ANTHROPIC_MODEL", "OPENAI_MODEL"}
+	// claudeCodeEnvVars mark a process running under Claude Code.
+	claudeCodeEnvVars = []string{"CLAUDECODE", "CLAUDE_CODE_ENTRYPOINT"}
+)
+
+// agentEnvHelp documents the discovery precedence for command help.
+const agentEnvHelp = `Identity discovered from the environment (explicit flags always win):
+  session ID  SLB_SESSION_ID
+  agent       SLB_AGENT_NAME, then AGENT_NAME
+  program     SLB_PROGRAM, then "claude-code" when CLAUDECODE or
+              CLAUDE_CODE_ENTRYPOINT is set
+  model       SLB_MODEL, then CLAUDE_MODEL, ANTHROPIC_MODEL, OPENAI_MODEL`
+
+// agentIdentity is the session identity discovered from the environment.
+type agentIdentity struct {
+	SessionID string
+	AgentName string
+	Program   string
+	Model     string
+	// Sources names the variable each non-empty field came from, keyed by
+	// "session_id", "agent_name", "program" and "model".
+	Sources map[string]string
+}
+
+// discoverAgentIdentity reads the agent's identity from the environment.
+func discoverAgentIdentity() agentIdentity {
+	env := make(map[string]string)
+	for _, kv := range agentEnviron() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			if v = strings.TrimSpace(v); v != "" {
+				env[k] = v
+			}
+		}
+	}
+
+	id := agentIdentity{Sources: make(map[string]string)}
+	first := func(field string, names []string) string {
+		for _, name := range names {
+			if v := env[name]; v != "" {
+				id.Sources[field] = name
+				return v
+			}
+		}
+		return ""
+	}
+	id.SessionID = first("session_id", sessionIDEnvVars)
+	id.AgentName = first("agent_name", agentNameEnvVars)
+	id.Program = first("program", programEnvVars)
+	if id.Program == "" {
+		for _, name := range claudeCodeEnvVars {
+			if env[name] != "" {
+				id.Program = claudeCodeProgram
+				id.Sources["program"] = name
+				break
+			}
+		}
+	}
+	id.Model = first("model", modelEnvVars)
+	return id
+}
+
+// applySessionIDFromEnv sets cmd's --session-id from SLB_SESSION_ID when
+// the command has the flag and it was not given explicitly.
+func applySessionIDFromEnv(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("session-id")
+	if flag == nil || flag.Changed {
+		return nil
+	}
+	id := discoverAgentIdentity().SessionID
+	if id == "" {
+		return nil
+	}
+	return cmd.Flags().Set("session-id", id)
+}
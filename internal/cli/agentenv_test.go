@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// setAgentEnv makes agent discovery see only the given KEY=value pairs.
+func setAgentEnv(t *testing.T, kv ...string) {
+	t.Helper()
+	orig := agentEnviron
+	agentEnviron = func() []string { return kv }
+	t.Cleanup(func() { agentEnviron = orig })
+}
+
+func TestDiscoverAgentIdentity(t *testing.T) {
+	tests := []struct {
+		name                          string
+		env                           []string
+		sessionID, agent, prog, model string
+		sources                       map[string]string
+	}{
+		{
+			name: "empty environment",
+		},
+		{
+			name:      "slb variables",
+			env:       []string{"SLB_SESSION_ID=sess-1", "SLB_AGENT_NAME=GreenLake", "SLB_PROGRAM=codex-cli", "SLB_MODEL=gpt-5"},
+			sessionID: "sess-1", agent: "GreenLake", prog: "codex-cli", model: "gpt-5",
+			sources: map[string]string{"session_id": "SLB_SESSION_ID", "agent_name": "SLB_AGENT_NAME", "program": "SLB_PROGRAM", "model": "SLB_MODEL"},
+		},
+		{
+			name:  "slb variables beat well-known conventions",
+			env:   []string{"AGENT_NAME=Generic", "SLB_AGENT_NAME=GreenLake", "CLAUDECODE=1", "SLB_PROGRAM=wrapper", "CLAUDE_MODEL=opus", "SLB_MODEL=gpt-5"},
+			agent: "GreenLake", prog: "wrapper", model: "gpt-5",
+			sources: map[string]string{"agent_name": "SLB_AGENT_NAME", "program": "SLB_PROGRAM", "model": "SLB_MODEL"},
+		},
+		{
+			name:  "claude code convention",
+			env:   []string{"AGENT_NAME=BlueDog", "CLAUDECODE=1", "ANTHROPIC_MODEL=opus"},
+			agent: "BlueDog", prog: "claude-code", model: "opus",
+			sources: map[string]string{"agent_name": "AGENT_NAME", "program": "CLAUDECODE", "model": "ANTHROPIC_MODEL"},
+		},
+		{
+			name:    "claude model wins over openai model",
+			env:     []string{"OPENAI_MODEL=gpt-5", "CLAUDE_MODEL=sonnet"},
+			model:   "sonnet",
+			sources: map[string]string{"model": "CLAUDE_MODEL"},
+		},
+		{
+			name:    "openai model alone sets no program",
+			env:     []string{"OPENAI_MODEL=gpt-5"},
+			model:   "gpt-5",
+			sources: map[string]string{"model": "OPENAI_MODEL"},
+		},
+		{
+			name:    "blank values are ignored",
+			env:     []string{"SLB_AGENT_NAME=  ", "AGENT_NAME=Fallback", "SLB_MODEL="},
+			agent:   "Fallback",
+			sources: map[string]string{"agent_name": "AGENT_NAME"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setAgentEnv(t, tt.env...)
+			got := discoverAgentIdentity()
+			if got.SessionID != tt.sessionID || got.AgentName != tt.agent || got.Program != tt.prog || got.Model != tt.model {
+				t.Errorf("discoverAgentIdentity() = %q/%q/%q/%q, want %q/%q/%q/%q",
+					got.SessionID, got.AgentName, got.Program, got.Model,
+					tt.sessionID, tt.agent, tt.prog, tt.model)
+			}
+			if len(got.Sources) != len(tt.sources) {
+				t.Errorf("Sources = %v, want %v", got.Sources, tt.sources)
+			}
+			for field, want := range tt.sources {
+				if got.Sources[field] != want {
+					t.Errorf("Sources[%s] = %q, want %q", field, got.Sources[field], want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplySessionIDFromEnv(t *testing.T) {
+	newCmd := func(id *string) *cobra.Command {
+		cmd := &cobra.Command{Use: "x", RunE: func(*cobra.Command, []string) error { return nil }}
+		cmd.Flags().StringVarP(id, "session-id", "s", "", "session ID")
+		return cmd
+	}
+
+	t.Run("fills unset flag", func(t *testing.T) {
+		setAgentEnv(t, "SLB_SESSION_ID=from-env")
+		var id string
+		cmd := newCmd(&id)
+		if err := cmd.ParseFlags(nil); err != nil {
+			t.Fatalf("ParseFlags: %v", err)
+		}
+		if err := applySessionIDFromEnv(cmd); err != nil {
+			t.Fatalf("applySessionIDFromEnv: %v", err)
+		}
+		if id != "from-env" {
+			t.Errorf("session ID = %q, want from-env", id)
+		}
+	})
+
+	t.Run("explicit flag wins", func(t *testing.T) {
+		setAgentEnv(t, "SLB_SESSION_ID=from-env")
+		var id string
+		cmd := newCmd(&id)
+		if err := cmd.ParseFlags([]string{"-s", "from-flag"}); err != nil {
+			t.Fatalf("ParseFlags: %v", err)
+		}
+		if err := applySessionIDFromEnv(cmd); err != nil {
+			t.Fatalf("applySessionIDFromEnv: %v", err)
+		}
+		if id != "from-flag" {
+			t.Errorf("session ID = %q, want from-flag", id)
+		}
+	})
+
+	t.Run("command without the flag", func(t *testing.T) {
+		setAgentEnv(t, "SLB_SESSION_ID=from-env")
+		cmd := &cobra.Command{Use: "x"}
+		if err := applySessionIDFromEnv(cmd); err != nil {
+			t.Fatalf("applySessionIDFromEnv: %v", err)
+		}
+	})
+}
@@ -2,11 +2,15 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/config"
 	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/integrations"
 	"github.com/Dicklesworthstone/slb/internal/output"
@@ -18,6 +22,10 @@ var (
 	flagApproveSessionKey    string
 	flagApproveComments      string
 	flagApproveTargetProject string
+	flagApproveAckUnviewed   bool
+	flagApproveSegments      string
+	flagApproveCallbackID    string
+	flagApproveExpedite      bool
 
 	// Structured response flags
 	flagApproveReasonResponse string
@@ -31,6 +39,10 @@ func init() {
 	approveCmd.Flags().StringVarP(&flagApproveSessionKey, "session-key", "k", "", "session HMAC key for signing (required)")
 	approveCmd.Flags().StringVarP(&flagApproveComments, "comments", "m", "", "additional comments")
 	approveCmd.Flags().StringVar(&flagApproveTargetProject, "target-project", "", "target project path for cross-project approvals")
+	approveCmd.Flags().BoolVar(&flagApproveAckUnviewed, "acknowledge-unviewed", false, "approve even though dry-run or diff evidence was not viewed")
+	approveCmd.Flags().StringVar(&flagApproveSegments, "segments", "", "approve only these segments of a compound command (e.g. 1,3); the rest are rejected")
+	approveCmd.Flags().StringVar(&flagApproveCallbackID, "callback-id", "", "ID of the chat or webhook callback delivering this approval; repeats are no-ops")
+	approveCmd.Flags().BoolVar(&flagApproveExpedite, "expedite", false, "move the request ahead of earlier approvals waiting for an execution slot")
 
 	// Structured response flags for justification fields
 	approveCmd.Flags().StringVar(&flagApproveReasonResponse, "reason-response", "", "response to the reason justification")
@@ -53,10 +65,31 @@ requests (unless you are a trusted self-approve agent).
 For cross-project reviews, use --target-project to specify which project's
 database contains the request you want to approve.
 
+Evidence you opened via 'slb show -s <your-session-id>' (text output) or the
+TUI is attached to the review; views by other sessions do not count. Approving
+with unviewed dry-run or diff evidence prints a warning; with
+general.unviewed_evidence_action = "block_critical", CRITICAL approvals are
+refused unless --acknowledge-unviewed is passed.
+
+For compound commands, --segments approves only the listed segments (numbered
+from 1, as shown by 'slb show') and rejects the rest. The request is approved
+once every segment is decided under the quorum rules; only the approved
+segments are then executed.
+
+Bridges relaying chat buttons or webhooks pass the provider's delivery ID as
+--callback-id. A repeated ID (a double click or a provider retry) succeeds
+without recording a second review and reports "replayed".
+
+DANGEROUS and CRITICAL requests in one project execute at most
+general.max_concurrent_executions at a time; the rest wait in the project's
+execution queue in approval order. --expedite moves this request ahead of
+earlier approvals that are not expedited.
+
 	Examples:
 	  slb approve abc123 -s $SESSION_ID -k $SESSION_KEY
 	  slb approve abc123 -s $SESSION_ID -k $SESSION_KEY -m "Looks safe"
 	  slb approve abc123 -s $SESSION_ID -k $SESSION_KEY --reason-response "Valid use case"
+	  slb approve abc123 -s $SESSION_ID -k $SESSION_KEY --segments 1,3 -m "Keep the cache"
 	  slb approve abc123 -s $SESSION_ID -k $SESSION_KEY --target-project /path/to/other/project`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -69,6 +102,10 @@ database contains the request you want to approve.
 		if flagApproveSessionKey == "" {
 			return fmt.Errorf("--session-key is required")
 		}
+		segments, err := parseSegmentList(flagApproveSegments)
+		if err != nil {
+			return err
+		}
 
 		// Determine project and database path
 		project, err := projectPath()
@@ -90,6 +127,45 @@ database contains the request you want to approve.
 		}
 		defer dbConn.Close()
 
+		request, err := dbConn.GetRequest(requestID)
+		if err != nil {
+			return fmt.Errorf("getting request: %w", err)
+		}
+
+		cfg, err := requestConfig(project, request)
+		if err != nil {
+			return err
+		}
+		reviewSvc := core.NewReviewService(dbConn, toReviewConfig(cfg))
+		reviewSvc.SetNotifier(buildAgentMailNotifier(project))
+
+		// A redelivered callback was already approved, past the evidence check
+		replayed, err := reviewSvc.ReplayedCallback(core.ReviewOptions{
+			SessionID:  flagApproveSessionID,
+			SessionKey: flagApproveSessionKey,
+			RequestID:  requestID,
+			Decision:   db.DecisionApprove,
+			CallbackID: flagApproveCallbackID,
+		})
+		if err != nil {
+			return fmt.Errorf("submitting approval: %w", err)
+		}
+
+		// Attach the evidence this reviewer session viewed and check for unviewed evidence
+		evidence, err := core.LoadEvidenceViews(request.ProjectPath, requestID, flagApproveSessionID)
+		if err != nil {
+			return fmt.Errorf("loading evidence views: %w", err)
+		}
+		if replayed == nil {
+			unviewed, err := reviewSvc.CheckUnviewedEvidence(request, evidence, flagApproveAckUnviewed)
+			if err != nil {
+				return fmt.Errorf("%w (inspect with 'slb show %s --with-attachments' or pass --acknowledge-unviewed)", err, requestID)
+			}
+			if len(unviewed) > 0 {
+				fmt.Fprintf(os.Stderr, "Warning: approving without viewing evidence: %s\n", strings.Join(unviewed, ", "))
+			}
+		}
+
 		// Build review options
 		opts := core.ReviewOptions{
 			SessionID:  flagApproveSessionID,
@@ -101,37 +177,56 @@ database contains the request you want to approve.
 				EffectResponse: flagApproveEffectResponse,
 				GoalResponse:   flagApproveGoalResponse,
 				SafetyResponse: flagApproveSafetyResponse,
+				EvidenceViewed: evidence,
 			},
-			Comments: flagApproveComments,
+			Comments:   flagApproveComments,
+			Segments:   segments,
+			CallbackID: flagApproveCallbackID,
+			Expedite:   flagApproveExpedite,
 		}
 
-		// Create review service and submit
-		reviewSvc := core.NewReviewService(dbConn, core.DefaultReviewConfig())
-		reviewSvc.SetNotifier(buildAgentMailNotifier(project))
 		result, err := reviewSvc.SubmitReview(opts)
 		if err != nil {
 			return fmt.Errorf("submitting approval: %w", err)
 		}
+		_ = core.ClearEvidenceViews(request.ProjectPath, requestID, flagApproveSessionID)
+		broadcastReviewOutcome(request, result)
 
 		// Build output
 		type approvalResult struct {
-			ReviewID             string `json:"review_id"`
-			RequestID            string `json:"request_id"`
-			Decision             string `json:"decision"`
-			Approvals            int    `json:"approvals"`
-			Rejections           int    `json:"rejections"`
-			RequestStatusChanged bool   `json:"request_status_changed"`
-			NewRequestStatus     string `json:"new_request_status,omitempty"`
-			CreatedAt            string `json:"created_at"`
+			ReviewID             string   `json:"review_id"`
+			RequestID            string   `json:"request_id"`
+			Decision             string   `json:"decision"`
+			Segments             []int    `json:"segments,omitempty"`
+			ApprovedSegments     []int    `json:"approved_segments,omitempty"`
+			ExcludedReviewers    []string `json:"excluded_reviewers,omitempty"`
+			SameHostReviewers    []string `json:"same_host_reviewers,omitempty"`
+			Replayed             bool     `json:"replayed,omitempty"`
+			Expedited            bool     `json:"expedited,omitempty"`
+			Approvals            int      `json:"approvals"`
+			Rejections           int      `json:"rejections"`
+			RequestStatusChanged bool     `json:"request_status_changed"`
+			NewRequestStatus     string   `json:"new_request_status,omitempty"`
+			// ResolutionApplied is the conflict resolution rule behind the
+			// status change.
+			ResolutionApplied *core.ResolutionApplied `json:"resolution_applied,omitempty"`
+			CreatedAt         string                  `json:"created_at"`
 		}
 
 		resp := approvalResult{
 			ReviewID:             result.Review.ID,
 			RequestID:            requestID,
 			Decision:             string(result.Review.Decision),
+			Segments:             result.Review.Segments,
+			ApprovedSegments:     result.ApprovedSegments,
+			ExcludedReviewers:    result.ExcludedReviewers,
+			SameHostReviewers:    result.SameHostReviewers,
+			Replayed:             result.Replayed,
+			Expedited:            flagApproveExpedite && !result.Replayed,
 			Approvals:            result.Approvals,
 			Rejections:           result.Rejections,
 			RequestStatusChanged: result.RequestStatusChanged,
+			ResolutionApplied:    result.ResolutionApplied,
 			CreatedAt:            result.Review.CreatedAt.Format(time.RFC3339),
 		}
 
@@ -145,14 +240,34 @@ database contains the request you want to approve.
 		}
 
 		// Human-readable output
+		if resp.Replayed {
+			fmt.Printf("Callback %s was already processed; no new review recorded\n", flagApproveCallbackID)
+		}
 		fmt.Printf("Approved request %s\n", requestID)
 		fmt.Printf("Review ID: %s\n", resp.ReviewID)
+		if len(resp.Segments) > 0 {
+			fmt.Printf("Segments approved: %s\n", formatSegmentList(resp.Segments))
+		}
 		fmt.Printf("Approvals: %d, Rejections: %d\n", resp.Approvals, resp.Rejections)
+		if resp.Expedited {
+			fmt.Println("Execution expedited: ahead of earlier approvals in the execution queue")
+		}
+		if len(resp.ExcludedReviewers) > 0 {
+			fmt.Printf("Not counted toward CRITICAL quorum (flagged reviewer pattern): %s\n", strings.Join(resp.ExcludedReviewers, ", "))
+		}
+		if len(resp.SameHostReviewers) > 0 {
+			fmt.Printf("Not counted (same host as the requestor): %s\n", strings.Join(resp.SameHostReviewers, ", "))
+		}
 
 		if result.RequestStatusChanged {
 			fmt.Printf("Request status changed to: %s\n", resp.NewRequestStatus)
+			printResolutionApplied(result.ResolutionApplied)
 			if result.NewRequestStatus == db.StatusApproved {
-				fmt.Println("Request is now approved and ready for execution!")
+				if len(result.ApprovedSegments) > 0 {
+					fmt.Printf("Request is partially approved: segments %s will execute\n", formatSegmentList(result.ApprovedSegments))
+				} else {
+					fmt.Println("Request is now approved and ready for execution!")
+				}
 			}
 		}
 
@@ -160,6 +275,39 @@ database contains the request you want to approve.
 	},
 }
 
+// parseSegmentList parses a --segments value such as "1,3" into segment numbers.
+func parseSegmentList(s string) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var segments []int
+	for _, part := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid --segments %q: expected comma-separated segment numbers starting at 1", s)
+		}
+		segments = append(segments, n)
+	}
+	return segments, nil
+}
+
+// formatSegmentList renders segment numbers as "1,3".
+func formatSegmentList(segments []int) string {
+	parts := make([]string, len(segments))
+	for i, seg := range segments {
+		parts[i] = strconv.Itoa(seg)
+	}
+	return strings.Join(parts, ",")
+}
+
+// printResolutionApplied prints the conflict resolution rule behind a status
+// change.
+func printResolutionApplied(ra *core.ResolutionApplied) {
+	if ra != nil {
+		fmt.Printf("Resolution: %s (%s)\n", ra.Resolution, ra.Explanation)
+	}
+}
+
 // buildAgentMailNotifier constructs a notifier from config; falls back to no-op on errors/disabled.
 func buildAgentMailNotifier(project string) integrations.RequestNotifier {
 	cfg, err := config.Load(config.LoadOptions{
@@ -172,5 +320,42 @@ func buildAgentMailNotifier(project string) integrations.RequestNotifier {
 	if !cfg.Integrations.AgentMailEnabled {
 		return integrations.NoopNotifier{}
 	}
-	return integrations.NewAgentMailClient(project, cfg.Integrations.AgentMailThread, "")
+	client := integrations.NewAgentMailClient(project, cfg.Integrations.AgentMailThread, "")
+	if routes, err := integrations.ParseLabelRoutes(cfg.Integrations.AgentMailRoutes); err == nil {
+		client.WithRoutes(routes)
+	}
+	return client
+}
+
+// requestConfig loads the configuration a review of request runs under:
+// the project's, overridden by the project policy above the request's
+// working directory. Unlike the notifier, a review does not fall back to
+// the defaults, since a broken policy must not loosen it.
+func requestConfig(project string, request *db.Request) (config.Config, error) {
+	cfg, err := config.Load(config.LoadOptions{
+		ProjectDir: project,
+		ConfigPath: flagConfig,
+		PolicyDir:  request.Command.Cwd,
+	})
+	if err != nil {
+		return config.Config{}, fmt.Errorf("loading config: %w", err)
+	}
+	return cfg, nil
+}
+
+// toReviewConfig converts the review settings, including the reviewer
+// fatigue thresholds and the unviewed-evidence action.
+func toReviewConfig(cfg config.Config) core.ReviewConfig {
+	return daemon.ReviewConfigFromConfig(cfg)
+}
+
+// toReviewerThresholds converts the agents.reviewer_* settings.
+func toReviewerThresholds(cfg config.Config) core.ReviewerThresholds {
+	return daemon.ReviewerThresholdsFromConfig(cfg)
+}
+
+// broadcastReviewOutcome tells a running daemon that a review decided its
+// request (see daemon.PublishReviewOutcome).
+func broadcastReviewOutcome(request *db.Request, result *core.ReviewResult) {
+	daemon.PublishReviewOutcome(request, result, os.Stderr)
 }
@@ -5,7 +5,9 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/integrations"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
@@ -37,6 +39,9 @@ func newTestApproveCmd(dbPath string) *cobra.Command {
 	approve.Flags().StringVarP(&flagApproveSessionKey, "session-key", "k", "", "session HMAC key for signing (required)")
 	approve.Flags().StringVarP(&flagApproveComments, "comments", "m", "", "additional comments")
 	approve.Flags().StringVar(&flagApproveTargetProject, "target-project", "", "target project path for cross-project approvals")
+	approve.Flags().BoolVar(&flagApproveAckUnviewed, "acknowledge-unviewed", false, "approve even though dry-run or diff evidence was not viewed")
+	approve.Flags().StringVar(&flagApproveCallbackID, "callback-id", "", "callback ID")
+	approve.Flags().BoolVar(&flagApproveExpedite, "expedite", false, "expedite execution")
 	approve.Flags().StringVar(&flagApproveReasonResponse, "reason-response", "", "response to the reason justification")
 	approve.Flags().StringVar(&flagApproveEffectResponse, "effect-response", "", "response to the expected effect")
 	approve.Flags().StringVar(&flagApproveGoalResponse, "goal-response", "", "response to the goal")
@@ -57,6 +62,9 @@ func resetApproveFlags() {
 	flagApproveSessionKey = ""
 	flagApproveComments = ""
 	flagApproveTargetProject = ""
+	flagApproveAckUnviewed = false
+	flagApproveCallbackID = ""
+	flagApproveExpedite = false
 	flagApproveReasonResponse = ""
 	flagApproveEffectResponse = ""
 	flagApproveGoalResponse = ""
@@ -167,6 +175,70 @@ func TestApproveCommand_ApprovesRequest(t *testing.T) {
 	if result["new_request_status"] != string(db.StatusApproved) {
 		t.Errorf("expected new_request_status=approved, got %v", result["new_request_status"])
 	}
+	if ra, _ := result["resolution_applied"].(map[string]any); ra["resolution"] != "any_rejection_blocks" || ra["explanation"] != "1 of 1 required approvals" {
+		t.Errorf("expected resolution_applied for the quorum, got %v", result["resolution_applied"])
+	}
+}
+
+func TestApproveCommand_CallbackReplay(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	requestorSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Requestor"),
+		testutil.WithModel("model-a"),
+	)
+	reviewerSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Reviewer"),
+		testutil.WithModel("model-b"),
+	)
+	req := testutil.MakeRequest(t, h.DB, requestorSess,
+		testutil.WithCommand("rm -rf ./build", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierDangerous),
+		testutil.WithMinApprovals(1),
+	)
+
+	approve := func() map[string]any {
+		t.Helper()
+		resetApproveFlags()
+		cmd := newTestApproveCmd(h.DBPath)
+		stdout, err := executeCommandCapture(t, cmd, "approve", req.ID,
+			"-s", reviewerSess.ID,
+			"-k", reviewerSess.SessionKey,
+			"-C", h.ProjectDir,
+			"--callback-id", "slack-action-42",
+			"-j",
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var result map[string]any
+		if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+			t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+		}
+		return result
+	}
+
+	first := approve()
+	if first["replayed"] != nil {
+		t.Errorf("first approval reported replayed=%v", first["replayed"])
+	}
+	second := approve()
+	if second["replayed"] != true {
+		t.Errorf("expected replayed=true on the repeated callback, got %v", second["replayed"])
+	}
+	if second["review_id"] != first["review_id"] {
+		t.Errorf("expected review_id=%v, got %v", first["review_id"], second["review_id"])
+	}
+
+	reviews, err := h.DB.ListReviewsForRequest(req.ID)
+	if err != nil {
+		t.Fatalf("ListReviewsForRequest: %v", err)
+	}
+	if len(reviews) != 1 {
+		t.Errorf("expected 1 review after a repeated callback, got %d", len(reviews))
+	}
 }
 
 func TestApproveCommand_WithComments(t *testing.T) {
@@ -295,6 +367,171 @@ func TestApproveCommand_RequestNotFound(t *testing.T) {
 	}
 }
 
+func TestApproveCommand_BlocksUnviewedCriticalEvidence(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetApproveFlags()
+
+	configPath := h.ProjectDir + "/slb.toml"
+	configContent := `
+[general]
+unviewed_evidence_action = "block_critical"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	requestorSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Requestor"),
+		testutil.WithModel("model-a"),
+	)
+	reviewerSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Reviewer"),
+		testutil.WithModel("model-b"),
+	)
+	req := testutil.MakeRequest(t, h.DB, requestorSess,
+		testutil.WithCommand("rm -rf /var/data", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierCritical),
+		testutil.WithDryRun("ls /var/data", "a b c"),
+	)
+
+	cmd := newTestApproveCmd(h.DBPath)
+	_, err := executeCommandCapture(t, cmd, "approve", req.ID,
+		"-s", reviewerSess.ID,
+		"-k", reviewerSess.SessionKey,
+		"-C", h.ProjectDir,
+		"-c", configPath,
+		"-j",
+	)
+	if err == nil {
+		t.Fatal("expected error for unviewed evidence on critical request")
+	}
+	if !strings.Contains(err.Error(), "unviewed evidence") || !strings.Contains(err.Error(), "dry_run") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	resetApproveFlags()
+	cmd = newTestApproveCmd(h.DBPath)
+	_, err = executeCommandCapture(t, cmd, "approve", req.ID,
+		"-s", reviewerSess.ID,
+		"-k", reviewerSess.SessionKey,
+		"-C", h.ProjectDir,
+		"-c", configPath,
+		"--acknowledge-unviewed",
+		"-j",
+	)
+	if err != nil {
+		t.Fatalf("expected acknowledged approval to succeed: %v", err)
+	}
+}
+
+func TestApproveCommand_EvidenceViewsArePerReviewer(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetApproveFlags()
+
+	configPath := h.ProjectDir + "/slb.toml"
+	if err := os.WriteFile(configPath, []byte("[general]\nunviewed_evidence_action = \"block_critical\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	requestorSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Requestor"),
+		testutil.WithModel("model-a"),
+	)
+	reviewerSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Reviewer"),
+		testutil.WithModel("model-b"),
+	)
+	req := testutil.MakeRequest(t, h.DB, requestorSess,
+		testutil.WithCommand("rm -rf /var/data", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierCritical),
+		testutil.WithDryRun("ls /var/data", "a b c"),
+	)
+
+	show := func(args ...string) {
+		t.Helper()
+		resetShowFlags()
+		if _, err := executeCommandCapture(t, newTestShowCmd(h.DBPath), append([]string{"show", req.ID, "-C", h.ProjectDir}, args...)...); err != nil {
+			t.Fatalf("show %v: %v", args, err)
+		}
+	}
+	approve := func() error {
+		t.Helper()
+		resetApproveFlags()
+		_, err := executeCommandCapture(t, newTestApproveCmd(h.DBPath), "approve", req.ID,
+			"-s", reviewerSess.ID, "-k", reviewerSess.SessionKey,
+			"-C", h.ProjectDir, "-c", configPath, "-j")
+		return err
+	}
+
+	// The requestor viewing the evidence does not count for the reviewer,
+	// and neither does the reviewer's machine-readable output.
+	show("-s", requestorSess.ID)
+	show("-s", reviewerSess.ID, "-j")
+	if err := approve(); err == nil || !strings.Contains(err.Error(), "unviewed evidence") {
+		t.Fatalf("expected unviewed evidence block, got %v", err)
+	}
+
+	show("-s", reviewerSess.ID)
+	if err := approve(); err != nil {
+		t.Fatalf("expected approval after the reviewer viewed the evidence: %v", err)
+	}
+}
+
+func TestApproveCommand_AttachesEvidenceViews(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetApproveFlags()
+
+	requestorSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Requestor"),
+		testutil.WithModel("model-a"),
+	)
+	reviewerSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Reviewer"),
+		testutil.WithModel("model-b"),
+	)
+	req := testutil.MakeRequest(t, h.DB, requestorSess,
+		testutil.WithDryRun("ls ./build", "out.bin"),
+	)
+
+	view := core.NewEvidenceView(core.EvidenceSectionDryRun, time.Now(), 3*time.Second)
+	if err := core.RecordEvidenceViews(req.ProjectPath, req.ID, reviewerSess.ID, view); err != nil {
+		t.Fatalf("RecordEvidenceViews: %v", err)
+	}
+
+	cmd := newTestApproveCmd(h.DBPath)
+	_, err := executeCommandCapture(t, cmd, "approve", req.ID,
+		"-s", reviewerSess.ID,
+		"-k", reviewerSess.SessionKey,
+		"-C", h.ProjectDir,
+		"-j",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reviews, err := h.DB.ListReviewsForRequest(req.ID)
+	if err != nil || len(reviews) != 1 {
+		t.Fatalf("ListReviewsForRequest: %v (len=%d)", err, len(reviews))
+	}
+	viewed := reviews[0].Responses.EvidenceViewed
+	if len(viewed) != 1 || viewed[0].Section != core.EvidenceSectionDryRun || viewed[0].DurationMs != 3000 {
+		t.Errorf("expected dry_run view attached to review, got %+v", viewed)
+	}
+
+	remaining, err := core.LoadEvidenceViews(req.ProjectPath, req.ID, reviewerSess.ID)
+	if err != nil {
+		t.Fatalf("LoadEvidenceViews: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("expected local evidence record to be cleared, got %+v", remaining)
+	}
+}
+
 func TestApproveCommand_Help(t *testing.T) {
 	h := testutil.NewHarness(t)
 	resetApproveFlags()
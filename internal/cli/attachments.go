@@ -1,25 +1,203 @@
-// Package cli provides CLI attachment collection helpers.
+// Package cli provides CLI attachment collection helpers and the attachment
+// command.
 package cli
 
 import (
 	"context"
 	"fmt"
+	"os"
+	"text/tabwriter"
 
+	"github.com/Dicklesworthstone/slb/internal/config"
 	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
 )
 
+func init() {
+	attachmentCmd.AddCommand(attachmentUsageCmd)
+	rootCmd.AddCommand(attachmentCmd)
+}
+
+var attachmentCmd = &cobra.Command{
+	Use:   "attachment",
+	Short: "Inspect request attachments",
+}
+
+var attachmentUsageCmd = &cobra.Command{
+	Use:   "usage <request-id>",
+	Short: "Show a request's attachment sizes against the attachment quota",
+	Long: `Show the size of each attachment on a request and how the total compares
+with the attachment quota (general.max_total_attachment_kb).
+
+Agent-supplied attachments (--attach-file, --attach-context,
+--attach-screenshot, --attach-run, --attach-url) may use the quota minus the part reserved
+for auto-collected context (general.attachment_context_reserve_kb): dry-run
+output, preview and migration evidence.
+
+Examples:
+  slb attachment usage abc123
+  slb attachment usage abc123 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := projectPath()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(config.LoadOptions{
+			ProjectDir: project,
+			ConfigPath: flagConfig,
+		})
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+
+		dbConn, err := db.Open(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		request, err := dbConn.GetRequest(args[0])
+		if err != nil {
+			return fmt.Errorf("getting request: %w", err)
+		}
+
+		ac := toAttachmentConfig(cfg)
+		usage := core.MeasureAttachments(request.Attachments, request.DryRun, &ac)
+
+		type attachmentView struct {
+			Index     int    `json:"index"`
+			Type      string `json:"type"`
+			Source    string `json:"source"` // agent | context
+			Name      string `json:"name,omitempty"`
+			SizeBytes int64  `json:"size_bytes"`
+		}
+		type usageView struct {
+			RequestID       string           `json:"request_id"`
+			Attachments     []attachmentView `json:"attachments"`
+			DryRunBytes     int64            `json:"dry_run_bytes,omitempty"`
+			AgentBytes      int64            `json:"agent_bytes"`
+			ContextBytes    int64            `json:"context_bytes"`
+			TotalBytes      int64            `json:"total_bytes"`
+			LimitBytes      int64            `json:"limit_bytes"`
+			ReservedBytes   int64            `json:"reserved_bytes"`
+			AgentLimitBytes int64            `json:"agent_limit_bytes"`
+		}
+
+		view := usageView{
+			RequestID:       request.ID,
+			Attachments:     make([]attachmentView, 0, len(request.Attachments)),
+			AgentBytes:      usage.AgentBytes,
+			ContextBytes:    usage.ContextBytes,
+			TotalBytes:      usage.TotalBytes(),
+			LimitBytes:      usage.LimitBytes,
+			ReservedBytes:   usage.ReservedBytes,
+			AgentLimitBytes: usage.AgentLimitBytes(),
+		}
+		for i, a := range request.Attachments {
+			source := "agent"
+			if core.IsAutoCollected(a) {
+				source = "context"
+			}
+			view.Attachments = append(view.Attachments, attachmentView{
+				Index:     i,
+				Type:      string(a.Type),
+				Source:    source,
+				Name:      attachmentName(a),
+				SizeBytes: core.AttachmentSize(a),
+			})
+		}
+		if request.DryRun != nil {
+			view.DryRunBytes = int64(len(request.DryRun.Output))
+		}
+
+		if GetOutput() == "json" {
+			out := output.New(output.Format(GetOutput()))
+			return out.Write(view)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "#\tTYPE\tSOURCE\tSIZE\tNAME")
+		for _, v := range view.Attachments {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", v.Index, v.Type, v.Source, formatCaptureSize(v.SizeBytes), v.Name)
+		}
+		if request.DryRun != nil {
+			fmt.Fprintf(w, "-\tdry_run\tcontext\t%s\t%s\n", formatCaptureSize(view.DryRunBytes), request.DryRun.Command)
+		}
+		w.Flush()
+
+		fmt.Println()
+		if usage.LimitBytes == 0 {
+			fmt.Printf("Total: %s (agent %s, context %s); no quota\n",
+				formatCaptureSize(view.TotalBytes), formatCaptureSize(view.AgentBytes), formatCaptureSize(view.ContextBytes))
+			return nil
+		}
+		fmt.Printf("Agent:   %s of %s\n", formatCaptureSize(view.AgentBytes), formatCaptureSize(view.AgentLimitBytes))
+		fmt.Printf("Context: %s (%s reserved)\n", formatCaptureSize(view.ContextBytes), formatCaptureSize(view.ReservedBytes))
+		fmt.Printf("Total:   %s of %s\n", formatCaptureSize(view.TotalBytes), formatCaptureSize(view.LimitBytes))
+		return nil
+	},
+}
+
+// attachmentName is the file, command or source an attachment came from.
+func attachmentName(a db.Attachment) string {
+	for _, key := range []string{"filename", "path", "command", "source"} {
+		if v, ok := a.Metadata[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	if runner, ok := a.Metadata["runner"].(string); ok {
+		return runner + " migrations"
+	}
+	return ""
+}
+
 // AttachmentFlags holds the attachment-related CLI flags.
 type AttachmentFlags struct {
 	Files       []string
 	Contexts    []string
 	Screenshots []string
+	Runs        []string // IDs of executed requests whose output to attach
+	URLs        []string // http(s) URLs whose content to attach
+
+	// Redact patterns are masked in text attachments, on top of the
+	// built-in sensitive-content patterns.
+	Redact []string
+
+	// StrictRedaction fails an attachment when a Redact pattern does not
+	// compile instead of skipping the pattern.
+	StrictRedaction bool
+
+	// Downscale shrinks oversized screenshots to fit instead of
+	// rejecting them.
+	Downscale bool
+
+	// AllowBinary attaches binary files base64-encoded instead of
+	// rejecting them.
+	AllowBinary bool
+
+	// PathPrivacy and ProjectPath control how file and screenshot paths
+	// are recorded (see core.AttachmentConfig.PathPrivacy).
+	PathPrivacy string
+	ProjectPath string
+
+	// DB resolves Runs; when nil it is opened from --db.
+	DB *db.DB
 }
 
 // CollectAttachments loads and processes attachments from CLI flags.
 // It returns a slice of attachments ready to be included in a request.
 func CollectAttachments(ctx context.Context, flags AttachmentFlags) ([]db.Attachment, error) {
 	config := core.DefaultAttachmentConfig()
+	config.DownscaleImages = flags.Downscale
+	config.AllowBinary = flags.AllowBinary
+	config.PathPrivacy = flags.PathPrivacy
+	config.ProjectPath = flags.ProjectPath
+	config.RedactByDefault = true
+	config.RedactPatterns = flags.Redact
+	config.StrictRedaction = flags.StrictRedaction
 	var attachments []db.Attachment
 
 	// Process file attachments
@@ -49,5 +227,31 @@ func CollectAttachments(ctx context.Context, flags AttachmentFlags) ([]db.Attach
 		attachments = append(attachments, *attachment)
 	}
 
+	// Process URL attachments
+	for _, u := range flags.URLs {
+		attachment, err := core.LoadAttachmentFromURL(u, &config)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %q: %w", u, err)
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	// Process prior run output attachments
+	if len(flags.Runs) > 0 && flags.DB == nil {
+		dbConn, err := db.Open(GetDB())
+		if err != nil {
+			return nil, fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+		flags.DB = dbConn
+	}
+	for _, id := range flags.Runs {
+		attachment, err := core.LoadPriorRunOutput(flags.DB, id, &config)
+		if err != nil {
+			return nil, fmt.Errorf("attaching output of request %q: %w", id, err)
+		}
+		attachments = append(attachments, *attachment)
+	}
+
 	return attachments, nil
 }
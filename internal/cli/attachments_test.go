@@ -2,11 +2,18 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
 )
 
 func TestCollectAttachments_EmptyFlags(t *testing.T) {
@@ -115,6 +122,28 @@ func TestCollectAttachments_ContextCommand(t *testing.T) {
 	}
 }
 
+func TestCollectAttachments_URL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("incident timeline"))
+	}))
+	defer srv.Close()
+
+	attachments, err := CollectAttachments(context.Background(), AttachmentFlags{URLs: []string{srv.URL + "/timeline"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(attachments) != 1 || attachments[0].Content != "incident timeline" {
+		t.Fatalf("expected the fetched body as an attachment, got %+v", attachments)
+	}
+	if name := attachmentName(attachments[0]); name != srv.URL+"/timeline" {
+		t.Errorf("attachmentName = %q, want the URL", name)
+	}
+
+	if _, err := CollectAttachments(context.Background(), AttachmentFlags{URLs: []string{"file:///etc/passwd"}}); err == nil {
+		t.Fatal("expected a non-http URL to be rejected")
+	}
+}
+
 func TestCollectAttachments_FailingContextCommand(t *testing.T) {
 	_ = testutil.NewHarness(t)
 
@@ -251,3 +280,76 @@ func TestCollectAttachments_MixedTypes(t *testing.T) {
 		t.Error("expected context attachment")
 	}
 }
+
+// newTestAttachmentCmd creates a fresh attachment command for testing.
+func newTestAttachmentCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVar(&flagConfig, "config", "", "config file")
+
+	attachment := &cobra.Command{Use: "attachment"}
+	attachment.AddCommand(&cobra.Command{
+		Use:  "usage <request-id>",
+		Args: cobra.ExactArgs(1),
+		RunE: attachmentUsageCmd.RunE,
+	})
+	root.AddCommand(attachment)
+	return root
+}
+
+func TestAttachmentUsage(t *testing.T) {
+	h := testutil.NewHarness(t)
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	req := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithDryRun("kubectl diff", "diff output"),
+		testutil.WithAttachments(
+			db.Attachment{Type: db.AttachmentTypeFile, Content: strings.Repeat("a", 2048), Metadata: map[string]any{"filename": "notes.txt"}},
+			core.MarkAutoCollected([]db.Attachment{{Type: db.AttachmentTypeContext, Content: "preview", Metadata: map[string]any{"source": "slb preview"}}})[0],
+		),
+	)
+
+	stdout, err := executeCommandCapture(t, newTestAttachmentCmd(h.DBPath), "attachment", "usage", req.ID, "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var usage struct {
+		Attachments []struct {
+			Source    string `json:"source"`
+			Name      string `json:"name"`
+			SizeBytes int64  `json:"size_bytes"`
+		} `json:"attachments"`
+		AgentBytes      int64 `json:"agent_bytes"`
+		ContextBytes    int64 `json:"context_bytes"`
+		LimitBytes      int64 `json:"limit_bytes"`
+		AgentLimitBytes int64 `json:"agent_limit_bytes"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &usage); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(usage.Attachments) != 2 || usage.Attachments[0].Name != "notes.txt" || usage.Attachments[1].Source != "context" {
+		t.Errorf("unexpected attachments: %+v", usage.Attachments)
+	}
+	if usage.AgentBytes != 2048 || usage.ContextBytes != int64(len("preview")+len("diff output")) {
+		t.Errorf("agent=%d context=%d", usage.AgentBytes, usage.ContextBytes)
+	}
+	if usage.LimitBytes != 5120*1024 || usage.AgentLimitBytes != 4096*1024 {
+		t.Errorf("expected the default quota, got limit=%d agent_limit=%d", usage.LimitBytes, usage.AgentLimitBytes)
+	}
+
+	stdout, err = executeCommandCapture(t, newTestAttachmentCmd(h.DBPath), "attachment", "usage", req.ID, "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"notes.txt", "2.0 KB", "dry_run", "Agent:   2.0 KB of 4.0 MB", "Total:"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("text output missing %q:\n%s", want, stdout)
+		}
+	}
+}
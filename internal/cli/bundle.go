@@ -0,0 +1,438 @@
+// Package cli implements the bundle command.
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagBundleOut             string
+	flagBundleIncludeRollback bool
+)
+
+func init() {
+	bundleCmd.Flags().StringVar(&flagBundleOut, "out", "", "bundle file to write (default <request-id>.tar.gz)")
+	bundleCmd.Flags().BoolVar(&flagBundleIncludeRollback, "include-rollback", false, "include captured rollback state, not just its metadata")
+
+	bundleCmd.AddCommand(bundleVerifyCmd)
+	rootCmd.AddCommand(bundleCmd)
+}
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle <request-id>",
+	Short: "Package a request into a portable audit bundle",
+	Long: `Package everything recorded about one request into a single gzipped tar
+that can be handed to an auditor and checked without access to this machine.
+
+The bundle contains:
+- request.json: the request as slb show --json renders it
+- signatures.json: each review signature and whether it verified
+- attachments/: attachment contents
+- logs/: execution logs
+- rollback/metadata.json: rollback metadata (captured state with --include-rollback)
+- trusted_scripts.json: the project's trusted scripts, their current status,
+  and the trust entry that lowered this request's tier (if any)
+- index.html: a human-readable summary
+- manifest.json: SHA-256 of every other file
+
+Commands appear only in redacted form and session keys are never included.
+Logs and attachments pass through the same redaction. SLB keeps no separate
+audit log, so the bundle has no audit-log section.
+
+Examples:
+  slb bundle abc123
+  slb bundle abc123 --out req.tar.gz --include-rollback
+  slb bundle verify req.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		requestID := args[0]
+
+		dbConn, err := db.Open(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		request, reviews, err := dbConn.GetRequestWithReviews(requestID)
+		if err != nil {
+			return fmt.Errorf("getting request: %w", err)
+		}
+
+		files, err := bundleFiles(dbConn, request, reviews, flagBundleIncludeRollback)
+		if err != nil {
+			return err
+		}
+
+		outPath := flagBundleOut
+		if outPath == "" {
+			outPath = request.ID + ".tar.gz"
+		}
+		var buf bytes.Buffer
+		manifest, err := core.WriteBundle(&buf, request.ID, files, time.Now())
+		if err != nil {
+			return fmt.Errorf("writing bundle: %w", err)
+		}
+		if err := os.WriteFile(outPath, buf.Bytes(), 0600); err != nil {
+			return fmt.Errorf("writing bundle: %w", err)
+		}
+
+		out := output.New(output.Format(GetOutput()))
+		if GetOutput() == "json" {
+			return out.Write(map[string]any{
+				"request_id": request.ID,
+				"path":       outPath,
+				"files":      len(manifest.Files),
+			})
+		}
+		fmt.Printf("Wrote %s (%d files)\n", outPath, len(manifest.Files))
+		return nil
+	},
+}
+
+var bundleVerifyCmd = &cobra.Command{
+	Use:   "verify <bundle>",
+	Short: "Check an audit bundle's hashes and signature results offline",
+	Long: `Check an audit bundle without a database.
+
+Every file must match the SHA-256 recorded in manifest.json, and no file may
+be missing or added. Review signatures are HMACs over session keys that are
+never bundled, so verify reports the result recorded when the bundle was made
+and fails if any review did not verify then.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("opening bundle: %w", err)
+		}
+		defer f.Close()
+
+		result, err := core.VerifyBundle(f)
+		if err != nil {
+			return err
+		}
+
+		out := output.New(output.Format(GetOutput()))
+		if GetOutput() == "json" {
+			if err := out.Write(result); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("Bundle for request %s (%d files, created %s)\n",
+				result.RequestID, result.Files, result.CreatedAt.Format(time.RFC3339))
+			for _, sig := range result.Signatures {
+				fmt.Printf("  review %s by %s (%s): signature %s\n",
+					sig.ReviewID, sig.ReviewerAgent, sig.Decision, sig.Result)
+			}
+			for _, p := range result.Problems {
+				fmt.Printf("  problem: %s\n", p)
+			}
+		}
+		if !result.OK() {
+			return fmt.Errorf("bundle failed verification: %d problem(s)", len(result.Problems))
+		}
+		if GetOutput() != "json" {
+			fmt.Println("OK")
+		}
+		return nil
+	},
+}
+
+// bundleFiles collects the redacted contents of a request's audit bundle.
+func bundleFiles(dbConn *db.DB, request *db.Request, reviews []*db.Review, includeRollback bool) ([]core.BundleFile, error) {
+	// Signatures are checked against the real reviewers; a pending request's
+	// bundle then names them as the exporting session may see them.
+	shown, names, err := reviewsForViewer(dbConn, request, reviews)
+	if err != nil {
+		return nil, err
+	}
+	view := redactShowView(buildShowView(dbConn, request, shown, showViewOptions{
+		WithReviews:     true,
+		WithExecution:   true,
+		WithAttachments: false,
+	}), request.Command)
+
+	var files []core.BundleFile
+	var requestJSON bytes.Buffer
+	if err := output.New(output.FormatJSON, output.WithOutput(&requestJSON)).Write(view); err != nil {
+		return nil, fmt.Errorf("rendering request: %w", err)
+	}
+	files = append(files, core.BundleFile{Name: "request.json", Data: requestJSON.Bytes()})
+
+	signatures := core.CheckReviewSignatures(dbConn, reviews)
+	if names != nil {
+		for i := range signatures {
+			signatures[i].ReviewerAgent = names[signatures[i].ReviewerAgent]
+			signatures[i].Signature = ""
+		}
+	}
+	sigJSON, err := json.MarshalIndent(signatures, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("rendering signatures: %w", err)
+	}
+	files = append(files, core.BundleFile{Name: core.BundleSignaturesName, Data: sigJSON})
+
+	for i, a := range request.Attachments {
+		name := fmt.Sprintf("attachments/%d-%s.txt", i+1, a.Type)
+		files = append(files, core.BundleFile{Name: name, Data: []byte(core.RedactBundleText(a.Content, request.Command))})
+	}
+
+	if request.Execution != nil && request.Execution.LogPath != "" {
+		if data, err := os.ReadFile(request.Execution.LogPath); err == nil {
+			name := "logs/" + filepath.Base(request.Execution.LogPath)
+			files = append(files, core.BundleFile{Name: name, Data: []byte(core.RedactBundleText(string(data), request.Command))})
+		}
+	}
+
+	trustFile, err := bundleTrustFile(dbConn, request)
+	if err != nil {
+		return nil, err
+	}
+	if trustFile != nil {
+		files = append(files, *trustFile)
+	}
+
+	if request.Rollback != nil && request.Rollback.Path != "" {
+		rollbackFiles, err := bundleRollbackFiles(request, includeRollback)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rollbackFiles...)
+	}
+
+	index, err := renderBundleIndex(view, signatures, files)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, core.BundleFile{Name: "index.html", Data: index})
+	return files, nil
+}
+
+// bundleTrustFile returns the project's trust table and the trust entry used
+// by request, or nil when the project has neither.
+func bundleTrustFile(dbConn *db.DB, request *db.Request) (*core.BundleFile, error) {
+	table, err := core.TrustTable(dbConn, request.ProjectPath, true)
+	if err != nil {
+		return nil, fmt.Errorf("loading trusted scripts: %w", err)
+	}
+	use, err := dbConn.GetTrustedScriptUse(request.ID)
+	if err != nil {
+		return nil, fmt.Errorf("loading trusted script use: %w", err)
+	}
+	if len(table) == 0 && use == nil {
+		return nil, nil
+	}
+	data, err := json.MarshalIndent(map[string]any{
+		"used_by_request": use,
+		"scripts":         table,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("rendering trusted scripts: %w", err)
+	}
+	return &core.BundleFile{Name: "trusted_scripts.json", Data: data}, nil
+}
+
+// bundleRollbackFiles returns the redacted rollback metadata and, when asked,
+// the captured state itself. Missing rollback data is not an error: it may
+// have been cleaned up by retention.
+func bundleRollbackFiles(request *db.Request, includeState bool) ([]core.BundleFile, error) {
+	data, err := core.LoadRollbackData(request.Rollback.Path)
+	if err != nil {
+		return nil, nil
+	}
+	data.CommandRaw = core.BundleCommand(request.Command)
+	meta, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("rendering rollback metadata: %w", err)
+	}
+	files := []core.BundleFile{{Name: "rollback/metadata.json", Data: meta}}
+	if !includeState {
+		return files, nil
+	}
+
+	root := request.Rollback.Path
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel == "metadata.json" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, core.BundleFile{Name: "rollback/state/" + filepath.ToSlash(rel), Data: content})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading rollback state: %w", err)
+	}
+	return files, nil
+}
+
+// redactShowView strips the raw command and anything derived from it from a
+// show view, leaving only redacted text.
+func redactShowView(view showView, cmd db.CommandSpec) showView {
+	redact := func(s string) string { return core.RedactBundleText(s, cmd) }
+
+	view.Command.Raw = core.BundleCommand(cmd)
+	view.Command.DisplayRedacted = ""
+	if cmd.ContainsSensitive {
+		view.Command.Argv = nil
+	} else if len(view.Command.Argv) > 0 {
+		argv := make([]string, len(view.Command.Argv))
+		for i, arg := range view.Command.Argv {
+			argv[i] = redact(arg)
+		}
+		view.Command.Argv = argv
+	}
+	if len(view.Command.Segments) > 0 {
+		segments := make([]string, len(view.Command.Segments))
+		for i, s := range view.Command.Segments {
+			segments[i] = redact(s)
+		}
+		view.Command.Segments = segments
+	}
+
+	view.Justification = showJustificationView{
+		Reason:         redact(view.Justification.Reason),
+		ExpectedEffect: redact(view.Justification.ExpectedEffect),
+		Goal:           redact(view.Justification.Goal),
+		SafetyArgument: redact(view.Justification.SafetyArgument),
+	}
+	if view.DryRun != nil {
+		view.DryRun = &showDryRunView{
+			Command: redact(view.DryRun.Command),
+			Output:  redact(view.DryRun.Output),
+		}
+	}
+	for i := range view.Reviews {
+		view.Reviews[i].Comments = redact(view.Reviews[i].Comments)
+	}
+	if view.Execution != nil {
+		exec := *view.Execution
+		exec.Stdout = redact(exec.Stdout)
+		exec.Stderr = redact(exec.Stderr)
+		if len(view.Execution.Segments) > 0 {
+			exec.Segments = make([]db.SegmentExecution, len(view.Execution.Segments))
+			for i, s := range view.Execution.Segments {
+				s.Command = redact(s.Command)
+				exec.Segments[i] = s
+			}
+		}
+		view.Execution = &exec
+	}
+	return view
+}
+
+var bundleIndexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"deref": func(p *int) int { return *p },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>slb request {{.View.RequestID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; max-width: 60em; }
+pre { background: #f4f4f4; padding: 0.5em; overflow-x: auto; }
+td, th { text-align: left; padding: 0.2em 0.8em 0.2em 0; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>Request {{.View.RequestID}}</h1>
+<table>
+<tr><th>Status</th><td>{{.View.Status}}</td></tr>
+<tr><th>Risk tier</th><td>{{.View.RiskTier}}</td></tr>
+<tr><th>Project</th><td>{{.View.ProjectPath}}</td></tr>
+<tr><th>Requested by</th><td>{{.View.RequestorAgent}} ({{.View.RequestorModel}})</td></tr>
+<tr><th>Created</th><td>{{.View.CreatedAt}}</td></tr>
+{{- if .View.ResolvedAt}}
+<tr><th>Resolved</th><td>{{.View.ResolvedAt}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Command</h2>
+<pre>{{.View.Command.Raw}}</pre>
+{{- with .View.Command.Cwd}}
+<p>Working directory: <code>{{.}}</code></p>
+{{- end}}
+
+<h2>Justification</h2>
+<table>
+<tr><th>Reason</th><td>{{.View.Justification.Reason}}</td></tr>
+<tr><th>Expected effect</th><td>{{.View.Justification.ExpectedEffect}}</td></tr>
+<tr><th>Goal</th><td>{{.View.Justification.Goal}}</td></tr>
+<tr><th>Safety argument</th><td>{{.View.Justification.SafetyArgument}}</td></tr>
+</table>
+
+<h2>Reviews</h2>
+{{- if .Signatures}}
+<table>
+<tr><th>Reviewer</th><th>Decision</th><th>Signature</th></tr>
+{{- range .Signatures}}
+<tr><td>{{.ReviewerAgent}}</td><td>{{.Decision}}</td><td>{{.Result}}{{with .Detail}} ({{.}}){{end}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No reviews.</p>
+{{- end}}
+
+{{- with .View.Execution}}
+<h2>Execution</h2>
+<table>
+<tr><th>Executed</th><td>{{.ExecutedAt}} by {{.ExecutedByAgent}}</td></tr>
+{{- if .ExitCode}}
+<tr><th>Exit code</th><td>{{deref .ExitCode}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+
+<h2>Files</h2>
+<ul>
+{{- range .Files}}
+<li><a href="{{.}}">{{.}}</a></li>
+{{- end}}
+</ul>
+<p>Generated by slb bundle. Run <code>slb bundle verify</code> on the archive to check it.</p>
+</body>
+</html>
+`))
+
+// renderBundleIndex renders the human-readable index page of a bundle.
+func renderBundleIndex(view showView, signatures []core.BundleSignature, files []core.BundleFile) ([]byte, error) {
+	names := make([]string, 0, len(files)+1)
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	names = append(names, core.BundleManifestName)
+
+	var buf bytes.Buffer
+	err := bundleIndexTemplate.Execute(&buf, struct {
+		View       showView
+		Signatures []core.BundleSignature
+		Files      []string
+	}{view, signatures, names})
+	if err != nil {
+		return nil, fmt.Errorf("rendering index: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestBundleCmd creates a fresh bundle command tree for testing.
+func newTestBundleCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+
+	bundleCmdTest := &cobra.Command{
+		Use:  "bundle <request-id>",
+		Args: cobra.ExactArgs(1),
+		RunE: bundleCmd.RunE,
+	}
+	bundleCmdTest.Flags().StringVar(&flagBundleOut, "out", "", "bundle file to write")
+	bundleCmdTest.Flags().BoolVar(&flagBundleIncludeRollback, "include-rollback", false, "include rollback state")
+	bundleCmdTest.AddCommand(&cobra.Command{
+		Use:  "verify <bundle>",
+		Args: cobra.ExactArgs(1),
+		RunE: bundleVerifyCmd.RunE,
+	})
+
+	root.AddCommand(bundleCmdTest)
+	return root
+}
+
+func resetBundleFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagBundleOut = ""
+	flagBundleIncludeRollback = false
+}
+
+// readBundle returns the contents of every file in a bundle.
+func readBundle(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[hdr.Name] = string(data)
+	}
+}
+
+func TestBundleCommand_RedactsAndVerifies(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetBundleFlags()
+
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+
+	logPath := filepath.Join(h.ProjectDir, "exec.log")
+	if err := os.WriteFile(logPath, []byte("$ deploy --token=s3cr3t\ndone\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	exitCode := 0
+	req := testutil.MakeRequest(t, h.DB, requestor,
+		testutil.WithCommand("deploy --token=s3cr3t", h.ProjectDir, true),
+		func(r *db.Request) {
+			r.Command.DisplayRedacted = "deploy [REDACTED]"
+			r.Command.ContainsSensitive = true
+			r.Command.Argv = []string{"deploy", "--token=s3cr3t"}
+			r.Attachments = []db.Attachment{{Type: db.AttachmentTypeContext, Content: "ran deploy --token=s3cr3t yesterday"}}
+		},
+	)
+	if err := h.DB.UpdateRequestExecution(req.ID, &db.Execution{LogPath: logPath, ExitCode: &exitCode}); err != nil {
+		t.Fatalf("record execution: %v", err)
+	}
+
+	ts := time.Now().UTC()
+	review := &db.Review{
+		RequestID:          req.ID,
+		ReviewerSessionID:  reviewer.ID,
+		ReviewerAgent:      reviewer.AgentName,
+		ReviewerModel:      reviewer.Model,
+		Decision:           db.DecisionApprove,
+		CommandHash:        req.Command.Hash,
+		Signature:          db.ComputeReviewSignature(reviewer.SessionKey, req.ID, req.Command.Hash, db.DecisionApprove, ts),
+		SignatureTimestamp: ts,
+	}
+	if err := h.DB.CreateReview(review); err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "req.tar.gz")
+	if _, err := executeCommandCapture(t, newTestBundleCmd(h.DBPath), "bundle", req.ID, "--out", out); err != nil {
+		t.Fatalf("bundle: %v", err)
+	}
+
+	files := readBundle(t, out)
+	for _, name := range []string{"manifest.json", "request.json", "signatures.json", "index.html", "logs/exec.log"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("bundle missing %s (have %v)", name, files)
+		}
+	}
+	for name, body := range files {
+		if strings.Contains(body, "s3cr3t") {
+			t.Errorf("%s contains the unredacted secret", name)
+		}
+		if strings.Contains(body, reviewer.SessionKey) || strings.Contains(body, requestor.SessionKey) {
+			t.Errorf("%s contains a session key", name)
+		}
+	}
+	if !strings.Contains(files["signatures.json"], `"result": "valid"`) {
+		t.Errorf("expected a valid signature, got %s", files["signatures.json"])
+	}
+
+	resetBundleFlags()
+	stdout, err := executeCommandCapture(t, newTestBundleCmd(h.DBPath), "bundle", "verify", out)
+	if err != nil {
+		t.Fatalf("verify: %v\n%s", err, stdout)
+	}
+	if !strings.Contains(stdout, "OK") {
+		t.Errorf("expected OK, got %q", stdout)
+	}
+}
+
+func TestBundleVerify_FailsOnInvalidSignature(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetBundleFlags()
+
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+	req := testutil.MakeRequest(t, h.DB, requestor)
+
+	review := &db.Review{
+		RequestID:          req.ID,
+		ReviewerSessionID:  reviewer.ID,
+		ReviewerAgent:      reviewer.AgentName,
+		ReviewerModel:      reviewer.Model,
+		Decision:           db.DecisionApprove,
+		Signature:          "forged",
+		SignatureTimestamp: time.Now().UTC(),
+	}
+	if err := h.DB.CreateReview(review); err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "req.tar.gz")
+	if _, err := executeCommandCapture(t, newTestBundleCmd(h.DBPath), "bundle", req.ID, "--out", out); err != nil {
+		t.Fatalf("bundle: %v", err)
+	}
+
+	resetBundleFlags()
+	stdout, err := executeCommandCapture(t, newTestBundleCmd(h.DBPath), "bundle", "verify", out)
+	if err == nil {
+		t.Fatal("expected verification to fail")
+	}
+	if !strings.Contains(stdout, "signature invalid") {
+		t.Errorf("expected invalid signature in output, got %q", stdout)
+	}
+}
@@ -0,0 +1,147 @@
+// Package cli implements the campaign commands.
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var flagCampaignDescription string
+
+func init() {
+	campaignCreateCmd.Flags().StringVar(&flagCampaignDescription, "description", "", "what the campaign is for")
+
+	campaignCmd.AddCommand(campaignCreateCmd)
+	campaignCmd.AddCommand(campaignStatusCmd)
+	campaignCmd.AddCommand(campaignListCmd)
+	rootCmd.AddCommand(campaignCmd)
+}
+
+var campaignCmd = &cobra.Command{
+	Use:   "campaign",
+	Short: "Group related requests into a campaign",
+	Long: `Group the requests of a multi-step operation, such as a ten-step database
+migration, into a campaign so their progress can be followed together.
+
+Create a campaign, then pass its ID to 'slb run' or 'slb request' with
+--campaign. 'slb campaign status' summarizes the members' statuses, and the
+history browser (slb tui) can filter by campaign.
+
+Examples:
+  id=$(slb campaign create "orders-v2 migration")
+  slb run "psql -f 001_orders.sql" --reason "step 1 of 10" --campaign "$id"
+  slb campaign status "$id"`,
+}
+
+var campaignCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a campaign and print its ID",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := projectPath()
+		if err != nil {
+			return err
+		}
+
+		dbConn, err := db.Open(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		campaign := &db.Campaign{
+			ProjectPath:        project,
+			Name:               args[0],
+			Description:        flagCampaignDescription,
+			CreatedBySessionID: flagSessionID,
+		}
+		if err := dbConn.CreateCampaign(campaign); err != nil {
+			return err
+		}
+
+		if GetOutput() != "json" {
+			fmt.Println(campaign.ID)
+			return nil
+		}
+		return output.New(output.FormatJSON).Write(campaign)
+	},
+}
+
+var campaignStatusCmd = &cobra.Command{
+	Use:   "status <campaign-id>",
+	Short: "Summarize the statuses of a campaign's requests",
+	Long: `Summarize the statuses of a campaign's requests.
+
+The campaign is complete once every member executed, failed once any member
+was rejected, cancelled, timed out or failed to execute, and in progress
+otherwise.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbConn, err := db.Open(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		summary, err := core.SummarizeCampaign(dbConn, args[0])
+		if err != nil {
+			return err
+		}
+
+		if GetOutput() == "json" {
+			return output.New(output.FormatJSON).Write(summary)
+		}
+		fmt.Printf("Campaign %s (%s): %s\n", summary.Campaign.Name, summary.Campaign.ID, summary.State)
+		if summary.Campaign.Description != "" {
+			fmt.Printf("  %s\n", summary.Campaign.Description)
+		}
+		fmt.Printf("%d request(s): %d executed, %d awaiting execution, %d awaiting review, %d failed\n",
+			summary.Total, summary.Executed, summary.AwaitingExecution, summary.AwaitingReview, summary.Failed)
+		for _, m := range summary.Members {
+			fmt.Printf("  %s  %-16s  %-9s  %s\n", m.RequestID, m.Status, m.RiskTier, m.Command)
+		}
+		return nil
+	},
+}
+
+var campaignListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the project's campaigns, newest first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := projectPath()
+		if err != nil {
+			return err
+		}
+
+		dbConn, err := db.Open(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		campaigns, err := dbConn.ListCampaigns(project)
+		if err != nil {
+			return err
+		}
+		if campaigns == nil {
+			campaigns = []*db.Campaign{}
+		}
+
+		if GetOutput() == "json" {
+			return output.New(output.FormatJSON).Write(campaigns)
+		}
+		if len(campaigns) == 0 {
+			fmt.Println("No campaigns.")
+			return nil
+		}
+		for _, c := range campaigns {
+			fmt.Printf("%s  %s  %s\n", c.ID, c.CreatedAt.Format("2006-01-02 15:04"), c.Name)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestCampaignCmd creates a fresh campaign command for testing.
+func newTestCampaignCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
+	root.PersistentFlags().StringVarP(&flagConfig, "config", "c", "", "config file")
+
+	root.AddCommand(campaignCmd)
+
+	return root
+}
+
+func resetCampaignFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagSessionID = ""
+	flagConfig = ""
+	flagCampaignDescription = ""
+}
+
+func TestCampaignCommands(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetCampaignFlags()
+
+	stdout, err := executeCommandCapture(t, newTestCampaignCmd(h.DBPath), "campaign", "create", "orders-v2", "--description", "ten steps", "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("campaign create: %v", err)
+	}
+	id := strings.TrimSpace(stdout)
+	campaign, err := h.DB.GetCampaign(id)
+	if err != nil || campaign.Name != "orders-v2" || campaign.Description != "ten steps" {
+		t.Fatalf("expected the printed ID to name the campaign, got %+v, %v", campaign, err)
+	}
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	testutil.MakeRequest(t, h.DB, sess, testutil.WithCampaign(id), testutil.WithStatus(db.StatusExecuted))
+	testutil.MakeRequest(t, h.DB, sess, testutil.WithCampaign(id), testutil.WithStatus(db.StatusPending))
+
+	resetCampaignFlags()
+	stdout, err = executeCommandCapture(t, newTestCampaignCmd(h.DBPath), "campaign", "status", id, "-j")
+	if err != nil {
+		t.Fatalf("campaign status: %v", err)
+	}
+	var summary core.CampaignSummary
+	if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+		t.Fatalf("parsing output %q: %v", stdout, err)
+	}
+	if summary.State != core.CampaignStateInProgress || summary.Total != 2 || summary.Executed != 1 || summary.AwaitingReview != 1 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	resetCampaignFlags()
+	stdout, err = executeCommandCapture(t, newTestCampaignCmd(h.DBPath), "campaign", "status", id)
+	if err != nil {
+		t.Fatalf("campaign status: %v", err)
+	}
+	if !strings.Contains(stdout, "orders-v2 ("+id+"): in_progress") || !strings.Contains(stdout, "2 request(s): 1 executed") {
+		t.Errorf("unexpected text summary: %q", stdout)
+	}
+
+	resetCampaignFlags()
+	stdout, err = executeCommandCapture(t, newTestCampaignCmd(h.DBPath), "campaign", "list", "-C", h.ProjectDir)
+	if err != nil || !strings.Contains(stdout, id) {
+		t.Errorf("campaign list = %q, %v", stdout, err)
+	}
+
+	resetCampaignFlags()
+	if _, err := executeCommandCapture(t, newTestCampaignCmd(h.DBPath), "campaign", "status", "missing"); err == nil {
+		t.Error("expected an error for an unknown campaign")
+	}
+}
@@ -2,66 +2,125 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/output"
 	"github.com/spf13/cobra"
 )
 
+var (
+	flagCancelReason   string
+	flagCancelOverride bool
+)
+
 func init() {
+	for _, c := range []*cobra.Command{cancelCmd, requestCancelCmd} {
+		c.Flags().StringVar(&flagCancelReason, "reason", "", "why the request is cancelled (recorded and sent to watchers)")
+		c.Flags().BoolVar(&flagCancelOverride, "override", false, "cancel another agent's request as an operator")
+	}
+	requestCmd.AddCommand(requestCancelCmd)
 	rootCmd.AddCommand(cancelCmd)
 }
 
+const cancelLong = `Cancel a command approval request that has not started executing.
+
+Queued, pending, approved and approval_expired requests can be cancelled;
+cancelling an approved request withdraws its approval. Requests that are
+executing or already resolved cannot.
+
+Only the requesting agent can cancel a request. Use --session-id/-s to give
+an active session of that agent in the request's project, or --override to
+cancel another agent's request as an operator. The --reason is stored with
+the request, reported to watchers with the request_cancelled event, and
+returned to a waiting 'slb run'.`
+
 var cancelCmd = &cobra.Command{
 	Use:   "cancel <request-id>",
 	Short: "Cancel a pending request",
-	Long: `Cancel a pending command approval request.
-
-You can only cancel requests that you created (matching session ID).
-Use --session-id/-s to specify your session if not using environment.`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		requestID := args[0]
-
-		if flagSessionID == "" {
-			return fmt.Errorf("--session-id is required to cancel a request")
-		}
-
-		dbConn, err := db.Open(GetDB())
-		if err != nil {
-			return fmt.Errorf("opening database: %w", err)
-		}
-		defer dbConn.Close()
-
-		// Get the request first to verify ownership
-		request, err := dbConn.GetRequest(requestID)
-		if err != nil {
-			return fmt.Errorf("getting request: %w", err)
-		}
-
-		// Verify the requestor matches
-		if request.RequestorSessionID != flagSessionID {
-			return fmt.Errorf("cannot cancel request: you are not the requestor (session mismatch)")
-		}
-
-		// Verify the request can be cancelled (pending or approved, but not yet executing)
-		if !core.CanCancel(request.Status) {
-			return fmt.Errorf("cannot cancel request: status is %s (must be pending or approved)", request.Status)
-		}
-
-		// Cancel the request
-		if err := dbConn.UpdateRequestStatus(requestID, db.StatusCancelled); err != nil {
-			return fmt.Errorf("cancelling request: %w", err)
-		}
-
-		out := output.New(output.Format(GetOutput()))
+	Long:  cancelLong,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runCancel,
+}
+
+var requestCancelCmd = &cobra.Command{
+	Use:   "cancel <request-id>",
+	Short: "Cancel a pending request",
+	Long:  cancelLong,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runCancel,
+}
+
+func runCancel(cmd *cobra.Command, args []string) error {
+	requestID := args[0]
+
+	if flagSessionID == "" {
+		return fmt.Errorf("--session-id is required to cancel a request")
+	}
+
+	dbConn, err := db.Open(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	creator, _, err := newProjectRequestCreator(dbConn)
+	if err != nil {
+		return err
+	}
+	result, err := creator.Cancel(core.CancelOptions{
+		RequestID: requestID,
+		SessionID: flagSessionID,
+		Reason:    flagCancelReason,
+		Override:  flagCancelOverride,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot cancel request: %w", err)
+	}
+	broadcastCancelled(result.Request)
+
+	// The freed slot goes to the session's next queued request. Failures
+	// are ignored; the next slb run, request or pending call admits it.
+	_, _ = creator.AdmitQueued(result.Request.RequestorSessionID)
+
+	request := result.Request
+	cancelledAt := time.Now().UTC()
+	if request.ResolvedAt != nil {
+		cancelledAt = *request.ResolvedAt
+	}
+	out := output.New(output.Format(GetOutput()))
+	if GetOutput() == "json" {
 		return out.Write(map[string]any{
-			"request_id":   requestID,
-			"status":       "cancelled",
-			"cancelled_at": time.Now().UTC().Format(time.RFC3339),
+			"request_id":      request.ID,
+			"status":          string(request.Status),
+			"previous_status": string(result.PreviousStatus),
+			"reason":          request.CancelReason,
+			"cancelled_by":    request.CancelledBy,
+			"cancelled_at":    cancelledAt.Format(time.RFC3339),
 		})
-	},
+	}
+
+	fmt.Printf("Request %s cancelled (was %s)\n", request.ID, result.PreviousStatus)
+	if request.CancelReason != "" {
+		fmt.Printf("Reason: %s\n", request.CancelReason)
+	}
+	return nil
+}
+
+// broadcastCancelled tells a running daemon that a request was cancelled so
+// watchers stop waiting on it. It is best effort, like
+// broadcastReviewOutcome.
+func broadcastCancelled(request *db.Request) {
+	if !daemon.NewClient().IsDaemonRunning() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client := daemon.NewIPCClient(daemon.DefaultSocketPath())
+	defer client.Close()
+	_ = client.Notify(ctx, "request_cancelled", daemon.CancelledEvent(request))
 }
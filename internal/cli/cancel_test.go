@@ -24,7 +24,7 @@ func newTestCancelCmd(dbPath string) *cobra.Command {
 	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
 	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
 
-	root.AddCommand(cancelCmd)
+	root.AddCommand(cancelCmd, requestCmd)
 
 	return root
 }
@@ -35,6 +35,12 @@ func resetCancelFlags() {
 	flagJSON = false
 	flagProject = ""
 	flagSessionID = ""
+	flagCancelReason = ""
+	flagCancelOverride = false
+	// A prior --help leaves cobra's help flag set on the shared command.
+	if f := cancelCmd.Flags().Lookup("help"); f != nil {
+		_ = f.Value.Set("false")
+	}
 }
 
 func TestCancelCommand_RequiresRequestID(t *testing.T) {
@@ -250,3 +256,87 @@ func TestCancelCommand_Help(t *testing.T) {
 		t.Error("expected help to mention 'pending' requests")
 	}
 }
+
+func TestCancelCommand_RequestSubcommandRecordsReason(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetCancelFlags()
+
+	sess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("TestAgent"),
+	)
+	req := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -rf ./build", h.ProjectDir, true),
+	)
+
+	cmd := newTestCancelCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "request", "cancel", req.ID,
+		"--reason", "wrong directory",
+		"-s", sess.ID,
+		"-C", h.ProjectDir,
+		"-j",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if result["reason"] != "wrong directory" || result["previous_status"] != "pending" {
+		t.Errorf("unexpected result %v", result)
+	}
+
+	updated, err := h.DB.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("failed to get request: %v", err)
+	}
+	if updated.Status != db.StatusCancelled || updated.CancelReason != "wrong directory" || updated.CancelledBy != "TestAgent" {
+		t.Errorf("unexpected cancelled request %+v", updated)
+	}
+
+	resetCancelFlags()
+	_, err = executeCommandCapture(t, newTestCancelCmd(h.DBPath), "request", "cancel", req.ID,
+		"-s", sess.ID,
+		"-C", h.ProjectDir,
+	)
+	if err == nil || !strings.Contains(err.Error(), "already resolved") {
+		t.Errorf("expected an already resolved error, got %v", err)
+	}
+}
+
+func TestCancelCommand_OverrideCancelsOthersRequest(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetCancelFlags()
+
+	requestorSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Requestor"),
+	)
+	operatorSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Operator"),
+	)
+	req := testutil.MakeRequest(t, h.DB, requestorSess,
+		testutil.WithCommand("rm -rf ./build", h.ProjectDir, true),
+	)
+
+	cmd := newTestCancelCmd(h.DBPath)
+	if _, err := executeCommandCapture(t, cmd, "cancel", req.ID,
+		"--override",
+		"--reason", "change freeze",
+		"-s", operatorSess.ID,
+		"-C", h.ProjectDir,
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := h.DB.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("failed to get request: %v", err)
+	}
+	if updated.Status != db.StatusCancelled || updated.CancelledBy != "Operator (override)" {
+		t.Errorf("unexpected cancelled request %+v", updated)
+	}
+}
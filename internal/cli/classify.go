@@ -0,0 +1,108 @@
+// Package cli implements the classify command.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagClassifyCwd   string
+	flagClassifyLocal bool
+)
+
+// classifyDaemonTimeout bounds asking the daemon to classify before falling
+// back to classifying in-process.
+const classifyDaemonTimeout = 500 * time.Millisecond
+
+func init() {
+	classifyCmd.Flags().StringVar(&flagClassifyCwd, "cwd", "", "directory the commands would run in (default: current directory)")
+	classifyCmd.Flags().BoolVar(&flagClassifyLocal, "local", false, "classify in this process even if the daemon is running")
+
+	rootCmd.AddCommand(classifyCmd)
+}
+
+var classifyCmd = &cobra.Command{
+	Use:   "classify <command>...",
+	Short: "Classify commands with the daemon's compiled patterns",
+	Long: `Classify one or more commands against the built-in patterns.
+
+When the daemon is running, the commands are sent to it in one "classify"
+call, so its already-compiled patterns spare this process the pattern
+compilation; otherwise (or with --local) they are classified in this
+process. Either way the verdicts are the built-in patterns' only: project
+risk overrides and trusted scripts are not applied (see slb simulate).
+
+slb check and slb patterns test always classify in this process.
+
+Examples:
+  slb classify "rm -rf ./build"
+  slb classify "git status" "kubectl delete ns prod" --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runClassify,
+}
+
+func runClassify(cmd *cobra.Command, args []string) error {
+	cwd := flagClassifyCwd
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	items := make([]daemon.ClassifyItem, len(args))
+	for i, command := range args {
+		items[i] = daemon.ClassifyItem{Command: command, Cwd: cwd}
+	}
+
+	source := "daemon"
+	verdicts := classifyViaDaemon(items)
+	if verdicts == nil {
+		source = "local"
+		verdicts = make([]daemon.ClassifyVerdict, len(items))
+		for i, item := range items {
+			verdicts[i] = daemon.NewClassifyVerdict(item.Command, core.Classify(item.Command, item.Cwd))
+		}
+	}
+
+	out := output.New(output.Format(GetOutput()))
+	if GetOutput() == "json" {
+		return out.Write(map[string]any{
+			"source":   source,
+			"verdicts": verdicts,
+		})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIER\tAPPROVALS\tCOMMAND")
+	for _, v := range verdicts {
+		tier := v.Tier
+		if tier == "" {
+			tier = "none"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", tier, v.MinApprovals, v.Command)
+	}
+	return w.Flush()
+}
+
+// classifyViaDaemon classifies items with the running daemon, or returns nil
+// when --local is set or no daemon answers in time.
+func classifyViaDaemon(items []daemon.ClassifyItem) []daemon.ClassifyVerdict {
+	if flagClassifyLocal || len(items) > daemon.MaxClassifyBatch {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), classifyDaemonTimeout)
+	defer cancel()
+	client := daemon.NewIPCClient(daemon.DefaultSocketPath())
+	defer client.Close()
+	verdicts, err := client.Classify(ctx, items)
+	if err != nil {
+		return nil
+	}
+	return verdicts
+}
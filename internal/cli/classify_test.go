@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestClassifyCmd creates a fresh classify command for testing.
+func newTestClassifyCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+
+	clsCmd := &cobra.Command{
+		Use:  "classify <command>...",
+		Args: cobra.MinimumNArgs(1),
+		RunE: classifyCmd.RunE,
+	}
+	clsCmd.Flags().StringVar(&flagClassifyCwd, "cwd", "", "working directory")
+	clsCmd.Flags().BoolVar(&flagClassifyLocal, "local", false, "classify locally")
+
+	root.AddCommand(clsCmd)
+
+	return root
+}
+
+func resetClassifyFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagClassifyCwd = ""
+	flagClassifyLocal = false
+}
+
+func TestClassifyCommand_Local(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetClassifyFlags()
+
+	cmd := newTestClassifyCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "classify", "-j", "--local", "--cwd", h.ProjectDir,
+		"git status", "kubectl delete namespace prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result struct {
+		Source   string `json:"source"`
+		Verdicts []struct {
+			Command       string `json:"command"`
+			Tier          string `json:"tier"`
+			NeedsApproval bool   `json:"needs_approval"`
+		} `json:"verdicts"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if result.Source != "local" || len(result.Verdicts) != 2 {
+		t.Fatalf("expected two local verdicts, got %+v", result)
+	}
+	if v := result.Verdicts[0]; v.Command != "git status" || v.NeedsApproval {
+		t.Errorf("git status verdict = %+v", v)
+	}
+	if v := result.Verdicts[1]; v.Tier != "critical" || !v.NeedsApproval {
+		t.Errorf("kubectl verdict = %+v", v)
+	}
+}
+
+func TestClassifyCommand_Text(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetClassifyFlags()
+
+	cmd := newTestClassifyCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "classify", "--local", "--cwd", h.ProjectDir, "rm -rf ./build")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout, "dangerous") || !strings.Contains(stdout, "rm -rf ./build") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+}
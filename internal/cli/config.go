@@ -1,10 +1,12 @@
 package cli
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"text/tabwriter"
 
 	"github.com/Dicklesworthstone/slb/internal/config"
 	"github.com/Dicklesworthstone/slb/internal/output"
@@ -12,7 +14,8 @@ import (
 )
 
 var (
-	flagConfigGlobal bool
+	flagConfigGlobal       bool
+	flagConfigEffectiveCwd string
 )
 
 func init() {
@@ -21,6 +24,9 @@ func init() {
 	configCmd.AddCommand(configGetCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configEffectiveCmd)
+
+	configEffectiveCmd.Flags().StringVar(&flagConfigEffectiveCwd, "cwd", "", "working directory a request would run in; the project policy is looked up from here (default: the project)")
 
 	rootCmd.AddCommand(configCmd)
 }
@@ -84,7 +90,10 @@ var configSetCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		userPath, projectPath := config.ConfigPaths(project, flagConfig)
+		userPath, projectPath, err := config.ConfigPaths(project, flagConfig)
+		if err != nil {
+			return err
+		}
 		target := projectPath
 		if flagConfigGlobal {
 			target = userPath
@@ -115,7 +124,10 @@ var configEditCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		userPath, projectPath := config.ConfigPaths(project, flagConfig)
+		userPath, projectPath, err := config.ConfigPaths(project, flagConfig)
+		if err != nil {
+			return err
+		}
 		target := projectPath
 		if flagConfigGlobal {
 			target = userPath
@@ -141,3 +153,79 @@ var configEditCmd = &cobra.Command{
 		return editCmd.Run()
 	},
 }
+
+var configEffectiveCmd = &cobra.Command{
+	Use:   "effective",
+	Short: "Show the effective configuration and where each setting came from",
+	Long: `Show every setting in effect for a project and the layer that set it:
+default, user (~/.slb/config.toml), workspace, project (.slb/config.toml),
+policy (the nearest .slb/policy.toml above the request's working directory),
+env (SLB_*) or flag. Later layers win.
+
+Pass --cwd to see the settings a request run from that directory would get.
+
+Examples:
+  slb config effective --project ~/src/monorepo
+  slb config effective --project ~/src/monorepo --cwd ~/src/monorepo/infra`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := projectPath()
+		if err != nil {
+			return err
+		}
+		policyDir := flagConfigEffectiveCwd
+		if policyDir == "" {
+			policyDir = project
+		}
+		_, settings, err := config.Effective(config.LoadOptions{
+			ProjectDir: project,
+			ConfigPath: flagConfig,
+			PolicyDir:  policyDir,
+		})
+		if err != nil {
+			return err
+		}
+		policy, err := config.FindPolicyFile(policyDir)
+		if err != nil {
+			return err
+		}
+
+		if GetOutput() != "text" {
+			return output.New(output.Format(GetOutput())).Write(map[string]any{
+				"project":  project,
+				"policy":   policy,
+				"settings": settings,
+			})
+		}
+
+		if policy != "" {
+			fmt.Printf("Project policy: %s\n\n", policy)
+		} else {
+			fmt.Printf("Project policy: none\n\n")
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, s := range settings {
+			source := s.Source
+			if s.Origin != "" {
+				source += " (" + s.Origin + ")"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, settingValueText(s.Value), source)
+		}
+		return w.Flush()
+	},
+}
+
+// settingValueText renders a setting value for the effective config table,
+// shortening long lists such as the tier patterns.
+func settingValueText(v any) string {
+	const maxLen = 60
+	text := fmt.Sprintf("%v", v)
+	if data, err := json.Marshal(v); err == nil {
+		text = string(data)
+	}
+	if len(text) > maxLen {
+		text = text[:maxLen-3] + "..."
+	}
+	return text
+}
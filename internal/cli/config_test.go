@@ -2,6 +2,8 @@ package cli
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -45,7 +47,14 @@ func newTestConfigCmd(dbPath string) *cobra.Command {
 		RunE:  configSetCmd.RunE,
 	}
 
-	cfgCmd.AddCommand(getCmd, setCmd)
+	effectiveCmd := &cobra.Command{
+		Use:  "effective",
+		Args: cobra.NoArgs,
+		RunE: configEffectiveCmd.RunE,
+	}
+	effectiveCmd.Flags().StringVar(&flagConfigEffectiveCwd, "cwd", "", "policy lookup directory")
+
+	cfgCmd.AddCommand(getCmd, setCmd, effectiveCmd)
 	root.AddCommand(cfgCmd)
 
 	return root
@@ -58,6 +67,7 @@ func resetConfigFlags() {
 	flagProject = ""
 	flagConfig = ""
 	flagConfigGlobal = false
+	flagConfigEffectiveCwd = ""
 }
 
 func TestConfigCommand_ShowsConfig(t *testing.T) {
@@ -207,3 +217,63 @@ func TestConfigCommand_Help(t *testing.T) {
 		t.Error("expected help to mention '--global' flag")
 	}
 }
+
+func TestConfigEffectiveCommand_ShowsPolicySource(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetConfigFlags()
+	t.Setenv("HOME", t.TempDir())
+
+	infra := filepath.Join(h.ProjectDir, "infra")
+	policy := filepath.Join(infra, ".slb", "policy.toml")
+	if err := os.MkdirAll(filepath.Dir(policy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(policy, []byte("[general]\nmin_approvals = 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newTestConfigCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "config", "effective", "-C", h.ProjectDir, "--cwd", infra, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result struct {
+		Policy   string `json:"policy"`
+		Settings []struct {
+			Key    string `json:"key"`
+			Value  any    `json:"value"`
+			Source string `json:"source"`
+			Origin string `json:"origin"`
+		} `json:"settings"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if result.Policy != policy {
+		t.Errorf("policy = %q, want %q", result.Policy, policy)
+	}
+	found := false
+	for _, s := range result.Settings {
+		if s.Key != "general.min_approvals" {
+			continue
+		}
+		found = true
+		if s.Value != float64(2) || s.Source != "policy" || s.Origin != policy {
+			t.Errorf("general.min_approvals = %+v, want 2 from the policy", s)
+		}
+	}
+	if !found {
+		t.Error("general.min_approvals missing from effective settings")
+	}
+
+	// A malformed policy is an error, not a silent fallback.
+	if err := os.WriteFile(policy, []byte("[general]\nmin_aprovals = 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resetConfigFlags()
+	cmd = newTestConfigCmd(h.DBPath)
+	_, err = executeCommandCapture(t, cmd, "config", "effective", "-C", h.ProjectDir, "--cwd", infra, "-j")
+	if err == nil || !strings.Contains(err.Error(), "unknown settings") {
+		t.Fatalf("expected unknown settings error, got %v", err)
+	}
+}
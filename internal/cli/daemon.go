@@ -3,6 +3,7 @@ package cli
 import (
 	"bufio"
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/daemon"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/output"
@@ -18,6 +20,8 @@ import (
 
 var (
 	flagDaemonStartForeground bool
+	flagDaemonListen          string
+	flagDaemonTokenKey        string
 	flagDaemonStopTimeoutSecs int
 	flagDaemonLogsFollow      bool
 	flagDaemonLogsLines       int
@@ -28,8 +32,12 @@ func init() {
 	daemonCmd.AddCommand(daemonStopCmd)
 	daemonCmd.AddCommand(daemonStatusCmd)
 	daemonCmd.AddCommand(daemonLogsCmd)
+	daemonCmd.AddCommand(daemonAPITokenCmd)
 
 	daemonStartCmd.Flags().BoolVar(&flagDaemonStartForeground, "foreground", false, "run the daemon in the current process (do not fork)")
+	daemonStartCmd.Flags().StringVar(&flagDaemonListen, "listen", "", "also serve the HTTP API for remote reviewers on this address (e.g. 127.0.0.1:8787)")
+
+	daemonAPITokenCmd.Flags().StringVarP(&flagDaemonTokenKey, "session-key", "k", "", "session HMAC key (required)")
 
 	daemonStopCmd.Flags().IntVar(&flagDaemonStopTimeoutSecs, "timeout", 10, "seconds to wait for graceful shutdown")
 
@@ -47,6 +55,21 @@ var daemonCmd = &cobra.Command{
 var daemonStartCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the daemon",
+	Long: `Start the daemon. It always serves the local Unix socket.
+
+With --listen it also serves a JSON HTTP API so reviewers on other machines
+can list and review requests:
+
+  GET  /requests?status=pending   list the project's requests
+  GET  /requests/{id}             a request and its reviews
+  POST /requests/{id}/reviews     submit a signed review
+  GET  /events                    server-sent events (?type=...&replay=N)
+
+Calls authenticate with "Authorization: Bearer <token>" where the token is
+derived from a session key (see 'slb daemon api-token') and only see the
+session's project. Reviews carry a signature computed client-side, so the
+session key is never sent. The API has no TLS: bind it to localhost or a
+private network, or put it behind a TLS proxy.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		project, err := daemonProjectPath()
 		if err != nil {
@@ -58,30 +81,40 @@ var daemonStartCmd = &cobra.Command{
 
 		startedAt := time.Now().UTC().Format(time.RFC3339)
 		socketPath := daemon.DefaultSocketPath()
+		opts := daemon.DefaultServerOptions()
+		opts.HTTPAddr = flagDaemonListen
 
 		if flagDaemonStartForeground {
 			out := output.New(output.Format(GetOutput()))
-			_ = out.Write(map[string]any{
+			result := map[string]any{
 				"pid":         os.Getpid(),
 				"socket_path": socketPath,
 				"started_at":  startedAt,
 				"foreground":  true,
-			})
-			return daemon.RunDaemon(context.Background(), daemon.DefaultServerOptions())
+			}
+			if opts.HTTPAddr != "" {
+				result["http_addr"] = opts.HTTPAddr
+			}
+			_ = out.Write(result)
+			return daemon.RunDaemon(context.Background(), opts)
 		}
 
-		if err := daemon.StartDaemon(); err != nil {
+		if err := daemon.StartDaemonWithOptions(context.Background(), opts); err != nil {
 			return err
 		}
 
 		info := daemon.NewClient().GetStatusInfo()
 		out := output.New(output.Format(GetOutput()))
-		return out.Write(map[string]any{
+		result := map[string]any{
 			"pid":         info.PID,
 			"socket_path": info.SocketPath,
 			"started_at":  startedAt,
 			"foreground":  false,
-		})
+		}
+		if opts.HTTPAddr != "" {
+			result["http_addr"] = opts.HTTPAddr
+		}
+		return out.Write(result)
 	},
 }
 
@@ -133,8 +166,7 @@ var daemonStatusCmd = &cobra.Command{
 
 		pendingCount, activeSessions := daemonProjectStats(project)
 
-		out := output.New(output.Format(GetOutput()))
-		return out.Write(map[string]any{
+		result := map[string]any{
 			"running":         info.Status == daemon.DaemonRunning,
 			"status":          info.Status.String(),
 			"pid":             info.PID,
@@ -146,7 +178,78 @@ var daemonStatusCmd = &cobra.Command{
 			"socket_path":     info.SocketPath,
 			"socket_alive":    info.SocketAlive,
 			"message":         info.Message,
-		})
+		}
+		if info.SocketAlive {
+			for k, v := range daemonPeerFields(info.SocketPath) {
+				result[k] = v
+			}
+		}
+
+		out := output.New(output.Format(GetOutput()))
+		return out.Write(result)
+	},
+}
+
+// daemonPeerFields reports the running daemon's version and features from
+// the IPC handshake, or why they could not be negotiated.
+func daemonPeerFields(socketPath string) map[string]any {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	client := daemon.NewIPCClient(socketPath)
+	defer client.Close()
+	peer, err := client.Handshake(ctx)
+	if err != nil {
+		return map[string]any{"protocol_error": err.Error()}
+	}
+	return map[string]any{
+		"daemon_version":  peer.Version,
+		"daemon_protocol": peer.Protocol,
+		"daemon_features": peer.Features,
+	}
+}
+
+var daemonAPITokenCmd = &cobra.Command{
+	Use:   "api-token",
+	Short: "Print the HTTP API bearer token for a session",
+	Long: `Print the bearer token a remote reviewer sends to the daemon's HTTP API
+(slb daemon start --listen). It is derived from the session key, which is
+checked against the session first; the key itself never leaves this machine.
+
+Example:
+  curl -H "Authorization: Bearer $(slb daemon api-token -s <id> -k <key>)" \
+    http://127.0.0.1:8787/requests?status=pending`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagSessionID == "" {
+			return fmt.Errorf("--session-id is required")
+		}
+		if flagDaemonTokenKey == "" {
+			return fmt.Errorf("--session-key is required")
+		}
+		dbConn, err := db.OpenAndMigrate(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		sess, err := dbConn.GetSession(flagSessionID)
+		if err != nil {
+			return fmt.Errorf("getting session: %w", err)
+		}
+		if subtle.ConstantTimeCompare([]byte(flagDaemonTokenKey), []byte(sess.SessionKey)) != 1 {
+			return core.ErrSessionKeyMismatch
+		}
+
+		token := daemon.HTTPToken(sess.ID, sess.SessionKey)
+		if GetOutput() == "json" {
+			return output.New(output.FormatJSON).Write(map[string]any{
+				"session_id": sess.ID,
+				"token":      token,
+			})
+		}
+		fmt.Println(token)
+		return nil
 	},
 }
 
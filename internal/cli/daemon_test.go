@@ -1,11 +1,15 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +23,9 @@ func resetDaemonFlags() {
 	flagDaemonStopTimeoutSecs = 10
 	flagDaemonLogsFollow = false
 	flagDaemonLogsLines = 200
+	flagDaemonListen = ""
+	flagDaemonTokenKey = ""
+	flagSessionID = ""
 }
 
 func TestDaemonProjectPath_FromFlag(t *testing.T) {
@@ -527,3 +534,49 @@ func TestDaemonLogPath_HomeUnset(t *testing.T) {
 		}
 	}
 }
+
+func newTestDaemonAPITokenCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
+
+	apiToken := &cobra.Command{
+		Use:  "api-token",
+		Args: cobra.NoArgs,
+		RunE: daemonAPITokenCmd.RunE,
+	}
+	apiToken.Flags().StringVarP(&flagDaemonTokenKey, "session-key", "k", "", "session HMAC key")
+	root.AddCommand(apiToken)
+	return root
+}
+
+func TestDaemonAPIToken(t *testing.T) {
+	h := testutil.NewHarness(t)
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+
+	resetDaemonFlags()
+	cmd := newTestDaemonAPITokenCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "api-token", "-s", sess.ID, "-k", sess.SessionKey, "-j")
+	if err != nil {
+		t.Fatalf("api-token: %v", err)
+	}
+	var out map[string]string
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("decoding %q: %v", stdout, err)
+	}
+	if out["token"] != daemon.HTTPToken(sess.ID, sess.SessionKey) || !strings.HasPrefix(out["token"], sess.ID+".") {
+		t.Errorf("unexpected token %+v", out)
+	}
+
+	resetDaemonFlags()
+	cmd = newTestDaemonAPITokenCmd(h.DBPath)
+	if _, err := executeCommandCapture(t, cmd, "api-token", "-s", sess.ID, "-k", "wrong"); !errors.Is(err, core.ErrSessionKeyMismatch) {
+		t.Errorf("expected ErrSessionKeyMismatch, got %v", err)
+	}
+}
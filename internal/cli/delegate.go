@@ -0,0 +1,241 @@
+// Package cli implements the delegate command for standing approvals.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagDelegateKey     string
+	flagDelegatePattern string
+	flagDelegateTier    string
+	flagDelegateTTL     time.Duration
+	flagDelegateComment string
+	flagDelegateAll     bool
+)
+
+func init() {
+	delegateCmd.Flags().StringVarP(&flagDelegateKey, "session-key", "k", "", "reviewer session HMAC key for signing (required)")
+	delegateCmd.Flags().StringVar(&flagDelegatePattern, "pattern", "", "glob over the normalized command (required)")
+	delegateCmd.Flags().StringVar(&flagDelegateTier, "tier", string(core.RiskTierCaution), "highest tier approved: caution or dangerous")
+	delegateCmd.Flags().DurationVar(&flagDelegateTTL, "ttl", time.Hour, "how long the delegation lasts")
+	delegateCmd.Flags().StringVarP(&flagDelegateComment, "comment", "m", "", "note recorded with the delegation")
+	delegateRevokeCmd.Flags().StringVarP(&flagDelegateKey, "session-key", "k", "", "session HMAC key (required)")
+	delegateListCmd.Flags().BoolVar(&flagDelegateAll, "all", false, "include revoked and expired delegations")
+
+	delegateCmd.AddCommand(delegateListCmd)
+	delegateCmd.AddCommand(delegateRevokeCmd)
+	rootCmd.AddCommand(delegateCmd)
+}
+
+var delegateCmd = &cobra.Command{
+	Use:   "delegate",
+	Short: "Grant time-boxed standing approval for a command pattern",
+	Long: `Record a signed delegation: until it expires, new requests whose normalized
+command matches --pattern, at --tier or below, are approved on the
+reviewer's behalf as soon as they are created.
+
+The approval is an ordinary review by the delegating session, with a comment
+naming the delegation, so the usual review rules still apply: it never
+approves the reviewer's own requests and counts once toward the quorum.
+Compound commands are never covered, and delegations cannot cover CRITICAL
+requests. Expired delegations are removed by the daemon's sweep.
+
+The pattern is a glob: * matches any run of characters, ? any single one.
+
+Examples:
+  slb delegate --pattern "terraform apply -target=module.cache*" --tier dangerous --ttl 4h -s <reviewer> -k <key>
+  slb delegate list
+  slb delegate revoke <id> -s <reviewer> -k <key>`,
+	Args: cobra.NoArgs,
+	RunE: runDelegate,
+}
+
+var delegateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the project's delegations",
+	Args:  cobra.NoArgs,
+	RunE:  runDelegateList,
+}
+
+var delegateRevokeCmd = &cobra.Command{
+	Use:   "revoke <delegation-id>",
+	Short: "Revoke a delegation before it expires",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDelegateRevoke,
+}
+
+func runDelegate(cmd *cobra.Command, args []string) error {
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	sess, err := keyedSession(dbConn, flagDelegateKey)
+	if err != nil {
+		return err
+	}
+	d, err := core.NewDelegation(sess, project, flagDelegatePattern, db.RiskTier(strings.ToLower(flagDelegateTier)),
+		flagDelegateTTL, flagDelegateComment, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := dbConn.CreateDelegation(d); err != nil {
+		return err
+	}
+
+	if GetOutput() == "json" {
+		return output.New(output.FormatJSON).Write(d)
+	}
+	fmt.Printf("Delegated %s approval of %q until %s as %s\n",
+		d.MaxTier, d.Pattern, d.ExpiresAt.Local().Format(time.RFC3339), d.ID)
+	return nil
+}
+
+func runDelegateList(cmd *cobra.Command, args []string) error {
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	now := time.Now()
+	list, err := dbConn.ListDelegations(project, flagDelegateAll, now)
+	if err != nil {
+		return err
+	}
+	if GetOutput() == "json" {
+		if list == nil {
+			list = []*db.Delegation{}
+		}
+		return output.New(output.FormatJSON).Write(list)
+	}
+	if len(list) == 0 {
+		fmt.Printf("No delegations in %s\n", project)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPATTERN\tTIER\tDELEGATED_BY\tEXPIRES_AT\tSTATUS")
+	for _, d := range list {
+		status := "active"
+		switch {
+		case d.RevokedAt != nil:
+			status = "revoked"
+		case !d.ActiveAt(now):
+			status = "expired"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			d.ID[:8], d.Pattern, d.MaxTier, d.AgentName, d.ExpiresAt.Local().Format(time.RFC3339), status)
+	}
+	return w.Flush()
+}
+
+func runDelegateRevoke(cmd *cobra.Command, args []string) error {
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	sess, err := keyedSession(dbConn, flagDelegateKey)
+	if err != nil {
+		return err
+	}
+	d, err := findDelegation(dbConn, project, args[0])
+	if err != nil {
+		return err
+	}
+	if err := dbConn.RevokeDelegation(d.ID, sess.AgentName, time.Now()); err != nil {
+		return err
+	}
+
+	if GetOutput() == "json" {
+		return output.New(output.FormatJSON).Write(map[string]any{
+			"id":         d.ID,
+			"pattern":    d.Pattern,
+			"revoked_by": sess.AgentName,
+		})
+	}
+	fmt.Printf("Revoked delegation %s for %q\n", d.ID, d.Pattern)
+	return nil
+}
+
+// findDelegation returns the project's active delegation whose ID starts
+// with ref.
+func findDelegation(dbConn *db.DB, project, ref string) (*db.Delegation, error) {
+	list, err := dbConn.ListDelegations(project, false, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var match *db.Delegation
+	for _, d := range list {
+		if ref != "" && strings.HasPrefix(d.ID, ref) {
+			if match != nil {
+				return nil, fmt.Errorf("delegation ID %s is ambiguous", ref)
+			}
+			match = d
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: %s", db.ErrDelegationNotFound, ref)
+	}
+	return match, nil
+}
+
+// applyDelegations approves a newly pending request under the project's
+// delegations that cover it, announcing any decision to a running daemon,
+// and returns the request as it now stands. Failures leave the request
+// pending for reviewers.
+func applyDelegations(dbConn *db.DB, cfg config.Config, request *db.Request) *db.Request {
+	if request.Status != db.StatusPending {
+		return request
+	}
+	results, err := core.NewReviewService(dbConn, toReviewConfig(cfg)).ApplyDelegations(request)
+	if err != nil && GetOutput() != "json" {
+		fmt.Fprintf(os.Stderr, "[slb] Applying delegations failed: %v\n", err)
+	}
+	if len(results) == 0 {
+		return request
+	}
+	for _, r := range results {
+		if GetOutput() != "json" {
+			fmt.Fprintf(os.Stderr, "[slb] Approved by %s under delegation %s\n", r.Review.ReviewerAgent, shortDelegationID(r.Review.DelegationID))
+		}
+		broadcastReviewOutcome(request, r)
+	}
+	updated, err := dbConn.GetRequest(request.ID)
+	if err != nil {
+		return request
+	}
+	return updated
+}
+
+func shortDelegationID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestDelegateCmd creates a fresh delegate command for testing.
+func newTestDelegateCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
+
+	root.AddCommand(delegateCmd)
+
+	return root
+}
+
+func resetDelegateFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagSessionID = ""
+	flagDelegateKey = ""
+	flagDelegatePattern = ""
+	flagDelegateTier = "caution"
+	flagDelegateTTL = time.Hour
+	flagDelegateComment = ""
+	flagDelegateAll = false
+}
+
+func TestDelegateCommand_CreateListRevoke(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetDelegateFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+	stdout, err := executeCommandCapture(t, newTestDelegateCmd(h.DBPath), "delegate",
+		"--pattern", "terraform apply -target=module.cache*", "--tier", "dangerous", "--ttl", "4h",
+		"-C", h.ProjectDir, "-s", sess.ID, "-k", sess.SessionKey, "-m", "cache rollout", "-j")
+	if err != nil {
+		t.Fatalf("delegate: %v", err)
+	}
+	var created db.Delegation
+	if err := json.Unmarshal([]byte(stdout), &created); err != nil {
+		t.Fatalf("parsing delegate output %q: %v", stdout, err)
+	}
+	if created.MaxTier != db.RiskTierDangerous || created.AgentName != "Reviewer" || created.ExpiresAt.Sub(created.CreatedAt).Hours() != 4 {
+		t.Fatalf("unexpected delegation %+v", created)
+	}
+	if !db.VerifyDelegationSignature(sess.SessionKey, &created) {
+		t.Error("expected the delegation signed with the reviewer's key")
+	}
+
+	resetDelegateFlags()
+	stdout, err = executeCommandCapture(t, newTestDelegateCmd(h.DBPath), "delegate", "list", "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("delegate list: %v", err)
+	}
+	if !strings.Contains(stdout, created.ID[:8]) || !strings.Contains(stdout, "active") {
+		t.Errorf("expected the active delegation listed, got %q", stdout)
+	}
+
+	resetDelegateFlags()
+	if _, err := executeCommandCapture(t, newTestDelegateCmd(h.DBPath), "delegate", "revoke", created.ID[:8],
+		"-C", h.ProjectDir, "-s", sess.ID, "-k", sess.SessionKey); err != nil {
+		t.Fatalf("delegate revoke: %v", err)
+	}
+
+	resetDelegateFlags()
+	stdout, err = executeCommandCapture(t, newTestDelegateCmd(h.DBPath), "delegate", "list", "--all", "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("delegate list --all: %v", err)
+	}
+	if !strings.Contains(stdout, "revoked") {
+		t.Errorf("expected the revoked delegation with --all, got %q", stdout)
+	}
+}
+
+func TestDelegateCommand_RefusesCriticalTier(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetDelegateFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	_, err := executeCommandCapture(t, newTestDelegateCmd(h.DBPath), "delegate",
+		"--pattern", "rm -rf *", "--tier", "critical", "--ttl", "1h",
+		"-C", h.ProjectDir, "-s", sess.ID, "-k", sess.SessionKey)
+	if err == nil || !strings.Contains(err.Error(), "critical") {
+		t.Errorf("expected a critical tier error, got %v", err)
+	}
+
+	resetDelegateFlags()
+	_, err = executeCommandCapture(t, newTestDelegateCmd(h.DBPath), "delegate",
+		"--pattern", "make deploy", "--ttl", "1h", "-C", h.ProjectDir, "-s", sess.ID)
+	if err == nil || !strings.Contains(err.Error(), "--session-key is required") {
+		t.Errorf("expected a missing key error, got %v", err)
+	}
+}
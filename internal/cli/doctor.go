@@ -0,0 +1,66 @@
+// Package cli implements the doctor command.
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/integrations"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check connectivity to configured integrations",
+	Long: `Ping each enabled integration (Agent Mail, notification webhook) and
+report which are reachable. An unreachable integration means its notifications
+will fail; approvals and execution are unaffected.
+
+The daemon runs the same checks at startup and logs the results.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := projectPath()
+		if err != nil {
+			return fmt.Errorf("resolving project: %w", err)
+		}
+		cfg, err := config.Load(config.LoadOptions{
+			ProjectDir: project,
+			ConfigPath: flagConfig,
+		})
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+
+		results := integrations.CheckConnectivity(context.Background(), daemon.ConfiguredIntegrations(project, cfg), 0)
+
+		if GetOutput() == "json" {
+			type doctorView struct {
+				Integrations []integrations.PingResult `json:"integrations"`
+			}
+			if results == nil {
+				results = []integrations.PingResult{}
+			}
+			return output.New(output.Format(GetOutput())).Write(doctorView{Integrations: results})
+		}
+
+		if len(results) == 0 {
+			fmt.Println("No integrations enabled.")
+			return nil
+		}
+		for _, r := range results {
+			if r.Reachable {
+				fmt.Printf("%-12s reachable (%dms)\n", r.Name, r.LatencyMS)
+			} else {
+				fmt.Printf("%-12s UNREACHABLE: %s\n", r.Name, r.Error)
+			}
+		}
+		return nil
+	},
+}
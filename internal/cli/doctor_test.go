@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestDoctorCmd creates a fresh doctor command for testing.
+func newTestDoctorCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagConfig, "config", "c", "", "config file")
+
+	root.AddCommand(&cobra.Command{
+		Use:  "doctor",
+		Args: cobra.NoArgs,
+		RunE: doctorCmd.RunE,
+	})
+	return root
+}
+
+func resetDoctorFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagConfig = ""
+}
+
+func writeDoctorConfig(t *testing.T, projectDir, webhookURL string) {
+	t.Helper()
+	cfg := "[integrations]\nagent_mail_enabled = false\n\n[notifications]\nwebhook_url = \"" + webhookURL + "\"\n"
+	if err := os.WriteFile(filepath.Join(projectDir, ".slb", "config.toml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDoctorCommand_ReportsReachableWebhook(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetDoctorFlags()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	writeDoctorConfig(t, h.ProjectDir, server.URL)
+
+	stdout, err := executeCommandCapture(t, newTestDoctorCmd(h.DBPath), "doctor", "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	var view struct {
+		Integrations []struct {
+			Name      string `json:"name"`
+			Reachable bool   `json:"reachable"`
+		} `json:"integrations"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &view); err != nil {
+		t.Fatalf("parse json: %v\n%s", err, stdout)
+	}
+	if len(view.Integrations) != 1 || view.Integrations[0].Name != "webhook" || !view.Integrations[0].Reachable {
+		t.Fatalf("unexpected report: %+v", view.Integrations)
+	}
+}
+
+func TestDoctorCommand_UnreachableWebhookIsReportedNotFatal(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetDoctorFlags()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+	writeDoctorConfig(t, h.ProjectDir, url)
+
+	stdout, err := executeCommandCapture(t, newTestDoctorCmd(h.DBPath), "doctor", "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	if !strings.Contains(stdout, "webhook") || !strings.Contains(stdout, "UNREACHABLE") {
+		t.Errorf("expected unreachable webhook, got %q", stdout)
+	}
+}
@@ -149,6 +149,12 @@ To get the command hash for --ack, run:
 			}
 			data, err := core.CaptureRollbackState(context.Background(), rollbackReq, core.RollbackCaptureOptions{
 				MaxSizeBytes: int64(cfg.General.MaxRollbackSizeMB) * 1024 * 1024,
+				RootPrefix:   cfg.General.RollbackRootPrefix,
+				MaxCaptures:  cfg.General.MaxRollbackCaptures,
+				// An emergency does not wait behind other captures.
+				MaxConcurrent: cfg.General.MaxConcurrentCaptures,
+				Wait:          -1,
+				Exclude:       cfg.General.RollbackExclude,
 			})
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "warning: rollback capture failed: %v\n", err)
@@ -3,10 +3,12 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/config"
 	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/integrations"
 	"github.com/Dicklesworthstone/slb/internal/output"
@@ -79,7 +81,9 @@ Examples:
 		}
 
 		// Create executor
-		executor := core.NewExecutor(dbConn, nil).WithNotifier(buildAgentMailNotifier(req.ProjectPath))
+		executor := core.NewExecutor(dbConn, nil).WithNotifier(buildAgentMailNotifier(req.ProjectPath)).
+			WithRiskOverrides(toRiskOverrideRules(cfg.RiskOverrides)).
+			WithChangeRecords(cfg.Integrations.ChangeRecordURL, cfg.Integrations.ChangeRecordTiers)
 
 		// Check if we can execute first
 		canExec, reason := executor.CanExecute(requestID)
@@ -89,19 +93,29 @@ Examples:
 
 		// Build options
 		opts := core.ExecuteOptions{
-			RequestID:         requestID,
-			SessionID:         flagExecuteSessionID,
-			Timeout:           time.Duration(flagExecuteTimeout) * time.Second,
-			Background:        flagExecuteBackground,
-			LogDir:            flagExecuteLogDir,
-			SuppressOutput:    GetOutput() == "json",
-			CaptureRollback:   cfg.General.EnableRollbackCapture,
-			MaxRollbackSizeMB: cfg.General.MaxRollbackSizeMB,
+			RequestID:               requestID,
+			SessionID:               flagExecuteSessionID,
+			Timeout:                 time.Duration(flagExecuteTimeout) * time.Second,
+			Background:              flagExecuteBackground,
+			LogDir:                  flagExecuteLogDir,
+			SuppressOutput:          GetOutput() == "json",
+			CaptureRollback:         cfg.General.EnableRollbackCapture,
+			RunAllApprovedSegments:  cfg.General.RunAllApprovedSegments,
+			MaxRollbackSizeMB:       cfg.General.MaxRollbackSizeMB,
+			RollbackRootPrefix:      cfg.General.RollbackRootPrefix,
+			MaxRollbackCaptures:     cfg.General.MaxRollbackCaptures,
+			MaxConcurrentCaptures:   cfg.General.MaxConcurrentCaptures,
+			RollbackCaptureWait:     time.Duration(cfg.General.RollbackCaptureWaitSecs) * time.Second,
+			RollbackExclude:         cfg.General.RollbackExclude,
+			MaxConcurrentExecutions: cfg.General.MaxConcurrentExecutions,
+			ExecutionQueueWait:      time.Duration(cfg.General.ExecutionQueueWaitSecs) * time.Second,
+			OnExecutionQueued:       reportExecutionQueued,
 		}
 
 		// Execute
 		ctx := context.Background()
 		result, err := executor.ExecuteApprovedRequest(ctx, opts)
+		err = withExecuteOutcome(err)
 
 		// Build output
 		type executeResult struct {
@@ -111,6 +125,8 @@ Examples:
 			LogPath    string `json:"log_path"`
 			TimedOut   bool   `json:"timed_out,omitempty"`
 			Error      string `json:"error,omitempty"`
+
+			Segments []db.SegmentExecution `json:"segments,omitempty"`
 		}
 
 		resp := executeResult{
@@ -122,6 +138,7 @@ Examples:
 			resp.DurationMs = result.Duration.Milliseconds()
 			resp.LogPath = result.LogPath
 			resp.TimedOut = result.TimedOut
+			resp.Segments = result.Segments
 		}
 
 		if err != nil {
@@ -148,8 +165,39 @@ Examples:
 		fmt.Printf("Executed request %s\n", requestID)
 		fmt.Printf("Exit code: %d\n", resp.ExitCode)
 		fmt.Printf("Duration: %dms\n", resp.DurationMs)
+		printSegmentExecutions(resp.Segments)
 		fmt.Printf("Log: %s\n", resp.LogPath)
 
 		return nil
 	},
 }
+
+// reportExecutionQueued tells the user that an approved request is waiting
+// for an execution slot and, if the daemon is running, broadcasts
+// request_execution_queued to watchers.
+func reportExecutionQueued(status *core.ExecutionQueueStatus) {
+	fmt.Fprintf(os.Stderr, "[slb] Waiting for an execution slot: %d of %d in use, queue position %d of %d\n",
+		status.Running, status.MaxConcurrent, status.Position, status.QueueLength)
+	if !daemon.NewClient().IsDaemonRunning() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client := daemon.NewIPCClient(daemon.DefaultSocketPath())
+	defer client.Close()
+	_ = client.Notify(ctx, core.ExecutionQueuedEvent, daemon.ExecutionQueuedEvent(status))
+}
+
+// printSegmentExecutions prints per-segment results of a partially approved request.
+func printSegmentExecutions(segments []db.SegmentExecution) {
+	for _, seg := range segments {
+		switch {
+		case seg.Skipped:
+			fmt.Printf("  [%d] skipped: %s\n", seg.Index, seg.Command)
+		case seg.ExitCode != nil:
+			fmt.Printf("  [%d] exit %d (%dms): %s\n", seg.Index, *seg.ExitCode, seg.DurationMs, seg.Command)
+		default:
+			fmt.Printf("  [%d] did not complete: %s\n", seg.Index, seg.Command)
+		}
+	}
+}
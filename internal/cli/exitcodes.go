@@ -0,0 +1,151 @@
+// Package cli implements process exit codes for agent control flow.
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Documented process exit codes. Codes 0-6 predate the run-specific codes and
+// keep their meaning; 7-10 distinguish the remaining outcomes of `slb run`
+// so agents can branch without parsing output.
+const (
+	ExitOK              = 0
+	ExitInternalError   = 1
+	ExitInvalidArgs     = 2
+	ExitNotFound        = 3
+	ExitPermission      = 4
+	ExitTimeout         = 5
+	ExitRateLimited     = 6
+	ExitRejected        = 7
+	ExitCancelled       = 8
+	ExitExecutionFailed = 9
+	ExitApprovalExpired = 10
+)
+
+// runOutcome is the terminal outcome of a `slb run` invocation.
+type runOutcome string
+
+const (
+	outcomeExecuted        runOutcome = "executed"
+	outcomeRejected        runOutcome = "rejected"
+	outcomeTimedOut        runOutcome = "timed_out"
+	outcomeCancelled       runOutcome = "cancelled"
+	outcomeRateLimited     runOutcome = "rate_limited"
+	outcomeExecutionFailed runOutcome = "execution_failed"
+	outcomeApprovalExpired runOutcome = "approval_expired"
+	outcomeInternalError   runOutcome = "internal_error"
+)
+
+// exitCodeFor maps a run outcome to its documented exit code. Unknown
+// outcomes are treated as internal errors.
+func exitCodeFor(outcome runOutcome) int {
+	switch outcome {
+	case outcomeExecuted:
+		return ExitOK
+	case outcomeRejected:
+		return ExitRejected
+	case outcomeTimedOut:
+		return ExitTimeout
+	case outcomeCancelled:
+		return ExitCancelled
+	case outcomeRateLimited:
+		return ExitRateLimited
+	case outcomeExecutionFailed:
+		return ExitExecutionFailed
+	case outcomeApprovalExpired:
+		return ExitApprovalExpired
+	default:
+		return ExitInternalError
+	}
+}
+
+// outcomeForStatus maps a request status that ends polling to the run outcome
+// it represents.
+func outcomeForStatus(status db.RequestStatus) runOutcome {
+	switch status {
+	case db.StatusExecuted:
+		return outcomeExecuted
+	case db.StatusRejected:
+		return outcomeRejected
+	case db.StatusTimeout, db.StatusTimedOut:
+		return outcomeTimedOut
+	case db.StatusCancelled:
+		return outcomeCancelled
+	case db.StatusExecutionFailed:
+		return outcomeExecutionFailed
+	case db.StatusApprovalExpired:
+		return outcomeApprovalExpired
+	default:
+		return outcomeInternalError
+	}
+}
+
+// outcomeForCreateError maps a request creation failure to a run outcome.
+func outcomeForCreateError(err error) runOutcome {
+	if errors.Is(err, core.ErrRateLimited) || errors.Is(err, core.ErrRejectionCooldown) {
+		return outcomeRateLimited
+	}
+	return outcomeInternalError
+}
+
+// withExecuteOutcome attaches ExitApprovalExpired to an execution error caused
+// by an expired approval, so the caller knows to re-request review rather
+// than start over. Other errors are returned unchanged.
+func withExecuteOutcome(err error) error {
+	if errors.Is(err, core.ErrApprovalExpired) {
+		return withOutcome(outcomeApprovalExpired, err)
+	}
+	return err
+}
+
+// exitCodeError carries a specific process exit code out of a command's RunE
+// so main can exit with it after deferred cleanup has run.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("exit code %d", e.code)
+}
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// withOutcome attaches the exit code for outcome to err. A nil err is
+// replaced by a bare exit code error so non-zero outcomes still propagate.
+func withOutcome(outcome runOutcome, err error) error {
+	code := exitCodeFor(outcome)
+	if code == ExitOK && err == nil {
+		return nil
+	}
+	return &exitCodeError{code: code, err: err}
+}
+
+// ExitCode returns the process exit code for an error returned by Execute.
+// A nil error exits 0; errors without an attached code exit 1.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var coded *exitCodeError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return ExitInternalError
+}
+
+// outcomeForExitCode maps the exit code of an executed command to a run
+// outcome. Any non-zero exit is an execution failure.
+func outcomeForExitCode(code int) runOutcome {
+	if code == 0 {
+		return outcomeExecuted
+	}
+	return outcomeExecutionFailed
+}
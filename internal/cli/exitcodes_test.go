@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func TestExitCodeFor_DocumentedCodes(t *testing.T) {
+	tests := []struct {
+		outcome runOutcome
+		want    int
+	}{
+		{outcomeExecuted, 0},
+		{outcomeInternalError, 1},
+		{outcomeTimedOut, 5},
+		{outcomeRateLimited, 6},
+		{outcomeRejected, 7},
+		{outcomeCancelled, 8},
+		{outcomeExecutionFailed, 9},
+		{outcomeApprovalExpired, 10},
+		{runOutcome("something_new"), 1},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.outcome), func(t *testing.T) {
+			if got := exitCodeFor(tc.outcome); got != tc.want {
+				t.Errorf("exitCodeFor(%q) = %d, want %d", tc.outcome, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExitCodeFor_TerminalOutcomesAreDistinct(t *testing.T) {
+	seen := map[int]runOutcome{}
+	for _, outcome := range []runOutcome{
+		outcomeExecuted, outcomeRejected, outcomeTimedOut, outcomeCancelled,
+		outcomeRateLimited, outcomeExecutionFailed, outcomeApprovalExpired,
+		outcomeInternalError,
+	} {
+		code := exitCodeFor(outcome)
+		if prev, ok := seen[code]; ok {
+			t.Errorf("outcomes %q and %q share exit code %d", prev, outcome, code)
+		}
+		seen[code] = outcome
+	}
+}
+
+func TestOutcomeForStatus(t *testing.T) {
+	tests := []struct {
+		status db.RequestStatus
+		want   runOutcome
+	}{
+		{db.StatusExecuted, outcomeExecuted},
+		{db.StatusRejected, outcomeRejected},
+		{db.StatusTimeout, outcomeTimedOut},
+		{db.StatusTimedOut, outcomeTimedOut},
+		{db.StatusCancelled, outcomeCancelled},
+		{db.StatusExecutionFailed, outcomeExecutionFailed},
+		{db.StatusApprovalExpired, outcomeApprovalExpired},
+		{db.StatusEscalated, outcomeInternalError},
+	}
+	for _, tc := range tests {
+		if got := outcomeForStatus(tc.status); got != tc.want {
+			t.Errorf("outcomeForStatus(%q) = %q, want %q", tc.status, got, tc.want)
+		}
+	}
+}
+
+func TestOutcomeForCreateError(t *testing.T) {
+	rlErr := &core.RateLimitError{SessionID: "s", Pending: 5, MaxPending: 5}
+	if got := outcomeForCreateError(rlErr); got != outcomeRateLimited {
+		t.Errorf("RateLimitError: got %q, want %q", got, outcomeRateLimited)
+	}
+	wrapped := fmt.Errorf("%w (action=queue): busy", core.ErrRateLimited)
+	if got := outcomeForCreateError(wrapped); got != outcomeRateLimited {
+		t.Errorf("wrapped ErrRateLimited: got %q, want %q", got, outcomeRateLimited)
+	}
+	cooldown := fmt.Errorf("%w: request r1 was rejected; retry in 5m0s", core.ErrRejectionCooldown)
+	if got := outcomeForCreateError(cooldown); got != outcomeRateLimited {
+		t.Errorf("rejection cool-down: got %q, want %q", got, outcomeRateLimited)
+	}
+	if got := outcomeForCreateError(core.ErrSessionNotFound); got != outcomeInternalError {
+		t.Errorf("session error: got %q, want %q", got, outcomeInternalError)
+	}
+}
+
+func TestOutcomeForExitCode(t *testing.T) {
+	if got := outcomeForExitCode(0); got != outcomeExecuted {
+		t.Errorf("exit 0: got %q", got)
+	}
+	if got := outcomeForExitCode(42); got != outcomeExecutionFailed {
+		t.Errorf("exit 42: got %q", got)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := ExitCode(nil); got != 0 {
+		t.Errorf("nil error: got %d, want 0", got)
+	}
+	if got := ExitCode(errors.New("boom")); got != 1 {
+		t.Errorf("plain error: got %d, want 1", got)
+	}
+
+	rejected := withOutcome(outcomeRejected, errors.New("request r1: rejected"))
+	if got := ExitCode(rejected); got != ExitRejected {
+		t.Errorf("rejected: got %d, want %d", got, ExitRejected)
+	}
+	if rejected.Error() != "request r1: rejected" {
+		t.Errorf("expected wrapped message, got %q", rejected.Error())
+	}
+
+	failed := withOutcome(outcomeExecutionFailed, nil)
+	if got := ExitCode(fmt.Errorf("outer: %w", failed)); got != ExitExecutionFailed {
+		t.Errorf("wrapped execution failure: got %d, want %d", got, ExitExecutionFailed)
+	}
+
+	if err := withOutcome(outcomeExecuted, nil); err != nil {
+		t.Errorf("expected nil error for successful outcome, got %v", err)
+	}
+}
+
+func TestWithExecuteOutcome(t *testing.T) {
+	expired := withExecuteOutcome(fmt.Errorf("%w: re-request review", core.ErrApprovalExpired))
+	if got := ExitCode(expired); got != ExitApprovalExpired {
+		t.Errorf("expired approval: got %d, want %d", got, ExitApprovalExpired)
+	}
+	if got := ExitCode(withExecuteOutcome(errors.New("boom"))); got != ExitInternalError {
+		t.Errorf("other error: got %d, want %d", got, ExitInternalError)
+	}
+	if err := withExecuteOutcome(nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
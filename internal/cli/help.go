@@ -108,6 +108,7 @@ func showQuickReference() {
 	plumbing := renderSection(useUnicode, "🔧 PLUMBING (advanced)", []string{
 		bullet("slb request \"...\" --wait --execute -s $SID --reason \"...\"", "submit without shorthand"),
 		bullet("slb cancel <request-id>", "cancel pending"),
+		bullet("slb rerequest <request-id>", "re-review an expired approval"),
 		bullet("slb rollback <request-id>", "apply rollback capture (if available)"),
 	})
 
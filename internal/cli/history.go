@@ -3,8 +3,12 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/output"
 	"github.com/spf13/cobra"
@@ -17,6 +21,12 @@ var (
 	flagHistoryTier   string
 	flagHistorySince  string
 	flagHistoryLimit  int
+
+	flagHistoryExportFormat string
+	flagHistoryExportOut    string
+	flagHistoryExportStatus string
+	flagHistoryExportTier   string
+	flagHistoryExportSince  time.Duration
 )
 
 func init() {
@@ -27,6 +37,13 @@ func init() {
 	historyCmd.Flags().StringVar(&flagHistorySince, "since", "", "only show requests after this date (RFC3339 or YYYY-MM-DD)")
 	historyCmd.Flags().IntVar(&flagHistoryLimit, "limit", 50, "max results to return")
 
+	historyExportCmd.Flags().StringVar(&flagHistoryExportFormat, "format", core.ExportFormatJSONL, "export format (csv, jsonl)")
+	historyExportCmd.Flags().StringVar(&flagHistoryExportOut, "out", "", "file to write (default stdout)")
+	historyExportCmd.Flags().StringVar(&flagHistoryExportStatus, "status", "", "only export requests with this status")
+	historyExportCmd.Flags().StringVar(&flagHistoryExportTier, "tier", "", "only export requests of this risk tier")
+	historyExportCmd.Flags().DurationVar(&flagHistoryExportSince, "since", 0, "only export requests created within this duration (e.g., 24h, 720h)")
+
+	historyCmd.AddCommand(historyExportCmd)
 	rootCmd.AddCommand(historyCmd)
 }
 
@@ -41,7 +58,9 @@ Examples:
   slb history --status executed        # Show only executed requests
   slb history --tier critical          # Show only critical tier requests
   slb history --agent "BrownStone"     # Show requests from specific agent
-  slb history --since 2025-12-01       # Show requests since date`,
+  slb history --since 2025-12-01       # Show requests since date
+  slb history -q label:env=prod        # Show requests labeled env=prod
+  slb history -q "label:team=web drop" # Combine label filters with search`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		dbConn, err := db.Open(GetDB())
 		if err != nil {
@@ -51,9 +70,15 @@ Examples:
 
 		var requests []*db.Request
 
+		// label:key=value terms are filtered in memory; the rest goes to FTS.
+		query, selector, err := splitHistoryQuery(flagHistoryQuery)
+		if err != nil {
+			return err
+		}
+
 		// If query is provided, use FTS search
-		if flagHistoryQuery != "" {
-			requests, err = dbConn.SearchRequests(flagHistoryQuery)
+		if query != "" {
+			requests, err = dbConn.SearchRequests(query)
 			if err != nil {
 				return fmt.Errorf("searching requests: %w", err)
 			}
@@ -67,6 +92,7 @@ Examples:
 
 		// Apply additional filters
 		requests = applyHistoryFilters(requests)
+		requests = filterRequestsByLabels(requests, selector)
 
 		// Limit results
 		if len(requests) > flagHistoryLimit {
@@ -75,14 +101,17 @@ Examples:
 
 		// Build response
 		type historyView struct {
-			RequestID      string `json:"request_id"`
-			Command        string `json:"command"`
-			RiskTier       string `json:"risk_tier"`
-			Status         string `json:"status"`
-			RequestorAgent string `json:"requestor_agent"`
-			ProjectPath    string `json:"project_path"`
-			CreatedAt      string `json:"created_at"`
-			ResolvedAt     string `json:"resolved_at,omitempty"`
+			RequestID      string            `json:"request_id"`
+			Command        string            `json:"command"`
+			Summary        string            `json:"summary,omitempty"`
+			RiskTier       string            `json:"risk_tier"`
+			TierReason     string            `json:"tier_reason,omitempty"`
+			Labels         map[string]string `json:"labels,omitempty"`
+			Status         string            `json:"status"`
+			RequestorAgent string            `json:"requestor_agent"`
+			ProjectPath    string            `json:"project_path"`
+			CreatedAt      string            `json:"created_at"`
+			ResolvedAt     string            `json:"resolved_at,omitempty"`
 		}
 
 		resp := make([]historyView, 0, len(requests))
@@ -90,7 +119,10 @@ Examples:
 			view := historyView{
 				RequestID:      r.ID,
 				Command:        r.Command.Raw,
+				Summary:        r.Command.Summary,
 				RiskTier:       string(r.RiskTier),
+				TierReason:     r.TierReason,
+				Labels:         r.Labels,
 				Status:         string(r.Status),
 				RequestorAgent: r.RequestorAgent,
 				ProjectPath:    r.ProjectPath,
@@ -111,8 +143,65 @@ Examples:
 	},
 }
 
-// listRequestsWithFilters retrieves requests with basic filtering.
-// For now this returns all requests - we could add more DB-level filtering.
+var historyExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the project's request history as CSV or JSONL",
+	Long: `Export every request of the project, oldest first, with its review counts,
+resolution time and command hash.
+
+CSV rows show the redacted command. JSONL lines carry the full request under
+its own JSON field names, plus "approvals" and "rejections", so they can be
+read back as requests. The history browser's export key (e) writes the same
+JSONL to a timestamped file in .slb.
+
+Examples:
+  slb history export --format csv --out history.csv
+  slb history export --status executed --since 720h > executed.jsonl`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := projectPath()
+		if err != nil {
+			return err
+		}
+
+		filter := db.RequestFilter{
+			ProjectPath: project,
+			Status:      db.RequestStatus(flagHistoryExportStatus),
+			Tier:        db.RiskTier(flagHistoryExportTier),
+		}
+		if flagHistoryExportSince > 0 {
+			filter.Since = time.Now().Add(-flagHistoryExportSince)
+		}
+
+		dbConn, err := db.Open(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		var w io.Writer = cmd.OutOrStdout()
+		if flagHistoryExportOut != "" {
+			f, err := os.Create(flagHistoryExportOut)
+			if err != nil {
+				return fmt.Errorf("creating %s: %w", flagHistoryExportOut, err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		n, err := core.ExportHistory(dbConn, w, flagHistoryExportFormat, filter)
+		if err != nil {
+			return fmt.Errorf("exporting history: %w", err)
+		}
+		if flagHistoryExportOut != "" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d request(s) to %s\n", n, flagHistoryExportOut)
+		}
+		return nil
+	},
+}
+
+// listRequestsWithFilters retrieves the project's requests, narrowed in
+// SQL by --status or --since; the remaining filters are applied in memory.
 func listRequestsWithFilters(dbConn *db.DB) ([]*db.Request, error) {
 	project, _ := projectPath()
 
@@ -122,41 +211,30 @@ func listRequestsWithFilters(dbConn *db.DB) ([]*db.Request, error) {
 		return dbConn.ListRequestsByStatus(status, project)
 	}
 
-	// Otherwise get all pending (and use in-memory filtering)
-	// Note: This is a simplification - in production we'd want a more flexible DB query
-	pending, err := dbConn.ListPendingRequests(project)
-	if err != nil {
-		return nil, err
-	}
+	return dbConn.ListRequestsBetween(project, historySince(), time.Time{})
+}
 
-	// Also get from FTS with wildcard to get all
-	// Note: This is a workaround - ideally we'd have a ListAllRequests function
-	all, err := dbConn.SearchRequests("*")
+// historySince parses --since as RFC3339 or a date, returning the zero time
+// when it is unset or invalid.
+func historySince() time.Time {
+	if flagHistorySince == "" {
+		return time.Time{}
+	}
+	sinceTime, err := time.Parse(time.RFC3339, flagHistorySince)
 	if err != nil {
-		// FTS might not work with wildcard, fall back to pending only
-		return pending, nil
+		sinceTime, err = time.Parse("2006-01-02", flagHistorySince)
+		if err != nil {
+			// Invalid date, ignore filter
+			return time.Time{}
+		}
 	}
-
-	return all, nil
+	return sinceTime
 }
 
 // applyHistoryFilters applies in-memory filters to requests.
 func applyHistoryFilters(requests []*db.Request) []*db.Request {
 	result := make([]*db.Request, 0, len(requests))
-
-	var sinceTime time.Time
-	if flagHistorySince != "" {
-		// Try parsing as RFC3339 first, then as date only
-		var err error
-		sinceTime, err = time.Parse(time.RFC3339, flagHistorySince)
-		if err != nil {
-			sinceTime, err = time.Parse("2006-01-02", flagHistorySince)
-			if err != nil {
-				// Invalid date, ignore filter
-				sinceTime = time.Time{}
-			}
-		}
-	}
+	sinceTime := historySince()
 
 	for _, r := range requests {
 		// Filter by status
@@ -184,3 +262,35 @@ func applyHistoryFilters(requests []*db.Request) []*db.Request {
 
 	return result
 }
+
+// splitHistoryQuery separates label:key=value terms from a history query,
+// returning the remaining full-text query and the label selector.
+func splitHistoryQuery(q string) (string, map[string]string, error) {
+	var text, pairs []string
+	for _, term := range strings.Fields(q) {
+		if pair, ok := strings.CutPrefix(term, "label:"); ok {
+			pairs = append(pairs, pair)
+			continue
+		}
+		text = append(text, term)
+	}
+	selector, err := db.ParseLabels(pairs)
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing label filter: %w", err)
+	}
+	return strings.Join(text, " "), selector, nil
+}
+
+// filterRequestsByLabels keeps requests whose labels match every selector entry.
+func filterRequestsByLabels(requests []*db.Request, selector map[string]string) []*db.Request {
+	if len(selector) == 0 {
+		return requests
+	}
+	result := make([]*db.Request, 0, len(requests))
+	for _, r := range requests {
+		if db.MatchLabels(r.Labels, selector) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -38,6 +39,17 @@ func newTestHistoryCmd(dbPath string) *cobra.Command {
 	histCmd.Flags().StringVar(&flagHistorySince, "since", "", "filter by date")
 	histCmd.Flags().IntVar(&flagHistoryLimit, "limit", 50, "max results")
 
+	exportCmd := &cobra.Command{
+		Use:  "export",
+		RunE: historyExportCmd.RunE,
+	}
+	exportCmd.Flags().StringVar(&flagHistoryExportFormat, "format", "jsonl", "export format")
+	exportCmd.Flags().StringVar(&flagHistoryExportOut, "out", "", "file to write")
+	exportCmd.Flags().StringVar(&flagHistoryExportStatus, "status", "", "filter by status")
+	exportCmd.Flags().StringVar(&flagHistoryExportTier, "tier", "", "filter by risk tier")
+	exportCmd.Flags().DurationVar(&flagHistoryExportSince, "since", 0, "filter by age")
+	histCmd.AddCommand(exportCmd)
+
 	root.AddCommand(histCmd)
 
 	return root
@@ -108,6 +120,42 @@ func TestHistoryCommand_ListsRequests(t *testing.T) {
 	}
 }
 
+func TestHistoryCommand_UsesStoredSummaryAndTierReason(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetHistoryFlags()
+
+	sess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("TestAgent"),
+	)
+	req := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -rf ./build", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierDangerous),
+	)
+	// Sentinels prove history reads the stored values instead of recomputing.
+	if _, err := h.DB.Exec(`UPDATE requests SET command_summary = ?, tier_reason = ? WHERE id = ?`,
+		"stored-summary", "stored-reason", req.ID); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	cmd := newTestHistoryCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "history", "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(result))
+	}
+	if result[0]["summary"] != "stored-summary" || result[0]["tier_reason"] != "stored-reason" {
+		t.Errorf("summary/tier_reason = %v / %v", result[0]["summary"], result[0]["tier_reason"])
+	}
+}
+
 func TestHistoryCommand_EmptyList(t *testing.T) {
 	h := testutil.NewHarness(t)
 	resetHistoryFlags()
@@ -522,6 +570,80 @@ func TestHistoryCommand_FullTextSearch(t *testing.T) {
 	// Note: FTS might not work exactly as expected in tests, just verify no error
 }
 
+func TestHistoryCommand_FilterByLabel(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetHistoryFlags()
+
+	sess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("TestAgent"),
+	)
+	prod := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("kubectl delete ns web", h.ProjectDir, true),
+		testutil.WithLabels(map[string]string{"env": "prod", "team": "web"}),
+	)
+	testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("kubectl delete ns api", h.ProjectDir, true),
+		testutil.WithLabels(map[string]string{"env": "staging"}),
+	)
+	testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -rf ./build", h.ProjectDir, true),
+	)
+
+	cmd := newTestHistoryCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "history",
+		"-C", h.ProjectDir,
+		"-q", "label:env=prod",
+		"-j",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(result) != 1 || result[0]["request_id"] != prod.ID {
+		t.Fatalf("expected only %s, got %v", prod.ID, result)
+	}
+	labels, _ := result[0]["labels"].(map[string]any)
+	if labels["team"] != "web" {
+		t.Errorf("expected labels in output, got %v", result[0]["labels"])
+	}
+
+	resetHistoryFlags()
+	cmd = newTestHistoryCmd(h.DBPath)
+	if _, err := executeCommandCapture(t, cmd, "history", "-C", h.ProjectDir, "-q", "label:Env=prod"); err == nil {
+		t.Error("expected invalid label filter to fail")
+	}
+}
+
+func TestSplitHistoryQuery(t *testing.T) {
+	query, selector, err := splitHistoryQuery("label:env=prod drop table label:team=web")
+	if err != nil {
+		t.Fatalf("splitHistoryQuery: %v", err)
+	}
+	if query != "drop table" {
+		t.Errorf("query = %q, want %q", query, "drop table")
+	}
+	if selector["env"] != "prod" || selector["team"] != "web" || len(selector) != 2 {
+		t.Errorf("selector = %v", selector)
+	}
+
+	requests := []*db.Request{
+		{ID: "1", Labels: map[string]string{"env": "prod", "team": "web"}},
+		{ID: "2", Labels: map[string]string{"env": "prod"}},
+		{ID: "3"},
+	}
+	if got := filterRequestsByLabels(requests, selector); len(got) != 1 || got[0].ID != "1" {
+		t.Errorf("filterRequestsByLabels = %v", got)
+	}
+	if got := filterRequestsByLabels(requests, nil); len(got) != 3 {
+		t.Errorf("empty selector should keep all requests, got %d", len(got))
+	}
+}
+
 func TestHistoryCommand_TextOutput(t *testing.T) {
 	h := testutil.NewHarness(t)
 	resetHistoryFlags()
@@ -545,3 +667,46 @@ func TestHistoryCommand_TextOutput(t *testing.T) {
 	// Text output should contain request information
 	_ = stdout // Just verify no error on text output
 }
+
+func TestHistoryExportCommand(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetHistoryFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand("echo a,b", h.ProjectDir, true), testutil.WithStatus(db.StatusExecuted))
+	testutil.MakeRequest(t, h.DB, sess, testutil.WithStatus(db.StatusRejected))
+
+	exportFlags := func() {
+		flagHistoryExportFormat = "jsonl"
+		flagHistoryExportOut = ""
+		flagHistoryExportStatus = ""
+		flagHistoryExportTier = ""
+		flagHistoryExportSince = 0
+	}
+
+	exportFlags()
+	stdout, err := executeCommandCapture(t, newTestHistoryCmd(h.DBPath), "history", "export", "--status", "executed", "--since", "1h", "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("history export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	var record db.Request
+	if len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &record) != nil || record.Command.Raw != "echo a,b" {
+		t.Errorf("expected the executed request as one JSONL line, got %q", stdout)
+	}
+
+	exportFlags()
+	out := h.ProjectDir + "/history.csv"
+	if _, err := executeCommandCapture(t, newTestHistoryCmd(h.DBPath), "history", "export", "--format", "csv", "--out", out, "-C", h.ProjectDir); err != nil {
+		t.Fatalf("history export csv: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || !strings.Contains(string(data), `"echo a,b"`) || strings.Count(string(data), "\n") != 3 {
+		t.Errorf("expected a quoted CSV with two rows, got %q, %v", data, err)
+	}
+
+	exportFlags()
+	if _, err := executeCommandCapture(t, newTestHistoryCmd(h.DBPath), "history", "export", "--format", "xml", "-C", h.ProjectDir); err == nil {
+		t.Error("expected an unknown format to fail")
+	}
+}
@@ -59,32 +59,17 @@ func runInit(cmd *cobra.Command, args []string) error {
 		// Force mode: continue but preserve existing data
 	}
 
-	// Create directory structure
-	dirs := []string{
-		slbDir,
-		filepath.Join(slbDir, "logs"),
-		filepath.Join(slbDir, "pending"),
-		filepath.Join(slbDir, "sessions"),
-		filepath.Join(slbDir, "rollback"),
-		filepath.Join(slbDir, "processed"),
-	}
-
-	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0700); err != nil {
-			return fmt.Errorf("creating directory %s: %w", dir, err)
-		}
+	// Create directory structure and database
+	dbPath, err := initProjectDir(slbDir)
+	if err != nil {
+		return err
 	}
 
-	// Initialize database
-	dbPath := filepath.Join(slbDir, "state.db")
-	database, err := db.OpenAndMigrate(dbPath)
+	// Create default config.toml unless the project already has a config
+	_, configPath, err := config.ConfigPaths(projectDir, "")
 	if err != nil {
-		return fmt.Errorf("initializing database: %w", err)
+		return err
 	}
-	database.Close()
-
-	// Create default config.toml
-	configPath := filepath.Join(slbDir, "config.toml")
 	if err := writeDefaultConfig(configPath, flagInitForce); err != nil {
 		return fmt.Errorf("creating config: %w", err)
 	}
@@ -131,12 +116,44 @@ func runInit(cmd *cobra.Command, args []string) error {
 	}
 }
 
+// initProjectDir creates the .slb directory structure and migrated state
+// database, preserving any existing data. It returns the database path.
+func initProjectDir(slbDir string) (string, error) {
+	dirs := []string{
+		slbDir,
+		filepath.Join(slbDir, "logs"),
+		filepath.Join(slbDir, "pending"),
+		filepath.Join(slbDir, "sessions"),
+		filepath.Join(slbDir, "rollback"),
+		filepath.Join(slbDir, "processed"),
+	}
+
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", fmt.Errorf("creating directory %s: %w", dir, err)
+		}
+	}
+
+	dbPath := filepath.Join(slbDir, "state.db")
+	database, err := db.OpenAndMigrate(dbPath)
+	if err != nil {
+		return "", fmt.Errorf("initializing database: %w", err)
+	}
+	database.Close()
+	return dbPath, nil
+}
+
 // writeDefaultConfig writes a default config.toml with comments.
 func writeDefaultConfig(path string, force bool) error {
 	// Check if config already exists
-	if _, err := os.Stat(path); err == nil && !force {
-		// Config exists, don't overwrite
-		return nil
+	if _, err := os.Stat(path); err == nil {
+		if !force {
+			// Config exists, don't overwrite
+			return nil
+		}
+		if filepath.Ext(path) != ".toml" {
+			return fmt.Errorf("--force only rewrites config.toml; remove %s first", path)
+		}
 	}
 
 	cfg := config.DefaultConfig()
@@ -151,7 +168,8 @@ func writeDefaultConfig(path string, force bool) error {
 	header := `# SLB Configuration
 # See https://github.com/Dicklesworthstone/slb for documentation.
 #
-# Precedence: defaults < user (~/.slb/config.toml) < project (.slb/config.toml) < env (SLB_*) < flags
+# Precedence: defaults < user (~/.slb/config.toml) < workspace root (.slb/config.toml)
+#             < project (.slb/config.toml) < env (SLB_*) < flags
 
 `
 	if _, err := f.WriteString(header); err != nil {
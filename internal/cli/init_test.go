@@ -131,6 +131,37 @@ func TestInitCommand_ForceReinitialize(t *testing.T) {
 	}
 }
 
+func TestInitCommand_KeepsExistingYAMLConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+
+	slbDir := filepath.Join(tmpDir, ".slb")
+	if err := os.MkdirAll(slbDir, 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	yamlPath := filepath.Join(slbDir, "config.yaml")
+	if err := os.WriteFile(yamlPath, []byte("general:\n  min_approvals: 3\n"), 0600); err != nil {
+		t.Fatalf("writing yaml config failed: %v", err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+
+	flagInitForce = true
+	flagOutput = "text"
+	flagJSON = false
+	defer func() { flagInitForce = false }()
+
+	// --force must not rewrite a YAML config as TOML.
+	if err := runInit(nil, nil); err == nil {
+		t.Fatal("expected --force to refuse rewriting config.yaml")
+	}
+	if _, err := os.Stat(filepath.Join(slbDir, "config.toml")); !os.IsNotExist(err) {
+		t.Errorf("config.toml created beside config.yaml: %v", err)
+	}
+}
+
 func TestInitCommand_JSONOutput(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
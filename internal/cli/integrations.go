@@ -1,12 +1,19 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/integrations"
+	"github.com/Dicklesworthstone/slb/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -137,6 +144,90 @@ and prompt for slb approval when the command requires review.`,
 	},
 }
 
+var replayChangeRecordsCmd = &cobra.Command{
+	Use:   "replay-change-records",
+	Short: "Backfill change records for requests executed while delivery was down",
+	Long: `Queue change records for requests executed since the given time that have
+none yet, then deliver every queued record that is due.
+
+Change records are sent to integrations.change_record_url for executed
+requests in integrations.change_record_tiers. They are normally queued when a
+request executes and delivered by the daemon, which retries until the
+receiver accepts them. Use this after the integration was unconfigured or the
+receiver was unreachable for a long time. Requests that already have a record
+are skipped, so replaying the same window twice sends nothing new.
+
+Examples:
+  slb integrations replay-change-records --since 2025-12-01
+  slb integrations replay-change-records --since 2025-12-01T09:00:00Z -j`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		if sinceFlag == "" {
+			return fmt.Errorf("--since is required")
+		}
+		since, err := time.Parse(time.RFC3339, sinceFlag)
+		if err != nil {
+			if since, err = time.Parse("2006-01-02", sinceFlag); err != nil {
+				return fmt.Errorf("invalid --since %q (use RFC3339 or YYYY-MM-DD)", sinceFlag)
+			}
+		}
+
+		project, err := projectPath()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(config.LoadOptions{ProjectDir: project, ConfigPath: flagConfig})
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		if cfg.Integrations.ChangeRecordURL == "" {
+			return fmt.Errorf("integrations.change_record_url is not set")
+		}
+
+		dbConn, err := db.OpenAndMigrate(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		now := time.Now().UTC()
+		queued, err := core.EnqueueMissingChangeRecords(dbConn, project, since, cfg.Integrations.ChangeRecordURL, cfg.Integrations.ChangeRecordTiers, now)
+		if err != nil {
+			return err
+		}
+		// Each pass delivers a batch; records that fail are rescheduled past
+		// now, so passes stop once nothing due is left.
+		var delivery daemon.DeliveryResult
+		poster := daemon.NewDefaultWebhookNotifier()
+		for {
+			pass, err := daemon.DeliverChangeRecords(context.Background(), dbConn, poster, now)
+			if err != nil {
+				return err
+			}
+			if pass.Delivered+pass.Failed == 0 {
+				break
+			}
+			delivery.Delivered += pass.Delivered
+			delivery.Failed += pass.Failed
+		}
+
+		if GetOutput() == "json" {
+			return output.New(output.FormatJSON).Write(map[string]any{
+				"since":     since.UTC().Format(time.RFC3339),
+				"queued":    queued,
+				"delivered": delivery.Delivered,
+				"failed":    delivery.Failed,
+			})
+		}
+		fmt.Printf("Queued %d change record(s); delivered %d, %d failed\n", queued, delivery.Delivered, delivery.Failed)
+		if delivery.Failed > 0 {
+			fmt.Fprintln(os.Stderr, "[slb] Failed records stay queued; the daemon retries them.")
+		}
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(integrationsCmd)
 
@@ -150,4 +241,7 @@ func init() {
 	claudeHooksCmd.Flags().Bool("install", false, "Write to .claude/hooks.json in the project directory")
 	claudeHooksCmd.Flags().Bool("preview", false, "Print what would be written (default)")
 	claudeHooksCmd.Flags().Bool("merge", true, "Merge with existing hooks.json (default true)")
+
+	integrationsCmd.AddCommand(replayChangeRecordsCmd)
+	replayChangeRecordsCmd.Flags().String("since", "", "replay requests executed at or after this time (RFC3339 or YYYY-MM-DD, required)")
 }
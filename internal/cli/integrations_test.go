@@ -1,12 +1,20 @@
 package cli
 
 import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/integrations"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
 )
 
 func TestClaudeHooksCmd_Flags(t *testing.T) {
@@ -246,3 +254,102 @@ func TestClaudeHooksPreview(t *testing.T) {
 		t.Error("expected on_block in preview")
 	}
 }
+
+// newTestIntegrationsCmd creates a fresh integrations command for testing.
+func newTestIntegrationsCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagConfig, "config", "c", "", "config file")
+
+	root.AddCommand(integrationsCmd)
+
+	return root
+}
+
+func resetIntegrationsFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagConfig = ""
+	_ = replayChangeRecordsCmd.Flags().Set("since", "")
+}
+
+func TestReplayChangeRecordsCmd(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetIntegrationsFlags()
+
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+	}))
+	defer server.Close()
+	cfg := "[integrations]\nagent_mail_enabled = false\nchange_record_url = \"" + server.URL + "\"\n"
+	if err := os.WriteFile(filepath.Join(h.ProjectDir, ".slb", "config.toml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	executed := testutil.MakeRequest(t, h.DB, sess, testutil.WithRisk(db.RiskTierCritical), testutil.WithStatus(db.StatusExecuted))
+	caution := testutil.MakeRequest(t, h.DB, sess, testutil.WithRisk(db.RiskTierCaution), testutil.WithStatus(db.StatusExecuted))
+	at := time.Now().UTC()
+	exitCode := 0
+	for _, r := range []*db.Request{executed, caution} {
+		if err := h.DB.UpdateRequestExecution(r.ID, &db.Execution{ExecutedAt: &at, ExitCode: &exitCode}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	since := at.Add(-time.Hour).Format(time.RFC3339)
+	stdout, err := executeCommandCapture(t, newTestIntegrationsCmd(h.DBPath), "integrations", "replay-change-records",
+		"--since", since, "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("replay-change-records: %v", err)
+	}
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("parsing output %q: %v", stdout, err)
+	}
+	if result["queued"] != float64(1) || result["delivered"] != float64(1) {
+		t.Fatalf("expected one record queued and delivered, got %v", result)
+	}
+	if len(bodies) != 1 || !strings.Contains(bodies[0], executed.ID) {
+		t.Fatalf("expected the critical request's record to be posted, got %q", bodies)
+	}
+
+	resetIntegrationsFlags()
+	stdout, err = executeCommandCapture(t, newTestIntegrationsCmd(h.DBPath), "integrations", "replay-change-records",
+		"--since", since, "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("second replay: %v", err)
+	}
+	if !strings.Contains(stdout, "Queued 0 change record(s)") || len(bodies) != 1 {
+		t.Errorf("expected a second replay to send nothing, got %q (%d posts)", stdout, len(bodies))
+	}
+}
+
+func TestReplayChangeRecordsCmd_RequiresSinceAndURL(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetIntegrationsFlags()
+
+	_, err := executeCommandCapture(t, newTestIntegrationsCmd(h.DBPath), "integrations", "replay-change-records", "-C", h.ProjectDir)
+	if err == nil || !strings.Contains(err.Error(), "--since is required") {
+		t.Errorf("expected a missing --since error, got %v", err)
+	}
+
+	resetIntegrationsFlags()
+	_, err = executeCommandCapture(t, newTestIntegrationsCmd(h.DBPath), "integrations", "replay-change-records",
+		"--since", "2025-12-01", "-C", h.ProjectDir)
+	if err == nil || !strings.Contains(err.Error(), "change_record_url is not set") {
+		t.Errorf("expected an unconfigured integration error, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var flagNotifyTestEvent string
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage request lifecycle notifications",
+}
+
+// notifyTestResult is the outcome of one test delivery.
+type notifyTestResult struct {
+	URL   string `json:"url"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a sample lifecycle event to every configured webhook",
+	Long: `Send a sample request lifecycle event to each URL in
+notifications.webhook_urls, rendered with notifications.webhook_template, and
+report whether each receiver accepted it.
+
+The daemon sends the real events (request_pending, request_approved,
+request_rejected and request_executed) in the background, retrying failed
+deliveries. This command posts once, right away, so a misconfigured URL or
+template shows up before a request depends on it. It fails if any URL does.
+
+Examples:
+  slb notify test
+  slb notify test --event request_executed -j`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		event := daemon.WebhookEvent(flagNotifyTestEvent)
+		switch event {
+		case daemon.WebhookEventRequestPending, daemon.WebhookEventRequestApproved,
+			daemon.WebhookEventRequestRejected, daemon.WebhookEventRequestExecuted:
+		default:
+			return fmt.Errorf("invalid --event %q (use request_pending|request_approved|request_rejected|request_executed)", flagNotifyTestEvent)
+		}
+
+		project, err := projectPath()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(config.LoadOptions{ProjectDir: project, ConfigPath: flagConfig})
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		if len(cfg.Notifications.WebhookURLs) == 0 {
+			return fmt.Errorf("notifications.webhook_urls is not set")
+		}
+
+		now := time.Now().UTC()
+		body, err := daemon.EncodeWebhookPayload(daemon.LifecyclePayload(event, sampleNotifyRequest(project, event), now), cfg.Notifications.WebhookTemplate)
+		if err != nil {
+			return err
+		}
+
+		poster := daemon.NewDefaultWebhookNotifier()
+		results := make([]notifyTestResult, 0, len(cfg.Notifications.WebhookURLs))
+		failed := 0
+		for _, url := range cfg.Notifications.WebhookURLs {
+			ctx, cancel := context.WithTimeout(context.Background(), daemon.WebhookTimeout)
+			postErr := poster.Post(ctx, url, body)
+			cancel()
+			result := notifyTestResult{URL: url, OK: postErr == nil}
+			if postErr != nil {
+				result.Error = postErr.Error()
+				failed++
+			}
+			results = append(results, result)
+		}
+
+		if GetOutput() == "json" {
+			if err := output.New(output.FormatJSON).Write(map[string]any{
+				"event":    string(event),
+				"template": cfg.Notifications.WebhookTemplate,
+				"results":  results,
+			}); err != nil {
+				return err
+			}
+		} else {
+			for _, r := range results {
+				if r.OK {
+					fmt.Printf("ok      %s\n", r.URL)
+				} else {
+					fmt.Printf("FAILED  %s: %s\n", r.URL, r.Error)
+				}
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d webhook(s) failed", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// sampleNotifyRequest is the made-up request that slb notify test reports.
+func sampleNotifyRequest(project string, event daemon.WebhookEvent) *db.Request {
+	req := &db.Request{
+		ID:             "test-notification",
+		ProjectPath:    project,
+		Command:        db.CommandSpec{Raw: "echo slb webhook test"},
+		RiskTier:       db.RiskTierDangerous,
+		RequestorAgent: "slb",
+		Status:         db.StatusPending,
+	}
+	switch event {
+	case daemon.WebhookEventRequestApproved:
+		req.Status = db.StatusApproved
+	case daemon.WebhookEventRequestRejected:
+		req.Status = db.StatusRejected
+	case daemon.WebhookEventRequestExecuted:
+		exitCode := 0
+		req.Status = db.StatusExecuted
+		req.Execution = &db.Execution{ExitCode: &exitCode}
+	}
+	return req
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+
+	notifyCmd.AddCommand(notifyTestCmd)
+	notifyTestCmd.Flags().StringVar(&flagNotifyTestEvent, "event", string(daemon.WebhookEventRequestPending), "lifecycle event to send")
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestNotifyCmd creates a fresh notify command for testing.
+func newTestNotifyCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagConfig, "config", "c", "", "config file")
+
+	root.AddCommand(notifyCmd)
+
+	return root
+}
+
+func resetNotifyFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagConfig = ""
+	flagNotifyTestEvent = "request_pending"
+}
+
+func TestNotifyTestCmd(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetNotifyFlags()
+
+	var body []byte
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	cfg := "[notifications]\nwebhook_template = \"slack\"\nwebhook_urls = [\"" + ok.URL + "\"]\n"
+	cfgPath := filepath.Join(h.ProjectDir, ".slb", "config.toml")
+	if err := os.WriteFile(cfgPath, []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, err := executeCommandCapture(t, newTestNotifyCmd(h.DBPath), "notify", "test", "--event", "request_executed", "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("notify test: %v", err)
+	}
+	var result struct {
+		Results []notifyTestResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("parsing output %q: %v", stdout, err)
+	}
+	if len(result.Results) != 1 || !result.Results[0].OK {
+		t.Fatalf("expected one successful delivery, got %+v", result.Results)
+	}
+	if !strings.Contains(string(body), `"text":"SLB request test-notification executed (DANGEROUS), exit code 0"`) {
+		t.Errorf("expected a slack message, got %s", body)
+	}
+
+	resetNotifyFlags()
+	cfg = "[notifications]\nwebhook_urls = [\"" + ok.URL + "\", \"" + failing.URL + "\"]\n"
+	if err := os.WriteFile(cfgPath, []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, err = executeCommandCapture(t, newTestNotifyCmd(h.DBPath), "notify", "test", "-C", h.ProjectDir)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 webhook(s) failed") {
+		t.Fatalf("expected one failure, got %v", err)
+	}
+	if !strings.Contains(stdout, "FAILED  "+failing.URL+": webhook returned status 502") {
+		t.Errorf("expected the failing URL to be reported, got %q", stdout)
+	}
+	if !strings.Contains(string(body), `"event":"request_pending"`) {
+		t.Errorf("expected a generic pending payload, got %s", body)
+	}
+}
+
+func TestNotifyTestCmd_Errors(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetNotifyFlags()
+
+	_, err := executeCommandCapture(t, newTestNotifyCmd(h.DBPath), "notify", "test", "-C", h.ProjectDir)
+	if err == nil || !strings.Contains(err.Error(), "webhook_urls is not set") {
+		t.Errorf("expected an unconfigured error, got %v", err)
+	}
+
+	resetNotifyFlags()
+	_, err = executeCommandCapture(t, newTestNotifyCmd(h.DBPath), "notify", "test", "--event", "request_exploded", "-C", h.ProjectDir)
+	if err == nil || !strings.Contains(err.Error(), "invalid --event") {
+		t.Errorf("expected an invalid event error, got %v", err)
+	}
+}
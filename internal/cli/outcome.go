@@ -177,7 +177,8 @@ Shows:
 - Total outcome count
 - Problematic percentage
 - Average human rating
-- Time-to-approval statistics`,
+- Time-to-approval statistics
+- Evidence viewed by reviewers`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		dbConn, err := db.Open(GetDB())
 		if err != nil {
@@ -197,6 +198,12 @@ Shows:
 			return fmt.Errorf("getting approval stats: %w", err)
 		}
 
+		// Get evidence interaction stats
+		evidenceStats, err := dbConn.GetEvidenceViewStats()
+		if err != nil {
+			return fmt.Errorf("getting evidence stats: %w", err)
+		}
+
 		out := output.New(output.Format(GetOutput()))
 		return out.Write(map[string]any{
 			"outcomes": map[string]any{
@@ -213,6 +220,12 @@ Shows:
 				"min_minutes":    approvalStats.MinMinutes,
 				"max_minutes":    approvalStats.MaxMinutes,
 			},
+			"evidence_viewed": map[string]any{
+				"review_count":         evidenceStats.ReviewCount,
+				"reviews_with_views":   evidenceStats.ReviewsWithViews,
+				"views_by_section":     evidenceStats.ViewsBySection,
+				"avg_view_duration_ms": evidenceStats.AvgViewDurationMs,
+			},
 		})
 	},
 }
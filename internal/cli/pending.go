@@ -3,9 +3,11 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/output"
 	"github.com/spf13/cobra"
@@ -14,23 +16,37 @@ import (
 var (
 	flagPendingAllProjects bool
 	flagPendingReviewPool  bool
+	flagPendingWorkspace   bool
+	flagPendingStatus      string
 )
 
 func init() {
 	pendingCmd.Flags().BoolVar(&flagPendingAllProjects, "all-projects", false, "list pending requests across all projects")
 	pendingCmd.Flags().BoolVar(&flagPendingReviewPool, "review-pool", false, "only show requests you can review (not your own)")
+	pendingCmd.Flags().BoolVar(&flagPendingWorkspace, "workspace", false, "list pending requests across all workspace members")
+	pendingCmd.Flags().StringVar(&flagPendingStatus, "status", string(db.StatusPending), "status to list: pending, queued (rate-limit queue, in admission order) or approved (awaiting execution)")
 
 	rootCmd.AddCommand(pendingCmd)
 }
 
 var pendingCmd = &cobra.Command{
-	Use:   "pending",
-	Short: "List pending requests awaiting approval",
+	Use:     "pending",
+	Aliases: []string{"list"},
+	Short:   "List pending requests awaiting approval",
 	Long: `List all pending command approval requests.
 
 By default, shows pending requests for the current project.
 Use --all-projects to see pending requests across all projects.
 Use --review-pool to filter to requests you can review (excludes your own).
+Use --workspace to list requests across every member of the enclosing
+workspace; each entry names its member project.
+Use --status queued to list requests held by the rate limiter (action
+"queue") in the order they will be admitted, with each one's position in its
+session's queue and estimated admission time.
+Use --status approved to list approved requests awaiting execution; those
+waiting for one of the project's execution slots (see
+general.max_concurrent_executions) come first, in the order they will run,
+with their position in the execution queue.
 
 When [general.cross_project_reviews] is true and review_pool is configured,
 --review-pool will pull requests from those projects in addition to the
@@ -49,28 +65,84 @@ current project.`,
 			return fmt.Errorf("loading config: %w", err)
 		}
 
-		dbConn, err := db.Open(GetDB())
-		if err != nil {
-			return fmt.Errorf("opening database: %w", err)
+		var requests []*db.Request
+		// Workspace member name per request ID, set with --workspace.
+		members := make(map[string]string)
+		// Queue position per request ID, set with --status queued.
+		queue := make(map[string]*core.QueueStatus)
+		// Execution queue position per request ID, set with --status approved.
+		execQueue := make(map[string]*core.ExecutionQueueStatus)
+
+		switch db.RequestStatus(flagPendingStatus) {
+		case db.StatusPending:
+		case db.StatusQueued:
+			if flagPendingWorkspace {
+				return fmt.Errorf("--status queued cannot be combined with --workspace")
+			}
+		case db.StatusApproved:
+			if flagPendingWorkspace || flagPendingAllProjects {
+				return fmt.Errorf("--status approved cannot be combined with --workspace or --all-projects")
+			}
+		default:
+			return fmt.Errorf("invalid --status %q (must be pending, queued or approved)", flagPendingStatus)
 		}
-		defer dbConn.Close()
 
-		var requests []*db.Request
+		if flagPendingStatus == string(db.StatusApproved) {
+			dbConn, err := db.Open(GetDB())
+			if err != nil {
+				return fmt.Errorf("opening database: %w", err)
+			}
+			defer dbConn.Close()
 
-		if flagPendingAllProjects {
-			requests, err = dbConn.ListPendingRequestsAllProjects()
+			requests, err = listApprovedForProject(dbConn, project, cfg, execQueue)
+			if err != nil {
+				return fmt.Errorf("listing approved requests: %w", err)
+			}
+		} else if flagPendingStatus == string(db.StatusQueued) {
+			dbConn, err := db.Open(GetDB())
+			if err != nil {
+				return fmt.Errorf("opening database: %w", err)
+			}
+			defer dbConn.Close()
+
+			requests, err = listQueuedForProject(dbConn, project, cfg, queue)
+			if err != nil {
+				return fmt.Errorf("listing queued requests: %w", err)
+			}
+		} else if flagPendingWorkspace {
+			ws, err := currentWorkspace()
+			if err != nil {
+				return err
+			}
+			for _, p := range workspaceProjects(ws) {
+				memberDB, err := openWorkspaceDB(p)
+				if err != nil {
+					return fmt.Errorf("opening database for %s: %w", p.Name, err)
+				}
+				reqs, err := memberDB.ListPendingRequestsAllProjects()
+				memberDB.Close()
+				if err != nil {
+					return fmt.Errorf("listing pending requests for %s: %w", p.Name, err)
+				}
+				for _, r := range reqs {
+					members[r.ID] = p.Name
+				}
+				requests = append(requests, reqs...)
+			}
+			sort.SliceStable(requests, func(i, j int) bool {
+				return requests[i].CreatedAt.After(requests[j].CreatedAt)
+			})
 		} else {
-			// Review pool: pull configured project paths if cross-project reviews enabled.
-			if flagPendingReviewPool && cfg.General.CrossProjectReviews && len(cfg.General.ReviewPool) > 0 {
-				paths := dedupeStrings(append([]string{project}, cfg.General.ReviewPool...))
-				requests, err = dbConn.ListPendingRequestsByProjects(paths)
-			} else {
-				requests, err = dbConn.ListPendingRequests(project)
+			dbConn, err := db.Open(GetDB())
+			if err != nil {
+				return fmt.Errorf("opening database: %w", err)
 			}
-		}
+			defer dbConn.Close()
 
-		if err != nil {
-			return fmt.Errorf("listing pending requests: %w", err)
+			requests, err = listPendingForProject(dbConn, project, cfg)
+			if err != nil {
+				return fmt.Errorf("listing pending requests: %w", err)
+			}
 		}
 
 		// Filter to review pool if requested (exclude own requests)
@@ -94,9 +166,15 @@ current project.`,
 			RequestorAgent  string `json:"requestor_agent"`
 			RequestorModel  string `json:"requestor_model"`
 			ProjectPath     string `json:"project_path"`
+			Project         string `json:"project,omitempty"`
 			Reason          string `json:"reason,omitempty"`
 			CreatedAt       string `json:"created_at"`
 			ExpiresAt       string `json:"expires_at,omitempty"`
+			// Queue is set for queued requests (--status queued).
+			Queue *core.QueueStatus `json:"queue,omitempty"`
+			// ExecutionQueue is set for approved requests waiting for an
+			// execution slot (--status approved).
+			ExecutionQueue *core.ExecutionQueueStatus `json:"execution_queue,omitempty"`
 		}
 
 		resp := make([]pendingView, 0, len(requests))
@@ -109,8 +187,11 @@ current project.`,
 				RequestorAgent: r.RequestorAgent,
 				RequestorModel: r.RequestorModel,
 				ProjectPath:    r.ProjectPath,
+				Project:        members[r.ID],
 				Reason:         r.Justification.Reason,
 				CreatedAt:      r.CreatedAt.Format(time.RFC3339),
+				Queue:          queue[r.ID],
+				ExecutionQueue: execQueue[r.ID],
 			}
 			if r.Command.DisplayRedacted != "" {
 				view.CommandRedacted = r.Command.DisplayRedacted
@@ -126,6 +207,90 @@ current project.`,
 	},
 }
 
+// listPendingForProject lists pending requests for project, honoring
+// --all-projects and the cross-project review pool.
+func listPendingForProject(dbConn *db.DB, project string, cfg config.Config) ([]*db.Request, error) {
+	if flagPendingAllProjects {
+		return dbConn.ListPendingRequestsAllProjects()
+	}
+	// Review pool: pull configured project paths if cross-project reviews enabled.
+	if flagPendingReviewPool && cfg.General.CrossProjectReviews && len(cfg.General.ReviewPool) > 0 {
+		paths := dedupeStrings(append([]string{project}, cfg.General.ReviewPool...))
+		return dbConn.ListPendingRequestsByProjects(paths)
+	}
+	return dbConn.ListPendingRequests(project)
+}
+
+// listQueuedForProject admits whatever the rate limiter now allows, then
+// lists the requests still queued in admission order, filling in each one's
+// queue status.
+func listQueuedForProject(dbConn *db.DB, project string, cfg config.Config, queue map[string]*core.QueueStatus) ([]*db.Request, error) {
+	scope := project
+	if flagPendingAllProjects {
+		scope = ""
+	}
+	rl := core.NewRateLimiter(dbConn, toRateLimitConfig(cfg))
+	creator := core.NewRequestCreator(dbConn, rl, nil, toRequestCreatorConfig(cfg))
+
+	requests, err := dbConn.ListQueuedRequests(scope)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make(map[string]bool)
+	for _, r := range requests {
+		if !sessions[r.RequestorSessionID] {
+			sessions[r.RequestorSessionID] = true
+			if _, err := creator.AdmitQueued(r.RequestorSessionID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	requests, err = dbConn.ListQueuedRequests(scope)
+	if err != nil {
+		return nil, err
+	}
+	for sessionID := range sessions {
+		statuses, err := rl.SessionQueue(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range statuses {
+			queue[st.RequestID] = st
+		}
+	}
+	return requests, nil
+}
+
+// listApprovedForProject releases execution slots left by executors that
+// died, then lists the project's approved requests: those waiting for an
+// execution slot first, in the order they will run, with each one's
+// execution queue status filled in.
+func listApprovedForProject(dbConn *db.DB, project string, cfg config.Config, execQueue map[string]*core.ExecutionQueueStatus) ([]*db.Request, error) {
+	if _, err := core.RecoverStaleExecutionClaims(dbConn, project, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	requests, err := dbConn.ListRequestsByStatus(db.StatusApproved, project)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := core.ExecutionQueue(dbConn, project, cfg.General.MaxConcurrentExecutions)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range statuses {
+		execQueue[st.RequestID] = st
+	}
+	sort.SliceStable(requests, func(i, j int) bool {
+		qi, qj := execQueue[requests[i].ID], execQueue[requests[j].ID]
+		if qi == nil || qj == nil {
+			return qi != nil && qj == nil
+		}
+		return qi.Position < qj.Position
+	})
+	return requests, nil
+}
+
 // dedupeStrings returns a copy with duplicates removed, preserving order.
 func dedupeStrings(in []string) []string {
 	seen := make(map[string]bool, len(in))
@@ -2,8 +2,11 @@ package cli
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
@@ -39,6 +42,12 @@ func resetPendingFlags() {
 	flagConfig = ""
 	flagPendingAllProjects = false
 	flagPendingReviewPool = false
+	flagPendingWorkspace = false
+	flagPendingStatus = string(db.StatusPending)
+	// A prior --help leaves cobra's help flag set on the shared command.
+	if f := pendingCmd.Flags().Lookup("help"); f != nil {
+		_ = f.Value.Set("false")
+	}
 }
 
 func TestPendingCommand_ListsPendingRequests(t *testing.T) {
@@ -257,3 +266,219 @@ func TestDedupeStrings(t *testing.T) {
 		})
 	}
 }
+
+func TestPendingCommand_WorkspaceAggregatesMembers(t *testing.T) {
+	root := setupWorkspaceRepo(t)
+	resetWorkspaceFlags()
+	if err := runWorkspaceInit(nil, nil); err != nil {
+		t.Fatalf("runWorkspaceInit failed: %v", err)
+	}
+	resetPendingFlags()
+
+	// One pending request per member, each in the member's own state.db.
+	// IDs are left for CreateRequest to assign, as in real use.
+	api := filepath.Join(root, "services", "api")
+	web := filepath.Join(root, "web")
+	for _, member := range []string{api, web} {
+		memberDB, err := db.Open(filepath.Join(member, ".slb", "state.db"))
+		if err != nil {
+			t.Fatalf("open member db: %v", err)
+		}
+		sess := testutil.MakeSession(t, memberDB, testutil.WithProject(member))
+		testutil.MakeRequest(t, memberDB, sess,
+			testutil.WithCommand("rm -rf ./build", member, true),
+			func(r *db.Request) { r.ID = "" },
+		)
+		memberDB.Close()
+	}
+
+	// Run from inside one member: --workspace still sees every member.
+	cmd := newTestPendingCmd("")
+	stdout, err := executeCommandCapture(t, cmd, "pending", "-C", api, "--workspace", "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected 2 pending requests across members, got %d", len(result))
+	}
+
+	projects := map[string]bool{}
+	ids := map[string]bool{}
+	for _, r := range result {
+		projects[r["project"].(string)] = true
+		ids[r["request_id"].(string)] = true
+	}
+	if !projects[filepath.Join("services", "api")] || !projects["web"] {
+		t.Errorf("expected both members in project column, got %v", projects)
+	}
+	if len(ids) != 2 {
+		t.Errorf("request IDs must be unique across members, got %v", ids)
+	}
+
+	// Without --workspace only the resolved member is listed.
+	resetPendingFlags()
+	cmd = newTestPendingCmd("")
+	stdout, err = executeCommandCapture(t, cmd, "pending", "-C", api, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result = nil
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(result) != 1 || result[0]["project"] != nil {
+		t.Fatalf("expected 1 request without project field, got %v", result)
+	}
+}
+
+func TestPendingCommand_WorkspaceOutsideWorkspace(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetPendingFlags()
+
+	cmd := newTestPendingCmd(h.DBPath)
+	_, err := executeCommandCapture(t, cmd, "pending", "-C", h.ProjectDir, "--workspace", "-j")
+	if err == nil || !strings.Contains(err.Error(), "not inside an SLB workspace") {
+		t.Fatalf("expected not-in-workspace error, got %v", err)
+	}
+}
+
+func TestPendingCommand_StatusQueuedListsQueueOrder(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetPendingFlags()
+
+	cfg := "[integrations]\nagent_mail_enabled = false\n\n[rate_limits]\nmax_pending_per_session = 1\nrate_limit_action = \"queue\"\n"
+	if err := os.WriteFile(filepath.Join(h.ProjectDir, ".slb", "config.toml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand("rm -rf ./build", h.ProjectDir, true))
+	first := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -rf ./dist", h.ProjectDir, true),
+		testutil.WithStatus(db.StatusQueued),
+	)
+	second := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -rf ./cache", h.ProjectDir, true),
+		testutil.WithStatus(db.StatusQueued),
+	)
+
+	cmd := newTestPendingCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "pending", "-C", h.ProjectDir, "--status", "queued", "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result []struct {
+		RequestID string `json:"request_id"`
+		Queue     struct {
+			Position    int `json:"position"`
+			QueueLength int `json:"queue_length"`
+			Pending     int `json:"pending"`
+		} `json:"queue"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(result) != 2 || result[0].RequestID != first.ID || result[1].RequestID != second.ID {
+		t.Fatalf("expected queue order [%s %s], got %+v", first.ID, second.ID, result)
+	}
+	for i, r := range result {
+		if r.Queue.Position != i+1 || r.Queue.QueueLength != 2 || r.Queue.Pending != 1 {
+			t.Errorf("entry %d queue = %+v, want position %d of 2 with 1 pending", i, r.Queue, i+1)
+		}
+	}
+
+	// The default listing shows only requests awaiting review.
+	resetPendingFlags()
+	cmd = newTestPendingCmd(h.DBPath)
+	stdout, err = executeCommandCapture(t, cmd, "pending", "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var pending []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &pending); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(pending) != 1 {
+		t.Errorf("expected 1 pending request, got %d", len(pending))
+	}
+}
+
+func TestPendingCommand_StatusApprovedShowsExecutionQueue(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetPendingFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	running := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -rf ./build", h.ProjectDir, true),
+		testutil.WithStatus(db.StatusApproved),
+	)
+	waiting := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -rf ./dist", h.ProjectDir, true),
+		testutil.WithStatus(db.StatusApproved),
+	)
+	testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand("rm -rf ./cache", h.ProjectDir, true))
+
+	host, _ := db.LocalHostIdentity()
+	now := time.Now().UTC()
+	for i, r := range []*db.Request{running, waiting} {
+		if err := h.DB.EnqueueExecution(&db.ExecutionClaim{
+			RequestID: r.ID, ProjectPath: h.ProjectDir, Hostname: host, PID: os.Getpid(),
+			ApprovedAt: now.Add(time.Duration(i-2) * time.Minute),
+		}); err != nil {
+			t.Fatalf("EnqueueExecution: %v", err)
+		}
+	}
+	if ok, err := h.DB.AcquireExecutionSlot(running.ID, 1, now, now.Add(time.Hour)); err != nil || !ok {
+		t.Fatalf("AcquireExecutionSlot = %v, %v", ok, err)
+	}
+
+	cmd := newTestPendingCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "list", "-C", h.ProjectDir, "--status", "approved", "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result []struct {
+		RequestID      string `json:"request_id"`
+		ExecutionQueue *struct {
+			Position int `json:"position"`
+			Running  int `json:"running"`
+		} `json:"execution_queue"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(result) != 2 || result[0].RequestID != waiting.ID || result[1].RequestID != running.ID {
+		t.Fatalf("expected [%s %s], got %+v", waiting.ID, running.ID, result)
+	}
+	if q := result[0].ExecutionQueue; q == nil || q.Position != 1 || q.Running != 1 {
+		t.Errorf("waiting request execution_queue = %+v, want position 1 with 1 running", q)
+	}
+	if result[1].ExecutionQueue != nil {
+		t.Errorf("running request should not be queued, got %+v", result[1].ExecutionQueue)
+	}
+
+	resetPendingFlags()
+	cmd = newTestPendingCmd(h.DBPath)
+	_, err = executeCommandCapture(t, cmd, "pending", "-C", h.ProjectDir, "--status", "approved", "--all-projects")
+	if err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Fatalf("expected combination error, got %v", err)
+	}
+}
+
+func TestPendingCommand_InvalidStatus(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetPendingFlags()
+
+	cmd := newTestPendingCmd(h.DBPath)
+	_, err := executeCommandCapture(t, cmd, "pending", "-C", h.ProjectDir, "--status", "executed")
+	if err == nil || !strings.Contains(err.Error(), "invalid --status") {
+		t.Fatalf("expected invalid status error, got %v", err)
+	}
+}
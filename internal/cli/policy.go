@@ -0,0 +1,336 @@
+// Package cli implements the policy command for auto-approve policy attestation.
+package cli
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// errPolicyAttestationOverdue is returned when auto-approval is refused
+// because the policy attestation is past its grace period.
+var errPolicyAttestationOverdue = errors.New("auto-approve policy attestation overdue; run 'slb policy attest'")
+
+// errHumanSessionRequired is returned when an attestation is attempted from
+// a session that is not marked as human-driven.
+var errHumanSessionRequired = errors.New("attestation requires a human session (start one from a terminal with 'slb session start --program human')")
+
+var (
+	flagPolicyYes      bool
+	flagPolicyAck      string
+	flagPolicyComment  string
+	flagPolicyReviewer string
+	flagPolicyKey      string
+)
+
+func init() {
+	policyAttestCmd.Flags().BoolVarP(&flagPolicyYes, "yes", "y", false, "skip interactive confirmation")
+	policyAttestCmd.Flags().StringVar(&flagPolicyAck, "ack", "", "policy hash acknowledgment (required with --yes)")
+	policyAttestCmd.Flags().StringVarP(&flagPolicyComment, "comment", "m", "", "note recorded with the attestation")
+	policyAttestCmd.Flags().StringVar(&flagPolicyReviewer, "reviewer", "", "attest a reviewer flagged for rubber-stamp approvals instead of the policy")
+	policyAttestCmd.Flags().StringVarP(&flagPolicyKey, "session-key", "k", "", "human session HMAC key for signing (required)")
+
+	policyCmd.AddCommand(policyAttestCmd)
+	policyCmd.AddCommand(policyStatusCmd)
+	rootCmd.AddCommand(policyCmd)
+}
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Attest that the auto-approve policy is still appropriate",
+	Long: `Manage periodic attestation of the project's auto-approve policy.
+
+The policy is the project's effective auto-approve settings (approval
+quorum, timeout action, tier patterns and delays, trusted and blocked agents)
+merged from ~/.slb/config.*, the workspace root config, the project config
+and SLB_* environment variables. With general.policy_attestation_days set, a
+human must re-attest it at that cadence. When the latest attestation is
+older, or any of those settings changed since it was attested, the daemon
+logs a warning and broadcasts a policy_attestation_due event, and
+'slb watch --auto-approve-caution' emits the same event before each
+auto-approval. With general.policy_attestation_grace_days set, auto-approval
+is refused once that many days have passed since the attestation became due,
+and straight away if the policy was never attested.
+
+Examples:
+  slb policy status
+  slb policy attest -s $HUMAN_SESSION_ID -k $HUMAN_SESSION_KEY -m "quarterly review"
+  slb policy attest -s $HUMAN_SESSION_ID -k $HUMAN_SESSION_KEY --yes --ack 3f2a9c1d
+  slb policy attest -s $HUMAN_SESSION_ID -k $HUMAN_SESSION_KEY --reviewer BlueDog -m "spot-checked"`,
+}
+
+var policyAttestCmd = &cobra.Command{
+	Use:   "attest",
+	Short: "Record a signed attestation covering the current policy file",
+	Long: `Record that the current auto-approve policy has been reviewed.
+
+The attestation is signed with the session's key and covers the SHA-256 of
+the effective auto-approve settings; any later change to them, in any config
+layer, invalidates it. The session must be a human session (started from a
+terminal with --program human) and its key must be passed with --session-key.
+Attesting requires interactive confirmation OR --yes with --ack containing a
+prefix (8+ characters) of the policy hash shown by 'slb policy status'.
+
+With --reviewer, the attestation instead clears a reviewer flagged by the
+agents.reviewer_* fatigue thresholds (see 'slb stats --reviewers'): only
+reviews after it are counted again. A reviewer cannot attest for itself, and
+--yes requires --ack with the reviewer's name.`,
+	Args: cobra.NoArgs,
+	RunE: runPolicyAttest,
+}
+
+var policyStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the policy attestation status and history",
+	Args:  cobra.NoArgs,
+	RunE:  runPolicyStatus,
+}
+
+func runPolicyAttest(cmd *cobra.Command, args []string) error {
+	if flagSessionID == "" {
+		return fmt.Errorf("--session-id is required")
+	}
+	if flagPolicyKey == "" {
+		return fmt.Errorf("--session-key is required")
+	}
+	if flagPolicyReviewer != "" {
+		return runReviewerAttest(flagPolicyReviewer)
+	}
+
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(config.LoadOptions{
+		ProjectDir: project,
+		ConfigPath: flagConfig,
+	})
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	_, policyPath, err := config.ConfigPaths(project, flagConfig)
+	if err != nil {
+		return err
+	}
+	hash := config.PolicyHash(cfg)
+
+	if flagPolicyYes {
+		if flagPolicyAck == "" {
+			return fmt.Errorf("--ack is required when using --yes")
+		}
+		if len(flagPolicyAck) < 8 {
+			return fmt.Errorf("--ack must be at least 8 characters of the policy hash")
+		}
+		if !strings.HasPrefix(hash, flagPolicyAck) {
+			return fmt.Errorf("--ack hash does not match policy file (expected prefix: %s)", hash[:8])
+		}
+	} else {
+		fmt.Println("=== POLICY ATTESTATION ===")
+		fmt.Printf("Project: %s\n", project)
+		fmt.Printf("Config:  %s\n", policyPath)
+		fmt.Printf("Hash:    %s\n", hash)
+		fmt.Println()
+		fmt.Println("You are attesting that this auto-approve policy is still appropriate.")
+		fmt.Print("Type 'ATTEST' to confirm: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading confirmation: %w", err)
+		}
+		if strings.TrimSpace(input) != "ATTEST" {
+			return fmt.Errorf("attestation cancelled")
+		}
+	}
+
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	sess, err := attestingSession(dbConn)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	attestation := &db.PolicyAttestation{
+		ProjectPath: project,
+		PolicyPath:  policyPath,
+		PolicyHash:  hash,
+		SessionID:   sess.ID,
+		AgentName:   sess.AgentName,
+		Model:       sess.Model,
+		Signature:   db.ComputeAttestationSignature(sess.SessionKey, project, hash, now),
+		Comment:     flagPolicyComment,
+		CreatedAt:   now,
+	}
+	if err := dbConn.CreatePolicyAttestation(attestation); err != nil {
+		return err
+	}
+
+	out := output.New(output.Format(GetOutput()))
+	return out.Write(attestation)
+}
+
+// runReviewerAttest records an attestation that clears a reviewer flagged for
+// rubber-stamp approvals. It reuses the policy attestation storage under the
+// reviewer's attestation scope.
+func runReviewerAttest(reviewer string) error {
+	if flagPolicyYes {
+		if flagPolicyAck != reviewer {
+			return fmt.Errorf("--ack must repeat the reviewer name (%s) when using --yes", reviewer)
+		}
+	} else {
+		fmt.Println("=== REVIEWER ATTESTATION ===")
+		fmt.Printf("Reviewer: %s\n", reviewer)
+		fmt.Println()
+		fmt.Println("You are attesting that this reviewer's recent approvals were meaningful reviews.")
+		fmt.Print("Type 'ATTEST' to confirm: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading confirmation: %w", err)
+		}
+		if strings.TrimSpace(input) != "ATTEST" {
+			return fmt.Errorf("attestation cancelled")
+		}
+	}
+
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	sess, err := attestingSession(dbConn)
+	if err != nil {
+		return err
+	}
+	if sess.AgentName == reviewer {
+		return fmt.Errorf("reviewer %s cannot attest for itself", reviewer)
+	}
+
+	now := time.Now().UTC()
+	scope := db.ReviewerAttestationScope(reviewer)
+	hash := db.ReviewerAttestationHash(reviewer)
+	attestation := &db.PolicyAttestation{
+		ProjectPath: scope,
+		PolicyHash:  hash,
+		SessionID:   sess.ID,
+		AgentName:   sess.AgentName,
+		Model:       sess.Model,
+		Signature:   db.ComputeAttestationSignature(sess.SessionKey, scope, hash, now),
+		Comment:     flagPolicyComment,
+		CreatedAt:   now,
+	}
+	if err := dbConn.CreatePolicyAttestation(attestation); err != nil {
+		return err
+	}
+
+	out := output.New(output.Format(GetOutput()))
+	return out.Write(attestation)
+}
+
+// attestingSession loads the session named by --session-id and checks that it
+// is active, human-marked and matches --session-key.
+func attestingSession(dbConn *db.DB) (*db.Session, error) {
+	sess, err := dbConn.GetSession(flagSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("getting session: %w", err)
+	}
+	if sess.EndedAt != nil {
+		return nil, fmt.Errorf("session %s has ended", sess.ID)
+	}
+	if subtle.ConstantTimeCompare([]byte(flagPolicyKey), []byte(sess.SessionKey)) != 1 {
+		return nil, core.ErrSessionKeyMismatch
+	}
+	if !sess.IsHuman() {
+		return nil, errHumanSessionRequired
+	}
+	return sess, nil
+}
+
+func runPolicyStatus(cmd *cobra.Command, args []string) error {
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	cfg, err := config.Load(config.LoadOptions{
+		ProjectDir: project,
+		ConfigPath: flagConfig,
+	})
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	_, policyPath, err := config.ConfigPaths(project, flagConfig)
+	if err != nil {
+		return err
+	}
+	check, err := checkPolicyAttestation(dbConn, cfg, project)
+	if err != nil {
+		return err
+	}
+	history, err := dbConn.ListPolicyAttestations(project)
+	if err != nil {
+		return err
+	}
+	if history == nil {
+		history = []*db.PolicyAttestation{}
+	}
+
+	resp := map[string]any{
+		"project":      project,
+		"policy_path":  policyPath,
+		"policy_hash":  check.PolicyHash,
+		"state":        string(check.State),
+		"refusing":     check.Refuse,
+		"cadence_days": cfg.General.PolicyAttestationDays,
+		"grace_days":   cfg.General.PolicyAttestationGraceDays,
+		"history":      history,
+	}
+	if !check.DueAt.IsZero() {
+		resp["due_at"] = check.DueAt.Format(time.RFC3339)
+	}
+	out := output.New(output.Format(GetOutput()))
+	return out.Write(resp)
+}
+
+// checkPolicyAttestation evaluates the project's latest attestation against
+// the policy in cfg using the configured cadence and grace period.
+func checkPolicyAttestation(dbConn *db.DB, cfg config.Config, project string) (db.AttestationCheck, error) {
+	day := 24 * time.Hour
+	return dbConn.CheckPolicyAttestation(project, config.PolicyHash(cfg),
+		time.Duration(cfg.General.PolicyAttestationDays)*day,
+		time.Duration(cfg.General.PolicyAttestationGraceDays)*day,
+		time.Now().UTC())
+}
+
+// autoApprovePolicyCheck loads the config of the project a request belongs
+// to and evaluates its policy attestation for auto-approval.
+func autoApprovePolicyCheck(dbConn *db.DB, project string) (db.AttestationCheck, error) {
+	cfg, err := config.Load(config.LoadOptions{ProjectDir: project})
+	if err != nil {
+		return db.AttestationCheck{}, fmt.Errorf("loading config: %w", err)
+	}
+	return checkPolicyAttestation(dbConn, cfg, project)
+}
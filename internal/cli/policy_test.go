@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestPolicyCmd creates a fresh policy command tree for testing.
+func newTestPolicyCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
+	root.PersistentFlags().StringVarP(&flagConfig, "config", "c", "", "config file")
+
+	polCmd := &cobra.Command{Use: "policy"}
+	attestCmd := &cobra.Command{
+		Use:  "attest",
+		Args: cobra.NoArgs,
+		RunE: policyAttestCmd.RunE,
+	}
+	attestCmd.Flags().BoolVarP(&flagPolicyYes, "yes", "y", false, "skip confirmation")
+	attestCmd.Flags().StringVar(&flagPolicyAck, "ack", "", "policy hash acknowledgment")
+	attestCmd.Flags().StringVarP(&flagPolicyComment, "comment", "m", "", "comment")
+	attestCmd.Flags().StringVar(&flagPolicyReviewer, "reviewer", "", "reviewer to attest")
+	attestCmd.Flags().StringVarP(&flagPolicyKey, "session-key", "k", "", "session key")
+	statusCmd := &cobra.Command{
+		Use:  "status",
+		Args: cobra.NoArgs,
+		RunE: policyStatusCmd.RunE,
+	}
+	polCmd.AddCommand(attestCmd, statusCmd)
+	root.AddCommand(polCmd)
+
+	return root
+}
+
+func resetPolicyFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagSessionID = ""
+	flagConfig = ""
+	flagPolicyYes = false
+	flagPolicyAck = ""
+	flagPolicyComment = ""
+	flagPolicyReviewer = ""
+	flagPolicyKey = ""
+}
+
+// writePolicy writes the project's config file and returns the hash of the
+// resulting effective policy.
+func writePolicy(t *testing.T, project, content string) string {
+	t.Helper()
+	path := filepath.Join(project, ".slb", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(config.LoadOptions{ProjectDir: project})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config.PolicyHash(cfg)
+}
+
+func TestPolicyAttest_RecordsSignedAttestation(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetPolicyFlags()
+	hash := writePolicy(t, h.ProjectDir, "[general]\npolicy_attestation_days = 30\n")
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Human"),
+		testutil.WithProgram(db.HumanProgram))
+
+	cmd := newTestPolicyCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "policy", "attest", "-C", h.ProjectDir, "-j",
+		"-s", sess.ID, "-k", sess.SessionKey, "--yes", "--ack", hash[:12], "-m", "reviewed")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got db.PolicyAttestation
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if got.PolicyHash != hash || got.AgentName != "Human" || got.Comment != "reviewed" {
+		t.Errorf("attestation = %+v", got)
+	}
+
+	latest, err := h.DB.LatestPolicyAttestation(h.ProjectDir)
+	if err != nil {
+		t.Fatalf("LatestPolicyAttestation failed: %v", err)
+	}
+	if !db.VerifyAttestationSignature(sess.SessionKey, latest) {
+		t.Error("expected attestation to be signed with the session key")
+	}
+}
+
+func TestPolicyAttest_RequiresSessionAndAck(t *testing.T) {
+	h := testutil.NewHarness(t)
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithProgram(db.HumanProgram))
+	agent := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Agent"))
+	hash := writePolicy(t, h.ProjectDir, "[general]\n")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no session", []string{"--yes", "--ack", "deadbeef"}, "--session-id"},
+		{"no key", []string{"-s", sess.ID, "--yes", "--ack", hash[:8]}, "--session-key"},
+		{"wrong key", []string{"-s", sess.ID, "-k", agent.SessionKey, "--yes", "--ack", hash[:8]}, "session key"},
+		{"agent session", []string{"-s", agent.ID, "-k", agent.SessionKey, "--yes", "--ack", hash[:8]}, "human session"},
+		{"no ack", []string{"-s", sess.ID, "-k", sess.SessionKey, "--yes"}, "--ack is required"},
+		{"short ack", []string{"-s", sess.ID, "-k", sess.SessionKey, "--yes", "--ack", "abc"}, "at least 8"},
+		{"wrong ack", []string{"-s", sess.ID, "-k", sess.SessionKey, "--yes", "--ack", "00000000"}, "does not match"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resetPolicyFlags()
+			cmd := newTestPolicyCmd(h.DBPath)
+			args := append([]string{"policy", "attest", "-C", h.ProjectDir}, tc.args...)
+			_, err := executeCommandCapture(t, cmd, args...)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+	if list, _ := h.DB.ListPolicyAttestations(h.ProjectDir); len(list) != 0 {
+		t.Errorf("expected no attestations, got %d", len(list))
+	}
+}
+
+func TestPolicyStatus_ReportsInvalidAfterEdit(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetPolicyFlags()
+	hash := writePolicy(t, h.ProjectDir, "[general]\npolicy_attestation_days = 30\n")
+	if err := h.DB.CreatePolicyAttestation(&db.PolicyAttestation{
+		ProjectPath: h.ProjectDir,
+		PolicyHash:  hash,
+		SessionID:   "sess-human",
+		AgentName:   "Human",
+		Signature:   "sig",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	status := func() map[string]any {
+		t.Helper()
+		resetPolicyFlags()
+		cmd := newTestPolicyCmd(h.DBPath)
+		stdout, err := executeCommandCapture(t, cmd, "policy", "status", "-C", h.ProjectDir, "-j")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var result map[string]any
+		if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+			t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+		}
+		return result
+	}
+
+	if got := status(); got["state"] != "current" {
+		t.Fatalf("state = %v, want current", got["state"])
+	}
+
+	writePolicy(t, h.ProjectDir, "[general]\npolicy_attestation_days = 30\nmin_approvals = 1\n")
+	got := status()
+	if got["state"] != "invalid" {
+		t.Errorf("state = %v, want invalid after editing the policy", got["state"])
+	}
+	if history, _ := got["history"].([]any); len(history) != 1 {
+		t.Errorf("history = %v", got["history"])
+	}
+}
+
+func TestAutoApproveCaution_PolicyAttestation(t *testing.T) {
+	h := testutil.NewHarness(t)
+	hash := writePolicy(t, h.ProjectDir, "[general]\npolicy_attestation_days = 30\npolicy_attestation_grace_days = 7\n")
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+	origSession := flagWatchSessionID
+	flagWatchSessionID = reviewer.ID
+	t.Cleanup(func() { flagWatchSessionID = origSession })
+
+	attest := func(age time.Duration) {
+		t.Helper()
+		if err := h.DB.CreatePolicyAttestation(&db.PolicyAttestation{
+			ProjectPath: h.ProjectDir,
+			PolicyHash:  hash,
+			SessionID:   sess.ID,
+			AgentName:   sess.AgentName,
+			Signature:   "sig",
+			CreatedAt:   time.Now().UTC().Add(-age),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	approve := func() (*db.Request, map[string]any, error) {
+		t.Helper()
+		req := testutil.MakeRequest(t, h.DB, sess,
+			testutil.WithCommand("echo hi", h.ProjectDir, true),
+			testutil.WithRisk(db.RiskTierCaution),
+			testutil.WithMinApprovals(1),
+		)
+		var buf bytes.Buffer
+		err := autoApproveCautionIn(context.Background(), h.DBPath, req.ID, "", json.NewEncoder(&buf))
+		var event map[string]any
+		if buf.Len() > 0 {
+			if jerr := json.Unmarshal(buf.Bytes(), &event); jerr != nil {
+				t.Fatalf("failed to parse event: %v", jerr)
+			}
+		}
+		req, _ = h.DB.GetRequest(req.ID)
+		return req, event, err
+	}
+
+	_, event, err := approve()
+	if !errors.Is(err, errPolicyAttestationOverdue) {
+		t.Fatalf("expected refusal for a never-attested policy, got %v", err)
+	}
+	if event["state"] != "missing" || event["refusing"] != true {
+		t.Errorf("nag event = %v", event)
+	}
+
+	day := 24 * time.Hour
+	attest(40 * day)
+	_, event, err = approve()
+	if !errors.Is(err, errPolicyAttestationOverdue) {
+		t.Fatalf("expected refusal past the grace period, got %v", err)
+	}
+	if event["event"] != daemon.PolicyAttestationDueEvent || event["refusing"] != true {
+		t.Errorf("nag event = %v", event)
+	}
+
+	attest(33 * day)
+	req, event, err := approve()
+	if err != nil {
+		t.Fatalf("expected approval within the grace period, got %v", err)
+	}
+	if event["state"] != "due" || event["refusing"] != false {
+		t.Errorf("nag event = %v", event)
+	}
+	if req.Status != db.StatusApproved {
+		t.Errorf("status = %s, want approved", req.Status)
+	}
+
+	attest(0)
+	if _, event, err = approve(); err != nil || event != nil {
+		t.Errorf("expected silent approval with a current attestation, got %v / %v", event, err)
+	}
+}
+
+func TestPolicyAttest_Reviewer(t *testing.T) {
+	h := testutil.NewHarness(t)
+	human := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Human"),
+		testutil.WithProgram(db.HumanProgram))
+	stamp := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Stamp"),
+		testutil.WithProgram(db.HumanProgram))
+	agent := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Agent"))
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"wrong ack", []string{"-s", human.ID, "-k", human.SessionKey, "--yes", "--ack", "Other"}, "reviewer name"},
+		{"agent session", []string{"-s", agent.ID, "-k", agent.SessionKey, "--yes", "--ack", "Stamp"}, "human session"},
+		{"self attestation", []string{"-s", stamp.ID, "-k", stamp.SessionKey, "--yes", "--ack", "Stamp"}, "cannot attest for itself"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resetPolicyFlags()
+			cmd := newTestPolicyCmd(h.DBPath)
+			args := append([]string{"policy", "attest", "-C", h.ProjectDir, "--reviewer", "Stamp"}, tc.args...)
+			_, err := executeCommandCapture(t, cmd, args...)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	resetPolicyFlags()
+	cmd := newTestPolicyCmd(h.DBPath)
+	_, err := executeCommandCapture(t, cmd, "policy", "attest", "-C", h.ProjectDir, "-j",
+		"-s", human.ID, "-k", human.SessionKey, "--reviewer", "Stamp", "--yes", "--ack", "Stamp", "-m", "spot-checked")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	latest, err := h.DB.LatestPolicyAttestation(db.ReviewerAttestationScope("Stamp"))
+	if err != nil {
+		t.Fatalf("LatestPolicyAttestation failed: %v", err)
+	}
+	if latest.AgentName != "Human" || latest.PolicyHash != db.ReviewerAttestationHash("Stamp") || !db.VerifyAttestationSignature(human.SessionKey, latest) {
+		t.Errorf("reviewer attestation = %+v", latest)
+	}
+	if list, _ := h.DB.ListPolicyAttestations(h.ProjectDir); len(list) != 0 {
+		t.Errorf("reviewer attestation must not count as a policy attestation, got %d", len(list))
+	}
+}
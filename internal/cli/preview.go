@@ -0,0 +1,149 @@
+// Package cli implements the preview command.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagPreviewPromote bool
+	flagPreviewReason  string
+	flagPreviewTimeout int
+)
+
+func init() {
+	previewCmd.Flags().BoolVar(&flagPreviewPromote, "promote", false, "create a real request with the preview evidence attached")
+	previewCmd.Flags().StringVar(&flagPreviewReason, "reason", "", "reason for the request (with --promote)")
+	previewCmd.Flags().IntVar(&flagPreviewTimeout, "timeout", 30, "trial execution timeout in seconds")
+
+	rootCmd.AddCommand(previewCmd)
+}
+
+var previewCmd = &cobra.Command{
+	Use:   "preview <command>",
+	Short: "Classify and trial-run a command in a scratch copy without requesting approval",
+	Long: `Preview a command without touching the approval state.
+
+The command is classified, its dry-run variant is run when one exists, and
+safe commands get a trial execution against a scratch copy of the working
+directory:
+
+  temp_copy  - commands that only write the paths they are given (rm, mv,
+               touch, chmod, ...) run on the host in a temporary copy with
+               a minimal environment; paths outside it are refused. This is
+               NOT a sandbox.
+  container  - with general.preview_container_image set, the command runs
+               in that image (docker or podman, no network) against the copy
+
+The copy is only made when the working directory is under
+general.preview_max_copy_mb. Files created, modified and deleted by the
+trial are reported.
+
+Use --promote to turn the preview into a real request (caution tier for
+safe commands) with the dry-run and trial output pre-attached.
+
+Examples:
+  slb preview "rm -r build/cache"
+  slb preview "rm old.log" --promote --reason "double-check before cleanup" -s $SID`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPreview,
+}
+
+func runPreview(cmd *cobra.Command, args []string) error {
+	command := args[0]
+
+	if flagPreviewPromote && flagSessionID == "" {
+		return fmt.Errorf("--session-id is required with --promote")
+	}
+
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = project
+	}
+
+	cfg, err := config.Load(config.LoadOptions{
+		ProjectDir: project,
+		ConfigPath: flagConfig,
+		PolicyDir:  cwd,
+	})
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	preview, err := core.RunPreview(cmd.Context(), core.PreviewOptions{
+		Command:        command,
+		Cwd:            cwd,
+		EnableDryRun:   cfg.General.EnableDryRun,
+		MaxCopyBytes:   int64(cfg.General.PreviewMaxCopyMB) * 1024 * 1024,
+		ContainerImage: cfg.General.PreviewContainerImage,
+		Timeout:        time.Duration(flagPreviewTimeout) * time.Second,
+		RiskOverrides:  toRiskOverrideRules(cfg.RiskOverrides),
+	})
+	if err != nil {
+		return fmt.Errorf("running preview: %w", err)
+	}
+
+	resp := map[string]any{
+		"command":        preview.Command,
+		"tier":           string(preview.Tier),
+		"tier_reason":    preview.TierReason,
+		"summary":        preview.Summary,
+		"needs_approval": preview.NeedsApproval,
+		"sandbox":        preview.Sandbox,
+	}
+	if preview.DryRun != nil {
+		resp["dry_run"] = preview.DryRun
+	}
+	if preview.DryRunError != "" {
+		resp["dry_run_error"] = preview.DryRunError
+	}
+
+	out := output.New(output.Format(GetOutput()))
+	if !flagPreviewPromote {
+		return out.Write(resp)
+	}
+
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	rl := core.NewRateLimiter(dbConn, toRateLimitConfig(cfg))
+	creator := core.NewRequestCreator(dbConn, rl, nil, toRequestCreatorConfig(cfg))
+	result, err := creator.CreateRequest(core.CreateRequestOptions{
+		SessionID:          flagSessionID,
+		Command:            command,
+		Cwd:                cwd,
+		Justification:      core.Justification{Reason: flagPreviewReason},
+		ContextAttachments: preview.Attachments(),
+		DryRun:             preview.DryRun,
+		ForceReview:        true,
+		ProjectPath:        project,
+	})
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	request := result.Request
+	resp["promoted"] = true
+	resp["request_id"] = request.ID
+	resp["status"] = string(request.Status)
+	resp["tier"] = string(request.RiskTier)
+	resp["tier_reason"] = request.TierReason
+	resp["min_approvals"] = request.MinApprovals
+	return out.Write(resp)
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestPreviewCmd creates a fresh preview command for testing.
+func newTestPreviewCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
+	root.PersistentFlags().StringVarP(&flagConfig, "config", "c", "", "config file")
+
+	prevCmd := &cobra.Command{
+		Use:  "preview <command>",
+		Args: cobra.ExactArgs(1),
+		RunE: previewCmd.RunE,
+	}
+	prevCmd.Flags().BoolVar(&flagPreviewPromote, "promote", false, "promote to a request")
+	prevCmd.Flags().StringVar(&flagPreviewReason, "reason", "", "reason")
+	prevCmd.Flags().IntVar(&flagPreviewTimeout, "timeout", 30, "timeout seconds")
+
+	root.AddCommand(prevCmd)
+
+	return root
+}
+
+func resetPreviewFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagSessionID = ""
+	flagConfig = ""
+	flagPreviewPromote = false
+	flagPreviewReason = ""
+	flagPreviewTimeout = 30
+}
+
+// chdirPreview runs the test from dir with a file for the trial to remove.
+func chdirPreview(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "old.log"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+}
+
+func TestPreviewCommand_RunsTrialWithoutRequest(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetPreviewFlags()
+	chdirPreview(t, h.ProjectDir)
+
+	cmd := newTestPreviewCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "preview", "-C", h.ProjectDir, "-j", "rm old.log")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	sandbox, _ := result["sandbox"].(map[string]any)
+	if sandbox["mode"] != "temp_copy" {
+		t.Fatalf("expected temp_copy trial, got %v", result["sandbox"])
+	}
+	if deleted, _ := sandbox["deleted"].([]any); len(deleted) != 1 || deleted[0] != "old.log" {
+		t.Errorf("deleted = %v", sandbox["deleted"])
+	}
+	if result["request_id"] != nil {
+		t.Error("preview without --promote must not create a request")
+	}
+	if _, err := os.Stat(filepath.Join(h.ProjectDir, "old.log")); err != nil {
+		t.Errorf("trial touched the real directory: %v", err)
+	}
+	if reqs, _ := h.DB.ListAllRequests(h.ProjectDir); len(reqs) != 0 {
+		t.Errorf("expected no requests, got %d", len(reqs))
+	}
+}
+
+func TestPreviewCommand_PromoteRequiresSession(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetPreviewFlags()
+
+	cmd := newTestPreviewCmd(h.DBPath)
+	if _, err := executeCommandCapture(t, cmd, "preview", "-C", h.ProjectDir, "--promote", "rm old.log"); err == nil {
+		t.Fatal("expected error without --session-id")
+	}
+}
+
+func TestPreviewCommand_PromoteAttachesEvidence(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetPreviewFlags()
+	chdirPreview(t, h.ProjectDir)
+
+	sess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("TestAgent"),
+	)
+
+	cmd := newTestPreviewCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "preview", "-C", h.ProjectDir, "-j",
+		"-s", sess.ID, "--promote", "--reason", "double-check", "rm old.log")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	id, _ := result["request_id"].(string)
+	if id == "" || result["tier"] != "caution" {
+		t.Fatalf("expected caution request, got %v", result)
+	}
+
+	req, err := h.DB.GetRequest(id)
+	if err != nil {
+		t.Fatalf("GetRequest failed: %v", err)
+	}
+	if req.Justification.Reason != "double-check" {
+		t.Errorf("reason = %q", req.Justification.Reason)
+	}
+	if req.DryRun == nil {
+		t.Error("expected dry-run evidence on the promoted request")
+	}
+	if len(req.Attachments) != 1 || req.Attachments[0].Metadata["source"] != "slb preview" {
+		t.Errorf("expected preview trial attachment, got %+v", req.Attachments)
+	}
+}
@@ -17,6 +17,8 @@ var (
 	flagRejectReason        string
 	flagRejectComments      string
 	flagRejectTargetProject string
+	flagRejectSegments      string
+	flagRejectCallbackID    string
 )
 
 func init() {
@@ -25,6 +27,8 @@ func init() {
 	rejectCmd.Flags().StringVarP(&flagRejectReason, "reason", "r", "", "reason for rejection (required)")
 	rejectCmd.Flags().StringVarP(&flagRejectComments, "comments", "m", "", "additional comments")
 	rejectCmd.Flags().StringVar(&flagRejectTargetProject, "target-project", "", "target project path for cross-project rejections")
+	rejectCmd.Flags().StringVar(&flagRejectSegments, "segments", "", "reject only these segments of a compound command (e.g. 2); the rest are approved")
+	rejectCmd.Flags().StringVar(&flagRejectCallbackID, "callback-id", "", "ID of the chat or webhook callback delivering this rejection; repeats are no-ops")
 
 	rootCmd.AddCommand(rejectCmd)
 }
@@ -43,9 +47,20 @@ authenticity.
 For cross-project reviews, use --target-project to specify which project's
 database contains the request you want to reject.
 
+Evidence you opened via 'slb show -s <your-session-id>' or the TUI is
+attached to the rejection, as it is for approvals.
+
+For compound commands, --segments rejects only the listed segments (numbered
+from 1, as shown by 'slb show') and approves the rest, so the remaining
+segments can still run once every segment is decided.
+
+As with approvals, --callback-id makes a relayed chat or webhook rejection
+safe to deliver more than once.
+
 	Examples:
 	  slb reject abc123 -s $SESSION_ID -k $SESSION_KEY -r "Command too dangerous"
 	  slb reject abc123 -s $SESSION_ID -k $SESSION_KEY -r "Justification insufficient" -m "Please add more context"
+	  slb reject abc123 -s $SESSION_ID -k $SESSION_KEY -r "Keep the cache" --segments 2
 	  slb reject abc123 -s $SESSION_ID -k $SESSION_KEY -r "Too risky" --target-project /path/to/other/project`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -61,6 +76,10 @@ database contains the request you want to reject.
 		if flagRejectReason == "" {
 			return fmt.Errorf("--reason is required for rejections")
 		}
+		segments, err := parseSegmentList(flagRejectSegments)
+		if err != nil {
+			return err
+		}
 
 		// Determine project and database path
 		project, err := projectPath()
@@ -82,6 +101,16 @@ database contains the request you want to reject.
 		}
 		defer dbConn.Close()
 
+		request, err := dbConn.GetRequest(requestID)
+		if err != nil {
+			return fmt.Errorf("getting request: %w", err)
+		}
+		// Attach the evidence this reviewer session viewed
+		evidence, err := core.LoadEvidenceViews(request.ProjectPath, requestID, flagRejectSessionID)
+		if err != nil {
+			return fmt.Errorf("loading evidence views: %w", err)
+		}
+
 		// Build review options - reason goes in comments for rejections
 		comments := flagRejectReason
 		if flagRejectComments != "" {
@@ -93,16 +122,25 @@ database contains the request you want to reject.
 			SessionKey: flagRejectSessionKey,
 			RequestID:  requestID,
 			Decision:   db.DecisionReject,
+			Responses:  db.ReviewResponse{EvidenceViewed: evidence},
 			Comments:   comments,
+			Segments:   segments,
+			CallbackID: flagRejectCallbackID,
 		}
 
 		// Create review service and submit
-		reviewSvc := core.NewReviewService(dbConn, core.DefaultReviewConfig())
+		cfg, err := requestConfig(project, request)
+		if err != nil {
+			return err
+		}
+		reviewSvc := core.NewReviewService(dbConn, toReviewConfig(cfg))
 		reviewSvc.SetNotifier(buildAgentMailNotifier(project))
 		result, err := reviewSvc.SubmitReview(opts)
 		if err != nil {
 			return fmt.Errorf("submitting rejection: %w", err)
 		}
+		_ = core.ClearEvidenceViews(request.ProjectPath, requestID, flagRejectSessionID)
+		broadcastReviewOutcome(request, result)
 
 		// Build output
 		type rejectionResult struct {
@@ -110,11 +148,17 @@ database contains the request you want to reject.
 			RequestID            string `json:"request_id"`
 			Decision             string `json:"decision"`
 			Reason               string `json:"reason"`
+			Segments             []int  `json:"segments,omitempty"`
+			ApprovedSegments     []int  `json:"approved_segments,omitempty"`
 			Approvals            int    `json:"approvals"`
 			Rejections           int    `json:"rejections"`
 			RequestStatusChanged bool   `json:"request_status_changed"`
 			NewRequestStatus     string `json:"new_request_status,omitempty"`
-			CreatedAt            string `json:"created_at"`
+			Replayed             bool   `json:"replayed,omitempty"`
+			// ResolutionApplied is the conflict resolution rule behind the
+			// status change.
+			ResolutionApplied *core.ResolutionApplied `json:"resolution_applied,omitempty"`
+			CreatedAt         string                  `json:"created_at"`
 		}
 
 		resp := rejectionResult{
@@ -122,9 +166,13 @@ database contains the request you want to reject.
 			RequestID:            requestID,
 			Decision:             string(result.Review.Decision),
 			Reason:               flagRejectReason,
+			Segments:             result.Review.Segments,
+			ApprovedSegments:     result.ApprovedSegments,
 			Approvals:            result.Approvals,
 			Rejections:           result.Rejections,
 			RequestStatusChanged: result.RequestStatusChanged,
+			Replayed:             result.Replayed,
+			ResolutionApplied:    result.ResolutionApplied,
 			CreatedAt:            result.Review.CreatedAt.Format(time.RFC3339),
 		}
 
@@ -138,13 +186,23 @@ database contains the request you want to reject.
 		}
 
 		// Human-readable output
+		if resp.Replayed {
+			fmt.Printf("Callback %s was already processed; no new review recorded\n", flagRejectCallbackID)
+		}
 		fmt.Printf("Rejected request %s\n", requestID)
 		fmt.Printf("Review ID: %s\n", resp.ReviewID)
 		fmt.Printf("Reason: %s\n", flagRejectReason)
+		if len(resp.Segments) > 0 {
+			fmt.Printf("Segments rejected: %s\n", formatSegmentList(resp.Segments))
+		}
 		fmt.Printf("Approvals: %d, Rejections: %d\n", resp.Approvals, resp.Rejections)
 
 		if result.RequestStatusChanged {
 			fmt.Printf("Request status changed to: %s\n", resp.NewRequestStatus)
+			printResolutionApplied(result.ResolutionApplied)
+			if len(result.ApprovedSegments) > 0 {
+				fmt.Printf("Request is partially approved: segments %s will execute\n", formatSegmentList(result.ApprovedSegments))
+			}
 		}
 
 		return nil
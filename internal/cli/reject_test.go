@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
 	"github.com/spf13/cobra"
@@ -36,6 +38,7 @@ func newTestRejectCmd(dbPath string) *cobra.Command {
 	reject.Flags().StringVarP(&flagRejectReason, "reason", "r", "", "reason for rejection (required)")
 	reject.Flags().StringVarP(&flagRejectComments, "comments", "m", "", "additional comments")
 	reject.Flags().StringVar(&flagRejectTargetProject, "target-project", "", "target project path for cross-project rejections")
+	reject.Flags().StringVar(&flagRejectCallbackID, "callback-id", "", "callback ID")
 
 	root.AddCommand(reject)
 
@@ -53,6 +56,39 @@ func resetRejectFlags() {
 	flagRejectReason = ""
 	flagRejectComments = ""
 	flagRejectTargetProject = ""
+	flagRejectCallbackID = ""
+}
+
+func TestRejectCommand_AttachesEvidenceViews(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRejectFlags()
+
+	requestorSess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"))
+	reviewerSess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+	req := testutil.MakeRequest(t, h.DB, requestorSess, testutil.WithDryRun("ls ./build", "out.bin"))
+
+	view := core.NewEvidenceView(core.EvidenceSectionDryRun, time.Now(), 2*time.Second)
+	if err := core.RecordEvidenceViews(req.ProjectPath, req.ID, reviewerSess.ID, view); err != nil {
+		t.Fatalf("RecordEvidenceViews: %v", err)
+	}
+
+	cmd := newTestRejectCmd(h.DBPath)
+	if _, err := executeCommandCapture(t, cmd, "reject", req.ID,
+		"-s", reviewerSess.ID, "-k", reviewerSess.SessionKey,
+		"-r", "not convinced", "-C", h.ProjectDir, "-j"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reviews, err := h.DB.ListReviewsForRequest(req.ID)
+	if err != nil || len(reviews) != 1 {
+		t.Fatalf("ListReviewsForRequest: %v (len=%d)", err, len(reviews))
+	}
+	if viewed := reviews[0].Responses.EvidenceViewed; len(viewed) != 1 || viewed[0].Section != core.EvidenceSectionDryRun {
+		t.Errorf("expected dry_run view attached to rejection, got %+v", viewed)
+	}
+	if remaining, _ := core.LoadEvidenceViews(req.ProjectPath, req.ID, reviewerSess.ID); len(remaining) != 0 {
+		t.Errorf("expected local evidence record to be cleared, got %+v", remaining)
+	}
 }
 
 func TestRejectCommand_RequiresRequestID(t *testing.T) {
@@ -181,6 +217,9 @@ func TestRejectCommand_RejectsRequest(t *testing.T) {
 	if result["new_request_status"] != string(db.StatusRejected) {
 		t.Errorf("expected new_request_status=rejected, got %v", result["new_request_status"])
 	}
+	if ra, _ := result["resolution_applied"].(map[string]any); ra["resolution"] != "any_rejection_blocks" || ra["explanation"] != "blocked by a rejection" {
+		t.Errorf("expected resolution_applied for the rejection, got %v", result["resolution_applied"])
+	}
 }
 
 func TestRejectCommand_WithComments(t *testing.T) {
@@ -26,6 +26,12 @@ var (
 	flagRequestAttachFile     []string
 	flagRequestAttachContext  []string
 	flagRequestAttachScreen   []string
+	flagRequestAttachRun      []string
+	flagRequestAttachURL      []string
+	flagRequestDownscale      bool
+	flagRequestAllowBinary    bool
+	flagRequestLabels         []string
+	flagRequestCampaign       string
 )
 
 func init() {
@@ -40,6 +46,12 @@ func init() {
 	requestCmd.Flags().StringSliceVar(&flagRequestAttachFile, "attach-file", nil, "attach file content as context")
 	requestCmd.Flags().StringSliceVar(&flagRequestAttachContext, "attach-context", nil, "run command and attach output as context")
 	requestCmd.Flags().StringSliceVar(&flagRequestAttachScreen, "attach-screenshot", nil, "attach screenshot/image file")
+	requestCmd.Flags().StringSliceVar(&flagRequestAttachRun, "attach-run", nil, "attach the execution output of a previous request (by ID)")
+	requestCmd.Flags().StringSliceVar(&flagRequestAttachURL, "attach-url", nil, "fetch an http(s) URL and attach its content")
+	requestCmd.Flags().BoolVar(&flagRequestDownscale, "downscale-screenshots", false, "shrink screenshots larger than 4096px to fit instead of rejecting them")
+	requestCmd.Flags().BoolVar(&flagRequestAllowBinary, "allow-binary", false, "attach binary --attach files base64-encoded instead of rejecting them")
+	requestCmd.Flags().StringSliceVar(&flagRequestLabels, "label", nil, "label the request (key=value, repeatable)")
+	requestCmd.Flags().StringVar(&flagRequestCampaign, "campaign", "", "add the request to a campaign (see 'slb campaign create')")
 
 	rootCmd.AddCommand(requestCmd)
 }
@@ -73,19 +85,20 @@ Use --execute with --wait to execute after approval.`,
 			return err
 		}
 
+		cwd, err := os.Getwd()
+		if err != nil {
+			cwd = project
+		}
+
 		cfg, err := config.Load(config.LoadOptions{
 			ProjectDir: project,
 			ConfigPath: flagConfig,
+			PolicyDir:  cwd,
 		})
 		if err != nil {
 			return fmt.Errorf("loading config: %w", err)
 		}
 
-		cwd, err := os.Getwd()
-		if err != nil {
-			cwd = project
-		}
-
 		dbConn, err := db.OpenAndMigrate(GetDB())
 		if err != nil {
 			return fmt.Errorf("opening database: %w", err)
@@ -94,18 +107,31 @@ Use --execute with --wait to execute after approval.`,
 
 		// Collect attachments from flags
 		attachments, err := CollectAttachments(cmd.Context(), AttachmentFlags{
-			Files:       flagRequestAttachFile,
-			Contexts:    flagRequestAttachContext,
-			Screenshots: flagRequestAttachScreen,
+			Files:           flagRequestAttachFile,
+			Contexts:        flagRequestAttachContext,
+			Screenshots:     flagRequestAttachScreen,
+			Runs:            flagRequestAttachRun,
+			URLs:            flagRequestAttachURL,
+			Redact:          redactPatterns(cfg, flagRequestRedact),
+			StrictRedaction: cfg.General.StrictRedaction,
+			Downscale:       flagRequestDownscale,
+			AllowBinary:     flagRequestAllowBinary,
+			PathPrivacy:     cfg.General.AttachmentPathPrivacy,
+			ProjectPath:     project,
+			DB:              dbConn,
 		})
 		if err != nil {
 			return fmt.Errorf("collecting attachments: %w", err)
 		}
+		labels, err := db.ParseLabels(flagRequestLabels)
+		if err != nil {
+			return fmt.Errorf("parsing labels: %w", err)
+		}
 
 		// Create the request using the core logic (config-driven rate limits + integrations).
 		rl := core.NewRateLimiter(dbConn, toRateLimitConfig(cfg))
 		creator := core.NewRequestCreator(dbConn, rl, nil, toRequestCreatorConfig(cfg))
-		result, err := creator.CreateRequest(core.CreateRequestOptions{
+		opts := core.CreateRequestOptions{
 			SessionID: flagSessionID,
 			Command:   command,
 			Cwd:       cwd,
@@ -116,9 +142,16 @@ Use --execute with --wait to execute after approval.`,
 				SafetyArgument: flagRequestSafety,
 			},
 			Attachments:    attachments,
-			RedactPatterns: flagRequestRedact,
+			RedactPatterns: redactPatterns(cfg, flagRequestRedact),
+			Labels:         labels,
+			CampaignID:     flagRequestCampaign,
 			ProjectPath:    project,
-		})
+		}
+		// --timeout only applies when waiting for the decision.
+		if flagRequestWait {
+			opts.TimeoutSecs = &flagRequestTimeout
+		}
+		result, err := creator.CreateRequest(opts)
 		if err != nil {
 			return fmt.Errorf("creating request: %w", err)
 		}
@@ -135,7 +168,8 @@ Use --execute with --wait to execute after approval.`,
 			})
 		}
 
-		request := result.Request
+		// Delegations may approve the request on a reviewer's behalf.
+		request := applyDelegations(dbConn, cfg, result.Request)
 
 		// Build response
 		resp := map[string]any{
@@ -154,6 +188,27 @@ Use --execute with --wait to execute after approval.`,
 		if request.ExpiresAt != nil {
 			resp["expires_at"] = request.ExpiresAt.Format(time.RFC3339)
 		}
+		if result.Queue != nil {
+			resp["queue"] = result.Queue
+		}
+		if result.NoopReason != "" {
+			resp["auto_approved_reason"] = result.NoopReason
+		}
+		if result.Trust != nil {
+			resp["trusted_script"] = result.Trust
+			if !result.Trust.Holds() && GetOutput() != "json" {
+				fmt.Fprintf(os.Stderr, "[slb] %s\n", describeVoidedTrust(result.Trust))
+			}
+		}
+		if flagRequestWait {
+			resp["timeout_secs"] = request.TimeoutSecs
+			if request.TimeoutClamped() {
+				resp["timeout_requested_secs"] = request.TimeoutRequestedSecs
+				if GetOutput() != "json" {
+					fmt.Fprintf(os.Stderr, "[slb] %s\n", describeTimeoutClamp(request))
+				}
+			}
+		}
 
 		// If not waiting, return now
 		if !flagRequestWait {
@@ -161,7 +216,7 @@ Use --execute with --wait to execute after approval.`,
 		}
 
 		// Wait for decision with timeout
-		deadline := time.Now().Add(time.Duration(flagRequestTimeout) * time.Second)
+		deadline := time.Now().Add(time.Duration(request.TimeoutSecs) * time.Second)
 		for time.Now().Before(deadline) {
 			request, _, err = dbConn.GetRequestWithReviews(request.ID)
 			if err != nil {
@@ -171,6 +226,15 @@ Use --execute with --wait to execute after approval.`,
 			if request.Status.IsTerminal() || request.Status == db.StatusApproved {
 				break
 			}
+			if request.Status == db.StatusQueued {
+				admitted, err := creator.AdmitQueued(request.RequestorSessionID)
+				if err != nil {
+					return fmt.Errorf("admitting queued requests: %w", err)
+				}
+				for _, r := range admitted {
+					applyDelegations(dbConn, cfg, r)
+				}
+			}
 
 			time.Sleep(500 * time.Millisecond)
 		}
@@ -183,14 +247,25 @@ Use --execute with --wait to execute after approval.`,
 
 		// Execute if approved and --execute was specified
 		if flagRequestExecute && request.Status == db.StatusApproved {
-			executor := core.NewExecutor(dbConn, nil).WithNotifier(buildAgentMailNotifier(project))
+			executor := core.NewExecutor(dbConn, nil).WithNotifier(buildAgentMailNotifier(project)).
+				WithRiskOverrides(toRiskOverrideRules(cfg.RiskOverrides)).
+				WithChangeRecords(cfg.Integrations.ChangeRecordURL, cfg.Integrations.ChangeRecordTiers)
 			execResult, execErr := executor.ExecuteApprovedRequest(context.Background(), core.ExecuteOptions{
-				RequestID:         request.ID,
-				SessionID:         flagSessionID,
-				LogDir:            ".slb/logs",
-				SuppressOutput:    GetOutput() == "json",
-				CaptureRollback:   cfg.General.EnableRollbackCapture,
-				MaxRollbackSizeMB: cfg.General.MaxRollbackSizeMB,
+				RequestID:               request.ID,
+				SessionID:               flagSessionID,
+				LogDir:                  ".slb/logs",
+				SuppressOutput:          GetOutput() == "json",
+				CaptureRollback:         cfg.General.EnableRollbackCapture,
+				RunAllApprovedSegments:  cfg.General.RunAllApprovedSegments,
+				MaxRollbackSizeMB:       cfg.General.MaxRollbackSizeMB,
+				RollbackRootPrefix:      cfg.General.RollbackRootPrefix,
+				MaxRollbackCaptures:     cfg.General.MaxRollbackCaptures,
+				MaxConcurrentCaptures:   cfg.General.MaxConcurrentCaptures,
+				RollbackCaptureWait:     time.Duration(cfg.General.RollbackCaptureWaitSecs) * time.Second,
+				RollbackExclude:         cfg.General.RollbackExclude,
+				MaxConcurrentExecutions: cfg.General.MaxConcurrentExecutions,
+				ExecutionQueueWait:      time.Duration(cfg.General.ExecutionQueueWaitSecs) * time.Second,
+				OnExecutionQueued:       reportExecutionQueued,
 			})
 
 			exitCode := 0
@@ -2,9 +2,13 @@ package cli
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
 	"github.com/spf13/cobra"
@@ -43,6 +47,12 @@ func newTestRequestCmd(dbPath string) *cobra.Command {
 	reqCmd.Flags().StringSliceVar(&flagRequestAttachFile, "attach-file", nil, "attach files")
 	reqCmd.Flags().StringSliceVar(&flagRequestAttachContext, "attach-context", nil, "attach context")
 	reqCmd.Flags().StringSliceVar(&flagRequestAttachScreen, "attach-screenshot", nil, "attach screenshots")
+	reqCmd.Flags().StringSliceVar(&flagRequestAttachRun, "attach-run", nil, "attach prior run output")
+	reqCmd.Flags().StringSliceVar(&flagRequestAttachURL, "attach-url", nil, "attach URLs")
+	reqCmd.Flags().BoolVar(&flagRequestDownscale, "downscale-screenshots", false, "downscale screenshots")
+	reqCmd.Flags().BoolVar(&flagRequestAllowBinary, "allow-binary", false, "allow binary attachments")
+	reqCmd.Flags().StringSliceVar(&flagRequestLabels, "label", nil, "labels")
+	reqCmd.Flags().StringVar(&flagRequestCampaign, "campaign", "", "campaign")
 
 	root.AddCommand(reqCmd)
 
@@ -67,6 +77,12 @@ func resetRequestFlags() {
 	flagRequestAttachFile = nil
 	flagRequestAttachContext = nil
 	flagRequestAttachScreen = nil
+	flagRequestAttachRun = nil
+	flagRequestAttachURL = nil
+	flagRequestDownscale = false
+	flagRequestAllowBinary = false
+	flagRequestLabels = nil
+	flagRequestCampaign = ""
 }
 
 func TestRequestCommand_RequiresCommand(t *testing.T) {
@@ -142,6 +158,70 @@ func TestRequestCommand_CreatesDangerousRequest(t *testing.T) {
 	}
 }
 
+func TestRequestCommand_AppliesDelegation(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRequestFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("TestAgent"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+	d, err := core.NewDelegation(reviewer, h.ProjectDir, "git reset --hard*", db.RiskTierDangerous, time.Hour, "", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DB.CreateDelegation(d); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, err := executeCommandCapture(t, newTestRequestCmd(h.DBPath), "request", "git reset --hard HEAD~1",
+		"-s", sess.ID, "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if result["status"] != string(db.StatusApproved) {
+		t.Fatalf("expected the delegation to approve the request, got %v", result["status"])
+	}
+	reviews, err := h.DB.ListReviewsForRequest(result["request_id"].(string))
+	if err != nil || len(reviews) != 1 || reviews[0].ReviewerAgent != "Reviewer" || reviews[0].DelegationID != d.ID {
+		t.Errorf("expected one review by Reviewer under %s, got %+v, %v", d.ID, reviews, err)
+	}
+}
+
+func TestRequestCommand_Campaign(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRequestFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	campaign := &db.Campaign{ProjectPath: h.ProjectDir, Name: "cleanup"}
+	if err := h.DB.CreateCampaign(campaign); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, err := executeCommandCapture(t, newTestRequestCmd(h.DBPath), "request", "rm -rf ./build",
+		"-s", sess.ID, "-C", h.ProjectDir, "--campaign", campaign.ID, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	req, err := h.DB.GetRequest(result["request_id"].(string))
+	if err != nil || req.CampaignID != campaign.ID {
+		t.Errorf("expected the request to join the campaign, got %q, %v", req.CampaignID, err)
+	}
+
+	resetRequestFlags()
+	_, err = executeCommandCapture(t, newTestRequestCmd(h.DBPath), "request", "rm -rf ./build",
+		"-s", sess.ID, "-C", h.ProjectDir, "--campaign", "missing")
+	if err == nil || !strings.Contains(err.Error(), "campaign not found") {
+		t.Errorf("expected an unknown campaign to be refused, got %v", err)
+	}
+}
+
 func TestRequestCommand_WithJustification(t *testing.T) {
 	h := testutil.NewHarness(t)
 	resetRequestFlags()
@@ -319,3 +399,50 @@ func TestRequestCommand_WithRedaction(t *testing.T) {
 		}
 	}
 }
+
+func TestRequestCommand_AttachRun(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRequestFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	failed := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("make migrate", h.ProjectDir, true),
+		testutil.WithStatus(db.StatusExecutionFailed))
+	logPath := filepath.Join(t.TempDir(), "exec.log")
+	if err := os.WriteFile(logPath, []byte("ERROR: relation \"users\" already exists\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DB.UpdateRequestExecution(failed.ID, &db.Execution{LogPath: logPath}); err != nil {
+		t.Fatalf("UpdateRequestExecution: %v", err)
+	}
+
+	cmd := newTestRequestCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "request", "rm -rf ./migrations/0042",
+		"-s", sess.ID, "-C", h.ProjectDir, "-j", "--attach-run", failed.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	created, err := h.DB.GetRequest(result["request_id"].(string))
+	if err != nil {
+		t.Fatalf("GetRequest: %v", err)
+	}
+	if len(created.Attachments) != 1 || !strings.Contains(created.Attachments[0].Content, "already exists") {
+		t.Fatalf("expected the prior run's output attached, got %+v", created.Attachments)
+	}
+	if created.Attachments[0].Metadata["request_id"] != failed.ID {
+		t.Errorf("expected attachment to reference %s, got %v", failed.ID, created.Attachments[0].Metadata)
+	}
+
+	resetRequestFlags()
+	pending := testutil.MakeRequest(t, h.DB, sess)
+	cmd = newTestRequestCmd(h.DBPath)
+	_, err = executeCommandCapture(t, cmd, "request", "rm -rf ./build",
+		"-s", sess.ID, "-C", h.ProjectDir, "-j", "--attach-run", pending.ID)
+	if err == nil || !strings.Contains(err.Error(), "has not been executed") {
+		t.Fatalf("expected a not-executed error, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package cli implements the rerequest command.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(rerequestCmd)
+}
+
+var rerequestCmd = &cobra.Command{
+	Use:   "rerequest <request-id>",
+	Short: "Send a request whose approval expired back for review",
+	Long: `Send a request whose approval expired before execution back for review.
+
+The request keeps its justification, attachments and earlier reviews and
+returns to pending for a new review round. Reviews from earlier rounds stay
+visible but no longer count toward the quorum. The command must still hash to
+what was approved; the reviewers who approved it are asked to re-confirm and
+can do so with a single 'slb approve'.
+
+Only the requesting agent can re-request review. Use --session-id/-s to give
+an active session of that agent in the request's project.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		requestID := args[0]
+
+		if flagSessionID == "" {
+			return fmt.Errorf("--session-id is required to re-request review")
+		}
+
+		dbConn, err := db.Open(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		creator, _, err := newProjectRequestCreator(dbConn)
+		if err != nil {
+			return err
+		}
+		result, err := creator.Rerequest(core.RerequestOptions{
+			RequestID: requestID,
+			SessionID: flagSessionID,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot re-request review: %w", err)
+		}
+		broadcastRerequest(result)
+
+		request := result.Request
+		approvers := make([]string, 0, len(result.PriorApprovals))
+		for _, r := range result.PriorApprovals {
+			approvers = append(approvers, r.ReviewerAgent)
+		}
+
+		out := output.New(output.Format(GetOutput()))
+		if GetOutput() == "json" {
+			resp := map[string]any{
+				"request_id":      request.ID,
+				"status":          string(request.Status),
+				"review_round":    request.ReviewRound,
+				"prior_approvers": approvers,
+			}
+			if request.ExpiresAt != nil {
+				resp["expires_at"] = request.ExpiresAt.Format(time.RFC3339)
+			}
+			return out.Write(resp)
+		}
+
+		fmt.Printf("Request %s is pending again (review round %d)\n", request.ID, request.ReviewRound)
+		if len(approvers) > 0 {
+			fmt.Printf("Asked to re-confirm: %v\n", approvers)
+		}
+		if request.ExpiresAt != nil {
+			fmt.Printf("Expires: %s\n", request.ExpiresAt.Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+// broadcastRerequest tells a running daemon that a request is back up for
+// review. It is best effort, like broadcastReviewOutcome.
+func broadcastRerequest(result *core.RerequestResult) {
+	if !daemon.NewClient().IsDaemonRunning() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client := daemon.NewIPCClient(daemon.DefaultSocketPath())
+	defer client.Close()
+	_ = client.Notify(ctx, "request_rerequested", daemon.RerequestEvent(result.Request, result.PriorApprovals))
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestRerequestCmd creates a fresh rerequest command for testing.
+func newTestRerequestCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
+
+	root.AddCommand(rerequestCmd)
+
+	return root
+}
+
+func TestRerequestCommand_RequiresSessionID(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetCancelFlags()
+
+	cmd := newTestRerequestCmd(h.DBPath)
+	_, _, err := executeCommand(cmd, "rerequest", "some-request-id")
+	if err == nil || !strings.Contains(err.Error(), "--session-id is required") {
+		t.Fatalf("expected a --session-id error, got %v", err)
+	}
+}
+
+func TestRerequestCommand_ReopensExpiredApproval(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetCancelFlags()
+
+	sess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("TestAgent"),
+	)
+	req := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -rf ./build", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierDangerous),
+		testutil.WithStatus(db.StatusApproved),
+	)
+
+	cmd := newTestRerequestCmd(h.DBPath)
+	if _, err := executeCommandCapture(t, cmd, "rerequest", req.ID, "-s", sess.ID, "-C", h.ProjectDir, "-j"); err == nil {
+		t.Fatal("expected an approved request to be refused")
+	}
+
+	if err := h.DB.UpdateRequestStatus(req.ID, db.StatusApprovalExpired); err != nil {
+		t.Fatal(err)
+	}
+	resetCancelFlags()
+	cmd = newTestRerequestCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "rerequest", req.ID, "-s", sess.ID, "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if result["status"] != string(db.StatusPending) || result["review_round"] != float64(1) {
+		t.Errorf("expected pending in round 1, got %v", result)
+	}
+
+	updated, err := h.DB.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("failed to get request: %v", err)
+	}
+	if updated.Status != db.StatusPending || updated.ReviewRound != 1 {
+		t.Errorf("expected pending in round 1, got %s in round %d", updated.Status, updated.ReviewRound)
+	}
+}
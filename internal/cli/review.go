@@ -2,10 +2,13 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/output"
 	"github.com/spf13/cobra"
@@ -14,12 +17,27 @@ import (
 var (
 	flagReviewAll  bool
 	flagReviewPool bool
+
+	// Batch mode flags
+	flagReviewDecision   string
+	flagReviewAllPending bool
+	flagReviewTier       string
+	flagReviewSessionID  string
+	flagReviewSessionKey string
+	flagReviewComments   string
 )
 
 func init() {
 	reviewCmd.PersistentFlags().BoolVarP(&flagReviewAll, "all", "a", false, "show requests from all projects")
 	reviewCmd.PersistentFlags().BoolVar(&flagReviewPool, "review-pool", false, "show requests from configured review pool (cross-project)")
 
+	reviewCmd.Flags().StringVar(&flagReviewDecision, "decision", "", "approve or reject the given requests in one batch")
+	reviewCmd.Flags().BoolVar(&flagReviewAllPending, "all-pending", false, "batch over every pending request (honors --all and --review-pool)")
+	reviewCmd.Flags().StringVar(&flagReviewTier, "tier", "", "only review requests of this risk tier (critical, dangerous, caution)")
+	reviewCmd.Flags().StringVarP(&flagReviewSessionID, "session-id", "s", "", "reviewer session ID (required with --decision)")
+	reviewCmd.Flags().StringVarP(&flagReviewSessionKey, "session-key", "k", "", "session HMAC key for signing (required with --decision)")
+	reviewCmd.Flags().StringVarP(&flagReviewComments, "comments", "m", "", "comments for every review; required as the reason when rejecting")
+
 	reviewCmd.AddCommand(reviewListCmd)
 	reviewCmd.AddCommand(reviewShowCmd)
 
@@ -34,9 +52,27 @@ var reviewCmd = &cobra.Command{
 If a request ID is provided, shows full details including command, justification,
 risk tier, and any existing reviews.
 
-Use 'slb review list' to see all pending requests.`,
-	Args: cobra.MaximumNArgs(1),
+Use 'slb review list' to see all pending requests.
+
+With --decision, approves or rejects several requests in one invocation:
+the given request IDs, or every pending request with --all-pending. --tier
+restricts the batch to one risk tier, so a sweep cannot approve CRITICAL
+requests by accident. Each request is reviewed independently; a summary
+table lists which were approved, rejected or failed (and why). The command
+exits non-zero only if every request in the batch failed.
+
+	Examples:
+	  slb review req-1 req-2 req-3 --decision approve -s $SESSION_ID -k $SESSION_KEY
+	  slb review --all-pending --decision reject -m "sweep" -s $SESSION_ID -k $SESSION_KEY
+	  slb review --all-pending --tier caution --decision approve -s $SESSION_ID -k $SESSION_KEY`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagReviewDecision != "" || flagReviewAllPending {
+			return runBatchReview(args)
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("accepts at most 1 request ID without --decision, received %d", len(args))
+		}
 		if len(args) == 0 {
 			// No ID provided, show list of pending
 			return reviewListCmd.RunE(cmd, args)
@@ -69,17 +105,7 @@ var reviewListCmd = &cobra.Command{
 		}
 		defer dbConn.Close()
 
-		var requests []*db.Request
-		if flagReviewAll {
-			requests, err = dbConn.ListPendingRequestsAllProjects()
-		} else {
-			if flagReviewPool && cfg.General.CrossProjectReviews && len(cfg.General.ReviewPool) > 0 {
-				paths := dedupeStrings(append([]string{project}, cfg.General.ReviewPool...))
-				requests, err = dbConn.ListPendingRequestsByProjects(paths)
-			} else {
-				requests, err = dbConn.ListPendingRequests(project)
-			}
-		}
+		requests, err := listReviewablePending(dbConn, project, cfg)
 		if err != nil {
 			return fmt.Errorf("listing requests: %w", err)
 		}
@@ -134,6 +160,45 @@ var reviewListCmd = &cobra.Command{
 	},
 }
 
+// listReviewablePending returns the pending requests a reviewer sees: this
+// project's, every project's with --all, or the review pool's with
+// --review-pool when cross-project reviews are enabled.
+func listReviewablePending(dbConn *db.DB, project string, cfg config.Config) ([]*db.Request, error) {
+	if flagReviewAll {
+		return dbConn.ListPendingRequestsAllProjects()
+	}
+	if flagReviewPool && cfg.General.CrossProjectReviews && len(cfg.General.ReviewPool) > 0 {
+		paths := dedupeStrings(append([]string{project}, cfg.General.ReviewPool...))
+		return dbConn.ListPendingRequestsByProjects(paths)
+	}
+	return dbConn.ListPendingRequests(project)
+}
+
+// reviewServiceForViewer returns a review service under request's project
+// config whose review listings are for the invoking session (--session-id).
+// Without a session the viewer is unknown and, with
+// general.anonymize_reviewers, sees pseudonyms until the request resolves.
+func reviewServiceForViewer(dbConn *db.DB, request *db.Request) (*core.ReviewService, error) {
+	cfg, err := requestConfig(request.ProjectPath, request)
+	if err != nil {
+		return nil, err
+	}
+	svc := core.NewReviewService(dbConn, toReviewConfig(cfg))
+	svc.SetViewer(core.ResolveReviewViewer(dbConn, flagSessionID))
+	return svc, nil
+}
+
+// reviewsForViewer returns the reviews of request as the invoking session
+// may see them (see core.ReviewService.ReviewsForViewer).
+func reviewsForViewer(dbConn *db.DB, request *db.Request, reviews []*db.Review) ([]*db.Review, map[string]string, error) {
+	svc, err := reviewServiceForViewer(dbConn, request)
+	if err != nil {
+		return nil, nil, err
+	}
+	shown, names := svc.ReviewsForViewer(request, reviews)
+	return shown, names, nil
+}
+
 var reviewShowCmd = &cobra.Command{
 	Use:   "show <request-id>",
 	Short: "Show full details of a request",
@@ -155,9 +220,13 @@ func showRequestDetails(requestID string) error {
 		return fmt.Errorf("getting request: %w", err)
 	}
 
-	// Count approvals and rejections
+	// Count approvals and rejections; reviews from a round whose approval
+	// expired stay listed but no longer count
 	var approvals, rejections int
 	for _, rev := range reviews {
+		if rev.Round != request.ReviewRound {
+			continue
+		}
 		switch rev.Decision {
 		case db.DecisionApprove:
 			approvals++
@@ -166,6 +235,28 @@ func showRequestDetails(requestID string) error {
 		}
 	}
 
+	// Same-host approvals don't count when the request requires host
+	// diversity, and required roles are checked against the configured
+	// reviewer roles. The requestor may only see pseudonyms while the
+	// request is pending.
+	svc, err := reviewServiceForViewer(dbConn, request)
+	if err != nil {
+		return err
+	}
+	var uncounted, missingRoles []string
+	if request.RequireDifferentHost || len(request.RequiredRoles) > 0 {
+		status, err := svc.GetReviewStatus(requestID)
+		if err != nil {
+			return fmt.Errorf("getting review status: %w", err)
+		}
+		if request.RequireDifferentHost {
+			approvals = status.Approvals
+			uncounted = status.UncountedApprovals
+		}
+		missingRoles = status.MissingRoles
+	}
+	reviews, _ = svc.ReviewsForViewer(request, reviews)
+
 	// Build output structure
 	type reviewView struct {
 		ID            string `json:"id"`
@@ -174,31 +265,38 @@ func showRequestDetails(requestID string) error {
 		Decision      string `json:"decision"`
 		Comments      string `json:"comments,omitempty"`
 		CreatedAt     string `json:"created_at"`
+		Superseded    bool   `json:"superseded,omitempty"`
 	}
 
 	type requestDetail struct {
-		ID                    string       `json:"id"`
-		Status                string       `json:"status"`
-		RiskTier              string       `json:"risk_tier"`
-		Command               string       `json:"command"`
-		CommandHash           string       `json:"command_hash"`
-		Cwd                   string       `json:"cwd"`
-		ProjectPath           string       `json:"project_path"`
-		RequestorAgent        string       `json:"requestor_agent"`
-		RequestorModel        string       `json:"requestor_model"`
-		JustificationReason   string       `json:"justification_reason"`
-		JustificationEffect   string       `json:"justification_expected_effect,omitempty"`
-		JustificationGoal     string       `json:"justification_goal,omitempty"`
-		JustificationSafety   string       `json:"justification_safety_argument,omitempty"`
-		MinApprovals          int          `json:"min_approvals"`
-		CurrentApprovals      int          `json:"current_approvals"`
-		CurrentRejections     int          `json:"current_rejections"`
-		RequireDifferentModel bool         `json:"require_different_model"`
-		Reviews               []reviewView `json:"reviews,omitempty"`
-		DryRunCommand         string       `json:"dry_run_command,omitempty"`
-		DryRunOutput          string       `json:"dry_run_output,omitempty"`
-		CreatedAt             string       `json:"created_at"`
-		ExpiresAt             string       `json:"expires_at,omitempty"`
+		ID                      string       `json:"id"`
+		Status                  string       `json:"status"`
+		RiskTier                string       `json:"risk_tier"`
+		Command                 string       `json:"command"`
+		CommandHash             string       `json:"command_hash"`
+		Cwd                     string       `json:"cwd"`
+		TargetContext           string       `json:"target_context,omitempty"`
+		ProjectPath             string       `json:"project_path"`
+		RequestorAgent          string       `json:"requestor_agent"`
+		RequestorModel          string       `json:"requestor_model"`
+		JustificationReason     string       `json:"justification_reason"`
+		JustificationEffect     string       `json:"justification_expected_effect,omitempty"`
+		JustificationGoal       string       `json:"justification_goal,omitempty"`
+		JustificationSafety     string       `json:"justification_safety_argument,omitempty"`
+		MinApprovals            int          `json:"min_approvals"`
+		CurrentApprovals        int          `json:"current_approvals"`
+		CurrentRejections       int          `json:"current_rejections"`
+		RequireDifferentModel   bool         `json:"require_different_model"`
+		RequireDifferentHost    bool         `json:"require_different_host,omitempty"`
+		RequireDifferentProgram bool         `json:"require_different_program,omitempty"`
+		UncountedApprovals      []string     `json:"uncounted_approvals,omitempty"`
+		RequiredRoles           []string     `json:"required_roles,omitempty"`
+		MissingRoles            []string     `json:"missing_roles,omitempty"`
+		Reviews                 []reviewView `json:"reviews,omitempty"`
+		DryRunCommand           string       `json:"dry_run_command,omitempty"`
+		DryRunOutput            string       `json:"dry_run_output,omitempty"`
+		CreatedAt               string       `json:"created_at"`
+		ExpiresAt               string       `json:"expires_at,omitempty"`
 	}
 
 	// Build command display
@@ -208,24 +306,30 @@ func showRequestDetails(requestID string) error {
 	}
 
 	detail := requestDetail{
-		ID:                    request.ID,
-		Status:                string(request.Status),
-		RiskTier:              string(request.RiskTier),
-		Command:               cmd,
-		CommandHash:           request.Command.Hash,
-		Cwd:                   request.Command.Cwd,
-		ProjectPath:           request.ProjectPath,
-		RequestorAgent:        request.RequestorAgent,
-		RequestorModel:        request.RequestorModel,
-		JustificationReason:   request.Justification.Reason,
-		JustificationEffect:   request.Justification.ExpectedEffect,
-		JustificationGoal:     request.Justification.Goal,
-		JustificationSafety:   request.Justification.SafetyArgument,
-		MinApprovals:          request.MinApprovals,
-		CurrentApprovals:      approvals,
-		CurrentRejections:     rejections,
-		RequireDifferentModel: request.RequireDifferentModel,
-		CreatedAt:             request.CreatedAt.Format(time.RFC3339),
+		ID:                      request.ID,
+		Status:                  string(request.Status),
+		RiskTier:                string(request.RiskTier),
+		Command:                 cmd,
+		CommandHash:             request.Command.Hash,
+		Cwd:                     request.Command.Cwd,
+		TargetContext:           core.DescribePinnedContext(request.PinnedContext),
+		ProjectPath:             request.ProjectPath,
+		RequestorAgent:          request.RequestorAgent,
+		RequestorModel:          request.RequestorModel,
+		JustificationReason:     request.Justification.Reason,
+		JustificationEffect:     request.Justification.ExpectedEffect,
+		JustificationGoal:       request.Justification.Goal,
+		JustificationSafety:     request.Justification.SafetyArgument,
+		MinApprovals:            request.MinApprovals,
+		CurrentApprovals:        approvals,
+		CurrentRejections:       rejections,
+		RequireDifferentModel:   request.RequireDifferentModel,
+		RequireDifferentHost:    request.RequireDifferentHost,
+		RequireDifferentProgram: request.RequireDifferentProgram,
+		UncountedApprovals:      uncounted,
+		RequiredRoles:           request.RequiredRoles,
+		MissingRoles:            missingRoles,
+		CreatedAt:               request.CreatedAt.Format(time.RFC3339),
 	}
 
 	if request.ExpiresAt != nil {
@@ -246,6 +350,7 @@ func showRequestDetails(requestID string) error {
 			Decision:      string(rev.Decision),
 			Comments:      rev.Comments,
 			CreatedAt:     rev.CreatedAt.Format(time.RFC3339),
+			Superseded:    rev.Round != request.ReviewRound,
 		})
 	}
 
@@ -262,6 +367,9 @@ func showRequestDetails(requestID string) error {
 	fmt.Printf("Command: %s\n", detail.Command)
 	fmt.Printf("Hash:    %s\n", detail.CommandHash)
 	fmt.Printf("CWD:     %s\n", detail.Cwd)
+	if detail.TargetContext != "" {
+		fmt.Printf("Context: %s (pinned for execution)\n", detail.TargetContext)
+	}
 	fmt.Println()
 	fmt.Printf("Requestor: %s (%s)\n", detail.RequestorAgent, detail.RequestorModel)
 	fmt.Println()
@@ -284,6 +392,21 @@ func showRequestDetails(requestID string) error {
 	if detail.RequireDifferentModel {
 		fmt.Println("Note: Requires approval from a different model")
 	}
+	if detail.RequireDifferentHost {
+		fmt.Println("Note: Requires approval from a session on a different host")
+	}
+	if detail.RequireDifferentProgram {
+		fmt.Println("Note: Requires approval from a session running a different program")
+	}
+	if len(detail.RequiredRoles) > 0 {
+		fmt.Printf("Note: Requires an approval from each role: %s\n", strings.Join(detail.RequiredRoles, ", "))
+		if len(detail.MissingRoles) > 0 {
+			fmt.Printf("Missing roles: %s\n", strings.Join(detail.MissingRoles, ", "))
+		}
+	}
+	for _, reason := range detail.UncountedApprovals {
+		fmt.Printf("Note: %s\n", reason)
+	}
 
 	if detail.DryRunCommand != "" {
 		fmt.Println()
@@ -316,3 +439,211 @@ func showRequestDetails(requestID string) error {
 
 	return nil
 }
+
+// batchReviewResult is the outcome of one request in a batch review.
+type batchReviewResult struct {
+	RequestID        string `json:"request_id"`
+	RiskTier         string `json:"risk_tier,omitempty"`
+	Outcome          string `json:"outcome"` // approved, rejected or failed
+	NewRequestStatus string `json:"new_request_status,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+}
+
+// runBatchReview submits the same decision for every request in args, or
+// for every pending request with --all-pending. Failures are collected per
+// request; the command only fails if nothing in the batch succeeded.
+func runBatchReview(args []string) error {
+	var decision db.Decision
+	switch flagReviewDecision {
+	case "approve":
+		decision = db.DecisionApprove
+	case "reject":
+		decision = db.DecisionReject
+	case "":
+		return fmt.Errorf("--decision is required with --all-pending")
+	default:
+		return fmt.Errorf("invalid --decision %q: must be approve or reject", flagReviewDecision)
+	}
+	if flagReviewAllPending && len(args) > 0 {
+		return fmt.Errorf("pass request IDs or --all-pending, not both")
+	}
+	if !flagReviewAllPending && len(args) == 0 {
+		return fmt.Errorf("request IDs or --all-pending required with --decision")
+	}
+	if flagReviewSessionID == "" {
+		return fmt.Errorf("--session-id is required")
+	}
+	if flagReviewSessionKey == "" {
+		return fmt.Errorf("--session-key is required")
+	}
+	if decision == db.DecisionReject && flagReviewComments == "" {
+		return fmt.Errorf("--comments is required as the reason when rejecting")
+	}
+	tier := db.RiskTier(strings.ToLower(flagReviewTier))
+	if tier != "" && !tier.Valid() {
+		return fmt.Errorf("invalid --tier %q: must be critical, dangerous or caution", flagReviewTier)
+	}
+
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(config.LoadOptions{
+		ProjectDir: project,
+		ConfigPath: flagConfig,
+	})
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	var requests []*db.Request
+	var results []batchReviewResult
+	if flagReviewAllPending {
+		pending, err := listReviewablePending(dbConn, project, cfg)
+		if err != nil {
+			return fmt.Errorf("listing requests: %w", err)
+		}
+		for _, r := range pending {
+			if tier == "" || r.RiskTier == tier {
+				requests = append(requests, r)
+			}
+		}
+	} else {
+		for _, id := range dedupeStrings(args) {
+			r, err := dbConn.GetRequest(id)
+			if err != nil {
+				results = append(results, batchReviewResult{RequestID: id, Outcome: "failed", Reason: err.Error()})
+				continue
+			}
+			if tier != "" && r.RiskTier != tier {
+				results = append(results, batchReviewResult{
+					RequestID: id,
+					RiskTier:  string(r.RiskTier),
+					Outcome:   "failed",
+					Reason:    fmt.Sprintf("risk tier %s does not match --tier %s", r.RiskTier, tier),
+				})
+				continue
+			}
+			requests = append(requests, r)
+		}
+	}
+
+	// Each request is reviewed under the project policy above its own
+	// working directory.
+	notifier := buildAgentMailNotifier(project)
+	for _, request := range requests {
+		reqCfg, err := requestConfig(project, request)
+		if err != nil {
+			results = append(results, batchReviewResult{
+				RequestID: request.ID,
+				RiskTier:  string(request.RiskTier),
+				Outcome:   "failed",
+				Reason:    err.Error(),
+			})
+			continue
+		}
+		reviewSvc := core.NewReviewService(dbConn, toReviewConfig(reqCfg))
+		reviewSvc.SetNotifier(notifier)
+		results = append(results, submitBatchReview(reviewSvc, request, decision))
+	}
+
+	summary := struct {
+		Decision string              `json:"decision"`
+		Approved int                 `json:"approved"`
+		Rejected int                 `json:"rejected"`
+		Failed   int                 `json:"failed"`
+		Results  []batchReviewResult `json:"results"`
+	}{Decision: string(decision), Results: results}
+	for _, r := range results {
+		switch r.Outcome {
+		case "approved":
+			summary.Approved++
+		case "rejected":
+			summary.Rejected++
+		default:
+			summary.Failed++
+		}
+	}
+	if summary.Results == nil {
+		summary.Results = []batchReviewResult{}
+	}
+
+	if GetOutput() == "json" {
+		if err := output.New(output.Format(GetOutput())).Write(summary); err != nil {
+			return err
+		}
+	} else if len(results) == 0 {
+		fmt.Println("No pending requests found.")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "REQUEST\tTIER\tRESULT\tDETAIL")
+		for _, r := range results {
+			detail := r.Reason
+			if r.NewRequestStatus != "" {
+				detail = "request " + r.NewRequestStatus
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.RequestID, r.RiskTier, r.Outcome, detail)
+		}
+		w.Flush()
+		fmt.Println()
+		fmt.Printf("Approved: %d, Rejected: %d, Failed: %d\n", summary.Approved, summary.Rejected, summary.Failed)
+	}
+
+	if len(results) > 0 && summary.Failed == len(results) {
+		return fmt.Errorf("all %d reviews in the batch failed", len(results))
+	}
+	return nil
+}
+
+// submitBatchReview reviews one request of a batch. Approvals follow the
+// same evidence rules as 'slb approve', without a way to acknowledge
+// unviewed evidence on CRITICAL requests.
+func submitBatchReview(reviewSvc *core.ReviewService, request *db.Request, decision db.Decision) batchReviewResult {
+	res := batchReviewResult{RequestID: request.ID, RiskTier: string(request.RiskTier), Outcome: "failed"}
+
+	evidence, err := core.LoadEvidenceViews(request.ProjectPath, request.ID, flagReviewSessionID)
+	if err != nil {
+		res.Reason = fmt.Sprintf("loading evidence views: %v", err)
+		return res
+	}
+	if decision == db.DecisionApprove {
+		unviewed, err := reviewSvc.CheckUnviewedEvidence(request, evidence, false)
+		if err != nil {
+			res.Reason = err.Error()
+			return res
+		}
+		if len(unviewed) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: approving %s without viewing evidence: %s\n", request.ID, strings.Join(unviewed, ", "))
+		}
+	}
+
+	result, err := reviewSvc.SubmitReview(core.ReviewOptions{
+		SessionID:  flagReviewSessionID,
+		SessionKey: flagReviewSessionKey,
+		RequestID:  request.ID,
+		Decision:   decision,
+		Responses:  db.ReviewResponse{EvidenceViewed: evidence},
+		Comments:   flagReviewComments,
+	})
+	if err != nil {
+		res.Reason = err.Error()
+		return res
+	}
+	_ = core.ClearEvidenceViews(request.ProjectPath, request.ID, flagReviewSessionID)
+	broadcastReviewOutcome(request, result)
+
+	res.Outcome = "approved"
+	if decision == db.DecisionReject {
+		res.Outcome = "rejected"
+	}
+	if result.RequestStatusChanged {
+		res.NewRequestStatus = string(result.NewRequestStatus)
+	}
+	return res
+}
@@ -28,11 +28,17 @@ func newTestReviewCmd(dbPath string) *cobra.Command {
 	revCmd := &cobra.Command{
 		Use:   "review [request-id]",
 		Short: "View request details for review",
-		Args:  cobra.MaximumNArgs(1),
+		Args:  cobra.ArbitraryArgs,
 		RunE:  reviewCmd.RunE,
 	}
 	revCmd.PersistentFlags().BoolVarP(&flagReviewAll, "all", "a", false, "show requests from all projects")
 	revCmd.PersistentFlags().BoolVar(&flagReviewPool, "review-pool", false, "show requests from configured review pool")
+	revCmd.Flags().StringVar(&flagReviewDecision, "decision", "", "approve or reject the given requests")
+	revCmd.Flags().BoolVar(&flagReviewAllPending, "all-pending", false, "batch over every pending request")
+	revCmd.Flags().StringVar(&flagReviewTier, "tier", "", "only review requests of this risk tier")
+	revCmd.Flags().StringVarP(&flagReviewSessionID, "session-id", "s", "", "reviewer session ID")
+	revCmd.Flags().StringVarP(&flagReviewSessionKey, "session-key", "k", "", "session HMAC key")
+	revCmd.Flags().StringVarP(&flagReviewComments, "comments", "m", "", "comments for every review")
 
 	listCmd := &cobra.Command{
 		Use:   "list",
@@ -62,6 +68,12 @@ func resetReviewFlags() {
 	flagConfig = ""
 	flagReviewAll = false
 	flagReviewPool = false
+	flagReviewDecision = ""
+	flagReviewAllPending = false
+	flagReviewTier = ""
+	flagReviewSessionID = ""
+	flagReviewSessionKey = ""
+	flagReviewComments = ""
 }
 
 func TestReviewListCommand_ListsPendingRequests(t *testing.T) {
@@ -260,6 +272,56 @@ func TestReviewShowCommand_IncludesReviews(t *testing.T) {
 	}
 }
 
+func TestReviewShowCommand_SameHostApprovalNotCounted(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetReviewFlags()
+
+	requestorSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Requestor"),
+		testutil.WithHost("build-01", "m-01"),
+	)
+	reviewerSess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("Neighbor"),
+		testutil.WithHost("build-01", "m-01"),
+	)
+	req := testutil.MakeRequest(t, h.DB, requestorSess,
+		testutil.WithCommand("rm -rf ./build", h.ProjectDir, true),
+		testutil.WithRequireDifferentHost(true),
+	)
+	if err := h.DB.CreateReview(&db.Review{
+		RequestID:         req.ID,
+		ReviewerSessionID: reviewerSess.ID,
+		ReviewerAgent:     reviewerSess.AgentName,
+		ReviewerModel:     reviewerSess.Model,
+		Decision:          db.DecisionApprove,
+	}); err != nil {
+		t.Fatalf("failed to create review: %v", err)
+	}
+
+	cmd := newTestReviewCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "review", "show", req.ID, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if result["current_approvals"] != float64(0) {
+		t.Errorf("expected current_approvals=0, got %v", result["current_approvals"])
+	}
+	if result["require_different_host"] != true {
+		t.Errorf("expected require_different_host=true, got %v", result["require_different_host"])
+	}
+	uncounted, ok := result["uncounted_approvals"].([]any)
+	if !ok || len(uncounted) != 1 || !strings.Contains(uncounted[0].(string), "Neighbor") {
+		t.Errorf("expected one uncounted approval by Neighbor, got %v", result["uncounted_approvals"])
+	}
+}
+
 func TestReviewCommand_NoArgs_ShowsList(t *testing.T) {
 	h := testutil.NewHarness(t)
 	resetReviewFlags()
@@ -744,3 +806,131 @@ func TestReviewShowCommand_TextOutputWithSafetyArgument(t *testing.T) {
 		t.Error("expected text output to contain 'Safety Argument:'")
 	}
 }
+
+// batchReviewSummary mirrors the JSON written by a batch review.
+type batchReviewSummary struct {
+	Decision string              `json:"decision"`
+	Approved int                 `json:"approved"`
+	Rejected int                 `json:"rejected"`
+	Failed   int                 `json:"failed"`
+	Results  []batchReviewResult `json:"results"`
+}
+
+func TestReviewCommand_BatchApproveCollectsFailures(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetReviewFlags()
+
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"), testutil.WithModel("model-a"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"), testutil.WithModel("model-b"))
+
+	req1 := testutil.MakeRequest(t, h.DB, requestor, testutil.WithCommand("rm -rf ./build", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierDangerous), testutil.WithMinApprovals(1), testutil.WithRequireDifferentModel(false))
+	req2 := testutil.MakeRequest(t, h.DB, requestor, testutil.WithCommand("rm -rf ./dist", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierDangerous), testutil.WithMinApprovals(1), testutil.WithRequireDifferentModel(false))
+	own := testutil.MakeRequest(t, h.DB, reviewer, testutil.WithCommand("rm -rf ./tmp", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierDangerous))
+
+	cmd := newTestReviewCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "review", req1.ID, req2.ID, own.ID, "missing-id",
+		"--decision", "approve", "-s", reviewer.ID, "-k", reviewer.SessionKey, "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var summary batchReviewSummary
+	if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if summary.Approved != 2 || summary.Failed != 2 || len(summary.Results) != 4 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	byID := map[string]batchReviewResult{}
+	for _, r := range summary.Results {
+		byID[r.RequestID] = r
+	}
+	if r := byID[req1.ID]; r.Outcome != "approved" || r.NewRequestStatus != string(db.StatusApproved) {
+		t.Errorf("req1 result = %+v", r)
+	}
+	if r := byID[own.ID]; r.Outcome != "failed" || !strings.Contains(r.Reason, "own request") {
+		t.Errorf("self-review result = %+v", r)
+	}
+	if r := byID["missing-id"]; r.Outcome != "failed" {
+		t.Errorf("missing request result = %+v", r)
+	}
+
+	// Reviewing the same requests again fails every one, so the command fails.
+	resetReviewFlags()
+	cmd = newTestReviewCmd(h.DBPath)
+	stdout, err = executeCommandCapture(t, cmd, "review", req1.ID, own.ID,
+		"--decision", "approve", "-s", reviewer.ID, "-k", reviewer.SessionKey, "-C", h.ProjectDir)
+	if err == nil || !strings.Contains(err.Error(), "all 2 reviews") {
+		t.Fatalf("expected the all-failed batch to error, got %v", err)
+	}
+	if !strings.Contains(stdout, "Approved: 0, Rejected: 0, Failed: 2") {
+		t.Errorf("expected summary line, got:\n%s", stdout)
+	}
+}
+
+func TestReviewCommand_BatchAllPendingWithTier(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetReviewFlags()
+
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"), testutil.WithModel("model-a"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"), testutil.WithModel("model-b"))
+
+	caution := testutil.MakeRequest(t, h.DB, requestor, testutil.WithCommand("npm cache clean", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierCaution), testutil.WithMinApprovals(1), testutil.WithRequireDifferentModel(false))
+	critical := testutil.MakeRequest(t, h.DB, requestor, testutil.WithCommand("rm -rf /", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierCritical))
+
+	cmd := newTestReviewCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "review", "--all-pending", "--tier", "caution",
+		"--decision", "reject", "-m", "sweep", "-s", reviewer.ID, "-k", reviewer.SessionKey, "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var summary batchReviewSummary
+	if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if summary.Rejected != 1 || len(summary.Results) != 1 || summary.Results[0].RequestID != caution.ID {
+		t.Fatalf("expected only the caution request to be rejected, got %+v", summary)
+	}
+
+	got, err := h.DB.GetRequest(critical.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != db.StatusPending {
+		t.Errorf("critical request status = %s, want pending", got.Status)
+	}
+}
+
+func TestReviewCommand_BatchValidation(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"reject needs comments", []string{"review", "req-1", "--decision", "reject", "-s", "s", "-k", "k"}, "--comments is required"},
+		{"bad decision", []string{"review", "req-1", "--decision", "maybe", "-s", "s", "-k", "k"}, "invalid --decision"},
+		{"bad tier", []string{"review", "--all-pending", "--decision", "approve", "--tier", "low", "-s", "s", "-k", "k"}, "invalid --tier"},
+		{"ids and all-pending", []string{"review", "req-1", "--all-pending", "--decision", "approve", "-s", "s", "-k", "k"}, "not both"},
+		{"all-pending needs decision", []string{"review", "--all-pending"}, "--decision is required"},
+		{"session required", []string{"review", "req-1", "--decision", "approve"}, "--session-id is required"},
+		{"several ids need decision", []string{"review", "req-1", "req-2"}, "without --decision"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetReviewFlags()
+			cmd := newTestReviewCmd(h.DBPath)
+			args := append(tt.args, "-C", h.ProjectDir)
+			_, err := executeCommandCapture(t, cmd, args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
+	"text/tabwriter"
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/core"
@@ -12,12 +14,20 @@ import (
 )
 
 var (
-	flagRollbackForce bool
+	flagRollbackForce         bool
+	flagRollbackRestoreForce  bool
+	flagRollbackDryRun        bool
+	flagRollbackRestoreDryRun bool
 )
 
 func init() {
 	rollbackCmd.Flags().BoolVarP(&flagRollbackForce, "force", "f", false, "force rollback even if state may be stale")
+	rollbackCmd.Flags().BoolVar(&flagRollbackDryRun, "dry-run", false, "show what a filesystem restore would do without writing anything")
+	rollbackRestoreCmd.Flags().BoolVarP(&flagRollbackRestoreForce, "force", "f", false, "force restore even if state may be stale")
+	rollbackRestoreCmd.Flags().BoolVar(&flagRollbackRestoreDryRun, "dry-run", false, "show what a filesystem restore would do without writing anything")
 
+	rollbackCmd.AddCommand(rollbackListCmd)
+	rollbackCmd.AddCommand(rollbackRestoreCmd)
 	rootCmd.AddCommand(rollbackCmd)
 }
 
@@ -27,94 +37,297 @@ var rollbackCmd = &cobra.Command{
 	Long: `Rollback the effects of an executed command using captured state.
 
 Rollback requires that:
-1. The request was executed (status: executed or execution_failed)
+1. The request has finished (it is not pending or executing)
 2. Rollback state was captured before execution (--capture-rollback flag)
 3. The captured state is still valid
 
 Note: Not all commands can be rolled back. Rollback is only available when
 pre-execution state capture was enabled.
 
+"slb rollback <request-id>" is shorthand for "slb rollback restore <request-id>".
+
 Examples:
+  slb rollback list
   slb rollback abc123
-  slb rollback abc123 --force`,
+  slb rollback restore abc123 --force`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		requestID := args[0]
+		return runRollbackRestore(args[0], flagRollbackForce, flagRollbackDryRun)
+	},
+}
 
-		// Open database
-		dbConn, err := db.OpenAndMigrate(GetDB())
+var rollbackListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List rollback captures for the project",
+	Long: `List the rollback captures stored under the project's .slb/rollback
+directory, newest first, with the kind of state captured and its size on disk.
+
+Examples:
+  slb rollback list
+  slb rollback list --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := projectPath()
 		if err != nil {
-			return fmt.Errorf("opening database: %w", err)
+			return fmt.Errorf("getting project path: %w", err)
 		}
-		defer dbConn.Close()
 
-		// Get the request
-		request, err := dbConn.GetRequest(requestID)
+		captures, err := core.ListRollbackCaptures(project)
 		if err != nil {
-			return fmt.Errorf("getting request: %w", err)
+			return err
 		}
 
-		// Validate request state
-		if request.Status != db.StatusExecuted && request.Status != db.StatusExecutionFailed {
-			return fmt.Errorf("cannot rollback: request status is %s (must be executed or execution_failed)", request.Status)
+		// Request status is informational; captures are listed even when
+		// the database has no record of the request.
+		statuses := make(map[string]string, len(captures))
+		rolledBack := make(map[string]string, len(captures))
+		if dbConn, err := db.OpenAndMigrate(GetDB()); err == nil {
+			defer dbConn.Close()
+			for _, c := range captures {
+				req, err := dbConn.GetRequest(c.Data.RequestID)
+				if err != nil {
+					continue
+				}
+				statuses[req.ID] = string(req.Status)
+				if req.Rollback != nil && req.Rollback.RolledBackAt != nil {
+					rolledBack[req.ID] = req.Rollback.RolledBackAt.Format(time.RFC3339)
+				}
+			}
 		}
 
-		// Check for rollback data
-		if request.Rollback == nil || request.Rollback.Path == "" {
-			return fmt.Errorf("no rollback data available for this request (was --capture-rollback used?)")
+		type captureView struct {
+			RequestID    string `json:"request_id"`
+			Kind         string `json:"kind"`
+			CapturedAt   string `json:"captured_at"`
+			SizeBytes    int64  `json:"size_bytes"`
+			Command      string `json:"command"`
+			RollbackPath string `json:"rollback_path"`
+			Status       string `json:"status,omitempty"`
+			RolledBackAt string `json:"rolled_back_at,omitempty"`
 		}
 
-		// Check if already rolled back
-		if request.Rollback.RolledBackAt != nil {
-			if !flagRollbackForce {
-				return fmt.Errorf("request was already rolled back at %s (use --force to rollback again)",
-					request.Rollback.RolledBackAt.Format(time.RFC3339))
-			}
+		views := make([]captureView, 0, len(captures))
+		for _, c := range captures {
+			views = append(views, captureView{
+				RequestID:    c.Data.RequestID,
+				Kind:         c.Data.Kind,
+				CapturedAt:   c.Data.CapturedAt.Format(time.RFC3339),
+				SizeBytes:    c.SizeBytes,
+				Command:      c.Data.CommandRaw,
+				RollbackPath: c.Data.RollbackPath,
+				Status:       statuses[c.Data.RequestID],
+				RolledBackAt: rolledBack[c.Data.RequestID],
+			})
 		}
 
-		rollbackData, err := core.LoadRollbackData(request.Rollback.Path)
-		if err != nil {
-			return fmt.Errorf("loading rollback data: %w", err)
+		if GetOutput() == "json" {
+			out := output.New(output.Format(GetOutput()))
+			return out.Write(views)
 		}
 
-		ctx := context.Background()
-		if err := core.RestoreRollbackState(ctx, rollbackData, core.RollbackRestoreOptions{Force: flagRollbackForce}); err != nil {
-			return fmt.Errorf("restoring rollback state: %w", err)
+		if len(views) == 0 {
+			fmt.Printf("No rollback captures in %s\n", project)
+			return nil
 		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "REQUEST_ID\tKIND\tCAPTURED_AT\tSIZE\tSTATUS\tCOMMAND")
+		for _, v := range views {
+			status := v.Status
+			if status == "" {
+				status = "-"
+			}
+			if v.RolledBackAt != "" {
+				status += " (rolled back)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				v.RequestID, v.Kind, v.CapturedAt, formatCaptureSize(v.SizeBytes), status, v.Command)
+		}
+		return w.Flush()
+	},
+}
 
-		// Build output
-		type rollbackResult struct {
-			RequestID    string `json:"request_id"`
-			RollbackPath string `json:"rollback_path"`
-			RolledBackAt string `json:"rolled_back_at"`
-			Status       string `json:"status"`
-			Message      string `json:"message"`
+var rollbackRestoreCmd = &cobra.Command{
+	Use:   "restore <request-id>",
+	Short: "Restore the state captured before a request executed",
+	Long: `Restore the state captured before a request executed.
+
+The capture recorded on the request is used, falling back to the project's
+.slb/rollback/req-<request-id> directory. Restoring is refused while the
+request is still pending or executing.
+
+--force is required for git rollbacks, which reset the working tree, and
+database rollbacks, which replace the dumped tables, and lets filesystem
+rollbacks overwrite existing files. It is also required to
+restore a request that was already rolled back.
+
+--dry-run lists, for a filesystem rollback, each path the restore would
+create, overwrite or skip, and the paths that would make it fail: existing
+paths without --force, and paths reached through a symlink. Nothing is
+written and the request is not marked as rolled back.
+
+Examples:
+  slb rollback restore abc123
+  slb rollback restore abc123 --dry-run
+  slb rollback restore abc123 --force --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRollbackRestore(args[0], flagRollbackRestoreForce, flagRollbackRestoreDryRun)
+	},
+}
+
+// runRollbackRestore restores the capture for requestID and records the
+// rollback on the request. With dryRun it only prints the restore plan.
+func runRollbackRestore(requestID string, force, dryRun bool) error {
+	// Open database
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	// Get the request
+	request, err := dbConn.GetRequest(requestID)
+	if err != nil {
+		return fmt.Errorf("getting request: %w", err)
+	}
+
+	// Validate request state
+	switch request.Status {
+	case db.StatusPending, db.StatusExecuting:
+		return fmt.Errorf("cannot rollback: request status is %s (wait until it has finished)", request.Status)
+	}
+
+	// Locate rollback data
+	rollbackPath := ""
+	if request.Rollback != nil {
+		rollbackPath = request.Rollback.Path
+	}
+	if rollbackPath == "" && request.ProjectPath != "" {
+		dir := core.RollbackDir(request.ProjectPath, request.ID)
+		if _, err := os.Stat(dir); err == nil {
+			rollbackPath = dir
 		}
+	}
+	if rollbackPath == "" {
+		return fmt.Errorf("no rollback data available for this request (was --capture-rollback used?)")
+	}
 
-		now := time.Now().UTC()
-		if err := dbConn.UpdateRequestRolledBackAt(requestID, now); err != nil {
-			return fmt.Errorf("recording rolled_back_at: %w", err)
+	// Check if already rolled back
+	if request.Rollback != nil && request.Rollback.RolledBackAt != nil {
+		if !force {
+			return fmt.Errorf("request was already rolled back at %s (use --force to rollback again)",
+				request.Rollback.RolledBackAt.Format(time.RFC3339))
 		}
+	}
 
-		resp := rollbackResult{
-			RequestID:    requestID,
-			RollbackPath: request.Rollback.Path,
-			RolledBackAt: now.Format(time.RFC3339),
-			Status:       "rolled_back",
-			Message:      "Rollback completed using captured state.",
+	rollbackData, err := core.LoadRollbackData(rollbackPath)
+	if err != nil {
+		if !dryRun {
+			_ = core.RecordRollbackRestore(dbConn, request, "", err)
 		}
+		return fmt.Errorf("loading rollback data: %w", err)
+	}
 
-		out := output.New(output.Format(GetOutput()))
-		if GetOutput() == "json" {
-			return out.Write(resp)
+	ctx := context.Background()
+	if dryRun {
+		plan, err := core.PlanRollbackRestore(ctx, rollbackData, core.RollbackRestoreOptions{Force: force})
+		if err != nil {
+			return fmt.Errorf("planning restore: %w", err)
 		}
+		return writeRestorePlan(plan)
+	}
+	var skipped []core.SkippedSymlink
+	err = core.RestoreRollbackState(ctx, rollbackData, core.RollbackRestoreOptions{
+		Force:            force,
+		OnSkippedSymlink: func(s core.SkippedSymlink) { skipped = append(skipped, s) },
+	})
+	// Recorded for 'slb stats --rollbacks'; a failure to record is ignored.
+	_ = core.RecordRollbackRestore(dbConn, request, rollbackData.Kind, err)
+	if err != nil {
+		return fmt.Errorf("restoring rollback state: %w", err)
+	}
 
-		// Human-readable output
-		fmt.Printf("Rollback for request %s\n", requestID)
-		fmt.Printf("Rollback data: %s\n", request.Rollback.Path)
-		fmt.Println()
-		fmt.Println("Rollback completed.")
+	// Build output
+	type rollbackResult struct {
+		RequestID    string `json:"request_id"`
+		Kind         string `json:"kind"`
+		RollbackPath string `json:"rollback_path"`
+		RolledBackAt string `json:"rolled_back_at"`
+		Status       string `json:"status"`
+		Message      string `json:"message"`
+		// SkippedSymlinks lists symlinks the platform would not let the
+		// restore create; everything else was restored.
+		SkippedSymlinks []core.SkippedSymlink `json:"skipped_symlinks,omitempty"`
+	}
 
-		return nil
-	},
+	now := time.Now().UTC()
+	if err := dbConn.UpdateRequestRolledBackAt(requestID, now); err != nil {
+		return fmt.Errorf("recording rolled_back_at: %w", err)
+	}
+
+	resp := rollbackResult{
+		RequestID:    requestID,
+		Kind:         rollbackData.Kind,
+		RollbackPath: rollbackPath,
+		RolledBackAt: now.Format(time.RFC3339),
+		Status:       "rolled_back",
+		Message:      "Rollback completed using captured state.",
+	}
+	if len(skipped) > 0 {
+		resp.SkippedSymlinks = skipped
+		resp.Message = fmt.Sprintf("Rollback completed using captured state; %d symlink(s) could not be created.", len(skipped))
+	}
+
+	out := output.New(output.Format(GetOutput()))
+	if GetOutput() == "json" {
+		return out.Write(resp)
+	}
+
+	// Human-readable output
+	fmt.Printf("Rollback for request %s\n", requestID)
+	fmt.Printf("Rollback data: %s (%s)\n", rollbackPath, rollbackData.Kind)
+	fmt.Println()
+	for _, s := range skipped {
+		fmt.Fprintf(os.Stderr, "Warning: symlink %s -> %s not restored: %s\n", s.Path, s.Target, s.Reason)
+	}
+	fmt.Println("Rollback completed.")
+
+	return nil
+}
+
+// writeRestorePlan prints a dry-run restore plan.
+func writeRestorePlan(plan *core.RestorePlan) error {
+	if GetOutput() == "json" {
+		out := output.New(output.Format(GetOutput()))
+		return out.Write(plan)
+	}
+
+	fmt.Printf("Restore plan for request %s (dry run, nothing written)\n\n", plan.RequestID)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tTYPE\tPATH\tREASON")
+	for _, e := range plan.Entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Action, e.Type, e.Path, e.Reason)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\nCreate: %d, Overwrite: %d, Skip: %d, Conflict: %d, Unsafe: %d\n",
+		plan.Count(core.RestoreCreate), plan.Count(core.RestoreOverwrite), plan.Count(core.RestoreSkip),
+		plan.Count(core.RestoreConflict), plan.Count(core.RestoreUnsafe))
+	if err := plan.Err(); err != nil {
+		fmt.Printf("The restore would fail: %v\n", err)
+	}
+	return nil
+}
+
+// formatCaptureSize renders a byte count for the rollback list table.
+func formatCaptureSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
 }
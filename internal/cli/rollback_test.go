@@ -1,9 +1,14 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
 	"github.com/spf13/cobra"
@@ -30,7 +35,22 @@ func newTestRollbackCmd(dbPath string) *cobra.Command {
 		RunE:  rollbackCmd.RunE,
 	}
 	rbCmd.Flags().BoolVarP(&flagRollbackForce, "force", "f", false, "force rollback")
+	rbCmd.Flags().BoolVar(&flagRollbackDryRun, "dry-run", false, "dry run")
 
+	listCmd := &cobra.Command{
+		Use:  "list",
+		Args: cobra.NoArgs,
+		RunE: rollbackListCmd.RunE,
+	}
+	restoreCmd := &cobra.Command{
+		Use:  "restore <request-id>",
+		Args: cobra.ExactArgs(1),
+		RunE: rollbackRestoreCmd.RunE,
+	}
+	restoreCmd.Flags().BoolVarP(&flagRollbackRestoreForce, "force", "f", false, "force restore")
+	restoreCmd.Flags().BoolVar(&flagRollbackRestoreDryRun, "dry-run", false, "dry run")
+
+	rbCmd.AddCommand(listCmd, restoreCmd)
 	root.AddCommand(rbCmd)
 
 	return root
@@ -42,6 +62,9 @@ func resetRollbackFlags() {
 	flagJSON = false
 	flagProject = ""
 	flagRollbackForce = false
+	flagRollbackRestoreForce = false
+	flagRollbackDryRun = false
+	flagRollbackRestoreDryRun = false
 }
 
 func TestRollbackCommand_RequiresRequestID(t *testing.T) {
@@ -145,3 +168,205 @@ func TestRollbackCommand_Help(t *testing.T) {
 		t.Error("expected help to mention 'executed' command")
 	}
 }
+
+// makeCapturedRequest creates an executed "rm -rf build" request in the
+// harness project with a filesystem capture of build/a.txt.
+func makeCapturedRequest(t *testing.T, h *testutil.Harness) (*db.Request, string) {
+	t.Helper()
+
+	buildDir := filepath.Join(h.ProjectDir, "build")
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		t.Fatalf("mkdir build: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(buildDir, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	sess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("TestAgent"),
+	)
+	req := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -rf build", h.ProjectDir, false),
+	)
+	data, err := core.CaptureRollbackState(context.Background(), req, core.RollbackCaptureOptions{})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if data == nil || data.Filesystem == nil {
+		t.Fatal("expected filesystem capture")
+	}
+	if _, err := h.DB.Exec(`UPDATE requests SET status = 'executed' WHERE id = ?`, req.ID); err != nil {
+		t.Fatalf("set executed: %v", err)
+	}
+	return req, buildDir
+}
+
+func TestRollbackListCommand_Empty(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRollbackFlags()
+
+	cmd := newTestRollbackCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "rollback", "list", "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("parse json: %v\n%s", err, stdout)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no captures, got %v", got)
+	}
+}
+
+func TestRollbackListCommand_ShowsCapture(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRollbackFlags()
+
+	req, _ := makeCapturedRequest(t, h)
+
+	cmd := newTestRollbackCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "rollback", "list", "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []struct {
+		RequestID  string `json:"request_id"`
+		Kind       string `json:"kind"`
+		CapturedAt string `json:"captured_at"`
+		SizeBytes  int64  `json:"size_bytes"`
+		Status     string `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("parse json: %v\n%s", err, stdout)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 capture, got %d", len(got))
+	}
+	if got[0].RequestID != req.ID || got[0].Kind != "filesystem" || got[0].Status != "executed" {
+		t.Errorf("unexpected capture: %+v", got[0])
+	}
+	if got[0].SizeBytes <= 0 || got[0].CapturedAt == "" {
+		t.Errorf("expected size and capture time, got %+v", got[0])
+	}
+
+	resetRollbackFlags()
+	cmd = newTestRollbackCmd(h.DBPath)
+	stdout, err = executeCommandCapture(t, cmd, "rollback", "list", "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout, req.ID) || !strings.Contains(stdout, "filesystem") {
+		t.Errorf("expected text listing to include the capture, got %q", stdout)
+	}
+}
+
+func TestRollbackRestoreCommand_RestoresCapture(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRollbackFlags()
+
+	req, buildDir := makeCapturedRequest(t, h)
+	if err := os.RemoveAll(buildDir); err != nil {
+		t.Fatalf("remove build: %v", err)
+	}
+
+	cmd := newTestRollbackCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "rollback", "restore", req.ID, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("parse json: %v\n%s", err, stdout)
+	}
+	if result["status"] != "rolled_back" || result["kind"] != "filesystem" {
+		t.Errorf("unexpected result: %v", result)
+	}
+
+	got, err := os.ReadFile(filepath.Join(buildDir, "a.txt"))
+	if err != nil {
+		t.Fatalf("read restored file: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("unexpected restored content: %q", got)
+	}
+
+	updated, err := h.DB.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("get request: %v", err)
+	}
+	if updated.Rollback == nil || updated.Rollback.RolledBackAt == nil {
+		t.Error("expected rolled_back_at to be recorded")
+	}
+
+	// A second restore needs --force.
+	resetRollbackFlags()
+	cmd = newTestRollbackCmd(h.DBPath)
+	_, err = executeCommandCapture(t, cmd, "rollback", "restore", req.ID, "-j")
+	if err == nil || !strings.Contains(err.Error(), "already rolled back") {
+		t.Fatalf("expected already rolled back error, got %v", err)
+	}
+
+	resetRollbackFlags()
+	cmd = newTestRollbackCmd(h.DBPath)
+	if _, err := executeCommandCapture(t, cmd, "rollback", "restore", req.ID, "--force", "-j"); err != nil {
+		t.Fatalf("forced restore: %v", err)
+	}
+}
+
+func TestRollbackRestoreCommand_DryRun(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRollbackFlags()
+
+	req, buildDir := makeCapturedRequest(t, h)
+	if err := os.RemoveAll(buildDir); err != nil {
+		t.Fatalf("remove build: %v", err)
+	}
+
+	cmd := newTestRollbackCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "rollback", "restore", req.ID, "--dry-run", "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var plan core.RestorePlan
+	if err := json.Unmarshal([]byte(stdout), &plan); err != nil {
+		t.Fatalf("parse json: %v\n%s", err, stdout)
+	}
+	if plan.RequestID != req.ID || plan.Count(core.RestoreCreate) != 2 {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+	if _, err := os.Stat(buildDir); !os.IsNotExist(err) {
+		t.Errorf("dry run restored files (stat err %v)", err)
+	}
+	updated, err := h.DB.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("get request: %v", err)
+	}
+	if updated.Rollback != nil && updated.Rollback.RolledBackAt != nil {
+		t.Error("dry run must not record rolled_back_at")
+	}
+}
+
+func TestRollbackRestoreCommand_RefusesExecuting(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRollbackFlags()
+
+	req, _ := makeCapturedRequest(t, h)
+	if _, err := h.DB.Exec(`UPDATE requests SET status = 'executing' WHERE id = ?`, req.ID); err != nil {
+		t.Fatalf("set executing: %v", err)
+	}
+
+	cmd := newTestRollbackCmd(h.DBPath)
+	_, err := executeCommandCapture(t, cmd, "rollback", "restore", req.ID, "-j")
+	if err == nil {
+		t.Fatal("expected error when restoring an executing request")
+	}
+	if !strings.Contains(err.Error(), "status is executing") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
 	"github.com/Dicklesworthstone/slb/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -46,6 +48,9 @@ Commands are classified by risk level:
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applySessionIDFromEnv(cmd); err != nil {
+			return err
+		}
 		if flagProject == "" {
 			return nil
 		}
@@ -65,13 +70,16 @@ var versionCmd = &cobra.Command{
 	Short: "Print version information",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		goVersion := runtime.Version()
+		projectPath, _ := os.Getwd()
 		configPath := flagConfig
 		if configPath == "" {
-			home, _ := os.UserHomeDir()
-			configPath = filepath.Join(home, ".slb", "config.toml")
+			userPath, _, err := config.ConfigPaths(projectPath, "")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			configPath = userPath
 		}
 		dbPath := GetDB()
-		projectPath, _ := os.Getwd()
 
 		payload := map[string]any{
 			"version":      version,
@@ -137,7 +145,7 @@ func GetActor() string {
 	if actor := os.Getenv("SLB_ACTOR"); actor != "" {
 		return actor
 	}
-	if actor := os.Getenv("AGENT_NAME"); actor != "" {
+	if actor := discoverAgentIdentity().AgentName; actor != "" {
 		return actor
 	}
 	// Fallback to username@hostname
@@ -163,6 +171,9 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
 	rootCmd.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
 
+	// Report this build's version in the daemon IPC handshake.
+	daemon.Version = version
+
 	// Add subcommands
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(sessionCmd)
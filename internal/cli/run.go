@@ -3,6 +3,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"os"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/Dicklesworthstone/slb/internal/config"
 	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/output"
 	"github.com/spf13/cobra"
@@ -26,6 +28,13 @@ var (
 	flagRunAttachFile     []string
 	flagRunAttachContext  []string
 	flagRunAttachScreen   []string
+	flagRunAttachRun      []string
+	flagRunAttachURL      []string
+	flagRunDownscale      bool
+	flagRunAllowBinary    bool
+	flagRunLabels         []string
+	flagRunPreview        bool
+	flagRunCampaign       string
 )
 
 func init() {
@@ -38,6 +47,13 @@ func init() {
 	runCmd.Flags().StringSliceVar(&flagRunAttachFile, "attach-file", nil, "attach file content as context")
 	runCmd.Flags().StringSliceVar(&flagRunAttachContext, "attach-context", nil, "run command and attach output as context")
 	runCmd.Flags().StringSliceVar(&flagRunAttachScreen, "attach-screenshot", nil, "attach screenshot/image file")
+	runCmd.Flags().StringSliceVar(&flagRunAttachRun, "attach-run", nil, "attach the execution output of a previous request (by ID)")
+	runCmd.Flags().StringSliceVar(&flagRunAttachURL, "attach-url", nil, "fetch an http(s) URL and attach its content")
+	runCmd.Flags().BoolVar(&flagRunDownscale, "downscale-screenshots", false, "shrink screenshots larger than 4096px to fit instead of rejecting them")
+	runCmd.Flags().BoolVar(&flagRunAllowBinary, "allow-binary", false, "attach binary --attach files base64-encoded instead of rejecting them")
+	runCmd.Flags().StringSliceVar(&flagRunLabels, "label", nil, "label the request (key=value, repeatable)")
+	runCmd.Flags().StringVar(&flagRunCampaign, "campaign", "", "add the request to a campaign (see 'slb campaign create')")
+	runCmd.Flags().BoolVar(&flagRunPreview, "preview", false, "run the command's dry-run variant first and attach its output to the request")
 
 	rootCmd.AddCommand(runCmd)
 }
@@ -54,10 +70,31 @@ Flow:
 2. If SAFE: execute immediately
 3. If DANGEROUS/CRITICAL: create request, block, wait for approval
 4. If approved: execute in caller's shell environment
-5. If rejected/timeout: exit 1 with error
+5. If rejected/timeout: exit with the outcome's code
+
+Before a DANGEROUS or CRITICAL request is created, the command's dry-run
+variant (kubectl --dry-run, terraform plan -destroy, ls for rm, ...) is run
+for up to 30s and its output attached for reviewers; --preview does this for
+any tier, and general.enable_dry_run = false turns off the automatic
+preview. A preview that fails or times out is attached marked as failed and
+does not block the request.
 
 The command inherits the caller's environment and working directory.
 
+Exit codes:
+  0  executed successfully
+  1  internal error
+  5  timed out waiting for approval
+  6  rate limited
+  7  rejected
+  8  cancelled
+  9  execution failed (the command's own exit code is in --json output)
+  10 approval expired before execution (re-request review with
+     'slb rerequest <id>')
+
+Earlier releases exited with the command's own exit code in text mode; that
+code is now only printed on stderr and reported as exit_code in --json output.
+
 Examples:
   slb run "rm -rf ./build" --reason "Clean build artifacts"
   slb run "git push --force" --reason "Rewrite history" --safety "Branch is not shared"
@@ -75,19 +112,22 @@ Examples:
 			return err
 		}
 
+		cwd, err := os.Getwd()
+		if err != nil {
+			cwd = project
+		}
+
+		// A .slb/policy.toml above the working directory overrides the
+		// project config for this request.
 		cfg, err := config.Load(config.LoadOptions{
 			ProjectDir: project,
 			ConfigPath: flagConfig,
+			PolicyDir:  cwd,
 		})
 		if err != nil {
 			return fmt.Errorf("loading config: %w", err)
 		}
 
-		cwd, err := os.Getwd()
-		if err != nil {
-			cwd = project
-		}
-
 		dbConn, err := db.OpenAndMigrate(GetDB())
 		if err != nil {
 			return fmt.Errorf("opening database: %w", err)
@@ -98,13 +138,26 @@ Examples:
 
 		// Collect attachments from flags
 		attachments, err := CollectAttachments(cmd.Context(), AttachmentFlags{
-			Files:       flagRunAttachFile,
-			Contexts:    flagRunAttachContext,
-			Screenshots: flagRunAttachScreen,
+			Files:           flagRunAttachFile,
+			Contexts:        flagRunAttachContext,
+			Screenshots:     flagRunAttachScreen,
+			Runs:            flagRunAttachRun,
+			URLs:            flagRunAttachURL,
+			Redact:          redactPatterns(cfg, nil),
+			StrictRedaction: cfg.General.StrictRedaction,
+			Downscale:       flagRunDownscale,
+			AllowBinary:     flagRunAllowBinary,
+			PathPrivacy:     cfg.General.AttachmentPathPrivacy,
+			ProjectPath:     project,
+			DB:              dbConn,
 		})
 		if err != nil {
 			return writeError(cmd, out, "attachment_error", command, err)
 		}
+		labels, err := db.ParseLabels(flagRunLabels)
+		if err != nil {
+			return writeError(cmd, out, "invalid_label", command, err)
+		}
 
 		// Step 1: Classify and create request using config-derived limits and notifiers
 		rl := core.NewRateLimiter(dbConn, toRateLimitConfig(cfg))
@@ -120,11 +173,18 @@ Examples:
 				Goal:           flagRunGoal,
 				SafetyArgument: flagRunSafety,
 			},
-			Attachments: attachments,
-			ProjectPath: project,
+			Attachments:    attachments,
+			RedactPatterns: redactPatterns(cfg, nil),
+			Labels:         labels,
+			CampaignID:     flagRunCampaign,
+			ProjectPath:    project,
+			TimeoutSecs:    &flagRunTimeout,
+			Preview:        flagRunPreview,
+			AutoPreview:    cfg.General.EnableDryRun,
 		})
 		if err != nil {
-			return writeError(cmd, out, "request_failed", command, err)
+			return withOutcome(outcomeForCreateError(err),
+				writeError(cmd, out, "request_failed", command, err))
 		}
 
 		// Step 2: If SAFE, execute immediately
@@ -133,33 +193,72 @@ Examples:
 			if err != nil {
 				return err
 			}
-			if exitCode != 0 {
-				os.Exit(exitCode)
-			}
-			return nil
+			return withOutcome(outcomeForExitCode(exitCode), nil)
 		}
 
-		request := result.Request
+		// Delegations may approve the request on a reviewer's behalf.
+		request := applyDelegations(dbConn, cfg, result.Request)
+		if result.Queued && GetOutput() != "json" && !flagRunYield {
+			fmt.Fprintf(os.Stderr, "[slb] %s\n", describeQueueStatus(request.ID, result.Queue))
+		}
+		if request.TimeoutClamped() && GetOutput() != "json" {
+			fmt.Fprintf(os.Stderr, "[slb] %s\n", describeTimeoutClamp(request))
+		}
+		if result.Trust != nil && !result.Trust.Holds() && GetOutput() != "json" {
+			fmt.Fprintf(os.Stderr, "[slb] %s\n", describeVoidedTrust(result.Trust))
+		}
+		if result.NoopReason != "" && GetOutput() != "json" {
+			fmt.Fprintf(os.Stderr, "[slb] Auto-approved: %s\n", result.NoopReason)
+		}
+		if msg := describePreview(result.Preview, flagRunPreview); msg != "" && GetOutput() != "json" {
+			fmt.Fprintf(os.Stderr, "[slb] %s\n", msg)
+		}
 
 		// Step 3: If yield mode and not immediately approved, return request info
-		if flagRunYield && request.Status == db.StatusPending {
-			return out.Write(map[string]any{
-				"status":        "pending",
+		if flagRunYield && (request.Status == db.StatusPending || request.Status == db.StatusQueued) {
+			resp := map[string]any{
+				"status":        string(request.Status),
 				"request_id":    request.ID,
 				"tier":          string(request.RiskTier),
 				"min_approvals": request.MinApprovals,
+				"timeout_secs":  request.TimeoutSecs,
 				"message":       "Request created, yielding to background. Check status with: slb status " + request.ID,
-			})
+			}
+			if request.TimeoutClamped() {
+				resp["timeout_requested_secs"] = request.TimeoutRequestedSecs
+			}
+			if result.Preview != nil {
+				resp["has_preview"] = true
+				if reason, ok := result.Preview.Metadata[core.PreviewErrorKey].(string); ok {
+					resp["preview_error"] = reason
+				}
+			}
+			if result.Queue != nil {
+				resp["queue"] = result.Queue
+				resp["message"] = "Request queued by rate limit, yielding to background. Check status with: slb status " + request.ID
+			}
+			return out.Write(resp)
 		}
 
 		// Step 4: Wait for approval
-		deadline := time.Now().Add(time.Duration(flagRunTimeout) * time.Second)
+		deadline := time.Now().Add(time.Duration(request.TimeoutSecs) * time.Second)
 		for time.Now().Before(deadline) {
 			request, _, err = dbConn.GetRequestWithReviews(request.ID)
 			if err != nil {
 				return writeError(cmd, out, "poll_failed", command, err)
 			}
 
+			// A queued request needs someone to admit it once a slot frees.
+			if request.Status == db.StatusQueued {
+				admitted, err := creator.AdmitQueued(request.RequestorSessionID)
+				if err != nil {
+					return writeError(cmd, out, "poll_failed", command, err)
+				}
+				for _, r := range admitted {
+					applyDelegations(dbConn, cfg, r)
+				}
+			}
+
 			// Evaluate status
 			decision := evaluateRequestForExecution(request.Status)
 
@@ -168,19 +267,30 @@ Examples:
 			}
 
 			if !decision.ShouldContinuePolling {
-				return writeError(cmd, out, string(request.Status), command,
-					fmt.Errorf("request %s: %s", request.ID, decision.Reason))
+				reason := decision.Reason
+				if request.Status == db.StatusCancelled && request.CancelReason != "" {
+					reason += ": " + request.CancelReason
+				}
+				return withOutcome(outcomeForStatus(request.Status),
+					writeError(cmd, out, string(request.Status), command,
+						fmt.Errorf("request %s: %s", request.ID, reason)))
 			}
 
 			time.Sleep(500 * time.Millisecond)
 		}
 
-		// Check if we timed out waiting
+		// Check if we timed out waiting. A request that never left the queue
+		// is cancelled so it cannot be admitted after we stop waiting.
+		if request.Status == db.StatusQueued {
+			_ = dbConn.UpdateRequestStatus(request.ID, db.StatusCancelled)
+			return withOutcome(outcomeTimedOut, writeError(cmd, out, "timeout", command,
+				fmt.Errorf("request %s timed out waiting in the rate-limit queue", request.ID)))
+		}
 		if request.Status == db.StatusPending {
 			// Mark as timeout
 			_ = dbConn.UpdateRequestStatus(request.ID, db.StatusTimeout)
-			return writeError(cmd, out, "timeout", command,
-				fmt.Errorf("request %s timed out waiting for approval", request.ID))
+			return withOutcome(outcomeTimedOut, writeError(cmd, out, "timeout", command,
+				fmt.Errorf("request %s timed out waiting for approval", request.ID)))
 		}
 
 		// Step 5: Execute the approved command
@@ -188,10 +298,7 @@ Examples:
 		if err != nil {
 			return err
 		}
-		if exitCode != 0 {
-			os.Exit(exitCode)
-		}
-		return nil
+		return withOutcome(outcomeForExitCode(exitCode), nil)
 	},
 }
 
@@ -255,15 +362,26 @@ func runSafeCommand(cmd *cobra.Command, out *output.Writer, command, cwd, projec
 }
 
 func runApprovedRequest(ctx context.Context, out *output.Writer, dbConn *db.DB, cfg config.Config, project, requestID string) (int, error) {
-	executor := core.NewExecutor(dbConn, nil).WithNotifier(buildAgentMailNotifier(project))
+	executor := core.NewExecutor(dbConn, nil).WithNotifier(buildAgentMailNotifier(project)).
+		WithRiskOverrides(toRiskOverrideRules(cfg.RiskOverrides)).
+		WithChangeRecords(cfg.Integrations.ChangeRecordURL, cfg.Integrations.ChangeRecordTiers)
 
 	execResult, execErr := executor.ExecuteApprovedRequest(ctx, core.ExecuteOptions{
-		RequestID:         requestID,
-		SessionID:         flagSessionID,
-		LogDir:            ".slb/logs",
-		SuppressOutput:    GetOutput() == "json",
-		CaptureRollback:   cfg.General.EnableRollbackCapture,
-		MaxRollbackSizeMB: cfg.General.MaxRollbackSizeMB,
+		RequestID:               requestID,
+		SessionID:               flagSessionID,
+		LogDir:                  ".slb/logs",
+		SuppressOutput:          GetOutput() == "json",
+		CaptureRollback:         cfg.General.EnableRollbackCapture,
+		RunAllApprovedSegments:  cfg.General.RunAllApprovedSegments,
+		MaxRollbackSizeMB:       cfg.General.MaxRollbackSizeMB,
+		RollbackRootPrefix:      cfg.General.RollbackRootPrefix,
+		MaxRollbackCaptures:     cfg.General.MaxRollbackCaptures,
+		MaxConcurrentCaptures:   cfg.General.MaxConcurrentCaptures,
+		RollbackCaptureWait:     time.Duration(cfg.General.RollbackCaptureWaitSecs) * time.Second,
+		RollbackExclude:         cfg.General.RollbackExclude,
+		MaxConcurrentExecutions: cfg.General.MaxConcurrentExecutions,
+		ExecutionQueueWait:      time.Duration(cfg.General.ExecutionQueueWaitSecs) * time.Second,
+		OnExecutionQueued:       reportExecutionQueued,
 	})
 
 	exitCode := 0
@@ -285,9 +403,16 @@ func runApprovedRequest(ctx context.Context, out *output.Writer, dbConn *db.DB,
 	if execErr != nil {
 		resp["error"] = execErr.Error()
 	}
+	expired := errors.Is(execErr, core.ErrApprovalExpired)
+	if expired {
+		resp["status"] = string(db.StatusApprovalExpired)
+	}
 
 	if GetOutput() == "json" {
 		_ = out.Write(resp)
+		if expired {
+			return 1, withOutcome(outcomeApprovalExpired, nil)
+		}
 		if execErr != nil {
 			return 1, nil
 		}
@@ -296,6 +421,9 @@ func runApprovedRequest(ctx context.Context, out *output.Writer, dbConn *db.DB,
 
 	if execErr != nil {
 		fmt.Fprintf(os.Stderr, "[slb] Execution failed: %s\n", execErr.Error())
+		if expired {
+			return 1, withOutcome(outcomeApprovalExpired, nil)
+		}
 		return 1, nil
 	}
 	if exitCode != 0 {
@@ -329,6 +457,22 @@ func createRunLogFile(project, prefix string) (string, error) {
 	return logPath, nil
 }
 
+// describeQueueStatus summarizes a queued request's position for humans.
+func describeQueueStatus(requestID string, q *core.QueueStatus) string {
+	if q == nil {
+		return fmt.Sprintf("Request %s queued by rate limit", requestID)
+	}
+	msg := fmt.Sprintf("Request %s queued by rate limit: position %d of %d (pending %d/%d, last minute %d/%d)",
+		requestID, q.Position, q.QueueLength, q.Pending, q.MaxPending, q.RecentRequests, q.MaxPerMinute)
+	if q.EstimatedAdmitAt != nil {
+		msg += "; estimated admission " + q.EstimatedAdmitAt.Local().Format(time.Kitchen)
+	}
+	if q.EstimateNote != "" {
+		msg += "; " + q.EstimateNote
+	}
+	return msg
+}
+
 // ExecutionDecision represents the result of evaluating whether a request
 // should be executed. This is returned by the pure evaluation function for testability.
 type ExecutionDecision struct {
@@ -344,7 +488,8 @@ type ExecutionDecision struct {
 // Decision rules:
 //   - StatusApproved: Execute the command
 //   - Terminal status (rejected, timeout, cancelled, execution_failed, timed_out): Stop with error
-//   - StatusPending: Continue polling
+//   - StatusApprovalExpired: Stop with error; the requestor must re-request review
+//   - StatusPending, StatusQueued: Continue polling
 func evaluateRequestForExecution(status db.RequestStatus) ExecutionDecision {
 	if status == db.StatusApproved {
 		return ExecutionDecision{
@@ -363,6 +508,14 @@ func evaluateRequestForExecution(status db.RequestStatus) ExecutionDecision {
 		}
 	}
 
+	if status == db.StatusApprovalExpired {
+		return ExecutionDecision{
+			ShouldExecute:         false,
+			ShouldContinuePolling: false,
+			Reason:                "approval expired before execution; re-request review with 'slb rerequest'",
+		}
+	}
+
 	// Still pending - continue waiting
 	return ExecutionDecision{
 		ShouldExecute:         false,
@@ -373,18 +526,27 @@ func evaluateRequestForExecution(status db.RequestStatus) ExecutionDecision {
 
 // Helpers to adapt config into core types ------------------------------------
 
-func toRateLimitConfig(cfg config.Config) core.RateLimitConfig {
-	action := core.RateLimitAction(cfg.RateLimits.RateLimitAction)
-	switch action {
-	case core.RateLimitActionReject, core.RateLimitActionQueue, core.RateLimitActionWarn:
-	default:
-		action = core.RateLimitActionReject
+// newProjectRequestCreator builds the request creator and rate limiter from
+// the current project's config, for commands that admit queued requests.
+func newProjectRequestCreator(dbConn *db.DB) (*core.RequestCreator, *core.RateLimiter, error) {
+	project, err := projectPath()
+	if err != nil {
+		return nil, nil, err
 	}
-	return core.RateLimitConfig{
-		MaxPendingPerSession: cfg.RateLimits.MaxPendingPerSession,
-		MaxRequestsPerMinute: cfg.RateLimits.MaxRequestsPerMinute,
-		Action:               action,
+	cfg, err := config.Load(config.LoadOptions{
+		ProjectDir: project,
+		ConfigPath: flagConfig,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading config: %w", err)
 	}
+	rl := core.NewRateLimiter(dbConn, toRateLimitConfig(cfg))
+	return core.NewRequestCreator(dbConn, rl, nil, toRequestCreatorConfig(cfg)), rl, nil
+}
+
+// toRateLimitConfig converts the rate_limits settings.
+func toRateLimitConfig(cfg config.Config) core.RateLimitConfig {
+	return daemon.RateLimitConfigFromConfig(cfg)
 }
 
 func toRequestCreatorConfig(cfg config.Config) *core.RequestCreatorConfig {
@@ -393,16 +555,143 @@ func toRequestCreatorConfig(cfg config.Config) *core.RequestCreatorConfig {
 		timeoutMinutes = 30
 	}
 	return &core.RequestCreatorConfig{
-		BlockedAgents:              cfg.Agents.Blocked,
-		DynamicQuorumEnabled:       false,
-		DynamicQuorumFloor:         1,
-		RequestTimeoutMinutes:      timeoutMinutes,
-		ApprovalTTLMinutes:         cfg.General.ApprovalTTLMins,
-		ApprovalTTLCriticalMinutes: cfg.General.ApprovalTTLCriticalMins,
-		AgentMailEnabled:           cfg.Integrations.AgentMailEnabled,
-		AgentMailThread:            cfg.Integrations.AgentMailThread,
-		AgentMailSender:            "",
+		BlockedAgents:                cfg.Agents.Blocked,
+		AgentPolicy:                  cfg.Agents.Policy,
+		AllowedAgents:                cfg.Agents.Allowed,
+		DynamicQuorumEnabled:         false,
+		DynamicQuorumFloor:           1,
+		SessionIdleTimeout:           cfg.Agents.SessionIdleTimeout(),
+		RequestTimeoutMinutes:        timeoutMinutes,
+		ApprovalTTLMinutes:           cfg.General.ApprovalTTLMins,
+		ApprovalTTLCriticalMinutes:   cfg.General.ApprovalTTLCriticalMins,
+		AgentMailEnabled:             cfg.Integrations.AgentMailEnabled,
+		AgentMailThread:              cfg.Integrations.AgentMailThread,
+		AgentMailRoutes:              cfg.Integrations.AgentMailRoutes,
+		AgentMailSender:              "",
+		ContextPinningFamilies:       cfg.General.ContextPinning,
+		SelfProtectionAction:         cfg.General.SelfProtection,
+		MigrationGlobs:               cfg.General.MigrationGlobs,
+		MigrationMaxAttachmentBytes:  migrationAttachmentBytes(cfg.General.MigrationMaxAttachmentKB),
+		DryRunWithholdTiers:          toRiskTiers(cfg.General.DryRunWithholdTiers),
+		RequireDifferentHostTiers:    toRiskTiers(cfg.General.RequireDifferentHostTiers),
+		RequireDifferentProgramTiers: toRiskTiers(cfg.General.RequireDifferentProgramTiers),
+		TimeoutBounds:                toTimeoutBounds(cfg.Patterns),
+		RequiredRoles:                toRequiredRoles(cfg.Patterns),
+		Attachments:                  toAttachmentConfig(cfg),
+		RiskOverrides:                toRiskOverrideRules(cfg.RiskOverrides),
+		TrustedScriptFloor:           core.RiskTier(cfg.General.TrustedScriptFloor),
+		DryRunNoopAction:             dryRunNoopAction(cfg),
+		RejectionCooldownSecs:        cfg.General.RejectionCooldownSecs,
+		RejectionCooldownAction:      cfg.General.RejectionCooldownAction,
+	}
+}
+
+// dryRunNoopAction returns general.dry_run_noop_action, or review when dry
+// runs are disabled.
+func dryRunNoopAction(cfg config.Config) string {
+	if !cfg.General.EnableDryRun {
+		return core.DryRunNoopReview
+	}
+	return cfg.General.DryRunNoopAction
+}
+
+// toRiskOverrideRules converts the configured risk override rules.
+func toRiskOverrideRules(ro config.RiskOverridesConfig) []core.RiskOverrideRule {
+	rules := make([]core.RiskOverrideRule, 0, len(ro.Rules))
+	for _, r := range ro.Rules {
+		rules = append(rules, core.RiskOverrideRule{
+			Glob:           r.Glob,
+			Regex:          r.Regex,
+			Tier:           core.RiskTier(r.Tier),
+			AllowDowngrade: r.AllowDowngrade,
+		})
+	}
+	return rules
+}
+
+// redactPatterns combines the configured redaction patterns with those
+// given by --redact.
+func redactPatterns(cfg config.Config, flags []string) []string {
+	return append(append([]string{}, cfg.General.RedactPatterns...), flags...)
+}
+
+// toAttachmentConfig applies the configured total attachment quota.
+func toAttachmentConfig(cfg config.Config) core.AttachmentConfig {
+	ac := core.DefaultAttachmentConfig()
+	ac.MaxTotalAttachmentBytes = int64(cfg.General.MaxTotalAttachmentKB) * 1024
+	ac.ReservedContextBytes = int64(cfg.General.AttachmentContextReserveKB) * 1024
+	ac.RedactByDefault = true
+	ac.StrictRedaction = cfg.General.StrictRedaction
+	return ac
+}
+
+// toRequiredRoles collects each tier's required reviewer roles.
+func toRequiredRoles(p config.PatternsConfig) map[core.RiskTier][]string {
+	return map[core.RiskTier][]string{
+		core.RiskTierCritical:  p.Critical.RequiredRoles,
+		core.RiskTierDangerous: p.Dangerous.RequiredRoles,
+		core.RiskTierCaution:   p.Caution.RequiredRoles,
+	}
+}
+
+// toTimeoutBounds collects each tier's configured --timeout floor and ceiling.
+func toTimeoutBounds(p config.PatternsConfig) map[core.RiskTier]core.TimeoutBounds {
+	bounds := func(t config.PatternTierConfig) core.TimeoutBounds {
+		return core.TimeoutBounds{MinSecs: t.MinTimeoutSeconds, MaxSecs: t.MaxTimeoutSeconds}
+	}
+	return map[core.RiskTier]core.TimeoutBounds{
+		core.RiskTierCritical:  bounds(p.Critical),
+		core.RiskTierDangerous: bounds(p.Dangerous),
+		core.RiskTierCaution:   bounds(p.Caution),
+	}
+}
+
+// describeTimeoutClamp explains a --timeout the tier's bounds changed.
+func describeTimeoutClamp(request *db.Request) string {
+	return fmt.Sprintf("--timeout %ds is outside the %s tier's bounds; waiting %ds",
+		request.TimeoutRequestedSecs, request.RiskTier, request.TimeoutSecs)
+}
+
+// describePreview reports a dry-run preview that failed, or a requested one
+// the command has no dry-run variant for.
+func describePreview(preview *db.Attachment, requested bool) string {
+	if preview == nil {
+		if requested {
+			return "no dry-run variant for this command; no preview attached"
+		}
+		return ""
+	}
+	if reason, ok := preview.Metadata[core.PreviewErrorKey].(string); ok {
+		return "dry-run preview " + reason + "; its output is attached marked as failed"
+	}
+	return ""
+}
+
+// describeVoidedTrust explains why a trusted script did not lower the tier.
+func describeVoidedTrust(v *core.TrustVerification) string {
+	msg := fmt.Sprintf("trusted script %s is %s; review is at the full tier", v.Entry.Path, v.Status)
+	if v.Detail != "" {
+		msg += " (" + v.Detail + ")"
+	}
+	return msg
+}
+
+// toRiskTiers converts configured tier names.
+func toRiskTiers(names []string) []core.RiskTier {
+	tiers := make([]core.RiskTier, 0, len(names))
+	for _, name := range names {
+		tiers = append(tiers, core.RiskTier(name))
+	}
+	return tiers
+}
+
+// migrationAttachmentBytes converts the configured KB cap, where 0 means
+// summary only, to core's byte cap, where 0 means the default.
+func migrationAttachmentBytes(kb int) int {
+	if kb <= 0 {
+		return -1
 	}
+	return kb * 1024
 }
 
 // writeError outputs an error response.
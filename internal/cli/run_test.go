@@ -1,12 +1,14 @@
 package cli
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
 	"github.com/spf13/cobra"
@@ -43,6 +45,12 @@ func newTestRunCmd(dbPath string) *cobra.Command {
 	rCmd.Flags().StringSliceVar(&flagRunAttachFile, "attach-file", nil, "attach file")
 	rCmd.Flags().StringSliceVar(&flagRunAttachContext, "attach-context", nil, "attach context")
 	rCmd.Flags().StringSliceVar(&flagRunAttachScreen, "attach-screenshot", nil, "attach screenshot")
+	rCmd.Flags().StringSliceVar(&flagRunAttachRun, "attach-run", nil, "attach prior run output")
+	rCmd.Flags().StringSliceVar(&flagRunAttachURL, "attach-url", nil, "attach URL")
+	rCmd.Flags().BoolVar(&flagRunDownscale, "downscale-screenshots", false, "downscale screenshots")
+	rCmd.Flags().BoolVar(&flagRunAllowBinary, "allow-binary", false, "allow binary attachments")
+	rCmd.Flags().StringSliceVar(&flagRunLabels, "label", nil, "labels")
+	rCmd.Flags().StringVar(&flagRunCampaign, "campaign", "", "campaign")
 
 	root.AddCommand(rCmd)
 
@@ -65,6 +73,12 @@ func resetRunFlags() {
 	flagRunAttachFile = nil
 	flagRunAttachContext = nil
 	flagRunAttachScreen = nil
+	flagRunAttachRun = nil
+	flagRunAttachURL = nil
+	flagRunDownscale = false
+	flagRunAllowBinary = false
+	flagRunLabels = nil
+	flagRunCampaign = ""
 }
 
 func TestRunCommand_RequiresCommand(t *testing.T) {
@@ -125,9 +139,49 @@ func TestRunCommand_Help(t *testing.T) {
 	}
 }
 
-// Note: TestRunCommand_InvalidSession is skipped because the run command
-// calls os.Exit on errors, which would terminate the test process.
-// This behavior would need to be refactored to support proper testing.
+func TestRunCommand_ExecutionFailureKeepsJSONExitCode(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRunFlags()
+	origWd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origWd) })
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+
+	cmd := newTestRunCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "run", "sh -c 'exit 42'",
+		"-C", h.ProjectDir, "-s", sess.ID, "-j")
+	if err == nil {
+		t.Fatal("expected an error for a failing command")
+	}
+	if got := ExitCode(err); got != ExitExecutionFailed {
+		t.Errorf("ExitCode(err) = %d, want %d", got, ExitExecutionFailed)
+	}
+
+	var resp map[string]any
+	if jerr := json.Unmarshal([]byte(stdout), &resp); jerr != nil {
+		t.Fatalf("expected JSON output, got %q: %v", stdout, jerr)
+	}
+	if code, _ := resp["exit_code"].(float64); code != 42 {
+		t.Errorf("expected JSON exit_code=42 from the command itself, got %v", resp["exit_code"])
+	}
+}
+
+func TestRunCommand_InvalidSessionExitsInternalError(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetRunFlags()
+	origWd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origWd) })
+
+	cmd := newTestRunCmd(h.DBPath)
+	_, err := executeCommandCapture(t, cmd, "run", "echo hello",
+		"-C", h.ProjectDir, "-s", "no-such-session", "-j")
+	if err == nil {
+		t.Fatal("expected error for unknown session")
+	}
+	if got := ExitCode(err); got != ExitInternalError {
+		t.Errorf("ExitCode(err) = %d, want %d", got, ExitInternalError)
+	}
+}
 
 func TestToRateLimitConfig(t *testing.T) {
 	// Test the helper function that converts config to rate limit config
@@ -153,6 +207,8 @@ func TestToRequestCreatorConfig(t *testing.T) {
 	cfg.General.RequestTimeoutSecs = 1800 // 30 minutes
 	cfg.General.ApprovalTTLMins = 60
 	cfg.Agents.Blocked = []string{"blocked-agent"}
+	cfg.Agents.Policy = "allowlist"
+	cfg.Agents.Allowed = []string{"known-agent"}
 
 	result := toRequestCreatorConfig(cfg)
 
@@ -165,6 +221,9 @@ func TestToRequestCreatorConfig(t *testing.T) {
 	if len(result.BlockedAgents) != 1 || result.BlockedAgents[0] != "blocked-agent" {
 		t.Errorf("expected BlockedAgents=['blocked-agent'], got %v", result.BlockedAgents)
 	}
+	if result.AgentPolicy != core.AgentPolicyAllowlist || len(result.AllowedAgents) != 1 || result.AllowedAgents[0] != "known-agent" {
+		t.Errorf("expected allowlist policy for known-agent, got %q %v", result.AgentPolicy, result.AllowedAgents)
+	}
 }
 
 func TestToRateLimitConfig_InvalidAction(t *testing.T) {
@@ -354,6 +413,9 @@ func TestEvaluateRequestForExecution_AllStatuses(t *testing.T) {
 		{"cancelled", db.StatusCancelled, false, false},
 		{"executed", db.StatusExecuted, false, false},
 		{"execution_failed", db.StatusExecutionFailed, false, false},
+
+		// Waiting on a re-request - stop polling
+		{"approval_expired", db.StatusApprovalExpired, false, false},
 	}
 
 	for _, tt := range tests {
@@ -5,15 +5,35 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/config"
 	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/output"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+// stdinIsTerminal reports whether stdin is an interactive terminal. It is a
+// variable so tests can stand in for a human at a terminal.
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// requireHumanTerminal refuses to hand out a human-marked session unless the
+// command runs from an interactive terminal, so agents driving slb through
+// pipes cannot obtain one.
+func requireHumanTerminal() error {
+	if !stdinIsTerminal() {
+		return fmt.Errorf("human sessions (--program %s) can only be started or resumed from an interactive terminal", db.HumanProgram)
+	}
+	return nil
+}
+
 var (
 	flagSessionAgent string
 	flagSessionProg  string
@@ -22,26 +42,35 @@ var (
 	flagResumeCreateIfMissing bool
 	flagResumeForce           bool
 
+	flagRegisterFromEnv bool
+
 	flagSessionGCDryRun    bool
 	flagSessionGCThreshold time.Duration
 	flagSessionGCForce     bool
+
+	flagSessionListAll bool
 )
 
 func init() {
 	sessionCmd.PersistentFlags().StringVarP(&flagSessionAgent, "agent", "a", "", "agent name (required for start/resume)")
-	sessionCmd.PersistentFlags().StringVarP(&flagSessionProg, "program", "p", "", "agent program (e.g., codex-cli)")
+	sessionCmd.PersistentFlags().StringVarP(&flagSessionProg, "program", "p", "", "agent program (e.g., codex-cli; \"human\" marks a human session)")
 	sessionCmd.PersistentFlags().StringVarP(&flagSessionModel, "model", "m", "", "agent model (e.g., gpt-5.1-codex)")
 
 	sessionResumeCmd.Flags().BoolVar(&flagResumeCreateIfMissing, "create-if-missing", true, "create a new session if none active")
 	sessionResumeCmd.Flags().BoolVar(&flagResumeForce, "force", false, "end mismatched active session and create a new one")
 
+	sessionRegisterCmd.Flags().BoolVar(&flagRegisterFromEnv, "from-env", false, "fill agent, program and model from the environment")
+
 	sessionGcCmd.Flags().BoolVar(&flagSessionGCDryRun, "dry-run", false, "show what would be cleaned up without ending sessions")
 	sessionGcCmd.Flags().DurationVar(&flagSessionGCThreshold, "threshold", 30*time.Minute, "inactivity threshold (e.g., 30m, 2h)")
 	sessionGcCmd.Flags().BoolVarP(&flagSessionGCForce, "force", "f", false, "skip interactive confirmation")
 
+	sessionListCmd.Flags().BoolVar(&flagSessionListAll, "all", false, "include ended sessions")
+
 	sessionCmd.AddCommand(sessionStartCmd)
 	sessionCmd.AddCommand(sessionEndCmd)
 	sessionCmd.AddCommand(sessionResumeCmd)
+	sessionCmd.AddCommand(sessionRegisterCmd)
 	sessionCmd.AddCommand(sessionListCmd)
 	sessionCmd.AddCommand(sessionHeartbeatCmd)
 	sessionCmd.AddCommand(sessionResetLimitsCmd)
@@ -60,6 +89,11 @@ var sessionStartCmd = &cobra.Command{
 		if flagSessionAgent == "" {
 			return fmt.Errorf("--agent is required")
 		}
+		if flagSessionProg == db.HumanProgram {
+			if err := requireHumanTerminal(); err != nil {
+				return err
+			}
+		}
 		project, err := projectPath()
 		if err != nil {
 			return err
@@ -130,6 +164,11 @@ var sessionResumeCmd = &cobra.Command{
 		if flagSessionAgent == "" {
 			return fmt.Errorf("--agent is required")
 		}
+		if flagSessionProg == db.HumanProgram {
+			if err := requireHumanTerminal(); err != nil {
+				return err
+			}
+		}
 		project, err := projectPath()
 		if err != nil {
 			return err
@@ -151,9 +190,13 @@ var sessionResumeCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if sess.IsHuman() {
+			if err := requireHumanTerminal(); err != nil {
+				return err
+			}
+		}
 
-		out := output.New(output.Format(GetOutput()))
-		return out.Write(map[string]any{
+		result := map[string]any{
 			"session_id":     sess.ID,
 			"session_key":    sess.SessionKey,
 			"agent_name":     sess.AgentName,
@@ -162,25 +205,174 @@ var sessionResumeCmd = &cobra.Command{
 			"project_path":   sess.ProjectPath,
 			"started_at":     sess.StartedAt.Format(time.RFC3339),
 			"last_active_at": sess.LastActiveAt.Format(time.RFC3339),
-		})
+		}
+		// Requests whose approval lapsed while the agent was away can be
+		// sent back for review instead of being made again.
+		expired, err := approvalExpiredRequestsFor(dbConn, sess)
+		if err != nil {
+			return err
+		}
+		if len(expired) > 0 {
+			result["approval_expired_requests"] = expired
+		}
+
+		out := output.New(output.Format(GetOutput()))
+		return out.Write(result)
+	},
+}
+
+// approvalExpiredRequestsFor lists the agent's requests in the session's
+// project that are waiting in approval_expired, with the command to re-request
+// each.
+func approvalExpiredRequestsFor(dbConn *db.DB, sess *db.Session) ([]map[string]any, error) {
+	requests, err := dbConn.ListRequestsByStatus(db.StatusApprovalExpired, sess.ProjectPath)
+	if err != nil {
+		return nil, fmt.Errorf("listing expired approvals: %w", err)
+	}
+	var out []map[string]any
+	for _, r := range requests {
+		if r.RequestorAgent != sess.AgentName {
+			continue
+		}
+		entry := map[string]any{
+			"request_id": r.ID,
+			"command":    r.Command.DisplayRedacted,
+			"rerequest":  fmt.Sprintf("slb rerequest %s -s %s", r.ID, sess.ID),
+		}
+		if r.Command.DisplayRedacted == "" {
+			entry["command"] = r.Command.Raw
+		}
+		if r.ApprovalExpiresAt != nil {
+			entry["expired_at"] = r.ApprovalExpiresAt.UTC().Format(time.RFC3339)
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+var sessionRegisterCmd = &cobra.Command{
+	Use:   "register",
+	Short: "Create or reuse a session matching the agent's identity",
+	Long: `Create a session for the agent, or reuse its active session in this project
+when agent, program and model all match. Running it again with the same
+identity returns the same session, so agent wrappers can call it on every
+start. The output reports whether the session was "reused" or "created".
+
+With --from-env, fields not given as flags are filled from the environment.
+An active session for the same agent with a different program or model is an
+error; end it or use 'slb session resume --force'.
+
+` + agentEnvHelp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		agent, program, model := flagSessionAgent, flagSessionProg, flagSessionModel
+		sources := map[string]string{}
+		if flagRegisterFromEnv {
+			env := discoverAgentIdentity()
+			fill := func(field string, dst *string, discovered string) {
+				if *dst == "" && discovered != "" {
+					*dst = discovered
+					sources[field] = env.Sources[field]
+				}
+			}
+			fill("agent_name", &agent, env.AgentName)
+			fill("program", &program, env.Program)
+			fill("model", &model, env.Model)
+		}
+		if agent == "" {
+			if flagRegisterFromEnv {
+				return fmt.Errorf("no agent name in --agent, SLB_AGENT_NAME or AGENT_NAME")
+			}
+			return fmt.Errorf("--agent is required (or use --from-env)")
+		}
+		if program == db.HumanProgram {
+			if err := requireHumanTerminal(); err != nil {
+				return err
+			}
+		}
+		project, err := projectPath()
+		if err != nil {
+			return err
+		}
+		dbConn, err := db.OpenAndMigrate(GetDB())
+		if err != nil {
+			return err
+		}
+		defer dbConn.Close()
+
+		action := "reused"
+		sess, err := dbConn.GetActiveSession(agent, project)
+		switch {
+		case errors.Is(err, db.ErrSessionNotFound):
+			action = "created"
+			sess = &db.Session{
+				AgentName:   agent,
+				Program:     program,
+				Model:       model,
+				ProjectPath: project,
+			}
+			if err := dbConn.CreateSession(sess); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case sess.Program != program || sess.Model != model:
+			return fmt.Errorf("active session %s for agent %q has program %q model %q, not %q %q (end it or use: slb session resume -a %s --force)",
+				sess.ID, agent, sess.Program, sess.Model, program, model, agent)
+		default:
+			if err := dbConn.UpdateSessionHeartbeat(sess.ID); err != nil {
+				return err
+			}
+		}
+
+		out := output.New(output.Format(GetOutput()))
+		result := map[string]any{
+			"action":       action,
+			"session_id":   sess.ID,
+			"session_key":  sess.SessionKey,
+			"agent_name":   sess.AgentName,
+			"program":      sess.Program,
+			"model":        sess.Model,
+			"project_path": sess.ProjectPath,
+			"started_at":   sess.StartedAt.Format(time.RFC3339),
+		}
+		if len(sources) > 0 {
+			result["env_sources"] = sources
+		}
+		return out.Write(result)
 	},
 }
 
 var sessionListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List active sessions for the project",
+	Short: "List the project's sessions and when each was last seen",
+	Long: `List the project's active sessions with their agent, model, project,
+last-seen time and active flag. A session not seen within
+agents.session_idle_minutes is shown as inactive: its reviews are refused
+and the daemon ends it on its next sweep. --all includes ended sessions.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		project, err := projectPath()
 		if err != nil {
 			return err
 		}
+		cfg, err := config.Load(config.LoadOptions{
+			ProjectDir: project,
+			ConfigPath: flagConfig,
+		})
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
 		dbConn, err := db.Open(GetDB())
 		if err != nil {
 			return err
 		}
 		defer dbConn.Close()
 
-		sessions, err := dbConn.ListActiveSessions(project)
+		var sessions []*db.Session
+		if flagSessionListAll {
+			sessions, err = dbConn.ListSessions(project)
+		} else {
+			sessions, err = dbConn.ListActiveSessions(project)
+		}
 		if err != nil {
 			return err
 		}
@@ -191,25 +383,53 @@ var sessionListCmd = &cobra.Command{
 			Program     string `json:"program"`
 			Model       string `json:"model"`
 			ProjectPath string `json:"project_path"`
+			Hostname    string `json:"hostname,omitempty"`
 			StartedAt   string `json:"started_at"`
 			LastActive  string `json:"last_active_at"`
+			EndedAt     string `json:"ended_at,omitempty"`
+			Active      bool   `json:"active"`
 		}
 
+		idle := cfg.Agents.SessionIdleTimeout()
+		now := time.Now().UTC()
 		resp := make([]sessionView, 0, len(sessions))
 		for _, s := range sessions {
-			resp = append(resp, sessionView{
+			view := sessionView{
 				SessionID:   s.ID,
 				AgentName:   s.AgentName,
 				Program:     s.Program,
 				Model:       s.Model,
 				ProjectPath: s.ProjectPath,
+				Hostname:    s.Hostname,
 				StartedAt:   s.StartedAt.Format(time.RFC3339),
 				LastActive:  s.LastActiveAt.Format(time.RFC3339),
-			})
+				Active:      s.IsLive(idle, now),
+			}
+			if s.EndedAt != nil {
+				view.EndedAt = s.EndedAt.Format(time.RFC3339)
+			}
+			resp = append(resp, view)
 		}
 
-		out := output.New(output.Format(GetOutput()))
-		return out.Write(resp)
+		if format := GetOutput(); format == "json" || format == "yaml" {
+			out := output.New(output.Format(format))
+			return out.Write(resp)
+		}
+		if len(resp) == 0 {
+			fmt.Println("No sessions.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "AGENT\tMODEL\tPROJECT\tLAST SEEN\tACTIVE\tSESSION")
+		for i, v := range resp {
+			active := "no"
+			if v.Active {
+				active = "yes"
+			}
+			age := now.Sub(sessions[i].LastActiveAt).Truncate(time.Second)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s (%s ago)\t%s\t%s\n", v.AgentName, v.Model, v.ProjectPath, v.LastActive, age, active, v.SessionID)
+		}
+		return w.Flush()
 	},
 }
 
@@ -391,6 +611,9 @@ var sessionGcCmd = &cobra.Command{
 	},
 }
 
+// projectPath resolves the project to operate on: --project, else the working
+// directory. Inside a workspace, a directory without its own .slb/ resolves
+// upward to the member (or workspace root) containing it.
 func projectPath() (string, error) {
 	if flagProject != "" {
 		return flagProject, nil
@@ -399,5 +622,14 @@ func projectPath() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if _, err := os.Stat(filepath.Join(pwd, ".slb")); err == nil {
+		return pwd, nil
+	}
+	if ws, err := config.FindWorkspace(pwd); err == nil && ws != nil {
+		if member := ws.MemberFor(pwd); member != "" {
+			return member, nil
+		}
+		return ws.Root, nil
+	}
 	return pwd, nil
 }
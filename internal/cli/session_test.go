@@ -88,8 +88,10 @@ func resetSessionFlags() {
 	flagSessionModel = ""
 	flagResumeCreateIfMissing = true
 	flagResumeForce = false
+	flagRegisterFromEnv = false
 	flagSessionGCDryRun = false
 	flagSessionGCForce = false
+	flagSessionListAll = false
 }
 
 func TestSessionStart_RequiresAgent(t *testing.T) {
@@ -179,6 +181,38 @@ func TestSessionStart_DuplicatePrevented(t *testing.T) {
 	}
 }
 
+func TestSessionStart_HumanRequiresTerminal(t *testing.T) {
+	h := testutil.NewHarness(t)
+	origTerminal := stdinIsTerminal
+	t.Cleanup(func() { stdinIsTerminal = origTerminal })
+
+	stdinIsTerminal = func() bool { return false }
+	resetSessionFlags()
+	cmd := newTestSessionCmd(h.DBPath)
+	_, err := executeCommandCapture(t, cmd, "session", "start",
+		"-a", "Human", "-p", db.HumanProgram, "-C", h.ProjectDir, "-j")
+	if err == nil || !strings.Contains(err.Error(), "interactive terminal") {
+		t.Fatalf("expected terminal error, got %v", err)
+	}
+
+	stdinIsTerminal = func() bool { return true }
+	resetSessionFlags()
+	cmd = newTestSessionCmd(h.DBPath)
+	if _, err := executeCommandCapture(t, cmd, "session", "start",
+		"-a", "Human", "-p", db.HumanProgram, "-C", h.ProjectDir, "-j"); err != nil {
+		t.Fatalf("unexpected error from a terminal: %v", err)
+	}
+
+	// Resuming the human session without naming the program still needs a terminal.
+	stdinIsTerminal = func() bool { return false }
+	resetSessionFlags()
+	cmd = newTestSessionCmd(h.DBPath)
+	_, err = executeCommandCapture(t, cmd, "session", "resume", "-a", "Human", "-C", h.ProjectDir, "-j")
+	if err == nil || !strings.Contains(err.Error(), "interactive terminal") {
+		t.Fatalf("expected terminal error on resume, got %v", err)
+	}
+}
+
 func TestSessionEnd_RequiresSessionID(t *testing.T) {
 	h := testutil.NewHarness(t)
 	resetSessionFlags()
@@ -266,6 +300,58 @@ func TestSessionList_ReturnsActiveSessions(t *testing.T) {
 	}
 }
 
+func TestSessionList_ShowsLastSeenAndActive(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetSessionFlags()
+
+	live := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Live"))
+	idle := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Idle"))
+	ended := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Ended"))
+	lastSeen := time.Now().UTC().Add(-48 * time.Hour).Format(time.RFC3339)
+	if _, err := h.DB.Exec(`UPDATE sessions SET last_active_at = ? WHERE id = ?`, lastSeen, idle.ID); err != nil {
+		t.Fatalf("backdating session: %v", err)
+	}
+	if err := h.DB.EndSession(ended.ID); err != nil {
+		t.Fatalf("EndSession: %v", err)
+	}
+
+	list := func(args ...string) map[string]map[string]any {
+		t.Helper()
+		cmd := newTestSessionCmd(h.DBPath)
+		stdout, err := executeCommandCapture(t, cmd, append([]string{"session", "list", "-C", h.ProjectDir, "-j"}, args...)...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var result []map[string]any
+		if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+			t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+		}
+		byAgent := make(map[string]map[string]any)
+		for _, s := range result {
+			byAgent[s["agent_name"].(string)] = s
+		}
+		return byAgent
+	}
+
+	got := list()
+	if len(got) != 2 || got["Ended"] != nil {
+		t.Fatalf("expected the live and idle sessions, got %v", got)
+	}
+	// The default 24h idle window leaves the idle session listed but inactive.
+	if got["Live"]["active"] != true || got["Idle"]["active"] != false {
+		t.Errorf("unexpected active flags: live=%v idle=%v", got["Live"]["active"], got["Idle"]["active"])
+	}
+	if got["Idle"]["last_active_at"] != lastSeen || got["Live"]["model"] != live.Model {
+		t.Errorf("unexpected session details %v", got["Idle"])
+	}
+
+	resetSessionFlags()
+	got = list("--all")
+	if len(got) != 3 || got["Ended"]["active"] != false || got["Ended"]["ended_at"] == nil {
+		t.Errorf("expected --all to include the ended session, got %v", got)
+	}
+}
+
 func TestSessionList_EmptyProject(t *testing.T) {
 	h := testutil.NewHarness(t)
 	resetSessionFlags()
@@ -287,6 +373,114 @@ func TestSessionList_EmptyProject(t *testing.T) {
 	}
 }
 
+func registerSession(t *testing.T, dbPath, projectDir string, args ...string) (map[string]any, error) {
+	t.Helper()
+	resetSessionFlags()
+	cmd := newTestSessionCmd(dbPath)
+	stdout, err := executeCommandCapture(t, cmd, append([]string{"session", "register", "-C", projectDir, "-j"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	return result, nil
+}
+
+func TestSessionRegister_FromEnvCreatesThenReuses(t *testing.T) {
+	h := testutil.NewHarness(t)
+	setAgentEnv(t, "AGENT_NAME=BlueDog", "CLAUDECODE=1", "ANTHROPIC_MODEL=opus")
+
+	first, err := registerSession(t, h.DBPath, h.ProjectDir, "--from-env")
+	if err != nil {
+		t.Fatalf("first register: %v", err)
+	}
+	if first["action"] != "created" {
+		t.Errorf("first action = %v, want created", first["action"])
+	}
+	if first["agent_name"] != "BlueDog" || first["program"] != "claude-code" || first["model"] != "opus" {
+		t.Errorf("identity = %v/%v/%v, want BlueDog/claude-code/opus", first["agent_name"], first["program"], first["model"])
+	}
+	sources, _ := first["env_sources"].(map[string]any)
+	if sources["program"] != "CLAUDECODE" || sources["model"] != "ANTHROPIC_MODEL" {
+		t.Errorf("env_sources = %v", first["env_sources"])
+	}
+
+	second, err := registerSession(t, h.DBPath, h.ProjectDir, "--from-env")
+	if err != nil {
+		t.Fatalf("second register: %v", err)
+	}
+	if second["action"] != "reused" || second["session_id"] != first["session_id"] {
+		t.Errorf("second = %v %v, want reused %v", second["action"], second["session_id"], first["session_id"])
+	}
+}
+
+func TestSessionRegister_FlagsBeatEnv(t *testing.T) {
+	h := testutil.NewHarness(t)
+	setAgentEnv(t, "SLB_AGENT_NAME=EnvAgent", "SLB_PROGRAM=env-program", "SLB_MODEL=env-model")
+
+	result, err := registerSession(t, h.DBPath, h.ProjectDir, "--from-env", "-a", "FlagAgent", "-m", "flag-model")
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if result["agent_name"] != "FlagAgent" || result["program"] != "env-program" || result["model"] != "flag-model" {
+		t.Errorf("identity = %v/%v/%v, want FlagAgent/env-program/flag-model", result["agent_name"], result["program"], result["model"])
+	}
+	sources, _ := result["env_sources"].(map[string]any)
+	if len(sources) != 1 || sources["program"] != "SLB_PROGRAM" {
+		t.Errorf("env_sources = %v, want only program from SLB_PROGRAM", result["env_sources"])
+	}
+}
+
+func TestSessionRegister_IgnoresEnvWithoutFromEnv(t *testing.T) {
+	h := testutil.NewHarness(t)
+	setAgentEnv(t, "SLB_AGENT_NAME=EnvAgent", "SLB_MODEL=env-model")
+
+	if _, err := registerSession(t, h.DBPath, h.ProjectDir); err == nil || !strings.Contains(err.Error(), "--agent is required") {
+		t.Fatalf("expected --agent required error, got %v", err)
+	}
+	result, err := registerSession(t, h.DBPath, h.ProjectDir, "-a", "FlagAgent")
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if result["model"] != "" {
+		t.Errorf("model = %v, want empty without --from-env", result["model"])
+	}
+}
+
+func TestSessionRegister_FromEnvRequiresAgent(t *testing.T) {
+	h := testutil.NewHarness(t)
+	setAgentEnv(t, "SLB_MODEL=opus")
+
+	_, err := registerSession(t, h.DBPath, h.ProjectDir, "--from-env")
+	if err == nil || !strings.Contains(err.Error(), "SLB_AGENT_NAME") {
+		t.Fatalf("expected missing agent error, got %v", err)
+	}
+}
+
+func TestSessionRegister_MismatchedActiveSession(t *testing.T) {
+	h := testutil.NewHarness(t)
+	setAgentEnv(t, "SLB_AGENT_NAME=GreenLake", "SLB_PROGRAM=codex-cli", "SLB_MODEL=gpt-5")
+
+	if _, err := registerSession(t, h.DBPath, h.ProjectDir, "--from-env"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	// Same agent, different model: registering must not silently reuse or
+	// replace the active session.
+	_, err := registerSession(t, h.DBPath, h.ProjectDir, "--from-env", "-m", "gpt-5-mini")
+	if err == nil || !strings.Contains(err.Error(), "resume -a GreenLake --force") {
+		t.Fatalf("expected mismatch error, got %v", err)
+	}
+	sessions, err := h.DB.ListActiveSessions(h.ProjectDir)
+	if err != nil {
+		t.Fatalf("ListActiveSessions: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Model != "gpt-5" {
+		t.Errorf("active sessions = %d (model %v), want the original only", len(sessions), sessions)
+	}
+}
+
 func TestSessionResume_RequiresAgent(t *testing.T) {
 	h := testutil.NewHarness(t)
 	resetSessionFlags()
@@ -368,6 +562,44 @@ func TestSessionResume_ReturnsExistingSession(t *testing.T) {
 	}
 }
 
+func TestSessionResume_ListsExpiredApprovals(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetSessionFlags()
+
+	sess := testutil.MakeSession(t, h.DB,
+		testutil.WithProject(h.ProjectDir),
+		testutil.WithAgent("ExistingAgent"),
+		testutil.WithProgram("original-program"),
+		testutil.WithModel("original-model"),
+	)
+	req := testutil.MakeRequest(t, h.DB, sess, testutil.WithStatus(db.StatusApproved))
+	if err := h.DB.UpdateRequestStatus(req.ID, db.StatusApprovalExpired); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newTestSessionCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "session", "resume",
+		"-a", "ExistingAgent",
+		"-p", "original-program",
+		"-m", "original-model",
+		"-C", h.ProjectDir,
+		"-j",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result struct {
+		Expired []map[string]any `json:"approval_expired_requests"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(result.Expired) != 1 || result.Expired[0]["request_id"] != req.ID {
+		t.Errorf("expected %s among the expired approvals, got %v", req.ID, result.Expired)
+	}
+}
+
 func TestSessionHeartbeat_RequiresSessionID(t *testing.T) {
 	h := testutil.NewHarness(t)
 	resetSessionFlags()
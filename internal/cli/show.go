@@ -3,8 +3,10 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/output"
 	"github.com/spf13/cobra"
@@ -34,6 +36,8 @@ This shows the full request details including:
 - Justification
 - Reviews and approvals
 - Execution results (if executed)
+- The most similar earlier request and how it went
+- Webhook and change record deliveries, with failed attempts
 - Attachments (with --with-attachments)`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -51,213 +55,364 @@ This shows the full request details including:
 			return fmt.Errorf("getting request: %w", err)
 		}
 
-		// Build detailed response
-		type attachmentView struct {
-			Type     string         `json:"type"`
-			Content  string         `json:"content,omitempty"`
-			Metadata map[string]any `json:"metadata,omitempty"`
+		// The requestor may only see pseudonyms while the request is pending
+		shownReviews, _, err := reviewsForViewer(dbConn, request, reviews)
+		if err != nil {
+			return err
 		}
 
-		type responsesView struct {
-			ReasonResponse string `json:"reason_response,omitempty"`
-			EffectResponse string `json:"effect_response,omitempty"`
-			GoalResponse   string `json:"goal_response,omitempty"`
-			SafetyResponse string `json:"safety_response,omitempty"`
-		}
+		view := buildShowView(dbConn, request, shownReviews, showViewOptions{
+			WithReviews:     flagShowWithReviews,
+			WithExecution:   flagShowWithExecution,
+			WithAttachments: flagShowWithAttachments,
+		})
 
-		type reviewView struct {
-			ReviewID          string         `json:"review_id"`
-			ReviewerSessionID string         `json:"reviewer_session_id"`
-			ReviewerAgent     string         `json:"reviewer_agent"`
-			ReviewerModel     string         `json:"reviewer_model"`
-			Decision          string         `json:"decision"`
-			Signature         string         `json:"signature,omitempty"`
-			SignatureTime     string         `json:"signature_timestamp,omitempty"`
-			Responses         *responsesView `json:"responses,omitempty"`
-			Comments          string         `json:"comments,omitempty"`
-			CreatedAt         string         `json:"created_at"`
+		// Record which evidence was displayed to a person so the viewing
+		// session's next review can attach it. Machine-readable output is not
+		// a view. Best effort: a read-only show must not fail because of this.
+		if GetOutput() == "text" && flagSessionID != "" {
+			if sess, err := dbConn.GetSession(flagSessionID); err == nil && sess.IsActive() {
+				_ = core.RecordEvidenceViews(request.ProjectPath, request.ID, sess.ID,
+					shownEvidence(request, flagShowWithAttachments, time.Now())...)
+			}
 		}
 
-		type executionView struct {
-			LogPath             string `json:"log_path,omitempty"`
-			ExitCode            *int   `json:"exit_code,omitempty"`
-			DurationMs          *int64 `json:"duration_ms,omitempty"`
-			ExecutedAt          string `json:"executed_at,omitempty"`
-			ExecutedBySessionID string `json:"executed_by_session_id,omitempty"`
-			ExecutedByAgent     string `json:"executed_by_agent,omitempty"`
-			ExecutedByModel     string `json:"executed_by_model,omitempty"`
-		}
+		out := output.New(output.Format(GetOutput()))
+		return out.Write(view)
+	},
+}
 
-		type rollbackView struct {
-			Path         string `json:"path,omitempty"`
-			RolledBackAt string `json:"rolled_back_at,omitempty"`
-		}
+// The show*View types are the JSON shape of a request in slb show. Audit
+// bundles render the same view.
+type (
+	showAttachmentView struct {
+		Type     string         `json:"type"`
+		Content  string         `json:"content,omitempty"`
+		Metadata map[string]any `json:"metadata,omitempty"`
+	}
 
-		type justificationView struct {
-			Reason         string `json:"reason,omitempty"`
-			ExpectedEffect string `json:"expected_effect,omitempty"`
-			Goal           string `json:"goal,omitempty"`
-			SafetyArgument string `json:"safety_argument,omitempty"`
-		}
+	showResponsesView struct {
+		ReasonResponse string            `json:"reason_response,omitempty"`
+		EffectResponse string            `json:"effect_response,omitempty"`
+		GoalResponse   string            `json:"goal_response,omitempty"`
+		SafetyResponse string            `json:"safety_response,omitempty"`
+		EvidenceViewed []db.EvidenceView `json:"evidence_viewed,omitempty"`
+	}
 
-		type commandView struct {
-			Raw               string   `json:"raw"`
-			DisplayRedacted   string   `json:"display_redacted,omitempty"`
-			Argv              []string `json:"argv,omitempty"`
-			Cwd               string   `json:"cwd,omitempty"`
-			Shell             bool     `json:"shell"`
-			Hash              string   `json:"hash"`
-			ContainsSensitive bool     `json:"contains_sensitive"`
-		}
+	showReviewView struct {
+		ReviewID          string             `json:"review_id"`
+		ReviewerSessionID string             `json:"reviewer_session_id"`
+		ReviewerAgent     string             `json:"reviewer_agent"`
+		ReviewerModel     string             `json:"reviewer_model"`
+		Decision          string             `json:"decision"`
+		Segments          []int              `json:"segments,omitempty"`
+		Signature         string             `json:"signature,omitempty"`
+		SignatureTime     string             `json:"signature_timestamp,omitempty"`
+		Responses         *showResponsesView `json:"responses,omitempty"`
+		Comments          string             `json:"comments,omitempty"`
+		CreatedAt         string             `json:"created_at"`
+		// Superseded marks a review from a round whose approval expired; it
+		// no longer counts toward the quorum.
+		Superseded bool `json:"superseded,omitempty"`
+	}
 
-		type dryRunView struct {
-			Command string `json:"command,omitempty"`
-			Output  string `json:"output,omitempty"`
-		}
+	showExecutionView struct {
+		LogPath             string `json:"log_path,omitempty"`
+		ExitCode            *int   `json:"exit_code,omitempty"`
+		DurationMs          *int64 `json:"duration_ms,omitempty"`
+		ExecutedAt          string `json:"executed_at,omitempty"`
+		ExecutedBySessionID string `json:"executed_by_session_id,omitempty"`
+		ExecutedByAgent     string `json:"executed_by_agent,omitempty"`
+		ExecutedByModel     string `json:"executed_by_model,omitempty"`
+		ContextPinning      string `json:"context_pinning,omitempty"`
+		Stdout              string `json:"stdout,omitempty"`
+		Stderr              string `json:"stderr,omitempty"`
+		StdoutTruncated     bool   `json:"stdout_truncated,omitempty"`
+		StderrTruncated     bool   `json:"stderr_truncated,omitempty"`
 
-		type showView struct {
-			RequestID             string            `json:"request_id"`
-			ProjectPath           string            `json:"project_path"`
-			Command               commandView       `json:"command"`
-			RiskTier              string            `json:"risk_tier"`
-			Status                string            `json:"status"`
-			MinApprovals          int               `json:"min_approvals"`
-			RequireDifferentModel bool              `json:"require_different_model"`
-			RequestorSessionID    string            `json:"requestor_session_id"`
-			RequestorAgent        string            `json:"requestor_agent"`
-			RequestorModel        string            `json:"requestor_model"`
-			Justification         justificationView `json:"justification"`
-			DryRun                *dryRunView       `json:"dry_run,omitempty"`
-			Attachments           []attachmentView  `json:"attachments,omitempty"`
-			Reviews               []reviewView      `json:"reviews,omitempty"`
-			Execution             *executionView    `json:"execution,omitempty"`
-			Rollback              *rollbackView     `json:"rollback,omitempty"`
-			CreatedAt             string            `json:"created_at"`
-			ResolvedAt            string            `json:"resolved_at,omitempty"`
-			ExpiresAt             string            `json:"expires_at,omitempty"`
-			ApprovalExpiresAt     string            `json:"approval_expires_at,omitempty"`
-		}
+		Segments []db.SegmentExecution `json:"segments,omitempty"`
+	}
+
+	showRollbackView struct {
+		Path         string `json:"path,omitempty"`
+		RolledBackAt string `json:"rolled_back_at,omitempty"`
+		Pending      bool   `json:"pending,omitempty"`
+	}
+
+	showJustificationView struct {
+		Reason         string `json:"reason,omitempty"`
+		ExpectedEffect string `json:"expected_effect,omitempty"`
+		Goal           string `json:"goal,omitempty"`
+		SafetyArgument string `json:"safety_argument,omitempty"`
+	}
+
+	showCommandView struct {
+		Raw               string   `json:"raw"`
+		DisplayRedacted   string   `json:"display_redacted,omitempty"`
+		Argv              []string `json:"argv,omitempty"`
+		Cwd               string   `json:"cwd,omitempty"`
+		Shell             bool     `json:"shell"`
+		Hash              string   `json:"hash"`
+		ContainsSensitive bool     `json:"contains_sensitive"`
+		Segments          []string `json:"segments,omitempty"`
+		LineCount         int      `json:"line_count,omitempty"` // set for multi-line commands
+	}
+
+	showDryRunView struct {
+		Command string `json:"command,omitempty"`
+		Output  string `json:"output,omitempty"`
+	}
+
+	showNotificationView struct {
+		Kind          string `json:"kind"`
+		URL           string `json:"url"`
+		Attempts      int    `json:"attempts"`
+		LastError     string `json:"last_error,omitempty"`
+		DeliveredAt   string `json:"delivered_at,omitempty"`
+		NextAttemptAt string `json:"next_attempt_at,omitempty"`
+	}
+
+	showSimilarView struct {
+		RequestID string  `json:"request_id"`
+		Score     float64 `json:"score"`
+		Summary   string  `json:"summary"`
+	}
+
+	showView struct {
+		RequestID               string                 `json:"request_id"`
+		ProjectPath             string                 `json:"project_path"`
+		Command                 showCommandView        `json:"command"`
+		PinnedContext           *db.PinnedContext      `json:"pinned_context,omitempty"`
+		Migrations              *db.MigrationSet       `json:"migrations,omitempty"`
+		RiskTier                string                 `json:"risk_tier"`
+		Status                  string                 `json:"status"`
+		Labels                  map[string]string      `json:"labels,omitempty"`
+		ApprovedSegments        []int                  `json:"approved_segments,omitempty"`
+		MinApprovals            int                    `json:"min_approvals"`
+		RequireDifferentModel   bool                   `json:"require_different_model"`
+		RequireDifferentHost    bool                   `json:"require_different_host,omitempty"`
+		RequireDifferentProgram bool                   `json:"require_different_program,omitempty"`
+		TimeoutSecs             int                    `json:"timeout_secs,omitempty"`
+		TimeoutRequestedSecs    int                    `json:"timeout_requested_secs,omitempty"`
+		RequestorSessionID      string                 `json:"requestor_session_id"`
+		RequestorAgent          string                 `json:"requestor_agent"`
+		RequestorModel          string                 `json:"requestor_model"`
+		Justification           showJustificationView  `json:"justification"`
+		DryRun                  *showDryRunView        `json:"dry_run,omitempty"`
+		Attachments             []showAttachmentView   `json:"attachments,omitempty"`
+		Reviews                 []showReviewView       `json:"reviews,omitempty"`
+		Execution               *showExecutionView     `json:"execution,omitempty"`
+		Rollback                *showRollbackView      `json:"rollback,omitempty"`
+		SimilarRequest          *showSimilarView       `json:"similar_request,omitempty"`
+		Notifications           []showNotificationView `json:"notifications,omitempty"`
+		CreatedAt               string                 `json:"created_at"`
+		ResolvedAt              string                 `json:"resolved_at,omitempty"`
+		ExpiresAt               string                 `json:"expires_at,omitempty"`
+		ApprovalExpiresAt       string                 `json:"approval_expires_at,omitempty"`
+	}
+)
+
+// showViewOptions selects the optional sections of a show view.
+type showViewOptions struct {
+	WithReviews     bool
+	WithExecution   bool
+	WithAttachments bool
+}
+
+// buildShowView renders a request and its reviews as slb show displays them.
+func buildShowView(dbConn *db.DB, request *db.Request, reviews []*db.Review, opts showViewOptions) showView {
+	view := showView{
+		RequestID:               request.ID,
+		ProjectPath:             request.ProjectPath,
+		RiskTier:                string(request.RiskTier),
+		Status:                  string(request.Status),
+		Labels:                  request.Labels,
+		ApprovedSegments:        request.ApprovedSegments,
+		MinApprovals:            request.MinApprovals,
+		RequireDifferentModel:   request.RequireDifferentModel,
+		RequireDifferentHost:    request.RequireDifferentHost,
+		RequireDifferentProgram: request.RequireDifferentProgram,
+		TimeoutSecs:             request.TimeoutSecs,
+		TimeoutRequestedSecs:    request.TimeoutRequestedSecs,
+		RequestorSessionID:      request.RequestorSessionID,
+		RequestorAgent:          request.RequestorAgent,
+		RequestorModel:          request.RequestorModel,
+		CreatedAt:               request.CreatedAt.Format(time.RFC3339),
+		Command: showCommandView{
+			Raw:               request.Command.Raw,
+			DisplayRedacted:   request.Command.DisplayRedacted,
+			Argv:              request.Command.Argv,
+			Cwd:               request.Command.Cwd,
+			Shell:             request.Command.Shell,
+			Hash:              request.Command.Hash,
+			ContainsSensitive: request.Command.ContainsSensitive,
+		},
+		PinnedContext: request.PinnedContext,
+		Migrations:    request.Migrations,
+		Justification: showJustificationView{
+			Reason:         request.Justification.Reason,
+			ExpectedEffect: request.Justification.ExpectedEffect,
+			Goal:           request.Justification.Goal,
+			SafetyArgument: request.Justification.SafetyArgument,
+		},
+	}
 
-		view := showView{
-			RequestID:             request.ID,
-			ProjectPath:           request.ProjectPath,
-			RiskTier:              string(request.RiskTier),
-			Status:                string(request.Status),
-			MinApprovals:          request.MinApprovals,
-			RequireDifferentModel: request.RequireDifferentModel,
-			RequestorSessionID:    request.RequestorSessionID,
-			RequestorAgent:        request.RequestorAgent,
-			RequestorModel:        request.RequestorModel,
-			CreatedAt:             request.CreatedAt.Format(time.RFC3339),
-			Command: commandView{
-				Raw:               request.Command.Raw,
-				DisplayRedacted:   request.Command.DisplayRedacted,
-				Argv:              request.Command.Argv,
-				Cwd:               request.Command.Cwd,
-				Shell:             request.Command.Shell,
-				Hash:              request.Command.Hash,
-				ContainsSensitive: request.Command.ContainsSensitive,
-			},
-			Justification: justificationView{
-				Reason:         request.Justification.Reason,
-				ExpectedEffect: request.Justification.ExpectedEffect,
-				Goal:           request.Justification.Goal,
-				SafetyArgument: request.Justification.SafetyArgument,
-			},
+	// Number compound segments so reviewers can approve them individually
+	display := request.Command.Raw
+	if request.Command.ContainsSensitive && request.Command.DisplayRedacted != "" {
+		display = request.Command.DisplayRedacted
+	}
+	if segments := core.CommandSegments(display); len(segments) > 1 {
+		view.Command.Segments = segments
+	}
+	if lines := strings.Count(strings.TrimRight(display, "\n"), "\n") + 1; lines > 1 {
+		view.Command.LineCount = lines
+	}
+
+	// Timestamps
+	if request.ResolvedAt != nil {
+		view.ResolvedAt = request.ResolvedAt.Format(time.RFC3339)
+	}
+	if request.ExpiresAt != nil {
+		view.ExpiresAt = request.ExpiresAt.Format(time.RFC3339)
+	}
+	if request.ApprovalExpiresAt != nil {
+		view.ApprovalExpiresAt = request.ApprovalExpiresAt.Format(time.RFC3339)
+	}
+
+	// Dry run
+	if request.DryRun != nil {
+		view.DryRun = &showDryRunView{
+			Command: request.DryRun.Command,
+			Output:  request.DryRun.Output,
 		}
+	}
 
-		// Timestamps
-		if request.ResolvedAt != nil {
-			view.ResolvedAt = request.ResolvedAt.Format(time.RFC3339)
+	// Reviews
+	if opts.WithReviews && len(reviews) > 0 {
+		view.Reviews = make([]showReviewView, 0, len(reviews))
+		for _, r := range reviews {
+			rv := showReviewView{
+				ReviewID:          r.ID,
+				ReviewerSessionID: r.ReviewerSessionID,
+				ReviewerAgent:     r.ReviewerAgent,
+				ReviewerModel:     r.ReviewerModel,
+				Decision:          string(r.Decision),
+				Segments:          r.Segments,
+				Signature:         r.Signature,
+				Comments:          r.Comments,
+				CreatedAt:         r.CreatedAt.Format(time.RFC3339),
+				Superseded:        r.Round != request.ReviewRound,
+			}
+			if !r.SignatureTimestamp.IsZero() {
+				rv.SignatureTime = r.SignatureTimestamp.Format(time.RFC3339)
+			}
+			// Include responses if any field is non-empty
+			if r.Responses.ReasonResponse != "" || r.Responses.EffectResponse != "" ||
+				r.Responses.GoalResponse != "" || r.Responses.SafetyResponse != "" ||
+				len(r.Responses.EvidenceViewed) > 0 {
+				rv.Responses = &showResponsesView{
+					ReasonResponse: r.Responses.ReasonResponse,
+					EffectResponse: r.Responses.EffectResponse,
+					GoalResponse:   r.Responses.GoalResponse,
+					SafetyResponse: r.Responses.SafetyResponse,
+					EvidenceViewed: r.Responses.EvidenceViewed,
+				}
+			}
+			view.Reviews = append(view.Reviews, rv)
 		}
-		if request.ExpiresAt != nil {
-			view.ExpiresAt = request.ExpiresAt.Format(time.RFC3339)
+	}
+
+	// Execution
+	if opts.WithExecution && request.Execution != nil {
+		view.Execution = &showExecutionView{
+			LogPath:             request.Execution.LogPath,
+			ExitCode:            request.Execution.ExitCode,
+			DurationMs:          request.Execution.DurationMs,
+			ExecutedBySessionID: request.Execution.ExecutedBySessionID,
+			ExecutedByAgent:     request.Execution.ExecutedByAgent,
+			ExecutedByModel:     request.Execution.ExecutedByModel,
+			ContextPinning:      request.Execution.ContextPinning,
+			Stdout:              request.Execution.Stdout,
+			Stderr:              request.Execution.Stderr,
+			StdoutTruncated:     request.Execution.StdoutTruncated,
+			StderrTruncated:     request.Execution.StderrTruncated,
+			Segments:            request.Execution.Segments,
 		}
-		if request.ApprovalExpiresAt != nil {
-			view.ApprovalExpiresAt = request.ApprovalExpiresAt.Format(time.RFC3339)
+		if request.Execution.ExecutedAt != nil {
+			view.Execution.ExecutedAt = request.Execution.ExecutedAt.Format(time.RFC3339)
 		}
+	}
 
-		// Dry run
-		if request.DryRun != nil {
-			view.DryRun = &dryRunView{
-				Command: request.DryRun.Command,
-				Output:  request.DryRun.Output,
-			}
+	// Rollback
+	if request.Rollback != nil {
+		view.Rollback = &showRollbackView{
+			Path:    request.Rollback.Path,
+			Pending: request.Rollback.Pending,
 		}
-
-		// Reviews
-		if flagShowWithReviews && len(reviews) > 0 {
-			view.Reviews = make([]reviewView, 0, len(reviews))
-			for _, r := range reviews {
-				rv := reviewView{
-					ReviewID:          r.ID,
-					ReviewerSessionID: r.ReviewerSessionID,
-					ReviewerAgent:     r.ReviewerAgent,
-					ReviewerModel:     r.ReviewerModel,
-					Decision:          string(r.Decision),
-					Signature:         r.Signature,
-					Comments:          r.Comments,
-					CreatedAt:         r.CreatedAt.Format(time.RFC3339),
-				}
-				if !r.SignatureTimestamp.IsZero() {
-					rv.SignatureTime = r.SignatureTimestamp.Format(time.RFC3339)
-				}
-				// Include responses if any field is non-empty
-				if r.Responses.ReasonResponse != "" || r.Responses.EffectResponse != "" ||
-					r.Responses.GoalResponse != "" || r.Responses.SafetyResponse != "" {
-					rv.Responses = &responsesView{
-						ReasonResponse: r.Responses.ReasonResponse,
-						EffectResponse: r.Responses.EffectResponse,
-						GoalResponse:   r.Responses.GoalResponse,
-						SafetyResponse: r.Responses.SafetyResponse,
-					}
-				}
-				view.Reviews = append(view.Reviews, rv)
-			}
+		if request.Rollback.RolledBackAt != nil {
+			view.Rollback.RolledBackAt = request.Rollback.RolledBackAt.Format(time.RFC3339)
 		}
+	}
 
-		// Execution
-		if flagShowWithExecution && request.Execution != nil {
-			view.Execution = &executionView{
-				LogPath:             request.Execution.LogPath,
-				ExitCode:            request.Execution.ExitCode,
-				DurationMs:          request.Execution.DurationMs,
-				ExecutedBySessionID: request.Execution.ExecutedBySessionID,
-				ExecutedByAgent:     request.Execution.ExecutedByAgent,
-				ExecutedByModel:     request.Execution.ExecutedByModel,
+	// Attachments
+	if len(request.Attachments) > 0 {
+		view.Attachments = make([]showAttachmentView, 0, len(request.Attachments))
+		for _, a := range request.Attachments {
+			av := showAttachmentView{
+				Type:     string(a.Type),
+				Metadata: a.Metadata,
 			}
-			if request.Execution.ExecutedAt != nil {
-				view.Execution.ExecutedAt = request.Execution.ExecutedAt.Format(time.RFC3339)
+			// Only include content if requested
+			if opts.WithAttachments {
+				av.Content = a.Content
 			}
+			view.Attachments = append(view.Attachments, av)
 		}
+	}
 
-		// Rollback
-		if request.Rollback != nil {
-			view.Rollback = &rollbackView{
-				Path: request.Rollback.Path,
-			}
-			if request.Rollback.RolledBackAt != nil {
-				view.Rollback.RolledBackAt = request.Rollback.RolledBackAt.Format(time.RFC3339)
-			}
+	// Closest earlier request, so reviewers see how similar commands went.
+	// Best effort: older databases may lack the token index.
+	if match, err := core.TopSimilarMatch(dbConn, request); err == nil && match != nil {
+		view.SimilarRequest = &showSimilarView{
+			RequestID: match.Request.ID,
+			Score:     match.Score,
+			Summary:   core.DescribeSimilarMatch(match, time.Now()),
 		}
+	}
 
-		// Attachments
-		if len(request.Attachments) > 0 {
-			view.Attachments = make([]attachmentView, 0, len(request.Attachments))
-			for _, a := range request.Attachments {
-				av := attachmentView{
-					Type:     string(a.Type),
-					Metadata: a.Metadata,
-				}
-				// Only include content if requested
-				if flagShowWithAttachments {
-					av.Content = a.Content
-				}
-				view.Attachments = append(view.Attachments, av)
+	// Webhook and change record deliveries, including failed attempts.
+	// Best effort, like the similarity lookup.
+	if notifications, err := dbConn.ListNotificationsForRequest(request.ID); err == nil {
+		for _, n := range notifications {
+			nv := showNotificationView{
+				Kind:      n.Kind,
+				URL:       n.URL,
+				Attempts:  n.Attempts,
+				LastError: n.LastError,
+			}
+			if n.DeliveredAt != nil {
+				nv.DeliveredAt = n.DeliveredAt.Format(time.RFC3339)
+			} else {
+				nv.NextAttemptAt = n.NextAttemptAt.Format(time.RFC3339)
 			}
+			view.Notifications = append(view.Notifications, nv)
 		}
+	}
+	return view
+}
 
-		out := output.New(output.Format(GetOutput()))
-		return out.Write(view)
-	},
+// shownEvidence lists the evidence sections that slb show displays for a request.
+// The CLI cannot observe reading time, so durations are zero.
+func shownEvidence(request *db.Request, withAttachments bool, now time.Time) []db.EvidenceView {
+	views := []db.EvidenceView{core.NewEvidenceView(core.EvidenceSectionJustification, now, 0)}
+	if request.DryRun != nil && request.DryRun.Output != "" {
+		views = append(views, core.NewEvidenceView(core.EvidenceSectionDryRun, now, 0))
+	}
+	if withAttachments {
+		for i := range request.Attachments {
+			views = append(views, core.NewEvidenceView(core.EvidenceAttachmentSection(i), now, 0))
+		}
+	}
+	return views
 }
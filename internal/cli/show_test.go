@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
@@ -22,6 +23,7 @@ func newTestShowCmd(dbPath string) *cobra.Command {
 	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
 	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
 	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
 
 	// Create a fresh showCmd
 	showCmdTest := &cobra.Command{
@@ -44,6 +46,7 @@ func resetShowFlags() {
 	flagOutput = "text"
 	flagJSON = false
 	flagProject = ""
+	flagSessionID = ""
 	flagShowWithReviews = true
 	flagShowWithExecution = true
 	flagShowWithAttachments = false
@@ -266,3 +269,110 @@ func TestShowCommand_JustificationFields(t *testing.T) {
 		t.Errorf("expected justification.expected_effect, got %v", just["expected_effect"])
 	}
 }
+
+func TestShowCommand_ShowsSimilarRequest(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetShowFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	past := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -r -f ./build", h.ProjectDir, true),
+	)
+	if err := h.DB.UpdateRequestStatus(past.ID, db.StatusRejected); err != nil {
+		t.Fatalf("rejecting request: %v", err)
+	}
+	req := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("rm -rf ./build", h.ProjectDir, true),
+	)
+
+	cmd := newTestShowCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "show", req.ID, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	similar, ok := result["similar_request"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected similar_request in output: %s", stdout)
+	}
+	if similar["request_id"] != past.ID {
+		t.Errorf("expected similar request %s, got %v", past.ID, similar["request_id"])
+	}
+	if summary, _ := similar["summary"].(string); !strings.Contains(summary, "rejected") {
+		t.Errorf("expected summary to mention rejection, got %q", summary)
+	}
+}
+
+func TestShowCommand_MultiLineCommand(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetShowFlags()
+
+	raw := "kubectl apply -f - <<EOF\nkind: ConfigMap\nmetadata:\n  name: app\nEOF"
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	req := testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand(raw, h.ProjectDir, true))
+
+	cmd := newTestShowCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "show", req.ID, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result struct {
+		Command showCommandView `json:"command"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if result.Command.Raw != raw {
+		t.Errorf("expected raw command to round-trip, got %q", result.Command.Raw)
+	}
+	if result.Command.LineCount != 5 {
+		t.Errorf("expected line_count=5, got %d", result.Command.LineCount)
+	}
+	if len(result.Command.Segments) != 0 {
+		t.Errorf("expected here-doc body not to be split into segments, got %q", result.Command.Segments)
+	}
+}
+
+func TestShowCommand_ShowsNotificationAttempts(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetShowFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	req := testutil.MakeRequest(t, h.DB, sess)
+	n := &db.UndeliveredNotification{
+		Kind:      db.WebhookNotificationKind("request_pending"),
+		RequestID: req.ID,
+		URL:       "https://hooks.example.com/slb",
+		Payload:   "{}",
+		CreatedAt: time.Now().UTC(),
+	}
+	if _, err := h.DB.EnqueueNotification(n); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DB.MarkNotificationFailed(n.ID, "webhook returned status 502", time.Now().UTC().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, err := executeCommandCapture(t, newTestShowCmd(h.DBPath), "show", req.ID, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result struct {
+		Notifications []showNotificationView `json:"notifications"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(result.Notifications) != 1 {
+		t.Fatalf("expected one notification, got %s", stdout)
+	}
+	got := result.Notifications[0]
+	if got.Kind != "webhook:request_pending" || got.Attempts != 1 || got.LastError != "webhook returned status 502" || got.NextAttemptAt == "" {
+		t.Errorf("unexpected notification %+v", got)
+	}
+}
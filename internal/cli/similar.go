@@ -0,0 +1,122 @@
+// Package cli implements the similar command.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagSimilarLimit    int
+	flagSimilarMinScore float64
+)
+
+func init() {
+	similarCmd.Flags().IntVarP(&flagSimilarLimit, "limit", "n", db.DefaultSimilarLimit, "max results to return")
+	similarCmd.Flags().Float64Var(&flagSimilarMinScore, "min-score", db.DefaultSimilarMinScore, "minimum similarity (0-1)")
+
+	rootCmd.AddCommand(similarCmd)
+}
+
+var similarCmd = &cobra.Command{
+	Use:   "similar <request-id|command>",
+	Short: "Find historical requests with similar commands",
+	Long: `Find historical requests whose commands resemble a request or command.
+
+Commands are compared by the Jaccard similarity of their normalized tokens
+(wrappers stripped, flag order canonicalized), so redacted secrets and flag
+spelling do not affect matching. Each match shows its outcome, reviewers,
+and any recorded post-execution problems.
+
+Examples:
+  slb similar 3f2a9c1e-...            # Requests similar to an existing request
+  slb similar "rm -rf ./build"        # Requests similar to a command
+  slb similar -n 10 --min-score 0.5 "kubectl delete ns staging"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSimilar,
+}
+
+// similarView is one similar request in slb similar output.
+type similarView struct {
+	RequestID      string   `json:"request_id"`
+	Score          float64  `json:"score"`
+	Command        string   `json:"command"`
+	RiskTier       string   `json:"risk_tier"`
+	Status         string   `json:"status"`
+	RequestorAgent string   `json:"requestor_agent"`
+	Reviewers      []string `json:"reviewers,omitempty"`
+	ExitCode       *int     `json:"exit_code,omitempty"`
+	CausedProblems bool     `json:"caused_problems"`
+	Problem        string   `json:"problem_description,omitempty"`
+	Summary        string   `json:"summary"`
+	CreatedAt      string   `json:"created_at"`
+}
+
+func runSimilar(cmd *cobra.Command, args []string) error {
+	dbConn, err := db.Open(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	opts := db.SimilarOptions{Limit: flagSimilarLimit, MinScore: flagSimilarMinScore}
+	var tokens []string
+	if len(args) == 1 {
+		if req, err := dbConn.GetRequest(args[0]); err == nil {
+			opts.ExcludeID = req.ID
+			if tokens, err = dbConn.RequestTokens(req.ID); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, db.ErrRequestNotFound) {
+			return fmt.Errorf("getting request: %w", err)
+		}
+	}
+	if opts.ExcludeID == "" {
+		tokens = core.CommandSimilarityTokens(strings.Join(args, " "))
+	}
+
+	matches, err := core.FindSimilarMatches(dbConn, tokens, opts)
+	if err != nil {
+		return fmt.Errorf("finding similar requests: %w", err)
+	}
+
+	now := time.Now()
+	resp := make([]similarView, 0, len(matches))
+	for i := range matches {
+		m := &matches[i]
+		r := m.Request
+		view := similarView{
+			RequestID:      r.ID,
+			Score:          m.Score,
+			Command:        r.Command.Raw,
+			RiskTier:       string(r.RiskTier),
+			Status:         string(r.Status),
+			RequestorAgent: r.RequestorAgent,
+			Reviewers:      m.Reviewers,
+			Summary:        core.DescribeSimilarMatch(m, now),
+			CreatedAt:      r.CreatedAt.Format(time.RFC3339),
+		}
+		// Use redacted version for display if available
+		if r.Command.DisplayRedacted != "" {
+			view.Command = r.Command.DisplayRedacted
+		}
+		if r.Execution != nil {
+			view.ExitCode = r.Execution.ExitCode
+		}
+		if m.Outcome != nil {
+			view.CausedProblems = m.Outcome.CausedProblems
+			view.Problem = m.Outcome.ProblemDescription
+		}
+		resp = append(resp, view)
+	}
+
+	out := output.New(output.Format(GetOutput()))
+	return out.Write(resp)
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestSimilarCmd creates a fresh similar command for testing.
+func newTestSimilarCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+
+	simCmd := &cobra.Command{
+		Use:  "similar <request-id|command>",
+		Args: cobra.MinimumNArgs(1),
+		RunE: similarCmd.RunE,
+	}
+	simCmd.Flags().IntVarP(&flagSimilarLimit, "limit", "n", db.DefaultSimilarLimit, "max results")
+	simCmd.Flags().Float64Var(&flagSimilarMinScore, "min-score", db.DefaultSimilarMinScore, "minimum similarity")
+
+	root.AddCommand(simCmd)
+
+	return root
+}
+
+func resetSimilarFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagSimilarLimit = db.DefaultSimilarLimit
+	flagSimilarMinScore = db.DefaultSimilarMinScore
+}
+
+func runSimilarJSON(t *testing.T, dbPath string, args ...string) []map[string]any {
+	t.Helper()
+	cmd := newTestSimilarCmd(dbPath)
+	stdout, err := executeCommandCapture(t, cmd, append([]string{"similar", "-j"}, args...)...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	return result
+}
+
+func TestSimilarCommand_ByCommand(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetSimilarFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	exact := testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand("rm -fr ./build", h.ProjectDir, true))
+	near := testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand("rm -rf ./dist", h.ProjectDir, true))
+	testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand("git push --force", h.ProjectDir, true))
+
+	result := runSimilarJSON(t, h.DBPath, "sudo rm -r -f ./build")
+	if len(result) != 2 {
+		t.Fatalf("expected 2 matches, got %d: %v", len(result), result)
+	}
+	if result[0]["request_id"] != exact.ID || result[0]["score"].(float64) != 1 {
+		t.Errorf("expected exact match first, got %v", result[0])
+	}
+	if result[1]["request_id"] != near.ID {
+		t.Errorf("expected near match second, got %v", result[1])
+	}
+	if result[0]["summary"] == "" {
+		t.Error("expected summary to be set")
+	}
+}
+
+func TestSimilarCommand_ByRequestID(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetSimilarFlags()
+
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+
+	past := testutil.MakeRequest(t, h.DB, requestor, testutil.WithCommand("kubectl delete ns preview", h.ProjectDir, true))
+	if err := h.DB.CreateReview(&db.Review{
+		RequestID:         past.ID,
+		ReviewerSessionID: reviewer.ID,
+		ReviewerAgent:     reviewer.AgentName,
+		ReviewerModel:     reviewer.Model,
+		Decision:          db.DecisionApprove,
+		Signature:         "sig",
+	}); err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+	if err := h.DB.CreateOutcome(&db.ExecutionOutcome{RequestID: past.ID, CausedProblems: true, ProblemDescription: "broke CI"}); err != nil {
+		t.Fatalf("CreateOutcome failed: %v", err)
+	}
+	current := testutil.MakeRequest(t, h.DB, requestor, testutil.WithCommand("kubectl delete ns staging", h.ProjectDir, true))
+
+	result := runSimilarJSON(t, h.DBPath, current.ID)
+	if len(result) != 1 {
+		t.Fatalf("expected 1 match (self excluded), got %d: %v", len(result), result)
+	}
+	m := result[0]
+	if m["request_id"] != past.ID {
+		t.Errorf("expected %s, got %v", past.ID, m["request_id"])
+	}
+	if reviewers, _ := m["reviewers"].([]any); len(reviewers) != 1 || reviewers[0] != "Reviewer (approve)" {
+		t.Errorf("unexpected reviewers: %v", m["reviewers"])
+	}
+	if m["caused_problems"] != true || m["problem_description"] != "broke CI" {
+		t.Errorf("expected problem outcome, got %v", m)
+	}
+}
+
+func TestSimilarCommand_UsesRedactedDisplay(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetSimilarFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	req := testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand("mysql -psecret123 -e 'DROP TABLE t'", h.ProjectDir, true))
+	if _, err := h.DB.Exec(`UPDATE requests SET command_display_redacted = ? WHERE id = ?`, "mysql -p***** -e 'DROP TABLE t'", req.ID); err != nil {
+		t.Fatalf("setting redaction: %v", err)
+	}
+
+	// The query matches the underlying command, but only the redacted form is shown.
+	result := runSimilarJSON(t, h.DBPath, "mysql -psecret123 -e 'DROP TABLE t'")
+	if len(result) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(result))
+	}
+	if result[0]["command"] != "mysql -p***** -e 'DROP TABLE t'" {
+		t.Errorf("expected redacted command, got %v", result[0]["command"])
+	}
+}
+
+func TestSimilarCommand_NoMatches(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetSimilarFlags()
+
+	result := runSimilarJSON(t, h.DBPath, "terraform destroy")
+	if len(result) != 0 {
+		t.Errorf("expected no matches, got %v", result)
+	}
+}
@@ -0,0 +1,133 @@
+// Package cli implements the simulate command.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagSimulateCwd    string
+	flagSimulateRedact []string
+)
+
+func init() {
+	simulateCmd.Flags().StringVar(&flagSimulateCwd, "cwd", "", "directory the command would run in (default: current directory)")
+	simulateCmd.Flags().StringSliceVar(&flagSimulateRedact, "redact", nil, "regex patterns to redact from display")
+
+	rootCmd.AddCommand(simulateCmd)
+}
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate <command>",
+	Short: "Show how a command would be classified without creating a request",
+	Long: `Simulate a request for a command without creating one.
+
+The command is normalized and classified exactly as slb request would, and
+the result shows:
+
+  - the risk tier and why, including the risk_overrides rule that matched
+  - the redacted display string reviewers would see
+  - the approvals required, after the dynamic quorum adjustment
+  - whether a rollback capture would be attempted, and of what kind
+  - the dry-run command a preview would run, if one exists
+
+Nothing is executed and nothing is written to the database, which is only
+opened read-only when it exists (trusted scripts and the dynamic quorum
+need it).
+
+Examples:
+  slb simulate "kubectl delete deployment web"
+  slb simulate "rm -rf ./build" --cwd ./service --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSimulate,
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+
+	cwd := flagSimulateCwd
+	if cwd == "" {
+		if cwd, err = os.Getwd(); err != nil {
+			cwd = project
+		}
+	}
+	if abs, err := filepath.Abs(cwd); err == nil {
+		cwd = abs
+	}
+
+	cfg, err := config.Load(config.LoadOptions{
+		ProjectDir: project,
+		ConfigPath: flagConfig,
+		PolicyDir:  cwd,
+	})
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	var dbConn *db.DB
+	if _, err := os.Stat(GetDB()); err == nil {
+		dbConn, err = db.OpenWithOptions(GetDB(), db.OpenOptions{ReadOnly: true})
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+	}
+
+	creator := core.NewRequestCreator(dbConn, nil, nil, toRequestCreatorConfig(cfg))
+	result, err := creator.Simulate(core.SimulateOptions{
+		Command:         args[0],
+		Cwd:             cwd,
+		ProjectPath:     project,
+		RedactPatterns:  redactPatterns(cfg, flagSimulateRedact),
+		CaptureRollback: cfg.General.EnableRollbackCapture,
+	})
+	if err != nil {
+		return fmt.Errorf("simulating request: %w", err)
+	}
+
+	out := output.New(output.Format(GetOutput()))
+	if GetOutput() == "json" {
+		return out.Write(result)
+	}
+
+	tier := string(result.Tier)
+	if tier == "" {
+		tier = "none"
+	}
+	fmt.Printf("Command:   %s\n", result.DisplayRedacted)
+	fmt.Printf("Tier:      %s\n", tier)
+	if result.TierReason != "" {
+		fmt.Printf("Reason:    %s\n", result.TierReason)
+	}
+	if result.RiskOverride != "" {
+		fmt.Printf("Override:  %s\n", result.RiskOverride)
+	}
+	if result.Refused != "" {
+		fmt.Printf("Refused:   %s\n", result.Refused)
+	} else if result.NeedsApproval {
+		fmt.Printf("Approvals: %d\n", result.MinApprovals)
+	} else {
+		fmt.Println("Approvals: none (runs without review)")
+	}
+	if result.DryRunCommand != "" {
+		fmt.Printf("Dry run:   %s\n", result.DryRunCommand)
+	}
+	switch {
+	case result.RollbackCapture:
+		fmt.Printf("Rollback:  %s state captured before execution\n", result.RollbackKind)
+	case result.RollbackKind != "":
+		fmt.Printf("Rollback:  %s (not captured)\n", result.RollbackKind)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestSimulateCmd creates a fresh simulate command for testing.
+func newTestSimulateCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagConfig, "config", "c", "", "config file")
+
+	simCmd := &cobra.Command{
+		Use:  "simulate <command>",
+		Args: cobra.ExactArgs(1),
+		RunE: simulateCmd.RunE,
+	}
+	simCmd.Flags().StringVar(&flagSimulateCwd, "cwd", "", "working directory")
+	simCmd.Flags().StringSliceVar(&flagSimulateRedact, "redact", nil, "redact patterns")
+
+	root.AddCommand(simCmd)
+
+	return root
+}
+
+func resetSimulateFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagConfig = ""
+	flagSimulateCwd = ""
+	flagSimulateRedact = nil
+}
+
+func TestSimulateCommand_JSON(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetSimulateFlags()
+
+	cmd := newTestSimulateCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "simulate", "-C", h.ProjectDir, "-j",
+		"--cwd", h.ProjectDir, "--redact", "TKT-[0-9]+", "kubectl delete deployment web --token=TKT-991")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if result["tier"] != "dangerous" || result["needs_approval"] != true || result["min_approvals"] != float64(1) {
+		t.Errorf("unexpected classification: %v", result)
+	}
+	if result["rollback_kind"] != "kubernetes" {
+		t.Errorf("rollback_kind = %v", result["rollback_kind"])
+	}
+	if dryRun, _ := result["dry_run_command"].(string); !strings.Contains(dryRun, "--dry-run=client") {
+		t.Errorf("dry_run_command = %q", dryRun)
+	}
+	if strings.Contains(stdout, "TKT-991") {
+		t.Errorf("output leaks the redacted pattern: %s", stdout)
+	}
+	if reqs, _ := h.DB.ListAllRequests(h.ProjectDir); len(reqs) != 0 {
+		t.Errorf("expected no requests, got %d", len(reqs))
+	}
+}
+
+func TestSimulateCommand_TextWithoutDatabase(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetSimulateFlags()
+
+	cmd := newTestSimulateCmd(filepath.Join(t.TempDir(), "missing.db"))
+	stdout, err := executeCommandCapture(t, cmd, "simulate", "-C", h.ProjectDir, "--cwd", h.ProjectDir, "rm -rf ./build")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Tier:      dangerous", "Approvals: 1", "Dry run:   ls -la -- ./build", "Rollback:  filesystem"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %q:\n%s", want, stdout)
+		}
+	}
+}
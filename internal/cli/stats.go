@@ -0,0 +1,147 @@
+// Package cli implements the stats command.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagStatsReviewers bool
+	flagStatsRollbacks bool
+)
+
+func init() {
+	statsCmd.Flags().BoolVar(&flagStatsReviewers, "reviewers", false, "include per-reviewer approval analytics")
+	statsCmd.Flags().BoolVar(&flagStatsRollbacks, "rollbacks", false, "include rollback capture and restore stats")
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show request and reviewer statistics",
+	Long: `Show request counts for the project by status and risk tier.
+
+With --reviewers, also show per-reviewer analytics: median time from request
+creation to approval, the share of approvals without comments or responses,
+and streaks of consecutive approvals. Reviewers exceeding the
+agents.reviewer_* thresholds are flagged and a reviewer_pattern_warning event
+is reported for each. With agents.reviewer_pattern_action = "exclude_critical"
+their approvals stop counting toward CRITICAL quorum until another session
+attests them with 'slb policy attest --reviewer <agent>'.
+
+With --rollbacks, also show rollback usage: captures by kind, restore
+attempts, the restore success rate, and failed restores by category
+(symlink_parent, checksum_mismatch, source_gone, other).
+
+Examples:
+  slb stats
+  slb stats --reviewers --json
+  slb stats --rollbacks`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(config.LoadOptions{
+		ProjectDir: project,
+		ConfigPath: flagConfig,
+	})
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	dbConn, err := db.Open(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	requests, err := dbConn.ListAllRequests(project)
+	if err != nil {
+		return fmt.Errorf("listing requests: %w", err)
+	}
+	byStatus := map[string]int{}
+	byTier := map[string]int{}
+	for _, r := range requests {
+		byStatus[string(r.Status)]++
+		byTier[string(r.RiskTier)]++
+	}
+
+	resp := map[string]any{
+		"project":   project,
+		"requests":  len(requests),
+		"by_status": byStatus,
+		"by_tier":   byTier,
+	}
+
+	if flagStatsReviewers {
+		thresholds := toReviewerThresholds(cfg)
+		stats, err := core.ReviewerPatternReport(dbConn, thresholds)
+		if err != nil {
+			return fmt.Errorf("computing reviewer stats: %w", err)
+		}
+		events := reviewerPatternEvents(stats)
+		resp["reviewers"] = stats
+		resp["reviewer_thresholds"] = map[string]any{
+			"fast_approval_seconds":  cfg.Agents.ReviewerFastApprovalSecs,
+			"empty_response_percent": cfg.Agents.ReviewerEmptyResponsePercent,
+			"approval_streak":        cfg.Agents.ReviewerApprovalStreak,
+			"min_reviews":            cfg.Agents.ReviewerMinReviews,
+			"action":                 cfg.Agents.ReviewerPatternAction,
+		}
+		resp["events"] = events
+		if GetOutput() != "json" {
+			for _, s := range stats {
+				if s.Flagged() {
+					fmt.Fprintf(os.Stderr, "Warning: reviewer %s matches rubber-stamp patterns: %s\n",
+						s.Agent, strings.Join(s.Warnings, ", "))
+				}
+			}
+		}
+	}
+
+	if flagStatsRollbacks {
+		events, err := dbConn.ListRollbackEvents(project)
+		if err != nil {
+			return fmt.Errorf("listing rollback events: %w", err)
+		}
+		resp["rollbacks"] = core.SummarizeRollbackEvents(events)
+	}
+
+	out := output.New(output.Format(GetOutput()))
+	return out.Write(resp)
+}
+
+// reviewerPatternEvents builds a reviewer_pattern_warning event for each
+// flagged reviewer.
+func reviewerPatternEvents(stats []core.ReviewerStats) []map[string]any {
+	events := []map[string]any{}
+	for _, s := range stats {
+		if !s.Flagged() {
+			continue
+		}
+		events = append(events, map[string]any{
+			"event":                   core.ReviewerPatternWarningEvent,
+			"agent":                   s.Agent,
+			"warnings":                s.Warnings,
+			"median_approval_seconds": s.MedianApprovalSeconds,
+			"empty_approval_percent":  s.EmptyApprovalPercent,
+			"current_approval_streak": s.CurrentStreak,
+			"excluded_from_critical":  s.ExcludedFromCritical,
+		})
+	}
+	return events
+}
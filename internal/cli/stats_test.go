@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestStatsCmd creates a fresh stats command for testing.
+func newTestStatsCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagConfig, "config", "c", "", "config file")
+
+	sCmd := &cobra.Command{
+		Use:  "stats",
+		Args: cobra.NoArgs,
+		RunE: statsCmd.RunE,
+	}
+	sCmd.Flags().BoolVar(&flagStatsReviewers, "reviewers", false, "reviewer analytics")
+	sCmd.Flags().BoolVar(&flagStatsRollbacks, "rollbacks", false, "rollback stats")
+	root.AddCommand(sCmd)
+
+	return root
+}
+
+func resetStatsFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagConfig = ""
+	flagStatsReviewers = false
+	flagStatsRollbacks = false
+}
+
+func TestStatsCommand_CountsRequests(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetStatsFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	testutil.MakeRequest(t, h.DB, sess, testutil.WithRisk(db.RiskTierCritical))
+	testutil.MakeRequest(t, h.DB, sess, testutil.WithRisk(db.RiskTierDangerous))
+
+	cmd := newTestStatsCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "stats", "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if result["requests"] != float64(2) {
+		t.Errorf("requests = %v, want 2", result["requests"])
+	}
+	if _, ok := result["reviewers"]; ok {
+		t.Error("reviewer analytics should require --reviewers")
+	}
+}
+
+func TestStatsCommand_ReviewersFlagsRubberStamp(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetStatsFlags()
+
+	configPath := filepath.Join(h.ProjectDir, ".slb", "config.toml")
+	if err := os.WriteFile(configPath, []byte("[agents]\nreviewer_approval_streak = 2\nreviewer_min_reviews = 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"))
+	stamp := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Stamp"))
+	for i := 0; i < 2; i++ {
+		req := testutil.MakeRequest(t, h.DB, requestor)
+		if err := h.DB.CreateReview(&db.Review{
+			RequestID:         req.ID,
+			ReviewerSessionID: stamp.ID,
+			ReviewerAgent:     stamp.AgentName,
+			Decision:          db.DecisionApprove,
+		}); err != nil {
+			t.Fatalf("CreateReview failed: %v", err)
+		}
+	}
+
+	cmd := newTestStatsCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "stats", "-C", h.ProjectDir, "--reviewers", "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result struct {
+		Reviewers []core.ReviewerStats `json:"reviewers"`
+		Events    []map[string]any     `json:"events"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if len(result.Reviewers) != 1 || result.Reviewers[0].Agent != "Stamp" || result.Reviewers[0].CurrentStreak != 2 {
+		t.Fatalf("reviewers = %+v", result.Reviewers)
+	}
+	if len(result.Events) != 1 || result.Events[0]["event"] != core.ReviewerPatternWarningEvent || result.Events[0]["agent"] != "Stamp" {
+		t.Errorf("events = %v", result.Events)
+	}
+	if !strings.Contains(stdout, core.ReviewerWarningApprovalStreak) {
+		t.Errorf("expected streak warning in output: %s", stdout)
+	}
+}
+
+func TestStatsCommand_RollbacksReportsRestoreOutcomes(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetStatsFlags()
+	resetRollbackFlags()
+
+	req, buildDir := makeCapturedRequest(t, h)
+	captureDir := core.RollbackDir(h.ProjectDir, req.ID)
+	if err := h.DB.UpdateRequestRollbackPath(req.ID, captureDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DB.RecordRollbackEvent(&db.RollbackEvent{
+		RequestID: req.ID, ProjectPath: h.ProjectDir, Event: db.RollbackEventCapture, Kind: "filesystem", Succeeded: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// One restore succeeds; a second fails because the capture was deleted.
+	if err := os.RemoveAll(buildDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executeCommandCapture(t, newTestRollbackCmd(h.DBPath), "rollback", "restore", req.ID, "-j"); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if err := os.RemoveAll(captureDir); err != nil {
+		t.Fatal(err)
+	}
+	resetRollbackFlags()
+	if _, err := executeCommandCapture(t, newTestRollbackCmd(h.DBPath), "rollback", "restore", req.ID, "--force", "-j"); err == nil {
+		t.Fatal("expected the restore of a deleted capture to fail")
+	}
+
+	cmd := newTestStatsCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "stats", "-C", h.ProjectDir, "--rollbacks", "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result struct {
+		Rollbacks core.RollbackStats `json:"rollbacks"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	rb := result.Rollbacks
+	if rb.CapturesByKind["filesystem"] != 1 || rb.RestoreAttempts != 2 || rb.RestoreSuccesses != 1 || rb.SuccessRate != 0.5 {
+		t.Errorf("rollback stats = %+v", rb)
+	}
+	if rb.FailuresByCategory[core.RestoreFailureSourceGone] != 1 {
+		t.Errorf("failures by category = %v, want one source_gone", rb.FailuresByCategory)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/output"
 	"github.com/spf13/cobra"
@@ -21,14 +22,24 @@ func init() {
 }
 
 var statusCmd = &cobra.Command{
-	Use:   "status <request-id>",
+	Use:   "status [request-id]",
 	Short: "Show status of a request",
 	Long: `Show the current status of a command approval request.
 
 Use --wait to block until the request reaches a terminal state
-(approved, rejected, cancelled, timeout, executed, etc).`,
-	Args: cobra.ExactArgs(1),
+(approved, rejected, cancelled, timeout, executed, etc).
+
+Without a request ID, show how many requests each session has waiting in
+the project's rate-limit queue. Status never admits queued requests; the
+daemon's sweep and the slb run waiting on them do.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			if flagStatusWait {
+				return fmt.Errorf("--wait requires a request ID")
+			}
+			return runQueueSummary()
+		}
 		requestID := args[0]
 
 		dbConn, err := db.Open(GetDB())
@@ -55,6 +66,22 @@ Use --wait to block until the request reaches a terminal state
 			}
 		}
 
+		// Status only reads: a queued request is admitted by the daemon's
+		// sweep or by the slb run waiting on it.
+		var queue *core.QueueStatus
+		if request.Status == db.StatusQueued {
+			if _, rl, err := newProjectRequestCreator(dbConn); err == nil {
+				if queue, err = rl.QueueStatus(requestID); err != nil {
+					return fmt.Errorf("reading queue position: %w", err)
+				}
+			}
+		}
+
+		// The requestor may only see pseudonyms while the request is pending
+		if reviews, _, err = reviewsForViewer(dbConn, request, reviews); err != nil {
+			return err
+		}
+
 		// Build response
 		type reviewView struct {
 			ReviewID  string `json:"review_id"`
@@ -63,6 +90,8 @@ Use --wait to block until the request reaches a terminal state
 			Decision  string `json:"decision"`
 			Comments  string `json:"comments,omitempty"`
 			CreatedAt string `json:"created_at"`
+			// Superseded marks a review from a round whose approval expired.
+			Superseded bool `json:"superseded,omitempty"`
 		}
 
 		type statusView struct {
@@ -75,6 +104,7 @@ Use --wait to block until the request reaches a terminal state
 			Status                string       `json:"status"`
 			MinApprovals          int          `json:"min_approvals"`
 			RequireDifferentModel bool         `json:"require_different_model"`
+			RequireDifferentHost  bool         `json:"require_different_host,omitempty"`
 			RequestorAgent        string       `json:"requestor_agent"`
 			RequestorModel        string       `json:"requestor_model"`
 			ProjectPath           string       `json:"project_path"`
@@ -89,6 +119,8 @@ Use --wait to block until the request reaches a terminal state
 			ApprovalCount         int          `json:"approval_count"`
 			RejectionCount        int          `json:"rejection_count"`
 			Reviews               []reviewView `json:"reviews"`
+			// Queue is set while the rate limiter holds the request.
+			Queue *core.QueueStatus `json:"queue,omitempty"`
 		}
 
 		view := statusView{
@@ -100,6 +132,7 @@ Use --wait to block until the request reaches a terminal state
 			Status:                string(request.Status),
 			MinApprovals:          request.MinApprovals,
 			RequireDifferentModel: request.RequireDifferentModel,
+			RequireDifferentHost:  request.RequireDifferentHost,
 			RequestorAgent:        request.RequestorAgent,
 			RequestorModel:        request.RequestorModel,
 			ProjectPath:           request.ProjectPath,
@@ -110,6 +143,7 @@ Use --wait to block until the request reaches a terminal state
 			CreatedAt:             request.CreatedAt.Format(time.RFC3339),
 			Reviews:               make([]reviewView, 0, len(reviews)),
 		}
+		view.Queue = queue
 
 		if request.Command.DisplayRedacted != "" {
 			view.CommandRedacted = request.Command.DisplayRedacted
@@ -124,21 +158,26 @@ Use --wait to block until the request reaches a terminal state
 			view.ApprovalExpiresAt = request.ApprovalExpiresAt.Format(time.RFC3339)
 		}
 
-		// Count approvals and rejections, build review list
+		// Count approvals and rejections, build review list. Reviews from an
+		// earlier round are listed but not counted.
 		for _, r := range reviews {
-			if r.Decision == db.DecisionApprove {
+			superseded := r.Round != request.ReviewRound
+			switch {
+			case superseded:
+			case r.Decision == db.DecisionApprove:
 				view.ApprovalCount++
-			} else if r.Decision == db.DecisionReject {
+			case r.Decision == db.DecisionReject:
 				view.RejectionCount++
 			}
 
 			rv := reviewView{
-				ReviewID:  r.ID,
-				Reviewer:  r.ReviewerAgent,
-				Model:     r.ReviewerModel,
-				Decision:  string(r.Decision),
-				Comments:  r.Comments,
-				CreatedAt: r.CreatedAt.Format(time.RFC3339),
+				ReviewID:   r.ID,
+				Reviewer:   r.ReviewerAgent,
+				Model:      r.ReviewerModel,
+				Decision:   string(r.Decision),
+				Comments:   r.Comments,
+				CreatedAt:  r.CreatedAt.Format(time.RFC3339),
+				Superseded: superseded,
 			}
 			view.Reviews = append(view.Reviews, rv)
 		}
@@ -147,3 +186,57 @@ Use --wait to block until the request reaches a terminal state
 		return out.Write(view)
 	},
 }
+
+// sessionQueueView is one session's share of the project's rate-limit queue.
+type sessionQueueView struct {
+	SessionID string `json:"session_id"`
+	Agent     string `json:"agent"`
+	Queued    int    `json:"queued"`
+	// Head is the session's next request to be admitted.
+	Head     string `json:"head"`
+	QueuedAt string `json:"queued_at"`
+}
+
+// queueSummaryView is what 'slb status' reports without a request ID.
+type queueSummaryView struct {
+	ProjectPath string             `json:"project_path"`
+	Queued      int                `json:"queued"`
+	Sessions    []sessionQueueView `json:"sessions"`
+}
+
+// runQueueSummary reports the project's queued requests per session. It
+// admits none; that is the daemon's sweep's job.
+func runQueueSummary() error {
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+	dbConn, err := db.Open(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	queued, err := dbConn.ListQueuedRequests(project)
+	if err != nil {
+		return err
+	}
+
+	view := queueSummaryView{ProjectPath: project, Queued: len(queued), Sessions: []sessionQueueView{}}
+	index := make(map[string]int)
+	for _, r := range queued {
+		i, ok := index[r.RequestorSessionID]
+		if !ok {
+			i = len(view.Sessions)
+			index[r.RequestorSessionID] = i
+			view.Sessions = append(view.Sessions, sessionQueueView{
+				SessionID: r.RequestorSessionID,
+				Agent:     r.RequestorAgent,
+				Head:      r.ID,
+				QueuedAt:  r.CreatedAt.Format(time.RFC3339),
+			})
+		}
+		view.Sessions[i].Queued++
+	}
+	return output.New(output.Format(GetOutput())).Write(view)
+}
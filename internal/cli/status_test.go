@@ -2,6 +2,8 @@ package cli
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -22,6 +24,7 @@ func newTestStatusCmd(dbPath string) *cobra.Command {
 	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
 	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
 	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
 
 	root.AddCommand(statusCmd)
 
@@ -33,21 +36,55 @@ func resetStatusFlags() {
 	flagOutput = "text"
 	flagJSON = false
 	flagProject = ""
+	flagSessionID = ""
 	flagStatusWait = false
+	// A prior --help leaves cobra's help flag set on the shared command.
+	if f := statusCmd.Flags().Lookup("help"); f != nil {
+		_ = f.Value.Set("false")
+	}
 }
 
-func TestStatusCommand_RequiresRequestID(t *testing.T) {
+func TestStatusCommand_QueueSummaryPerSession(t *testing.T) {
 	h := testutil.NewHarness(t)
 	resetStatusFlags()
 
-	cmd := newTestStatusCmd(h.DBPath)
-	_, _, err := executeCommand(cmd, "status")
+	cfg := "[integrations]\nagent_mail_enabled = false\n\n[rate_limits]\nmax_pending_per_session = 1\nrate_limit_action = \"queue\"\n"
+	if err := os.WriteFile(filepath.Join(h.ProjectDir, ".slb", "config.toml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
 
-	if err == nil {
-		t.Fatal("expected error when request ID is missing")
+	busy := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	testutil.MakeRequest(t, h.DB, busy, testutil.WithCommand("rm -rf ./build", h.ProjectDir, true))
+	head := testutil.MakeRequest(t, h.DB, busy, testutil.WithCommand("rm -rf ./dist", h.ProjectDir, true), testutil.WithStatus(db.StatusQueued))
+	testutil.MakeRequest(t, h.DB, busy, testutil.WithCommand("rm -rf ./cache", h.ProjectDir, true), testutil.WithStatus(db.StatusQueued))
+	// This session has a free slot, but status only reads and admits nothing.
+	idle := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	waiting := testutil.MakeRequest(t, h.DB, idle, testutil.WithCommand("rm -rf ./tmp", h.ProjectDir, true), testutil.WithStatus(db.StatusQueued))
+
+	cmd := newTestStatusCmd(h.DBPath)
+	stdout, err := executeCommandCapture(t, cmd, "status", "-C", h.ProjectDir, "-j")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if !strings.Contains(err.Error(), "accepts 1 arg") {
-		t.Errorf("unexpected error message: %v", err)
+	var result queueSummaryView
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+	}
+	if result.Queued != 3 || len(result.Sessions) != 2 {
+		t.Fatalf("expected 3 queued requests in two sessions, got %+v", result)
+	}
+	if s := result.Sessions[0]; s.SessionID != busy.ID || s.Queued != 2 || s.Head != head.ID {
+		t.Errorf("unexpected session summary %+v", s)
+	}
+	if s := result.Sessions[1]; s.SessionID != idle.ID || s.Queued != 1 || s.Head != waiting.ID {
+		t.Errorf("unexpected session summary %+v", s)
+	}
+	if got, _ := h.DB.GetRequest(waiting.ID); got.Status != db.StatusQueued {
+		t.Errorf("expected status to leave the idle session's request queued, got %s", got.Status)
+	}
+
+	if _, err := executeCommandCapture(t, cmd, "status", "--wait"); err == nil {
+		t.Error("expected --wait without a request ID to fail")
 	}
 }
 
@@ -199,3 +236,68 @@ func TestStatusCommand_Help(t *testing.T) {
 		t.Error("expected help to mention '--wait' flag")
 	}
 }
+
+func TestStatusCommand_AnonymizesReviewersForRequestor(t *testing.T) {
+	h := testutil.NewHarness(t)
+	t.Setenv("SLB_ANONYMIZE_REVIEWERS", "true")
+	t.Setenv("SLB_REVIEW_AUDITORS", "Auditor")
+
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"), testutil.WithModel("model-a"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"), testutil.WithModel("model-b"))
+	auditor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Auditor"), testutil.WithModel("model-c"))
+
+	req := testutil.MakeRequest(t, h.DB, requestor, testutil.WithCommand("rm -rf ./build", h.ProjectDir, true))
+	if err := h.DB.CreateReview(&db.Review{
+		RequestID:         req.ID,
+		ReviewerSessionID: reviewer.ID,
+		ReviewerAgent:     reviewer.AgentName,
+		ReviewerModel:     reviewer.Model,
+		Decision:          db.DecisionApprove,
+	}); err != nil {
+		t.Fatalf("failed to create review: %v", err)
+	}
+
+	reviewerAs := func(sessionID string) map[string]any {
+		t.Helper()
+		resetStatusFlags()
+		cmd := newTestStatusCmd(h.DBPath)
+		args := []string{"status", req.ID, "-j"}
+		if sessionID != "" {
+			args = append(args, "-s", sessionID)
+		}
+		stdout, err := executeCommandCapture(t, cmd, args...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var result map[string]any
+		if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+			t.Fatalf("failed to parse JSON: %v\nstdout: %s", err, stdout)
+		}
+		reviews := result["reviews"].([]any)
+		if len(reviews) != 1 {
+			t.Fatalf("expected 1 review, got %d", len(reviews))
+		}
+		return reviews[0].(map[string]any)
+	}
+
+	if r := reviewerAs(requestor.ID); r["reviewer"] != "reviewer A" || r["model"] != "" {
+		t.Errorf("requestor should see an anonymized reviewer while pending, got %v", r)
+	}
+	if r := reviewerAs(""); r["reviewer"] != "reviewer A" {
+		t.Errorf("a viewer without a session should see an anonymized reviewer while pending, got %v", r)
+	}
+	if r := reviewerAs("sess-unknown"); r["reviewer"] != "reviewer A" {
+		t.Errorf("an unknown session should see an anonymized reviewer while pending, got %v", r)
+	}
+	if r := reviewerAs(auditor.ID); r["reviewer"] != "Reviewer" {
+		t.Errorf("auditor should see the real reviewer, got %v", r)
+	}
+	if r := reviewerAs(reviewer.ID); r["reviewer"] != "Reviewer" {
+		t.Errorf("a known non-requestor should see the real reviewer, got %v", r)
+	}
+
+	h.DB.Exec(`UPDATE requests SET status = ? WHERE id = ?`, db.StatusApproved, req.ID)
+	if r := reviewerAs(requestor.ID); r["reviewer"] != "Reviewer" || r["model"] != "model-b" {
+		t.Errorf("requestor should see the real reviewer after resolution, got %v", r)
+	}
+}
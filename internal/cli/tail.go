@@ -0,0 +1,357 @@
+// Package cli implements the tail command: recent request activity for humans.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagTailLines  int
+	flagTailFollow bool
+)
+
+// tailPollInterval is how often --follow re-reads the database when the
+// daemon is not running.
+const tailPollInterval = 2 * time.Second
+
+func init() {
+	tailCmd.Flags().IntVarP(&flagTailLines, "lines", "n", 20, "number of recent events to show")
+	tailCmd.Flags().BoolVarP(&flagTailFollow, "follow", "f", false, "keep printing new events as they happen")
+
+	rootCmd.AddCommand(tailCmd)
+}
+
+var tailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Show recent request activity",
+	Long: `Print the project's most recent request events, one compact line each:
+requests created, approved, rejected and executed.
+
+With --follow, new events are printed as they happen, like 'tail -f'. Events
+stream from the daemon when it is running; otherwise the database is polled.
+
+'slb watch' streams the same events as NDJSON for programs; 'slb tail' is for
+people.
+
+Examples:
+  slb tail
+  slb tail -n 50
+  slb tail -f`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagTailLines < 0 {
+			return fmt.Errorf("--lines must not be negative")
+		}
+		project, err := projectPath()
+		if err != nil {
+			return err
+		}
+
+		dbConn, err := db.Open(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		events, err := requestEventHistory(dbConn, project)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		for _, e := range lastTailEvents(events, flagTailLines) {
+			fmt.Fprintln(out, formatTailLine(e))
+		}
+		if !flagTailFollow {
+			return nil
+		}
+
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sigCh)
+		go func() {
+			select {
+			case <-sigCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		if daemon.NewClient().IsDaemonRunning() {
+			return followTailDaemon(ctx, project, out)
+		}
+		return followTailPolling(ctx, dbConn, project, events, out)
+	},
+}
+
+// requestEventHistory reconstructs the project's request events, oldest
+// first: each request's creation, its reviews, and its execution. Reviewers
+// of unresolved requests are named as the invoking session may see them.
+func requestEventHistory(dbConn *db.DB, project string) ([]daemon.RequestStreamEvent, error) {
+	cfg, err := config.Load(config.LoadOptions{ProjectDir: project, ConfigPath: flagConfig})
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	reviewSvc := core.NewReviewService(dbConn, toReviewConfig(cfg))
+	reviewSvc.SetViewer(core.ResolveReviewViewer(dbConn, flagSessionID))
+
+	requests, err := dbConn.ListAllRequests(project)
+	if err != nil {
+		return nil, fmt.Errorf("listing requests: %w", err)
+	}
+	byID := make(map[string]*db.Request, len(requests))
+	var events []daemon.RequestStreamEvent
+	for _, r := range requests {
+		byID[r.ID] = r
+		events = append(events, tailEvent("request_pending", r, r.CreatedAt))
+		if r.Execution != nil && r.Execution.ExecutedAt != nil {
+			e := tailEvent("request_executed", r, *r.Execution.ExecutedAt)
+			e.ExitCode = r.Execution.ExitCode
+			events = append(events, e)
+		}
+	}
+
+	activity, err := dbConn.ListReviewActivity(db.ReviewActivityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var reviewed []string
+	reviews := make(map[string][]*db.Review)
+	for _, a := range activity {
+		id := a.Review.RequestID
+		if byID[id] == nil {
+			continue
+		}
+		if reviews[id] == nil {
+			reviewed = append(reviewed, id)
+		}
+		reviews[id] = append(reviews[id], a.Review)
+	}
+	for _, id := range reviewed {
+		r := byID[id]
+		shown, _ := reviewSvc.ReviewsForViewer(r, reviews[id])
+		for _, review := range shown {
+			var e daemon.RequestStreamEvent
+			if review.Decision == db.DecisionApprove {
+				e = tailEvent("request_approved", r, review.CreatedAt)
+				e.ApprovedBy = review.ReviewerAgent
+			} else {
+				e = tailEvent("request_rejected", r, review.CreatedAt)
+				e.RejectedBy = review.ReviewerAgent
+				e.Reason = review.Comments
+			}
+			events = append(events, e)
+		}
+	}
+
+	// Creation sorts before anything else that happened in the same second.
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].CreatedAt != events[j].CreatedAt {
+			return events[i].CreatedAt < events[j].CreatedAt
+		}
+		return events[i].Event == "request_pending" && events[j].Event != "request_pending"
+	})
+	return events, nil
+}
+
+// tailEvent builds an event of type for r that happened at.
+func tailEvent(eventType string, r *db.Request, at time.Time) daemon.RequestStreamEvent {
+	command := r.Command.DisplayRedacted
+	if command == "" {
+		command = r.Command.Raw
+	}
+	return daemon.RequestStreamEvent{
+		Event:     eventType,
+		RequestID: r.ID,
+		Project:   r.ProjectPath,
+		RiskTier:  string(r.RiskTier),
+		Command:   command,
+		Requestor: r.RequestorAgent,
+		CreatedAt: at.UTC().Format(time.RFC3339),
+	}
+}
+
+// lastTailEvents returns the last n events.
+func lastTailEvents(events []daemon.RequestStreamEvent, n int) []daemon.RequestStreamEvent {
+	if n >= len(events) {
+		return events
+	}
+	return events[len(events)-n:]
+}
+
+// tailEventKeys identifies each event so polling prints it once. The
+// reviewer is left out, since a pseudonym becomes the real name once the
+// request resolves; reviews of one request in the same second are told
+// apart by their order instead.
+func tailEventKeys(events []daemon.RequestStreamEvent) []string {
+	keys := make([]string, len(events))
+	seen := make(map[string]int, len(events))
+	for i, e := range events {
+		key := strings.Join([]string{e.Event, e.RequestID, e.CreatedAt}, "\x00")
+		keys[i] = fmt.Sprintf("%s\x00%d", key, seen[key])
+		seen[key]++
+	}
+	return keys
+}
+
+// followTailPolling prints events that appear in the database after seen.
+func followTailPolling(ctx context.Context, dbConn *db.DB, project string, seen []daemon.RequestStreamEvent, out io.Writer) error {
+	printed := make(map[string]bool, len(seen))
+	for _, key := range tailEventKeys(seen) {
+		printed[key] = true
+	}
+
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			events, err := requestEventHistory(dbConn, project)
+			if err != nil {
+				return err
+			}
+			for i, key := range tailEventKeys(events) {
+				if !printed[key] {
+					printed[key] = true
+					fmt.Fprintln(out, formatTailLine(events[i]))
+				}
+			}
+		}
+	}
+}
+
+// followTailDaemon prints the project's request events as the daemon
+// publishes them.
+func followTailDaemon(ctx context.Context, project string, out io.Writer) error {
+	ipcClient := daemon.NewIPCClient(daemon.DefaultSocketPath())
+	defer ipcClient.Close()
+
+	events, err := ipcClient.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("subscribing to events: %w", err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			e := daemon.ToRequestStreamEvent(event)
+			if e.RequestID == "" || (e.Project != "" && e.Project != project) {
+				continue
+			}
+			fmt.Fprintln(out, formatTailLine(*e))
+		}
+	}
+}
+
+// tailLabels are the short labels of the request events.
+var tailLabels = map[string]string{
+	"request_pending":          "CREATED",
+	"request_queued":           "QUEUED",
+	"request_approved":         "APPROVED",
+	"request_rejected":         "REJECTED",
+	"request_executed":         "EXECUTED",
+	"request_timeout":          "TIMEOUT",
+	"request_cancelled":        "CANCELLED",
+	"request_approval_expired": "EXPIRED",
+	"request_rerequested":      "REREQUESTED",
+}
+
+// formatTailLine renders an event as one line, e.g.
+// "Jan 02 10:30:15 APPROVED  abc12345 DANGEROUS rm -rf ./build  by GreenLake".
+// Colors follow the help output's palette and are dropped when the output
+// is not a terminal.
+func formatTailLine(e daemon.RequestStreamEvent) string {
+	when := e.CreatedAt
+	if t, err := time.Parse(time.RFC3339, e.CreatedAt); err == nil {
+		when = t.Local().Format("Jan 02 15:04:05")
+	}
+
+	label, ok := tailLabels[e.Event]
+	if !ok {
+		label = strings.ToUpper(strings.TrimPrefix(e.Event, "request_"))
+	}
+	by := func(agent string) string {
+		if agent == "" {
+			return ""
+		}
+		return "by " + agent
+	}
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(colorBlue)
+	detail := ""
+	switch e.Event {
+	case "request_pending", "request_rerequested":
+		detail = by(e.Requestor)
+	case "request_approved":
+		labelStyle = labelStyle.Foreground(colorGreen)
+		detail = by(e.ApprovedBy)
+	case "request_rejected":
+		labelStyle = labelStyle.Foreground(colorRed)
+		detail = by(e.RejectedBy)
+		if e.Reason != "" {
+			detail += ": " + e.Reason
+		}
+	case "request_executed":
+		labelStyle = labelStyle.Foreground(colorGreen)
+		if e.ExitCode != nil {
+			detail = fmt.Sprintf("exit %d", *e.ExitCode)
+			if *e.ExitCode != 0 {
+				labelStyle = labelStyle.Foreground(colorRed)
+			}
+		}
+	case "request_timeout", "request_cancelled", "request_approval_expired":
+		labelStyle = labelStyle.Foreground(colorOverlay)
+	}
+
+	tier := strings.ToUpper(e.RiskTier)
+	tierStyle := mutedStyle
+	switch e.RiskTier {
+	case string(db.RiskTierCritical):
+		tierStyle = criticalStyle
+	case string(db.RiskTierDangerous):
+		tierStyle = dangerousStyle
+	case string(db.RiskTierCaution):
+		tierStyle = cautionStyle
+	}
+
+	id := e.RequestID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	command := strings.Join(strings.Fields(e.Command), " ")
+	if len(command) > 60 {
+		command = command[:57] + "..."
+	}
+
+	line := fmt.Sprintf("%s %s %s %s %s",
+		mutedStyle.Render(when),
+		labelStyle.Render(fmt.Sprintf("%-9s", label)),
+		id,
+		tierStyle.Render(fmt.Sprintf("%-9s", tier)),
+		commandStyle.Render(command))
+	if detail != "" {
+		line += "  " + mutedStyle.Render(detail)
+	}
+	return line
+}
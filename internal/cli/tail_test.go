@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestTailCmd creates a fresh tail command for testing.
+func newTestTailCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
+
+	root.AddCommand(tailCmd)
+
+	return root
+}
+
+func resetTailFlags() {
+	flagDB = ""
+	flagProject = ""
+	flagSessionID = ""
+	flagTailLines = 20
+	flagTailFollow = false
+}
+
+func TestFormatTailLine(t *testing.T) {
+	at := time.Date(2026, 3, 4, 10, 30, 15, 0, time.UTC)
+	when := at.Local().Format("Jan 02 15:04:05")
+	exit0, exit1 := 0, 1
+
+	tests := []struct {
+		name  string
+		event daemon.RequestStreamEvent
+		want  []string
+	}{
+		{
+			name:  "created",
+			event: daemon.RequestStreamEvent{Event: "request_pending", RequestID: "abcdef123456", RiskTier: "dangerous", Command: "rm -rf ./build", Requestor: "GreenLake"},
+			want:  []string{when, "CREATED", "abcdef12 ", "DANGEROUS", "rm -rf ./build", "by GreenLake"},
+		},
+		{
+			name:  "approved",
+			event: daemon.RequestStreamEvent{Event: "request_approved", RequestID: "abc", RiskTier: "critical", Command: "git push -f", ApprovedBy: "BlueLake"},
+			want:  []string{"APPROVED", "CRITICAL", "by BlueLake"},
+		},
+		{
+			name:  "rejected with reason",
+			event: daemon.RequestStreamEvent{Event: "request_rejected", RequestID: "abc", RejectedBy: "RedStone", Reason: "too broad"},
+			want:  []string{"REJECTED", "by RedStone: too broad"},
+		},
+		{
+			name:  "executed",
+			event: daemon.RequestStreamEvent{Event: "request_executed", RequestID: "abc", ExitCode: &exit0},
+			want:  []string{"EXECUTED", "exit 0"},
+		},
+		{
+			name:  "failed",
+			event: daemon.RequestStreamEvent{Event: "request_executed", RequestID: "abc", ExitCode: &exit1},
+			want:  []string{"EXECUTED", "exit 1"},
+		},
+		{
+			name:  "other event",
+			event: daemon.RequestStreamEvent{Event: "request_execution_queued", RequestID: "abc"},
+			want:  []string{"EXECUTION_QUEUED"},
+		},
+		{
+			name:  "multi-line command collapsed",
+			event: daemon.RequestStreamEvent{Event: "request_pending", RequestID: "abc", Command: "cat <<EOF\nhello\nEOF"},
+			want:  []string{"cat <<EOF hello EOF"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.CreatedAt = at.Format(time.RFC3339)
+			line := formatTailLine(tt.event)
+			if strings.Contains(line, "\n") {
+				t.Errorf("expected a single line, got %q", line)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("line %q missing %q", line, want)
+				}
+			}
+		})
+	}
+
+	long := formatTailLine(daemon.RequestStreamEvent{Event: "request_pending", Command: strings.Repeat("x", 100)})
+	if strings.Contains(long, strings.Repeat("x", 61)) || !strings.Contains(long, "...") {
+		t.Errorf("expected a long command to be truncated, got %q", long)
+	}
+}
+
+func TestLastTailEvents(t *testing.T) {
+	events := make([]daemon.RequestStreamEvent, 5)
+	for i := range events {
+		events[i].RequestID = string(rune('a' + i))
+	}
+	for _, tt := range []struct {
+		n    int
+		want string
+	}{
+		{0, ""},
+		{2, "de"},
+		{5, "abcde"},
+		{10, "abcde"},
+	} {
+		var got strings.Builder
+		for _, e := range lastTailEvents(events, tt.n) {
+			got.WriteString(e.RequestID)
+		}
+		if got.String() != tt.want {
+			t.Errorf("lastTailEvents(n=%d) = %q, want %q", tt.n, got.String(), tt.want)
+		}
+	}
+}
+
+func TestRequestEventHistory(t *testing.T) {
+	h := testutil.NewHarness(t)
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("GreenLake"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("BlueLake"))
+	other := testutil.MakeSession(t, h.DB, testutil.WithAgent("Elsewhere"))
+
+	req := testutil.MakeRequest(t, h.DB, requestor, testutil.WithCommand("rm -rf ./build", h.ProjectDir, true))
+	testutil.MakeRequest(t, h.DB, other)
+	if _, err := core.NewReviewService(h.DB, core.DefaultReviewConfig()).SubmitReview(core.ReviewOptions{
+		SessionID: reviewer.ID, SessionKey: reviewer.SessionKey, RequestID: req.ID, Decision: db.DecisionApprove,
+	}); err != nil {
+		t.Fatalf("SubmitReview: %v", err)
+	}
+	exitCode := 0
+	executedAt := time.Now().UTC().Add(time.Minute)
+	if err := h.DB.UpdateRequestExecution(req.ID, &db.Execution{ExecutedAt: &executedAt, ExitCode: &exitCode}); err != nil {
+		t.Fatalf("UpdateRequestExecution: %v", err)
+	}
+
+	events, err := requestEventHistory(h.DB, h.ProjectDir)
+	if err != nil {
+		t.Fatalf("requestEventHistory: %v", err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Event)
+	}
+	want := "request_pending request_approved request_executed"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("events = %v, want %s", got, want)
+	}
+	if events[1].ApprovedBy != "BlueLake" || events[2].ExitCode == nil || *events[2].ExitCode != 0 {
+		t.Errorf("unexpected events %+v", events)
+	}
+
+	resetTailFlags()
+	stdout, err := executeCommandCapture(t, newTestTailCmd(h.DBPath), "tail", "-n", "2", "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("tail: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "APPROVED") || !strings.Contains(lines[1], "EXECUTED") {
+		t.Errorf("expected the last two events, got %q", stdout)
+	}
+
+	resetTailFlags()
+	if _, err := executeCommandCapture(t, newTestTailCmd(h.DBPath), "tail", "-n", "-1", "-C", h.ProjectDir); err == nil {
+		t.Error("expected a negative -n to fail")
+	}
+}
+
+func TestRequestEventHistory_AnonymizesPendingReviewers(t *testing.T) {
+	h := testutil.NewHarness(t)
+	t.Setenv("SLB_ANONYMIZE_REVIEWERS", "true")
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("GreenLake"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("BlueLake"))
+
+	req := testutil.MakeRequest(t, h.DB, requestor, testutil.WithMinApprovals(2))
+	if err := h.DB.CreateReview(&db.Review{
+		RequestID: req.ID, ReviewerSessionID: reviewer.ID, ReviewerAgent: reviewer.AgentName, Decision: db.DecisionApprove,
+	}); err != nil {
+		t.Fatalf("CreateReview: %v", err)
+	}
+
+	approvedBy := func(sessionID string) (string, string) {
+		t.Helper()
+		resetTailFlags()
+		flagSessionID = sessionID
+		events, err := requestEventHistory(h.DB, h.ProjectDir)
+		if err != nil || len(events) != 2 {
+			t.Fatalf("requestEventHistory: %v, %+v", err, events)
+		}
+		return events[1].ApprovedBy, tailEventKeys(events)[1]
+	}
+	hidden, hiddenKey := approvedBy("")
+	if hidden != "reviewer A" {
+		t.Errorf("viewer without a session sees %q while pending, want a pseudonym", hidden)
+	}
+	if got, _ := approvedBy(requestor.ID); got != "reviewer A" {
+		t.Errorf("requestor sees %q while pending, want a pseudonym", got)
+	}
+	if got, _ := approvedBy(reviewer.ID); got != "BlueLake" {
+		t.Errorf("reviewer sees %q, want the real name", got)
+	}
+
+	if err := h.DB.UpdateRequestStatus(req.ID, db.StatusApproved); err != nil {
+		t.Fatalf("UpdateRequestStatus: %v", err)
+	}
+	revealed, revealedKey := approvedBy("")
+	if revealed != "BlueLake" {
+		t.Errorf("resolved request shows %q, want the real name", revealed)
+	}
+	if revealedKey != hiddenKey {
+		t.Error("revealing the reviewer changed the event key, so --follow would print it twice")
+	}
+}
@@ -0,0 +1,334 @@
+// Package cli implements the trust command for pre-approved scripts.
+package cli
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagTrustKey     string
+	flagTrustComment string
+	flagTrustAll     bool
+)
+
+func init() {
+	trustAddCmd.Flags().StringVarP(&flagTrustKey, "session-key", "k", "", "reviewer session HMAC key for signing (required)")
+	trustAddCmd.Flags().StringVarP(&flagTrustComment, "comment", "m", "", "note recorded with the trust entry")
+	trustRevokeCmd.Flags().StringVarP(&flagTrustKey, "session-key", "k", "", "session HMAC key (required)")
+	trustListCmd.Flags().BoolVar(&flagTrustAll, "all", false, "include revoked entries")
+
+	trustCmd.AddCommand(trustAddCmd)
+	trustCmd.AddCommand(trustListCmd)
+	trustCmd.AddCommand(trustVerifyCmd)
+	trustCmd.AddCommand(trustRevokeCmd)
+	rootCmd.AddCommand(trustCmd)
+}
+
+var trustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Manage trusted scripts",
+	Long: `Manage the project's trusted scripts: vetted scripts whose invocation is
+reviewed one tier lighter while their contents are unchanged.
+
+A trust entry pins the script's SHA-256 and is signed by the reviewer who
+trusted it. When a request runs a trusted script (./ops/restart.sh,
+bash ops/restart.sh) whose current hash matches, its tier is lowered one step
+but not below general.trusted_script_floor (default caution), and the tier
+reason names the trust entry. An edited script voids the trust: the request
+keeps its full tier and the mismatch is reported. The script is checked
+again before execution.`,
+}
+
+var trustAddCmd = &cobra.Command{
+	Use:   "add <script>...",
+	Short: "Trust scripts at their current contents",
+	Long: `Record a signed trust entry for each script, pinning its current SHA-256.
+Scripts must be regular files inside the project. Trusting a script again
+replaces its previous entry.
+
+Examples:
+  slb trust add ops-scripts/restart.sh -s <reviewer-session> -k <key>
+  slb trust add ops-scripts/*.sh -s <id> -k <key> -m "vetted in ops review"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runTrustAdd,
+}
+
+var trustListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List trusted scripts and whether they still match",
+	Args:  cobra.NoArgs,
+	RunE:  runTrustList,
+}
+
+var trustVerifyCmd = &cobra.Command{
+	Use:   "verify [script...]",
+	Short: "Check trusted scripts against their pinned hashes",
+	Long: `Check every active trust entry (or those for the given scripts) against
+the script's current contents and the entry's signature. Exits non-zero if
+any entry no longer holds.`,
+	RunE: runTrustVerify,
+}
+
+var trustRevokeCmd = &cobra.Command{
+	Use:   "revoke <script|trust-id>",
+	Short: "Revoke a script's trust entry",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTrustRevoke,
+}
+
+func runTrustAdd(cmd *cobra.Command, args []string) error {
+	project, cwd, err := trustPaths()
+	if err != nil {
+		return err
+	}
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	sess, err := keyedSession(dbConn, flagTrustKey)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*db.TrustedScript, 0, len(args))
+	for _, arg := range args {
+		rel, err := core.ResolveTrustedScriptPath(project, cwd, arg)
+		if err != nil {
+			return fmt.Errorf("trusting %s: %w", arg, err)
+		}
+		sum, err := core.HashScript(filepath.Join(project, filepath.FromSlash(rel)))
+		if err != nil {
+			return fmt.Errorf("hashing %s: %w", arg, err)
+		}
+		now := time.Now().UTC()
+		entry := &db.TrustedScript{
+			ProjectPath: project,
+			Path:        rel,
+			SHA256:      sum,
+			SessionID:   sess.ID,
+			AgentName:   sess.AgentName,
+			Model:       sess.Model,
+			Signature:   db.ComputeTrustSignature(sess.SessionKey, project, rel, sum, now),
+			Comment:     flagTrustComment,
+			CreatedAt:   now,
+		}
+		if err := dbConn.CreateTrustedScript(entry); err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+
+	if GetOutput() == "json" {
+		return output.New(output.FormatJSON).Write(entries)
+	}
+	for _, e := range entries {
+		fmt.Printf("Trusted %s (sha256 %s) as %s\n", e.Path, e.SHA256[:12], e.ID)
+	}
+	return nil
+}
+
+func runTrustList(cmd *cobra.Command, args []string) error {
+	project, err := projectPath()
+	if err != nil {
+		return err
+	}
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	checks, err := core.TrustTable(dbConn, project, flagTrustAll)
+	if err != nil {
+		return err
+	}
+	if GetOutput() == "json" {
+		return output.New(output.FormatJSON).Write(checks)
+	}
+	if len(checks) == 0 {
+		fmt.Printf("No trusted scripts in %s\n", project)
+		return nil
+	}
+	return writeTrustTable(checks)
+}
+
+func runTrustVerify(cmd *cobra.Command, args []string) error {
+	project, cwd, err := trustPaths()
+	if err != nil {
+		return err
+	}
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	checks, err := core.TrustTable(dbConn, project, false)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		wanted := make(map[string]bool, len(args))
+		for _, arg := range args {
+			rel, err := core.ResolveTrustedScriptPath(project, cwd, arg)
+			if err != nil {
+				return fmt.Errorf("verifying %s: %w", arg, err)
+			}
+			wanted[rel] = true
+		}
+		filtered := checks[:0]
+		for _, c := range checks {
+			if wanted[c.Entry.Path] {
+				filtered = append(filtered, c)
+				delete(wanted, c.Entry.Path)
+			}
+		}
+		for rel := range wanted {
+			return fmt.Errorf("%s is not trusted", rel)
+		}
+		checks = filtered
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if !c.Holds() {
+			failed++
+		}
+	}
+	if GetOutput() == "json" {
+		if err := output.New(output.FormatJSON).Write(checks); err != nil {
+			return err
+		}
+	} else if len(checks) == 0 {
+		fmt.Printf("No trusted scripts in %s\n", project)
+	} else if err := writeTrustTable(checks); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d trusted script(s) no longer match their trust entry", failed)
+	}
+	return nil
+}
+
+func runTrustRevoke(cmd *cobra.Command, args []string) error {
+	project, cwd, err := trustPaths()
+	if err != nil {
+		return err
+	}
+	dbConn, err := db.OpenAndMigrate(GetDB())
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer dbConn.Close()
+
+	sess, err := keyedSession(dbConn, flagTrustKey)
+	if err != nil {
+		return err
+	}
+	entry, err := findTrustEntry(dbConn, project, cwd, args[0])
+	if err != nil {
+		return err
+	}
+	if err := dbConn.RevokeTrustedScript(entry.ID, sess.AgentName, time.Now()); err != nil {
+		return err
+	}
+
+	if GetOutput() == "json" {
+		return output.New(output.FormatJSON).Write(map[string]any{
+			"id":         entry.ID,
+			"path":       entry.Path,
+			"revoked_by": sess.AgentName,
+		})
+	}
+	fmt.Printf("Revoked trust %s for %s\n", entry.ID, entry.Path)
+	return nil
+}
+
+// trustPaths returns the project and the directory script arguments are
+// relative to.
+func trustPaths() (string, string, error) {
+	project, err := projectPath()
+	if err != nil {
+		return "", "", err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = project
+	}
+	return project, cwd, nil
+}
+
+// keyedSession loads the session named by --session-id and checks that it is
+// active and matches key, the command's --session-key.
+func keyedSession(dbConn *db.DB, key string) (*db.Session, error) {
+	if flagSessionID == "" {
+		return nil, fmt.Errorf("--session-id is required")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("--session-key is required")
+	}
+	sess, err := dbConn.GetSession(flagSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("getting session: %w", err)
+	}
+	if sess.EndedAt != nil {
+		return nil, fmt.Errorf("session %s has ended", sess.ID)
+	}
+	if subtle.ConstantTimeCompare([]byte(key), []byte(sess.SessionKey)) != 1 {
+		return nil, core.ErrSessionKeyMismatch
+	}
+	return sess, nil
+}
+
+// findTrustEntry returns the active entry for a script path, or the entry
+// whose ID starts with ref.
+func findTrustEntry(dbConn *db.DB, project, cwd, ref string) (*db.TrustedScript, error) {
+	if rel, err := core.ResolveTrustedScriptPath(project, cwd, ref); err == nil {
+		entry, err := dbConn.ActiveTrustedScript(project, rel)
+		if err == nil || !errors.Is(err, db.ErrTrustedScriptNotFound) {
+			return entry, err
+		}
+	}
+	entries, err := dbConn.ListTrustedScripts(project, false)
+	if err != nil {
+		return nil, err
+	}
+	var match *db.TrustedScript
+	for _, e := range entries {
+		if ref != "" && strings.HasPrefix(e.ID, ref) {
+			if match != nil {
+				return nil, fmt.Errorf("trust ID %s is ambiguous", ref)
+			}
+			match = e
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: %s", db.ErrTrustedScriptNotFound, ref)
+	}
+	return match, nil
+}
+
+func writeTrustTable(checks []core.TrustVerification) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPATH\tSHA256\tTRUSTED_BY\tTRUSTED_AT\tSTATUS")
+	for _, c := range checks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.Entry.ID[:8], c.Entry.Path, c.Entry.SHA256[:12], c.Entry.AgentName,
+			c.Entry.CreatedAt.Format(time.RFC3339), c.Status)
+	}
+	return w.Flush()
+}
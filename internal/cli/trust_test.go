@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestTrustCmd creates a fresh trust command for testing.
+func newTestTrustCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.PersistentFlags().StringVarP(&flagProject, "project", "C", "", "project directory")
+	root.PersistentFlags().StringVarP(&flagSessionID, "session-id", "s", "", "session ID")
+
+	root.AddCommand(trustCmd)
+
+	return root
+}
+
+func resetTrustFlags() {
+	flagDB = ""
+	flagOutput = "text"
+	flagJSON = false
+	flagProject = ""
+	flagSessionID = ""
+	flagTrustKey = ""
+	flagTrustComment = ""
+	flagTrustAll = false
+}
+
+func TestTrustCommand_AddVerifyRevoke(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetTrustFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+	script := filepath.Join(h.ProjectDir, "ops", "restart.sh")
+	if err := os.MkdirAll(filepath.Dir(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsystemctl restart app\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, err := executeCommandCapture(t, newTestTrustCmd(h.DBPath), "trust", "add", script,
+		"-C", h.ProjectDir, "-s", sess.ID, "-k", sess.SessionKey, "-m", "vetted", "-j")
+	if err != nil {
+		t.Fatalf("trust add: %v", err)
+	}
+	var added []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &added); err != nil {
+		t.Fatalf("parsing add output %q: %v", stdout, err)
+	}
+	if len(added) != 1 || added[0]["path"] != "ops/restart.sh" || added[0]["agent_name"] != "Reviewer" {
+		t.Fatalf("unexpected add output %v", added)
+	}
+
+	resetTrustFlags()
+	stdout, err = executeCommandCapture(t, newTestTrustCmd(h.DBPath), "trust", "verify", "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("trust verify: %v", err)
+	}
+	if !strings.Contains(stdout, "ops/restart.sh") || !strings.Contains(stdout, "verified") {
+		t.Errorf("expected the script to verify, got %q", stdout)
+	}
+
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nrm -rf /\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	resetTrustFlags()
+	stdout, err = executeCommandCapture(t, newTestTrustCmd(h.DBPath), "trust", "verify", "-C", h.ProjectDir)
+	if err == nil || !strings.Contains(err.Error(), "no longer match") {
+		t.Errorf("expected verify to fail for an edited script, got %v", err)
+	}
+	if !strings.Contains(stdout, "modified") {
+		t.Errorf("expected the table to report the script as modified, got %q", stdout)
+	}
+
+	resetTrustFlags()
+	if _, err := executeCommandCapture(t, newTestTrustCmd(h.DBPath), "trust", "revoke", script,
+		"-C", h.ProjectDir, "-s", sess.ID, "-k", sess.SessionKey); err != nil {
+		t.Fatalf("trust revoke: %v", err)
+	}
+
+	resetTrustFlags()
+	stdout, err = executeCommandCapture(t, newTestTrustCmd(h.DBPath), "trust", "list", "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("trust list: %v", err)
+	}
+	if !strings.Contains(stdout, "No trusted scripts") {
+		t.Errorf("expected no active entries after revoke, got %q", stdout)
+	}
+
+	resetTrustFlags()
+	stdout, err = executeCommandCapture(t, newTestTrustCmd(h.DBPath), "trust", "list", "--all", "-C", h.ProjectDir)
+	if err != nil {
+		t.Fatalf("trust list --all: %v", err)
+	}
+	if !strings.Contains(stdout, "revoked") {
+		t.Errorf("expected the revoked entry with --all, got %q", stdout)
+	}
+}
+
+func TestTrustCommand_AddRequiresSessionKey(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetTrustFlags()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	script := filepath.Join(h.ProjectDir, "x.sh")
+	if err := os.WriteFile(script, []byte("echo x\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := executeCommandCapture(t, newTestTrustCmd(h.DBPath), "trust", "add", script, "-C", h.ProjectDir, "-s", sess.ID)
+	if err == nil || !strings.Contains(err.Error(), "--session-key is required") {
+		t.Errorf("expected a missing key error, got %v", err)
+	}
+
+	resetTrustFlags()
+	_, err = executeCommandCapture(t, newTestTrustCmd(h.DBPath), "trust", "add", script, "-C", h.ProjectDir, "-s", sess.ID, "-k", "wrong")
+	if err == nil || !strings.Contains(err.Error(), "key") {
+		t.Errorf("expected a key mismatch error, got %v", err)
+	}
+
+	resetTrustFlags()
+	outside := filepath.Join(t.TempDir(), "y.sh")
+	if err := os.WriteFile(outside, []byte("echo y\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_, err = executeCommandCapture(t, newTestTrustCmd(h.DBPath), "trust", "add", outside,
+		"-C", h.ProjectDir, "-s", sess.ID, "-k", sess.SessionKey)
+	if err == nil || !strings.Contains(err.Error(), "outside the project") {
+		t.Errorf("expected a script outside the project to be rejected, got %v", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/tui"
 	"github.com/spf13/cobra"
 )
@@ -17,12 +19,14 @@ var (
 )
 
 func init() {
-	tuiCmd.Flags().BoolVar(&flagTuiNoMouse, "no-mouse", false, "disable mouse support")
-	tuiCmd.Flags().IntVar(&flagTuiRefreshSeconds, "refresh-interval", 5, "polling interval when no daemon (seconds)")
-	tuiCmd.Flags().StringVar(&flagTuiTheme, "theme", "", "override theme (mocha, macchiato, frappe, latte)")
-	tuiCmd.Flags().StringVar(&flagTuiSessionID, "session-id", "", "session ID for approvals")
-	tuiCmd.Flags().StringVar(&flagTuiSessionKey, "session-key", "", "session key for approvals")
+	// Persistent so that 'slb tui approve' accepts them too
+	tuiCmd.PersistentFlags().BoolVar(&flagTuiNoMouse, "no-mouse", false, "disable mouse support")
+	tuiCmd.PersistentFlags().IntVar(&flagTuiRefreshSeconds, "refresh-interval", 5, "polling interval when no daemon (seconds)")
+	tuiCmd.PersistentFlags().StringVar(&flagTuiTheme, "theme", "", "override theme (mocha, macchiato, frappe, latte)")
+	tuiCmd.PersistentFlags().StringVar(&flagTuiSessionID, "session-id", "", "session ID for approvals")
+	tuiCmd.PersistentFlags().StringVar(&flagTuiSessionKey, "session-key", "", "session key for approvals")
 
+	tuiCmd.AddCommand(tuiApproveCmd)
 	rootCmd.AddCommand(tuiCmd)
 }
 
@@ -42,6 +46,8 @@ Key bindings:
   H              History browser
   q              Quit
 
+'slb tui approve' opens the approval queue instead.
+
 Theme options: mocha (default), macchiato, frappe, latte`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Determine project path
@@ -65,3 +71,51 @@ Theme options: mocha (default), macchiato, frappe, latte`,
 		return nil
 	},
 }
+
+var tuiApproveCmd = &cobra.Command{
+	Use:   "approve",
+	Short: "Review pending requests in an interactive approval queue",
+	Long: `Open the approval queue: the project's pending requests, critical first and
+then oldest first, with the selected request's justification, attachments,
+dry-run output and review counts in a detail pane.
+
+Reviews are submitted as the --session-id/--session-key session, exactly as
+'slb approve' and 'slb reject' would submit them. Refusals, such as reviewing
+your own request, are shown in the status line. Approving a CRITICAL request
+asks you to type "yes" first.
+
+Key bindings:
+  up/down (j/k)  Select a request
+  a              Approve the selected request
+  r              Reject the selected request (prompts for a reason)
+  R              Refresh now
+  q              Quit`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := projectPath()
+		if err != nil {
+			return err
+		}
+
+		opts := tui.Options{
+			ProjectPath:     project,
+			Theme:           flagTuiTheme,
+			DisableMouse:    flagTuiNoMouse,
+			RefreshInterval: flagTuiRefreshSeconds,
+			SessionID:       flagTuiSessionID,
+			SessionKey:      flagTuiSessionKey,
+			ReviewConfig: func(req *db.Request) (core.ReviewConfig, error) {
+				cfg, err := requestConfig(project, req)
+				if err != nil {
+					return core.ReviewConfig{}, err
+				}
+				return toReviewConfig(cfg), nil
+			},
+		}
+
+		if err := tui.RunApproveQueue(opts); err != nil {
+			return fmt.Errorf("tui: %w", err)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+// verifyResult is what 'slb verify' reports: the command and signature
+// checks plus the request's recorded audit events.
+type verifyResult struct {
+	*core.CommandVerification
+	AuditEvents []*db.AuditEvent `json:"audit_events,omitempty"`
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <request-id>",
+	Short: "Check a request's command against the hash its reviewers signed",
+	Long: `Audit a request after the fact with the checks made before it executes.
+
+The command must still hash to the value recorded when the request was
+created, every review must have signed over that hash, and every review
+signature must verify against its reviewer's session key. Reviews given
+before signatures covered the command hash are checked on their signature
+alone.
+
+Refused executions recorded for the request are listed too. Exits non-zero if
+any check fails.
+
+Examples:
+  slb verify abc123
+  slb verify abc123 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbConn, err := db.Open(GetDB())
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer dbConn.Close()
+
+		request, reviews, err := dbConn.GetRequestWithReviews(args[0])
+		if err != nil {
+			return fmt.Errorf("getting request: %w", err)
+		}
+		events, err := dbConn.ListAuditEvents(request.ID)
+		if err != nil {
+			return err
+		}
+		result := verifyResult{
+			CommandVerification: core.VerifyRequestCommand(dbConn, request, reviews),
+			AuditEvents:         events,
+		}
+
+		w := cmd.OutOrStdout()
+		if GetOutput() == "json" {
+			if err := output.New(output.FormatJSON, output.WithOutput(w)).Write(result); err != nil {
+				return err
+			}
+		} else {
+			hash := "matches"
+			if !result.HashMatches {
+				hash = "MISMATCH"
+			}
+			fmt.Fprintf(w, "Request %s: command hash %s (%s)\n", request.ID, hash, result.StoredHash)
+			for _, r := range result.Reviews {
+				signed := "signed hash matches"
+				switch {
+				case r.CommandHash == "":
+					signed = "signed before hashes were bound"
+				case !r.HashMatches:
+					signed = "signed hash " + r.CommandHash
+				}
+				fmt.Fprintf(w, "  review %s by %s (%s): signature %s, %s\n",
+					r.ReviewID, r.ReviewerAgent, r.Decision, r.Result, signed)
+			}
+			for _, e := range result.AuditEvents {
+				fmt.Fprintf(w, "  %s %s: %s\n", e.CreatedAt.Local().Format(time.RFC3339), e.Event, e.Detail)
+			}
+			for _, p := range result.Problems {
+				fmt.Fprintf(w, "  problem: %s\n", p)
+			}
+		}
+		if !result.OK() {
+			return fmt.Errorf("request failed verification: %d problem(s)", len(result.Problems))
+		}
+		if GetOutput() != "json" {
+			fmt.Fprintln(w, "OK")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newTestVerifyCmd creates a fresh verify command tree for testing.
+func newTestVerifyCmd(dbPath string) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "slb",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.PersistentFlags().StringVar(&flagDB, "db", dbPath, "database path")
+	root.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "output format")
+	root.PersistentFlags().BoolVarP(&flagJSON, "json", "j", false, "json output")
+	root.AddCommand(&cobra.Command{
+		Use:  "verify <request-id>",
+		Args: cobra.ExactArgs(1),
+		RunE: verifyCmd.RunE,
+	})
+	return root
+}
+
+func TestVerifyCommand(t *testing.T) {
+	h := testutil.NewHarness(t)
+	resetBundleFlags()
+
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+	req := testutil.MakeRequest(t, h.DB, requestor, testutil.WithCommand("rm -rf ./build", h.ProjectDir, true))
+	if _, err := core.NewReviewService(h.DB, core.DefaultReviewConfig()).SubmitReview(core.ReviewOptions{
+		SessionID:  reviewer.ID,
+		SessionKey: reviewer.SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+	}); err != nil {
+		t.Fatalf("SubmitReview: %v", err)
+	}
+
+	stdout, err := executeCommandCapture(t, newTestVerifyCmd(h.DBPath), "verify", req.ID)
+	if err != nil {
+		t.Fatalf("verify: %v\n%s", err, stdout)
+	}
+	if !strings.Contains(stdout, "signature valid, signed hash matches") || !strings.Contains(stdout, "OK") {
+		t.Errorf("unexpected output %q", stdout)
+	}
+
+	if _, err := h.DB.Exec(`UPDATE requests SET command_raw = ? WHERE id = ?`, "rm -rf /", req.ID); err != nil {
+		t.Fatal(err)
+	}
+	resetBundleFlags()
+	stdout, err = executeCommandCapture(t, newTestVerifyCmd(h.DBPath), "verify", req.ID)
+	if err == nil {
+		t.Fatal("expected verification to fail")
+	}
+	if !strings.Contains(stdout, "command hash MISMATCH") || !strings.Contains(stdout, "problem:") {
+		t.Errorf("expected the mismatch in output, got %q", stdout)
+	}
+}
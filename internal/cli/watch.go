@@ -8,9 +8,12 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/daemon"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/spf13/cobra"
@@ -20,12 +23,29 @@ var (
 	flagWatchSessionID          string
 	flagWatchAutoApproveCaution bool
 	flagWatchPollInterval       time.Duration
+	flagWatchWorkspace          bool
+	flagWatchAutoExecute        bool
+	flagWatchMaxTier            string
+	flagWatchEventSchema        int
+	flagWatchStateFile          string
+	flagWatchProjects           []string
+	flagWatchTiers              []string
+	flagWatchRequestor          string
 )
 
 func init() {
 	watchCmd.Flags().StringVarP(&flagWatchSessionID, "session-id", "s", "", "session ID for auto-approve attribution")
 	watchCmd.Flags().BoolVar(&flagWatchAutoApproveCaution, "auto-approve-caution", false, "automatically approve CAUTION tier requests")
 	watchCmd.Flags().DurationVar(&flagWatchPollInterval, "poll-interval", 2*time.Second, "polling interval when daemon not available")
+	watchCmd.Flags().BoolVar(&flagWatchWorkspace, "workspace", false, "watch requests across all workspace members")
+	watchCmd.Flags().BoolVar(&flagWatchAutoExecute, "auto-execute-approved", false, "execute approved requests up to --max-tier (requires --session-id)")
+	watchCmd.Flags().StringVar(&flagWatchMaxTier, "max-tier", string(db.RiskTierCaution), "highest tier to auto-execute: caution or dangerous (never critical)")
+	watchCmd.Flags().IntVar(&flagWatchEventSchema, "event-schema", daemon.DefaultEventSchema, "event schema version to emit (1-3)")
+	watchCmd.Flags().StringVar(&flagWatchStateFile, "state-file", "", "persist reported request statuses here so a restarted polling watcher resumes")
+	// Shadows the global --project, keeping its -C shorthand; see watchDBPath.
+	watchCmd.Flags().StringArrayVarP(&flagWatchProjects, "project", "C", nil, "only watch requests from this project path (repeatable)")
+	watchCmd.Flags().StringSliceVar(&flagWatchTiers, "tier", nil, "only watch requests of these tiers (e.g. dangerous,critical)")
+	watchCmd.Flags().StringVar(&flagWatchRequestor, "requestor", "", "only watch requests from this agent")
 
 	rootCmd.AddCommand(watchCmd)
 }
@@ -42,18 +62,72 @@ If the daemon is running, events are received in real-time via IPC subscription.
 If the daemon is not running, the command falls back to polling the database.
 
 Event types:
-  request_pending   - New request awaiting approval
+  request_queued    - New request held by the rate limiter (action "queue")
+  request_pending   - New request awaiting approval, or a queued request admitted
   request_approved  - Request was approved
   request_rejected  - Request was rejected
   request_executed  - Approved request was executed
   request_timeout   - Request timed out
   request_cancelled - Request was cancelled
-
-Use --auto-approve-caution to automatically approve CAUTION tier requests.`,
+  request_approval_expired - Approval lapsed before execution; the requestor
+                      can send it back for review with 'slb rerequest'
+  request_rerequested - Request returned to pending for a new review round
+  request_execution_queued - Approved request is waiting for an execution
+                      slot (general.max_concurrent_executions)
+
+Use --auto-approve-caution to automatically approve CAUTION tier requests.
+When general.policy_attestation_days is set and the project's policy is due
+for re-attestation (see 'slb policy'), a policy_attestation_due event is
+emitted before each auto-approval; past general.policy_attestation_grace_days
+auto-approval is refused with an auto_approve_error.
+
+Use --auto-execute-approved with --session-id to execute approved requests at
+or below --max-tier (default caution; CRITICAL is never auto-executed). Each
+execution passes the same gates as 'slb execute' and emits a
+request_auto_executed event (or auto_execute_error). Command output goes to
+the execution log, not the event stream. Executions run one at a time.
+Daemon events carry the request's project path, and the request is looked up
+in that project's database.
+
+Use --workspace to watch every member of the enclosing workspace. Member
+databases are polled and each event carries a "project" field naming the
+member it came from.
+
+Use --state-file when polling to remember which requests were already
+reported. A restarted watcher given the same file resumes where it left off:
+requests it reported are not emitted again as new, and changes made while it
+was stopped are emitted as status changes. Watchers sharing one file are told
+apart by --session-id. The file is replaced atomically after each poll that
+changes it.
+
+Use --project (repeatable), --tier (comma-separated) and --requestor to watch
+only matching requests. With the daemon, events are filtered as they arrive;
+when polling, the filter is part of the database query. Once a request's
+first event has been emitted, all of its later events are too, so every
+request_pending is followed by its outcome. --auto-approve-caution and
+--auto-execute-approved only act on requests that pass the filters. Outside
+--workspace and without --db, the first --project also picks the database to
+poll, as -C does for other commands.
+
+Use --event-schema to pick the event shape. Each schema version is frozen
+once released: its fields keep their names, types and meanings, and changes
+ship as a new version, so a consumer pinned to a version is never broken.
+  1 - the original shape (default)
+  2 - adds "schema_version" to every event and "expired_at" to
+      request_timeout and request_approval_expired events
+  3 - adds "has_preview" to request_pending events: whether the request
+      carries a dry-run preview attachment (slb run --preview)`,
 	RunE: runWatch,
 }
 
 func runWatch(cmd *cobra.Command, args []string) error {
+	if err := daemon.ValidateEventSchema(flagWatchEventSchema); err != nil {
+		return err
+	}
+	if err := validateAutoExecuteFlags(); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithCancel(cmd.Context())
 	defer cancel()
 
@@ -65,10 +139,22 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
+	if flagWatchWorkspace {
+		ws, err := currentWorkspace()
+		if err != nil {
+			return err
+		}
+		return runWatchWorkspace(ctx, ws, cmd.OutOrStdout())
+	}
+
 	// Try daemon IPC first
 	client := daemon.NewClient()
 	if client.IsDaemonRunning() {
-		return runWatchDaemon(ctx, client, cmd.OutOrStdout())
+		filter, err := newWatchFilter()
+		if err != nil {
+			return err
+		}
+		return runWatchDaemon(ctx, client, filter, cmd.OutOrStdout())
 	}
 
 	// Fall back to polling
@@ -76,8 +162,26 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	return runWatchPolling(ctx, cmd.OutOrStdout())
 }
 
+// encodeStreamEvent writes event in the --event-schema shape.
+func encodeStreamEvent(enc *json.Encoder, event daemon.RequestStreamEvent) error {
+	wire, err := event.Versioned(flagWatchEventSchema)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(wire)
+}
+
+// encodeWatchEvent writes an ad hoc watch event in the --event-schema shape.
+func encodeWatchEvent(enc *json.Encoder, event map[string]any) error {
+	wire, err := daemon.VersionedMap(event, flagWatchEventSchema)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(wire)
+}
+
 // runWatchDaemon streams events via daemon IPC subscription.
-func runWatchDaemon(ctx context.Context, client *daemon.Client, out io.Writer) error {
+func runWatchDaemon(ctx context.Context, client *daemon.Client, filter *watchFilter, out io.Writer) error {
 	ipcClient := daemon.NewIPCClient(daemon.DefaultSocketPath())
 	defer ipcClient.Close()
 
@@ -97,53 +201,90 @@ func runWatchDaemon(ctx context.Context, client *daemon.Client, out io.Writer) e
 				return nil
 			}
 
-			watchEvent := daemon.ToRequestStreamEvent(event)
-			if err := enc.Encode(watchEvent); err != nil {
-				return fmt.Errorf("encoding event: %w", err)
+			if err := handleDaemonEvent(ctx, event, filter, enc); err != nil {
+				return err
 			}
+		}
+	}
+}
 
-			// Auto-approve CAUTION tier if enabled
-			if flagWatchAutoApproveCaution && watchEvent.Event == "request_pending" && watchEvent.RiskTier == "caution" {
-				if err := autoApproveCaution(ctx, watchEvent.RequestID); err != nil {
-					// Log error but continue watching
-					errEvent := map[string]any{
-						"event":      "auto_approve_error",
-						"request_id": watchEvent.RequestID,
-						"error":      err.Error(),
-					}
-					enc.Encode(errEvent)
-				}
+// handleDaemonEvent emits a daemon event that passes filter and runs the
+// auto-execute and auto-approve hooks for it. Events that carry a project
+// path act on that project's database, so approvals in workspace members
+// registered with the daemon are not looked up in the current project.
+func handleDaemonEvent(ctx context.Context, event daemon.Event, filter *watchFilter, enc *json.Encoder) error {
+	watchEvent := daemon.ToRequestStreamEvent(event)
+	if !filter.admit(watchEvent) {
+		return nil
+	}
+	if err := encodeStreamEvent(enc, *watchEvent); err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+
+	dbPath := watchDBPath()
+	if watchEvent.Project != "" {
+		dbPath = filepath.Join(watchEvent.Project, ".slb", "state.db")
+	}
+
+	if flagWatchAutoExecute && watchEvent.Event == "request_approved" && watchEvent.RequestID != "" {
+		if err := autoExecuteApproved(ctx, dbPath, watchEvent.RequestID, watchEvent.Project, enc); err != nil {
+			return err
+		}
+	}
+
+	// Auto-approve CAUTION tier if enabled
+	if flagWatchAutoApproveCaution && watchEvent.Event == "request_pending" && watchEvent.RiskTier == "caution" {
+		if err := autoApproveCautionIn(ctx, dbPath, watchEvent.RequestID, watchEvent.Project, enc); err != nil {
+			// Log error but continue watching
+			errEvent := map[string]any{
+				"event":      "auto_approve_error",
+				"request_id": watchEvent.RequestID,
+				"error":      err.Error(),
 			}
+			encodeWatchEvent(enc, errEvent)
 		}
 	}
+	return nil
 }
 
 // runWatchPolling polls the database for pending requests.
 func runWatchPolling(ctx context.Context, out io.Writer) error {
-	dbConn, err := db.Open(GetDB())
+	filter, err := newWatchFilter()
+	if err != nil {
+		return err
+	}
+	dbConn, err := db.Open(watchDBPath())
 	if err != nil {
 		return fmt.Errorf("opening database: %w", err)
 	}
 	defer dbConn.Close()
 
+	target := watchTarget{DBPath: watchDBPath(), Filter: filter.query()}
+	state := &watchStateFile{path: flagWatchStateFile, key: watchStateKey(target)}
+	seen, err := state.load()
+	if err != nil {
+		return err
+	}
+
 	enc := json.NewEncoder(out)
-	seen := make(map[string]db.RequestStatus)
 	ticker := time.NewTicker(flagWatchPollInterval)
 	defer ticker.Stop()
 
 	// Initial poll
-	if err := pollRequests(ctx, dbConn, enc, seen); err != nil {
+	if err := pollTargetRequests(ctx, dbConn, target, enc, seen); err != nil {
 		return err
 	}
+	state.save(seen)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
-			if err := pollRequests(ctx, dbConn, enc, seen); err != nil {
+			if err := pollTargetRequests(ctx, dbConn, target, enc, seen); err != nil {
 				return err
 			}
+			state.save(seen)
 		}
 	}
 }
@@ -173,7 +314,11 @@ type RequestPollResult struct {
 // contains the core polling business logic.
 //
 // Decision rules:
-//   - New request (not in seen map): emit "request_pending" event
+//   - New request (not in seen map): emit "request_pending" event, or
+//     "request_queued" if the rate limiter is holding it
+//   - Queued request admitted: emit "request_pending" as for a new request
+//   - Expired approval re-requested: emit "request_rerequested", again with
+//     the full request so reviewers can re-confirm it
 //   - Status changed: emit appropriate status change event
 //   - Status unchanged: skip (no event)
 func evaluateRequestForPolling(
@@ -184,6 +329,13 @@ func evaluateRequestForPolling(
 	prevStatus, exists := seen[requestID]
 
 	if !exists {
+		if currentStatus == db.StatusQueued {
+			return RequestPollResult{
+				Action:    PollActionEmitNew,
+				EventType: "request_queued",
+				Reason:    "new queued request discovered",
+			}
+		}
 		// New request - emit pending event
 		return RequestPollResult{
 			Action:    PollActionEmitNew,
@@ -192,6 +344,22 @@ func evaluateRequestForPolling(
 		}
 	}
 
+	if prevStatus == db.StatusQueued && currentStatus == db.StatusPending {
+		// Admitted from the queue - reviewers see it for the first time
+		return RequestPollResult{
+			Action:    PollActionEmitNew,
+			EventType: "request_pending",
+			Reason:    "queued request admitted",
+		}
+	}
+	if prevStatus == db.StatusApprovalExpired && currentStatus == db.StatusPending {
+		return RequestPollResult{
+			Action:    PollActionEmitNew,
+			EventType: "request_rerequested",
+			Reason:    "expired approval re-requested",
+		}
+	}
+
 	if prevStatus == currentStatus {
 		// No change - skip
 		return RequestPollResult{
@@ -230,19 +398,121 @@ func statusToEventType(status db.RequestStatus) string {
 		return "request_timeout"
 	case db.StatusCancelled:
 		return "request_cancelled"
+	case db.StatusApprovalExpired:
+		return "request_approval_expired"
 	default:
 		return ""
 	}
 }
 
+// runWatchWorkspace polls every workspace member's database, tagging events
+// with the member they came from.
+func runWatchWorkspace(ctx context.Context, ws *config.Workspace, out io.Writer) error {
+	filter, err := newWatchFilter()
+	if err != nil {
+		return err
+	}
+	type memberWatch struct {
+		target watchTarget
+		dbConn *db.DB
+		seen   map[string]db.RequestStatus
+		state  *watchStateFile
+	}
+	var watches []memberWatch
+	defer func() {
+		for _, w := range watches {
+			w.dbConn.Close()
+		}
+	}()
+	for _, p := range workspaceProjects(ws) {
+		dbConn, err := db.Open(p.DBPath)
+		if err != nil {
+			return fmt.Errorf("opening database for %s: %w", p.Name, err)
+		}
+		target := watchTarget{Project: p.Name, DBPath: p.DBPath, Filter: filter.query()}
+		state := &watchStateFile{path: flagWatchStateFile, key: watchStateKey(target)}
+		seen, err := state.load()
+		if err != nil {
+			dbConn.Close()
+			return err
+		}
+		watches = append(watches, memberWatch{
+			target: target,
+			dbConn: dbConn,
+			seen:   seen,
+			state:  state,
+		})
+	}
+
+	enc := json.NewEncoder(out)
+	poll := func() error {
+		for _, w := range watches {
+			if err := pollTargetRequests(ctx, w.dbConn, w.target, enc, w.seen); err != nil {
+				return err
+			}
+			w.state.save(w.seen)
+		}
+		return nil
+	}
+
+	ticker := time.NewTicker(flagWatchPollInterval)
+	defer ticker.Stop()
+
+	if err := poll(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := poll(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchTarget identifies where polled requests live.
+type watchTarget struct {
+	// Project is the workspace member name; empty outside workspace mode.
+	Project string
+	// DBPath is the database auto-approvals are written to.
+	DBPath string
+	// Filter selects the pending and queued requests reported.
+	Filter db.PendingFilter
+}
+
+// watchDBPath is the database watch polls and acts on. --project shadows
+// the global flag on watch, so without --db its first value picks the
+// database, as -C does for every other command.
+func watchDBPath() string {
+	if flagDB == "" && len(flagWatchProjects) > 0 {
+		return filepath.Join(flagWatchProjects[0], ".slb", "state.db")
+	}
+	return GetDB()
+}
+
 // pollRequests checks for new or changed requests and emits events.
 // It handles requests that move out of pending status by checking tracked IDs.
 func pollRequests(ctx context.Context, dbConn *db.DB, enc *json.Encoder, seen map[string]db.RequestStatus) error {
-	// Get all pending requests for all projects
-	requests, err := dbConn.ListPendingRequestsAllProjects()
+	return pollTargetRequests(ctx, dbConn, watchTarget{DBPath: GetDB()}, enc, seen)
+}
+
+// pollTargetRequests is pollRequests for a specific watch target. Only
+// requests matching target.Filter are reported as new; requests already
+// reported keep being followed to their outcome.
+func pollTargetRequests(ctx context.Context, dbConn *db.DB, target watchTarget, enc *json.Encoder, seen map[string]db.RequestStatus) error {
+	// Get the matching pending and queued requests for all projects
+	requests, err := dbConn.ListPendingRequestsMatching(target.Filter)
 	if err != nil {
 		return fmt.Errorf("listing requests: %w", err)
 	}
+	queued, err := dbConn.ListQueuedRequestsMatching(target.Filter)
+	if err != nil {
+		return fmt.Errorf("listing queued requests: %w", err)
+	}
+	requests = append(requests, queued...)
 
 	// Track which IDs were found in the pending list
 	foundPending := make(map[string]bool)
@@ -250,7 +520,7 @@ func pollRequests(ctx context.Context, dbConn *db.DB, enc *json.Encoder, seen ma
 	// Process current pending requests
 	for _, req := range requests {
 		foundPending[req.ID] = true
-		if err := processPolledRequest(ctx, req, enc, seen); err != nil {
+		if err := processTargetRequest(ctx, req, target, enc, seen); err != nil {
 			return err
 		}
 	}
@@ -271,7 +541,7 @@ func pollRequests(ctx context.Context, dbConn *db.DB, enc *json.Encoder, seen ma
 			continue
 		}
 
-		if err := processPolledRequest(ctx, req, enc, seen); err != nil {
+		if err := processTargetRequest(ctx, req, target, enc, seen); err != nil {
 			return err
 		}
 	}
@@ -280,6 +550,10 @@ func pollRequests(ctx context.Context, dbConn *db.DB, enc *json.Encoder, seen ma
 }
 
 func processPolledRequest(ctx context.Context, req *db.Request, enc *json.Encoder, seen map[string]db.RequestStatus) error {
+	return processTargetRequest(ctx, req, watchTarget{DBPath: GetDB()}, enc, seen)
+}
+
+func processTargetRequest(ctx context.Context, req *db.Request, target watchTarget, enc *json.Encoder, seen map[string]db.RequestStatus) error {
 	// Use pure function for decision logic
 	result := evaluateRequestForPolling(req.ID, req.Status, seen)
 
@@ -289,6 +563,7 @@ func processPolledRequest(ctx context.Context, req *db.Request, enc *json.Encode
 		event := daemon.RequestStreamEvent{
 			Event:     result.EventType,
 			RequestID: req.ID,
+			Project:   target.Project,
 			RiskTier:  string(req.RiskTier),
 			Command:   req.Command.DisplayRedacted,
 			Requestor: req.RequestorAgent,
@@ -297,19 +572,23 @@ func processPolledRequest(ctx context.Context, req *db.Request, enc *json.Encode
 		if req.Command.DisplayRedacted == "" {
 			event.Command = req.Command.Raw
 		}
-		if err := enc.Encode(event); err != nil {
+		if event.Event == "request_pending" {
+			hasPreview := core.HasDryRunPreview(req.Attachments)
+			event.HasPreview = &hasPreview
+		}
+		if err := encodeStreamEvent(enc, event); err != nil {
 			return fmt.Errorf("encoding event: %w", err)
 		}
 
-		// Auto-approve CAUTION tier if enabled
-		if flagWatchAutoApproveCaution && req.RiskTier == db.RiskTierCaution {
-			if err := autoApproveCaution(ctx, req.ID); err != nil {
+		// Auto-approve CAUTION tier if enabled (once it is out of the queue)
+		if flagWatchAutoApproveCaution && req.RiskTier == db.RiskTierCaution && req.Status == db.StatusPending {
+			if err := autoApproveCautionIn(ctx, target.DBPath, req.ID, target.Project, enc); err != nil {
 				errEvent := map[string]any{
 					"event":      "auto_approve_error",
 					"request_id": req.ID,
 					"error":      err.Error(),
 				}
-				enc.Encode(errEvent)
+				encodeWatchEvent(enc, errEvent)
 			}
 		}
 
@@ -318,11 +597,24 @@ func processPolledRequest(ctx context.Context, req *db.Request, enc *json.Encode
 		event := daemon.RequestStreamEvent{
 			Event:     result.EventType,
 			RequestID: req.ID,
+			Project:   target.Project,
+		}
+		if req.Status == db.StatusApprovalExpired && req.ApprovalExpiresAt != nil {
+			event.ExpiredAt = req.ApprovalExpiresAt.UTC().Format(time.RFC3339)
+		}
+		if req.Status == db.StatusCancelled {
+			event.Reason = req.CancelReason
 		}
-		if err := enc.Encode(event); err != nil {
+		if err := encodeStreamEvent(enc, event); err != nil {
 			return fmt.Errorf("encoding event: %w", err)
 		}
 
+		if flagWatchAutoExecute && req.Status == db.StatusApproved {
+			// Record the approval first so a failed execution is not retried.
+			seen[req.ID] = req.Status
+			return autoExecuteApproved(ctx, target.DBPath, req.ID, target.Project, enc)
+		}
+
 	case PollActionSkip:
 		// No action needed
 	}
@@ -375,10 +667,196 @@ func shouldAutoApproveCaution(
 	}
 }
 
+// AutoExecuteDecision encapsulates the result of the auto-execute decision.
+// This is returned by the pure decision function for testability.
+type AutoExecuteDecision struct {
+	ShouldExecute bool
+	Reason        string
+}
+
+// autoExecuteTierRank orders the tiers that may ever be auto-executed.
+// CRITICAL is intentionally absent.
+var autoExecuteTierRank = map[db.RiskTier]int{
+	db.RiskTierCaution:   1,
+	db.RiskTierDangerous: 2,
+}
+
+// shouldAutoExecuteApproved is a SAFETY-CRITICAL pure function that determines
+// whether an approved request should be executed by the watcher. This function
+// MUST maintain 100% test coverage as it guards against unattended execution.
+//
+// Decision rules:
+//   - Auto-execute must be enabled (checked at call site)
+//   - Request must be in approved status
+//   - Request must never be CRITICAL tier, regardless of maxTier
+//   - maxTier must itself be caution or dangerous
+//   - Request tier must not exceed maxTier
+//
+// This function is intentionally side-effect free for reliable testing.
+func shouldAutoExecuteApproved(
+	requestStatus db.RequestStatus,
+	requestRiskTier db.RiskTier,
+	maxTier db.RiskTier,
+) AutoExecuteDecision {
+	// Guard 1: Request must be approved
+	if requestStatus != db.StatusApproved {
+		return AutoExecuteDecision{
+			ShouldExecute: false,
+			Reason:        "request not approved (status: " + string(requestStatus) + ")",
+		}
+	}
+
+	// Guard 2: CRITICAL tier MUST always be executed by a human-driven command
+	if requestRiskTier == db.RiskTierCritical {
+		return AutoExecuteDecision{
+			ShouldExecute: false,
+			Reason:        "critical tier is never auto-executed",
+		}
+	}
+
+	// Guard 3: The ceiling must be an auto-executable tier
+	maxRank, ok := autoExecuteTierRank[maxTier]
+	if !ok {
+		return AutoExecuteDecision{
+			ShouldExecute: false,
+			Reason:        "invalid max tier: " + string(maxTier),
+		}
+	}
+
+	// Guard 4: Request tier must be known and within the ceiling
+	rank, ok := autoExecuteTierRank[requestRiskTier]
+	if !ok {
+		return AutoExecuteDecision{
+			ShouldExecute: false,
+			Reason:        "unknown tier: " + string(requestRiskTier),
+		}
+	}
+	if rank > maxRank {
+		return AutoExecuteDecision{
+			ShouldExecute: false,
+			Reason:        "tier " + string(requestRiskTier) + " exceeds max tier " + string(maxTier),
+		}
+	}
+
+	return AutoExecuteDecision{
+		ShouldExecute: true,
+		Reason:        string(requestRiskTier) + " tier request eligible for auto-execution",
+	}
+}
+
+// validateAutoExecuteFlags rejects unsafe or incomplete auto-execute setups
+// before any event is processed.
+func validateAutoExecuteFlags() error {
+	if !flagWatchAutoExecute {
+		return nil
+	}
+	if flagWatchSessionID == "" {
+		return fmt.Errorf("--auto-execute-approved requires --session-id")
+	}
+	if db.RiskTier(flagWatchMaxTier) == db.RiskTierCritical {
+		return fmt.Errorf("--max-tier critical is not allowed: CRITICAL requests are never auto-executed")
+	}
+	if _, ok := autoExecuteTierRank[db.RiskTier(flagWatchMaxTier)]; !ok {
+		return fmt.Errorf("invalid --max-tier %q (must be caution or dangerous)", flagWatchMaxTier)
+	}
+	return nil
+}
+
+// autoExecuteApproved executes an approved request through the executor's
+// gates when the decision function allows it, emitting the outcome. Requests
+// outside the configured tiers are skipped silently; execution failures are
+// reported as events and do not stop the watcher.
+func autoExecuteApproved(ctx context.Context, dbPath, requestID, project string, enc *json.Encoder) error {
+	emitErr := func(err error) error {
+		errEvent := map[string]any{
+			"event":      "auto_execute_error",
+			"request_id": requestID,
+			"error":      err.Error(),
+		}
+		if project != "" {
+			errEvent["project"] = project
+		}
+		return encodeWatchEvent(enc, errEvent)
+	}
+
+	dbConn, err := db.Open(dbPath)
+	if err != nil {
+		return emitErr(fmt.Errorf("opening database: %w", err))
+	}
+	defer dbConn.Close()
+
+	request, err := dbConn.GetRequest(requestID)
+	if err != nil {
+		return emitErr(fmt.Errorf("getting request: %w", err))
+	}
+
+	// Use pure decision function for safety-critical logic
+	decision := shouldAutoExecuteApproved(request.Status, request.RiskTier, db.RiskTier(flagWatchMaxTier))
+	if !decision.ShouldExecute {
+		return nil
+	}
+
+	cfg, err := config.Load(config.LoadOptions{
+		ProjectDir: request.ProjectPath,
+		ConfigPath: flagConfig,
+	})
+	if err != nil {
+		return emitErr(fmt.Errorf("loading config: %w", err))
+	}
+
+	executor := core.NewExecutor(dbConn, nil).WithNotifier(buildAgentMailNotifier(request.ProjectPath)).
+		WithRiskOverrides(toRiskOverrideRules(cfg.RiskOverrides)).
+		WithChangeRecords(cfg.Integrations.ChangeRecordURL, cfg.Integrations.ChangeRecordTiers)
+	result, err := executor.ExecuteApprovedRequest(ctx, core.ExecuteOptions{
+		RequestID:               requestID,
+		SessionID:               flagWatchSessionID,
+		LogDir:                  filepath.Join(request.ProjectPath, ".slb", "logs"),
+		SuppressOutput:          true,
+		CaptureRollback:         cfg.General.EnableRollbackCapture,
+		RunAllApprovedSegments:  cfg.General.RunAllApprovedSegments,
+		MaxRollbackSizeMB:       cfg.General.MaxRollbackSizeMB,
+		RollbackRootPrefix:      cfg.General.RollbackRootPrefix,
+		MaxRollbackCaptures:     cfg.General.MaxRollbackCaptures,
+		MaxConcurrentCaptures:   cfg.General.MaxConcurrentCaptures,
+		RollbackCaptureWait:     time.Duration(cfg.General.RollbackCaptureWaitSecs) * time.Second,
+		RollbackExclude:         cfg.General.RollbackExclude,
+		MaxConcurrentExecutions: cfg.General.MaxConcurrentExecutions,
+		ExecutionQueueWait:      time.Duration(cfg.General.ExecutionQueueWaitSecs) * time.Second,
+		OnExecutionQueued:       reportExecutionQueued,
+	})
+	if err != nil {
+		return emitErr(err)
+	}
+
+	event := map[string]any{
+		"event":       "request_auto_executed",
+		"request_id":  requestID,
+		"risk_tier":   string(request.RiskTier),
+		"exit_code":   result.ExitCode,
+		"duration_ms": result.Duration.Milliseconds(),
+		"log_path":    result.LogPath,
+	}
+	if result.TimedOut {
+		event["timed_out"] = true
+	}
+	if project != "" {
+		event["project"] = project
+	}
+	return encodeWatchEvent(enc, event)
+}
+
 // autoApproveCaution automatically approves a CAUTION tier request.
 // This is the side-effectful wrapper that calls the pure decision function.
 func autoApproveCaution(ctx context.Context, requestID string) error {
-	dbConn, err := db.Open(GetDB())
+	return autoApproveCautionIn(ctx, GetDB(), requestID, "", nil)
+}
+
+// autoApproveCautionIn is autoApproveCaution against the database at dbPath.
+// When the request's auto-approve policy needs re-attestation a
+// policy_attestation_due event is written to enc (if non-nil); past the
+// grace period the approval is refused.
+func autoApproveCautionIn(ctx context.Context, dbPath, requestID, project string, enc *json.Encoder) error {
+	dbConn, err := db.Open(dbPath)
 	if err != nil {
 		return fmt.Errorf("opening database: %w", err)
 	}
@@ -398,6 +876,38 @@ func autoApproveCaution(ctx context.Context, requestID string) error {
 		}
 		return fmt.Errorf("auto-approve denied: %s", decision.Reason)
 	}
+	// The auto-reviewer runs on whatever host the watcher does, so it can
+	// never vouch for host diversity.
+	if request.RequireDifferentHost {
+		return fmt.Errorf("auto-approve denied: request requires a reviewer on a different host")
+	}
+	if core.HasRuntimeTargets(request.Command.Raw) {
+		return fmt.Errorf("auto-approve denied: targets derived from runtime substitution")
+	}
+
+	check, err := autoApprovePolicyCheck(dbConn, request.ProjectPath)
+	if err != nil {
+		return fmt.Errorf("checking policy attestation: %w", err)
+	}
+	if check.NeedsAttention() && enc != nil {
+		event := map[string]any{
+			"event":       daemon.PolicyAttestationDueEvent,
+			"request_id":  requestID,
+			"state":       string(check.State),
+			"policy_hash": check.PolicyHash,
+			"refusing":    check.Refuse,
+		}
+		if !check.DueAt.IsZero() {
+			event["due_at"] = check.DueAt.Format(time.RFC3339)
+		}
+		if project != "" {
+			event["project"] = project
+		}
+		encodeWatchEvent(enc, event)
+	}
+	if check.Refuse {
+		return errPolicyAttestationOverdue
+	}
 
 	// Determine reviewer identity
 	agent := "auto-reviewer"
@@ -416,6 +926,14 @@ func autoApproveCaution(ctx context.Context, requestID string) error {
 		Decision:          db.DecisionApprove,
 		Comments:          "Auto-approved CAUTION tier request",
 		CreatedAt:         time.Now(),
+		CommandHash:       request.Command.Hash,
+	}
+	// Sign with the watcher's session key so the approval verifies when the
+	// request runs; without a registered session it stays unsigned and
+	// execution refuses it.
+	if sess, err := dbConn.GetSession(session); err == nil {
+		review.SignatureTimestamp = time.Now().UTC()
+		review.Signature = db.ComputeReviewSignature(sess.SessionKey, requestID, review.CommandHash, db.DecisionApprove, review.SignatureTimestamp)
 	}
 
 	if err := dbConn.CreateReview(review); err != nil {
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// watchFilter limits slb watch to the requests matching --project, --tier
+// and --requestor. A zero filter matches every request.
+type watchFilter struct {
+	projects  []string
+	tiers     []db.RiskTier
+	requestor string
+
+	// emitted holds the requests whose new-request event the daemon stream
+	// delivered. Their later events are delivered whether or not they carry
+	// the fields the filter checks, so no request_pending is left without
+	// its terminal event.
+	emitted map[string]bool
+}
+
+// newWatchFilter builds the filter from the watch flags.
+func newWatchFilter() (*watchFilter, error) {
+	f := &watchFilter{
+		requestor: strings.TrimSpace(flagWatchRequestor),
+		emitted:   make(map[string]bool),
+	}
+	for _, p := range flagWatchProjects {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, fmt.Errorf("resolving --project %q: %w", p, err)
+		}
+		f.projects = append(f.projects, abs)
+	}
+	for _, t := range flagWatchTiers {
+		tier := db.RiskTier(strings.ToLower(strings.TrimSpace(t)))
+		switch tier {
+		case db.RiskTierCaution, db.RiskTierDangerous, db.RiskTierCritical:
+			f.tiers = append(f.tiers, tier)
+		default:
+			return nil, fmt.Errorf("invalid --tier %q (must be caution, dangerous or critical)", t)
+		}
+	}
+	return f, nil
+}
+
+// active reports whether any filter flag was given.
+func (f *watchFilter) active() bool {
+	return len(f.projects) > 0 || len(f.tiers) > 0 || f.requestor != ""
+}
+
+// query is the filter for the polling path's database queries.
+func (f *watchFilter) query() db.PendingFilter {
+	return db.PendingFilter{ProjectPaths: f.projects, Tiers: f.tiers, Requestor: f.requestor}
+}
+
+// matches reports whether a request with these fields passes the filter.
+// An empty field fails any filter on it.
+func (f *watchFilter) matches(project, tier, requestor string) bool {
+	if len(f.projects) > 0 {
+		found := false
+		for _, p := range f.projects {
+			if project != "" && filepath.Clean(project) == p {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.tiers) > 0 {
+		found := false
+		for _, t := range f.tiers {
+			if string(t) == tier {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return f.requestor == "" || strings.EqualFold(f.requestor, requestor)
+}
+
+// admit reports whether the daemon stream should deliver event. Events
+// not about a request, and later events of requests already delivered,
+// always pass; other events pass when their fields match.
+func (f *watchFilter) admit(event *daemon.RequestStreamEvent) bool {
+	if !f.active() || event.RequestID == "" {
+		return true
+	}
+	if f.emitted[event.RequestID] {
+		if watchTerminalEvents[event.Event] {
+			delete(f.emitted, event.RequestID)
+		}
+		return true
+	}
+	if !f.matches(event.Project, event.RiskTier, event.Requestor) {
+		return false
+	}
+	if !watchTerminalEvents[event.Event] {
+		f.emitted[event.RequestID] = true
+	}
+	return true
+}
+
+// watchTerminalEvents are the events after which a request has no more.
+var watchTerminalEvents = map[string]bool{
+	"request_rejected":  true,
+	"request_executed":  true,
+	"request_timeout":   true,
+	"request_cancelled": true,
+}
@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// setWatchFilterFlags sets the watch filter flags for the test.
+func setWatchFilterFlags(t *testing.T, projects, tiers []string, requestor string) {
+	t.Helper()
+	origProjects, origTiers, origRequestor := flagWatchProjects, flagWatchTiers, flagWatchRequestor
+	t.Cleanup(func() {
+		flagWatchProjects, flagWatchTiers, flagWatchRequestor = origProjects, origTiers, origRequestor
+	})
+	flagWatchProjects, flagWatchTiers, flagWatchRequestor = projects, tiers, requestor
+}
+
+// watchEvents decodes NDJSON watch output into events.
+func watchEvents(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		var ev map[string]any
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestNewWatchFilter(t *testing.T) {
+	setWatchFilterFlags(t, nil, nil, "")
+	f, err := newWatchFilter()
+	if err != nil {
+		t.Fatalf("newWatchFilter: %v", err)
+	}
+	if f.active() {
+		t.Error("expected no filter without flags")
+	}
+
+	setWatchFilterFlags(t, []string{"rel/api"}, []string{"Dangerous", " critical"}, "BlueLake")
+	f, err = newWatchFilter()
+	if err != nil {
+		t.Fatalf("newWatchFilter: %v", err)
+	}
+	abs, _ := filepath.Abs("rel/api")
+	q := f.query()
+	if len(q.ProjectPaths) != 1 || q.ProjectPaths[0] != abs {
+		t.Errorf("expected project %s, got %v", abs, q.ProjectPaths)
+	}
+	if len(q.Tiers) != 2 || q.Tiers[0] != db.RiskTierDangerous || q.Tiers[1] != db.RiskTierCritical {
+		t.Errorf("unexpected tiers %v", q.Tiers)
+	}
+	if !f.matches(abs+"/", "critical", "bluelake") || f.matches(abs, "caution", "BlueLake") || f.matches("", "critical", "BlueLake") {
+		t.Error("unexpected matches result")
+	}
+
+	setWatchFilterFlags(t, nil, []string{"safe"}, "")
+	if _, err := newWatchFilter(); err == nil || !strings.Contains(err.Error(), `invalid --tier "safe"`) {
+		t.Errorf("expected an invalid tier error, got %v", err)
+	}
+}
+
+func TestWatchFilter_AdmitDaemonEvents(t *testing.T) {
+	setWatchFilterFlags(t, []string{"/repo/api"}, nil, "")
+	f, err := newWatchFilter()
+	if err != nil {
+		t.Fatalf("newWatchFilter: %v", err)
+	}
+
+	steps := []struct {
+		event daemon.RequestStreamEvent
+		want  bool
+	}{
+		{daemon.RequestStreamEvent{Event: "request_pending", RequestID: "api-1", Project: "/repo/api", RiskTier: "dangerous"}, true},
+		{daemon.RequestStreamEvent{Event: "request_pending", RequestID: "web-1", Project: "/repo/web", RiskTier: "dangerous"}, false},
+		// Later events lack the project but belong to an emitted request.
+		{daemon.RequestStreamEvent{Event: "request_approved", RequestID: "api-1"}, true},
+		{daemon.RequestStreamEvent{Event: "request_approved", RequestID: "web-1"}, false},
+		{daemon.RequestStreamEvent{Event: "request_executed", RequestID: "api-1"}, true},
+		{daemon.RequestStreamEvent{Event: "request_executed", RequestID: "web-1"}, false},
+		{daemon.RequestStreamEvent{Event: "daemon_status"}, true},
+	}
+	for _, step := range steps {
+		ev := step.event
+		if got := f.admit(&ev); got != step.want {
+			t.Errorf("admit(%s %s) = %v, want %v", ev.Event, ev.RequestID, got, step.want)
+		}
+	}
+	if len(f.emitted) != 0 {
+		t.Errorf("expected finished requests to be forgotten, got %v", f.emitted)
+	}
+}
+
+func TestHandleDaemonEvent_FilterGatesAutoApprove(t *testing.T) {
+	h := testutil.NewHarness(t)
+	alice := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Alice"))
+	bob := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Bob"))
+	mine := testutil.MakeRequest(t, h.DB, alice, testutil.WithRisk(db.RiskTierCaution))
+	theirs := testutil.MakeRequest(t, h.DB, bob, testutil.WithRisk(db.RiskTierCaution))
+
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+
+	setWatchFilterFlags(t, nil, nil, "Alice")
+	origAuto, origSession := flagWatchAutoApproveCaution, flagWatchSessionID
+	t.Cleanup(func() { flagWatchAutoApproveCaution, flagWatchSessionID = origAuto, origSession })
+	flagWatchAutoApproveCaution, flagWatchSessionID = true, reviewer.ID
+
+	f, err := newWatchFilter()
+	if err != nil {
+		t.Fatalf("newWatchFilter: %v", err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, req := range []*db.Request{mine, theirs} {
+		event := daemon.Event{Type: "request_pending", Payload: map[string]any{
+			"request_id":   req.ID,
+			"project_path": h.ProjectDir,
+			"risk_tier":    string(req.RiskTier),
+			"requestor":    req.RequestorAgent,
+		}}
+		if err := handleDaemonEvent(context.Background(), event, f, enc); err != nil {
+			t.Fatalf("handleDaemonEvent: %v", err)
+		}
+	}
+
+	events := watchEvents(t, buf.String())
+	if len(events) != 1 || events[0]["request_id"] != mine.ID {
+		t.Fatalf("expected only %s to be emitted, got %v", mine.ID, events)
+	}
+	for req, want := range map[*db.Request]db.RequestStatus{mine: db.StatusApproved, theirs: db.StatusPending} {
+		got, err := h.DB.GetRequest(req.ID)
+		if err != nil {
+			t.Fatalf("GetRequest: %v", err)
+		}
+		if got.Status != want {
+			t.Errorf("request from %s: status %s, want %s", req.RequestorAgent, got.Status, want)
+		}
+	}
+}
+
+func TestPollTargetRequests_Filter(t *testing.T) {
+	h := testutil.NewHarness(t)
+	other := t.TempDir()
+	alice := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Alice"))
+	bob := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Bob"))
+	elsewhere := testutil.MakeSession(t, h.DB, testutil.WithProject(other), testutil.WithAgent("Alice"))
+
+	critical := testutil.MakeRequest(t, h.DB, alice, testutil.WithRisk(db.RiskTierCritical))
+	caution := testutil.MakeRequest(t, h.DB, alice, testutil.WithRisk(db.RiskTierCaution))
+	testutil.MakeRequest(t, h.DB, bob, testutil.WithRisk(db.RiskTierCritical))
+	testutil.MakeRequest(t, h.DB, elsewhere, testutil.WithRisk(db.RiskTierCritical))
+
+	setWatchFilterFlags(t, []string{h.ProjectDir}, []string{"dangerous", "critical"}, "alice")
+	origAuto := flagWatchAutoApproveCaution
+	t.Cleanup(func() { flagWatchAutoApproveCaution = origAuto })
+	flagWatchAutoApproveCaution = true
+
+	f, err := newWatchFilter()
+	if err != nil {
+		t.Fatalf("newWatchFilter: %v", err)
+	}
+	target := watchTarget{DBPath: h.DBPath, Filter: f.query()}
+	seen := make(map[string]db.RequestStatus)
+	var buf bytes.Buffer
+	if err := pollTargetRequests(context.Background(), h.DB, target, json.NewEncoder(&buf), seen); err != nil {
+		t.Fatalf("pollTargetRequests: %v", err)
+	}
+	events := watchEvents(t, buf.String())
+	if len(events) != 1 || events[0]["request_id"] != critical.ID {
+		t.Fatalf("expected only %s to be emitted, got %v", critical.ID, events)
+	}
+	if got, _ := h.DB.GetRequest(caution.ID); got.Status != db.StatusPending {
+		t.Errorf("filtered-out caution request was auto-approved: %s", got.Status)
+	}
+
+	// The emitted request is followed to its outcome.
+	if err := h.DB.CancelRequest(critical.ID, db.StatusPending, "Alice", "not needed"); err != nil {
+		t.Fatalf("CancelRequest: %v", err)
+	}
+	buf.Reset()
+	if err := pollTargetRequests(context.Background(), h.DB, target, json.NewEncoder(&buf), seen); err != nil {
+		t.Fatalf("pollTargetRequests: %v", err)
+	}
+	events = watchEvents(t, buf.String())
+	if len(events) != 1 || events[0]["event"] != "request_cancelled" || events[0]["request_id"] != critical.ID {
+		t.Fatalf("expected a request_cancelled event for %s, got %v", critical.ID, events)
+	}
+}
+
+func TestWatchDBPath(t *testing.T) {
+	origDB := flagDB
+	t.Cleanup(func() { flagDB = origDB })
+	flagDB = ""
+	setWatchFilterFlags(t, []string{"/repo/api", "/repo/web"}, nil, "")
+
+	if got := watchDBPath(); got != filepath.Join("/repo/api", ".slb", "state.db") {
+		t.Errorf("watchDBPath() = %s, want the first project's database", got)
+	}
+	flagDB = "/tmp/shared.db"
+	if got := watchDBPath(); got != "/tmp/shared.db" {
+		t.Errorf("watchDBPath() = %s, want --db", got)
+	}
+}
@@ -69,3 +69,48 @@ func TestRunWatch_FallbackToPolling(t *testing.T) {
 		t.Errorf("expected output to contain request ID, got: %s", buf2.String())
 	}
 }
+
+func TestRunWatch_EventSchema(t *testing.T) {
+	h := testutil.NewHarness(t)
+	oldDB, oldInterval, oldSchema := flagDB, flagWatchPollInterval, flagWatchEventSchema
+	defer func() { flagDB, flagWatchPollInterval, flagWatchEventSchema = oldDB, oldInterval, oldSchema }()
+	flagDB = h.DBPath
+	flagWatchPollInterval = 10 * time.Millisecond
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("echo test", h.ProjectDir, true),
+		testutil.WithStatus(db.StatusPending),
+	)
+
+	run := func(schema int) (string, error) {
+		flagWatchEventSchema = schema
+		var buf bytes.Buffer
+		cmd := &cobra.Command{Use: "watch"}
+		cmd.SetOut(&buf)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		cmd.SetContext(ctx)
+		err := runWatch(cmd, nil)
+		return buf.String(), err
+	}
+
+	out, err := run(1)
+	if err != nil || !strings.Contains(out, `"request_pending"`) || strings.Contains(out, "schema_version") {
+		t.Errorf("expected a v1 event without schema_version, got %q, %v", out, err)
+	}
+	out, err = run(2)
+	if err != nil || !strings.Contains(out, `"schema_version":2`) {
+		t.Errorf("expected a v2 event with schema_version, got %q, %v", out, err)
+	}
+	if strings.Contains(out, "has_preview") {
+		t.Errorf("v2 must not carry has_preview, got %q", out)
+	}
+	out, err = run(3)
+	if err != nil || !strings.Contains(out, `"has_preview":false`) {
+		t.Errorf("expected a v3 request_pending event with has_preview, got %q, %v", out, err)
+	}
+	if _, err := run(99); err == nil || !strings.Contains(err.Error(), "unknown event schema version 99") {
+		t.Errorf("expected an unknown version error, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Package cli implements the --state-file persistence for the polling watcher.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// watchStateVersion is the version of the --state-file format.
+const watchStateVersion = 1
+
+// watchState is the --state-file contents: the request statuses each
+// polling watcher has already reported, keyed by watcher identity (see
+// watchStateKey).
+type watchState struct {
+	Version  int                      `json:"version"`
+	Watchers map[string]*watcherState `json:"watchers"`
+}
+
+type watcherState struct {
+	UpdatedAt time.Time                   `json:"updated_at"`
+	Seen      map[string]db.RequestStatus `json:"seen"`
+}
+
+// watchStateKey identifies the watcher whose seen map is persisted for
+// target: its --session-id, or "default", plus "@member" in workspace mode.
+func watchStateKey(target watchTarget) string {
+	key := flagWatchSessionID
+	if key == "" {
+		key = "default"
+	}
+	if target.Project != "" {
+		key += "@" + target.Project
+	}
+	return key
+}
+
+// watchStateFile loads and saves one watcher's seen map. A zero path
+// disables persistence.
+type watchStateFile struct {
+	path  string
+	key   string
+	saved map[string]db.RequestStatus
+}
+
+// load returns the seen map saved under the watcher's key; a missing file
+// or key starts empty.
+func (f *watchStateFile) load() (map[string]db.RequestStatus, error) {
+	seen := make(map[string]db.RequestStatus)
+	if f.path == "" {
+		return seen, nil
+	}
+	state, err := readWatchState(f.path)
+	if err != nil {
+		return nil, err
+	}
+	if w := state.Watchers[f.key]; w != nil {
+		maps.Copy(seen, w.Seen)
+	}
+	f.saved = maps.Clone(seen)
+	return seen, nil
+}
+
+// save persists seen if it changed since the last load or save. Requests
+// in a terminal status are left out, since polling never reports them
+// again. Other watchers' entries are kept, and the file is replaced
+// atomically so a crash never leaves it half written. A failure is
+// reported on stderr and retried after the next poll; the watcher keeps
+// running.
+func (f *watchStateFile) save(seen map[string]db.RequestStatus) {
+	if f.path == "" || maps.Equal(seen, f.saved) {
+		return
+	}
+	if err := f.write(seen); err != nil {
+		fmt.Fprintf(os.Stderr, "[slb] Saving watch state failed: %v\n", err)
+		return
+	}
+	f.saved = maps.Clone(seen)
+}
+
+func (f *watchStateFile) write(seen map[string]db.RequestStatus) error {
+	state, err := readWatchState(f.path)
+	if err != nil {
+		return err
+	}
+	kept := make(map[string]db.RequestStatus, len(seen))
+	for id, status := range seen {
+		if !status.IsTerminal() {
+			kept[id] = status
+		}
+	}
+	state.Version = watchStateVersion
+	state.Watchers[f.key] = &watcherState{UpdatedAt: time.Now().UTC(), Seen: kept}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding watch state: %w", err)
+	}
+	return writeFileAtomic(f.path, data, 0600)
+}
+
+func readWatchState(path string) (*watchState, error) {
+	state := &watchState{Version: watchStateVersion}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading watch state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("parsing watch state %s: %w", path, err)
+		}
+		if state.Version > watchStateVersion {
+			return nil, fmt.Errorf("watch state %s has version %d, newer than supported %d", path, state.Version, watchStateVersion)
+		}
+	}
+	if state.Watchers == nil {
+		state.Watchers = make(map[string]*watcherState)
+	}
+	return state, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", tmpPath, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("syncing %s: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", tmpPath, err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("setting permissions on %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replacing %s: %w", path, err)
+	}
+	return nil
+}
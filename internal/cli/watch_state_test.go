@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// pollWatchEvents runs the polling watcher for a few polls and returns the
+// events it emitted.
+func pollWatchEvents(t *testing.T) []daemon.RequestStreamEvent {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var buf bytes.Buffer
+	if err := runWatchPolling(ctx, &buf); err != nil {
+		t.Fatalf("runWatchPolling failed: %v", err)
+	}
+	var events []daemon.RequestStreamEvent
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var event daemon.RequestStreamEvent
+		if err := dec.Decode(&event); err != nil {
+			t.Fatalf("failed to decode event: %v", err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestRunWatchPolling_StateFileResumes(t *testing.T) {
+	h := testutil.NewHarness(t)
+	oldDB, oldInterval, oldAuto, oldState := flagDB, flagWatchPollInterval, flagWatchAutoApproveCaution, flagWatchStateFile
+	flagDB = h.DBPath
+	flagWatchPollInterval = 10 * time.Millisecond
+	flagWatchAutoApproveCaution = false
+	flagWatchStateFile = filepath.Join(t.TempDir(), "watch", "state.json")
+	defer func() {
+		flagDB, flagWatchPollInterval, flagWatchAutoApproveCaution, flagWatchStateFile = oldDB, oldInterval, oldAuto, oldState
+	}()
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	first := testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand("echo first", h.ProjectDir, true))
+	still := testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand("echo still", h.ProjectDir, true))
+
+	events := pollWatchEvents(t)
+	if len(events) != 2 {
+		t.Fatalf("expected two request_pending events, got %+v", events)
+	}
+
+	// While the watcher is stopped, one request is approved and a new one
+	// arrives.
+	if err := h.DB.UpdateRequestStatus(first.ID, db.StatusApproved); err != nil {
+		t.Fatal(err)
+	}
+	second := testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand("echo second", h.ProjectDir, true))
+
+	got := map[string]string{}
+	for _, e := range pollWatchEvents(t) {
+		if prev, ok := got[e.RequestID]; ok {
+			t.Errorf("request %s reported twice: %s and %s", e.RequestID, prev, e.Event)
+		}
+		got[e.RequestID] = e.Event
+	}
+	want := map[string]string{first.ID: "request_approved", second.ID: "request_pending"}
+	if len(got) != len(want) || got[first.ID] != want[first.ID] || got[second.ID] != want[second.ID] {
+		t.Errorf("after restart got events %v, want %v (and none for %s)", got, want, still.ID)
+	}
+
+	data, err := os.ReadFile(flagWatchStateFile)
+	if err != nil {
+		t.Fatalf("reading state file: %v", err)
+	}
+	var state watchState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("parsing state file: %v", err)
+	}
+	seen := state.Watchers["default"]
+	if state.Version != watchStateVersion || seen == nil || len(seen.Seen) != 3 || seen.Seen[first.ID] != db.StatusApproved {
+		t.Errorf("unexpected state file contents: %s", data)
+	}
+}
+
+func TestWatchStateFile_KeepsOtherWatchersAndDropsTerminal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	other := &watchStateFile{path: path, key: "other"}
+	if _, err := other.load(); err != nil {
+		t.Fatalf("load from missing file: %v", err)
+	}
+	other.save(map[string]db.RequestStatus{"req-a": db.StatusPending})
+
+	mine := &watchStateFile{path: path, key: "mine@api"}
+	mine.save(map[string]db.RequestStatus{
+		"req-b": db.StatusApproved,
+		"req-c": db.StatusExecuted,
+	})
+
+	reloaded, err := (&watchStateFile{path: path, key: "mine@api"}).load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(reloaded) != 1 || reloaded["req-b"] != db.StatusApproved {
+		t.Errorf("expected only the non-terminal request kept, got %v", reloaded)
+	}
+	if seen, _ := (&watchStateFile{path: path, key: "other"}).load(); seen["req-a"] != db.StatusPending {
+		t.Errorf("expected the other watcher's entry kept, got %v", seen)
+	}
+
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("expected no temporary files left behind, got %v", entries)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mine.load(); err == nil || !strings.Contains(err.Error(), "parsing watch state") {
+		t.Errorf("expected a parse error for a corrupt state file, got %v", err)
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/daemon"
 	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
 )
 
 // =============================================================================
@@ -292,6 +296,43 @@ func TestEvaluateRequestForPolling_NewRequest(t *testing.T) {
 	}
 }
 
+// TestEvaluateRequestForPolling_Queued verifies that a queued request is
+// announced as request_queued, then as request_pending once admitted.
+func TestEvaluateRequestForPolling_Queued(t *testing.T) {
+	seen := make(map[string]db.RequestStatus)
+	result := evaluateRequestForPolling("req-123", db.StatusQueued, seen)
+	if result.Action != PollActionEmitNew || result.EventType != "request_queued" {
+		t.Errorf("new queued request: got %v %q, want EmitNew request_queued", result.Action, result.EventType)
+	}
+
+	seen["req-123"] = db.StatusQueued
+	result = evaluateRequestForPolling("req-123", db.StatusPending, seen)
+	if result.Action != PollActionEmitNew || result.EventType != "request_pending" {
+		t.Errorf("admitted request: got %v %q, want EmitNew request_pending", result.Action, result.EventType)
+	}
+
+	result = evaluateRequestForPolling("req-123", db.StatusCancelled, seen)
+	if result.Action != PollActionEmitStatusChange || result.EventType != "request_cancelled" {
+		t.Errorf("cancelled while queued: got %v %q, want request_cancelled", result.Action, result.EventType)
+	}
+}
+
+// TestEvaluateRequestForPolling_Rerequested verifies that an expired approval
+// is announced, then re-announced in full once it is re-requested.
+func TestEvaluateRequestForPolling_Rerequested(t *testing.T) {
+	seen := map[string]db.RequestStatus{"req-123": db.StatusApproved}
+	result := evaluateRequestForPolling("req-123", db.StatusApprovalExpired, seen)
+	if result.Action != PollActionEmitStatusChange || result.EventType != "request_approval_expired" {
+		t.Errorf("expired approval: got %v %q, want request_approval_expired", result.Action, result.EventType)
+	}
+
+	seen["req-123"] = db.StatusApprovalExpired
+	result = evaluateRequestForPolling("req-123", db.StatusPending, seen)
+	if result.Action != PollActionEmitNew || result.EventType != "request_rerequested" {
+		t.Errorf("re-requested: got %v %q, want EmitNew request_rerequested", result.Action, result.EventType)
+	}
+}
+
 // TestEvaluateRequestForPolling_StatusUnchanged verifies that a request with
 // unchanged status is skipped.
 func TestEvaluateRequestForPolling_StatusUnchanged(t *testing.T) {
@@ -444,6 +485,7 @@ func TestStatusToEventType_AllStatuses(t *testing.T) {
 		{db.StatusExecutionFailed, "request_executed"},
 		{db.StatusTimeout, "request_timeout"},
 		{db.StatusCancelled, "request_cancelled"},
+		{db.StatusApprovalExpired, "request_approval_expired"},
 		{db.StatusPending, ""},            // Pending is not a status change event
 		{db.RequestStatus("unknown"), ""}, // Unknown status returns empty
 	}
@@ -1353,6 +1395,25 @@ func TestAutoApproveCaution_WrongTier(t *testing.T) {
 	}
 }
 
+func TestAutoApproveCaution_RuntimeTargets(t *testing.T) {
+	h := testutil.NewHarness(t)
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	// Stored as CAUTION (e.g. classified before the substitution rule), the
+	// request must still not be auto-approved.
+	req := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand("touch $(cat targets.txt)", h.ProjectDir, true),
+		testutil.WithRisk(db.RiskTierCaution),
+	)
+
+	err := autoApproveCautionIn(context.Background(), h.DBPath, req.ID, "", nil)
+	if err == nil || !strings.Contains(err.Error(), "runtime substitution") {
+		t.Fatalf("expected a runtime substitution denial, got %v", err)
+	}
+	if got, _ := h.DB.GetRequest(req.ID); got.Status != db.StatusPending {
+		t.Errorf("expected the request left pending, got %s", got.Status)
+	}
+}
+
 func TestAutoApproveCaution_SuccessfulApproval(t *testing.T) {
 	tmpDir := t.TempDir()
 	dbPath := tmpDir + "/test.db"
@@ -1459,6 +1520,15 @@ func TestAutoApproveCaution_SuccessfulApproval(t *testing.T) {
 	if reviews[0].ReviewerSessionID != "auto-approve" {
 		t.Errorf("expected session 'auto-approve', got %s", reviews[0].ReviewerSessionID)
 	}
+	// The approval signs the command hash, so it verifies at execution.
+	autoSess, err := dbConn.GetSession("auto-approve")
+	if err != nil {
+		t.Fatalf("failed to get auto-approve session: %v", err)
+	}
+	r := reviews[0]
+	if r.CommandHash != "caution123" || !db.VerifyReviewSignature(autoSess.SessionKey, r.RequestID, r.CommandHash, r.Decision, r.SignatureTimestamp, r.Signature) {
+		t.Errorf("expected a signed approval over the command hash, got hash %q signature %q", r.CommandHash, r.Signature)
+	}
 }
 
 func TestAutoApproveCaution_WithCustomSession(t *testing.T) {
@@ -1624,3 +1694,426 @@ func TestAutoApproveCaution_MultipleApprovalsNeeded(t *testing.T) {
 		t.Errorf("expected 1 review, got %d", len(reviews))
 	}
 }
+
+func TestRunWatchWorkspace_TagsEventsWithMember(t *testing.T) {
+	root := setupWorkspaceRepo(t)
+	resetWorkspaceFlags()
+	if err := runWorkspaceInit(nil, nil); err != nil {
+		t.Fatalf("runWorkspaceInit failed: %v", err)
+	}
+	ws, err := config.LoadWorkspace(root)
+	if err != nil {
+		t.Fatalf("LoadWorkspace: %v", err)
+	}
+
+	want := map[string]string{} // request ID -> member name
+	for _, member := range ws.Members {
+		memberDB, err := db.Open(filepath.Join(member, ".slb", "state.db"))
+		if err != nil {
+			t.Fatalf("open member db: %v", err)
+		}
+		sess := testutil.MakeSession(t, memberDB, testutil.WithProject(member))
+		req := testutil.MakeRequest(t, memberDB, sess, func(r *db.Request) { r.ID = "" })
+		memberDB.Close()
+		want[req.ID] = ws.MemberName(member)
+	}
+
+	oldInterval := flagWatchPollInterval
+	oldAuto := flagWatchAutoApproveCaution
+	flagWatchPollInterval = 10 * time.Millisecond
+	flagWatchAutoApproveCaution = false
+	defer func() {
+		flagWatchPollInterval = oldInterval
+		flagWatchAutoApproveCaution = oldAuto
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var buf bytes.Buffer
+	if err := runWatchWorkspace(ctx, ws, &buf); err != nil {
+		t.Fatalf("runWatchWorkspace failed: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event daemon.RequestStreamEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("failed to parse event: %v (line: %s)", err, line)
+		}
+		if event.Event != "request_pending" {
+			t.Fatalf("unexpected event %q", event.Event)
+		}
+		got[event.RequestID] = event.Project
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected one pending event per member (%d), got %v", len(want), got)
+	}
+	for id, member := range want {
+		if got[id] != member {
+			t.Errorf("event for %s has project %q, want %q", id, got[id], member)
+		}
+	}
+}
+
+// =============================================================================
+// SAFETY-CRITICAL TESTS for shouldAutoExecuteApproved
+// =============================================================================
+//
+// The shouldAutoExecuteApproved function guards against unattended execution
+// of commands. Every status/tier/ceiling combination MUST be covered.
+// =============================================================================
+
+// TestShouldAutoExecuteApproved_AllCombinations verifies ALL status, tier and
+// max-tier combinations behave correctly.
+func TestShouldAutoExecuteApproved_AllCombinations(t *testing.T) {
+	statuses := []db.RequestStatus{
+		db.StatusPending,
+		db.StatusApproved,
+		db.StatusRejected,
+		db.StatusExecuting,
+		db.StatusExecuted,
+		db.StatusExecutionFailed,
+		db.StatusTimeout,
+		db.StatusCancelled,
+	}
+
+	tiers := []db.RiskTier{
+		db.RiskTierCaution,
+		db.RiskTierDangerous,
+		db.RiskTierCritical,
+		db.RiskTier("safe"),
+		db.RiskTier(""),
+	}
+
+	maxTiers := []db.RiskTier{
+		db.RiskTierCaution,
+		db.RiskTierDangerous,
+		db.RiskTierCritical,
+		db.RiskTier("bogus"),
+	}
+
+	for _, status := range statuses {
+		for _, tier := range tiers {
+			for _, maxTier := range maxTiers {
+				t.Run(string(status)+"_"+string(tier)+"_max_"+string(maxTier), func(t *testing.T) {
+					decision := shouldAutoExecuteApproved(status, tier, maxTier)
+
+					// ONLY approved caution (any valid ceiling) or approved
+					// dangerous (dangerous ceiling) may execute.
+					expected := status == db.StatusApproved &&
+						((tier == db.RiskTierCaution && (maxTier == db.RiskTierCaution || maxTier == db.RiskTierDangerous)) ||
+							(tier == db.RiskTierDangerous && maxTier == db.RiskTierDangerous))
+
+					if decision.ShouldExecute != expected {
+						t.Errorf(
+							"status=%s tier=%s max=%s: expected ShouldExecute=%v, got %v (reason: %s)",
+							status, tier, maxTier, expected, decision.ShouldExecute, decision.Reason,
+						)
+					}
+					if decision.Reason == "" {
+						t.Errorf("status=%s tier=%s max=%s: reason should not be empty", status, tier, maxTier)
+					}
+				})
+			}
+		}
+	}
+}
+
+// TestShouldAutoExecuteApproved_NeverCritical verifies CRITICAL requests are
+// refused even with the most permissive (or an invalid critical) ceiling.
+func TestShouldAutoExecuteApproved_NeverCritical(t *testing.T) {
+	for _, maxTier := range []db.RiskTier{db.RiskTierDangerous, db.RiskTierCritical} {
+		decision := shouldAutoExecuteApproved(db.StatusApproved, db.RiskTierCritical, maxTier)
+		if decision.ShouldExecute {
+			t.Fatalf("CRITICAL request must never be auto-executed (max=%s)", maxTier)
+		}
+		if !strings.Contains(decision.Reason, "critical") {
+			t.Errorf("reason should mention critical, got: %s", decision.Reason)
+		}
+	}
+}
+
+func TestShouldAutoExecuteApproved_ReasonContainsDetails(t *testing.T) {
+	decision := shouldAutoExecuteApproved(db.StatusPending, db.RiskTierCaution, db.RiskTierCaution)
+	if !strings.Contains(decision.Reason, "pending") {
+		t.Errorf("reason should contain status, got: %s", decision.Reason)
+	}
+
+	decision = shouldAutoExecuteApproved(db.StatusApproved, db.RiskTierDangerous, db.RiskTierCaution)
+	if !strings.Contains(decision.Reason, "dangerous") || !strings.Contains(decision.Reason, "caution") {
+		t.Errorf("reason should contain tier and max tier, got: %s", decision.Reason)
+	}
+}
+
+func TestValidateAutoExecuteFlags(t *testing.T) {
+	origEnabled, origSession, origMax := flagWatchAutoExecute, flagWatchSessionID, flagWatchMaxTier
+	defer func() {
+		flagWatchAutoExecute, flagWatchSessionID, flagWatchMaxTier = origEnabled, origSession, origMax
+	}()
+
+	tests := []struct {
+		name    string
+		enabled bool
+		session string
+		maxTier string
+		wantErr string
+	}{
+		{"disabled ignores other flags", false, "", "critical", ""},
+		{"caution", true, "sess", "caution", ""},
+		{"dangerous", true, "sess", "dangerous", ""},
+		{"missing session", true, "", "caution", "--session-id"},
+		{"critical rejected", true, "sess", "critical", "never auto-executed"},
+		{"invalid tier", true, "sess", "safe", "invalid --max-tier"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			flagWatchAutoExecute, flagWatchSessionID, flagWatchMaxTier = tc.enabled, tc.session, tc.maxTier
+			err := validateAutoExecuteFlags()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// setupAutoExecuteFlags enables auto-execute for sessionID and restores the
+// previous flag values when the test ends.
+func setupAutoExecuteFlags(t *testing.T, sessionID, maxTier string) {
+	t.Helper()
+	origEnabled, origSession, origMax, origConfig := flagWatchAutoExecute, flagWatchSessionID, flagWatchMaxTier, flagConfig
+	t.Cleanup(func() {
+		flagWatchAutoExecute, flagWatchSessionID, flagWatchMaxTier, flagConfig = origEnabled, origSession, origMax, origConfig
+	})
+	flagWatchAutoExecute, flagWatchSessionID, flagWatchMaxTier, flagConfig = true, sessionID, maxTier, ""
+}
+
+// makeApprovedRequest creates an approved request for a harmless command with
+// a hash that passes the executor's gates.
+func makeApprovedRequest(t *testing.T, h *testutil.Harness, sess *db.Session, tier db.RiskTier) *db.Request {
+	t.Helper()
+	req := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand(testutil.TruePath(), h.ProjectDir, true),
+		testutil.WithRisk(tier),
+	)
+	req.Command.Hash = db.ComputeCommandHash(req.Command)
+	if _, err := h.DB.Exec(`UPDATE requests SET command_hash = ? WHERE id = ?`, req.Command.Hash, req.ID); err != nil {
+		t.Fatalf("updating hash: %v", err)
+	}
+	if err := h.DB.UpdateRequestStatus(req.ID, db.StatusApproved); err != nil {
+		t.Fatalf("approving request: %v", err)
+	}
+	return req
+}
+
+func TestPollRequests_AutoExecuteApproved(t *testing.T) {
+	h := testutil.NewHarness(t)
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	setupAutoExecuteFlags(t, sess.ID, "caution")
+
+	caution := makeApprovedRequest(t, h, sess, db.RiskTierCaution)
+	dangerous := makeApprovedRequest(t, h, sess, db.RiskTierDangerous)
+	critical := makeApprovedRequest(t, h, sess, db.RiskTierCritical)
+
+	// Requests were seen while pending, so this poll observes the approval.
+	seen := map[string]db.RequestStatus{
+		caution.ID:   db.StatusPending,
+		dangerous.ID: db.StatusPending,
+		critical.ID:  db.StatusPending,
+	}
+	var buf bytes.Buffer
+	if err := pollTargetRequests(context.Background(), h.DB, watchTarget{DBPath: h.DBPath}, json.NewEncoder(&buf), seen); err != nil {
+		t.Fatalf("pollTargetRequests failed: %v", err)
+	}
+
+	var executed []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var ev map[string]any
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		switch ev["event"] {
+		case "request_auto_executed":
+			executed = append(executed, ev["request_id"].(string))
+			if ev["exit_code"].(float64) != 0 {
+				t.Errorf("expected exit_code 0, got %v", ev["exit_code"])
+			}
+			if ev["log_path"] == "" {
+				t.Error("expected log_path to be set")
+			}
+		case "auto_execute_error":
+			t.Errorf("unexpected auto_execute_error: %v", ev["error"])
+		}
+	}
+	if len(executed) != 1 || executed[0] != caution.ID {
+		t.Fatalf("expected only %s to be auto-executed, got %v", caution.ID, executed)
+	}
+
+	wantStatus := map[string]db.RequestStatus{
+		caution.ID:   db.StatusExecuted,
+		dangerous.ID: db.StatusApproved,
+		critical.ID:  db.StatusApproved,
+	}
+	for id, want := range wantStatus {
+		got, err := h.DB.GetRequest(id)
+		if err != nil {
+			t.Fatalf("GetRequest(%s): %v", id, err)
+		}
+		if got.Status != want {
+			t.Errorf("request %s status = %s, want %s", id, got.Status, want)
+		}
+	}
+
+	// A second poll must not re-execute anything.
+	buf.Reset()
+	if err := pollTargetRequests(context.Background(), h.DB, watchTarget{DBPath: h.DBPath}, json.NewEncoder(&buf), seen); err != nil {
+		t.Fatalf("second poll failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "request_auto_executed") {
+		t.Errorf("request re-executed on second poll: %s", buf.String())
+	}
+}
+
+func TestAutoExecuteApproved_DangerousWithinMaxTier(t *testing.T) {
+	h := testutil.NewHarness(t)
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	setupAutoExecuteFlags(t, sess.ID, "dangerous")
+	req := makeApprovedRequest(t, h, sess, db.RiskTierDangerous)
+
+	var buf bytes.Buffer
+	if err := autoExecuteApproved(context.Background(), h.DBPath, req.ID, "api", json.NewEncoder(&buf)); err != nil {
+		t.Fatalf("autoExecuteApproved failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"request_auto_executed"`) || !strings.Contains(buf.String(), `"project":"api"`) {
+		t.Fatalf("expected tagged request_auto_executed event, got: %s", buf.String())
+	}
+}
+
+func TestAutoExecuteApproved_ExecutionGateFailure(t *testing.T) {
+	h := testutil.NewHarness(t)
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	setupAutoExecuteFlags(t, sess.ID, "caution")
+	req := makeApprovedRequest(t, h, sess, db.RiskTierCaution)
+
+	// Tamper with the stored hash: gate 3 must still refuse execution.
+	if _, err := h.DB.Exec(`UPDATE requests SET command_hash = 'tampered' WHERE id = ?`, req.ID); err != nil {
+		t.Fatalf("tampering hash: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := autoExecuteApproved(context.Background(), h.DBPath, req.ID, "", json.NewEncoder(&buf)); err != nil {
+		t.Fatalf("autoExecuteApproved should report errors as events, got: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"auto_execute_error"`) {
+		t.Fatalf("expected auto_execute_error event, got: %s", buf.String())
+	}
+	got, _ := h.DB.GetRequest(req.ID)
+	if got.Status != db.StatusApproved {
+		t.Errorf("status = %s, want approved", got.Status)
+	}
+}
+
+func TestAutoExecuteApproved_SkipsNonApproved(t *testing.T) {
+	h := testutil.NewHarness(t)
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	setupAutoExecuteFlags(t, sess.ID, "dangerous")
+	req := testutil.MakeRequest(t, h.DB, sess, testutil.WithRisk(db.RiskTierCaution))
+
+	var buf bytes.Buffer
+	if err := autoExecuteApproved(context.Background(), h.DBPath, req.ID, "", json.NewEncoder(&buf)); err != nil {
+		t.Fatalf("autoExecuteApproved failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no events for pending request, got: %s", buf.String())
+	}
+}
+
+func TestHandleDaemonEvent_AutoExecutesInEventProject(t *testing.T) {
+	root := testutil.NewHarness(t)
+	member := testutil.NewHarness(t)
+	sess := testutil.MakeSession(t, member.DB, testutil.WithProject(member.ProjectDir))
+	setupAutoExecuteFlags(t, sess.ID, "caution")
+	origDB := flagDB
+	t.Cleanup(func() { flagDB = origDB })
+	flagDB = root.DBPath
+
+	req := makeApprovedRequest(t, member, sess, db.RiskTierCaution)
+	eventType, payload, ok := daemon.ReviewOutcomeEvent(req, &core.ReviewResult{
+		Review:               &db.Review{ReviewerAgent: "Reviewer"},
+		RequestStatusChanged: true,
+		NewRequestStatus:     db.StatusApproved,
+	})
+	if !ok || eventType != "request_approved" {
+		t.Fatalf("ReviewOutcomeEvent = %q, %v; want request_approved", eventType, ok)
+	}
+
+	// Round-trip through JSON the way subscribers receive daemon events.
+	data, err := json.Marshal(daemon.Event{Type: eventType, Payload: payload, Time: time.Now().Unix()})
+	if err != nil {
+		t.Fatalf("marshal event: %v", err)
+	}
+	var event daemon.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("unmarshal event: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := handleDaemonEvent(context.Background(), event, &watchFilter{}, json.NewEncoder(&buf)); err != nil {
+		t.Fatalf("handleDaemonEvent failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"request_auto_executed"`) {
+		t.Fatalf("expected request_auto_executed event, got: %s", buf.String())
+	}
+	got, err := member.DB.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("GetRequest: %v", err)
+	}
+	if got.Status != db.StatusExecuted {
+		t.Errorf("member request status = %s, want executed", got.Status)
+	}
+}
+
+func TestReviewOutcomeEvent_SkipsUndecided(t *testing.T) {
+	req := &db.Request{ID: "r1", ProjectPath: "/p"}
+	if _, _, ok := daemon.ReviewOutcomeEvent(req, &core.ReviewResult{Review: &db.Review{}}); ok {
+		t.Error("expected no event for a review that left the request pending")
+	}
+	_, payload, ok := daemon.ReviewOutcomeEvent(req, &core.ReviewResult{
+		Review:               &db.Review{ReviewerAgent: "R"},
+		RequestStatusChanged: true,
+		NewRequestStatus:     db.StatusRejected,
+	})
+	if !ok || payload["rejected_by"] != "R" || payload["project_path"] != "/p" {
+		t.Errorf("unexpected rejection payload: %v", payload)
+	}
+}
+
+func TestPollTargetRequests_MultiLineCommandIsOneNDJSONLine(t *testing.T) {
+	h := testutil.NewHarness(t)
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	raw := "psql -d app <<SQL\nUPDATE users\n   SET active = false;\nSQL"
+	req := testutil.MakeRequest(t, h.DB, sess, testutil.WithCommand(raw, h.ProjectDir, true))
+
+	var buf bytes.Buffer
+	seen := map[string]db.RequestStatus{}
+	if err := pollTargetRequests(context.Background(), h.DB, watchTarget{DBPath: h.DBPath}, json.NewEncoder(&buf), seen); err != nil {
+		t.Fatalf("pollTargetRequests failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one NDJSON line, got %d: %q", len(lines), buf.String())
+	}
+	var ev daemon.RequestStreamEvent
+	if err := json.Unmarshal([]byte(lines[0]), &ev); err != nil {
+		t.Fatalf("invalid NDJSON line %q: %v", lines[0], err)
+	}
+	if ev.RequestID != req.ID || ev.Command != raw {
+		t.Errorf("expected command %q for %s, got %q for %s", raw, req.ID, ev.Command, ev.RequestID)
+	}
+}
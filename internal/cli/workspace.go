@@ -0,0 +1,294 @@
+// Package cli implements the workspace commands for monorepos.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagWorkspaceMembers  []string
+	flagWorkspaceDiscover bool
+	flagWorkspaceForce    bool
+	flagWorkspaceKey      string
+)
+
+func init() {
+	workspaceInitCmd.Flags().StringSliceVar(&flagWorkspaceMembers, "member", nil, "member project directory relative to the root (repeatable; skips discovery)")
+	workspaceInitCmd.Flags().BoolVar(&flagWorkspaceDiscover, "discover", false, "rediscover members even if .slb/workspace.toml exists")
+	workspaceInitCmd.Flags().BoolVarP(&flagWorkspaceForce, "force", "f", false, "rewrite the workspace root config.toml")
+	workspaceInitCmd.Flags().StringVarP(&flagWorkspaceKey, "session-key", "k", "", "session key for registering members with a running daemon")
+
+	workspaceCmd.AddCommand(workspaceInitCmd)
+	rootCmd.AddCommand(workspaceCmd)
+}
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manage multi-project workspaces (monorepos)",
+}
+
+var workspaceInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Initialize SLB for every project in a monorepo",
+	Long: `Initialize an SLB workspace at the repository root.
+
+Members are read from .slb/workspace.toml when present, taken from --member,
+or discovered by scanning for project manifests (go.mod, package.json,
+Cargo.toml, pyproject.toml, ...) and existing .slb/ directories.
+
+Each member gets its own .slb/ state. The root's .slb/config.toml holds the
+shared policy; members inherit it and can override any key in their own
+.slb/config.toml. If the daemon is running and --session-id/--session-key name
+an active session in the root or a member, all members are registered with it.
+
+Commands run anywhere inside a member resolve to that member automatically.
+Use 'slb pending --workspace' and 'slb watch --workspace' to see requests
+across all members.`,
+	RunE: runWorkspaceInit,
+}
+
+func runWorkspaceInit(cmd *cobra.Command, args []string) error {
+	root, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+
+	ws, err := resolveWorkspaceMembers(root)
+	if err != nil {
+		return err
+	}
+	if len(ws.Members) == 0 {
+		return fmt.Errorf("no member projects found under %s (list them with --member)", root)
+	}
+	if err := config.WriteWorkspace(ws); err != nil {
+		return err
+	}
+
+	// The root holds the shared policy and state for commands run outside members.
+	rootSLB := filepath.Join(ws.Root, ".slb")
+	if _, err := initProjectDir(rootSLB); err != nil {
+		return err
+	}
+	_, rootConfig, err := config.ConfigPaths(ws.Root, "")
+	if err != nil {
+		return err
+	}
+	if err := writeDefaultConfig(rootConfig, flagWorkspaceForce); err != nil {
+		return fmt.Errorf("creating config: %w", err)
+	}
+
+	type memberView struct {
+		Name     string `json:"name"`
+		Path     string `json:"path"`
+		Database string `json:"database"`
+		Config   string `json:"config"`
+	}
+	members := make([]memberView, 0, len(ws.Members))
+	for _, m := range ws.Members {
+		slbDir := filepath.Join(m, ".slb")
+		dbPath, err := initProjectDir(slbDir)
+		if err != nil {
+			return err
+		}
+		_, configPath, err := config.ConfigPaths(m, "")
+		if err != nil {
+			return err
+		}
+		if err := writeMemberConfig(configPath, rootConfig); err != nil {
+			return fmt.Errorf("creating config for %s: %w", m, err)
+		}
+		members = append(members, memberView{
+			Name:     ws.MemberName(m),
+			Path:     m,
+			Database: dbPath,
+			Config:   configPath,
+		})
+	}
+
+	if err := addToGitignore(filepath.Join(ws.Root, ".gitignore")); err != nil {
+		// Non-fatal: just warn
+		fmt.Fprintf(os.Stderr, "Warning: could not update .gitignore: %v\n", err)
+	}
+
+	ctx := context.Background()
+	if cmd != nil {
+		ctx = cmd.Context()
+	}
+	registered, err := registerWorkspaceWithDaemon(ctx, ws)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not register workspace with daemon: %v\n", err)
+	}
+
+	result := map[string]any{
+		"initialized": true,
+		"root":        ws.Root,
+		"manifest":    config.WorkspacePath(ws.Root),
+		"members":     members,
+		"registered":  registered,
+	}
+
+	switch GetOutput() {
+	case "json", "yaml":
+		out := output.New(output.Format(GetOutput()))
+		return out.Write(result)
+	case "text":
+		fmt.Printf("Initialized SLB workspace in %s\n", ws.Root)
+		fmt.Println()
+		fmt.Printf("Members (%d):\n", len(members))
+		for _, m := range members {
+			fmt.Printf("  %s\n", m.Name)
+		}
+		fmt.Println()
+		fmt.Println("Shared policy: .slb/config.toml (members override in their own .slb/config.toml)")
+		if registered {
+			fmt.Println("Registered all members with the running daemon.")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s", GetOutput())
+	}
+}
+
+// resolveWorkspaceMembers determines the workspace members for root from
+// --member, the existing manifest, or discovery, in that order.
+func resolveWorkspaceMembers(root string) (*config.Workspace, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving workspace root: %w", err)
+	}
+
+	if len(flagWorkspaceMembers) > 0 {
+		ws := &config.Workspace{Root: root}
+		seen := make(map[string]bool)
+		for _, m := range flagWorkspaceMembers {
+			if !filepath.IsAbs(m) {
+				m = filepath.Join(root, m)
+			}
+			m = filepath.Clean(m)
+			if rel, err := filepath.Rel(root, m); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return nil, fmt.Errorf("member %s must be a subdirectory of %s", m, root)
+			}
+			if info, err := os.Stat(m); err != nil || !info.IsDir() {
+				return nil, fmt.Errorf("member %s is not a directory", m)
+			}
+			if !seen[m] {
+				seen[m] = true
+				ws.Members = append(ws.Members, m)
+			}
+		}
+		sort.Strings(ws.Members)
+		return ws, nil
+	}
+
+	if _, err := os.Stat(config.WorkspacePath(root)); err == nil && !flagWorkspaceDiscover {
+		return config.LoadWorkspace(root)
+	}
+
+	members, err := config.DiscoverWorkspaceMembers(root)
+	if err != nil {
+		return nil, err
+	}
+	return &config.Workspace{Root: root, Members: members}, nil
+}
+
+// writeMemberConfig writes a member config file that inherits the workspace
+// policy. Existing member configs are left untouched.
+func writeMemberConfig(path, rootConfig string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	content := fmt.Sprintf(`# SLB Configuration (workspace member)
+#
+# Policies are inherited from the workspace root: %s
+# Set keys here to override them for this project only, e.g.:
+#
+# [general]
+# min_approvals = 1
+`, rootConfig)
+	return os.WriteFile(path, []byte(content), 0600)
+}
+
+// registerWorkspaceWithDaemon registers the root and members with a running
+// daemon using the --session-id/--session-key session. It reports false
+// without error when no daemon is running.
+func registerWorkspaceWithDaemon(ctx context.Context, ws *config.Workspace) (bool, error) {
+	if !daemon.NewClient().IsDaemonRunning() {
+		return false, nil
+	}
+	if flagSessionID == "" || flagWorkspaceKey == "" {
+		return false, errors.New("daemon is running; pass --session-id and --session-key to register the members")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	client := daemon.NewIPCClient(daemon.DefaultSocketPath())
+	defer client.Close()
+	for _, p := range append([]string{ws.Root}, ws.Members...) {
+		if err := client.RegisterProject(ctx, p, flagSessionID, flagWorkspaceKey); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// currentWorkspace returns the workspace containing the current project.
+func currentWorkspace() (*config.Workspace, error) {
+	project, err := projectPath()
+	if err != nil {
+		return nil, err
+	}
+	ws, err := config.FindWorkspace(project)
+	if err != nil {
+		return nil, err
+	}
+	if ws == nil {
+		return nil, errors.New("not inside an SLB workspace (run 'slb workspace init' at the repository root)")
+	}
+	return ws, nil
+}
+
+// workspaceProjects returns the root and member project directories whose
+// state databases exist, paired with their display names.
+func workspaceProjects(ws *config.Workspace) []workspaceProject {
+	var projects []workspaceProject
+	for _, p := range append([]string{ws.Root}, ws.Members...) {
+		dbPath := filepath.Join(p, ".slb", "state.db")
+		if _, err := os.Stat(dbPath); err != nil {
+			continue
+		}
+		name := ws.MemberName(p)
+		if p == ws.Root {
+			name = "."
+		}
+		projects = append(projects, workspaceProject{Name: name, Path: p, DBPath: dbPath})
+	}
+	return projects
+}
+
+// workspaceProject is one project (root or member) in a workspace.
+type workspaceProject struct {
+	Name   string
+	Path   string
+	DBPath string
+}
+
+// openWorkspaceDB opens a workspace project's state database read-only.
+func openWorkspaceDB(p workspaceProject) (*db.DB, error) {
+	return db.OpenWithOptions(p.DBPath, db.OpenOptions{ReadOnly: true})
+}
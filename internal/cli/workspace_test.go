@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+)
+
+// setupWorkspaceRepo creates a monorepo layout with two member projects and
+// chdirs into it.
+func setupWorkspaceRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, f := range []string{"services/api/go.mod", "web/package.json", "web/node_modules/x/package.json", "docs/README.md"} {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	if err := os.Chdir(root); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	// Resolve symlinks (e.g. macOS /var -> /private/var) so paths compare equal.
+	if wd, err := os.Getwd(); err == nil {
+		root = wd
+	}
+	return root
+}
+
+func resetWorkspaceFlags() {
+	flagWorkspaceMembers = nil
+	flagWorkspaceDiscover = false
+	flagWorkspaceForce = false
+	flagWorkspaceKey = ""
+	flagProject = ""
+	flagOutput = "json"
+	flagJSON = true
+}
+
+func TestWorkspaceInit_DiscoversMembers(t *testing.T) {
+	root := setupWorkspaceRepo(t)
+	resetWorkspaceFlags()
+
+	if err := runWorkspaceInit(nil, nil); err != nil {
+		t.Fatalf("runWorkspaceInit failed: %v", err)
+	}
+
+	ws, err := config.LoadWorkspace(root)
+	if err != nil {
+		t.Fatalf("LoadWorkspace: %v", err)
+	}
+	api := filepath.Join(root, "services", "api")
+	web := filepath.Join(root, "web")
+	if len(ws.Members) != 2 || ws.Members[0] != api || ws.Members[1] != web {
+		t.Fatalf("members = %v, want [%s %s]", ws.Members, api, web)
+	}
+
+	for _, dir := range []string{root, api, web} {
+		if _, err := os.Stat(filepath.Join(dir, ".slb", "state.db")); err != nil {
+			t.Errorf("state.db not created in %s: %v", dir, err)
+		}
+	}
+
+	// Root gets the full default policy; members get an inheriting stub.
+	rootCfg, _ := os.ReadFile(filepath.Join(root, ".slb", "config.toml"))
+	if !strings.Contains(string(rootCfg), "min_approvals") {
+		t.Errorf("root config should contain defaults:\n%s", rootCfg)
+	}
+	memberCfg, _ := os.ReadFile(filepath.Join(api, ".slb", "config.toml"))
+	if !strings.Contains(string(memberCfg), "inherited from the workspace root") {
+		t.Errorf("member config should describe inheritance:\n%s", memberCfg)
+	}
+
+	gitignore, _ := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if !strings.Contains(string(gitignore), ".slb/") {
+		t.Error(".gitignore does not contain .slb/ entry")
+	}
+}
+
+func TestWorkspaceInit_KeepsMemberOverrides(t *testing.T) {
+	root := setupWorkspaceRepo(t)
+	resetWorkspaceFlags()
+
+	if err := runWorkspaceInit(nil, nil); err != nil {
+		t.Fatalf("runWorkspaceInit failed: %v", err)
+	}
+	memberCfg := filepath.Join(root, "web", ".slb", "config.toml")
+	if err := config.WriteValue(memberCfg, "general.min_approvals", 1); err != nil {
+		t.Fatalf("WriteValue: %v", err)
+	}
+
+	// Re-running init (even with --force) must not clobber member overrides.
+	flagWorkspaceForce = true
+	if err := runWorkspaceInit(nil, nil); err != nil {
+		t.Fatalf("second runWorkspaceInit failed: %v", err)
+	}
+	data, _ := os.ReadFile(memberCfg)
+	if !strings.Contains(string(data), "min_approvals = 1") {
+		t.Fatalf("member override lost:\n%s", data)
+	}
+}
+
+func TestWorkspaceInit_KeepsExistingYAMLConfig(t *testing.T) {
+	root := setupWorkspaceRepo(t)
+	resetWorkspaceFlags()
+
+	memberSLB := filepath.Join(root, "web", ".slb")
+	if err := os.MkdirAll(memberSLB, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(memberSLB, "config.yaml"), []byte("general:\n  min_approvals: 1\n"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := runWorkspaceInit(nil, nil); err != nil {
+		t.Fatalf("runWorkspaceInit failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(memberSLB, "config.toml")); !os.IsNotExist(err) {
+		t.Errorf("config.toml created beside the member's config.yaml: %v", err)
+	}
+
+	// A second config file makes the lookup ambiguous, and init says so.
+	if err := os.WriteFile(filepath.Join(memberSLB, "config.toml"), nil, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := runWorkspaceInit(nil, nil); !errors.Is(err, config.ErrAmbiguousConfig) {
+		t.Fatalf("runWorkspaceInit err = %v, want ErrAmbiguousConfig", err)
+	}
+}
+
+func TestWorkspaceInit_ExplicitMembers(t *testing.T) {
+	root := setupWorkspaceRepo(t)
+	resetWorkspaceFlags()
+	flagWorkspaceMembers = []string{"docs"}
+
+	if err := runWorkspaceInit(nil, nil); err != nil {
+		t.Fatalf("runWorkspaceInit failed: %v", err)
+	}
+	ws, err := config.LoadWorkspace(root)
+	if err != nil {
+		t.Fatalf("LoadWorkspace: %v", err)
+	}
+	if len(ws.Members) != 1 || ws.Members[0] != filepath.Join(root, "docs") {
+		t.Fatalf("members = %v, want only docs", ws.Members)
+	}
+
+	// Existing manifest is reused on re-init unless --discover is given.
+	flagWorkspaceMembers = nil
+	if err := runWorkspaceInit(nil, nil); err != nil {
+		t.Fatalf("re-init failed: %v", err)
+	}
+	if ws, _ := config.LoadWorkspace(root); len(ws.Members) != 1 {
+		t.Fatalf("manifest should be reused, got %v", ws.Members)
+	}
+	flagWorkspaceDiscover = true
+	if err := runWorkspaceInit(nil, nil); err != nil {
+		t.Fatalf("re-init with --discover failed: %v", err)
+	}
+	// docs now has an .slb/ of its own, so discovery keeps it alongside api and web.
+	if ws, _ := config.LoadWorkspace(root); len(ws.Members) != 3 {
+		t.Fatalf("--discover should rediscover members, got %v", ws.Members)
+	}
+}
+
+func TestWorkspaceInit_Errors(t *testing.T) {
+	setupWorkspaceRepo(t)
+	resetWorkspaceFlags()
+
+	flagWorkspaceMembers = []string{"../outside"}
+	if err := runWorkspaceInit(nil, nil); err == nil {
+		t.Error("expected error for member outside the root")
+	}
+
+	flagWorkspaceMembers = []string{"missing"}
+	if err := runWorkspaceInit(nil, nil); err == nil {
+		t.Error("expected error for missing member directory")
+	}
+
+	empty := t.TempDir()
+	if err := os.Chdir(empty); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	flagWorkspaceMembers = nil
+	if err := runWorkspaceInit(nil, nil); err == nil || !strings.Contains(err.Error(), "no member projects") {
+		t.Errorf("expected no member projects error, got %v", err)
+	}
+}
+
+func TestProjectPath_ResolvesWorkspaceMember(t *testing.T) {
+	root := setupWorkspaceRepo(t)
+	resetWorkspaceFlags()
+	if err := runWorkspaceInit(nil, nil); err != nil {
+		t.Fatalf("runWorkspaceInit failed: %v", err)
+	}
+
+	api := filepath.Join(root, "services", "api")
+	deep := filepath.Join(api, "internal", "handlers")
+	if err := os.MkdirAll(deep, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	tests := []struct {
+		cwd  string
+		want string
+	}{
+		{deep, api},
+		{api, api},
+		{filepath.Join(root, "docs"), root},
+		{root, root},
+	}
+	for _, tc := range tests {
+		if err := os.Chdir(tc.cwd); err != nil {
+			t.Fatalf("chdir failed: %v", err)
+		}
+		got, err := projectPath()
+		if err != nil {
+			t.Fatalf("projectPath: %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("projectPath() from %s = %s, want %s", tc.cwd, got, tc.want)
+		}
+	}
+}
+
+func TestCurrentWorkspace_NotInWorkspace(t *testing.T) {
+	resetWorkspaceFlags()
+	flagProject = t.TempDir()
+	defer func() { flagProject = "" }()
+
+	if _, err := currentWorkspace(); err == nil || !strings.Contains(err.Error(), "workspace init") {
+		t.Fatalf("expected not-in-workspace error, got %v", err)
+	}
+}
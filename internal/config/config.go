@@ -1,5 +1,6 @@
 // Package config implements hierarchical configuration for SLB.
-// Precedence: defaults < user (~/.slb/config.toml) < project (.slb/config.toml) < env (SLB_*) < flags.
+// Precedence: defaults < user (~/.slb/config.toml) < project (.slb/config.toml)
+// < project policy (.slb/policy.toml) < env (SLB_*) < flags.
 package config
 
 // Note: Additional imports will be added as needed during implementation.
@@ -14,23 +15,56 @@ type Config struct {
 	Patterns      PatternsConfig      `toml:"patterns" mapstructure:"patterns"`
 	Integrations  IntegrationsConfig  `toml:"integrations" mapstructure:"integrations"`
 	Agents        AgentsConfig        `toml:"agents" mapstructure:"agents"`
+	RiskOverrides RiskOverridesConfig `toml:"risk_overrides" mapstructure:"risk_overrides"`
 }
 
 // GeneralConfig holds core behavior knobs.
 type GeneralConfig struct {
-	MinApprovals              int      `toml:"min_approvals" mapstructure:"min_approvals"`
-	RequireDifferentModel     bool     `toml:"require_different_model" mapstructure:"require_different_model"`
-	DifferentModelTimeoutSecs int      `toml:"different_model_timeout" mapstructure:"different_model_timeout"`
-	ConflictResolution        string   `toml:"conflict_resolution" mapstructure:"conflict_resolution"` // any_rejection_blocks | first_wins | human_breaks_tie
-	RequestTimeoutSecs        int      `toml:"request_timeout" mapstructure:"request_timeout"`
-	ApprovalTTLMins           int      `toml:"approval_ttl_minutes" mapstructure:"approval_ttl_minutes"`
-	ApprovalTTLCriticalMins   int      `toml:"approval_ttl_critical_minutes" mapstructure:"approval_ttl_critical_minutes"`
-	TimeoutAction             string   `toml:"timeout_action" mapstructure:"timeout_action"` // escalate | auto_reject | auto_approve_warn
-	EnableDryRun              bool     `toml:"enable_dry_run" mapstructure:"enable_dry_run"`
-	EnableRollbackCapture     bool     `toml:"enable_rollback_capture" mapstructure:"enable_rollback_capture"`
-	MaxRollbackSizeMB         int      `toml:"max_rollback_size_mb" mapstructure:"max_rollback_size_mb"`
-	CrossProjectReviews       bool     `toml:"cross_project_reviews" mapstructure:"cross_project_reviews"`
-	ReviewPool                []string `toml:"review_pool" mapstructure:"review_pool"`
+	MinApprovals                 int      `toml:"min_approvals" mapstructure:"min_approvals"`
+	RequireDifferentModel        bool     `toml:"require_different_model" mapstructure:"require_different_model"`
+	DifferentModelTimeoutSecs    int      `toml:"different_model_timeout" mapstructure:"different_model_timeout"`
+	ConflictResolution           string   `toml:"conflict_resolution" mapstructure:"conflict_resolution"` // any_rejection_blocks | first_wins | human_breaks_tie | weighted_quorum
+	RequestTimeoutSecs           int      `toml:"request_timeout" mapstructure:"request_timeout"`
+	ApprovalTTLMins              int      `toml:"approval_ttl_minutes" mapstructure:"approval_ttl_minutes"`
+	ApprovalTTLCriticalMins      int      `toml:"approval_ttl_critical_minutes" mapstructure:"approval_ttl_critical_minutes"`
+	TimeoutAction                string   `toml:"timeout_action" mapstructure:"timeout_action"` // escalate | auto_reject | auto_approve_warn
+	EnableDryRun                 bool     `toml:"enable_dry_run" mapstructure:"enable_dry_run"`
+	EnableRollbackCapture        bool     `toml:"enable_rollback_capture" mapstructure:"enable_rollback_capture"`
+	MaxRollbackSizeMB            int      `toml:"max_rollback_size_mb" mapstructure:"max_rollback_size_mb"`
+	RollbackRootPrefix           string   `toml:"rollback_root_prefix" mapstructure:"rollback_root_prefix"`                         // filesystem capture roots are <prefix>0, <prefix>1, ...
+	MaxRollbackCaptures          int      `toml:"max_rollback_captures" mapstructure:"max_rollback_captures"`                       // 0 = no cap
+	MaxConcurrentCaptures        int      `toml:"max_concurrent_rollback_captures" mapstructure:"max_concurrent_rollback_captures"` // per process; 0 = no limit
+	RollbackCaptureWaitSecs      int      `toml:"rollback_capture_wait_seconds" mapstructure:"rollback_capture_wait_seconds"`
+	RollbackExclude              []string `toml:"rollback_exclude" mapstructure:"rollback_exclude"`                   // gitignore-style patterns left out of filesystem captures
+	MaxConcurrentExecutions      int      `toml:"max_concurrent_executions" mapstructure:"max_concurrent_executions"` // per project, DANGEROUS and CRITICAL only; 0 = no limit
+	ExecutionQueueWaitSecs       int      `toml:"execution_queue_wait_seconds" mapstructure:"execution_queue_wait_seconds"`
+	CrossProjectReviews          bool     `toml:"cross_project_reviews" mapstructure:"cross_project_reviews"`
+	ReviewPool                   []string `toml:"review_pool" mapstructure:"review_pool"`
+	UnviewedEvidenceAction       string   `toml:"unviewed_evidence_action" mapstructure:"unviewed_evidence_action"` // warn | block_critical
+	ContextPinning               []string `toml:"context_pinning" mapstructure:"context_pinning"`                   // kubectl | aws | gcloud
+	PreviewMaxCopyMB             int      `toml:"preview_max_copy_mb" mapstructure:"preview_max_copy_mb"`
+	PreviewContainerImage        string   `toml:"preview_container_image" mapstructure:"preview_container_image"`
+	SelfProtection               string   `toml:"self_protection" mapstructure:"self_protection"` // critical | refuse
+	PolicyAttestationDays        int      `toml:"policy_attestation_days" mapstructure:"policy_attestation_days"`
+	PolicyAttestationGraceDays   int      `toml:"policy_attestation_grace_days" mapstructure:"policy_attestation_grace_days"`
+	RunAllApprovedSegments       bool     `toml:"run_all_approved_segments" mapstructure:"run_all_approved_segments"`
+	MigrationGlobs               []string `toml:"migration_globs" mapstructure:"migration_globs"`                                 // extra migration file globs, relative to the command's cwd
+	MigrationMaxAttachmentKB     int      `toml:"migration_max_attachment_kb" mapstructure:"migration_max_attachment_kb"`         // 0 = summary only
+	DryRunWithholdTiers          []string `toml:"dry_run_withhold_tiers" mapstructure:"dry_run_withhold_tiers"`                   // critical | dangerous | caution
+	RequireDifferentHostTiers    []string `toml:"require_different_host_tiers" mapstructure:"require_different_host_tiers"`       // critical | dangerous | caution
+	RequireDifferentProgramTiers []string `toml:"require_different_program_tiers" mapstructure:"require_different_program_tiers"` // critical | dangerous | caution
+	MaxTotalAttachmentKB         int      `toml:"max_total_attachment_kb" mapstructure:"max_total_attachment_kb"`                 // per request, dry-run output included; 0 = unlimited
+	AttachmentContextReserveKB   int      `toml:"attachment_context_reserve_kb" mapstructure:"attachment_context_reserve_kb"`     // part of the quota only auto-collected context may use
+	AttachmentPathPrivacy        string   `toml:"attachment_path_privacy" mapstructure:"attachment_path_privacy"`                 // full | relative | basename
+	RedactPatterns               []string `toml:"redact_patterns" mapstructure:"redact_patterns"`                                 // regexes masked in commands and attachments, on top of --redact
+	StrictRedaction              bool     `toml:"strict_redaction" mapstructure:"strict_redaction"`                               // fail instead of skipping a redaction pattern that does not compile
+	AnonymizeReviewers           bool     `toml:"anonymize_reviewers" mapstructure:"anonymize_reviewers"`                         // hide reviewer identities from the requestor and unknown viewers until resolution
+	ReviewAuditors               []string `toml:"review_auditors" mapstructure:"review_auditors"`                                 // agent names that always see reviewer identities
+	TrustedScriptFloor           string   `toml:"trusted_script_floor" mapstructure:"trusted_script_floor"`                       // lowest tier a trusted script lowers to: safe | caution | dangerous
+	DryRunNoopAction             string   `toml:"dry_run_noop_action" mapstructure:"dry_run_noop_action"`                         // review | auto_approve | skip; never applies to CRITICAL
+	RejectionCooldownSecs        int      `toml:"rejection_cooldown_seconds" mapstructure:"rejection_cooldown_seconds"`           // 0 = no cool-down
+	RejectionCooldownAction      string   `toml:"rejection_cooldown_action" mapstructure:"rejection_cooldown_action"`             // refuse | justify
+	EscalateAfterMinutes         int      `toml:"escalate_after_minutes" mapstructure:"escalate_after_minutes"`                   // escalate unrejected pending requests to a human; 0 = never
 }
 
 // DaemonConfig holds daemon process settings.
@@ -57,6 +91,15 @@ type NotificationsConfig struct {
 	DesktopDelaySecs int    `toml:"desktop_delay_seconds" mapstructure:"desktop_delay_seconds"`
 	WebhookURL       string `toml:"webhook_url" mapstructure:"webhook_url"`
 	EmailEnabled     bool   `toml:"email_enabled" mapstructure:"email_enabled"`
+	// WebhookURLs receive a payload for each request lifecycle event in
+	// WebhookEvents, rendered with WebhookTemplate (generic or slack).
+	WebhookURLs     []string `toml:"webhook_urls" mapstructure:"webhook_urls"`
+	WebhookEvents   []string `toml:"webhook_events" mapstructure:"webhook_events"`
+	WebhookTemplate string   `toml:"webhook_template" mapstructure:"webhook_template"`
+	// NotifyStatuses limits notifications about resolved requests to the
+	// listed statuses (e.g. rejected, execution_failed); empty notifies
+	// every status. Pending requests are always notified.
+	NotifyStatuses []string `toml:"notify_statuses" mapstructure:"notify_statuses"`
 }
 
 // HistoryConfig holds history/audit persistence settings.
@@ -81,14 +124,39 @@ type PatternTierConfig struct {
 	DynamicQuorum           bool     `toml:"dynamic_quorum" mapstructure:"dynamic_quorum"`
 	DynamicQuorumFloor      int      `toml:"dynamic_quorum_floor" mapstructure:"dynamic_quorum_floor"`
 	AutoApproveDelaySeconds int      `toml:"auto_approve_delay_seconds" mapstructure:"auto_approve_delay_seconds"`
+	SLASeconds              int      `toml:"sla_seconds" mapstructure:"sla_seconds"`                       // 0 disables the pending SLA
+	MinTimeoutSeconds       int      `toml:"min_timeout_seconds" mapstructure:"min_timeout_seconds"`       // floor for --timeout; 0 = none
+	MaxTimeoutSeconds       int      `toml:"max_timeout_seconds" mapstructure:"max_timeout_seconds"`       // ceiling for --timeout; 0 = none
+	RequiredRoles           []string `toml:"required_roles" mapstructure:"required_roles"`                 // reviewer roles that must each approve
+	EscalateAfterMinutes    int      `toml:"escalate_after_minutes" mapstructure:"escalate_after_minutes"` // overrides general.escalate_after_minutes; 0 = use it
 	Patterns                []string `toml:"patterns" mapstructure:"patterns"`
 }
 
+// RiskOverridesConfig holds rules that replace the built-in risk tier of
+// matching commands. The matching rule with the longest pattern wins.
+type RiskOverridesConfig struct {
+	Rules []RiskOverrideRule `toml:"rules" mapstructure:"rules"`
+}
+
+// RiskOverrideRule matches the normalized primary command with a glob
+// (whole command) or a regex (anywhere); exactly one must be set.
+type RiskOverrideRule struct {
+	Glob           string `toml:"glob" mapstructure:"glob"`
+	Regex          string `toml:"regex" mapstructure:"regex"`
+	Tier           string `toml:"tier" mapstructure:"tier"`                       // critical | dangerous | caution | safe
+	AllowDowngrade bool   `toml:"allow_downgrade" mapstructure:"allow_downgrade"` // permit a safe rule to cover critical commands
+}
+
 // IntegrationsConfig holds external integration toggles.
 type IntegrationsConfig struct {
-	AgentMailEnabled   bool   `toml:"agent_mail_enabled" mapstructure:"agent_mail_enabled"`
-	AgentMailThread    string `toml:"agent_mail_thread" mapstructure:"agent_mail_thread"`
-	ClaudeHooksEnabled bool   `toml:"claude_hooks_enabled" mapstructure:"claude_hooks_enabled"`
+	AgentMailEnabled   bool     `toml:"agent_mail_enabled" mapstructure:"agent_mail_enabled"`
+	AgentMailThread    string   `toml:"agent_mail_thread" mapstructure:"agent_mail_thread"`
+	AgentMailRoutes    []string `toml:"agent_mail_routes" mapstructure:"agent_mail_routes"` // "env=prod,team=web:Thread"
+	ClaudeHooksEnabled bool     `toml:"claude_hooks_enabled" mapstructure:"claude_hooks_enabled"`
+	// ChangeRecordURL receives a change record for each executed request in
+	// ChangeRecordTiers (empty disables the integration).
+	ChangeRecordURL   string   `toml:"change_record_url" mapstructure:"change_record_url"`
+	ChangeRecordTiers []string `toml:"change_record_tiers" mapstructure:"change_record_tiers"`
 }
 
 // AgentsConfig holds agent-specific allow/deny lists.
@@ -96,4 +164,25 @@ type AgentsConfig struct {
 	TrustedSelfApprove          []string `toml:"trusted_self_approve" mapstructure:"trusted_self_approve"`
 	TrustedSelfApproveDelaySecs int      `toml:"trusted_self_approve_delay_seconds" mapstructure:"trusted_self_approve_delay_seconds"`
 	Blocked                     []string `toml:"blocked" mapstructure:"blocked"`
+	// Policy decides which agents may create requests: open (any agent),
+	// allowlist (only Allowed agents) or denylist (any agent not Blocked).
+	Policy  string   `toml:"policy" mapstructure:"policy"` // open | allowlist | denylist
+	Allowed []string `toml:"allowed" mapstructure:"allowed"`
+	// Reviewer fatigue thresholds; zero disables each check.
+	ReviewerFastApprovalSecs     int    `toml:"reviewer_fast_approval_seconds" mapstructure:"reviewer_fast_approval_seconds"`
+	ReviewerEmptyResponsePercent int    `toml:"reviewer_empty_response_percent" mapstructure:"reviewer_empty_response_percent"`
+	ReviewerApprovalStreak       int    `toml:"reviewer_approval_streak" mapstructure:"reviewer_approval_streak"`
+	ReviewerMinReviews           int    `toml:"reviewer_min_reviews" mapstructure:"reviewer_min_reviews"`
+	ReviewerWindowDays           int    `toml:"reviewer_window_days" mapstructure:"reviewer_window_days"`       // 0 = whole history
+	ReviewerPatternAction        string `toml:"reviewer_pattern_action" mapstructure:"reviewer_pattern_action"` // warn | exclude_critical
+	// ReviewerWeights gives agents a weight under weighted_quorum conflict
+	// resolution as "Agent=weight" entries; unlisted agents weigh 1.
+	ReviewerWeights []string `toml:"reviewer_weights" mapstructure:"reviewer_weights"`
+	// ReviewerRoles assigns reviewer roles to agents as "Agent=role"
+	// entries, one per role, for patterns.<tier>.required_roles.
+	ReviewerRoles []string `toml:"reviewer_roles" mapstructure:"reviewer_roles"`
+	// SessionIdleMinutes is how long a session may go unseen before the
+	// daemon ends it, its reviews are refused and it stops counting toward
+	// dynamic quorum. 0 keeps sessions until they are ended.
+	SessionIdleMinutes int `toml:"session_idle_minutes" mapstructure:"session_idle_minutes"`
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -24,17 +25,56 @@ func TestValidate_Errors(t *testing.T) {
 	cfg.General.ApprovalTTLMins = 0
 	cfg.General.ApprovalTTLCriticalMins = 0
 	cfg.General.MaxRollbackSizeMB = -1
+	cfg.General.MaxRollbackCaptures = -1
+	cfg.General.MaxConcurrentCaptures = -1
+	cfg.General.RollbackCaptureWaitSecs = -1
+	cfg.General.MaxConcurrentExecutions = -1
+	cfg.General.ExecutionQueueWaitSecs = -1
+	cfg.General.RollbackRootPrefix = "../p"
+	cfg.General.PreviewMaxCopyMB = -1
 	cfg.General.ConflictResolution = "bad"
 	cfg.General.TimeoutAction = "bad"
+	cfg.General.UnviewedEvidenceAction = "bad"
+	cfg.General.SelfProtection = "bad"
+	cfg.General.TrustedScriptFloor = "critical"
+	cfg.General.DryRunNoopAction = "approve"
+	cfg.General.RejectionCooldownSecs = -1
+	cfg.General.RejectionCooldownAction = "bad"
+	cfg.General.EscalateAfterMinutes = -1
+	cfg.General.PolicyAttestationDays = -1
+	cfg.General.PolicyAttestationGraceDays = -1
+	cfg.General.ContextPinning = []string{"kubectl", "terraform"}
+	cfg.General.DryRunWithholdTiers = []string{"safe"}
+	cfg.General.RequireDifferentHostTiers = []string{"safe"}
+	cfg.General.RequireDifferentProgramTiers = []string{"safe"}
+	cfg.General.MaxTotalAttachmentKB = 100
+	cfg.General.AttachmentContextReserveKB = 200
+	cfg.General.AttachmentPathPrivacy = "hidden"
+	cfg.General.RedactPatterns = []string{"[invalid"}
 	cfg.RateLimits.MaxPendingPerSession = -1
 	cfg.RateLimits.MaxRequestsPerMinute = -1
 	cfg.RateLimits.RateLimitAction = "bad"
 	cfg.Notifications.DesktopDelaySecs = -1
+	cfg.Notifications.WebhookURLs = []string{"ftp://hooks.example.com"}
+	cfg.Notifications.WebhookEvents = []string{"request_exploded"}
+	cfg.Notifications.WebhookTemplate = "teams"
+	cfg.Notifications.NotifyStatuses = []string{"pending"}
+	cfg.Integrations.ChangeRecordTiers = []string{"bogus"}
 	cfg.History.RetentionDays = -1
 	cfg.Patterns.Critical.MinApprovals = -1
 	cfg.Patterns.Dangerous.DynamicQuorumFloor = -1
 	cfg.Patterns.Caution.AutoApproveDelaySeconds = -1
+	cfg.Patterns.Dangerous.SLASeconds = -1
+	cfg.Patterns.Critical.MinTimeoutSeconds = 600
+	cfg.Patterns.Critical.MaxTimeoutSeconds = 60
+	cfg.Patterns.Caution.MaxTimeoutSeconds = -1
 	cfg.Agents.TrustedSelfApproveDelaySecs = -1
+	cfg.Agents.ReviewerWeights = []string{"Opus=0", "noweight"}
+	cfg.Agents.ReviewerRoles = []string{"Opus=", "norole"}
+	cfg.Agents.SessionIdleMinutes = -1
+	cfg.Patterns.Critical.RequiredRoles = []string{" "}
+	cfg.Patterns.Dangerous.EscalateAfterMinutes = -1
+	cfg.RiskOverrides.Rules = []RiskOverrideRule{{Glob: "a*", Regex: "b", Tier: "bad"}}
 
 	err := Validate(cfg)
 	if err == nil {
@@ -81,6 +121,60 @@ func TestLoad_Precedence_DefaultsUserProjectEnvFlags(t *testing.T) {
 	}
 }
 
+func TestLoad_RiskOverrides(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+	writeProjectConfig := func(body string) {
+		t.Helper()
+		path := filepath.Join(project, ".slb", "config.toml")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeProjectConfig(`
+[[risk_overrides.rules]]
+glob = "terraform apply *staging*"
+tier = "caution"
+
+[[risk_overrides.rules]]
+regex = 'prod\.tfvars'
+tier = "critical"
+
+[[risk_overrides.rules]]
+glob = "make clean"
+tier = "safe"
+allow_downgrade = true
+`)
+	cfg, err := Load(LoadOptions{ProjectDir: project})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := []RiskOverrideRule{
+		{Glob: "terraform apply *staging*", Tier: "caution"},
+		{Regex: `prod\.tfvars`, Tier: "critical"},
+		{Glob: "make clean", Tier: "safe", AllowDowngrade: true},
+	}
+	if !reflect.DeepEqual(cfg.RiskOverrides.Rules, want) {
+		t.Fatalf("rules = %+v, want %+v", cfg.RiskOverrides.Rules, want)
+	}
+
+	for name, body := range map[string]string{
+		"bad tier":      "[[risk_overrides.rules]]\nglob = \"x\"\ntier = \"urgent\"\n",
+		"both patterns": "[[risk_overrides.rules]]\nglob = \"x\"\nregex = \"x\"\ntier = \"caution\"\n",
+		"no pattern":    "[[risk_overrides.rules]]\ntier = \"caution\"\n",
+		"bad regex":     "[[risk_overrides.rules]]\nregex = \"(\"\ntier = \"caution\"\n",
+	} {
+		writeProjectConfig(body)
+		if _, err := Load(LoadOptions{ProjectDir: project}); err == nil || !strings.Contains(err.Error(), "risk_overrides.rules[0]") {
+			t.Errorf("%s: expected a risk_overrides validation error, got %v", name, err)
+		}
+	}
+}
+
 func TestLoad_InvalidEnvValueErrors(t *testing.T) {
 	t.Setenv("SLB_MIN_APPROVALS", "not-an-int")
 	if _, err := Load(LoadOptions{ProjectDir: t.TempDir()}); err == nil {
@@ -160,7 +254,10 @@ func TestConfigPathsAndProjectConfigPath(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
 
-	u, p := ConfigPaths("/proj", "")
+	u, p, err := ConfigPaths("/proj", "")
+	if err != nil {
+		t.Fatalf("ConfigPaths: %v", err)
+	}
 	if u != filepath.Join(home, ".slb", "config.toml") {
 		t.Fatalf("unexpected user path: %q", u)
 	}
@@ -168,14 +265,38 @@ func TestConfigPathsAndProjectConfigPath(t *testing.T) {
 		t.Fatalf("unexpected project path: %q", p)
 	}
 
-	if got := projectConfigPath("", ""); got != ".slb/config.toml" {
+	if got, _ := projectConfigPath("", ""); got != ".slb/config.toml" {
 		t.Fatalf("projectConfigPath(empty)=%q", got)
 	}
-	if got := projectConfigPath("/proj", "/override.toml"); got != "/override.toml" {
+	if got, _ := projectConfigPath("/proj", "/override.toml"); got != "/override.toml" {
 		t.Fatalf("projectConfigPath(override)=%q", got)
 	}
 }
 
+func TestConfigPaths_AmbiguousConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+	slbDir := filepath.Join(project, ".slb")
+	if err := os.MkdirAll(slbDir, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, name := range []string{"config.toml", "config.yaml"} {
+		if err := os.WriteFile(filepath.Join(slbDir, name), nil, 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	if _, _, err := ConfigPaths(project, ""); !errors.Is(err, ErrAmbiguousConfig) {
+		t.Fatalf("ConfigPaths err = %v, want ErrAmbiguousConfig", err)
+	}
+	if _, err := Load(LoadOptions{ProjectDir: project}); !errors.Is(err, ErrAmbiguousConfig) {
+		t.Fatalf("Load err = %v, want ErrAmbiguousConfig", err)
+	}
+	if _, _, err := ConfigPaths(project, filepath.Join(slbDir, "config.yaml")); err != nil {
+		t.Fatalf("explicit --config should bypass lookup: %v", err)
+	}
+}
+
 func TestParseValue(t *testing.T) {
 	v, err := ParseValue("general.min_approvals", "7")
 	if err != nil {
@@ -236,8 +357,38 @@ func TestGetValue(t *testing.T) {
 		{"general.enable_dry_run", cfg.General.EnableDryRun},
 		{"general.enable_rollback_capture", cfg.General.EnableRollbackCapture},
 		{"general.max_rollback_size_mb", cfg.General.MaxRollbackSizeMB},
+		{"general.rollback_root_prefix", cfg.General.RollbackRootPrefix},
+		{"general.max_rollback_captures", cfg.General.MaxRollbackCaptures},
+		{"general.max_concurrent_rollback_captures", cfg.General.MaxConcurrentCaptures},
+		{"general.rollback_capture_wait_seconds", cfg.General.RollbackCaptureWaitSecs},
+		{"general.rollback_exclude", cfg.General.RollbackExclude},
+		{"general.max_concurrent_executions", cfg.General.MaxConcurrentExecutions},
+		{"general.execution_queue_wait_seconds", cfg.General.ExecutionQueueWaitSecs},
 		{"general.cross_project_reviews", cfg.General.CrossProjectReviews},
 		{"general.review_pool", cfg.General.ReviewPool},
+		{"general.unviewed_evidence_action", cfg.General.UnviewedEvidenceAction},
+		{"general.context_pinning", cfg.General.ContextPinning},
+		{"general.preview_max_copy_mb", cfg.General.PreviewMaxCopyMB},
+		{"general.preview_container_image", cfg.General.PreviewContainerImage},
+		{"general.self_protection", cfg.General.SelfProtection},
+		{"general.policy_attestation_days", cfg.General.PolicyAttestationDays},
+		{"general.policy_attestation_grace_days", cfg.General.PolicyAttestationGraceDays},
+		{"general.run_all_approved_segments", cfg.General.RunAllApprovedSegments},
+		{"general.dry_run_withhold_tiers", cfg.General.DryRunWithholdTiers},
+		{"general.require_different_host_tiers", cfg.General.RequireDifferentHostTiers},
+		{"general.require_different_program_tiers", cfg.General.RequireDifferentProgramTiers},
+		{"general.max_total_attachment_kb", cfg.General.MaxTotalAttachmentKB},
+		{"general.attachment_context_reserve_kb", cfg.General.AttachmentContextReserveKB},
+		{"general.attachment_path_privacy", cfg.General.AttachmentPathPrivacy},
+		{"general.redact_patterns", cfg.General.RedactPatterns},
+		{"general.strict_redaction", cfg.General.StrictRedaction},
+		{"general.anonymize_reviewers", cfg.General.AnonymizeReviewers},
+		{"general.review_auditors", cfg.General.ReviewAuditors},
+		{"general.trusted_script_floor", cfg.General.TrustedScriptFloor},
+		{"general.dry_run_noop_action", cfg.General.DryRunNoopAction},
+		{"general.rejection_cooldown_seconds", cfg.General.RejectionCooldownSecs},
+		{"general.rejection_cooldown_action", cfg.General.RejectionCooldownAction},
+		{"general.escalate_after_minutes", cfg.General.EscalateAfterMinutes},
 
 		{"daemon.use_file_watcher", cfg.Daemon.UseFileWatcher},
 		{"daemon.ipc_socket", cfg.Daemon.IPCSocket},
@@ -255,6 +406,10 @@ func TestGetValue(t *testing.T) {
 		{"notifications.desktop_delay_seconds", cfg.Notifications.DesktopDelaySecs},
 		{"notifications.webhook_url", cfg.Notifications.WebhookURL},
 		{"notifications.email_enabled", cfg.Notifications.EmailEnabled},
+		{"notifications.webhook_urls", cfg.Notifications.WebhookURLs},
+		{"notifications.webhook_events", cfg.Notifications.WebhookEvents},
+		{"notifications.webhook_template", cfg.Notifications.WebhookTemplate},
+		{"notifications.notify_statuses", cfg.Notifications.NotifyStatuses},
 
 		{"history.database_path", cfg.History.DatabasePath},
 		{"history.git_repo_path", cfg.History.GitRepoPath},
@@ -266,6 +421,11 @@ func TestGetValue(t *testing.T) {
 		{"patterns.critical.dynamic_quorum", cfg.Patterns.Critical.DynamicQuorum},
 		{"patterns.critical.dynamic_quorum_floor", cfg.Patterns.Critical.DynamicQuorumFloor},
 		{"patterns.critical.auto_approve_delay_seconds", cfg.Patterns.Critical.AutoApproveDelaySeconds},
+		{"patterns.critical.sla_seconds", cfg.Patterns.Critical.SLASeconds},
+		{"patterns.critical.min_timeout_seconds", cfg.Patterns.Critical.MinTimeoutSeconds},
+		{"patterns.critical.max_timeout_seconds", cfg.Patterns.Critical.MaxTimeoutSeconds},
+		{"patterns.critical.required_roles", cfg.Patterns.Critical.RequiredRoles},
+		{"patterns.critical.escalate_after_minutes", cfg.Patterns.Critical.EscalateAfterMinutes},
 		{"patterns.critical.patterns", cfg.Patterns.Critical.Patterns},
 
 		{"patterns.dangerous", cfg.Patterns.Dangerous},
@@ -273,6 +433,11 @@ func TestGetValue(t *testing.T) {
 		{"patterns.dangerous.dynamic_quorum", cfg.Patterns.Dangerous.DynamicQuorum},
 		{"patterns.dangerous.dynamic_quorum_floor", cfg.Patterns.Dangerous.DynamicQuorumFloor},
 		{"patterns.dangerous.auto_approve_delay_seconds", cfg.Patterns.Dangerous.AutoApproveDelaySeconds},
+		{"patterns.dangerous.sla_seconds", cfg.Patterns.Dangerous.SLASeconds},
+		{"patterns.dangerous.min_timeout_seconds", cfg.Patterns.Dangerous.MinTimeoutSeconds},
+		{"patterns.dangerous.max_timeout_seconds", cfg.Patterns.Dangerous.MaxTimeoutSeconds},
+		{"patterns.dangerous.required_roles", cfg.Patterns.Dangerous.RequiredRoles},
+		{"patterns.dangerous.escalate_after_minutes", cfg.Patterns.Dangerous.EscalateAfterMinutes},
 		{"patterns.dangerous.patterns", cfg.Patterns.Dangerous.Patterns},
 
 		{"patterns.caution", cfg.Patterns.Caution},
@@ -280,6 +445,11 @@ func TestGetValue(t *testing.T) {
 		{"patterns.caution.dynamic_quorum", cfg.Patterns.Caution.DynamicQuorum},
 		{"patterns.caution.dynamic_quorum_floor", cfg.Patterns.Caution.DynamicQuorumFloor},
 		{"patterns.caution.auto_approve_delay_seconds", cfg.Patterns.Caution.AutoApproveDelaySeconds},
+		{"patterns.caution.sla_seconds", cfg.Patterns.Caution.SLASeconds},
+		{"patterns.caution.min_timeout_seconds", cfg.Patterns.Caution.MinTimeoutSeconds},
+		{"patterns.caution.max_timeout_seconds", cfg.Patterns.Caution.MaxTimeoutSeconds},
+		{"patterns.caution.required_roles", cfg.Patterns.Caution.RequiredRoles},
+		{"patterns.caution.escalate_after_minutes", cfg.Patterns.Caution.EscalateAfterMinutes},
 		{"patterns.caution.patterns", cfg.Patterns.Caution.Patterns},
 
 		{"patterns.safe", cfg.Patterns.Safe},
@@ -287,15 +457,34 @@ func TestGetValue(t *testing.T) {
 		{"patterns.safe.dynamic_quorum", cfg.Patterns.Safe.DynamicQuorum},
 		{"patterns.safe.dynamic_quorum_floor", cfg.Patterns.Safe.DynamicQuorumFloor},
 		{"patterns.safe.auto_approve_delay_seconds", cfg.Patterns.Safe.AutoApproveDelaySeconds},
+		{"patterns.safe.sla_seconds", cfg.Patterns.Safe.SLASeconds},
+		{"patterns.safe.min_timeout_seconds", cfg.Patterns.Safe.MinTimeoutSeconds},
+		{"patterns.safe.max_timeout_seconds", cfg.Patterns.Safe.MaxTimeoutSeconds},
+		{"patterns.safe.required_roles", cfg.Patterns.Safe.RequiredRoles},
+		{"patterns.safe.escalate_after_minutes", cfg.Patterns.Safe.EscalateAfterMinutes},
 		{"patterns.safe.patterns", cfg.Patterns.Safe.Patterns},
 
 		{"integrations.agent_mail_enabled", cfg.Integrations.AgentMailEnabled},
 		{"integrations.agent_mail_thread", cfg.Integrations.AgentMailThread},
+		{"integrations.agent_mail_routes", cfg.Integrations.AgentMailRoutes},
 		{"integrations.claude_hooks_enabled", cfg.Integrations.ClaudeHooksEnabled},
+		{"integrations.change_record_url", cfg.Integrations.ChangeRecordURL},
+		{"integrations.change_record_tiers", cfg.Integrations.ChangeRecordTiers},
 
 		{"agents.trusted_self_approve", cfg.Agents.TrustedSelfApprove},
 		{"agents.trusted_self_approve_delay_seconds", cfg.Agents.TrustedSelfApproveDelaySecs},
 		{"agents.blocked", cfg.Agents.Blocked},
+		{"agents.policy", cfg.Agents.Policy},
+		{"agents.allowed", cfg.Agents.Allowed},
+		{"agents.reviewer_fast_approval_seconds", cfg.Agents.ReviewerFastApprovalSecs},
+		{"agents.reviewer_empty_response_percent", cfg.Agents.ReviewerEmptyResponsePercent},
+		{"agents.reviewer_approval_streak", cfg.Agents.ReviewerApprovalStreak},
+		{"agents.reviewer_min_reviews", cfg.Agents.ReviewerMinReviews},
+		{"agents.reviewer_window_days", cfg.Agents.ReviewerWindowDays},
+		{"agents.reviewer_pattern_action", cfg.Agents.ReviewerPatternAction},
+		{"agents.reviewer_weights", cfg.Agents.ReviewerWeights},
+		{"agents.reviewer_roles", cfg.Agents.ReviewerRoles},
+		{"agents.session_idle_minutes", cfg.Agents.SessionIdleMinutes},
 
 		{"general", cfg.General},
 		{"daemon", cfg.Daemon},
@@ -378,3 +567,226 @@ func TestWriteValue_DecodeExistingInvalidTOMLErrors(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestLoad_FormatsProduceIdenticalConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	files := map[string]string{
+		"config.toml": `
+[general]
+min_approvals = 3
+conflict_resolution = "first_wins"
+review_pool = ["alice", "bob"]
+enable_dry_run = false
+
+[patterns.critical]
+min_approvals = 4
+patterns = ["^rm -rf /$"]
+`,
+		"config.yaml": `
+general:
+  min_approvals: 3
+  conflict_resolution: first_wins
+  review_pool: [alice, bob]
+  enable_dry_run: false
+patterns:
+  critical:
+    min_approvals: 4
+    patterns: ["^rm -rf /$"]
+`,
+		"config.json": `{
+  "general": {
+    "min_approvals": 3,
+    "conflict_resolution": "first_wins",
+    "review_pool": ["alice", "bob"],
+    "enable_dry_run": false
+  },
+  "patterns": {"critical": {"min_approvals": 4, "patterns": ["^rm -rf /$"]}}
+}`,
+	}
+
+	loaded := map[string]Config{}
+	for name, content := range files {
+		project := t.TempDir()
+		path := filepath.Join(project, ".slb", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if _, p, err := ConfigPaths(project, ""); err != nil || p != path {
+			t.Fatalf("ConfigPaths found %q (%v), want %q", p, err, path)
+		}
+		cfg, err := Load(LoadOptions{ProjectDir: project})
+		if err != nil {
+			t.Fatalf("Load(%s): %v", name, err)
+		}
+		loaded[name] = cfg
+	}
+
+	want := loaded["config.toml"]
+	if want.General.MinApprovals != 3 || want.Patterns.Critical.MinApprovals != 4 {
+		t.Fatalf("toml config not applied: %+v", want.General)
+	}
+	for name, cfg := range loaded {
+		if !reflect.DeepEqual(cfg, want) {
+			t.Errorf("%s produced a different config than config.toml", name)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		data    string
+		want    Format
+		wantErr bool
+	}{
+		{"config.yml", "", FormatYAML, false},
+		{"config.JSON", "", FormatJSON, false},
+		{"slb.conf", "[general]\nmin_approvals = 2\n", FormatTOML, false},
+		{"slb.conf", "general:\n  min_approvals: 2\n", FormatYAML, false},
+		{"slb.conf", `{"general": {"min_approvals": 2}}`, FormatJSON, false},
+		{"slb.conf", "this is not a config", "", true},
+	}
+	for _, tt := range tests {
+		got, err := DetectFormat(tt.path, []byte(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("DetectFormat(%q, %q) err = %v, wantErr %v", tt.path, tt.data, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("DetectFormat(%q) err = %v, want ErrUnknownFormat", tt.data, err)
+		}
+		if got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %q, want %q", tt.path, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestMergeConfigFile_InvalidFormats(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"config.yaml": "general: [unterminated\n",
+		"config.json": `{"general": `,
+		"config.conf": "this is not a config",
+	}
+	for name, content := range cases {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if err := mergeConfigFile(newTestViper(), path); err == nil {
+			t.Errorf("expected error for invalid %s", name)
+		}
+	}
+}
+
+func TestWriteValue_KeepsFormat(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"config.yaml", "config.json"} {
+		path := filepath.Join(dir, name)
+		if err := WriteValue(path, "general.min_approvals", 3); err != nil {
+			t.Fatalf("WriteValue(%s): %v", name, err)
+		}
+		if err := WriteValue(path, "general.timeout_action", "auto_reject"); err != nil {
+			t.Fatalf("WriteValue(%s): %v", name, err)
+		}
+		v := newTestViper()
+		if err := mergeConfigFile(v, path); err != nil {
+			t.Fatalf("mergeConfigFile(%s): %v", name, err)
+		}
+		if v.GetInt("general.min_approvals") != 3 || v.GetString("general.timeout_action") != "auto_reject" {
+			t.Errorf("%s did not round-trip: %v", name, v.AllSettings()["general"])
+		}
+	}
+}
+
+func TestPolicyHash_CoversEveryLayer(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	root := t.TempDir()
+	member := filepath.Join(root, "api")
+	if err := WriteWorkspace(&Workspace{Root: root, Members: []string{member}}); err != nil {
+		t.Fatalf("WriteWorkspace: %v", err)
+	}
+
+	hash := func() string {
+		t.Helper()
+		cfg, err := Load(LoadOptions{ProjectDir: member})
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return PolicyHash(cfg)
+	}
+
+	seen := map[string]string{"defaults": hash()}
+	check := func(layer string) {
+		t.Helper()
+		got := hash()
+		for prev, h := range seen {
+			if h == got {
+				t.Errorf("%s change left the policy hash equal to %s", layer, prev)
+			}
+		}
+		seen[layer] = got
+	}
+
+	if err := WriteValue(filepath.Join(home, ".slb", "config.toml"), "general.timeout_action", "auto_approve_warn"); err != nil {
+		t.Fatal(err)
+	}
+	check("user")
+	if err := WriteValue(filepath.Join(root, ".slb", "config.toml"), "agents.trusted_self_approve", []string{"Bot"}); err != nil {
+		t.Fatal(err)
+	}
+	check("workspace")
+	if err := WriteValue(filepath.Join(member, ".slb", "config.json"), "patterns.caution.auto_approve_delay_seconds", 5); err != nil {
+		t.Fatal(err)
+	}
+	check("project")
+	t.Setenv("SLB_MIN_APPROVALS", "1")
+	check("env")
+
+	if err := WriteValue(filepath.Join(member, ".slb", "config.json"), "notifications.webhook_url", "https://hooks.example.com/slb"); err != nil {
+		t.Fatal(err)
+	}
+	if got := hash(); got != seen["env"] {
+		t.Error("settings unrelated to auto-approval should not change the policy hash")
+	}
+}
+
+func TestValidate_AgentPolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		allowed []string
+		wantErr string
+	}{
+		{"denylist", nil, ""},
+		{"open", nil, ""},
+		{"allowlist", []string{"BlueLake"}, ""},
+		{"allowlist", nil, "agents.policy allowlist requires at least one agents.allowed entry"},
+		{"closed", nil, "agents.policy must be one of open|allowlist|denylist"},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.Agents.Policy = tt.policy
+		cfg.Agents.Allowed = tt.allowed
+		err := Validate(cfg)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("policy %q: unexpected error %v", tt.policy, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("policy %q: error = %v, want it to contain %q", tt.policy, err, tt.wantErr)
+		}
+	}
+
+	def := DefaultConfig()
+	open := DefaultConfig()
+	open.Agents.Policy = "open"
+	if PolicyHash(def) == PolicyHash(open) {
+		t.Error("an open agent policy should change the policy hash")
+	}
+}
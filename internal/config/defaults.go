@@ -63,19 +63,51 @@ var (
 func DefaultConfig() Config {
 	return Config{
 		General: GeneralConfig{
-			MinApprovals:              2,
-			RequireDifferentModel:     false,
-			DifferentModelTimeoutSecs: 300,
-			ConflictResolution:        "any_rejection_blocks",
-			RequestTimeoutSecs:        1800,
-			ApprovalTTLMins:           30,
-			ApprovalTTLCriticalMins:   10,
-			TimeoutAction:             "escalate",
-			EnableDryRun:              true,
-			EnableRollbackCapture:     true,
-			MaxRollbackSizeMB:         100,
-			CrossProjectReviews:       false,
-			ReviewPool:                []string{},
+			MinApprovals:                 2,
+			RequireDifferentModel:        false,
+			DifferentModelTimeoutSecs:    300,
+			ConflictResolution:           "any_rejection_blocks",
+			RequestTimeoutSecs:           1800,
+			ApprovalTTLMins:              30,
+			ApprovalTTLCriticalMins:      10,
+			TimeoutAction:                "escalate",
+			EnableDryRun:                 true,
+			EnableRollbackCapture:        true,
+			MaxRollbackSizeMB:            100,
+			RollbackRootPrefix:           "p",
+			MaxRollbackCaptures:          0,
+			MaxConcurrentCaptures:        2,
+			RollbackCaptureWaitSecs:      30,
+			RollbackExclude:              []string{},
+			MaxConcurrentExecutions:      1,
+			ExecutionQueueWaitSecs:       600,
+			CrossProjectReviews:          false,
+			ReviewPool:                   []string{},
+			UnviewedEvidenceAction:       "warn",
+			ContextPinning:               []string{"kubectl", "aws", "gcloud"},
+			PreviewMaxCopyMB:             50,
+			PreviewContainerImage:        "",
+			SelfProtection:               "critical",
+			PolicyAttestationDays:        0,
+			PolicyAttestationGraceDays:   0,
+			RunAllApprovedSegments:       false,
+			MigrationGlobs:               []string{},
+			MigrationMaxAttachmentKB:     256,
+			DryRunWithholdTiers:          []string{},
+			RequireDifferentHostTiers:    []string{},
+			RequireDifferentProgramTiers: []string{},
+			MaxTotalAttachmentKB:         5120,
+			AttachmentContextReserveKB:   1024,
+			AttachmentPathPrivacy:        "full",
+			RedactPatterns:               []string{},
+			StrictRedaction:              false,
+			AnonymizeReviewers:           false,
+			ReviewAuditors:               []string{},
+			TrustedScriptFloor:           "caution",
+			DryRunNoopAction:             "review",
+			RejectionCooldownSecs:        0,
+			RejectionCooldownAction:      "refuse",
+			EscalateAfterMinutes:         0,
 		},
 		Daemon: DaemonConfig{
 			UseFileWatcher: true,
@@ -96,6 +128,9 @@ func DefaultConfig() Config {
 			DesktopDelaySecs: 60,
 			WebhookURL:       "",
 			EmailEnabled:     false,
+			WebhookURLs:      nil,
+			WebhookEvents:    []string{"request_pending", "request_approved", "request_rejected", "request_executed"},
+			WebhookTemplate:  "generic",
 		},
 		History: HistoryConfig{
 			DatabasePath:  "",
@@ -109,6 +144,11 @@ func DefaultConfig() Config {
 				DynamicQuorum:           false,
 				DynamicQuorumFloor:      2,
 				AutoApproveDelaySeconds: 0,
+				SLASeconds:              300,
+				MinTimeoutSeconds:       10,
+				MaxTimeoutSeconds:       3600,
+				RequiredRoles:           []string{},
+				EscalateAfterMinutes:    0,
 				Patterns:                defaultCriticalPatterns,
 			},
 			Dangerous: PatternTierConfig{
@@ -116,6 +156,11 @@ func DefaultConfig() Config {
 				DynamicQuorum:           false,
 				DynamicQuorumFloor:      1,
 				AutoApproveDelaySeconds: 0,
+				SLASeconds:              900,
+				MinTimeoutSeconds:       10,
+				MaxTimeoutSeconds:       3600,
+				RequiredRoles:           []string{},
+				EscalateAfterMinutes:    0,
 				Patterns:                defaultDangerousPatterns,
 			},
 			Caution: PatternTierConfig{
@@ -123,6 +168,11 @@ func DefaultConfig() Config {
 				DynamicQuorum:           false,
 				DynamicQuorumFloor:      0,
 				AutoApproveDelaySeconds: 30,
+				SLASeconds:              0,
+				MinTimeoutSeconds:       10,
+				MaxTimeoutSeconds:       3600,
+				RequiredRoles:           []string{},
+				EscalateAfterMinutes:    0,
 				Patterns:                defaultCautionPatterns,
 			},
 			Safe: PatternTierConfig{
@@ -130,18 +180,34 @@ func DefaultConfig() Config {
 				DynamicQuorum:           false,
 				DynamicQuorumFloor:      0,
 				AutoApproveDelaySeconds: 0,
+				SLASeconds:              0,
+				MinTimeoutSeconds:       0,
+				MaxTimeoutSeconds:       0,
+				RequiredRoles:           []string{},
+				EscalateAfterMinutes:    0,
 				Patterns:                defaultSafePatterns,
 			},
 		},
 		Integrations: IntegrationsConfig{
 			AgentMailEnabled:   true,
 			AgentMailThread:    "SLB-Reviews",
+			AgentMailRoutes:    []string{},
 			ClaudeHooksEnabled: true,
+			ChangeRecordURL:    "",
+			ChangeRecordTiers:  []string{"critical", "dangerous"},
 		},
 		Agents: AgentsConfig{
 			TrustedSelfApprove:          []string{},
 			TrustedSelfApproveDelaySecs: 300,
 			Blocked:                     []string{},
+			Policy:                      "denylist",
+			Allowed:                     []string{},
+			ReviewerMinReviews:          10,
+			ReviewerWindowDays:          30,
+			ReviewerPatternAction:       "warn",
+			ReviewerWeights:             []string{},
+			ReviewerRoles:               []string{},
+			SessionIdleMinutes:          1440,
 		},
 	}
 }
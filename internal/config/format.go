@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// Format is a config file encoding.
+type Format string
+
+// Supported config file formats.
+const (
+	FormatTOML Format = "toml"
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// ErrUnknownFormat is returned when a config file's format cannot be determined.
+var ErrUnknownFormat = errors.New("unrecognized config format")
+
+// ErrAmbiguousConfig is returned when a .slb directory holds more than one
+// config file, since only one of them would take effect.
+var ErrAmbiguousConfig = errors.New("multiple config files")
+
+// configFileNames are the config file names looked up in a .slb directory.
+var configFileNames = []string{"config.toml", "config.yaml", "config.yml", "config.json"}
+
+// findConfigFile returns the config file in dir, defaulting to config.toml
+// when none exists. It fails with ErrAmbiguousConfig when several exist.
+func findConfigFile(dir string) (string, error) {
+	var found []string
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			found = append(found, name)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return filepath.Join(dir, configFileNames[0]), nil
+	case 1:
+		return filepath.Join(dir, found[0]), nil
+	}
+	return "", fmt.Errorf("%w in %s (%s): keep only one", ErrAmbiguousConfig, dir, strings.Join(found, ", "))
+}
+
+// formatFromExtension maps a file extension to a format.
+func formatFromExtension(path string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return FormatTOML, true
+	case ".yaml", ".yml":
+		return FormatYAML, true
+	case ".json":
+		return FormatJSON, true
+	}
+	return "", false
+}
+
+// DetectFormat determines a config file's format from its extension, falling
+// back to sniffing the content when the extension is not recognized.
+func DetectFormat(path string, data []byte) (Format, error) {
+	if f, ok := formatFromExtension(path); ok {
+		return f, nil
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return FormatTOML, nil
+	}
+	var probe map[string]any
+	if trimmed[0] == '{' && json.Unmarshal(trimmed, &probe) == nil {
+		return FormatJSON, nil
+	}
+	if _, err := toml.Decode(string(data), &probe); err == nil {
+		return FormatTOML, nil
+	}
+	probe = nil
+	if err := yaml.Unmarshal(data, &probe); err == nil && probe != nil {
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+}
+
+// decodeConfigMap decodes config data in the given format into a generic map.
+func decodeConfigMap(format Format, data []byte) (map[string]any, error) {
+	m := map[string]any{}
+	var err error
+	switch format {
+	case FormatTOML:
+		_, err = toml.Decode(string(data), &m)
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &m)
+	case FormatJSON:
+		if len(bytes.TrimSpace(data)) > 0 {
+			err = json.Unmarshal(data, &m)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = map[string]any{}
+	}
+	return m, nil
+}
+
+// encodeConfigMap encodes a generic config map in the given format.
+func encodeConfigMap(format Format, m map[string]any) ([]byte, error) {
+	switch format {
+	case FormatTOML:
+		var buf bytes.Buffer
+		enc := toml.NewEncoder(&buf)
+		enc.Indent = "  "
+		if err := enc.Encode(m); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatYAML:
+		return yaml.Marshal(m)
+	case FormatJSON:
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
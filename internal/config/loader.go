@@ -5,28 +5,114 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/BurntSushi/toml"
 	"github.com/spf13/viper"
 )
 
 // LoadOptions controls configuration loading.
 type LoadOptions struct {
-	// ProjectDir is used to locate .slb/config.toml. Defaults to CWD when empty.
+	// ProjectDir is used to locate .slb/config.{toml,yaml,yml,json}. Defaults to CWD when empty.
 	ProjectDir string
 	// ConfigPath overrides the project config path if provided.
 	ConfigPath string
+	// PolicyDir is where the search for a project policy (.slb/policy.toml)
+	// starts, walking up; typically the request's working directory.
+	// Defaults to ProjectDir.
+	PolicyDir string
 	// FlagOverrides are highest-priority overrides from CLI flags (dot-notated keys).
 	FlagOverrides map[string]any
 }
 
 // Load returns the effective configuration after applying precedence:
-// defaults < user (~/.slb/config.toml) < project (.slb/config.toml) < env (SLB_*) < flags.
+// defaults < user (~/.slb/config.toml) < workspace root (.slb/config.toml)
+// < project (.slb/config.toml) < project policy (.slb/policy.toml)
+// < env (SLB_*) < flags.
+// The workspace layer only applies when ProjectDir is a workspace member.
+// The project policy is the nearest .slb/policy.toml above PolicyDir; a
+// malformed one is an error naming the file.
 func Load(opts LoadOptions) (Config, error) {
+	cfg, _, err := load(opts)
+	return cfg, err
+}
+
+// Setting is one effective configuration value and the layer that set it.
+type Setting struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+	// Source is default, user, workspace, project, policy, env or flag.
+	Source string `json:"source"`
+	// Origin is the file or environment variable the value came from.
+	Origin string `json:"origin,omitempty"`
+}
+
+// Effective loads the configuration like Load and reports, for every
+// setting, the value in effect and the layer it came from.
+func Effective(opts LoadOptions) (Config, []Setting, error) {
+	cfg, sources, err := load(opts)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	keys := make([]string, 0, len(keyKinds)+1)
+	for key := range keyKinds {
+		keys = append(keys, key)
+	}
+	keys = append(keys, "risk_overrides.rules")
+	sort.Strings(keys)
+
+	settings := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		var value any
+		if key == "risk_overrides.rules" {
+			value = cfg.RiskOverrides.Rules
+		} else if v, ok := GetValue(cfg, key); ok {
+			value = v
+		} else {
+			continue
+		}
+		src, ok := sources[key]
+		if !ok {
+			src = settingSource{Source: "default"}
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: src.Source, Origin: src.Origin})
+	}
+	return cfg, settings, nil
+}
+
+// settingSource records which layer last set a key.
+type settingSource struct {
+	Source string
+	Origin string
+}
+
+// load merges every layer and records where each key was last set. When
+// the merged result does not hold up, the project policy is blamed if the
+// configuration is fine without it.
+func load(opts LoadOptions) (Config, map[string]settingSource, error) {
+	cfg, sources, policyPath, err := loadLayers(opts, true)
+	if err != nil && policyPath != "" {
+		if _, _, _, withoutErr := loadLayers(opts, false); withoutErr == nil {
+			return Config{}, nil, fmt.Errorf("project policy %s: %w", policyPath, err)
+		}
+	}
+	return cfg, sources, err
+}
+
+// loadLayers merges the layers, skipping the project policy unless
+// withPolicy is set, and returns the policy file it found.
+func loadLayers(opts LoadOptions, withPolicy bool) (Config, map[string]settingSource, string, error) {
 	v := viper.New()
 	setDefaults(v)
+	sources := make(map[string]settingSource)
+	merge := func(source, path string) error {
+		keys, err := mergeConfigLayer(v, path)
+		for _, key := range keys {
+			sources[key] = settingSource{Source: source, Origin: path}
+		}
+		return err
+	}
 
 	projectDir := opts.ProjectDir
 	if projectDir == "" {
@@ -36,28 +122,73 @@ func Load(opts LoadOptions) (Config, error) {
 	}
 
 	// 1) User config
-	if err := mergeConfigFile(v, userConfigPath()); err != nil {
-		return Config{}, err
+	userPath, err := userConfigPath()
+	if err != nil {
+		return Config{}, nil, "", err
+	}
+	if err := merge("user", userPath); err != nil {
+		return Config{}, nil, "", err
+	}
+	// 2) Workspace config (inherited by members, overridable per member)
+	if ws, err := FindWorkspace(projectDir); err != nil {
+		return Config{}, nil, "", err
+	} else if ws != nil && ws.MemberFor(projectDir) != "" {
+		rootPath, err := projectConfigPath(ws.Root, "")
+		if err != nil {
+			return Config{}, nil, "", err
+		}
+		if err := merge("workspace", rootPath); err != nil {
+			return Config{}, nil, "", err
+		}
+	}
+	// 3) Project config
+	projectPath, err := projectConfigPath(projectDir, opts.ConfigPath)
+	if err != nil {
+		return Config{}, nil, "", err
+	}
+	if err := merge("project", projectPath); err != nil {
+		return Config{}, nil, "", err
 	}
-	// 2) Project config
-	if err := mergeConfigFile(v, projectConfigPath(projectDir, opts.ConfigPath)); err != nil {
-		return Config{}, err
+	// 4) Project policy
+	policyDir := opts.PolicyDir
+	if policyDir == "" {
+		policyDir = projectDir
 	}
-	// 3) Environment variables
+	policyPath, err := FindPolicyFile(policyDir)
+	if err != nil {
+		return Config{}, nil, "", err
+	}
+	if withPolicy && policyPath != "" {
+		if err := checkPolicyFile(policyPath); err != nil {
+			return Config{}, nil, policyPath, err
+		}
+		if err := merge("policy", policyPath); err != nil {
+			return Config{}, nil, policyPath, fmt.Errorf("project policy: %w", err)
+		}
+	}
+	// 5) Environment variables
 	if err := applyEnvOverrides(v); err != nil {
-		return Config{}, err
+		return Config{}, nil, "", err
 	}
-	// 4) CLI flags (highest)
+	for _, binding := range envBindings {
+		if os.Getenv(binding.Env) != "" {
+			sources[binding.Key] = settingSource{Source: "env", Origin: binding.Env}
+		}
+	}
+	// 6) CLI flags (highest)
 	applyFlagOverrides(v, opts.FlagOverrides)
+	for key := range opts.FlagOverrides {
+		sources[key] = settingSource{Source: "flag"}
+	}
 
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
-		return Config{}, fmt.Errorf("unmarshal config: %w", err)
+		return Config{}, nil, policyPath, fmt.Errorf("unmarshal config: %w", err)
 	}
 	if err := Validate(cfg); err != nil {
-		return Config{}, err
+		return Config{}, nil, policyPath, err
 	}
-	return cfg, nil
+	return cfg, sources, policyPath, nil
 }
 
 // setDefaults seeds viper with built-in defaults.
@@ -75,8 +206,40 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("general.enable_dry_run", def.General.EnableDryRun)
 	v.SetDefault("general.enable_rollback_capture", def.General.EnableRollbackCapture)
 	v.SetDefault("general.max_rollback_size_mb", def.General.MaxRollbackSizeMB)
+	v.SetDefault("general.rollback_root_prefix", def.General.RollbackRootPrefix)
+	v.SetDefault("general.max_rollback_captures", def.General.MaxRollbackCaptures)
+	v.SetDefault("general.max_concurrent_rollback_captures", def.General.MaxConcurrentCaptures)
+	v.SetDefault("general.rollback_capture_wait_seconds", def.General.RollbackCaptureWaitSecs)
+	v.SetDefault("general.rollback_exclude", def.General.RollbackExclude)
+	v.SetDefault("general.max_concurrent_executions", def.General.MaxConcurrentExecutions)
+	v.SetDefault("general.execution_queue_wait_seconds", def.General.ExecutionQueueWaitSecs)
 	v.SetDefault("general.cross_project_reviews", def.General.CrossProjectReviews)
 	v.SetDefault("general.review_pool", def.General.ReviewPool)
+	v.SetDefault("general.unviewed_evidence_action", def.General.UnviewedEvidenceAction)
+	v.SetDefault("general.context_pinning", def.General.ContextPinning)
+	v.SetDefault("general.preview_max_copy_mb", def.General.PreviewMaxCopyMB)
+	v.SetDefault("general.preview_container_image", def.General.PreviewContainerImage)
+	v.SetDefault("general.self_protection", def.General.SelfProtection)
+	v.SetDefault("general.policy_attestation_days", def.General.PolicyAttestationDays)
+	v.SetDefault("general.policy_attestation_grace_days", def.General.PolicyAttestationGraceDays)
+	v.SetDefault("general.run_all_approved_segments", def.General.RunAllApprovedSegments)
+	v.SetDefault("general.migration_globs", def.General.MigrationGlobs)
+	v.SetDefault("general.migration_max_attachment_kb", def.General.MigrationMaxAttachmentKB)
+	v.SetDefault("general.dry_run_withhold_tiers", def.General.DryRunWithholdTiers)
+	v.SetDefault("general.require_different_host_tiers", def.General.RequireDifferentHostTiers)
+	v.SetDefault("general.require_different_program_tiers", def.General.RequireDifferentProgramTiers)
+	v.SetDefault("general.max_total_attachment_kb", def.General.MaxTotalAttachmentKB)
+	v.SetDefault("general.attachment_context_reserve_kb", def.General.AttachmentContextReserveKB)
+	v.SetDefault("general.attachment_path_privacy", def.General.AttachmentPathPrivacy)
+	v.SetDefault("general.redact_patterns", def.General.RedactPatterns)
+	v.SetDefault("general.strict_redaction", def.General.StrictRedaction)
+	v.SetDefault("general.anonymize_reviewers", def.General.AnonymizeReviewers)
+	v.SetDefault("general.review_auditors", def.General.ReviewAuditors)
+	v.SetDefault("general.trusted_script_floor", def.General.TrustedScriptFloor)
+	v.SetDefault("general.dry_run_noop_action", def.General.DryRunNoopAction)
+	v.SetDefault("general.rejection_cooldown_seconds", def.General.RejectionCooldownSecs)
+	v.SetDefault("general.rejection_cooldown_action", def.General.RejectionCooldownAction)
+	v.SetDefault("general.escalate_after_minutes", def.General.EscalateAfterMinutes)
 
 	v.SetDefault("daemon.use_file_watcher", def.Daemon.UseFileWatcher)
 	v.SetDefault("daemon.ipc_socket", def.Daemon.IPCSocket)
@@ -94,6 +257,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("notifications.desktop_delay_seconds", def.Notifications.DesktopDelaySecs)
 	v.SetDefault("notifications.webhook_url", def.Notifications.WebhookURL)
 	v.SetDefault("notifications.email_enabled", def.Notifications.EmailEnabled)
+	v.SetDefault("notifications.webhook_urls", def.Notifications.WebhookURLs)
+	v.SetDefault("notifications.webhook_events", def.Notifications.WebhookEvents)
+	v.SetDefault("notifications.webhook_template", def.Notifications.WebhookTemplate)
+	v.SetDefault("notifications.notify_statuses", def.Notifications.NotifyStatuses)
 
 	v.SetDefault("history.database_path", def.History.DatabasePath)
 	v.SetDefault("history.git_repo_path", def.History.GitRepoPath)
@@ -108,11 +275,25 @@ func setDefaults(v *viper.Viper) {
 
 	v.SetDefault("integrations.agent_mail_enabled", def.Integrations.AgentMailEnabled)
 	v.SetDefault("integrations.agent_mail_thread", def.Integrations.AgentMailThread)
+	v.SetDefault("integrations.agent_mail_routes", def.Integrations.AgentMailRoutes)
 	v.SetDefault("integrations.claude_hooks_enabled", def.Integrations.ClaudeHooksEnabled)
+	v.SetDefault("integrations.change_record_url", def.Integrations.ChangeRecordURL)
+	v.SetDefault("integrations.change_record_tiers", def.Integrations.ChangeRecordTiers)
 
 	v.SetDefault("agents.trusted_self_approve", def.Agents.TrustedSelfApprove)
 	v.SetDefault("agents.trusted_self_approve_delay_seconds", def.Agents.TrustedSelfApproveDelaySecs)
 	v.SetDefault("agents.blocked", def.Agents.Blocked)
+	v.SetDefault("agents.policy", def.Agents.Policy)
+	v.SetDefault("agents.allowed", def.Agents.Allowed)
+	v.SetDefault("agents.reviewer_fast_approval_seconds", def.Agents.ReviewerFastApprovalSecs)
+	v.SetDefault("agents.reviewer_empty_response_percent", def.Agents.ReviewerEmptyResponsePercent)
+	v.SetDefault("agents.reviewer_approval_streak", def.Agents.ReviewerApprovalStreak)
+	v.SetDefault("agents.reviewer_min_reviews", def.Agents.ReviewerMinReviews)
+	v.SetDefault("agents.reviewer_window_days", def.Agents.ReviewerWindowDays)
+	v.SetDefault("agents.reviewer_pattern_action", def.Agents.ReviewerPatternAction)
+	v.SetDefault("agents.reviewer_weights", def.Agents.ReviewerWeights)
+	v.SetDefault("agents.reviewer_roles", def.Agents.ReviewerRoles)
+	v.SetDefault("agents.session_idle_minutes", def.Agents.SessionIdleMinutes)
 }
 
 func setTierDefaults(v *viper.Viper, prefix string, tier PatternTierConfig) {
@@ -120,29 +301,82 @@ func setTierDefaults(v *viper.Viper, prefix string, tier PatternTierConfig) {
 	v.SetDefault(prefix+".dynamic_quorum", tier.DynamicQuorum)
 	v.SetDefault(prefix+".dynamic_quorum_floor", tier.DynamicQuorumFloor)
 	v.SetDefault(prefix+".auto_approve_delay_seconds", tier.AutoApproveDelaySeconds)
+	v.SetDefault(prefix+".sla_seconds", tier.SLASeconds)
+	v.SetDefault(prefix+".min_timeout_seconds", tier.MinTimeoutSeconds)
+	v.SetDefault(prefix+".max_timeout_seconds", tier.MaxTimeoutSeconds)
+	v.SetDefault(prefix+".required_roles", tier.RequiredRoles)
+	v.SetDefault(prefix+".escalate_after_minutes", tier.EscalateAfterMinutes)
 	v.SetDefault(prefix+".patterns", tier.Patterns)
 }
 
-// mergeConfigFile merges the TOML config file if it exists.
+// mergeConfigFile merges the config file if it exists. TOML, YAML and JSON
+// are accepted; the format comes from the extension or, failing that, the content.
 func mergeConfigFile(v *viper.Viper, path string) error {
+	_, err := mergeConfigLayer(v, path)
+	return err
+}
+
+// mergeConfigLayer merges the config file like mergeConfigFile and returns
+// the dot-notated keys it sets.
+func mergeConfigLayer(v *viper.Viper, path string) ([]string, error) {
+	m, format, err := readConfigMap(path)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	v.SetConfigType(string(format))
+	if err := v.MergeConfigMap(m); err != nil {
+		return nil, fmt.Errorf("merge config %s: %w", path, err)
+	}
+	return flattenKeys("", m), nil
+}
+
+// readConfigMap decodes the config file at path, returning nil if it does
+// not exist.
+func readConfigMap(path string) (map[string]any, Format, error) {
 	if path == "" {
-		return nil
+		return nil, "", nil
 	}
 	info, err := os.Stat(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil
+			return nil, "", nil
 		}
-		return fmt.Errorf("stat config %s: %w", path, err)
+		return nil, "", fmt.Errorf("stat config %s: %w", path, err)
 	}
 	if info.IsDir() {
-		return fmt.Errorf("config path %s is a directory", path)
+		return nil, "", fmt.Errorf("config path %s is a directory", path)
 	}
-	v.SetConfigFile(path)
-	if err := v.MergeInConfig(); err != nil {
-		return fmt.Errorf("merge config %s: %w", path, err)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("read config %s: %w", path, err)
 	}
-	return nil
+	format, err := DetectFormat(path, data)
+	if err != nil {
+		return nil, "", err
+	}
+	m, err := decodeConfigMap(format, data)
+	if err != nil {
+		return nil, "", fmt.Errorf("merge config %s: %w", path, err)
+	}
+	return m, format, nil
+}
+
+// flattenKeys returns the dot-notated, lower-cased keys of the leaf values
+// in m.
+func flattenKeys(prefix string, m map[string]any) []string {
+	var keys []string
+	for k, val := range m {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if sub, ok := val.(map[string]any); ok {
+			keys = append(keys, flattenKeys(key, sub)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
 }
 
 // applyEnvOverrides reads SLB_* env vars and applies them.
@@ -168,27 +402,36 @@ func applyFlagOverrides(v *viper.Viper, overrides map[string]any) {
 	}
 }
 
-// ConfigPaths returns the user and project config file paths.
-func ConfigPaths(projectDir, configOverride string) (string, string) {
-	return userConfigPath(), projectConfigPath(projectDir, configOverride)
+// ConfigPaths returns the user and project config file paths. It fails with
+// ErrAmbiguousConfig when either .slb directory holds several config files.
+func ConfigPaths(projectDir, configOverride string) (string, string, error) {
+	userPath, err := userConfigPath()
+	if err != nil {
+		return "", "", err
+	}
+	projectPath, err := projectConfigPath(projectDir, configOverride)
+	if err != nil {
+		return "", "", err
+	}
+	return userPath, projectPath, nil
 }
 
-func userConfigPath() string {
+func userConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return ""
+		return "", nil
 	}
-	return filepath.Join(home, ".slb", "config.toml")
+	return findConfigFile(filepath.Join(home, ".slb"))
 }
 
-func projectConfigPath(projectDir, override string) string {
+func projectConfigPath(projectDir, override string) (string, error) {
 	if override != "" {
-		return override
+		return override, nil
 	}
 	if projectDir == "" {
-		return ".slb/config.toml"
+		return findConfigFile(".slb")
 	}
-	return filepath.Join(projectDir, ".slb", "config.toml")
+	return findConfigFile(filepath.Join(projectDir, ".slb"))
 }
 
 // ParseValue parses a raw string into the expected type for a given config key.
@@ -254,10 +497,74 @@ func GetValue(cfg Config, key string) (any, bool) {
 				return c.EnableRollbackCapture, true
 			case "max_rollback_size_mb":
 				return c.MaxRollbackSizeMB, true
+			case "rollback_root_prefix":
+				return c.RollbackRootPrefix, true
+			case "max_rollback_captures":
+				return c.MaxRollbackCaptures, true
+			case "max_concurrent_rollback_captures":
+				return c.MaxConcurrentCaptures, true
+			case "rollback_capture_wait_seconds":
+				return c.RollbackCaptureWaitSecs, true
+			case "rollback_exclude":
+				return c.RollbackExclude, true
+			case "max_concurrent_executions":
+				return c.MaxConcurrentExecutions, true
+			case "execution_queue_wait_seconds":
+				return c.ExecutionQueueWaitSecs, true
 			case "cross_project_reviews":
 				return c.CrossProjectReviews, true
 			case "review_pool":
 				return c.ReviewPool, true
+			case "unviewed_evidence_action":
+				return c.UnviewedEvidenceAction, true
+			case "context_pinning":
+				return c.ContextPinning, true
+			case "preview_max_copy_mb":
+				return c.PreviewMaxCopyMB, true
+			case "preview_container_image":
+				return c.PreviewContainerImage, true
+			case "self_protection":
+				return c.SelfProtection, true
+			case "policy_attestation_days":
+				return c.PolicyAttestationDays, true
+			case "policy_attestation_grace_days":
+				return c.PolicyAttestationGraceDays, true
+			case "run_all_approved_segments":
+				return c.RunAllApprovedSegments, true
+			case "migration_globs":
+				return c.MigrationGlobs, true
+			case "migration_max_attachment_kb":
+				return c.MigrationMaxAttachmentKB, true
+			case "dry_run_withhold_tiers":
+				return c.DryRunWithholdTiers, true
+			case "require_different_host_tiers":
+				return c.RequireDifferentHostTiers, true
+			case "require_different_program_tiers":
+				return c.RequireDifferentProgramTiers, true
+			case "max_total_attachment_kb":
+				return c.MaxTotalAttachmentKB, true
+			case "attachment_context_reserve_kb":
+				return c.AttachmentContextReserveKB, true
+			case "attachment_path_privacy":
+				return c.AttachmentPathPrivacy, true
+			case "redact_patterns":
+				return c.RedactPatterns, true
+			case "strict_redaction":
+				return c.StrictRedaction, true
+			case "anonymize_reviewers":
+				return c.AnonymizeReviewers, true
+			case "review_auditors":
+				return c.ReviewAuditors, true
+			case "trusted_script_floor":
+				return c.TrustedScriptFloor, true
+			case "dry_run_noop_action":
+				return c.DryRunNoopAction, true
+			case "rejection_cooldown_seconds":
+				return c.RejectionCooldownSecs, true
+			case "rejection_cooldown_action":
+				return c.RejectionCooldownAction, true
+			case "escalate_after_minutes":
+				return c.EscalateAfterMinutes, true
 			default:
 				return nil, false
 			}
@@ -301,6 +608,14 @@ func GetValue(cfg Config, key string) (any, bool) {
 				return c.WebhookURL, true
 			case "email_enabled":
 				return c.EmailEnabled, true
+			case "webhook_urls":
+				return c.WebhookURLs, true
+			case "webhook_events":
+				return c.WebhookEvents, true
+			case "webhook_template":
+				return c.WebhookTemplate, true
+			case "notify_statuses":
+				return c.NotifyStatuses, true
 			default:
 				return nil, false
 			}
@@ -340,6 +655,16 @@ func GetValue(cfg Config, key string) (any, bool) {
 				return c.DynamicQuorumFloor, true
 			case "auto_approve_delay_seconds":
 				return c.AutoApproveDelaySeconds, true
+			case "sla_seconds":
+				return c.SLASeconds, true
+			case "min_timeout_seconds":
+				return c.MinTimeoutSeconds, true
+			case "max_timeout_seconds":
+				return c.MaxTimeoutSeconds, true
+			case "required_roles":
+				return c.RequiredRoles, true
+			case "escalate_after_minutes":
+				return c.EscalateAfterMinutes, true
 			case "patterns":
 				return c.Patterns, true
 			default:
@@ -351,8 +676,14 @@ func GetValue(cfg Config, key string) (any, bool) {
 				return c.AgentMailEnabled, true
 			case "agent_mail_thread":
 				return c.AgentMailThread, true
+			case "agent_mail_routes":
+				return c.AgentMailRoutes, true
 			case "claude_hooks_enabled":
 				return c.ClaudeHooksEnabled, true
+			case "change_record_url":
+				return c.ChangeRecordURL, true
+			case "change_record_tiers":
+				return c.ChangeRecordTiers, true
 			default:
 				return nil, false
 			}
@@ -364,6 +695,28 @@ func GetValue(cfg Config, key string) (any, bool) {
 				return c.TrustedSelfApproveDelaySecs, true
 			case "blocked":
 				return c.Blocked, true
+			case "policy":
+				return c.Policy, true
+			case "allowed":
+				return c.Allowed, true
+			case "reviewer_fast_approval_seconds":
+				return c.ReviewerFastApprovalSecs, true
+			case "reviewer_empty_response_percent":
+				return c.ReviewerEmptyResponsePercent, true
+			case "reviewer_approval_streak":
+				return c.ReviewerApprovalStreak, true
+			case "reviewer_min_reviews":
+				return c.ReviewerMinReviews, true
+			case "reviewer_window_days":
+				return c.ReviewerWindowDays, true
+			case "reviewer_pattern_action":
+				return c.ReviewerPatternAction, true
+			case "reviewer_weights":
+				return c.ReviewerWeights, true
+			case "reviewer_roles":
+				return c.ReviewerRoles, true
+			case "session_idle_minutes":
+				return c.SessionIdleMinutes, true
 			default:
 				return nil, false
 			}
@@ -374,21 +727,21 @@ func GetValue(cfg Config, key string) (any, bool) {
 	return current, true
 }
 
-// WriteValue sets a single key/value into the specified TOML config file (creating it if needed).
+// WriteValue sets a single key/value into the specified config file (creating it if needed),
+// keeping the file's format. Files without a recognized extension are written as TOML.
 func WriteValue(path, key string, value any) error {
 	if path == "" {
 		return fmt.Errorf("config path is empty")
 	}
-	var existing map[string]any
-	if _, err := os.Stat(path); err == nil {
-		if _, err := toml.DecodeFile(path, &existing); err != nil {
+	format, ok := formatFromExtension(path)
+	if !ok {
+		format = FormatTOML
+	}
+	existing := map[string]any{}
+	if data, err := os.ReadFile(path); err == nil {
+		if existing, err = decodeConfigMap(format, data); err != nil {
 			return fmt.Errorf("decode config: %w", err)
 		}
-		if existing == nil {
-			existing = map[string]any{}
-		}
-	} else {
-		existing = map[string]any{}
 	}
 
 	if err := setNested(existing, key, value); err != nil {
@@ -398,17 +751,13 @@ func WriteValue(path, key string, value any) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
 		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
 	}
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	data, err := encodeConfigMap(format, existing)
 	if err != nil {
-		return fmt.Errorf("create config %s: %w", path, err)
-	}
-	defer f.Close()
-
-	enc := toml.NewEncoder(f)
-	enc.Indent = "  "
-	if err := enc.Encode(existing); err != nil {
 		return fmt.Errorf("encode config: %w", err)
 	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("create config %s: %w", path, err)
+	}
 	return nil
 }
 
@@ -451,19 +800,51 @@ const (
 )
 
 var keyKinds = map[string]valueKind{
-	"general.min_approvals":                 kindInt,
-	"general.require_different_model":       kindBool,
-	"general.different_model_timeout":       kindInt,
-	"general.conflict_resolution":           kindString,
-	"general.request_timeout":               kindInt,
-	"general.approval_ttl_minutes":          kindInt,
-	"general.approval_ttl_critical_minutes": kindInt,
-	"general.timeout_action":                kindString,
-	"general.enable_dry_run":                kindBool,
-	"general.enable_rollback_capture":       kindBool,
-	"general.max_rollback_size_mb":          kindInt,
-	"general.cross_project_reviews":         kindBool,
-	"general.review_pool":                   kindStringSlice,
+	"general.min_approvals":                    kindInt,
+	"general.require_different_model":          kindBool,
+	"general.different_model_timeout":          kindInt,
+	"general.conflict_resolution":              kindString,
+	"general.request_timeout":                  kindInt,
+	"general.approval_ttl_minutes":             kindInt,
+	"general.approval_ttl_critical_minutes":    kindInt,
+	"general.timeout_action":                   kindString,
+	"general.enable_dry_run":                   kindBool,
+	"general.enable_rollback_capture":          kindBool,
+	"general.max_rollback_size_mb":             kindInt,
+	"general.rollback_root_prefix":             kindString,
+	"general.max_rollback_captures":            kindInt,
+	"general.max_concurrent_rollback_captures": kindInt,
+	"general.rollback_capture_wait_seconds":    kindInt,
+	"general.rollback_exclude":                 kindStringSlice,
+	"general.max_concurrent_executions":        kindInt,
+	"general.execution_queue_wait_seconds":     kindInt,
+	"general.cross_project_reviews":            kindBool,
+	"general.review_pool":                      kindStringSlice,
+	"general.unviewed_evidence_action":         kindString,
+	"general.context_pinning":                  kindStringSlice,
+	"general.preview_max_copy_mb":              kindInt,
+	"general.preview_container_image":          kindString,
+	"general.self_protection":                  kindString,
+	"general.policy_attestation_days":          kindInt,
+	"general.policy_attestation_grace_days":    kindInt,
+	"general.run_all_approved_segments":        kindBool,
+	"general.migration_globs":                  kindStringSlice,
+	"general.migration_max_attachment_kb":      kindInt,
+	"general.dry_run_withhold_tiers":           kindStringSlice,
+	"general.require_different_host_tiers":     kindStringSlice,
+	"general.require_different_program_tiers":  kindStringSlice,
+	"general.max_total_attachment_kb":          kindInt,
+	"general.attachment_context_reserve_kb":    kindInt,
+	"general.attachment_path_privacy":          kindString,
+	"general.redact_patterns":                  kindStringSlice,
+	"general.strict_redaction":                 kindBool,
+	"general.anonymize_reviewers":              kindBool,
+	"general.review_auditors":                  kindStringSlice,
+	"general.trusted_script_floor":             kindString,
+	"general.dry_run_noop_action":              kindString,
+	"general.rejection_cooldown_seconds":       kindInt,
+	"general.rejection_cooldown_action":        kindString,
+	"general.escalate_after_minutes":           kindInt,
 
 	"daemon.use_file_watcher": kindBool,
 	"daemon.ipc_socket":       kindString,
@@ -481,6 +862,10 @@ var keyKinds = map[string]valueKind{
 	"notifications.desktop_delay_seconds": kindInt,
 	"notifications.webhook_url":           kindString,
 	"notifications.email_enabled":         kindBool,
+	"notifications.webhook_urls":          kindStringSlice,
+	"notifications.webhook_events":        kindStringSlice,
+	"notifications.webhook_template":      kindString,
+	"notifications.notify_statuses":       kindStringSlice,
 
 	"history.database_path":   kindString,
 	"history.git_repo_path":   kindString,
@@ -491,33 +876,67 @@ var keyKinds = map[string]valueKind{
 	"patterns.critical.dynamic_quorum":             kindBool,
 	"patterns.critical.dynamic_quorum_floor":       kindInt,
 	"patterns.critical.auto_approve_delay_seconds": kindInt,
+	"patterns.critical.sla_seconds":                kindInt,
+	"patterns.critical.min_timeout_seconds":        kindInt,
+	"patterns.critical.max_timeout_seconds":        kindInt,
+	"patterns.critical.required_roles":             kindStringSlice,
+	"patterns.critical.escalate_after_minutes":     kindInt,
 	"patterns.critical.patterns":                   kindStringSlice,
 
 	"patterns.dangerous.min_approvals":              kindInt,
 	"patterns.dangerous.dynamic_quorum":             kindBool,
 	"patterns.dangerous.dynamic_quorum_floor":       kindInt,
 	"patterns.dangerous.auto_approve_delay_seconds": kindInt,
+	"patterns.dangerous.sla_seconds":                kindInt,
+	"patterns.dangerous.min_timeout_seconds":        kindInt,
+	"patterns.dangerous.max_timeout_seconds":        kindInt,
+	"patterns.dangerous.required_roles":             kindStringSlice,
+	"patterns.dangerous.escalate_after_minutes":     kindInt,
 	"patterns.dangerous.patterns":                   kindStringSlice,
 
 	"patterns.caution.min_approvals":              kindInt,
 	"patterns.caution.dynamic_quorum":             kindBool,
 	"patterns.caution.dynamic_quorum_floor":       kindInt,
 	"patterns.caution.auto_approve_delay_seconds": kindInt,
+	"patterns.caution.sla_seconds":                kindInt,
+	"patterns.caution.min_timeout_seconds":        kindInt,
+	"patterns.caution.max_timeout_seconds":        kindInt,
+	"patterns.caution.required_roles":             kindStringSlice,
+	"patterns.caution.escalate_after_minutes":     kindInt,
 	"patterns.caution.patterns":                   kindStringSlice,
 
 	"patterns.safe.min_approvals":              kindInt,
 	"patterns.safe.dynamic_quorum":             kindBool,
 	"patterns.safe.dynamic_quorum_floor":       kindInt,
 	"patterns.safe.auto_approve_delay_seconds": kindInt,
+	"patterns.safe.sla_seconds":                kindInt,
+	"patterns.safe.min_timeout_seconds":        kindInt,
+	"patterns.safe.max_timeout_seconds":        kindInt,
+	"patterns.safe.required_roles":             kindStringSlice,
+	"patterns.safe.escalate_after_minutes":     kindInt,
 	"patterns.safe.patterns":                   kindStringSlice,
 
 	"integrations.agent_mail_enabled":   kindBool,
 	"integrations.agent_mail_thread":    kindString,
+	"integrations.agent_mail_routes":    kindStringSlice,
 	"integrations.claude_hooks_enabled": kindBool,
+	"integrations.change_record_url":    kindString,
+	"integrations.change_record_tiers":  kindStringSlice,
 
 	"agents.trusted_self_approve":               kindStringSlice,
 	"agents.trusted_self_approve_delay_seconds": kindInt,
 	"agents.blocked":                            kindStringSlice,
+	"agents.policy":                             kindString,
+	"agents.allowed":                            kindStringSlice,
+	"agents.reviewer_fast_approval_seconds":     kindInt,
+	"agents.reviewer_empty_response_percent":    kindInt,
+	"agents.reviewer_approval_streak":           kindInt,
+	"agents.reviewer_min_reviews":               kindInt,
+	"agents.reviewer_window_days":               kindInt,
+	"agents.reviewer_pattern_action":            kindString,
+	"agents.reviewer_weights":                   kindStringSlice,
+	"agents.reviewer_roles":                     kindStringSlice,
+	"agents.session_idle_minutes":               kindInt,
 }
 
 var envBindings = []struct {
@@ -536,8 +955,40 @@ var envBindings = []struct {
 	{"SLB_ENABLE_DRY_RUN", "general.enable_dry_run", kindBool},
 	{"SLB_ENABLE_ROLLBACK_CAPTURE", "general.enable_rollback_capture", kindBool},
 	{"SLB_MAX_ROLLBACK_SIZE_MB", "general.max_rollback_size_mb", kindInt},
+	{"SLB_ROLLBACK_ROOT_PREFIX", "general.rollback_root_prefix", kindString},
+	{"SLB_MAX_ROLLBACK_CAPTURES", "general.max_rollback_captures", kindInt},
+	{"SLB_MAX_CONCURRENT_ROLLBACK_CAPTURES", "general.max_concurrent_rollback_captures", kindInt},
+	{"SLB_ROLLBACK_CAPTURE_WAIT_SECONDS", "general.rollback_capture_wait_seconds", kindInt},
+	{"SLB_ROLLBACK_EXCLUDE", "general.rollback_exclude", kindStringSlice},
+	{"SLB_MAX_CONCURRENT_EXECUTIONS", "general.max_concurrent_executions", kindInt},
+	{"SLB_EXECUTION_QUEUE_WAIT_SECONDS", "general.execution_queue_wait_seconds", kindInt},
 	{"SLB_CROSS_PROJECT_REVIEWS", "general.cross_project_reviews", kindBool},
 	{"SLB_REVIEW_POOL", "general.review_pool", kindStringSlice},
+	{"SLB_UNVIEWED_EVIDENCE_ACTION", "general.unviewed_evidence_action", kindString},
+	{"SLB_CONTEXT_PINNING", "general.context_pinning", kindStringSlice},
+	{"SLB_PREVIEW_MAX_COPY_MB", "general.preview_max_copy_mb", kindInt},
+	{"SLB_PREVIEW_CONTAINER_IMAGE", "general.preview_container_image", kindString},
+	{"SLB_SELF_PROTECTION", "general.self_protection", kindString},
+	{"SLB_POLICY_ATTESTATION_DAYS", "general.policy_attestation_days", kindInt},
+	{"SLB_POLICY_ATTESTATION_GRACE_DAYS", "general.policy_attestation_grace_days", kindInt},
+	{"SLB_RUN_ALL_APPROVED_SEGMENTS", "general.run_all_approved_segments", kindBool},
+	{"SLB_MIGRATION_GLOBS", "general.migration_globs", kindStringSlice},
+	{"SLB_MIGRATION_MAX_ATTACHMENT_KB", "general.migration_max_attachment_kb", kindInt},
+	{"SLB_DRY_RUN_WITHHOLD_TIERS", "general.dry_run_withhold_tiers", kindStringSlice},
+	{"SLB_REQUIRE_DIFFERENT_HOST_TIERS", "general.require_different_host_tiers", kindStringSlice},
+	{"SLB_REQUIRE_DIFFERENT_PROGRAM_TIERS", "general.require_different_program_tiers", kindStringSlice},
+	{"SLB_MAX_TOTAL_ATTACHMENT_KB", "general.max_total_attachment_kb", kindInt},
+	{"SLB_ATTACHMENT_CONTEXT_RESERVE_KB", "general.attachment_context_reserve_kb", kindInt},
+	{"SLB_ATTACHMENT_PATH_PRIVACY", "general.attachment_path_privacy", kindString},
+	{"SLB_REDACT_PATTERNS", "general.redact_patterns", kindStringSlice},
+	{"SLB_STRICT_REDACTION", "general.strict_redaction", kindBool},
+	{"SLB_ANONYMIZE_REVIEWERS", "general.anonymize_reviewers", kindBool},
+	{"SLB_REVIEW_AUDITORS", "general.review_auditors", kindStringSlice},
+	{"SLB_TRUSTED_SCRIPT_FLOOR", "general.trusted_script_floor", kindString},
+	{"SLB_DRY_RUN_NOOP_ACTION", "general.dry_run_noop_action", kindString},
+	{"SLB_REJECTION_COOLDOWN_SECONDS", "general.rejection_cooldown_seconds", kindInt},
+	{"SLB_REJECTION_COOLDOWN_ACTION", "general.rejection_cooldown_action", kindString},
+	{"SLB_ESCALATE_AFTER_MINUTES", "general.escalate_after_minutes", kindInt},
 
 	{"SLB_DAEMON_USE_FILE_WATCHER", "daemon.use_file_watcher", kindBool},
 	{"SLB_DAEMON_IPC_SOCKET", "daemon.ipc_socket", kindString},
@@ -555,6 +1006,10 @@ var envBindings = []struct {
 	{"SLB_DESKTOP_DELAY_SECONDS", "notifications.desktop_delay_seconds", kindInt},
 	{"SLB_WEBHOOK_URL", "notifications.webhook_url", kindString},
 	{"SLB_EMAIL_ENABLED", "notifications.email_enabled", kindBool},
+	{"SLB_WEBHOOK_URLS", "notifications.webhook_urls", kindStringSlice},
+	{"SLB_WEBHOOK_EVENTS", "notifications.webhook_events", kindStringSlice},
+	{"SLB_WEBHOOK_TEMPLATE", "notifications.webhook_template", kindString},
+	{"SLB_NOTIFY_STATUSES", "notifications.notify_statuses", kindStringSlice},
 
 	{"SLB_HISTORY_DB_PATH", "history.database_path", kindString},
 	{"SLB_HISTORY_GIT_PATH", "history.git_repo_path", kindString},
@@ -563,11 +1018,25 @@ var envBindings = []struct {
 
 	{"SLB_AGENT_MAIL_ENABLED", "integrations.agent_mail_enabled", kindBool},
 	{"SLB_AGENT_MAIL_THREAD", "integrations.agent_mail_thread", kindString},
+	{"SLB_AGENT_MAIL_ROUTES", "integrations.agent_mail_routes", kindStringSlice},
 	{"SLB_CLAUDE_HOOKS_ENABLED", "integrations.claude_hooks_enabled", kindBool},
+	{"SLB_CHANGE_RECORD_URL", "integrations.change_record_url", kindString},
+	{"SLB_CHANGE_RECORD_TIERS", "integrations.change_record_tiers", kindStringSlice},
 
 	{"SLB_TRUSTED_SELF_APPROVE", "agents.trusted_self_approve", kindStringSlice},
 	{"SLB_TRUSTED_SELF_APPROVE_DELAY_SECONDS", "agents.trusted_self_approve_delay_seconds", kindInt},
 	{"SLB_BLOCKED_AGENTS", "agents.blocked", kindStringSlice},
+	{"SLB_AGENT_POLICY", "agents.policy", kindString},
+	{"SLB_ALLOWED_AGENTS", "agents.allowed", kindStringSlice},
+	{"SLB_REVIEWER_FAST_APPROVAL_SECONDS", "agents.reviewer_fast_approval_seconds", kindInt},
+	{"SLB_REVIEWER_EMPTY_RESPONSE_PERCENT", "agents.reviewer_empty_response_percent", kindInt},
+	{"SLB_REVIEWER_APPROVAL_STREAK", "agents.reviewer_approval_streak", kindInt},
+	{"SLB_REVIEWER_MIN_REVIEWS", "agents.reviewer_min_reviews", kindInt},
+	{"SLB_REVIEWER_WINDOW_DAYS", "agents.reviewer_window_days", kindInt},
+	{"SLB_REVIEWER_PATTERN_ACTION", "agents.reviewer_pattern_action", kindString},
+	{"SLB_REVIEWER_WEIGHTS", "agents.reviewer_weights", kindStringSlice},
+	{"SLB_REVIEWER_ROLES", "agents.reviewer_roles", kindStringSlice},
+	{"SLB_SESSION_IDLE_MINUTES", "agents.session_idle_minutes", kindInt},
 }
 
 func parseValueByKind(raw string, kind valueKind) (any, error) {
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// autoApprovePolicy is the part of the effective configuration that decides
+// what gets through without a fresh review. Policy attestations cover its
+// hash, so an edit in any layer (user, workspace, project or SLB_* env) that
+// loosens auto-approval invalidates them.
+type autoApprovePolicy struct {
+	MinApprovals                int                `json:"min_approvals"`
+	RequireDifferentModel       bool               `json:"require_different_model"`
+	ConflictResolution          string             `json:"conflict_resolution"`
+	TimeoutAction               string             `json:"timeout_action"`
+	SelfProtection              string             `json:"self_protection"`
+	RunAllApprovedSegments      bool               `json:"run_all_approved_segments"`
+	Patterns                    PatternsConfig     `json:"patterns"`
+	TrustedSelfApprove          []string           `json:"trusted_self_approve"`
+	TrustedSelfApproveDelaySecs int                `json:"trusted_self_approve_delay_seconds"`
+	Blocked                     []string           `json:"blocked"`
+	RiskOverrides               []RiskOverrideRule `json:"risk_overrides,omitempty"`
+	TrustedScriptFloor          string             `json:"trusted_script_floor,omitempty"`
+	DryRunNoopAction            string             `json:"dry_run_noop_action,omitempty"`
+	AgentPolicy                 string             `json:"agent_policy,omitempty"`
+	AllowedAgents               []string           `json:"allowed_agents,omitempty"`
+}
+
+// PolicyHash returns the hex SHA-256 of the auto-approve settings in cfg,
+// which should be the merged result of Load rather than a single file.
+func PolicyHash(cfg Config) string {
+	policy := autoApprovePolicy{
+		MinApprovals:                cfg.General.MinApprovals,
+		RequireDifferentModel:       cfg.General.RequireDifferentModel,
+		ConflictResolution:          cfg.General.ConflictResolution,
+		TimeoutAction:               cfg.General.TimeoutAction,
+		SelfProtection:              cfg.General.SelfProtection,
+		RunAllApprovedSegments:      cfg.General.RunAllApprovedSegments,
+		Patterns:                    cfg.Patterns,
+		TrustedSelfApprove:          cfg.Agents.TrustedSelfApprove,
+		TrustedSelfApproveDelaySecs: cfg.Agents.TrustedSelfApproveDelaySecs,
+		Blocked:                     cfg.Agents.Blocked,
+		RiskOverrides:               cfg.RiskOverrides.Rules,
+	}
+	// Recorded only when changed, so attestations made before the setting
+	// existed stay valid.
+	if cfg.General.TrustedScriptFloor != DefaultConfig().General.TrustedScriptFloor {
+		policy.TrustedScriptFloor = cfg.General.TrustedScriptFloor
+	}
+	if cfg.General.DryRunNoopAction != DefaultConfig().General.DryRunNoopAction {
+		policy.DryRunNoopAction = cfg.General.DryRunNoopAction
+	}
+	if cfg.Agents.Policy != DefaultConfig().Agents.Policy {
+		policy.AgentPolicy = cfg.Agents.Policy
+		policy.AllowedAgents = cfg.Agents.Allowed
+	}
+	// Marshalling a struct of plain values cannot fail.
+	data, _ := json.Marshal(policy)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
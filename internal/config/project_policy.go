@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PolicyFileName is the name of a project policy file in a .slb directory.
+// A project policy overrides the other config files for requests whose
+// working directory is at or below the directory holding that .slb.
+const PolicyFileName = "policy.toml"
+
+// PolicyPath returns the project policy path for dir.
+func PolicyPath(dir string) string {
+	return filepath.Join(dir, ".slb", PolicyFileName)
+}
+
+// FindPolicyFile walks up from dir to the nearest .slb/policy.toml and
+// returns its path, or "" if there is none.
+func FindPolicyFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("resolving directory: %w", err)
+	}
+	for {
+		path := PolicyPath(dir)
+		if info, err := os.Stat(path); err == nil {
+			if info.IsDir() {
+				return "", fmt.Errorf("project policy %s is a directory", path)
+			}
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("stat project policy %s: %w", path, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// checkPolicyFile fails if the project policy at path cannot be parsed or
+// sets keys that are not settings, so a typo cannot silently leave the
+// global value in force.
+func checkPolicyFile(path string) error {
+	m, _, err := readConfigMap(path)
+	if err != nil {
+		return fmt.Errorf("project policy: %w", err)
+	}
+	var unknown []string
+	for _, key := range flattenKeys("", m) {
+		if !knownSetting(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("project policy %s: unknown settings: %s", path, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// knownSetting reports whether key names a setting.
+func knownSetting(key string) bool {
+	if _, ok := keyKinds[key]; ok {
+		return true
+	}
+	return key == "risk_overrides.rules"
+}
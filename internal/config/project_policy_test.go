@@ -0,0 +1,177 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePolicy(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := PolicyPath(dir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFindPolicyFile(t *testing.T) {
+	root := t.TempDir()
+	deep := filepath.Join(root, "infra", "k8s", "prod")
+	if err := os.MkdirAll(deep, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := FindPolicyFile(deep); err != nil || got != "" {
+		t.Fatalf("FindPolicyFile without policy = %q, %v", got, err)
+	}
+
+	rootPolicy := writePolicy(t, root, "")
+	if got, err := FindPolicyFile(deep); err != nil || got != rootPolicy {
+		t.Fatalf("FindPolicyFile = %q, %v; want %q", got, err, rootPolicy)
+	}
+	// The nearest policy wins.
+	infraPolicy := writePolicy(t, filepath.Join(root, "infra"), "")
+	if got, err := FindPolicyFile(deep); err != nil || got != infraPolicy {
+		t.Fatalf("FindPolicyFile = %q, %v; want %q", got, err, infraPolicy)
+	}
+}
+
+func TestLoad_ProjectPolicy(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+	infra := filepath.Join(project, "infra")
+	docs := filepath.Join(project, "docs")
+	for _, dir := range []string{infra, docs} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := WriteValue(filepath.Join(home, ".slb", "config.toml"), "general.min_approvals", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteValue(filepath.Join(project, ".slb", "config.toml"), "general.request_timeout", 600); err != nil {
+		t.Fatal(err)
+	}
+	writePolicy(t, infra, `
+[general]
+min_approvals = 2
+request_timeout = 900
+
+[[risk_overrides.rules]]
+glob = "kubectl *"
+tier = "critical"
+`)
+
+	cfg, err := Load(LoadOptions{ProjectDir: project, PolicyDir: filepath.Join(infra, "k8s")})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.General.MinApprovals != 2 || cfg.General.RequestTimeoutSecs != 900 {
+		t.Errorf("policy should win over user and project config, got min_approvals=%d request_timeout=%d",
+			cfg.General.MinApprovals, cfg.General.RequestTimeoutSecs)
+	}
+	if len(cfg.RiskOverrides.Rules) != 1 || cfg.RiskOverrides.Rules[0].Tier != "critical" {
+		t.Errorf("risk overrides = %+v", cfg.RiskOverrides.Rules)
+	}
+
+	// Requests outside the policy's directory do not get it.
+	cfg, err = Load(LoadOptions{ProjectDir: project, PolicyDir: docs})
+	if err != nil {
+		t.Fatalf("Load(docs): %v", err)
+	}
+	if cfg.General.MinApprovals != 3 || cfg.General.RequestTimeoutSecs != 600 {
+		t.Errorf("docs config = min_approvals=%d request_timeout=%d; want 3 and 600",
+			cfg.General.MinApprovals, cfg.General.RequestTimeoutSecs)
+	}
+
+	// Environment variables still win over the policy.
+	t.Setenv("SLB_MIN_APPROVALS", "4")
+	cfg, err = Load(LoadOptions{ProjectDir: project, PolicyDir: infra})
+	if err != nil {
+		t.Fatalf("Load(env): %v", err)
+	}
+	if cfg.General.MinApprovals != 4 {
+		t.Errorf("min_approvals = %d, want env value 4", cfg.General.MinApprovals)
+	}
+}
+
+func TestLoad_MalformedProjectPolicyErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"syntax", "[general\n", "project policy"},
+		{"unknown key", "[general]\nmin_aprovals = 2\n", "unknown settings: general.min_aprovals"},
+		{"invalid value", "[general]\nmin_approvals = 0\n", "general.min_approvals must be >= 1"},
+		{"wrong type", "[general]\nmin_approvals = \"two\"\n", "unmarshal config"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writePolicy(t, project, tc.content)
+			_, err := Load(LoadOptions{ProjectDir: project})
+			if err == nil {
+				t.Fatal("expected error for malformed policy")
+			}
+			if !strings.Contains(err.Error(), path) || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %q should name %s and contain %q", err, path, tc.want)
+			}
+		})
+	}
+}
+
+func TestEffective_ReportsSources(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+
+	userPath := filepath.Join(home, ".slb", "config.toml")
+	if err := WriteValue(userPath, "general.min_approvals", 3); err != nil {
+		t.Fatal(err)
+	}
+	policyPath := writePolicy(t, project, "[general]\nrequest_timeout = 900\n")
+	t.Setenv("SLB_TIMEOUT_ACTION", "auto_reject")
+
+	_, settings, err := Effective(LoadOptions{
+		ProjectDir:    project,
+		FlagOverrides: map[string]any{"general.approval_ttl_minutes": 5},
+	})
+	if err != nil {
+		t.Fatalf("Effective: %v", err)
+	}
+	byKey := make(map[string]Setting, len(settings))
+	for _, s := range settings {
+		byKey[s.Key] = s
+	}
+
+	want := map[string]Setting{
+		"general.min_approvals":        {Value: 3, Source: "user", Origin: userPath},
+		"general.request_timeout":      {Value: 900, Source: "policy", Origin: policyPath},
+		"general.timeout_action":       {Value: "auto_reject", Source: "env", Origin: "SLB_TIMEOUT_ACTION"},
+		"general.approval_ttl_minutes": {Value: 5, Source: "flag"},
+		"general.conflict_resolution":  {Value: "any_rejection_blocks", Source: "default"},
+	}
+	for key, w := range want {
+		got, ok := byKey[key]
+		if !ok {
+			t.Errorf("%s missing from effective settings", key)
+			continue
+		}
+		if got.Value != w.Value || got.Source != w.Source || got.Origin != w.Origin {
+			t.Errorf("%s = %+v, want %+v", key, got, w)
+		}
+	}
+	if _, ok := byKey["risk_overrides.rules"]; !ok {
+		t.Error("risk_overrides.rules missing from effective settings")
+	}
+}
@@ -2,9 +2,23 @@ package config
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// resolvedStatuses are the request statuses notifications.notify_statuses
+// may list; config cannot import db.
+var resolvedStatuses = []string{
+	"approved", "rejected", "approval_expired", "executed", "execution_failed",
+	"timed_out", "timeout", "cancelled", "escalated",
+}
+
+// rollbackRootPrefixRe matches core.ValidateRollbackRootPrefix; config cannot
+// import core.
+var rollbackRootPrefixRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,31}$`)
+
 // Validate checks the configuration for semantic errors.
 func Validate(cfg Config) error {
 	var errs []string
@@ -24,12 +38,112 @@ func Validate(cfg Config) error {
 	if cfg.General.MaxRollbackSizeMB < 0 {
 		errs = append(errs, "general.max_rollback_size_mb cannot be negative")
 	}
-	if !oneOf(cfg.General.ConflictResolution, "any_rejection_blocks", "first_wins", "human_breaks_tie") {
-		errs = append(errs, "general.conflict_resolution must be one of any_rejection_blocks|first_wins|human_breaks_tie")
+	if cfg.General.MaxRollbackCaptures < 0 {
+		errs = append(errs, "general.max_rollback_captures cannot be negative")
+	}
+	if cfg.General.MaxConcurrentCaptures < 0 {
+		errs = append(errs, "general.max_concurrent_rollback_captures cannot be negative")
+	}
+	if cfg.General.RollbackCaptureWaitSecs < 0 {
+		errs = append(errs, "general.rollback_capture_wait_seconds cannot be negative")
+	}
+	if cfg.General.MaxConcurrentExecutions < 0 {
+		errs = append(errs, "general.max_concurrent_executions cannot be negative")
+	}
+	if cfg.General.ExecutionQueueWaitSecs < 0 {
+		errs = append(errs, "general.execution_queue_wait_seconds cannot be negative")
+	}
+	if !rollbackRootPrefixRe.MatchString(cfg.General.RollbackRootPrefix) {
+		errs = append(errs, "general.rollback_root_prefix must be a letter followed by up to 31 letters, digits, '_' or '-'")
+	}
+	if cfg.General.PreviewMaxCopyMB < 0 {
+		errs = append(errs, "general.preview_max_copy_mb cannot be negative")
+	}
+	if cfg.General.PolicyAttestationDays < 0 {
+		errs = append(errs, "general.policy_attestation_days cannot be negative")
+	}
+	if cfg.General.PolicyAttestationGraceDays < 0 {
+		errs = append(errs, "general.policy_attestation_grace_days cannot be negative")
+	}
+	if !oneOf(cfg.General.ConflictResolution, "any_rejection_blocks", "first_wins", "human_breaks_tie", "weighted_quorum") {
+		errs = append(errs, "general.conflict_resolution must be one of any_rejection_blocks|first_wins|human_breaks_tie|weighted_quorum")
 	}
 	if !oneOf(cfg.General.TimeoutAction, "escalate", "auto_reject", "auto_approve_warn") {
 		errs = append(errs, "general.timeout_action must be one of escalate|auto_reject|auto_approve_warn")
 	}
+	if !oneOf(cfg.General.UnviewedEvidenceAction, "warn", "block_critical") {
+		errs = append(errs, "general.unviewed_evidence_action must be one of warn|block_critical")
+	}
+	if cfg.Agents.ReviewerFastApprovalSecs < 0 || cfg.Agents.ReviewerApprovalStreak < 0 || cfg.Agents.ReviewerMinReviews < 0 || cfg.Agents.ReviewerWindowDays < 0 {
+		errs = append(errs, "agents.reviewer_* thresholds cannot be negative")
+	}
+	if cfg.Agents.SessionIdleMinutes < 0 {
+		errs = append(errs, "agents.session_idle_minutes cannot be negative")
+	}
+	if cfg.Agents.ReviewerEmptyResponsePercent < 0 || cfg.Agents.ReviewerEmptyResponsePercent > 100 {
+		errs = append(errs, "agents.reviewer_empty_response_percent must be between 0 and 100")
+	}
+	if !oneOf(cfg.Agents.ReviewerPatternAction, "warn", "exclude_critical") {
+		errs = append(errs, "agents.reviewer_pattern_action must be one of warn|exclude_critical")
+	}
+	if !oneOf(cfg.General.SelfProtection, "critical", "refuse") {
+		errs = append(errs, "general.self_protection must be one of critical|refuse")
+	}
+	if !oneOf(cfg.General.TrustedScriptFloor, "safe", "caution", "dangerous") {
+		errs = append(errs, "general.trusted_script_floor must be one of safe|caution|dangerous")
+	}
+	if !oneOf(cfg.General.DryRunNoopAction, "review", "auto_approve", "skip") {
+		errs = append(errs, "general.dry_run_noop_action must be one of review|auto_approve|skip")
+	}
+	if cfg.General.RejectionCooldownSecs < 0 {
+		errs = append(errs, "general.rejection_cooldown_seconds cannot be negative")
+	}
+	if !oneOf(cfg.General.RejectionCooldownAction, "refuse", "justify") {
+		errs = append(errs, "general.rejection_cooldown_action must be one of refuse|justify")
+	}
+	if cfg.General.EscalateAfterMinutes < 0 {
+		errs = append(errs, "general.escalate_after_minutes cannot be negative")
+	}
+	if cfg.General.MigrationMaxAttachmentKB < 0 {
+		errs = append(errs, "general.migration_max_attachment_kb cannot be negative")
+	}
+	if cfg.General.MaxTotalAttachmentKB < 0 {
+		errs = append(errs, "general.max_total_attachment_kb cannot be negative")
+	}
+	if cfg.General.AttachmentContextReserveKB < 0 {
+		errs = append(errs, "general.attachment_context_reserve_kb cannot be negative")
+	}
+	if cfg.General.MaxTotalAttachmentKB > 0 && cfg.General.AttachmentContextReserveKB > cfg.General.MaxTotalAttachmentKB {
+		errs = append(errs, "general.attachment_context_reserve_kb cannot exceed general.max_total_attachment_kb")
+	}
+	if !oneOf(cfg.General.AttachmentPathPrivacy, "full", "relative", "basename") {
+		errs = append(errs, "general.attachment_path_privacy must be one of full|relative|basename")
+	}
+	for _, pattern := range cfg.General.RedactPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Sprintf("general.redact_patterns entry %q is not a valid regex: %v", pattern, err))
+		}
+	}
+	for _, family := range cfg.General.ContextPinning {
+		if !oneOf(family, "kubectl", "aws", "gcloud") {
+			errs = append(errs, fmt.Sprintf("general.context_pinning entries must be one of kubectl|aws|gcloud (got %q)", family))
+		}
+	}
+	for _, tier := range cfg.General.DryRunWithholdTiers {
+		if !oneOf(tier, "critical", "dangerous", "caution") {
+			errs = append(errs, fmt.Sprintf("general.dry_run_withhold_tiers entries must be one of critical|dangerous|caution (got %q)", tier))
+		}
+	}
+	for _, tier := range cfg.General.RequireDifferentHostTiers {
+		if !oneOf(tier, "critical", "dangerous", "caution") {
+			errs = append(errs, fmt.Sprintf("general.require_different_host_tiers entries must be one of critical|dangerous|caution (got %q)", tier))
+		}
+	}
+	for _, tier := range cfg.General.RequireDifferentProgramTiers {
+		if !oneOf(tier, "critical", "dangerous", "caution") {
+			errs = append(errs, fmt.Sprintf("general.require_different_program_tiers entries must be one of critical|dangerous|caution (got %q)", tier))
+		}
+	}
 
 	if cfg.RateLimits.MaxPendingPerSession < 0 {
 		errs = append(errs, "rate_limits.max_pending_per_session cannot be negative")
@@ -44,6 +158,35 @@ func Validate(cfg Config) error {
 	if cfg.Notifications.DesktopDelaySecs < 0 {
 		errs = append(errs, "notifications.desktop_delay_seconds cannot be negative")
 	}
+	for _, u := range cfg.Notifications.WebhookURLs {
+		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			errs = append(errs, fmt.Sprintf("notifications.webhook_urls entries must be http:// or https:// URLs (got %q)", u))
+		}
+	}
+	for _, event := range cfg.Notifications.WebhookEvents {
+		if !oneOf(event, "request_pending", "request_approved", "request_rejected", "request_executed") {
+			errs = append(errs, fmt.Sprintf("notifications.webhook_events entries must be one of request_pending|request_approved|request_rejected|request_executed (got %q)", event))
+		}
+	}
+	if !oneOf(cfg.Notifications.WebhookTemplate, "generic", "slack") {
+		errs = append(errs, "notifications.webhook_template must be one of generic|slack")
+	}
+	for _, status := range cfg.Notifications.NotifyStatuses {
+		if !oneOf(status, resolvedStatuses...) {
+			errs = append(errs, fmt.Sprintf("notifications.notify_statuses entries must be one of %s (got %q)", strings.Join(resolvedStatuses, "|"), status))
+		}
+	}
+
+	for _, route := range cfg.Integrations.AgentMailRoutes {
+		if i := strings.LastIndex(route, ":"); i <= 0 || i == len(route)-1 {
+			errs = append(errs, fmt.Sprintf("integrations.agent_mail_routes entries must look like labels:thread (got %q)", route))
+		}
+	}
+	for _, tier := range cfg.Integrations.ChangeRecordTiers {
+		if !oneOf(tier, "critical", "dangerous", "caution", "safe") {
+			errs = append(errs, fmt.Sprintf("integrations.change_record_tiers entries must be one of critical|dangerous|caution|safe (got %q)", tier))
+		}
+	}
 
 	if cfg.History.RetentionDays < 0 {
 		errs = append(errs, "history.retention_days cannot be negative")
@@ -59,15 +202,65 @@ func Validate(cfg Config) error {
 		if tier.AutoApproveDelaySeconds < 0 {
 			errs = append(errs, fmt.Sprintf("patterns.%s.auto_approve_delay_seconds cannot be negative", name))
 		}
+		if tier.SLASeconds < 0 {
+			errs = append(errs, fmt.Sprintf("patterns.%s.sla_seconds cannot be negative", name))
+		}
+		if tier.MinTimeoutSeconds < 0 {
+			errs = append(errs, fmt.Sprintf("patterns.%s.min_timeout_seconds cannot be negative", name))
+		}
+		if tier.MaxTimeoutSeconds < 0 {
+			errs = append(errs, fmt.Sprintf("patterns.%s.max_timeout_seconds cannot be negative", name))
+		}
+		if tier.MaxTimeoutSeconds > 0 && tier.MinTimeoutSeconds > tier.MaxTimeoutSeconds {
+			errs = append(errs, fmt.Sprintf("patterns.%s.min_timeout_seconds cannot exceed max_timeout_seconds", name))
+		}
+		if tier.EscalateAfterMinutes < 0 {
+			errs = append(errs, fmt.Sprintf("patterns.%s.escalate_after_minutes cannot be negative", name))
+		}
+		for _, role := range tier.RequiredRoles {
+			if strings.TrimSpace(role) == "" {
+				errs = append(errs, fmt.Sprintf("patterns.%s.required_roles cannot contain an empty role", name))
+			}
+		}
 	}
 	validateTier("critical", cfg.Patterns.Critical)
 	validateTier("dangerous", cfg.Patterns.Dangerous)
 	validateTier("caution", cfg.Patterns.Caution)
 	validateTier("safe", cfg.Patterns.Safe)
 
+	for i, rule := range cfg.RiskOverrides.Rules {
+		if (rule.Glob == "") == (rule.Regex == "") {
+			errs = append(errs, fmt.Sprintf("risk_overrides.rules[%d] must set exactly one of glob or regex", i))
+		}
+		if rule.Regex != "" {
+			if _, err := regexp.Compile(rule.Regex); err != nil {
+				errs = append(errs, fmt.Sprintf("risk_overrides.rules[%d].regex is invalid: %v", i, err))
+			}
+		}
+		if !oneOf(rule.Tier, "critical", "dangerous", "caution", "safe") {
+			errs = append(errs, fmt.Sprintf("risk_overrides.rules[%d].tier must be one of critical|dangerous|caution|safe (got %q)", i, rule.Tier))
+		}
+	}
+
 	if cfg.Agents.TrustedSelfApproveDelaySecs < 0 {
 		errs = append(errs, "agents.trusted_self_approve_delay_seconds cannot be negative")
 	}
+	if !oneOf(cfg.Agents.Policy, "open", "allowlist", "denylist") {
+		errs = append(errs, "agents.policy must be one of open|allowlist|denylist")
+	}
+	if cfg.Agents.Policy == "allowlist" && len(cfg.Agents.Allowed) == 0 {
+		errs = append(errs, "agents.policy allowlist requires at least one agents.allowed entry")
+	}
+	for _, entry := range cfg.Agents.ReviewerWeights {
+		if _, _, ok := parseReviewerWeight(entry); !ok {
+			errs = append(errs, fmt.Sprintf("agents.reviewer_weights entries must look like agent=weight with a weight >= 1 (got %q)", entry))
+		}
+	}
+	for _, entry := range cfg.Agents.ReviewerRoles {
+		if _, _, ok := parseReviewerRole(entry); !ok {
+			errs = append(errs, fmt.Sprintf("agents.reviewer_roles entries must look like agent=role (got %q)", entry))
+		}
+	}
 
 	if len(errs) > 0 {
 		return fmt.Errorf("config validation failed: %s", strings.Join(errs, "; "))
@@ -83,3 +276,57 @@ func oneOf(val string, options ...string) bool {
 	}
 	return false
 }
+
+// ReviewerWeightMap parses ReviewerWeights, skipping malformed entries
+// (Validate rejects them).
+func (c AgentsConfig) ReviewerWeightMap() map[string]int {
+	weights := make(map[string]int, len(c.ReviewerWeights))
+	for _, entry := range c.ReviewerWeights {
+		if agent, weight, ok := parseReviewerWeight(entry); ok {
+			weights[agent] = weight
+		}
+	}
+	return weights
+}
+
+// parseReviewerWeight parses an "Agent=weight" entry with a positive weight.
+func parseReviewerWeight(entry string) (string, int, bool) {
+	agent, value, ok := strings.Cut(entry, "=")
+	agent = strings.TrimSpace(agent)
+	if !ok || agent == "" {
+		return "", 0, false
+	}
+	weight, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || weight < 1 {
+		return "", 0, false
+	}
+	return agent, weight, true
+}
+
+// ReviewerRoleMap parses ReviewerRoles into each agent's roles, skipping
+// malformed entries (Validate rejects them).
+func (c AgentsConfig) ReviewerRoleMap() map[string][]string {
+	roles := make(map[string][]string, len(c.ReviewerRoles))
+	for _, entry := range c.ReviewerRoles {
+		if agent, role, ok := parseReviewerRole(entry); ok {
+			roles[agent] = append(roles[agent], role)
+		}
+	}
+	return roles
+}
+
+// SessionIdleTimeout returns SessionIdleMinutes as a duration; zero means
+// sessions never go idle.
+func (c AgentsConfig) SessionIdleTimeout() time.Duration {
+	return time.Duration(c.SessionIdleMinutes) * time.Minute
+}
+
+// parseReviewerRole parses an "Agent=role" entry.
+func parseReviewerRole(entry string) (string, string, bool) {
+	agent, role, ok := strings.Cut(entry, "=")
+	agent, role = strings.TrimSpace(agent), strings.TrimSpace(role)
+	if !ok || agent == "" || role == "" {
+		return "", "", false
+	}
+	return agent, role, true
+}
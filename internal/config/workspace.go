@@ -0,0 +1,210 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// WorkspaceFileName is the workspace manifest kept in the workspace root's .slb/.
+const WorkspaceFileName = "workspace.toml"
+
+// maxWorkspaceDiscoveryDepth bounds how deep DiscoverWorkspaceMembers looks.
+const maxWorkspaceDiscoveryDepth = 4
+
+// workspaceMarkers identify a directory as a member project during discovery.
+var workspaceMarkers = []string{
+	".slb", "go.mod", "package.json", "Cargo.toml", "pyproject.toml",
+	"setup.py", "pom.xml", "build.gradle", "Gemfile", "composer.json",
+}
+
+// workspaceSkipDirs are never descended into during discovery.
+var workspaceSkipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "target": true, "dist": true, "build": true,
+}
+
+// Workspace groups member projects of a monorepo under one root. Members keep
+// their own .slb state; policies in the root's .slb/config.toml are inherited
+// by every member and can be overridden in the member's own config.
+type Workspace struct {
+	// Root is the absolute workspace root directory.
+	Root string
+	// Members are absolute member project directories, sorted.
+	Members []string
+}
+
+type workspaceFile struct {
+	Members []string `toml:"members"`
+}
+
+// WorkspacePath returns the manifest path for a workspace root.
+func WorkspacePath(root string) string {
+	return filepath.Join(root, ".slb", WorkspaceFileName)
+}
+
+// LoadWorkspace reads the workspace manifest at root.
+func LoadWorkspace(root string) (*Workspace, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving workspace root: %w", err)
+	}
+	var wf workspaceFile
+	if _, err := toml.DecodeFile(WorkspacePath(root), &wf); err != nil {
+		return nil, fmt.Errorf("reading workspace %s: %w", WorkspacePath(root), err)
+	}
+
+	ws := &Workspace{Root: root}
+	seen := make(map[string]bool)
+	for _, m := range wf.Members {
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		if !filepath.IsAbs(m) {
+			m = filepath.Join(root, m)
+		}
+		m = filepath.Clean(m)
+		if m == root || !isWithin(root, m) {
+			return nil, fmt.Errorf("workspace member %s must be a subdirectory of %s", m, root)
+		}
+		if !seen[m] {
+			seen[m] = true
+			ws.Members = append(ws.Members, m)
+		}
+	}
+	sort.Strings(ws.Members)
+	return ws, nil
+}
+
+// WriteWorkspace writes the workspace manifest at root with members stored
+// relative to root.
+func WriteWorkspace(ws *Workspace) error {
+	wf := workspaceFile{Members: make([]string, 0, len(ws.Members))}
+	for _, m := range ws.Members {
+		wf.Members = append(wf.Members, filepath.ToSlash(ws.MemberName(m)))
+	}
+
+	path := WorkspacePath(ws.Root)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating workspace dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("writing workspace: %w", err)
+	}
+	defer f.Close()
+
+	header := "# SLB workspace: member projects share this root's policies and reviewer pool.\n" +
+		"# Paths are relative to the workspace root.\n\n"
+	if _, err := f.WriteString(header); err != nil {
+		return err
+	}
+	return toml.NewEncoder(f).Encode(wf)
+}
+
+// FindWorkspace walks up from dir looking for a workspace manifest.
+// It returns nil without error when dir is not inside a workspace.
+func FindWorkspace(dir string) (*Workspace, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving directory: %w", err)
+	}
+	for {
+		if _, err := os.Stat(WorkspacePath(dir)); err == nil {
+			return LoadWorkspace(dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// DiscoverWorkspaceMembers finds candidate member projects below root: any
+// directory holding an .slb/ or a common project manifest (go.mod,
+// package.json, ...). Hidden and dependency directories are skipped, and
+// discovery does not descend into a member once found.
+func DiscoverWorkspaceMembers(root string) ([]string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving workspace root: %w", err)
+	}
+
+	var members []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() || path == root {
+			return nil
+		}
+		name := d.Name()
+		if strings.HasPrefix(name, ".") || workspaceSkipDirs[name] {
+			return fs.SkipDir
+		}
+		rel, _ := filepath.Rel(root, path)
+		if strings.Count(rel, string(filepath.Separator))+1 > maxWorkspaceDiscoveryDepth {
+			return fs.SkipDir
+		}
+		for _, marker := range workspaceMarkers {
+			if _, err := os.Stat(filepath.Join(path, marker)); err == nil {
+				members = append(members, path)
+				return fs.SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("discovering workspace members: %w", err)
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+// MemberFor returns the member project containing dir, or "" if dir is not
+// inside any member. Nested members resolve to the deepest match.
+func (w *Workspace) MemberFor(dir string) string {
+	if w == nil {
+		return ""
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	best := ""
+	for _, m := range w.Members {
+		if (abs == m || isWithin(m, abs)) && len(m) > len(best) {
+			best = m
+		}
+	}
+	return best
+}
+
+// MemberName returns a member's path relative to the workspace root, used as
+// its display name.
+func (w *Workspace) MemberName(member string) string {
+	rel, err := filepath.Rel(w.Root, member)
+	if err != nil {
+		return member
+	}
+	return rel
+}
+
+// isWithin reports whether path is strictly inside dir.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
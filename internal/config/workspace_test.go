@@ -0,0 +1,177 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mkdirs creates dirs (relative to root) and touches the given marker files.
+func mkdirs(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		if strings.HasSuffix(f, "/") {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				t.Fatalf("MkdirAll %s: %v", path, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("WriteFile %s: %v", path, err)
+		}
+	}
+}
+
+func TestWorkspace_WriteLoadRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	ws := &Workspace{Root: root, Members: []string{
+		filepath.Join(root, "services", "api"),
+		filepath.Join(root, "web"),
+	}}
+	if err := WriteWorkspace(ws); err != nil {
+		t.Fatalf("WriteWorkspace: %v", err)
+	}
+
+	data, err := os.ReadFile(WorkspacePath(root))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(data), root) {
+		t.Fatalf("manifest should store relative paths:\n%s", data)
+	}
+
+	got, err := LoadWorkspace(root)
+	if err != nil {
+		t.Fatalf("LoadWorkspace: %v", err)
+	}
+	if !reflect.DeepEqual(got, ws) {
+		t.Fatalf("LoadWorkspace = %+v, want %+v", got, ws)
+	}
+}
+
+func TestLoadWorkspace_RejectsMembersOutsideRoot(t *testing.T) {
+	for _, member := range []string{"../elsewhere", "."} {
+		root := t.TempDir()
+		mkdirs(t, root, ".slb/")
+		content := "members = [\"" + member + "\"]\n"
+		if err := os.WriteFile(WorkspacePath(root), []byte(content), 0600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if _, err := LoadWorkspace(root); err == nil {
+			t.Fatalf("expected error for member %q", member)
+		}
+	}
+}
+
+func TestFindWorkspace(t *testing.T) {
+	root := t.TempDir()
+	if ws, err := FindWorkspace(root); err != nil || ws != nil {
+		t.Fatalf("FindWorkspace outside workspace = %+v, %v; want nil, nil", ws, err)
+	}
+
+	api := filepath.Join(root, "services", "api")
+	mkdirs(t, root, "services/api/internal/pkg/")
+	if err := WriteWorkspace(&Workspace{Root: root, Members: []string{api}}); err != nil {
+		t.Fatalf("WriteWorkspace: %v", err)
+	}
+
+	ws, err := FindWorkspace(filepath.Join(api, "internal", "pkg"))
+	if err != nil {
+		t.Fatalf("FindWorkspace: %v", err)
+	}
+	if ws == nil || ws.Root != root {
+		t.Fatalf("FindWorkspace = %+v, want root %s", ws, root)
+	}
+}
+
+func TestDiscoverWorkspaceMembers(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root,
+		"services/api/go.mod",
+		"services/api/cmd/tool/go.mod", // nested under a member: not descended into
+		"web/package.json",
+		"web/node_modules/left-pad/package.json",
+		"tools/.slb/",
+		".hidden/go.mod",
+		"docs/README.md",
+		"a/b/c/d/e/go.mod", // beyond max depth
+	)
+
+	got, err := DiscoverWorkspaceMembers(root)
+	if err != nil {
+		t.Fatalf("DiscoverWorkspaceMembers: %v", err)
+	}
+	want := []string{
+		filepath.Join(root, "services", "api"),
+		filepath.Join(root, "tools"),
+		filepath.Join(root, "web"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiscoverWorkspaceMembers = %v, want %v", got, want)
+	}
+}
+
+func TestWorkspace_MemberFor(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "services", "api")
+	nested := filepath.Join(api, "plugins", "auth")
+	ws := &Workspace{Root: root, Members: []string{api, nested}}
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{api, api},
+		{filepath.Join(api, "internal"), api},
+		{filepath.Join(nested, "src"), nested},
+		{filepath.Join(root, "services", "apiv2"), ""},
+		{root, ""},
+	}
+	for _, tc := range tests {
+		if got := ws.MemberFor(tc.dir); got != tc.want {
+			t.Errorf("MemberFor(%s) = %q, want %q", tc.dir, got, tc.want)
+		}
+	}
+	if got := ws.MemberName(api); got != filepath.Join("services", "api") {
+		t.Errorf("MemberName = %q", got)
+	}
+}
+
+func TestLoad_WorkspaceMemberInheritsRootPolicy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	api := filepath.Join(root, "api")
+	web := filepath.Join(root, "web")
+	if err := WriteWorkspace(&Workspace{Root: root, Members: []string{api, web}}); err != nil {
+		t.Fatalf("WriteWorkspace: %v", err)
+	}
+	if err := WriteValue(filepath.Join(root, ".slb", "config.toml"), "general.min_approvals", 4); err != nil {
+		t.Fatalf("WriteValue root: %v", err)
+	}
+	if err := WriteValue(filepath.Join(web, ".slb", "config.toml"), "general.min_approvals", 1); err != nil {
+		t.Fatalf("WriteValue web: %v", err)
+	}
+
+	cfg, err := Load(LoadOptions{ProjectDir: api})
+	if err != nil {
+		t.Fatalf("Load api: %v", err)
+	}
+	if cfg.General.MinApprovals != 4 {
+		t.Fatalf("api min_approvals = %d, want inherited 4", cfg.General.MinApprovals)
+	}
+
+	cfg, err = Load(LoadOptions{ProjectDir: web})
+	if err != nil {
+		t.Fatalf("Load web: %v", err)
+	}
+	if cfg.General.MinApprovals != 1 {
+		t.Fatalf("web min_approvals = %d, want overridden 1", cfg.General.MinApprovals)
+	}
+}
@@ -0,0 +1,53 @@
+// Package core implements the agent policy deciding which agents may create
+// requests.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Agent policies (agents.policy).
+const (
+	// AgentPolicyOpen lets any agent create requests, blocked ones included.
+	AgentPolicyOpen = "open"
+	// AgentPolicyAllowlist lets only agents in AllowedAgents create
+	// requests, and not if they are also blocked.
+	AgentPolicyAllowlist = "allowlist"
+	// AgentPolicyDenylist lets any agent not in BlockedAgents create
+	// requests (default).
+	AgentPolicyDenylist = "denylist"
+)
+
+// ErrAgentNotAllowed is returned under AgentPolicyAllowlist when the agent
+// is not on the allowlist.
+var ErrAgentNotAllowed = errors.New("agent is not on the allowlist")
+
+// checkAgentPolicy returns ErrAgentBlocked or ErrAgentNotAllowed when the
+// configured agent policy refuses requests from agentName. Agent names
+// match case-insensitively.
+func (rc *RequestCreator) checkAgentPolicy(agentName string) error {
+	switch rc.config.AgentPolicy {
+	case AgentPolicyOpen:
+		return nil
+	case AgentPolicyAllowlist:
+		if !agentListed(rc.config.AllowedAgents, agentName) {
+			return fmt.Errorf("%w: %s", ErrAgentNotAllowed, agentName)
+		}
+	}
+	if agentListed(rc.config.BlockedAgents, agentName) {
+		return fmt.Errorf("%w: %s", ErrAgentBlocked, agentName)
+	}
+	return nil
+}
+
+// agentListed reports whether agentName is in names.
+func agentListed(names []string, agentName string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, agentName) {
+			return true
+		}
+	}
+	return false
+}
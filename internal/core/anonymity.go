@@ -0,0 +1,142 @@
+// Package core implements reviewer anonymity for request presentation.
+package core
+
+import (
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// ReviewViewer identifies who is looking at a request's reviews. A viewer is
+// known only when resolved from an existing session (see
+// ResolveReviewViewer); the zero ReviewViewer is an unknown viewer.
+type ReviewViewer struct {
+	SessionID string
+	AgentName string
+}
+
+// ResolveReviewViewer returns the viewer behind sessionID, or the unknown
+// viewer when sessionID is empty or names no session. Names that cannot be
+// verified, such as --actor, never identify a viewer.
+func ResolveReviewViewer(database *db.DB, sessionID string) ReviewViewer {
+	if database == nil || sessionID == "" {
+		return ReviewViewer{}
+	}
+	sess, err := database.GetSession(sessionID)
+	if err != nil {
+		return ReviewViewer{}
+	}
+	return ReviewViewer{SessionID: sess.ID, AgentName: sess.AgentName}
+}
+
+// known reports whether the viewer was resolved from a session.
+func (v ReviewViewer) known() bool {
+	return v.SessionID != "" && v.AgentName != ""
+}
+
+// ReviewerAnonymity controls whether reviewer identities are hidden while a
+// request is unresolved (general.anonymize_reviewers). Anonymity is a
+// presentation concern: reviews are stored and verified with real
+// identities.
+type ReviewerAnonymity struct {
+	Enabled bool
+	// Auditors are agent names that always see reviewer identities.
+	Auditors []string
+}
+
+// HidesReviewers reports whether viewer should see pseudonyms instead of
+// the reviewers of request. It fails closed: while anonymity is on and the
+// request is unresolved, only auditors and known viewers other than the
+// requestor see the reviewers.
+func (a ReviewerAnonymity) HidesReviewers(request *db.Request, viewer ReviewViewer) bool {
+	if !a.Enabled || request == nil || requestResolved(request.Status) {
+		return false
+	}
+	if !viewer.known() {
+		return true
+	}
+	for _, auditor := range a.Auditors {
+		if strings.EqualFold(auditor, viewer.AgentName) {
+			return false
+		}
+	}
+	return viewer.SessionID == request.RequestorSessionID || viewer.AgentName == request.RequestorAgent
+}
+
+// SetViewer sets who the review listings of GetReviewStatus and
+// ReviewsForViewer are for. Without it the viewer is unknown.
+func (rs *ReviewService) SetViewer(viewer ReviewViewer) {
+	rs.viewer = viewer
+}
+
+// ReviewsForViewer returns reviews of request as the service's viewer may
+// see them: anonymized when the configured Anonymity hides the reviewers,
+// unchanged otherwise. names maps each real reviewer agent to its pseudonym
+// and is nil when nothing is hidden.
+func (rs *ReviewService) ReviewsForViewer(request *db.Request, reviews []*db.Review) ([]*db.Review, map[string]string) {
+	if !rs.config.Anonymity.HidesReviewers(request, rs.viewer) {
+		return reviews, nil
+	}
+	return AnonymizeReviews(reviews)
+}
+
+// requestResolved reports whether a request's review outcome is decided.
+func requestResolved(status db.RequestStatus) bool {
+	switch status {
+	case db.StatusQueued, db.StatusPending, db.StatusEscalated:
+		return false
+	default:
+		return true
+	}
+}
+
+// AnonymizeReviews returns copies of reviews with each reviewer replaced by
+// "reviewer A", "reviewer B", ... in the order they first reviewed. The
+// session, model and signature, which would identify the reviewer, are
+// cleared. It also returns the pseudonym of each reviewer agent, for
+// redacting messages that name them (see AnonymizeText).
+func AnonymizeReviews(reviews []*db.Review) ([]*db.Review, map[string]string) {
+	names := make(map[string]string)
+	bySession := make(map[string]string)
+	out := make([]*db.Review, 0, len(reviews))
+	for _, r := range reviews {
+		key := r.ReviewerSessionID
+		if key == "" {
+			key = r.ReviewerAgent
+		}
+		alias, ok := bySession[key]
+		if !ok {
+			alias = reviewerAlias(len(bySession))
+			bySession[key] = alias
+		}
+		if r.ReviewerAgent != "" {
+			names[r.ReviewerAgent] = alias
+		}
+		cp := *r
+		cp.ReviewerSessionID = ""
+		cp.ReviewerAgent = alias
+		cp.ReviewerModel = ""
+		cp.Signature = ""
+		out = append(out, &cp)
+	}
+	return out, names
+}
+
+// AnonymizeText replaces reviewer agent names in s with their pseudonyms.
+func AnonymizeText(s string, names map[string]string) string {
+	for name, alias := range names {
+		s = strings.ReplaceAll(s, name, alias)
+	}
+	return s
+}
+
+// reviewerAlias returns "reviewer A" for 0, ..., "reviewer Z", then
+// "reviewer AA", "reviewer AB", ...
+func reviewerAlias(n int) string {
+	label := ""
+	for n >= 0 {
+		label = string(rune('A'+n%26)) + label
+		n = n/26 - 1
+	}
+	return "reviewer " + label
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestReviewerAnonymity_HidesReviewers(t *testing.T) {
+	pending := &db.Request{Status: db.StatusPending, RequestorSessionID: "sess-req", RequestorAgent: "Requestor"}
+	approved := &db.Request{Status: db.StatusApproved, RequestorSessionID: "sess-req", RequestorAgent: "Requestor"}
+	on := ReviewerAnonymity{Enabled: true, Auditors: []string{"Auditor"}}
+
+	tests := []struct {
+		name      string
+		anonymity ReviewerAnonymity
+		request   *db.Request
+		viewer    ReviewViewer
+		want      bool
+	}{
+		{"requestor session while pending", on, pending, ReviewViewer{SessionID: "sess-req", AgentName: "Requestor"}, true},
+		{"requestor agent in another session", on, pending, ReviewViewer{SessionID: "sess-other", AgentName: "Requestor"}, true},
+		{"requestor after resolution", on, approved, ReviewViewer{SessionID: "sess-req", AgentName: "Requestor"}, false},
+		{"reviewer while pending", on, pending, ReviewViewer{SessionID: "sess-rev", AgentName: "Reviewer"}, false},
+		{"auditor", on, pending, ReviewViewer{SessionID: "sess-aud", AgentName: "auditor"}, false},
+		{"auditor name without a session", on, pending, ReviewViewer{AgentName: "Auditor"}, true},
+		{"disabled", ReviewerAnonymity{}, pending, ReviewViewer{}, false},
+		{"unknown viewer", on, pending, ReviewViewer{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.anonymity.HidesReviewers(tt.request, tt.viewer); got != tt.want {
+				t.Errorf("HidesReviewers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewService_GetReviewStatusAnonymizes(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	requestor := testutil.MakeSession(t, database, testutil.WithAgent("Requestor"))
+	reviewer := testutil.MakeSession(t, database, testutil.WithAgent("Reviewer"))
+	req := testutil.MakeRequest(t, database, requestor, testutil.WithMinApprovals(2))
+	if err := database.CreateReview(&db.Review{
+		RequestID: req.ID, ReviewerSessionID: reviewer.ID, ReviewerAgent: reviewer.AgentName, Decision: db.DecisionApprove,
+	}); err != nil {
+		t.Fatalf("CreateReview: %v", err)
+	}
+
+	config := DefaultReviewConfig()
+	config.Anonymity = ReviewerAnonymity{Enabled: true}
+	tests := []struct {
+		name   string
+		viewer ReviewViewer
+		want   string
+	}{
+		{"unknown viewer", ReviewViewer{}, "reviewer A"},
+		{"requestor", ResolveReviewViewer(database, requestor.ID), "reviewer A"},
+		{"unresolved session", ResolveReviewViewer(database, "sess-missing"), "reviewer A"},
+		{"reviewer", ResolveReviewViewer(database, reviewer.ID), "Reviewer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := NewReviewService(database, config)
+			rs.SetViewer(tt.viewer)
+			status, err := rs.GetReviewStatus(req.ID)
+			if err != nil {
+				t.Fatalf("GetReviewStatus: %v", err)
+			}
+			if len(status.Reviews) != 1 || status.Reviews[0].ReviewerAgent != tt.want {
+				t.Errorf("reviews = %+v, want reviewer %q", status.Reviews, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnonymizeReviews(t *testing.T) {
+	reviews := []*db.Review{
+		{ID: "r1", ReviewerSessionID: "s1", ReviewerAgent: "BlueLake", ReviewerModel: "m1", Signature: "sig1", Decision: db.DecisionApprove},
+		{ID: "r2", ReviewerSessionID: "s2", ReviewerAgent: "GreenHill", ReviewerModel: "m2", Signature: "sig2", Decision: db.DecisionReject},
+		{ID: "r3", ReviewerSessionID: "s1", ReviewerAgent: "BlueLake", ReviewerModel: "m1", Signature: "sig3", Decision: db.DecisionApprove},
+	}
+	got, names := AnonymizeReviews(reviews)
+
+	wantAgents := []string{"reviewer A", "reviewer B", "reviewer A"}
+	for i, r := range got {
+		if r.ReviewerAgent != wantAgents[i] {
+			t.Errorf("review %d agent = %q, want %q", i, r.ReviewerAgent, wantAgents[i])
+		}
+		if r.ReviewerSessionID != "" || r.ReviewerModel != "" || r.Signature != "" {
+			t.Errorf("review %d still identifies the reviewer: %+v", i, r)
+		}
+		if r.Decision != reviews[i].Decision {
+			t.Errorf("review %d decision changed", i)
+		}
+	}
+	if reviews[0].ReviewerAgent != "BlueLake" {
+		t.Error("AnonymizeReviews modified its input")
+	}
+
+	msg := AnonymizeText("approval by BlueLake does not count", names)
+	if strings.Contains(msg, "BlueLake") || !strings.Contains(msg, "reviewer A") {
+		t.Errorf("AnonymizeText() = %q", msg)
+	}
+}
+
+func TestReviewerAlias(t *testing.T) {
+	for n, want := range map[int]string{0: "reviewer A", 25: "reviewer Z", 26: "reviewer AA", 27: "reviewer AB"} {
+		if got := reviewerAlias(n); got != want {
+			t.Errorf("reviewerAlias(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -8,12 +8,18 @@ import (
 	"errors"
 	"fmt"
 	"image"
-	_ "image/gif"  // Register GIF format
-	_ "image/jpeg" // Register JPEG format
-	_ "image/png"  // Register PNG format
+	"image/color"
+	_ "image/gif" // Register GIF format
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -30,10 +36,44 @@ type AttachmentConfig struct {
 	// MaxCommandRuntime is the maximum runtime for context commands (default 10s).
 	// Zero means no timeout.
 	MaxCommandRuntime time.Duration
+	// FetchTimeout bounds fetching a URL attachment, body included
+	// (default 30s). Zero means no timeout.
+	FetchTimeout time.Duration
 	// MaxImageSize is the maximum dimension for images (default 4096x4096).
 	MaxImageSize int
+	// RedactPatterns are regexes masked with [REDACTED] in text attachments
+	// (files, diffs, context command output, URLs and log excerpts) before
+	// they are stored. Screenshots and other base64 data URIs are not
+	// scanned.
+	RedactPatterns []string
+	// RedactByDefault also masks the built-in sensitive-content patterns
+	// (see ApplyRedaction) in text attachments.
+	RedactByDefault bool
+	// StrictRedaction fails an attachment whose RedactPatterns do not all
+	// compile, instead of storing it with those patterns skipped.
+	StrictRedaction bool
+	// DownscaleImages re-encodes screenshots larger than MaxImageSize to fit
+	// within it, keeping their aspect ratio, instead of rejecting them.
+	DownscaleImages bool
+	// AllowBinary attaches files that look binary (see isBinaryContent) as
+	// a base64 data URI instead of rejecting them.
+	AllowBinary bool
+	// PathPrivacy controls how a file or screenshot's source path is
+	// recorded in its metadata: PathPrivacyFull (the default, also used
+	// when empty), PathPrivacyRelative or PathPrivacyBasename.
+	PathPrivacy string
+	// ProjectPath is the root PathPrivacyRelative paths are relative to.
+	ProjectPath string
 	// AllowedFileTypes restricts file types (empty means all allowed).
 	AllowedFileTypes []string
+	// MaxTotalAttachmentBytes bounds the sum of a request's attachments,
+	// including its dry-run output (default 5MB, 0 means unlimited).
+	MaxTotalAttachmentBytes int64
+	// ReservedContextBytes is the part of MaxTotalAttachmentBytes only
+	// auto-collected context (dry-run output, preview and migration
+	// evidence) may use, so agent attachments cannot crowd it out
+	// (default 1MB).
+	ReservedContextBytes int64
 }
 
 // DefaultAttachmentConfig returns default configuration.
@@ -42,12 +82,44 @@ func DefaultAttachmentConfig() AttachmentConfig {
 		MaxFileSize:       1024 * 1024, // 1MB
 		MaxOutputSize:     100 * 1024,  // 100KB
 		MaxCommandRuntime: 10 * time.Second,
+		FetchTimeout:      30 * time.Second,
 		MaxImageSize:      4096,       // 4096px
 		AllowedFileTypes:  []string{}, // Allow all
+
+		MaxTotalAttachmentBytes: 5 * 1024 * 1024, // 5MB
+		ReservedContextBytes:    1024 * 1024,     // 1MB
 	}
 }
 
 // AttachmentError represents an attachment processing error.
+// Path privacy modes for AttachmentConfig.PathPrivacy.
+const (
+	// PathPrivacyFull records the absolute path.
+	PathPrivacyFull = "full"
+	// PathPrivacyRelative records the path relative to ProjectPath, or the
+	// base name for a file outside it.
+	PathPrivacyRelative = "relative"
+	// PathPrivacyBasename records only the file name.
+	PathPrivacyBasename = "basename"
+)
+
+// sourcePath is absPath as PathPrivacy allows it to be recorded.
+func (c *AttachmentConfig) sourcePath(absPath string) string {
+	switch c.PathPrivacy {
+	case PathPrivacyBasename:
+		return filepath.Base(absPath)
+	case PathPrivacyRelative:
+		if c.ProjectPath != "" {
+			if rel, err := filepath.Rel(c.ProjectPath, absPath); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return filepath.ToSlash(rel)
+			}
+		}
+		return filepath.Base(absPath)
+	default:
+		return absPath
+	}
+}
+
 type AttachmentError struct {
 	Type    db.AttachmentType
 	Path    string
@@ -58,7 +130,186 @@ func (e *AttachmentError) Error() string {
 	return fmt.Sprintf("attachment error (%s): %s", e.Type, e.Message)
 }
 
-// LoadAttachmentFromFile reads a file and creates an attachment.
+// autoCollectedKey marks attachments SLB collected itself rather than the
+// requesting agent.
+const autoCollectedKey = "auto_collected"
+
+// MarkAutoCollected flags attachments as auto-collected context, which may
+// use the budget reserved by ReservedContextBytes.
+func MarkAutoCollected(attachments []db.Attachment) []db.Attachment {
+	marked := make([]db.Attachment, len(attachments))
+	for i, a := range attachments {
+		meta := make(map[string]any, len(a.Metadata)+1)
+		for k, v := range a.Metadata {
+			meta[k] = v
+		}
+		meta[autoCollectedKey] = true
+		a.Metadata = meta
+		marked[i] = a
+	}
+	return marked
+}
+
+// IsAutoCollected reports whether an attachment is auto-collected context.
+func IsAutoCollected(a db.Attachment) bool {
+	v, _ := a.Metadata[autoCollectedKey].(bool)
+	return v
+}
+
+// AttachmentUsage accounts for the attachment bytes of one request.
+type AttachmentUsage struct {
+	// AgentBytes is the size of the attachments the agent supplied.
+	AgentBytes int64 `json:"agent_bytes"`
+	// ContextBytes is the size of auto-collected context, dry-run output
+	// included.
+	ContextBytes int64 `json:"context_bytes"`
+	// LimitBytes is MaxTotalAttachmentBytes (0 means unlimited).
+	LimitBytes int64 `json:"limit_bytes"`
+	// ReservedBytes is the part of the limit reserved for context.
+	ReservedBytes int64 `json:"reserved_bytes"`
+}
+
+// TotalBytes is the combined size of all attachments.
+func (u AttachmentUsage) TotalBytes() int64 {
+	return u.AgentBytes + u.ContextBytes
+}
+
+// AgentLimitBytes is the part of the limit agent attachments may use.
+func (u AttachmentUsage) AgentLimitBytes() int64 {
+	return max(u.LimitBytes-u.ReservedBytes, 0)
+}
+
+// MeasureAttachments sums the attachment bytes of a request with the given
+// attachments and dry-run evidence.
+func MeasureAttachments(attachments []db.Attachment, dryRun *db.DryRunResult, config *AttachmentConfig) AttachmentUsage {
+	var u AttachmentUsage
+	if config != nil && config.MaxTotalAttachmentBytes > 0 {
+		u.LimitBytes = config.MaxTotalAttachmentBytes
+		u.ReservedBytes = max(config.ReservedContextBytes, 0)
+		if u.ReservedBytes > u.LimitBytes {
+			u.ReservedBytes = u.LimitBytes
+		}
+	}
+	for _, a := range attachments {
+		if IsAutoCollected(a) {
+			u.ContextBytes += AttachmentSize(a)
+		} else {
+			u.AgentBytes += AttachmentSize(a)
+		}
+	}
+	if dryRun != nil {
+		u.ContextBytes += int64(len(dryRun.Output))
+	}
+	return u
+}
+
+// AttachmentQuotaError reports attachments over the total attachment quota.
+type AttachmentQuotaError struct {
+	Usage AttachmentUsage
+}
+
+func (e *AttachmentQuotaError) Error() string {
+	u := e.Usage
+	if u.AgentBytes > u.AgentLimitBytes() {
+		return fmt.Sprintf("attachment quota exceeded: agent attachments total %d bytes, limit %d bytes (%d byte quota, %d reserved for auto-collected context)",
+			u.AgentBytes, u.AgentLimitBytes(), u.LimitBytes, u.ReservedBytes)
+	}
+	return fmt.Sprintf("attachment quota exceeded: attachments total %d bytes (%d agent, %d auto-collected), limit %d bytes",
+		u.TotalBytes(), u.AgentBytes, u.ContextBytes, u.LimitBytes)
+}
+
+// CheckAttachmentQuota returns an *AttachmentQuotaError when a request's
+// attachments exceed MaxTotalAttachmentBytes, or when the agent-supplied
+// ones exceed the part of it not reserved for auto-collected context. Call
+// it with every attachment the request will hold whenever attachments are
+// added, not just the new ones.
+func CheckAttachmentQuota(attachments []db.Attachment, dryRun *db.DryRunResult, config *AttachmentConfig) error {
+	u := MeasureAttachments(attachments, dryRun, config)
+	if u.LimitBytes == 0 {
+		return nil
+	}
+	if u.AgentBytes > u.AgentLimitBytes() || u.TotalBytes() > u.LimitBytes {
+		return &AttachmentQuotaError{Usage: u}
+	}
+	return nil
+}
+
+// AttachmentSize returns the bytes an attachment holds: the decoded size
+// of a base64 data URI (screenshots, binary files), otherwise the length of
+// its content.
+func AttachmentSize(a db.Attachment) int64 {
+	if strings.HasPrefix(a.Content, "data:") {
+		if i := strings.Index(a.Content, ";base64,"); i >= 0 {
+			payload := strings.TrimRight(a.Content[i+len(";base64,"):], "=")
+			return int64(base64.RawStdEncoding.DecodedLen(len(payload)))
+		}
+	}
+	return int64(len(a.Content))
+}
+
+// AttachmentBudgetError reports the attachment that took a request's
+// attachments over their total budget.
+type AttachmentBudgetError struct {
+	// Index is the attachment's position in the request's attachments.
+	Index int
+	Type  db.AttachmentType
+	// Name is the attachment's file name or source, if it has one.
+	Name string
+	// Size is the attachment's own size; Total is the running total it
+	// brought the request to.
+	Size, Total, Limit int64
+}
+
+func (e *AttachmentBudgetError) Error() string {
+	name := string(e.Type)
+	if e.Name != "" {
+		name += " " + e.Name
+	}
+	return fmt.Sprintf("attachment budget exceeded: attachment %d (%s, %d bytes) brings the total to %d bytes, limit %d bytes",
+		e.Index+1, name, e.Size, e.Total, e.Limit)
+}
+
+// ValidateAttachmentBudget returns an *AttachmentBudgetError naming the
+// first attachment whose size, added to those before it, exceeds maxTotal
+// bytes. Data URIs count at their decoded size (see AttachmentSize). A
+// maxTotal of zero or less means unlimited.
+func ValidateAttachmentBudget(atts []*db.Attachment, maxTotal int64) error {
+	if maxTotal <= 0 {
+		return nil
+	}
+	var total int64
+	for i, a := range atts {
+		if a == nil {
+			continue
+		}
+		size := AttachmentSize(*a)
+		total += size
+		if total > maxTotal {
+			return &AttachmentBudgetError{
+				Index: i,
+				Type:  a.Type,
+				Name:  attachmentLabel(*a),
+				Size:  size,
+				Total: total,
+				Limit: maxTotal,
+			}
+		}
+	}
+	return nil
+}
+
+// attachmentLabel names an attachment by its file name or source.
+func attachmentLabel(a db.Attachment) string {
+	for _, key := range []string{"filename", "path", "url", "command", "source"} {
+		if v, ok := a.Metadata[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// LoadAttachmentFromFile reads a file and creates an attachment. PDFs are
+// attached as their extracted text (see loadPDFAttachment).
 func LoadAttachmentFromFile(path string, config *AttachmentConfig) (*db.Attachment, error) {
 	if config == nil {
 		cfg := DefaultAttachmentConfig()
@@ -104,6 +355,14 @@ func LoadAttachmentFromFile(path string, config *AttachmentConfig) (*db.Attachme
 		}
 	}
 
+	if isPDFFile(absPath, content) {
+		att := loadPDFAttachment(absPath, config.sourcePath(absPath), content, info.Size())
+		if err := redactAttachment(att, path, config); err != nil {
+			return nil, err
+		}
+		return att, nil
+	}
+
 	// Detect if this is an image
 	attachType := db.AttachmentTypeFile
 	if isImageFile(absPath) {
@@ -112,24 +371,149 @@ func LoadAttachmentFromFile(path string, config *AttachmentConfig) (*db.Attachme
 		attachType = db.AttachmentTypeGitDiff
 	}
 
+	meta := map[string]any{
+		"source":   config.sourcePath(absPath),
+		"filename": filepath.Base(absPath),
+		"size":     info.Size(),
+	}
+
 	// For images, encode as base64 data URI
 	var contentStr string
-	if attachType == db.AttachmentTypeScreenshot {
+	switch {
+	case attachType == db.AttachmentTypeScreenshot:
 		mimeType := detectImageMimeType(absPath)
 		contentStr = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(content))
-	} else {
+	case isBinaryContent(content):
+		if !config.AllowBinary {
+			return nil, &AttachmentError{
+				Type:    db.AttachmentTypeFile,
+				Path:    path,
+				Message: "file looks binary; attach a hex dump (xxd file | head) or a text excerpt instead, or pass --allow-binary to attach it base64-encoded",
+			}
+		}
+		attachType = db.AttachmentTypeFile
+		mimeType := http.DetectContentType(content)
+		contentStr = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(content))
+		meta["binary"] = true
+		meta["mime_type"] = mimeType
+	default:
 		contentStr = string(content)
 	}
 
-	return &db.Attachment{
+	att := &db.Attachment{
+		Type:     attachType,
+		Content:  contentStr,
+		Metadata: meta,
+	}
+	if err := redactAttachment(att, path, config); err != nil {
+		return nil, err
+	}
+	return att, nil
+}
+
+// binarySniffLen is how much of a file isBinaryContent examines.
+const binarySniffLen = 8000
+
+// isBinaryContent reports whether content looks like binary rather than
+// text: its first binarySniffLen bytes contain a NUL byte, or more than 30%
+// of them are control characters other than whitespace. Bytes of UTF-8
+// sequences count as text.
+func isBinaryContent(content []byte) bool {
+	sample := content
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+	}
+	if len(sample) == 0 {
+		return false
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	control := 0
+	for _, b := range sample {
+		switch {
+		case b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == '\b' || b == 0x1b:
+		case b < 0x20 || b == 0x7f:
+			control++
+		}
+	}
+	return control*10 > len(sample)*3
+}
+
+// LoadAttachmentFromURL fetches an http(s) URL and creates an attachment
+// from the response body. Images become screenshot data URIs; anything else
+// is attached as context. The body is read up to MaxFileSize, and the fetch
+// fails rather than truncating a larger one.
+func LoadAttachmentFromURL(rawURL string, config *AttachmentConfig) (*db.Attachment, error) {
+	if config == nil {
+		cfg := DefaultAttachmentConfig()
+		config = &cfg
+	}
+	fail := func(format string, args ...any) error {
+		return &AttachmentError{
+			Type:    db.AttachmentTypeContext,
+			Path:    rawURL,
+			Message: fmt.Sprintf(format, args...),
+		}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fail("parsing URL: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fail("unsupported URL scheme %q (use http or https)", u.Scheme)
+	}
+
+	client := &http.Client{Timeout: config.FetchTimeout}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, fail("fetching: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fail("fetching: %s", resp.Status)
+	}
+
+	if config.MaxFileSize > 0 && resp.ContentLength > config.MaxFileSize {
+		return nil, fail("response too large: %d bytes (max %d)", resp.ContentLength, config.MaxFileSize)
+	}
+	body := io.Reader(resp.Body)
+	if config.MaxFileSize > 0 {
+		// One byte past the limit tells an oversized body from one that fits.
+		body = io.LimitReader(resp.Body, config.MaxFileSize+1)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fail("reading response: %v", err)
+	}
+	if config.MaxFileSize > 0 && int64(len(content)) > config.MaxFileSize {
+		return nil, fail("response too large: more than %d bytes", config.MaxFileSize)
+	}
+
+	finalURL := resp.Request.URL
+	contentType := resp.Header.Get("Content-Type")
+	attachType := db.AttachmentTypeContext
+	contentStr := string(content)
+	if mimeType := imageMimeTypeFromContentType(contentType, finalURL.Path); mimeType != "" {
+		attachType = db.AttachmentTypeScreenshot
+		contentStr = fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(content))
+	}
+
+	att := &db.Attachment{
 		Type:    attachType,
 		Content: contentStr,
 		Metadata: map[string]any{
-			"source":   absPath,
-			"filename": filepath.Base(absPath),
-			"size":     info.Size(),
+			"source":       rawURL,
+			"url":          finalURL.String(),
+			"content_type": contentType,
+			"size":         int64(len(content)),
 		},
-	}, nil
+	}
+	if err := redactAttachment(att, rawURL, config); err != nil {
+		return nil, err
+	}
+	return att, nil
 }
 
 // LoadScreenshot loads an image file as a screenshot attachment.
@@ -168,7 +552,7 @@ func LoadScreenshot(path string, config *AttachmentConfig) (*db.Attachment, erro
 	}
 	defer f.Close()
 
-	imgConfig, _, err := image.DecodeConfig(f)
+	imgConfig, format, err := image.DecodeConfig(f)
 	if err != nil {
 		return nil, &AttachmentError{
 			Type:    db.AttachmentTypeScreenshot,
@@ -179,6 +563,9 @@ func LoadScreenshot(path string, config *AttachmentConfig) (*db.Attachment, erro
 
 	if config.MaxImageSize > 0 {
 		if imgConfig.Width > config.MaxImageSize || imgConfig.Height > config.MaxImageSize {
+			if config.DownscaleImages {
+				return loadDownscaledScreenshot(path, absPath, config.sourcePath(absPath), format, imgConfig, config.MaxImageSize)
+			}
 			return nil, &AttachmentError{
 				Type:    db.AttachmentTypeScreenshot,
 				Path:    path,
@@ -204,7 +591,7 @@ func LoadScreenshot(path string, config *AttachmentConfig) (*db.Attachment, erro
 		Type:    db.AttachmentTypeScreenshot,
 		Content: dataURI,
 		Metadata: map[string]any{
-			"source":      absPath,
+			"source":      config.sourcePath(absPath),
 			"filename":    filepath.Base(absPath),
 			"width":       imgConfig.Width,
 			"height":      imgConfig.Height,
@@ -213,6 +600,91 @@ func LoadScreenshot(path string, config *AttachmentConfig) (*db.Attachment, erro
 	}, nil
 }
 
+// loadDownscaledScreenshot decodes an oversized image and re-encodes it to fit
+// within maxDim, as JPEG if it was one and as PNG otherwise.
+func loadDownscaledScreenshot(path, absPath, source, format string, original image.Config, maxDim int) (*db.Attachment, error) {
+	fail := func(msg string, err error) error {
+		return &AttachmentError{Type: db.AttachmentTypeScreenshot, Path: path, Message: fmt.Sprintf("%s: %v", msg, err)}
+	}
+	f, err := os.Open(absPath)
+	if err != nil {
+		return nil, fail("opening file", err)
+	}
+	defer f.Close()
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fail("decoding image", err)
+	}
+
+	width, height := scaledImageSize(original.Width, original.Height, maxDim)
+	scaled := downscaleImage(src, width, height)
+
+	var buf bytes.Buffer
+	mimeType := "image/png"
+	if format == "jpeg" {
+		mimeType = "image/jpeg"
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: 90})
+	} else {
+		err = png.Encode(&buf, scaled)
+	}
+	if err != nil {
+		return nil, fail("encoding downscaled image", err)
+	}
+
+	return &db.Attachment{
+		Type:    db.AttachmentTypeScreenshot,
+		Content: fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(buf.Bytes())),
+		Metadata: map[string]any{
+			"source":          source,
+			"filename":        filepath.Base(absPath),
+			"width":           width,
+			"height":          height,
+			"original_width":  original.Width,
+			"original_height": original.Height,
+			"downscaled":      true,
+			"description":     "",
+		},
+	}, nil
+}
+
+// scaledImageSize fits width x height within maxDim on both sides, keeping
+// the aspect ratio.
+func scaledImageSize(width, height, maxDim int) (int, int) {
+	if width <= maxDim && height <= maxDim {
+		return width, height
+	}
+	if width >= height {
+		return maxDim, max(1, (height*maxDim+width/2)/width)
+	}
+	return max(1, (width*maxDim+height/2)/height), maxDim
+}
+
+// downscaleImage resizes src to width x height by averaging the block of
+// source pixels behind each destination pixel.
+func downscaleImage(src image.Image, width, height int) image.Image {
+	b := src.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := b.Min.Y + y*srcH/height
+		y1 := max(b.Min.Y+(y+1)*srcH/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := b.Min.X + x*srcW/width
+			x1 := max(b.Min.X+(x+1)*srcW/width, x0+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
 type cappedBuffer struct {
 	max       int64
 	truncated bool
@@ -264,8 +736,21 @@ func (b *cappedBuffer) Truncated() bool {
 	return b.truncated
 }
 
-// RunContextCommand executes a command and captures output as an attachment.
+// contextCommandWaitDelay is how long a context command's output is still
+// read after it is killed for its timeout or a cancelled context.
+const contextCommandWaitDelay = 500 * time.Millisecond
+
+// RunContextCommand executes a command and captures output as an
+// attachment. The command is killed once config.MaxCommandRuntime passes or
+// ctx is done; the output read until then is kept and the metadata records
+// timed_out or cancelled.
 func RunContextCommand(ctx context.Context, command string, config *AttachmentConfig) (*db.Attachment, error) {
+	return runContextCommand(ctx, command, "", config)
+}
+
+// runContextCommand is RunContextCommand run in dir ("" for the current
+// directory).
+func runContextCommand(ctx context.Context, command, dir string, config *AttachmentConfig) (*db.Attachment, error) {
 	if config == nil {
 		cfg := DefaultAttachmentConfig()
 		config = &cfg
@@ -294,10 +779,21 @@ func RunContextCommand(ctx context.Context, command string, config *AttachmentCo
 		}
 		cmd = exec.CommandContext(execCtx, shell, "-c", command)
 	}
+	cmd.Dir = dir
 	cmd.Env = os.Environ()
+	// Killing the shell does not kill what it started, and a child still
+	// holding the output pipes would keep Wait blocked; stop waiting for
+	// them shortly after the kill and keep the output read so far.
+	cmd.WaitDelay = contextCommandWaitDelay
 
-	stdout := &cappedBuffer{max: config.MaxOutputSize}
-	stderr := &cappedBuffer{max: config.MaxOutputSize}
+	// Output is truncated only after redaction, so capture twice the limit
+	// when redacting: a secret straddling the limit is then still whole.
+	captureMax := config.MaxOutputSize
+	if config.redacting() && captureMax > 0 {
+		captureMax *= 2
+	}
+	stdout := &cappedBuffer{max: captureMax}
+	stderr := &cappedBuffer{max: captureMax}
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
@@ -316,11 +812,13 @@ func RunContextCommand(ctx context.Context, command string, config *AttachmentCo
 	}
 
 	exitCode := 0
-	timedOut := false
+	timedOut, cancelled := false, false
 	var exitErr *exec.ExitError
 	if runErr != nil {
 		if errors.Is(runErr, context.DeadlineExceeded) || errors.Is(execCtx.Err(), context.DeadlineExceeded) {
 			timedOut = true
+		} else if errors.Is(execCtx.Err(), context.Canceled) {
+			cancelled = true
 		}
 		if errors.As(runErr, &exitErr) {
 			exitCode = exitErr.ExitCode()
@@ -334,6 +832,14 @@ func RunContextCommand(ctx context.Context, command string, config *AttachmentCo
 		outputStr = runErr.Error()
 	}
 
+	redactions := -1
+	if config.redacting() {
+		var err error
+		if outputStr, redactions, err = redactAttachmentText(outputStr, config); err != nil {
+			return nil, &AttachmentError{Type: db.AttachmentTypeContext, Path: command, Message: fmt.Sprintf("redacting: %v", err)}
+		}
+	}
+
 	truncated := stdout.Truncated() || stderr.Truncated()
 	if config.MaxOutputSize > 0 && int64(len(outputStr)) > config.MaxOutputSize {
 		truncated = true
@@ -348,10 +854,16 @@ func RunContextCommand(ctx context.Context, command string, config *AttachmentCo
 	if timedOut {
 		meta["timed_out"] = true
 	}
+	if cancelled {
+		meta["cancelled"] = true
+	}
 	if truncated {
 		meta["truncated"] = true
 	}
-	if runErr != nil && (timedOut || exitCode == -1) {
+	if redactions >= 0 {
+		meta["redactions"] = redactions
+	}
+	if runErr != nil && (timedOut || cancelled || exitCode == -1) {
 		meta["error"] = runErr.Error()
 	}
 
@@ -362,16 +874,145 @@ func RunContextCommand(ctx context.Context, command string, config *AttachmentCo
 	}, nil
 }
 
-// CreateLogExcerpt creates a log excerpt attachment from a file.
+// redacting reports whether config asks for text attachments to be redacted.
+func (c *AttachmentConfig) redacting() bool {
+	return c.RedactByDefault || len(c.RedactPatterns) > 0
+}
+
+// redactAttachmentText masks config's redaction patterns in s and returns
+// the number of matches masked. Under StrictRedaction a custom pattern that
+// does not compile is returned as a *RedactionPatternError.
+func redactAttachmentText(s string, config *AttachmentConfig) (string, int, error) {
+	if config.StrictRedaction {
+		if err := ValidateRedactionPatterns(config.RedactPatterns); err != nil {
+			return "", 0, err
+		}
+	}
+	n := 0
+	if config.RedactByDefault {
+		s, n = redactPatternsCount(s, defaultRedactionPatterns)
+	}
+	s, custom := redactPatternsCount(s, config.RedactPatterns)
+	return s, n + custom, nil
+}
+
+// redactAttachment redacts a text attachment in place when config asks for
+// it, recording the number of matches masked as its "redactions" metadata.
+// Data URIs (screenshots, binary files) are left alone; diffs are redacted a
+// line at a time so a pattern cannot match across lines.
+func redactAttachment(att *db.Attachment, path string, config *AttachmentConfig) error {
+	if !config.redacting() || strings.HasPrefix(att.Content, "data:") {
+		return nil
+	}
+	var n int
+	var err error
+	if att.Type == db.AttachmentTypeGitDiff {
+		lines := strings.Split(att.Content, "\n")
+		for i, line := range lines {
+			var count int
+			if lines[i], count, err = redactAttachmentText(line, config); err != nil {
+				break
+			}
+			n += count
+		}
+		att.Content = strings.Join(lines, "\n")
+	} else {
+		att.Content, n, err = redactAttachmentText(att.Content, config)
+	}
+	if err != nil {
+		return &AttachmentError{Type: att.Type, Path: path, Message: fmt.Sprintf("redacting: %v", err)}
+	}
+	if att.Metadata == nil {
+		att.Metadata = map[string]any{}
+	}
+	att.Metadata["redactions"] = n
+	return nil
+}
+
+// ErrNoLogMatch is returned by CreateLogExcerptAroundMatch when no line of
+// the log matches the pattern.
+var ErrNoLogMatch = errors.New("no log line matches pattern")
+
+// CreateLogExcerpt creates a log excerpt attachment from a file. Lines are
+// 1-indexed and inclusive; negative line numbers count from the end of the
+// file, so -1 is the last line and startLine -50 with endLine 0 is the last
+// 50 lines. An endLine of 0 or past the end means the end of the file.
 func CreateLogExcerpt(path string, startLine, endLine int, config *AttachmentConfig) (*db.Attachment, error) {
 	if config == nil {
 		cfg := DefaultAttachmentConfig()
 		config = &cfg
 	}
 
-	absPath, err := filepath.Abs(path)
+	absPath, lines, err := readLogLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if startLine < 0 {
+		startLine += len(lines) + 1
+	}
+	if endLine < 0 {
+		endLine += len(lines) + 1
+	}
+	att := logExcerpt(absPath, lines, startLine, endLine)
+	if err := redactAttachment(att, path, config); err != nil {
+		return nil, err
+	}
+	return att, nil
+}
+
+// CreateLogExcerptAroundMatch creates a log excerpt attachment of the last
+// line matching the regular expression pattern, with up to contextLines lines
+// before and after it. It returns an error wrapping ErrNoLogMatch when no
+// line matches.
+func CreateLogExcerptAroundMatch(path, pattern string, contextLines int, config *AttachmentConfig) (*db.Attachment, error) {
+	if config == nil {
+		cfg := DefaultAttachmentConfig()
+		config = &cfg
+	}
+
+	re, err := regexp.Compile(pattern)
 	if err != nil {
 		return nil, &AttachmentError{
+			Type:    db.AttachmentTypeFile,
+			Path:    path,
+			Message: fmt.Sprintf("invalid pattern: %v", err),
+		}
+	}
+	absPath, lines, err := readLogLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	match := 0
+	for i := len(lines) - 1; i >= 0; i-- {
+		if re.MatchString(lines[i]) {
+			match = i + 1
+			break
+		}
+	}
+	if match == 0 {
+		return nil, fmt.Errorf("%w %q in %s", ErrNoLogMatch, pattern, absPath)
+	}
+	if contextLines < 0 {
+		contextLines = 0
+	}
+
+	att := logExcerpt(absPath, lines, match-contextLines, match+contextLines)
+	att.Metadata["pattern"] = pattern
+	att.Metadata["match_line"] = match
+	if err := redactAttachment(att, path, config); err != nil {
+		return nil, err
+	}
+	return att, nil
+}
+
+// readLogLines reads the log at path and splits it into lines. A final
+// newline ends the last line rather than starting an empty one.
+func readLogLines(path string) (string, []string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, &AttachmentError{
 			Type:    db.AttachmentTypeFile,
 			Path:    path,
 			Message: fmt.Sprintf("resolving path: %v", err),
@@ -380,16 +1021,20 @@ func CreateLogExcerpt(path string, startLine, endLine int, config *AttachmentCon
 
 	content, err := os.ReadFile(absPath)
 	if err != nil {
-		return nil, &AttachmentError{
+		return "", nil, &AttachmentError{
 			Type:    db.AttachmentTypeFile,
 			Path:    path,
 			Message: fmt.Sprintf("reading file: %v", err),
 		}
 	}
 
-	lines := strings.Split(string(content), "\n")
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	return absPath, lines, nil
+}
 
-	// Adjust line numbers (1-indexed to 0-indexed)
+// logExcerpt builds the attachment for lines startLine through endLine
+// (1-indexed), clamped to the file.
+func logExcerpt(absPath string, lines []string, startLine, endLine int) *db.Attachment {
 	if startLine < 1 {
 		startLine = 1
 	}
@@ -400,18 +1045,112 @@ func CreateLogExcerpt(path string, startLine, endLine int, config *AttachmentCon
 		startLine = endLine
 	}
 
-	// Extract lines
-	excerpt := strings.Join(lines[startLine-1:endLine], "\n")
-
 	return &db.Attachment{
 		Type:    db.AttachmentTypeFile, // Log excerpts are a type of file attachment
-		Content: excerpt,
+		Content: strings.Join(lines[startLine-1:endLine], "\n"),
 		Metadata: map[string]any{
 			"file":        absPath,
 			"lines":       fmt.Sprintf("%d-%d", startLine, endLine),
 			"total_lines": len(lines),
 			"type":        "log_excerpt",
 		},
+	}
+}
+
+// LoadPriorRunOutput attaches the execution log of a previously executed
+// request, so reviewers see the output (e.g. the failure) that prompted the
+// new request. The log is redacted and, when longer than MaxOutputSize,
+// trimmed from the front since errors tend to be at the end.
+func LoadPriorRunOutput(database *db.DB, requestID string, config *AttachmentConfig) (*db.Attachment, error) {
+	if config == nil {
+		cfg := DefaultAttachmentConfig()
+		config = &cfg
+	}
+
+	prior, err := database.GetRequest(requestID)
+	if err != nil {
+		if errors.Is(err, db.ErrRequestNotFound) {
+			return nil, &AttachmentError{
+				Type:    db.AttachmentTypeContext,
+				Path:    requestID,
+				Message: fmt.Sprintf("request %s not found", requestID),
+			}
+		}
+		return nil, &AttachmentError{
+			Type:    db.AttachmentTypeContext,
+			Path:    requestID,
+			Message: fmt.Sprintf("getting request %s: %v", requestID, err),
+		}
+	}
+
+	switch prior.Status {
+	case db.StatusExecuted, db.StatusExecutionFailed, db.StatusTimedOut:
+	default:
+		return nil, &AttachmentError{
+			Type:    db.AttachmentTypeContext,
+			Path:    requestID,
+			Message: fmt.Sprintf("request %s has not been executed (status %s)", requestID, prior.Status),
+		}
+	}
+	if prior.Execution == nil || prior.Execution.LogPath == "" {
+		return nil, &AttachmentError{
+			Type:    db.AttachmentTypeContext,
+			Path:    requestID,
+			Message: fmt.Sprintf("request %s has no execution log", requestID),
+		}
+	}
+
+	content, err := os.ReadFile(prior.Execution.LogPath)
+	if err != nil {
+		return nil, &AttachmentError{
+			Type:    db.AttachmentTypeContext,
+			Path:    requestID,
+			Message: fmt.Sprintf("reading execution log: %v", err),
+		}
+	}
+
+	// The log is always redacted, with the built-in patterns at least.
+	redactConfig := *config
+	redactConfig.RedactByDefault = true
+	output, redactions, err := redactAttachmentText(string(content), &redactConfig)
+	if err != nil {
+		return nil, &AttachmentError{
+			Type:    db.AttachmentTypeContext,
+			Path:    requestID,
+			Message: fmt.Sprintf("redacting: %v", err),
+		}
+	}
+	truncated := false
+	if config.MaxOutputSize > 0 && int64(len(output)) > config.MaxOutputSize {
+		truncated = true
+		output = "[truncated] ...\n" + strings.ToValidUTF8(output[int64(len(output))-config.MaxOutputSize:], "")
+	}
+
+	command := prior.Command.DisplayRedacted
+	if command == "" {
+		command = prior.Command.Raw
+	}
+	meta := map[string]any{
+		"source":     "slb request " + prior.ID,
+		"request_id": prior.ID,
+		"command":    command,
+		"status":     string(prior.Status),
+		"redactions": redactions,
+	}
+	if prior.Execution.ExitCode != nil {
+		meta["exit_code"] = *prior.Execution.ExitCode
+	}
+	if prior.Execution.ExecutedAt != nil {
+		meta["executed_at"] = prior.Execution.ExecutedAt.Format(time.RFC3339)
+	}
+	if truncated {
+		meta["truncated"] = true
+	}
+
+	return &db.Attachment{
+		Type:     db.AttachmentTypeContext,
+		Content:  output,
+		Metadata: meta,
 	}, nil
 }
 
@@ -428,35 +1167,48 @@ func CreateDiffAttachment(diffContent string, ref string) *db.Attachment {
 
 // Helper functions
 
+// imageMimeTypes maps the image extensions attachments recognize to their
+// MIME types.
+var imageMimeTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".bmp":  "image/bmp",
+	".webp": "image/webp",
+}
+
 func isImageFile(path string) bool {
-	ext := strings.ToLower(filepath.Ext(path))
-	imageExts := map[string]bool{
-		".png":  true,
-		".jpg":  true,
-		".jpeg": true,
-		".gif":  true,
-		".bmp":  true,
-		".webp": true,
-	}
-	return imageExts[ext]
+	_, ok := imageMimeTypes[strings.ToLower(filepath.Ext(path))]
+	return ok
 }
 
 func detectImageMimeType(path string) string {
-	ext := strings.ToLower(filepath.Ext(path))
-	mimeTypes := map[string]string{
-		".png":  "image/png",
-		".jpg":  "image/jpeg",
-		".jpeg": "image/jpeg",
-		".gif":  "image/gif",
-		".bmp":  "image/bmp",
-		".webp": "image/webp",
-	}
-	if mime, ok := mimeTypes[ext]; ok {
+	if mime, ok := imageMimeTypes[strings.ToLower(filepath.Ext(path))]; ok {
 		return mime
 	}
 	return "application/octet-stream"
 }
 
+// imageMimeTypeFromContentType returns the image MIME type of a fetched
+// body, or "" if it is not an image. The Content-Type header decides; a
+// missing or generic one falls back to the extension of the URL's path.
+func imageMimeTypeFromContentType(contentType, urlPath string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" || mediaType == "application/octet-stream" {
+		if isImageFile(urlPath) {
+			return detectImageMimeType(urlPath)
+		}
+		return ""
+	}
+	for _, known := range imageMimeTypes {
+		if mediaType == known {
+			return known
+		}
+	}
+	return ""
+}
+
 func isDiffFile(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	return ext == ".diff" || ext == ".patch"
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/ledongthuc/pdf"
+)
+
+// isPDFFile reports whether a file is a PDF by extension or by its header.
+func isPDFFile(path string, content []byte) bool {
+	return strings.EqualFold(filepath.Ext(path), ".pdf") || bytes.HasPrefix(content, []byte("%PDF-"))
+}
+
+// loadPDFAttachment attaches the text of a PDF rather than its bytes, with
+// pages separated by a blank line. A PDF that cannot be parsed, or has no
+// text (a scan, for instance), is attached as a note saying so. source is
+// the path recorded in the metadata.
+func loadPDFAttachment(absPath, source string, content []byte, size int64) *db.Attachment {
+	meta := map[string]any{
+		"source":   source,
+		"filename": filepath.Base(absPath),
+		"size":     size,
+		"type":     "pdf",
+	}
+	text, pages, err := extractPDFText(content)
+	if pages > 0 {
+		meta["pages"] = pages
+	}
+	switch {
+	case err != nil:
+		meta["parse_error"] = err.Error()
+		text = fmt.Sprintf("[PDF %s could not be parsed: %v]", filepath.Base(absPath), err)
+	case strings.TrimSpace(text) == "":
+		text = fmt.Sprintf("[PDF %s has no extractable text]", filepath.Base(absPath))
+	}
+	return &db.Attachment{
+		Type:     db.AttachmentTypeFile,
+		Content:  text,
+		Metadata: meta,
+	}
+}
+
+// extractPDFText returns the text of each page of a PDF and the page count.
+// The parser panics on some malformed input; that is returned as an error.
+func extractPDFText(content []byte) (text string, pages int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			text, err = "", fmt.Errorf("%v", r)
+		}
+	}()
+	r, err := pdf.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return "", 0, err
+	}
+	pages = r.NumPage()
+	fonts := make(map[string]*pdf.Font)
+	texts := make([]string, 0, pages)
+	for i := 1; i <= pages; i++ {
+		p := r.Page(i)
+		if p.V.IsNull() {
+			continue
+		}
+		for _, name := range p.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				f := p.Font(name)
+				fonts[name] = &f
+			}
+		}
+		pageText, err := p.GetPlainText(fonts)
+		if err != nil {
+			return "", pages, fmt.Errorf("page %d: %w", i, err)
+		}
+		texts = append(texts, strings.TrimSpace(pageText))
+	}
+	return strings.Join(texts, "\n\n"), pages, nil
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func TestLoadAttachmentFromFile_PDF(t *testing.T) {
+	att, err := LoadAttachmentFromFile(filepath.Join("testdata", "design.pdf"), nil)
+	if err != nil {
+		t.Fatalf("LoadAttachmentFromFile: %v", err)
+	}
+	if att.Type != db.AttachmentTypeFile {
+		t.Errorf("Type = %s, want %s", att.Type, db.AttachmentTypeFile)
+	}
+	if att.Metadata["type"] != "pdf" || att.Metadata["pages"] != 2 {
+		t.Errorf("unexpected metadata %v", att.Metadata)
+	}
+	for _, want := range []string{"drain the cache nodes first", "restore the snapshot taken at step 2"} {
+		if !strings.Contains(att.Content, want) {
+			t.Errorf("expected %q in the extracted text, got %q", want, att.Content)
+		}
+	}
+	if strings.Contains(att.Content, "%PDF") || strings.Contains(att.Content, "endobj") {
+		t.Errorf("expected text, not PDF syntax, got %q", att.Content)
+	}
+}
+
+func TestLoadAttachmentFromFile_UnparseablePDF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4\n\x00\x01\x02 not really a pdf"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	att, err := LoadAttachmentFromFile(path, nil)
+	if err != nil {
+		t.Fatalf("LoadAttachmentFromFile: %v", err)
+	}
+	if att.Metadata["type"] != "pdf" || att.Metadata["parse_error"] == nil {
+		t.Errorf("expected a parse error in the metadata, got %v", att.Metadata)
+	}
+	if !strings.HasPrefix(att.Content, "[PDF broken.pdf could not be parsed") || strings.ContainsRune(att.Content, '\x00') {
+		t.Errorf("expected a note instead of the raw bytes, got %q", att.Content)
+	}
+}
@@ -3,10 +3,14 @@ package core
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"image"
 	"image/color"
 	"image/png"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -15,6 +19,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
 )
 
 func TestDefaultAttachmentConfig(t *testing.T) {
@@ -194,6 +199,71 @@ func TestCreateLogExcerpt(t *testing.T) {
 	}
 }
 
+func TestCreateLogExcerpt_LastLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(path, []byte("l1\nl2\nl3\nl4\nl5\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	tests := []struct {
+		start, end int
+		want       string
+		lines      string
+	}{
+		{-2, 0, "l4\nl5", "4-5"},
+		{-50, 0, "l1\nl2\nl3\nl4\nl5", "1-5"},
+		{-3, -2, "l3\nl4", "3-4"},
+		{2, -1, "l2\nl3\nl4\nl5", "2-5"},
+	}
+	for _, tt := range tests {
+		att, err := CreateLogExcerpt(path, tt.start, tt.end, nil)
+		if err != nil {
+			t.Fatalf("CreateLogExcerpt(%d, %d): %v", tt.start, tt.end, err)
+		}
+		if att.Content != tt.want || att.Metadata["lines"] != tt.lines {
+			t.Errorf("CreateLogExcerpt(%d, %d) = %q (lines %v), want %q (lines %s)",
+				tt.start, tt.end, att.Content, att.Metadata["lines"], tt.want, tt.lines)
+		}
+	}
+}
+
+func TestCreateLogExcerptAroundMatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "build.log")
+	log := "start\nERROR: first\nstep 3\nstep 4\nstep 5\nERROR: disk full\nstep 7\nend\n"
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	att, err := CreateLogExcerptAroundMatch(path, `^ERROR:`, 1, nil)
+	if err != nil {
+		t.Fatalf("CreateLogExcerptAroundMatch: %v", err)
+	}
+	// The last match wins.
+	if att.Content != "step 5\nERROR: disk full\nstep 7" {
+		t.Errorf("Content = %q", att.Content)
+	}
+	if att.Metadata["match_line"] != 6 || att.Metadata["lines"] != "5-7" || att.Metadata["type"] != "log_excerpt" {
+		t.Errorf("Metadata = %v", att.Metadata)
+	}
+
+	// Context is clamped to the file.
+	att, err = CreateLogExcerptAroundMatch(path, `^start$`, 3, nil)
+	if err != nil {
+		t.Fatalf("CreateLogExcerptAroundMatch: %v", err)
+	}
+	if att.Metadata["lines"] != "1-4" {
+		t.Errorf("lines = %v, want 1-4", att.Metadata["lines"])
+	}
+
+	if _, err := CreateLogExcerptAroundMatch(path, `panic:`, 2, nil); !errors.Is(err, ErrNoLogMatch) {
+		t.Errorf("expected ErrNoLogMatch, got %v", err)
+	}
+	var attErr *AttachmentError
+	if _, err := CreateLogExcerptAroundMatch(path, `(`, 2, nil); !errors.As(err, &attErr) {
+		t.Errorf("expected an AttachmentError for an invalid pattern, got %v", err)
+	}
+}
+
 func TestLoadAttachmentFromFile_DiffAndSizeLimit(t *testing.T) {
 	dir := t.TempDir()
 
@@ -247,6 +317,60 @@ func TestLoadScreenshot_ValidAndRejectsNonImage(t *testing.T) {
 	}
 }
 
+func TestLoadScreenshot_DownscalesOversizedImages(t *testing.T) {
+	dir := t.TempDir()
+	pngPath := filepath.Join(dir, "wide.png")
+	writeTinyPNG(t, pngPath, 8000, 1000)
+
+	if _, err := LoadScreenshot(pngPath, nil); err == nil || !strings.Contains(err.Error(), "image too large") {
+		t.Fatalf("expected the default config to reject the image, got %v", err)
+	}
+
+	cfg := DefaultAttachmentConfig()
+	cfg.DownscaleImages = true
+	att, err := LoadScreenshot(pngPath, &cfg)
+	if err != nil {
+		t.Fatalf("LoadScreenshot: %v", err)
+	}
+	if att.Metadata["original_width"] != 8000 || att.Metadata["original_height"] != 1000 {
+		t.Errorf("expected the original dimensions recorded, got %v", att.Metadata)
+	}
+	if att.Metadata["width"] != 4096 || att.Metadata["height"] != 512 {
+		t.Errorf("expected 4096x512, got %vx%v", att.Metadata["width"], att.Metadata["height"])
+	}
+
+	const prefix = "data:image/png;base64,"
+	if !strings.HasPrefix(att.Content, prefix) {
+		t.Fatalf("unexpected data URI prefix: %q", att.Content[:min(len(att.Content), 24)])
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(att.Content, prefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("decoding the downscaled image: %v", err)
+	}
+	if decoded.Width > 4096 || decoded.Height > 4096 {
+		t.Errorf("expected the encoded image within 4096px, got %dx%d", decoded.Width, decoded.Height)
+	}
+}
+
+func TestScaledImageSize(t *testing.T) {
+	cases := []struct{ w, h, max, wantW, wantH int }{
+		{8000, 1000, 4096, 4096, 512},
+		{1000, 8000, 4096, 512, 4096},
+		{5000, 5000, 4096, 4096, 4096},
+		{100, 50, 4096, 100, 50},
+		{10000, 1, 4096, 4096, 1},
+	}
+	for _, tc := range cases {
+		if w, h := scaledImageSize(tc.w, tc.h, tc.max); w != tc.wantW || h != tc.wantH {
+			t.Errorf("scaledImageSize(%d, %d, %d) = %dx%d, want %dx%d", tc.w, tc.h, tc.max, w, h, tc.wantW, tc.wantH)
+		}
+	}
+}
+
 func TestLoadAttachmentFromFile_ImageBecomesScreenshot(t *testing.T) {
 	dir := t.TempDir()
 	pngPath := filepath.Join(dir, "x.png")
@@ -264,6 +388,106 @@ func TestLoadAttachmentFromFile_ImageBecomesScreenshot(t *testing.T) {
 	}
 }
 
+func TestLoadAttachmentFromURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/notes.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("runbook notes"))
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/notes.txt", http.StatusFound)
+	})
+	mux.HandleFunc("/dashboard", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("\x89PNG"))
+	})
+	mux.HandleFunc("/raw/graph.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte("\x89PNG"))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	att, err := LoadAttachmentFromURL(srv.URL+"/moved", nil)
+	if err != nil {
+		t.Fatalf("LoadAttachmentFromURL(text): %v", err)
+	}
+	if att.Type != db.AttachmentTypeContext || att.Content != "runbook notes" {
+		t.Fatalf("unexpected text attachment: %+v", att)
+	}
+	if att.Metadata["source"] != srv.URL+"/moved" || att.Metadata["url"] != srv.URL+"/notes.txt" {
+		t.Fatalf("expected source and final URL in metadata, got %v", att.Metadata)
+	}
+	if att.Metadata["content_type"] != "text/plain; charset=utf-8" || att.Metadata["size"] != int64(13) {
+		t.Fatalf("unexpected metadata: %v", att.Metadata)
+	}
+
+	for _, path := range []string{"/dashboard", "/raw/graph.png"} {
+		att, err := LoadAttachmentFromURL(srv.URL+path, nil)
+		if err != nil {
+			t.Fatalf("LoadAttachmentFromURL(%s): %v", path, err)
+		}
+		if att.Type != db.AttachmentTypeScreenshot || !strings.HasPrefix(att.Content, "data:image/png;base64,") {
+			t.Fatalf("%s: expected a PNG screenshot data URI, got %s %q", path, att.Type, att.Content)
+		}
+	}
+
+	if _, err := LoadAttachmentFromURL(srv.URL+"/missing", nil); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected a 404 error, got %v", err)
+	}
+}
+
+func TestLoadAttachmentFromURL_SizeLimit(t *testing.T) {
+	body := strings.Repeat("x", 64)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// Flushing before writing the body drops Content-Length, so
+			// only the streamed read can catch the size.
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	cfg := &AttachmentConfig{MaxFileSize: 32}
+	for _, path := range []string{"/sized", "/chunked"} {
+		_, err := LoadAttachmentFromURL(srv.URL+path, cfg)
+		var attErr *AttachmentError
+		if !errors.As(err, &attErr) || !strings.Contains(attErr.Message, "too large") {
+			t.Fatalf("%s: expected a size limit error, got %v", path, err)
+		}
+	}
+
+	cfg.MaxFileSize = 64
+	if _, err := LoadAttachmentFromURL(srv.URL+"/chunked", cfg); err != nil {
+		t.Fatalf("expected a body at the limit to load, got %v", err)
+	}
+}
+
+func TestLoadAttachmentFromURL_RejectsOtherSchemes(t *testing.T) {
+	for _, u := range []string{"file:///etc/passwd", "ftp://example.com/x", "/relative/path"} {
+		if _, err := LoadAttachmentFromURL(u, nil); err == nil || !strings.Contains(err.Error(), "unsupported URL scheme") {
+			t.Errorf("%s: expected an unsupported scheme error, got %v", u, err)
+		}
+	}
+}
+
+func TestLoadAttachmentFromURL_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := DefaultAttachmentConfig()
+	cfg.FetchTimeout = 50 * time.Millisecond
+	if _, err := LoadAttachmentFromURL(srv.URL, &cfg); err == nil {
+		t.Fatal("expected the fetch to time out")
+	}
+}
+
 func TestRunContextCommand_BasicAndTruncation(t *testing.T) {
 	cfg := DefaultAttachmentConfig()
 	cfg.MaxCommandRuntime = 0
@@ -299,6 +523,170 @@ func TestRunContextCommand_BasicAndTruncation(t *testing.T) {
 	}
 }
 
+func TestRunContextCommand_Redaction(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh printf")
+	}
+	const cmd = `printf 'api_key=sk-live-123456\nbuild id 42\n'; printf 'ticket ABC-991\n' 1>&2`
+
+	cfg := DefaultAttachmentConfig()
+	att, err := RunContextCommand(context.Background(), cmd, &cfg)
+	if err != nil {
+		t.Fatalf("RunContextCommand: %v", err)
+	}
+	if !strings.Contains(att.Content, "sk-live-123456") {
+		t.Fatalf("expected output kept verbatim without redaction, got %q", att.Content)
+	}
+
+	cfg.RedactByDefault = true
+	cfg.RedactPatterns = []string{`ABC-[0-9]+`}
+	att, err = RunContextCommand(context.Background(), cmd, &cfg)
+	if err != nil {
+		t.Fatalf("RunContextCommand: %v", err)
+	}
+	if strings.Contains(att.Content, "sk-live-123456") || strings.Contains(att.Content, "ABC-991") {
+		t.Errorf("expected the API key and custom pattern redacted, got %q", att.Content)
+	}
+	if strings.Count(att.Content, "[REDACTED]") != 2 || !strings.Contains(att.Content, "build id 42") {
+		t.Errorf("expected two redactions and the rest kept, got %q", att.Content)
+	}
+	if got := att.Metadata["redactions"]; got != 2 {
+		t.Errorf("redactions = %v, want 2", got)
+	}
+
+	// Custom patterns alone leave the built-in ones off.
+	cfg.RedactByDefault = false
+	att, err = RunContextCommand(context.Background(), cmd, &cfg)
+	if err != nil {
+		t.Fatalf("RunContextCommand: %v", err)
+	}
+	if !strings.Contains(att.Content, "sk-live-123456") || strings.Contains(att.Content, "ABC-991") {
+		t.Errorf("expected only the custom pattern redacted, got %q", att.Content)
+	}
+}
+
+func TestRunContextCommand_RedactsBeforeTruncating(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh printf")
+	}
+	cfg := DefaultAttachmentConfig()
+	cfg.RedactByDefault = true
+	// The limit falls inside the key, which must not survive in part.
+	cfg.MaxOutputSize = 20
+	att, err := RunContextCommand(context.Background(), `printf 'ok\ntoken=abcdefghijklmnopqrstuvwxyz\n'`, &cfg)
+	if err != nil {
+		t.Fatalf("RunContextCommand: %v", err)
+	}
+	if strings.Contains(att.Content, "abcdef") {
+		t.Errorf("expected the token redacted before truncation, got %q", att.Content)
+	}
+	if att.Content != "ok\n[REDACTED]\n" {
+		t.Errorf("expected the redacted output to fit the limit, got %q", att.Content)
+	}
+}
+
+func TestLoadAttachmentFromFile_Redaction(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	envFile := write("app.env", "PORT=8080\nAPI_KEY=sk-live-123456\nDB=postgres://admin:hunter2@db/app\n")
+	diffFile := write("config.diff", "--- a/config.yml\n+++ b/config.yml\n-password: old-pass\n+password: new-pass\n BEGIN\n END\n")
+
+	cfg := DefaultAttachmentConfig()
+	att, err := LoadAttachmentFromFile(envFile, &cfg)
+	if err != nil {
+		t.Fatalf("LoadAttachmentFromFile: %v", err)
+	}
+	if !strings.Contains(att.Content, "sk-live-123456") {
+		t.Errorf("expected content kept verbatim without redaction, got %q", att.Content)
+	}
+	if _, ok := att.Metadata["redactions"]; ok {
+		t.Errorf("expected no redactions metadata without redaction, got %v", att.Metadata)
+	}
+
+	cfg.RedactByDefault = true
+	att, err = LoadAttachmentFromFile(envFile, &cfg)
+	if err != nil {
+		t.Fatalf("LoadAttachmentFromFile: %v", err)
+	}
+	if strings.Contains(att.Content, "sk-live-123456") || strings.Contains(att.Content, "hunter2") || !strings.Contains(att.Content, "PORT=8080") {
+		t.Errorf("expected the key and credentials redacted, got %q", att.Content)
+	}
+	if got := att.Metadata["redactions"]; got != 2 {
+		t.Errorf("redactions = %v, want 2", got)
+	}
+
+	// Diffs are redacted a line at a time: the multi-line custom pattern
+	// cannot match, while each password line is masked.
+	cfg.RedactPatterns = []string{`(?s)BEGIN.*END`}
+	att, err = LoadAttachmentFromFile(diffFile, &cfg)
+	if err != nil {
+		t.Fatalf("LoadAttachmentFromFile: %v", err)
+	}
+	if att.Type != db.AttachmentTypeGitDiff {
+		t.Fatalf("type = %s, want git_diff", att.Type)
+	}
+	if strings.Contains(att.Content, "old-pass") || strings.Contains(att.Content, "new-pass") || !strings.Contains(att.Content, " BEGIN\n END") {
+		t.Errorf("expected both password lines masked and nothing else, got %q", att.Content)
+	}
+	if got := att.Metadata["redactions"]; got != 2 {
+		t.Errorf("redactions = %v, want 2", got)
+	}
+
+	// Base64 payloads are not scanned.
+	cfg.AllowBinary = true
+	binFile := write("blob.bin", "\x00\x01token=abc")
+	att, err = LoadAttachmentFromFile(binFile, &cfg)
+	if err != nil {
+		t.Fatalf("LoadAttachmentFromFile: %v", err)
+	}
+	if !strings.HasPrefix(att.Content, "data:") || att.Metadata["redactions"] != nil {
+		t.Errorf("expected the binary file attached unscanned, got %q %v", att.Content, att.Metadata)
+	}
+}
+
+func TestAttachmentRedaction_Strict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("token=abc123\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultAttachmentConfig()
+	cfg.RedactByDefault = true
+	cfg.RedactPatterns = []string{`[invalid`}
+
+	// By default the bad pattern is skipped and the rest still applies.
+	att, err := LoadAttachmentFromFile(path, &cfg)
+	if err != nil {
+		t.Fatalf("LoadAttachmentFromFile: %v", err)
+	}
+	if strings.Contains(att.Content, "abc123") {
+		t.Errorf("expected the built-in patterns still applied, got %q", att.Content)
+	}
+
+	cfg.StrictRedaction = true
+	if _, err := LoadAttachmentFromFile(path, &cfg); err == nil || !strings.Contains(err.Error(), "invalid redaction pattern") {
+		t.Errorf("expected a redaction error for a file, got %v", err)
+	}
+	if _, err := RunContextCommand(context.Background(), "echo hi", &cfg); err == nil || !strings.Contains(err.Error(), "invalid redaction pattern") {
+		t.Errorf("expected a redaction error for a context command, got %v", err)
+	}
+	var patternErr *RedactionPatternError
+	if _, err := CreateLogExcerpt(path, 1, 1, &cfg); !errors.As(err, new(*AttachmentError)) || !strings.Contains(err.Error(), `"[invalid"`) {
+		t.Errorf("expected an attachment error naming the pattern for a log excerpt, got %v", err)
+	}
+	if err := ValidateRedactionPatterns(cfg.RedactPatterns); !errors.As(err, &patternErr) || patternErr.Pattern != "[invalid" {
+		t.Errorf("ValidateRedactionPatterns = %v, want a *RedactionPatternError", err)
+	}
+	if err := ValidateRedactionPatterns([]string{`token-[0-9]+`}); err != nil {
+		t.Errorf("ValidateRedactionPatterns(valid) = %v", err)
+	}
+}
+
 func TestRunContextCommand_Timeout(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("skip timeout test on windows")
@@ -316,6 +704,54 @@ func TestRunContextCommand_Timeout(t *testing.T) {
 	}
 }
 
+func TestRunContextCommand_TimeoutKeepsPartialOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skip timeout test on windows")
+	}
+
+	cfg := DefaultAttachmentConfig()
+	cfg.MaxCommandRuntime = 200 * time.Millisecond
+
+	// sleep is not the last command, so the shell forks it rather than
+	// exec'ing it; it outlives the killed shell and keeps the output pipe
+	// open.
+	start := time.Now()
+	att, err := RunContextCommand(context.Background(), "echo partial; sleep 60; echo done", &cfg)
+	if err != nil {
+		t.Fatalf("RunContextCommand(timeout): %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected to return shortly after the timeout, took %s", elapsed)
+	}
+	if att.Metadata["timed_out"] != true {
+		t.Errorf("expected timed_out metadata, got %v", att.Metadata)
+	}
+	if !strings.Contains(att.Content, "partial") {
+		t.Errorf("expected the partial output kept, got %q", att.Content)
+	}
+}
+
+func TestRunContextCommand_Cancelled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skip cancellation test on windows")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	att, err := RunContextCommand(ctx, "sleep 60", nil)
+	if err != nil {
+		t.Fatalf("RunContextCommand(cancelled): %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected to return shortly after cancellation, took %s", elapsed)
+	}
+	if att.Metadata["cancelled"] != true || att.Metadata["timed_out"] != nil {
+		t.Errorf("expected cancelled (not timed_out) metadata, got %v", att.Metadata)
+	}
+}
+
 func writeTinyPNG(t *testing.T, path string, width, height int) {
 	t.Helper()
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -445,6 +881,127 @@ func TestLoadAttachmentFromFile_Errors(t *testing.T) {
 	})
 }
 
+func TestIsBinaryContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{"empty", nil, false},
+		{"plain text", []byte("deploy notes\n\tstep 1: drain\r\n"), false},
+		{"utf-8 text", []byte("café — naïve résumé ✓\n"), false},
+		{"ansi colored log", []byte("\x1b[31merror\x1b[0m: disk full\n"), false},
+		{"nul byte", []byte("text\x00more text"), true},
+		{"mostly control bytes", []byte("\x01\x02\x03\x04ab"), true},
+		{"a few control bytes", []byte("\x07 bell in an otherwise normal line of text"), false},
+		{"nul past the sniffed prefix", append(bytes.Repeat([]byte("a"), binarySniffLen), 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBinaryContent(tt.content); got != tt.want {
+				t.Errorf("isBinaryContent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAttachmentFromFile_Binary(t *testing.T) {
+	path := filepath.Join("testdata", "hello.bin")
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadAttachmentFromFile(path, nil)
+	var ae *AttachmentError
+	if !errors.As(err, &ae) || !strings.Contains(ae.Message, "looks binary") || !strings.Contains(ae.Message, "hex dump") {
+		t.Fatalf("expected a binary AttachmentError suggesting a hex dump, got %v", err)
+	}
+
+	cfg := DefaultAttachmentConfig()
+	cfg.AllowBinary = true
+	att, err := LoadAttachmentFromFile(path, &cfg)
+	if err != nil {
+		t.Fatalf("LoadAttachmentFromFile with AllowBinary: %v", err)
+	}
+	if att.Type != db.AttachmentTypeFile || att.Metadata["binary"] != true {
+		t.Errorf("expected a binary file attachment, got type %s metadata %v", att.Type, att.Metadata)
+	}
+	prefix := "data:application/octet-stream;base64,"
+	if !strings.HasPrefix(att.Content, prefix) {
+		t.Fatalf("expected a base64 data URI, got %.60q", att.Content)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(att.Content, prefix))
+	if err != nil || !bytes.Equal(decoded, raw) {
+		t.Errorf("data URI does not decode to the file (%v)", err)
+	}
+	if got := AttachmentSize(*att); got != int64(len(raw)) {
+		t.Errorf("AttachmentSize = %d, want %d", got, len(raw))
+	}
+}
+
+func TestLoadAttachmentFromFile_PathPrivacy(t *testing.T) {
+	project := t.TempDir()
+	inside := filepath.Join(project, "deploy", "notes.txt")
+	outside := filepath.Join(t.TempDir(), "secret-home", "plan.txt")
+	for _, p := range []string{inside, outside} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("drain first\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		mode, path, want string
+	}{
+		{"", inside, inside},
+		{PathPrivacyFull, inside, inside},
+		{PathPrivacyBasename, inside, "notes.txt"},
+		{PathPrivacyRelative, inside, "deploy/notes.txt"},
+		{PathPrivacyRelative, outside, "plan.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+filepath.Base(tt.path), func(t *testing.T) {
+			cfg := DefaultAttachmentConfig()
+			cfg.PathPrivacy = tt.mode
+			cfg.ProjectPath = project
+			att, err := LoadAttachmentFromFile(tt.path, &cfg)
+			if err != nil {
+				t.Fatalf("LoadAttachmentFromFile: %v", err)
+			}
+			if got := att.Metadata["source"]; got != tt.want {
+				t.Errorf("source = %v, want %s", got, tt.want)
+			}
+			if tt.want == tt.path {
+				return
+			}
+			meta, _ := json.Marshal(att.Metadata)
+			if strings.Contains(string(meta), filepath.Dir(tt.path)) {
+				t.Errorf("metadata leaks the absolute path: %s", meta)
+			}
+		})
+	}
+
+	// Screenshots follow the same setting.
+	img := filepath.Join(project, "shots", "before.png")
+	if err := os.MkdirAll(filepath.Dir(img), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTinyPNG(t, img, 4, 4)
+	cfg := DefaultAttachmentConfig()
+	cfg.PathPrivacy = PathPrivacyRelative
+	cfg.ProjectPath = project
+	att, err := LoadScreenshot(img, &cfg)
+	if err != nil {
+		t.Fatalf("LoadScreenshot: %v", err)
+	}
+	if got := att.Metadata["source"]; got != "shots/before.png" {
+		t.Errorf("screenshot source = %v, want shots/before.png", got)
+	}
+}
+
 func TestLoadScreenshot_Errors(t *testing.T) {
 	t.Run("file not found", func(t *testing.T) {
 		_, err := LoadScreenshot("/nonexistent/image.png", nil)
@@ -598,3 +1155,150 @@ func TestCappedBuffer_Write(t *testing.T) {
 		}
 	})
 }
+
+func TestMeasureAttachments(t *testing.T) {
+	config := &AttachmentConfig{MaxTotalAttachmentBytes: 100, ReservedContextBytes: 500}
+	attachments := append([]db.Attachment{{Content: "agent"}}, MarkAutoCollected([]db.Attachment{{Content: "ctx", Metadata: map[string]any{"source": "slb preview"}}})...)
+	u := MeasureAttachments(attachments, &db.DryRunResult{Output: "dry"}, config)
+
+	if u.AgentBytes != 5 || u.ContextBytes != 6 || u.TotalBytes() != 11 {
+		t.Errorf("usage = %+v, want 5 agent and 6 context bytes", u)
+	}
+	if u.ReservedBytes != 100 || u.AgentLimitBytes() != 0 {
+		t.Errorf("reserve larger than the limit should be capped: %+v", u)
+	}
+	if !IsAutoCollected(attachments[1]) || attachments[1].Metadata["source"] != "slb preview" {
+		t.Errorf("MarkAutoCollected should keep existing metadata: %v", attachments[1].Metadata)
+	}
+	if IsAutoCollected(attachments[0]) {
+		t.Error("agent attachment reported as auto-collected")
+	}
+	if err := CheckAttachmentQuota(attachments, nil, &AttachmentConfig{}); err != nil {
+		t.Errorf("zero quota should be unlimited, got %v", err)
+	}
+}
+
+func TestAttachmentSize(t *testing.T) {
+	dataURI := func(n int) string {
+		return "data:image/png;base64," + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0xff}, n))
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    int64
+	}{
+		{"plain text", "hello", 5},
+		{"data URI without padding", dataURI(300), 300},
+		{"data URI with one pad", dataURI(301), 301},
+		{"data URI with two pads", dataURI(302), 302},
+		{"empty data URI", "data:image/png;base64,", 0},
+		{"data URI that is not base64", "data:text/plain,hello", int64(len("data:text/plain,hello"))},
+	}
+	for _, tt := range tests {
+		if got := AttachmentSize(db.Attachment{Content: tt.content}); got != tt.want {
+			t.Errorf("%s: AttachmentSize() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateAttachmentBudget(t *testing.T) {
+	screenshot := &db.Attachment{
+		Type:     db.AttachmentTypeScreenshot,
+		Content:  "data:image/png;base64," + base64.StdEncoding.EncodeToString(make([]byte, 600)),
+		Metadata: map[string]any{"filename": "dashboard.png"},
+	}
+	notes := &db.Attachment{Type: db.AttachmentTypeFile, Content: strings.Repeat("x", 300)}
+
+	// The screenshot's data URI is 822 characters but holds 600 bytes.
+	if err := ValidateAttachmentBudget([]*db.Attachment{screenshot, nil, notes}, 900); err != nil {
+		t.Errorf("expected 900 bytes to fit the budget, got %v", err)
+	}
+	if err := ValidateAttachmentBudget([]*db.Attachment{screenshot, notes}, 0); err != nil {
+		t.Errorf("zero budget should be unlimited, got %v", err)
+	}
+
+	err := ValidateAttachmentBudget([]*db.Attachment{notes, screenshot, notes}, 800)
+	var budgetErr *AttachmentBudgetError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected *AttachmentBudgetError, got %v", err)
+	}
+	if budgetErr.Index != 1 || budgetErr.Size != 600 || budgetErr.Total != 900 || budgetErr.Limit != 800 {
+		t.Errorf("unexpected error %+v", budgetErr)
+	}
+	if want := "attachment 2 (screenshot dashboard.png, 600 bytes) brings the total to 900 bytes, limit 800 bytes"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+}
+
+func TestLoadPriorRunOutput(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+
+	executed := func(t *testing.T, status db.RequestStatus, log string) *db.Request {
+		t.Helper()
+		req := testutil.MakeRequest(t, database, session,
+			testutil.WithCommand("make test", "/tmp", true),
+			testutil.WithStatus(status))
+		logPath := filepath.Join(t.TempDir(), "exec.log")
+		if err := os.WriteFile(logPath, []byte(log), 0600); err != nil {
+			t.Fatal(err)
+		}
+		exitCode := 2
+		now := time.Now()
+		if err := database.UpdateRequestExecution(req.ID, &db.Execution{LogPath: logPath, ExitCode: &exitCode, ExecutedAt: &now}); err != nil {
+			t.Fatalf("UpdateRequestExecution() error = %v", err)
+		}
+		return req
+	}
+
+	t.Run("attaches the execution output", func(t *testing.T) {
+		req := executed(t, db.StatusExecutionFailed, "FAIL: TestWidget\nexpected 3, got 4\n")
+		att, err := LoadPriorRunOutput(database, req.ID, nil)
+		if err != nil {
+			t.Fatalf("LoadPriorRunOutput() error = %v", err)
+		}
+		if att.Type != db.AttachmentTypeContext || !strings.Contains(att.Content, "expected 3, got 4") {
+			t.Errorf("attachment = %+v", att)
+		}
+		if att.Metadata["request_id"] != req.ID || att.Metadata["command"] != "make test" || att.Metadata["exit_code"] != 2 {
+			t.Errorf("metadata = %v", att.Metadata)
+		}
+	})
+
+	t.Run("keeps the end of long output", func(t *testing.T) {
+		req := executed(t, db.StatusExecuted, strings.Repeat("noise\n", 100)+"the real error")
+		att, err := LoadPriorRunOutput(database, req.ID, &AttachmentConfig{MaxOutputSize: 50})
+		if err != nil {
+			t.Fatalf("LoadPriorRunOutput() error = %v", err)
+		}
+		if !strings.HasSuffix(att.Content, "the real error") || att.Metadata["truncated"] != true {
+			t.Errorf("expected the tail with a truncation marker, got %q (%v)", att.Content, att.Metadata)
+		}
+	})
+
+	errorCases := []struct {
+		name    string
+		id      func(t *testing.T) string
+		wantMsg string
+	}{
+		{"unknown request", func(t *testing.T) string { return "req-missing" }, "request req-missing not found"},
+		{"pending request", func(t *testing.T) string {
+			return testutil.MakeRequest(t, database, session).ID
+		}, "has not been executed (status pending)"},
+		{"executed without a log", func(t *testing.T) string {
+			return testutil.MakeRequest(t, database, session, testutil.WithStatus(db.StatusExecuted)).ID
+		}, "has no execution log"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadPriorRunOutput(database, tc.id(t), nil)
+			var attErr *AttachmentError
+			if !errors.As(err, &attErr) {
+				t.Fatalf("expected *AttachmentError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Errorf("error = %q, want it to contain %q", err, tc.wantMsg)
+			}
+		})
+	}
+}
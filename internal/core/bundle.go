@@ -0,0 +1,278 @@
+// Package core implements portable audit bundles for a single request.
+package core
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Well-known files in an audit bundle.
+const (
+	BundleManifestName   = "manifest.json"
+	BundleSignaturesName = "signatures.json"
+	bundleVersion        = 1
+)
+
+// Signature check results recorded in a bundle.
+const (
+	SignatureValid        = "valid"
+	SignatureInvalid      = "invalid"
+	SignatureUnverifiable = "unverifiable"
+)
+
+// ErrInvalidBundle is returned when a bundle cannot be read at all.
+var ErrInvalidBundle = errors.New("invalid audit bundle")
+
+// BundleFile is one file to place in an audit bundle.
+type BundleFile struct {
+	Name string
+	Data []byte
+}
+
+// BundleManifestEntry records the size and SHA-256 of one bundled file.
+type BundleManifestEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BundleManifest lists every file in a bundle except itself.
+type BundleManifest struct {
+	Version   int                   `json:"version"`
+	RequestID string                `json:"request_id"`
+	CreatedAt time.Time             `json:"created_at"`
+	Files     []BundleManifestEntry `json:"files"`
+}
+
+// BundleSignature is the result of checking one review signature against the
+// reviewer's session key when the bundle was written. Keys are never bundled,
+// so the HMAC cannot be recomputed offline.
+type BundleSignature struct {
+	ReviewID      string `json:"review_id"`
+	ReviewerAgent string `json:"reviewer_agent"`
+	Decision      string `json:"decision"`
+	Signature     string `json:"signature"`
+	Result        string `json:"result"`
+	Detail        string `json:"detail,omitempty"`
+}
+
+// BundleVerification is the outcome of VerifyBundle.
+type BundleVerification struct {
+	RequestID  string            `json:"request_id"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Files      int               `json:"files"`
+	Signatures []BundleSignature `json:"signatures,omitempty"`
+	Problems   []string          `json:"problems,omitempty"`
+}
+
+// OK reports whether the bundle verified without problems.
+func (v *BundleVerification) OK() bool {
+	return len(v.Problems) == 0
+}
+
+// CheckReviewSignatures verifies each review's signature with its reviewer's
+// session key. Reviews whose session is gone are reported as unverifiable.
+func CheckReviewSignatures(database *db.DB, reviews []*db.Review) []BundleSignature {
+	results := make([]BundleSignature, 0, len(reviews))
+	for _, r := range reviews {
+		sig := BundleSignature{
+			ReviewID:      r.ID,
+			ReviewerAgent: r.ReviewerAgent,
+			Decision:      string(r.Decision),
+			Signature:     r.Signature,
+		}
+		sess, err := database.GetSession(r.ReviewerSessionID)
+		switch {
+		case err != nil:
+			sig.Result = SignatureUnverifiable
+			sig.Detail = "reviewer session not found"
+		case VerifyReview(r, sess.SessionKey):
+			sig.Result = SignatureValid
+		default:
+			sig.Result = SignatureInvalid
+		}
+		results = append(results, sig)
+	}
+	return results
+}
+
+// RedactBundleText masks a request's command and known secret patterns in
+// text bound for a bundle. The raw command is replaced by its redacted
+// display form wherever it appears, then the default redaction patterns run.
+func RedactBundleText(text string, cmd db.CommandSpec) string {
+	display := BundleCommand(cmd)
+	if cmd.Raw != "" && cmd.Raw != display {
+		text = strings.ReplaceAll(text, cmd.Raw, display)
+	}
+	return ApplyRedaction(text, nil)
+}
+
+// BundleCommand returns the form of a command that may appear in a bundle.
+func BundleCommand(cmd db.CommandSpec) string {
+	if cmd.DisplayRedacted != "" {
+		return cmd.DisplayRedacted
+	}
+	return ApplyRedaction(cmd.Raw, nil)
+}
+
+// WriteBundle writes files as a gzipped tar with a manifest of their hashes.
+// File names must be relative slash paths and unique.
+func WriteBundle(w io.Writer, requestID string, files []BundleFile, now time.Time) (*BundleManifest, error) {
+	manifest := &BundleManifest{
+		Version:   bundleVersion,
+		RequestID: requestID,
+		CreatedAt: now.UTC(),
+		Files:     make([]BundleManifestEntry, 0, len(files)),
+	}
+	seen := make(map[string]bool, len(files))
+	for _, f := range files {
+		if err := validBundleName(f.Name); err != nil {
+			return nil, err
+		}
+		if seen[f.Name] {
+			return nil, fmt.Errorf("duplicate bundle file %q", f.Name)
+		}
+		seen[f.Name] = true
+		sum := sha256.Sum256(f.Data)
+		manifest.Files = append(manifest.Files, BundleManifestEntry{
+			Name:   f.Name,
+			Size:   int64(len(f.Data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal bundle manifest: %w", err)
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	all := append([]BundleFile{{Name: BundleManifestName, Data: manifestData}}, files...)
+	for _, f := range all {
+		hdr := &tar.Header{
+			Name:     f.Name,
+			Mode:     0600,
+			Size:     int64(len(f.Data)),
+			ModTime:  manifest.CreatedAt,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, fmt.Errorf("write tar header: %w", err)
+		}
+		if _, err := tw.Write(f.Data); err != nil {
+			return nil, fmt.Errorf("write tar body: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("closing tar: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("closing gzip: %w", err)
+	}
+	return manifest, nil
+}
+
+// VerifyBundle re-checks a bundle offline: every file must match the size and
+// hash in the manifest, nothing may be missing or extra, and every recorded
+// review signature must have been valid when the bundle was written.
+func VerifyBundle(r io.Reader) (*BundleVerification, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	defer gr.Close()
+
+	contents := make(map[string][]byte)
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("%w: unexpected entry %q", ErrInvalidBundle, hdr.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: reading %s: %v", ErrInvalidBundle, hdr.Name, err)
+		}
+		contents[hdr.Name] = data
+	}
+
+	manifestData, ok := contents[BundleManifestName]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidBundle, BundleManifestName)
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: parsing manifest: %v", ErrInvalidBundle, err)
+	}
+
+	v := &BundleVerification{
+		RequestID: manifest.RequestID,
+		CreatedAt: manifest.CreatedAt,
+		Files:     len(manifest.Files),
+	}
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, entry := range manifest.Files {
+		listed[entry.Name] = true
+		data, ok := contents[entry.Name]
+		if !ok {
+			v.Problems = append(v.Problems, fmt.Sprintf("%s: missing", entry.Name))
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != entry.Size || hex.EncodeToString(sum[:]) != entry.SHA256 {
+			v.Problems = append(v.Problems, fmt.Sprintf("%s: hash mismatch", entry.Name))
+		}
+	}
+	var extra []string
+	for name := range contents {
+		if name != BundleManifestName && !listed[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		v.Problems = append(v.Problems, fmt.Sprintf("%s: not in manifest", name))
+	}
+
+	if data, ok := contents[BundleSignaturesName]; ok {
+		if err := json.Unmarshal(data, &v.Signatures); err != nil {
+			v.Problems = append(v.Problems, fmt.Sprintf("%s: %v", BundleSignaturesName, err))
+		}
+	}
+	for _, sig := range v.Signatures {
+		if sig.Result != SignatureValid {
+			v.Problems = append(v.Problems, fmt.Sprintf("review %s by %s: signature %s", sig.ReviewID, sig.ReviewerAgent, sig.Result))
+		}
+	}
+	return v, nil
+}
+
+// validBundleName rejects names that could escape the extraction directory.
+func validBundleName(name string) error {
+	clean := path.Clean(name)
+	if name == "" || clean != name || path.IsAbs(name) || strings.HasPrefix(clean, "../") || clean == ".." {
+		return fmt.Errorf("invalid bundle file name %q", name)
+	}
+	if name == BundleManifestName {
+		return fmt.Errorf("bundle file name %q is reserved", name)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestWriteBundle_VerifiesClean(t *testing.T) {
+	var buf bytes.Buffer
+	files := []BundleFile{
+		{Name: "request.json", Data: []byte(`{"request_id":"r1"}`)},
+		{Name: "logs/r1.log", Data: []byte("hello\n")},
+	}
+	manifest, err := WriteBundle(&buf, "r1", files, time.Now())
+	if err != nil {
+		t.Fatalf("WriteBundle: %v", err)
+	}
+	if len(manifest.Files) != 2 {
+		t.Fatalf("manifest files = %d, want 2", len(manifest.Files))
+	}
+
+	v, err := VerifyBundle(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("VerifyBundle: %v", err)
+	}
+	if !v.OK() || v.RequestID != "r1" {
+		t.Fatalf("expected clean verification for r1, got %+v", v)
+	}
+}
+
+func TestWriteBundle_RejectsBadNames(t *testing.T) {
+	for _, name := range []string{"", "/etc/passwd", "../x", "a/../../x", BundleManifestName} {
+		_, err := WriteBundle(io.Discard, "r1", []BundleFile{{Name: name}}, time.Now())
+		if err == nil {
+			t.Errorf("name %q: expected error", name)
+		}
+	}
+	_, err := WriteBundle(io.Discard, "r1", []BundleFile{{Name: "a"}, {Name: "a"}}, time.Now())
+	if err == nil {
+		t.Error("duplicate names: expected error")
+	}
+}
+
+// rewriteBundle copies a bundle, letting edit change or drop entries.
+func rewriteBundle(t *testing.T, data []byte, edit func(name string, body []byte) ([]byte, bool), extra *BundleFile) []byte {
+	t.Helper()
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	write := func(name string, body []byte) {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(tr)
+		if body, keep := edit(hdr.Name, body); keep {
+			write(hdr.Name, body)
+		}
+	}
+	if extra != nil {
+		write(extra.Name, extra.Data)
+	}
+	tw.Close()
+	gw.Close()
+	return out.Bytes()
+}
+
+func TestVerifyBundle_DetectsTampering(t *testing.T) {
+	var buf bytes.Buffer
+	files := []BundleFile{
+		{Name: "request.json", Data: []byte(`{"status":"rejected"}`)},
+		{Name: "logs/r1.log", Data: []byte("output\n")},
+	}
+	if _, err := WriteBundle(&buf, "r1", files, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(string, []byte) ([]byte, bool)
+		extra *BundleFile
+		want  string
+	}{
+		{
+			name: "modified",
+			edit: func(name string, body []byte) ([]byte, bool) {
+				if name == "request.json" {
+					return []byte(`{"status":"approved"}`), true
+				}
+				return body, true
+			},
+			want: "request.json: hash mismatch",
+		},
+		{
+			name: "removed",
+			edit: func(name string, body []byte) ([]byte, bool) {
+				return body, name != "logs/r1.log"
+			},
+			want: "logs/r1.log: missing",
+		},
+		{
+			name:  "added",
+			edit:  func(_ string, body []byte) ([]byte, bool) { return body, true },
+			extra: &BundleFile{Name: "extra.txt", Data: []byte("x")},
+			want:  "extra.txt: not in manifest",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := VerifyBundle(bytes.NewReader(rewriteBundle(t, buf.Bytes(), tc.edit, tc.extra)))
+			if err != nil {
+				t.Fatalf("VerifyBundle: %v", err)
+			}
+			if v.OK() || !strings.Contains(strings.Join(v.Problems, "\n"), tc.want) {
+				t.Fatalf("expected problem %q, got %v", tc.want, v.Problems)
+			}
+		})
+	}
+}
+
+func TestVerifyBundle_NotABundle(t *testing.T) {
+	if _, err := VerifyBundle(strings.NewReader("plain text")); !errors.Is(err, ErrInvalidBundle) {
+		t.Fatalf("expected ErrInvalidBundle, got %v", err)
+	}
+}
+
+func TestCheckReviewSignatures(t *testing.T) {
+	h := testutil.NewHarness(t)
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+	req := testutil.MakeRequest(t, h.DB, requestor)
+
+	ts := time.Now().UTC()
+	good := &db.Review{
+		RequestID:          req.ID,
+		ReviewerSessionID:  reviewer.ID,
+		ReviewerAgent:      reviewer.AgentName,
+		ReviewerModel:      reviewer.Model,
+		Decision:           db.DecisionApprove,
+		CommandHash:        req.Command.Hash,
+		Signature:          db.ComputeReviewSignature(reviewer.SessionKey, req.ID, req.Command.Hash, db.DecisionApprove, ts),
+		SignatureTimestamp: ts,
+	}
+	forged := *good
+	forged.Signature = db.ComputeReviewSignature("wrong-key", req.ID, req.Command.Hash, db.DecisionApprove, ts)
+	orphan := *good
+	orphan.ReviewerSessionID = "missing"
+
+	results := CheckReviewSignatures(h.DB, []*db.Review{good, &forged, &orphan})
+	want := []string{SignatureValid, SignatureInvalid, SignatureUnverifiable}
+	for i, r := range results {
+		if r.Result != want[i] {
+			t.Errorf("review %d: result %q, want %q", i, r.Result, want[i])
+		}
+	}
+}
+
+func TestRedactBundleText(t *testing.T) {
+	cmd := db.CommandSpec{
+		Raw:             "deploy --token=abc123",
+		DisplayRedacted: "deploy [REDACTED]",
+	}
+	got := RedactBundleText("running deploy --token=abc123\nexport API_KEY=zzz\n", cmd)
+	if strings.Contains(got, "abc123") || strings.Contains(got, "zzz") {
+		t.Fatalf("secret survived redaction: %q", got)
+	}
+	if !strings.Contains(got, "deploy [REDACTED]") {
+		t.Fatalf("expected display form of command, got %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Campaign states, from CampaignSummary.State.
+const (
+	// CampaignStateEmpty means no request has joined the campaign yet.
+	CampaignStateEmpty = "empty"
+	// CampaignStateInProgress means some members are still awaiting review
+	// or execution and none has failed.
+	CampaignStateInProgress = "in_progress"
+	// CampaignStateComplete means every member executed successfully.
+	CampaignStateComplete = "complete"
+	// CampaignStateFailed means a member was rejected, cancelled or failed
+	// to execute, so the campaign cannot finish as planned.
+	CampaignStateFailed = "failed"
+)
+
+// CampaignMember is one request of a campaign.
+type CampaignMember struct {
+	RequestID string    `json:"request_id"`
+	Status    string    `json:"status"`
+	RiskTier  string    `json:"risk_tier"`
+	Command   string    `json:"command"`
+	Requestor string    `json:"requestor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CampaignSummary aggregates the statuses of a campaign's requests.
+type CampaignSummary struct {
+	Campaign *db.Campaign `json:"campaign"`
+	State    string       `json:"state"`
+	Total    int          `json:"total"`
+	// AwaitingReview counts members not yet decided: queued, pending,
+	// escalated, timed out waiting for review, or whose approval expired.
+	AwaitingReview int `json:"awaiting_review"`
+	// AwaitingExecution counts approved members not yet finished.
+	AwaitingExecution int `json:"awaiting_execution"`
+	Executed          int `json:"executed"`
+	// Failed counts rejected, cancelled and failed executions.
+	Failed   int              `json:"failed"`
+	ByStatus map[string]int   `json:"by_status"`
+	Members  []CampaignMember `json:"members"`
+}
+
+// SummarizeCampaign loads a campaign and summarizes its members, oldest
+// first.
+func SummarizeCampaign(database *db.DB, campaignID string) (*CampaignSummary, error) {
+	campaign, err := database.GetCampaign(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := database.ListCampaignRequests(campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("listing campaign requests: %w", err)
+	}
+
+	summary := &CampaignSummary{
+		Campaign: campaign,
+		Total:    len(requests),
+		ByStatus: make(map[string]int),
+		Members:  make([]CampaignMember, 0, len(requests)),
+	}
+	for _, r := range requests {
+		summary.ByStatus[string(r.Status)]++
+		switch r.Status {
+		case db.StatusApproved, db.StatusExecuting:
+			summary.AwaitingExecution++
+		case db.StatusExecuted:
+			summary.Executed++
+		case db.StatusRejected, db.StatusCancelled, db.StatusExecutionFailed, db.StatusTimedOut:
+			summary.Failed++
+		default:
+			summary.AwaitingReview++
+		}
+		cmd := r.Command.DisplayRedacted
+		if cmd == "" {
+			cmd = r.Command.Raw
+		}
+		summary.Members = append(summary.Members, CampaignMember{
+			RequestID: r.ID,
+			Status:    string(r.Status),
+			RiskTier:  string(r.RiskTier),
+			Command:   cmd,
+			Requestor: r.RequestorAgent,
+			CreatedAt: r.CreatedAt,
+		})
+	}
+
+	switch {
+	case summary.Total == 0:
+		summary.State = CampaignStateEmpty
+	case summary.Failed > 0:
+		summary.State = CampaignStateFailed
+	case summary.Executed == summary.Total:
+		summary.State = CampaignStateComplete
+	default:
+		summary.State = CampaignStateInProgress
+	}
+	return summary, nil
+}
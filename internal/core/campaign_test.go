@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestCreateRequest_Campaign(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	creator := NewRequestCreator(database, nil, nil, nil)
+
+	campaign := &db.Campaign{ProjectPath: session.ProjectPath, Name: "orders-v2"}
+	if err := database.CreateCampaign(campaign); err != nil {
+		t.Fatal(err)
+	}
+	result, err := creator.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "git reset --hard HEAD~3",
+		Justification: Justification{Reason: "Step 1"},
+		CampaignID:    campaign.ID,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Request.CampaignID != campaign.ID {
+		t.Errorf("expected the request to join the campaign, got %q", result.Request.CampaignID)
+	}
+
+	_, err = creator.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "git reset --hard HEAD~3",
+		Justification: Justification{Reason: "Step 2"},
+		CampaignID:    "missing",
+	})
+	if !errors.Is(err, db.ErrCampaignNotFound) {
+		t.Errorf("expected ErrCampaignNotFound, got %v", err)
+	}
+
+	other := &db.Campaign{ProjectPath: "/elsewhere", Name: "other"}
+	if err := database.CreateCampaign(other); err != nil {
+		t.Fatal(err)
+	}
+	_, err = creator.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "git reset --hard HEAD~3",
+		Justification: Justification{Reason: "Step 2"},
+		CampaignID:    other.ID,
+	})
+	if !errors.Is(err, db.ErrCampaignNotFound) {
+		t.Errorf("expected another project's campaign to be refused, got %v", err)
+	}
+}
+
+func TestSummarizeCampaign(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+
+	campaign := &db.Campaign{ProjectPath: session.ProjectPath, Name: "orders-v2"}
+	if err := database.CreateCampaign(campaign); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := SummarizeCampaign(database, campaign.ID)
+	if err != nil || summary.State != CampaignStateEmpty || summary.Total != 0 {
+		t.Fatalf("empty campaign: %+v, %v", summary, err)
+	}
+
+	member := func(status db.RequestStatus) *db.Request {
+		return testutil.MakeRequest(t, database, session, testutil.WithCampaign(campaign.ID), testutil.WithStatus(status))
+	}
+	member(db.StatusExecuted)
+	member(db.StatusApproved)
+	member(db.StatusPending)
+	testutil.MakeRequest(t, database, session) // not in the campaign
+
+	summary, err = SummarizeCampaign(database, campaign.ID)
+	if err != nil {
+		t.Fatalf("SummarizeCampaign: %v", err)
+	}
+	if summary.State != CampaignStateInProgress || summary.Total != 3 ||
+		summary.Executed != 1 || summary.AwaitingExecution != 1 || summary.AwaitingReview != 1 || summary.Failed != 0 {
+		t.Errorf("mixed campaign: %+v", summary)
+	}
+	if summary.ByStatus["pending"] != 1 || len(summary.Members) != 3 {
+		t.Errorf("by status = %v, members = %d", summary.ByStatus, len(summary.Members))
+	}
+
+	member(db.StatusRejected)
+	summary, _ = SummarizeCampaign(database, campaign.ID)
+	if summary.State != CampaignStateFailed || summary.Failed != 1 {
+		t.Errorf("campaign with a rejection: state %s, failed %d", summary.State, summary.Failed)
+	}
+
+	done := &db.Campaign{ProjectPath: session.ProjectPath, Name: "done"}
+	if err := database.CreateCampaign(done); err != nil {
+		t.Fatal(err)
+	}
+	testutil.MakeRequest(t, database, session, testutil.WithCampaign(done.ID), testutil.WithStatus(db.StatusExecuted))
+	if summary, _ := SummarizeCampaign(database, done.ID); summary.State != CampaignStateComplete {
+		t.Errorf("all executed: state %s, want complete", summary.State)
+	}
+
+	if _, err := SummarizeCampaign(database, "missing"); !errors.Is(err, db.ErrCampaignNotFound) {
+		t.Errorf("expected ErrCampaignNotFound, got %v", err)
+	}
+}
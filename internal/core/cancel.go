@@ -0,0 +1,96 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Cancel errors.
+var (
+	// ErrCancelNotRequestor is returned when a session other than the
+	// requesting agent's tries to cancel a request without an override.
+	ErrCancelNotRequestor = errors.New("only the requesting agent can cancel a request: you are not the requestor")
+	// ErrRequestResolved is returned when cancelling a request that already
+	// reached a terminal state.
+	ErrRequestResolved = errors.New("request is already resolved")
+	// ErrNotCancellable is returned when cancelling a request that is past
+	// the point of cancelling, such as one that is executing.
+	ErrNotCancellable = errors.New("request can no longer be cancelled")
+)
+
+// CancelOptions selects the request to cancel and why.
+type CancelOptions struct {
+	RequestID string
+	// SessionID is an active session of the agent that made the request,
+	// in the request's project, or of any agent when Override is set.
+	SessionID string
+	Reason    string
+	// Override lets an operator cancel another agent's request. The
+	// cancelling agent is still recorded.
+	Override bool
+}
+
+// CancelResult is the outcome of a cancel.
+type CancelResult struct {
+	Request *db.Request
+	// PreviousStatus is the status the request was cancelled from.
+	PreviousStatus db.RequestStatus
+}
+
+// Cancel withdraws a request that has not started executing. Like
+// Rerequest, the session may differ from the original one but must belong
+// to the same agent in the same project, unless opts.Override is set. An
+// approved request loses its approval, so it can never be executed.
+func (rc *RequestCreator) Cancel(opts CancelOptions) (*CancelResult, error) {
+	if opts.SessionID == "" {
+		return nil, ErrSessionRequired
+	}
+
+	request, err := rc.db.GetRequest(opts.RequestID)
+	if err != nil {
+		return nil, fmt.Errorf("getting request: %w", err)
+	}
+	session, err := rc.db.GetSession(opts.SessionID)
+	if err != nil {
+		if errors.Is(err, db.ErrSessionNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("getting session: %w", err)
+	}
+	if session.EndedAt != nil {
+		return nil, ErrSessionInactive
+	}
+	isRequestor := session.AgentName == request.RequestorAgent && session.ProjectPath == request.ProjectPath
+	if !isRequestor && !opts.Override {
+		return nil, ErrCancelNotRequestor
+	}
+
+	previous := request.Status
+	if TerminalStates[previous] {
+		return nil, fmt.Errorf("%w: status is %s", ErrRequestResolved, previous)
+	}
+	if !CanCancel(previous) {
+		return nil, fmt.Errorf("%w: status is %s", ErrNotCancellable, previous)
+	}
+	if err := Transition(request, db.StatusCancelled); err != nil {
+		return nil, err
+	}
+
+	cancelledBy := session.AgentName
+	if !isRequestor {
+		cancelledBy += " (override)"
+	}
+	if err := rc.db.CancelRequest(request.ID, previous, cancelledBy, opts.Reason); err != nil {
+		if errors.Is(err, db.ErrInvalidTransition) {
+			return nil, fmt.Errorf("%w: request changed while cancelling", ErrNotCancellable)
+		}
+		return nil, err
+	}
+	request, err = rc.db.GetRequest(request.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting request: %w", err)
+	}
+	return &CancelResult{Request: request, PreviousStatus: previous}, nil
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func TestCancel_ApprovedClearsApproval(t *testing.T) {
+	dbConn, sess, req := setupReviewTest(t)
+	defer dbConn.Close()
+	reviewer := &db.Session{
+		AgentName:   "GreenLake",
+		Program:     "claude-code",
+		Model:       "opus-4.5",
+		ProjectPath: "/test/project",
+	}
+	if err := dbConn.CreateSession(reviewer); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	approve(t, dbConn, reviewer, req.ID)
+	deadline := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	if _, err := dbConn.Exec(`UPDATE requests SET approval_expires_at = ? WHERE id = ?`, deadline, req.ID); err != nil {
+		t.Fatalf("setting approval deadline: %v", err)
+	}
+	if approved, _ := dbConn.GetRequest(req.ID); approved.Status != db.StatusApproved || approved.ApprovalExpiresAt == nil {
+		t.Fatalf("expected an approved request with an approval deadline, got %+v", approved)
+	}
+
+	rc := NewRequestCreator(dbConn, nil, nil, DefaultRequestCreatorConfig())
+	result, err := rc.Cancel(CancelOptions{RequestID: req.ID, SessionID: sess.ID, Reason: "superseded by a smaller fix"})
+	if err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	got := result.Request
+	if result.PreviousStatus != db.StatusApproved || got.Status != db.StatusCancelled {
+		t.Errorf("expected approved -> cancelled, got %s -> %s", result.PreviousStatus, got.Status)
+	}
+	if got.ApprovalExpiresAt != nil || got.ResolvedAt == nil {
+		t.Errorf("expected the approval cleared and the request resolved, got %+v", got)
+	}
+	if got.CancelReason != "superseded by a smaller fix" || got.CancelledBy != sess.AgentName {
+		t.Errorf("unexpected cancel record %q by %q", got.CancelReason, got.CancelledBy)
+	}
+
+	_, err = rc.Cancel(CancelOptions{RequestID: req.ID, SessionID: sess.ID})
+	if !errors.Is(err, ErrRequestResolved) {
+		t.Errorf("expected ErrRequestResolved cancelling twice, got %v", err)
+	}
+}
+
+func TestCancel_RequestorOnlyUnlessOverride(t *testing.T) {
+	dbConn, _, req := setupReviewTest(t)
+	defer dbConn.Close()
+	operator := &db.Session{
+		AgentName:   "Operator",
+		Program:     "human",
+		Model:       "human",
+		ProjectPath: "/test/project",
+	}
+	if err := dbConn.CreateSession(operator); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	rc := NewRequestCreator(dbConn, nil, nil, DefaultRequestCreatorConfig())
+	if _, err := rc.Cancel(CancelOptions{RequestID: req.ID, SessionID: operator.ID}); !errors.Is(err, ErrCancelNotRequestor) {
+		t.Fatalf("expected ErrCancelNotRequestor, got %v", err)
+	}
+
+	result, err := rc.Cancel(CancelOptions{RequestID: req.ID, SessionID: operator.ID, Override: true, Reason: "freeze"})
+	if err != nil {
+		t.Fatalf("Cancel() with override error = %v", err)
+	}
+	if result.Request.CancelledBy != "Operator (override)" {
+		t.Errorf("expected the override recorded, got %q", result.Request.CancelledBy)
+	}
+}
+
+func TestCancel_ExecutingNotCancellable(t *testing.T) {
+	dbConn, sess, req := setupReviewTest(t)
+	defer dbConn.Close()
+	for _, status := range []db.RequestStatus{db.StatusApproved, db.StatusExecuting} {
+		if err := dbConn.UpdateRequestStatus(req.ID, status); err != nil {
+			t.Fatalf("UpdateRequestStatus(%s) error = %v", status, err)
+		}
+	}
+
+	rc := NewRequestCreator(dbConn, nil, nil, DefaultRequestCreatorConfig())
+	if _, err := rc.Cancel(CancelOptions{RequestID: req.ID, SessionID: sess.ID}); !errors.Is(err, ErrNotCancellable) {
+		t.Errorf("expected ErrNotCancellable, got %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+// Package core builds change records for executed requests.
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// ChangeRecordEvent is the event name carried by every change record.
+const ChangeRecordEvent = "request_executed"
+
+// auditChainSeed starts every request's audit chain.
+const auditChainSeed = "slb-audit-chain-v1"
+
+// ChangeRecord describes an executed request for change-management systems.
+type ChangeRecord struct {
+	Event          string                     `json:"event"`
+	RequestID      string                     `json:"request_id"`
+	Project        string                     `json:"project"`
+	Command        string                     `json:"command"`
+	Classification ChangeRecordClassification `json:"classification"`
+	Justification  db.Justification           `json:"justification"`
+	Requestor      ChangeRecordActor          `json:"requestor"`
+	Approvers      []ChangeRecordApprover     `json:"approvers"`
+	Execution      ChangeRecordExecution      `json:"execution"`
+	// RollbackAvailable reports whether captured state can still be restored.
+	RollbackAvailable bool `json:"rollback_available"`
+	// AuditChainHash covers the request, its reviews and its execution; see
+	// RequestAuditChainHash.
+	AuditChainHash string    `json:"audit_chain_hash"`
+	GeneratedAt    time.Time `json:"generated_at"`
+}
+
+// ChangeRecordClassification is the risk classification of the command.
+type ChangeRecordClassification struct {
+	Tier   string            `json:"tier"`
+	Reason string            `json:"reason,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ChangeRecordActor identifies the agent behind a session.
+type ChangeRecordActor struct {
+	Agent     string `json:"agent"`
+	Model     string `json:"model,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// ChangeRecordApprover is one approving review and whether its signature
+// verified against the reviewer's session key.
+type ChangeRecordApprover struct {
+	ChangeRecordActor
+	ApprovedAt time.Time `json:"approved_at"`
+	// Signature is valid, invalid or unverifiable (see CheckReviewSignatures).
+	Signature       string `json:"signature"`
+	SignatureDetail string `json:"signature_detail,omitempty"`
+}
+
+// ChangeRecordExecution is the execution window and outcome.
+type ChangeRecordExecution struct {
+	Status      string            `json:"status"`
+	ExecutedBy  ChangeRecordActor `json:"executed_by"`
+	RequestedAt time.Time         `json:"requested_at"`
+	ApprovedAt  *time.Time        `json:"approved_at,omitempty"`
+	StartedAt   *time.Time        `json:"started_at,omitempty"`
+	FinishedAt  *time.Time        `json:"finished_at,omitempty"`
+	DurationMs  *int64            `json:"duration_ms,omitempty"`
+	ExitCode    *int              `json:"exit_code,omitempty"`
+}
+
+// ChangeRecordTierEnabled reports whether tier is listed in tiers, the
+// integrations.change_record_tiers setting.
+func ChangeRecordTierEnabled(tiers []string, tier db.RiskTier) bool {
+	for _, t := range tiers {
+		if t == string(tier) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildChangeRecord assembles the change record for an executed request.
+func BuildChangeRecord(database *db.DB, request *db.Request, now time.Time) (*ChangeRecord, error) {
+	reviews, err := database.ListReviewsForRequest(request.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing reviews: %w", err)
+	}
+	chain, err := RequestAuditChainHash(request, reviews)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &ChangeRecord{
+		Event:     ChangeRecordEvent,
+		RequestID: request.ID,
+		Project:   request.ProjectPath,
+		Command:   BundleCommand(request.Command),
+		Classification: ChangeRecordClassification{
+			Tier:   string(request.RiskTier),
+			Reason: request.TierReason,
+			Labels: request.Labels,
+		},
+		Justification: request.Justification,
+		Requestor: ChangeRecordActor{
+			Agent:     request.RequestorAgent,
+			Model:     request.RequestorModel,
+			SessionID: request.RequestorSessionID,
+		},
+		Approvers: []ChangeRecordApprover{},
+		Execution: ChangeRecordExecution{
+			Status:      string(request.Status),
+			RequestedAt: request.CreatedAt,
+			ApprovedAt:  request.ResolvedAt,
+		},
+		RollbackAvailable: request.Rollback != nil && request.Rollback.Path != "" && request.Rollback.RolledBackAt == nil,
+		AuditChainHash:    chain,
+		GeneratedAt:       now.UTC(),
+	}
+
+	signatures := CheckReviewSignatures(database, reviews)
+	for i, r := range reviews {
+		if r.Decision != db.DecisionApprove {
+			continue
+		}
+		record.Approvers = append(record.Approvers, ChangeRecordApprover{
+			ChangeRecordActor: ChangeRecordActor{
+				Agent:     r.ReviewerAgent,
+				Model:     r.ReviewerModel,
+				SessionID: r.ReviewerSessionID,
+			},
+			ApprovedAt:      r.CreatedAt,
+			Signature:       signatures[i].Result,
+			SignatureDetail: signatures[i].Detail,
+		})
+	}
+
+	if exec := request.Execution; exec != nil {
+		record.Execution.ExecutedBy = ChangeRecordActor{
+			Agent:     exec.ExecutedByAgent,
+			Model:     exec.ExecutedByModel,
+			SessionID: exec.ExecutedBySessionID,
+		}
+		record.Execution.StartedAt = exec.ExecutedAt
+		record.Execution.DurationMs = exec.DurationMs
+		record.Execution.ExitCode = exec.ExitCode
+		if exec.ExecutedAt != nil && exec.DurationMs != nil {
+			finished := exec.ExecutedAt.Add(time.Duration(*exec.DurationMs) * time.Millisecond)
+			record.Execution.FinishedAt = &finished
+		}
+	}
+	return record, nil
+}
+
+// RequestAuditChainHash hashes a request's audit trail as a chain: starting
+// from a fixed seed, each link is SHA-256(previous link || entry JSON) over
+// the request as submitted, then each review in order, then the execution.
+// Altering, dropping or reordering any entry changes the final link.
+func RequestAuditChainHash(request *db.Request, reviews []*db.Review) (string, error) {
+	entries := []any{
+		struct {
+			ID            string           `json:"id"`
+			Project       string           `json:"project"`
+			CommandHash   string           `json:"command_hash"`
+			Tier          db.RiskTier      `json:"tier"`
+			Requestor     string           `json:"requestor_session_id"`
+			Justification db.Justification `json:"justification"`
+			CreatedAt     time.Time        `json:"created_at"`
+		}{request.ID, request.ProjectPath, request.Command.Hash, request.RiskTier,
+			request.RequestorSessionID, request.Justification, request.CreatedAt.UTC()},
+	}
+	for _, r := range reviews {
+		entries = append(entries, struct {
+			ID        string      `json:"id"`
+			Session   string      `json:"reviewer_session_id"`
+			Decision  db.Decision `json:"decision"`
+			Segments  []int       `json:"segments,omitempty"`
+			Signature string      `json:"signature"`
+			CreatedAt time.Time   `json:"created_at"`
+		}{r.ID, r.ReviewerSessionID, r.Decision, r.Segments, r.Signature, r.CreatedAt.UTC()})
+	}
+	if exec := request.Execution; exec != nil {
+		entries = append(entries, struct {
+			Status     db.RequestStatus `json:"status"`
+			Session    string           `json:"executed_by_session_id"`
+			ExecutedAt *time.Time       `json:"executed_at,omitempty"`
+			ExitCode   *int             `json:"exit_code,omitempty"`
+			DurationMs *int64           `json:"duration_ms,omitempty"`
+		}{request.Status, exec.ExecutedBySessionID, exec.ExecutedAt, exec.ExitCode, exec.DurationMs})
+	}
+
+	link := sha256.Sum256([]byte(auditChainSeed))
+	for _, entry := range entries {
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return "", fmt.Errorf("encoding audit chain entry: %w", err)
+		}
+		h := sha256.New()
+		h.Write(link[:])
+		h.Write(b)
+		copy(link[:], h.Sum(nil))
+	}
+	return hex.EncodeToString(link[:]), nil
+}
+
+// EnqueueChangeRecord stores request's change record for delivery to url.
+// It reports false if a record was already queued or delivered for the
+// request, so at most one record is sent per request.
+func EnqueueChangeRecord(database *db.DB, request *db.Request, url string, now time.Time) (bool, error) {
+	if existing, err := database.GetNotification(db.NotificationKindChangeRecord, request.ID); err != nil || existing != nil {
+		return false, err
+	}
+	record, err := BuildChangeRecord(database, request, now)
+	if err != nil {
+		return false, err
+	}
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return false, fmt.Errorf("encoding change record: %w", err)
+	}
+	return database.EnqueueNotification(&db.UndeliveredNotification{
+		Kind:      db.NotificationKindChangeRecord,
+		RequestID: request.ID,
+		URL:       url,
+		Payload:   string(payload),
+		CreatedAt: now.UTC(),
+	})
+}
+
+// EnqueueMissingChangeRecords queues change records for a project's requests
+// executed at or after since, in tiers, that have none yet. It returns the
+// number queued.
+func EnqueueMissingChangeRecords(database *db.DB, projectPath string, since time.Time, url string, tiers []string, now time.Time) (int, error) {
+	requests, err := database.ListExecutedRequestsSince(projectPath, since)
+	if err != nil {
+		return 0, err
+	}
+	queued := 0
+	for _, r := range requests {
+		if !ChangeRecordTierEnabled(tiers, r.RiskTier) {
+			continue
+		}
+		ok, err := EnqueueChangeRecord(database, r, url, now)
+		if err != nil {
+			return queued, fmt.Errorf("queueing change record for %s: %w", r.ID, err)
+		}
+		if ok {
+			queued++
+		}
+	}
+	return queued, nil
+}
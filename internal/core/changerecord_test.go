@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// executeWithChangeRecords creates an approved request for a command that
+// exits 0, approves it with a signed review and runs it with change records
+// enabled for tiers.
+func executeWithChangeRecords(t *testing.T, database *db.DB, tier db.RiskTier, tiers []string) *db.Request {
+	t.Helper()
+	project := t.TempDir()
+	requestor := testutil.MakeSession(t, database, testutil.WithProject(project), testutil.WithAgent("Requestor"))
+	reviewer := testutil.MakeSession(t, database, testutil.WithProject(project), testutil.WithAgent("Reviewer"))
+
+	truePath := testutil.TruePath()
+	cmdSpec := db.CommandSpec{Raw: truePath, Argv: []string{truePath}, Cwd: project}
+	cmdSpec.Hash = db.ComputeCommandHash(cmdSpec)
+	expires := time.Now().Add(time.Hour)
+	req := &db.Request{
+		ProjectPath:        project,
+		RequestorSessionID: requestor.ID,
+		RequestorAgent:     requestor.AgentName,
+		RequestorModel:     requestor.Model,
+		RiskTier:           tier,
+		Command:            cmdSpec,
+		Justification:      db.Justification{Reason: "rotate logs"},
+		Status:             db.StatusApproved,
+		ApprovalExpiresAt:  &expires,
+	}
+	if err := database.CreateRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Now().UTC()
+	if err := database.CreateReview(&db.Review{
+		RequestID:          req.ID,
+		ReviewerSessionID:  reviewer.ID,
+		ReviewerAgent:      reviewer.AgentName,
+		ReviewerModel:      reviewer.Model,
+		Decision:           db.DecisionApprove,
+		CommandHash:        req.Command.Hash,
+		Signature:          db.ComputeReviewSignature(reviewer.SessionKey, req.ID, req.Command.Hash, db.DecisionApprove, ts),
+		SignatureTimestamp: ts,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := NewExecutor(database, nil).WithChangeRecords("https://cmdb.example.com/hook", tiers)
+	if _, err := exec.ExecuteApprovedRequest(context.Background(), ExecuteOptions{
+		RequestID:      req.ID,
+		SessionID:      requestor.ID,
+		LogDir:         filepath.Join(project, "logs"),
+		SuppressOutput: true,
+	}); err != nil {
+		t.Fatalf("ExecuteApprovedRequest: %v", err)
+	}
+	return req
+}
+
+func TestExecuteApprovedRequest_QueuesChangeRecord(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell execution test uses /bin/sh or $SHELL")
+	}
+	database := testutil.NewTestDB(t)
+	req := executeWithChangeRecords(t, database, db.RiskTierDangerous, []string{"critical", "dangerous"})
+
+	n, err := database.GetNotification(db.NotificationKindChangeRecord, req.ID)
+	if err != nil || n == nil {
+		t.Fatalf("expected a queued change record, got %+v, %v", n, err)
+	}
+	if n.URL != "https://cmdb.example.com/hook" || n.DeliveredAt != nil {
+		t.Errorf("unexpected record %+v", n)
+	}
+	var record ChangeRecord
+	if err := json.Unmarshal([]byte(n.Payload), &record); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+	if record.Event != ChangeRecordEvent || record.Classification.Tier != "dangerous" || record.Justification.Reason != "rotate logs" {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if len(record.Approvers) != 1 || record.Approvers[0].Agent != "Reviewer" || record.Approvers[0].Signature != SignatureValid {
+		t.Errorf("expected one approver with a valid signature, got %+v", record.Approvers)
+	}
+	if record.Execution.Status != string(db.StatusExecuted) || record.Execution.ExitCode == nil || *record.Execution.ExitCode != 0 {
+		t.Errorf("unexpected execution %+v", record.Execution)
+	}
+	if record.Execution.StartedAt == nil || record.Execution.FinishedAt == nil || record.RollbackAvailable {
+		t.Errorf("expected an execution window and no rollback, got %+v", record)
+	}
+
+	stored, reviews, err := database.GetRequestWithReviews(req.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain, _ := RequestAuditChainHash(stored, reviews); chain != record.AuditChainHash {
+		t.Errorf("audit chain hash %q does not match %q", record.AuditChainHash, chain)
+	}
+
+	caution := executeWithChangeRecords(t, database, db.RiskTierCaution, []string{"critical", "dangerous"})
+	if n, _ := database.GetNotification(db.NotificationKindChangeRecord, caution.ID); n != nil {
+		t.Errorf("expected no change record for an unlisted tier, got %+v", n)
+	}
+}
+
+func TestRequestAuditChainHash(t *testing.T) {
+	now := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	exitCode := 0
+	req := &db.Request{
+		ID:        "req-1",
+		RiskTier:  db.RiskTierDangerous,
+		Command:   db.CommandSpec{Hash: "abc"},
+		CreatedAt: now,
+		Status:    db.StatusExecuted,
+		Execution: &db.Execution{ExecutedAt: &now, ExitCode: &exitCode},
+	}
+	first := &db.Review{ID: "rev-1", Decision: db.DecisionApprove, Signature: "s1", CreatedAt: now}
+	second := &db.Review{ID: "rev-2", Decision: db.DecisionApprove, Signature: "s2", CreatedAt: now}
+
+	base, err := RequestAuditChainHash(req, []*db.Review{first, second})
+	if err != nil || len(base) != 64 {
+		t.Fatalf("unexpected hash %q, %v", base, err)
+	}
+	if again, _ := RequestAuditChainHash(req, []*db.Review{first, second}); again != base {
+		t.Error("expected the hash to be deterministic")
+	}
+	if swapped, _ := RequestAuditChainHash(req, []*db.Review{second, first}); swapped == base {
+		t.Error("expected reordering reviews to change the hash")
+	}
+	if dropped, _ := RequestAuditChainHash(req, []*db.Review{first}); dropped == base {
+		t.Error("expected dropping a review to change the hash")
+	}
+	failed := 1
+	altered := *req
+	altered.Execution = &db.Execution{ExecutedAt: &now, ExitCode: &failed}
+	if changed, _ := RequestAuditChainHash(&altered, []*db.Review{first, second}); changed == base {
+		t.Error("expected a different exit code to change the hash")
+	}
+}
+
+func TestEnqueueMissingChangeRecords(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell execution test uses /bin/sh or $SHELL")
+	}
+	database := testutil.NewTestDB(t)
+	// Executed with the integration off: nothing is queued.
+	req := executeWithChangeRecords(t, database, db.RiskTierCritical, nil)
+	if n, _ := database.GetNotification(db.NotificationKindChangeRecord, req.ID); n != nil {
+		t.Fatalf("expected no change record, got %+v", n)
+	}
+
+	since := time.Now().Add(-time.Hour)
+	tiers := []string{"critical"}
+	queued, err := EnqueueMissingChangeRecords(database, req.ProjectPath, since, "https://cmdb.example.com/hook", tiers, time.Now())
+	if err != nil || queued != 1 {
+		t.Fatalf("expected one record queued, got %d, %v", queued, err)
+	}
+	queued, err = EnqueueMissingChangeRecords(database, req.ProjectPath, since, "https://cmdb.example.com/hook", tiers, time.Now())
+	if err != nil || queued != 0 {
+		t.Fatalf("expected a second replay to queue nothing, got %d, %v", queued, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/db"
@@ -20,13 +21,44 @@ type CommandResult struct {
 	ExitCode int
 	// Output is the combined stdout/stderr.
 	Output string
+	// Stdout and Stderr are the command's output streams, each truncated
+	// to ExecutionOutputLimit bytes on its own.
+	Stdout string
+	Stderr string
+	// StdoutTruncated and StderrTruncated report whether that stream
+	// passed ExecutionOutputLimit.
+	StdoutTruncated bool
+	StderrTruncated bool
 	// Duration is the execution time.
 	Duration time.Duration
 }
 
+// ExecutionOutputLimit is how many bytes of each of stdout and stderr an
+// execution record keeps.
+const ExecutionOutputLimit = 64 * 1024
+
+// lockedWriter serializes writes from the stdout and stderr copiers to a
+// writer they share.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
 // RunCommand executes a command and captures output to both terminal and log file.
 // The command runs in the current shell environment, inheriting all env vars.
 func RunCommand(ctx context.Context, spec *db.CommandSpec, logPath string, stream io.Writer) (*CommandResult, error) {
+	return runCommand(ctx, spec, logPath, stream, nil)
+}
+
+// runCommand is RunCommand with environment overrides applied on top of the
+// inherited environment. An empty override value removes the variable.
+func runCommand(ctx context.Context, spec *db.CommandSpec, logPath string, stream io.Writer, envOverrides map[string]string) (*CommandResult, error) {
 	startTime := time.Now()
 
 	// Open log file for writing
@@ -76,7 +108,7 @@ func RunCommand(ctx context.Context, spec *db.CommandSpec, logPath string, strea
 	}
 
 	// Inherit environment
-	cmd.Env = os.Environ()
+	cmd.Env = overrideEnv(os.Environ(), envOverrides)
 
 	// Set up output capture
 	var outputBuf bytes.Buffer
@@ -95,10 +127,14 @@ func RunCommand(ctx context.Context, spec *db.CommandSpec, logPath string, strea
 		writers = append(writers, logFile)
 	}
 
-	// Combine writers
-	multiWriter := io.MultiWriter(writers...)
-	cmd.Stdout = multiWriter
-	cmd.Stderr = multiWriter
+	// Combine writers; each stream is also kept on its own. The stream
+	// buffers come first so a failing terminal or log write cannot cut
+	// them short.
+	shared := &lockedWriter{w: io.MultiWriter(writers...)}
+	stdout := &cappedBuffer{max: ExecutionOutputLimit}
+	stderr := &cappedBuffer{max: ExecutionOutputLimit}
+	cmd.Stdout = io.MultiWriter(stdout, shared)
+	cmd.Stderr = io.MultiWriter(stderr, shared)
 
 	// Connect stdin to terminal for interactive commands
 	cmd.Stdin = os.Stdin
@@ -130,8 +166,34 @@ func RunCommand(ctx context.Context, spec *db.CommandSpec, logPath string, strea
 	}
 
 	return &CommandResult{
-		ExitCode: exitCode,
-		Output:   outputBuf.String(),
-		Duration: duration,
+		ExitCode:        exitCode,
+		Output:          outputBuf.String(),
+		Stdout:          stdout.String(),
+		Stderr:          stderr.String(),
+		StdoutTruncated: stdout.Truncated(),
+		StderrTruncated: stderr.Truncated(),
+		Duration:        duration,
 	}, nil
 }
+
+// overrideEnv returns env with overrides applied. Empty override values unset
+// the variable.
+func overrideEnv(env []string, overrides map[string]string) []string {
+	if len(overrides) == 0 {
+		return env
+	}
+	out := make([]string, 0, len(env)+len(overrides))
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if _, ok := overrides[key]; ok {
+			continue
+		}
+		out = append(out, kv)
+	}
+	for k, v := range overrides {
+		if v != "" {
+			out = append(out, k+"="+v)
+		}
+	}
+	return out
+}
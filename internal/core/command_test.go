@@ -3,6 +3,7 @@ package core
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -290,3 +291,65 @@ func TestRunCommand(t *testing.T) {
 		}
 	})
 }
+
+func TestRunCommand_EnvOverrides(t *testing.T) {
+	t.Setenv("SLB_PIN_KEEP", "kept")
+	t.Setenv("SLB_PIN_DROP", "dropped")
+
+	spec := &db.CommandSpec{
+		Raw:   `echo "$SLB_PIN_KEEP|$SLB_PIN_DROP|$SLB_PIN_SET"`,
+		Shell: true,
+	}
+	result, err := runCommand(context.Background(), spec, "", nil, map[string]string{
+		"SLB_PIN_DROP": "",
+		"SLB_PIN_SET":  "set",
+	})
+	if err != nil {
+		t.Fatalf("runCommand error: %v", err)
+	}
+	if got := strings.TrimSpace(result.Output); got != "kept||set" {
+		t.Fatalf("output = %q, want %q", got, "kept||set")
+	}
+}
+
+func TestRunCommand_SeparatesStreams(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell execution tests use Unix commands")
+	}
+
+	spec := &db.CommandSpec{
+		Raw:   "echo out1; echo err1 >&2; echo out2; echo err2 >&2; exit 3",
+		Shell: true,
+	}
+	result, err := RunCommand(context.Background(), spec, "", nil)
+	if err != nil {
+		t.Fatalf("RunCommand error: %v", err)
+	}
+	if result.Stdout != "out1\nout2\n" {
+		t.Errorf("Stdout = %q, want %q", result.Stdout, "out1\nout2\n")
+	}
+	if result.Stderr != "err1\nerr2\n" {
+		t.Errorf("Stderr = %q, want %q", result.Stderr, "err1\nerr2\n")
+	}
+	for _, want := range []string{"out1", "err1", "out2", "err2"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("expected combined output to contain %q, got %q", want, result.Output)
+		}
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("ExitCode = %d, want 3", result.ExitCode)
+	}
+
+	// Each stream is truncated on its own.
+	spec.Raw = fmt.Sprintf("head -c %d /dev/zero | tr '\\0' x; echo short >&2", ExecutionOutputLimit+100)
+	result, err = RunCommand(context.Background(), spec, "", nil)
+	if err != nil {
+		t.Fatalf("RunCommand error: %v", err)
+	}
+	if len(result.Stdout) != ExecutionOutputLimit || !result.StdoutTruncated {
+		t.Errorf("expected stdout truncated to %d bytes, got %d (truncated=%v)", ExecutionOutputLimit, len(result.Stdout), result.StdoutTruncated)
+	}
+	if result.Stderr != "short\n" || result.StderrTruncated {
+		t.Errorf("expected stderr kept whole, got %q (truncated=%v)", result.Stderr, result.StderrTruncated)
+	}
+}
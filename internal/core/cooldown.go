@@ -0,0 +1,60 @@
+// Package core implements the cool-down after a rejection, during which the
+// same session cannot immediately re-request the rejected command.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Rejection cool-down actions (general.rejection_cooldown_action).
+const (
+	// RejectionCooldownRefuse refuses the re-request outright (default).
+	RejectionCooldownRefuse = "refuse"
+	// RejectionCooldownJustify accepts the re-request only with a safety
+	// argument and a reason different from the rejected request's.
+	RejectionCooldownJustify = "justify"
+)
+
+// ErrRejectionCooldown is returned when a session re-requests a command that
+// was rejected within the cool-down.
+var ErrRejectionCooldown = errors.New("command was rejected recently")
+
+// checkRejectionCooldown refuses a re-request of a command the session had
+// rejected within general.rejection_cooldown_seconds. Commands are matched
+// by their canonical hash, so reordered flags count as the same command.
+func (rc *RequestCreator) checkRejectionCooldown(sessionID string, cmd db.CommandSpec, justification Justification, now time.Time) error {
+	if rc.config.RejectionCooldownSecs <= 0 {
+		return nil
+	}
+	cooldown := time.Duration(rc.config.RejectionCooldownSecs) * time.Second
+	rejected, err := rc.db.LatestRejectionSince(sessionID, db.ComputeCommandHash(cmd), now.Add(-cooldown))
+	if err != nil {
+		return fmt.Errorf("checking rejection cool-down: %w", err)
+	}
+	if rejected == nil {
+		return nil
+	}
+
+	if rc.config.RejectionCooldownAction == RejectionCooldownJustify {
+		reason := strings.TrimSpace(justification.Reason)
+		if strings.TrimSpace(justification.SafetyArgument) != "" && reason != "" &&
+			reason != strings.TrimSpace(rejected.Justification.Reason) {
+			return nil
+		}
+	}
+
+	remaining := cooldown
+	if rejected.ResolvedAt != nil {
+		remaining = rejected.ResolvedAt.Add(cooldown).Sub(now).Round(time.Second)
+	}
+	hint := ""
+	if rc.config.RejectionCooldownAction == RejectionCooldownJustify {
+		hint = " or re-request with --safety and a new --reason"
+	}
+	return fmt.Errorf("%w: request %s was rejected; retry in %s%s", ErrRejectionCooldown, rejected.ID, remaining, hint)
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// cooldownCreator returns a request creator with a rejection cool-down.
+func cooldownCreator(database *db.DB, secs int, action string) *RequestCreator {
+	cfg := DefaultRequestCreatorConfig()
+	cfg.AgentMailEnabled = false
+	cfg.RejectionCooldownSecs = secs
+	cfg.RejectionCooldownAction = action
+	return NewRequestCreator(database, nil, nil, cfg)
+}
+
+// createAndReject creates a request for command and rejects it.
+func createAndReject(t *testing.T, rc *RequestCreator, database *db.DB, sessionID, command string) *db.Request {
+	t.Helper()
+	result, err := rc.CreateRequest(CreateRequestOptions{
+		SessionID:     sessionID,
+		Command:       command,
+		Cwd:           "/tmp",
+		Justification: Justification{Reason: "clean build"},
+	})
+	if err != nil || result.Request == nil {
+		t.Fatalf("CreateRequest(%q) = %+v, %v", command, result, err)
+	}
+	if err := database.UpdateRequestStatus(result.Request.ID, db.StatusRejected); err != nil {
+		t.Fatalf("rejecting: %v", err)
+	}
+	return result.Request
+}
+
+func TestCreateRequest_RejectionCooldown(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	rc := cooldownCreator(database, 600, RejectionCooldownRefuse)
+
+	rejected := createAndReject(t, rc, database, session.ID, "rm -rf ./build")
+
+	// Reordered flags canonicalize to the same command.
+	_, err := rc.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "rm -fr ./build",
+		Cwd:           "/tmp",
+		Justification: Justification{Reason: "really clean build"},
+	})
+	if !errors.Is(err, ErrRejectionCooldown) {
+		t.Fatalf("expected the re-request to be refused, got %v", err)
+	}
+
+	// A different command is unaffected.
+	if _, err := rc.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "rm -rf ./dist",
+		Cwd:           "/tmp",
+		Justification: Justification{Reason: "clean dist"},
+	}); err != nil {
+		t.Errorf("expected a different command to be allowed, got %v", err)
+	}
+
+	// Another session is unaffected.
+	other := testutil.MakeSession(t, database, testutil.SessionWithAgentName("other"))
+	if _, err := rc.CreateRequest(CreateRequestOptions{
+		SessionID:     other.ID,
+		Command:       "rm -rf ./build",
+		Cwd:           "/tmp",
+		Justification: Justification{Reason: "clean build"},
+	}); err != nil {
+		t.Errorf("expected another session to be allowed, got %v", err)
+	}
+
+	// Once the cool-down has passed, the re-request is allowed.
+	past := time.Now().UTC().Add(-11 * time.Minute).Format(time.RFC3339)
+	if _, err := database.Exec(`UPDATE requests SET resolved_at = ? WHERE id = ?`, past, rejected.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rc.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "rm -rf ./build",
+		Cwd:           "/tmp",
+		Justification: Justification{Reason: "clean build"},
+	}); err != nil {
+		t.Errorf("expected the re-request after the cool-down to be allowed, got %v", err)
+	}
+}
+
+func TestCreateRequest_RejectionCooldownDisabled(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	rc := cooldownCreator(database, 0, RejectionCooldownRefuse)
+
+	createAndReject(t, rc, database, session.ID, "rm -rf ./build")
+	if _, err := rc.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "rm -rf ./build",
+		Cwd:           "/tmp",
+		Justification: Justification{Reason: "clean build"},
+	}); err != nil {
+		t.Errorf("expected no cool-down by default, got %v", err)
+	}
+}
+
+func TestCreateRequest_RejectionCooldownJustify(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	rc := cooldownCreator(database, 600, RejectionCooldownJustify)
+
+	createAndReject(t, rc, database, session.ID, "rm -rf ./build")
+
+	for _, j := range []Justification{
+		{Reason: "clean build"},
+		{Reason: "clean build", SafetyArgument: "build is regenerated"},
+		{Reason: "stale artifacts break the release"},
+	} {
+		_, err := rc.CreateRequest(CreateRequestOptions{
+			SessionID: session.ID, Command: "rm -rf ./build", Cwd: "/tmp", Justification: j,
+		})
+		if !errors.Is(err, ErrRejectionCooldown) {
+			t.Errorf("justification %+v: expected ErrRejectionCooldown, got %v", j, err)
+		}
+	}
+
+	result, err := rc.CreateRequest(CreateRequestOptions{
+		SessionID: session.ID,
+		Command:   "rm -rf ./build",
+		Cwd:       "/tmp",
+		Justification: Justification{
+			Reason:         "stale artifacts break the release",
+			SafetyArgument: "build is regenerated by make",
+		},
+	})
+	if err != nil || result.Request == nil {
+		t.Errorf("expected a new reason and safety argument to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+// Package core implements approval delegation: a reviewer's time-boxed
+// standing approval for requests matching a command pattern.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+var (
+	// ErrDelegationCritical is returned for delegations that would cover
+	// CRITICAL requests, which always need reviewers' own approval.
+	ErrDelegationCritical = errors.New("delegations cannot cover critical requests")
+	// ErrInvalidDelegation is returned for delegations with a bad pattern,
+	// tier or TTL.
+	ErrInvalidDelegation = errors.New("invalid delegation")
+)
+
+// NewDelegation validates a delegation from session for projectPath and signs
+// it with the session's key. Requests whose normalized primary command
+// matches the glob pattern (* matches any run of characters, ? any single
+// one), at maxTier or below, are approved on the session's behalf for ttl
+// from now. The caller stores it.
+func NewDelegation(session *db.Session, projectPath, pattern string, maxTier db.RiskTier, ttl time.Duration, comment string, now time.Time) (*db.Delegation, error) {
+	switch maxTier {
+	case RiskTierCritical:
+		return nil, ErrDelegationCritical
+	case RiskTierDangerous, RiskTierCaution:
+	default:
+		return nil, fmt.Errorf("%w: tier must be dangerous or caution, not %q", ErrInvalidDelegation, maxTier)
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("%w: pattern is required", ErrInvalidDelegation)
+	}
+	if _, err := regexp.Compile(globToRegexp(pattern)); err != nil {
+		return nil, fmt.Errorf("%w: pattern %q: %v", ErrInvalidDelegation, pattern, err)
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("%w: ttl must be positive", ErrInvalidDelegation)
+	}
+
+	now = now.UTC().Truncate(time.Second)
+	expiresAt := now.Add(ttl)
+	return &db.Delegation{
+		ProjectPath: projectPath,
+		Pattern:     pattern,
+		MaxTier:     maxTier,
+		SessionID:   session.ID,
+		AgentName:   session.AgentName,
+		Model:       session.Model,
+		Signature:   db.ComputeDelegationSignature(session.SessionKey, projectPath, pattern, maxTier, expiresAt, now),
+		Comment:     comment,
+		CreatedAt:   now,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// DelegationCovers reports whether d covers request: request is not
+// CRITICAL, is at d's tier or below, and is a single command whose
+// normalized primary command matches d's pattern. Compound commands are
+// never covered, so a matching command cannot carry another one with it.
+func DelegationCovers(d *db.Delegation, request *db.Request) bool {
+	if request.RiskTier == RiskTierCritical || d.MaxTier == RiskTierCritical {
+		return false
+	}
+	if tierRank(request.RiskTier) > tierRank(d.MaxTier) {
+		return false
+	}
+	normalized := NormalizeCommand(request.Command.Raw)
+	if normalized.IsCompound && len(normalized.Segments) > 1 {
+		return false
+	}
+	re, err := regexp.Compile(globToRegexp(d.Pattern))
+	if err != nil {
+		return false
+	}
+	return re.MatchString(normalized.Primary)
+}
+
+// delegationRefusals are SubmitReview errors that mean a delegation cannot
+// approve a request on its reviewer's behalf, as the reviewer could not
+// have either; the next delegation is tried.
+var delegationRefusals = []error{
+	ErrSelfReview, ErrAlreadyReviewed, ErrRequireDiffModel, ErrRequireDiffHost,
+	ErrRequireDiffProgram, ErrSessionInactive, ErrSessionStale, ErrRequestNotPending, db.ErrSessionNotFound,
+}
+
+// ApplyDelegations approves a newly pending request on behalf of each active
+// delegation in its project that covers it (see DelegationCovers), oldest
+// delegation first, until the request leaves pending. Each approval goes
+// through SubmitReview as the delegating session, so it is signed, counted
+// and checked like one given by hand: a delegation never approves its own
+// reviewer's request, and one session approves at most once. Delegations
+// whose signature no longer verifies are skipped.
+func (rs *ReviewService) ApplyDelegations(request *db.Request) ([]*ReviewResult, error) {
+	if request.Status != db.StatusPending || request.RiskTier == RiskTierCritical || HasRuntimeTargets(request.Command.Raw) {
+		return nil, nil
+	}
+	delegations, err := rs.db.ListDelegations(request.ProjectPath, false, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*ReviewResult
+	for _, d := range delegations {
+		if !DelegationCovers(d, request) {
+			continue
+		}
+		session, err := rs.db.GetSession(d.SessionID)
+		if err != nil || !db.VerifyDelegationSignature(session.SessionKey, d) {
+			continue
+		}
+		result, err := rs.SubmitReview(ReviewOptions{
+			SessionID:    session.ID,
+			SessionKey:   session.SessionKey,
+			RequestID:    request.ID,
+			Decision:     db.DecisionApprove,
+			Comments:     fmt.Sprintf("approved under delegation %s (%s)", d.ID, d.Pattern),
+			DelegationID: d.ID,
+		})
+		if err != nil {
+			if isDelegationRefusal(err) {
+				continue
+			}
+			return results, fmt.Errorf("applying delegation %s: %w", d.ID, err)
+		}
+		results = append(results, result)
+		if result.RequestStatusChanged && result.NewRequestStatus != db.StatusPending {
+			break
+		}
+	}
+	return results, nil
+}
+
+func isDelegationRefusal(err error) bool {
+	for _, target := range delegationRefusals {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// delegate stores a delegation from reviewer for pattern up to tier.
+func delegate(t *testing.T, database *db.DB, reviewer *db.Session, pattern string, tier db.RiskTier) *db.Delegation {
+	t.Helper()
+	d, err := NewDelegation(reviewer, reviewer.ProjectPath, pattern, tier, time.Hour, "", time.Now())
+	if err != nil {
+		t.Fatalf("NewDelegation: %v", err)
+	}
+	if err := database.CreateDelegation(d); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestNewDelegation_Validation(t *testing.T) {
+	sess := &db.Session{ID: "sess-1", AgentName: "Reviewer", SessionKey: "00ff"}
+	now := time.Now()
+	if _, err := NewDelegation(sess, "/p", "make deploy", RiskTierCritical, time.Hour, "", now); !errors.Is(err, ErrDelegationCritical) {
+		t.Errorf("critical tier: got %v, want ErrDelegationCritical", err)
+	}
+	for name, build := range map[string]func() error{
+		"safe tier": func() error {
+			_, err := NewDelegation(sess, "/p", "make deploy", db.RiskTier("safe"), time.Hour, "", now)
+			return err
+		},
+		"empty pattern": func() error {
+			_, err := NewDelegation(sess, "/p", "", RiskTierDangerous, time.Hour, "", now)
+			return err
+		},
+		"zero ttl": func() error {
+			_, err := NewDelegation(sess, "/p", "make deploy", RiskTierDangerous, 0, "", now)
+			return err
+		},
+	} {
+		if err := build(); !errors.Is(err, ErrInvalidDelegation) {
+			t.Errorf("%s: got %v, want ErrInvalidDelegation", name, err)
+		}
+	}
+
+	d, err := NewDelegation(sess, "/p", "make deploy", RiskTierCaution, 4*time.Hour, "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !db.VerifyDelegationSignature(sess.SessionKey, d) || d.ExpiresAt.Sub(d.CreatedAt) != 4*time.Hour {
+		t.Errorf("unexpected delegation %+v", d)
+	}
+}
+
+func TestDelegationCovers(t *testing.T) {
+	d := &db.Delegation{Pattern: "terraform apply -target=module.cache*", MaxTier: RiskTierDangerous}
+	cases := []struct {
+		command string
+		tier    db.RiskTier
+		want    bool
+	}{
+		{"terraform apply -target=module.cache_redis", RiskTierDangerous, true},
+		{"terraform apply -target=module.cache", RiskTierCaution, true},
+		{"sudo terraform apply -target=module.cache", RiskTierDangerous, true},
+		{"terraform apply -target=module.vpc", RiskTierDangerous, false},
+		{"terraform apply -target=module.cache", RiskTierCritical, false},
+		{"terraform apply -target=module.cache && rm -rf /", RiskTierDangerous, false},
+	}
+	for _, tc := range cases {
+		req := &db.Request{Command: db.CommandSpec{Raw: tc.command}, RiskTier: tc.tier}
+		if got := DelegationCovers(d, req); got != tc.want {
+			t.Errorf("DelegationCovers(%q, %s) = %v, want %v", tc.command, tc.tier, got, tc.want)
+		}
+	}
+
+	caution := &db.Delegation{Pattern: "make *", MaxTier: RiskTierCaution}
+	if DelegationCovers(caution, &db.Request{Command: db.CommandSpec{Raw: "make deploy"}, RiskTier: RiskTierDangerous}) {
+		t.Error("expected a caution delegation not to cover a dangerous request")
+	}
+}
+
+func TestApplyDelegations(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	requestor := testutil.MakeSession(t, database)
+	reviewer := testutil.MakeSession(t, database, testutil.WithProject(requestor.ProjectPath))
+	d := delegate(t, database, reviewer, "terraform apply -target=module.cache*", RiskTierDangerous)
+	rs := NewReviewService(database, DefaultReviewConfig())
+
+	req := testutil.MakeRequest(t, database, requestor,
+		testutil.WithCommand("terraform apply -target=module.cache_redis", requestor.ProjectPath, true))
+	results, err := rs.ApplyDelegations(req)
+	if err != nil {
+		t.Fatalf("ApplyDelegations: %v", err)
+	}
+	if len(results) != 1 || results[0].NewRequestStatus != db.StatusApproved {
+		t.Fatalf("expected one approving review, got %+v", results)
+	}
+	review := results[0].Review
+	if review.ReviewerSessionID != reviewer.ID || review.DelegationID != d.ID || !strings.Contains(review.Comments, d.ID) {
+		t.Errorf("expected a review attributed to the reviewer under %s, got %+v", d.ID, review)
+	}
+	reviews, _ := database.ListReviewsForRequest(req.ID)
+	if len(reviews) != 1 || reviews[0].DelegationID != d.ID {
+		t.Errorf("expected the stored review to carry the delegation ID, got %+v", reviews)
+	}
+
+	// Unmatched commands and critical requests are left for reviewers.
+	other := testutil.MakeRequest(t, database, requestor,
+		testutil.WithCommand("terraform apply", requestor.ProjectPath, true))
+	critical := testutil.MakeRequest(t, database, requestor, testutil.WithRisk(db.RiskTierCritical),
+		testutil.WithCommand("terraform apply -target=module.cache", requestor.ProjectPath, true))
+	for _, r := range []*db.Request{other, critical} {
+		if results, err := rs.ApplyDelegations(r); err != nil || len(results) != 0 {
+			t.Errorf("expected %q (%s) left pending, got %+v, %v", r.Command.Raw, r.RiskTier, results, err)
+		}
+	}
+
+	// A delegation never approves its own reviewer's request.
+	own := testutil.MakeRequest(t, database, reviewer,
+		testutil.WithCommand("terraform apply -target=module.cache", reviewer.ProjectPath, true))
+	if results, err := rs.ApplyDelegations(own); err != nil || len(results) != 0 {
+		t.Errorf("expected the reviewer's own request left pending, got %+v, %v", results, err)
+	}
+
+	// Revoked or tampered delegations no longer apply.
+	if err := database.RevokeDelegation(d.ID, reviewer.AgentName, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	tampered := delegate(t, database, reviewer, "terraform plan", RiskTierDangerous)
+	if _, err := database.Exec(`UPDATE delegations SET pattern = ? WHERE id = ?`, "terraform *", tampered.ID); err != nil {
+		t.Fatal(err)
+	}
+	again := testutil.MakeRequest(t, database, requestor,
+		testutil.WithCommand("terraform apply -target=module.cache", requestor.ProjectPath, true))
+	if results, err := rs.ApplyDelegations(again); err != nil || len(results) != 0 {
+		t.Errorf("expected revoked and tampered delegations ignored, got %+v, %v", results, err)
+	}
+}
+
+func TestSweepExpiredRequests_RemovesExpiredDelegations(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	reviewer := testutil.MakeSession(t, database)
+	live := delegate(t, database, reviewer, "make deploy", RiskTierCaution)
+
+	result, err := SweepExpiredRequests(database, reviewer.ProjectPath, time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("SweepExpiredRequests: %v", err)
+	}
+	if len(result.DelegationsExpired) != 1 || result.DelegationsExpired[0].ID != live.ID {
+		t.Errorf("expected the delegation swept after its TTL, got %+v", result.DelegationsExpired)
+	}
+}
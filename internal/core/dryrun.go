@@ -16,6 +16,9 @@ import (
 
 const defaultDryRunTimeout = 30 * time.Second
 
+// dryRunStderrSeparator separates stdout from stderr in dry-run output.
+const dryRunStderrSeparator = "\n--- stderr ---\n"
+
 // GetDryRunCommand returns a shell-safe dry-run variant of cmd when supported.
 // The second return value is false when no dry-run variant is available.
 func GetDryRunCommand(cmd string) (string, bool) {
@@ -228,7 +231,7 @@ func combineStdoutStderr(stdout, stderr string) string {
 	if stdout == "" {
 		return stderr
 	}
-	return stdout + "\n--- stderr ---\n" + stderr
+	return stdout + dryRunStderrSeparator + stderr
 }
 
 func shellJoin(tokens []string) string {
@@ -0,0 +1,92 @@
+// Package core implements auto-resolution of commands whose dry run shows
+// they would change nothing.
+package core
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Dry-run no-op actions (general.dry_run_noop_action).
+const (
+	// DryRunNoopReview sends the command for review as usual (default).
+	DryRunNoopReview = "review"
+	// DryRunNoopAutoApprove creates the request already approved, recording
+	// the dry run's finding in its tier reason.
+	DryRunNoopAutoApprove = "auto_approve"
+	// DryRunNoopSkip creates no request, as for a safe command.
+	DryRunNoopSkip = "skip"
+)
+
+var (
+	ansiEscapeRe         = regexp.MustCompile("\x1b\\[[0-9;]*m")
+	kubectlNoResourcesRe = regexp.MustCompile(`^No resources found(\s+in\s+\S+\s+namespace)?\.?$`)
+	terraformNoChangesRe = regexp.MustCompile(`(?m)^\s*No changes\.`)
+	terraformPlanLineRe  = regexp.MustCompile(`(?m)^\s*Plan:`)
+)
+
+// DryRunNoEffect reports whether a dry run that completed without error shows
+// its command would change nothing, and why. It is deliberately narrow: only
+// outputs that positively state there is nothing to act on count, so
+// anything unexpected (extra output, a failed dry run, an unknown command
+// family) means the command still needs review.
+//
+// Recognized:
+//   - kubectl delete: the only output is "No resources found"
+//   - terraform destroy: the destroy plan reports "No changes." and no plan
+func DryRunNoEffect(result *db.DryRunResult, runErr error) (string, bool) {
+	if result == nil || runErr != nil {
+		return "", false
+	}
+	tokens := parseShellTokens(result.Command)
+	if len(tokens) < 2 {
+		return "", false
+	}
+	output := ansiEscapeRe.ReplaceAllString(result.Output, "")
+
+	switch {
+	case tokens[0] == "kubectl" && tokens[1] == "delete":
+		stdout, stderr, _ := strings.Cut(output, dryRunStderrSeparator)
+		matched := false
+		for _, line := range strings.Split(stdout+"\n"+stderr, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if !kubectlNoResourcesRe.MatchString(line) {
+				return "", false
+			}
+			matched = true
+		}
+		if matched {
+			return "kubectl dry run found no resources to delete", true
+		}
+	case tokens[0] == "terraform" && tokens[1] == "plan" && hasFlag(tokens, "-destroy"):
+		if terraformNoChangesRe.MatchString(output) && !terraformPlanLineRe.MatchString(output) {
+			return "terraform destroy plan has no objects to destroy", true
+		}
+	}
+	return "", false
+}
+
+// checkDryRunNoop runs the command's dry run when general.dry_run_noop_action
+// could resolve it, returning the dry-run evidence and, when the dry run
+// shows no effect, the reason. CRITICAL commands and compound commands
+// (whose dry run covers only the first segment) are never resolved, and are
+// not dry-run here.
+func (rc *RequestCreator) checkDryRunNoop(opts CreateRequestOptions, tier RiskTier) (*db.DryRunResult, string) {
+	action := rc.config.DryRunNoopAction
+	if action == "" || action == DryRunNoopReview || tier == RiskTierCritical {
+		return nil, ""
+	}
+	if len(NormalizeCommand(opts.Command).Segments) != 1 {
+		return nil, ""
+	}
+	result, err := RunDryRun(&db.CommandSpec{Raw: opts.Command, Cwd: opts.Cwd})
+	if reason, ok := DryRunNoEffect(result, err); ok {
+		return result, reason
+	}
+	return result, ""
+}
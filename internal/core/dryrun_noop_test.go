@@ -0,0 +1,157 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestDryRunNoEffect(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *db.DryRunResult
+		err    error
+		want   bool
+	}{
+		{"kubectl no resources", &db.DryRunResult{Command: "kubectl delete pods -l app=web --dry-run=client -o yaml", Output: "No resources found in default namespace."}, nil, true},
+		{"kubectl no resources on stderr", &db.DryRunResult{Command: "kubectl delete pods -l app=web --dry-run=client", Output: "\n--- stderr ---\nNo resources found"}, nil, true},
+		{"kubectl deletes something", &db.DryRunResult{Command: "kubectl delete pods -l app=web --dry-run=client", Output: "pod \"web-1\" deleted (dry run)\nNo resources found"}, nil, false},
+		{"kubectl dry run failed", &db.DryRunResult{Command: "kubectl delete pod web --dry-run=client", Output: "No resources found"}, errors.New("dry-run exited with code 1"), false},
+		{"kubectl empty output", &db.DryRunResult{Command: "kubectl delete pod web --dry-run=client", Output: ""}, nil, false},
+		{"terraform no changes", &db.DryRunResult{Command: "terraform plan -destroy", Output: "\x1b[1mNo changes.\x1b[0m No objects need to be destroyed."}, nil, true},
+		{"terraform destroys", &db.DryRunResult{Command: "terraform plan -destroy", Output: "Plan: 0 to add, 0 to change, 3 to destroy."}, nil, false},
+		{"rm listing", &db.DryRunResult{Command: "ls -la -- build", Output: "No resources found"}, nil, false},
+		{"no dry run", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, got := DryRunNoEffect(tt.result, tt.err)
+			if got != tt.want {
+				t.Errorf("DryRunNoEffect() = %v (%q), want %v", got, reason, tt.want)
+			}
+			if got && reason == "" {
+				t.Error("expected a reason")
+			}
+		})
+	}
+}
+
+// fakeKubectl puts a kubectl on PATH that prints output on stderr.
+func fakeKubectl(t *testing.T, output string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s\\n' '" + output + "' >&2\n"
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func noopCreator(database *db.DB, action string) *RequestCreator {
+	cfg := DefaultRequestCreatorConfig()
+	cfg.AgentMailEnabled = false
+	cfg.DryRunNoopAction = action
+	return NewRequestCreator(database, nil, nil, cfg)
+}
+
+func TestCreateRequest_DryRunNoopAutoApproves(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	fakeKubectl(t, "No resources found in default namespace.")
+
+	result, err := noopCreator(database, DryRunNoopAutoApprove).CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "kubectl delete pods -l app=stale",
+		Cwd:           t.TempDir(),
+		Justification: Justification{Reason: "clean up stale pods"},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if result.Request == nil || result.Request.Status != db.StatusApproved {
+		t.Fatalf("expected an approved request, got %+v", result)
+	}
+	if result.NoopReason == "" || !strings.Contains(result.Request.TierReason, "auto-approved") {
+		t.Errorf("expected the no-op reason recorded, got %q / %q", result.NoopReason, result.Request.TierReason)
+	}
+	stored, err := database.GetRequest(result.Request.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != db.StatusApproved || stored.ApprovalExpiresAt == nil || stored.DryRun == nil {
+		t.Errorf("expected the approval and dry-run evidence stored, got %+v", stored)
+	}
+}
+
+func TestCreateRequest_DryRunWithMatchesNeedsReview(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	fakeKubectl(t, `pod "stale-1" deleted (dry run)`)
+
+	result, err := noopCreator(database, DryRunNoopAutoApprove).CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "kubectl delete pods -l app=stale",
+		Cwd:           t.TempDir(),
+		Justification: Justification{Reason: "clean up stale pods"},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if result.Request == nil || result.Request.Status != db.StatusPending || result.NoopReason != "" {
+		t.Fatalf("expected a pending request, got %+v", result)
+	}
+}
+
+func TestCreateRequest_DryRunNoopSkipAndCritical(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	fakeKubectl(t, "No resources found")
+
+	skip := noopCreator(database, DryRunNoopSkip)
+	result, err := skip.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "kubectl delete pods -l app=stale",
+		Justification: Justification{Reason: "clean up stale pods"},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if !result.Skipped || !strings.Contains(result.SkipReason, "no effect") {
+		t.Errorf("expected a skip, got %+v", result)
+	}
+
+	// The dry run only covers the first segment of a compound command.
+	result, err = skip.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "kubectl delete pods -l app=stale && rm -rf ./cache",
+		Justification: Justification{Reason: "clean up stale pods"},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if result.Skipped {
+		t.Errorf("expected a compound command to need review, got %+v", result)
+	}
+
+	// CRITICAL commands are reviewed whatever the dry run says.
+	result, err = skip.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "kubectl delete namespace staging",
+		Justification: Justification{Reason: "tear down staging"},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if result.Skipped || result.Request == nil || result.Request.Status != db.StatusPending {
+		t.Errorf("expected a CRITICAL request to need review, got %+v", result)
+	}
+}
@@ -0,0 +1,85 @@
+// Package core implements dry-run previews attached to new requests.
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Metadata keys on a dry-run preview attachment.
+const (
+	// DryRunPreviewKey marks an attachment as a dry-run preview.
+	DryRunPreviewKey = "dry_run_preview"
+	// PreviewFailedKey is set when the preview exited non-zero or timed
+	// out; whatever it printed is still attached.
+	PreviewFailedKey = "preview_failed"
+	// PreviewErrorKey says why the preview failed.
+	PreviewErrorKey = "preview_error"
+)
+
+// dryRunPreviewTimeout bounds a dry-run preview (a var so tests can shorten
+// it).
+var dryRunPreviewTimeout = defaultDryRunTimeout
+
+// RunDryRunPreview runs the dry-run variant of command in cwd and returns
+// its output as a context attachment, or nil when the command has no
+// dry-run variant. A preview that fails or times out is still returned,
+// marked preview_failed, so the request can go ahead without it.
+func RunDryRunPreview(ctx context.Context, command, cwd string, config *AttachmentConfig) *db.Attachment {
+	dryRun, ok := GetDryRunCommand(command)
+	if !ok {
+		return nil
+	}
+	cfg := DefaultAttachmentConfig()
+	if config != nil {
+		cfg = *config
+	}
+	cfg.MaxCommandRuntime = dryRunPreviewTimeout
+
+	attachment, err := runContextCommand(ctx, dryRun, cwd, &cfg)
+	if err != nil {
+		attachment = &db.Attachment{
+			Type:     db.AttachmentTypeContext,
+			Content:  err.Error(),
+			Metadata: map[string]any{"source": dryRun},
+		}
+	}
+	attachment.Metadata[DryRunPreviewKey] = true
+
+	failure := ""
+	switch {
+	case err != nil:
+		failure = err.Error()
+	case attachment.Metadata["timed_out"] == true:
+		failure = fmt.Sprintf("timed out after %s", dryRunPreviewTimeout)
+	case attachment.Metadata["exit_code"] != 0:
+		failure = fmt.Sprintf("exited with code %v", attachment.Metadata["exit_code"])
+	}
+	if failure != "" {
+		attachment.Metadata[PreviewFailedKey] = true
+		attachment.Metadata[PreviewErrorKey] = failure
+	}
+	return attachment
+}
+
+// HasDryRunPreview reports whether attachments include a dry-run preview.
+func HasDryRunPreview(attachments []db.Attachment) bool {
+	for _, a := range attachments {
+		if v, _ := a.Metadata[DryRunPreviewKey].(bool); v {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsDryRunPreview reports whether a request at tier gets a dry-run
+// preview: always with Preview, and for DANGEROUS and CRITICAL commands
+// with AutoPreview.
+func wantsDryRunPreview(opts CreateRequestOptions, tier RiskTier) bool {
+	if opts.Preview {
+		return true
+	}
+	return opts.AutoPreview && (tier == RiskTierDangerous || tier == RiskTierCritical)
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func previewCreator(database *db.DB) *RequestCreator {
+	cfg := DefaultRequestCreatorConfig()
+	cfg.AgentMailEnabled = false
+	return NewRequestCreator(database, nil, nil, cfg)
+}
+
+func TestCreateRequest_AttachesDryRunPreview(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("rm's dry-run variant is ls")
+	}
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	cwd := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(cwd, "build"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cwd, "build", "marker.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "rm -rf build",
+		Cwd:           cwd,
+		Justification: Justification{Reason: "clean build"},
+	}
+	result, err := previewCreator(database).CreateRequest(opts)
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if result.Preview != nil || HasDryRunPreview(result.Request.Attachments) {
+		t.Fatal("expected no preview without Preview or AutoPreview")
+	}
+
+	opts.AutoPreview = true
+	result, err = previewCreator(database).CreateRequest(opts)
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if result.Request.RiskTier != RiskTierDangerous && result.Request.RiskTier != RiskTierCritical {
+		t.Fatalf("expected a dangerous or critical request, got %s", result.Request.RiskTier)
+	}
+	stored, err := database.GetRequest(result.Request.ID)
+	if err != nil {
+		t.Fatalf("GetRequest: %v", err)
+	}
+	if !HasDryRunPreview(stored.Attachments) {
+		t.Fatalf("expected a stored dry-run preview, got %+v", stored.Attachments)
+	}
+	preview := stored.Attachments[0]
+	if preview.Type != db.AttachmentTypeContext || !IsAutoCollected(preview) {
+		t.Errorf("expected an auto-collected context attachment, got %+v", preview)
+	}
+	// The dry run ran in the request's working directory.
+	if !strings.Contains(preview.Content, "marker.txt") {
+		t.Errorf("expected the listing of build, got %q", preview.Content)
+	}
+	if _, failed := preview.Metadata[PreviewFailedKey]; failed {
+		t.Errorf("expected a successful preview, got %v", preview.Metadata)
+	}
+}
+
+func TestCreateRequest_FailedPreviewStillCreatesRequest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte("#!/bin/sh\n[ \"$1\" = delete ] && exec sleep 5\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	oldTimeout := dryRunPreviewTimeout
+	dryRunPreviewTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { dryRunPreviewTimeout = oldTimeout })
+
+	result, err := previewCreator(database).CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "kubectl delete deployment web",
+		Cwd:           t.TempDir(),
+		Justification: Justification{Reason: "remove web"},
+		Preview:       true,
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if result.Request == nil || result.Request.Status != db.StatusPending {
+		t.Fatalf("expected a pending request, got %+v", result.Request)
+	}
+	if result.Preview == nil || result.Preview.Metadata[PreviewFailedKey] != true {
+		t.Fatalf("expected a failed preview, got %+v", result.Preview)
+	}
+	if reason, _ := result.Preview.Metadata[PreviewErrorKey].(string); !strings.Contains(reason, "timed out") {
+		t.Errorf("expected a timeout reason, got %q", reason)
+	}
+	if !HasDryRunPreview(result.Request.Attachments) {
+		t.Error("expected the failed preview to be attached")
+	}
+}
+
+func TestRunDryRunPreview_NoVariant(t *testing.T) {
+	if got := RunDryRunPreview(t.Context(), "echo hello", t.TempDir(), nil); got != nil {
+		t.Errorf("expected no preview for a command without a dry-run variant, got %+v", got)
+	}
+}
@@ -0,0 +1,214 @@
+// Package core implements redaction of dry-run output before it is stored.
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// lastAppliedAnnotation holds a full copy of the applied object, secrets
+// included.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+var (
+	yamlSecretKindRe   = regexp.MustCompile(`(?m)^\s*(-\s+)?kind:\s*["']?Secret["']?\s*$`)
+	yamlSecretDataRe   = regexp.MustCompile(`^(\s*)(-\s+)?(data|stringData):\s*(.*)$`)
+	yamlLastAppliedRe  = regexp.MustCompile(`^(\s*)(-\s+)?` + regexp.QuoteMeta(lastAppliedAnnotation) + `:`)
+	yamlDocSeparatorRe = regexp.MustCompile(`^---\s*$`)
+)
+
+// RedactDryRunOutput masks secrets in dry-run output before reviewers see
+// it: the data, stringData and last-applied-configuration of Kubernetes
+// Secret manifests (YAML or JSON), then the default and custom redaction
+// patterns.
+func RedactDryRunOutput(output string, customPatterns []string) string {
+	if output == "" {
+		return output
+	}
+	// RunDryRun appends stderr after stdout; only stdout holds manifests.
+	stdout, stderr, hasStderr := strings.Cut(output, dryRunStderrSeparator)
+	if redacted, ok := redactSecretJSON(stdout); ok {
+		stdout = redacted
+	} else {
+		stdout = redactSecretYAML(stdout)
+	}
+	if hasStderr {
+		stdout += dryRunStderrSeparator + stderr
+	}
+	return ApplyRedaction(stdout, customPatterns)
+}
+
+// PrepareDryRun returns the dry-run evidence to store on a request: redacted,
+// or with the output withheld entirely for tiers listed in withholdTiers.
+func PrepareDryRun(dryRun *db.DryRunResult, tier RiskTier, withholdTiers []RiskTier, customPatterns []string) *db.DryRunResult {
+	if dryRun == nil {
+		return nil
+	}
+	prepared := &db.DryRunResult{
+		Command: ApplyRedaction(dryRun.Command, customPatterns),
+		Output:  RedactDryRunOutput(dryRun.Output, customPatterns),
+	}
+	for _, t := range withholdTiers {
+		if t == tier && prepared.Output != "" {
+			prepared.Output = fmt.Sprintf("[dry-run output withheld for %s requests; run the dry-run command above to inspect it]", tier)
+			break
+		}
+	}
+	return prepared
+}
+
+// redactSecretYAML masks Secret data in each YAML document that declares
+// kind: Secret. Within such a document every data/stringData mapping is
+// masked, so a List mixing Secrets and ConfigMaps errs towards hiding more.
+func redactSecretYAML(output string) string {
+	lines := strings.Split(output, "\n")
+	var out []string
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && !yamlDocSeparatorRe.MatchString(lines[i]) {
+			continue
+		}
+		doc := lines[start:i]
+		if yamlSecretKindRe.MatchString(strings.Join(doc, "\n")) {
+			doc = redactSecretYAMLDoc(doc)
+		}
+		out = append(out, doc...)
+		if i < len(lines) {
+			out = append(out, lines[i])
+		}
+		start = i + 1
+	}
+	return strings.Join(out, "\n")
+}
+
+// redactSecretYAMLDoc masks one YAML document. Nested lines under a masked
+// key (block scalars, last-applied JSON) are dropped.
+func redactSecretYAMLDoc(doc []string) []string {
+	out := make([]string, 0, len(doc))
+	dataIndent, entryIndent, skipIndent := -1, -1, -1
+	for _, line := range doc {
+		indent := yamlKeyIndent(line)
+		if strings.TrimSpace(line) == "" {
+			out = append(out, line)
+			continue
+		}
+		if skipIndent >= 0 {
+			if indent > skipIndent {
+				continue
+			}
+			skipIndent = -1
+		}
+		if dataIndent >= 0 {
+			if indent > dataIndent {
+				if entryIndent < 0 {
+					entryIndent = indent
+				}
+				if indent == entryIndent {
+					if idx := strings.Index(line, ":"); idx >= 0 {
+						out = append(out, line[:idx+1]+" [REDACTED]")
+					}
+				}
+				continue
+			}
+			dataIndent, entryIndent = -1, -1
+		}
+
+		if m := yamlSecretDataRe.FindStringSubmatch(line); m != nil {
+			value := strings.TrimSpace(m[4])
+			switch {
+			case value == "":
+				dataIndent = len(m[1]) + len(m[2])
+				out = append(out, line)
+			case value == "{}":
+				out = append(out, line)
+			default:
+				out = append(out, m[1]+m[2]+m[3]+": [REDACTED]")
+			}
+			continue
+		}
+		if m := yamlLastAppliedRe.FindStringSubmatch(line); m != nil {
+			out = append(out, m[0]+" [REDACTED]")
+			skipIndent = len(m[1]) + len(m[2])
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// yamlKeyIndent returns the column a line's key starts at, counting a list
+// item's "- " as indentation.
+func yamlKeyIndent(line string) int {
+	trimmed := strings.TrimLeft(line, " ")
+	indent := len(line) - len(trimmed)
+	for strings.HasPrefix(trimmed, "- ") {
+		rest := strings.TrimLeft(trimmed[2:], " ")
+		indent += len(trimmed) - len(rest)
+		trimmed = rest
+	}
+	return indent
+}
+
+// redactSecretJSON masks Secret data in JSON output (kubectl -o json). It
+// reports false if output is not a JSON object.
+func redactSecretJSON(output string) (string, bool) {
+	trimmed := strings.TrimSpace(output)
+	if !strings.HasPrefix(trimmed, "{") {
+		return "", false
+	}
+	var doc any
+	if err := json.Unmarshal([]byte(trimmed), &doc); err != nil {
+		return "", false
+	}
+	if !redactSecretObjects(doc) {
+		return output, true
+	}
+	data, err := json.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// redactSecretObjects masks Secret objects anywhere in v, reporting whether
+// anything changed.
+func redactSecretObjects(v any) bool {
+	changed := false
+	switch t := v.(type) {
+	case map[string]any:
+		if t["kind"] == "Secret" {
+			for _, key := range []string{"data", "stringData"} {
+				if data, ok := t[key].(map[string]any); ok {
+					for k := range data {
+						data[k] = "[REDACTED]"
+						changed = true
+					}
+				}
+			}
+			if meta, ok := t["metadata"].(map[string]any); ok {
+				if ann, ok := meta["annotations"].(map[string]any); ok {
+					if _, ok := ann[lastAppliedAnnotation]; ok {
+						ann[lastAppliedAnnotation] = "[REDACTED]"
+						changed = true
+					}
+				}
+			}
+		}
+		for _, child := range t {
+			if redactSecretObjects(child) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, child := range t {
+			if redactSecretObjects(child) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
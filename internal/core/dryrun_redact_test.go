@@ -0,0 +1,122 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// secretManifest is kubectl delete secret --dry-run=client -o yaml output.
+const secretManifest = `apiVersion: v1
+data:
+  password: aHVudGVyMg==
+  tls.key: LS0tLS1CRUdJTi1wcml2YXRl
+kind: Secret
+metadata:
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: |
+      {"apiVersion":"v1","data":{"password":"aHVudGVyMg=="},"kind":"Secret"}
+  name: db-creds
+  namespace: prod
+stringData:
+  api: plain-text-value
+type: Opaque`
+
+func assertNoSecrets(t *testing.T, out string) {
+	t.Helper()
+	for _, secret := range []string{"aHVudGVyMg==", "LS0tLS1CRUdJTi1wcml2YXRl", "plain-text-value"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("secret %q survived redaction:\n%s", secret, out)
+		}
+	}
+}
+
+func TestRedactDryRunOutput_SecretYAML(t *testing.T) {
+	out := RedactDryRunOutput(secretManifest+"\n--- stderr ---\nsecret \"db-creds\" deleted (dry run)", nil)
+	assertNoSecrets(t, out)
+	for _, want := range []string{"  tls.key: [REDACTED]", "name: db-creds", "namespace: prod", "type: Opaque", `secret "db-creds" deleted`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRedactDryRunOutput_SecretList(t *testing.T) {
+	list := `apiVersion: v1
+items:
+- apiVersion: v1
+  data:
+    db-url: aHVudGVyMg==
+  kind: Secret
+  metadata:
+    name: a
+kind: List
+---
+apiVersion: v1
+data:
+  color: blue
+kind: ConfigMap`
+	out := RedactDryRunOutput(list, nil)
+	assertNoSecrets(t, out)
+	if !strings.Contains(out, "    db-url: [REDACTED]") || !strings.Contains(out, "  color: blue") {
+		t.Errorf("expected only the Secret document masked:\n%s", out)
+	}
+}
+
+func TestRedactDryRunOutput_SecretJSON(t *testing.T) {
+	out := RedactDryRunOutput(`{"apiVersion":"v1","kind":"Secret","data":{"password":"aHVudGVyMg=="},"stringData":{"api":"plain-text-value"},"metadata":{"name":"x"}}`, nil)
+	assertNoSecrets(t, out)
+	if !strings.Contains(out, `"name": "x"`) {
+		t.Errorf("expected metadata kept:\n%s", out)
+	}
+}
+
+func TestPrepareDryRun_WithholdsConfiguredTiers(t *testing.T) {
+	dr := &db.DryRunResult{Command: "kubectl delete secret db-creds --dry-run=client -o yaml", Output: secretManifest}
+
+	kept := PrepareDryRun(dr, RiskTierDangerous, []RiskTier{RiskTierCritical}, nil)
+	if !strings.Contains(kept.Output, "name: db-creds") {
+		t.Errorf("dangerous output should be kept redacted, got %q", kept.Output)
+	}
+	withheld := PrepareDryRun(dr, RiskTierCritical, []RiskTier{RiskTierCritical}, nil)
+	if withheld.Command != dr.Command || !strings.Contains(withheld.Output, "withheld") || strings.Contains(withheld.Output, "db-creds") {
+		t.Errorf("critical output should be withheld, got %+v", withheld)
+	}
+	if dr.Output != secretManifest {
+		t.Error("input dry run was modified")
+	}
+}
+
+func TestCreateRequest_StoresRedactedSecretDryRun(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+
+	creator := NewRequestCreator(database, nil, nil, nil)
+	result, err := creator.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "kubectl delete secret db-creds -n prod",
+		Cwd:           t.TempDir(),
+		Justification: Justification{Reason: "rotate credentials"},
+		DryRun:        &db.DryRunResult{Command: "kubectl delete secret db-creds -n prod --dry-run=client -o yaml", Output: secretManifest},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if result.Request == nil {
+		t.Fatalf("expected a request, got skip: %s", result.SkipReason)
+	}
+
+	stored, err := database.GetRequest(result.Request.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.DryRun == nil {
+		t.Fatal("dry run not stored")
+	}
+	assertNoSecrets(t, stored.DryRun.Output)
+	if !strings.Contains(stored.DryRun.Output, "tls.key: [REDACTED]") {
+		t.Errorf("expected redacted manifest, got:\n%s", stored.DryRun.Output)
+	}
+}
@@ -0,0 +1,192 @@
+// Package core implements local evidence-interaction tracking for reviews.
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Evidence section identifiers recorded in db.EvidenceView.Section.
+const (
+	// EvidenceSectionDryRun is the dry-run output of a request.
+	EvidenceSectionDryRun = "dry_run"
+	// EvidenceSectionJustification is the requestor's justification.
+	EvidenceSectionJustification = "justification"
+)
+
+// Unviewed-evidence actions (general.unviewed_evidence_action).
+const (
+	// UnviewedEvidenceWarn warns but allows the approval.
+	UnviewedEvidenceWarn = "warn"
+	// UnviewedEvidenceBlockCritical refuses CRITICAL approvals unless acknowledged.
+	UnviewedEvidenceBlockCritical = "block_critical"
+)
+
+// EvidenceAttachmentSection returns the section identifier for the attachment at index i.
+func EvidenceAttachmentSection(i int) string {
+	return fmt.Sprintf("attachment:%d", i)
+}
+
+// evidenceLog is the on-disk record of one session's evidence views for one request.
+type evidenceLog struct {
+	RequestID string            `json:"request_id"`
+	SessionID string            `json:"session_id"`
+	Views     []db.EvidenceView `json:"views"`
+}
+
+// evidenceLogPath returns where a session's evidence views for a request are
+// kept locally. Views are per viewing session so that one reviewer (or the
+// requestor) opening the evidence does not count for another.
+func evidenceLogPath(projectPath, requestID, sessionID string) string {
+	return filepath.Join(projectPath, ".slb", "evidence", sanitizeFilename(requestID), sanitizeFilename(sessionID)+".json")
+}
+
+// LoadEvidenceViews returns the evidence views a session recorded for a
+// request. A missing record is not an error and yields no views.
+func LoadEvidenceViews(projectPath, requestID, sessionID string) ([]db.EvidenceView, error) {
+	if strings.TrimSpace(sessionID) == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(evidenceLogPath(projectPath, requestID, sessionID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading evidence log: %w", err)
+	}
+	var record evidenceLog
+	if err := json.Unmarshal(b, &record); err != nil {
+		return nil, fmt.Errorf("parsing evidence log: %w", err)
+	}
+	return record.Views, nil
+}
+
+// RecordEvidenceViews merges views into a session's local record for a
+// request. Repeated views of a section keep the earliest OpenedAt and sum
+// durations.
+func RecordEvidenceViews(projectPath, requestID, sessionID string, views ...db.EvidenceView) error {
+	if strings.TrimSpace(requestID) == "" {
+		return fmt.Errorf("request id is required")
+	}
+	if strings.TrimSpace(sessionID) == "" {
+		return fmt.Errorf("session id is required")
+	}
+	if len(views) == 0 {
+		return nil
+	}
+
+	existing, err := LoadEvidenceViews(projectPath, requestID, sessionID)
+	if err != nil {
+		return err
+	}
+	merged := MergeEvidenceViews(existing, views)
+
+	path := evidenceLogPath(projectPath, requestID, sessionID)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating evidence dir: %w", err)
+	}
+	b, err := json.MarshalIndent(evidenceLog{RequestID: requestID, SessionID: sessionID, Views: merged}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal evidence log: %w", err)
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		return fmt.Errorf("writing evidence log: %w", err)
+	}
+	return nil
+}
+
+// ClearEvidenceViews removes a session's local record for a request once it
+// has been attached to a submitted review.
+func ClearEvidenceViews(projectPath, requestID, sessionID string) error {
+	if strings.TrimSpace(sessionID) == "" {
+		return nil
+	}
+	err := os.Remove(evidenceLogPath(projectPath, requestID, sessionID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing evidence log: %w", err)
+	}
+	return nil
+}
+
+// MergeEvidenceViews combines two view lists, one entry per section, in first-seen order.
+func MergeEvidenceViews(a, b []db.EvidenceView) []db.EvidenceView {
+	out := make([]db.EvidenceView, 0, len(a)+len(b))
+	index := make(map[string]int)
+	for _, v := range append(append([]db.EvidenceView{}, a...), b...) {
+		if i, ok := index[v.Section]; ok {
+			if !v.OpenedAt.IsZero() && (out[i].OpenedAt.IsZero() || v.OpenedAt.Before(out[i].OpenedAt)) {
+				out[i].OpenedAt = v.OpenedAt
+			}
+			out[i].DurationMs += v.DurationMs
+			continue
+		}
+		index[v.Section] = len(out)
+		out = append(out, v)
+	}
+	return out
+}
+
+// UnviewedEvidence returns the dry-run and diff sections of a request that
+// do not appear in views.
+func UnviewedEvidence(req *db.Request, views []db.EvidenceView) []string {
+	if req == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(views))
+	for _, v := range views {
+		seen[v.Section] = true
+	}
+
+	var missing []string
+	if req.DryRun != nil && req.DryRun.Output != "" && !seen[EvidenceSectionDryRun] {
+		missing = append(missing, EvidenceSectionDryRun)
+	}
+	for i, att := range req.Attachments {
+		if att.Type != db.AttachmentTypeGitDiff {
+			continue
+		}
+		if section := EvidenceAttachmentSection(i); !seen[section] {
+			missing = append(missing, section)
+		}
+	}
+	return missing
+}
+
+// ErrUnviewedEvidence is returned by CheckUnviewedEvidence when a CRITICAL
+// approval is blocked because its evidence was not viewed.
+var ErrUnviewedEvidence = errors.New("unviewed evidence on CRITICAL request")
+
+// CheckUnviewedEvidence is the evidence gate every approval path applies
+// before SubmitReview. It returns the evidence sections of req missing from
+// views, which the caller warns about, or an error wrapping
+// ErrUnviewedEvidence when the configured UnviewedEvidenceAction blocks a
+// CRITICAL approval. An acknowledged approval passes without a warning.
+func (rs *ReviewService) CheckUnviewedEvidence(req *db.Request, views []db.EvidenceView, acknowledged bool) ([]string, error) {
+	unviewed := UnviewedEvidence(req, views)
+	if len(unviewed) == 0 || acknowledged {
+		return nil, nil
+	}
+	if req.RiskTier == db.RiskTierCritical && rs.config.UnviewedEvidenceAction == UnviewedEvidenceBlockCritical {
+		return unviewed, fmt.Errorf("%w: %s", ErrUnviewedEvidence, strings.Join(unviewed, ", "))
+	}
+	return unviewed, nil
+}
+
+// NewEvidenceView builds a view for a section opened at openedAt and shown for d.
+func NewEvidenceView(section string, openedAt time.Time, d time.Duration) db.EvidenceView {
+	if d < 0 {
+		d = 0
+	}
+	return db.EvidenceView{
+		Section:    section,
+		OpenedAt:   openedAt.UTC(),
+		DurationMs: d.Milliseconds(),
+	}
+}
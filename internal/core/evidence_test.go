@@ -0,0 +1,139 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func TestEvidenceViews_RecordLoadClear(t *testing.T) {
+	project := t.TempDir()
+	t0 := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	views, err := LoadEvidenceViews(project, "req-1", "sess-a")
+	if err != nil {
+		t.Fatalf("LoadEvidenceViews on missing record: %v", err)
+	}
+	if len(views) != 0 {
+		t.Fatalf("expected no views, got %+v", views)
+	}
+
+	if err := RecordEvidenceViews(project, "req-1", "sess-a", NewEvidenceView(EvidenceSectionDryRun, t0.Add(time.Minute), 2*time.Second)); err != nil {
+		t.Fatalf("RecordEvidenceViews: %v", err)
+	}
+	if err := RecordEvidenceViews(project, "req-1", "sess-a",
+		NewEvidenceView(EvidenceSectionDryRun, t0, time.Second),
+		NewEvidenceView(EvidenceAttachmentSection(0), t0, 500*time.Millisecond),
+	); err != nil {
+		t.Fatalf("RecordEvidenceViews: %v", err)
+	}
+
+	views, err = LoadEvidenceViews(project, "req-1", "sess-a")
+	if err != nil {
+		t.Fatalf("LoadEvidenceViews: %v", err)
+	}
+	want := []db.EvidenceView{
+		{Section: EvidenceSectionDryRun, OpenedAt: t0, DurationMs: 3000},
+		{Section: "attachment:0", OpenedAt: t0, DurationMs: 500},
+	}
+	if !reflect.DeepEqual(views, want) {
+		t.Fatalf("views = %+v, want %+v", views, want)
+	}
+
+	if err := ClearEvidenceViews(project, "req-1", "sess-a"); err != nil {
+		t.Fatalf("ClearEvidenceViews: %v", err)
+	}
+	if err := ClearEvidenceViews(project, "req-1", "sess-a"); err != nil {
+		t.Fatalf("ClearEvidenceViews on missing record: %v", err)
+	}
+	views, _ = LoadEvidenceViews(project, "req-1", "sess-a")
+	if len(views) != 0 {
+		t.Fatalf("expected views cleared, got %+v", views)
+	}
+}
+
+func TestEvidenceViews_PerSession(t *testing.T) {
+	project := t.TempDir()
+	if err := RecordEvidenceViews(project, "req-1", "requestor", NewEvidenceView(EvidenceSectionDryRun, time.Now(), 0)); err != nil {
+		t.Fatalf("RecordEvidenceViews: %v", err)
+	}
+	views, err := LoadEvidenceViews(project, "req-1", "reviewer")
+	if err != nil {
+		t.Fatalf("LoadEvidenceViews: %v", err)
+	}
+	if len(views) != 0 {
+		t.Errorf("another session's views leaked to the reviewer: %+v", views)
+	}
+	if views, _ := LoadEvidenceViews(project, "req-1", ""); len(views) != 0 {
+		t.Errorf("expected no views without a session, got %+v", views)
+	}
+	if err := RecordEvidenceViews(project, "req-1", "", NewEvidenceView(EvidenceSectionDryRun, time.Now(), 0)); err == nil {
+		t.Error("expected error recording without a session id")
+	}
+}
+
+func TestRecordEvidenceViews_RequiresRequestID(t *testing.T) {
+	if err := RecordEvidenceViews(t.TempDir(), " ", "sess-a", NewEvidenceView(EvidenceSectionDryRun, time.Now(), 0)); err == nil {
+		t.Fatal("expected error for empty request id")
+	}
+}
+
+func TestUnviewedEvidence(t *testing.T) {
+	req := &db.Request{
+		DryRun: &db.DryRunResult{Command: "ls", Output: "a"},
+		Attachments: []db.Attachment{
+			{Type: db.AttachmentTypeContext, Content: "ctx"},
+			{Type: db.AttachmentTypeGitDiff, Content: "diff --git a b"},
+		},
+	}
+
+	got := UnviewedEvidence(req, nil)
+	if want := []string{EvidenceSectionDryRun, "attachment:1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("UnviewedEvidence = %v, want %v", got, want)
+	}
+
+	views := []db.EvidenceView{{Section: EvidenceSectionDryRun}, {Section: "attachment:1"}}
+	if got := UnviewedEvidence(req, views); len(got) != 0 {
+		t.Fatalf("expected all evidence viewed, got %v", got)
+	}
+
+	if got := UnviewedEvidence(&db.Request{}, nil); len(got) != 0 {
+		t.Fatalf("expected nothing unviewed without evidence, got %v", got)
+	}
+}
+
+func TestCheckUnviewedEvidence(t *testing.T) {
+	critical := &db.Request{RiskTier: db.RiskTierCritical, DryRun: &db.DryRunResult{Command: "ls", Output: "a"}}
+	dangerous := &db.Request{RiskTier: db.RiskTierDangerous, DryRun: &db.DryRunResult{Command: "ls", Output: "a"}}
+	blocking := DefaultReviewConfig()
+	blocking.UnviewedEvidenceAction = UnviewedEvidenceBlockCritical
+	block := NewReviewService(nil, blocking)
+
+	if _, err := block.CheckUnviewedEvidence(critical, nil, false); !errors.Is(err, ErrUnviewedEvidence) {
+		t.Fatalf("expected ErrUnviewedEvidence for a CRITICAL approval, got %v", err)
+	}
+	if unviewed, err := block.CheckUnviewedEvidence(critical, nil, true); err != nil || len(unviewed) != 0 {
+		t.Fatalf("acknowledged approval: unviewed %v, err %v", unviewed, err)
+	}
+	if unviewed, err := block.CheckUnviewedEvidence(dangerous, nil, false); err != nil || len(unviewed) != 1 {
+		t.Fatalf("dangerous approval should only warn: unviewed %v, err %v", unviewed, err)
+	}
+	warn := NewReviewService(nil, DefaultReviewConfig())
+	if unviewed, err := warn.CheckUnviewedEvidence(critical, nil, false); err != nil || len(unviewed) != 1 {
+		t.Fatalf("warn action should only warn: unviewed %v, err %v", unviewed, err)
+	}
+	views := []db.EvidenceView{{Section: EvidenceSectionDryRun}}
+	if unviewed, err := block.CheckUnviewedEvidence(critical, views, false); err != nil || len(unviewed) != 0 {
+		t.Fatalf("viewed evidence: unviewed %v, err %v", unviewed, err)
+	}
+}
+
+func TestNewEvidenceView_ClampsNegativeDuration(t *testing.T) {
+	v := NewEvidenceView(EvidenceSectionJustification, time.Now(), -time.Second)
+	if v.DurationMs != 0 {
+		t.Fatalf("DurationMs = %d, want 0", v.DurationMs)
+	}
+}
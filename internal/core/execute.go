@@ -22,6 +22,8 @@ var (
 	ErrAlreadyExecuted     = errors.New("request has already been executed")
 	ErrAlreadyExecuting    = errors.New("request is already being executed")
 	ErrExecutionTimeout    = errors.New("command execution timed out")
+	ErrNoApprovedPrefix    = errors.New("first segment was not approved")
+	ErrUnsafeSegmentSkip   = errors.New("skipping an unapproved segment would change later segments")
 )
 
 // DefaultExecutionTimeout is the default timeout for command execution.
@@ -47,6 +49,33 @@ type ExecuteOptions struct {
 	CaptureRollback bool
 	// MaxRollbackSizeMB limits filesystem rollback capture (0 uses config default).
 	MaxRollbackSizeMB int
+	// RollbackRootPrefix names filesystem capture roots (see
+	// RollbackCaptureOptions.RootPrefix); empty uses the default "p".
+	RollbackRootPrefix string
+	// MaxRollbackCaptures caps the number of rollback captures kept per
+	// project (0 means no cap).
+	MaxRollbackCaptures int
+	// MaxConcurrentCaptures and RollbackCaptureWait limit captures running
+	// at once in this process (see RollbackCaptureOptions).
+	MaxConcurrentCaptures int
+	RollbackCaptureWait   time.Duration
+	// RollbackExclude lists gitignore-style patterns left out of
+	// filesystem captures (see RollbackCaptureOptions.Exclude).
+	RollbackExclude []string
+
+	// MaxConcurrentExecutions limits DANGEROUS and CRITICAL executions
+	// running at once in the request's project (0 means no limit). When the
+	// slots are full the request waits in the project's execution queue for
+	// up to ExecutionQueueWait, then fails with ErrExecutionQueued.
+	MaxConcurrentExecutions int
+	ExecutionQueueWait      time.Duration
+	// OnExecutionQueued, if set, is called once when the request starts
+	// waiting for an execution slot.
+	OnExecutionQueued func(*ExecutionQueueStatus)
+
+	// RunAllApprovedSegments runs every approved segment of a partially
+	// approved request instead of only the contiguously approved prefix.
+	RunAllApprovedSegments bool
 }
 
 // ExecutionResult holds the result of command execution.
@@ -63,6 +92,8 @@ type ExecutionResult struct {
 	Output string
 	// TimedOut indicates if the command timed out.
 	TimedOut bool
+	// Segments holds per-segment results for a partially approved request.
+	Segments []db.SegmentExecution
 	// Error contains any execution error.
 	Error error
 }
@@ -72,6 +103,10 @@ type Executor struct {
 	db            *db.DB
 	patternEngine *PatternEngine
 	notifier      integrations.RequestNotifier
+	riskOverrides []RiskOverrideRule
+
+	changeRecordURL   string
+	changeRecordTiers []string
 }
 
 // NewExecutor creates a new executor.
@@ -94,6 +129,40 @@ func (e *Executor) WithNotifier(n integrations.RequestNotifier) *Executor {
 	return e
 }
 
+// WithRiskOverrides sets the risk override rules applied when re-checking a
+// request's tier before execution, so a tier an override lowered at creation
+// is not mistaken for an escalation.
+func (e *Executor) WithRiskOverrides(rules []RiskOverrideRule) *Executor {
+	e.riskOverrides = rules
+	return e
+}
+
+// WithChangeRecords queues a change record for delivery to url after each
+// execution of a request in tiers. An empty url disables change records.
+func (e *Executor) WithChangeRecords(url string, tiers []string) *Executor {
+	e.changeRecordURL = url
+	e.changeRecordTiers = tiers
+	return e
+}
+
+// currentClassification classifies request's command under the current
+// patterns and risk overrides. A tier lowered by a trusted script whose use
+// still verifies (see VerifyTrustedScriptUse) stays lowered.
+func (e *Executor) currentClassification(request *db.Request, trustUse *db.TrustedScriptUse) (*MatchResult, error) {
+	overrides, err := CompileRiskOverrides(e.riskOverrides)
+	if err != nil {
+		return nil, err
+	}
+	classification := e.patternEngine.ClassifyCommand(request.Command.Raw, request.Command.Cwd)
+	classification, _ = overrides.Apply(classification, request.Command.Raw)
+	if trustUse != nil && classification.Tier == trustUse.FromTier {
+		lowered := *classification
+		lowered.Tier = trustUse.ToTier
+		classification = &lowered
+	}
+	return classification, nil
+}
+
 // ExecuteApprovedRequest validates and executes an approved request.
 // This runs the command in the CALLER'S shell environment (client-side execution).
 func (e *Executor) ExecuteApprovedRequest(ctx context.Context, opts ExecuteOptions) (*ExecutionResult, error) {
@@ -136,28 +205,79 @@ func (e *Executor) ExecuteApprovedRequest(ctx context.Context, opts ExecuteOptio
 	if request.Status == db.StatusExecuted || request.Status == db.StatusExecutionFailed {
 		return nil, ErrAlreadyExecuted
 	}
+	if request.Status == db.StatusApprovalExpired {
+		return nil, approvalExpiredError(request.ID)
+	}
 	if request.Status != db.StatusApproved {
 		return nil, fmt.Errorf("%w: status is %s", ErrRequestNotApproved, request.Status)
 	}
 
-	// Gate 2: Approval must not be expired
-	if request.ApprovalExpiresAt != nil && time.Now().After(*request.ApprovalExpiresAt) {
-		return nil, ErrApprovalExpired
+	// Gate 2: Approval must not be expired. The request is parked as
+	// approval_expired so its requestor can ask for it to be re-reviewed.
+	if CheckApprovalExpiry(request) {
+		_ = e.db.UpdateRequestStatus(request.ID, db.StatusApprovalExpired)
+		return nil, approvalExpiredError(request.ID)
 	}
 
-	// Gate 3: Command hash must match (prevents mutation)
-	expectedHash := db.ComputeCommandHash(request.Command)
-	if expectedHash != request.Command.Hash {
-		return nil, fmt.Errorf("%w: stored=%s computed=%s", ErrCommandHashMismatch, request.Command.Hash, expectedHash)
+	// Gate 3: Command hash must match the one approved (prevents mutation)
+	if err := verifyApprovedCommand(e.db, request); err != nil {
+		return nil, err
+	}
+	if err := VerifyMigrations(request.Command, request.Migrations); err != nil {
+		return nil, err
+	}
+	trustUse, err := VerifyTrustedScriptUse(e.db, request)
+	if err != nil {
+		return nil, err
 	}
 
 	// Gate 4: Current pattern policy doesn't require higher tier
-	classification := e.patternEngine.ClassifyCommand(request.Command.Raw, request.Command.Cwd)
+	classification, err := e.currentClassification(request, trustUse)
+	if err != nil {
+		return nil, err
+	}
 	if tierHigher(classification.Tier, request.RiskTier) {
 		return nil, fmt.Errorf("%w: approved as %s but now classified as %s",
 			ErrTierEscalated, request.RiskTier, classification.Tier)
 	}
 
+	// Gate 5: Pinned cluster/cloud context must still exist; the command is
+	// run against it explicitly rather than whatever is active now.
+	pin, err := pinContext(ctx, request.Command, request.PinnedContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gate 6: A partially approved request runs only its approved segments,
+	// in one shell with the original operators, and never when a skipped
+	// segment would have changed the state the approved ones run in.
+	var plan []int
+	if len(request.ApprovedSegments) > 0 {
+		plan = segmentRunPlan(request.ApprovedSegments, opts.RunAllApprovedSegments)
+		if len(plan) == 0 {
+			return nil, fmt.Errorf("%w: approved segments %v (set general.run_all_approved_segments to run them anyway)",
+				ErrNoApprovedPrefix, request.ApprovedSegments)
+		}
+		if err := checkSegmentSkips(CommandSegments(request.Command.Raw), plan); err != nil {
+			return nil, err
+		}
+	}
+
+	// Gate 7: Wait for one of the project's execution slots, so approved
+	// destructive commands in one project do not run simultaneously.
+	holdsSlot, err := e.acquireExecutionSlot(ctx, request, opts)
+	if err != nil {
+		return nil, err
+	}
+	if holdsSlot {
+		defer func() { _ = e.db.ReleaseExecutionClaim(request.ID) }()
+		// The approval may have lapsed while the request was queued.
+		if CheckApprovalExpiry(request) {
+			_ = e.db.UpdateRequestStatus(request.ID, db.StatusApprovalExpired)
+			return nil, approvalExpiredError(request.ID)
+		}
+	}
+
 	// Preflight: create log file and capture rollback state before locking EXECUTING.
 	logPath, err := e.createLogFile(opts.LogDir, request.ID)
 	if err != nil {
@@ -166,8 +286,21 @@ func (e *Executor) ExecuteApprovedRequest(ctx context.Context, opts ExecuteOptio
 
 	if opts.CaptureRollback && (request.Rollback == nil || request.Rollback.Path == "") {
 		data, err := CaptureRollbackState(ctx, request, RollbackCaptureOptions{
-			MaxSizeBytes: int64(opts.MaxRollbackSizeMB) * 1024 * 1024,
+			MaxSizeBytes:  int64(opts.MaxRollbackSizeMB) * 1024 * 1024,
+			RootPrefix:    opts.RollbackRootPrefix,
+			MaxCaptures:   opts.MaxRollbackCaptures,
+			MaxConcurrent: opts.MaxConcurrentCaptures,
+			Wait:          opts.RollbackCaptureWait,
+			Exclude:       opts.RollbackExclude,
 		})
+		if errors.Is(err, ErrRollbackCaptureBusy) {
+			// Defer execution rather than run without a capture; the
+			// request stays approved and can be executed again later.
+			if flagErr := e.db.UpdateRequestRollbackPending(opts.RequestID, true); flagErr != nil {
+				return nil, fmt.Errorf("flagging rollback pending: %w", flagErr)
+			}
+			return nil, fmt.Errorf("capturing rollback state (execution deferred, retry later): %w", err)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("capturing rollback state: %w", err)
 		}
@@ -176,10 +309,18 @@ func (e *Executor) ExecuteApprovedRequest(ctx context.Context, opts ExecuteOptio
 			if err := e.db.UpdateRequestRollbackPath(opts.RequestID, data.RollbackPath); err != nil {
 				return nil, fmt.Errorf("recording rollback path: %w", err)
 			}
+			// Usage stats only; a failure to record does not stop execution.
+			_ = e.db.RecordRollbackEvent(&db.RollbackEvent{
+				RequestID:   request.ID,
+				ProjectPath: request.ProjectPath,
+				Event:       db.RollbackEventCapture,
+				Kind:        data.Kind,
+				Succeeded:   true,
+			})
 		}
 	}
 
-	// Gate 5: First executor wins - transition to EXECUTING
+	// Gate 8: First executor wins - transition to EXECUTING
 	if err := e.db.UpdateRequestStatus(opts.RequestID, db.StatusExecuting); err != nil {
 		// If another executor already started, we'll get an error
 		if errors.Is(err, db.ErrInvalidTransition) {
@@ -196,6 +337,7 @@ func (e *Executor) ExecuteApprovedRequest(ctx context.Context, opts ExecuteOptio
 		ExecutedByAgent:     session.AgentName,
 		ExecutedByModel:     session.Model,
 		LogPath:             logPath,
+		ContextPinning:      pin.Decision,
 	}
 
 	// Update execution info
@@ -217,7 +359,13 @@ func (e *Executor) ExecuteApprovedRequest(ctx context.Context, opts ExecuteOptio
 	if !opts.SuppressOutput {
 		streamWriter = os.Stdout
 	}
-	cmdResult, err := RunCommand(execCtx, &request.Command, logPath, streamWriter)
+	var cmdResult *CommandResult
+	if plan != nil {
+		result.Segments, cmdResult, err = runSegments(execCtx, pin, request.Command.Raw, plan, logPath, streamWriter)
+		exec.Segments = result.Segments
+	} else {
+		cmdResult, err = runCommand(execCtx, &pin.Spec, logPath, streamWriter, pin.Env)
+	}
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			result.TimedOut = true
@@ -247,9 +395,23 @@ func (e *Executor) ExecuteApprovedRequest(ctx context.Context, opts ExecuteOptio
 		durationMs := result.Duration.Milliseconds()
 		exec.ExitCode = &exitCode
 		exec.DurationMs = &durationMs
+		exec.Stdout = cmdResult.Stdout
+		exec.Stderr = cmdResult.Stderr
+		exec.StdoutTruncated = cmdResult.StdoutTruncated
+		exec.StderrTruncated = cmdResult.StderrTruncated
 	}
 	_ = e.db.UpdateRequestExecution(opts.RequestID, exec)
 
+	// Queue the change record; the daemon delivers it and retries until
+	// the receiver accepts it.
+	if e.changeRecordURL != "" && ChangeRecordTierEnabled(e.changeRecordTiers, request.RiskTier) {
+		if executed, err := e.db.GetRequest(opts.RequestID); err == nil {
+			if _, err := EnqueueChangeRecord(e.db, executed, e.changeRecordURL, time.Now()); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to queue change record: %v\n", err)
+			}
+		}
+	}
+
 	// Notify (best effort)
 	_ = e.notifier.NotifyRequestExecuted(request, exec, result.ExitCode)
 
@@ -291,6 +453,11 @@ func tierHigher(tier1, tier2 db.RiskTier) bool {
 	return tierOrder[tier1] > tierOrder[tier2]
 }
 
+// approvalExpiredError wraps ErrApprovalExpired with how to recover.
+func approvalExpiredError(requestID string) error {
+	return fmt.Errorf("%w: re-request review with 'slb rerequest %s'", ErrApprovalExpired, requestID)
+}
+
 // CanExecute checks if a request can be executed and returns the reason if not.
 func (e *Executor) CanExecute(requestID string) (bool, string) {
 	request, err := e.db.GetRequest(requestID)
@@ -311,12 +478,21 @@ func (e *Executor) CanExecute(requestID string) (bool, string) {
 		return false, "approval has expired"
 	}
 
-	expectedHash := db.ComputeCommandHash(request.Command)
-	if expectedHash != request.Command.Hash {
+	if !db.CommandHashMatches(request.Command) {
 		return false, "command hash mismatch (command may have been modified)"
 	}
+	if err := VerifyMigrations(request.Command, request.Migrations); err != nil {
+		return false, err.Error()
+	}
+	trustUse, err := VerifyTrustedScriptUse(e.db, request)
+	if err != nil {
+		return false, err.Error()
+	}
 
-	classification := e.patternEngine.ClassifyCommand(request.Command.Raw, request.Command.Cwd)
+	classification, err := e.currentClassification(request, trustUse)
+	if err != nil {
+		return false, err.Error()
+	}
 	if tierHigher(classification.Tier, request.RiskTier) {
 		return false, fmt.Sprintf("policy escalation: command now classified as %s", classification.Tier)
 	}
@@ -143,6 +143,10 @@ func (m *mockExecutorNotifier) NotifyRequestExecuted(req *db.Request, exec *db.E
 	return nil
 }
 
+func (m *mockExecutorNotifier) NotifyReconfirmRequested(req *db.Request, priorApprovals []*db.Review) error {
+	return nil
+}
+
 // Ensure mockExecutorNotifier implements integrations.RequestNotifier
 var _ integrations.RequestNotifier = (*mockExecutorNotifier)(nil)
 
@@ -891,6 +895,20 @@ func TestExecuteApprovedRequest(t *testing.T) {
 		if !errors.Is(err, ErrApprovalExpired) {
 			t.Errorf("expected ErrApprovalExpired, got %v", err)
 		}
+
+		// The request is parked for a re-request rather than lost, and
+		// stays unexecutable until it is approved again.
+		got, _ := dbConn.GetRequest(req.ID)
+		if got.Status != db.StatusApprovalExpired {
+			t.Errorf("expected status approval_expired, got %s", got.Status)
+		}
+		_, err = exec.ExecuteApprovedRequest(context.Background(), ExecuteOptions{
+			RequestID: req.ID,
+			SessionID: "test-session",
+		})
+		if !errors.Is(err, ErrApprovalExpired) {
+			t.Errorf("expected ErrApprovalExpired on retry, got %v", err)
+		}
 	})
 
 	t.Run("command hash mismatch returns error", func(t *testing.T) {
@@ -1064,6 +1082,79 @@ func TestExecuteApprovedRequest(t *testing.T) {
 		}
 	})
 
+	t.Run("execution record keeps stdout and stderr apart", func(t *testing.T) {
+		dbConn, err := db.Open(":memory:")
+		if err != nil {
+			t.Fatalf("db.Open(:memory:) error = %v", err)
+		}
+		defer dbConn.Close()
+
+		session := &db.Session{
+			ID:          "test-session",
+			ProjectPath: "/tmp/test",
+			AgentName:   "test-agent",
+			Program:     "test-program",
+			Model:       "test-model",
+		}
+		if err := dbConn.CreateSession(session); err != nil {
+			t.Fatalf("CreateSession error = %v", err)
+		}
+
+		tmpDir := t.TempDir()
+		cmdSpec := db.CommandSpec{
+			Raw:   "echo built; echo 'warning: stale cache' >&2; exit 2",
+			Cwd:   tmpDir,
+			Shell: true,
+		}
+		cmdSpec.Hash = db.ComputeCommandHash(cmdSpec)
+
+		futureTime := time.Now().Add(1 * time.Hour)
+		req := &db.Request{
+			ProjectPath:        tmpDir,
+			RequestorSessionID: "test-session",
+			RequestorAgent:     "test-agent",
+			RequestorModel:     "test-model",
+			RiskTier:           db.RiskTierCaution,
+			Command:            cmdSpec,
+			Status:             db.StatusApproved,
+			ApprovalExpiresAt:  &futureTime,
+		}
+		if err := dbConn.CreateRequest(req); err != nil {
+			t.Fatalf("CreateRequest error = %v", err)
+		}
+
+		exec := NewExecutor(dbConn, nil)
+		if _, err := exec.ExecuteApprovedRequest(context.Background(), ExecuteOptions{
+			RequestID:      req.ID,
+			SessionID:      "test-session",
+			LogDir:         filepath.Join(tmpDir, "logs"),
+			SuppressOutput: true,
+		}); err != nil {
+			t.Fatalf("ExecuteApprovedRequest error = %v", err)
+		}
+
+		updatedReq, err := dbConn.GetRequest(req.ID)
+		if err != nil {
+			t.Fatalf("GetRequest error = %v", err)
+		}
+		got := updatedReq.Execution
+		if got == nil {
+			t.Fatal("expected an execution record")
+		}
+		if got.Stdout != "built\n" || got.Stderr != "warning: stale cache\n" {
+			t.Errorf("stdout=%q stderr=%q, want them recorded separately", got.Stdout, got.Stderr)
+		}
+		if got.StdoutTruncated || got.StderrTruncated {
+			t.Error("expected neither stream to be truncated")
+		}
+		if got.ExitCode == nil || *got.ExitCode != 2 {
+			t.Errorf("ExitCode = %v, want 2", got.ExitCode)
+		}
+		if got.DurationMs == nil {
+			t.Error("expected a duration")
+		}
+	})
+
 	t.Run("execution with non-zero exit code", func(t *testing.T) {
 		dbConn, err := db.Open(":memory:")
 		if err != nil {
@@ -1594,4 +1685,45 @@ func TestExecuteApprovedRequest(t *testing.T) {
 			t.Errorf("expected policy escalation message, got %q", reason)
 		}
 	})
+
+	t.Run("tier lowered by a risk override is not an escalation", func(t *testing.T) {
+		dbConn, err := db.Open(":memory:")
+		if err != nil {
+			t.Fatalf("db.Open(:memory:) error = %v", err)
+		}
+		defer dbConn.Close()
+
+		session := &db.Session{
+			ID:          "test-session",
+			ProjectPath: "/tmp/test",
+			AgentName:   "test-agent",
+			Program:     "test-program",
+			Model:       "test-model",
+		}
+		if err := dbConn.CreateSession(session); err != nil {
+			t.Fatalf("CreateSession error = %v", err)
+		}
+
+		cmdSpec := db.CommandSpec{Raw: "rm -rf /very/important/path", Cwd: "/"}
+		cmdSpec.Hash = db.ComputeCommandHash(cmdSpec)
+		req := &db.Request{
+			ProjectPath:        "/tmp/test",
+			RequestorSessionID: "test-session",
+			RequestorAgent:     "test-agent",
+			RequestorModel:     "test-model",
+			RiskTier:           db.RiskTierCaution,
+			Command:            cmdSpec,
+			Status:             db.StatusApproved,
+		}
+		if err := dbConn.CreateRequest(req); err != nil {
+			t.Fatalf("CreateRequest error = %v", err)
+		}
+
+		exec := NewExecutor(dbConn, nil).WithRiskOverrides([]RiskOverrideRule{
+			{Glob: "rm -rf /very/important/*", Tier: RiskTierCaution},
+		})
+		if canExec, reason := exec.CanExecute(req.ID); !canExec {
+			t.Errorf("expected canExec=true with the override applied, got %q", reason)
+		}
+	})
 }
@@ -0,0 +1,271 @@
+// Package core implements per-project execution slots and the execution queue.
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Execution slot errors.
+var (
+	// ErrExecutionQueued is returned when an approved request gave up
+	// waiting for an execution slot. It stays approved and can be executed
+	// again.
+	ErrExecutionQueued = errors.New("execution slots for this project are full")
+	// ErrExpediteRequiresApproval is returned when a rejection asks to
+	// expedite execution.
+	ErrExpediteRequiresApproval = errors.New("only approvals can expedite execution")
+)
+
+// ExpeditedExecutionPriority is the execution priority an expedited approval
+// sets (see ReviewOptions.Expedite); requests default to 0.
+const ExpeditedExecutionPriority = 1
+
+// ExecutionQueuedEvent is the event emitted when an approved request starts
+// waiting for an execution slot.
+const ExecutionQueuedEvent = "request_execution_queued"
+
+// executionLeaseGrace is how long past the execution timeout a held slot is
+// honored when its executor runs on another host and cannot be checked.
+const executionLeaseGrace = time.Minute
+
+// executionWaitLease is how long a waiting claim from another host is kept
+// without being refreshed. Waiters refresh it on every poll.
+const executionWaitLease = time.Minute
+
+// executionSlotPoll is how often a waiting executor retries for a slot.
+var executionSlotPoll = time.Second
+
+// processAlive is replaced in tests to simulate executors that died.
+var processAlive = func(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
+}
+
+// NeedsExecutionSlot reports whether requests of tier count against the
+// per-project execution concurrency limit: DANGEROUS and CRITICAL do.
+func NeedsExecutionSlot(tier db.RiskTier) bool {
+	return tier == db.RiskTierDangerous || tier == db.RiskTierCritical
+}
+
+// ExecutionQueueStatus describes where a request waiting for an execution
+// slot stands.
+type ExecutionQueueStatus struct {
+	RequestID   string `json:"request_id"`
+	ProjectPath string `json:"project_path"`
+	// Position is 1-based; position 1 gets the next free slot.
+	Position    int `json:"position"`
+	QueueLength int `json:"queue_length"`
+	// Running is the number of slots held; MaxConcurrent is the limit.
+	Running       int       `json:"running"`
+	MaxConcurrent int       `json:"max_concurrent"`
+	Priority      int       `json:"priority"`
+	QueuedAt      time.Time `json:"queued_at"`
+}
+
+// ExecutionQueue returns the requests waiting for an execution slot in
+// projectPath (every project when empty), in the order slots go to them.
+// maxConcurrent is reported as the limit they wait on.
+func ExecutionQueue(database *db.DB, projectPath string, maxConcurrent int) ([]*ExecutionQueueStatus, error) {
+	claims, err := database.ListExecutionClaims(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	running := make(map[string]int)
+	waiting := make(map[string][]*db.ExecutionClaim)
+	var projects []string
+	for _, c := range claims {
+		if _, ok := running[c.ProjectPath]; !ok {
+			projects = append(projects, c.ProjectPath)
+			running[c.ProjectPath] = 0
+		}
+		if c.Held() {
+			running[c.ProjectPath]++
+		} else {
+			waiting[c.ProjectPath] = append(waiting[c.ProjectPath], c)
+		}
+	}
+
+	var statuses []*ExecutionQueueStatus
+	for _, p := range projects {
+		queue := waiting[p]
+		for i, c := range queue {
+			statuses = append(statuses, &ExecutionQueueStatus{
+				RequestID:     c.RequestID,
+				ProjectPath:   p,
+				Position:      i + 1,
+				QueueLength:   len(queue),
+				Running:       running[p],
+				MaxConcurrent: maxConcurrent,
+				Priority:      c.Priority,
+				QueuedAt:      c.QueuedAt,
+			})
+		}
+	}
+	return statuses, nil
+}
+
+// RecoverStaleExecutionClaims releases the claims in projectPath (every
+// project when empty) whose executor is gone, as of now, and returns them.
+// A claim is stale when its request is no longer approved or executing,
+// when its executor ran on this host and its process has exited, or when
+// its executor ran on another host and its lease has lapsed. A request
+// still marked executing under a stale claim is failed, since nothing is
+// left to report its result.
+func RecoverStaleExecutionClaims(database *db.DB, projectPath string, now time.Time) ([]*db.ExecutionClaim, error) {
+	claims, err := database.ListExecutionClaims(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	localHost, _ := db.LocalHostIdentity()
+
+	var recovered []*db.ExecutionClaim
+	for _, c := range claims {
+		req, err := database.GetRequest(c.RequestID)
+		if err != nil {
+			return recovered, fmt.Errorf("getting request %s: %w", c.RequestID, err)
+		}
+		if !executionClaimStale(c, req, localHost, now) {
+			continue
+		}
+		if req.Status == db.StatusExecuting {
+			if err := database.UpdateRequestStatus(req.ID, db.StatusExecutionFailed); err != nil && !errors.Is(err, db.ErrInvalidTransition) {
+				return recovered, fmt.Errorf("failing abandoned execution %s: %w", req.ID, err)
+			}
+		}
+		if err := database.ReleaseExecutionClaim(c.RequestID); err != nil {
+			return recovered, err
+		}
+		recovered = append(recovered, c)
+	}
+	return recovered, nil
+}
+
+// executionClaimStale reports whether c no longer has a live executor.
+func executionClaimStale(c *db.ExecutionClaim, req *db.Request, localHost string, now time.Time) bool {
+	switch req.Status {
+	case db.StatusApproved:
+	case db.StatusExecuting:
+		if !c.Held() {
+			return true
+		}
+	default:
+		return true
+	}
+	if c.Hostname != "" && strings.EqualFold(c.Hostname, localHost) {
+		return !processAlive(c.PID)
+	}
+	return c.LeaseExpiresAt != nil && now.After(*c.LeaseExpiresAt)
+}
+
+// acquireExecutionSlot waits in request's project execution queue until it
+// gets a slot, reporting whether it holds one; requests the limit does not
+// apply to get none and do not wait. The caller must release a held slot.
+// A waiter that gives up leaves the queue, and the request stays approved.
+func (e *Executor) acquireExecutionSlot(ctx context.Context, request *db.Request, opts ExecuteOptions) (bool, error) {
+	if opts.MaxConcurrentExecutions <= 0 || !NeedsExecutionSlot(request.RiskTier) {
+		return false, nil
+	}
+
+	host, _ := db.LocalHostIdentity()
+	claim := &db.ExecutionClaim{
+		RequestID:   request.ID,
+		ProjectPath: request.ProjectPath,
+		Hostname:    host,
+		PID:         os.Getpid(),
+		ApprovedAt:  request.CreatedAt,
+	}
+	if request.ResolvedAt != nil {
+		claim.ApprovedAt = *request.ResolvedAt
+	}
+	deadline := time.Now().Add(opts.ExecutionQueueWait)
+	announced := false
+
+	for {
+		now := time.Now().UTC()
+		if _, err := RecoverStaleExecutionClaims(e.db, request.ProjectPath, now); err != nil {
+			return false, fmt.Errorf("recovering stale execution slots: %w", err)
+		}
+		lease := now.Add(executionWaitLease)
+		claim.LeaseExpiresAt = &lease
+		if err := e.db.EnqueueExecution(claim); err != nil {
+			return false, err
+		}
+		acquired, err := e.db.AcquireExecutionSlot(request.ID, opts.MaxConcurrentExecutions, now, now.Add(opts.Timeout+executionLeaseGrace))
+		if err != nil {
+			_ = e.db.ReleaseExecutionClaim(request.ID)
+			return false, err
+		}
+		if acquired {
+			return true, nil
+		}
+
+		status, err := e.executionQueueStatus(request, opts.MaxConcurrentExecutions)
+		if err != nil {
+			_ = e.db.ReleaseExecutionClaim(request.ID)
+			return false, err
+		}
+		if !announced && status != nil {
+			announced = true
+			if opts.OnExecutionQueued != nil {
+				opts.OnExecutionQueued(status)
+			}
+		}
+		if opts.ExecutionQueueWait <= 0 || !time.Now().Before(deadline) {
+			_ = e.db.ReleaseExecutionClaim(request.ID)
+			if status != nil {
+				return false, fmt.Errorf("%w: %d of %d slots in use, queue position %d of %d (retry later)",
+					ErrExecutionQueued, status.Running, status.MaxConcurrent, status.Position, status.QueueLength)
+			}
+			return false, fmt.Errorf("%w (retry later)", ErrExecutionQueued)
+		}
+
+		select {
+		case <-ctx.Done():
+			_ = e.db.ReleaseExecutionClaim(request.ID)
+			return false, ctx.Err()
+		case <-time.After(executionSlotPoll):
+		}
+
+		current, err := e.db.GetRequest(request.ID)
+		if err != nil {
+			_ = e.db.ReleaseExecutionClaim(request.ID)
+			return false, fmt.Errorf("getting request: %w", err)
+		}
+		if current.Status != db.StatusApproved {
+			_ = e.db.ReleaseExecutionClaim(request.ID)
+			if current.Status == db.StatusApprovalExpired {
+				return false, approvalExpiredError(request.ID)
+			}
+			return false, fmt.Errorf("%w: status changed to %s while waiting for an execution slot", ErrRequestNotApproved, current.Status)
+		}
+	}
+}
+
+// executionQueueStatus returns request's place in its project's execution
+// queue, or nil if it is not waiting.
+func (e *Executor) executionQueueStatus(request *db.Request, maxConcurrent int) (*ExecutionQueueStatus, error) {
+	statuses, err := ExecutionQueue(e.db, request.ProjectPath, maxConcurrent)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range statuses {
+		if st.RequestID == request.ID {
+			return st, nil
+		}
+	}
+	return nil, nil
+}
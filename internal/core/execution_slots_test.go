@@ -0,0 +1,272 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// setupExecutionSlotTest returns a database with an executor session and a
+// project directory to run requests in.
+func setupExecutionSlotTest(t *testing.T) (*db.DB, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell execution test uses /bin/sh or $SHELL")
+	}
+	dbConn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("db.Open(:memory:) error = %v", err)
+	}
+	t.Cleanup(func() { dbConn.Close() })
+
+	project := t.TempDir()
+	session := &db.Session{
+		ID:          "executor-session",
+		ProjectPath: project,
+		AgentName:   "test-agent",
+		Program:     "test-program",
+		Model:       "test-model",
+	}
+	if err := dbConn.CreateSession(session); err != nil {
+		t.Fatalf("CreateSession error = %v", err)
+	}
+	return dbConn, project
+}
+
+// makeSlotRequest creates an approved request of tier that runs true.
+func makeSlotRequest(t *testing.T, dbConn *db.DB, project string, tier db.RiskTier) *db.Request {
+	t.Helper()
+	truePath := testutil.TruePath()
+	cmdSpec := db.CommandSpec{Raw: truePath, Argv: []string{truePath}, Cwd: project}
+	cmdSpec.Hash = db.ComputeCommandHash(cmdSpec)
+	expires := time.Now().Add(time.Hour)
+	req := &db.Request{
+		ProjectPath:        project,
+		RequestorSessionID: "executor-session",
+		RequestorAgent:     "test-agent",
+		RequestorModel:     "test-model",
+		RiskTier:           tier,
+		Command:            cmdSpec,
+		Status:             db.StatusApproved,
+		ApprovalExpiresAt:  &expires,
+	}
+	if err := dbConn.CreateRequest(req); err != nil {
+		t.Fatalf("CreateRequest error = %v", err)
+	}
+	return req
+}
+
+// holdSlot gives req a held execution slot claimed by pid on this host.
+func holdSlot(t *testing.T, dbConn *db.DB, req *db.Request, pid int) {
+	t.Helper()
+	host, _ := db.LocalHostIdentity()
+	if err := dbConn.EnqueueExecution(&db.ExecutionClaim{
+		RequestID:   req.ID,
+		ProjectPath: req.ProjectPath,
+		Hostname:    host,
+		PID:         pid,
+	}); err != nil {
+		t.Fatalf("EnqueueExecution error = %v", err)
+	}
+	now := time.Now().UTC()
+	if ok, err := dbConn.AcquireExecutionSlot(req.ID, 1, now, now.Add(time.Hour)); err != nil || !ok {
+		t.Fatalf("AcquireExecutionSlot = %v, %v", ok, err)
+	}
+}
+
+func slotOptions(req *db.Request, project string) ExecuteOptions {
+	return ExecuteOptions{
+		RequestID:               req.ID,
+		SessionID:               "executor-session",
+		LogDir:                  filepath.Join(project, "logs"),
+		SuppressOutput:          true,
+		MaxConcurrentExecutions: 1,
+	}
+}
+
+func TestExecuteApprovedRequest_QueuesWhenSlotsFull(t *testing.T) {
+	dbConn, project := setupExecutionSlotTest(t)
+	running := makeSlotRequest(t, dbConn, project, db.RiskTierDangerous)
+	waiting := makeSlotRequest(t, dbConn, project, db.RiskTierCritical)
+	holdSlot(t, dbConn, running, os.Getpid())
+
+	var queued *ExecutionQueueStatus
+	opts := slotOptions(waiting, project)
+	opts.OnExecutionQueued = func(st *ExecutionQueueStatus) { queued = st }
+	_, err := NewExecutor(dbConn, nil).ExecuteApprovedRequest(context.Background(), opts)
+	if !errors.Is(err, ErrExecutionQueued) {
+		t.Fatalf("expected ErrExecutionQueued, got %v", err)
+	}
+	if queued == nil || queued.Position != 1 || queued.Running != 1 || queued.MaxConcurrent != 1 {
+		t.Fatalf("OnExecutionQueued status = %+v", queued)
+	}
+
+	// Giving up leaves the queue; the request stays approved.
+	if claim, _ := dbConn.GetExecutionClaim(waiting.ID); claim != nil {
+		t.Errorf("expected the waiter to leave the queue, got %+v", claim)
+	}
+	if got, _ := dbConn.GetRequest(waiting.ID); got.Status != db.StatusApproved {
+		t.Errorf("expected request to stay approved, got %s", got.Status)
+	}
+
+	// CAUTION requests do not need a slot.
+	caution := makeSlotRequest(t, dbConn, project, db.RiskTierCaution)
+	if _, err := NewExecutor(dbConn, nil).ExecuteApprovedRequest(context.Background(), slotOptions(caution, project)); err != nil {
+		t.Fatalf("caution execution error = %v", err)
+	}
+}
+
+func TestExecuteApprovedRequest_WaitsForFreedSlot(t *testing.T) {
+	dbConn, project := setupExecutionSlotTest(t)
+	oldPoll := executionSlotPoll
+	executionSlotPoll = 10 * time.Millisecond
+	t.Cleanup(func() { executionSlotPoll = oldPoll })
+
+	running := makeSlotRequest(t, dbConn, project, db.RiskTierDangerous)
+	waiting := makeSlotRequest(t, dbConn, project, db.RiskTierDangerous)
+	holdSlot(t, dbConn, running, os.Getpid())
+
+	opts := slotOptions(waiting, project)
+	opts.ExecutionQueueWait = 10 * time.Second
+	opts.OnExecutionQueued = func(*ExecutionQueueStatus) {
+		// The running execution finishes while the request waits.
+		_ = dbConn.ReleaseExecutionClaim(running.ID)
+	}
+	result, err := NewExecutor(dbConn, nil).ExecuteApprovedRequest(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("ExecuteApprovedRequest error = %v", err)
+	}
+	if result.ExitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", result.ExitCode)
+	}
+	if got, _ := dbConn.GetRequest(waiting.ID); got.Status != db.StatusExecuted {
+		t.Errorf("expected executed, got %s", got.Status)
+	}
+	// The slot is released on completion.
+	if claim, _ := dbConn.GetExecutionClaim(waiting.ID); claim != nil {
+		t.Errorf("expected slot released after execution, got %+v", claim)
+	}
+}
+
+func TestRecoverStaleExecutionClaims_ExecutorDiedHoldingSlot(t *testing.T) {
+	dbConn, project := setupExecutionSlotTest(t)
+	const deadPID = 424242
+	oldAlive := processAlive
+	processAlive = func(pid int) bool { return pid != deadPID }
+	t.Cleanup(func() { processAlive = oldAlive })
+
+	// The executor took a slot, marked the request executing, then died.
+	abandoned := makeSlotRequest(t, dbConn, project, db.RiskTierDangerous)
+	holdSlot(t, dbConn, abandoned, deadPID)
+	if err := dbConn.UpdateRequestStatus(abandoned.ID, db.StatusExecuting); err != nil {
+		t.Fatalf("UpdateRequestStatus error = %v", err)
+	}
+
+	// The next request gets the slot once the dead executor's is recovered.
+	next := makeSlotRequest(t, dbConn, project, db.RiskTierDangerous)
+	if _, err := NewExecutor(dbConn, nil).ExecuteApprovedRequest(context.Background(), slotOptions(next, project)); err != nil {
+		t.Fatalf("ExecuteApprovedRequest error = %v", err)
+	}
+	if claim, _ := dbConn.GetExecutionClaim(abandoned.ID); claim != nil {
+		t.Errorf("expected the dead executor's slot to be released, got %+v", claim)
+	}
+	if got, _ := dbConn.GetRequest(abandoned.ID); got.Status != db.StatusExecutionFailed {
+		t.Errorf("expected abandoned execution to fail, got %s", got.Status)
+	}
+}
+
+func TestRecoverStaleExecutionClaims(t *testing.T) {
+	dbConn, project := setupExecutionSlotTest(t)
+	now := time.Now().UTC()
+
+	claim := func(req *db.Request, host string, pid int, lease time.Time, hold bool) {
+		t.Helper()
+		if err := dbConn.EnqueueExecution(&db.ExecutionClaim{
+			RequestID: req.ID, ProjectPath: project, Hostname: host, PID: pid, LeaseExpiresAt: &lease,
+		}); err != nil {
+			t.Fatalf("EnqueueExecution error = %v", err)
+		}
+		if hold {
+			if ok, err := dbConn.AcquireExecutionSlot(req.ID, 10, now, lease); err != nil || !ok {
+				t.Fatalf("AcquireExecutionSlot = %v, %v", ok, err)
+			}
+		}
+	}
+
+	live := makeSlotRequest(t, dbConn, project, db.RiskTierDangerous)
+	claim(live, "", os.Getpid(), now.Add(-time.Hour), true) // no host recorded: lease decides
+	remoteLive := makeSlotRequest(t, dbConn, project, db.RiskTierDangerous)
+	claim(remoteLive, "other-host.invalid", 1, now.Add(time.Hour), true)
+	remoteExpired := makeSlotRequest(t, dbConn, project, db.RiskTierDangerous)
+	claim(remoteExpired, "other-host.invalid", 1, now.Add(-time.Second), false)
+	cancelled := makeSlotRequest(t, dbConn, project, db.RiskTierDangerous)
+	claim(cancelled, "other-host.invalid", 1, now.Add(time.Hour), false)
+	if err := dbConn.UpdateRequestStatus(cancelled.ID, db.StatusCancelled); err != nil {
+		t.Fatalf("UpdateRequestStatus error = %v", err)
+	}
+
+	recovered, err := RecoverStaleExecutionClaims(dbConn, project, now)
+	if err != nil {
+		t.Fatalf("RecoverStaleExecutionClaims error = %v", err)
+	}
+	got := make(map[string]bool)
+	for _, c := range recovered {
+		got[c.RequestID] = true
+	}
+	want := map[string]bool{live.ID: true, remoteExpired.ID: true, cancelled.ID: true}
+	if len(got) != len(want) {
+		t.Fatalf("recovered %v, want %v", got, want)
+	}
+	for id := range want {
+		if !got[id] {
+			t.Errorf("expected %s to be recovered", id)
+		}
+	}
+	if c, _ := dbConn.GetExecutionClaim(remoteLive.ID); c == nil || !c.Held() {
+		t.Errorf("expected the unexpired remote slot to be kept, got %+v", c)
+	}
+	// A recovered approved request is left approved.
+	if r, _ := dbConn.GetRequest(remoteExpired.ID); r.Status != db.StatusApproved {
+		t.Errorf("expected %s to stay approved, got %s", remoteExpired.ID, r.Status)
+	}
+}
+
+func TestSubmitReview_Expedite(t *testing.T) {
+	dbConn, _, req := setupReviewTest(t)
+	defer dbConn.Close()
+
+	reviewer := &db.Session{AgentName: "GreenLake", Program: "claude-code", Model: "opus-4.5", ProjectPath: "/test/project"}
+	if err := dbConn.CreateSession(reviewer); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+
+	_, err := rs.SubmitReview(ReviewOptions{
+		SessionID: reviewer.ID, SessionKey: reviewer.SessionKey, RequestID: req.ID,
+		Decision: db.DecisionReject, Comments: "no", Expedite: true,
+	})
+	if !errors.Is(err, ErrExpediteRequiresApproval) {
+		t.Fatalf("expected ErrExpediteRequiresApproval, got %v", err)
+	}
+
+	if _, err := rs.SubmitReview(ReviewOptions{
+		SessionID: reviewer.ID, SessionKey: reviewer.SessionKey, RequestID: req.ID,
+		Decision: db.DecisionApprove, Expedite: true,
+	}); err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+	priority, err := dbConn.GetExecutionPriority(req.ID)
+	if err != nil {
+		t.Fatalf("GetExecutionPriority() error = %v", err)
+	}
+	if priority != ExpeditedExecutionPriority {
+		t.Errorf("expected priority %d, got %d", ExpeditedExecutionPriority, priority)
+	}
+}
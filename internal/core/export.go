@@ -0,0 +1,152 @@
+// Package core implements the history export shared by 'slb history export'
+// and the history browser.
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// History export formats.
+const (
+	ExportFormatCSV   = "csv"
+	ExportFormatJSONL = "jsonl"
+)
+
+// exportPageSize is how many requests are read from the database at a time.
+const exportPageSize = 500
+
+// ExportRecord is one JSONL export line: the request under its own JSON tags,
+// so a line unmarshals back into a db.Request, plus its review counts.
+type ExportRecord struct {
+	db.Request
+	Approvals  int `json:"approvals"`
+	Rejections int `json:"rejections"`
+}
+
+// MarshalJSON writes the request in its own JSON form with the review counts
+// appended. Without it the embedded request's MarshalJSON would be promoted
+// and the counts dropped.
+func (e ExportRecord) MarshalJSON() ([]byte, error) {
+	req, err := json.Marshal(&e.Request)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := json.Marshal(struct {
+		Approvals  int `json:"approvals"`
+		Rejections int `json:"rejections"`
+	}{e.Approvals, e.Rejections})
+	if err != nil {
+		return nil, err
+	}
+	// Splice {"id":...} and {"approvals":...} into one object.
+	out := append(req[:len(req)-1], ',')
+	return append(out, counts[1:]...), nil
+}
+
+// exportCSVHeader names the CSV export columns.
+var exportCSVHeader = []string{
+	"id", "project_path", "status", "risk_tier", "command", "command_hash",
+	"requestor_agent", "requestor_model", "reason", "min_approvals",
+	"approvals", "rejections", "created_at", "resolved_at",
+}
+
+// ExportHistory writes the requests matching filter to w in format, oldest
+// first, and returns how many it wrote. Requests are read a page at a time,
+// so the export does not hold the whole history in memory.
+func ExportHistory(database *db.DB, w io.Writer, format string, filter db.RequestFilter) (int, error) {
+	var write func(r *db.Request, approvals, rejections int) error
+	var flush func() error
+	switch format {
+	case ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportCSVHeader); err != nil {
+			return 0, err
+		}
+		write = func(r *db.Request, approvals, rejections int) error {
+			return cw.Write(exportCSVRow(r, approvals, rejections))
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case ExportFormatJSONL:
+		enc := json.NewEncoder(w)
+		write = func(r *db.Request, approvals, rejections int) error {
+			return enc.Encode(ExportRecord{Request: *r, Approvals: approvals, Rejections: rejections})
+		}
+		flush = func() error { return nil }
+	default:
+		return 0, fmt.Errorf("unknown export format %q (use %s or %s)", format, ExportFormatCSV, ExportFormatJSONL)
+	}
+
+	count := 0
+	var after *db.Request
+	for {
+		page, err := database.ListRequestsPage(filter, after, exportPageSize)
+		if err != nil {
+			return count, err
+		}
+		for _, r := range page {
+			approvals, rejections, err := database.CountReviewsByDecision(r.ID)
+			if err != nil {
+				return count, err
+			}
+			if err := write(r, approvals, rejections); err != nil {
+				return count, fmt.Errorf("writing request %s: %w", r.ID, err)
+			}
+			count++
+		}
+		if len(page) < exportPageSize {
+			break
+		}
+		after = page[len(page)-1]
+	}
+	return count, flush()
+}
+
+// exportCSVRow renders a request as a CSV export row. The command is the
+// redacted display form when the request has one.
+func exportCSVRow(r *db.Request, approvals, rejections int) []string {
+	command := r.Command.Raw
+	if r.Command.DisplayRedacted != "" {
+		command = r.Command.DisplayRedacted
+	}
+	resolved := ""
+	if r.ResolvedAt != nil {
+		resolved = r.ResolvedAt.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		r.ID, r.ProjectPath, string(r.Status), string(r.RiskTier), command, r.Command.Hash,
+		r.RequestorAgent, r.RequestorModel, r.Justification.Reason, strconv.Itoa(r.MinApprovals),
+		strconv.Itoa(approvals), strconv.Itoa(rejections), r.CreatedAt.UTC().Format(time.RFC3339), resolved,
+	}
+}
+
+// ExportHistoryFile exports the requests matching filter to a timestamped
+// file in the project's .slb directory and returns its path and how many
+// requests it holds.
+func ExportHistoryFile(database *db.DB, projectPath, format string, filter db.RequestFilter, now time.Time) (string, int, error) {
+	path := filepath.Join(projectPath, ".slb", fmt.Sprintf("history-%s.%s", now.UTC().Format("20060102-150405"), format))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", 0, fmt.Errorf("creating export file: %w", err)
+	}
+	n, err := ExportHistory(database, f, format, filter)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", 0, err
+	}
+	return path, n, nil
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestExportHistoryCSV(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	awkward := "printf 'a,b' \\\n  && echo \"done\""
+	req := testutil.MakeRequest(t, database, session, testutil.WithCommand(awkward, "/tmp", true))
+	testutil.MakeRequest(t, database, session, testutil.WithRisk(db.RiskTierCritical))
+
+	var buf bytes.Buffer
+	n, err := ExportHistory(database, &buf, ExportFormatCSV, db.RequestFilter{ProjectPath: session.ProjectPath, Tier: db.RiskTierDangerous})
+	if err != nil || n != 1 {
+		t.Fatalf("ExportHistory = %d, %v", n, err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV back: %v", err)
+	}
+	if len(records) != 2 || records[0][0] != "id" {
+		t.Fatalf("expected a header and one row, got %v", records)
+	}
+	row := records[1]
+	if row[0] != req.ID || row[4] != awkward || row[5] != req.Command.Hash {
+		t.Errorf("unexpected row %q", row)
+	}
+}
+
+func TestExportHistoryJSONLRoundTrip(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	reviewer := testutil.MakeSession(t, database)
+	req := testutil.MakeRequest(t, database, session,
+		testutil.WithJustification("clean", "build removed", "fresh build", "regenerated"),
+		testutil.WithLabels(map[string]string{"env": "dev"}))
+	if _, err := NewReviewService(database, DefaultReviewConfig()).SubmitReview(ReviewOptions{
+		SessionID: reviewer.ID, SessionKey: reviewer.SessionKey, RequestID: req.ID, Decision: db.DecisionApprove,
+	}); err != nil {
+		t.Fatalf("SubmitReview: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := ExportHistory(database, &buf, ExportFormatJSONL, db.RequestFilter{}); err != nil {
+		t.Fatalf("ExportHistory: %v", err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	if !scanner.Scan() {
+		t.Fatal("expected a JSONL line")
+	}
+	var record ExportRecord
+	if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+		t.Fatalf("unmarshal record: %v", err)
+	}
+	if record.Approvals != 1 || record.Rejections != 0 {
+		t.Errorf("counts = %d/%d, want 1/0", record.Approvals, record.Rejections)
+	}
+
+	// The same line reads back as a plain request.
+	var got db.Request
+	if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal request: %v", err)
+	}
+	want, _ := database.GetRequest(req.ID)
+	if got.ID != want.ID || got.Command.Hash != want.Command.Hash || got.Status != db.StatusApproved ||
+		got.Justification != want.Justification || got.Labels["env"] != "dev" ||
+		!got.CreatedAt.Equal(want.CreatedAt) || (got.ResolvedAt == nil) != (want.ResolvedAt == nil) {
+		t.Errorf("round trip mismatch:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestExportHistoryFile(t *testing.T) {
+	h := testutil.NewHarness(t)
+	session := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	testutil.MakeRequest(t, h.DB, session)
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	path, n, err := ExportHistoryFile(h.DB, h.ProjectDir, ExportFormatCSV, db.RequestFilter{ProjectPath: h.ProjectDir}, now)
+	if err != nil || n != 1 {
+		t.Fatalf("ExportHistoryFile = %d, %v", n, err)
+	}
+	if want := filepath.Join(h.ProjectDir, ".slb", "history-20260102-030405.csv"); path != want {
+		t.Errorf("path = %s, want %s", path, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || strings.Count(string(data), "\n") != 2 {
+		t.Errorf("expected a header and one row, got %q, %v", data, err)
+	}
+
+	if _, _, err := ExportHistoryFile(h.DB, h.ProjectDir, "xml", db.RequestFilter{}, now); err == nil {
+		t.Error("expected an unknown format to fail")
+	}
+}
@@ -0,0 +1,261 @@
+// Package core provides reviewer fatigue analytics that flag rubber-stamp
+// approval patterns.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// ReviewerPatternWarningEvent is emitted when a reviewer's approvals exceed
+// the configured fatigue thresholds.
+const ReviewerPatternWarningEvent = "reviewer_pattern_warning"
+
+// Reviewer pattern warnings.
+const (
+	ReviewerWarningFastApprovals  = "fast_approvals"
+	ReviewerWarningEmptyResponses = "empty_responses"
+	ReviewerWarningApprovalStreak = "approval_streak"
+)
+
+// ReviewerThresholds configures when a reviewer is flagged. A zero value
+// disables the corresponding check.
+type ReviewerThresholds struct {
+	// FastApproval flags reviewers whose median time from request creation
+	// to approval is below this duration.
+	FastApproval time.Duration
+	// EmptyResponsePercent flags reviewers whose approvals carry no comments
+	// or responses at least this often (0-100).
+	EmptyResponsePercent int
+	// ApprovalStreak flags reviewers with a run of consecutive approvals of
+	// this length. The flag stays after a later rejection and is cleared only
+	// by a reviewer attestation (or once the run falls out of Window).
+	ApprovalStreak int
+	// MinReviews is the number of reviews needed before any check applies.
+	MinReviews int
+	// Window limits the analysis to reviews made within this long of now;
+	// zero considers the whole history.
+	Window time.Duration
+	// ExcludeCritical stops flagged reviewers' approvals from counting toward
+	// CRITICAL quorum until a reviewer attestation clears them.
+	ExcludeCritical bool
+}
+
+// Enabled reports whether any fatigue check is configured.
+func (t ReviewerThresholds) Enabled() bool {
+	return t.FastApproval > 0 || t.EmptyResponsePercent > 0 || t.ApprovalStreak > 0
+}
+
+// ReviewerStats summarizes one reviewer's recent approval behavior.
+type ReviewerStats struct {
+	Agent      string `json:"agent"`
+	Reviews    int    `json:"reviews"`
+	Approvals  int    `json:"approvals"`
+	Rejections int    `json:"rejections"`
+	// MedianApprovalSeconds is the median time from request creation to
+	// this reviewer's approval.
+	MedianApprovalSeconds float64 `json:"median_approval_seconds"`
+	// EmptyApprovalPercent is the share of approvals with no comments or
+	// justification responses.
+	EmptyApprovalPercent float64 `json:"empty_approval_percent"`
+	// CurrentStreak is the number of consecutive approvals since the
+	// reviewer's last outright rejection. Segment decisions count as
+	// approvals since they approve the unselected segments.
+	CurrentStreak int `json:"current_approval_streak"`
+	LongestStreak int `json:"longest_approval_streak"`
+	// AttestedAt is the latest reviewer attestation; only reviews after it
+	// are counted.
+	AttestedAt *time.Time `json:"attested_at,omitempty"`
+	// Warnings lists the thresholds this reviewer exceeds.
+	Warnings []string `json:"warnings,omitempty"`
+	// ExcludedFromCritical is set when the reviewer's approvals do not count
+	// toward CRITICAL quorum.
+	ExcludedFromCritical bool `json:"excluded_from_critical"`
+}
+
+// Flagged reports whether the reviewer exceeds any threshold.
+func (s ReviewerStats) Flagged() bool {
+	return len(s.Warnings) > 0
+}
+
+// ComputeReviewerStats aggregates review activity per reviewer agent and
+// applies the thresholds. Reviews at or before a reviewer's attestation time
+// in attested are ignored, so an attestation starts a fresh window.
+// Results are sorted by agent name.
+func ComputeReviewerStats(activity []db.ReviewActivity, attested map[string]time.Time, t ReviewerThresholds) []ReviewerStats {
+	byAgent := make(map[string]*ReviewerStats)
+	delays := make(map[string][]float64)
+	empty := make(map[string]int)
+
+	for _, a := range activity {
+		r := a.Review
+		if r == nil {
+			continue
+		}
+		s, ok := byAgent[r.ReviewerAgent]
+		if !ok {
+			s = &ReviewerStats{Agent: r.ReviewerAgent}
+			if at, ok := attested[r.ReviewerAgent]; ok {
+				at := at
+				s.AttestedAt = &at
+			}
+			byAgent[r.ReviewerAgent] = s
+		}
+		if s.AttestedAt != nil && !r.CreatedAt.After(*s.AttestedAt) {
+			continue
+		}
+
+		s.Reviews++
+		if !approvesAny(r) {
+			s.Rejections++
+			s.CurrentStreak = 0
+			continue
+		}
+		s.Approvals++
+		s.CurrentStreak++
+		if s.CurrentStreak > s.LongestStreak {
+			s.LongestStreak = s.CurrentStreak
+		}
+		if !a.RequestCreatedAt.IsZero() {
+			delay := r.CreatedAt.Sub(a.RequestCreatedAt).Seconds()
+			if delay < 0 {
+				delay = 0
+			}
+			delays[r.ReviewerAgent] = append(delays[r.ReviewerAgent], delay)
+		}
+		if isEmptyReview(r) {
+			empty[r.ReviewerAgent]++
+		}
+	}
+
+	stats := make([]ReviewerStats, 0, len(byAgent))
+	for agent, s := range byAgent {
+		s.MedianApprovalSeconds = median(delays[agent])
+		if s.Approvals > 0 {
+			s.EmptyApprovalPercent = 100 * float64(empty[agent]) / float64(s.Approvals)
+		}
+		s.Warnings = reviewerWarnings(*s, len(delays[agent]) > 0, t)
+		s.ExcludedFromCritical = s.Flagged() && t.ExcludeCritical
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Agent < stats[j].Agent })
+	return stats
+}
+
+// reviewerWarnings returns the thresholds s exceeds.
+func reviewerWarnings(s ReviewerStats, hasDelays bool, t ReviewerThresholds) []string {
+	if s.Reviews == 0 || s.Reviews < t.MinReviews {
+		return nil
+	}
+	var warnings []string
+	if t.FastApproval > 0 && hasDelays && s.MedianApprovalSeconds < t.FastApproval.Seconds() {
+		warnings = append(warnings, ReviewerWarningFastApprovals)
+	}
+	if t.EmptyResponsePercent > 0 && s.Approvals > 0 && s.EmptyApprovalPercent >= float64(t.EmptyResponsePercent) {
+		warnings = append(warnings, ReviewerWarningEmptyResponses)
+	}
+	if t.ApprovalStreak > 0 && s.LongestStreak >= t.ApprovalStreak {
+		warnings = append(warnings, ReviewerWarningApprovalStreak)
+	}
+	return warnings
+}
+
+// approvesAny reports whether a review approves at least part of a request:
+// an approval, or a segment decision, which approves the segments it does
+// not reject.
+func approvesAny(r *db.Review) bool {
+	return r.Decision == db.DecisionApprove || len(r.Segments) > 0
+}
+
+// isEmptyReview reports whether a review carries no comments or responses.
+func isEmptyReview(r *db.Review) bool {
+	resp := r.Responses
+	return strings.TrimSpace(r.Comments) == "" &&
+		strings.TrimSpace(resp.ReasonResponse) == "" &&
+		strings.TrimSpace(resp.EffectResponse) == "" &&
+		strings.TrimSpace(resp.GoalResponse) == "" &&
+		strings.TrimSpace(resp.SafetyResponse) == ""
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// ReviewerPatternReport computes reviewer stats from the database, taking
+// each reviewer's latest attestation into account.
+func ReviewerPatternReport(database *db.DB, t ReviewerThresholds) ([]ReviewerStats, error) {
+	return reviewerPatternReport(database, t, nil)
+}
+
+// reviewerPatternReport computes stats for the given agents, or for every
+// reviewer when agents is empty, over the thresholds' window.
+func reviewerPatternReport(database *db.DB, t ReviewerThresholds, agents []string) ([]ReviewerStats, error) {
+	filter := db.ReviewActivityFilter{Agents: agents}
+	if t.Window > 0 {
+		filter.Since = time.Now().UTC().Add(-t.Window)
+	}
+	activity, err := database.ListReviewActivity(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	attested := make(map[string]time.Time)
+	for _, a := range activity {
+		agent := a.Review.ReviewerAgent
+		if _, seen := attested[agent]; seen {
+			continue
+		}
+		latest, err := database.LatestPolicyAttestation(db.ReviewerAttestationScope(agent))
+		switch {
+		case err == nil:
+			attested[agent] = latest.CreatedAt
+		case errors.Is(err, db.ErrAttestationNotFound):
+			attested[agent] = time.Time{}
+		default:
+			return nil, fmt.Errorf("loading reviewer attestation: %w", err)
+		}
+	}
+	for agent, at := range attested {
+		if at.IsZero() {
+			delete(attested, agent)
+		}
+	}
+
+	return ComputeReviewerStats(activity, attested, t), nil
+}
+
+// FlaggedReviewers returns which of agents have their approvals excluded
+// from CRITICAL quorum. Only those agents' reviews within the window are
+// read, so the cost scales with the request's reviewers rather than the
+// whole review history. It is empty unless thresholds are enabled with
+// ExcludeCritical set.
+func FlaggedReviewers(database *db.DB, t ReviewerThresholds, agents []string) (map[string]bool, error) {
+	if !t.Enabled() || !t.ExcludeCritical || len(agents) == 0 {
+		return nil, nil
+	}
+	stats, err := reviewerPatternReport(database, t, agents)
+	if err != nil {
+		return nil, err
+	}
+	flagged := make(map[string]bool)
+	for _, s := range stats {
+		if s.ExcludedFromCritical {
+			flagged[s.Agent] = true
+		}
+	}
+	return flagged, nil
+}
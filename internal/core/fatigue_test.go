@@ -0,0 +1,245 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func activityAt(agent string, decision db.Decision, created time.Time, delay time.Duration, comments string) db.ReviewActivity {
+	return db.ReviewActivity{
+		Review: &db.Review{
+			ReviewerAgent: agent,
+			Decision:      decision,
+			Comments:      comments,
+			CreatedAt:     created.Add(delay),
+		},
+		RequestCreatedAt: created,
+	}
+}
+
+func TestComputeReviewerStats(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	activity := []db.ReviewActivity{
+		activityAt("Careful", db.DecisionApprove, base, 5*time.Minute, "checked the plan"),
+		activityAt("Stamp", db.DecisionApprove, base, time.Second, ""),
+		activityAt("Careful", db.DecisionReject, base, 10*time.Minute, "too broad"),
+		activityAt("Stamp", db.DecisionApprove, base, 2*time.Second, ""),
+		activityAt("Stamp", db.DecisionApprove, base, 3*time.Second, "lgtm"),
+		activityAt("Careful", db.DecisionApprove, base, 7*time.Minute, "ok"),
+	}
+	thresholds := ReviewerThresholds{
+		FastApproval:         10 * time.Second,
+		EmptyResponsePercent: 50,
+		ApprovalStreak:       3,
+		MinReviews:           3,
+		ExcludeCritical:      true,
+	}
+
+	stats := ComputeReviewerStats(activity, nil, thresholds)
+	if len(stats) != 2 || stats[0].Agent != "Careful" || stats[1].Agent != "Stamp" {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	careful := stats[0]
+	if careful.Reviews != 3 || careful.Approvals != 2 || careful.Rejections != 1 {
+		t.Errorf("careful counts = %+v", careful)
+	}
+	if careful.MedianApprovalSeconds != 360 {
+		t.Errorf("careful median = %v, want 360", careful.MedianApprovalSeconds)
+	}
+	if careful.CurrentStreak != 1 || careful.LongestStreak != 1 || careful.Flagged() {
+		t.Errorf("careful = %+v", careful)
+	}
+
+	stamp := stats[1]
+	if stamp.MedianApprovalSeconds != 2 {
+		t.Errorf("stamp median = %v, want 2", stamp.MedianApprovalSeconds)
+	}
+	if stamp.EmptyApprovalPercent < 66 || stamp.EmptyApprovalPercent > 67 {
+		t.Errorf("stamp empty percent = %v", stamp.EmptyApprovalPercent)
+	}
+	want := []string{ReviewerWarningFastApprovals, ReviewerWarningEmptyResponses, ReviewerWarningApprovalStreak}
+	if !reflect.DeepEqual(stamp.Warnings, want) || !stamp.ExcludedFromCritical {
+		t.Errorf("stamp warnings = %v excluded=%v", stamp.Warnings, stamp.ExcludedFromCritical)
+	}
+
+	// Below the minimum sample size nothing is flagged.
+	thresholds.MinReviews = 4
+	if stats := ComputeReviewerStats(activity, nil, thresholds); stats[1].Flagged() {
+		t.Errorf("expected no warnings below min reviews, got %v", stats[1].Warnings)
+	}
+}
+
+func TestComputeReviewerStats_AttestationStartsFreshWindow(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	activity := []db.ReviewActivity{
+		activityAt("Stamp", db.DecisionApprove, base, time.Second, ""),
+		activityAt("Stamp", db.DecisionApprove, base, 2*time.Second, ""),
+		activityAt("Stamp", db.DecisionApprove, base.Add(time.Hour), time.Second, ""),
+	}
+	thresholds := ReviewerThresholds{ApprovalStreak: 2, MinReviews: 1}
+
+	if stats := ComputeReviewerStats(activity, nil, thresholds); !stats[0].Flagged() {
+		t.Fatalf("expected streak warning, got %+v", stats[0])
+	}
+
+	attested := map[string]time.Time{"Stamp": base.Add(time.Minute)}
+	stats := ComputeReviewerStats(activity, attested, thresholds)
+	if stats[0].Reviews != 1 || stats[0].Flagged() {
+		t.Errorf("expected one counted review and no warning after attestation, got %+v", stats[0])
+	}
+	if stats[0].AttestedAt == nil || !stats[0].AttestedAt.Equal(attested["Stamp"]) {
+		t.Errorf("AttestedAt = %v", stats[0].AttestedAt)
+	}
+}
+
+func TestComputeReviewerStats_StreakFlagPersistsUntilAttestation(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	activity := []db.ReviewActivity{
+		activityAt("Stamp", db.DecisionApprove, base, time.Second, ""),
+		activityAt("Stamp", db.DecisionApprove, base, 2*time.Second, ""),
+		activityAt("Stamp", db.DecisionApprove, base, 3*time.Second, ""),
+		activityAt("Stamp", db.DecisionReject, base, 4*time.Second, "no"),
+	}
+	thresholds := ReviewerThresholds{ApprovalStreak: 3, MinReviews: 1}
+
+	stats := ComputeReviewerStats(activity, nil, thresholds)
+	if stats[0].CurrentStreak != 0 || !reflect.DeepEqual(stats[0].Warnings, []string{ReviewerWarningApprovalStreak}) {
+		t.Errorf("a single rejection must not clear the streak flag, got %+v", stats[0])
+	}
+
+	attested := map[string]time.Time{"Stamp": base.Add(3 * time.Second)}
+	if stats := ComputeReviewerStats(activity, attested, thresholds); stats[0].Flagged() {
+		t.Errorf("attestation should clear the streak flag, got %+v", stats[0])
+	}
+}
+
+func TestComputeReviewerStats_SegmentDecisionsCountAsApprovals(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	activity := []db.ReviewActivity{
+		activityAt("Stamp", db.DecisionApprove, base, time.Second, ""),
+		activityAt("Stamp", db.DecisionReject, base, 2*time.Second, ""),
+		activityAt("Stamp", db.DecisionApprove, base, 3*time.Second, ""),
+	}
+	activity[1].Review.Segments = []int{2}
+	thresholds := ReviewerThresholds{ApprovalStreak: 3, MinReviews: 1}
+
+	stats := ComputeReviewerStats(activity, nil, thresholds)
+	if stats[0].Approvals != 3 || stats[0].Rejections != 0 || stats[0].CurrentStreak != 3 {
+		t.Errorf("segment decision should count as an approval, got %+v", stats[0])
+	}
+	if !stats[0].Flagged() {
+		t.Errorf("expected streak warning, got %+v", stats[0])
+	}
+}
+
+func TestReviewerThresholdsEnabled(t *testing.T) {
+	if (ReviewerThresholds{MinReviews: 10, ExcludeCritical: true}).Enabled() {
+		t.Error("thresholds without checks should be disabled")
+	}
+	if !(ReviewerThresholds{ApprovalStreak: 5}).Enabled() {
+		t.Error("streak threshold should enable checks")
+	}
+}
+
+func TestEligibleReviews(t *testing.T) {
+	reviews := []*db.Review{
+		{ReviewerAgent: "Stamp", Decision: db.DecisionApprove},
+		{ReviewerAgent: "Stamp", Decision: db.DecisionReject},
+		{ReviewerAgent: "Stamp", Decision: db.DecisionReject, Segments: []int{1}},
+		{ReviewerAgent: "Careful", Decision: db.DecisionApprove},
+	}
+	flagged := map[string]bool{"Stamp": true}
+
+	critical := &db.Request{RiskTier: db.RiskTierCritical}
+	eligible, excluded := eligibleReviews(critical, reviews, flagged)
+	if len(eligible) != 2 || eligible[0] != reviews[1] || eligible[1] != reviews[3] {
+		t.Errorf("eligible = %v", eligible)
+	}
+	if !reflect.DeepEqual(excluded, []string{"Stamp", "Stamp"}) {
+		t.Errorf("excluded = %v", excluded)
+	}
+	if a, r := countDecisions(eligible); a != 1 || r != 1 {
+		t.Errorf("countDecisions = %d, %d", a, r)
+	}
+
+	dangerous := &db.Request{RiskTier: db.RiskTierDangerous}
+	if eligible, excluded := eligibleReviews(dangerous, reviews, flagged); len(eligible) != 4 || excluded != nil {
+		t.Errorf("non-critical requests should not be filtered, got %d / %v", len(eligible), excluded)
+	}
+}
+
+func TestSubmitReview_FlaggedReviewerExcludedFromCriticalQuorum(t *testing.T) {
+	dbConn, requestor, _ := setupReviewTest(t)
+	defer dbConn.Close()
+
+	stamp := &db.Session{AgentName: "Stamp", Program: "claude-code", Model: "opus", ProjectPath: "/test/project"}
+	if err := dbConn.CreateSession(stamp); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	newRequest := func(tier db.RiskTier) *db.Request {
+		req := &db.Request{
+			ProjectPath:        "/test/project",
+			RequestorSessionID: requestor.ID,
+			RequestorAgent:     requestor.AgentName,
+			RequestorModel:     requestor.Model,
+			RiskTier:           tier,
+			MinApprovals:       1,
+			Command:            db.CommandSpec{Raw: "rm -rf ./build", Cwd: "/test/project"},
+		}
+		if err := dbConn.CreateRequest(req); err != nil {
+			t.Fatalf("CreateRequest() error = %v", err)
+		}
+		return req
+	}
+
+	cfg := DefaultReviewConfig()
+	cfg.ReviewerThresholds = ReviewerThresholds{ApprovalStreak: 2, MinReviews: 2, ExcludeCritical: true}
+	rs := NewReviewService(dbConn, cfg)
+	approve := func(req *db.Request) *ReviewResult {
+		t.Helper()
+		result, err := rs.SubmitReview(ReviewOptions{
+			SessionID:  stamp.ID,
+			SessionKey: stamp.SessionKey,
+			RequestID:  req.ID,
+			Decision:   db.DecisionApprove,
+		})
+		if err != nil {
+			t.Fatalf("SubmitReview() error = %v", err)
+		}
+		return result
+	}
+
+	// Build up a streak on non-critical requests; those still count.
+	for i := 0; i < 2; i++ {
+		if result := approve(newRequest(db.RiskTierDangerous)); result.NewRequestStatus != db.StatusApproved {
+			t.Fatalf("dangerous request status = %q, want approved", result.NewRequestStatus)
+		}
+	}
+
+	critical := newRequest(db.RiskTierCritical)
+	result := approve(critical)
+	if result.RequestStatusChanged || result.Approvals != 0 {
+		t.Errorf("flagged approval should not satisfy CRITICAL quorum: %+v", result)
+	}
+	if !reflect.DeepEqual(result.ExcludedReviewers, []string{"Stamp"}) {
+		t.Errorf("ExcludedReviewers = %v", result.ExcludedReviewers)
+	}
+
+	// Another session attests the reviewer, starting a fresh window.
+	if err := dbConn.CreatePolicyAttestation(&db.PolicyAttestation{
+		ProjectPath: db.ReviewerAttestationScope("Stamp"),
+		PolicyHash:  db.ReviewerAttestationHash("Stamp"),
+		SessionID:   requestor.ID,
+		AgentName:   requestor.AgentName,
+		CreatedAt:   time.Now().UTC().Add(time.Second),
+	}); err != nil {
+		t.Fatalf("CreatePolicyAttestation() error = %v", err)
+	}
+	if result := approve(newRequest(db.RiskTierCritical)); result.NewRequestStatus != db.StatusApproved || len(result.ExcludedReviewers) != 0 {
+		t.Errorf("attested reviewer approval should count: %+v", result)
+	}
+}
@@ -0,0 +1,360 @@
+// Package core implements here-doc parsing and inspection for classification.
+package core
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Heredoc is a here-doc (<<DELIM ... DELIM) found in a command. Its body is
+// data for the command it feeds, not more commands, so classification only
+// looks at it through the inspector for that command (see inspectHeredocs).
+type Heredoc struct {
+	// Command is the command line the here-doc belongs to, without the body
+	// (e.g. "psql -d app <<SQL" or "cat <<EOF | kubectl apply -f -").
+	Command string
+	// Delimiter is the unquoted terminator word.
+	Delimiter string
+	// Body is the here-doc content, one line per line, without the terminator.
+	Body string
+}
+
+// heredocOp is a here-doc redirection waiting for its body.
+type heredocOp struct {
+	delimiter string
+	stripTabs bool // <<- strips leading tabs from body and terminator lines
+}
+
+// parseHeredocOp parses a here-doc redirection starting at runes[i] ("<<").
+// It returns the operator and the index just past its delimiter word, or
+// ok=false when runes[i] does not start one (e.g. a <<< here-string).
+func parseHeredocOp(runes []rune, i int) (op heredocOp, end int, ok bool) {
+	if i+1 >= len(runes) || runes[i] != '<' || runes[i+1] != '<' {
+		return heredocOp{}, i, false
+	}
+	if i > 0 && runes[i-1] == '<' {
+		return heredocOp{}, i, false
+	}
+	j := i + 2
+	if j < len(runes) && runes[j] == '<' {
+		return heredocOp{}, i, false
+	}
+	if j < len(runes) && runes[j] == '-' {
+		op.stripTabs = true
+		j++
+	}
+	for j < len(runes) && (runes[j] == ' ' || runes[j] == '\t') {
+		j++
+	}
+
+	var delim strings.Builder
+	for j < len(runes) {
+		r := runes[j]
+		if r == '\'' || r == '"' {
+			close := j + 1
+			for close < len(runes) && runes[close] != r {
+				close++
+			}
+			delim.WriteString(string(runes[j+1 : min(close, len(runes))]))
+			j = close + 1
+			continue
+		}
+		if r == '\\' && j+1 < len(runes) {
+			delim.WriteRune(runes[j+1])
+			j += 2
+			continue
+		}
+		if r == ' ' || r == '\t' || r == '\n' || strings.ContainsRune(";&|<>()", r) {
+			break
+		}
+		delim.WriteRune(r)
+		j++
+	}
+	if delim.Len() == 0 {
+		return heredocOp{}, i, false
+	}
+	op.delimiter = delim.String()
+	return op, min(j, len(runes)), true
+}
+
+// readHeredocBodies reads the bodies of pending here-docs, which start on the
+// line after the newline at runes[nl]. It returns each body and the index
+// just past the last terminator line: the newline that ends the command, or
+// len(runes) if the input ends first (an unterminated body runs to the end).
+func readHeredocBodies(runes []rune, nl int, pending []heredocOp) ([]string, int) {
+	bodies := make([]string, 0, len(pending))
+	k := nl + 1
+	for _, op := range pending {
+		var lines []string
+		for k < len(runes) {
+			e := k
+			for e < len(runes) && runes[e] != '\n' {
+				e++
+			}
+			line := string(runes[k:e])
+			check := line
+			if op.stripTabs {
+				check = strings.TrimLeft(check, "\t")
+			}
+			if check == op.delimiter {
+				k = e + 1
+				break
+			}
+			lines = append(lines, line)
+			k = e + 1
+		}
+		bodies = append(bodies, strings.Join(lines, "\n"))
+	}
+	return bodies, min(k-1, len(runes))
+}
+
+// splitHeredocs separates a segment into its command text and here-docs.
+func splitHeredocs(seg string) (string, []Heredoc) {
+	runes := []rune(seg)
+	var (
+		cmd          strings.Builder
+		pending      []heredocOp
+		docs         []Heredoc
+		inSingle     bool
+		inDouble     bool
+		escaped      bool
+		lineStartIdx int
+	)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && !inSingle:
+			escaped = true
+		case r == '\'' && !inDouble:
+			inSingle = !inSingle
+		case r == '"' && !inSingle:
+			inDouble = !inDouble
+		case !inSingle && !inDouble && r == '<':
+			if op, end, ok := parseHeredocOp(runes, i); ok {
+				pending = append(pending, op)
+				cmd.WriteString(string(runes[i:end]))
+				i = end - 1
+				continue
+			}
+		case !inSingle && !inDouble && r == '\n' && len(pending) > 0:
+			bodies, next := readHeredocBodies(runes, i, pending)
+			line := strings.TrimSpace(cmd.String()[lineStartIdx:])
+			for n, op := range pending {
+				docs = append(docs, Heredoc{Command: line, Delimiter: op.delimiter, Body: bodies[n]})
+			}
+			pending = nil
+			i = next
+			cmd.WriteRune('\n')
+			lineStartIdx = cmd.Len()
+			continue
+		case !inSingle && !inDouble && r == '\n':
+			cmd.WriteRune(r)
+			lineStartIdx = cmd.Len()
+			continue
+		}
+		cmd.WriteRune(r)
+	}
+	// A here-doc operator with no body (the command ends on its line).
+	for _, op := range pending {
+		docs = append(docs, Heredoc{Command: strings.TrimSpace(cmd.String()[lineStartIdx:]), Delimiter: op.delimiter})
+	}
+	if len(docs) == 0 {
+		return seg, nil
+	}
+	return strings.TrimSpace(cmd.String()), docs
+}
+
+// Commands whose here-doc input is inspected.
+var (
+	heredocSQLClients  = map[string]bool{"psql": true, "mysql": true, "mariadb": true, "sqlite3": true}
+	manifestKindRe     = regexp.MustCompile(`(?m)^\s*kind:\s*["']?([A-Za-z]+)["']?\s*$`)
+	criticalDeleteKind = map[string]bool{
+		"namespace": true, "node": true, "persistentvolume": true, "persistentvolumeclaim": true,
+	}
+	clusterWideKinds = map[string]bool{
+		"namespace": true, "node": true, "persistentvolume": true,
+		"clusterrole": true, "clusterrolebinding": true, "customresourcedefinition": true,
+		"mutatingwebhookconfiguration": true, "validatingwebhookconfiguration": true,
+	}
+)
+
+// heredocConsumer returns the tokens of the command that reads a here-doc:
+// the command carrying the <<, or, for `cat <<EOF | cmd`, the command cat
+// pipes into.
+func heredocConsumer(command string) []string {
+	parts := pipePattern.Split(command, -1)
+	idx := 0
+	for i, part := range parts {
+		if strings.Contains(part, "<<") {
+			idx = i
+			break
+		}
+	}
+	for idx+1 < len(parts) {
+		fields := strings.Fields(parts[idx])
+		if len(fields) == 0 || filepath.Base(fields[0]) != "cat" {
+			break
+		}
+		idx++
+	}
+	normalized, _, _ := normalizeSegment(strings.TrimSpace(parts[idx]))
+	return strings.Fields(normalized)
+}
+
+// inspectHeredocs classifies here-doc bodies fed to a shell (as commands), a
+// SQL client (through InspectSQL) or kubectl reading manifests from stdin.
+// Bodies fed to anything else are data and are not classified.
+func (e *PatternEngine) inspectHeredocs(docs []Heredoc, cwd string) []SegmentMatch {
+	var matches []SegmentMatch
+	for _, doc := range docs {
+		if strings.TrimSpace(doc.Body) == "" {
+			continue
+		}
+		tokens := heredocConsumer(doc.Command)
+		if len(tokens) == 0 {
+			continue
+		}
+		name := filepath.Base(tokens[0])
+		var tier RiskTier
+		var pattern string
+		switch {
+		case isShellExecutor(name) && !containsToken(tokens[1:], "-c"):
+			res := e.ClassifyCommand(doc.Body, cwd)
+			if res.NeedsApproval {
+				tier, pattern = res.Tier, res.MatchedPattern
+			}
+		case heredocSQLClients[name]:
+			tier, pattern = e.inspectSQLTier(doc.Body)
+		case name == "kubectl":
+			tier, pattern = inspectManifestStdin(tokens[1:], doc.Body)
+		}
+		if tier != "" {
+			matches = append(matches, SegmentMatch{
+				Segment:        doc.Command,
+				Tier:           tier,
+				MatchedPattern: "heredoc: " + pattern,
+			})
+		}
+	}
+	return matches
+}
+
+// inspectSQLTier returns the highest tier of the SQL patterns script matches.
+func (e *PatternEngine) inspectSQLTier(script string) (RiskTier, string) {
+	findings := e.InspectSQL(script)
+	if len(findings) == 0 {
+		return "", ""
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, f := range findings {
+		if f == "fallback_sql_delete_no_where" {
+			return RiskTierCritical, f
+		}
+		for _, p := range e.critical {
+			if p.Pattern == f {
+				return RiskTierCritical, f
+			}
+		}
+	}
+	return RiskTierDangerous, findings[0]
+}
+
+// inspectManifestStdin classifies manifests kubectl reads from stdin (-f -):
+// deleting namespaces, nodes or volumes is critical and any other delete
+// dangerous, like the kubectl delete patterns; applying cluster-wide kinds
+// is dangerous.
+func inspectManifestStdin(args []string, body string) (RiskTier, string) {
+	if len(args) == 0 || !readsManifestFromStdin(args) {
+		return "", ""
+	}
+	verb := args[0]
+	var kinds []string
+	for _, m := range manifestKindRe.FindAllStringSubmatch(body, -1) {
+		kinds = append(kinds, strings.ToLower(m[1]))
+	}
+	switch verb {
+	case "delete":
+		for _, k := range kinds {
+			if criticalDeleteKind[k] {
+				return RiskTierCritical, "kubectl delete -f - kind: " + k
+			}
+		}
+		return RiskTierDangerous, "kubectl delete -f -"
+	case "apply", "create", "replace":
+		for _, k := range kinds {
+			if clusterWideKinds[k] {
+				return RiskTierDangerous, "kubectl " + verb + " -f - kind: " + k
+			}
+		}
+	}
+	return "", ""
+}
+
+func readsManifestFromStdin(args []string) bool {
+	for i, a := range args {
+		switch {
+		case a == "-f-" || a == "--filename=-" || a == "-f=-":
+			return true
+		case (a == "-f" || a == "--filename") && i+1 < len(args) && args[i+1] == "-":
+			return true
+		}
+	}
+	return false
+}
+
+func isShellExecutor(name string) bool {
+	for _, s := range shellExecutors {
+		if name == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsToken(tokens []string, want string) bool {
+	for _, t := range tokens {
+		if t == want {
+			return true
+		}
+	}
+	return false
+}
+
+// tierRank orders tiers for picking the highest: critical > dangerous >
+// caution > safe/none.
+func tierRank(t RiskTier) int {
+	switch t {
+	case RiskTierCritical:
+		return 3
+	case RiskTierDangerous:
+		return 2
+	case RiskTierCaution:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// applyHeredocInspection raises res to the highest tier found by inspecting
+// cmd's here-docs.
+func (e *PatternEngine) applyHeredocInspection(res *MatchResult, cmd, cwd string) *MatchResult {
+	docs := NormalizeCommand(cmd).Heredocs
+	if len(docs) == 0 {
+		return res
+	}
+	for _, m := range e.inspectHeredocs(docs, cwd) {
+		res.MatchedSegments = append(res.MatchedSegments, m)
+		if tierRank(m.Tier) > tierRank(res.Tier) || (res.IsSafe && m.Tier != "") {
+			res.Tier = m.Tier
+			res.MatchedPattern = m.MatchedPattern
+			res.MinApprovals = tierApprovals(m.Tier)
+			res.NeedsApproval = true
+			res.IsSafe = false
+		}
+	}
+	return res
+}
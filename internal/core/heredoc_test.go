@@ -0,0 +1,157 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandSegments_Heredoc(t *testing.T) {
+	cmd := "cat <<EOF > notes.txt\na; b && c | d\nEOF\nrm -rf /tmp/x"
+	got := CommandSegments(cmd)
+	want := []string{"cat <<EOF > notes.txt\na; b && c | d\nEOF", "rm -rf /tmp/x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CommandSegments() = %q, want %q", got, want)
+	}
+	if ops := SegmentOperators(cmd); !reflect.DeepEqual(ops, []string{";", ""}) {
+		t.Errorf("SegmentOperators() = %q", ops)
+	}
+}
+
+func TestCommandSegments_Newlines(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string
+	}{
+		{"echo a\nrm -rf /tmp/x", []string{"echo a", "rm -rf /tmp/x"}},
+		{"make build &&\n  make test", []string{"make build", "make test"}},
+		{"ls |\n  grep foo", []string{"ls |\n  grep foo"}},
+		{"echo 'a\nb'", []string{"echo 'a\nb'"}},
+		{"cat <<< 'x'\necho y", []string{"cat <<< 'x'", "echo y"}},
+	}
+	for _, tt := range tests {
+		if got := CommandSegments(tt.cmd); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CommandSegments(%q) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestSplitHeredocs(t *testing.T) {
+	tests := []struct {
+		name    string
+		seg     string
+		command string
+		docs    []Heredoc
+	}{
+		{
+			name:    "plain",
+			seg:     "psql -d app <<SQL\nDROP TABLE users;\nSQL",
+			command: "psql -d app <<SQL",
+			docs:    []Heredoc{{Command: "psql -d app <<SQL", Delimiter: "SQL", Body: "DROP TABLE users;"}},
+		},
+		{
+			name:    "quoted delimiter and tab stripping",
+			seg:     "sh <<-'END'\n\techo $HOME\n\tEND",
+			command: "sh <<-'END'",
+			docs:    []Heredoc{{Command: "sh <<-'END'", Delimiter: "END", Body: "\techo $HOME"}},
+		},
+		{
+			name:    "unterminated body runs to the end",
+			seg:     "cat <<EOF\nline 1\nline 2",
+			command: "cat <<EOF",
+			docs:    []Heredoc{{Command: "cat <<EOF", Delimiter: "EOF", Body: "line 1\nline 2"}},
+		},
+		{
+			name:    "two here-docs on one line",
+			seg:     "diff <(cat) /dev/fd/3 <<A 3<<B\na\nA\nb\nB",
+			command: "diff <(cat) /dev/fd/3 <<A 3<<B",
+			docs: []Heredoc{
+				{Command: "diff <(cat) /dev/fd/3 <<A 3<<B", Delimiter: "A", Body: "a"},
+				{Command: "diff <(cat) /dev/fd/3 <<A 3<<B", Delimiter: "B", Body: "b"},
+			},
+		},
+		{
+			name:    "here-string is not a here-doc",
+			seg:     "cat <<< 'EOF'",
+			command: "cat <<< 'EOF'",
+		},
+		{
+			name:    "quoted << is not a here-doc",
+			seg:     "echo '<<EOF'",
+			command: "echo '<<EOF'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, docs := splitHeredocs(tt.seg)
+			if command != tt.command {
+				t.Errorf("command = %q, want %q", command, tt.command)
+			}
+			if !reflect.DeepEqual(docs, tt.docs) {
+				t.Errorf("docs = %+v, want %+v", docs, tt.docs)
+			}
+		})
+	}
+}
+
+func TestNormalizeCommand_HeredocBodyIsNotSegments(t *testing.T) {
+	n := NormalizeCommand("cat <<EOF > notes.txt\nrm -rf / ; git push --force\nEOF")
+	if len(n.Segments) != 1 {
+		t.Fatalf("Segments = %q, want the cat command only", n.Segments)
+	}
+	if strings.Contains(n.Segments[0], "rm") {
+		t.Errorf("segment %q contains the here-doc body", n.Segments[0])
+	}
+	if len(n.Heredocs) != 1 || n.Heredocs[0].Body != "rm -rf / ; git push --force" {
+		t.Errorf("Heredocs = %+v", n.Heredocs)
+	}
+}
+
+func TestClassifyCommand_Heredocs(t *testing.T) {
+	engine := NewPatternEngine()
+
+	tests := []struct {
+		name string
+		cmd  string
+		tier RiskTier // "" means no approval needed
+	}{
+		{"body fed to cat is data", "cat <<EOF > notes.txt\nrm -rf /etc\nDROP DATABASE prod;\nEOF", ""},
+		{"body fed to a shell is classified", "bash <<EOF\nrm -rf /etc\nEOF", RiskTierCritical},
+		{"body fed to sh via cat pipe", "cat <<'EOF' | sh\ngit reset --hard HEAD~3\nEOF", RiskTierDangerous},
+		{"sql body is inspected", "psql -d app <<SQL\nDROP DATABASE prod;\nSQL", RiskTierCritical},
+		{"sql delete without where", "mysql app <<SQL\nDELETE FROM users;\nSQL", RiskTierCritical},
+		{"harmless sql", "psql -d app <<SQL\nSELECT count(*) FROM users;\nSQL", ""},
+		{"kubectl delete of a namespace manifest", "kubectl delete -f - <<EOF\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: prod\nEOF", RiskTierCritical},
+		{"kubectl apply of a cluster role", "cat <<EOF | kubectl apply -f -\nkind: ClusterRole\nEOF", RiskTierDangerous},
+		{"kubectl apply of a deployment", "kubectl apply -f - <<EOF\nkind: Deployment\nEOF", ""},
+		{"command after the here-doc is classified", "cat <<EOF > notes.txt\nhello\nEOF\nrm -rf /etc", RiskTierCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := engine.ClassifyCommand(tt.cmd, "")
+			if tt.tier == "" {
+				if res.NeedsApproval {
+					t.Fatalf("expected no approval, got tier %s (%s)", res.Tier, res.MatchedPattern)
+				}
+				return
+			}
+			if !res.NeedsApproval || res.Tier != tt.tier {
+				t.Fatalf("tier = %q (needs approval %v, pattern %q), want %q", res.Tier, res.NeedsApproval, res.MatchedPattern, tt.tier)
+			}
+		})
+	}
+}
+
+func TestApplyRedaction_PreservesLines(t *testing.T) {
+	cmd := "mysql app <<SQL\n-- password:\nSELECT 1;\nSQL"
+	got := ApplyRedaction(cmd, nil)
+	if got != cmd {
+		t.Errorf("ApplyRedaction() = %q, want the command unchanged", got)
+	}
+
+	cmd = "export TOKEN=abc123\necho done"
+	got = ApplyRedaction(cmd, nil)
+	if strings.Count(got, "\n") != 1 || strings.Contains(got, "abc123") {
+		t.Errorf("ApplyRedaction() = %q, want the token redacted and both lines kept", got)
+	}
+}
@@ -0,0 +1,340 @@
+// Package core implements migration awareness for migration-runner commands.
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Supported migration runners.
+const (
+	MigrationRunnerAlembic       = "alembic"
+	MigrationRunnerFlyway        = "flyway"
+	MigrationRunnerGolangMigrate = "golang-migrate"
+)
+
+// DefaultMigrationAttachmentBytes caps the migration contents attached to a
+// request.
+const DefaultMigrationAttachmentBytes = 256 * 1024
+
+// ErrMigrationsChanged is returned at execution time when the migration
+// files differ from the ones that were approved.
+var ErrMigrationsChanged = errors.New("migration files changed since approval")
+
+// defaultMigrationGlobs are each runner's conventional migration locations,
+// relative to the command's cwd.
+var defaultMigrationGlobs = map[string][]string{
+	MigrationRunnerAlembic:       {"alembic/versions/*.py", "migrations/versions/*.py"},
+	MigrationRunnerFlyway:        {"sql/*.sql", "db/migration/*.sql", "src/main/resources/db/migration/*.sql"},
+	MigrationRunnerGolangMigrate: {"migrations/*.sql", "db/migrations/*.sql"},
+}
+
+var (
+	alembicRunnerRe = regexp.MustCompile(`^(\S*/)?alembic\b.*\b(upgrade|downgrade)\b`)
+	flywayRunnerRe  = regexp.MustCompile(`^(\S*/)?flyway\b.*\bmigrate\b`)
+	migrateRunnerRe = regexp.MustCompile(`^(\S*/)?migrate\b.*\b(up|down|goto)\b`)
+	alembicDropRe   = regexp.MustCompile(`\bop\.drop_(table|column|index|constraint)\b`)
+)
+
+// MigrationOptions configures migration capture.
+type MigrationOptions struct {
+	// Globs are extra patterns, relative to the command's cwd, that locate
+	// migration files in addition to the runner's conventional directories.
+	Globs []string
+	// MaxAttachmentBytes caps the attached file contents (0 uses
+	// DefaultMigrationAttachmentBytes, negative attaches only the summary).
+	MaxAttachmentBytes int
+	// RedactPatterns are custom patterns masked in attached contents.
+	RedactPatterns []string
+}
+
+// DetectMigrationRunner returns the migration runner a command invokes, or
+// "" if it does not run migrations.
+func DetectMigrationRunner(cmd string) string {
+	for _, seg := range NormalizeCommand(cmd).Segments {
+		seg = strings.TrimSpace(seg)
+		switch {
+		case alembicRunnerRe.MatchString(seg):
+			return MigrationRunnerAlembic
+		case flywayRunnerRe.MatchString(seg):
+			return MigrationRunnerFlyway
+		case migrateRunnerRe.MatchString(seg):
+			return MigrationRunnerGolangMigrate
+		}
+	}
+	return ""
+}
+
+// migrationPatterns returns the globs to search for a runner. Locations given
+// on the command line (migrate -path/-source, flyway -locations) replace the
+// conventional directories.
+func migrationPatterns(runner string, argv []string, extra []string) []string {
+	var dirs []string
+	for i, arg := range argv {
+		next := ""
+		if i+1 < len(argv) {
+			next = argv[i+1]
+		}
+		switch runner {
+		case MigrationRunnerGolangMigrate:
+			switch {
+			case arg == "-path" || arg == "--path":
+				dirs = append(dirs, next)
+			case strings.HasPrefix(arg, "-path=") || strings.HasPrefix(arg, "--path="):
+				dirs = append(dirs, arg[strings.Index(arg, "=")+1:])
+			case arg == "-source" || arg == "--source":
+				if strings.HasPrefix(next, "file://") {
+					dirs = append(dirs, strings.TrimPrefix(next, "file://"))
+				}
+			case strings.HasPrefix(arg, "-source=file://") || strings.HasPrefix(arg, "--source=file://"):
+				dirs = append(dirs, arg[strings.Index(arg, "file://")+len("file://"):])
+			}
+		case MigrationRunnerFlyway:
+			if strings.HasPrefix(arg, "-locations=") {
+				for _, loc := range strings.Split(strings.TrimPrefix(arg, "-locations="), ",") {
+					if strings.HasPrefix(loc, "filesystem:") {
+						dirs = append(dirs, strings.TrimPrefix(loc, "filesystem:"))
+					}
+				}
+			}
+		}
+	}
+
+	var patterns []string
+	if len(dirs) > 0 {
+		for _, dir := range dirs {
+			patterns = append(patterns, path.Join(filepath.ToSlash(dir), "*.sql"))
+		}
+	} else {
+		patterns = append(patterns, defaultMigrationGlobs[runner]...)
+	}
+	return append(patterns, extra...)
+}
+
+// CaptureMigrations locates the migration files a migration-runner command
+// will read and returns them bound to the command, with attachments for
+// reviewers: a summary plus the (redacted, size-capped) file contents.
+// It returns nil for commands that do not run migrations.
+//
+// SLB cannot see which migrations a database has already applied, so every
+// file in the migration directories is bound; adding, removing or editing
+// any of them after approval blocks execution.
+func CaptureMigrations(cmd db.CommandSpec, opts MigrationOptions) (*db.MigrationSet, []db.Attachment, error) {
+	runner := DetectMigrationRunner(cmd.Raw)
+	if runner == "" {
+		return nil, nil, nil
+	}
+	argv := cmd.Argv
+	if len(argv) == 0 {
+		argv, _ = ParseCommandToArgv(cmd.Raw)
+	}
+	set := &db.MigrationSet{
+		Runner:   runner,
+		Patterns: migrationPatterns(runner, argv, opts.Globs),
+	}
+	files, contents, err := readMigrationFiles(cmd.Cwd, set.Patterns)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range files {
+		files[i].Destructive = inspectMigration(files[i].Path, contents[i])
+	}
+	set.Files = files
+	set.Digest = migrationDigest(set)
+	return set, migrationAttachments(set, contents, opts), nil
+}
+
+// VerifyMigrations re-reads a request's bound migration files and reports
+// ErrMigrationsChanged if any was added, removed or edited since approval.
+func VerifyMigrations(cmd db.CommandSpec, set *db.MigrationSet) error {
+	if set == nil {
+		if cmd.MigrationsDigest != "" {
+			return fmt.Errorf("%w: migration record is missing", ErrMigrationsChanged)
+		}
+		return nil
+	}
+	files, _, err := readMigrationFiles(cmd.Cwd, set.Patterns)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMigrationsChanged, err)
+	}
+	current := &db.MigrationSet{Patterns: set.Patterns, Files: files}
+	if digest := migrationDigest(current); digest != cmd.MigrationsDigest || digest != set.Digest {
+		return fmt.Errorf("%w: %s", ErrMigrationsChanged, describeMigrationChanges(set.Files, files))
+	}
+	return nil
+}
+
+// readMigrationFiles expands patterns under dir and hashes every match.
+func readMigrationFiles(dir string, patterns []string) ([]db.MigrationFile, [][]byte, error) {
+	found := make(map[string]string) // relative path -> file
+	for _, pattern := range patterns {
+		full := pattern
+		if !filepath.IsAbs(pattern) {
+			full = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(full)
+		if err != nil {
+			return nil, nil, fmt.Errorf("migration glob %q: %w", pattern, err)
+		}
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			rel, err := filepath.Rel(dir, m)
+			if err != nil {
+				rel = m
+			}
+			found[filepath.ToSlash(rel)] = m
+		}
+	}
+	rels := make([]string, 0, len(found))
+	for rel := range found {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	files := make([]db.MigrationFile, 0, len(rels))
+	contents := make([][]byte, 0, len(rels))
+	for _, rel := range rels {
+		data, err := os.ReadFile(found[rel])
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading migration %s: %w", rel, err)
+		}
+		sum := sha256.Sum256(data)
+		files = append(files, db.MigrationFile{Path: rel, SHA256: hex.EncodeToString(sum[:]), Size: int64(len(data))})
+		contents = append(contents, data)
+	}
+	return files, contents, nil
+}
+
+// migrationDigest hashes a set's patterns and file hashes.
+func migrationDigest(set *db.MigrationSet) string {
+	h := sha256.New()
+	for _, p := range set.Patterns {
+		fmt.Fprintf(h, "pattern %s\n", p)
+	}
+	for _, f := range set.Files {
+		fmt.Fprintf(h, "file %s %s\n", f.Path, f.SHA256)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// inspectMigration reports destructive statements in a migration file.
+func inspectMigration(name string, content []byte) []string {
+	findings := GetDefaultEngine().InspectSQL(string(content))
+	if strings.HasSuffix(name, ".py") {
+		for _, m := range alembicDropRe.FindAllString(string(content), -1) {
+			if !containsString(findings, m) {
+				findings = append(findings, m)
+			}
+		}
+	}
+	return findings
+}
+
+// migrationAttachments builds the summary and content attachments.
+func migrationAttachments(set *db.MigrationSet, contents [][]byte, opts MigrationOptions) []db.Attachment {
+	maxBytes := opts.MaxAttachmentBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultMigrationAttachmentBytes
+	}
+
+	var b strings.Builder
+	destructive := 0
+	for _, f := range set.Files {
+		if len(f.Destructive) > 0 {
+			destructive++
+		}
+	}
+	fmt.Fprintf(&b, "%s migrations: %d file(s), %d with destructive SQL\n", set.Runner, len(set.Files), destructive)
+	if len(set.Files) == 0 {
+		fmt.Fprintf(&b, "No migration files matched %s\n", strings.Join(set.Patterns, ", "))
+	}
+	for _, f := range set.Files {
+		fmt.Fprintf(&b, "  %s (%d bytes)", f.Path, f.Size)
+		if len(f.Destructive) > 0 {
+			fmt.Fprintf(&b, " DESTRUCTIVE: %s", strings.Join(f.Destructive, ", "))
+		}
+		b.WriteString("\n")
+	}
+	attachments := []db.Attachment{{
+		Type:    db.AttachmentTypeContext,
+		Content: b.String(),
+		Metadata: map[string]any{
+			"migration_summary": true,
+			"runner":            set.Runner,
+			"files":             len(set.Files),
+			"destructive_files": destructive,
+		},
+	}}
+	if maxBytes < 0 {
+		return attachments
+	}
+
+	// Newest migrations (last in path order) are the likeliest to be
+	// pending, so they get the budget first.
+	budget := maxBytes
+	var files []db.Attachment
+	for i := len(set.Files) - 1; i >= 0 && budget > 0; i-- {
+		content := ApplyRedaction(string(contents[i]), opts.RedactPatterns)
+		truncated := false
+		if len(content) > budget {
+			content = strings.ToValidUTF8(content[:budget], "")
+			truncated = true
+		}
+		budget -= len(content)
+		files = append(files, db.Attachment{
+			Type:    db.AttachmentTypeFile,
+			Content: content,
+			Metadata: map[string]any{
+				"migration": true,
+				"path":      set.Files[i].Path,
+				"sha256":    set.Files[i].SHA256,
+				"truncated": truncated,
+			},
+		})
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		attachments = append(attachments, files[i])
+	}
+	return attachments
+}
+
+// describeMigrationChanges lists added, removed and edited files.
+func describeMigrationChanges(approved, current []db.MigrationFile) string {
+	before := make(map[string]string, len(approved))
+	for _, f := range approved {
+		before[f.Path] = f.SHA256
+	}
+	var changes []string
+	for _, f := range current {
+		sum, ok := before[f.Path]
+		switch {
+		case !ok:
+			changes = append(changes, "added "+f.Path)
+		case sum != f.SHA256:
+			changes = append(changes, "edited "+f.Path)
+		}
+		delete(before, f.Path)
+	}
+	for _, f := range approved {
+		if _, ok := before[f.Path]; ok {
+			changes = append(changes, "removed "+f.Path)
+		}
+	}
+	if len(changes) == 0 {
+		return "migration digest mismatch"
+	}
+	return strings.Join(changes, ", ")
+}
@@ -0,0 +1,249 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func writeMigration(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetectMigrationRunner(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{"alembic upgrade head", MigrationRunnerAlembic},
+		{"sudo alembic -c prod.ini downgrade -1", MigrationRunnerAlembic},
+		{"alembic history", ""},
+		{"flyway -url=jdbc:x migrate", MigrationRunnerFlyway},
+		{"flyway info", ""},
+		{"migrate -path db/migrations -database $DB up", MigrationRunnerGolangMigrate},
+		{"cd app && migrate up", MigrationRunnerGolangMigrate},
+		{"python manage.py migrate", ""},
+		{"rm -rf build", ""},
+	}
+	for _, tc := range tests {
+		if got := DetectMigrationRunner(tc.cmd); got != tc.want {
+			t.Errorf("DetectMigrationRunner(%q) = %q, want %q", tc.cmd, got, tc.want)
+		}
+	}
+}
+
+func TestMigrationPatterns(t *testing.T) {
+	got := migrationPatterns(MigrationRunnerGolangMigrate, []string{"migrate", "-path", "schema", "up"}, []string{"extra/*.sql"})
+	want := []string{"schema/*.sql", "extra/*.sql"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("patterns = %v, want %v", got, want)
+	}
+	got = migrationPatterns(MigrationRunnerFlyway, []string{"flyway", "-locations=filesystem:db/sql,classpath:x", "migrate"}, nil)
+	if strings.Join(got, ",") != "db/sql/*.sql" {
+		t.Errorf("flyway patterns = %v", got)
+	}
+	got = migrationPatterns(MigrationRunnerAlembic, []string{"alembic", "upgrade", "head"}, nil)
+	if len(got) == 0 || got[0] != "alembic/versions/*.py" {
+		t.Errorf("alembic patterns = %v", got)
+	}
+}
+
+func TestInspectSQL(t *testing.T) {
+	engine := NewPatternEngine()
+	if got := engine.InspectSQL("ALTER TABLE users ADD COLUMN age int;\nCREATE INDEX i ON users(age);"); len(got) != 0 {
+		t.Errorf("additive migration flagged: %v", got)
+	}
+	got := engine.InspectSQL("UPDATE t SET a = 1 WHERE id = 2;\ndelete from sessions;\ndrop table\n  audit;")
+	joined := strings.Join(got, ",")
+	if !strings.Contains(joined, "fallback_sql_delete_no_where") || !strings.Contains(joined, `DROP\s+TABLE`) {
+		t.Errorf("expected delete-without-where and drop table, got %v", got)
+	}
+}
+
+func TestCaptureMigrations(t *testing.T) {
+	dir := t.TempDir()
+	writeMigration(t, dir, "migrations/0001_init.up.sql", "CREATE TABLE users (id int);")
+	writeMigration(t, dir, "migrations/0002_drop.up.sql", "DROP TABLE legacy; -- password=hunter2")
+	writeMigration(t, dir, "migrations/README.md", "not a migration")
+
+	cmd := db.CommandSpec{Raw: "migrate -database $DB up", Cwd: dir}
+	set, attachments, err := CaptureMigrations(cmd, MigrationOptions{})
+	if err != nil {
+		t.Fatalf("CaptureMigrations: %v", err)
+	}
+	if set == nil || set.Runner != MigrationRunnerGolangMigrate || len(set.Files) != 2 {
+		t.Fatalf("unexpected set: %+v", set)
+	}
+	if len(set.Files[0].Destructive) != 0 || len(set.Files[1].Destructive) == 0 {
+		t.Errorf("destructive detection wrong: %+v", set.Files)
+	}
+	if set.Digest == "" {
+		t.Error("expected a digest")
+	}
+
+	if len(attachments) != 3 {
+		t.Fatalf("expected summary + 2 files, got %d", len(attachments))
+	}
+	if !strings.Contains(attachments[0].Content, "2 file(s), 1 with destructive SQL") {
+		t.Errorf("summary = %q", attachments[0].Content)
+	}
+	for _, a := range attachments {
+		if strings.Contains(a.Content, "hunter2") {
+			t.Errorf("attachment not redacted: %q", a.Content)
+		}
+	}
+
+	if set, _, _ := CaptureMigrations(db.CommandSpec{Raw: "rm -rf build", Cwd: dir}, MigrationOptions{}); set != nil {
+		t.Errorf("non-migration command captured %+v", set)
+	}
+}
+
+func TestCaptureMigrations_AttachmentBudget(t *testing.T) {
+	dir := t.TempDir()
+	writeMigration(t, dir, "migrations/0001.up.sql", strings.Repeat("a", 100))
+	writeMigration(t, dir, "migrations/0002.up.sql", strings.Repeat("b", 100))
+
+	cmd := db.CommandSpec{Raw: "migrate up", Cwd: dir}
+	_, attachments, err := CaptureMigrations(cmd, MigrationOptions{MaxAttachmentBytes: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The newest file is attached whole, the older one truncated.
+	if len(attachments) != 3 || attachments[2].Content != strings.Repeat("b", 100) || len(attachments[1].Content) != 50 {
+		t.Fatalf("unexpected attachments: %+v", attachments)
+	}
+
+	_, attachments, err = CaptureMigrations(cmd, MigrationOptions{MaxAttachmentBytes: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attachments) != 1 {
+		t.Fatalf("summary-only capture attached %d items", len(attachments))
+	}
+}
+
+func TestVerifyMigrations(t *testing.T) {
+	setup := func(t *testing.T) (string, db.CommandSpec, *db.MigrationSet) {
+		dir := t.TempDir()
+		writeMigration(t, dir, "alembic/versions/a1_init.py", "op.create_table('users')")
+		cmd := db.CommandSpec{Raw: "alembic upgrade head", Cwd: dir}
+		set, _, err := CaptureMigrations(cmd, MigrationOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmd.MigrationsDigest = set.Digest
+		return dir, cmd, set
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		_, cmd, set := setup(t)
+		if err := VerifyMigrations(cmd, set); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("edited", func(t *testing.T) {
+		dir, cmd, set := setup(t)
+		writeMigration(t, dir, "alembic/versions/a1_init.py", "op.drop_table('users')")
+		err := VerifyMigrations(cmd, set)
+		if !errors.Is(err, ErrMigrationsChanged) || !strings.Contains(err.Error(), "edited alembic/versions/a1_init.py") {
+			t.Fatalf("expected edit to be caught, got %v", err)
+		}
+	})
+	t.Run("added", func(t *testing.T) {
+		dir, cmd, set := setup(t)
+		writeMigration(t, dir, "alembic/versions/b2_more.py", "op.drop_table('orders')")
+		if err := VerifyMigrations(cmd, set); !errors.Is(err, ErrMigrationsChanged) {
+			t.Fatalf("expected addition to be caught, got %v", err)
+		}
+	})
+	t.Run("record dropped", func(t *testing.T) {
+		_, cmd, _ := setup(t)
+		if err := VerifyMigrations(cmd, nil); !errors.Is(err, ErrMigrationsChanged) {
+			t.Fatalf("expected missing record to be caught, got %v", err)
+		}
+	})
+}
+
+func TestExecuteApprovedRequest_RefusesSwappedMigrations(t *testing.T) {
+	h := testutil.NewHarness(t)
+	writeMigration(t, h.ProjectDir, "migrations/0001.up.sql", "CREATE TABLE t (id int);")
+
+	sess := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	cmd := db.CommandSpec{Raw: "migrate up", Cwd: h.ProjectDir, Shell: true}
+	set, _, err := CaptureMigrations(cmd, MigrationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := testutil.MakeRequest(t, h.DB, sess,
+		testutil.WithCommand(cmd.Raw, cmd.Cwd, true),
+		testutil.WithStatus(db.StatusApproved),
+		func(r *db.Request) {
+			r.Command.MigrationsDigest = set.Digest
+			r.Migrations = set
+		},
+	)
+
+	got, err := h.DB.GetRequest(req.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Migrations == nil || got.Command.MigrationsDigest != set.Digest || !db.CommandHashMatches(got.Command) {
+		t.Fatalf("migrations not persisted with the command hash: %+v", got.Command)
+	}
+
+	writeMigration(t, h.ProjectDir, "migrations/0001.up.sql", "DROP TABLE t;")
+	_, err = NewExecutor(h.DB, nil).ExecuteApprovedRequest(context.Background(), ExecuteOptions{
+		RequestID: req.ID,
+		SessionID: sess.ID,
+		LogDir:    t.TempDir(),
+	})
+	if !errors.Is(err, ErrMigrationsChanged) {
+		t.Fatalf("expected ErrMigrationsChanged, got %v", err)
+	}
+}
+
+func TestCreateRequest_BindsMigrations(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	dir := t.TempDir()
+	writeMigration(t, dir, "migrations/0001.up.sql", "DROP TABLE legacy;")
+
+	engine := NewPatternEngine()
+	if err := engine.AddPattern(RiskTierCritical, `^migrate\b`, "migrations", "test"); err != nil {
+		t.Fatal(err)
+	}
+	creator := NewRequestCreator(database, nil, engine, nil)
+	result, err := creator.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "migrate up",
+		Cwd:           dir,
+		Justification: Justification{Reason: "ship schema"},
+		Attachments:   []db.Attachment{{Type: db.AttachmentTypeContext, Content: "ticket 42"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	req := result.Request
+	if req == nil || req.Migrations == nil || len(req.Migrations.Files) != 1 {
+		t.Fatalf("expected migrations bound to request, got %+v", req)
+	}
+	if req.Command.MigrationsDigest != req.Migrations.Digest {
+		t.Errorf("digest not bound into command")
+	}
+	if len(req.Attachments) != 3 || req.Attachments[0].Content != "ticket 42" {
+		t.Errorf("expected caller attachment plus summary and file, got %d", len(req.Attachments))
+	}
+}
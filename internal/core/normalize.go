@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/mattn/go-shellwords"
 )
 
@@ -22,10 +23,26 @@ type NormalizedCommand struct {
 	IsCompound bool
 	// HasSubshell indicates if the command contains subshells.
 	HasSubshell bool
+	// SubshellCommands are the normalized commands run inside the
+	// command's $(...) and `...` substitutions and ( ... ) groups, nested
+	// ones included, each with its segments joined by "; ".
+	SubshellCommands []string
 	// StrippedWrappers lists the wrappers that were stripped.
 	StrippedWrappers []string
+	// PrivilegeEscalation is the wrapper chain, such as "sudo su -c", of a
+	// segment that runs its command as another user through a nested shell.
+	// It is empty when no segment does (see privilegeEscalation).
+	PrivilegeEscalation string
 	// ParseError indicates if parsing failed (triggers tier upgrade).
 	ParseError bool
+	// Heredocs are the command's here-docs. Their bodies are removed from
+	// Segments: they are data, inspected separately (see inspectHeredocs).
+	Heredocs []Heredoc
+	// TeeWrites are the files written through tee by pipeline stages,
+	// recorded before wrappers such as sudo are stripped.
+	TeeWrites []TeeWrite
+	// Stripped is the trimmed command with here-doc bodies removed.
+	Stripped string
 }
 
 // Command wrapper prefixes to strip
@@ -41,6 +58,10 @@ var wrapperPrefixes = []string{
 	"nohup",
 	"strace",
 	"ltrace",
+	"timeout",
+	"xargs",
+	"flock",
+	"watch",
 }
 
 // Shell commands that execute other commands with -c flag
@@ -66,13 +87,33 @@ var envAssignPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
 // splitCompoundShellAware splits a command on compound separators (;, &&, ||, &)
 // while respecting shell quoting rules. Separators inside quotes are not split.
 func splitCompoundShellAware(cmd string) []string {
-	var segments []string
+	segments, _ := splitCompoundWithOps(cmd)
+	return segments
+}
+
+// splitCompoundWithOps is splitCompoundShellAware that also returns, for each
+// segment, the operator that followed it ("&&", "||", ";", "&", or "" for the
+// final segment). An & that belongs to a redirection (2>&1, &>file) is not a
+// separator. A newline separates like ";" (reported as ";"), and here-doc
+// bodies stay in the segment of the command they feed.
+func splitCompoundWithOps(cmd string) ([]string, []string) {
+	var segments, ops []string
 	var current strings.Builder
+	var pending []heredocOp
 	inSingleQuote := false
 	inDoubleQuote := false
 	escaped := false
 	runes := []rune(cmd)
 
+	flush := func(op string) {
+		seg := strings.TrimSpace(current.String())
+		if seg != "" {
+			segments = append(segments, seg)
+			ops = append(ops, op)
+		}
+		current.Reset()
+	}
+
 	for i := 0; i < len(runes); i++ {
 		r := runes[i]
 
@@ -104,26 +145,48 @@ func splitCompoundShellAware(cmd string) []string {
 
 		// Check for compound separators only when outside quotes
 		if !inSingleQuote && !inDoubleQuote {
+			// A here-doc body is data: it is kept verbatim in its segment
+			// and never split, so the segment still runs exactly as written.
+			if r == '<' {
+				if op, end, ok := parseHeredocOp(runes, i); ok {
+					pending = append(pending, op)
+					current.WriteString(string(runes[i:end]))
+					i = end - 1
+					continue
+				}
+			}
+
+			// A newline ends a command like ; does, unless the line ends in
+			// a pipe. Pending here-doc bodies start on the next line.
+			if r == '\n' {
+				if len(pending) > 0 {
+					_, next := readHeredocBodies(runes, i, pending)
+					current.WriteString(string(runes[i:next]))
+					pending = nil
+					i = next
+					flush(";")
+					continue
+				}
+				if strings.HasSuffix(strings.TrimSpace(current.String()), "|") {
+					current.WriteRune(r)
+					continue
+				}
+				flush(";")
+				continue
+			}
+
 			// Check for && or ||
 			if i+1 < len(runes) {
 				if (r == '&' && runes[i+1] == '&') || (r == '|' && runes[i+1] == '|') {
-					seg := strings.TrimSpace(current.String())
-					if seg != "" {
-						segments = append(segments, seg)
-					}
-					current.Reset()
+					flush(string(runes[i : i+2]))
 					i++ // Skip the second character of && or ||
 					continue
 				}
 			}
 
-			// Check for ; or single &
-			if r == ';' || r == '&' {
-				seg := strings.TrimSpace(current.String())
-				if seg != "" {
-					segments = append(segments, seg)
-				}
-				current.Reset()
+			// Check for ; or single & (but not the & of a redirection)
+			if r == ';' || (r == '&' && !isRedirectAmpersand(runes, i)) {
+				flush(string(r))
 				continue
 			}
 		}
@@ -132,12 +195,18 @@ func splitCompoundShellAware(cmd string) []string {
 	}
 
 	// Add the last segment
-	seg := strings.TrimSpace(current.String())
-	if seg != "" {
-		segments = append(segments, seg)
-	}
+	flush("")
 
-	return segments
+	return segments, ops
+}
+
+// isRedirectAmpersand reports whether the & at runes[i] is part of a
+// redirection such as 2>&1, <&3 or &>file rather than a background operator.
+func isRedirectAmpersand(runes []rune, i int) bool {
+	if i > 0 && (runes[i-1] == '>' || runes[i-1] == '<') {
+		return true
+	}
+	return i+1 < len(runes) && runes[i+1] == '>'
 }
 
 // NormalizeCommand parses and normalizes a command for pattern matching.
@@ -154,8 +223,21 @@ func NormalizeCommand(cmd string) *NormalizedCommand {
 		return result
 	}
 
-	// Check for subshells
+	// Check for subshells and normalize the commands inside them. Group
+	// parentheses are blanked so the grouped commands split like any others;
+	// nesting that cannot be read reliably is a parse error.
 	result.HasSubshell = subshellPattern.MatchString(cmd)
+	scan := scanSubshells(cmd)
+	for _, sub := range scan.subshells {
+		inner := NormalizeCommand(sub.body)
+		if len(inner.Segments) > 0 {
+			result.SubshellCommands = append(result.SubshellCommands, strings.Join(inner.Segments, "; "))
+		}
+		result.SubshellCommands = append(result.SubshellCommands, inner.SubshellCommands...)
+		result.ParseError = result.ParseError || inner.ParseError
+	}
+	result.ParseError = result.ParseError || scan.ambiguous
+	cmd = strings.TrimSpace(scan.flat)
 
 	// Split on compound separators using shell-aware parsing.
 	// We use proper tokenization to determine if separators are inside quotes.
@@ -164,6 +246,19 @@ func NormalizeCommand(cmd string) *NormalizedCommand {
 		result.IsCompound = true
 	}
 
+	// Set here-doc bodies aside; only the command lines are classified
+	stripped := make([]string, 0, len(segments))
+	for i, seg := range segments {
+		command, docs := splitHeredocs(seg)
+		segments[i] = command
+		result.Heredocs = append(result.Heredocs, docs...)
+		stripped = append(stripped, command)
+	}
+	result.Stripped = strings.Join(stripped, "; ")
+	if len(result.Heredocs) == 0 {
+		result.Stripped = cmd
+	}
+
 	// Also check for pipes (not technically compound, but multiple commands)
 	for _, seg := range segments {
 		if pipePattern.MatchString(seg) {
@@ -174,12 +269,14 @@ func NormalizeCommand(cmd string) *NormalizedCommand {
 				part = strings.TrimSpace(part)
 				if part != "" {
 					result.Segments = append(result.Segments, part)
+					result.TeeWrites = append(result.TeeWrites, parseTeeWrites(part)...)
 				}
 			}
 		} else {
 			seg = strings.TrimSpace(seg)
 			if seg != "" {
 				result.Segments = append(result.Segments, seg)
+				result.TeeWrites = append(result.TeeWrites, parseTeeWrites(seg)...)
 			}
 		}
 	}
@@ -194,6 +291,9 @@ func NormalizeCommand(cmd string) *NormalizedCommand {
 		if normalized != "" {
 			normalizedSegments = append(normalizedSegments, normalized)
 		}
+		if chain := privilegeEscalation(wrappers); chain != "" && result.PrivilegeEscalation == "" {
+			result.PrivilegeEscalation = chain
+		}
 		result.StrippedWrappers = append(result.StrippedWrappers, wrappers...)
 	}
 	result.Segments = normalizedSegments
@@ -206,7 +306,50 @@ func NormalizeCommand(cmd string) *NormalizedCommand {
 	return result
 }
 
-// normalizeSegment strips wrappers using a shell-aware tokenizer.
+// Words kept in a command summary: plain flags and a lowercase subcommand.
+var (
+	summaryFlagPattern = regexp.MustCompile(`^--?[A-Za-z][A-Za-z-]*$`)
+	summaryWordPattern = regexp.MustCompile(`^[a-z][a-z-]*$`)
+)
+
+// SummarizeCommand returns a short description of cmd built from its
+// normalized segments: each segment's command name, leading flags, and first
+// subcommand, without operands (e.g. "cd; rm -rf", "kubectl delete").
+// Callers should pass the redacted display form so flags never leak secrets.
+func SummarizeCommand(cmd string) string {
+	normalized := NormalizeCommand(cmd)
+	parts := make([]string, 0, len(normalized.Segments))
+	for _, seg := range normalized.Segments {
+		if summary := summarizeSegment(seg); summary != "" {
+			parts = append(parts, summary)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func summarizeSegment(seg string) string {
+	words := strings.Fields(seg)
+	if len(words) == 0 {
+		return ""
+	}
+	out := []string{filepath.Base(strings.Trim(words[0], `"'`))}
+	for i, w := range words[1:] {
+		switch {
+		case summaryFlagPattern.MatchString(w):
+			out = append(out, w)
+		case i == 0 && summaryWordPattern.MatchString(w):
+			// Only a word directly after the command name is a subcommand.
+			out = append(out, w)
+		default:
+			return strings.Join(out, " ")
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// normalizeSegment strips wrappers using a shell-aware tokenizer. Commands
+// run through sudo, su -c, runuser or a shell -c are unwrapped recursively,
+// so the innermost command becomes the normalized segment.
 func normalizeSegment(seg string) (string, []string, bool) {
 	// First check for shell -c 'command' pattern and extract inner command
 	if match := shellCPattern.FindStringSubmatch(seg); match != nil {
@@ -241,11 +384,57 @@ func normalizeSegment(seg string) (string, []string, bool) {
 			continue
 		}
 
+		if tok == "sudo" || tok == "doas" {
+			stripped = append(stripped, tok)
+			i = skipSudoOptions(tokens, i+1)
+			continue
+		}
+
+		// timeout, xargs, flock and watch take options, and timeout a
+		// duration and flock a lock file, before the command they run.
+		if find, ok := optionWrappers[tok]; ok {
+			inner, next, ok := find(tokens[i+1:])
+			if !ok {
+				break
+			}
+			stripped = append(stripped, tok)
+			if next < 0 {
+				normalized, wrappers, innerErr := normalizeSegment(inner)
+				return normalized, append(stripped, wrappers...), parseErr || innerErr
+			}
+			i += 1 + next
+			continue
+		}
+
 		if isWrapper(tok) {
 			stripped = append(stripped, tok)
 			i++
 			continue
 		}
+
+		// su -c 'cmd' and runuser -c 'cmd' run cmd through the target
+		// user's shell; runuser -u user cmd runs it directly.
+		if tok == "su" || tok == "runuser" {
+			inner, next, ok := switchUserCommand(tokens[i+1:])
+			if !ok {
+				break
+			}
+			if next < 0 {
+				normalized, wrappers, innerErr := normalizeSegment(inner)
+				stripped = append(stripped, tok+" -c")
+				return normalized, append(stripped, wrappers...), parseErr || innerErr
+			}
+			stripped = append(stripped, tok)
+			i += 1 + next
+			continue
+		}
+
+		// A shell -c left after stripping wrappers, as in sudo bash -c '...'
+		if isShellExecutor(tok) && i+2 < len(tokens) && tokens[i+1] == "-c" {
+			normalized, wrappers, innerErr := normalizeSegment(tokens[i+2])
+			stripped = append(stripped, tok+" -c")
+			return normalized, append(stripped, wrappers...), parseErr || innerErr
+		}
 		break
 	}
 
@@ -253,7 +442,9 @@ func normalizeSegment(seg string) (string, []string, bool) {
 		return "", stripped, parseErr
 	}
 
-	normalized := strings.TrimSpace(strings.Join(tokens[i:], " "))
+	// Canonicalize flag order for known tools so equivalent spellings
+	// (rm -fr, rm -r -f, rm --recursive --force) match the same rules.
+	normalized := strings.TrimSpace(strings.Join(db.CanonicalizeArgv(tokens[i:]), " "))
 	return normalized, stripped, parseErr
 }
 
@@ -266,6 +457,160 @@ func ExtractXargsCommand(seg string) string {
 	return ""
 }
 
+// skipSudoOptions returns the index of the first token after the options
+// of a sudo or doas invocation starting at tokens[i].
+func skipSudoOptions(tokens []string, i int) int {
+	return skipOptions(tokens, i, sudoArgFlags)
+}
+
+// skipOptions returns the index of the first token after the options
+// starting at tokens[i], where argFlags are the options that take a
+// separate argument.
+func skipOptions(tokens []string, i int, argFlags map[string]bool) int {
+	for i < len(tokens) && strings.HasPrefix(tokens[i], "-") && tokens[i] != "-" {
+		if tokens[i] == "--" {
+			return i + 1
+		}
+		if argFlags[tokens[i]] {
+			i++
+		}
+		i++
+	}
+	return i
+}
+
+// optionWrappers find the command run by wrappers that take options or
+// operands of their own, given the arguments after the wrapper. They follow
+// switchUserCommand: next is the index of the command's first word, or
+// negative when command is a string run through a shell, and ok is false
+// when no command is given.
+var optionWrappers = map[string]func(args []string) (command string, next int, ok bool){
+	"timeout": timeoutCommand,
+	"xargs":   xargsCommand,
+	"flock":   flockCommand,
+	"watch":   watchCommand,
+}
+
+// Options of timeout, xargs, flock and watch that take a separate argument.
+var (
+	timeoutArgFlags = map[string]bool{"-s": true, "--signal": true, "-k": true, "--kill-after": true}
+	xargsArgFlags   = map[string]bool{
+		"-a": true, "--arg-file": true, "-d": true, "--delimiter": true, "-E": true, "--eof": true,
+		"-I": true, "--replace": true, "-L": true, "--max-lines": true, "-n": true, "--max-args": true,
+		"-P": true, "--max-procs": true, "-s": true, "--max-chars": true, "--process-slot-var": true,
+	}
+	flockArgFlags = map[string]bool{"-w": true, "--wait": true, "--timeout": true, "-E": true, "--conflict-exit-code": true}
+	watchArgFlags = map[string]bool{"-n": true, "--interval": true, "-q": true, "--equexit": true}
+)
+
+// timeoutCommand skips timeout's options and duration: timeout 30 rm -rf x.
+func timeoutCommand(args []string) (string, int, bool) {
+	next := skipOptions(args, 0, timeoutArgFlags) + 1
+	return "", next, next < len(args)
+}
+
+// xargsCommand skips xargs's options: xargs -0 -n 1 rm -rf. Without a
+// command xargs runs echo, which is left as is.
+func xargsCommand(args []string) (string, int, bool) {
+	next := skipOptions(args, 0, xargsArgFlags)
+	return "", next, next < len(args)
+}
+
+// flockCommand skips flock's options and lock file. The command follows the
+// file, or is given to -c as a shell command string; flock with only a file
+// descriptor runs nothing.
+func flockCommand(args []string) (string, int, bool) {
+	next := skipOptions(args, 0, flockArgFlags) + 1
+	if next+1 < len(args) && (args[next] == "-c" || args[next] == "--command") {
+		return args[next+1], -1, true
+	}
+	return "", next, next < len(args)
+}
+
+// watchCommand skips watch's options. watch joins the remaining words and
+// runs them through sh -c, unless -x (--exec) runs them directly.
+func watchCommand(args []string) (string, int, bool) {
+	next := skipOptions(args, 0, watchArgFlags)
+	if next >= len(args) {
+		return "", 0, false
+	}
+	for _, a := range args[:next] {
+		if a == "-x" || a == "--exec" {
+			return "", next, true
+		}
+	}
+	return strings.Join(args[next:], " "), -1, true
+}
+
+// switchUserArgFlags are su and runuser options that take a separate
+// argument.
+var switchUserArgFlags = map[string]bool{
+	"-s": true, "--shell": true, "-g": true, "--group": true,
+	"-G": true, "--supp-group": true, "-w": true, "--whitelist-environment": true,
+}
+
+// switchUserCommand finds the command an su or runuser invocation runs,
+// given the arguments after su or runuser. For -c (--command) it returns the
+// command string and next < 0; for runuser -u user, the command follows the
+// options and next is the index of its first word. ok is false when no
+// command is given, as for an interactive login.
+func switchUserCommand(args []string) (command string, next int, ok bool) {
+	runAs := false
+	for j := 0; j < len(args); j++ {
+		a := args[j]
+		switch {
+		case a == "-c" || a == "--command":
+			if j+1 < len(args) {
+				return args[j+1], -1, true
+			}
+			return "", 0, false
+		case strings.HasPrefix(a, "--command="):
+			return strings.TrimPrefix(a, "--command="), -1, true
+		case a == "-u" || a == "--user":
+			runAs = true
+			j++
+		case a == "--":
+			if runAs && j+1 < len(args) {
+				return "", j + 1, true
+			}
+			return "", 0, false
+		case switchUserArgFlags[a]:
+			j++
+		case strings.HasPrefix(a, "-"):
+		case runAs:
+			return "", j, true
+		}
+		// Anything else is the user to switch to.
+	}
+	return "", 0, false
+}
+
+// privilegeEscalation returns the privilege wrappers of a segment that runs
+// its command as another user through a nested shell: su -c or runuser -c,
+// or a shell -c under sudo, doas or runuser. It returns "" otherwise; plain
+// sudo cmd is left to the command's own patterns.
+func privilegeEscalation(wrappers []string) string {
+	var chain []string
+	privileged, nested := false, false
+	for _, w := range wrappers {
+		switch {
+		case w == "sudo" || w == "doas" || w == "runuser":
+			privileged = true
+			chain = append(chain, w)
+		case w == "su -c" || w == "runuser -c":
+			privileged, nested = true, true
+			chain = append(chain, w)
+		case privileged && strings.HasSuffix(w, " -c"):
+			nested = true
+			chain = append(chain, w)
+		}
+	}
+	if !nested {
+		return ""
+	}
+	return strings.Join(chain, " ")
+}
+
 func isWrapper(tok string) bool {
 	for _, w := range wrapperPrefixes {
 		if tok == w {
@@ -3,6 +3,7 @@ package core
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -71,6 +72,42 @@ func TestNormalizeCommandEdgeCases(t *testing.T) {
 	})
 }
 
+func TestNormalizeCommandSubshells(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      string
+		segments []string
+		subs     []string
+		parseErr bool
+	}{
+		{"substitution", "echo $(rm -rf /)", []string{"echo $(rm -rf /)"}, []string{"rm -rf /"}, false},
+		{"backticks", "echo `sudo rm -rf /`", []string{"echo `sudo rm -rf /`"}, []string{"rm -rf /"}, false},
+		{"group", "(cd /x && rm -rf y)", []string{"cd /x", "rm -rf y"}, []string{"cd /x; rm -rf y"}, false},
+		{"group after separator", "make && (cd /x; rm -rf y)", []string{"make", "cd /x", "rm -rf y"}, []string{"cd /x; rm -rf y"}, false},
+		{"nested", "(echo $(dirname $(which go)))", []string{"echo $(dirname $(which go))"}, []string{"echo $(dirname $(which go))", "dirname $(which go)", "which go"}, true},
+		{"quoted text is literal", `echo '(rm -rf /)' "a)"`, []string{"echo (rm -rf /) a)"}, nil, false},
+		{"unclosed substitution", "echo $(rm -rf /", []string{"echo $(rm -rf /"}, []string{"rm -rf /"}, true},
+		{"unclosed backtick", "echo `rm -rf /", []string{"echo `rm -rf /"}, []string{"rm -rf /"}, true},
+		{"unmatched close", "echo a) && rm -rf y", []string{"echo a)", "rm -rf y"}, nil, true},
+		{"unclosed group", "(cd /x && rm -rf y", []string{"cd /x", "rm -rf y"}, []string{"cd /x; rm -rf y"}, true},
+		{"double paren", "((cd /x) && rm -rf y)", []string{"cd /x", "rm -rf y"}, []string{"cd /x; rm -rf y", "cd /x"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := NormalizeCommand(tt.cmd)
+			if !reflect.DeepEqual(res.Segments, tt.segments) {
+				t.Errorf("Segments = %q, want %q", res.Segments, tt.segments)
+			}
+			if !reflect.DeepEqual(res.SubshellCommands, tt.subs) {
+				t.Errorf("SubshellCommands = %q, want %q", res.SubshellCommands, tt.subs)
+			}
+			if res.ParseError != tt.parseErr {
+				t.Errorf("ParseError = %v, want %v", res.ParseError, tt.parseErr)
+			}
+		})
+	}
+}
+
 func TestNormalizeCommandEnvAssignments(t *testing.T) {
 	res := NormalizeCommand("env FOO=bar BAR=baz kubectl delete pod nginx-123")
 	if res.Primary != "kubectl delete pod nginx-123" {
@@ -264,6 +301,54 @@ func TestNormalizeSegmentWrapperStripping(t *testing.T) {
 			wantNormalized: "./long-running-script.sh",
 			wantWrappers:   []string{"nohup"},
 		},
+		{
+			name:           "wrapper then canonical flags",
+			input:          "sudo rm -f -r /tmp",
+			wantNormalized: "rm -rf /tmp",
+			wantWrappers:   []string{"sudo"},
+		},
+		{
+			name:           "sudo options",
+			input:          "sudo -u root -E rm -rf /tmp",
+			wantNormalized: "rm -rf /tmp",
+			wantWrappers:   []string{"sudo"},
+		},
+		{
+			name:           "sudo su -c",
+			input:          `sudo su -c "rm -rf x"`,
+			wantNormalized: "rm -rf x",
+			wantWrappers:   []string{"sudo", "su -c"},
+		},
+		{
+			name:           "su -c with login and user",
+			input:          `su - root -c "rm -fr x"`,
+			wantNormalized: "rm -rf x",
+			wantWrappers:   []string{"su -c"},
+		},
+		{
+			name:           "sudo -u root bash -c",
+			input:          `sudo -u root bash -c "sudo nice rm -rf x"`,
+			wantNormalized: "rm -rf x",
+			wantWrappers:   []string{"sudo", "bash -c", "sudo", "nice"},
+		},
+		{
+			name:           "runuser -c",
+			input:          `runuser -l postgres -c "dropdb app"`,
+			wantNormalized: "dropdb app",
+			wantWrappers:   []string{"runuser -c"},
+		},
+		{
+			name:           "runuser -u",
+			input:          "runuser -u postgres -- dropdb app",
+			wantNormalized: "dropdb app",
+			wantWrappers:   []string{"runuser"},
+		},
+		{
+			name:           "interactive su",
+			input:          "sudo su -",
+			wantNormalized: "su -",
+			wantWrappers:   []string{"sudo"},
+		},
 		{
 			name:           "no wrapper",
 			input:          "ls -la",
@@ -290,8 +375,84 @@ func TestNormalizeSegmentWrapperStripping(t *testing.T) {
 	}
 }
 
+func TestNormalizeCommandOptionWrappers(t *testing.T) {
+	tests := []struct {
+		cmd          string
+		wantPrimary  string
+		wantWrappers []string
+	}{
+		{"timeout 30 rm -rf /", "rm -rf /", []string{"timeout"}},
+		{"timeout -s KILL -k 5 30s rm -fr x", "rm -rf x", []string{"timeout"}},
+		{"timeout --signal=TERM --preserve-status 1m kubectl delete ns prod", "kubectl delete ns prod", []string{"timeout"}},
+		{"xargs rm", "rm", []string{"xargs"}},
+		{"xargs -0 -n 1 -P 4 rm -rf", "rm -rf", []string{"xargs"}},
+		{"xargs -I {} --max-procs 2 docker rm -f {}", "docker rm -f {}", []string{"xargs"}},
+		{"flock /tmp/lock docker rm app", "docker rm app", []string{"flock"}},
+		{"flock -w 10 -x /tmp/lock -c 'sudo rm -rf /srv'", "rm -rf /srv", []string{"flock", "sudo"}},
+		{"watch kubectl delete pod web", "kubectl delete pod web", []string{"watch"}},
+		{"watch -n 5 'kubectl delete pod web && echo done'", "kubectl delete pod web", []string{"watch"}},
+		{"watch -x -n 2 kubectl delete pod web", "kubectl delete pod web", []string{"watch"}},
+		{"sudo timeout 30 xargs -0 rm -rf", "rm -rf", []string{"sudo", "timeout", "xargs"}},
+		// Without a command there is nothing to unwrap.
+		{"timeout 30", "timeout 30", nil},
+		{"xargs", "xargs", nil},
+		{"flock 9", "flock 9", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.cmd, func(t *testing.T) {
+			got := NormalizeCommand(tc.cmd)
+			if got.Primary != tc.wantPrimary {
+				t.Errorf("Primary = %q, want %q", got.Primary, tc.wantPrimary)
+			}
+			if !reflect.DeepEqual(got.StrippedWrappers, tc.wantWrappers) {
+				t.Errorf("StrippedWrappers = %q, want %q", got.StrippedWrappers, tc.wantWrappers)
+			}
+		})
+	}
+}
+
+func TestNormalizeCommandPrivilegeEscalation(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{`sudo su -c "rm -rf x"`, "sudo su -c"},
+		{`su -c "rm -rf x"`, "su -c"},
+		{`sudo -u root bash -c "rm -rf x"`, "sudo bash -c"},
+		{`doas sh -c "rm -rf x"`, "doas sh -c"},
+		{`runuser -u postgres -- bash -c "dropdb app"`, "runuser bash -c"},
+		{`cd /srv && sudo su -c "rm -rf x"`, "sudo su -c"},
+		{"sudo rm -rf x", ""},
+		{`bash -c "sudo rm -rf x"`, ""},
+		{"runuser -u postgres -- dropdb app", ""},
+	}
+	for _, tc := range tests {
+		if got := NormalizeCommand(tc.cmd).PrivilegeEscalation; got != tc.want {
+			t.Errorf("NormalizeCommand(%q).PrivilegeEscalation = %q, want %q", tc.cmd, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeCommandCanonicalFlags(t *testing.T) {
+	want := NormalizeCommand("rm -rf x").Primary
+	if want != "rm -rf x" {
+		t.Fatalf("Primary = %q, want %q", want, "rm -rf x")
+	}
+	for _, cmd := range []string{"rm -fr x", "rm -r -f x", "rm --recursive --force x"} {
+		if got := NormalizeCommand(cmd).Primary; got != want {
+			t.Errorf("NormalizeCommand(%q).Primary = %q, want %q", cmd, got, want)
+		}
+	}
+
+	// Each segment of a compound command is canonicalized independently.
+	got := NormalizeCommand("rm -fr a && mkdir -vp b").Segments
+	if len(got) != 2 || got[0] != "rm -rf a" || got[1] != "mkdir -pv b" {
+		t.Errorf("Segments = %q", got)
+	}
+}
+
 func TestIsWrapper(t *testing.T) {
-	wrappers := []string{"sudo", "doas", "env", "command", "builtin", "time", "nice", "ionice", "nohup", "strace", "ltrace"}
+	wrappers := []string{"sudo", "doas", "env", "command", "builtin", "time", "nice", "ionice", "nohup", "strace", "ltrace", "timeout", "xargs", "flock", "watch"}
 	for _, w := range wrappers {
 		if !isWrapper(w) {
 			t.Errorf("isWrapper(%q) = false, want true", w)
@@ -451,3 +612,24 @@ func TestCompoundCommandWithQuotes(t *testing.T) {
 		}
 	})
 }
+
+func TestSummarizeCommand(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{"rm -rf ./build", "rm -rf"},
+		{"sudo rm -rf /var/cache", "rm -rf"},
+		{"/usr/bin/git push --force origin main", "git push --force"},
+		{"kubectl delete ns staging", "kubectl delete"},
+		{"cd /srv && rm -rf cache | tee /tmp/log", "cd; rm -rf; tee"},
+		{"mysql --password=hunter2 -e 'DROP DATABASE x'", "mysql"},
+		{"DROP TABLE users", "DROP"},
+		{"", ""},
+	}
+	for _, tc := range tests {
+		if got := SummarizeCommand(tc.cmd); got != tc.want {
+			t.Errorf("SummarizeCommand(%q) = %q, want %q", tc.cmd, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Package core implements configured risk tier overrides.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RiskOverrideRule is a configured tier override (risk_overrides.rules):
+// commands whose normalized primary command matches Glob or Regex (exactly
+// one is set) are classified at Tier instead of the built-in tier.
+type RiskOverrideRule struct {
+	// Glob must match the whole primary command; * matches any run of
+	// characters (spaces and slashes included) and ? any single one.
+	Glob string
+	// Regex matches anywhere in the primary command.
+	Regex string
+	// Tier is the tier matching commands are classified at.
+	Tier RiskTier
+	// AllowDowngrade permits a SAFE rule to cover commands the built-in
+	// patterns classify as CRITICAL.
+	AllowDowngrade bool
+}
+
+// String describes the rule for tier reasons and errors, e.g.
+// `glob "terraform apply *staging*"`.
+func (r RiskOverrideRule) String() string {
+	if r.Glob != "" {
+		return fmt.Sprintf("glob %q", r.Glob)
+	}
+	return fmt.Sprintf("regex %q", r.Regex)
+}
+
+// pattern is the rule's pattern text, whose length decides precedence.
+func (r RiskOverrideRule) pattern() string {
+	if r.Glob != "" {
+		return r.Glob
+	}
+	return r.Regex
+}
+
+// ErrInvalidRiskOverride is returned for risk override rules that cannot be
+// compiled or would silently downgrade CRITICAL commands.
+var ErrInvalidRiskOverride = errors.New("invalid risk override")
+
+// criticalOverrideProbes are commands the built-in patterns classify as
+// CRITICAL. A SAFE rule matching any of them is rejected unless it sets
+// AllowDowngrade, catching catch-all rules like glob "*" at load time.
+var criticalOverrideProbes = []string{
+	"rm -rf /",
+	"rm -rf /etc",
+	"rm -rf ~",
+	"terraform destroy",
+	"terraform destroy -auto-approve",
+	"kubectl delete namespace production",
+	"git push --force origin main",
+	"git push -f origin main",
+	"psql -c 'DROP DATABASE app'",
+	"aws ec2 terminate-instances --instance-ids i-0123456789abcdef0",
+	"dd if=/dev/zero of=/dev/sda",
+	"mkfs.ext4 /dev/sda1",
+	"chmod -R 777 /etc",
+}
+
+// RiskOverrides is a compiled set of risk override rules.
+type RiskOverrides struct {
+	rules []compiledRiskOverride
+}
+
+type compiledRiskOverride struct {
+	rule RiskOverrideRule
+	re   *regexp.Regexp
+}
+
+// CompileRiskOverrides validates and compiles rules. It returns nil when
+// there are no rules.
+func CompileRiskOverrides(rules []RiskOverrideRule) (*RiskOverrides, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	compiled := make([]compiledRiskOverride, 0, len(rules))
+	for i, rule := range rules {
+		if (rule.Glob == "") == (rule.Regex == "") {
+			return nil, fmt.Errorf("%w: rule %d must set exactly one of glob or regex", ErrInvalidRiskOverride, i+1)
+		}
+		switch rule.Tier {
+		case RiskTierCritical, RiskTierDangerous, RiskTierCaution, RiskTier(RiskSafe):
+		default:
+			return nil, fmt.Errorf("%w: rule %d (%s) has unknown tier %q", ErrInvalidRiskOverride, i+1, rule, rule.Tier)
+		}
+		expr := rule.Regex
+		if rule.Glob != "" {
+			expr = globToRegexp(rule.Glob)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: rule %d (%s): %v", ErrInvalidRiskOverride, i+1, rule, err)
+		}
+		if rule.Tier == RiskTier(RiskSafe) && !rule.AllowDowngrade {
+			for _, probe := range criticalOverrideProbes {
+				if re.MatchString(NormalizeCommand(probe).Primary) {
+					return nil, fmt.Errorf("%w: rule %d (%s) would downgrade the critical command %q to safe; set allow_downgrade = true if that is intended",
+						ErrInvalidRiskOverride, i+1, rule, probe)
+				}
+			}
+		}
+		compiled = append(compiled, compiledRiskOverride{rule: rule, re: re})
+	}
+	return &RiskOverrides{rules: compiled}, nil
+}
+
+// globToRegexp translates a command glob into an anchored regular
+// expression. Bracket expressions are passed through; a [ without a closing
+// ] is literal.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// Match returns the rule that applies to primary: the matching rule with
+// the longest pattern, the stricter tier on a tie, then the earliest rule.
+func (o *RiskOverrides) Match(primary string) (RiskOverrideRule, bool) {
+	var best *RiskOverrideRule
+	if o != nil {
+		for i := range o.rules {
+			r := &o.rules[i].rule
+			if !o.rules[i].re.MatchString(primary) {
+				continue
+			}
+			if best == nil || len(r.pattern()) > len(best.pattern()) ||
+				(len(r.pattern()) == len(best.pattern()) && tierRank(r.Tier) > tierRank(best.Tier)) {
+				best = r
+			}
+		}
+	}
+	if best == nil {
+		return RiskOverrideRule{}, false
+	}
+	return *best, true
+}
+
+// Apply returns res with its tier replaced by the rule matching cmd's
+// normalized primary command, and a note for the request's tier reason.
+// Overrides never apply to self-protection, and a rule cannot lower the tier
+// of a compound command, whose primary command does not speak for the other
+// segments. res is returned unchanged with an empty note when no rule
+// matches.
+func (o *RiskOverrides) Apply(res *MatchResult, cmd string) (*MatchResult, string) {
+	if o == nil || res == nil || res.MatchedPattern == SelfProtectionPattern {
+		return res, ""
+	}
+	normalized := NormalizeCommand(cmd)
+	rule, ok := o.Match(normalized.Primary)
+	if !ok {
+		return res, ""
+	}
+
+	current := res.Tier
+	if !res.NeedsApproval {
+		current = RiskTier(RiskSafe)
+	}
+	if rule.Tier == current {
+		return res, fmt.Sprintf("risk_overrides rule %s matched; tier unchanged", rule)
+	}
+	lowers := tierRank(rule.Tier) < tierRank(current)
+	if lowers && normalized.IsCompound && len(normalized.Segments) > 1 {
+		return res, fmt.Sprintf("risk_overrides rule %s not applied: overrides cannot lower the tier of a compound command", rule)
+	}
+	if current == RiskTierCritical && rule.Tier == RiskTier(RiskSafe) && !rule.AllowDowngrade {
+		return res, fmt.Sprintf("risk_overrides rule %s not applied: downgrading critical to safe requires allow_downgrade", rule)
+	}
+
+	overridden := *res
+	overridden.Tier = rule.Tier
+	overridden.MatchedPattern = rule.pattern()
+	overridden.MinApprovals = tierApprovals(rule.Tier)
+	overridden.NeedsApproval = rule.Tier != RiskTier(RiskSafe)
+	overridden.IsSafe = rule.Tier == RiskTier(RiskSafe)
+	return &overridden, fmt.Sprintf("overridden from %s to %s by risk_overrides rule %s", current, rule.Tier, rule)
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestCriticalOverrideProbesAreCritical(t *testing.T) {
+	engine := NewPatternEngine()
+	for _, probe := range criticalOverrideProbes {
+		if got := engine.ClassifyCommand(probe, "").Tier; got != RiskTierCritical {
+			t.Errorf("probe %q classified %s, want critical", probe, got)
+		}
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		cmd   string
+		match bool
+	}{
+		{"terraform apply *staging*", "terraform apply -var-file=envs/staging.tfvars", true},
+		{"terraform apply *staging*", "terraform plan -var-file=staging.tfvars", false},
+		{"make clean", "make clean", true},
+		{"make clean", "make clean all", false},
+		{"kubectl ? pods", "kubectl x pods", true},
+		{"git push origin [!m]*", "git push origin main", false},
+		{"git push origin [!m]*", "git push origin feature", true},
+		{"echo [unclosed", "echo [unclosed", true},
+		{"echo a.b", "echo aXb", false},
+	}
+	for _, tt := range tests {
+		overrides, err := CompileRiskOverrides([]RiskOverrideRule{{Glob: tt.glob, Tier: RiskTierCaution}})
+		if err != nil {
+			t.Fatalf("compile %q: %v", tt.glob, err)
+		}
+		if _, got := overrides.Match(tt.cmd); got != tt.match {
+			t.Errorf("glob %q on %q: match=%v, want %v", tt.glob, tt.cmd, got, tt.match)
+		}
+	}
+}
+
+func TestCompileRiskOverrides_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule RiskOverrideRule
+		want string
+	}{
+		{"no pattern", RiskOverrideRule{Tier: RiskTierCaution}, "exactly one of glob or regex"},
+		{"both patterns", RiskOverrideRule{Glob: "x", Regex: "x", Tier: RiskTierCaution}, "exactly one of glob or regex"},
+		{"unknown tier", RiskOverrideRule{Glob: "x", Tier: "urgent"}, `unknown tier "urgent"`},
+		{"bad regex", RiskOverrideRule{Regex: "(", Tier: RiskTierCaution}, "regex"},
+		{"catch-all safe glob", RiskOverrideRule{Glob: "*", Tier: RiskTier(RiskSafe)}, "allow_downgrade"},
+		{"safe regex covering terraform destroy", RiskOverrideRule{Regex: "^terraform ", Tier: RiskTier(RiskSafe)}, "terraform destroy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileRiskOverrides([]RiskOverrideRule{tt.rule})
+			if !errors.Is(err, ErrInvalidRiskOverride) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected ErrInvalidRiskOverride mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	allowed := RiskOverrideRule{Regex: "^terraform ", Tier: RiskTier(RiskSafe), AllowDowngrade: true}
+	if _, err := CompileRiskOverrides([]RiskOverrideRule{allowed}); err != nil {
+		t.Fatalf("expected allow_downgrade to permit the rule, got %v", err)
+	}
+	if o, err := CompileRiskOverrides(nil); o != nil || err != nil {
+		t.Fatalf("expected nil overrides for no rules, got %v, %v", o, err)
+	}
+}
+
+func TestRiskOverrides_MatchPrecedence(t *testing.T) {
+	overrides, err := CompileRiskOverrides([]RiskOverrideRule{
+		{Glob: "terraform *", Tier: RiskTierDangerous},
+		{Glob: "terraform apply *staging*", Tier: RiskTierCaution},
+		{Regex: `prod\.tfvars`, Tier: RiskTierCritical},
+		{Regex: `prod.tfvars`, Tier: RiskTierCaution},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{"terraform apply -var-file=staging.tfvars", "terraform apply *staging*"},
+		{"terraform plan", "terraform *"},
+		// Equal-length patterns: the stricter tier wins.
+		{"tflint prod.tfvars", `prod\.tfvars`},
+		// The longest pattern wins even over a stricter tier.
+		{"terraform apply -var-file=staging-prod.tfvars", "terraform apply *staging*"},
+	}
+	for _, tt := range tests {
+		rule, ok := overrides.Match(tt.cmd)
+		if !ok || rule.pattern() != tt.want {
+			t.Errorf("Match(%q) = %+v (%v), want pattern %q", tt.cmd, rule, ok, tt.want)
+		}
+	}
+	if _, ok := overrides.Match("ls"); ok {
+		t.Error("expected no rule to match ls")
+	}
+}
+
+func TestRiskOverrides_Apply(t *testing.T) {
+	engine := NewPatternEngine()
+	overrides, err := CompileRiskOverrides([]RiskOverrideRule{
+		{Glob: "terraform apply *staging*", Tier: RiskTierCaution},
+		{Regex: `prod\.tfvars`, Tier: RiskTierCritical},
+		{Glob: "git push --force origin scratch", Tier: RiskTier(RiskSafe)},
+		{Glob: "rm -rf ./build", Tier: RiskTier(RiskSafe)},
+		{Glob: "mkfs.ext4 /dev/loop0", Tier: RiskTier(RiskSafe), AllowDowngrade: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		cmd      string
+		wantTier RiskTier
+		wantNote string
+	}{
+		{"raise unmatched command", "terraform plan -var-file=prod.tfvars", RiskTierCritical, "overridden from safe to critical"},
+		{"set tier of wrapped command", "sudo terraform apply -var-file=staging.tfvars", RiskTierCaution, "overridden from safe to caution"},
+		{"downgrade dangerous to safe", "rm -rf ./build", RiskTier(RiskSafe), "overridden from dangerous to safe"},
+		{"critical to safe needs allow_downgrade", "git push --force origin scratch", RiskTierCritical, "requires allow_downgrade"},
+		{"critical to safe with allow_downgrade", "mkfs.ext4 /dev/loop0", RiskTier(RiskSafe), "overridden from critical to safe"},
+		{"no lowering for compound commands", "rm -rf ./build && rm -rf /etc", RiskTierCritical, "compound command"},
+		{"no match", "ls -la", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, note := overrides.Apply(engine.ClassifyCommand(tt.cmd, ""), tt.cmd)
+			if res.Tier != tt.wantTier {
+				t.Errorf("tier = %q, want %q", res.Tier, tt.wantTier)
+			}
+			if !strings.Contains(note, tt.wantNote) || (tt.wantNote == "") != (note == "") {
+				t.Errorf("note = %q, want it to contain %q", note, tt.wantNote)
+			}
+			if res.NeedsApproval != (tt.wantTier != "" && tt.wantTier != RiskTier(RiskSafe)) {
+				t.Errorf("NeedsApproval = %v for tier %q", res.NeedsApproval, res.Tier)
+			}
+			if res.MinApprovals != tierApprovals(res.Tier) {
+				t.Errorf("MinApprovals = %d, want %d", res.MinApprovals, tierApprovals(res.Tier))
+			}
+		})
+	}
+
+	self := &MatchResult{Tier: RiskTierCritical, MatchedPattern: SelfProtectionPattern, NeedsApproval: true, MinApprovals: 2}
+	all, _ := CompileRiskOverrides([]RiskOverrideRule{{Glob: "rm *", Tier: RiskTierCaution}})
+	if res, note := all.Apply(self, "rm -rf .slb"); res != self || note != "" {
+		t.Errorf("expected self-protection to be left alone, got %+v %q", res, note)
+	}
+}
+
+func TestCreateRequest_RecordsRiskOverride(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	cfg := DefaultRequestCreatorConfig()
+	cfg.RiskOverrides = []RiskOverrideRule{
+		{Glob: "terraform apply *staging*", Tier: RiskTierCaution},
+		{Regex: `prod\.tfvars`, Tier: RiskTierCritical},
+	}
+	creator := NewRequestCreator(database, nil, nil, cfg)
+
+	result, err := creator.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "terraform apply -var-file=prod.tfvars",
+		Cwd:           "/project",
+		Justification: Justification{Reason: "ship it"},
+	})
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+	if result.Skipped || result.Request == nil {
+		t.Fatalf("expected the overridden command to need review, got %+v", result)
+	}
+	if result.Request.RiskTier != RiskTierCritical || !result.Request.RequireDifferentModel {
+		t.Errorf("expected a critical request requiring a different model, got %s", result.Request.RiskTier)
+	}
+	stored, err := database.GetRequest(result.Request.ID)
+	if err != nil {
+		t.Fatalf("GetRequest: %v", err)
+	}
+	if want := `risk_overrides rule regex "prod\\.tfvars"`; !strings.Contains(stored.TierReason, want) {
+		t.Errorf("tier reason %q does not record the rule (%s)", stored.TierReason, want)
+	}
+
+	cfg.RiskOverrides = []RiskOverrideRule{{Glob: "*", Tier: RiskTier(RiskSafe)}}
+	if _, err := creator.CreateRequest(CreateRequestOptions{SessionID: session.ID, Command: "rm -rf ./build"}); !errors.Is(err, ErrInvalidRiskOverride) {
+		t.Errorf("expected ErrInvalidRiskOverride for a catch-all safe rule, got %v", err)
+	}
+}
@@ -43,6 +43,14 @@ type MatchResult struct {
 	ParseError bool
 	// Segments lists matched segments for compound commands.
 	MatchedSegments []SegmentMatch
+	// PrivilegeEscalation is the wrapper chain, such as "sudo su -c", of a
+	// command run as another user through a nested shell; the tier was
+	// raised one step for it.
+	PrivilegeEscalation string
+	// RuntimeTargets is set when a command needing approval takes its
+	// arguments from a command substitution, as in rm -rf "$(cat list)";
+	// the tier was raised one step for it (see HasRuntimeTargets).
+	RuntimeTargets bool
 }
 
 // SegmentMatch describes a match within a compound command.
@@ -171,8 +179,41 @@ func compilePatterns(tier RiskTier, patterns []string, source string) []*Pattern
 	return result
 }
 
-// ClassifyCommand determines the risk tier for a command.
+// ClassifyCommand determines the risk tier for a command. Writes through tee
+// to privileged or out-of-project paths raise the tier (see TeeWrite), as do
+// commands run inside, or taking arguments from, command substitutions.
+// Commands that target SLB's own state are always CRITICAL (see
+// TargetsSLBState).
 func (e *PatternEngine) ClassifyCommand(cmd, cwd string) *MatchResult {
+	res := e.applyHeredocInspection(e.classifyCommand(cmd, cwd), cmd, cwd)
+	res = applyTeeInspection(res, cmd, cwd)
+	res = e.applySubstitutionInspection(res, cmd, cwd)
+	res = applyPrivilegeEscalation(res, cmd)
+	return applySelfProtection(res, cmd, cwd)
+}
+
+// applyPrivilegeEscalation raises res one tier when cmd runs its command as
+// another user through a nested shell, as in sudo su -c '...', since the
+// escalation is easy to miss in review.
+func applyPrivilegeEscalation(res *MatchResult, cmd string) *MatchResult {
+	chain := NormalizeCommand(cmd).PrivilegeEscalation
+	if chain == "" {
+		return res
+	}
+	res.PrivilegeEscalation = chain
+	if res.Tier == "" {
+		res.Tier = RiskTierCaution
+	} else {
+		res.Tier = upgradeTier(res.Tier)
+	}
+	res.MinApprovals = tierApprovals(res.Tier)
+	res.NeedsApproval = true
+	res.IsSafe = false
+	return res
+}
+
+// classifyCommand determines the risk tier from the pattern tiers alone.
+func (e *PatternEngine) classifyCommand(cmd, cwd string) *MatchResult {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -243,8 +284,9 @@ func (e *PatternEngine) ClassifyCommand(cmd, cwd string) *MatchResult {
 		return e.applyParseUpgrade(result, normalized.ParseError)
 	}
 
-	// Fallback SQL detection on raw command (handles wrappers like psql -c "<SQL>")
-	lowerRaw := strings.ToLower(cmd)
+	// Fallback SQL detection on raw command (handles wrappers like psql -c "<SQL>").
+	// Here-doc bodies are left to inspectHeredocs.
+	lowerRaw := strings.ToLower(normalized.Stripped)
 	if strings.Contains(lowerRaw, "delete from") {
 		if !strings.Contains(lowerRaw, "where") {
 			result.Tier = RiskTierCritical
@@ -366,6 +408,42 @@ func (e *PatternEngine) matchPatterns(cmd string, patterns []*Pattern) *Pattern
 	return nil
 }
 
+// InspectSQL reports the destructive-SQL patterns a SQL script matches: the
+// critical and dangerous patterns not anchored to a shell command, plus the
+// DELETE-without-WHERE fallback. Statements are checked one at a time so a
+// WHERE in one statement does not excuse another.
+func (e *PatternEngine) InspectSQL(script string) []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var findings []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			findings = append(findings, name)
+		}
+	}
+	for _, stmt := range strings.Split(script, ";") {
+		stmt = strings.Join(strings.Fields(stmt), " ")
+		if stmt == "" {
+			continue
+		}
+		for _, tier := range [][]*Pattern{e.critical, e.dangerous} {
+			for _, p := range tier {
+				if !strings.HasPrefix(p.Pattern, "^") && p.Compiled.MatchString(stmt) {
+					add(p.Pattern)
+				}
+			}
+		}
+		lower := strings.ToLower(stmt)
+		if strings.Contains(lower, "delete from") && !strings.Contains(lower, "where") {
+			add("fallback_sql_delete_no_where")
+		}
+	}
+	return findings
+}
+
 // applyParseUpgrade enforces conservative behavior when normalization fails.
 // It upgrades the tier by one step (safe→caution→dangerous→critical) or sets
 // a default caution tier if no tier was determined.
@@ -401,6 +479,65 @@ func (e *PatternEngine) applyParseUpgrade(res *MatchResult, parseErr bool) *Matc
 	return res
 }
 
+// DescribeClassification explains a classification in one line so the
+// rationale can be stored with the request. Only pattern text is included,
+// never command operands, so the result is safe to display unredacted.
+func DescribeClassification(m *MatchResult) string {
+	if m == nil {
+		return ""
+	}
+	reason := describeMatch(m)
+	if m.PrivilegeEscalation != "" && m.MatchedPattern != SelfProtectionPattern {
+		reason += fmt.Sprintf("; upgraded to %s because the command escalates privilege through a nested shell (%s)", m.Tier, m.PrivilegeEscalation)
+	}
+	if m.RuntimeTargets && m.MatchedPattern != SelfProtectionPattern {
+		reason += fmt.Sprintf("; upgraded to %s: %s", m.Tier, runtimeTargetsRationale)
+	}
+	return reason
+}
+
+// describeMatch explains the pattern match behind m.
+func describeMatch(m *MatchResult) string {
+	if m.MatchedPattern == "parse_error" {
+		return fmt.Sprintf("classified %s because the command could not be parsed reliably", m.Tier)
+	}
+	if m.MatchedPattern == SelfProtectionPattern {
+		return fmt.Sprintf("classified %s by self-protection: the command targets slb's own state (.slb directory or state database); approving destruction of the audit trail is a red flag", m.Tier)
+	}
+	if m.MatchedPattern == substitutionInnerPattern {
+		return fmt.Sprintf("classified %s because a command substitution runs a %s command", m.Tier, m.Tier)
+	}
+	if rationale, ok := teeRationales[m.MatchedPattern]; ok {
+		return fmt.Sprintf("classified %s because the command %s", m.Tier, rationale)
+	}
+
+	var reason string
+	switch {
+	case len(m.MatchedSegments) > 0:
+		best := m.MatchedSegments[0]
+		for _, seg := range m.MatchedSegments[1:] {
+			if tierHigher(seg.Tier, best.Tier) {
+				best = seg
+			}
+		}
+		reason = fmt.Sprintf("compound command: a segment matched %s pattern %q", best.Tier, best.MatchedPattern)
+		if n := len(m.MatchedSegments); n > 1 {
+			reason += fmt.Sprintf(" (%d segments matched)", n)
+		}
+	case m.MatchedPattern != "":
+		reason = fmt.Sprintf("matched pattern %q", m.MatchedPattern)
+		if !m.ParseError {
+			reason = fmt.Sprintf("matched %s pattern %q", m.Tier, m.MatchedPattern)
+		}
+	default:
+		return "no pattern matched"
+	}
+	if m.ParseError {
+		reason += fmt.Sprintf("; upgraded to %s because the command could not be parsed reliably", m.Tier)
+	}
+	return reason
+}
+
 func tierApprovals(t RiskTier) int {
 	switch t {
 	case RiskTierCritical:
@@ -516,23 +653,24 @@ func (e *PatternEngine) AllPatterns() map[string][]*Pattern {
 	}
 }
 
-// Global pattern engine instance
-var defaultEngine = NewPatternEngine()
+// defaultEngine returns the global pattern engine, compiling its patterns on
+// first use so commands that classify through the daemon don't pay for it.
+var defaultEngine = sync.OnceValue(NewPatternEngine)
 
 // GetDefaultEngine returns the global pattern engine.
 func GetDefaultEngine() *PatternEngine {
-	return defaultEngine
+	return defaultEngine()
 }
 
 // Classify is a convenience function using the default engine.
 func Classify(cmd, cwd string) *MatchResult {
-	return defaultEngine.ClassifyCommand(cmd, cwd)
+	return defaultEngine().ClassifyCommand(cmd, cwd)
 }
 
 // TestPattern tests if a command matches any dangerous pattern.
 // Returns true if the command needs approval.
 func TestPattern(cmd string) bool {
-	result := defaultEngine.ClassifyCommand(cmd, "")
+	result := defaultEngine().ClassifyCommand(cmd, "")
 	return result.NeedsApproval
 }
 
@@ -2,6 +2,7 @@
 package core
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -66,6 +67,20 @@ func TestClassifyCommand(t *testing.T) {
 			wantApprovals:     1,
 			wantNeedsApproval: true,
 		},
+		{
+			name:              "rm -r -f local (split flags)",
+			cmd:               "rm -r -f ./build",
+			wantTier:          RiskTierDangerous,
+			wantApprovals:     1,
+			wantNeedsApproval: true,
+		},
+		{
+			name:              "rm long flags on root",
+			cmd:               "rm --recursive --force /",
+			wantTier:          RiskTierCritical,
+			wantApprovals:     2,
+			wantNeedsApproval: true,
+		},
 		{
 			name:              "git reset --hard",
 			cmd:               "git reset --hard HEAD~3",
@@ -733,6 +748,26 @@ func TestCompilePatterns_InvalidPattern(t *testing.T) {
 	}
 }
 
+func TestClassifyCommand_OptionWrappers(t *testing.T) {
+	engine := NewPatternEngine()
+	for _, tc := range []struct{ wrapped, inner string }{
+		{"timeout 30 rm -rf /", "rm -rf /"},
+		{"timeout -k 5 1m kubectl delete namespace prod", "kubectl delete namespace prod"},
+		{"find . -name '*.tmp' | xargs -0 rm -rf", "rm -rf"},
+		{"flock /tmp/deploy.lock docker rm -f web", "docker rm -f web"},
+		{"flock /tmp/deploy.lock -c 'git push --force origin main'", "git push --force origin main"},
+		{"watch -n 10 kubectl delete namespace staging", "kubectl delete namespace staging"},
+	} {
+		want := engine.ClassifyCommand(tc.inner, "")
+		if !want.NeedsApproval {
+			t.Fatalf("expected %q to need approval", tc.inner)
+		}
+		if got := engine.ClassifyCommand(tc.wrapped, ""); got.Tier != want.Tier {
+			t.Errorf("ClassifyCommand(%q).Tier = %q, want %q as for %q", tc.wrapped, got.Tier, want.Tier, tc.inner)
+		}
+	}
+}
+
 func TestClassifyCompoundCommands(t *testing.T) {
 	engine := NewPatternEngine()
 
@@ -970,3 +1005,63 @@ func TestClassifyCommand_SQLDetection(t *testing.T) {
 		}
 	})
 }
+
+func TestClassifyCommand_ChainedPrivilegeEscalation(t *testing.T) {
+	engine := NewPatternEngine()
+
+	res := engine.ClassifyCommand(`sudo su -c "rm -rf x"`, "")
+	if res.Tier != RiskTierCritical || res.MinApprovals != tierApprovals(RiskTierCritical) {
+		t.Fatalf("expected privilege escalation plus recursive delete to be critical, got %s (%d approvals)", res.Tier, res.MinApprovals)
+	}
+	if res.MatchedPattern != `^rm\s+-[rf]{2}` {
+		t.Errorf("expected the inner rm to match, got pattern %q", res.MatchedPattern)
+	}
+	if got := DescribeClassification(res); !strings.Contains(got, "escalates privilege through a nested shell (sudo su -c)") {
+		t.Errorf("expected the escalation in the rationale, got %q", got)
+	}
+
+	for cmd, want := range map[string]RiskTier{
+		`sudo -u root bash -c "rm -rf x"`: RiskTierCritical,
+		`su -c "ls -la"`:                  RiskTierCaution,
+		`sudo su -c "./deploy.sh"`:        RiskTierCaution,
+		"sudo rm -rf x":                   RiskTierDangerous,
+	} {
+		if got := engine.ClassifyCommand(cmd, "").Tier; got != want {
+			t.Errorf("ClassifyCommand(%q) = %s, want %s", cmd, got, want)
+		}
+	}
+}
+
+func TestDescribeClassification(t *testing.T) {
+	tests := []struct {
+		name string
+		m    *MatchResult
+		want string
+	}{
+		{"nil", nil, ""},
+		{"single", &MatchResult{Tier: RiskTierDangerous, MatchedPattern: `^rm\s+-rf`},
+			`matched dangerous pattern "^rm\\s+-rf"`},
+		{"compound picks highest", &MatchResult{Tier: RiskTierCritical, MatchedSegments: []SegmentMatch{
+			{Segment: "rm -rf x", Tier: RiskTierDangerous, MatchedPattern: "rm"},
+			{Segment: "kubectl delete ns prod", Tier: RiskTierCritical, MatchedPattern: "kubectl delete ns"},
+		}}, `compound command: a segment matched critical pattern "kubectl delete ns" (2 segments matched)`},
+		{"parse upgrade", &MatchResult{Tier: RiskTierCritical, MatchedPattern: "rm", ParseError: true},
+			`matched pattern "rm"; upgraded to critical because the command could not be parsed reliably`},
+		{"parse error only", &MatchResult{Tier: RiskTierCaution, MatchedPattern: "parse_error", ParseError: true},
+			"classified caution because the command could not be parsed reliably"},
+		{"no match", &MatchResult{}, "no pattern matched"},
+	}
+	for _, tc := range tests {
+		if got := DescribeClassification(tc.m); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDescribeClassification_OmitsOperands(t *testing.T) {
+	engine := NewPatternEngine()
+	got := DescribeClassification(engine.ClassifyCommand("rm -rf /home/alice/secret-project", "/tmp"))
+	if strings.Contains(got, "alice") || strings.Contains(got, "secret-project") {
+		t.Errorf("rationale leaks operands: %q", got)
+	}
+}
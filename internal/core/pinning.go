@@ -0,0 +1,267 @@
+// Package core implements cluster/cloud context pinning for approved commands.
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// ErrPinnedContextMissing is returned when the context a request was approved
+// against no longer exists at execution time.
+var ErrPinnedContextMissing = errors.New("pinned context no longer exists")
+
+const defaultContextProbeTimeout = 10 * time.Second
+
+// contextProbe runs a read-only CLI query (kubectl config, gcloud config, ...).
+// Tests replace it to avoid depending on installed tooling.
+var contextProbe = runCmdString
+
+// ContextFamily returns the pinnable command family of a command
+// (kubectl, aws, gcloud), or "" if the command is not in a pinnable family.
+func ContextFamily(raw string) string {
+	normalized := NormalizeCommand(raw)
+	cmd := strings.TrimSpace(normalized.Primary)
+	if cmd == "" {
+		cmd = strings.TrimSpace(raw)
+	}
+	tokens := parseShellTokens(cmd)
+	if len(tokens) == 0 {
+		return ""
+	}
+	switch filepath.Base(tokens[0]) {
+	case "kubectl":
+		return db.ContextFamilyKubectl
+	case "aws":
+		return db.ContextFamilyAWS
+	case "gcloud":
+		return db.ContextFamilyGCloud
+	default:
+		return ""
+	}
+}
+
+// CaptureContext records the context a command would run against right now.
+// It returns nil when the command's family is not listed in families or the
+// context cannot be determined (e.g. the CLI is not installed).
+func CaptureContext(ctx context.Context, spec db.CommandSpec, families []string) *db.PinnedContext {
+	family := ContextFamily(spec.Raw)
+	if family == "" || !containsString(families, family) {
+		return nil
+	}
+	tokens := parseShellTokens(spec.Raw)
+
+	probeCtx, cancel := context.WithTimeout(ctx, defaultContextProbeTimeout)
+	defer cancel()
+
+	pc := &db.PinnedContext{Family: family}
+	switch family {
+	case db.ContextFamilyKubectl:
+		pc.KubeContext, _ = flagValue(tokens, "--context", "")
+		if pc.KubeContext == "" {
+			out, err := contextProbe(probeCtx, spec.Cwd, "kubectl", "config", "current-context")
+			if err != nil {
+				return nil
+			}
+			pc.KubeContext = strings.TrimSpace(out)
+		}
+		if pc.KubeContext == "" {
+			return nil
+		}
+		pc.KubeNamespace, _ = flagValue(tokens, "--namespace", "-n")
+		if pc.KubeNamespace == "" {
+			out, _ := contextProbe(probeCtx, spec.Cwd, "kubectl", "config", "view", "--minify",
+				"--context", pc.KubeContext, "-o", "jsonpath={..namespace}")
+			pc.KubeNamespace = strings.TrimSpace(out)
+		}
+		if pc.KubeNamespace == "" {
+			pc.KubeNamespace = "default"
+		}
+	case db.ContextFamilyAWS:
+		pc.AWSProfile, _ = flagValue(tokens, "--profile", "")
+		if pc.AWSProfile == "" {
+			pc.AWSProfile = os.Getenv("AWS_PROFILE")
+		}
+		if pc.AWSProfile == "" {
+			pc.AWSProfile = "default"
+		}
+	case db.ContextFamilyGCloud:
+		pc.GCloudConfig = os.Getenv("CLOUDSDK_ACTIVE_CONFIG_NAME")
+		if pc.GCloudConfig == "" {
+			out, err := contextProbe(probeCtx, spec.Cwd, "gcloud", "config", "configurations", "list",
+				"--filter=is_active:true", "--format=value(name)")
+			if err != nil {
+				return nil
+			}
+			pc.GCloudConfig = strings.TrimSpace(out)
+		}
+		if pc.GCloudConfig == "" {
+			return nil
+		}
+		pc.GCloudProject, _ = flagValue(tokens, "--project", "")
+		if pc.GCloudProject == "" {
+			out, _ := contextProbe(probeCtx, spec.Cwd, "gcloud", "config", "get-value", "project",
+				"--configuration", pc.GCloudConfig)
+			pc.GCloudProject = strings.TrimSpace(out)
+		}
+	}
+	return pc
+}
+
+// DescribePinnedContext renders a pinned context for reviewers, e.g.
+// "kubectl context=prod namespace=web".
+func DescribePinnedContext(pc *db.PinnedContext) string {
+	if pc == nil {
+		return ""
+	}
+	parts := []string{pc.Family}
+	add := func(k, v string) {
+		if v != "" {
+			parts = append(parts, k+"="+v)
+		}
+	}
+	add("context", pc.KubeContext)
+	add("namespace", pc.KubeNamespace)
+	add("profile", pc.AWSProfile)
+	add("configuration", pc.GCloudConfig)
+	add("project", pc.GCloudProject)
+	return strings.Join(parts, " ")
+}
+
+// contextPin is how a pinned context is applied to one execution.
+type contextPin struct {
+	// Spec is the command to run, with pinning flags injected where possible.
+	Spec db.CommandSpec
+	// Env overrides the inherited environment; an empty value unsets the key.
+	Env map[string]string
+	// Decision is recorded on the execution record.
+	Decision string
+}
+
+// pinContext verifies the pinned context still exists and returns the command
+// and environment that run against it. Requests without a pinned context run
+// unchanged.
+func pinContext(ctx context.Context, spec db.CommandSpec, pc *db.PinnedContext) (*contextPin, error) {
+	pin := &contextPin{Spec: spec}
+	if pc == nil {
+		return pin, nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, defaultContextProbeTimeout)
+	defer cancel()
+
+	desc := DescribePinnedContext(pc)
+	switch pc.Family {
+	case db.ContextFamilyKubectl:
+		out, err := contextProbe(probeCtx, spec.Cwd, "kubectl", "config", "get-contexts", "-o", "name")
+		if err != nil {
+			return nil, fmt.Errorf("%w: listing kubectl contexts: %v", ErrPinnedContextMissing, err)
+		}
+		if !containsString(strings.Fields(out), pc.KubeContext) {
+			return nil, fmt.Errorf("%w: kubectl context %q", ErrPinnedContextMissing, pc.KubeContext)
+		}
+		if spec.Shell || len(spec.Argv) == 0 {
+			pin.Decision = "verified " + desc + "; flags not injected into shell command"
+			return pin, nil
+		}
+		argv, injected := injectKubectlFlags(spec.Argv, pc)
+		pin.Spec.Argv = argv
+		if len(injected) == 0 {
+			pin.Decision = "verified " + desc + "; already explicit in command"
+		} else {
+			pin.Decision = "pinned " + desc + " via " + strings.Join(injected, " ")
+		}
+	case db.ContextFamilyAWS:
+		if pc.AWSProfile != "default" {
+			out, err := contextProbe(probeCtx, spec.Cwd, "aws", "configure", "list-profiles")
+			if err != nil {
+				return nil, fmt.Errorf("%w: listing aws profiles: %v", ErrPinnedContextMissing, err)
+			}
+			if !containsString(strings.Fields(out), pc.AWSProfile) {
+				return nil, fmt.Errorf("%w: aws profile %q", ErrPinnedContextMissing, pc.AWSProfile)
+			}
+			pin.Env = map[string]string{"AWS_PROFILE": pc.AWSProfile}
+		} else {
+			pin.Env = map[string]string{"AWS_PROFILE": "", "AWS_DEFAULT_PROFILE": ""}
+		}
+		pin.Decision = "pinned " + desc + " via AWS_PROFILE"
+	case db.ContextFamilyGCloud:
+		if _, err := contextProbe(probeCtx, spec.Cwd, "gcloud", "config", "configurations", "describe", pc.GCloudConfig); err != nil {
+			return nil, fmt.Errorf("%w: gcloud configuration %q", ErrPinnedContextMissing, pc.GCloudConfig)
+		}
+		pin.Env = map[string]string{"CLOUDSDK_ACTIVE_CONFIG_NAME": pc.GCloudConfig}
+		via := "CLOUDSDK_ACTIVE_CONFIG_NAME"
+		if pc.GCloudProject != "" {
+			pin.Env["CLOUDSDK_CORE_PROJECT"] = pc.GCloudProject
+			via += ", CLOUDSDK_CORE_PROJECT"
+		}
+		pin.Decision = "pinned " + desc + " via " + via
+	default:
+		pin.Decision = "unpinned: unknown family " + pc.Family
+	}
+	return pin, nil
+}
+
+// injectKubectlFlags adds --context/--namespace after the kubectl token unless
+// the command already sets them. It returns the new argv and the added flags.
+func injectKubectlFlags(argv []string, pc *db.PinnedContext) ([]string, []string) {
+	idx := -1
+	for i, a := range argv {
+		if filepath.Base(a) == "kubectl" {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return argv, nil
+	}
+
+	var flags []string
+	if _, ok := flagValue(argv, "--context", ""); !ok {
+		flags = append(flags, "--context="+pc.KubeContext)
+	}
+	if _, ok := flagValue(argv, "--namespace", "-n"); !ok && pc.KubeNamespace != "" {
+		flags = append(flags, "--namespace="+pc.KubeNamespace)
+	}
+	if len(flags) == 0 {
+		return argv, nil
+	}
+
+	out := make([]string, 0, len(argv)+len(flags))
+	out = append(out, argv[:idx+1]...)
+	out = append(out, flags...)
+	out = append(out, argv[idx+1:]...)
+	return out, flags
+}
+
+// flagValue returns the value of a "--long value", "--long=value" or
+// "-s value" flag in tokens.
+func flagValue(tokens []string, long, short string) (string, bool) {
+	for i, t := range tokens {
+		if t == long || (short != "" && t == short) {
+			if i+1 < len(tokens) {
+				return tokens[i+1], true
+			}
+			return "", true
+		}
+		if strings.HasPrefix(t, long+"=") {
+			return strings.TrimPrefix(t, long+"="), true
+		}
+	}
+	return "", false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// stubContextProbe replaces contextProbe with canned outputs keyed by the
+// joined command line. Unknown commands fail like a missing CLI would.
+func stubContextProbe(t *testing.T, outputs map[string]string) {
+	t.Helper()
+	orig := contextProbe
+	t.Cleanup(func() { contextProbe = orig })
+	contextProbe = func(ctx context.Context, dir, name string, args ...string) (string, error) {
+		key := strings.Join(append([]string{name}, args...), " ")
+		if out, ok := outputs[key]; ok {
+			return out, nil
+		}
+		return "", errors.New("not found: " + key)
+	}
+}
+
+func TestContextFamily(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{"kubectl delete pod web-1", db.ContextFamilyKubectl},
+		{"sudo kubectl apply -f x.yaml", db.ContextFamilyKubectl},
+		{"/usr/local/bin/aws s3 rm s3://bucket --recursive", db.ContextFamilyAWS},
+		{"gcloud compute instances delete vm-1", db.ContextFamilyGCloud},
+		{"rm -rf ./build", ""},
+		{"", ""},
+	}
+	for _, tc := range tests {
+		if got := ContextFamily(tc.cmd); got != tc.want {
+			t.Errorf("ContextFamily(%q) = %q, want %q", tc.cmd, got, tc.want)
+		}
+	}
+}
+
+func TestCaptureContext(t *testing.T) {
+	stubContextProbe(t, map[string]string{
+		"kubectl config current-context":                                                 "prod\n",
+		"kubectl config view --minify --context prod -o jsonpath={..namespace}":          "web",
+		"kubectl config view --minify --context staging -o jsonpath={..namespace}":       "",
+		"gcloud config configurations list --filter=is_active:true --format=value(name)": "work\n",
+		"gcloud config get-value project --configuration work":                           "my-proj\n",
+	})
+	t.Setenv("AWS_PROFILE", "ops")
+	t.Setenv("CLOUDSDK_ACTIVE_CONFIG_NAME", "")
+	all := []string{db.ContextFamilyKubectl, db.ContextFamilyAWS, db.ContextFamilyGCloud}
+
+	tests := []struct {
+		name     string
+		cmd      string
+		families []string
+		want     *db.PinnedContext
+	}{
+		{"kubectl current context", "kubectl delete pod x", all,
+			&db.PinnedContext{Family: "kubectl", KubeContext: "prod", KubeNamespace: "web"}},
+		{"kubectl explicit flags", "kubectl --context staging -n api delete pod x", all,
+			&db.PinnedContext{Family: "kubectl", KubeContext: "staging", KubeNamespace: "api"}},
+		{"kubectl namespace defaults", "kubectl --context=staging delete pod x", all,
+			&db.PinnedContext{Family: "kubectl", KubeContext: "staging", KubeNamespace: "default"}},
+		{"aws env profile", "aws s3 rm s3://b/k", all,
+			&db.PinnedContext{Family: "aws", AWSProfile: "ops"}},
+		{"aws flag profile", "aws --profile prod s3 rm s3://b/k", all,
+			&db.PinnedContext{Family: "aws", AWSProfile: "prod"}},
+		{"gcloud active config", "gcloud compute instances delete vm", all,
+			&db.PinnedContext{Family: "gcloud", GCloudConfig: "work", GCloudProject: "my-proj"}},
+		{"family not configured", "kubectl delete pod x", []string{db.ContextFamilyAWS}, nil},
+		{"not pinnable", "rm -rf ./build", all, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CaptureContext(context.Background(), db.CommandSpec{Raw: tc.cmd}, tc.families)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("CaptureContext(%q) = %+v, want %+v", tc.cmd, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCaptureContext_ProbeFailureYieldsNil(t *testing.T) {
+	stubContextProbe(t, nil)
+	got := CaptureContext(context.Background(), db.CommandSpec{Raw: "kubectl delete pod x"}, []string{db.ContextFamilyKubectl})
+	if got != nil {
+		t.Fatalf("expected nil when kubectl is unavailable, got %+v", got)
+	}
+}
+
+func TestDescribePinnedContext(t *testing.T) {
+	if got := DescribePinnedContext(nil); got != "" {
+		t.Fatalf("DescribePinnedContext(nil) = %q", got)
+	}
+	pc := &db.PinnedContext{Family: "kubectl", KubeContext: "prod", KubeNamespace: "web"}
+	if got, want := DescribePinnedContext(pc), "kubectl context=prod namespace=web"; got != want {
+		t.Fatalf("DescribePinnedContext = %q, want %q", got, want)
+	}
+}
+
+func TestPinContext(t *testing.T) {
+	stubContextProbe(t, map[string]string{
+		"kubectl config get-contexts -o name":           "dev\nprod\n",
+		"aws configure list-profiles":                   "default\nops\n",
+		"gcloud config configurations describe work":    "name: work",
+		"gcloud config configurations describe missing": "",
+	})
+	kube := &db.PinnedContext{Family: "kubectl", KubeContext: "prod", KubeNamespace: "web"}
+
+	t.Run("no pinned context runs unchanged", func(t *testing.T) {
+		spec := db.CommandSpec{Raw: "ls", Argv: []string{"ls"}}
+		pin, err := pinContext(context.Background(), spec, nil)
+		if err != nil {
+			t.Fatalf("pinContext: %v", err)
+		}
+		if !reflect.DeepEqual(pin.Spec, spec) || pin.Env != nil || pin.Decision != "" {
+			t.Fatalf("unexpected pin %+v", pin)
+		}
+	})
+
+	t.Run("kubectl flags injected", func(t *testing.T) {
+		spec := db.CommandSpec{Raw: "kubectl delete pod x", Argv: []string{"kubectl", "delete", "pod", "x"}}
+		pin, err := pinContext(context.Background(), spec, kube)
+		if err != nil {
+			t.Fatalf("pinContext: %v", err)
+		}
+		want := []string{"kubectl", "--context=prod", "--namespace=web", "delete", "pod", "x"}
+		if !reflect.DeepEqual(pin.Spec.Argv, want) {
+			t.Fatalf("argv = %v, want %v", pin.Spec.Argv, want)
+		}
+		if !strings.HasPrefix(pin.Decision, "pinned kubectl context=prod") {
+			t.Fatalf("decision = %q", pin.Decision)
+		}
+		if len(spec.Argv) != 4 {
+			t.Fatalf("original argv mutated: %v", spec.Argv)
+		}
+	})
+
+	t.Run("kubectl explicit flags kept", func(t *testing.T) {
+		spec := db.CommandSpec{Argv: []string{"kubectl", "--context", "prod", "-n", "web", "delete", "pod", "x"}}
+		pin, err := pinContext(context.Background(), spec, kube)
+		if err != nil {
+			t.Fatalf("pinContext: %v", err)
+		}
+		if !reflect.DeepEqual(pin.Spec.Argv, spec.Argv) {
+			t.Fatalf("argv changed: %v", pin.Spec.Argv)
+		}
+		if !strings.Contains(pin.Decision, "already explicit") {
+			t.Fatalf("decision = %q", pin.Decision)
+		}
+	})
+
+	t.Run("kubectl context removed refuses", func(t *testing.T) {
+		gone := &db.PinnedContext{Family: "kubectl", KubeContext: "old-cluster"}
+		_, err := pinContext(context.Background(), db.CommandSpec{Argv: []string{"kubectl", "get", "pods"}}, gone)
+		if !errors.Is(err, ErrPinnedContextMissing) {
+			t.Fatalf("expected ErrPinnedContextMissing, got %v", err)
+		}
+	})
+
+	t.Run("aws profile via env", func(t *testing.T) {
+		pin, err := pinContext(context.Background(), db.CommandSpec{Raw: "aws s3 ls"}, &db.PinnedContext{Family: "aws", AWSProfile: "ops"})
+		if err != nil {
+			t.Fatalf("pinContext: %v", err)
+		}
+		if pin.Env["AWS_PROFILE"] != "ops" {
+			t.Fatalf("env = %v", pin.Env)
+		}
+	})
+
+	t.Run("aws missing profile refuses", func(t *testing.T) {
+		_, err := pinContext(context.Background(), db.CommandSpec{Raw: "aws s3 ls"}, &db.PinnedContext{Family: "aws", AWSProfile: "gone"})
+		if !errors.Is(err, ErrPinnedContextMissing) {
+			t.Fatalf("expected ErrPinnedContextMissing, got %v", err)
+		}
+	})
+
+	t.Run("gcloud config via env", func(t *testing.T) {
+		pc := &db.PinnedContext{Family: "gcloud", GCloudConfig: "work", GCloudProject: "p1"}
+		pin, err := pinContext(context.Background(), db.CommandSpec{Raw: "gcloud compute instances list"}, pc)
+		if err != nil {
+			t.Fatalf("pinContext: %v", err)
+		}
+		want := map[string]string{"CLOUDSDK_ACTIVE_CONFIG_NAME": "work", "CLOUDSDK_CORE_PROJECT": "p1"}
+		if !reflect.DeepEqual(pin.Env, want) {
+			t.Fatalf("env = %v, want %v", pin.Env, want)
+		}
+	})
+}
+
+func TestExecuteApprovedRequest_RefusesMissingPinnedContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell execution test uses /bin/sh or $SHELL")
+	}
+	stubContextProbe(t, map[string]string{"kubectl config get-contexts -o name": "dev\n"})
+
+	dbConn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("db.Open(:memory:) error = %v", err)
+	}
+	defer dbConn.Close()
+
+	session := &db.Session{ID: "pin-session", ProjectPath: "/tmp/test", AgentName: "pin-agent", Program: "test", Model: "test-model"}
+	if err := dbConn.CreateSession(session); err != nil {
+		t.Fatalf("CreateSession error = %v", err)
+	}
+	req := &db.Request{
+		ProjectPath:        "/tmp/test",
+		RequestorSessionID: session.ID,
+		RequestorAgent:     session.AgentName,
+		RequestorModel:     session.Model,
+		RiskTier:           db.RiskTierDangerous,
+		Command:            db.CommandSpec{Raw: "kubectl get pods", Argv: []string{"kubectl", "get", "pods"}, Cwd: "/tmp"},
+		Status:             db.StatusApproved,
+		PinnedContext:      &db.PinnedContext{Family: "kubectl", KubeContext: "prod", KubeNamespace: "web"},
+	}
+	if err := dbConn.CreateRequest(req); err != nil {
+		t.Fatalf("CreateRequest error = %v", err)
+	}
+
+	_, err = NewExecutor(dbConn, nil).ExecuteApprovedRequest(context.Background(), ExecuteOptions{
+		RequestID: req.ID,
+		SessionID: session.ID,
+		LogDir:    t.TempDir(),
+	})
+	if !errors.Is(err, ErrPinnedContextMissing) {
+		t.Fatalf("expected ErrPinnedContextMissing, got %v", err)
+	}
+
+	got, err := dbConn.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("GetRequest error = %v", err)
+	}
+	if got.Status != db.StatusApproved {
+		t.Fatalf("status = %s, want approved (not executed)", got.Status)
+	}
+}
@@ -0,0 +1,491 @@
+// Package core implements trial previews of commands in a scratch copy.
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+const defaultPreviewTimeout = 30 * time.Second
+
+// Sandbox modes reported by a preview trial.
+const (
+	SandboxModeTempCopy  = "temp_copy"
+	SandboxModeContainer = "container"
+	SandboxModeSkipped   = "skipped"
+)
+
+// fileTouchingCommands only write the paths named on their command line, so
+// once sandboxEscape has checked those paths a trial in a copy of the
+// working directory shows what they would do. The temp copy is not an
+// isolation boundary: tools that can write paths from their scripts or
+// archives (sed, find, tar, unzip, patch), run other programs (find -exec)
+// or create links out of the copy (ln) are deliberately left out.
+var fileTouchingCommands = map[string]bool{
+	"rm": true, "rmdir": true, "mv": true, "touch": true, "mkdir": true,
+	"chmod": true, "chown": true, "chgrp": true, "zip": true, "gzip": true,
+	"gunzip": true, "truncate": true, "tee": true,
+}
+
+// gitLocalSubcommands are git subcommands that only touch the work tree and
+// run no hooks.
+var gitLocalSubcommands = map[string]bool{
+	"add": true, "apply": true, "clean": true, "mv": true, "reset": true,
+	"restore": true, "rm": true, "stash": true,
+}
+
+// PreviewOptions controls RunPreview.
+type PreviewOptions struct {
+	// Command is the raw command to preview.
+	Command string
+	// Cwd is the directory the command targets (copied for the trial).
+	Cwd string
+	// EnableDryRun runs the command's dry-run variant when one exists.
+	EnableDryRun bool
+	// MaxCopyBytes caps the scratch copy of Cwd; 0 disables the trial.
+	MaxCopyBytes int64
+	// ContainerImage runs the trial in a container (docker or podman)
+	// when set, instead of directly in the temp copy.
+	ContainerImage string
+	// Timeout bounds the trial execution (default 30s).
+	Timeout time.Duration
+	// PatternEngine classifies the command (default engine if nil).
+	PatternEngine *PatternEngine
+	// RiskOverrides are applied after classification, as for requests.
+	RiskOverrides []RiskOverrideRule
+}
+
+// PreviewResult is everything gathered by a preview.
+type PreviewResult struct {
+	Command       string           `json:"command"`
+	Tier          RiskTier         `json:"tier"`
+	TierReason    string           `json:"tier_reason"`
+	Summary       string           `json:"summary,omitempty"`
+	NeedsApproval bool             `json:"needs_approval"`
+	DryRun        *db.DryRunResult `json:"dry_run,omitempty"`
+	DryRunError   string           `json:"dry_run_error,omitempty"`
+	Sandbox       *SandboxTrial    `json:"sandbox"`
+
+	Classification *MatchResult `json:"-"`
+}
+
+// SandboxTrial is the result of a trial run against a scratch copy of the
+// working directory, directly on the host or inside a container.
+type SandboxTrial struct {
+	// Mode is temp_copy, container, or skipped.
+	Mode string `json:"mode"`
+	// SkipReason explains why no trial ran.
+	SkipReason string `json:"skip_reason,omitempty"`
+	// CopyBytes is the size of the copied working directory.
+	CopyBytes  int64  `json:"copy_bytes,omitempty"`
+	ExitCode   int    `json:"exit_code"`
+	Output     string `json:"output,omitempty"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	TimedOut   bool   `json:"timed_out,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	// Created, Modified and Deleted list paths (relative to Cwd) the trial
+	// changed in the copy.
+	Created  []string `json:"created,omitempty"`
+	Modified []string `json:"modified,omitempty"`
+	Deleted  []string `json:"deleted,omitempty"`
+}
+
+// RunPreview classifies a command, runs its dry-run variant, and for safe
+// commands runs a trial against a scratch copy of the working directory. It
+// never creates or changes requests.
+func RunPreview(ctx context.Context, opts PreviewOptions) (*PreviewResult, error) {
+	if strings.TrimSpace(opts.Command) == "" {
+		return nil, ErrCommandRequired
+	}
+	if opts.Cwd == "" {
+		return nil, fmt.Errorf("cwd is required")
+	}
+	engine := opts.PatternEngine
+	if engine == nil {
+		engine = GetDefaultEngine()
+	}
+
+	overrides, err := CompileRiskOverrides(opts.RiskOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	classification := engine.ClassifyCommand(opts.Command, opts.Cwd)
+	tierReason := DescribeClassification(classification)
+	if overridden, note := overrides.Apply(classification, opts.Command); note != "" {
+		classification = overridden
+		tierReason += "; " + note
+	}
+	result := &PreviewResult{
+		Command:        opts.Command,
+		Tier:           classification.Tier,
+		TierReason:     tierReason,
+		Summary:        SummarizeCommand(opts.Command),
+		NeedsApproval:  classification.NeedsApproval,
+		Classification: classification,
+	}
+
+	if opts.EnableDryRun {
+		dryRun, err := RunDryRun(&db.CommandSpec{Raw: opts.Command, Cwd: opts.Cwd})
+		if dryRun != nil {
+			dryRun.Output = RedactDryRunOutput(dryRun.Output, nil)
+		}
+		result.DryRun = dryRun
+		if err != nil {
+			result.DryRunError = err.Error()
+		}
+	}
+
+	result.Sandbox = runSandboxTrial(ctx, opts, classification)
+	return result, nil
+}
+
+// Attachments returns the preview evidence as request attachments.
+func (r *PreviewResult) Attachments() []db.Attachment {
+	if r.Sandbox == nil || r.Sandbox.Mode == SandboxModeSkipped {
+		return nil
+	}
+	s := r.Sandbox
+	meta := map[string]any{
+		"source":       "slb preview",
+		"sandbox_mode": s.Mode,
+		"exit_code":    s.ExitCode,
+		"duration_ms":  s.DurationMs,
+	}
+	if s.TimedOut {
+		meta["timed_out"] = true
+	}
+	if s.Truncated {
+		meta["truncated"] = true
+	}
+
+	var content strings.Builder
+	content.WriteString(s.Output)
+	for _, change := range []struct {
+		label string
+		paths []string
+	}{{"created", s.Created}, {"modified", s.Modified}, {"deleted", s.Deleted}} {
+		if len(change.paths) == 0 {
+			continue
+		}
+		meta[change.label] = change.paths
+		if content.Len() > 0 {
+			content.WriteString("\n")
+		}
+		fmt.Fprintf(&content, "--- %s ---\n%s", change.label, strings.Join(change.paths, "\n"))
+	}
+
+	return []db.Attachment{{
+		Type:     db.AttachmentTypeContext,
+		Content:  content.String(),
+		Metadata: meta,
+	}}
+}
+
+func skippedTrial(format string, args ...any) *SandboxTrial {
+	return &SandboxTrial{Mode: SandboxModeSkipped, SkipReason: fmt.Sprintf(format, args...)}
+}
+
+func runSandboxTrial(ctx context.Context, opts PreviewOptions, classification *MatchResult) *SandboxTrial {
+	if classification.NeedsApproval {
+		return skippedTrial("command is classified %s; trials only run for safe commands (use slb request)", classification.Tier)
+	}
+	if opts.MaxCopyBytes <= 0 {
+		return skippedTrial("scratch copy is disabled (general.preview_max_copy_mb = 0)")
+	}
+
+	var runtime string
+	if opts.ContainerImage != "" {
+		runtime = containerRuntime()
+		if runtime == "" {
+			return skippedTrial("no container runtime (docker or podman) found for %s", opts.ContainerImage)
+		}
+	} else {
+		if !isFileTouching(opts.Command) {
+			return skippedTrial("not a file-touching command; set general.preview_container_image for container isolation")
+		}
+		if reason := sandboxEscape(opts.Command); reason != "" {
+			return skippedTrial("%s", reason)
+		}
+	}
+
+	size, err := estimateFileBytes([]string{opts.Cwd}, opts.MaxCopyBytes)
+	if err != nil {
+		if errors.Is(err, errSizeCapExceeded) {
+			return skippedTrial("estimated copy of %s exceeds the %d MB cap", opts.Cwd, opts.MaxCopyBytes/(1024*1024))
+		}
+		return skippedTrial("%v", err)
+	}
+
+	tmp, err := os.MkdirTemp("", "slb-preview-")
+	if err != nil {
+		return skippedTrial("creating scratch copy: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+	work := filepath.Join(tmp, "work")
+	if err := copyTree(opts.Cwd, work); err != nil {
+		return skippedTrial("copying %s: %v", opts.Cwd, err)
+	}
+
+	before, err := snapshotTree(work)
+	if err != nil {
+		return skippedTrial("%v", err)
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultPreviewTimeout
+	}
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	trial := &SandboxTrial{Mode: SandboxModeTempCopy, CopyBytes: size}
+	if runtime != "" {
+		trial.Mode = SandboxModeContainer
+		// The container does not inherit this environment; the runtime
+		// client needs it to reach its daemon.
+		cmd = exec.CommandContext(execCtx, runtime, "run", "--rm", "--network", "none",
+			"-v", work+":/work", "-w", "/work", opts.ContainerImage, "sh", "-c", opts.Command)
+		cmd.Env = os.Environ()
+	} else {
+		cmd = exec.CommandContext(execCtx, "sh", "-c", opts.Command)
+		cmd.Dir = work
+		cmd.Env = previewEnv(tmp)
+	}
+
+	maxOutput := DefaultAttachmentConfig().MaxOutputSize
+	stdout := &cappedBuffer{max: maxOutput}
+	stderr := &cappedBuffer{max: maxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	runErr := cmd.Run()
+	trial.DurationMs = time.Since(start).Milliseconds()
+	trial.Output = combineStdoutStderr(stdout.String(), stderr.String())
+	trial.Truncated = stdout.Truncated() || stderr.Truncated()
+	if runErr != nil {
+		trial.TimedOut = errors.Is(execCtx.Err(), context.DeadlineExceeded)
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			trial.ExitCode = exitErr.ExitCode()
+		} else {
+			trial.ExitCode = -1
+			if trial.Output == "" {
+				trial.Output = runErr.Error()
+			}
+		}
+	}
+
+	after, err := snapshotTree(work)
+	if err == nil {
+		trial.Created, trial.Modified, trial.Deleted = diffSnapshots(before, after)
+	}
+	return trial
+}
+
+// isFileTouching reports whether every segment of cmd is a local file tool.
+func isFileTouching(cmd string) bool {
+	segments := NormalizeCommand(cmd).Segments
+	if len(segments) == 0 {
+		return false
+	}
+	for _, seg := range segments {
+		tokens := parseShellTokens(seg)
+		if len(tokens) == 0 {
+			return false
+		}
+		base := filepath.Base(tokens[0])
+		if base == "git" {
+			if len(tokens) < 2 || !gitLocalSubcommands[tokens[1]] {
+				return false
+			}
+			continue
+		}
+		if !fileTouchingCommands[base] {
+			return false
+		}
+	}
+	return true
+}
+
+// sandboxEscape returns why cmd could reach outside a copy of its working
+// directory, or "" when none of its arguments do. It only inspects the
+// command line, which is why fileTouchingCommands is limited to tools that
+// write nothing but their arguments.
+func sandboxEscape(cmd string) string {
+	if strings.ContainsAny(cmd, "$`~") {
+		return "command uses shell expansion that could reach outside the scratch copy"
+	}
+	for _, seg := range NormalizeCommand(cmd).Segments {
+		tokens := parseShellTokens(seg)
+		if len(tokens) > 0 && filepath.Base(tokens[0]) == "cd" {
+			return "command changes directory outside the scratch copy"
+		}
+		for _, tok := range tokens[1:] {
+			if strings.HasPrefix(tok, "/") || strings.Contains(tok, "=/") {
+				return fmt.Sprintf("command references absolute path %q outside the scratch copy", tok)
+			}
+			if strings.HasPrefix(tok, "-") && strings.Contains(tok, "/") {
+				return fmt.Sprintf("command passes path %q in an option; only plain relative operands are allowed", tok)
+			}
+			for _, part := range strings.Split(filepath.ToSlash(tok), "/") {
+				if part == ".." {
+					return fmt.Sprintf("command references parent path %q outside the scratch copy", tok)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// previewEnv is the environment for a trial: nothing is inherited beyond
+// PATH and locale, HOME and TMPDIR point into the scratch directory, and git
+// is kept from reading user config or running hooks and fsmonitor commands.
+func previewEnv(scratch string) []string {
+	env := []string{
+		"HOME=" + scratch,
+		"TMPDIR=" + scratch,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL=" + os.DevNull,
+		"GIT_CONFIG_COUNT=2",
+		"GIT_CONFIG_KEY_0=core.hooksPath",
+		"GIT_CONFIG_VALUE_0=" + os.DevNull,
+		"GIT_CONFIG_KEY_1=core.fsmonitor",
+		"GIT_CONFIG_VALUE_1=false",
+	}
+	for _, key := range []string{"PATH", "LANG", "LC_ALL"} {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+	return env
+}
+
+// containerRuntime returns the first available container runtime.
+func containerRuntime() string {
+	for _, name := range []string{"docker", "podman"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// copyTree copies regular files and directories from src into dst.
+// Symlinks and special files are not copied so the trial cannot follow
+// them out of the scratch copy.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case info.Mode().IsRegular():
+			return copyFile(p, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// snapshotTree maps each path under root to a digest of its content and mode.
+func snapshotTree(root string) (map[string]string, error) {
+	snap := make(map[string]string)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			snap[filepath.ToSlash(rel)] = info.Mode().String()
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		snap[filepath.ToSlash(rel)] = info.Mode().String() + ":" + hex.EncodeToString(sum[:])
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("snapshotting scratch copy: %w", err)
+	}
+	return snap, nil
+}
+
+// diffSnapshots returns the sorted paths created, modified and deleted
+// between two snapshots.
+func diffSnapshots(before, after map[string]string) (created, modified, deleted []string) {
+	for p, digest := range after {
+		prev, ok := before[p]
+		switch {
+		case !ok:
+			created = append(created, p)
+		case prev != digest:
+			modified = append(modified, p)
+		}
+	}
+	for p := range before {
+		if _, ok := after[p]; !ok {
+			deleted = append(deleted, p)
+		}
+	}
+	sort.Strings(created)
+	sort.Strings(modified)
+	sort.Strings(deleted)
+	return created, modified, deleted
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writePreviewFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunPreview_TempCopyTrial(t *testing.T) {
+	dir := t.TempDir()
+	writePreviewFiles(t, dir, map[string]string{"old.log": "x", "keep.txt": "a"})
+
+	res, err := RunPreview(context.Background(), PreviewOptions{
+		Command:      "rm old.log",
+		Cwd:          dir,
+		EnableDryRun: true,
+		MaxCopyBytes: 1 << 20,
+	})
+	if err != nil {
+		t.Fatalf("RunPreview failed: %v", err)
+	}
+	if res.NeedsApproval || res.Tier != RiskTier(RiskSafe) {
+		t.Errorf("expected safe classification, got %s (needs approval %v)", res.Tier, res.NeedsApproval)
+	}
+	if res.DryRun == nil || !strings.Contains(res.DryRun.Command, "ls") {
+		t.Errorf("expected rm dry-run mapping, got %+v", res.DryRun)
+	}
+	s := res.Sandbox
+	if s.Mode != SandboxModeTempCopy || s.ExitCode != 0 {
+		t.Fatalf("expected successful temp_copy trial, got %+v", s)
+	}
+	if !reflect.DeepEqual(s.Deleted, []string{"old.log"}) || len(s.Created) != 0 || len(s.Modified) != 0 {
+		t.Errorf("changes = created %v modified %v deleted %v", s.Created, s.Modified, s.Deleted)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.log")); err != nil {
+		t.Errorf("trial touched the real directory: %v", err)
+	}
+}
+
+func TestRunPreview_ReportsCreatedAndModified(t *testing.T) {
+	dir := t.TempDir()
+	writePreviewFiles(t, dir, map[string]string{"sub/keep.txt": "a"})
+
+	res, err := RunPreview(context.Background(), PreviewOptions{
+		Command:      "touch new.txt && chmod 600 sub/keep.txt",
+		Cwd:          dir,
+		MaxCopyBytes: 1 << 20,
+	})
+	if err != nil {
+		t.Fatalf("RunPreview failed: %v", err)
+	}
+	s := res.Sandbox
+	if s.Mode != SandboxModeTempCopy {
+		t.Fatalf("expected temp_copy trial, got %+v", s)
+	}
+	if !reflect.DeepEqual(s.Created, []string{"new.txt"}) || !reflect.DeepEqual(s.Modified, []string{"sub/keep.txt"}) {
+		t.Errorf("changes = created %v modified %v", s.Created, s.Modified)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(err) {
+		t.Error("trial created a file in the real directory")
+	}
+}
+
+func TestRunPreview_SkipsTrial(t *testing.T) {
+	dir := t.TempDir()
+	writePreviewFiles(t, dir, map[string]string{"big.log": strings.Repeat("x", 4096)})
+
+	tests := []struct {
+		name    string
+		command string
+		maxCopy int64
+		want    string
+	}{
+		{"needs approval", "rm -rf ./build", 1 << 20, "classified dangerous"},
+		{"copy disabled", "rm big.log", 0, "disabled"},
+		{"over cap", "rm big.log", 1024, "exceeds"},
+		{"not file-touching", "echo hello", 1 << 20, "not a file-touching command"},
+		{"escapes copy", "rm /tmp/other.log", 1 << 20, "absolute path"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := RunPreview(context.Background(), PreviewOptions{Command: tc.command, Cwd: dir, MaxCopyBytes: tc.maxCopy})
+			if err != nil {
+				t.Fatalf("RunPreview failed: %v", err)
+			}
+			if res.Sandbox.Mode != SandboxModeSkipped || !strings.Contains(res.Sandbox.SkipReason, tc.want) {
+				t.Errorf("sandbox = %+v, want skipped with %q", res.Sandbox, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunPreview_RequiresCommandAndCwd(t *testing.T) {
+	if _, err := RunPreview(context.Background(), PreviewOptions{Cwd: "/tmp"}); err != ErrCommandRequired {
+		t.Errorf("expected ErrCommandRequired, got %v", err)
+	}
+	if _, err := RunPreview(context.Background(), PreviewOptions{Command: "ls"}); err == nil {
+		t.Error("expected error without cwd")
+	}
+}
+
+func TestIsFileTouching(t *testing.T) {
+	tests := map[string]bool{
+		"rm old.log":                  true,
+		"sudo mv a b":                 true,
+		"mkdir x && touch x/y":        true,
+		"git stash":                   true,
+		"git commit -m x":             false,
+		"sed -n 'w out' f":            false,
+		"tar -xf x.tar":               false,
+		"cp -t dir f":                 false,
+		"find . -exec rm {} ;":        false,
+		"ln -s / root":                false,
+		"git push origin main":        false,
+		"curl https://example.com":    false,
+		"touch a && curl example.com": false,
+		"":                            false,
+	}
+	for cmd, want := range tests {
+		if got := isFileTouching(cmd); got != want {
+			t.Errorf("isFileTouching(%q) = %v, want %v", cmd, got, want)
+		}
+	}
+}
+
+func TestSandboxEscape(t *testing.T) {
+	tests := map[string]string{
+		"rm old.log":           "",
+		"mv a/b.txt c.txt":     "",
+		"rm /tmp/x.log":        "absolute path",
+		"cp a --target-dir=/x": "absolute path",
+		"rm ../x.log":          "parent path",
+		"rm $HOME/x.log":       "shell expansion",
+		"rm ~/x.log":           "shell expansion",
+		"cd sub && rm x.log":   "changes directory",
+		"mv -t/etc f":          "in an option",
+		"truncate -r/etc/x f":  "in an option",
+	}
+	for cmd, want := range tests {
+		got := sandboxEscape(cmd)
+		if want == "" && got != "" || want != "" && !strings.Contains(got, want) {
+			t.Errorf("sandboxEscape(%q) = %q, want %q", cmd, got, want)
+		}
+	}
+}
+
+func TestPreviewEnv(t *testing.T) {
+	t.Setenv("SLB_PREVIEW_SECRET", "hunter2")
+	env := previewEnv("/scratch")
+	joined := strings.Join(env, "\n")
+	if strings.Contains(joined, "hunter2") {
+		t.Error("trial environment inherited an unrelated variable")
+	}
+	for _, want := range []string{"HOME=/scratch", "GIT_CONFIG_KEY_0=core.hooksPath"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("trial environment missing %q", want)
+		}
+	}
+}
+
+func TestPreviewResultAttachments(t *testing.T) {
+	skipped := &PreviewResult{Sandbox: skippedTrial("nope")}
+	if got := skipped.Attachments(); got != nil {
+		t.Errorf("expected no attachments for a skipped trial, got %v", got)
+	}
+
+	res := &PreviewResult{Sandbox: &SandboxTrial{
+		Mode:    SandboxModeTempCopy,
+		Output:  "done",
+		Deleted: []string{"old.log"},
+	}}
+	atts := res.Attachments()
+	if len(atts) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(atts))
+	}
+	a := atts[0]
+	if a.Metadata["source"] != "slb preview" || a.Metadata["sandbox_mode"] != SandboxModeTempCopy {
+		t.Errorf("metadata = %v", a.Metadata)
+	}
+	if a.Content != "done\n--- deleted ---\nold.log" {
+		t.Errorf("content = %q", a.Content)
+	}
+}
@@ -0,0 +1,173 @@
+// Package core implements the rate limiter's per-session request queue.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// QueueStatus describes where a queued request stands and the budget it is
+// waiting on.
+type QueueStatus struct {
+	RequestID string `json:"request_id"`
+	// Position is 1-based; position 1 is admitted next.
+	Position    int `json:"position"`
+	QueueLength int `json:"queue_length"`
+	// Pending and RecentRequests are the session's current consumption of
+	// MaxPending and MaxPerMinute.
+	Pending        int `json:"pending"`
+	MaxPending     int `json:"max_pending"`
+	RecentRequests int `json:"recent_requests"`
+	MaxPerMinute   int `json:"max_per_minute"`
+	// EstimatedAdmitAt assumes every request ahead is admitted as soon as it
+	// can be. It is nil when admission waits on reviews of pending requests,
+	// which cannot be predicted.
+	EstimatedAdmitAt *time.Time `json:"estimated_admit_at,omitempty"`
+	EstimateNote     string     `json:"estimate_note,omitempty"`
+}
+
+// QueueStatus reports a queued request's position and estimated admission,
+// or nil if the request is not waiting in a queue.
+func (rl *RateLimiter) QueueStatus(requestID string) (*QueueStatus, error) {
+	entry, err := rl.db.GetQueueEntry(requestID)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	statuses, err := rl.sessionQueueStatus(entry.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range statuses {
+		if st.RequestID == requestID {
+			return st, nil
+		}
+	}
+	return nil, nil
+}
+
+// SessionQueue reports every request waiting in a session's queue, head first.
+func (rl *RateLimiter) SessionQueue(sessionID string) ([]*QueueStatus, error) {
+	return rl.sessionQueueStatus(sessionID)
+}
+
+func (rl *RateLimiter) sessionQueueStatus(sessionID string) ([]*QueueStatus, error) {
+	cfg := rl.cfg.normalized()
+	entries, err := rl.db.ListQueue(sessionID)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	now := rl.now().UTC()
+	windowStart, err := rl.windowStart(sessionID, now)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := rl.db.CountPendingBySession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	admissions, err := rl.db.ListAdmissionTimesSince(sessionID, windowStart)
+	if err != nil {
+		return nil, err
+	}
+	estimates := estimateAdmissions(now, admissions, len(entries), cfg.MaxRequestsPerMinute)
+
+	statuses := make([]*QueueStatus, len(entries))
+	for i, e := range entries {
+		st := &QueueStatus{
+			RequestID:      e.RequestID,
+			Position:       i + 1,
+			QueueLength:    len(entries),
+			Pending:        pending,
+			MaxPending:     cfg.MaxPendingPerSession,
+			RecentRequests: len(admissions),
+			MaxPerMinute:   cfg.MaxRequestsPerMinute,
+		}
+		// Admitting position p adds p pending requests, counting those ahead.
+		if pending+i+1 > cfg.MaxPendingPerSession {
+			st.EstimateNote = fmt.Sprintf("waiting for %d pending request(s) to be reviewed", pending+i+1-cfg.MaxPendingPerSession)
+		} else {
+			at := estimates[i]
+			st.EstimatedAdmitAt = &at
+			if at.After(now) {
+				st.EstimateNote = "waiting for the per-minute window to slide"
+			}
+		}
+		statuses[i] = st
+	}
+	return statuses, nil
+}
+
+// estimateAdmissions simulates admitting n queued requests in order against
+// a sliding one-minute window already holding admissions (oldest first).
+func estimateAdmissions(now time.Time, admissions []time.Time, n, maxPerMinute int) []time.Time {
+	window := append([]time.Time(nil), admissions...)
+	sort.Slice(window, func(i, j int) bool { return window[i].Before(window[j]) })
+
+	out := make([]time.Time, n)
+	at := now
+	for i := 0; i < n; i++ {
+		for {
+			// Entries at or after at-1m count against the window.
+			idx := sort.Search(len(window), func(k int) bool { return !window[k].Before(at.Add(-time.Minute)) })
+			inWindow := len(window) - idx
+			if inWindow < maxPerMinute {
+				break
+			}
+			// Wait until enough of the oldest entries slide out; timestamps
+			// are stored to the second, hence the extra second.
+			at = window[idx+inWindow-maxPerMinute].Add(time.Minute + time.Second)
+		}
+		out[i] = at
+		window = append(window, at)
+	}
+	return out
+}
+
+// AdmitQueued admits the session's queued requests, oldest first, while the
+// session has capacity. The queue is strictly FIFO: if the head cannot be
+// admitted nothing behind it is. It returns the admitted request IDs.
+func (rl *RateLimiter) AdmitQueued(sessionID string) ([]string, error) {
+	cfg := rl.cfg.normalized()
+	var admitted []string
+	for {
+		entries, err := rl.db.ListQueue(sessionID)
+		if err != nil {
+			return admitted, err
+		}
+		if len(entries) == 0 {
+			return admitted, nil
+		}
+
+		now := rl.now().UTC()
+		windowStart, err := rl.windowStart(sessionID, now)
+		if err != nil {
+			return admitted, err
+		}
+		pending, err := rl.db.CountPendingBySession(sessionID)
+		if err != nil {
+			return admitted, err
+		}
+		recent, err := rl.db.CountRequestsSince(sessionID, windowStart)
+		if err != nil {
+			return admitted, err
+		}
+		if pending >= cfg.MaxPendingPerSession || recent >= cfg.MaxRequestsPerMinute {
+			return admitted, nil
+		}
+
+		head := entries[0].RequestID
+		if err := rl.db.AdmitQueuedRequest(head, now); err != nil {
+			// Another process admitted or cancelled the head first; re-read.
+			if errors.Is(err, db.ErrInvalidTransition) {
+				continue
+			}
+			return admitted, err
+		}
+		admitted = append(admitted, head)
+	}
+}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// newQueueFixture returns a creator whose session already has maxPending
+// pending requests, so further requests are queued.
+func newQueueFixture(t *testing.T, database *db.DB, maxPending int) (*RequestCreator, *RateLimiter, *db.Session, []*db.Request) {
+	t.Helper()
+	session := testutil.MakeSession(t, database)
+	var pending []*db.Request
+	for i := 0; i < maxPending; i++ {
+		pending = append(pending, testutil.MakeRequest(t, database, session))
+	}
+	rl := NewRateLimiter(database, RateLimitConfig{
+		MaxPendingPerSession: maxPending,
+		MaxRequestsPerMinute: 100,
+		Action:               RateLimitActionQueue,
+	})
+	return NewRequestCreator(database, rl, nil, nil), rl, session, pending
+}
+
+func queueRequest(t *testing.T, creator *RequestCreator, sessionID, command string) *db.Request {
+	t.Helper()
+	result, err := creator.CreateRequest(CreateRequestOptions{SessionID: sessionID, Command: command})
+	if err != nil {
+		t.Fatalf("CreateRequest(%q): %v", command, err)
+	}
+	if !result.Queued {
+		t.Fatalf("CreateRequest(%q) was not queued (status %s)", command, result.Request.Status)
+	}
+	return result.Request
+}
+
+func assertQueueOrder(t *testing.T, rl *RateLimiter, sessionID string, want ...string) {
+	t.Helper()
+	statuses, err := rl.SessionQueue(sessionID)
+	if err != nil {
+		t.Fatalf("SessionQueue: %v", err)
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("queue length = %d, want %d", len(statuses), len(want))
+	}
+	for i, st := range statuses {
+		if st.RequestID != want[i] || st.Position != i+1 || st.QueueLength != len(want) {
+			t.Errorf("queue[%d] = %s position %d of %d, want %s position %d of %d",
+				i, st.RequestID, st.Position, st.QueueLength, want[i], i+1, len(want))
+		}
+	}
+}
+
+func TestAdmitQueued_FIFO(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	creator, rl, session, pending := newQueueFixture(t, database, 2)
+
+	a := queueRequest(t, creator, session.ID, "rm -rf /tmp/a")
+	b := queueRequest(t, creator, session.ID, "rm -rf /tmp/b")
+	c := queueRequest(t, creator, session.ID, "rm -rf /tmp/c")
+	assertQueueOrder(t, rl, session.ID, a.ID, b.ID, c.ID)
+
+	// Nothing fits yet.
+	if admitted, err := rl.AdmitQueued(session.ID); err != nil || len(admitted) != 0 {
+		t.Fatalf("AdmitQueued with no capacity = %v, %v", admitted, err)
+	}
+
+	// One slot frees: only the head is admitted.
+	if err := database.UpdateRequestStatus(pending[0].ID, db.StatusApproved); err != nil {
+		t.Fatalf("approving: %v", err)
+	}
+	admitted, err := rl.AdmitQueued(session.ID)
+	if err != nil {
+		t.Fatalf("AdmitQueued: %v", err)
+	}
+	if len(admitted) != 1 || admitted[0] != a.ID {
+		t.Fatalf("admitted = %v, want [%s]", admitted, a.ID)
+	}
+	assertQueueOrder(t, rl, session.ID, b.ID, c.ID)
+
+	// The transition is recorded and the approval timeout restarts.
+	got, err := database.GetRequest(a.ID)
+	if err != nil {
+		t.Fatalf("GetRequest: %v", err)
+	}
+	if got.Status != db.StatusPending {
+		t.Errorf("admitted status = %s, want pending", got.Status)
+	}
+	entry, err := database.GetQueueEntry(a.ID)
+	if err != nil || entry == nil {
+		t.Fatalf("GetQueueEntry: %v, %v", entry, err)
+	}
+	if entry.AdmittedAt == nil || entry.CancelledAt != nil {
+		t.Errorf("queue entry admitted=%v cancelled=%v, want admitted only", entry.AdmittedAt, entry.CancelledAt)
+	}
+	timeout := time.Duration(DefaultRequestCreatorConfig().RequestTimeoutMinutes) * time.Minute
+	if got.ExpiresAt == nil || got.ExpiresAt.Sub(*entry.AdmittedAt) != timeout {
+		t.Errorf("expires_at %v, want admission %v + %s", got.ExpiresAt, entry.AdmittedAt, timeout)
+	}
+}
+
+func TestAdmitQueued_CancelFreesSlotAndShiftsQueue(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	creator, rl, session, pending := newQueueFixture(t, database, 1)
+
+	a := queueRequest(t, creator, session.ID, "rm -rf /tmp/a")
+	b := queueRequest(t, creator, session.ID, "rm -rf /tmp/b")
+	c := queueRequest(t, creator, session.ID, "rm -rf /tmp/c")
+
+	// Cancelling from the middle moves everyone behind it up at once.
+	if err := database.UpdateRequestStatus(b.ID, db.StatusCancelled); err != nil {
+		t.Fatalf("cancelling queued request: %v", err)
+	}
+	assertQueueOrder(t, rl, session.ID, a.ID, c.ID)
+	entry, err := database.GetQueueEntry(b.ID)
+	if err != nil || entry == nil || entry.CancelledAt == nil || entry.AdmittedAt != nil {
+		t.Fatalf("cancelled queue entry = %+v, %v", entry, err)
+	}
+
+	// Cancelling the head hands its place to the next request.
+	if err := database.UpdateRequestStatus(a.ID, db.StatusCancelled); err != nil {
+		t.Fatalf("cancelling head: %v", err)
+	}
+	st, err := rl.QueueStatus(c.ID)
+	if err != nil || st == nil || st.Position != 1 {
+		t.Fatalf("QueueStatus after head cancelled = %+v, %v", st, err)
+	}
+
+	// Cancelling a pending request frees a slot for the queue.
+	if err := database.UpdateRequestStatus(pending[0].ID, db.StatusCancelled); err != nil {
+		t.Fatalf("cancelling pending: %v", err)
+	}
+	admitted, err := rl.AdmitQueued(session.ID)
+	if err != nil || len(admitted) != 1 || admitted[0] != c.ID {
+		t.Fatalf("AdmitQueued = %v, %v; want [%s]", admitted, err, c.ID)
+	}
+
+	// Requests cancelled while queued never used the per-minute budget.
+	recent, err := database.CountRequestsSince(session.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("CountRequestsSince: %v", err)
+	}
+	if recent != 2 { // pending[0] and c
+		t.Errorf("CountRequestsSince = %d, want 2", recent)
+	}
+}
+
+func TestCreateRequest_NewcomerWaitsBehindQueue(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	creator, rl, session, pending := newQueueFixture(t, database, 1)
+
+	a := queueRequest(t, creator, session.ID, "rm -rf /tmp/a")
+	if err := database.UpdateRequestStatus(pending[0].ID, db.StatusApproved); err != nil {
+		t.Fatalf("approving: %v", err)
+	}
+
+	// A slot is free, but a is ahead: CheckRateLimit must not let a newcomer in.
+	check, err := rl.CheckRateLimit(session.ID)
+	if err != nil {
+		t.Fatalf("CheckRateLimit: %v", err)
+	}
+	if check.Allowed || check.Queued != 1 {
+		t.Errorf("CheckRateLimit allowed=%v queued=%d, want blocked behind 1", check.Allowed, check.Queued)
+	}
+
+	// Creating the newcomer admits a first, then queues the newcomer.
+	b := queueRequest(t, creator, session.ID, "rm -rf /tmp/b")
+	got, err := database.GetRequest(a.ID)
+	if err != nil {
+		t.Fatalf("GetRequest: %v", err)
+	}
+	if got.Status != db.StatusPending {
+		t.Errorf("head status = %s, want pending", got.Status)
+	}
+	assertQueueOrder(t, rl, session.ID, b.ID)
+}
+
+func TestQueue_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	first, err := db.OpenAndMigrate(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	creator, _, session, _ := newQueueFixture(t, first, 1)
+	a := queueRequest(t, creator, session.ID, "rm -rf /tmp/a")
+	b := queueRequest(t, creator, session.ID, "rm -rf /tmp/b")
+	if err := first.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	second := testutil.NewTestDBAtPath(t, path)
+	rl := NewRateLimiter(second, RateLimitConfig{MaxPendingPerSession: 1, Action: RateLimitActionQueue})
+	assertQueueOrder(t, rl, session.ID, a.ID, b.ID)
+}
+
+func TestEstimateAdmissions(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	admissions := []time.Time{
+		now.Add(-10 * time.Second),
+		now.Add(-50 * time.Second),
+		now.Add(-40 * time.Second),
+	}
+
+	tests := []struct {
+		name string
+		max  int
+		want []time.Duration
+	}{
+		{"room for all", 10, []time.Duration{0, 0}},
+		{"one slot left", 4, []time.Duration{0, 11 * time.Second}},
+		// The fourth request waits for the first queued admission to slide out.
+		{"window full", 3, []time.Duration{11 * time.Second, 21 * time.Second, 51 * time.Second, 72 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := estimateAdmissions(now, admissions, len(tt.want), tt.max)
+			for i, want := range tt.want {
+				if !got[i].Equal(now.Add(want)) {
+					t.Errorf("admission %d at %s, want now+%s", i+1, got[i].Sub(now), want)
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -60,9 +61,16 @@ type RateLimitResult struct {
 	RemainingPending   int             `json:"remaining_pending"`
 	RemainingPerMinute int             `json:"remaining_per_minute"`
 	ResetAt            time.Time       `json:"reset_at"`
-	Message            string          `json:"message,omitempty"`
+	// Queued is the number of the session's requests already waiting in the
+	// queue (Action == "queue" only); a new request goes behind them.
+	Queued  int    `json:"queued,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
+// ErrRateLimited matches any request refused because a session exceeded its
+// rate limits, whether surfaced as a RateLimitError or a blocked result.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
 // RateLimitError is returned when Action == "reject" and limits are exceeded.
 type RateLimitError struct {
 	SessionID    string
@@ -93,6 +101,11 @@ func (e *RateLimitError) Error() string {
 	return parts
 }
 
+// Is reports whether target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
 // RateLimiter enforces per-session request rate limits.
 type RateLimiter struct {
 	db  *db.DB
@@ -126,13 +139,9 @@ func (rl *RateLimiter) CheckRateLimit(sessionID string) (*RateLimitResult, error
 	}
 	cfg := rl.cfg.normalized()
 
-	now := rl.now().UTC()
-	windowStart := now.Add(-time.Minute)
-
-	if resetAt, err := rl.db.GetSessionRateLimitResetAt(sessionID); err != nil {
+	windowStart, err := rl.windowStart(sessionID, rl.now().UTC())
+	if err != nil {
 		return nil, err
-	} else if resetAt != nil && resetAt.After(windowStart) {
-		windowStart = resetAt.UTC()
 	}
 
 	pending, err := rl.db.CountPendingBySession(sessionID)
@@ -164,18 +173,34 @@ func (rl *RateLimiter) CheckRateLimit(sessionID string) (*RateLimitResult, error
 		}
 	}
 
+	// Requests already queued go first, so a newcomer must wait behind them
+	// even if a slot is free right now.
+	queued := 0
+	if cfg.Action == RateLimitActionQueue {
+		entries, err := rl.db.ListQueue(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		queued = len(entries)
+	}
+
 	result := &RateLimitResult{
 		Allowed:            true,
 		Action:             cfg.Action,
 		RemainingPending:   remainingPending,
 		RemainingPerMinute: remainingPerMinute,
 		ResetAt:            resetAt,
+		Queued:             queued,
 		Message:            "ok",
 	}
 
 	blockedPending := pending >= cfg.MaxPendingPerSession
 	blockedPerMinute := recent >= cfg.MaxRequestsPerMinute
 	if !blockedPending && !blockedPerMinute {
+		if queued > 0 {
+			result.Allowed = false
+			result.Message = fmt.Sprintf("%d request(s) already queued", queued)
+		}
 		return result, nil
 	}
 
@@ -209,3 +234,17 @@ func (rl *RateLimiter) CheckRateLimit(sessionID string) (*RateLimitResult, error
 		}
 	}
 }
+
+// windowStart returns the start of the per-minute window ending at now,
+// moved forward to the session's last limit reset if that is more recent.
+func (rl *RateLimiter) windowStart(sessionID string, now time.Time) (time.Time, error) {
+	windowStart := now.Add(-time.Minute)
+	resetAt, err := rl.db.GetSessionRateLimitResetAt(sessionID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if resetAt != nil && resetAt.After(windowStart) {
+		windowStart = resetAt.UTC()
+	}
+	return windowStart, nil
+}
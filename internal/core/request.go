@@ -2,10 +2,10 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/db"
@@ -27,10 +27,33 @@ type CreateRequestOptions struct {
 	Justification Justification
 	// Attachments are optional context files.
 	Attachments []db.Attachment
+	// ContextAttachments are evidence SLB collected itself (e.g. slb preview
+	// output). They may use the attachment budget reserved for context.
+	ContextAttachments []db.Attachment
 	// RedactPatterns are custom patterns to redact from display.
 	RedactPatterns []string
 	// ProjectPath overrides the project path (defaults to session's project).
 	ProjectPath string
+	// DryRun is pre-gathered dry-run evidence (e.g. from slb preview).
+	DryRun *db.DryRunResult
+	// ForceReview creates a caution-tier request for commands that would
+	// otherwise be skipped as safe (e.g. a promoted preview).
+	ForceReview bool
+	// Labels are key/value annotations (env=prod, team=payments) used for
+	// history filtering and notification routing.
+	Labels map[string]string
+	// TimeoutSecs is how long the caller will wait for a decision
+	// (--timeout). It is clamped to the tier's TimeoutBounds and recorded on
+	// the request. Nil when the caller does not wait.
+	TimeoutSecs *int
+	// Preview runs the command's dry-run variant before the request is
+	// created and attaches its output as context (slb run --preview).
+	Preview bool
+	// AutoPreview does the same for DANGEROUS and CRITICAL commands.
+	AutoPreview bool
+	// CampaignID groups the request with related ones (slb campaign). The
+	// campaign must belong to the request's project.
+	CampaignID string
 }
 
 // CreateRequestResult holds the result of creating a request.
@@ -43,6 +66,21 @@ type CreateRequestResult struct {
 	SkipReason string
 	// Classification is the risk classification result.
 	Classification *MatchResult
+	// Queued indicates the rate limiter (action "queue") held the request;
+	// it becomes pending once the session has capacity.
+	Queued bool
+	// Queue is the request's place in the queue when Queued is set.
+	Queue *QueueStatus
+	// Trust is the trust entry for the script the command runs, if any,
+	// including one that no longer holds and so was not applied.
+	Trust *TrustVerification
+	// NoopReason is set when a dry run showed the command would change
+	// nothing and general.dry_run_noop_action resolved it without review:
+	// the request was created approved, or skipped.
+	NoopReason string
+	// Preview is the dry-run preview attached to the request, or nil if
+	// none was run or the command has no dry-run variant.
+	Preview *db.Attachment
 }
 
 // Request creation errors.
@@ -55,6 +93,9 @@ var (
 	ErrSessionNotFound = errors.New("session not found")
 	// ErrSessionInactive is returned when the session has ended.
 	ErrSessionInactive = errors.New("session is no longer active")
+	// ErrSessionStale is returned when a reviewer's session has not been
+	// seen within the idle window since its key was issued.
+	ErrSessionStale = errors.New("session has gone stale; send a heartbeat or resume the session")
 	// ErrAgentBlocked is returned when the agent is blocked from creating requests.
 	ErrAgentBlocked = errors.New("agent is blocked from creating requests")
 )
@@ -72,10 +113,19 @@ type RequestCreator struct {
 type RequestCreatorConfig struct {
 	// BlockedAgents is a list of agent names that cannot create requests.
 	BlockedAgents []string
+	// AgentPolicy decides which agents may create requests: AgentPolicyOpen,
+	// AgentPolicyAllowlist or AgentPolicyDenylist (default).
+	AgentPolicy string
+	// AllowedAgents are the agents allowed to create requests under
+	// AgentPolicyAllowlist.
+	AllowedAgents []string
 	// DynamicQuorumEnabled enables dynamic quorum adjustment.
 	DynamicQuorumEnabled bool
 	// DynamicQuorumFloor is the minimum approvals even with dynamic quorum.
 	DynamicQuorumFloor int
+	// SessionIdleTimeout is how recently a session must have been seen to
+	// count toward dynamic quorum (0 counts every active session).
+	SessionIdleTimeout time.Duration
 	// RequestTimeoutMinutes is the default timeout for pending requests.
 	RequestTimeoutMinutes int
 	// ApprovalTTLMinutes is the default TTL for approvals (dangerous tier).
@@ -88,12 +138,82 @@ type RequestCreatorConfig struct {
 	AgentMailThread string
 	// AgentMailSender optional sender name.
 	AgentMailSender string
+	// AgentMailRoutes route notifications to other threads by request
+	// labels ("env=prod:Prod-Reviews"); invalid rules are ignored.
+	AgentMailRoutes []string
+	// ContextPinningFamilies lists command families (kubectl, aws, gcloud)
+	// whose active context is captured and pinned for execution.
+	ContextPinningFamilies []string
+	// SelfProtectionAction is what happens to commands that target SLB's own
+	// state: SelfProtectionCritical (default) or SelfProtectionRefuse.
+	SelfProtectionAction string
+	// MigrationGlobs are extra patterns locating migration files for
+	// migration-runner commands (alembic, flyway, migrate).
+	MigrationGlobs []string
+	// MigrationMaxAttachmentBytes caps attached migration contents (0 uses
+	// the default, negative attaches only the summary).
+	MigrationMaxAttachmentBytes int
+	// DryRunWithholdTiers are tiers whose dry-run output is not stored;
+	// reviewers see only the dry-run command. Output for other tiers is
+	// stored redacted.
+	DryRunWithholdTiers []RiskTier
+	// RequireDifferentHostTiers are tiers whose approvals only count from
+	// sessions on a different host than the requestor's.
+	RequireDifferentHostTiers []RiskTier
+	// RequireDifferentProgramTiers are tiers whose approvals must come from
+	// sessions running a different program than the requestor's.
+	RequireDifferentProgramTiers []RiskTier
+	// RequiredRoles are the reviewer roles each tier's requests need an
+	// approval from, on top of MinApprovals.
+	RequiredRoles map[RiskTier][]string
+	// TimeoutBounds limit the requestor's wait timeout per tier. Tiers
+	// without an entry are unbounded.
+	TimeoutBounds map[RiskTier]TimeoutBounds
+	// Attachments bounds the request's total attachment bytes through its
+	// MaxTotalAttachmentBytes and ReservedContextBytes (zero is unlimited).
+	Attachments AttachmentConfig
+	// RiskOverrides replace the built-in tier of matching commands; the
+	// rule that fired is recorded in the request's tier reason.
+	RiskOverrides []RiskOverrideRule
+	// TrustedScriptFloor is the lowest tier a trusted script can lower a
+	// command to (default CAUTION).
+	TrustedScriptFloor RiskTier
+	// DryRunNoopAction resolves non-CRITICAL commands whose dry run shows no
+	// effect: DryRunNoopReview (default), DryRunNoopAutoApprove or
+	// DryRunNoopSkip.
+	DryRunNoopAction string
+	// RejectionCooldownSecs is how long after a rejection the same session
+	// cannot re-request the same command (0 disables the cool-down).
+	RejectionCooldownSecs int
+	// RejectionCooldownAction is RejectionCooldownRefuse (default) or
+	// RejectionCooldownJustify.
+	RejectionCooldownAction string
+}
+
+// TimeoutBounds is the allowed range for a requestor's wait timeout. A zero
+// bound is not enforced.
+type TimeoutBounds struct {
+	MinSecs int
+	MaxSecs int
+}
+
+// Clamp returns secs limited to the bounds and whether it was changed.
+func (b TimeoutBounds) Clamp(secs int) (int, bool) {
+	clamped := secs
+	if b.MinSecs > 0 && clamped < b.MinSecs {
+		clamped = b.MinSecs
+	}
+	if b.MaxSecs > 0 && clamped > b.MaxSecs {
+		clamped = b.MaxSecs
+	}
+	return clamped, clamped != secs
 }
 
 // DefaultRequestCreatorConfig returns the default configuration.
 func DefaultRequestCreatorConfig() *RequestCreatorConfig {
 	return &RequestCreatorConfig{
 		BlockedAgents:              []string{},
+		AgentPolicy:                AgentPolicyDenylist,
 		DynamicQuorumEnabled:       false,
 		DynamicQuorumFloor:         1,
 		RequestTimeoutMinutes:      30,
@@ -102,6 +222,12 @@ func DefaultRequestCreatorConfig() *RequestCreatorConfig {
 		AgentMailEnabled:           true,
 		AgentMailThread:            "SLB-Reviews",
 		AgentMailSender:            "SLB-System",
+		ContextPinningFamilies:     []string{db.ContextFamilyKubectl, db.ContextFamilyAWS, db.ContextFamilyGCloud},
+		SelfProtectionAction:       SelfProtectionCritical,
+		Attachments:                DefaultAttachmentConfig(),
+		TrustedScriptFloor:         RiskTierCaution,
+		DryRunNoopAction:           DryRunNoopReview,
+		RejectionCooldownAction:    RejectionCooldownRefuse,
 	}
 }
 
@@ -134,6 +260,14 @@ func (rc *RequestCreator) CreateRequest(opts CreateRequestOptions) (*CreateReque
 	if opts.Command == "" {
 		return nil, ErrCommandRequired
 	}
+	if err := db.ValidateLabels(opts.Labels); err != nil {
+		return nil, err
+	}
+	if rc.config.Attachments.StrictRedaction {
+		if err := ValidateRedactionPatterns(opts.RedactPatterns); err != nil {
+			return nil, err
+		}
+	}
 
 	// Step 1: Validate session exists and is active
 	session, err := rc.db.GetSession(opts.SessionID)
@@ -146,31 +280,73 @@ func (rc *RequestCreator) CreateRequest(opts CreateRequestOptions) (*CreateReque
 	if session.EndedAt != nil {
 		return nil, ErrSessionInactive
 	}
+	// Submitting a request counts as being seen.
+	if err := rc.db.UpdateSessionHeartbeat(session.ID); err != nil {
+		return nil, fmt.Errorf("recording session activity: %w", err)
+	}
 
 	// Initialize notifier with project context if enabled.
-	notifier := rc.notifier
-	if rc.config != nil && rc.config.AgentMailEnabled {
-		notifier = integrations.NewAgentMailClient(session.ProjectPath, rc.config.AgentMailThread, rc.config.AgentMailSender)
-	}
+	notifier := rc.notifierFor(session.ProjectPath)
 
-	// Step 2: Check agent not blocked
-	if rc.isAgentBlocked(session.AgentName) {
-		return nil, fmt.Errorf("%w: %s", ErrAgentBlocked, session.AgentName)
+	// Step 2: Check the agent policy allows the agent to request
+	if err := rc.checkAgentPolicy(session.AgentName); err != nil {
+		return nil, err
 	}
 
-	// Step 3: Check rate limits
+	// Step 3: Check rate limits, first admitting anything already queued
+	// whose slot has freed up so the queue drains in order.
+	if _, err := rc.AdmitQueued(opts.SessionID); err != nil {
+		return nil, fmt.Errorf("admitting queued requests: %w", err)
+	}
 	// CheckRateLimit returns an error when Action=reject and limits are exceeded
 	limitResult, err := rc.rateLimiter.CheckRateLimit(opts.SessionID)
 	if err != nil {
 		return nil, err
 	}
-	if !limitResult.Allowed {
-		// Enforce block for actions that return Allowed=false (like queue, if not handled)
-		return nil, fmt.Errorf("rate limit exceeded (action=%s): %s", limitResult.Action, limitResult.Message)
+	queued := !limitResult.Allowed && limitResult.Action == RateLimitActionQueue
+	if !limitResult.Allowed && !queued {
+		return nil, fmt.Errorf("%w (action=%s): %s", ErrRateLimited, limitResult.Action, limitResult.Message)
 	}
 
-	// Step 4: Classify command
-	classification := rc.patternEngine.ClassifyCommand(opts.Command, opts.Cwd)
+	// Determine project path
+	projectPath := opts.ProjectPath
+	if projectPath == "" {
+		projectPath = session.ProjectPath
+	}
+	if opts.CampaignID != "" {
+		campaign, err := rc.db.GetCampaign(opts.CampaignID)
+		if err != nil {
+			if errors.Is(err, db.ErrCampaignNotFound) {
+				return nil, fmt.Errorf("%w: %s", db.ErrCampaignNotFound, opts.CampaignID)
+			}
+			return nil, fmt.Errorf("getting campaign: %w", err)
+		}
+		if campaign.ProjectPath != projectPath {
+			return nil, fmt.Errorf("%w in project %s: %s", db.ErrCampaignNotFound, projectPath, opts.CampaignID)
+		}
+	}
+
+	// Step 4: Classify command, apply configured risk overrides, and review
+	// a vetted script one tier lighter while its contents still match the
+	// trust entry
+	classified, err := rc.classify(opts.Command, opts.Cwd, projectPath)
+	if err != nil {
+		return nil, err
+	}
+	classification, tierReason := classified.match, classified.reason
+	trust, trustUse := classified.trust, classified.trustUse
+	if classification.MatchedPattern == SelfProtectionPattern && rc.config.SelfProtectionAction == SelfProtectionRefuse {
+		return nil, ErrSelfProtection
+	}
+	if opts.ForceReview && !classification.NeedsApproval {
+		forced := *classification
+		forced.Tier = RiskTierCaution
+		forced.MinApprovals = tierApprovals(RiskTierCaution)
+		forced.NeedsApproval = true
+		forced.IsSafe = false
+		classification = &forced
+		tierReason += "; review requested explicitly"
+	}
 
 	// Step 5: If SAFE, skip
 	if classification.IsSafe {
@@ -179,6 +355,7 @@ func (rc *RequestCreator) CreateRequest(opts CreateRequestOptions) (*CreateReque
 			Skipped:        true,
 			SkipReason:     "Command is classified as safe and does not require approval",
 			Classification: classification,
+			Trust:          trust,
 		}, nil
 	}
 
@@ -189,50 +366,133 @@ func (rc *RequestCreator) CreateRequest(opts CreateRequestOptions) (*CreateReque
 			Skipped:        true,
 			SkipReason:     "Command does not match any dangerous patterns",
 			Classification: classification,
+			Trust:          trust,
 		}, nil
 	}
 
+	// Step 5b: Resolve a command whose dry run shows it would change nothing
+	// (general.dry_run_noop_action; never CRITICAL)
+	noopDryRun, noopReason := rc.checkDryRunNoop(opts, classification.Tier)
+	if noopReason != "" && rc.config.DryRunNoopAction == DryRunNoopSkip {
+		return &CreateRequestResult{
+			Request:        nil,
+			Skipped:        true,
+			SkipReason:     "Dry run shows no effect: " + noopReason,
+			Classification: classification,
+			Trust:          trust,
+			NoopReason:     noopReason,
+		}, nil
+	}
+	autoApprove := noopReason != "" && rc.config.DryRunNoopAction == DryRunNoopAutoApprove && !queued &&
+		!HasRuntimeTargets(opts.Command)
+
 	// Step 6: Parse command to argv
 	argv, _ := ParseCommandToArgv(opts.Command)
 
-	// Step 7: Build command spec (hash computed by db.CreateRequest)
+	// Step 7: Build command spec (hash computed by db.CreateRequest). The
+	// normalization is stored so display and similarity never recompute it.
 	cmdSpec := db.CommandSpec{
-		Raw:   opts.Command,
-		Argv:  argv,
-		Cwd:   opts.Cwd,
-		Shell: opts.Shell,
+		Raw:                opts.Command,
+		Argv:               argv,
+		Cwd:                opts.Cwd,
+		Shell:              opts.Shell,
+		NormalizedSegments: NormalizeCommand(opts.Command).Segments,
 	}
 
-	// Step 8: Apply redaction
+	// Step 8: Apply redaction (the summary is built from the redacted form)
 	cmdSpec.DisplayRedacted = ApplyRedaction(opts.Command, opts.RedactPatterns)
 	cmdSpec.ContainsSensitive = cmdSpec.DisplayRedacted != opts.Command
+	cmdSpec.Summary = SummarizeCommand(cmdSpec.DisplayRedacted)
+
+	// Step 9: Capture the cluster/cloud context the command targets
+	pinned := CaptureContext(context.Background(), cmdSpec, rc.config.ContextPinningFamilies)
+
+	// Step 9b: Bind the migration files a migration runner will apply, so
+	// reviewers see the DDL and swapped files fail the hash check
+	migrations, migrationAttachments, err := CaptureMigrations(cmdSpec, MigrationOptions{
+		Globs:              rc.config.MigrationGlobs,
+		MaxAttachmentBytes: rc.config.MigrationMaxAttachmentBytes,
+		RedactPatterns:     opts.RedactPatterns,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("capturing migrations: %w", err)
+	}
+	attachments := opts.Attachments
+	if migrations != nil {
+		cmdSpec.MigrationsDigest = migrations.Digest
+	}
+
+	// Step 9b2: Refuse a re-request of a command this session just had
+	// rejected (general.rejection_cooldown_seconds)
+	if err := rc.checkRejectionCooldown(opts.SessionID, cmdSpec, opts.Justification, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	// Step 9c: Attach the dry-run variant's output; a failed preview is
+	// attached marked as failed rather than blocking the request
+	contextAttachments := opts.ContextAttachments
+	var preview *db.Attachment
+	if wantsDryRunPreview(opts, classification.Tier) {
+		previewConfig := rc.config.Attachments
+		previewConfig.RedactPatterns = append(append([]string{}, previewConfig.RedactPatterns...), opts.RedactPatterns...)
+		preview = RunDryRunPreview(context.Background(), opts.Command, opts.Cwd, &previewConfig)
+		if preview != nil {
+			contextAttachments = append(append([]db.Attachment{}, contextAttachments...), *preview)
+		}
+	}
+	if len(contextAttachments) > 0 || len(migrationAttachments) > 0 {
+		attachments = append(append([]db.Attachment{}, opts.Attachments...), MarkAutoCollected(contextAttachments)...)
+		attachments = append(attachments, MarkAutoCollected(migrationAttachments)...)
+	}
+
+	// Step 9d: Redact dry-run evidence (e.g. Secret manifests) before
+	// reviewers see it, withholding it entirely for configured tiers
+	dryRunEvidence := opts.DryRun
+	if dryRunEvidence == nil {
+		dryRunEvidence = noopDryRun
+	}
+	dryRun := PrepareDryRun(dryRunEvidence, classification.Tier, rc.config.DryRunWithholdTiers, opts.RedactPatterns)
+
+	// Step 9e: Enforce the total attachment budget, naming the attachment
+	// that goes over it, then the quota with dry-run output included
+	budget := make([]*db.Attachment, len(attachments))
+	for i := range attachments {
+		budget[i] = &attachments[i]
+	}
+	if err := ValidateAttachmentBudget(budget, rc.config.Attachments.MaxTotalAttachmentBytes); err != nil {
+		return nil, err
+	}
+	if err := CheckAttachmentQuota(attachments, dryRun, &rc.config.Attachments); err != nil {
+		return nil, err
+	}
 
-	// Step 9: Get min approvals (with dynamic quorum check)
+	// Step 10: Get min approvals (with dynamic quorum check)
 	minApprovals := classification.MinApprovals
 	if rc.config.DynamicQuorumEnabled {
 		minApprovals = rc.checkDynamicQuorum(classification.Tier, minApprovals, opts.ProjectPath)
 	}
 
-	// Step 10: Set expiry times
+	// Step 11: Set expiry times
 	now := time.Now().UTC()
 	requestExpiry := now.Add(time.Duration(rc.config.RequestTimeoutMinutes) * time.Minute)
 
-	// Determine project path
-	projectPath := opts.ProjectPath
-	if projectPath == "" {
-		projectPath = session.ProjectPath
-	}
-
-	// Step 11: Create request in DB
+	// Step 12: Create request in DB
 	request := &db.Request{
 		ProjectPath:        projectPath,
 		Command:            cmdSpec,
 		RiskTier:           classification.Tier,
+		TierReason:         tierReason,
 		RequestorSessionID: opts.SessionID,
 		RequestorAgent:     session.AgentName,
 		RequestorModel:     session.Model,
+		RequestorProgram:   session.Program,
 		Justification:      opts.Justification,
-		Attachments:        opts.Attachments,
+		Labels:             opts.Labels,
+		CampaignID:         opts.CampaignID,
+		Attachments:        attachments,
+		DryRun:             dryRun,
+		PinnedContext:      pinned,
+		Migrations:         migrations,
 		Status:             db.StatusPending,
 		MinApprovals:       minApprovals,
 		ExpiresAt:          &requestExpiry,
@@ -242,44 +502,221 @@ func (rc *RequestCreator) CreateRequest(opts CreateRequestOptions) (*CreateReque
 	if classification.Tier == RiskTierCritical {
 		request.RequireDifferentModel = true
 	}
+	for _, t := range rc.config.RequireDifferentHostTiers {
+		if t == classification.Tier {
+			request.RequireDifferentHost = true
+			break
+		}
+	}
+	for _, t := range rc.config.RequireDifferentProgramTiers {
+		if t == classification.Tier {
+			request.RequireDifferentProgram = true
+			break
+		}
+	}
+	if roles := rc.config.RequiredRoles[classification.Tier]; len(roles) > 0 {
+		request.RequiredRoles = append([]string(nil), roles...)
+	}
+	// Keep the requestor's wait within the tier's bounds, remembering what
+	// was asked for.
+	if opts.TimeoutSecs != nil {
+		request.TimeoutSecs, _ = rc.config.TimeoutBounds[classification.Tier].Clamp(*opts.TimeoutSecs)
+		request.TimeoutRequestedSecs = *opts.TimeoutSecs
+	}
+	// A queued request waits for capacity before reviewers see it.
+	if queued {
+		request.Status = db.StatusQueued
+	}
+	// A no-op command is approved on the dry run's evidence, with the
+	// finding recorded where reviewers look for why a tier was assigned.
+	if autoApprove {
+		approvalExpiry := now.Add(time.Duration(rc.config.ApprovalTTLMinutes) * time.Minute)
+		request.Status = db.StatusApproved
+		request.ApprovalExpiresAt = &approvalExpiry
+		request.TierReason += "; auto-approved: " + noopReason
+	}
 
 	if err := rc.db.CreateRequest(request); err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
+	if trustUse != nil {
+		trustUse.RequestID = request.ID
+		if err := rc.db.RecordTrustedScriptUse(trustUse); err != nil {
+			return nil, err
+		}
+	}
+
+	if queued {
+		queue, err := rc.rateLimiter.QueueStatus(request.ID)
+		if err != nil {
+			return nil, fmt.Errorf("reading queue position: %w", err)
+		}
+		return &CreateRequestResult{
+			Request:        request,
+			Classification: classification,
+			Trust:          trust,
+			Queued:         true,
+			Queue:          queue,
+			Preview:        preview,
+		}, nil
+	}
 
-	// Step 12: Notify via Agent Mail (best effort; errors ignored)
+	if autoApprove {
+		return &CreateRequestResult{
+			Request:        request,
+			Classification: classification,
+			Trust:          trust,
+			NoopReason:     noopReason,
+			Preview:        preview,
+		}, nil
+	}
+
+	// Step 13: Notify via Agent Mail (best effort; errors ignored)
 	_ = notifier.NotifyNewRequest(request)
 
-	// Step 12: (TODO) Materialize JSON file in .slb/pending/
+	// Step 14: (TODO) Materialize JSON file in .slb/pending/
 	// This will be implemented when file materialization is needed
 
 	return &CreateRequestResult{
 		Request:        request,
 		Skipped:        false,
 		Classification: classification,
+		Trust:          trust,
+		Preview:        preview,
 	}, nil
 }
 
-// isAgentBlocked checks if an agent is in the blocked list.
-func (rc *RequestCreator) isAgentBlocked(agentName string) bool {
-	for _, blocked := range rc.config.BlockedAgents {
-		if strings.EqualFold(blocked, agentName) {
-			return true
+// AdmitQueued admits a session's queued requests that now fit within its
+// rate limits (see RateLimiter.AdmitQueued) and notifies reviewers of each,
+// as CreateRequest does for requests that were never queued.
+func (rc *RequestCreator) AdmitQueued(sessionID string) ([]*db.Request, error) {
+	ids, err := rc.rateLimiter.AdmitQueued(sessionID)
+	var admitted []*db.Request
+	for _, id := range ids {
+		request, getErr := rc.db.GetRequest(id)
+		if getErr != nil {
+			continue
 		}
+		_ = rc.notifierFor(request.ProjectPath).NotifyNewRequest(request)
+		admitted = append(admitted, request)
 	}
-	return false
+	return admitted, err
+}
+
+// AdmitProjectQueued runs AdmitQueued for every session with requests queued
+// in projectPath, in the order the sessions' oldest requests were queued.
+func (rc *RequestCreator) AdmitProjectQueued(projectPath string) ([]*db.Request, error) {
+	queued, err := rc.db.ListQueuedRequests(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	var admitted []*db.Request
+	seen := make(map[string]bool)
+	for _, r := range queued {
+		if seen[r.RequestorSessionID] {
+			continue
+		}
+		seen[r.RequestorSessionID] = true
+		reqs, err := rc.AdmitQueued(r.RequestorSessionID)
+		admitted = append(admitted, reqs...)
+		if err != nil {
+			return admitted, err
+		}
+	}
+	return admitted, nil
+}
+
+// notifierFor returns the notifier for requests in projectPath.
+func (rc *RequestCreator) notifierFor(projectPath string) integrations.RequestNotifier {
+	if rc.config != nil && rc.config.AgentMailEnabled {
+		client := integrations.NewAgentMailClient(projectPath, rc.config.AgentMailThread, rc.config.AgentMailSender)
+		if routes, err := integrations.ParseLabelRoutes(rc.config.AgentMailRoutes); err == nil {
+			client.WithRoutes(routes)
+		}
+		return client
+	}
+	return rc.notifier
+}
+
+// classifiedCommand is a command's tier as CreateRequest decides it.
+type classifiedCommand struct {
+	match *MatchResult
+	// reason is the tier reason, with a note for each override or trusted
+	// script considered.
+	reason string
+	// override is the risk_overrides rule matching the command, if any.
+	override string
+	trust    *TrustVerification
+	// trustUse records the trusted script that lowered the tier, if one did.
+	trustUse *db.TrustedScriptUse
 }
 
-// checkDynamicQuorum adjusts min approvals based on active sessions.
+// classify classifies command: the pattern tiers, then the configured risk
+// overrides, then a trusted script's lowering. Trusted scripts are only
+// looked up when rc has a database.
+func (rc *RequestCreator) classify(command, cwd, projectPath string) (*classifiedCommand, error) {
+	overrides, err := CompileRiskOverrides(rc.config.RiskOverrides)
+	if err != nil {
+		return nil, err
+	}
+	c := &classifiedCommand{match: rc.patternEngine.ClassifyCommand(command, cwd)}
+	c.reason = DescribeClassification(c.match)
+	if rule, ok := overrides.Match(NormalizeCommand(command).Primary); ok {
+		c.override = rule.String()
+	}
+	if overridden, note := overrides.Apply(c.match, command); note != "" {
+		c.match = overridden
+		c.reason += "; " + note
+	}
+
+	if rc.db == nil {
+		return c, nil
+	}
+	c.trust, err = TrustedScriptFor(rc.db, projectPath, command, cwd)
+	if err != nil {
+		return nil, fmt.Errorf("checking trusted scripts: %w", err)
+	}
+	if lowered, note := ApplyTrustedScript(c.match, c.trust, rc.trustedScriptFloor()); note != "" {
+		if lowered != c.match {
+			c.trustUse = &db.TrustedScriptUse{
+				TrustID:  c.trust.Entry.ID,
+				SHA256:   c.trust.CurrentSHA256,
+				FromTier: c.match.Tier,
+				ToTier:   lowered.Tier,
+			}
+		}
+		c.match = lowered
+		c.reason += "; " + note
+	}
+	return c, nil
+}
+
+// trustedScriptFloor returns the configured floor for trusted scripts.
+func (rc *RequestCreator) trustedScriptFloor() RiskTier {
+	if rc.config.TrustedScriptFloor == "" {
+		return RiskTierCaution
+	}
+	return rc.config.TrustedScriptFloor
+}
+
+// checkDynamicQuorum adjusts min approvals based on active sessions. Only
+// sessions seen within SessionIdleTimeout count, so agents that died
+// without ending their session do not hold the quorum up.
 func (rc *RequestCreator) checkDynamicQuorum(tier RiskTier, minApprovals int, projectPath string) int {
-	// Count active sessions in the project
+	// Count live sessions in the project
 	sessions, err := rc.db.ListActiveSessions(projectPath)
 	if err != nil {
 		// On error, use default min approvals
 		return minApprovals
 	}
 
-	activeSessions := len(sessions)
+	now := time.Now().UTC()
+	activeSessions := 0
+	for _, s := range sessions {
+		if s.IsLive(rc.config.SessionIdleTimeout, now) {
+			activeSessions++
+		}
+	}
 	if activeSessions == 0 {
 		return minApprovals
 	}
@@ -306,45 +743,79 @@ func ParseCommandToArgv(cmd string) ([]string, error) {
 }
 
 // Default redaction patterns for sensitive data.
+// Separators are matched with [ \t], never \s, so a match cannot swallow a
+// newline and join the lines of a multi-line command.
 var defaultRedactionPatterns = []string{
 	// API keys and tokens
-	`(?i)(api[_-]?key|apikey|token|secret|password|passwd|pwd)\s*[=:]\s*['"]?[^\s'"]+['"]?`,
+	`(?i)(api[_-]?key|apikey|token|secret|password|passwd|pwd)[ \t]*[=:][ \t]*['"]?[^\s'"]+['"]?`,
 	// AWS credentials
-	`(?i)aws[_-]?(access[_-]?key|secret[_-]?key|session[_-]?token)\s*[=:]\s*['"]?[^\s'"]+['"]?`,
+	`(?i)aws[_-]?(access[_-]?key|secret[_-]?key|session[_-]?token)[ \t]*[=:][ \t]*['"]?[^\s'"]+['"]?`,
 	// Environment variable exports with sensitive names
-	`(?i)export\s+(API_KEY|SECRET|TOKEN|PASSWORD|AWS_ACCESS_KEY_ID|AWS_SECRET_ACCESS_KEY|DATABASE_URL)\s*=\s*['"]?[^\s'"]+['"]?`,
+	`(?i)export[ \t]+(API_KEY|SECRET|TOKEN|PASSWORD|AWS_ACCESS_KEY_ID|AWS_SECRET_ACCESS_KEY|DATABASE_URL)[ \t]*=[ \t]*['"]?[^\s'"]+['"]?`,
 	// Connection strings
 	`(?i)(postgres|mysql|mongodb|redis)://[^@\s]+@`,
 	// Bearer tokens
-	`(?i)bearer\s+[a-zA-Z0-9._-]+`,
+	`(?i)bearer[ \t]+[a-zA-Z0-9._-]+`,
 	// Private keys (just the header)
-	`(?i)-----BEGIN\s+[A-Z]+\s+PRIVATE\s+KEY-----`,
+	`(?i)-----BEGIN[ \t]+[A-Z]+[ \t]+PRIVATE[ \t]+KEY-----`,
 }
 
 // ApplyRedaction applies redaction patterns to a command string.
 // Returns a display-safe version of the command with sensitive data masked.
 func ApplyRedaction(cmd string, customPatterns []string) string {
-	result := cmd
+	return redactPatterns(redactPatterns(cmd, defaultRedactionPatterns), customPatterns)
+}
 
-	// Apply default patterns
-	for _, pattern := range defaultRedactionPatterns {
+// redactPatterns masks every match of patterns in s with [REDACTED],
+// skipping patterns that do not compile.
+func redactPatterns(s string, patterns []string) string {
+	s, _ = redactPatternsCount(s, patterns)
+	return s
+}
+
+// redactPatternsCount is redactPatterns that also returns the number of
+// matches masked.
+func redactPatternsCount(s string, patterns []string) (string, int) {
+	n := 0
+	for _, pattern := range patterns {
 		re, err := regexp.Compile(pattern)
 		if err != nil {
 			continue
 		}
-		result = re.ReplaceAllString(result, "[REDACTED]")
+		s = re.ReplaceAllStringFunc(s, func(string) string {
+			n++
+			return "[REDACTED]"
+		})
 	}
+	return s, n
+}
 
-	// Apply custom patterns
-	for _, pattern := range customPatterns {
-		re, err := regexp.Compile(pattern)
-		if err != nil {
-			continue
+// RedactionPatternError reports a custom redaction pattern that does not
+// compile.
+type RedactionPatternError struct {
+	Pattern string
+	Err     error
+}
+
+func (e *RedactionPatternError) Error() string {
+	return fmt.Sprintf("invalid redaction pattern %q: %v", e.Pattern, e.Err)
+}
+
+func (e *RedactionPatternError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateRedactionPatterns returns a *RedactionPatternError for the first
+// pattern that does not compile. Redaction skips such patterns, so callers
+// that must not store unredacted text check them first (see
+// AttachmentConfig.StrictRedaction).
+func ValidateRedactionPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return &RedactionPatternError{Pattern: pattern, Err: err}
 		}
-		result = re.ReplaceAllString(result, "[REDACTED]")
 	}
-
-	return result
+	return nil
 }
 
 // DetectSensitiveContent checks if a command contains sensitive data.
@@ -1,7 +1,11 @@
 package core
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
@@ -65,6 +69,71 @@ func TestCreateRequest_BlockedAgent(t *testing.T) {
 	}
 }
 
+func TestCreateRequest_StrictRedaction(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	config := DefaultRequestCreatorConfig()
+	opts := CreateRequestOptions{
+		SessionID:      session.ID,
+		Command:        "rm -rf ./build",
+		Justification:  Justification{Reason: "clean build"},
+		RedactPatterns: []string{`[invalid`},
+	}
+
+	if _, err := NewRequestCreator(database, nil, nil, config).CreateRequest(opts); err != nil {
+		t.Fatalf("expected the bad pattern skipped without strict redaction, got %v", err)
+	}
+
+	config.Attachments.StrictRedaction = true
+	var patternErr *RedactionPatternError
+	if _, err := NewRequestCreator(database, nil, nil, config).CreateRequest(opts); !errors.As(err, &patternErr) {
+		t.Errorf("expected a *RedactionPatternError under strict redaction, got %v", err)
+	}
+}
+
+func TestCreateRequest_AgentPolicy(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	sessions := map[string]*db.Session{}
+	for _, agent := range []string{"KnownAgent", "UnknownAgent", "BlockedAgent"} {
+		sessions[agent] = testutil.MakeSession(t, database, testutil.SessionWithAgentName(agent))
+	}
+
+	tests := []struct {
+		policy string
+		want   map[string]error // by agent; nil means the request is created
+	}{
+		{AgentPolicyOpen, map[string]error{"KnownAgent": nil, "UnknownAgent": nil, "BlockedAgent": nil}},
+		{AgentPolicyAllowlist, map[string]error{"KnownAgent": nil, "UnknownAgent": ErrAgentNotAllowed, "BlockedAgent": ErrAgentBlocked}},
+		{AgentPolicyDenylist, map[string]error{"KnownAgent": nil, "UnknownAgent": nil, "BlockedAgent": ErrAgentBlocked}},
+		{"", map[string]error{"KnownAgent": nil, "UnknownAgent": nil, "BlockedAgent": ErrAgentBlocked}},
+	}
+	for _, tt := range tests {
+		config := DefaultRequestCreatorConfig()
+		config.AgentPolicy = tt.policy
+		config.AllowedAgents = []string{"knownagent", "BlockedAgent"}
+		config.BlockedAgents = []string{"BlockedAgent"}
+		creator := NewRequestCreator(database, nil, nil, config)
+
+		for agent, wantErr := range tt.want {
+			_, err := creator.CreateRequest(CreateRequestOptions{
+				SessionID:     sessions[agent].ID,
+				Command:       "rm -rf /etc/test",
+				Cwd:           "/",
+				Justification: Justification{Reason: "Testing agent policy"},
+			})
+			if wantErr == nil {
+				if err != nil {
+					t.Errorf("policy %q, agent %s: unexpected error %v", tt.policy, agent, err)
+				}
+				continue
+			}
+			if !errors.Is(err, wantErr) {
+				t.Errorf("policy %q, agent %s: error = %v, want %v", tt.policy, agent, err, wantErr)
+			}
+		}
+	}
+}
+
 func TestCreateRequest_SafeCommand_Skipped(t *testing.T) {
 	database := testutil.NewTestDB(t)
 	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
@@ -143,6 +212,181 @@ func TestCreateRequest_CriticalCommand_RequiresDifferentModel(t *testing.T) {
 	}
 }
 
+func TestCreateRequest_RequireDifferentProgramTiers(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.WithProgram("codex-cli"))
+	config := DefaultRequestCreatorConfig()
+	config.RequireDifferentProgramTiers = []RiskTier{RiskTierDangerous}
+	creator := NewRequestCreator(database, nil, nil, config)
+
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"git reset --hard HEAD~3", true},
+		{"rm -rf /etc/test", false},
+	}
+	for _, tt := range tests {
+		result, err := creator.CreateRequest(CreateRequestOptions{
+			SessionID:     session.ID,
+			Command:       tt.command,
+			Cwd:           "/",
+			Justification: Justification{Reason: "Testing program diversity"},
+		})
+		if err != nil {
+			t.Fatalf("CreateRequest(%q) error = %v", tt.command, err)
+		}
+		stored, err := database.GetRequest(result.Request.ID)
+		if err != nil {
+			t.Fatalf("GetRequest() error = %v", err)
+		}
+		if stored.RequireDifferentProgram != tt.want {
+			t.Errorf("CreateRequest(%q) stored RequireDifferentProgram = %v, want %v", tt.command, stored.RequireDifferentProgram, tt.want)
+		}
+		if stored.RequestorProgram != "codex-cli" {
+			t.Errorf("stored RequestorProgram = %q, want codex-cli", stored.RequestorProgram)
+		}
+	}
+}
+
+func TestCreateRequest_RequireDifferentHostTiers(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	config := DefaultRequestCreatorConfig()
+	config.RequireDifferentHostTiers = []RiskTier{RiskTierCritical}
+	creator := NewRequestCreator(database, nil, nil, config)
+
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"rm -rf /etc/test", true},
+		{"git reset --hard HEAD~3", false},
+	}
+	for _, tt := range tests {
+		result, err := creator.CreateRequest(CreateRequestOptions{
+			SessionID:     session.ID,
+			Command:       tt.command,
+			Cwd:           "/",
+			Justification: Justification{Reason: "Testing host diversity"},
+		})
+		if err != nil {
+			t.Fatalf("CreateRequest(%q) error = %v", tt.command, err)
+		}
+		if result.Request.RequireDifferentHost != tt.want {
+			t.Errorf("CreateRequest(%q) RequireDifferentHost = %v, want %v", tt.command, result.Request.RequireDifferentHost, tt.want)
+		}
+		stored, err := database.GetRequest(result.Request.ID)
+		if err != nil {
+			t.Fatalf("GetRequest() error = %v", err)
+		}
+		if stored.RequireDifferentHost != tt.want {
+			t.Errorf("stored RequireDifferentHost = %v, want %v", stored.RequireDifferentHost, tt.want)
+		}
+	}
+}
+
+func TestCreateRequest_TimeoutBounds(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	config := DefaultRequestCreatorConfig()
+	config.TimeoutBounds = map[RiskTier]TimeoutBounds{
+		RiskTierCritical: {MinSecs: 30, MaxSecs: 3600},
+	}
+	creator := NewRequestCreator(database, nil, nil, config)
+
+	tests := []struct {
+		name        string
+		timeout     int
+		wantTimeout int
+		wantClamped bool
+	}{
+		{"too large is clamped to the ceiling", 86400, 3600, true},
+		{"too small is clamped to the floor", 0, 30, true},
+		{"in range is preserved", 300, 300, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout := tt.timeout
+			result, err := creator.CreateRequest(CreateRequestOptions{
+				SessionID:     session.ID,
+				Command:       "rm -rf /etc/test",
+				Cwd:           "/",
+				Justification: Justification{Reason: "Testing timeout bounds"},
+				TimeoutSecs:   &timeout,
+			})
+			if err != nil {
+				t.Fatalf("CreateRequest() error = %v", err)
+			}
+			stored, err := database.GetRequest(result.Request.ID)
+			if err != nil {
+				t.Fatalf("GetRequest() error = %v", err)
+			}
+			if stored.TimeoutSecs != tt.wantTimeout {
+				t.Errorf("TimeoutSecs = %d, want %d", stored.TimeoutSecs, tt.wantTimeout)
+			}
+			if stored.TimeoutRequestedSecs != tt.timeout {
+				t.Errorf("TimeoutRequestedSecs = %d, want %d", stored.TimeoutRequestedSecs, tt.timeout)
+			}
+			if stored.TimeoutClamped() != tt.wantClamped {
+				t.Errorf("TimeoutClamped() = %v, want %v", stored.TimeoutClamped(), tt.wantClamped)
+			}
+		})
+	}
+
+	t.Run("unbounded tier and no timeout", func(t *testing.T) {
+		timeout := 86400
+		result, err := creator.CreateRequest(CreateRequestOptions{
+			SessionID:     session.ID,
+			Command:       "git reset --hard HEAD~3",
+			Cwd:           "/",
+			Justification: Justification{Reason: "Testing timeout bounds"},
+			TimeoutSecs:   &timeout,
+		})
+		if err != nil {
+			t.Fatalf("CreateRequest() error = %v", err)
+		}
+		if result.Request.TimeoutSecs != 86400 || result.Request.TimeoutClamped() {
+			t.Errorf("expected unbounded tier to keep 86400, got %d", result.Request.TimeoutSecs)
+		}
+
+		result, err = creator.CreateRequest(CreateRequestOptions{
+			SessionID:     session.ID,
+			Command:       "rm -rf /etc/other",
+			Cwd:           "/",
+			Justification: Justification{Reason: "Testing timeout bounds"},
+		})
+		if err != nil {
+			t.Fatalf("CreateRequest() error = %v", err)
+		}
+		if result.Request.TimeoutSecs != 0 || result.Request.TimeoutClamped() {
+			t.Errorf("expected no timeout recorded, got %d", result.Request.TimeoutSecs)
+		}
+	})
+}
+
+func TestTimeoutBounds_Clamp(t *testing.T) {
+	tests := []struct {
+		bounds      TimeoutBounds
+		secs        int
+		want        int
+		wantClamped bool
+	}{
+		{TimeoutBounds{}, 0, 0, false},
+		{TimeoutBounds{MinSecs: 10}, 5, 10, true},
+		{TimeoutBounds{MinSecs: 10}, 99999, 99999, false},
+		{TimeoutBounds{MaxSecs: 60}, 61, 60, true},
+		{TimeoutBounds{MaxSecs: 60}, -5, -5, false},
+		{TimeoutBounds{MinSecs: 10, MaxSecs: 60}, 30, 30, false},
+	}
+	for _, tt := range tests {
+		got, clamped := tt.bounds.Clamp(tt.secs)
+		if got != tt.want || clamped != tt.wantClamped {
+			t.Errorf("%+v.Clamp(%d) = (%d, %v), want (%d, %v)", tt.bounds, tt.secs, got, clamped, tt.want, tt.wantClamped)
+		}
+	}
+}
+
 func TestApplyRedaction_APIKey(t *testing.T) {
 	cmd := "curl -H 'API-KEY: secret123' https://api.example.com"
 	result := ApplyRedaction(cmd, nil)
@@ -293,6 +537,34 @@ func TestDynamicQuorum_BelowFloor(t *testing.T) {
 	}
 }
 
+func TestDynamicQuorum_IgnoresIdleSessions(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	project := "/test/project"
+
+	testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"), testutil.SessionWithProject(project))
+	testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent2"), testutil.SessionWithProject(project))
+	dead := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent3"), testutil.SessionWithProject(project))
+	lastSeen := time.Now().UTC().Add(-72 * time.Hour).Format(time.RFC3339)
+	if _, err := database.Exec(`UPDATE sessions SET last_active_at = ? WHERE id = ?`, lastSeen, dead.ID); err != nil {
+		t.Fatalf("backdating session: %v", err)
+	}
+
+	config := DefaultRequestCreatorConfig()
+	config.DynamicQuorumEnabled = true
+	config.DynamicQuorumFloor = 1
+
+	// Without an idle window the dead session still counts: 2 reviewers.
+	if got := NewRequestCreator(database, nil, nil, config).checkDynamicQuorum(RiskTierCritical, 2, project); got != 2 {
+		t.Errorf("expected minApprovals=2 counting every session, got %d", got)
+	}
+
+	// With one, only the 2 live sessions count, leaving 1 reviewer.
+	config.SessionIdleTimeout = 24 * time.Hour
+	if got := NewRequestCreator(database, nil, nil, config).checkDynamicQuorum(RiskTierCritical, 2, project); got != 1 {
+		t.Errorf("expected minApprovals=1 ignoring the idle session, got %d", got)
+	}
+}
+
 func TestCreateRequest_SessionInactive(t *testing.T) {
 	database := testutil.NewTestDB(t)
 	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
@@ -354,17 +626,262 @@ func TestCreateRequest_RateLimitActionQueue(t *testing.T) {
 
 	config := DefaultRateLimitConfig()
 	config.MaxPendingPerSession = 5
-	config.Action = RateLimitActionQueue // Should block (Allowed=false)
+	config.Action = RateLimitActionQueue // Should hold the request in the queue
 
 	limiter := NewRateLimiter(database, config)
 	creator := NewRequestCreator(database, limiter, nil, nil)
 
-	_, err := creator.CreateRequest(CreateRequestOptions{
+	result, err := creator.CreateRequest(CreateRequestOptions{
 		SessionID: session.ID,
 		Command:   "rm -rf /tmp/test",
 	})
+	if err != nil {
+		t.Fatalf("CreateRequest with queue action: %v", err)
+	}
+	if !result.Queued || result.Request.Status != db.StatusQueued {
+		t.Fatalf("expected queued request, got queued=%v status=%s", result.Queued, result.Request.Status)
+	}
+	q := result.Queue
+	if q == nil {
+		t.Fatal("expected queue status")
+	}
+	if q.Position != 1 || q.QueueLength != 1 {
+		t.Errorf("position = %d of %d, want 1 of 1", q.Position, q.QueueLength)
+	}
+	if q.Pending != 5 || q.MaxPending != 5 {
+		t.Errorf("pending = %d/%d, want 5/5", q.Pending, q.MaxPending)
+	}
+	// Admission waits on reviews, which cannot be predicted.
+	if q.EstimatedAdmitAt != nil || q.EstimateNote == "" {
+		t.Errorf("expected no estimate and a note, got %v %q", q.EstimatedAdmitAt, q.EstimateNote)
+	}
+}
 
-	if err == nil {
-		t.Error("expected error for rate limit queue action")
+func TestCreateRequest_PersistsNormalization(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	creator := NewRequestCreator(database, nil, nil, nil)
+
+	command := "cd /srv && sudo rm -fr cache"
+	result, err := creator.CreateRequest(CreateRequestOptions{
+		SessionID: session.ID,
+		Command:   command,
+		Cwd:       "/srv",
+	})
+	if err != nil || result.Request == nil {
+		t.Fatalf("CreateRequest failed: %v", err)
+	}
+
+	stored, err := database.GetRequest(result.Request.ID)
+	if err != nil {
+		t.Fatalf("GetRequest failed: %v", err)
+	}
+	fresh := NormalizeCommand(command)
+	if !reflect.DeepEqual(stored.Command.NormalizedSegments, fresh.Segments) {
+		t.Errorf("stored segments = %q, fresh NormalizeCommand = %q", stored.Command.NormalizedSegments, fresh.Segments)
+	}
+	if want := SummarizeCommand(command); stored.Command.Summary != want {
+		t.Errorf("stored summary = %q, want %q", stored.Command.Summary, want)
+	}
+	if want := DescribeClassification(result.Classification); stored.TierReason != want || want == "" {
+		t.Errorf("stored tier reason = %q, want %q", stored.TierReason, want)
+	}
+}
+
+func TestCreateRequest_SummaryUsesRedactedCommand(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	creator := NewRequestCreator(database, nil, nil, nil)
+
+	result, err := creator.CreateRequest(CreateRequestOptions{
+		SessionID:      session.ID,
+		Command:        "rm -rf /tmp/x --tag-hunter2",
+		Cwd:            "/tmp",
+		RedactPatterns: []string{`--tag-\S+`},
+	})
+	if err != nil || result.Request == nil {
+		t.Fatalf("CreateRequest failed: %v", err)
+	}
+	if strings.Contains(result.Request.Command.Summary, "hunter2") {
+		t.Errorf("summary leaks redacted text: %q", result.Request.Command.Summary)
+	}
+}
+
+func TestCreateRequest_ForceReviewPromotesSafeCommand(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	creator := NewRequestCreator(database, nil, nil, nil)
+
+	opts := CreateRequestOptions{SessionID: session.ID, Command: "rm old.log", Cwd: "/tmp"}
+	result, err := creator.CreateRequest(opts)
+	if err != nil || !result.Skipped {
+		t.Fatalf("expected safe command to be skipped, got %+v, %v", result, err)
+	}
+
+	opts.ForceReview = true
+	opts.DryRun = &db.DryRunResult{Command: "ls -la -- old.log", Output: "old.log"}
+	result, err = creator.CreateRequest(opts)
+	if err != nil || result.Request == nil {
+		t.Fatalf("CreateRequest failed: %+v, %v", result, err)
+	}
+
+	stored, err := database.GetRequest(result.Request.ID)
+	if err != nil {
+		t.Fatalf("GetRequest failed: %v", err)
+	}
+	if stored.RiskTier != db.RiskTierCaution {
+		t.Errorf("tier = %s, want caution", stored.RiskTier)
+	}
+	if !strings.HasSuffix(stored.TierReason, "; review requested explicitly") {
+		t.Errorf("tier reason = %q", stored.TierReason)
+	}
+	if stored.DryRun == nil || stored.DryRun.Output != "old.log" {
+		t.Errorf("dry run not stored: %+v", stored.DryRun)
+	}
+}
+
+func TestCreateRequest_SelfProtection(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	project := t.TempDir()
+	opts := CreateRequestOptions{SessionID: session.ID, Command: "rm -rf .slb", Cwd: project}
+
+	result, err := NewRequestCreator(database, nil, nil, nil).CreateRequest(opts)
+	if err != nil || result.Request == nil {
+		t.Fatalf("CreateRequest failed: %v", err)
+	}
+	if result.Request.RiskTier != db.RiskTierCritical || !strings.Contains(result.Request.TierReason, "self-protection") {
+		t.Errorf("got tier %s, reason %q", result.Request.RiskTier, result.Request.TierReason)
+	}
+
+	cfg := DefaultRequestCreatorConfig()
+	cfg.SelfProtectionAction = SelfProtectionRefuse
+	if _, err := NewRequestCreator(database, nil, nil, cfg).CreateRequest(opts); !errors.Is(err, ErrSelfProtection) {
+		t.Errorf("expected ErrSelfProtection, got %v", err)
+	}
+}
+
+func TestCreateRequest_AttachmentQuota(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	config := DefaultRequestCreatorConfig()
+	config.Attachments.MaxTotalAttachmentBytes = 1000
+	config.Attachments.ReservedContextBytes = 400
+	creator := NewRequestCreator(database, nil, nil, config)
+
+	blob := func(n int) db.Attachment {
+		return db.Attachment{Type: db.AttachmentTypeFile, Content: strings.Repeat("x", n)}
+	}
+	tests := []struct {
+		name    string
+		agent   []db.Attachment
+		context []db.Attachment
+		dryRun  int
+		wantErr string
+	}{
+		{"agent within its share", []db.Attachment{blob(300), blob(300)}, nil, 0, ""},
+		{"agent crowding the reserve", []db.Attachment{blob(300), blob(301)}, nil, 0, "agent attachments total 601 bytes, limit 600 bytes"},
+		{"context may use the reserve", []db.Attachment{blob(600)}, []db.Attachment{blob(200)}, 200, ""},
+		{"total over the limit", []db.Attachment{blob(600)}, []db.Attachment{blob(300)}, 200, "attachments total 1100 bytes (600 agent, 500 auto-collected), limit 1000 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := CreateRequestOptions{
+				SessionID:          session.ID,
+				Command:            "rm -rf /etc/test",
+				Cwd:                "/",
+				Justification:      Justification{Reason: "Testing attachment quota"},
+				Attachments:        tt.agent,
+				ContextAttachments: tt.context,
+			}
+			if tt.dryRun > 0 {
+				opts.DryRun = &db.DryRunResult{Command: "true", Output: strings.Repeat("y", tt.dryRun)}
+			}
+			result, err := creator.CreateRequest(opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CreateRequest() error = %v", err)
+				}
+				stored, err := database.GetRequest(result.Request.ID)
+				if err != nil {
+					t.Fatalf("GetRequest() error = %v", err)
+				}
+				if got := len(stored.Attachments); got != len(tt.agent)+len(tt.context) {
+					t.Errorf("stored %d attachments, want %d", got, len(tt.agent)+len(tt.context))
+				}
+				return
+			}
+			var quotaErr *AttachmentQuotaError
+			if !errors.As(err, &quotaErr) {
+				t.Fatalf("CreateRequest() error = %v, want *AttachmentQuotaError", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateRequest_AttachmentBudget(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	config := DefaultRequestCreatorConfig()
+	config.Attachments.MaxTotalAttachmentBytes = 1000
+	config.Attachments.ReservedContextBytes = 0
+	creator := NewRequestCreator(database, nil, nil, config)
+
+	_, err := creator.CreateRequest(CreateRequestOptions{
+		SessionID:     session.ID,
+		Command:       "rm -rf /etc/test",
+		Cwd:           "/",
+		Justification: Justification{Reason: "Testing attachment budget"},
+		Attachments: []db.Attachment{
+			{Type: db.AttachmentTypeFile, Content: strings.Repeat("x", 600), Metadata: map[string]any{"filename": "a.log"}},
+			{Type: db.AttachmentTypeFile, Content: strings.Repeat("x", 500), Metadata: map[string]any{"filename": "b.log"}},
+		},
+	})
+	var budgetErr *AttachmentBudgetError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("CreateRequest() error = %v, want *AttachmentBudgetError", err)
+	}
+	if budgetErr.Index != 1 || budgetErr.Name != "b.log" {
+		t.Errorf("expected the second attachment to be blamed, got %+v", budgetErr)
+	}
+	if pending, _ := database.ListPendingRequests(session.ProjectPath); len(pending) != 0 {
+		t.Errorf("expected no request to be created, got %d", len(pending))
+	}
+}
+
+func TestCreateRequest_RequiredRoles(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database)
+	config := DefaultRequestCreatorConfig()
+	config.RequiredRoles = map[RiskTier][]string{RiskTierCritical: {"security"}}
+	creator := NewRequestCreator(database, nil, nil, config)
+
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"rm -rf /etc/test", []string{"security"}},
+		{"git reset --hard HEAD~3", nil},
+	}
+	for _, tt := range tests {
+		result, err := creator.CreateRequest(CreateRequestOptions{
+			SessionID:     session.ID,
+			Command:       tt.command,
+			Cwd:           "/",
+			Justification: Justification{Reason: "Testing required roles"},
+		})
+		if err != nil {
+			t.Fatalf("CreateRequest(%q) error = %v", tt.command, err)
+		}
+		stored, err := database.GetRequest(result.Request.ID)
+		if err != nil {
+			t.Fatalf("GetRequest() error = %v", err)
+		}
+		if strings.Join(stored.RequiredRoles, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("CreateRequest(%q) stored RequiredRoles = %v, want %v", tt.command, stored.RequiredRoles, tt.want)
+		}
 	}
 }
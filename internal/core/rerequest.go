@@ -0,0 +1,95 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Re-request errors.
+var (
+	// ErrApprovalNotExpired is returned when re-requesting a request that is
+	// not in the approval_expired state.
+	ErrApprovalNotExpired = errors.New("request approval has not expired")
+	// ErrNotRequestor is returned when a session other than the requesting
+	// agent's tries to re-request review.
+	ErrNotRequestor = errors.New("only the requesting agent can re-request review")
+)
+
+// RerequestOptions selects the request to send back for review.
+type RerequestOptions struct {
+	RequestID string
+	// SessionID is an active session of the agent that made the request,
+	// in the request's project.
+	SessionID string
+}
+
+// RerequestResult is the outcome of a re-request.
+type RerequestResult struct {
+	Request *db.Request
+	// PriorApprovals are the approvals from the round that expired. Their
+	// reviewers were asked to re-confirm.
+	PriorApprovals []*db.Review
+}
+
+// Rerequest sends a request whose approval expired back to pending for a new
+// review round, keeping its justification, attachments and earlier reviews.
+// The earlier reviews stay on record but no longer count toward the quorum,
+// and the approvers among them are asked to re-confirm. The command must
+// still hash to what they approved. The session may differ from the
+// original one (agents resume sessions), but it must belong to the same
+// agent in the same project.
+func (rc *RequestCreator) Rerequest(opts RerequestOptions) (*RerequestResult, error) {
+	if opts.SessionID == "" {
+		return nil, ErrSessionRequired
+	}
+
+	request, err := rc.db.GetRequest(opts.RequestID)
+	if err != nil {
+		return nil, fmt.Errorf("getting request: %w", err)
+	}
+	session, err := rc.db.GetSession(opts.SessionID)
+	if err != nil {
+		if errors.Is(err, db.ErrSessionNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("getting session: %w", err)
+	}
+	if session.EndedAt != nil {
+		return nil, ErrSessionInactive
+	}
+	if session.AgentName != request.RequestorAgent || session.ProjectPath != request.ProjectPath {
+		return nil, ErrNotRequestor
+	}
+	if request.Status != db.StatusApprovalExpired {
+		return nil, fmt.Errorf("%w: status is %s", ErrApprovalNotExpired, request.Status)
+	}
+	if !db.CommandHashMatches(request.Command) {
+		return nil, fmt.Errorf("%w: stored=%s computed=%s", ErrCommandHashMismatch, request.Command.Hash, db.ComputeCommandHash(request.Command))
+	}
+
+	prior, err := rc.db.ListReviewsForRequest(request.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing reviews: %w", err)
+	}
+	var approvals []*db.Review
+	for _, r := range prior {
+		if r.Decision == db.DecisionApprove {
+			approvals = append(approvals, r)
+		}
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Duration(rc.config.RequestTimeoutMinutes) * time.Minute)
+	if err := rc.db.ReopenExpiredApproval(request.ID, &expiresAt); err != nil {
+		return nil, fmt.Errorf("reopening request: %w", err)
+	}
+	request, err = rc.db.GetRequest(request.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting request: %w", err)
+	}
+
+	_ = rc.notifierFor(request.ProjectPath).NotifyReconfirmRequested(request, approvals)
+	return &RerequestResult{Request: request, PriorApprovals: approvals}, nil
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// expireApproval approves req with a reviewer from another model and then
+// lets the approval lapse.
+func expireApproval(t *testing.T, dbConn *db.DB, req *db.Request) *db.Session {
+	t.Helper()
+	reviewer := &db.Session{
+		AgentName:   "GreenLake",
+		Program:     "claude-code",
+		Model:       "opus-4.5",
+		ProjectPath: "/test/project",
+	}
+	if err := dbConn.CreateSession(reviewer); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	approve(t, dbConn, reviewer, req.ID)
+	if err := dbConn.UpdateRequestStatus(req.ID, db.StatusApprovalExpired); err != nil {
+		t.Fatalf("UpdateRequestStatus() error = %v", err)
+	}
+	return reviewer
+}
+
+func approve(t *testing.T, dbConn *db.DB, reviewer *db.Session, requestID string) *ReviewResult {
+	t.Helper()
+	result, err := NewReviewService(dbConn, DefaultReviewConfig()).SubmitReview(ReviewOptions{
+		SessionID:  reviewer.ID,
+		SessionKey: reviewer.SessionKey,
+		RequestID:  requestID,
+		Decision:   db.DecisionApprove,
+	})
+	if err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+	return result
+}
+
+func TestRerequest_NewRoundAndReconfirm(t *testing.T) {
+	dbConn, sess, req := setupReviewTest(t)
+	defer dbConn.Close()
+	reviewer := expireApproval(t, dbConn, req)
+
+	cfg := DefaultRequestCreatorConfig()
+	cfg.AgentMailEnabled = false
+	notifier := &mockRequestNotifier{}
+	rc := NewRequestCreator(dbConn, nil, nil, cfg)
+	rc.notifier = notifier
+
+	result, err := rc.Rerequest(RerequestOptions{RequestID: req.ID, SessionID: sess.ID})
+	if err != nil {
+		t.Fatalf("Rerequest() error = %v", err)
+	}
+	if result.Request.Status != db.StatusPending || result.Request.ReviewRound != 1 {
+		t.Errorf("expected pending in round 1, got %s in round %d", result.Request.Status, result.Request.ReviewRound)
+	}
+	if result.Request.ExpiresAt == nil || result.Request.ApprovalExpiresAt != nil {
+		t.Errorf("expected a new review deadline and no approval expiry, got %v / %v", result.Request.ExpiresAt, result.Request.ApprovalExpiresAt)
+	}
+	if len(notifier.reconfirmAgents) != 1 || notifier.reconfirmAgents[0] != reviewer.AgentName {
+		t.Errorf("expected %s to be asked to re-confirm, got %v", reviewer.AgentName, notifier.reconfirmAgents)
+	}
+
+	// The earlier approval stays on record but not in the new round.
+	current, err := dbConn.ListReviewsForRequest(req.ID)
+	if err != nil || len(current) != 0 {
+		t.Fatalf("expected no reviews in the new round, got %v, %v", current, err)
+	}
+	_, all, err := dbConn.GetRequestWithReviews(req.ID)
+	if err != nil || len(all) != 1 || all[0].Round != 0 {
+		t.Fatalf("expected the round 0 review to remain, got %+v, %v", all, err)
+	}
+
+	// The same reviewer re-confirms in one action.
+	again := approve(t, dbConn, reviewer, req.ID)
+	if again.NewRequestStatus != db.StatusApproved || again.Review.Round != 1 {
+		t.Errorf("expected a round 1 approval, got %s in round %d", again.NewRequestStatus, again.Review.Round)
+	}
+}
+
+func TestRerequest_Errors(t *testing.T) {
+	dbConn, sess, req := setupReviewTest(t)
+	defer dbConn.Close()
+	rc := NewRequestCreator(dbConn, nil, nil, nil)
+
+	if _, err := rc.Rerequest(RerequestOptions{RequestID: req.ID, SessionID: sess.ID}); !errors.Is(err, ErrApprovalNotExpired) {
+		t.Errorf("pending request: expected ErrApprovalNotExpired, got %v", err)
+	}
+
+	reviewer := expireApproval(t, dbConn, req)
+	if _, err := rc.Rerequest(RerequestOptions{RequestID: req.ID}); !errors.Is(err, ErrSessionRequired) {
+		t.Errorf("no session: expected ErrSessionRequired, got %v", err)
+	}
+	if _, err := rc.Rerequest(RerequestOptions{RequestID: req.ID, SessionID: reviewer.ID}); !errors.Is(err, ErrNotRequestor) {
+		t.Errorf("reviewer session: expected ErrNotRequestor, got %v", err)
+	}
+
+	if _, err := dbConn.Exec(`UPDATE requests SET command_raw = 'rm -rf /' WHERE id = ?`, req.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rc.Rerequest(RerequestOptions{RequestID: req.ID, SessionID: sess.ID}); !errors.Is(err, ErrCommandHashMismatch) {
+		t.Errorf("changed command: expected ErrCommandHashMismatch, got %v", err)
+	}
+	if got, _ := dbConn.GetRequest(req.ID); got.Status != db.StatusApprovalExpired {
+		t.Errorf("expected the request to stay approval_expired, got %s", got.Status)
+	}
+}
@@ -17,11 +17,21 @@ var (
 	ErrSelfReview         = errors.New("cannot review your own request")
 	ErrAlreadyReviewed    = errors.New("you have already reviewed this request")
 	ErrRequireDiffModel   = errors.New("different model required for approval")
+	ErrRequireDiffHost    = errors.New("different host required for approval")
+	ErrRequireDiffProgram = errors.New("different program required for approval")
 	ErrInvalidDecision    = errors.New("invalid decision (must be approve or reject)")
 	ErrMissingSessionKey  = errors.New("session key required for signature")
 	ErrSessionKeyMismatch = errors.New("session key does not match session")
+	ErrInvalidSegments    = errors.New("invalid segment selection")
+	ErrCallbackConflict   = errors.New("callback ID already used for a different review")
+	ErrInvalidSignature   = errors.New("review signature does not verify")
+	ErrNoReviewToWithdraw = errors.New("you have not reviewed this request")
 )
 
+// MaxSignatureSkew bounds how far a client-computed review signature's
+// timestamp may be from the server's clock.
+const MaxSignatureSkew = 5 * time.Minute
+
 // ConflictResolution specifies how to handle conflicting reviews.
 type ConflictResolution string
 
@@ -32,6 +42,9 @@ const (
 	ConflictFirstWins ConflictResolution = "first_wins"
 	// ConflictHumanBreaksTie means escalate to human on conflict.
 	ConflictHumanBreaksTie ConflictResolution = "human_breaks_tie"
+	// ConflictWeightedQuorum means approvals count by reviewer weight toward
+	// MinApprovals; any rejection still blocks.
+	ConflictWeightedQuorum ConflictResolution = "weighted_quorum"
 )
 
 // ReviewOptions contains parameters for submitting a review.
@@ -48,6 +61,27 @@ type ReviewOptions struct {
 	Responses db.ReviewResponse
 	// Comments contains optional additional comments.
 	Comments string
+	// Segments limits Decision to these 1-based segments of a compound
+	// command; the other segments get the opposite decision. Empty means the
+	// decision covers the whole command.
+	Segments []int
+	// CallbackID identifies the inbound callback delivering this review,
+	// such as a chat action or webhook delivery ID. A callback ID that was
+	// already processed returns the original review instead of a new one.
+	CallbackID string
+	// Signature, when set, is the review signature computed by the client
+	// over SignatureTimestamp (see db.ComputeReviewSignature). It must
+	// verify against the session key and is stored instead of a signature
+	// computed here.
+	Signature          string
+	SignatureTimestamp time.Time
+	// Expedite raises the request's execution priority, so once approved it
+	// gets an execution slot ahead of earlier approvals waiting in its
+	// project's execution queue. Only approvals may expedite.
+	Expedite bool
+	// DelegationID records that the review is given on the reviewer's
+	// behalf under this delegation (see ReviewService.ApplyDelegations).
+	DelegationID string
 }
 
 // ReviewConfig provides configuration for the review process.
@@ -61,6 +95,33 @@ type ReviewConfig struct {
 	// DifferentModelTimeout is how long to wait for a different-model reviewer
 	// before escalating to human when require_different_model is set.
 	DifferentModelTimeout time.Duration
+	// EscalateAfter is how long a request may sit pending without a
+	// rejection before SweepEscalations hands it to a human. Zero disables
+	// time-boxed escalation.
+	EscalateAfter time.Duration
+	// EscalateAfterTiers overrides EscalateAfter for the listed risk tiers.
+	EscalateAfterTiers map[db.RiskTier]time.Duration
+	// ReviewerThresholds flags rubber-stamp reviewers; with ExcludeCritical
+	// their approvals do not count toward CRITICAL quorum. Only reviews
+	// submitted through SubmitReview are checked: the TUI and the watch
+	// auto-approver record reviews directly and bypass the thresholds.
+	ReviewerThresholds ReviewerThresholds
+	// ReviewerWeights maps agent names to their approval weight under
+	// ConflictWeightedQuorum; agents not listed weigh 1.
+	ReviewerWeights map[string]int
+	// AgentRoles maps agent names to the reviewer roles they hold. A request
+	// with RequiredRoles needs an approval from each role on top of
+	// MinApprovals.
+	AgentRoles map[string][]string
+	// SessionIdleTimeout rejects reviews from sessions not seen for longer
+	// than this, even before the daemon ends them (0 disables the check).
+	SessionIdleTimeout time.Duration
+	// UnviewedEvidenceAction is general.unviewed_evidence_action, applied by
+	// CheckUnviewedEvidence.
+	UnviewedEvidenceAction string
+	// Anonymity hides reviewer identities from GetReviewStatus and
+	// ReviewsForViewer while a request is unresolved.
+	Anonymity ReviewerAnonymity
 }
 
 // DefaultReviewConfig returns the default review configuration.
@@ -85,6 +146,31 @@ type ReviewResult struct {
 	Approvals int
 	// Rejections is the current rejection count.
 	Rejections int
+	// ApprovedSegments lists the segments cleared for execution when the
+	// request was partially approved.
+	ApprovedSegments []int
+	// ExcludedReviewers lists flagged reviewers whose approvals were not
+	// counted toward this CRITICAL request's quorum.
+	ExcludedReviewers []string
+	// SameHostReviewers lists reviewers whose approvals were not counted
+	// because the request requires a different host and theirs is not
+	// provably different from the requestor's.
+	SameHostReviewers []string
+	// Replayed reports that the review's callback was already processed:
+	// Review is the review it recorded and nothing new was submitted.
+	Replayed bool
+	// ResolutionApplied explains which conflict resolution rule drove the
+	// status change (nil if the status did not change).
+	ResolutionApplied *ResolutionApplied
+}
+
+// ResolutionApplied is the conflict resolution rule that decided a request's
+// new status, and why.
+type ResolutionApplied struct {
+	// Resolution is the ConflictResolution in effect.
+	Resolution ConflictResolution `json:"resolution"`
+	// Explanation is a short reason, e.g. "escalated due to mixed approvals".
+	Explanation string `json:"explanation"`
 }
 
 // ReviewService handles review operations.
@@ -92,6 +178,7 @@ type ReviewService struct {
 	db       *db.DB
 	config   ReviewConfig
 	notifier integrations.RequestNotifier
+	viewer   ReviewViewer
 }
 
 // NewReviewService creates a new review service.
@@ -126,6 +213,15 @@ func (rs *ReviewService) SubmitReview(opts ReviewOptions) (*ReviewResult, error)
 	if opts.Decision != db.DecisionApprove && opts.Decision != db.DecisionReject {
 		return nil, ErrInvalidDecision
 	}
+	if opts.Expedite && opts.Decision != db.DecisionApprove {
+		return nil, ErrExpediteRequiresApproval
+	}
+
+	// A repeated callback is a no-op success, even though the request (or
+	// the reviewer's session) has likely moved on since it was processed
+	if replayed, err := rs.ReplayedCallback(opts); err != nil || replayed != nil {
+		return replayed, err
+	}
 
 	// Step 1: Get and validate session
 	session, err := rs.db.GetSession(opts.SessionID)
@@ -138,6 +234,13 @@ func (rs *ReviewService) SubmitReview(opts ReviewOptions) (*ReviewResult, error)
 	if opts.SessionKey != session.SessionKey {
 		return nil, ErrSessionKeyMismatch
 	}
+	if !session.IsLive(rs.config.SessionIdleTimeout, time.Now().UTC()) {
+		return nil, ErrSessionStale
+	}
+	// Submitting a review counts as being seen.
+	if err := rs.db.UpdateSessionHeartbeat(session.ID); err != nil {
+		return nil, fmt.Errorf("recording session activity: %w", err)
+	}
 
 	// Step 2: Get and validate request
 	request, err := rs.db.GetRequest(opts.RequestID)
@@ -147,6 +250,18 @@ func (rs *ReviewService) SubmitReview(opts ReviewOptions) (*ReviewResult, error)
 	if !CanApprove(request.Status) {
 		return nil, fmt.Errorf("%w: status is %s", ErrRequestNotPending, request.Status)
 	}
+	commandSegments := CommandSegments(request.Command.Raw)
+	segmentCount := len(commandSegments)
+	segments, err := NormalizeSegmentSelection(opts.Segments, segmentCount)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) > 0 {
+		cleared := clearedSegments(opts.Decision, segments, segmentCount)
+		if err := checkSegmentSkips(commandSegments, cleared); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSegments, err)
+		}
+	}
 
 	// Step 3: Check not self-review (unless trusted self-approve agent)
 	isSelfReview := opts.SessionID == request.RequestorSessionID
@@ -170,16 +285,83 @@ func (rs *ReviewService) SubmitReview(opts ReviewOptions) (*ReviewResult, error)
 		return nil, ErrAlreadyReviewed
 	}
 
-	// Step 5: Check require_different_model (for approvals only)
-	if opts.Decision == db.DecisionApprove && request.RequireDifferentModel {
+	// Step 5: Check require_different_model (for approvals only; a segment
+	// decision approves the unselected segments of a rejection)
+	if (opts.Decision == db.DecisionApprove || len(segments) > 0) && request.RequireDifferentModel {
 		if session.Model == request.RequestorModel {
 			return nil, fmt.Errorf("%w: your model (%s) matches the requestor's", ErrRequireDiffModel, session.Model)
 		}
 	}
 
-	// Step 6: Generate signature
+	// Step 5a: Check require_different_program independently of the model,
+	// so two instances of one program can't approve each other by
+	// labelling themselves with different models
+	if (opts.Decision == db.DecisionApprove || len(segments) > 0) && request.RequireDifferentProgram {
+		if session.Program == request.RequestorProgram {
+			return nil, fmt.Errorf("%w: your program (%s) matches the requestor's", ErrRequireDiffProgram, session.Program)
+		}
+	}
+
+	// Step 5b: Check require_different_host the same way. Approvals from
+	// same-host sessions recorded by other paths are excluded below.
+	var sameHost map[string]bool
+	if request.RequireDifferentHost {
+		requestor, err := rs.db.GetSession(request.RequestorSessionID)
+		if err != nil && !errors.Is(err, db.ErrSessionNotFound) {
+			return nil, fmt.Errorf("getting requestor session: %w", err)
+		}
+		if (opts.Decision == db.DecisionApprove || len(segments) > 0) && !session.OnDifferentHost(requestor) {
+			return nil, fmt.Errorf("%w: your host (%s) is not provably different from the requestor's (%s)",
+				ErrRequireDiffHost, hostLabel(session), hostLabel(requestor))
+		}
+		prior, err := rs.db.ListReviewsForRequest(opts.RequestID)
+		if err != nil {
+			return nil, fmt.Errorf("listing reviews: %w", err)
+		}
+		sameHost, err = sameHostReviewers(rs.db, requestor, prior)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 6: Load which of this request's reviewers are flagged for
+	// rubber-stamp approvals, whose approvals do not count toward CRITICAL
+	// quorum
+	var flagged map[string]bool
+	if request.RiskTier == db.RiskTierCritical && rs.config.ReviewerThresholds.Enabled() {
+		prior, err := rs.db.ListReviewsForRequest(opts.RequestID)
+		if err != nil {
+			return nil, fmt.Errorf("listing reviews: %w", err)
+		}
+		agents := []string{session.AgentName}
+		for _, r := range prior {
+			agents = append(agents, r.ReviewerAgent)
+		}
+		flagged, err = FlaggedReviewers(rs.db, rs.config.ReviewerThresholds, agents)
+		if err != nil {
+			return nil, fmt.Errorf("checking reviewer patterns: %w", err)
+		}
+	}
+
+	// Step 7: Generate signature, or check the one the client computed. It
+	// covers the command hash, so the approval does not carry over to an
+	// altered command.
 	timestamp := time.Now().UTC()
-	signature := db.ComputeReviewSignature(opts.SessionKey, opts.RequestID, opts.Decision, timestamp)
+	commandHash := request.Command.Hash
+	signature := db.ComputeReviewSignature(opts.SessionKey, opts.RequestID, commandHash, opts.Decision, timestamp)
+	if opts.Signature != "" {
+		skew := timestamp.Sub(opts.SignatureTimestamp)
+		if skew < -MaxSignatureSkew || skew > MaxSignatureSkew {
+			return nil, fmt.Errorf("%w: timestamp is more than %s from the server clock", ErrInvalidSignature, MaxSignatureSkew)
+		}
+		// Verify over the UTC timestamp, as stored, so VerifyReview
+		// accepts the review later.
+		timestamp = opts.SignatureTimestamp.UTC()
+		if !db.VerifyReviewSignature(opts.SessionKey, opts.RequestID, commandHash, opts.Decision, timestamp, opts.Signature) {
+			return nil, ErrInvalidSignature
+		}
+		signature = opts.Signature
+	}
 
 	review := &db.Review{
 		RequestID:          opts.RequestID,
@@ -187,8 +369,11 @@ func (rs *ReviewService) SubmitReview(opts ReviewOptions) (*ReviewResult, error)
 		ReviewerAgent:      session.AgentName,
 		ReviewerModel:      session.Model,
 		Decision:           opts.Decision,
+		Segments:           segments,
 		Signature:          signature,
 		SignatureTimestamp: timestamp,
+		CommandHash:        commandHash,
+		DelegationID:       opts.DelegationID,
 		Responses:          opts.Responses,
 		Comments:           opts.Comments,
 	}
@@ -213,6 +398,20 @@ func (rs *ReviewService) SubmitReview(opts ReviewOptions) (*ReviewResult, error)
 		if err := rs.db.CreateReviewTx(tx, review); err != nil {
 			return fmt.Errorf("creating review: %w", err)
 		}
+		if opts.Expedite {
+			if err := rs.db.SetExecutionPriorityTx(tx, opts.RequestID, ExpeditedExecutionPriority); err != nil {
+				return err
+			}
+		}
+		if opts.CallbackID != "" {
+			if err := rs.db.RecordReviewCallbackTx(tx, &db.ReviewCallback{
+				CallbackID: opts.CallbackID,
+				RequestID:  opts.RequestID,
+				ReviewID:   review.ID,
+			}); err != nil {
+				return err
+			}
+		}
 
 		approvals, rejections, err := rs.db.CountReviewsByDecisionTx(tx, opts.RequestID)
 		if err != nil {
@@ -227,20 +426,53 @@ func (rs *ReviewService) SubmitReview(opts ReviewOptions) (*ReviewResult, error)
 			return fmt.Errorf("getting request: %w", err)
 		}
 
-		// Apply conflict resolution rules
-		newStatus := rs.determineNewStatus(reqTx, opts.Decision, approvals, rejections)
+		// Apply conflict resolution rules, per segment once any review
+		// decided on individual segments
+		reviews, err := rs.db.ListReviewsForRequestTx(tx, opts.RequestID)
+		if err != nil {
+			return fmt.Errorf("listing reviews: %w", err)
+		}
+		reviews, result.ExcludedReviewers = eligibleReviews(reqTx, reviews, flagged)
+		reviews, result.SameHostReviewers = differentHostReviews(reviews, sameHost)
+		if len(result.ExcludedReviewers) > 0 || len(result.SameHostReviewers) > 0 {
+			approvals, rejections = countDecisions(reviews)
+			result.Approvals = approvals
+			result.Rejections = rejections
+		}
+		var newStatus db.RequestStatus
+		var explanation string
+		if hasSegmentReviews(reviews) {
+			newStatus, result.ApprovedSegments, explanation = rs.determineSegmentStatus(reqTx, reviews, segmentCount)
+		} else {
+			newStatus, explanation = rs.determineNewStatus(reqTx, opts.Decision, rs.approvalWeight(reviews), rejections, rs.missingRoles(reqTx, approvingAgents(reviews)))
+		}
 		if newStatus != "" && newStatus != reqTx.Status {
+			if len(result.ApprovedSegments) > 0 {
+				if err := rs.db.UpdateApprovedSegmentsTx(tx, opts.RequestID, result.ApprovedSegments); err != nil {
+					return err
+				}
+			}
 			// Pass current status for optimistic locking check
 			if err := rs.db.UpdateRequestStatusTx(tx, opts.RequestID, newStatus, reqTx.Status); err != nil {
 				return fmt.Errorf("updating request status: %w", err)
 			}
 			result.RequestStatusChanged = true
 			result.NewRequestStatus = newStatus
+			result.ResolutionApplied = &ResolutionApplied{
+				Resolution:  rs.config.ConflictResolution,
+				Explanation: explanation,
+			}
 		}
 		return nil
 	})
 
 	if err != nil {
+		// A concurrent delivery of the same callback won the race
+		if opts.CallbackID != "" && (errors.Is(err, ErrAlreadyReviewed) || errors.Is(err, db.ErrCallbackProcessed)) {
+			if replayed, rerr := rs.ReplayedCallback(opts); rerr != nil || replayed != nil {
+				return replayed, rerr
+			}
+		}
 		return nil, err
 	}
 
@@ -255,6 +487,122 @@ func (rs *ReviewService) SubmitReview(opts ReviewOptions) (*ReviewResult, error)
 	return result, nil
 }
 
+// ReplayedCallback returns the result of the review already recorded for
+// opts.CallbackID, or nil if that callback has not been processed. The
+// callback must have delivered the same decision on the same request by the
+// same session, whose key opts must hold; anything else is
+// ErrCallbackConflict.
+func (rs *ReviewService) ReplayedCallback(opts ReviewOptions) (*ReviewResult, error) {
+	if opts.CallbackID == "" {
+		return nil, nil
+	}
+	cb, err := rs.db.GetReviewCallback(opts.CallbackID)
+	if err != nil || cb == nil {
+		return nil, err
+	}
+	review, err := rs.db.GetReview(cb.ReviewID)
+	if err != nil {
+		return nil, fmt.Errorf("getting callback review: %w", err)
+	}
+	if review.RequestID != opts.RequestID || review.ReviewerSessionID != opts.SessionID || review.Decision != opts.Decision {
+		return nil, fmt.Errorf("%w: %s was processed as %s of request %s", ErrCallbackConflict, opts.CallbackID, review.Decision, review.RequestID)
+	}
+	session, err := rs.db.GetSession(opts.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("getting session: %w", err)
+	}
+	if opts.SessionKey != session.SessionKey {
+		return nil, ErrSessionKeyMismatch
+	}
+	approvals, rejections, err := rs.db.CountReviewsByDecision(opts.RequestID)
+	if err != nil {
+		return nil, fmt.Errorf("counting reviews: %w", err)
+	}
+	return &ReviewResult{
+		Review:     review,
+		Approvals:  approvals,
+		Rejections: rejections,
+		Replayed:   true,
+	}, nil
+}
+
+// WithdrawReview deletes the session's review of a request that is still
+// awaiting approval. The remaining reviews keep their effect: a withdrawn
+// approval simply stops counting toward MinApprovals. A request escalated by
+// a tie (ConflictHumanBreaksTie) that the remaining reviews no longer form
+// goes back to pending for another review to decide. Approved and finished
+// requests cannot have reviews withdrawn.
+func (rs *ReviewService) WithdrawReview(sessionID, requestID string) error {
+	if sessionID == "" {
+		return errors.New("session_id is required")
+	}
+	if requestID == "" {
+		return errors.New("request_id is required")
+	}
+
+	session, err := rs.db.GetSession(sessionID)
+	if err != nil {
+		return fmt.Errorf("getting session: %w", err)
+	}
+	if !session.IsActive() {
+		return ErrSessionInactive
+	}
+
+	return rs.db.Transaction(func(tx *sql.Tx) error {
+		request, err := rs.db.GetRequestTx(tx, requestID)
+		if err != nil {
+			return fmt.Errorf("getting request: %w", err)
+		}
+		if !CanApprove(request.Status) {
+			return fmt.Errorf("%w: status is %s", ErrRequestNotPending, request.Status)
+		}
+
+		before, err := rs.db.ListReviewsForRequestTx(tx, requestID)
+		if err != nil {
+			return fmt.Errorf("listing reviews: %w", err)
+		}
+		if err := rs.db.DeleteReviewTx(tx, requestID, sessionID); err != nil {
+			if errors.Is(err, db.ErrReviewNotFound) {
+				return ErrNoReviewToWithdraw
+			}
+			return err
+		}
+		if request.Status != db.StatusEscalated {
+			return nil
+		}
+
+		// Recount: only a tie can be undone by removing a review
+		after, err := rs.db.ListReviewsForRequestTx(tx, requestID)
+		if err != nil {
+			return fmt.Errorf("listing reviews: %w", err)
+		}
+		segmentCount := len(CommandSegments(request.Command.Raw))
+		if !rs.tied(request, before, segmentCount) || rs.tied(request, after, segmentCount) {
+			return nil
+		}
+		if err := rs.db.UpdateRequestStatusTx(tx, requestID, db.StatusPending, request.Status); err != nil {
+			return fmt.Errorf("updating request status: %w", err)
+		}
+		return nil
+	})
+}
+
+// tied reports whether the conflict resolution rules escalate a request with
+// these reviews, oldest first.
+func (rs *ReviewService) tied(request *db.Request, reviews []*db.Review, segmentCount int) bool {
+	if len(reviews) == 0 {
+		return false
+	}
+	if hasSegmentReviews(reviews) {
+		status, _, _ := rs.determineSegmentStatus(request, reviews, segmentCount)
+		return status == db.StatusEscalated
+	}
+	_, rejections := countDecisions(reviews)
+	last := reviews[len(reviews)-1].Decision
+	status, _ := rs.determineNewStatus(request, last, rs.approvalWeight(reviews), rejections, rs.missingRoles(request, approvingAgents(reviews)))
+	return status == db.StatusEscalated
+}
+
 // isTrustedSelfApprove checks if an agent is in the trusted self-approve list.
 func (rs *ReviewService) isTrustedSelfApprove(agentName string) bool {
 	for _, trusted := range rs.config.TrustedSelfApprove {
@@ -265,48 +613,263 @@ func (rs *ReviewService) isTrustedSelfApprove(agentName string) bool {
 	return false
 }
 
-// determineNewStatus determines what status the request should transition to.
+// eligibleReviews filters out reviews that may not count toward the request's
+// quorum before determineNewStatus sees them: on CRITICAL requests, approvals
+// (including segment decisions) by reviewers in flagged. Rejections by
+// flagged reviewers still count. It returns the remaining reviews and the
+// agents whose approvals were excluded.
+func eligibleReviews(request *db.Request, reviews []*db.Review, flagged map[string]bool) ([]*db.Review, []string) {
+	if request.RiskTier != db.RiskTierCritical || len(flagged) == 0 {
+		return reviews, nil
+	}
+	eligible := make([]*db.Review, 0, len(reviews))
+	var excluded []string
+	for _, r := range reviews {
+		approves := r.Decision == db.DecisionApprove || len(r.Segments) > 0
+		if approves && flagged[r.ReviewerAgent] {
+			excluded = append(excluded, r.ReviewerAgent)
+			continue
+		}
+		eligible = append(eligible, r)
+	}
+	return eligible, excluded
+}
+
+// sameHostReviewers returns, keyed by reviewer session ID, which reviews
+// come from sessions not provably on a different host than the requestor.
+func sameHostReviewers(database *db.DB, requestor *db.Session, reviews []*db.Review) (map[string]bool, error) {
+	same := make(map[string]bool, len(reviews))
+	for _, r := range reviews {
+		reviewer, err := database.GetSession(r.ReviewerSessionID)
+		if err != nil && !errors.Is(err, db.ErrSessionNotFound) {
+			return nil, fmt.Errorf("getting reviewer session: %w", err)
+		}
+		if !reviewer.OnDifferentHost(requestor) {
+			same[r.ReviewerSessionID] = true
+		}
+	}
+	return same, nil
+}
+
+// differentHostReviews drops approvals (including segment decisions) by
+// reviewers in sameHost, keyed by session ID; rejections still count. It
+// returns the remaining reviews and the agents whose approvals were dropped.
+func differentHostReviews(reviews []*db.Review, sameHost map[string]bool) ([]*db.Review, []string) {
+	if len(sameHost) == 0 {
+		return reviews, nil
+	}
+	eligible := make([]*db.Review, 0, len(reviews))
+	var excluded []string
+	for _, r := range reviews {
+		approves := r.Decision == db.DecisionApprove || len(r.Segments) > 0
+		if approves && sameHost[r.ReviewerSessionID] {
+			excluded = append(excluded, r.ReviewerAgent)
+			continue
+		}
+		eligible = append(eligible, r)
+	}
+	return eligible, excluded
+}
+
+// hostLabel describes a session's host for error messages.
+func hostLabel(s *db.Session) string {
+	switch {
+	case s == nil:
+		return "unknown session"
+	case s.Hostname != "":
+		return s.Hostname
+	case s.MachineID != "":
+		return "machine " + s.MachineID
+	default:
+		return "unrecorded"
+	}
+}
+
+// countDecisions counts approvals and rejections among reviews.
+func countDecisions(reviews []*db.Review) (approvals, rejections int) {
+	for _, r := range reviews {
+		if r.Decision == db.DecisionApprove {
+			approvals++
+		} else {
+			rejections++
+		}
+	}
+	return approvals, rejections
+}
+
+// reviewerWeight returns how much an approval by agent counts toward
+// MinApprovals: its configured weight under ConflictWeightedQuorum, else 1.
+func (rs *ReviewService) reviewerWeight(agent string) int {
+	if rs.config.ConflictResolution != ConflictWeightedQuorum {
+		return 1
+	}
+	if w, ok := rs.config.ReviewerWeights[agent]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// approvalWeight sums the weight of the approvals among reviews.
+func (rs *ReviewService) approvalWeight(reviews []*db.Review) int {
+	total := 0
+	for _, r := range reviews {
+		if r.Decision == db.DecisionApprove {
+			total += rs.reviewerWeight(r.ReviewerAgent)
+		}
+	}
+	return total
+}
+
+// approvingAgents returns the agents that approved among reviews.
+func approvingAgents(reviews []*db.Review) []string {
+	var agents []string
+	for _, r := range reviews {
+		if r.Decision == db.DecisionApprove {
+			agents = append(agents, r.ReviewerAgent)
+		}
+	}
+	return agents
+}
+
+// missingRoles returns the request's required roles that none of approvers
+// holds, in the request's order.
+func (rs *ReviewService) missingRoles(request *db.Request, approvers []string) []string {
+	var missing []string
+	for _, role := range request.RequiredRoles {
+		if !rs.anyHoldsRole(approvers, role) {
+			missing = append(missing, role)
+		}
+	}
+	return missing
+}
+
+// anyHoldsRole reports whether one of agents holds role.
+func (rs *ReviewService) anyHoldsRole(agents []string, role string) bool {
+	for _, agent := range agents {
+		for _, r := range rs.config.AgentRoles[agent] {
+			if r == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// determineNewStatus determines what status the request should transition to,
+// with a short explanation of the rule that decided it.
+// Under ConflictWeightedQuorum, approvals is the approvals' total weight.
+// missingRoles are the request's required roles no approver holds yet; the
+// request is not approved while any remain.
 func (rs *ReviewService) determineNewStatus(
 	request *db.Request,
 	decision db.Decision,
 	approvals, rejections int,
-) db.RequestStatus {
+	missingRoles []string,
+) (db.RequestStatus, string) {
+	rolesMet := len(missingRoles) == 0
 	switch rs.config.ConflictResolution {
-	case ConflictAnyRejectionBlocks:
+	case ConflictAnyRejectionBlocks, ConflictWeightedQuorum:
 		// Any rejection immediately blocks
 		if rejections > 0 {
-			return db.StatusRejected
+			return db.StatusRejected, "blocked by a rejection"
 		}
 		// Check if we have enough approvals
-		if approvals >= request.MinApprovals {
-			return db.StatusApproved
+		if approvals >= request.MinApprovals && rolesMet {
+			return db.StatusApproved, quorumExplanation(rs.config.ConflictResolution, approvals, request.MinApprovals)
 		}
 
 	case ConflictFirstWins:
 		// First review determines outcome
 		if approvals+rejections == 1 {
 			if decision == db.DecisionApprove {
-				return db.StatusApproved
+				if rolesMet {
+					return db.StatusApproved, "first review approved"
+				}
+				break
+			}
+			return db.StatusRejected, "first review rejected"
+		}
+		// A first approval held for required roles is decided by the first
+		// rejection or by the approval that completes the roles
+		if len(request.RequiredRoles) > 0 {
+			if rejections > 0 {
+				return db.StatusRejected, "rejected before the required roles approved"
+			}
+			if rolesMet {
+				return db.StatusApproved, "first approval held until the required roles approved"
 			}
-			return db.StatusRejected
 		}
 
 	case ConflictHumanBreaksTie:
 		// If there's a mix of approvals and rejections, escalate
 		if approvals > 0 && rejections > 0 {
-			return db.StatusEscalated
+			return db.StatusEscalated, "escalated due to mixed approvals"
 		}
 		// Otherwise, check if we have enough approvals
-		if approvals >= request.MinApprovals {
-			return db.StatusApproved
+		if approvals >= request.MinApprovals && rolesMet {
+			return db.StatusApproved, quorumExplanation(rs.config.ConflictResolution, approvals, request.MinApprovals)
 		}
 		// Or if any rejections
 		if rejections > 0 {
-			return db.StatusRejected
+			return db.StatusRejected, "rejected with no approvals"
+		}
+	}
+
+	return "", "" // No status change
+}
+
+// quorumExplanation explains an approval by quorum.
+func quorumExplanation(resolution ConflictResolution, approvals, minApprovals int) string {
+	if resolution == ConflictWeightedQuorum {
+		return fmt.Sprintf("approval weight %d met quorum of %d", approvals, minApprovals)
+	}
+	return fmt.Sprintf("%d of %d required approvals", approvals, minApprovals)
+}
+
+// determineSegmentStatus applies the conflict resolution rules to each segment
+// of a compound command. The request only changes status once every segment
+// is decided; if some segments are approved and others rejected, it is
+// approved with the approved segments returned.
+func (rs *ReviewService) determineSegmentStatus(
+	request *db.Request,
+	reviews []*db.Review,
+	segmentCount int,
+) (db.RequestStatus, []int, string) {
+	var approved []int
+	rejected := 0
+	for seg := 1; seg <= segmentCount; seg++ {
+		var approvals, rejections int
+		var last db.Decision
+		var approvers []string
+		for _, r := range reviews {
+			last = SegmentDecision(r, seg)
+			if last == db.DecisionApprove {
+				approvals += rs.reviewerWeight(r.ReviewerAgent)
+				approvers = append(approvers, r.ReviewerAgent)
+			} else {
+				rejections++
+			}
+		}
+		status, explanation := rs.determineNewStatus(request, last, approvals, rejections, rs.missingRoles(request, approvers))
+		switch status {
+		case db.StatusApproved:
+			approved = append(approved, seg)
+		case db.StatusRejected:
+			rejected++
+		case db.StatusEscalated:
+			return db.StatusEscalated, nil, fmt.Sprintf("segment %d %s", seg, explanation)
+		default:
+			return "", nil, "" // Segment still undecided
 		}
 	}
 
-	return "" // No status change
+	if len(approved) == 0 {
+		return db.StatusRejected, nil, "every segment rejected"
+	}
+	if rejected == 0 {
+		return db.StatusApproved, nil, "every segment approved"
+	}
+	return db.StatusApproved, approved, fmt.Sprintf("%d of %d segments approved", len(approved), segmentCount)
 }
 
 // VerifyReview validates a review's signature.
@@ -314,6 +877,7 @@ func VerifyReview(review *db.Review, sessionKey string) bool {
 	return db.VerifyReviewSignature(
 		sessionKey,
 		review.RequestID,
+		review.CommandHash,
 		review.Decision,
 		review.SignatureTimestamp,
 		review.Signature,
@@ -377,9 +941,17 @@ type ReviewStatus struct {
 	NeedsMoreApprovals bool
 	// Reviews contains all reviews for the request.
 	Reviews []*db.Review
+	// UncountedApprovals explains each approval that does not count toward
+	// MinApprovals, e.g. because it came from the requestor's host. Approvals
+	// excludes them.
+	UncountedApprovals []string
+	// MissingRoles lists the request's required roles that no counted
+	// approval comes from yet.
+	MissingRoles []string
 }
 
-// GetReviewStatus retrieves the current review status for a request.
+// GetReviewStatus retrieves the current review status for a request. Its
+// reviews and uncounted approvals are anonymized as ReviewsForViewer does.
 func (rs *ReviewService) GetReviewStatus(requestID string) (*ReviewStatus, error) {
 	request, err := rs.db.GetRequest(requestID)
 	if err != nil {
@@ -396,13 +968,44 @@ func (rs *ReviewService) GetReviewStatus(requestID string) (*ReviewStatus, error
 		return nil, fmt.Errorf("counting reviews: %w", err)
 	}
 
+	var uncounted []string
+	counted := reviews
+	if request.RequireDifferentHost {
+		requestor, err := rs.db.GetSession(request.RequestorSessionID)
+		if err != nil && !errors.Is(err, db.ErrSessionNotFound) {
+			return nil, fmt.Errorf("getting requestor session: %w", err)
+		}
+		sameHost, err := sameHostReviewers(rs.db, requestor, reviews)
+		if err != nil {
+			return nil, err
+		}
+		counted, _ = differentHostReviews(reviews, sameHost)
+		for _, r := range reviews {
+			if r.Decision == db.DecisionApprove && sameHost[r.ReviewerSessionID] {
+				approvals--
+				uncounted = append(uncounted, fmt.Sprintf(
+					"approval by %s does not count: request requires a different host and the reviewer's is not provably different from the requestor's (%s)",
+					r.ReviewerAgent, hostLabel(requestor)))
+			}
+		}
+	}
+
+	missing := rs.missingRoles(request, approvingAgents(counted))
+
+	shown, names := rs.ReviewsForViewer(request, reviews)
+	for i, reason := range uncounted {
+		uncounted[i] = AnonymizeText(reason, names)
+	}
+
 	return &ReviewStatus{
 		RequestStatus:      request.Status,
 		Approvals:          approvals,
 		Rejections:         rejections,
 		MinApprovals:       request.MinApprovals,
-		NeedsMoreApprovals: approvals < request.MinApprovals && request.Status == db.StatusPending,
-		Reviews:            reviews,
+		NeedsMoreApprovals: (rs.approvalWeight(counted) < request.MinApprovals || len(missing) > 0) && request.Status == db.StatusPending,
+		Reviews:            shown,
+		UncountedApprovals: uncounted,
+		MissingRoles:       missing,
 	}, nil
 }
 
@@ -494,7 +1097,6 @@ func (rs *ReviewService) CheckDifferentModelEscalation(requestID string) (*Diffe
 
 // EscalateDifferentModelTimeout escalates a request to human review because
 // no different-model reviewer was available within the timeout.
-// The state machine requires pending→timeout→escalated transitions.
 func (rs *ReviewService) EscalateDifferentModelTimeout(requestID string) error {
 	// Verify escalation is warranted
 	status, err := rs.CheckDifferentModelEscalation(requestID)
@@ -506,20 +1108,6 @@ func (rs *ReviewService) EscalateDifferentModelTimeout(requestID string) error {
 		return errors.New("escalation not warranted: different model available or timeout not expired")
 	}
 
-	// Get current request status
-	request, err := rs.db.GetRequest(requestID)
-	if err != nil {
-		return fmt.Errorf("getting request: %w", err)
-	}
-
-	// State machine requires: pending → timeout → escalated
-	if request.Status == db.StatusPending {
-		if err := rs.db.UpdateRequestStatus(requestID, db.StatusTimeout); err != nil {
-			return fmt.Errorf("transitioning to timeout: %w", err)
-		}
-	}
-
-	// Now transition to escalated
 	if err := rs.db.UpdateRequestStatus(requestID, db.StatusEscalated); err != nil {
 		return fmt.Errorf("transitioning to escalated: %w", err)
 	}
@@ -556,3 +1144,67 @@ func (rs *ReviewService) CheckAndEscalatePendingRequests(projectPath string) (in
 
 	return escalated, nil
 }
+
+// SweepEscalations escalates to human review every pending request that has
+// waited longer than its escalate-after age as of now without a rejection in
+// its current round, and returns how many it escalated. See
+// EscalateStaleRequests.
+func (rs *ReviewService) SweepEscalations(now time.Time) (int, error) {
+	escalated, err := rs.EscalateStaleRequests(now)
+	return len(escalated), err
+}
+
+// EscalateStaleRequests moves each pending request older than its tier's
+// EscalateAfterTiers age, or EscalateAfter when its tier has none, to
+// escalated and returns the requests it moved. A request with a rejection is
+// left to ConflictResolution. Each change is conditional on the request still
+// being pending, so one reviewed or cancelled meanwhile is left alone.
+func (rs *ReviewService) EscalateStaleRequests(now time.Time) ([]*db.Request, error) {
+	if rs.config.EscalateAfter <= 0 && len(rs.config.EscalateAfterTiers) == 0 {
+		return nil, nil
+	}
+	requests, err := rs.db.ListPendingRequestsAllProjects()
+	if err != nil {
+		return nil, fmt.Errorf("listing pending requests: %w", err)
+	}
+
+	var escalated []*db.Request
+	for _, req := range requests {
+		after := rs.escalateAfter(req.RiskTier)
+		if after <= 0 || now.Sub(req.CreatedAt) < after {
+			continue
+		}
+		err := rs.db.Transaction(func(tx *sql.Tx) error {
+			_, rejections, err := rs.db.CountReviewsByDecisionTx(tx, req.ID)
+			if err != nil {
+				return err
+			}
+			if rejections > 0 {
+				return errNoEscalation
+			}
+			return rs.db.UpdateRequestStatusTx(tx, req.ID, db.StatusEscalated, db.StatusPending)
+		})
+		switch {
+		case err == nil:
+			req.Status = db.StatusEscalated
+			escalated = append(escalated, req)
+		case errors.Is(err, errNoEscalation), errors.Is(err, db.ErrInvalidTransition):
+		default:
+			return escalated, fmt.Errorf("escalating request %s: %w", req.ID, err)
+		}
+	}
+	return escalated, nil
+}
+
+// escalateAfter returns how long a pending request of tier waits before it
+// is escalated; zero means never.
+func (rs *ReviewService) escalateAfter(tier db.RiskTier) time.Duration {
+	if after := rs.config.EscalateAfterTiers[tier]; after > 0 {
+		return after
+	}
+	return rs.config.EscalateAfter
+}
+
+// errNoEscalation rolls back an escalation transaction for a request that
+// has been rejected.
+var errNoEscalation = errors.New("request has a rejection")
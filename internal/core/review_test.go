@@ -1,10 +1,13 @@
 package core
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
 )
 
 // setupReviewTest creates a DB with a session and request for testing.
@@ -392,6 +395,53 @@ func TestSubmitReview_SessionKeyMismatch_Rejected(t *testing.T) {
 	}
 }
 
+func TestSubmitReview_ClientSignature(t *testing.T) {
+	dbConn, _, req := setupReviewTest(t)
+	defer dbConn.Close()
+
+	reviewerSess := &db.Session{
+		AgentName:   "GreenLake",
+		Program:     "claude-code",
+		Model:       "opus-4.5",
+		ProjectPath: "/test/project",
+	}
+	if err := dbConn.CreateSession(reviewerSess); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+	opts := func(sig string, ts time.Time) ReviewOptions {
+		return ReviewOptions{
+			SessionID:          reviewerSess.ID,
+			SessionKey:         reviewerSess.SessionKey,
+			RequestID:          req.ID,
+			Decision:           db.DecisionApprove,
+			Signature:          sig,
+			SignatureTimestamp: ts,
+		}
+	}
+
+	stale := time.Now().Add(-MaxSignatureSkew - time.Minute)
+	staleSig := db.ComputeReviewSignature(reviewerSess.SessionKey, req.ID, req.Command.Hash, db.DecisionApprove, stale)
+	if _, err := rs.SubmitReview(opts(staleSig, stale)); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for a stale timestamp, got %v", err)
+	}
+	ts := time.Now().Truncate(time.Second)
+	if _, err := rs.SubmitReview(opts("not-a-signature", ts)); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for a bad signature, got %v", err)
+	}
+
+	// A client in another time zone signs over the UTC timestamp.
+	local := ts.In(time.FixedZone("UTC+2", 2*60*60))
+	sig := db.ComputeReviewSignature(reviewerSess.SessionKey, req.ID, req.Command.Hash, db.DecisionApprove, local.UTC())
+	result, err := rs.SubmitReview(opts(sig, local))
+	if err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+	if result.Review.Signature != sig || !result.Review.SignatureTimestamp.Equal(ts) {
+		t.Errorf("expected the client signature to be stored, got %+v", result.Review)
+	}
+}
+
 func TestSubmitReview_MissingSessionKey_Rejected(t *testing.T) {
 	dbConn, _, req := setupReviewTest(t)
 	defer dbConn.Close()
@@ -487,6 +537,7 @@ type mockRequestNotifier struct {
 	approvedCalled   bool
 	rejectedCalled   bool
 	executedCalled   bool
+	reconfirmAgents  []string
 }
 
 func (m *mockRequestNotifier) NotifyNewRequest(req *db.Request) error {
@@ -509,6 +560,13 @@ func (m *mockRequestNotifier) NotifyRequestExecuted(req *db.Request, exec *db.Ex
 	return nil
 }
 
+func (m *mockRequestNotifier) NotifyReconfirmRequested(req *db.Request, priorApprovals []*db.Review) error {
+	for _, r := range priorApprovals {
+		m.reconfirmAgents = append(m.reconfirmAgents, r.ReviewerAgent)
+	}
+	return nil
+}
+
 func TestIsTrustedSelfApprove(t *testing.T) {
 	dbConn, err := db.Open(":memory:")
 	if err != nil {
@@ -650,6 +708,25 @@ func TestDetermineNewStatus(t *testing.T) {
 			wantStatus: "",
 		},
 
+		// ConflictWeightedQuorum tests (approvals is the approval weight)
+		{
+			name:       "weighted_quorum: enough weight",
+			resolution: ConflictWeightedQuorum,
+			request:    &db.Request{MinApprovals: 3},
+			decision:   db.DecisionApprove,
+			approvals:  3,
+			rejections: 0,
+			wantStatus: db.StatusApproved,
+		},
+		{
+			name:       "weighted_quorum: rejection blocks",
+			resolution: ConflictWeightedQuorum,
+			request:    &db.Request{MinApprovals: 3},
+			decision:   db.DecisionReject,
+			approvals:  5,
+			rejections: 1,
+			wantStatus: db.StatusRejected,
+		},
 		// ConflictHumanBreaksTie tests
 		{
 			name:       "human_breaks_tie: mixed reviews escalate",
@@ -693,7 +770,7 @@ func TestDetermineNewStatus(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			config := ReviewConfig{ConflictResolution: tc.resolution}
 			rs := NewReviewService(dbConn, config)
-			got := rs.determineNewStatus(tc.request, tc.decision, tc.approvals, tc.rejections)
+			got, _ := rs.determineNewStatus(tc.request, tc.decision, tc.approvals, tc.rejections, nil)
 			if got != tc.wantStatus {
 				t.Errorf("determineNewStatus() = %q, want %q", got, tc.wantStatus)
 			}
@@ -706,11 +783,12 @@ func TestVerifyReview(t *testing.T) {
 	sessionKey := "deadbeef0123456789abcdef0123456789abcdef0123456789abcdef01234567"
 	wrongKey := "cafebabe0123456789abcdef0123456789abcdef0123456789abcdef01234567"
 	requestID := "req-abc-123"
+	commandHash := db.ComputeCommandHash(db.CommandSpec{Raw: "rm -rf ./build", Cwd: "/tmp", Shell: true})
 	decision := db.DecisionApprove
 	timestamp := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
 
 	// Compute expected signature
-	expectedSig := db.ComputeReviewSignature(sessionKey, requestID, decision, timestamp)
+	expectedSig := db.ComputeReviewSignature(sessionKey, requestID, commandHash, decision, timestamp)
 
 	tests := []struct {
 		name       string
@@ -722,6 +800,7 @@ func TestVerifyReview(t *testing.T) {
 			name: "valid signature",
 			review: &db.Review{
 				RequestID:          requestID,
+				CommandHash:        commandHash,
 				Decision:           decision,
 				Signature:          expectedSig,
 				SignatureTimestamp: timestamp,
@@ -733,6 +812,7 @@ func TestVerifyReview(t *testing.T) {
 			name: "wrong session key",
 			review: &db.Review{
 				RequestID:          requestID,
+				CommandHash:        commandHash,
 				Decision:           decision,
 				Signature:          expectedSig,
 				SignatureTimestamp: timestamp,
@@ -744,6 +824,7 @@ func TestVerifyReview(t *testing.T) {
 			name: "tampered request ID",
 			review: &db.Review{
 				RequestID:          "tampered-id",
+				CommandHash:        commandHash,
 				Decision:           decision,
 				Signature:          expectedSig,
 				SignatureTimestamp: timestamp,
@@ -755,6 +836,7 @@ func TestVerifyReview(t *testing.T) {
 			name: "tampered decision",
 			review: &db.Review{
 				RequestID:          requestID,
+				CommandHash:        commandHash,
 				Decision:           db.DecisionReject,
 				Signature:          expectedSig,
 				SignatureTimestamp: timestamp,
@@ -762,10 +844,23 @@ func TestVerifyReview(t *testing.T) {
 			sessionKey: sessionKey,
 			want:       false,
 		},
+		{
+			name: "tampered command hash",
+			review: &db.Review{
+				RequestID:          requestID,
+				CommandHash:        db.ComputeCommandHash(db.CommandSpec{Raw: "rm -rf /", Cwd: "/tmp", Shell: true}),
+				Decision:           decision,
+				Signature:          expectedSig,
+				SignatureTimestamp: timestamp,
+			},
+			sessionKey: sessionKey,
+			want:       false,
+		},
 		{
 			name: "tampered timestamp",
 			review: &db.Review{
 				RequestID:          requestID,
+				CommandHash:        commandHash,
 				Decision:           decision,
 				Signature:          expectedSig,
 				SignatureTimestamp: timestamp.Add(time.Hour),
@@ -1033,6 +1128,46 @@ func TestSubmitReview_SessionErrors(t *testing.T) {
 	})
 }
 
+func TestSubmitReview_StaleSession(t *testing.T) {
+	dbConn, _, req := setupReviewTest(t)
+	defer dbConn.Close()
+
+	sess := &db.Session{
+		AgentName:   "GreenLake",
+		Program:     "claude-code",
+		Model:       "opus-4.5",
+		ProjectPath: "/test/project",
+	}
+	if err := dbConn.CreateSession(sess); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	lastSeen := time.Now().UTC().Add(-2 * time.Hour).Format(time.RFC3339)
+	if _, err := dbConn.Exec(`UPDATE sessions SET last_active_at = ? WHERE id = ?`, lastSeen, sess.ID); err != nil {
+		t.Fatalf("backdating session: %v", err)
+	}
+
+	cfg := DefaultReviewConfig()
+	cfg.SessionIdleTimeout = time.Hour
+	rs := NewReviewService(dbConn, cfg)
+	opts := ReviewOptions{
+		SessionID:  sess.ID,
+		SessionKey: sess.SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+	}
+	if _, err := rs.SubmitReview(opts); !errors.Is(err, ErrSessionStale) {
+		t.Fatalf("expected ErrSessionStale, got %v", err)
+	}
+
+	// A heartbeat brings the session back within the window.
+	if err := dbConn.UpdateSessionHeartbeat(sess.ID); err != nil {
+		t.Fatalf("UpdateSessionHeartbeat() error = %v", err)
+	}
+	if _, err := rs.SubmitReview(opts); err != nil {
+		t.Fatalf("SubmitReview() after heartbeat error = %v", err)
+	}
+}
+
 func TestSubmitReview_RequestErrors(t *testing.T) {
 	t.Run("request not pending", func(t *testing.T) {
 		dbConn, _, req := setupReviewTest(t)
@@ -1237,6 +1372,256 @@ func TestSubmitReview_Rejection(t *testing.T) {
 	}
 }
 
+func TestSubmitReview_WeightedQuorum(t *testing.T) {
+	dbConn, sess, _ := setupReviewTest(t)
+	defer dbConn.Close()
+
+	reviewer := func(name string) *db.Session {
+		t.Helper()
+		s := &db.Session{AgentName: name, Program: "claude-code", Model: "opus-4.5", ProjectPath: "/test/project"}
+		if err := dbConn.CreateSession(s); err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+		return s
+	}
+	heavy, light1, light2 := reviewer("SeniorOwl"), reviewer("JuniorFox"), reviewer("JuniorCat")
+	newRequest := func() *db.Request {
+		t.Helper()
+		req := &db.Request{
+			ProjectPath:        "/test/project",
+			RequestorSessionID: sess.ID,
+			RequestorAgent:     sess.AgentName,
+			RequestorModel:     sess.Model,
+			RiskTier:           db.RiskTierCritical,
+			MinApprovals:       3,
+			Command:            db.CommandSpec{Raw: "terraform destroy", Cwd: "/test/project"},
+			Justification:      db.Justification{Reason: "Tearing down staging"},
+		}
+		if err := dbConn.CreateRequest(req); err != nil {
+			t.Fatalf("CreateRequest() error = %v", err)
+		}
+		return req
+	}
+	review := func(rs *ReviewService, s *db.Session, req *db.Request, decision db.Decision) *ReviewResult {
+		t.Helper()
+		result, err := rs.SubmitReview(ReviewOptions{
+			SessionID: s.ID, SessionKey: s.SessionKey, RequestID: req.ID, Decision: decision,
+		})
+		if err != nil {
+			t.Fatalf("SubmitReview() error = %v", err)
+		}
+		return result
+	}
+
+	config := DefaultReviewConfig()
+	config.ConflictResolution = ConflictWeightedQuorum
+	config.ReviewerWeights = map[string]int{"SeniorOwl": 3, "JuniorFox": 2, "JuniorCat": 1}
+	rs := NewReviewService(dbConn, config)
+
+	// One heavy reviewer meets the quorum alone.
+	req := newRequest()
+	if result := review(rs, heavy, req, db.DecisionApprove); result.NewRequestStatus != db.StatusApproved {
+		t.Errorf("heavy approval: status = %q, want approved", result.NewRequestStatus)
+	}
+
+	// Two light reviewers meet it together, not alone.
+	req = newRequest()
+	if result := review(rs, light1, req, db.DecisionApprove); result.RequestStatusChanged {
+		t.Errorf("one light approval should not meet quorum, got %q", result.NewRequestStatus)
+	}
+	status, err := rs.GetReviewStatus(req.ID)
+	if err != nil {
+		t.Fatalf("GetReviewStatus() error = %v", err)
+	}
+	if !status.NeedsMoreApprovals {
+		t.Error("expected NeedsMoreApprovals after one light approval")
+	}
+	if result := review(rs, light2, req, db.DecisionApprove); result.NewRequestStatus != db.StatusApproved {
+		t.Errorf("two light approvals: status = %q, want approved", result.NewRequestStatus)
+	}
+
+	// A rejection still blocks, whatever the approval weight.
+	req = newRequest()
+	review(rs, light1, req, db.DecisionApprove)
+	if result := review(rs, light2, req, db.DecisionReject); result.NewRequestStatus != db.StatusRejected {
+		t.Errorf("rejection: status = %q, want rejected", result.NewRequestStatus)
+	}
+
+	// Weights are ignored under other conflict resolutions.
+	rs = NewReviewService(dbConn, ReviewConfig{ConflictResolution: ConflictAnyRejectionBlocks, ReviewerWeights: config.ReviewerWeights})
+	req = newRequest()
+	if result := review(rs, heavy, req, db.DecisionApprove); result.RequestStatusChanged {
+		t.Errorf("weights should not apply under any_rejection_blocks, got %q", result.NewRequestStatus)
+	}
+}
+
+func TestWithdrawReview(t *testing.T) {
+	dbConn, sess, _ := setupReviewTest(t)
+	defer dbConn.Close()
+
+	reviewer := func(name string) *db.Session {
+		t.Helper()
+		s := &db.Session{AgentName: name, Program: "claude-code", Model: "opus-4.5", ProjectPath: "/test/project"}
+		if err := dbConn.CreateSession(s); err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+		return s
+	}
+	first, second := reviewer("GreenLake"), reviewer("RedStone")
+	newRequest := func() *db.Request {
+		t.Helper()
+		req := &db.Request{
+			ProjectPath:        "/test/project",
+			RequestorSessionID: sess.ID,
+			RequestorAgent:     sess.AgentName,
+			RequestorModel:     sess.Model,
+			RiskTier:           db.RiskTierCritical,
+			MinApprovals:       2,
+			Command:            db.CommandSpec{Raw: "terraform destroy", Cwd: "/test/project"},
+			Justification:      db.Justification{Reason: "Tearing down staging"},
+		}
+		if err := dbConn.CreateRequest(req); err != nil {
+			t.Fatalf("CreateRequest() error = %v", err)
+		}
+		return req
+	}
+	review := func(rs *ReviewService, s *db.Session, req *db.Request, decision db.Decision) *ReviewResult {
+		t.Helper()
+		result, err := rs.SubmitReview(ReviewOptions{
+			SessionID: s.ID, SessionKey: s.SessionKey, RequestID: req.ID, Decision: decision,
+		})
+		if err != nil {
+			t.Fatalf("SubmitReview() error = %v", err)
+		}
+		return result
+	}
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+
+	// Approve, withdraw, and the approval no longer counts.
+	req := newRequest()
+	review(rs, first, req, db.DecisionApprove)
+	if err := rs.WithdrawReview(first.ID, req.ID); err != nil {
+		t.Fatalf("WithdrawReview() error = %v", err)
+	}
+	status, err := rs.GetReviewStatus(req.ID)
+	if err != nil {
+		t.Fatalf("GetReviewStatus() error = %v", err)
+	}
+	if status.RequestStatus != db.StatusPending || status.Approvals != 0 || !status.NeedsMoreApprovals {
+		t.Errorf("after withdrawal: status = %s, approvals = %d, needs more = %v", status.RequestStatus, status.Approvals, status.NeedsMoreApprovals)
+	}
+	if err := rs.WithdrawReview(first.ID, req.ID); !errors.Is(err, ErrNoReviewToWithdraw) {
+		t.Errorf("second withdrawal: error = %v, want ErrNoReviewToWithdraw", err)
+	}
+
+	// The reviewer may review again; the request then needs both approvals.
+	if result := review(rs, second, req, db.DecisionApprove); result.RequestStatusChanged {
+		t.Errorf("one approval should not meet quorum, got %q", result.NewRequestStatus)
+	}
+	if result := review(rs, first, req, db.DecisionApprove); result.NewRequestStatus != db.StatusApproved {
+		t.Errorf("re-review: status = %q, want approved", result.NewRequestStatus)
+	}
+	if err := rs.WithdrawReview(first.ID, req.ID); !errors.Is(err, ErrRequestNotPending) {
+		t.Errorf("withdrawal after approval: error = %v, want ErrRequestNotPending", err)
+	}
+
+	// Withdrawing the review that tied an escalated request reverts it to pending.
+	rs = NewReviewService(dbConn, ReviewConfig{ConflictResolution: ConflictHumanBreaksTie})
+	req = newRequest()
+	for _, s := range []db.RequestStatus{db.StatusTimeout, db.StatusEscalated} {
+		if err := dbConn.UpdateRequestStatus(req.ID, s); err != nil {
+			t.Fatalf("UpdateRequestStatus(%s) error = %v", s, err)
+		}
+	}
+	review(rs, first, req, db.DecisionApprove)
+	review(rs, second, req, db.DecisionReject)
+	if err := rs.WithdrawReview(second.ID, req.ID); err != nil {
+		t.Fatalf("WithdrawReview() error = %v", err)
+	}
+	got, err := dbConn.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("GetRequest() error = %v", err)
+	}
+	if got.Status != db.StatusPending {
+		t.Errorf("after undoing the tie: status = %s, want pending", got.Status)
+	}
+}
+
+func TestSubmitReview_ResolutionApplied(t *testing.T) {
+	dbConn, sess, _ := setupReviewTest(t)
+	defer dbConn.Close()
+
+	var reviewers []*db.Session
+	for _, name := range []string{"GreenLake", "RedStone"} {
+		s := &db.Session{AgentName: name, Program: "claude-code", Model: "opus-4.5", ProjectPath: "/test/project"}
+		if err := dbConn.CreateSession(s); err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+		reviewers = append(reviewers, s)
+	}
+
+	tests := []struct {
+		name        string
+		resolution  ConflictResolution
+		decisions   []db.Decision
+		wantStatus  db.RequestStatus
+		explanation string
+	}{
+		{"any_rejection_blocks: quorum met", ConflictAnyRejectionBlocks, []db.Decision{db.DecisionApprove, db.DecisionApprove}, db.StatusApproved, "2 of 2 required approvals"},
+		{"any_rejection_blocks: rejection blocks", ConflictAnyRejectionBlocks, []db.Decision{db.DecisionApprove, db.DecisionReject}, db.StatusRejected, "blocked by a rejection"},
+		{"weighted_quorum: weight met", ConflictWeightedQuorum, []db.Decision{db.DecisionApprove}, db.StatusApproved, "approval weight 2 met quorum of 2"},
+		{"weighted_quorum: rejection blocks", ConflictWeightedQuorum, []db.Decision{db.DecisionReject}, db.StatusRejected, "blocked by a rejection"},
+		{"first_wins: approval", ConflictFirstWins, []db.Decision{db.DecisionApprove}, db.StatusApproved, "first review approved"},
+		{"first_wins: rejection", ConflictFirstWins, []db.Decision{db.DecisionReject}, db.StatusRejected, "first review rejected"},
+		{"human_breaks_tie: mixed reviews", ConflictHumanBreaksTie, []db.Decision{db.DecisionApprove, db.DecisionReject}, db.StatusEscalated, "escalated due to mixed approvals"},
+		{"human_breaks_tie: quorum met", ConflictHumanBreaksTie, []db.Decision{db.DecisionApprove, db.DecisionApprove}, db.StatusApproved, "2 of 2 required approvals"},
+		{"human_breaks_tie: rejection", ConflictHumanBreaksTie, []db.Decision{db.DecisionReject}, db.StatusRejected, "rejected with no approvals"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &db.Request{
+				ProjectPath:        "/test/project",
+				RequestorSessionID: sess.ID,
+				RequestorAgent:     sess.AgentName,
+				RequestorModel:     sess.Model,
+				RiskTier:           db.RiskTierCritical,
+				MinApprovals:       2,
+				Command:            db.CommandSpec{Raw: "terraform destroy", Cwd: "/test/project"},
+				Justification:      db.Justification{Reason: "Tearing down staging"},
+			}
+			if err := dbConn.CreateRequest(req); err != nil {
+				t.Fatalf("CreateRequest() error = %v", err)
+			}
+			rs := NewReviewService(dbConn, ReviewConfig{
+				ConflictResolution: tt.resolution,
+				ReviewerWeights:    map[string]int{"GreenLake": 2},
+			})
+
+			var result *ReviewResult
+			for i, decision := range tt.decisions {
+				var err error
+				result, err = rs.SubmitReview(ReviewOptions{
+					SessionID: reviewers[i].ID, SessionKey: reviewers[i].SessionKey, RequestID: req.ID, Decision: decision,
+				})
+				if err != nil {
+					t.Fatalf("SubmitReview() error = %v", err)
+				}
+				if i < len(tt.decisions)-1 && result.ResolutionApplied != nil {
+					t.Errorf("undecided review: ResolutionApplied = %+v, want nil", result.ResolutionApplied)
+				}
+			}
+
+			if result.NewRequestStatus != tt.wantStatus {
+				t.Fatalf("NewRequestStatus = %q, want %q", result.NewRequestStatus, tt.wantStatus)
+			}
+			want := ResolutionApplied{Resolution: tt.resolution, Explanation: tt.explanation}
+			if result.ResolutionApplied == nil || *result.ResolutionApplied != want {
+				t.Errorf("ResolutionApplied = %+v, want %+v", result.ResolutionApplied, want)
+			}
+		})
+	}
+}
+
 func TestSubmitReview_NotifierCalled(t *testing.T) {
 	t.Run("notifier called on approval", func(t *testing.T) {
 		dbConn, _, req := setupReviewTest(t)
@@ -1518,3 +1903,579 @@ func TestGetReviewStatus_Errors(t *testing.T) {
 		}
 	})
 }
+
+// setupDifferentHostTest creates a request requiring a reviewer on a
+// different host, made by a session on host "alpha".
+func setupDifferentHostTest(t *testing.T) (*db.DB, *db.Request) {
+	t.Helper()
+	dbConn, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("db.Open(:memory:) error = %v", err)
+	}
+	t.Cleanup(func() { dbConn.Close() })
+
+	sess := &db.Session{
+		AgentName:   "BlueSnow",
+		Program:     "codex-cli",
+		Model:       "gpt-5.2",
+		ProjectPath: "/test/project",
+		Hostname:    "alpha",
+		MachineID:   "machine-alpha",
+	}
+	if err := dbConn.CreateSession(sess); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	req := &db.Request{
+		ProjectPath:          "/test/project",
+		RequestorSessionID:   sess.ID,
+		RequestorAgent:       sess.AgentName,
+		RequestorModel:       sess.Model,
+		RiskTier:             db.RiskTierDangerous,
+		MinApprovals:         1,
+		RequireDifferentHost: true,
+		Command:              db.CommandSpec{Raw: "rm -rf ./build", Cwd: "/test/project"},
+		Justification:        db.Justification{Reason: "Cleaning build output"},
+	}
+	if err := dbConn.CreateRequest(req); err != nil {
+		t.Fatalf("CreateRequest() error = %v", err)
+	}
+	return dbConn, req
+}
+
+func createHostSession(t *testing.T, dbConn *db.DB, agent, hostname, machineID string) *db.Session {
+	t.Helper()
+	sess := &db.Session{
+		AgentName:   agent,
+		Program:     "claude-code",
+		Model:       "opus-4.5",
+		ProjectPath: "/test/project",
+		Hostname:    hostname,
+		MachineID:   machineID,
+	}
+	if err := dbConn.CreateSession(sess); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	return sess
+}
+
+func TestSubmitReview_DifferentProgramRequired(t *testing.T) {
+	tests := []struct {
+		name           string
+		requireModel   bool
+		program, model string
+		wantErr        error
+	}{
+		{"same program, different model", false, "codex-cli", "o3", ErrRequireDiffProgram},
+		{"same program, different model, both required", true, "codex-cli", "o3", ErrRequireDiffProgram},
+		{"different program, same model", false, "claude-code", "gpt-5.2", nil},
+		{"different program, same model, both required", true, "claude-code", "gpt-5.2", ErrRequireDiffModel},
+		{"different program and model", true, "claude-code", "opus-4.5", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbConn, sess, _ := setupReviewTest(t)
+			defer dbConn.Close()
+
+			req := &db.Request{
+				ProjectPath:             "/test/project",
+				RequestorSessionID:      sess.ID,
+				RequestorAgent:          sess.AgentName,
+				RequestorModel:          sess.Model,
+				RequestorProgram:        sess.Program,
+				RiskTier:                db.RiskTierDangerous,
+				MinApprovals:            1,
+				RequireDifferentModel:   tt.requireModel,
+				RequireDifferentProgram: true,
+				Command:                 db.CommandSpec{Raw: "rm -rf ./dist", Cwd: "/test/project"},
+				Justification:           db.Justification{Reason: "Cleaning dist"},
+			}
+			if err := dbConn.CreateRequest(req); err != nil {
+				t.Fatalf("CreateRequest() error = %v", err)
+			}
+			reviewer := &db.Session{AgentName: "GreenLake", Program: tt.program, Model: tt.model, ProjectPath: "/test/project"}
+			if err := dbConn.CreateSession(reviewer); err != nil {
+				t.Fatalf("CreateSession() error = %v", err)
+			}
+
+			rs := NewReviewService(dbConn, DefaultReviewConfig())
+			result, err := rs.SubmitReview(ReviewOptions{
+				SessionID:  reviewer.ID,
+				SessionKey: reviewer.SessionKey,
+				RequestID:  req.ID,
+				Decision:   db.DecisionApprove,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SubmitReview() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SubmitReview() error = %v", err)
+			}
+			if result.NewRequestStatus != db.StatusApproved {
+				t.Errorf("NewRequestStatus = %s, want approved", result.NewRequestStatus)
+			}
+		})
+	}
+}
+
+func TestSubmitReview_DifferentProgramRequired_SameProgramRejectionAllowed(t *testing.T) {
+	dbConn, sess, req := setupReviewTest(t)
+	defer dbConn.Close()
+	if _, err := dbConn.Exec(`UPDATE requests SET require_different_program = 1, requestor_program = ? WHERE id = ?`, sess.Program, req.ID); err != nil {
+		t.Fatal(err)
+	}
+	reviewer := &db.Session{AgentName: "GreenLake", Program: sess.Program, Model: "o3", ProjectPath: "/test/project"}
+	if err := dbConn.CreateSession(reviewer); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+	if _, err := rs.SubmitReview(ReviewOptions{
+		SessionID:  reviewer.ID,
+		SessionKey: reviewer.SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionReject,
+	}); err != nil {
+		t.Errorf("expected a same-program rejection to be allowed, got %v", err)
+	}
+}
+
+func TestSubmitReview_DifferentHostRequired(t *testing.T) {
+	tests := []struct {
+		name      string
+		hostname  string
+		machineID string
+		wantErr   bool
+	}{
+		{"same machine ID", "alpha-renamed", "machine-alpha", true},
+		{"same hostname without machine ID", "ALPHA", "", true},
+		{"different machine ID", "beta", "machine-beta", false},
+		{"different hostname", "beta", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbConn, req := setupDifferentHostTest(t)
+			reviewer := createHostSession(t, dbConn, "GreenLake", tt.hostname, tt.machineID)
+
+			rs := NewReviewService(dbConn, DefaultReviewConfig())
+			result, err := rs.SubmitReview(ReviewOptions{
+				SessionID:  reviewer.ID,
+				SessionKey: reviewer.SessionKey,
+				RequestID:  req.ID,
+				Decision:   db.DecisionApprove,
+			})
+			if tt.wantErr {
+				if !errors.Is(err, ErrRequireDiffHost) {
+					t.Fatalf("SubmitReview() error = %v, want ErrRequireDiffHost", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SubmitReview() error = %v", err)
+			}
+			if result.NewRequestStatus != db.StatusApproved {
+				t.Errorf("NewRequestStatus = %s, want approved", result.NewRequestStatus)
+			}
+		})
+	}
+}
+
+func TestSubmitReview_DifferentHostRequired_SameHostRejectionAllowed(t *testing.T) {
+	dbConn, req := setupDifferentHostTest(t)
+	reviewer := createHostSession(t, dbConn, "GreenLake", "alpha", "machine-alpha")
+
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+	result, err := rs.SubmitReview(ReviewOptions{
+		SessionID:  reviewer.ID,
+		SessionKey: reviewer.SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionReject,
+	})
+	if err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+	if result.NewRequestStatus != db.StatusRejected {
+		t.Errorf("NewRequestStatus = %s, want rejected", result.NewRequestStatus)
+	}
+}
+
+func TestSubmitReview_DifferentHostRequired_UnrecordedHostFailsClosed(t *testing.T) {
+	dbConn, req := setupDifferentHostTest(t)
+	// A session whose host could not be determined.
+	reviewer := createHostSession(t, dbConn, "GreenLake", "", "")
+	if _, err := dbConn.Exec(`UPDATE sessions SET hostname = '', machine_id = '' WHERE id = ?`, reviewer.ID); err != nil {
+		t.Fatalf("clearing host: %v", err)
+	}
+
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+	_, err := rs.SubmitReview(ReviewOptions{
+		SessionID:  reviewer.ID,
+		SessionKey: reviewer.SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+	})
+	if !errors.Is(err, ErrRequireDiffHost) {
+		t.Fatalf("SubmitReview() error = %v, want ErrRequireDiffHost", err)
+	}
+}
+
+func TestSubmitReview_DifferentHostRequired_ExcludesRecordedSameHostApprovals(t *testing.T) {
+	dbConn, req := setupDifferentHostTest(t)
+	sameHost := createHostSession(t, dbConn, "RedCat", "alpha", "machine-alpha")
+	otherHost := createHostSession(t, dbConn, "GreenLake", "beta", "machine-beta")
+
+	req.MinApprovals = 2
+	if _, err := dbConn.Exec(`UPDATE requests SET min_approvals = 2 WHERE id = ?`, req.ID); err != nil {
+		t.Fatalf("updating min_approvals: %v", err)
+	}
+	// An approval recorded without going through SubmitReview.
+	if err := dbConn.CreateReview(&db.Review{
+		RequestID:          req.ID,
+		ReviewerSessionID:  sameHost.ID,
+		ReviewerAgent:      sameHost.AgentName,
+		ReviewerModel:      sameHost.Model,
+		Decision:           db.DecisionApprove,
+		Signature:          "sig",
+		SignatureTimestamp: time.Now(),
+	}); err != nil {
+		t.Fatalf("CreateReview() error = %v", err)
+	}
+
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+	status, err := rs.GetReviewStatus(req.ID)
+	if err != nil {
+		t.Fatalf("GetReviewStatus() error = %v", err)
+	}
+	if status.Approvals != 0 {
+		t.Errorf("Approvals = %d, want 0", status.Approvals)
+	}
+	if len(status.UncountedApprovals) != 1 || !strings.Contains(status.UncountedApprovals[0], "RedCat") {
+		t.Errorf("UncountedApprovals = %v, want one entry for RedCat", status.UncountedApprovals)
+	}
+
+	result, err := rs.SubmitReview(ReviewOptions{
+		SessionID:  otherHost.ID,
+		SessionKey: otherHost.SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+	})
+	if err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+	if result.Approvals != 1 {
+		t.Errorf("Approvals = %d, want 1", result.Approvals)
+	}
+	if result.RequestStatusChanged {
+		t.Errorf("request status changed to %s, want still pending", result.NewRequestStatus)
+	}
+	if len(result.SameHostReviewers) != 1 || result.SameHostReviewers[0] != "RedCat" {
+		t.Errorf("SameHostReviewers = %v, want [RedCat]", result.SameHostReviewers)
+	}
+}
+
+func TestSubmitReview_CallbackReplay(t *testing.T) {
+	dbConn, _, req := setupReviewTest(t)
+	defer dbConn.Close()
+
+	reviewer := &db.Session{
+		AgentName:   "GreenLake",
+		Program:     "claude-code",
+		Model:       "opus-4.5",
+		ProjectPath: "/test/project",
+	}
+	if err := dbConn.CreateSession(reviewer); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	opts := ReviewOptions{
+		SessionID:  reviewer.ID,
+		SessionKey: reviewer.SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+		CallbackID: "slack-action-1",
+	}
+
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+	first, err := rs.SubmitReview(opts)
+	if err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+	if first.Replayed {
+		t.Error("first delivery reported as replayed")
+	}
+	if first.NewRequestStatus != db.StatusApproved {
+		t.Fatalf("NewRequestStatus = %s, want approved", first.NewRequestStatus)
+	}
+
+	// The provider retries the same callback after the request was approved
+	second, err := rs.SubmitReview(opts)
+	if err != nil {
+		t.Fatalf("SubmitReview() replay error = %v", err)
+	}
+	if !second.Replayed {
+		t.Error("duplicate delivery not reported as replayed")
+	}
+	if second.Review.ID != first.Review.ID {
+		t.Errorf("replayed review ID = %s, want %s", second.Review.ID, first.Review.ID)
+	}
+	if second.RequestStatusChanged {
+		t.Error("replay changed the request status")
+	}
+	reviews, err := dbConn.ListReviewsForRequest(req.ID)
+	if err != nil {
+		t.Fatalf("ListReviewsForRequest() error = %v", err)
+	}
+	if len(reviews) != 1 {
+		t.Errorf("got %d reviews after replay, want 1", len(reviews))
+	}
+
+	t.Run("wrong session key", func(t *testing.T) {
+		bad := opts
+		bad.SessionKey = "not-the-key"
+		if _, err := rs.SubmitReview(bad); !errors.Is(err, ErrSessionKeyMismatch) {
+			t.Errorf("SubmitReview() error = %v, want ErrSessionKeyMismatch", err)
+		}
+	})
+
+	t.Run("different decision", func(t *testing.T) {
+		conflict := opts
+		conflict.Decision = db.DecisionReject
+		if _, err := rs.SubmitReview(conflict); !errors.Is(err, ErrCallbackConflict) {
+			t.Errorf("SubmitReview() error = %v, want ErrCallbackConflict", err)
+		}
+	})
+}
+
+func TestSubmitReview_NewCallbackCreatesReview(t *testing.T) {
+	dbConn, sess, _ := setupReviewTest(t)
+	defer dbConn.Close()
+
+	req := &db.Request{
+		ProjectPath:        "/test/project",
+		RequestorSessionID: sess.ID,
+		RequestorAgent:     sess.AgentName,
+		RequestorModel:     sess.Model,
+		RiskTier:           db.RiskTierCritical,
+		MinApprovals:       2,
+		Command:            db.CommandSpec{Raw: "rm -rf ./data", Cwd: "/test/project"},
+		Justification:      db.Justification{Reason: "Cleaning data"},
+	}
+	if err := dbConn.CreateRequest(req); err != nil {
+		t.Fatalf("CreateRequest() error = %v", err)
+	}
+
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+	for i, agent := range []string{"GreenLake", "RedCat"} {
+		reviewer := &db.Session{AgentName: agent, Program: "claude-code", Model: "opus-4.5", ProjectPath: "/test/project"}
+		if err := dbConn.CreateSession(reviewer); err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+		result, err := rs.SubmitReview(ReviewOptions{
+			SessionID:  reviewer.ID,
+			SessionKey: reviewer.SessionKey,
+			RequestID:  req.ID,
+			Decision:   db.DecisionApprove,
+			CallbackID: "webhook-delivery-" + agent,
+		})
+		if err != nil {
+			t.Fatalf("SubmitReview(%s) error = %v", agent, err)
+		}
+		if result.Replayed {
+			t.Errorf("SubmitReview(%s) reported a new callback as replayed", agent)
+		}
+		if result.Approvals != i+1 {
+			t.Errorf("Approvals = %d, want %d", result.Approvals, i+1)
+		}
+	}
+
+	status, err := rs.GetReviewStatus(req.ID)
+	if err != nil {
+		t.Fatalf("GetReviewStatus() error = %v", err)
+	}
+	if status.RequestStatus != db.StatusApproved {
+		t.Errorf("RequestStatus = %s, want approved", status.RequestStatus)
+	}
+}
+
+func TestSweepEscalations(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	requestor := testutil.MakeSession(t, database)
+	reviewer := testutil.MakeSession(t, database, testutil.SessionWithAgentName("Reviewer"))
+
+	unreviewed := testutil.MakeRequest(t, database, requestor)
+	approvedOnce := testutil.MakeRequest(t, database, requestor, testutil.WithMinApprovals(2))
+	rejected := testutil.MakeRequest(t, database, requestor, testutil.WithMinApprovals(2))
+	cancelled := testutil.MakeRequest(t, database, requestor)
+	for _, r := range []struct {
+		req      *db.Request
+		decision db.Decision
+	}{{approvedOnce, db.DecisionApprove}, {rejected, db.DecisionReject}} {
+		if err := database.CreateReview(&db.Review{
+			RequestID:         r.req.ID,
+			ReviewerSessionID: reviewer.ID,
+			ReviewerAgent:     reviewer.AgentName,
+			ReviewerModel:     reviewer.Model,
+			Decision:          r.decision,
+		}); err != nil {
+			t.Fatalf("CreateReview: %v", err)
+		}
+	}
+	if err := database.UpdateRequestStatus(cancelled.ID, db.StatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultReviewConfig()
+	cfg.EscalateAfter = 30 * time.Minute
+	rs := NewReviewService(database, cfg)
+
+	// Before the threshold nothing moves.
+	n, err := rs.SweepEscalations(unreviewed.CreatedAt.Add(29 * time.Minute))
+	if err != nil || n != 0 {
+		t.Fatalf("SweepEscalations before the threshold = %d, %v; want 0", n, err)
+	}
+
+	n, err = rs.SweepEscalations(unreviewed.CreatedAt.Add(31 * time.Minute))
+	if err != nil || n != 2 {
+		t.Fatalf("SweepEscalations = %d, %v; want 2", n, err)
+	}
+	for req, want := range map[*db.Request]db.RequestStatus{
+		unreviewed:   db.StatusEscalated,
+		approvedOnce: db.StatusEscalated,
+		rejected:     db.StatusPending,
+		cancelled:    db.StatusCancelled,
+	} {
+		got, err := database.GetRequest(req.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != want {
+			t.Errorf("request %s: status %s, want %s", req.ID, got.Status, want)
+		}
+	}
+
+	// Escalated requests are no longer pending, so a second sweep is a no-op.
+	if n, err := rs.SweepEscalations(unreviewed.CreatedAt.Add(time.Hour)); err != nil || n != 0 {
+		t.Errorf("second SweepEscalations = %d, %v; want 0", n, err)
+	}
+}
+
+func TestSweepEscalations_Disabled(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	req := testutil.MakeRequest(t, database, testutil.MakeSession(t, database))
+
+	rs := NewReviewService(database, DefaultReviewConfig())
+	if n, err := rs.SweepEscalations(req.CreatedAt.Add(24 * time.Hour)); err != nil || n != 0 {
+		t.Fatalf("SweepEscalations with EscalateAfter unset = %d, %v; want 0", n, err)
+	}
+	if got, _ := database.GetRequest(req.ID); got.Status != db.StatusPending {
+		t.Errorf("expected the request to stay pending, got %s", got.Status)
+	}
+}
+
+func TestSubmitReview_RequiredRoles(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	requestor := testutil.MakeSession(t, database)
+	alice := testutil.MakeSession(t, database, testutil.SessionWithAgentName("Alice"))
+	bob := testutil.MakeSession(t, database, testutil.SessionWithAgentName("Bob"))
+	sec := testutil.MakeSession(t, database, testutil.SessionWithAgentName("SecBot"))
+	req := testutil.MakeRequest(t, database, requestor, testutil.WithMinApprovals(2),
+		func(r *db.Request) { r.RequiredRoles = []string{"security"} })
+
+	cfg := DefaultReviewConfig()
+	cfg.AgentRoles = map[string][]string{"SecBot": {"security"}, "Alice": {"dba"}}
+	rs := NewReviewService(database, cfg)
+	approve := func(s *db.Session) *ReviewResult {
+		t.Helper()
+		result, err := rs.SubmitReview(ReviewOptions{SessionID: s.ID, SessionKey: s.SessionKey, RequestID: req.ID, Decision: db.DecisionApprove})
+		if err != nil {
+			t.Fatalf("SubmitReview(%s) error = %v", s.AgentName, err)
+		}
+		return result
+	}
+
+	// Two approvals meet MinApprovals, but neither approver is security.
+	approve(alice)
+	if result := approve(bob); result.RequestStatusChanged {
+		t.Fatalf("expected the request to stay pending without a security approval, got %s", result.NewRequestStatus)
+	}
+	status, err := rs.GetReviewStatus(req.ID)
+	if err != nil {
+		t.Fatalf("GetReviewStatus() error = %v", err)
+	}
+	if status.RequestStatus != db.StatusPending || !status.NeedsMoreApprovals {
+		t.Errorf("expected pending and needing approvals, got %+v", status)
+	}
+	if len(status.MissingRoles) != 1 || status.MissingRoles[0] != "security" {
+		t.Errorf("MissingRoles = %v, want [security]", status.MissingRoles)
+	}
+
+	if result := approve(sec); result.NewRequestStatus != db.StatusApproved {
+		t.Fatalf("expected the security approval to approve the request, got %q", result.NewRequestStatus)
+	}
+	status, err = rs.GetReviewStatus(req.ID)
+	if err != nil {
+		t.Fatalf("GetReviewStatus() error = %v", err)
+	}
+	if len(status.MissingRoles) != 0 {
+		t.Errorf("MissingRoles = %v, want none", status.MissingRoles)
+	}
+}
+
+func TestDetermineNewStatus_RequiredRoles(t *testing.T) {
+	request := &db.Request{MinApprovals: 1, RequiredRoles: []string{"security"}}
+	missing := []string{"security"}
+	tests := []struct {
+		name       string
+		resolution ConflictResolution
+		decision   db.Decision
+		approvals  int
+		rejections int
+		missing    []string
+		want       db.RequestStatus
+	}{
+		{"any_rejection: quorum met, role missing", ConflictAnyRejectionBlocks, db.DecisionApprove, 2, 0, missing, ""},
+		{"any_rejection: quorum and role met", ConflictAnyRejectionBlocks, db.DecisionApprove, 2, 0, nil, db.StatusApproved},
+		{"any_rejection: rejection still blocks", ConflictAnyRejectionBlocks, db.DecisionReject, 1, 1, missing, db.StatusRejected},
+		{"weighted_quorum: weight met, role missing", ConflictWeightedQuorum, db.DecisionApprove, 5, 0, missing, ""},
+		{"human_breaks_tie: quorum met, role missing", ConflictHumanBreaksTie, db.DecisionApprove, 2, 0, missing, ""},
+		{"first_wins: first approval held for role", ConflictFirstWins, db.DecisionApprove, 1, 0, missing, ""},
+		{"first_wins: role completes held approval", ConflictFirstWins, db.DecisionApprove, 2, 0, nil, db.StatusApproved},
+		{"first_wins: rejection decides held approval", ConflictFirstWins, db.DecisionReject, 1, 1, missing, db.StatusRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := NewReviewService(nil, ReviewConfig{ConflictResolution: tt.resolution})
+			if got, _ := rs.determineNewStatus(request, tt.decision, tt.approvals, tt.rejections, tt.missing); got != tt.want {
+				t.Errorf("determineNewStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscalateStaleRequests_PerTier(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	requestor := testutil.MakeSession(t, database)
+	dangerous := testutil.MakeRequest(t, database, requestor, testutil.WithRisk(db.RiskTierDangerous))
+	critical := testutil.MakeRequest(t, database, requestor, testutil.WithRisk(db.RiskTierCritical))
+
+	cfg := DefaultReviewConfig()
+	cfg.EscalateAfterTiers = map[db.RiskTier]time.Duration{db.RiskTierDangerous: 10 * time.Minute}
+	rs := NewReviewService(database, cfg)
+
+	escalated, err := rs.EscalateStaleRequests(dangerous.CreatedAt.Add(11 * time.Minute))
+	if err != nil {
+		t.Fatalf("EscalateStaleRequests() error = %v", err)
+	}
+	if len(escalated) != 1 || escalated[0].ID != dangerous.ID || escalated[0].Status != db.StatusEscalated {
+		t.Fatalf("expected only the DANGEROUS request escalated, got %+v", escalated)
+	}
+
+	// Without a general EscalateAfter, tiers not listed never escalate.
+	if escalated, err := rs.EscalateStaleRequests(critical.CreatedAt.Add(24 * time.Hour)); err != nil || len(escalated) != 0 {
+		t.Errorf("expected CRITICAL to stay pending, got %+v, %v", escalated, err)
+	}
+	if got, _ := database.GetRequest(dangerous.ID); got.Status != db.StatusEscalated || got.ResolvedAt != nil {
+		t.Errorf("expected escalated and unresolved, got %s resolved %v", got.Status, got.ResolvedAt)
+	}
+}
@@ -14,7 +14,12 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/db"
@@ -29,7 +34,11 @@ const (
 	rollbackKindFilesystem       = "filesystem"
 	rollbackKindGit              = "git"
 	rollbackKindKubernetes       = "kubernetes"
+	rollbackKindDocker           = "docker"
+	rollbackKindDatabase         = "database"
 	rollbackKubernetesDirName    = "k8s"
+	rollbackDockerDirName        = "docker"
+	rollbackDatabaseDirName      = "database"
 	rollbackGitDirName           = "git"
 	rollbackGitHeadFilename      = "head.txt"
 	rollbackGitBranchFilename    = "branch.txt"
@@ -37,20 +46,85 @@ const (
 	rollbackGitDiffFilename      = "diff.patch"
 	rollbackGitCachedFilename    = "diff_cached.patch"
 	rollbackGitUntrackedFilename = "untracked.txt"
+	// DefaultRollbackRootPrefix names filesystem capture roots p0, p1, ...
+	DefaultRollbackRootPrefix = "p"
 )
 
+// rollbackRootPrefixRe keeps root prefixes to a single safe path component.
+var rollbackRootPrefixRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,31}$`)
+
 type RollbackCaptureOptions struct {
-	// MaxSizeBytes limits filesystem capture. 0 disables the limit.
+	// MaxSizeBytes limits filesystem and database captures. 0 disables the
+	// limit. A filesystem capture is refused before anything is written
+	// when its files add up to more, and stopped as soon as the compressed
+	// archive grows past it.
 	MaxSizeBytes int64
+	// Exclude lists gitignore-style patterns for paths inside filesystem
+	// capture roots to leave out, such as caches (see
+	// compileRollbackExcludes). The rm targets themselves are always
+	// captured.
+	Exclude []string
 	// Retention controls cleanup of old rollback captures. 0 uses the default.
 	Retention time.Duration
+	// MaxCaptures keeps at most this many captures after the age-based
+	// cleanup, removing the least recently modified. 0 disables the limit.
+	MaxCaptures int
+	// RootPrefix names each captured filesystem root's top-level directory
+	// in the tar: prefix + index ("p0", "p1", ...). Empty uses
+	// DefaultRollbackRootPrefix.
+	RootPrefix string
+	// MaxConcurrent caps the captures running at once in this process;
+	// further captures wait for a slot. 0 disables the limit.
+	MaxConcurrent int
+	// Wait is how long a capture waits for a slot before failing with
+	// ErrRollbackCaptureBusy. 0 uses the default of 30 seconds; a negative
+	// value does not wait.
+	Wait time.Duration
 	// Now overrides time.Now for tests.
 	Now func() time.Time
 }
 
 type RollbackRestoreOptions struct {
-	// Force allows overwriting existing files and running destructive git restores.
+	// Force allows overwriting existing files, running destructive git
+	// restores and re-creating docker containers.
 	Force bool
+	// DryRun checks a filesystem restore without writing anything (see
+	// PlanRollbackRestore); the restore fails if the plan is blocked.
+	DryRun bool
+	// IncludePaths limits a filesystem restore to archive entries whose
+	// names (such as "p0/build/sub") equal or fall under one of these
+	// prefixes; other entries are skipped. Empty restores everything.
+	IncludePaths []string
+	// OnSkippedSymlink is called for each symlink a filesystem restore could
+	// not create because the platform refused it, as Windows does without
+	// Developer Mode or the symlink privilege. The restore carries on with
+	// the remaining entries. Nil ignores skipped symlinks.
+	OnSkippedSymlink func(SkippedSymlink)
+}
+
+// SkippedSymlink is a symlink a filesystem restore left out.
+type SkippedSymlink struct {
+	// Path is where the symlink would have been created.
+	Path string `json:"path"`
+	// Target is the symlink's captured target.
+	Target string `json:"target"`
+	// Reason is the error creating it returned.
+	Reason string `json:"reason"`
+}
+
+// includesEntry reports whether the archive entry name passes IncludePaths.
+func (o RollbackRestoreOptions) includesEntry(name string) bool {
+	if len(o.IncludePaths) == 0 {
+		return true
+	}
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	for _, prefix := range o.IncludePaths {
+		prefix = path.Clean(strings.TrimPrefix(filepath.ToSlash(prefix), "./"))
+		if name == prefix || strings.HasPrefix(name, prefix+"/") {
+			return true
+		}
+	}
+	return false
 }
 
 type RollbackData struct {
@@ -66,21 +140,61 @@ type RollbackData struct {
 	Filesystem *FilesystemRollbackData `json:"filesystem,omitempty"`
 	Git        *GitRollbackData        `json:"git,omitempty"`
 	Kubernetes *KubernetesRollbackData `json:"kubernetes,omitempty"`
+	Docker     *DockerRollbackData     `json:"docker,omitempty"`
+	Database   *DatabaseRollbackData   `json:"database,omitempty"`
 }
 
 type FilesystemRollbackData struct {
-	TarGz      string            `json:"tar_gz"`
-	Roots      []FilesystemRoot  `json:"roots"`
-	TotalBytes int64             `json:"total_bytes"`
-	Missing    []string          `json:"missing,omitempty"`
-	Notes      map[string]string `json:"notes,omitempty"`
+	TarGz string `json:"tar_gz"`
+	// RootPrefix is the prefix the capture numbered Roots' IDs with. It is
+	// informational; restore relies only on Roots. Empty in captures made
+	// before the prefix was configurable, which always used "p".
+	RootPrefix string `json:"root_prefix,omitempty"`
+	// Roots is the prefix-to-path mapping: every tar entry lives under one
+	// root's ID and restores beneath that root's Path.
+	Roots      []FilesystemRoot `json:"roots"`
+	TotalBytes int64            `json:"total_bytes"`
+	Missing    []string         `json:"missing,omitempty"`
+	// Notes maps rm targets that were not captured as their own root, such
+	// as a directory nested inside another target, to the reason.
+	Notes map[string]string `json:"notes,omitempty"`
+	// Exclude lists the patterns whose matches were left out of the
+	// capture; restore cannot bring those paths back.
+	Exclude []string `json:"exclude,omitempty"`
 }
 
+// FilesystemRoot maps a top-level tar directory to the absolute path it was
+// captured from.
 type FilesystemRoot struct {
-	ID   string `json:"id"`
+	// ID is the tar directory, e.g. "p0".
+	ID string `json:"id"`
+	// Path is the original absolute path.
 	Path string `json:"path"`
 }
 
+// RootPaths returns the tar-directory-to-path mapping used by restore.
+// Incomplete roots are skipped; an ID claimed twice or a relative path is
+// an error, since restoring it could write to the wrong place.
+func (d *FilesystemRollbackData) RootPaths() (map[string]string, error) {
+	roots := make(map[string]string, len(d.Roots))
+	for _, r := range d.Roots {
+		if r.ID == "" || r.Path == "" {
+			continue
+		}
+		if strings.ContainsAny(r.ID, `/\`) || r.ID == "." || r.ID == ".." {
+			return nil, fmt.Errorf("invalid rollback root id: %q", r.ID)
+		}
+		if !filepath.IsAbs(r.Path) {
+			return nil, fmt.Errorf("rollback root %s has relative path: %q", r.ID, r.Path)
+		}
+		if prev, ok := roots[r.ID]; ok && prev != r.Path {
+			return nil, fmt.Errorf("rollback root id %s maps to both %s and %s", r.ID, prev, r.Path)
+		}
+		roots[r.ID] = r.Path
+	}
+	return roots, nil
+}
+
 type GitRollbackData struct {
 	RepoRoot      string `json:"repo_root"`
 	Head          string `json:"head"`
@@ -116,26 +230,33 @@ func CaptureRollbackState(ctx context.Context, req *db.Request, opts RollbackCap
 	}
 
 	opts = normalizeRollbackCaptureOptions(opts)
-
-	normalized := NormalizeCommand(req.Command.Raw)
-	cmd := strings.TrimSpace(normalized.Primary)
-	if cmd == "" {
-		cmd = strings.TrimSpace(req.Command.Raw)
+	if err := ValidateRollbackRootPrefix(opts.RootPrefix); err != nil {
+		return nil, err
 	}
-	tokens := parseShellTokens(cmd)
+	if _, err := compileRollbackExcludes(opts.Exclude); err != nil {
+		return nil, err
+	}
+
+	tokens := rollbackTokens(req.Command.Raw)
 	if len(tokens) == 0 {
 		return nil, fmt.Errorf("empty command")
 	}
 
-	kind := detectRollbackKind(tokens)
+	kind := RollbackKind(req.Command.Raw)
 	if kind == "" {
 		return nil, nil
 	}
 
-	baseDir := filepath.Join(req.ProjectPath, ".slb", "rollback")
-	_ = cleanupOldRollbackCaptures(baseDir, opts.Retention, opts.Now())
+	release, err := rollbackCaptures.acquire(ctx, opts.MaxConcurrent, opts.Wait)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	baseDir := rollbackBaseDir(req.ProjectPath)
+	_, _ = cleanupOldRollbackCaptures(baseDir, opts.Retention, opts.MaxCaptures, opts.Now())
 
-	rollbackDir := filepath.Join(baseDir, "req-"+req.ID)
+	rollbackDir := RollbackDir(req.ProjectPath, req.ID)
 	if err := os.MkdirAll(rollbackDir, 0700); err != nil {
 		return nil, fmt.Errorf("creating rollback dir: %w", err)
 	}
@@ -170,6 +291,18 @@ func CaptureRollbackState(ctx context.Context, req *db.Request, opts RollbackCap
 			return nil, err
 		}
 		data.Kubernetes = k8sData
+	case rollbackKindDocker:
+		dockerData, err := captureDockerRollback(ctx, rollbackDir, req, tokens)
+		if err != nil {
+			return nil, err
+		}
+		data.Docker = dockerData
+	case rollbackKindDatabase:
+		dbData, err := captureDatabaseRollback(ctx, rollbackDir, req, opts)
+		if err != nil {
+			return nil, err
+		}
+		data.Database = dbData
 	default:
 		return nil, nil
 	}
@@ -199,6 +332,74 @@ func LoadRollbackData(rollbackDir string) (*RollbackData, error) {
 	return &data, nil
 }
 
+// RollbackDir returns the directory CaptureRollbackState uses for requestID
+// under projectPath.
+func RollbackDir(projectPath, requestID string) string {
+	return filepath.Join(rollbackBaseDir(projectPath), "req-"+requestID)
+}
+
+func rollbackBaseDir(projectPath string) string {
+	return filepath.Join(projectPath, ".slb", "rollback")
+}
+
+// RollbackCapture is one capture found on disk by ListRollbackCaptures.
+type RollbackCapture struct {
+	Data *RollbackData
+	// SizeBytes is the total size of the files in the capture directory.
+	SizeBytes int64
+}
+
+// ListRollbackCaptures reads every capture under projectPath's rollback
+// directory, newest first. Directories without readable metadata are skipped.
+func ListRollbackCaptures(projectPath string) ([]RollbackCapture, error) {
+	baseDir := rollbackBaseDir(projectPath)
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading rollback dir: %w", err)
+	}
+	var captures []RollbackCapture
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), "req-") {
+			continue
+		}
+		dir := filepath.Join(baseDir, e.Name())
+		data, err := LoadRollbackData(dir)
+		if err != nil {
+			continue
+		}
+		size, err := dirSize(dir)
+		if err != nil {
+			return nil, fmt.Errorf("sizing %s: %w", dir, err)
+		}
+		captures = append(captures, RollbackCapture{Data: data, SizeBytes: size})
+	}
+	sort.SliceStable(captures, func(i, j int) bool {
+		return captures[i].Data.CapturedAt.After(captures[j].Data.CapturedAt)
+	})
+	return captures, nil
+}
+
+func dirSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
 func RestoreRollbackState(ctx context.Context, data *RollbackData, opts RollbackRestoreOptions) error {
 	if data == nil {
 		return fmt.Errorf("rollback data is required")
@@ -210,6 +411,14 @@ func RestoreRollbackState(ctx context.Context, data *RollbackData, opts Rollback
 		ctx = context.Background()
 	}
 
+	if opts.DryRun {
+		plan, err := PlanRollbackRestore(ctx, data, opts)
+		if err != nil {
+			return err
+		}
+		return plan.Err()
+	}
+
 	switch data.Kind {
 	case rollbackKindFilesystem:
 		return restoreFilesystemRollback(data, opts)
@@ -217,6 +426,10 @@ func RestoreRollbackState(ctx context.Context, data *RollbackData, opts Rollback
 		return restoreGitRollback(ctx, data, opts)
 	case rollbackKindKubernetes:
 		return restoreKubernetesRollback(ctx, data, opts)
+	case rollbackKindDocker:
+		return restoreDockerRollback(ctx, data, opts)
+	case rollbackKindDatabase:
+		return restoreDatabaseRollback(ctx, data, opts)
 	default:
 		return fmt.Errorf("unsupported rollback kind: %s", data.Kind)
 	}
@@ -229,9 +442,45 @@ func normalizeRollbackCaptureOptions(opts RollbackCaptureOptions) RollbackCaptur
 	if opts.Retention == 0 {
 		opts.Retention = defaultRollbackRetention
 	}
+	if opts.Wait == 0 {
+		opts.Wait = defaultRollbackCaptureWait
+	}
+	if strings.TrimSpace(opts.RootPrefix) == "" {
+		opts.RootPrefix = DefaultRollbackRootPrefix
+	}
 	return opts
 }
 
+// ValidateRollbackRootPrefix reports whether prefix can name filesystem
+// capture roots: a letter followed by up to 31 letters, digits, '_' or '-'.
+func ValidateRollbackRootPrefix(prefix string) error {
+	if !rollbackRootPrefixRe.MatchString(prefix) {
+		return fmt.Errorf("invalid rollback root prefix %q (want a letter followed by letters, digits, '_' or '-')", prefix)
+	}
+	return nil
+}
+
+// RollbackKind returns the kind of state CaptureRollbackState captures
+// before command runs (filesystem, git, kubernetes, docker or database), or
+// "" when it captures nothing for the command.
+func RollbackKind(command string) string {
+	kind := detectRollbackKind(rollbackTokens(command))
+	if kind == "" && detectDatabaseRollback(command) != nil {
+		kind = rollbackKindDatabase
+	}
+	return kind
+}
+
+// rollbackTokens splits command's primary command, wrappers stripped, into
+// shell words.
+func rollbackTokens(command string) []string {
+	cmd := strings.TrimSpace(NormalizeCommand(command).Primary)
+	if cmd == "" {
+		cmd = strings.TrimSpace(command)
+	}
+	return parseShellTokens(cmd)
+}
+
 func detectRollbackKind(tokens []string) string {
 	if len(tokens) == 0 {
 		return ""
@@ -250,6 +499,11 @@ func detectRollbackKind(tokens []string) string {
 			return rollbackKindKubernetes
 		}
 		return ""
+	case "docker", "docker-compose":
+		if parseDockerCommand(tokens) != nil {
+			return rollbackKindDocker
+		}
+		return ""
 	default:
 		return ""
 	}
@@ -266,17 +520,28 @@ func writeRollbackMetadata(dir string, data *RollbackData) error {
 	return nil
 }
 
-func cleanupOldRollbackCaptures(baseDir string, retention time.Duration, now time.Time) error {
-	if retention <= 0 {
-		return nil
+// cleanupOldRollbackCaptures removes req- capture directories last modified
+// more than retention ago, then all but the maxCaptures most recently
+// modified (ties keep the lexically smaller name). Either limit is off when
+// not positive. It returns the number of directories removed.
+func cleanupOldRollbackCaptures(baseDir string, retention time.Duration, maxCaptures int, now time.Time) (int, error) {
+	if retention <= 0 && maxCaptures <= 0 {
+		return 0, nil
 	}
 	entries, err := os.ReadDir(baseDir)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil
+			return 0, nil
 		}
-		return err
+		return 0, err
 	}
+
+	type capture struct {
+		name    string
+		modTime time.Time
+	}
+	var kept []capture
+	removed := 0
 	cutoff := now.Add(-retention)
 	for _, e := range entries {
 		if !e.IsDir() {
@@ -289,11 +554,30 @@ func cleanupOldRollbackCaptures(baseDir string, retention time.Duration, now tim
 		if err != nil {
 			continue
 		}
-		if info.ModTime().Before(cutoff) {
-			_ = os.RemoveAll(filepath.Join(baseDir, e.Name()))
+		if retention > 0 && info.ModTime().Before(cutoff) {
+			if os.RemoveAll(filepath.Join(baseDir, e.Name())) == nil {
+				removed++
+			}
+			continue
 		}
+		kept = append(kept, capture{name: e.Name(), modTime: info.ModTime()})
 	}
-	return nil
+
+	if maxCaptures <= 0 || len(kept) <= maxCaptures {
+		return removed, nil
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		if !kept[i].modTime.Equal(kept[j].modTime) {
+			return kept[i].modTime.After(kept[j].modTime)
+		}
+		return kept[i].name < kept[j].name
+	})
+	for _, c := range kept[maxCaptures:] {
+		if os.RemoveAll(filepath.Join(baseDir, c.name)) == nil {
+			removed++
+		}
+	}
+	return removed, nil
 }
 
 func captureFilesystemRollback(rollbackDir string, req *db.Request, tokens []string, opts RollbackCaptureOptions) (*FilesystemRollbackData, error) {
@@ -311,8 +595,8 @@ func captureFilesystemRollback(rollbackDir string, req *db.Request, tokens []str
 	if len(paths) == 0 {
 		return nil, fmt.Errorf("no existing rm targets to capture")
 	}
-
-	totalBytes, err := estimateFileBytes(paths, opts.MaxSizeBytes)
+	paths, covered := collapseNestedPaths(paths)
+	exclude, err := compileRollbackExcludes(opts.Exclude)
 	if err != nil {
 		return nil, err
 	}
@@ -320,24 +604,81 @@ func captureFilesystemRollback(rollbackDir string, req *db.Request, tokens []str
 	roots := make([]FilesystemRoot, 0, len(paths))
 	for i, p := range paths {
 		roots = append(roots, FilesystemRoot{
-			ID:   fmt.Sprintf("p%d", i),
+			ID:   fmt.Sprintf("%s%d", opts.RootPrefix, i),
 			Path: p,
 		})
 	}
 
+	// Walk everything first so an oversized capture is refused before any
+	// of it is written.
+	totalBytes, err := estimateCaptureBytes(roots, exclude, opts.MaxSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+
 	tarPath := filepath.Join(rollbackDir, rollbackFilesystemTarGz)
-	if err := writeTarGz(tarPath, roots); err != nil {
+	if err := writeTarGz(tarPath, roots, exclude, opts.MaxSizeBytes); err != nil {
 		return nil, err
 	}
 
+	var notes map[string]string
+	if len(covered) > 0 {
+		notes = make(map[string]string, len(covered))
+		for nested, ancestor := range covered {
+			for _, r := range roots {
+				if r.Path == ancestor {
+					notes[nested] = fmt.Sprintf("captured within root %s (%s)", r.ID, ancestor)
+					break
+				}
+			}
+		}
+	}
+
 	return &FilesystemRollbackData{
 		TarGz:      rollbackFilesystemTarGz,
+		RootPrefix: opts.RootPrefix,
 		Roots:      roots,
 		TotalBytes: totalBytes,
 		Missing:    missing,
+		Notes:      notes,
+		Exclude:    opts.Exclude,
 	}, nil
 }
 
+// collapseNestedPaths drops paths that lie inside another path in the list,
+// since capturing the ancestor already captures them; otherwise restore
+// would write the same files twice. Order is preserved. covered maps each
+// dropped path to the ancestor that captures it.
+func collapseNestedPaths(paths []string) (kept []string, covered map[string]string) {
+	for _, p := range paths {
+		// The shortest enclosing path is the outermost, which is kept.
+		ancestor := ""
+		for _, other := range paths {
+			if pathWithin(other, p) && (ancestor == "" || len(other) < len(ancestor)) {
+				ancestor = other
+			}
+		}
+		if ancestor == "" {
+			kept = append(kept, p)
+			continue
+		}
+		if covered == nil {
+			covered = make(map[string]string)
+		}
+		covered[p] = ancestor
+	}
+	return kept, covered
+}
+
+// pathWithin reports whether p is strictly inside dir. Both must be clean.
+func pathWithin(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil || rel == "." || rel == ".." {
+		return false
+	}
+	return !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func resolvePaths(cwd string, targets []string) ([]string, []string) {
 	var paths []string
 	var missing []string
@@ -392,6 +733,9 @@ func resolvePaths(cwd string, targets []string) ([]string, []string) {
 	return paths, missing
 }
 
+// errSizeCapExceeded is wrapped by estimateFileBytes when maxBytes is hit.
+var errSizeCapExceeded = errors.New("exceeds max size")
+
 func estimateFileBytes(roots []string, maxBytes int64) (int64, error) {
 	var total int64
 	for _, root := range roots {
@@ -406,7 +750,7 @@ func estimateFileBytes(roots []string, maxBytes int64) (int64, error) {
 			if info.Mode().IsRegular() {
 				total += info.Size()
 				if maxBytes > 0 && total > maxBytes {
-					return fmt.Errorf("rollback capture exceeds max size (%d bytes)", maxBytes)
+					return fmt.Errorf("rollback capture %w (%d bytes)", errSizeCapExceeded, maxBytes)
 				}
 			}
 			return nil
@@ -418,61 +762,238 @@ func estimateFileBytes(roots []string, maxBytes int64) (int64, error) {
 	return total, nil
 }
 
-func writeTarGz(outPath string, roots []FilesystemRoot) error {
-	f, err := os.Create(outPath)
+// estimateCaptureBytes walks roots, in parallel, and totals the size of the
+// regular files that exclude leaves in. It fails with errSizeCapExceeded as
+// soon as the total passes maxBytes (0 disables the limit).
+func estimateCaptureBytes(roots []FilesystemRoot, exclude rollbackExcludes, maxBytes int64) (int64, error) {
+	budget := &captureBudget{max: maxBytes}
+	err := budget.eachRoot(roots, func(_ int, root FilesystemRoot) error {
+		return walkCaptureRoot(root, exclude, func(_, _ string, info fs.FileInfo) error {
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			return budget.add(info.Size())
+		})
+	})
 	if err != nil {
-		return fmt.Errorf("creating tar.gz: %w", err)
+		return 0, fmt.Errorf("estimating rollback size: %w", err)
 	}
-	defer f.Close()
+	return budget.used.Load(), nil
+}
 
-	gw := gzip.NewWriter(f)
-	defer gw.Close()
+// walkCaptureRoot calls fn for root and everything beneath it that exclude
+// leaves in, with the entry's tar name under root.ID. Symlinks are passed
+// to fn, not followed.
+func walkCaptureRoot(root FilesystemRoot, exclude rollbackExcludes, fn func(fsPath, tarName string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(root.Path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := ""
+		if p != root.Path {
+			r, err := filepath.Rel(root.Path, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(r)
+			if exclude.excludes(rel, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		info, err := os.Lstat(p)
+		if err != nil {
+			return err
+		}
+		name := root.ID
+		if rel != "" {
+			name += "/" + rel
+		}
+		if info.IsDir() {
+			name += "/"
+		}
+		return fn(p, name, info)
+	})
+}
 
-	tw := tar.NewWriter(gw)
-	defer tw.Close()
+// errCaptureStopped is returned by the goroutines capturing the other roots
+// once one root's capture fails.
+var errCaptureStopped = errors.New("rollback capture stopped")
 
-	for _, root := range roots {
-		if err := addRootToTar(tw, root.ID, root.Path); err != nil {
+// captureBudget is a byte limit shared by the goroutines capturing one
+// rollback's roots. The first failure stops the rest.
+type captureBudget struct {
+	max     int64
+	used    atomic.Int64
+	stopped atomic.Bool
+}
+
+// add counts n more bytes, failing with errSizeCapExceeded once the total
+// passes max (0 disables the limit).
+func (b *captureBudget) add(n int64) error {
+	if b.stopped.Load() {
+		return errCaptureStopped
+	}
+	if used := b.used.Add(n); b.max > 0 && used > b.max {
+		return fmt.Errorf("rollback capture %w (%d bytes)", errSizeCapExceeded, b.max)
+	}
+	return nil
+}
+
+// eachRoot runs fn for every root, up to GOMAXPROCS at a time, and returns
+// the first failure; a failure stops the others at their next add.
+func (b *captureBudget) eachRoot(roots []FilesystemRoot, fn func(i int, root FilesystemRoot) error) error {
+	if len(roots) == 1 {
+		return fn(0, roots[0])
+	}
+	errs := make([]error, len(roots))
+	slots := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, root := range roots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if b.stopped.Load() {
+				errs[i] = errCaptureStopped
+				return
+			}
+			if errs[i] = fn(i, root); errs[i] != nil {
+				b.stopped.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var stopped error
+	for _, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, errCaptureStopped):
+			stopped = err
+		default:
 			return err
 		}
 	}
+	return stopped
+}
+
+// budgetWriter counts bytes written to w against a captureBudget and fails
+// the write that passes it.
+type budgetWriter struct {
+	w      io.Writer
+	budget *captureBudget
+}
+
+func (w budgetWriter) Write(p []byte) (int, error) {
+	if err := w.budget.add(int64(len(p))); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
+
+// writeTarGz archives roots to outPath, leaving out what exclude matches,
+// and stops as soon as the compressed archive passes maxBytes (0 disables
+// the limit). Each root is written as its own gzip member holding a tar
+// stream without the end-of-archive marker, so several roots are
+// compressed in parallel and then concatenated, followed by a member with
+// the marker; gzip readers see one continuous tar. On failure the partial
+// archive is removed.
+func writeTarGz(outPath string, roots []FilesystemRoot, exclude rollbackExcludes, maxBytes int64) (err error) {
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("creating tar.gz: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("closing tar.gz: %w", cerr)
+		}
+		if err != nil {
+			_ = os.Remove(outPath)
+		}
+	}()
+
+	budget := &captureBudget{max: maxBytes}
+	if len(roots) == 1 {
+		err = writeTarGzMember(budgetWriter{w: f, budget: budget}, roots[0], exclude)
+	} else {
+		err = writeTarGzParts(f, outPath, roots, exclude, budget)
+	}
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(f)
+	if err := tar.NewWriter(gw).Close(); err != nil {
+		return fmt.Errorf("write tar trailer: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("write tar trailer: %w", err)
+	}
 	return nil
 }
 
-func addRootToTar(tw *tar.Writer, rootID, rootPath string) error {
-	info, err := os.Lstat(rootPath)
+// writeTarGzParts writes each root's member to a part file next to outPath,
+// in parallel, then appends the parts to out in root order.
+func writeTarGzParts(out io.Writer, outPath string, roots []FilesystemRoot, exclude rollbackExcludes, budget *captureBudget) error {
+	parts := make([]string, len(roots))
+	for i := range roots {
+		parts[i] = fmt.Sprintf("%s.%d.part", outPath, i)
+	}
+	defer func() {
+		for _, p := range parts {
+			_ = os.Remove(p)
+		}
+	}()
+
+	err := budget.eachRoot(roots, func(i int, root FilesystemRoot) error {
+		f, err := os.Create(parts[i])
+		if err != nil {
+			return fmt.Errorf("creating tar.gz part: %w", err)
+		}
+		err = writeTarGzMember(budgetWriter{w: f, budget: budget}, root, exclude)
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("closing tar.gz part: %w", cerr)
+		}
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("stat %s: %w", rootPath, err)
+		return err
 	}
-	if info.IsDir() {
-		return filepath.WalkDir(rootPath, func(p string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-			fi, err := os.Lstat(p)
-			if err != nil {
-				return err
-			}
-			rel := ""
-			if p != rootPath {
-				rel, _ = filepath.Rel(rootPath, p)
-			}
-			name := rootID
-			if rel != "" && rel != "." {
-				name = filepath.ToSlash(filepath.Join(rootID, rel))
-			}
-			if fi.IsDir() && !strings.HasSuffix(name, "/") {
-				name += "/"
-			}
-			return addPathToTar(tw, p, name, fi)
-		})
+
+	for _, p := range parts {
+		f, err := os.Open(p)
+		if err != nil {
+			return fmt.Errorf("opening tar.gz part: %w", err)
+		}
+		_, err = io.Copy(out, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("appending tar.gz part: %w", err)
+		}
 	}
+	return nil
+}
 
-	name := rootID
-	if info.IsDir() && !strings.HasSuffix(name, "/") {
-		name += "/"
+// writeTarGzMember writes root as one gzip member. The tar writer is
+// flushed, not closed, so the member carries no end-of-archive marker and
+// the next member's entries continue the same archive.
+func writeTarGzMember(w io.Writer, root FilesystemRoot, exclude rollbackExcludes) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	err := walkCaptureRoot(root, exclude, func(fsPath, tarName string, info fs.FileInfo) error {
+		return addPathToTar(tw, fsPath, tarName, info)
+	})
+	if err == nil {
+		err = tw.Flush()
 	}
-	return addPathToTar(tw, rootPath, name, info)
+	if cerr := gw.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("write gzip: %w", cerr)
+	}
+	return err
 }
 
 func addPathToTar(tw *tar.Writer, fsPath, tarName string, info fs.FileInfo) error {
@@ -484,7 +1005,8 @@ func addPathToTar(tw *tar.Writer, fsPath, tarName string, info fs.FileInfo) erro
 		if err != nil {
 			return fmt.Errorf("readlink %s: %w", fsPath, err)
 		}
-		linkTarget = target
+		// Stored with forward slashes so archives restore on any platform.
+		linkTarget = filepath.ToSlash(target)
 	}
 
 	hdr, err := tar.FileInfoHeader(info, linkTarget)
@@ -521,6 +1043,10 @@ func addPathToTar(tw *tar.Writer, fsPath, tarName string, info fs.FileInfo) erro
 	return nil
 }
 
+// ErrRestoreThroughSymlink is returned when a restore path's root or a
+// parent directory is a symlink, which could redirect the write elsewhere.
+var ErrRestoreThroughSymlink = errors.New("refusing to restore through symlink")
+
 func ensureNoSymlinkParents(rootPath, targetPath string) error {
 	root := filepath.Clean(rootPath)
 	target := filepath.Clean(targetPath)
@@ -539,7 +1065,7 @@ func ensureNoSymlinkParents(rootPath, targetPath string) error {
 
 	if fi, err := os.Lstat(root); err == nil {
 		if fi.Mode()&os.ModeSymlink != 0 {
-			return fmt.Errorf("refusing to restore through symlink root: %s", root)
+			return fmt.Errorf("%w root: %s", ErrRestoreThroughSymlink, root)
 		}
 	} else if !errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("lstat %s: %w", root, err)
@@ -564,22 +1090,31 @@ func ensureNoSymlinkParents(rootPath, targetPath string) error {
 			return fmt.Errorf("lstat %s: %w", cur, err)
 		}
 		if fi.Mode()&os.ModeSymlink != 0 {
-			return fmt.Errorf("refusing to restore through symlink parent: %s", cur)
+			return fmt.Errorf("%w parent: %s", ErrRestoreThroughSymlink, cur)
 		}
 	}
 
 	return nil
 }
 
-func restoreFilesystemRollback(data *RollbackData, opts RollbackRestoreOptions) error {
+// filesystemRollbackEntry is an entry of a filesystem capture's archive
+// resolved to the path it restores.
+type filesystemRollbackEntry struct {
+	hdr      *tar.Header
+	rootPath string
+	target   string
+}
+
+// walkFilesystemRollback calls fn for each entry of a filesystem capture's
+// archive, in archive order; fn may read the entry's content from r. Entry
+// names that do not map into a captured root are an error.
+func walkFilesystemRollback(data *RollbackData, fn func(e filesystemRollbackEntry, r io.Reader) error) error {
 	if data.Filesystem == nil {
 		return fmt.Errorf("filesystem rollback data missing")
 	}
-	rootMap := make(map[string]string, len(data.Filesystem.Roots))
-	for _, r := range data.Filesystem.Roots {
-		if r.ID != "" && r.Path != "" {
-			rootMap[r.ID] = r.Path
-		}
+	rootMap, err := data.Filesystem.RootPaths()
+	if err != nil {
+		return err
 	}
 	if len(rootMap) == 0 {
 		return fmt.Errorf("filesystem rollback roots missing")
@@ -636,89 +1171,113 @@ func restoreFilesystemRollback(data *RollbackData, opts RollbackRestoreOptions)
 			target = filepath.Join(rootPath, relOS)
 		}
 
-		mode := os.FileMode(hdr.Mode) & os.ModePerm
+		if err := fn(filesystemRollbackEntry{hdr: hdr, rootPath: rootPath, target: target}, tr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreFilesystemRollback(data *RollbackData, opts RollbackRestoreOptions) error {
+	return walkFilesystemRollback(data, func(e filesystemRollbackEntry, r io.Reader) error {
+		if !opts.includesEntry(e.hdr.Name) {
+			return nil
+		}
+		return restoreFilesystemEntry(e, r, opts)
+	})
+}
+
+// restoreFilesystemEntry writes one archive entry back to disk.
+func restoreFilesystemEntry(e filesystemRollbackEntry, r io.Reader, opts RollbackRestoreOptions) error {
+	hdr, rootPath, target := e.hdr, e.rootPath, e.target
+	mode := os.FileMode(hdr.Mode) & os.ModePerm
 
-		switch hdr.Typeflag {
-		case tar.TypeDir:
-			if err := ensureNoSymlinkParents(rootPath, target); err != nil {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := ensureNoSymlinkParents(rootPath, target); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(target, mode); err != nil {
+			return fmt.Errorf("creating dir %s: %w", target, err)
+		}
+	case tar.TypeSymlink:
+		parent := filepath.Dir(target)
+		if filepath.Clean(target) != filepath.Clean(rootPath) {
+			if err := ensureNoSymlinkParents(rootPath, parent); err != nil {
 				return err
 			}
-			if err := os.MkdirAll(target, mode); err != nil {
-				return fmt.Errorf("creating dir %s: %w", target, err)
-			}
-		case tar.TypeSymlink:
-			parent := filepath.Dir(target)
-			if filepath.Clean(target) != filepath.Clean(rootPath) {
-				if err := ensureNoSymlinkParents(rootPath, parent); err != nil {
-					return err
-				}
-			}
-			if info, err := os.Lstat(target); err == nil {
-				if !opts.Force {
-					return fmt.Errorf("path exists: %s (use --force to overwrite)", target)
-				}
-				if info.IsDir() {
-					if err := os.RemoveAll(target); err != nil {
-						return fmt.Errorf("removing %s: %w", target, err)
-					}
-				} else {
-					if err := os.Remove(target); err != nil {
-						return fmt.Errorf("removing %s: %w", target, err)
-					}
-				}
-			} else if err != nil && !errors.Is(err, os.ErrNotExist) {
-				return fmt.Errorf("lstat %s: %w", target, err)
-			}
-			if err := os.MkdirAll(parent, 0755); err != nil {
-				return fmt.Errorf("creating parent dir: %w", err)
-			}
-			if err := os.Symlink(hdr.Linkname, target); err != nil {
+		}
+		if err := removeExistingForRestore(target, opts); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(parent, 0755); err != nil {
+			return fmt.Errorf("creating parent dir: %w", err)
+		}
+		linkname := filepath.FromSlash(hdr.Linkname)
+		if err := createSymlink(linkname, target); err != nil {
+			if !symlinkNotPermitted(err) {
 				return fmt.Errorf("creating symlink %s: %w", target, err)
 			}
-		case tar.TypeReg, tar.TypeRegA:
-			parent := filepath.Dir(target)
-			if filepath.Clean(target) != filepath.Clean(rootPath) {
-				if err := ensureNoSymlinkParents(rootPath, parent); err != nil {
-					return err
-				}
-			}
-			if info, err := os.Lstat(target); err == nil {
-				if !opts.Force {
-					return fmt.Errorf("path exists: %s (use --force to overwrite)", target)
-				}
-				if info.IsDir() {
-					if err := os.RemoveAll(target); err != nil {
-						return fmt.Errorf("removing %s: %w", target, err)
-					}
-				} else {
-					if err := os.Remove(target); err != nil {
-						return fmt.Errorf("removing %s: %w", target, err)
-					}
-				}
-			} else if err != nil && !errors.Is(err, os.ErrNotExist) {
-				return fmt.Errorf("lstat %s: %w", target, err)
-			}
-			if err := os.MkdirAll(parent, 0755); err != nil {
-				return fmt.Errorf("creating parent dir: %w", err)
-			}
-			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
-			if err != nil {
-				return fmt.Errorf("creating file %s: %w", target, err)
-			}
-			if _, err := io.Copy(f, tr); err != nil {
-				f.Close()
-				return fmt.Errorf("writing file %s: %w", target, err)
+			if opts.OnSkippedSymlink != nil {
+				opts.OnSkippedSymlink(SkippedSymlink{Path: target, Target: linkname, Reason: err.Error()})
 			}
-			if err := f.Close(); err != nil {
-				return fmt.Errorf("closing file %s: %w", target, err)
+		}
+	case tar.TypeReg, tar.TypeRegA:
+		parent := filepath.Dir(target)
+		if filepath.Clean(target) != filepath.Clean(rootPath) {
+			if err := ensureNoSymlinkParents(rootPath, parent); err != nil {
+				return err
 			}
-			modTime := hdr.ModTime
-			_ = os.Chtimes(target, modTime, modTime)
-		default:
-			// Skip unsupported types.
 		}
+		if err := removeExistingForRestore(target, opts); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(parent, 0755); err != nil {
+			return fmt.Errorf("creating parent dir: %w", err)
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+		if err != nil {
+			return fmt.Errorf("creating file %s: %w", target, err)
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			f.Close()
+			return fmt.Errorf("writing file %s: %w", target, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("closing file %s: %w", target, err)
+		}
+		modTime := hdr.ModTime
+		_ = os.Chtimes(target, modTime, modTime)
+	default:
+		// Skip unsupported types.
 	}
+	return nil
+}
+
+// createSymlink is replaced in tests to simulate a platform that refuses
+// symlinks.
+var createSymlink = os.Symlink
 
+// removeExistingForRestore removes whatever is at target so a file or
+// symlink can be restored there; without Force an existing path is an error.
+func removeExistingForRestore(target string, opts RollbackRestoreOptions) error {
+	info, err := os.Lstat(target)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("lstat %s: %w", target, err)
+	}
+	if !opts.Force {
+		return fmt.Errorf("path exists: %s (use --force to overwrite)", target)
+	}
+	if info.IsDir() {
+		if err := os.RemoveAll(target); err != nil {
+			return fmt.Errorf("removing %s: %w", target, err)
+		}
+	} else if err := os.Remove(target); err != nil {
+		return fmt.Errorf("removing %s: %w", target, err)
+	}
 	return nil
 }
 
@@ -835,8 +1394,9 @@ func captureKubernetesRollback(ctx context.Context, rollbackDir string, req *db.
 	if len(tokens) < 2 || tokens[1] != "delete" {
 		return nil, fmt.Errorf("unsupported kubectl command")
 	}
-	if _, err := exec.LookPath("kubectl"); err != nil {
-		return nil, fmt.Errorf("kubectl not found in PATH")
+	kubectl, err := kubectlBinary()
+	if err != nil {
+		return nil, err
 	}
 
 	captureCtx, cancel := context.WithTimeout(ctx, defaultRollbackCmdTimeout)
@@ -868,7 +1428,7 @@ func captureKubernetesRollback(ctx context.Context, rollbackDir string, req *db.
 		}
 		args = append(args, "-o", "yaml")
 
-		out, err := runCmdString(captureCtx, cwd, "kubectl", args...)
+		out, err := runCmdString(captureCtx, cwd, kubectl, args...)
 		if err != nil {
 			return nil, fmt.Errorf("kubectl get %s/%s: %w", r.Kind, r.Name, err)
 		}
@@ -951,8 +1511,9 @@ func restoreKubernetesRollback(ctx context.Context, data *RollbackData, _ Rollba
 	if data.Kubernetes == nil {
 		return fmt.Errorf("kubernetes rollback data missing")
 	}
-	if _, err := exec.LookPath("kubectl"); err != nil {
-		return fmt.Errorf("kubectl not found in PATH")
+	kubectl, err := kubectlBinary()
+	if err != nil {
+		return err
 	}
 
 	restoreCtx, cancel := context.WithTimeout(ctx, 2*DefaultExecutionTimeout)
@@ -966,13 +1527,28 @@ func restoreKubernetesRollback(ctx context.Context, data *RollbackData, _ Rollba
 	for _, rel := range data.Kubernetes.Manifests {
 		full := filepath.Join(data.RollbackPath, filepath.FromSlash(rel))
 		args := []string{"apply", "-f", full}
-		if _, err := runCmdString(restoreCtx, cwd, "kubectl", args...); err != nil {
+		if _, err := runCmdString(restoreCtx, cwd, kubectl, args...); err != nil {
 			return fmt.Errorf("kubectl apply %s: %w", rel, err)
 		}
 	}
 	return nil
 }
 
+// kubectlBinary returns the kubectl to run. On Windows kubectl.exe is
+// preferred over a kubectl.bat or .cmd shim found earlier in PATH.
+func kubectlBinary() (string, error) {
+	names := []string{"kubectl"}
+	if runtime.GOOS == "windows" {
+		names = []string{"kubectl.exe", "kubectl"}
+	}
+	for _, name := range names {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("kubectl not found in PATH")
+}
+
 func runCmdString(ctx context.Context, dir, name string, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Env = os.Environ()
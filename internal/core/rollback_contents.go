@@ -0,0 +1,126 @@
+// Package core implements listing the contents of rollback captures.
+package core
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RollbackEntry is one item of a rollback capture.
+type RollbackEntry struct {
+	// Name is the archive entry name for filesystem captures, "HEAD" or
+	// "branch" for git refs, and the capture-relative file otherwise.
+	Name string `json:"name"`
+	// Type is file, dir, symlink or other for filesystem entries, and
+	// head, branch, diff or manifest for git and kubernetes captures.
+	Type string `json:"type"`
+	// Path is where a filesystem entry restores to.
+	Path       string      `json:"path,omitempty"`
+	Size       int64       `json:"size"`
+	Mode       os.FileMode `json:"mode,omitempty"`
+	IsSymlink  bool        `json:"is_symlink,omitempty"`
+	LinkTarget string      `json:"link_target,omitempty"`
+	// Value is the commit or branch name of a git ref entry.
+	Value string `json:"value,omitempty"`
+}
+
+// ListRollbackContents lists what a capture holds without restoring or
+// extracting it. Filesystem archives are read header by header; git and
+// kubernetes captures are described from their metadata and the sizes of
+// the files it names.
+func ListRollbackContents(data *RollbackData) ([]RollbackEntry, error) {
+	if data == nil {
+		return nil, fmt.Errorf("rollback data is required")
+	}
+	if strings.TrimSpace(data.RollbackPath) == "" {
+		return nil, fmt.Errorf("rollback path is required")
+	}
+
+	switch data.Kind {
+	case rollbackKindFilesystem:
+		return listFilesystemRollback(data)
+	case rollbackKindGit:
+		return listGitRollback(data)
+	case rollbackKindKubernetes:
+		return listKubernetesRollback(data)
+	default:
+		return nil, fmt.Errorf("listing contents is not supported for %s rollbacks", data.Kind)
+	}
+}
+
+func listFilesystemRollback(data *RollbackData) ([]RollbackEntry, error) {
+	var entries []RollbackEntry
+	err := walkFilesystemRollback(data, func(e filesystemRollbackEntry, _ io.Reader) error {
+		hdr := e.hdr
+		entry := RollbackEntry{
+			Name: hdr.Name,
+			Path: e.target,
+			Size: hdr.Size,
+			Mode: hdr.FileInfo().Mode(),
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			entry.Type = "dir"
+		case tar.TypeReg, tar.TypeRegA:
+			entry.Type = "file"
+		case tar.TypeSymlink:
+			entry.Type = "symlink"
+			entry.IsSymlink = true
+			entry.LinkTarget = hdr.Linkname
+		default:
+			entry.Type = "other"
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func listGitRollback(data *RollbackData) ([]RollbackEntry, error) {
+	if data.Git == nil {
+		return nil, fmt.Errorf("git rollback data missing")
+	}
+	entries := []RollbackEntry{{Name: "HEAD", Type: "head", Value: data.Git.Head}}
+	if data.Git.Branch != "" {
+		entries = append(entries, RollbackEntry{Name: "branch", Type: "branch", Value: data.Git.Branch})
+	}
+	if data.Git.DiffFile != "" {
+		entry, err := rollbackFileEntry(data, data.Git.DiffFile, "diff")
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func listKubernetesRollback(data *RollbackData) ([]RollbackEntry, error) {
+	if data.Kubernetes == nil {
+		return nil, fmt.Errorf("kubernetes rollback data missing")
+	}
+	entries := make([]RollbackEntry, 0, len(data.Kubernetes.Manifests))
+	for _, rel := range data.Kubernetes.Manifests {
+		entry, err := rollbackFileEntry(data, rel, "manifest")
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// rollbackFileEntry describes a file stored in the capture directory.
+func rollbackFileEntry(data *RollbackData, rel, typ string) (RollbackEntry, error) {
+	info, err := os.Lstat(filepath.Join(data.RollbackPath, filepath.FromSlash(rel)))
+	if err != nil {
+		return RollbackEntry{}, fmt.Errorf("stat %s: %w", rel, err)
+	}
+	return RollbackEntry{Name: rel, Type: typ, Size: info.Size(), Mode: info.Mode()}, nil
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func TestListRollbackContents_Filesystem(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks not reliably supported on windows")
+	}
+	project := t.TempDir()
+	work := filepath.Join(project, "work")
+	buildDir := filepath.Join(work, "build")
+	if err := os.MkdirAll(filepath.Join(buildDir, "sub"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(buildDir, "sub", "a.txt"), []byte("hello"), 0640); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.Symlink("sub/a.txt", filepath.Join(buildDir, "link")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	req := &db.Request{
+		ID:          "test-list-fs",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "rm -rf build", Cwd: work},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{MaxSizeBytes: 10 << 20})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+
+	entries, err := ListRollbackContents(data)
+	if err != nil {
+		t.Fatalf("ListRollbackContents: %v", err)
+	}
+	byPath := make(map[string]RollbackEntry, len(entries))
+	for _, e := range entries {
+		rel, _ := filepath.Rel(buildDir, e.Path)
+		byPath[filepath.ToSlash(rel)] = e
+	}
+
+	file, ok := byPath["sub/a.txt"]
+	if !ok {
+		t.Fatalf("sub/a.txt not listed: %+v", entries)
+	}
+	if file.Type != "file" || file.Size != 5 || file.Mode.Perm() != 0640 || file.IsSymlink {
+		t.Errorf("sub/a.txt = %+v", file)
+	}
+	if dir := byPath["sub"]; dir.Type != "dir" || !dir.Mode.IsDir() {
+		t.Errorf("sub = %+v", dir)
+	}
+	link := byPath["link"]
+	if link.Type != "symlink" || !link.IsSymlink || link.LinkTarget != "sub/a.txt" {
+		t.Errorf("link = %+v", link)
+	}
+
+	// Listing must not extract anything.
+	if err := os.RemoveAll(buildDir); err != nil {
+		t.Fatalf("remove build: %v", err)
+	}
+	if _, err := ListRollbackContents(data); err != nil {
+		t.Fatalf("ListRollbackContents after removal: %v", err)
+	}
+	if _, err := os.Stat(buildDir); !os.IsNotExist(err) {
+		t.Fatalf("expected build dir to stay removed, stat err = %v", err)
+	}
+}
+
+func TestListRollbackContents_Git(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, rollbackGitDirName), 0700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	diff := "diff --git a/a.txt b/a.txt\n"
+	diffFile := rollbackGitDirName + "/" + rollbackGitDiffFilename
+	if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(diffFile)), []byte(diff), 0600); err != nil {
+		t.Fatalf("write diff: %v", err)
+	}
+
+	data := &RollbackData{
+		RollbackPath: dir,
+		Kind:         rollbackKindGit,
+		Git:          &GitRollbackData{Head: "abc123", Branch: "main", DiffFile: diffFile},
+	}
+	entries, err := ListRollbackContents(data)
+	if err != nil {
+		t.Fatalf("ListRollbackContents: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", entries)
+	}
+	if e := entries[0]; e.Type != "head" || e.Value != "abc123" {
+		t.Errorf("head entry = %+v", e)
+	}
+	if e := entries[1]; e.Type != "branch" || e.Value != "main" {
+		t.Errorf("branch entry = %+v", e)
+	}
+	if e := entries[2]; e.Type != "diff" || e.Name != diffFile || e.Size != int64(len(diff)) {
+		t.Errorf("diff entry = %+v", e)
+	}
+
+	// A detached HEAD has no branch entry; a missing diff file is an error.
+	data.Git.Branch = ""
+	data.Git.DiffFile = "git/missing.patch"
+	if _, err := ListRollbackContents(data); err == nil || !strings.Contains(err.Error(), "missing.patch") {
+		t.Fatalf("expected missing diff error, got %v", err)
+	}
+}
+
+func TestListRollbackContents_Kubernetes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, rollbackKubernetesDirName), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	manifests := []string{"k8s/deployment_web.yaml", "k8s/service_web.yaml"}
+	for _, m := range manifests {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(m)), []byte("kind: x\n"), 0600); err != nil {
+			t.Fatalf("write manifest: %v", err)
+		}
+	}
+
+	data := &RollbackData{
+		RollbackPath: dir,
+		Kind:         rollbackKindKubernetes,
+		Kubernetes:   &KubernetesRollbackData{Manifests: manifests},
+	}
+	entries, err := ListRollbackContents(data)
+	if err != nil {
+		t.Fatalf("ListRollbackContents: %v", err)
+	}
+	if len(entries) != len(manifests) {
+		t.Fatalf("expected %d entries, got %+v", len(manifests), entries)
+	}
+	for i, e := range entries {
+		if e.Name != manifests[i] || e.Type != "manifest" || e.Size != 8 {
+			t.Errorf("entry %d = %+v", i, e)
+		}
+	}
+}
+
+func TestListRollbackContents_Errors(t *testing.T) {
+	if _, err := ListRollbackContents(nil); err == nil {
+		t.Error("expected error for nil data")
+	}
+	if _, err := ListRollbackContents(&RollbackData{Kind: rollbackKindGit}); err == nil {
+		t.Error("expected error for missing rollback path")
+	}
+	dir := t.TempDir()
+	for _, kind := range []string{rollbackKindFilesystem, rollbackKindGit, rollbackKindKubernetes} {
+		if _, err := ListRollbackContents(&RollbackData{RollbackPath: dir, Kind: kind}); err == nil {
+			t.Errorf("expected error for %s without its data", kind)
+		}
+	}
+	_, err := ListRollbackContents(&RollbackData{RollbackPath: dir, Kind: rollbackKindDocker})
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("expected unsupported kind error, got %v", err)
+	}
+}
@@ -0,0 +1,544 @@
+// Package core implements database table rollback capture and restoration.
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// DatabaseRollbackData records the tables a psql, mysql or sqlite3 command
+// changed. Each dump file is a pg_dump, mysqldump or sqlite3 .dump of one
+// table taken before execution, relative to the rollback directory.
+type DatabaseRollbackData struct {
+	// Client is "psql", "mysql" or "sqlite3".
+	Client string `json:"client"`
+	// Database is the database (psql connection string, or sqlite3 database
+	// file) the command ran against, with any password removed.
+	Database string `json:"database"`
+	// ConnArgs are the connection flags passed to the client and its dump
+	// tool, with any password removed: restore takes the password from the
+	// environment (PGPASSWORD, ~/.pgpass, MYSQL_PWD, ~/.my.cnf).
+	ConnArgs []string            `json:"conn_args,omitempty"`
+	Tables   []DatabaseTableDump `json:"tables"`
+}
+
+// DatabaseTableDump is one dumped table.
+type DatabaseTableDump struct {
+	Table string `json:"table"`
+	// Statement is the kind of SQL statement that targets the table
+	// (DELETE, DROP TABLE, TRUNCATE or UPDATE).
+	Statement string `json:"statement"`
+	DumpFile  string `json:"dump_file"`
+}
+
+// databaseCommand is a parsed psql, mysql or sqlite3 invocation running SQL
+// that changes known tables.
+type databaseCommand struct {
+	client   string
+	database string
+	// connArgs are the connection flags as given, passwords included.
+	connArgs []string
+	tables   []sqlTarget
+}
+
+// sqlTarget is a table a destructive SQL statement targets.
+type sqlTarget struct {
+	table     string
+	statement string
+}
+
+// databaseDumpTools maps each supported client to its dump binary.
+var databaseDumpTools = map[string]string{
+	"psql":    "pg_dump",
+	"mysql":   "mysqldump",
+	"sqlite3": "sqlite3",
+}
+
+// psqlValueFlags are psql's short flags that take a value.
+var psqlValueFlags = map[string]string{
+	"-c": "--command", "-d": "--dbname", "-h": "--host", "-p": "--port",
+	"-U": "--username", "-f": "--file", "-v": "--set", "-o": "--output",
+	"-P": "--pset", "-F": "--field-separator", "-R": "--record-separator",
+	"-T": "--table-attr", "-L": "--log-file",
+}
+
+// mysqlValueFlags are mysql's short flags that take a value. -p only takes
+// an attached value (-psecret); on its own it prompts.
+var mysqlValueFlags = map[string]string{
+	"-e": "--execute", "-D": "--database", "-h": "--host", "-P": "--port",
+	"-u": "--user", "-S": "--socket",
+}
+
+// sqliteValueFlags are sqlite3's options that take a value. sqlite3 accepts
+// each with one or two leading dashes.
+var sqliteValueFlags = map[string]bool{
+	"-cmd": true, "-init": true, "-separator": true, "-newline": true,
+	"-nullvalue": true, "-vfs": true, "-maxsize": true, "-mmap": true,
+	"-escape": true,
+}
+
+const sqlIdent = "(?:\"[^\"]+\"|`[^`]+`|[A-Za-z_][A-Za-z0-9_$]*)(?:\\.(?:\"[^\"]+\"|`[^`]+`|[A-Za-z_][A-Za-z0-9_$]*))?"
+
+// sqlTargetPatterns find the tables destructive statements target. Group 1
+// is the table (or comma separated list of tables).
+var sqlTargetPatterns = []struct {
+	statement string
+	re        *regexp.Regexp
+}{
+	{"DELETE", regexp.MustCompile(`(?i)\bDELETE\s+FROM\s+(?:ONLY\s+)?(` + sqlIdent + `)`)},
+	{"DROP TABLE", regexp.MustCompile(`(?i)\bDROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(` + sqlIdent + `(?:\s*,\s*` + sqlIdent + `)*)`)},
+	{"TRUNCATE", regexp.MustCompile(`(?i)\bTRUNCATE\s+(?:TABLE\s+)?(?:ONLY\s+)?(` + sqlIdent + `(?:\s*,\s*` + sqlIdent + `)*)`)},
+	{"UPDATE", regexp.MustCompile(`(?i)\bUPDATE\s+(?:ONLY\s+)?(` + sqlIdent + `)\s+SET\b`)},
+}
+
+// sqlTargets returns the tables the destructive statements in sql target,
+// in order of first appearance.
+func sqlTargets(sql string) []sqlTarget {
+	type hit struct {
+		pos int
+		sqlTarget
+	}
+	var hits []hit
+	for _, p := range sqlTargetPatterns {
+		for _, m := range p.re.FindAllStringSubmatchIndex(sql, -1) {
+			for _, name := range strings.Split(sql[m[2]:m[3]], ",") {
+				hits = append(hits, hit{m[0], sqlTarget{table: strings.TrimSpace(name), statement: p.statement}})
+			}
+		}
+	}
+	// Stable, so a statement's tables keep their listed order.
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].pos < hits[j].pos })
+	seen := make(map[string]bool, len(hits))
+	var targets []sqlTarget
+	for _, h := range hits {
+		key := strings.ToLower(h.table)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		targets = append(targets, h.sqlTarget)
+	}
+	return targets
+}
+
+// parseDatabaseCommand recognizes psql -c, mysql -e and sqlite3 commands
+// whose SQL targets known tables. The database must be named: for psql with
+// -d/--dbname or a connection string (postgres://..., host=... dbname=...),
+// for mysql with -D/--database or its database argument, for sqlite3 as a
+// database file. It returns nil for anything else.
+func parseDatabaseCommand(tokens []string) *databaseCommand {
+	if len(tokens) == 0 {
+		return nil
+	}
+	client := filepath.Base(tokens[0])
+	var valueFlags map[string]string
+	switch client {
+	case "psql":
+		valueFlags = psqlValueFlags
+	case "mysql":
+		valueFlags = mysqlValueFlags
+	case "sqlite3":
+		return parseSQLiteCommand(tokens)
+	default:
+		return nil
+	}
+
+	c := &databaseCommand{client: client}
+	var sql []string
+	var positional []string
+	args := tokens[1:]
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		flag, value, hasValue := a, "", false
+		switch {
+		case a == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+			continue
+		case strings.HasPrefix(a, "--"):
+			if k, v, ok := strings.Cut(a, "="); ok {
+				flag, value, hasValue = k, v, true
+			}
+		case strings.HasPrefix(a, "-") && len(a) > 2:
+			if _, ok := valueFlags[a[:2]]; ok || (client == "mysql" && a[:2] == "-p") {
+				flag, value, hasValue = a[:2], a[2:], true
+			}
+		case !strings.HasPrefix(a, "-"):
+			positional = append(positional, a)
+			continue
+		}
+		long, takesValue := valueFlags[flag]
+		if takesValue {
+			flag = long
+		} else {
+			for _, l := range valueFlags {
+				takesValue = takesValue || flag == l
+			}
+		}
+		if takesValue && !hasValue {
+			if i+1 >= len(args) {
+				return nil
+			}
+			i++
+			value = args[i]
+		}
+
+		switch flag {
+		case "--command", "--execute":
+			sql = append(sql, value)
+		case "--dbname", "--database":
+			c.database = value
+		case "--host", "--port", "--username", "--user", "--socket":
+			c.connArgs = append(c.connArgs, flag+"="+value)
+		case "-p", "--password":
+			if client == "mysql" && hasValue {
+				c.connArgs = append(c.connArgs, "--password="+value)
+			}
+		}
+	}
+
+	if c.database == "" && len(positional) > 0 {
+		switch {
+		case client == "mysql":
+			c.database = positional[0]
+		case strings.Contains(positional[0], "://") || strings.Contains(positional[0], "="):
+			c.database = positional[0]
+		}
+	}
+	if c.database == "" || len(sql) == 0 {
+		return nil
+	}
+	c.tables = sqlTargets(strings.Join(sql, ";\n"))
+	if len(c.tables) == 0 {
+		return nil
+	}
+	return c
+}
+
+// parseSQLiteCommand recognizes sqlite3 DATABASE SQL... commands, with SQL
+// given as arguments or with -cmd, whose SQL targets known tables. SQL read
+// from standard input is not seen, and an in-memory database has nothing to
+// capture.
+func parseSQLiteCommand(tokens []string) *databaseCommand {
+	c := &databaseCommand{client: "sqlite3"}
+	var sql []string
+	var positional []string
+	args := tokens[1:]
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(a, "-") {
+			positional = append(positional, a)
+			continue
+		}
+		flag := "-" + strings.TrimLeft(a, "-")
+		if !sqliteValueFlags[flag] {
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil
+		}
+		i++
+		if flag == "-cmd" {
+			sql = append(sql, args[i])
+		}
+	}
+	if len(positional) == 0 || positional[0] == "" || positional[0] == ":memory:" {
+		return nil
+	}
+	c.database = positional[0]
+	sql = append(sql, positional[1:]...)
+	if len(sql) == 0 {
+		return nil
+	}
+	c.tables = sqlTargets(strings.Join(sql, ";\n"))
+	if len(c.tables) == 0 {
+		return nil
+	}
+	return c
+}
+
+// detectDatabaseRollback returns the parsed database command when raw is a
+// single psql, mysql or sqlite3 command that can be captured: its SQL targets known
+// tables and the client's dump tool is on PATH. Without the dump tool the
+// command runs without a rollback capture.
+//
+// The command is re-tokenized from raw rather than from the normalized
+// primary command, which loses the quoting around the SQL.
+func detectDatabaseRollback(raw string) *databaseCommand {
+	normalized := NormalizeCommand(raw)
+	if len(normalized.Segments) != 1 || len(normalized.Heredocs) > 0 {
+		return nil
+	}
+	tokens := parseShellTokens(normalized.Original)
+	for len(tokens) > 0 && (isEnvAssignment(tokens[0]) || isWrapper(tokens[0])) {
+		tokens = tokens[1:]
+	}
+	c := parseDatabaseCommand(tokens)
+	if c == nil {
+		return nil
+	}
+	if _, err := exec.LookPath(databaseDumpTools[c.client]); err != nil {
+		return nil
+	}
+	return c
+}
+
+// redactConnArg removes a password from a connection flag or string. It
+// returns "" for a flag that is only a password.
+func redactConnArg(arg string) string {
+	if strings.HasPrefix(arg, "--password=") {
+		return ""
+	}
+	if strings.Contains(arg, "://") {
+		u, err := url.Parse(arg)
+		if err != nil {
+			return arg
+		}
+		if _, ok := u.User.Password(); ok {
+			u.User = url.User(u.User.Username())
+			arg = u.String()
+		}
+		if q := u.Query(); q.Has("password") {
+			q.Del("password")
+			u.RawQuery = q.Encode()
+			arg = u.String()
+		}
+		return arg
+	}
+	if strings.Contains(arg, "password=") {
+		fields := strings.Fields(arg)
+		kept := fields[:0]
+		for _, f := range fields {
+			if !strings.HasPrefix(f, "password=") {
+				kept = append(kept, f)
+			}
+		}
+		return strings.Join(kept, " ")
+	}
+	return arg
+}
+
+// databaseDumpArgs returns the dump tool arguments that write table to
+// standard output.
+func databaseDumpArgs(client, database string, connArgs []string, table string) []string {
+	args := append([]string{}, connArgs...)
+	switch client {
+	case "psql":
+		return append(args, "--dbname="+database, "--table="+table, "--clean", "--if-exists")
+	case "sqlite3":
+		return append(args, database, ".dump '"+sqliteTableName(table)+"'")
+	}
+	// mysqldump adds DROP TABLE IF EXISTS before each table by default.
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		database, table = strings.Trim(schema, "`"), name
+	}
+	return append(args, database, strings.Trim(table, "`"))
+}
+
+// databaseDumpHeader returns what precedes a table's dump so that replaying
+// it replaces the table. pg_dump --clean and mysqldump write their own DROP
+// TABLE; sqlite3 .dump does not.
+func databaseDumpHeader(client, table string) string {
+	if client != "sqlite3" {
+		return ""
+	}
+	name := sqliteTableName(table)
+	return fmt.Sprintf("DROP TABLE IF EXISTS \"%s\";\n", strings.ReplaceAll(name, `"`, `""`))
+}
+
+// sqliteTableName returns a table reference's unquoted table name, without
+// any schema.
+func sqliteTableName(table string) string {
+	if _, name, ok := strings.Cut(table, "."); ok {
+		table = name
+	}
+	return strings.Trim(table, "\"`[]")
+}
+
+// errDumpTooLarge is returned by cappedWriter past its limit.
+var errDumpTooLarge = errors.New("dump too large")
+
+// cappedWriter writes at most limit bytes (no limit when negative); the
+// first write past it calls stop and fails.
+type cappedWriter struct {
+	w        io.Writer
+	limit    int64
+	n        int64
+	stop     func()
+	exceeded bool
+}
+
+func (c *cappedWriter) Write(p []byte) (int, error) {
+	if c.limit >= 0 && c.n+int64(len(p)) > c.limit {
+		c.exceeded = true
+		c.stop()
+		return 0, errDumpTooLarge
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// runDatabaseDump runs tool in cwd and writes header and the tool's standard
+// output to outFile, returning the bytes written. With limit >= 0 the tool is
+// killed, and errDumpTooLarge returned, as soon as the file would grow past
+// limit bytes.
+func runDatabaseDump(ctx context.Context, cwd, outFile, header string, limit int64, tool string, args ...string) (int64, error) {
+	f, err := os.OpenFile(outFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("creating table dump: %w", err)
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	out := &cappedWriter{w: f, limit: limit, stop: cancel}
+	if _, err := io.WriteString(out, header); err != nil {
+		return out.n, err
+	}
+
+	cmd := exec.CommandContext(ctx, tool, args...)
+	cmd.Env = os.Environ()
+	if strings.TrimSpace(cwd) != "" {
+		cmd.Dir = cwd
+	}
+	var stderr bytes.Buffer
+	cmd.Stdout = out
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if out.exceeded {
+		return out.n, errDumpTooLarge
+	}
+	if err != nil {
+		return out.n, fmt.Errorf("%s %s: %w\n%s", tool, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out.n, f.Close()
+}
+
+// captureDatabaseRollback dumps each table the command targets. The dumps
+// together may not exceed opts.MaxSizeBytes: a dump that would is stopped,
+// and the capture fails without leaving partial dumps behind.
+func captureDatabaseRollback(ctx context.Context, rollbackDir string, req *db.Request, opts RollbackCaptureOptions) (_ *DatabaseRollbackData, err error) {
+	parsed := detectDatabaseRollback(req.Command.Raw)
+	if parsed == nil {
+		return nil, fmt.Errorf("unsupported database command")
+	}
+	tool := databaseDumpTools[parsed.client]
+
+	captureCtx, cancel := context.WithTimeout(ctx, defaultRollbackCmdTimeout)
+	defer cancel()
+
+	cwd := req.Command.Cwd
+	if strings.TrimSpace(cwd) == "" {
+		cwd = req.ProjectPath
+	}
+
+	outDir := filepath.Join(rollbackDir, rollbackDatabaseDirName)
+	if err := os.MkdirAll(outDir, 0700); err != nil {
+		return nil, fmt.Errorf("creating database rollback dir: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(outDir)
+		}
+	}()
+
+	data := &DatabaseRollbackData{
+		Client:   parsed.client,
+		Database: redactConnArg(parsed.database),
+	}
+	for _, arg := range parsed.connArgs {
+		if redacted := redactConnArg(arg); redacted != "" {
+			data.ConnArgs = append(data.ConnArgs, redacted)
+		}
+	}
+	var total int64
+	for i, target := range parsed.tables {
+		// Numbered so tables differing only in quoting or case don't collide.
+		filename := fmt.Sprintf("%d_%s.sql", i, sanitizeFilename(strings.Trim(target.table, "\"`")))
+		out := filepath.Join(outDir, filename)
+		limit := int64(-1)
+		if opts.MaxSizeBytes > 0 {
+			limit = opts.MaxSizeBytes - total
+		}
+		args := databaseDumpArgs(parsed.client, parsed.database, parsed.connArgs, target.table)
+		n, err := runDatabaseDump(captureCtx, cwd, out, databaseDumpHeader(parsed.client, target.table), limit, tool, args...)
+		if errors.Is(err, errDumpTooLarge) {
+			return nil, fmt.Errorf("%s %s: rollback capture %w (%d bytes)", tool, target.table, errSizeCapExceeded, opts.MaxSizeBytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", tool, target.table, err)
+		}
+		total += n
+		data.Tables = append(data.Tables, DatabaseTableDump{
+			Table:     target.table,
+			Statement: target.statement,
+			DumpFile:  filepath.ToSlash(filepath.Join(rollbackDatabaseDirName, filename)),
+		})
+	}
+	return data, nil
+}
+
+func restoreDatabaseRollback(ctx context.Context, data *RollbackData, opts RollbackRestoreOptions) error {
+	if data.Database == nil {
+		return fmt.Errorf("database rollback data missing")
+	}
+	if !opts.Force {
+		return fmt.Errorf("database rollback replaces the tables' current contents (use --force)")
+	}
+	d := data.Database
+	if _, ok := databaseDumpTools[d.Client]; !ok {
+		return fmt.Errorf("unsupported database client: %s", d.Client)
+	}
+	if _, err := exec.LookPath(d.Client); err != nil {
+		return fmt.Errorf("%s not found in PATH", d.Client)
+	}
+
+	restoreCtx, cancel := context.WithTimeout(ctx, 2*DefaultExecutionTimeout)
+	defer cancel()
+
+	cwd := data.CommandCwd
+	if strings.TrimSpace(cwd) == "" {
+		cwd = data.ProjectPath
+	}
+
+	for _, dump := range d.Tables {
+		full := filepath.Join(data.RollbackPath, filepath.FromSlash(dump.DumpFile))
+		if _, err := os.Stat(full); err != nil {
+			return fmt.Errorf("reading table dump %s: %w", dump.Table, err)
+		}
+		args := append([]string{}, d.ConnArgs...)
+		switch d.Client {
+		case "psql":
+			args = append(args, "--dbname="+d.Database, "--set=ON_ERROR_STOP=1", "--single-transaction", "--file="+full)
+		case "sqlite3":
+			args = append(args, "-bail", d.Database, ".read '"+full+"'")
+		default:
+			database := d.Database
+			if schema, _, ok := strings.Cut(dump.Table, "."); ok {
+				database = strings.Trim(schema, "`")
+			}
+			args = append(args, "--database="+database, "--execute=source "+full)
+		}
+		if _, err := runCmdString(restoreCtx, cwd, d.Client, args...); err != nil {
+			return fmt.Errorf("restoring table %s: %w", dump.Table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func TestSQLTargets(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []sqlTarget
+	}{
+		{"DELETE FROM users WHERE id = 3", []sqlTarget{{"users", "DELETE"}}},
+		{"delete from only public.users", []sqlTarget{{"public.users", "DELETE"}}},
+		{"DROP TABLE IF EXISTS a, b CASCADE", []sqlTarget{{"a", "DROP TABLE"}, {"b", "DROP TABLE"}}},
+		{"TRUNCATE TABLE `orders`", []sqlTarget{{"`orders`", "TRUNCATE"}}},
+		{`UPDATE "Accounts" SET balance = 0`, []sqlTarget{{`"Accounts"`, "UPDATE"}}},
+		{"TRUNCATE logs; DELETE FROM users; delete from LOGS", []sqlTarget{{"logs", "TRUNCATE"}, {"users", "DELETE"}}},
+		{"SELECT * FROM users", nil},
+		{"DROP INDEX users_email_idx", nil},
+	}
+	for _, tt := range tests {
+		if got := sqlTargets(tt.sql); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sqlTargets(%q) = %+v, want %+v", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestParseDatabaseCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []string
+		want   *databaseCommand
+	}{
+		{"psql -d", []string{"psql", "-h", "db1", "-U", "app", "-d", "shop", "-c", "DELETE FROM users"},
+			&databaseCommand{client: "psql", database: "shop", connArgs: []string{"--host=db1", "--username=app"}, tables: []sqlTarget{{"users", "DELETE"}}}},
+		{"psql connection string", []string{"psql", "postgres://app:pw@db1/shop", "-c", "DROP TABLE orders"},
+			&databaseCommand{client: "psql", database: "postgres://app:pw@db1/shop", tables: []sqlTarget{{"orders", "DROP TABLE"}}}},
+		{"psql attached values", []string{"psql", "-dshop", "-p5433", "--command=TRUNCATE logs"},
+			&databaseCommand{client: "psql", database: "shop", connArgs: []string{"--port=5433"}, tables: []sqlTarget{{"logs", "TRUNCATE"}}}},
+		{"mysql -D", []string{"mysql", "-u", "root", "-psecret", "-D", "shop", "-e", "DELETE FROM users"},
+			&databaseCommand{client: "mysql", database: "shop", connArgs: []string{"--user=root", "--password=secret"}, tables: []sqlTarget{{"users", "DELETE"}}}},
+		{"mysql database argument", []string{"mysql", "-p", "shop", "-e", "DROP TABLE carts"},
+			&databaseCommand{client: "mysql", database: "shop", tables: []sqlTarget{{"carts", "DROP TABLE"}}}},
+		{"psql plain database name", []string{"psql", "shop", "-c", "DELETE FROM users"}, nil},
+		{"no database", []string{"psql", "-c", "DELETE FROM users"}, nil},
+		{"read-only SQL", []string{"psql", "-d", "shop", "-c", "SELECT 1"}, nil},
+		{"script file", []string{"psql", "-d", "shop", "-f", "cleanup.sql"}, nil},
+		{"sqlite3", []string{"sqlite3", "-bail", "shop.db", "DELETE FROM users", "DROP TABLE carts"},
+			&databaseCommand{client: "sqlite3", database: "shop.db", tables: []sqlTarget{{"users", "DELETE"}, {"carts", "DROP TABLE"}}}},
+		{"sqlite3 -cmd", []string{"sqlite3", "-cmd", "TRUNCATE logs", "--separator", ",", "shop.db"},
+			&databaseCommand{client: "sqlite3", database: "shop.db", tables: []sqlTarget{{"logs", "TRUNCATE"}}}},
+		{"sqlite3 in-memory", []string{"sqlite3", ":memory:", "DELETE FROM users"}, nil},
+		{"sqlite3 without SQL", []string{"sqlite3", "shop.db"}, nil},
+		{"other client", []string{"duckdb", "shop.db", "DELETE FROM users"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDatabaseCommand(tt.tokens); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDatabaseCommand(%q) = %+v, want %+v", tt.tokens, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactConnArg(t *testing.T) {
+	tests := map[string]string{
+		"--password=secret":          "",
+		"--host=db1":                 "--host=db1",
+		"postgres://app:pw@db1/shop": "postgres://app@db1/shop",
+		"postgres://db1/shop?password=pw&sslmode=require": "postgres://db1/shop?sslmode=require",
+		"host=db1 password=pw dbname=shop":                "host=db1 dbname=shop",
+		"shop":                                            "shop",
+	}
+	for in, want := range tests {
+		if got := redactConnArg(in); got != want {
+			t.Errorf("redactConnArg(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// installFakePostgres puts pg_dump and psql scripts on PATH. pg_dump dumps
+// its arguments; both log their invocation to the returned file.
+func installFakePostgres(t *testing.T, dir string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script clients not supported on windows")
+	}
+	binDir := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("mkdir bin: %v", err)
+	}
+	logPath := filepath.Join(dir, "pg.log")
+	t.Setenv("PG_LOG", logPath)
+	t.Setenv("PATH", binDir)
+
+	pgDump := `#!/bin/sh
+echo "pg_dump $*" >> "$PG_LOG"
+echo "-- dump: $*"
+`
+	psql := `#!/bin/sh
+echo "psql $*" >> "$PG_LOG"
+`
+	for name, script := range map[string]string{"pg_dump": pgDump, "psql": psql} {
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0755); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return logPath
+}
+
+func TestRollbackDatabaseCaptureAndRestoreWithFakePostgres(t *testing.T) {
+	project := t.TempDir()
+	logPath := installFakePostgres(t, project)
+
+	req := &db.Request{
+		ID:          "test-database",
+		ProjectPath: project,
+		Command: db.CommandSpec{
+			Raw: `PGPASSWORD=pw psql "postgres://app:pw@db1/shop" -c "DELETE FROM users WHERE active = false; DROP TABLE carts"`,
+			Cwd: project,
+		},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if data == nil || data.Kind != rollbackKindDatabase || data.Database == nil || len(data.Database.Tables) != 2 {
+		t.Fatalf("expected two tables captured, got %+v", data)
+	}
+	d := data.Database
+	if d.Client != "psql" || d.Database != "postgres://app@db1/shop" {
+		t.Errorf("expected the password to be removed from the stored database, got %+v", d)
+	}
+	if d.Tables[0].Table != "users" || d.Tables[0].Statement != "DELETE" || d.Tables[1].Table != "carts" {
+		t.Errorf("unexpected tables: %+v", d.Tables)
+	}
+	dump, err := os.ReadFile(filepath.Join(data.RollbackPath, filepath.FromSlash(d.Tables[0].DumpFile)))
+	if err != nil || !strings.Contains(string(dump), "--table=users --clean --if-exists") {
+		t.Fatalf("expected the users dump, got %q (%v)", dump, err)
+	}
+
+	loaded, err := LoadRollbackData(data.RollbackPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), loaded, RollbackRestoreOptions{}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected restore without force to be refused, got %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), loaded, RollbackRestoreOptions{Force: true}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	b, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	want := "psql --dbname=postgres://app@db1/shop --set=ON_ERROR_STOP=1 --single-transaction --file=" +
+		filepath.Join(data.RollbackPath, filepath.FromSlash(d.Tables[1].DumpFile))
+	if !strings.Contains(string(b), want) {
+		t.Fatalf("expected %q in log, got: %q", want, string(b))
+	}
+}
+
+func TestRollbackDatabaseSkippedWithoutDumpTool(t *testing.T) {
+	project := t.TempDir()
+	t.Setenv("PATH", t.TempDir())
+
+	req := &db.Request{
+		ID:          "test-database-no-dump",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: `psql -d shop -c "DELETE FROM users"`, Cwd: project},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{})
+	if err != nil || data != nil {
+		t.Fatalf("expected no capture without pg_dump, got %+v, %v", data, err)
+	}
+}
+
+// setupSQLiteRollback creates a sqlite3 database in a new project directory
+// with users and carts tables.
+func setupSQLiteRollback(t *testing.T) (project, dbPath string) {
+	t.Helper()
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	project = t.TempDir()
+	dbPath = filepath.Join(project, "shop.db")
+	sqlite(t, dbPath, `CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+INSERT INTO users (name) VALUES ('ada'), ('grace');
+CREATE TABLE carts (id INTEGER PRIMARY KEY, user_id INTEGER);
+INSERT INTO carts (user_id) VALUES (1);`)
+	return project, dbPath
+}
+
+func sqlite(t *testing.T, dbPath, sql string) string {
+	t.Helper()
+	out, err := exec.Command("sqlite3", dbPath, sql).CombinedOutput()
+	if err != nil {
+		t.Fatalf("sqlite3 %q: %v\n%s", sql, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestRollbackDatabaseCaptureAndRestoreWithSQLite(t *testing.T) {
+	project, dbPath := setupSQLiteRollback(t)
+
+	req := &db.Request{
+		ID:          "test-database-sqlite",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: `sqlite3 shop.db "DELETE FROM users WHERE id = 1; DROP TABLE carts"`, Cwd: project},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if data == nil || data.Kind != rollbackKindDatabase || data.Database == nil || len(data.Database.Tables) != 2 {
+		t.Fatalf("expected two tables captured, got %+v", data)
+	}
+	if data.Database.Client != "sqlite3" || data.Database.Database != "shop.db" {
+		t.Errorf("unexpected database: %+v", data.Database)
+	}
+
+	// Run the command, then put the tables back.
+	sqlite(t, dbPath, "DELETE FROM users WHERE id = 1; DROP TABLE carts")
+	loaded, err := LoadRollbackData(data.RollbackPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), loaded, RollbackRestoreOptions{}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected restore without force to be refused, got %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), loaded, RollbackRestoreOptions{Force: true}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if got := sqlite(t, dbPath, "SELECT group_concat(name) FROM (SELECT name FROM users ORDER BY id)"); got != "ada,grace" {
+		t.Errorf("users after restore = %q, want ada,grace", got)
+	}
+	if got := sqlite(t, dbPath, "SELECT count(*) FROM carts"); got != "1" {
+		t.Errorf("carts after restore = %q rows, want 1", got)
+	}
+}
+
+func TestRollbackDatabaseOversizedDumpAborts(t *testing.T) {
+	project, dbPath := setupSQLiteRollback(t)
+	sqlite(t, dbPath, "INSERT INTO users (name) SELECT hex(randomblob(512)) FROM (WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 200) SELECT i FROM n)")
+
+	req := &db.Request{
+		ID:          "test-database-sqlite-large",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: `sqlite3 shop.db "DELETE FROM carts; DELETE FROM users"`, Cwd: project},
+	}
+	// The carts dump fits; users pushes the capture past the limit.
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{MaxSizeBytes: 16 * 1024})
+	if err == nil || !errors.Is(err, errSizeCapExceeded) {
+		t.Fatalf("expected the capture to exceed max size, got %+v, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(RollbackDir(project, req.ID), rollbackDatabaseDirName)); !os.IsNotExist(err) {
+		t.Errorf("expected partial dumps to be removed, stat err = %v", err)
+	}
+}
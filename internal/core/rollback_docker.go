@@ -0,0 +1,529 @@
+// Package core implements Docker rollback capture and restoration.
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// DockerRollbackData records what a docker rm, rmi or compose down removed.
+// Each spec file is the `docker inspect` JSON captured before execution,
+// relative to the rollback directory.
+type DockerRollbackData struct {
+	Containers []DockerContainerSpec `json:"containers,omitempty"`
+	Images     []DockerImageSpec     `json:"images,omitempty"`
+	Compose    *DockerComposeData    `json:"compose,omitempty"`
+}
+
+// DockerContainerSpec is a removed container.
+type DockerContainerSpec struct {
+	Name     string `json:"name"`
+	ID       string `json:"id"`
+	Image    string `json:"image"`
+	ImageID  string `json:"image_id,omitempty"`
+	SpecFile string `json:"spec_file"`
+}
+
+// DockerImageSpec is a removed image.
+type DockerImageSpec struct {
+	Ref         string   `json:"ref"`
+	ID          string   `json:"id"`
+	RepoTags    []string `json:"repo_tags,omitempty"`
+	RepoDigests []string `json:"repo_digests,omitempty"`
+	SpecFile    string   `json:"spec_file"`
+}
+
+// DockerComposeData is a compose project taken down.
+type DockerComposeData struct {
+	// Binary is "docker" (docker compose) or "docker-compose".
+	Binary      string `json:"binary"`
+	ProjectName string `json:"project_name,omitempty"`
+	ProjectDir  string `json:"project_dir"`
+	// Files are the compose file snapshots; OriginalFiles where they came from.
+	Files         []string `json:"files"`
+	OriginalFiles []string `json:"original_files"`
+	// Containers are the project's containers at capture time, for reference;
+	// restore brings the project back with compose up.
+	Containers []DockerContainerSpec `json:"containers,omitempty"`
+	// VolumesRemoved is set for down -v: restore re-creates the volumes
+	// empty, their data is not captured.
+	VolumesRemoved bool `json:"volumes_removed,omitempty"`
+}
+
+// dockerCommand is a parsed docker rm, rmi or compose down.
+type dockerCommand struct {
+	containers []string
+	images     []string
+	compose    *dockerComposeCommand
+}
+
+type dockerComposeCommand struct {
+	binary        string
+	files         []string
+	projectName   string
+	projectDir    string
+	removeVolumes bool
+}
+
+// defaultComposeFiles are the files compose looks for, in order.
+var defaultComposeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+
+// dockerGlobalValueFlags are docker CLI flags before the subcommand that
+// take a value.
+var dockerGlobalValueFlags = map[string]bool{
+	"-c": true, "--context": true, "-H": true, "--host": true,
+	"--config": true, "-l": true, "--log-level": true,
+}
+
+// parseDockerCommand recognizes the docker commands rollback can capture.
+// It returns nil for anything else.
+func parseDockerCommand(tokens []string) *dockerCommand {
+	if len(tokens) == 0 {
+		return nil
+	}
+	if filepath.Base(tokens[0]) == "docker-compose" {
+		return parseComposeDown("docker-compose", tokens[1:])
+	}
+	if filepath.Base(tokens[0]) != "docker" {
+		return nil
+	}
+
+	args := tokens[1:]
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if dockerGlobalValueFlags[args[0]] && len(args) > 1 {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	switch {
+	case args[0] == "rm":
+		return dockerTargets(args[1:], false)
+	case args[0] == "container" && len(args) > 1 && (args[1] == "rm" || args[1] == "remove"):
+		return dockerTargets(args[2:], false)
+	case args[0] == "rmi":
+		return dockerTargets(args[1:], true)
+	case args[0] == "image" && len(args) > 1 && (args[1] == "rm" || args[1] == "remove"):
+		return dockerTargets(args[2:], true)
+	case args[0] == "compose":
+		return parseComposeDown("docker", args[1:])
+	}
+	return nil
+}
+
+// dockerTargets collects the container or image names of docker rm/rmi; the
+// flags of both take no values.
+func dockerTargets(args []string, images bool) *dockerCommand {
+	var names []string
+	for _, a := range args {
+		if a == "--" || strings.HasPrefix(a, "-") {
+			continue
+		}
+		names = append(names, a)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	if images {
+		return &dockerCommand{images: names}
+	}
+	return &dockerCommand{containers: names}
+}
+
+// parseComposeDown parses compose global flags followed by down.
+func parseComposeDown(binary string, args []string) *dockerCommand {
+	c := &dockerComposeCommand{binary: binary}
+	down := false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		value := func() string {
+			if k, v, ok := strings.Cut(a, "="); ok && strings.HasPrefix(k, "--") {
+				return v
+			}
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+		switch {
+		case a == "down":
+			down = true
+		case a == "-f" || a == "--file" || strings.HasPrefix(a, "--file="):
+			c.files = append(c.files, value())
+		case a == "-p" || a == "--project-name" || strings.HasPrefix(a, "--project-name="):
+			c.projectName = value()
+		case a == "--project-directory" || strings.HasPrefix(a, "--project-directory="):
+			c.projectDir = value()
+		case a == "--env-file" || strings.HasPrefix(a, "--env-file=") || a == "--profile" || strings.HasPrefix(a, "--profile="):
+			value()
+		case down && (a == "-v" || a == "--volumes"):
+			c.removeVolumes = true
+		case down && (a == "--rmi" || a == "-t" || a == "--timeout"):
+			value()
+		case !down && !strings.HasPrefix(a, "-"):
+			// Another compose subcommand (up, ps, ...).
+			return nil
+		}
+	}
+	if !down {
+		return nil
+	}
+	return &dockerCommand{compose: c}
+}
+
+// dockerInspect is the part of `docker inspect` output restore uses.
+type dockerInspect struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Image  string `json:"Image"`
+	Config struct {
+		Image      string            `json:"Image"`
+		Env        []string          `json:"Env"`
+		Cmd        []string          `json:"Cmd"`
+		Entrypoint []string          `json:"Entrypoint"`
+		WorkingDir string            `json:"WorkingDir"`
+		User       string            `json:"User"`
+		Labels     map[string]string `json:"Labels"`
+		Tty        bool              `json:"Tty"`
+		OpenStdin  bool              `json:"OpenStdin"`
+	} `json:"Config"`
+	HostConfig struct {
+		Binds        []string `json:"Binds"`
+		PortBindings map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"PortBindings"`
+		RestartPolicy struct {
+			Name              string `json:"Name"`
+			MaximumRetryCount int    `json:"MaximumRetryCount"`
+		} `json:"RestartPolicy"`
+		NetworkMode string `json:"NetworkMode"`
+		Privileged  bool   `json:"Privileged"`
+	} `json:"HostConfig"`
+}
+
+// dockerImageInspect is the part of `docker image inspect` output recorded.
+type dockerImageInspect struct {
+	ID          string   `json:"Id"`
+	RepoTags    []string `json:"RepoTags"`
+	RepoDigests []string `json:"RepoDigests"`
+}
+
+func captureDockerRollback(ctx context.Context, rollbackDir string, req *db.Request, tokens []string) (*DockerRollbackData, error) {
+	parsed := parseDockerCommand(tokens)
+	if parsed == nil {
+		return nil, fmt.Errorf("unsupported docker command")
+	}
+	if _, err := exec.LookPath("docker"); err != nil && (parsed.compose == nil || parsed.compose.binary == "docker") {
+		return nil, fmt.Errorf("docker not found in PATH")
+	}
+
+	captureCtx, cancel := context.WithTimeout(ctx, defaultRollbackCmdTimeout)
+	defer cancel()
+
+	cwd := req.Command.Cwd
+	if strings.TrimSpace(cwd) == "" {
+		cwd = req.ProjectPath
+	}
+
+	outDir := filepath.Join(rollbackDir, rollbackDockerDirName)
+	if err := os.MkdirAll(outDir, 0700); err != nil {
+		return nil, fmt.Errorf("creating docker rollback dir: %w", err)
+	}
+
+	data := &DockerRollbackData{}
+	for _, name := range parsed.containers {
+		spec, err := inspectDockerContainer(captureCtx, cwd, outDir, name)
+		if err != nil {
+			return nil, err
+		}
+		data.Containers = append(data.Containers, *spec)
+	}
+	for _, ref := range parsed.images {
+		out, err := runCmdString(captureCtx, cwd, "docker", "image", "inspect", ref)
+		if err != nil {
+			return nil, fmt.Errorf("docker image inspect %s: %w", ref, err)
+		}
+		var inspected []dockerImageInspect
+		if err := json.Unmarshal([]byte(out), &inspected); err != nil || len(inspected) == 0 {
+			return nil, fmt.Errorf("parsing docker image inspect %s: unexpected output", ref)
+		}
+		filename := "image_" + sanitizeFilename(ref) + ".json"
+		if err := os.WriteFile(filepath.Join(outDir, filename), []byte(out), 0600); err != nil {
+			return nil, fmt.Errorf("writing image spec: %w", err)
+		}
+		data.Images = append(data.Images, DockerImageSpec{
+			Ref:         ref,
+			ID:          inspected[0].ID,
+			RepoTags:    inspected[0].RepoTags,
+			RepoDigests: inspected[0].RepoDigests,
+			SpecFile:    filepath.ToSlash(filepath.Join(rollbackDockerDirName, filename)),
+		})
+	}
+	if parsed.compose != nil {
+		compose, err := captureComposeProject(captureCtx, cwd, outDir, parsed.compose)
+		if err != nil {
+			return nil, err
+		}
+		data.Compose = compose
+	}
+	return data, nil
+}
+
+// inspectDockerContainer saves a container's inspect JSON under outDir.
+func inspectDockerContainer(ctx context.Context, cwd, outDir, name string) (*DockerContainerSpec, error) {
+	out, err := runCmdString(ctx, cwd, "docker", "inspect", "--type", "container", name)
+	if err != nil {
+		return nil, fmt.Errorf("docker inspect %s: %w", name, err)
+	}
+	var inspected []dockerInspect
+	if err := json.Unmarshal([]byte(out), &inspected); err != nil || len(inspected) == 0 {
+		return nil, fmt.Errorf("parsing docker inspect %s: unexpected output", name)
+	}
+	c := inspected[0]
+	if n := strings.TrimPrefix(c.Name, "/"); n != "" {
+		name = n
+	}
+	filename := "container_" + sanitizeFilename(name) + ".json"
+	if err := os.WriteFile(filepath.Join(outDir, filename), []byte(out), 0600); err != nil {
+		return nil, fmt.Errorf("writing container spec: %w", err)
+	}
+	return &DockerContainerSpec{
+		Name:     name,
+		ID:       c.ID,
+		Image:    c.Config.Image,
+		ImageID:  c.Image,
+		SpecFile: filepath.ToSlash(filepath.Join(rollbackDockerDirName, filename)),
+	}, nil
+}
+
+// captureComposeProject snapshots the compose files and inspects the
+// project's containers (best effort).
+func captureComposeProject(ctx context.Context, cwd, outDir string, c *dockerComposeCommand) (*DockerComposeData, error) {
+	projectDir := c.projectDir
+	if projectDir == "" {
+		projectDir = cwd
+	} else if !filepath.IsAbs(projectDir) {
+		projectDir = filepath.Join(cwd, projectDir)
+	}
+
+	files := c.files
+	if len(files) == 0 {
+		for _, name := range defaultComposeFiles {
+			if _, err := os.Stat(filepath.Join(projectDir, name)); err == nil {
+				files = []string{name}
+				break
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no compose file found in %s", projectDir)
+	}
+
+	composeDir := filepath.Join(outDir, "compose")
+	if err := os.MkdirAll(composeDir, 0700); err != nil {
+		return nil, fmt.Errorf("creating compose snapshot dir: %w", err)
+	}
+	data := &DockerComposeData{
+		Binary:         c.binary,
+		ProjectName:    c.projectName,
+		ProjectDir:     projectDir,
+		VolumesRemoved: c.removeVolumes,
+	}
+	for i, f := range files {
+		src := f
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(cwd, src)
+		}
+		content, err := os.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("reading compose file: %w", err)
+		}
+		// Numbered so same-named files from different directories don't collide.
+		name := fmt.Sprintf("%d_%s", i, sanitizeFilename(filepath.Base(src)))
+		if err := os.WriteFile(filepath.Join(composeDir, name), content, 0600); err != nil {
+			return nil, fmt.Errorf("writing compose snapshot: %w", err)
+		}
+		data.Files = append(data.Files, filepath.ToSlash(filepath.Join(rollbackDockerDirName, "compose", name)))
+		data.OriginalFiles = append(data.OriginalFiles, src)
+	}
+
+	ps, err := runCmdString(ctx, projectDir, data.Binary, append(composeArgs(data, nil), "ps", "-a", "-q")...)
+	if err == nil {
+		for _, id := range strings.Fields(ps) {
+			if spec, err := inspectDockerContainer(ctx, projectDir, outDir, id); err == nil {
+				data.Containers = append(data.Containers, *spec)
+			}
+		}
+	}
+	return data, nil
+}
+
+// composeArgs builds the compose command prefix for data's project, using
+// files (the originals when nil).
+func composeArgs(data *DockerComposeData, files []string) []string {
+	var args []string
+	if data.Binary != "docker-compose" {
+		args = append(args, "compose")
+	}
+	if files == nil {
+		files = data.OriginalFiles
+	}
+	for _, f := range files {
+		args = append(args, "-f", f)
+	}
+	if data.ProjectName != "" {
+		args = append(args, "-p", data.ProjectName)
+	}
+	return append(args, "--project-directory", data.ProjectDir)
+}
+
+func restoreDockerRollback(ctx context.Context, data *RollbackData, opts RollbackRestoreOptions) error {
+	if data.Docker == nil {
+		return fmt.Errorf("docker rollback data missing")
+	}
+	if !opts.Force {
+		return fmt.Errorf("docker rollback re-creates containers (use --force)")
+	}
+	d := data.Docker
+	binary := "docker"
+	if d.Compose != nil && len(d.Containers) == 0 && len(d.Images) == 0 {
+		binary = d.Compose.Binary
+	}
+	if _, err := exec.LookPath(binary); err != nil {
+		return fmt.Errorf("%s not found in PATH", binary)
+	}
+
+	restoreCtx, cancel := context.WithTimeout(ctx, 2*DefaultExecutionTimeout)
+	defer cancel()
+
+	cwd := data.CommandCwd
+	if strings.TrimSpace(cwd) == "" {
+		cwd = data.ProjectPath
+	}
+
+	// Removed images come back from their registry.
+	for _, img := range d.Images {
+		ref := img.Ref
+		if len(img.RepoTags) > 0 {
+			ref = img.RepoTags[0]
+		} else if len(img.RepoDigests) > 0 {
+			ref = img.RepoDigests[0]
+		}
+		if _, err := runCmdString(restoreCtx, cwd, "docker", "image", "inspect", ref); err == nil {
+			continue
+		}
+		if _, err := runCmdString(restoreCtx, cwd, "docker", "pull", ref); err != nil {
+			return fmt.Errorf("docker pull %s: %w", ref, err)
+		}
+	}
+
+	if c := d.Compose; c != nil {
+		files := make([]string, 0, len(c.Files))
+		for _, rel := range c.Files {
+			files = append(files, filepath.Join(data.RollbackPath, filepath.FromSlash(rel)))
+		}
+		args := append(composeArgs(c, files), "up", "-d")
+		if _, err := runCmdString(restoreCtx, c.ProjectDir, c.Binary, args...); err != nil {
+			return fmt.Errorf("%s up: %w", c.Binary, err)
+		}
+	}
+
+	for _, spec := range d.Containers {
+		b, err := os.ReadFile(filepath.Join(data.RollbackPath, filepath.FromSlash(spec.SpecFile)))
+		if err != nil {
+			return fmt.Errorf("reading container spec %s: %w", spec.Name, err)
+		}
+		var inspected []dockerInspect
+		if err := json.Unmarshal(b, &inspected); err != nil || len(inspected) == 0 {
+			return fmt.Errorf("parsing container spec %s: unexpected content", spec.Name)
+		}
+		args := dockerRunArgs(spec.Name, inspected[0])
+		if _, err := runCmdString(restoreCtx, cwd, "docker", args...); err != nil {
+			return fmt.Errorf("docker run %s: %w", spec.Name, err)
+		}
+	}
+	return nil
+}
+
+// dockerRunArgs rebuilds a `docker run` invocation from inspect output. It
+// covers the common settings (env, ports, binds, restart policy, network,
+// labels, entrypoint, command); anything else is lost.
+func dockerRunArgs(name string, c dockerInspect) []string {
+	args := []string{"run", "-d", "--name", name}
+	for _, e := range c.Config.Env {
+		args = append(args, "--env", e)
+	}
+	ports := make([]string, 0, len(c.HostConfig.PortBindings))
+	for port := range c.HostConfig.PortBindings {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		for _, b := range c.HostConfig.PortBindings[port] {
+			mapping := port
+			if b.HostPort != "" {
+				mapping = b.HostPort + ":" + port
+				if b.HostIP != "" {
+					mapping = b.HostIP + ":" + mapping
+				}
+			}
+			args = append(args, "--publish", mapping)
+		}
+	}
+	for _, bind := range c.HostConfig.Binds {
+		args = append(args, "--volume", bind)
+	}
+	if p := c.HostConfig.RestartPolicy; p.Name != "" && p.Name != "no" {
+		policy := p.Name
+		if p.Name == "on-failure" && p.MaximumRetryCount > 0 {
+			policy = fmt.Sprintf("on-failure:%d", p.MaximumRetryCount)
+		}
+		args = append(args, "--restart", policy)
+	}
+	if n := c.HostConfig.NetworkMode; n != "" && n != "default" {
+		args = append(args, "--network", n)
+	}
+	if c.HostConfig.Privileged {
+		args = append(args, "--privileged")
+	}
+	labels := make([]string, 0, len(c.Config.Labels))
+	for k, v := range c.Config.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	for _, l := range labels {
+		args = append(args, "--label", l)
+	}
+	if c.Config.WorkingDir != "" {
+		args = append(args, "--workdir", c.Config.WorkingDir)
+	}
+	if c.Config.User != "" {
+		args = append(args, "--user", c.Config.User)
+	}
+	if c.Config.Tty {
+		args = append(args, "--tty")
+	}
+	if c.Config.OpenStdin {
+		args = append(args, "--interactive")
+	}
+	cmd := c.Config.Cmd
+	if len(c.Config.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", c.Config.Entrypoint[0])
+		cmd = append(append([]string{}, c.Config.Entrypoint[1:]...), cmd...)
+	}
+	args = append(args, c.Config.Image)
+	return append(args, cmd...)
+}
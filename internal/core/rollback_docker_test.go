@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func TestParseDockerCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []string
+		want   *dockerCommand
+	}{
+		{"docker rm", []string{"docker", "rm", "-f", "web", "db"}, &dockerCommand{containers: []string{"web", "db"}}},
+		{"docker container rm", []string{"docker", "container", "rm", "web"}, &dockerCommand{containers: []string{"web"}}},
+		{"global flags", []string{"docker", "--context", "prod", "rm", "web"}, &dockerCommand{containers: []string{"web"}}},
+		{"docker rmi", []string{"docker", "rmi", "nginx:1.25"}, &dockerCommand{images: []string{"nginx:1.25"}}},
+		{"docker image rm", []string{"docker", "image", "rm", "-f", "nginx"}, &dockerCommand{images: []string{"nginx"}}},
+		{"compose down -v", []string{"docker", "compose", "-f", "stack.yml", "-p", "shop", "down", "-v"},
+			&dockerCommand{compose: &dockerComposeCommand{binary: "docker", files: []string{"stack.yml"}, projectName: "shop", removeVolumes: true}}},
+		{"docker-compose down", []string{"docker-compose", "--file=a.yml", "down", "--rmi", "all"},
+			&dockerCommand{compose: &dockerComposeCommand{binary: "docker-compose", files: []string{"a.yml"}}}},
+		{"compose up", []string{"docker", "compose", "up", "-d"}, nil},
+		{"rm without targets", []string{"docker", "rm", "-f"}, nil},
+		{"docker ps", []string{"docker", "ps"}, nil},
+		{"not docker", []string{"podman", "rm", "web"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseDockerCommand(tt.tokens)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDockerCommand(%v) = %+v, want %+v", tt.tokens, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerRunArgs(t *testing.T) {
+	var c dockerInspect
+	c.Config.Image = "nginx:1.25"
+	c.Config.Env = []string{"MODE=prod"}
+	c.Config.Entrypoint = []string{"/docker-entrypoint.sh", "--verbose"}
+	c.Config.Cmd = []string{"nginx", "-g", "daemon off;"}
+	c.HostConfig.Binds = []string{"/srv/www:/usr/share/nginx/html:ro"}
+	c.HostConfig.RestartPolicy.Name = "on-failure"
+	c.HostConfig.RestartPolicy.MaximumRetryCount = 3
+	c.HostConfig.NetworkMode = "default"
+	c.HostConfig.PortBindings = map[string][]struct {
+		HostIP   string `json:"HostIp"`
+		HostPort string `json:"HostPort"`
+	}{"80/tcp": {{HostPort: "8080"}}}
+
+	got := dockerRunArgs("web", c)
+	want := []string{
+		"run", "-d", "--name", "web",
+		"--env", "MODE=prod",
+		"--publish", "8080:80/tcp",
+		"--volume", "/srv/www:/usr/share/nginx/html:ro",
+		"--restart", "on-failure:3",
+		"--entrypoint", "/docker-entrypoint.sh",
+		"nginx:1.25", "--verbose", "nginx", "-g", "daemon off;",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dockerRunArgs() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// installFakeDocker puts a docker script on PATH that answers inspect and
+// compose ps, and logs every other invocation to the returned file.
+func installFakeDocker(t *testing.T, dir string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script docker not supported on windows")
+	}
+	binDir := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("mkdir bin: %v", err)
+	}
+	logPath := filepath.Join(dir, "docker.log")
+	t.Setenv("DOCKER_LOG", logPath)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	script := `#!/bin/sh
+set -eu
+case "$*" in
+  "inspect --type container "*)
+    name="$4"
+    echo "[{\"Id\": \"id-$name\", \"Name\": \"/$name\", \"Image\": \"sha256:img\", \"Config\": {\"Image\": \"nginx:1.25\", \"Env\": [\"MODE=prod\"], \"Cmd\": [\"nginx\"]}, \"HostConfig\": {\"RestartPolicy\": {\"Name\": \"always\"}}}]"
+    ;;
+  "image inspect "*)
+    if [ "${DOCKER_IMAGES_GONE:-}" = "1" ]; then exit 1; fi
+    echo "[{\"Id\": \"sha256:img\", \"RepoTags\": [\"$3\"]}]"
+    ;;
+  *" ps -a -q")
+    echo "shop-web-1"
+    ;;
+  *)
+    echo "$*" >> "$DOCKER_LOG"
+    ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(binDir, "docker"), []byte(script), 0755); err != nil {
+		t.Fatalf("write docker: %v", err)
+	}
+	return logPath
+}
+
+func TestRollbackDockerCaptureAndRestoreWithFakeDocker(t *testing.T) {
+	project := t.TempDir()
+	logPath := installFakeDocker(t, project)
+
+	req := &db.Request{
+		ID:          "test-docker",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "docker rm -f web", Cwd: project},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if data == nil || data.Kind != rollbackKindDocker || data.Docker == nil || len(data.Docker.Containers) != 1 {
+		t.Fatalf("expected one docker container captured, got %+v", data)
+	}
+	spec := data.Docker.Containers[0]
+	if spec.Name != "web" || spec.ID != "id-web" || spec.Image != "nginx:1.25" {
+		t.Errorf("unexpected container spec: %+v", spec)
+	}
+	if _, err := os.Stat(filepath.Join(data.RollbackPath, filepath.FromSlash(spec.SpecFile))); err != nil {
+		t.Errorf("expected inspect JSON to be stored: %v", err)
+	}
+
+	loaded, err := LoadRollbackData(data.RollbackPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), loaded, RollbackRestoreOptions{}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected restore without force to be refused, got %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), loaded, RollbackRestoreOptions{Force: true}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	b, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read docker log: %v", err)
+	}
+	if want := "run -d --name web --env MODE=prod --restart always nginx:1.25 nginx"; !strings.Contains(string(b), want) {
+		t.Fatalf("expected %q in docker log, got: %q", want, string(b))
+	}
+}
+
+func TestRollbackDockerImageRestorePullsMissingImages(t *testing.T) {
+	project := t.TempDir()
+	logPath := installFakeDocker(t, project)
+
+	req := &db.Request{
+		ID:          "test-docker-rmi",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "docker rmi nginx:1.25", Cwd: project},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if len(data.Docker.Images) != 1 || data.Docker.Images[0].ID != "sha256:img" {
+		t.Fatalf("expected the image to be recorded, got %+v", data.Docker)
+	}
+
+	t.Setenv("DOCKER_IMAGES_GONE", "1")
+	if err := RestoreRollbackState(context.Background(), data, RollbackRestoreOptions{Force: true}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	b, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(b), "pull nginx:1.25") {
+		t.Fatalf("expected the image to be pulled, got: %q", string(b))
+	}
+}
+
+func TestRollbackDockerComposeCaptureAndRestore(t *testing.T) {
+	project := t.TempDir()
+	logPath := installFakeDocker(t, project)
+	composeFile := filepath.Join(project, "compose.yaml")
+	if err := os.WriteFile(composeFile, []byte("services:\n  web:\n    image: nginx\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &db.Request{
+		ID:          "test-compose",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "docker compose -p shop down -v", Cwd: project},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	c := data.Docker.Compose
+	if c == nil || c.ProjectName != "shop" || !c.VolumesRemoved || len(c.Files) != 1 {
+		t.Fatalf("unexpected compose data: %+v", c)
+	}
+	if len(c.Containers) != 1 || c.Containers[0].Name != "shop-web-1" {
+		t.Errorf("expected the project's containers to be inspected, got %+v", c.Containers)
+	}
+
+	// Later edits to the compose file don't affect the restore.
+	if err := os.WriteFile(composeFile, []byte("services: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := os.ReadFile(filepath.Join(data.RollbackPath, filepath.FromSlash(c.Files[0])))
+	if err != nil || !strings.Contains(string(snapshot), "image: nginx") {
+		t.Fatalf("expected the compose file snapshot, got %q (%v)", snapshot, err)
+	}
+
+	if err := RestoreRollbackState(context.Background(), data, RollbackRestoreOptions{Force: true}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	b, _ := os.ReadFile(logPath)
+	want := "compose -f " + filepath.Join(data.RollbackPath, filepath.FromSlash(c.Files[0])) + " -p shop --project-directory " + project + " up -d"
+	if !strings.Contains(string(b), want) {
+		t.Fatalf("expected %q in docker log, got: %q", want, string(b))
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// rollbackExcludeRule is one compiled RollbackCaptureOptions.Exclude
+// pattern.
+type rollbackExcludeRule struct {
+	re      *regexp.Regexp
+	dirOnly bool
+	negate  bool
+}
+
+// rollbackExcludes decides which paths inside a filesystem capture root are
+// left out of the archive.
+type rollbackExcludes []rollbackExcludeRule
+
+// compileRollbackExcludes compiles gitignore-style patterns:
+//
+//   - blank lines and lines starting with '#' are ignored;
+//   - a pattern with no '/' other than a trailing one matches a name at any
+//     depth ("node_modules", "*.log");
+//   - any other pattern is anchored to the capture root ("/build",
+//     "web/.cache");
+//   - a trailing '/' matches directories only ("cache/");
+//   - '*' and '?' do not match '/', "**" does ("**/tmp", "logs/**");
+//   - a leading '!' re-includes what an earlier pattern excluded.
+//
+// As with gitignore, the last matching pattern wins, and nothing inside an
+// excluded directory can be re-included.
+func compileRollbackExcludes(patterns []string) (rollbackExcludes, error) {
+	var rules rollbackExcludes
+	for _, raw := range patterns {
+		p := strings.TrimSpace(raw)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		var rule rollbackExcludeRule
+		if strings.HasPrefix(p, "!") {
+			rule.negate = true
+			p = p[1:]
+		}
+		if strings.HasSuffix(p, "/") {
+			rule.dirOnly = true
+			p = strings.TrimRight(p, "/")
+		}
+		if p == "" {
+			return nil, fmt.Errorf("invalid rollback exclude pattern %q", raw)
+		}
+		re, err := regexp.Compile(rollbackExcludeRegexp(p))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rollback exclude pattern %q: %v", raw, err)
+		}
+		rule.re = re
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// excludes reports whether rel, a slash-separated path relative to a
+// capture root, is left out of the capture.
+func (r rollbackExcludes) excludes(rel string, isDir bool) bool {
+	excluded := false
+	for _, rule := range r {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(rel) {
+			excluded = !rule.negate
+		}
+	}
+	return excluded
+}
+
+// rollbackExcludeRegexp translates a gitignore-style pattern, without its
+// '!' and trailing '/', into a regexp over root-relative paths.
+func rollbackExcludeRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	if !strings.Contains(pattern, "/") {
+		b.WriteString("(?:.*/)?")
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case pattern[i:] == "**":
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package core
+
+import "testing"
+
+func TestRollbackExcludes(t *testing.T) {
+	ex, err := compileRollbackExcludes([]string{
+		"# caches",
+		"node_modules",
+		"*.log",
+		"/dist",
+		"web/.cache/",
+		"**/tmp",
+		"logs/**",
+		"!keep.log",
+	})
+	if err != nil {
+		t.Fatalf("compileRollbackExcludes: %v", err)
+	}
+
+	cases := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"node_modules", true, true},
+		{"pkg/node_modules", true, true},
+		{"node_modules_backup", true, false},
+		{"debug.log", false, true},
+		{"a/b/debug.log", false, true},
+		{"keep.log", false, false},
+		{"dist", true, true},
+		{"pkg/dist", true, false},
+		{"web/.cache", true, true},
+		{"web/.cache", false, false},
+		{"other/web/.cache", true, false},
+		{"tmp", true, true},
+		{"a/b/tmp", true, true},
+		{"logs/2024/app.txt", false, true},
+		{"logs", true, false},
+		{"src/main.go", false, false},
+	}
+	for _, tc := range cases {
+		if got := ex.excludes(tc.rel, tc.isDir); got != tc.want {
+			t.Errorf("excludes(%q, dir=%v) = %v, want %v", tc.rel, tc.isDir, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"/", "!", "[z-a]"} {
+		if _, err := compileRollbackExcludes([]string{bad}); err == nil {
+			t.Errorf("expected an error for pattern %q", bad)
+		}
+	}
+}
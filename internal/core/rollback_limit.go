@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultRollbackCaptureWait is how long a capture waits for a free slot
+// when RollbackCaptureOptions.Wait is 0.
+const defaultRollbackCaptureWait = 30 * time.Second
+
+// ErrRollbackCaptureBusy is returned by CaptureRollbackState when the
+// concurrent capture limit stays reached for the whole wait.
+var ErrRollbackCaptureBusy = errors.New("rollback capture limit reached")
+
+// rollbackCaptureLimiter is a semaphore over the rollback captures running
+// in this process, so a burst of destructive requests executed by one
+// daemon or watcher does not run every filesystem or git capture at once.
+type rollbackCaptureLimiter struct {
+	mu    sync.Mutex
+	limit int
+	slots chan struct{}
+}
+
+var rollbackCaptures rollbackCaptureLimiter
+
+// acquire takes a capture slot, waiting up to wait for one to free up.
+// A limit of 0 or less means no limit. The semaphore is resized when the
+// limit changes; captures holding a slot of the old size release it there.
+func (l *rollbackCaptureLimiter) acquire(ctx context.Context, limit int, wait time.Duration) (release func(), err error) {
+	if limit <= 0 {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	if l.slots == nil || l.limit != limit {
+		l.limit = limit
+		l.slots = make(chan struct{}, limit)
+	}
+	slots := l.slots
+	l.mu.Unlock()
+
+	release = func() { <-slots }
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: %d capture(s) already running after waiting %s", ErrRollbackCaptureBusy, limit, wait)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
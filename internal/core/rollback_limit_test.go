@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestRollbackCaptureLimiter_SerializesAtLimitOne(t *testing.T) {
+	var l rollbackCaptureLimiter
+	var active, maxActive int32
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.acquire(context.Background(), 1, 5*time.Second)
+			if err != nil {
+				t.Errorf("acquire: %v", err)
+				return
+			}
+			defer release()
+			n := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&maxActive)
+				if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+		}()
+	}
+	wg.Wait()
+	if maxActive != 1 {
+		t.Errorf("expected captures to run one at a time, saw %d at once", maxActive)
+	}
+}
+
+func TestRollbackCaptureLimiter_TimesOut(t *testing.T) {
+	var l rollbackCaptureLimiter
+	release, err := l.acquire(context.Background(), 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.acquire(context.Background(), 1, 10*time.Millisecond); !errors.Is(err, ErrRollbackCaptureBusy) {
+		t.Fatalf("expected ErrRollbackCaptureBusy, got %v", err)
+	}
+	release()
+	release, err = l.acquire(context.Background(), 1, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected a slot after release, got %v", err)
+	}
+	release()
+
+	if release, err := l.acquire(context.Background(), 0, 0); err != nil {
+		t.Fatalf("expected no limit at 0, got %v", err)
+	} else {
+		release()
+	}
+}
+
+func TestExecuteApprovedRequest_DefersWhenCaptureLimitReached(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses rm")
+	}
+	database := testutil.NewTestDB(t)
+	project := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(project, "build"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sess := testutil.MakeSession(t, database, testutil.WithProject(project))
+	cmdSpec := db.CommandSpec{Raw: "rm -rf build", Argv: []string{"rm", "-rf", "build"}, Cwd: project}
+	cmdSpec.Hash = db.ComputeCommandHash(cmdSpec)
+	expires := time.Now().Add(time.Hour)
+	req := &db.Request{
+		ProjectPath:        project,
+		RequestorSessionID: sess.ID,
+		RequestorAgent:     sess.AgentName,
+		RequestorModel:     sess.Model,
+		RiskTier:           db.RiskTierDangerous,
+		Command:            cmdSpec,
+		Status:             db.StatusApproved,
+		ApprovalExpiresAt:  &expires,
+	}
+	if err := database.CreateRequest(req); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another capture holds the only slot.
+	release, err := rollbackCaptures.acquire(context.Background(), 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := ExecuteOptions{
+		RequestID:             req.ID,
+		SessionID:             sess.ID,
+		LogDir:                filepath.Join(project, "logs"),
+		SuppressOutput:        true,
+		CaptureRollback:       true,
+		MaxConcurrentCaptures: 1,
+		RollbackCaptureWait:   10 * time.Millisecond,
+	}
+	exec := NewExecutor(database, nil)
+	if _, err := exec.ExecuteApprovedRequest(context.Background(), opts); !errors.Is(err, ErrRollbackCaptureBusy) {
+		release()
+		t.Fatalf("expected ErrRollbackCaptureBusy, got %v", err)
+	}
+	release()
+
+	deferred, _ := database.GetRequest(req.ID)
+	if deferred.Status != db.StatusApproved || deferred.Rollback == nil || !deferred.Rollback.Pending {
+		t.Fatalf("expected an approved request flagged rollback pending, got %s %+v", deferred.Status, deferred.Rollback)
+	}
+	if _, err := os.Stat(filepath.Join(project, "build")); err != nil {
+		t.Fatalf("expected the command not to run: %v", err)
+	}
+
+	if _, err := exec.ExecuteApprovedRequest(context.Background(), opts); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	done, _ := database.GetRequest(req.ID)
+	if done.Rollback == nil || done.Rollback.Pending || done.Rollback.Path == "" {
+		t.Errorf("expected a captured rollback with the flag cleared, got %+v", done.Rollback)
+	}
+}
@@ -0,0 +1,156 @@
+// Package core implements dry-run planning of rollback restores.
+package core
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RestoreAction is what a restore would do to one path.
+type RestoreAction string
+
+const (
+	// RestoreCreate means the path does not exist and would be created.
+	RestoreCreate RestoreAction = "create"
+	// RestoreOverwrite means an existing path would be replaced (--force).
+	RestoreOverwrite RestoreAction = "overwrite"
+	// RestoreSkip means the entry needs no change or is not restorable.
+	RestoreSkip RestoreAction = "skip"
+	// RestoreConflict means the path exists and the restore would fail
+	// without --force.
+	RestoreConflict RestoreAction = "conflict"
+	// RestoreUnsafe means the path fails the restore's safety checks (e.g.
+	// it is reached through a symlink) and the restore would fail.
+	RestoreUnsafe RestoreAction = "unsafe"
+)
+
+// RestorePlanEntry describes what a restore would do to one path.
+type RestorePlanEntry struct {
+	Path   string        `json:"path"`
+	Type   string        `json:"type"` // file, dir, symlink or other
+	Action RestoreAction `json:"action"`
+	Reason string        `json:"reason,omitempty"`
+}
+
+// RestorePlan lists, in archive order, what restoring a filesystem capture
+// would do to each path.
+type RestorePlan struct {
+	RequestID string             `json:"request_id"`
+	Kind      string             `json:"kind"`
+	Force     bool               `json:"force"`
+	Entries   []RestorePlanEntry `json:"entries"`
+}
+
+// Count returns the number of entries with action.
+func (p *RestorePlan) Count(action RestoreAction) int {
+	n := 0
+	for _, e := range p.Entries {
+		if e.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns why the planned restore would fail, or nil if it would not.
+func (p *RestorePlan) Err() error {
+	for _, e := range p.Entries {
+		switch e.Action {
+		case RestoreUnsafe, RestoreConflict:
+			return fmt.Errorf("%s: %s", e.Path, e.Reason)
+		}
+	}
+	return nil
+}
+
+// PlanRollbackRestore reports what RestoreRollbackState would do to each
+// path of a filesystem capture, without modifying the disk. The symlink
+// checks of a real restore are run, and paths that fail them are planned as
+// RestoreUnsafe rather than aborting the plan. opts.Force decides whether
+// existing paths are overwritten or conflicts.
+func PlanRollbackRestore(ctx context.Context, data *RollbackData, opts RollbackRestoreOptions) (*RestorePlan, error) {
+	if data == nil {
+		return nil, fmt.Errorf("rollback data is required")
+	}
+	if strings.TrimSpace(data.RollbackPath) == "" {
+		return nil, fmt.Errorf("rollback path is required")
+	}
+	if data.Kind != rollbackKindFilesystem {
+		return nil, fmt.Errorf("dry run is only supported for filesystem rollbacks, not %s", data.Kind)
+	}
+
+	plan := &RestorePlan{RequestID: data.RequestID, Kind: data.Kind, Force: opts.Force}
+	err := walkFilesystemRollback(data, func(e filesystemRollbackEntry, _ io.Reader) error {
+		if ctx != nil {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if !opts.includesEntry(e.hdr.Name) {
+			return nil
+		}
+		plan.Entries = append(plan.Entries, planFilesystemEntry(e, opts))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planFilesystemEntry mirrors restoreFilesystemEntry without writing.
+func planFilesystemEntry(e filesystemRollbackEntry, opts RollbackRestoreOptions) RestorePlanEntry {
+	entry := RestorePlanEntry{Path: e.target}
+
+	var check error
+	switch e.hdr.Typeflag {
+	case tar.TypeDir:
+		entry.Type = "dir"
+		check = ensureNoSymlinkParents(e.rootPath, e.target)
+	case tar.TypeSymlink, tar.TypeReg, tar.TypeRegA:
+		entry.Type = "file"
+		if e.hdr.Typeflag == tar.TypeSymlink {
+			entry.Type = "symlink"
+		}
+		if filepath.Clean(e.target) != filepath.Clean(e.rootPath) {
+			check = ensureNoSymlinkParents(e.rootPath, filepath.Dir(e.target))
+		}
+	default:
+		entry.Type = "other"
+		entry.Action = RestoreSkip
+		entry.Reason = "unsupported archive entry type"
+		return entry
+	}
+	if check != nil {
+		entry.Action = RestoreUnsafe
+		entry.Reason = check.Error()
+		return entry
+	}
+
+	info, err := os.Lstat(e.target)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		entry.Action = RestoreCreate
+	case err != nil:
+		entry.Action = RestoreUnsafe
+		entry.Reason = err.Error()
+	case entry.Type == "dir" && info.IsDir():
+		entry.Action = RestoreSkip
+		entry.Reason = "directory exists"
+	case entry.Type == "dir":
+		entry.Action = RestoreConflict
+		entry.Reason = "exists and is not a directory"
+	case opts.Force:
+		entry.Action = RestoreOverwrite
+	default:
+		entry.Action = RestoreConflict
+		entry.Reason = "path exists (use --force to overwrite)"
+	}
+	return entry
+}
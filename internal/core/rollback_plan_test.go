@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// captureBuildDir captures work/build (with the given files) for rm -rf build.
+func captureBuildDir(t *testing.T, id string, files map[string]string) (*RollbackData, string) {
+	t.Helper()
+	project := t.TempDir()
+	work := filepath.Join(project, "work")
+	buildDir := filepath.Join(work, "build")
+	for name, content := range files {
+		p := filepath.Join(buildDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	req := &db.Request{
+		ID:          id,
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "rm -rf build", Cwd: work},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{MaxSizeBytes: 10 << 20})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if data == nil || data.Filesystem == nil {
+		t.Fatalf("expected filesystem rollback data")
+	}
+	return data, buildDir
+}
+
+func planActions(plan *RestorePlan, root string) map[string]RestoreAction {
+	actions := make(map[string]RestoreAction, len(plan.Entries))
+	for _, e := range plan.Entries {
+		rel, _ := filepath.Rel(root, e.Path)
+		actions[filepath.ToSlash(rel)] = e.Action
+	}
+	return actions
+}
+
+func TestPlanRollbackRestore_DryRunWritesNothing(t *testing.T) {
+	data, buildDir := captureBuildDir(t, "test-plan", map[string]string{
+		"a.txt":     "original",
+		"b.txt":     "deleted later",
+		"sub/c.txt": "nested",
+	})
+
+	// Change the tree after the capture.
+	if err := os.WriteFile(filepath.Join(buildDir, "a.txt"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(buildDir, "b.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(buildDir, "sub")); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := PlanRollbackRestore(context.Background(), data, RollbackRestoreOptions{})
+	if err != nil {
+		t.Fatalf("PlanRollbackRestore: %v", err)
+	}
+	want := map[string]RestoreAction{
+		".":         RestoreSkip,
+		"a.txt":     RestoreConflict,
+		"b.txt":     RestoreCreate,
+		"sub":       RestoreCreate,
+		"sub/c.txt": RestoreCreate,
+	}
+	got := planActions(plan, buildDir)
+	for path, action := range want {
+		if got[path] != action {
+			t.Errorf("%s: action = %q, want %q (plan %+v)", path, got[path], action, plan.Entries)
+		}
+	}
+	if err := plan.Err(); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("Err() = %v, want the a.txt conflict", err)
+	}
+
+	forced, err := PlanRollbackRestore(context.Background(), data, RollbackRestoreOptions{Force: true})
+	if err != nil {
+		t.Fatalf("PlanRollbackRestore force: %v", err)
+	}
+	if got := planActions(forced, buildDir)["a.txt"]; got != RestoreOverwrite {
+		t.Errorf("a.txt with force: action = %q, want overwrite", got)
+	}
+	if forced.Err() != nil || forced.Count(RestoreCreate) != 3 {
+		t.Errorf("forced plan: err %v, %d creates", forced.Err(), forced.Count(RestoreCreate))
+	}
+
+	if err := RestoreRollbackState(context.Background(), data, RollbackRestoreOptions{Force: true, DryRun: true}); err != nil {
+		t.Fatalf("dry-run restore: %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), data, RollbackRestoreOptions{DryRun: true}); err == nil {
+		t.Error("expected a dry run without force to report the conflict")
+	}
+
+	if b, _ := os.ReadFile(filepath.Join(buildDir, "a.txt")); string(b) != "edited" {
+		t.Errorf("a.txt = %q, dry run must not overwrite it", b)
+	}
+	for _, name := range []string{"b.txt", "sub"} {
+		if _, err := os.Lstat(filepath.Join(buildDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s exists after a dry run (err %v)", name, err)
+		}
+	}
+}
+
+func TestPlanRollbackRestore_FlagsSymlinkParents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink tests are not reliable on windows")
+	}
+
+	data, buildDir := captureBuildDir(t, "test-plan-symlink", map[string]string{"sub/a.txt": "hello"})
+	subDir := filepath.Join(buildDir, "sub")
+	if err := os.RemoveAll(subDir); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(filepath.Dir(buildDir), "outside")
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, subDir); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+
+	plan, err := PlanRollbackRestore(context.Background(), data, RollbackRestoreOptions{Force: true})
+	if err != nil {
+		t.Fatalf("PlanRollbackRestore: %v", err)
+	}
+	got := planActions(plan, buildDir)
+	for _, path := range []string{"sub", "sub/a.txt"} {
+		if got[path] != RestoreUnsafe {
+			t.Errorf("%s: action = %q, want unsafe", path, got[path])
+		}
+	}
+	if err := plan.Err(); err == nil || !strings.Contains(err.Error(), "symlink") {
+		t.Errorf("Err() = %v, want the symlink refusal", err)
+	}
+	if err := RestoreRollbackState(context.Background(), data, RollbackRestoreOptions{Force: true, DryRun: true}); err == nil {
+		t.Error("expected the dry run to fail on the symlink parent")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "a.txt")); err == nil {
+		t.Fatal("dry run wrote through the symlink parent")
+	}
+}
+
+func TestPlanRollbackRestore_FilesystemOnly(t *testing.T) {
+	data := &RollbackData{Kind: rollbackKindGit, RollbackPath: t.TempDir()}
+	if _, err := PlanRollbackRestore(context.Background(), data, RollbackRestoreOptions{}); err == nil {
+		t.Error("expected an error for a git rollback")
+	}
+}
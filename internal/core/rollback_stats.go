@@ -0,0 +1,102 @@
+// Package core implements rollback usage stats.
+package core
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"os"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Restore failure categories.
+const (
+	// RestoreFailureSymlinkParent is a restore refused because a path's
+	// root or parent is a symlink.
+	RestoreFailureSymlinkParent = "symlink_parent"
+	// RestoreFailureChecksumMismatch is a capture archive that failed its
+	// integrity check.
+	RestoreFailureChecksumMismatch = "checksum_mismatch"
+	// RestoreFailureSourceGone is a capture whose files no longer exist.
+	RestoreFailureSourceGone = "source_gone"
+	// RestoreFailureOther is any other failure.
+	RestoreFailureOther = "other"
+)
+
+// ClassifyRestoreFailure returns the category of a restore error.
+func ClassifyRestoreFailure(err error) string {
+	switch {
+	case errors.Is(err, ErrRestoreThroughSymlink):
+		return RestoreFailureSymlinkParent
+	case errors.Is(err, gzip.ErrChecksum), errors.Is(err, tar.ErrHeader):
+		return RestoreFailureChecksumMismatch
+	case errors.Is(err, os.ErrNotExist):
+		return RestoreFailureSourceGone
+	default:
+		return RestoreFailureOther
+	}
+}
+
+// RecordRollbackRestore records a restore attempt of req's capture; err is
+// the attempt's error, nil on success.
+func RecordRollbackRestore(database *db.DB, req *db.Request, kind string, err error) error {
+	event := &db.RollbackEvent{
+		RequestID:   req.ID,
+		ProjectPath: req.ProjectPath,
+		Event:       db.RollbackEventRestore,
+		Kind:        kind,
+		Succeeded:   err == nil,
+	}
+	if err != nil {
+		event.FailureCategory = ClassifyRestoreFailure(err)
+		event.Error = err.Error()
+	}
+	return database.RecordRollbackEvent(event)
+}
+
+// RollbackStats summarizes rollback usage.
+type RollbackStats struct {
+	Captures         int            `json:"captures"`
+	CapturesByKind   map[string]int `json:"captures_by_kind"`
+	RestoreAttempts  int            `json:"restore_attempts"`
+	RestoreSuccesses int            `json:"restore_successes"`
+	RestoreFailures  int            `json:"restore_failures"`
+	// SuccessRate is the share of restore attempts that succeeded, from 0
+	// to 1; 0 when there were none.
+	SuccessRate float64 `json:"success_rate"`
+	// FailuresByCategory counts failed restores by ClassifyRestoreFailure
+	// category.
+	FailuresByCategory map[string]int `json:"failures_by_category"`
+}
+
+// SummarizeRollbackEvents aggregates recorded rollback events.
+func SummarizeRollbackEvents(events []*db.RollbackEvent) RollbackStats {
+	stats := RollbackStats{
+		CapturesByKind:     map[string]int{},
+		FailuresByCategory: map[string]int{},
+	}
+	for _, e := range events {
+		switch e.Event {
+		case db.RollbackEventCapture:
+			stats.Captures++
+			stats.CapturesByKind[e.Kind]++
+		case db.RollbackEventRestore:
+			stats.RestoreAttempts++
+			if e.Succeeded {
+				stats.RestoreSuccesses++
+				continue
+			}
+			stats.RestoreFailures++
+			category := e.FailureCategory
+			if category == "" {
+				category = RestoreFailureOther
+			}
+			stats.FailuresByCategory[category]++
+		}
+	}
+	if stats.RestoreAttempts > 0 {
+		stats.SuccessRate = float64(stats.RestoreSuccesses) / float64(stats.RestoreAttempts)
+	}
+	return stats
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func TestClassifyRestoreFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	root := t.TempDir()
+	if err := os.Symlink(t.TempDir(), filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	symlinkErr := ensureNoSymlinkParents(root, filepath.Join(root, "link", "file"))
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"symlink parent", symlinkErr, RestoreFailureSymlinkParent},
+		{"gzip checksum", fmt.Errorf("writing file x: %w", gzip.ErrChecksum), RestoreFailureChecksumMismatch},
+		{"tar header", fmt.Errorf("reading tar: %w", tar.ErrHeader), RestoreFailureChecksumMismatch},
+		{"capture deleted", fmt.Errorf("reading rollback metadata: %w", fs.ErrNotExist), RestoreFailureSourceGone},
+		{"other", errors.New("path exists: x (use --force to overwrite)"), RestoreFailureOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyRestoreFailure(tt.err); got != tt.want {
+				t.Errorf("ClassifyRestoreFailure(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeRollbackEvents(t *testing.T) {
+	capture := func(kind string) *db.RollbackEvent {
+		return &db.RollbackEvent{Event: db.RollbackEventCapture, Kind: kind, Succeeded: true}
+	}
+	restore := func(category string) *db.RollbackEvent {
+		return &db.RollbackEvent{Event: db.RollbackEventRestore, Kind: "filesystem", Succeeded: category == "", FailureCategory: category}
+	}
+	events := []*db.RollbackEvent{
+		capture("filesystem"), capture("filesystem"), capture("git"), capture("kubernetes"),
+		restore(""), restore(""), restore(""),
+		restore(RestoreFailureSymlinkParent),
+		restore(RestoreFailureSourceGone), restore(RestoreFailureSourceGone),
+		restore(RestoreFailureChecksumMismatch),
+		{Event: db.RollbackEventRestore}, // failed before a category was recorded
+	}
+
+	stats := SummarizeRollbackEvents(events)
+	if stats.Captures != 4 || stats.CapturesByKind["filesystem"] != 2 || stats.CapturesByKind["git"] != 1 ||
+		stats.CapturesByKind["kubernetes"] != 1 {
+		t.Errorf("captures = %d %v", stats.Captures, stats.CapturesByKind)
+	}
+	if stats.RestoreAttempts != 8 || stats.RestoreSuccesses != 3 || stats.RestoreFailures != 5 {
+		t.Errorf("restores = %d attempts, %d successes, %d failures; want 8, 3, 5",
+			stats.RestoreAttempts, stats.RestoreSuccesses, stats.RestoreFailures)
+	}
+	if stats.SuccessRate != 0.375 {
+		t.Errorf("success rate = %v, want 0.375", stats.SuccessRate)
+	}
+	want := map[string]int{
+		RestoreFailureSymlinkParent:    1,
+		RestoreFailureSourceGone:       2,
+		RestoreFailureChecksumMismatch: 1,
+		RestoreFailureOther:            1,
+	}
+	if len(stats.FailuresByCategory) != len(want) {
+		t.Errorf("failures by category = %v, want %v", stats.FailuresByCategory, want)
+	}
+	for category, n := range want {
+		if stats.FailuresByCategory[category] != n {
+			t.Errorf("failures[%s] = %d, want %d", category, stats.FailuresByCategory[category], n)
+		}
+	}
+
+	if empty := SummarizeRollbackEvents(nil); empty.SuccessRate != 0 || empty.RestoreAttempts != 0 {
+		t.Errorf("empty stats = %+v", empty)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package core
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// symlinkNotPermitted reports whether creating a symlink failed because the
+// filesystem does not support symlinks or refused one, as FAT and some
+// network mounts do.
+func symlinkNotPermitted(err error) bool {
+	return errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.ENOTSUP) || errors.Is(err, errors.ErrUnsupported)
+}
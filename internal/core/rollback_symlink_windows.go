@@ -0,0 +1,17 @@
+//go:build windows
+
+package core
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// symlinkNotPermitted reports whether creating a symlink failed because the
+// process may not create one: without Developer Mode or
+// SeCreateSymbolicLinkPrivilege, Windows refuses with
+// ERROR_PRIVILEGE_NOT_HELD.
+func symlinkNotPermitted(err error) bool {
+	return errors.Is(err, syscall.ERROR_PRIVILEGE_NOT_HELD) || errors.Is(err, os.ErrPermission)
+}
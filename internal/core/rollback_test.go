@@ -5,10 +5,13 @@ import (
 	"compress/gzip"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"math/rand"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -80,6 +83,289 @@ func TestRollbackFilesystemCaptureAndRestore(t *testing.T) {
 	}
 }
 
+func TestRollbackFilesystemCaptureAndRestore_TwoRoots(t *testing.T) {
+	project := t.TempDir()
+	work := filepath.Join(project, "work")
+	files := map[string]string{
+		filepath.Join(work, "build", "a.txt"):       "from build",
+		filepath.Join(work, "dist", "a.txt"):        "from dist",
+		filepath.Join(work, "dist", "sub", "b.txt"): "nested",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	req := &db.Request{
+		ID:          "test-req-two-roots",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "rm -rf build dist", Cwd: work},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{
+		MaxSizeBytes: 10 << 20,
+		RootPrefix:   "root",
+	})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if data == nil || data.Filesystem == nil {
+		t.Fatalf("expected filesystem rollback data")
+	}
+	if data.Filesystem.RootPrefix != "root" {
+		t.Errorf("RootPrefix = %q, want %q", data.Filesystem.RootPrefix, "root")
+	}
+
+	loaded, err := LoadRollbackData(data.RollbackPath)
+	if err != nil {
+		t.Fatalf("load rollback: %v", err)
+	}
+	roots, err := loaded.Filesystem.RootPaths()
+	if err != nil {
+		t.Fatalf("RootPaths: %v", err)
+	}
+	wantRoots := map[string]string{
+		"root0": filepath.Join(work, "build"),
+		"root1": filepath.Join(work, "dist"),
+	}
+	if len(roots) != len(wantRoots) {
+		t.Fatalf("roots = %v, want %v", roots, wantRoots)
+	}
+	for id, want := range wantRoots {
+		if roots[id] != want {
+			t.Errorf("root %s = %q, want %q", id, roots[id], want)
+		}
+	}
+
+	// The tar stores each directory under its own root ID.
+	f, err := os.Open(filepath.Join(data.RollbackPath, data.Filesystem.TarGz))
+	if err != nil {
+		t.Fatalf("open tar.gz: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	entries := make(map[string]bool)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read tar: %v", err)
+		}
+		entries[hdr.Name] = true
+	}
+	for _, name := range []string{"root0/a.txt", "root1/a.txt", "root1/sub/b.txt"} {
+		if !entries[name] {
+			t.Errorf("tar missing %s; entries: %v", name, entries)
+		}
+	}
+
+	for _, dir := range wantRoots {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatalf("remove %s: %v", dir, err)
+		}
+	}
+	if err := RestoreRollbackState(context.Background(), loaded, RollbackRestoreOptions{}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	for path, want := range files {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read restored %s: %v", path, err)
+		}
+		if string(got) != want {
+			t.Errorf("restored %s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRollbackFilesystemCapture_OverlappingTargets(t *testing.T) {
+	project := t.TempDir()
+	work := filepath.Join(project, "work")
+	files := map[string]string{
+		filepath.Join(work, "build", "out.bin"):        "build output",
+		filepath.Join(work, "build", "cache", "c.txt"): "cache",
+		filepath.Join(work, "dist", "out.bin"):         "dist output",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	keep := filepath.Join(work, "src", "main.go")
+	if err := os.MkdirAll(filepath.Dir(keep), 0755); err != nil {
+		t.Fatalf("mkdir src: %v", err)
+	}
+	if err := os.WriteFile(keep, []byte("package main"), 0644); err != nil {
+		t.Fatalf("write src: %v", err)
+	}
+
+	req := &db.Request{
+		ID:          "test-req-overlap",
+		ProjectPath: project,
+		// build/cache lies inside build, and ./build repeats build.
+		Command: db.CommandSpec{Raw: "rm -rf ./build/cache ./build dist build", Cwd: work},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{MaxSizeBytes: 10 << 20})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	fsData := data.Filesystem
+	if len(fsData.Roots) != 2 {
+		t.Fatalf("roots = %+v, want build and dist only", fsData.Roots)
+	}
+	wantRoots := []FilesystemRoot{
+		{ID: "p0", Path: filepath.Join(work, "build")},
+		{ID: "p1", Path: filepath.Join(work, "dist")},
+	}
+	for i, want := range wantRoots {
+		if fsData.Roots[i] != want {
+			t.Errorf("root %d = %+v, want %+v", i, fsData.Roots[i], want)
+		}
+	}
+	// Each byte is counted once even though build/cache was named twice.
+	var wantBytes int64
+	for _, content := range files {
+		wantBytes += int64(len(content))
+	}
+	if fsData.TotalBytes != wantBytes {
+		t.Errorf("TotalBytes = %d, want %d", fsData.TotalBytes, wantBytes)
+	}
+	note := fsData.Notes[filepath.Join(work, "build", "cache")]
+	if !strings.Contains(note, "root p0") {
+		t.Errorf("note for build/cache = %q, want it to name root p0", note)
+	}
+
+	for _, root := range wantRoots {
+		if err := os.RemoveAll(root.Path); err != nil {
+			t.Fatalf("remove %s: %v", root.Path, err)
+		}
+	}
+	loaded, err := LoadRollbackData(data.RollbackPath)
+	if err != nil {
+		t.Fatalf("load rollback: %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), loaded, RollbackRestoreOptions{}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	for path, want := range files {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read restored %s: %v", path, err)
+		}
+		if string(got) != want {
+			t.Errorf("restored %s = %q, want %q", path, got, want)
+		}
+	}
+	if got, err := os.ReadFile(keep); err != nil || string(got) != "package main" {
+		t.Errorf("untouched file = %q, %v", got, err)
+	}
+}
+
+func TestCollapseNestedPaths(t *testing.T) {
+	sep := string(filepath.Separator)
+	a := sep + filepath.Join("w", "a")
+	ab := filepath.Join(a, "b")
+	abc := filepath.Join(ab, "c")
+	ax := sep + filepath.Join("w", "ax")
+	d := sep + filepath.Join("w", "d")
+
+	kept, covered := collapseNestedPaths([]string{abc, ab, ax, a, d})
+	wantKept := []string{ax, a, d}
+	if len(kept) != len(wantKept) {
+		t.Fatalf("kept = %v, want %v", kept, wantKept)
+	}
+	for i := range wantKept {
+		if kept[i] != wantKept[i] {
+			t.Errorf("kept[%d] = %s, want %s", i, kept[i], wantKept[i])
+		}
+	}
+	// Nested paths point at the outermost kept ancestor; a sibling sharing
+	// a name prefix (ax) is not nested in a.
+	wantCovered := map[string]string{abc: a, ab: a}
+	if len(covered) != len(wantCovered) {
+		t.Fatalf("covered = %v, want %v", covered, wantCovered)
+	}
+	for p, anc := range wantCovered {
+		if covered[p] != anc {
+			t.Errorf("covered[%s] = %s, want %s", p, covered[p], anc)
+		}
+	}
+
+	if kept, covered := collapseNestedPaths([]string{a, d}); len(kept) != 2 || covered != nil {
+		t.Errorf("disjoint paths: kept=%v covered=%v", kept, covered)
+	}
+}
+
+func TestFilesystemRollbackData_RootPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		roots   []FilesystemRoot
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:  "maps each id",
+			roots: []FilesystemRoot{{ID: "p0", Path: "/a"}, {ID: "p1", Path: "/b"}},
+			want:  map[string]string{"p0": "/a", "p1": "/b"},
+		},
+		{
+			name:  "skips incomplete roots",
+			roots: []FilesystemRoot{{ID: "", Path: "/a"}, {ID: "p1", Path: ""}, {ID: "p2", Path: "/c"}},
+			want:  map[string]string{"p2": "/c"},
+		},
+		{
+			name:    "id mapped twice",
+			roots:   []FilesystemRoot{{ID: "p0", Path: "/a"}, {ID: "p0", Path: "/b"}},
+			wantErr: "maps to both",
+		},
+		{
+			name:    "relative path",
+			roots:   []FilesystemRoot{{ID: "p0", Path: "build"}},
+			wantErr: "relative path",
+		},
+		{
+			name:    "id with separator",
+			roots:   []FilesystemRoot{{ID: "../p0", Path: "/a"}},
+			wantErr: "invalid rollback root id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &FilesystemRollbackData{Roots: tt.roots}
+			got, err := data.RootPaths()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RootPaths() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RootPaths: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("RootPaths() = %v, want %v", got, tt.want)
+			}
+			for id, path := range tt.want {
+				if got[id] != path {
+					t.Errorf("RootPaths()[%s] = %q, want %q", id, got[id], path)
+				}
+			}
+		})
+	}
+}
+
 func TestRollbackFilesystemCaptureStoresSymlinks(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("symlink tests are not reliable on windows")
@@ -232,6 +518,63 @@ func TestRollbackFilesystemRestoreRefusesSymlinkParents(t *testing.T) {
 	}
 }
 
+func TestRollbackFilesystemRestoreSkipsRefusedSymlinks(t *testing.T) {
+	project := t.TempDir()
+	work := filepath.Join(project, "work")
+	buildDir := filepath.Join(work, "build")
+	if err := os.MkdirAll(filepath.Join(buildDir, "sub"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(buildDir, "sub", "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatalf("write a: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("sub", "a.txt"), filepath.Join(buildDir, "link.txt")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+
+	req := &db.Request{
+		ID:          "test-symlink-refused",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "rm -rf build", Cwd: work},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{MaxSizeBytes: 10 << 20})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+
+	origCreate := createSymlink
+	t.Cleanup(func() { createSymlink = origCreate })
+	createSymlink = func(oldname, newname string) error {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: os.ErrPermission}
+	}
+
+	if err := os.RemoveAll(buildDir); err != nil {
+		t.Fatalf("remove build: %v", err)
+	}
+	var skipped []SkippedSymlink
+	opts := RollbackRestoreOptions{OnSkippedSymlink: func(s SkippedSymlink) { skipped = append(skipped, s) }}
+	if err := RestoreRollbackState(context.Background(), data, opts); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(buildDir, "sub", "a.txt")); err != nil || string(b) != "hello" {
+		t.Errorf("expected sub/a.txt restored, got %q (%v)", b, err)
+	}
+	if len(skipped) != 1 || skipped[0].Path != filepath.Join(buildDir, "link.txt") || skipped[0].Target != filepath.Join("sub", "a.txt") {
+		t.Fatalf("expected link.txt to be reported as skipped, got %+v", skipped)
+	}
+
+	// Other failures still fail the restore.
+	createSymlink = func(oldname, newname string) error {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: errors.New("disk on fire")}
+	}
+	if err := os.RemoveAll(buildDir); err != nil {
+		t.Fatalf("remove build: %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), data, RollbackRestoreOptions{}); err == nil || !strings.Contains(err.Error(), "disk on fire") {
+		t.Fatalf("expected the symlink error, got %v", err)
+	}
+}
+
 func TestRollbackGitCaptureWritesMetadata(t *testing.T) {
 	if _, err := execLookPath("git"); err != nil {
 		t.Skip("git not available")
@@ -1208,6 +1551,9 @@ func TestDetectRollbackKind(t *testing.T) {
 		{"kubectl delete", []string{"kubectl", "delete", "deployment", "myapp"}, rollbackKindKubernetes},
 		{"kubectl only delete", []string{"kubectl", "delete"}, rollbackKindKubernetes},
 		{"kubectl without delete", []string{"kubectl", "get", "pods"}, ""},
+		{"docker rm", []string{"docker", "rm", "web"}, rollbackKindDocker},
+		{"docker compose down", []string{"docker", "compose", "down"}, rollbackKindDocker},
+		{"docker run", []string{"docker", "run", "nginx"}, ""},
 		{"git reset", []string{"git", "reset", "--hard", "HEAD"}, rollbackKindGit},
 		{"git checkout", []string{"git", "checkout", "--", "."}, rollbackKindGit},
 		{"git clean", []string{"git", "clean", "-fd"}, rollbackKindGit},
@@ -1327,6 +1673,22 @@ func TestCaptureRollbackState_Errors(t *testing.T) {
 		}
 	})
 
+	t.Run("invalid root prefix", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		req := &db.Request{
+			ID:          "test-bad-prefix",
+			ProjectPath: tmpDir,
+			Command: db.CommandSpec{
+				Raw: "rm -rf ./build",
+				Cwd: tmpDir,
+			},
+		}
+		_, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{RootPrefix: "../p"})
+		if err == nil || !strings.Contains(err.Error(), "invalid rollback root prefix") {
+			t.Errorf("expected invalid prefix error, got %v", err)
+		}
+	})
+
 	t.Run("unrecognized command returns nil data", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		req := &db.Request{
@@ -1411,7 +1773,7 @@ func TestWriteTarGz(t *testing.T) {
 
 		outPath := filepath.Join(tmpDir, "output.tar.gz")
 		roots := []FilesystemRoot{{ID: "p0", Path: sourceDir}}
-		if err := writeTarGz(outPath, roots); err != nil {
+		if err := writeTarGz(outPath, roots, nil, 0); err != nil {
 			t.Fatalf("writeTarGz: %v", err)
 		}
 
@@ -1430,7 +1792,7 @@ func TestWriteTarGz(t *testing.T) {
 
 		outPath := filepath.Join(tmpDir, "single.tar.gz")
 		roots := []FilesystemRoot{{ID: "p0", Path: sourceFile}}
-		if err := writeTarGz(outPath, roots); err != nil {
+		if err := writeTarGz(outPath, roots, nil, 0); err != nil {
 			t.Fatalf("writeTarGz: %v", err)
 		}
 	})
@@ -1439,7 +1801,7 @@ func TestWriteTarGz(t *testing.T) {
 		tmpDir := t.TempDir()
 		outPath := filepath.Join(tmpDir, "fail.tar.gz")
 		roots := []FilesystemRoot{{ID: "p0", Path: "/nonexistent/path"}}
-		err := writeTarGz(outPath, roots)
+		err := writeTarGz(outPath, roots, nil, 0)
 		if err == nil {
 			t.Error("expected error for nonexistent source")
 		}
@@ -1453,7 +1815,7 @@ func TestWriteTarGz(t *testing.T) {
 		}
 
 		roots := []FilesystemRoot{{ID: "p0", Path: sourceFile}}
-		err := writeTarGz("/nonexistent/dir/output.tar.gz", roots)
+		err := writeTarGz("/nonexistent/dir/output.tar.gz", roots, nil, 0)
 		if err == nil {
 			t.Error("expected error for invalid output path")
 		}
@@ -1655,7 +2017,7 @@ func TestSanitizeFilename(t *testing.T) {
 func TestCleanupOldRollbackCaptures(t *testing.T) {
 	t.Run("zero retention does nothing", func(t *testing.T) {
 		tmpDir := t.TempDir()
-		err := cleanupOldRollbackCaptures(tmpDir, 0, time.Now())
+		_, err := cleanupOldRollbackCaptures(tmpDir, 0, 0, time.Now())
 		if err != nil {
 			t.Errorf("cleanupOldRollbackCaptures error = %v", err)
 		}
@@ -1663,14 +2025,14 @@ func TestCleanupOldRollbackCaptures(t *testing.T) {
 
 	t.Run("negative retention does nothing", func(t *testing.T) {
 		tmpDir := t.TempDir()
-		err := cleanupOldRollbackCaptures(tmpDir, -1*time.Hour, time.Now())
+		_, err := cleanupOldRollbackCaptures(tmpDir, -1*time.Hour, 0, time.Now())
 		if err != nil {
 			t.Errorf("cleanupOldRollbackCaptures error = %v", err)
 		}
 	})
 
 	t.Run("nonexistent directory returns nil", func(t *testing.T) {
-		err := cleanupOldRollbackCaptures("/nonexistent/path/xyz", time.Hour, time.Now())
+		_, err := cleanupOldRollbackCaptures("/nonexistent/path/xyz", time.Hour, 0, time.Now())
 		if err != nil {
 			t.Errorf("expected nil error for nonexistent directory, got %v", err)
 		}
@@ -1690,7 +2052,7 @@ func TestCleanupOldRollbackCaptures(t *testing.T) {
 			t.Fatalf("chtimes: %v", err)
 		}
 
-		err := cleanupOldRollbackCaptures(tmpDir, time.Hour, time.Now())
+		_, err := cleanupOldRollbackCaptures(tmpDir, time.Hour, 0, time.Now())
 		if err != nil {
 			t.Errorf("cleanupOldRollbackCaptures error = %v", err)
 		}
@@ -1709,7 +2071,7 @@ func TestCleanupOldRollbackCaptures(t *testing.T) {
 			t.Fatalf("write file: %v", err)
 		}
 
-		err := cleanupOldRollbackCaptures(tmpDir, time.Hour, time.Now())
+		_, err := cleanupOldRollbackCaptures(tmpDir, time.Hour, 0, time.Now())
 		if err != nil {
 			t.Errorf("cleanupOldRollbackCaptures error = %v", err)
 		}
@@ -1734,7 +2096,7 @@ func TestCleanupOldRollbackCaptures(t *testing.T) {
 			t.Fatalf("chtimes: %v", err)
 		}
 
-		err := cleanupOldRollbackCaptures(tmpDir, time.Hour, time.Now())
+		_, err := cleanupOldRollbackCaptures(tmpDir, time.Hour, 0, time.Now())
 		if err != nil {
 			t.Errorf("cleanupOldRollbackCaptures error = %v", err)
 		}
@@ -1755,7 +2117,7 @@ func TestCleanupOldRollbackCaptures(t *testing.T) {
 
 		// Modification time is already recent (just created)
 
-		err := cleanupOldRollbackCaptures(tmpDir, time.Hour, time.Now())
+		_, err := cleanupOldRollbackCaptures(tmpDir, time.Hour, 0, time.Now())
 		if err != nil {
 			t.Errorf("cleanupOldRollbackCaptures error = %v", err)
 		}
@@ -1785,7 +2147,7 @@ func TestCleanupOldRollbackCaptures(t *testing.T) {
 			t.Fatalf("mkdir recent: %v", err)
 		}
 
-		err := cleanupOldRollbackCaptures(tmpDir, time.Hour, time.Now())
+		_, err := cleanupOldRollbackCaptures(tmpDir, time.Hour, 0, time.Now())
 		if err != nil {
 			t.Errorf("cleanupOldRollbackCaptures error = %v", err)
 		}
@@ -1800,4 +2162,404 @@ func TestCleanupOldRollbackCaptures(t *testing.T) {
 			t.Error("expected recent req- directory to not be deleted")
 		}
 	})
+
+	t.Run("keeps only the most recent captures", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		now := time.Now()
+		mtimes := map[string]time.Duration{
+			"req-expired": 2 * time.Hour,
+			"req-a":       30 * time.Minute,
+			"req-b":       20 * time.Minute,
+			"req-c":       10 * time.Minute,
+			"req-d":       5 * time.Minute,
+		}
+		for name, age := range mtimes {
+			dir := filepath.Join(tmpDir, name)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+			if err := os.Chtimes(dir, now.Add(-age), now.Add(-age)); err != nil {
+				t.Fatalf("chtimes: %v", err)
+			}
+		}
+
+		removed, err := cleanupOldRollbackCaptures(tmpDir, time.Hour, 2, now)
+		if err != nil {
+			t.Fatalf("cleanupOldRollbackCaptures error = %v", err)
+		}
+		if removed != 3 {
+			t.Errorf("removed = %d, want 3", removed)
+		}
+		for name := range mtimes {
+			_, err := os.Stat(filepath.Join(tmpDir, name))
+			kept := name == "req-c" || name == "req-d"
+			if kept && err != nil {
+				t.Errorf("expected %s to be kept: %v", name, err)
+			}
+			if !kept && !os.IsNotExist(err) {
+				t.Errorf("expected %s to be removed", name)
+			}
+		}
+	})
+
+	t.Run("count limit without retention breaks ties by name", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		same := time.Now().Add(-time.Minute)
+		for _, name := range []string{"req-z", "req-x", "req-y", "other"} {
+			dir := filepath.Join(tmpDir, name)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+			if err := os.Chtimes(dir, same, same); err != nil {
+				t.Fatalf("chtimes: %v", err)
+			}
+		}
+
+		removed, err := cleanupOldRollbackCaptures(tmpDir, 0, 2, time.Now())
+		if err != nil {
+			t.Fatalf("cleanupOldRollbackCaptures error = %v", err)
+		}
+		if removed != 1 {
+			t.Errorf("removed = %d, want 1", removed)
+		}
+		entries, _ := os.ReadDir(tmpDir)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		if want := []string{"other", "req-x", "req-y"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("remaining = %v, want %v", names, want)
+		}
+	})
+}
+
+func TestListRollbackCaptures(t *testing.T) {
+	t.Run("missing directory returns nothing", func(t *testing.T) {
+		captures, err := ListRollbackCaptures(t.TempDir())
+		if err != nil {
+			t.Fatalf("ListRollbackCaptures error = %v", err)
+		}
+		if len(captures) != 0 {
+			t.Fatalf("expected no captures, got %d", len(captures))
+		}
+	})
+
+	t.Run("lists captures newest first and skips unreadable ones", func(t *testing.T) {
+		project := t.TempDir()
+		base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		for i, id := range []string{"older", "newer"} {
+			dir := RollbackDir(project, id)
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+			data := &RollbackData{
+				Version:    rollbackDataVersion,
+				RequestID:  id,
+				CapturedAt: base.Add(time.Duration(i) * time.Hour),
+				Kind:       rollbackKindGit,
+			}
+			if err := writeRollbackMetadata(dir, data); err != nil {
+				t.Fatalf("write metadata: %v", err)
+			}
+		}
+		if err := os.MkdirAll(RollbackDir(project, "broken"), 0700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+
+		captures, err := ListRollbackCaptures(project)
+		if err != nil {
+			t.Fatalf("ListRollbackCaptures error = %v", err)
+		}
+		if len(captures) != 2 {
+			t.Fatalf("expected 2 captures, got %d", len(captures))
+		}
+		if captures[0].Data.RequestID != "newer" || captures[1].Data.RequestID != "older" {
+			t.Errorf("unexpected order: %s, %s", captures[0].Data.RequestID, captures[1].Data.RequestID)
+		}
+		if captures[0].SizeBytes <= 0 {
+			t.Errorf("expected positive size, got %d", captures[0].SizeBytes)
+		}
+		if captures[0].Data.RollbackPath != RollbackDir(project, "newer") {
+			t.Errorf("RollbackPath = %q", captures[0].Data.RollbackPath)
+		}
+	})
+}
+
+func TestRollbackFilesystemRestore_IncludePaths(t *testing.T) {
+	project := t.TempDir()
+	work := filepath.Join(project, "work")
+	files := map[string]string{
+		"build/sub/a.txt":      "a",
+		"build/sub/deep/b.txt": "b",
+		"build/sibling.txt":    "sibling",
+		"build/subway/c.txt":   "prefix lookalike",
+		"other.txt":            "other",
+	}
+	for name, content := range files {
+		p := filepath.Join(work, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	req := &db.Request{
+		ID:          "test-include",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "rm -rf work", Cwd: project},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{MaxSizeBytes: 10 << 20})
+	if err != nil || data == nil || data.Filesystem == nil {
+		t.Fatalf("capture: %+v, %v", data, err)
+	}
+	if err := os.RemoveAll(work); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := RollbackRestoreOptions{IncludePaths: []string{"p0/build/sub"}}
+	if err := RestoreRollbackState(context.Background(), data, opts); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	for _, name := range []string{"build/sub/a.txt", "build/sub/deep/b.txt"} {
+		got, err := os.ReadFile(filepath.Join(work, filepath.FromSlash(name)))
+		if err != nil || string(got) != files[name] {
+			t.Errorf("%s: got %q, %v", name, got, err)
+		}
+	}
+	for _, name := range []string{"build/sibling.txt", "build/subway", "other.txt"} {
+		if _, err := os.Lstat(filepath.Join(work, filepath.FromSlash(name))); !os.IsNotExist(err) {
+			t.Errorf("%s: expected it not to be restored, got %v", name, err)
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	// The symlink-parent check still covers the included entries.
+	if err := os.RemoveAll(work); err != nil {
+		t.Fatal(err)
+	}
+	elsewhere := t.TempDir()
+	if err := os.MkdirAll(work, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(elsewhere, filepath.Join(work, "build")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), data, opts); err == nil || !strings.Contains(err.Error(), "symlink") {
+		t.Fatalf("expected the symlink parent to be refused, got %v", err)
+	}
+	if entries, _ := os.ReadDir(elsewhere); len(entries) != 0 {
+		t.Errorf("restore wrote through the symlink: %v", entries)
+	}
+}
+
+// writeRollbackTree creates files (slash-separated names relative to dir).
+func writeRollbackTree(t testing.TB, dir string, files map[string][]byte) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, content, 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+}
+
+// randomRollbackBytes returns n bytes that gzip cannot shrink.
+func randomRollbackBytes(n int, seed int64) []byte {
+	b := make([]byte, n)
+	_, _ = rand.New(rand.NewSource(seed)).Read(b)
+	return b
+}
+
+func TestRollbackFilesystemCapture_Exclude(t *testing.T) {
+	project := t.TempDir()
+	work := filepath.Join(project, "work")
+	writeRollbackTree(t, work, map[string][]byte{
+		"build/a.txt":                   []byte("kept"),
+		"build/.cache/blob.bin":         []byte("cache"),
+		"build/node_modules/x/index.js": []byte("dep"),
+		"build/debug.log":               []byte("log"),
+		"dist/b.txt":                    []byte("also kept"),
+		"dist/node_modules/y.js":        []byte("dep"),
+	})
+	if runtime.GOOS != "windows" {
+		if err := os.Symlink("a.txt", filepath.Join(work, "build", "link.txt")); err != nil {
+			t.Skipf("symlink not supported: %v", err)
+		}
+	}
+
+	req := &db.Request{
+		ID:          "test-exclude",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "rm -rf build dist", Cwd: work},
+	}
+	exclude := []string{".cache/", "node_modules", "*.log"}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{
+		MaxSizeBytes: 10 << 20,
+		Exclude:      exclude,
+	})
+	if err != nil || data == nil || data.Filesystem == nil {
+		t.Fatalf("capture: %+v, %v", data, err)
+	}
+	if !reflect.DeepEqual(data.Filesystem.Exclude, exclude) {
+		t.Errorf("Exclude = %v, want %v", data.Filesystem.Exclude, exclude)
+	}
+	if want := int64(len("kept") + len("also kept")); data.Filesystem.TotalBytes != want {
+		t.Errorf("TotalBytes = %d, want %d", data.Filesystem.TotalBytes, want)
+	}
+
+	entries, err := ListRollbackContents(data)
+	if err != nil {
+		t.Fatalf("ListRollbackContents: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	want := []string{"p0/", "p0/a.txt", "p0/link.txt", "p1/", "p1/b.txt"}
+	if runtime.GOOS == "windows" {
+		want = []string{"p0/", "p0/a.txt", "p1/", "p1/b.txt"}
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("archive entries = %v, want %v", names, want)
+	}
+
+	if err := os.RemoveAll(work); err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreRollbackState(context.Background(), data, RollbackRestoreOptions{}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(work, "dist", "b.txt")); err != nil || string(got) != "also kept" {
+		t.Errorf("dist/b.txt: got %q, %v", got, err)
+	}
+	if runtime.GOOS != "windows" {
+		if target, err := os.Readlink(filepath.Join(work, "build", "link.txt")); err != nil || target != "a.txt" {
+			t.Errorf("expected the symlink restored as a symlink, got %q, %v", target, err)
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(work, "build", "node_modules")); !os.IsNotExist(err) {
+		t.Errorf("expected node_modules left out, got %v", err)
+	}
+
+	_, err = CaptureRollbackState(context.Background(), &db.Request{
+		ID:          "test-exclude-bad",
+		ProjectPath: project,
+		Command:     req.Command,
+	}, RollbackCaptureOptions{Exclude: []string{"[z-a]"}})
+	if err == nil || !strings.Contains(err.Error(), "exclude pattern") {
+		t.Errorf("expected an invalid pattern error, got %v", err)
+	}
+}
+
+func TestRollbackFilesystemCapture_RefusesOversizeBeforeWriting(t *testing.T) {
+	project := t.TempDir()
+	work := filepath.Join(project, "work")
+	writeRollbackTree(t, work, map[string][]byte{
+		"build/big.bin": randomRollbackBytes(64<<10, 1),
+	})
+
+	req := &db.Request{
+		ID:          "test-oversize",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "rm -rf build", Cwd: work},
+	}
+	_, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{MaxSizeBytes: 32 << 10})
+	if !errors.Is(err, errSizeCapExceeded) {
+		t.Fatalf("expected errSizeCapExceeded, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(RollbackDir(project, req.ID), rollbackFilesystemTarGz)); !os.IsNotExist(err) {
+		t.Errorf("expected no archive written, got %v", err)
+	}
+
+	// Excluding the large file brings the capture under the limit.
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{
+		MaxSizeBytes: 32 << 10,
+		Exclude:      []string{"*.bin"},
+	})
+	if err != nil || data == nil || data.Filesystem == nil || data.Filesystem.TotalBytes != 0 {
+		t.Errorf("expected an empty capture with the file excluded, got %+v, %v", data, err)
+	}
+}
+
+func TestWriteTarGz_StopsAtCompressedLimit(t *testing.T) {
+	for _, n := range []int{1, 3} {
+		dir := t.TempDir()
+		var roots []FilesystemRoot
+		for i := 0; i < n; i++ {
+			root := filepath.Join(dir, "src", fmt.Sprintf("r%d", i))
+			writeRollbackTree(t, root, map[string][]byte{"data.bin": randomRollbackBytes(256<<10, int64(i))})
+			roots = append(roots, FilesystemRoot{ID: fmt.Sprintf("p%d", i), Path: root})
+		}
+
+		outPath := filepath.Join(dir, "files.tar.gz")
+		err := writeTarGz(outPath, roots, nil, 64<<10)
+		if !errors.Is(err, errSizeCapExceeded) {
+			t.Fatalf("%d root(s): expected errSizeCapExceeded, got %v", n, err)
+		}
+		if left, _ := filepath.Glob(outPath + "*"); len(left) != 0 {
+			t.Errorf("%d root(s): expected partial files removed, found %v", n, left)
+		}
+	}
+}
+
+// benchmarkRollbackCapture captures four 4 MiB directories per iteration.
+func benchmarkRollbackCapture(b *testing.B, opts RollbackCaptureOptions) {
+	project := b.TempDir()
+	work := filepath.Join(project, "work")
+	for i, dir := range []string{"a", "b", "c", "d"} {
+		files := make(map[string][]byte)
+		for j := 0; j < 16; j++ {
+			files[fmt.Sprintf("%s/f%d.bin", dir, j)] = randomRollbackBytes(128<<10, int64(i*16+j))
+			files[fmt.Sprintf("%s/cache/f%d.bin", dir, j)] = randomRollbackBytes(128<<10, int64(i*16+j))
+		}
+		writeRollbackTree(b, work, files)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := &db.Request{
+			ID:          fmt.Sprintf("bench-%d", i),
+			ProjectPath: project,
+			Command:     db.CommandSpec{Raw: "rm -rf a b c d", Cwd: work},
+		}
+		if _, err := CaptureRollbackState(context.Background(), req, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCaptureRollbackState_Filesystem compares compressing the roots
+// one at a time (GOMAXPROCS=1) with compressing them in parallel.
+func BenchmarkCaptureRollbackState_Filesystem(b *testing.B) {
+	procs := []int{1}
+	if n := runtime.NumCPU(); n > 1 {
+		procs = append(procs, n)
+	}
+	for _, n := range procs {
+		b.Run(fmt.Sprintf("procs=%d", n), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(n))
+			benchmarkRollbackCapture(b, RollbackCaptureOptions{})
+		})
+	}
+	b.Run("exclude=cache", func(b *testing.B) {
+		benchmarkRollbackCapture(b, RollbackCaptureOptions{Exclude: []string{"cache/"}})
+	})
+	b.Run("over_limit", func(b *testing.B) {
+		project := b.TempDir()
+		writeRollbackTree(b, project, map[string][]byte{"a/big.bin": randomRollbackBytes(4<<20, 1)})
+		req := &db.Request{ID: "bench-over", ProjectPath: project, Command: db.CommandSpec{Raw: "rm -rf a", Cwd: project}}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{MaxSizeBytes: 1 << 20}); !errors.Is(err, errSizeCapExceeded) {
+				b.Fatal(err)
+			}
+		}
+	})
 }
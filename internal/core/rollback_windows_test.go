@@ -0,0 +1,172 @@
+//go:build windows
+
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func TestRollbackFilesystemCaptureAndRestore_Windows(t *testing.T) {
+	project := t.TempDir()
+	work := filepath.Join(project, "work")
+	buildDir := filepath.Join(work, "build")
+	if err := os.MkdirAll(filepath.Join(buildDir, "sub", "deep"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join("sub", "a.txt"):         "alpha",
+		filepath.Join("sub", "deep", "b.txt"): "beta",
+		"top.txt":                             "top",
+	}
+	for rel, content := range files {
+		if err := os.WriteFile(filepath.Join(buildDir, rel), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	// Creating a symlink needs Developer Mode or an elevated shell; without
+	// either the restore below exercises the skipped-symlink path instead.
+	hasLink := os.Symlink(filepath.Join("sub", "a.txt"), filepath.Join(buildDir, "link.txt")) == nil
+
+	req := &db.Request{
+		ID:          "test-windows-fs",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: `rm -rf build`, Cwd: work},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{MaxSizeBytes: 10 << 20})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+
+	entries, err := ListRollbackContents(data)
+	if err != nil {
+		t.Fatalf("ListRollbackContents: %v", err)
+	}
+	names := make(map[string]RollbackEntry)
+	for _, e := range entries {
+		if strings.Contains(e.Name, `\`) {
+			t.Errorf("archive entry %q uses a backslash", e.Name)
+		}
+		names[e.Name] = e
+	}
+	if _, ok := names["p0/sub/deep/b.txt"]; !ok {
+		t.Fatalf("expected p0/sub/deep/b.txt in the archive, got %v", entries)
+	}
+	if hasLink && names["p0/link.txt"].LinkTarget != "sub/a.txt" {
+		t.Errorf("expected the link target stored as sub/a.txt, got %q", names["p0/link.txt"].LinkTarget)
+	}
+
+	if err := os.RemoveAll(buildDir); err != nil {
+		t.Fatalf("remove build: %v", err)
+	}
+	loaded, err := LoadRollbackData(data.RollbackPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	var skipped []SkippedSymlink
+	opts := RollbackRestoreOptions{OnSkippedSymlink: func(s SkippedSymlink) { skipped = append(skipped, s) }}
+	if err := RestoreRollbackState(context.Background(), loaded, opts); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	for rel, want := range files {
+		b, err := os.ReadFile(filepath.Join(buildDir, rel))
+		if err != nil || string(b) != want {
+			t.Errorf("%s: got %q (%v), want %q", rel, b, err, want)
+		}
+	}
+	if hasLink && len(skipped) == 0 {
+		target, err := os.Readlink(filepath.Join(buildDir, "link.txt"))
+		if err != nil || target != filepath.Join("sub", "a.txt") {
+			t.Errorf("expected link.txt -> sub\\a.txt, got %q (%v)", target, err)
+		}
+	}
+}
+
+func TestKubectlBinary_PrefersExe(t *testing.T) {
+	shimDir := t.TempDir()
+	exeDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(shimDir, "kubectl.cmd"), []byte("@echo off\r\n"), 0755); err != nil {
+		t.Fatalf("write kubectl.cmd: %v", err)
+	}
+	exe := filepath.Join(exeDir, "kubectl.exe")
+	if err := os.WriteFile(exe, nil, 0755); err != nil {
+		t.Fatalf("write kubectl.exe: %v", err)
+	}
+	t.Setenv("PATH", shimDir+string(os.PathListSeparator)+exeDir)
+
+	got, err := kubectlBinary()
+	if err != nil {
+		t.Fatalf("kubectlBinary: %v", err)
+	}
+	if !strings.EqualFold(got, exe) {
+		t.Errorf("kubectlBinary() = %s, want %s", got, exe)
+	}
+}
+
+func TestRollbackKubernetesCaptureAndRestore_Windows(t *testing.T) {
+	project := t.TempDir()
+	work := filepath.Join(project, "work")
+	binDir := filepath.Join(project, "bin")
+	for _, dir := range []string{work, binDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+	logPath := filepath.Join(project, "kubectl.log")
+	t.Setenv("KUBECTL_LOG", logPath)
+	// Only the fake and cmd.exe: a real kubectl.exe elsewhere in PATH
+	// would be preferred over the fake.
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+filepath.Join(os.Getenv("SystemRoot"), "System32"))
+
+	script := strings.Join([]string{
+		"@echo off",
+		`if "%1"=="get" (`,
+		"  echo kind: %2",
+		"  echo metadata:",
+		"  echo   name: %3",
+		"  exit /b 0",
+		")",
+		`if "%1"=="apply" echo apply %*>>"%KUBECTL_LOG%"`,
+		"",
+	}, "\r\n")
+	if err := os.WriteFile(filepath.Join(binDir, "kubectl.cmd"), []byte(script), 0755); err != nil {
+		t.Fatalf("write kubectl.cmd: %v", err)
+	}
+
+	req := &db.Request{
+		ID:          "test-windows-k8s",
+		ProjectPath: project,
+		Command:     db.CommandSpec{Raw: "kubectl delete deployment myapp", Cwd: work},
+	}
+	data, err := CaptureRollbackState(context.Background(), req, RollbackCaptureOptions{})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if data == nil || data.Kubernetes == nil || len(data.Kubernetes.Manifests) != 1 {
+		t.Fatalf("expected one kubernetes manifest, got %+v", data)
+	}
+	manifest, err := os.ReadFile(filepath.Join(data.RollbackPath, filepath.FromSlash(data.Kubernetes.Manifests[0])))
+	if err != nil || !strings.Contains(string(manifest), "name: myapp") {
+		t.Fatalf("unexpected manifest %q (%v)", manifest, err)
+	}
+
+	loaded, err := LoadRollbackData(data.RollbackPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := RestoreRollbackState(context.Background(), loaded, RollbackRestoreOptions{}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	b, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read kubectl log: %v", err)
+	}
+	if !strings.Contains(string(b), "apply -f") {
+		t.Fatalf("expected kubectl apply to be invoked, got: %q", b)
+	}
+}
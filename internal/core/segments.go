@@ -0,0 +1,266 @@
+// Package core implements partial approval and segmented execution of compound commands.
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// CommandSegments splits a compound command on ;, &&, || and & into the
+// segments that partial approval and segmented execution operate on. Unlike
+// NormalizeCommand, pipelines are kept whole and wrappers are not stripped,
+// so each segment can be run exactly as written.
+func CommandSegments(cmd string) []string {
+	return splitCompoundShellAware(cmd)
+}
+
+// SegmentOperators returns, for each segment of CommandSegments, the operator
+// that followed it in the command ("&&", "||", ";", "&", or "" at the end).
+func SegmentOperators(cmd string) []string {
+	_, ops := splitCompoundWithOps(cmd)
+	return ops
+}
+
+// NormalizeSegmentSelection validates a 1-based segment selection against a
+// command with segmentCount segments and returns it sorted and de-duplicated.
+// A selection covering every segment is returned as nil: it is a full decision.
+func NormalizeSegmentSelection(segments []int, segmentCount int) ([]int, error) {
+	if len(segments) == 0 {
+		return nil, nil
+	}
+	if segmentCount < 2 {
+		return nil, fmt.Errorf("%w: command is not compound", ErrInvalidSegments)
+	}
+	seen := make(map[int]bool, len(segments))
+	out := make([]int, 0, len(segments))
+	for _, seg := range segments {
+		if seg < 1 || seg > segmentCount {
+			return nil, fmt.Errorf("%w: segment %d out of range 1-%d", ErrInvalidSegments, seg, segmentCount)
+		}
+		if !seen[seg] {
+			seen[seg] = true
+			out = append(out, seg)
+		}
+	}
+	sort.Ints(out)
+	if len(out) == segmentCount {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// SegmentDecision returns a review's decision for one 1-based segment. A
+// review without a segment selection applies its decision to every segment;
+// one with a selection applies the opposite decision to unselected segments.
+func SegmentDecision(r *db.Review, segment int) db.Decision {
+	if len(r.Segments) == 0 {
+		return r.Decision
+	}
+	for _, seg := range r.Segments {
+		if seg == segment {
+			return r.Decision
+		}
+	}
+	if r.Decision == db.DecisionApprove {
+		return db.DecisionReject
+	}
+	return db.DecisionApprove
+}
+
+// clearedSegments returns the segments a single segment-level review clears
+// for execution: the selection of an approval, or the rest of a rejection.
+func clearedSegments(decision db.Decision, selection []int, segmentCount int) []int {
+	var cleared []int
+	r := &db.Review{Decision: decision, Segments: selection}
+	for idx := 1; idx <= segmentCount; idx++ {
+		if SegmentDecision(r, idx) == db.DecisionApprove {
+			cleared = append(cleared, idx)
+		}
+	}
+	return cleared
+}
+
+// hasSegmentReviews reports whether any review decided on individual segments.
+func hasSegmentReviews(reviews []*db.Review) bool {
+	for _, r := range reviews {
+		if len(r.Segments) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentRunPlan returns the approved segments to execute. By default only
+// the contiguously approved prefix (1, 2, ...) runs, so no segment runs
+// without everything before it; runAll runs every approved segment.
+func segmentRunPlan(approved []int, runAll bool) []int {
+	if runAll {
+		return approved
+	}
+	var plan []int
+	for i, seg := range approved {
+		if seg != i+1 {
+			break
+		}
+		plan = append(plan, seg)
+	}
+	return plan
+}
+
+// shellStateCommands are builtins whose effect persists in the shell that
+// runs them, so skipping one changes what the segments after it do.
+var shellStateCommands = map[string]bool{
+	"cd": true, "pushd": true, "popd": true, "export": true, "unset": true,
+	"set": true, "source": true, ".": true, "alias": true, "unalias": true,
+	"umask": true, "shopt": true, "exec": true, "trap": true, "readonly": true,
+	"declare": true, "typeset": true, "local": true, "ulimit": true,
+}
+
+// changesShellState reports whether a segment alters state later segments
+// inherit: a state builtin such as cd or export, or a bare VAR=value assignment.
+func changesShellState(segment string) bool {
+	fields := strings.Fields(segment)
+	if len(fields) == 0 {
+		return false
+	}
+	if shellStateCommands[fields[0]] {
+		return true
+	}
+	for _, f := range fields {
+		if !envAssignPattern.MatchString(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkSegmentSkips refuses a run plan that skips a state-changing segment
+// ahead of a segment that does run: `cd /srv/app && rm -rf *` with only the
+// rm approved would otherwise delete from the request's cwd.
+func checkSegmentSkips(segments []string, plan []int) error {
+	if len(plan) == 0 {
+		return nil
+	}
+	planned := make(map[int]bool, len(plan))
+	for _, idx := range plan {
+		planned[idx] = true
+	}
+	last := plan[len(plan)-1]
+	for idx := 1; idx < last; idx++ {
+		if !planned[idx] && changesShellState(segments[idx-1]) {
+			return fmt.Errorf("%w: segment %d (%s) changes shell state for later segments",
+				ErrUnsafeSegmentSkip, idx, segments[idx-1])
+		}
+	}
+	return nil
+}
+
+// buildSegmentScript rebuilds the command as a single script that keeps the
+// original &&, ||, ; and & operators. Segments outside the plan become the
+// no-op `:`, so control flow between the planned segments is what the
+// approver saw. Each planned segment reports its exit status on fd 9, and a
+// final "end" line marks a script that was not cut short by exit.
+func buildSegmentScript(segments, ops []string, plan []int, statusPath string) string {
+	planned := make(map[int]bool, len(plan))
+	for _, idx := range plan {
+		planned[idx] = true
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "exec 9>%s\n", shellQuote(statusPath))
+	for i, seg := range segments {
+		idx := i + 1
+		if planned[idx] {
+			fmt.Fprintf(&b, "{ { %s\n}; __slb_rc=$?; echo \"%d $__slb_rc\" >&9; (exit $__slb_rc); }", seg, idx)
+		} else {
+			b.WriteString(":")
+		}
+		if ops[i] != "" {
+			b.WriteString(" " + ops[i] + " ")
+		}
+	}
+	b.WriteString("\n__slb_rc=$?; echo end >&9; exit $__slb_rc\n")
+	return b.String()
+}
+
+// parseSegmentStatus reads the status lines written by a segment script.
+func parseSegmentStatus(data string) (codes map[int]int, completed bool) {
+	codes = make(map[int]int)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "end" {
+			completed = true
+			continue
+		}
+		var idx, code int
+		if _, err := fmt.Sscanf(line, "%d %d", &idx, &code); err == nil {
+			codes[idx] = code
+		}
+	}
+	return codes, completed
+}
+
+// runSegments runs the planned segments of a compound command as one script
+// in a single shell, so cd, exported variables and the original operators
+// carry over exactly as in the full command. Planned segments that did not
+// run (short-circuited, or after an exit) are recorded as skipped; a segment
+// that exited the shell is given the script's exit code.
+func runSegments(ctx context.Context, pin *contextPin, raw string, plan []int, logPath string, stream io.Writer) ([]db.SegmentExecution, *CommandResult, error) {
+	segments, ops := splitCompoundWithOps(raw)
+
+	statusFile, err := os.CreateTemp("", "slb-segments-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating segment status file: %w", err)
+	}
+	statusPath := statusFile.Name()
+	_ = statusFile.Close()
+	defer os.Remove(statusPath)
+
+	spec := pin.Spec
+	spec.Raw = buildSegmentScript(segments, ops, plan, statusPath)
+	spec.Argv = nil
+	spec.Shell = true
+
+	res, runErr := runCommand(ctx, &spec, logPath, stream, pin.Env)
+
+	data, _ := os.ReadFile(statusPath)
+	codes, completed := parseSegmentStatus(string(data))
+
+	results := make([]db.SegmentExecution, 0, len(plan))
+	exited := false
+	for _, idx := range plan {
+		segExec := db.SegmentExecution{Index: idx, Command: segments[idx-1]}
+		if code, ok := codes[idx]; ok {
+			segExec.ExitCode = &code
+		} else if !completed && !exited && runErr == nil && res != nil && !laterSegmentRan(codes, plan, idx) {
+			// The shell exited inside this segment.
+			code := res.ExitCode
+			segExec.ExitCode = &code
+			exited = true
+		} else {
+			segExec.Skipped = true
+		}
+		results = append(results, segExec)
+	}
+	if runErr != nil {
+		return results, nil, runErr
+	}
+	return results, res, nil
+}
+
+// laterSegmentRan reports whether any planned segment after idx reported a status.
+func laterSegmentRan(codes map[int]int, plan []int, idx int) bool {
+	for _, other := range plan {
+		if other > idx {
+			if _, ok := codes[other]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,452 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+func TestCommandSegments(t *testing.T) {
+	got := CommandSegments(`systemctl stop app && rm -rf /var/cache/app | tee log; echo "a && b"`)
+	want := []string{"systemctl stop app", "rm -rf /var/cache/app | tee log", `echo "a && b"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CommandSegments() = %q, want %q", got, want)
+	}
+}
+
+func TestSegmentOperators(t *testing.T) {
+	cmd := `cd /srv/app && make || echo "a || b"; sleep 1 & wait 2>&1`
+	wantSegs := []string{"cd /srv/app", "make", `echo "a || b"`, "sleep 1", "wait 2>&1"}
+	wantOps := []string{"&&", "||", ";", "&", ""}
+	if got := CommandSegments(cmd); !reflect.DeepEqual(got, wantSegs) {
+		t.Errorf("CommandSegments() = %q, want %q", got, wantSegs)
+	}
+	if got := SegmentOperators(cmd); !reflect.DeepEqual(got, wantOps) {
+		t.Errorf("SegmentOperators() = %q, want %q", got, wantOps)
+	}
+}
+
+func TestCheckSegmentSkips(t *testing.T) {
+	segments := []string{"cd /srv/app", "rm -rf *", "FOO=1", "echo done"}
+	if err := checkSegmentSkips(segments, []int{2}); !errors.Is(err, ErrUnsafeSegmentSkip) {
+		t.Errorf("skipping cd before an approved segment: err = %v, want ErrUnsafeSegmentSkip", err)
+	}
+	if err := checkSegmentSkips(segments, []int{1, 2, 4}); !errors.Is(err, ErrUnsafeSegmentSkip) {
+		t.Errorf("skipping an assignment: err = %v, want ErrUnsafeSegmentSkip", err)
+	}
+	if err := checkSegmentSkips(segments, []int{1, 2}); err != nil {
+		t.Errorf("skipping only trailing segments: err = %v", err)
+	}
+}
+
+func TestNormalizeSegmentSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []int
+		count    int
+		want     []int
+		wantErr  bool
+	}{
+		{"empty is full decision", nil, 3, nil, false},
+		{"sorted and deduplicated", []int{3, 1, 3}, 3, []int{1, 3}, false},
+		{"all segments is full decision", []int{1, 2, 3}, 3, nil, false},
+		{"out of range", []int{4}, 3, nil, true},
+		{"zero", []int{0}, 3, nil, true},
+		{"not compound", []int{1}, 1, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeSegmentSelection(tt.segments, tt.count)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSegments) {
+				t.Errorf("err = %v, want ErrInvalidSegments", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmentDecision(t *testing.T) {
+	full := &db.Review{Decision: db.DecisionApprove}
+	partial := &db.Review{Decision: db.DecisionReject, Segments: []int{2}}
+	for seg, want := range map[int]db.Decision{1: db.DecisionApprove, 2: db.DecisionApprove, 3: db.DecisionApprove} {
+		if got := SegmentDecision(full, seg); got != want {
+			t.Errorf("full review segment %d = %s, want %s", seg, got, want)
+		}
+	}
+	for seg, want := range map[int]db.Decision{1: db.DecisionApprove, 2: db.DecisionReject, 3: db.DecisionApprove} {
+		if got := SegmentDecision(partial, seg); got != want {
+			t.Errorf("partial review segment %d = %s, want %s", seg, got, want)
+		}
+	}
+}
+
+func TestSegmentRunPlan(t *testing.T) {
+	if got := segmentRunPlan([]int{1, 3}, false); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("prefix plan = %v, want [1]", got)
+	}
+	if got := segmentRunPlan([]int{2, 3}, false); got != nil {
+		t.Errorf("prefix plan without segment 1 = %v, want nil", got)
+	}
+	if got := segmentRunPlan([]int{1, 3}, true); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("run-all plan = %v, want [1 3]", got)
+	}
+}
+
+// createSegmentReviewers creates n reviewer sessions with a model different from the requestor's.
+func createSegmentReviewers(t *testing.T, dbConn *db.DB, n int) []*db.Session {
+	t.Helper()
+	var sessions []*db.Session
+	for i := 0; i < n; i++ {
+		sess := &db.Session{
+			AgentName:   []string{"GreenLake", "RedCat", "BlueFox"}[i],
+			Program:     "claude-code",
+			Model:       "opus-4.5",
+			ProjectPath: "/test/project",
+		}
+		if err := dbConn.CreateSession(sess); err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions
+}
+
+func createCompoundRequest(t *testing.T, dbConn *db.DB, requestor *db.Session, minApprovals int) *db.Request {
+	t.Helper()
+	req := &db.Request{
+		ProjectPath:        "/test/project",
+		RequestorSessionID: requestor.ID,
+		RequestorAgent:     requestor.AgentName,
+		RequestorModel:     requestor.Model,
+		RiskTier:           db.RiskTierDangerous,
+		MinApprovals:       minApprovals,
+		Command: db.CommandSpec{
+			Raw: "systemctl stop app && rm -rf /var/cache/app && systemctl start app",
+			Cwd: "/test/project",
+		},
+		Justification: db.Justification{Reason: "Restart with a clean cache"},
+	}
+	if err := dbConn.CreateRequest(req); err != nil {
+		t.Fatalf("CreateRequest() error = %v", err)
+	}
+	return req
+}
+
+func TestSubmitReview_PartialApproval(t *testing.T) {
+	dbConn, requestor, _ := setupReviewTest(t)
+	defer dbConn.Close()
+	reviewers := createSegmentReviewers(t, dbConn, 2)
+	req := createCompoundRequest(t, dbConn, requestor, 2)
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+
+	// First reviewer rejects the middle segment: not yet decided under quorum 2.
+	result, err := rs.SubmitReview(ReviewOptions{
+		SessionID:  reviewers[0].ID,
+		SessionKey: reviewers[0].SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionReject,
+		Segments:   []int{2},
+		Comments:   "keep the cache",
+	})
+	if err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+	if result.RequestStatusChanged {
+		t.Fatalf("status changed to %s before segment decisions were complete", result.NewRequestStatus)
+	}
+
+	// Second reviewer approves everything: segments 1 and 3 reach quorum, 2 is rejected.
+	result, err = rs.SubmitReview(ReviewOptions{
+		SessionID:  reviewers[1].ID,
+		SessionKey: reviewers[1].SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+	})
+	if err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+	if result.NewRequestStatus != db.StatusApproved {
+		t.Fatalf("NewRequestStatus = %q, want approved", result.NewRequestStatus)
+	}
+	if !reflect.DeepEqual(result.ApprovedSegments, []int{1, 3}) {
+		t.Errorf("ApprovedSegments = %v, want [1 3]", result.ApprovedSegments)
+	}
+	if ra := result.ResolutionApplied; ra == nil || ra.Resolution != ConflictAnyRejectionBlocks || ra.Explanation != "2 of 3 segments approved" {
+		t.Errorf("ResolutionApplied = %+v, want 2 of 3 segments approved", ra)
+	}
+
+	stored, err := dbConn.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("GetRequest() error = %v", err)
+	}
+	if !reflect.DeepEqual(stored.ApprovedSegments, []int{1, 3}) {
+		t.Errorf("stored ApprovedSegments = %v, want [1 3]", stored.ApprovedSegments)
+	}
+}
+
+func TestSubmitReview_SegmentsDecideRequest(t *testing.T) {
+	dbConn, requestor, _ := setupReviewTest(t)
+	defer dbConn.Close()
+	reviewers := createSegmentReviewers(t, dbConn, 2)
+	req := createCompoundRequest(t, dbConn, requestor, 1)
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+
+	result, err := rs.SubmitReview(ReviewOptions{
+		SessionID:  reviewers[0].ID,
+		SessionKey: reviewers[0].SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+		Segments:   []int{1},
+	})
+	if err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+	if result.NewRequestStatus != db.StatusApproved || !reflect.DeepEqual(result.ApprovedSegments, []int{1}) {
+		t.Fatalf("got status %q segments %v, want approved [1]", result.NewRequestStatus, result.ApprovedSegments)
+	}
+
+	if _, err := rs.SubmitReview(ReviewOptions{
+		SessionID:  reviewers[1].ID,
+		SessionKey: reviewers[1].SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionReject,
+		Segments:   []int{5},
+	}); !errors.Is(err, ErrRequestNotPending) {
+		t.Errorf("expected ErrRequestNotPending once decided, got %v", err)
+	}
+}
+
+func TestSubmitReview_RefusesSkippingStateSegment(t *testing.T) {
+	dbConn, requestor, _ := setupReviewTest(t)
+	defer dbConn.Close()
+	reviewers := createSegmentReviewers(t, dbConn, 1)
+	req := &db.Request{
+		ProjectPath:        "/test/project",
+		RequestorSessionID: requestor.ID,
+		RequestorAgent:     requestor.AgentName,
+		RequestorModel:     requestor.Model,
+		RiskTier:           db.RiskTierDangerous,
+		MinApprovals:       1,
+		Command:            db.CommandSpec{Raw: "cd /srv/app && rm -rf *", Cwd: "/test/project"},
+		Justification:      db.Justification{Reason: "clean app dir"},
+	}
+	if err := dbConn.CreateRequest(req); err != nil {
+		t.Fatalf("CreateRequest() error = %v", err)
+	}
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+
+	_, err := rs.SubmitReview(ReviewOptions{
+		SessionID:  reviewers[0].ID,
+		SessionKey: reviewers[0].SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionReject,
+		Segments:   []int{1},
+	})
+	if !errors.Is(err, ErrInvalidSegments) || !errors.Is(err, ErrUnsafeSegmentSkip) {
+		t.Errorf("expected ErrInvalidSegments wrapping ErrUnsafeSegmentSkip, got %v", err)
+	}
+}
+
+func TestSubmitReview_SegmentsOnSimpleCommand(t *testing.T) {
+	dbConn, _, req := setupReviewTest(t)
+	defer dbConn.Close()
+	reviewers := createSegmentReviewers(t, dbConn, 1)
+	rs := NewReviewService(dbConn, DefaultReviewConfig())
+
+	_, err := rs.SubmitReview(ReviewOptions{
+		SessionID:  reviewers[0].ID,
+		SessionKey: reviewers[0].SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+		Segments:   []int{1},
+	})
+	if !errors.Is(err, ErrInvalidSegments) {
+		t.Errorf("expected ErrInvalidSegments, got %v", err)
+	}
+}
+
+func TestExecuteApprovedRequest_Segments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell execution test uses /bin/sh or $SHELL")
+	}
+
+	run := func(t *testing.T, raw string, approved []int, runAll bool) (*db.Request, *ExecutionResult, error) {
+		t.Helper()
+		dbConn, err := db.Open(":memory:")
+		if err != nil {
+			t.Fatalf("db.Open(:memory:) error = %v", err)
+		}
+		t.Cleanup(func() { dbConn.Close() })
+
+		session := &db.Session{
+			ID:          "test-session",
+			ProjectPath: "/tmp/test",
+			AgentName:   "test-agent",
+			Program:     "test-program",
+			Model:       "test-model",
+		}
+		if err := dbConn.CreateSession(session); err != nil {
+			t.Fatalf("CreateSession error = %v", err)
+		}
+
+		tmpDir := t.TempDir()
+		cmdSpec := db.CommandSpec{Raw: raw, Cwd: tmpDir, Shell: true}
+		cmdSpec.Hash = db.ComputeCommandHash(cmdSpec)
+		futureTime := time.Now().Add(time.Hour)
+		req := &db.Request{
+			ProjectPath:        tmpDir,
+			RequestorSessionID: "test-session",
+			RequestorAgent:     "test-agent",
+			RequestorModel:     "test-model",
+			RiskTier:           db.RiskTierCaution,
+			Command:            cmdSpec,
+			Status:             db.StatusApproved,
+			ApprovalExpiresAt:  &futureTime,
+		}
+		if err := dbConn.CreateRequest(req); err != nil {
+			t.Fatalf("CreateRequest error = %v", err)
+		}
+		if err := dbConn.Transaction(func(tx *sql.Tx) error {
+			return dbConn.UpdateApprovedSegmentsTx(tx, req.ID, approved)
+		}); err != nil {
+			t.Fatalf("UpdateApprovedSegmentsTx error = %v", err)
+		}
+
+		result, err := NewExecutor(dbConn, nil).ExecuteApprovedRequest(context.Background(), ExecuteOptions{
+			RequestID:              req.ID,
+			SessionID:              "test-session",
+			LogDir:                 filepath.Join(tmpDir, "logs"),
+			SuppressOutput:         true,
+			RunAllApprovedSegments: runAll,
+		})
+		stored, getErr := dbConn.GetRequest(req.ID)
+		if getErr != nil {
+			t.Fatalf("GetRequest error = %v", getErr)
+		}
+		return stored, result, err
+	}
+
+	t.Run("runs only the approved prefix", func(t *testing.T) {
+		stored, result, err := run(t, "touch one && touch two && touch three", []int{1, 3}, false)
+		if err != nil {
+			t.Fatalf("ExecuteApprovedRequest error = %v", err)
+		}
+		if len(result.Segments) != 1 || result.Segments[0].Index != 1 {
+			t.Fatalf("Segments = %+v, want only segment 1", result.Segments)
+		}
+		for name, want := range map[string]bool{"one": true, "two": false, "three": false} {
+			_, statErr := os.Stat(filepath.Join(stored.Command.Cwd, name))
+			if (statErr == nil) != want {
+				t.Errorf("file %s exists = %v, want %v", name, statErr == nil, want)
+			}
+		}
+		if stored.Execution == nil || len(stored.Execution.Segments) != 1 {
+			t.Errorf("stored execution segments = %+v, want 1", stored.Execution)
+		}
+		if stored.Status != db.StatusExecuted {
+			t.Errorf("status = %s, want executed", stored.Status)
+		}
+	})
+
+	t.Run("run all approved segments", func(t *testing.T) {
+		stored, result, err := run(t, "touch one && touch two && touch three", []int{1, 3}, true)
+		if err != nil {
+			t.Fatalf("ExecuteApprovedRequest error = %v", err)
+		}
+		if len(result.Segments) != 2 {
+			t.Fatalf("Segments = %+v, want 2", result.Segments)
+		}
+		if _, err := os.Stat(filepath.Join(stored.Command.Cwd, "three")); err != nil {
+			t.Errorf("segment 3 did not run: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(stored.Command.Cwd, "two")); err == nil {
+			t.Error("rejected segment 2 ran")
+		}
+	})
+
+	t.Run("failure skips later segments", func(t *testing.T) {
+		stored, result, err := run(t, "exit 3; touch two; touch three", []int{1, 3}, true)
+		if err != nil {
+			t.Fatalf("ExecuteApprovedRequest error = %v", err)
+		}
+		if result.ExitCode != 3 {
+			t.Errorf("ExitCode = %d, want 3", result.ExitCode)
+		}
+		if len(result.Segments) != 2 || !result.Segments[1].Skipped {
+			t.Fatalf("Segments = %+v, want segment 3 skipped", result.Segments)
+		}
+		if stored.Status != db.StatusExecutionFailed {
+			t.Errorf("status = %s, want execution_failed", stored.Status)
+		}
+	})
+
+	t.Run("or keeps its original meaning", func(t *testing.T) {
+		// "false || touch fallback" must only touch when false fails, and
+		// "true || touch never" must not touch at all.
+		stored, result, err := run(t, "false || touch fallback; true || touch never; touch three", []int{1, 2, 3, 4}, true)
+		if err != nil {
+			t.Fatalf("ExecuteApprovedRequest error = %v", err)
+		}
+		for name, want := range map[string]bool{"fallback": true, "never": false} {
+			_, statErr := os.Stat(filepath.Join(stored.Command.Cwd, name))
+			if (statErr == nil) != want {
+				t.Errorf("file %s exists = %v, want %v", name, statErr == nil, want)
+			}
+		}
+		if len(result.Segments) != 4 || !result.Segments[3].Skipped {
+			t.Errorf("Segments = %+v, want segment 4 short-circuited", result.Segments)
+		}
+		if result.Segments[0].ExitCode == nil || *result.Segments[0].ExitCode != 1 {
+			t.Errorf("segment 1 exit code = %v, want 1", result.Segments[0].ExitCode)
+		}
+	})
+
+	t.Run("cd carries over to later segments", func(t *testing.T) {
+		stored, result, err := run(t, "mkdir sub && cd sub && touch inner && touch skipped", []int{1, 2, 3}, false)
+		if err != nil {
+			t.Fatalf("ExecuteApprovedRequest error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(stored.Command.Cwd, "sub", "inner")); err != nil {
+			t.Errorf("segment 3 did not run inside sub: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(stored.Command.Cwd, "inner")); err == nil {
+			t.Error("segment 3 ran in the request cwd instead of after cd")
+		}
+		if _, err := os.Stat(filepath.Join(stored.Command.Cwd, "sub", "skipped")); err == nil {
+			t.Error("unapproved segment 4 ran")
+		}
+		if len(result.Segments) != 3 {
+			t.Errorf("Segments = %+v, want 3", result.Segments)
+		}
+	})
+
+	t.Run("refuses skipping cd before an approved segment", func(t *testing.T) {
+		stored, _, err := run(t, "cd sub && touch target", []int{2}, true)
+		if !errors.Is(err, ErrUnsafeSegmentSkip) {
+			t.Fatalf("expected ErrUnsafeSegmentSkip, got %v", err)
+		}
+		if _, statErr := os.Stat(filepath.Join(stored.Command.Cwd, "target")); statErr == nil {
+			t.Error("segment 2 ran without the cd it depends on")
+		}
+	})
+
+	t.Run("no approved prefix", func(t *testing.T) {
+		_, _, err := run(t, "touch one && touch two", []int{2}, false)
+		if !errors.Is(err, ErrNoApprovedPrefix) {
+			t.Errorf("expected ErrNoApprovedPrefix, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+// Package core implements self-protection for SLB's own state.
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SelfProtectionPattern is the MatchedPattern recorded when a command
+// targets SLB's own .slb directory or state database.
+const SelfProtectionPattern = "self_protection"
+
+// Self-protection actions (general.self_protection).
+const (
+	// SelfProtectionCritical classifies such commands as CRITICAL.
+	SelfProtectionCritical = "critical"
+	// SelfProtectionRefuse refuses to create a request for them.
+	SelfProtectionRefuse = "refuse"
+)
+
+// ErrSelfProtection is returned when request creation refuses a command
+// that targets SLB's own state.
+var ErrSelfProtection = errors.New("command targets slb's own state (.slb directory or state database)")
+
+// slbStateDir is the per-project directory holding the state DB and logs.
+const slbStateDir = ".slb"
+
+// readOnlyStateCommands may inspect .slb without threatening the audit trail.
+var readOnlyStateCommands = map[string]bool{
+	"ls": true, "cat": true, "head": true, "tail": true, "less": true,
+	"more": true, "stat": true, "file": true, "du": true, "wc": true,
+	"grep": true, "rg": true, "diff": true, "tree": true,
+	"sha256sum": true, "md5sum": true, "slb": true,
+}
+
+// TargetsSLBState reports whether cmd would modify SLB's own state and
+// returns the resolved path it targets. Paths are resolved against cwd
+// (following cd between segments) so relative, absolute and ../ variants,
+// and commands run from inside .slb, are all caught.
+func TargetsSLBState(cmd, cwd string) (string, bool) {
+	home, _ := os.UserHomeDir()
+	dir := cwd
+	for _, seg := range NormalizeCommand(cmd).Segments {
+		tokens := parseShellTokens(seg)
+		if len(tokens) == 0 {
+			continue
+		}
+		name := filepath.Base(tokens[0])
+		if name == "cd" {
+			if len(tokens) > 1 {
+				dir = resolveStatePath(tokens[1], dir, home)
+			} else {
+				dir = home
+			}
+			continue
+		}
+		if readOnlyStateCommands[name] {
+			continue
+		}
+		for _, tok := range tokens[1:] {
+			if strings.HasPrefix(tok, "-") {
+				idx := strings.Index(tok, "=")
+				if idx == -1 {
+					continue
+				}
+				tok = tok[idx+1:]
+			}
+			if tok == "" {
+				continue
+			}
+			if p := resolveStatePath(tok, dir, home); isSLBStatePath(p) {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// resolveStatePath resolves tok against dir, expanding ~ and following
+// symlinks when the path exists.
+func resolveStatePath(tok, dir, home string) string {
+	if home != "" && (tok == "~" || strings.HasPrefix(tok, "~/")) {
+		tok = filepath.Join(home, strings.TrimPrefix(tok, "~"))
+	}
+	if !filepath.IsAbs(tok) && dir != "" {
+		tok = filepath.Join(dir, tok)
+	}
+	tok = filepath.Clean(tok)
+	if resolved, err := filepath.EvalSymlinks(tok); err == nil {
+		return resolved
+	}
+	return tok
+}
+
+// isSLBStatePath reports whether p is a .slb directory or inside one.
+func isSLBStatePath(p string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(p), "/") {
+		if part == slbStateDir {
+			return true
+		}
+	}
+	return false
+}
+
+// applySelfProtection raises commands that target SLB's own state to
+// CRITICAL, overriding any pattern match (including SAFE ones such as
+// "rm *.log").
+func applySelfProtection(res *MatchResult, cmd, cwd string) *MatchResult {
+	if _, ok := TargetsSLBState(cmd, cwd); !ok {
+		return res
+	}
+	res.Tier = RiskTierCritical
+	res.MatchedPattern = SelfProtectionPattern
+	res.MinApprovals = tierApprovals(RiskTierCritical)
+	res.NeedsApproval = true
+	res.IsSafe = false
+	return res
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTargetsSLBState(t *testing.T) {
+	project := t.TempDir()
+	stateDir := filepath.Join(project, ".slb")
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cmd  string
+		cwd  string
+		want bool
+	}{
+		{"relative dir", "rm -rf .slb", project, true},
+		{"dot-slash dir", "rm -rf ./.slb/", project, true},
+		{"absolute dir", "rm -rf " + stateDir, "/", true},
+		{"parent traversal", "rm -rf sub/../.slb", project, true},
+		{"direct db path", `sqlite3 .slb/state.db "DROP TABLE requests"`, project, true},
+		{"absolute db path", "truncate -s 0 " + filepath.Join(stateDir, "state.db"), "/tmp", true},
+		{"flag value", "cp --target-directory=.slb evil.db", project, true},
+		{"run from inside .slb", "rm state.db", stateDir, true},
+		{"cd into .slb", "cd .slb && rm state.db", project, true},
+		{"wrapped", "sudo rm -rf .slb", project, true},
+		{"unrelated", "rm -rf ./build", project, false},
+		{"similar name", "rm -rf .slbackup", project, false},
+		{"read only", "cat .slb/config.toml", project, false},
+		{"slb itself", "slb history --db .slb/state.db", project, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path, got := TargetsSLBState(tc.cmd, tc.cwd)
+			if got != tc.want {
+				t.Fatalf("TargetsSLBState(%q, %q) = %v (%q), want %v", tc.cmd, tc.cwd, got, path, tc.want)
+			}
+			if got && !strings.Contains(path, ".slb") {
+				t.Errorf("resolved path %q does not name .slb", path)
+			}
+		})
+	}
+}
+
+func TestClassifyCommand_SelfProtection(t *testing.T) {
+	engine := NewPatternEngine()
+	project := t.TempDir()
+
+	tests := []struct {
+		cmd string
+	}{
+		{"rm -rf .slb"},
+		{"rm .slb/logs/old.log"}, // SAFE by pattern, but inside .slb
+		{"sqlite3 " + filepath.Join(project, ".slb", "state.db") + ` "DROP TABLE reviews"`},
+		{"ls && rm -rf .slb"},
+	}
+	for _, tc := range tests {
+		res := engine.ClassifyCommand(tc.cmd, project)
+		if res.Tier != RiskTierCritical || res.MatchedPattern != SelfProtectionPattern || !res.NeedsApproval || res.IsSafe {
+			t.Errorf("ClassifyCommand(%q) = tier %s pattern %q, want critical self-protection", tc.cmd, res.Tier, res.MatchedPattern)
+		}
+		if reason := DescribeClassification(res); !strings.Contains(reason, "self-protection") {
+			t.Errorf("rationale = %q", reason)
+		}
+	}
+
+	res := engine.ClassifyCommand("rm -rf ./build", project)
+	if res.MatchedPattern == SelfProtectionPattern || res.Tier != RiskTierDangerous {
+		t.Errorf("unrelated command classified %s (%q)", res.Tier, res.MatchedPattern)
+	}
+}
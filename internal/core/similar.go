@@ -0,0 +1,143 @@
+// Package core provides similarity lookup over historical requests.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// SimilarMatch is a historical request similar to a command, with the
+// review and outcome history reviewers need to judge it.
+type SimilarMatch struct {
+	Request *db.Request `json:"-"`
+	// Score is the Jaccard similarity of the normalized command tokens (0..1).
+	Score float64 `json:"score"`
+	// Reviewers lists "agent (decision)" for each review.
+	Reviewers []string `json:"reviewers,omitempty"`
+	// Outcome is the recorded post-execution outcome, if any.
+	Outcome *db.ExecutionOutcome `json:"outcome,omitempty"`
+}
+
+// CommandSimilarityTokens returns the index tokens for an ad-hoc command,
+// using the same normalization as request creation.
+func CommandSimilarityTokens(command string) []string {
+	return db.SimilarityTokens(NormalizeCommand(command).Segments)
+}
+
+// FindSimilarMatches returns the requests most similar to tokens, enriched
+// with their reviewers and outcomes.
+func FindSimilarMatches(database *db.DB, tokens []string, opts db.SimilarOptions) ([]SimilarMatch, error) {
+	similar, err := database.FindSimilarRequests(tokens, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]SimilarMatch, 0, len(similar))
+	for _, s := range similar {
+		m := SimilarMatch{Request: s.Request, Score: s.Score}
+		reviews, err := database.ListReviewsForRequest(s.Request.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reviews {
+			m.Reviewers = append(m.Reviewers, fmt.Sprintf("%s (%s)", r.ReviewerAgent, r.Decision))
+		}
+		outcome, err := database.GetOutcomeForRequest(s.Request.ID)
+		if err != nil && !errors.Is(err, db.ErrOutcomeNotFound) {
+			return nil, err
+		}
+		m.Outcome = outcome
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
+
+// TopSimilarMatch returns the closest request created before request, or nil
+// when none scores at least db.DefaultSimilarMinScore.
+func TopSimilarMatch(database *db.DB, request *db.Request) (*SimilarMatch, error) {
+	tokens, err := database.RequestTokens(request.ID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := FindSimilarMatches(database, tokens, db.SimilarOptions{
+		ExcludeID: request.ID,
+		Limit:     1,
+		MinScore:  db.DefaultSimilarMinScore,
+		BeforeID:  request.ID,
+	})
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+	return &matches[0], nil
+}
+
+// DescribeSimilarMatch summarizes a match in one line, e.g.
+// "similar request (92% match) approved 12 days ago, executed successfully".
+func DescribeSimilarMatch(m *SimilarMatch, now time.Time) string {
+	req := m.Request
+	when := req.CreatedAt
+	if req.ResolvedAt != nil {
+		when = *req.ResolvedAt
+	}
+
+	var verb string
+	switch req.Status {
+	case db.StatusPending:
+		verb = "requested"
+	case db.StatusRejected:
+		verb = "rejected"
+	case db.StatusTimeout, db.StatusTimedOut:
+		verb = "timed out"
+	case db.StatusCancelled:
+		verb = "cancelled"
+	case db.StatusEscalated:
+		verb = "escalated"
+	default:
+		verb = "approved"
+	}
+	desc := fmt.Sprintf("similar request (%d%% match) %s %s", int(math.Round(m.Score*100)), verb, describeAge(now.Sub(when)))
+
+	switch {
+	case m.Outcome != nil && m.Outcome.CausedProblems:
+		desc += ", caused problems"
+		if m.Outcome.ProblemDescription != "" {
+			desc += ": " + m.Outcome.ProblemDescription
+		}
+	case req.Status == db.StatusExecutionFailed:
+		desc += ", execution failed"
+		if req.Execution != nil && req.Execution.ExitCode != nil {
+			desc += fmt.Sprintf(" (exit %d)", *req.Execution.ExitCode)
+		}
+	case req.Status == db.StatusExecuted:
+		desc += ", executed successfully"
+	case req.Status == db.StatusExecuting:
+		desc += ", executing"
+	case req.Status == db.StatusApproved:
+		desc += ", not executed"
+	case req.Status == db.StatusPending:
+		desc += ", still pending"
+	}
+	if req.Rollback != nil && req.Rollback.RolledBackAt != nil {
+		desc += ", rolled back"
+	}
+	return desc
+}
+
+// describeAge renders a coarse relative age ("today", "3 days ago").
+func describeAge(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	switch {
+	case d < time.Hour:
+		return "just now"
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestCreateRequest_IndexesNormalizedSegments(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+	creator := NewRequestCreator(database, nil, nil, nil)
+
+	result, err := creator.CreateRequest(CreateRequestOptions{
+		SessionID: session.ID,
+		Command:   "sudo rm -rf /tmp/build",
+		Cwd:       "/tmp",
+	})
+	if err != nil || result.Request == nil {
+		t.Fatalf("CreateRequest failed: %v", err)
+	}
+
+	got, err := database.RequestTokens(result.Request.ID)
+	if err != nil {
+		t.Fatalf("RequestTokens failed: %v", err)
+	}
+	// Wrappers and flag order do not affect the indexed tokens.
+	if want := CommandSimilarityTokens("rm -fr /tmp/build"); !reflect.DeepEqual(got, want) {
+		t.Errorf("indexed tokens = %v, want %v", got, want)
+	}
+}
+
+func TestFindSimilarMatches_IncludesReviewersAndOutcome(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	requestor := testutil.MakeSession(t, database, testutil.SessionWithAgentName("requestor"))
+	reviewer := testutil.MakeSession(t, database, testutil.SessionWithAgentName("BlueLake"))
+
+	past := testutil.MakeRequest(t, database, requestor, testutil.WithCommand("rm -rf ./build", "/tmp", true))
+	if err := database.CreateReview(&db.Review{
+		RequestID:         past.ID,
+		ReviewerSessionID: reviewer.ID,
+		ReviewerAgent:     reviewer.AgentName,
+		ReviewerModel:     reviewer.Model,
+		Decision:          db.DecisionApprove,
+		Signature:         "sig",
+	}); err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+	if err := database.CreateOutcome(&db.ExecutionOutcome{
+		RequestID:          past.ID,
+		CausedProblems:     true,
+		ProblemDescription: "deleted cached assets",
+	}); err != nil {
+		t.Fatalf("CreateOutcome failed: %v", err)
+	}
+
+	matches, err := FindSimilarMatches(database, CommandSimilarityTokens("rm -fr ./build"), db.SimilarOptions{})
+	if err != nil {
+		t.Fatalf("FindSimilarMatches failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Request.ID != past.ID {
+		t.Fatalf("expected the past request as the only match, got %+v", matches)
+	}
+	m := matches[0]
+	if m.Score != 1 {
+		t.Errorf("score = %v, want 1", m.Score)
+	}
+	if !reflect.DeepEqual(m.Reviewers, []string{"BlueLake (approve)"}) {
+		t.Errorf("reviewers = %v", m.Reviewers)
+	}
+	if m.Outcome == nil || !m.Outcome.CausedProblems {
+		t.Errorf("expected problem outcome, got %+v", m.Outcome)
+	}
+}
+
+func TestTopSimilarMatch(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"))
+
+	current := testutil.MakeRequest(t, database, session, testutil.WithCommand("kubectl delete ns staging", "/tmp", true))
+	if m, err := TopSimilarMatch(database, current); err != nil || m != nil {
+		t.Fatalf("expected no match (self excluded), got %+v, %v", m, err)
+	}
+
+	// Later requests are never the "earlier" match, however similar, whether
+	// created in the same second or after it.
+	sameSecond := testutil.MakeRequest(t, database, session, testutil.WithCommand("kubectl delete ns staging", "/tmp", true))
+	later := testutil.MakeRequest(t, database, session, testutil.WithCommand("kubectl delete ns staging", "/tmp", true))
+	setCreated := func(r *db.Request, d time.Duration) {
+		ts := current.CreatedAt.Add(d).UTC().Format(time.RFC3339)
+		if _, err := database.Exec(`UPDATE requests SET created_at = ? WHERE id = ?`, ts, r.ID); err != nil {
+			t.Fatalf("setting created_at: %v", err)
+		}
+	}
+	setCreated(sameSecond, 0)
+	setCreated(later, time.Hour)
+	if m, err := TopSimilarMatch(database, current); err != nil || m != nil {
+		t.Fatalf("expected no match from later requests, got %+v, %v", m, err)
+	}
+
+	past := testutil.MakeRequest(t, database, session, testutil.WithCommand("kubectl delete ns preview", "/tmp", true))
+	setCreated(past, -time.Hour)
+	testutil.MakeRequest(t, database, session, testutil.WithCommand("git push --force", "/tmp", true))
+
+	m, err := TopSimilarMatch(database, current)
+	if err != nil {
+		t.Fatalf("TopSimilarMatch failed: %v", err)
+	}
+	if m == nil || m.Request.ID != past.ID {
+		t.Fatalf("expected %s as top match, got %+v", past.ID, m)
+	}
+}
+
+func TestDescribeSimilarMatch(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	resolved := now.Add(-12 * 24 * time.Hour)
+	exit := 2
+
+	tests := []struct {
+		name    string
+		status  db.RequestStatus
+		outcome *db.ExecutionOutcome
+		exec    *db.Execution
+		rolled  bool
+		want    string
+	}{
+		{"executed", db.StatusExecuted, nil, nil, false,
+			"similar request (88% match) approved 12 days ago, executed successfully"},
+		{"problems", db.StatusExecuted, &db.ExecutionOutcome{CausedProblems: true, ProblemDescription: "outage"}, nil, false,
+			"similar request (88% match) approved 12 days ago, caused problems: outage"},
+		{"failed", db.StatusExecutionFailed, nil, &db.Execution{ExitCode: &exit}, false,
+			"similar request (88% match) approved 12 days ago, execution failed (exit 2)"},
+		{"rolled back", db.StatusExecuted, nil, nil, true,
+			"similar request (88% match) approved 12 days ago, executed successfully, rolled back"},
+		{"approved only", db.StatusApproved, nil, nil, false,
+			"similar request (88% match) approved 12 days ago, not executed"},
+		{"rejected", db.StatusRejected, nil, nil, false,
+			"similar request (88% match) rejected 12 days ago"},
+		{"timeout", db.StatusTimeout, nil, nil, false,
+			"similar request (88% match) timed out 12 days ago"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &db.Request{Status: tc.status, CreatedAt: resolved.Add(-time.Hour), ResolvedAt: &resolved, Execution: tc.exec}
+			if tc.rolled {
+				req.Rollback = &db.Rollback{Path: "/tmp/rb", RolledBackAt: &now}
+			}
+			got := DescribeSimilarMatch(&SimilarMatch{Request: req, Score: 0.875, Outcome: tc.outcome}, now)
+			if got != tc.want {
+				t.Errorf("got  %q\nwant %q", got, tc.want)
+			}
+		})
+	}
+
+	pending := &db.Request{Status: db.StatusPending, CreatedAt: now.Add(-30 * time.Minute)}
+	if got := DescribeSimilarMatch(&SimilarMatch{Request: pending, Score: 1}, now); got != "similar request (100% match) requested just now, still pending" {
+		t.Errorf("pending description = %q", got)
+	}
+}
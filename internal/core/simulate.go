@@ -0,0 +1,88 @@
+// Package core implements request simulation.
+package core
+
+import "strings"
+
+// SimulateOptions holds the options for Simulate.
+type SimulateOptions struct {
+	// Command is the raw command to simulate.
+	Command string
+	// Cwd is the directory the command would run in.
+	Cwd string
+	// ProjectPath is the project whose trusted scripts and live sessions
+	// apply.
+	ProjectPath string
+	// RedactPatterns are custom patterns to redact from display.
+	RedactPatterns []string
+	// CaptureRollback reports whether executions capture rollback state
+	// (general.enable_rollback_capture).
+	CaptureRollback bool
+}
+
+// SimulateResult is what a request for a command would look like. It never
+// holds the raw command, only its redacted form.
+type SimulateResult struct {
+	DisplayRedacted   string   `json:"display_redacted"`
+	ContainsSensitive bool     `json:"contains_sensitive"`
+	Summary           string   `json:"summary,omitempty"`
+	Tier              RiskTier `json:"tier"`
+	TierReason        string   `json:"tier_reason"`
+	MatchedPattern    string   `json:"matched_pattern,omitempty"`
+	NeedsApproval     bool     `json:"needs_approval"`
+	// MinApprovals is the approvals the request would need, after the
+	// dynamic quorum adjustment.
+	MinApprovals int `json:"min_approvals"`
+	// RiskOverride is the risk_overrides rule matching the command, if any;
+	// TierReason says whether it was applied.
+	RiskOverride string `json:"risk_override,omitempty"`
+	// Refused is why the request would be refused outright, if it would be.
+	Refused string `json:"refused,omitempty"`
+	// DryRunCommand is the dry-run variant a preview would run, redacted.
+	DryRunCommand string `json:"dry_run_command,omitempty"`
+	// RollbackKind is the state a rollback capture would save (see
+	// RollbackKind), and RollbackCapture whether execution would capture it.
+	RollbackKind    string `json:"rollback_kind,omitempty"`
+	RollbackCapture bool   `json:"rollback_capture"`
+}
+
+// Simulate reports how CreateRequest would classify and gate a command,
+// without creating a request, running anything, or writing to the database.
+// Trusted scripts and the dynamic quorum are only considered when rc has a
+// database.
+func (rc *RequestCreator) Simulate(opts SimulateOptions) (*SimulateResult, error) {
+	if strings.TrimSpace(opts.Command) == "" {
+		return nil, ErrCommandRequired
+	}
+	classified, err := rc.classify(opts.Command, opts.Cwd, opts.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+	match := classified.match
+
+	display := ApplyRedaction(opts.Command, opts.RedactPatterns)
+	res := &SimulateResult{
+		DisplayRedacted:   display,
+		ContainsSensitive: display != opts.Command || DetectSensitiveContent(opts.Command),
+		Summary:           SummarizeCommand(display),
+		Tier:              match.Tier,
+		TierReason:        classified.reason,
+		MatchedPattern:    match.MatchedPattern,
+		NeedsApproval:     match.NeedsApproval,
+		RiskOverride:      classified.override,
+		RollbackKind:      RollbackKind(opts.Command),
+	}
+	if match.MatchedPattern == SelfProtectionPattern && rc.config.SelfProtectionAction == SelfProtectionRefuse {
+		res.Refused = ErrSelfProtection.Error()
+	}
+	if match.NeedsApproval {
+		res.MinApprovals = match.MinApprovals
+		if rc.config.DynamicQuorumEnabled && rc.db != nil {
+			res.MinApprovals = rc.checkDynamicQuorum(match.Tier, res.MinApprovals, opts.ProjectPath)
+		}
+	}
+	if dryRun, ok := GetDryRunCommand(opts.Command); ok {
+		res.DryRunCommand = ApplyRedaction(dryRun, opts.RedactPatterns)
+	}
+	res.RollbackCapture = opts.CaptureRollback && match.NeedsApproval && res.Refused == "" && res.RollbackKind != ""
+	return res, nil
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestSimulate(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	project := "/test/project"
+	creator := NewRequestCreator(database, nil, nil, DefaultRequestCreatorConfig())
+
+	tests := []struct {
+		name      string
+		cmd       string
+		tier      RiskTier
+		approvals int
+		dryRun    string
+		rollback  string
+		capture   bool
+	}{
+		{"critical with rollback", "kubectl delete namespace staging", RiskTierCritical, 2, "kubectl delete namespace staging --dry-run=client -o yaml", "kubernetes", true},
+		{"dangerous filesystem", "rm -rf ./build", RiskTierDangerous, 1, "ls -la -- ./build", "filesystem", true},
+		{"no rollback kind", "terraform destroy", RiskTierCritical, 2, "terraform plan -destroy", "", false},
+		{"no approval needed", "echo hello", "", 0, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := creator.Simulate(SimulateOptions{Command: tt.cmd, Cwd: project, ProjectPath: project, CaptureRollback: true})
+			if err != nil {
+				t.Fatalf("Simulate: %v", err)
+			}
+			if res.Tier != tt.tier || res.MinApprovals != tt.approvals {
+				t.Errorf("tier = %q with %d approvals, want %q with %d", res.Tier, res.MinApprovals, tt.tier, tt.approvals)
+			}
+			if res.DryRunCommand != tt.dryRun {
+				t.Errorf("dry-run command = %q, want %q", res.DryRunCommand, tt.dryRun)
+			}
+			if res.RollbackKind != tt.rollback || res.RollbackCapture != tt.capture {
+				t.Errorf("rollback = %q (capture %v), want %q (capture %v)", res.RollbackKind, res.RollbackCapture, tt.rollback, tt.capture)
+			}
+		})
+	}
+
+	res, err := creator.Simulate(SimulateOptions{Command: "rm -rf ./build", Cwd: project, ProjectPath: project})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if res.RollbackCapture {
+		t.Error("expected no rollback capture with capture disabled")
+	}
+
+	var count int
+	if err := database.QueryRow(`SELECT COUNT(*) FROM requests`).Scan(&count); err != nil || count != 0 {
+		t.Errorf("expected no requests written, got %d (%v)", count, err)
+	}
+	if _, err := creator.Simulate(SimulateOptions{Command: "  "}); err != ErrCommandRequired {
+		t.Errorf("expected ErrCommandRequired for an empty command, got %v", err)
+	}
+}
+
+func TestSimulate_RedactsCommand(t *testing.T) {
+	creator := NewRequestCreator(nil, nil, nil, DefaultRequestCreatorConfig())
+	cmd := `psql "postgres://admin:hunter2@db/app" -c "DROP TABLE users" --set ticket=TKT-4411`
+
+	res, err := creator.Simulate(SimulateOptions{Command: cmd, Cwd: "/tmp", RedactPatterns: []string{`TKT-[0-9]+`}})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if !res.ContainsSensitive || !res.NeedsApproval {
+		t.Errorf("expected a sensitive command needing approval, got %+v", res)
+	}
+	out, _ := json.Marshal(res)
+	if strings.Contains(string(out), "hunter2") || strings.Contains(string(out), "TKT-4411") {
+		t.Errorf("simulation leaks the secret: %s", out)
+	}
+}
+
+func TestSimulate_OverridesAndPolicy(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	project := "/test/project"
+	for _, agent := range []string{"agent1", "agent2"} {
+		testutil.MakeSession(t, database, testutil.SessionWithAgentName(agent), testutil.SessionWithProject(project))
+	}
+
+	config := DefaultRequestCreatorConfig()
+	config.RiskOverrides = []RiskOverrideRule{{Glob: "kubectl delete namespace dev-*", Tier: RiskTierDangerous}}
+	config.DynamicQuorumEnabled = true
+	config.DynamicQuorumFloor = 1
+	config.SelfProtectionAction = SelfProtectionRefuse
+	creator := NewRequestCreator(database, nil, nil, config)
+
+	res, err := creator.Simulate(SimulateOptions{Command: "kubectl delete namespace dev-42", Cwd: project, ProjectPath: project})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if res.Tier != RiskTierDangerous || res.RiskOverride != `glob "kubectl delete namespace dev-*"` || !strings.Contains(res.TierReason, "overridden from critical to dangerous") {
+		t.Errorf("expected the override applied and named, got tier %q, rule %q, reason %q", res.Tier, res.RiskOverride, res.TierReason)
+	}
+
+	// Two live sessions leave one reviewer for a critical command.
+	res, err = creator.Simulate(SimulateOptions{Command: "kubectl delete namespace prod", Cwd: project, ProjectPath: project})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if res.Tier != RiskTierCritical || res.MinApprovals != 1 || res.RiskOverride != "" {
+		t.Errorf("expected critical with the quorum lowered to 1, got %q with %d (rule %q)", res.Tier, res.MinApprovals, res.RiskOverride)
+	}
+
+	res, err = creator.Simulate(SimulateOptions{Command: "rm -rf .slb", Cwd: project, ProjectPath: project})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if res.Refused != ErrSelfProtection.Error() {
+		t.Errorf("expected the self-protection refusal reported, got %q", res.Refused)
+	}
+}
@@ -16,15 +16,25 @@ const (
 // validTransitions defines all valid state transitions.
 // Map key is the from state, value is a list of valid to states.
 var validTransitions = map[db.RequestStatus][]db.RequestStatus{
+	db.StatusQueued: {
+		db.StatusPending, // Admitted by the rate limiter
+		db.StatusCancelled,
+	},
 	db.StatusPending: {
 		db.StatusApproved,
 		db.StatusRejected,
 		db.StatusCancelled,
 		db.StatusTimeout,
+		db.StatusEscalated, // Pending past its tier's escalate-after age
 	},
 	db.StatusApproved: {
 		db.StatusExecuting,
 		db.StatusCancelled,
+		db.StatusApprovalExpired, // Approval lapsed before execution
+	},
+	db.StatusApprovalExpired: {
+		db.StatusPending, // Re-requested for a new review round
+		db.StatusCancelled,
 	},
 	db.StatusExecuting: {
 		db.StatusExecuted,
@@ -38,6 +48,7 @@ var validTransitions = map[db.RequestStatus][]db.RequestStatus{
 	db.StatusEscalated: {
 		db.StatusApproved, // Human can approve after escalation
 		db.StatusRejected, // Human can reject after escalation
+		db.StatusPending,  // A withdrawn review undid a tie
 	},
 }
 
@@ -64,7 +75,7 @@ func (e *TransitionError) Error() string {
 // CanTransition returns true if the transition from one state to another is valid.
 func CanTransition(from, to db.RequestStatus) bool {
 	// Allow creation-time transition.
-	if from == "" && to == db.StatusPending {
+	if from == "" && (to == db.StatusPending || to == db.StatusQueued) {
 		return true
 	}
 
@@ -109,35 +120,10 @@ func ValidateTransition(from, to db.RequestStatus) error {
 	return nil
 }
 
-// Transition attempts to transition a request to a new state.
-// Returns an error if the transition is invalid.
+// Transition attempts to transition a request to a new state, using the
+// default approval TTLs. Returns an error if the transition is invalid.
 func Transition(req *db.Request, to db.RequestStatus) error {
-	if err := ValidateTransition(req.Status, to); err != nil {
-		return err
-	}
-
-	// Update the request
-	now := time.Now().UTC()
-	if req.Status == "" && to == db.StatusPending && req.CreatedAt.IsZero() {
-		req.CreatedAt = now
-	}
-	req.Status = to
-
-	if to == db.StatusApproved && req.ApprovalExpiresAt == nil {
-		ttl := defaultApprovalTTL
-		if req.RiskTier == db.RiskTierCritical {
-			ttl = defaultApprovalTTLCritical
-		}
-		expiresAt := now.Add(ttl)
-		req.ApprovalExpiresAt = &expiresAt
-	}
-
-	// Set resolved timestamp for terminal states
-	if TerminalStates[to] {
-		req.ResolvedAt = &now
-	}
-
-	return nil
+	return defaultStateMachine.Transition(req, to)
 }
 
 // TransitionWithReason transitions a request and logs the reason.
@@ -154,7 +140,7 @@ func TransitionWithReason(req *db.Request, to db.RequestStatus, reason string) e
 // GetValidTransitions returns all valid target states from the given state.
 func GetValidTransitions(from db.RequestStatus) []db.RequestStatus {
 	if from == "" {
-		return []db.RequestStatus{db.StatusPending}
+		return []db.RequestStatus{db.StatusPending, db.StatusQueued}
 	}
 	if TerminalStates[from] {
 		return nil
@@ -203,12 +189,19 @@ func CanExecute(status db.RequestStatus) bool {
 
 // CanCancel checks if a request can be cancelled.
 func CanCancel(status db.RequestStatus) bool {
-	return status == db.StatusPending || status == db.StatusApproved
+	return status == db.StatusPending || status == db.StatusApproved || status == db.StatusQueued ||
+		status == db.StatusApprovalExpired
 }
 
 // CheckExpiry checks if a pending request has expired.
 // Returns the appropriate status transition if expired.
 func CheckExpiry(req *db.Request) (db.RequestStatus, bool) {
+	return CheckExpiryAt(req, time.Now())
+}
+
+// CheckExpiryAt is CheckExpiry as of now. A request expires only once now is
+// strictly after ExpiresAt, so one reviewed exactly at the deadline stands.
+func CheckExpiryAt(req *db.Request, now time.Time) (db.RequestStatus, bool) {
 	if req.Status != db.StatusPending {
 		return "", false
 	}
@@ -217,7 +210,7 @@ func CheckExpiry(req *db.Request) (db.RequestStatus, bool) {
 		return "", false
 	}
 
-	if time.Now().After(*req.ExpiresAt) {
+	if now.After(*req.ExpiresAt) {
 		return db.StatusTimeout, true
 	}
 
@@ -226,6 +219,11 @@ func CheckExpiry(req *db.Request) (db.RequestStatus, bool) {
 
 // CheckApprovalExpiry checks if an approved request's approval has become stale.
 func CheckApprovalExpiry(req *db.Request) bool {
+	return CheckApprovalExpiryAt(req, time.Now())
+}
+
+// CheckApprovalExpiryAt is CheckApprovalExpiry as of now.
+func CheckApprovalExpiryAt(req *db.Request, now time.Time) bool {
 	if req.Status != db.StatusApproved {
 		return false
 	}
@@ -234,20 +232,71 @@ func CheckApprovalExpiry(req *db.Request) bool {
 		return false
 	}
 
-	return time.Now().After(*req.ApprovalExpiresAt)
+	return now.After(*req.ApprovalExpiresAt)
 }
 
 // StateMachine provides request state management.
-type StateMachine struct{}
+type StateMachine struct {
+	// approvalTTLs is how long an approval stays valid, per risk tier.
+	approvalTTLs map[db.RiskTier]time.Duration
+}
+
+// defaultStateMachine backs the package-level Transition.
+var defaultStateMachine = NewStateMachine()
 
-// NewStateMachine creates a new state machine.
+// NewStateMachine creates a new state machine with the default approval TTLs.
 func NewStateMachine() *StateMachine {
 	return &StateMachine{}
 }
 
-// Transition transitions a request to a new state.
+// NewStateMachineWithApprovalTTLs creates a state machine whose approvals
+// stay valid for the given TTL per risk tier. A tier without a positive TTL
+// keeps the default.
+func NewStateMachineWithApprovalTTLs(ttls map[db.RiskTier]time.Duration) *StateMachine {
+	sm := &StateMachine{approvalTTLs: make(map[db.RiskTier]time.Duration, len(ttls))}
+	for tier, ttl := range ttls {
+		sm.approvalTTLs[tier] = ttl
+	}
+	return sm
+}
+
+// ApprovalTTL returns how long an approval of a request at tier stays valid.
+func (sm *StateMachine) ApprovalTTL(tier db.RiskTier) time.Duration {
+	if ttl := sm.approvalTTLs[tier]; ttl > 0 {
+		return ttl
+	}
+	if tier == db.RiskTierCritical {
+		return defaultApprovalTTLCritical
+	}
+	return defaultApprovalTTL
+}
+
+// Transition transitions a request to a new state. An approval expires after
+// the TTL for the request's tier. Returns an error if the transition is
+// invalid.
 func (sm *StateMachine) Transition(req *db.Request, to db.RequestStatus) error {
-	return Transition(req, to)
+	if err := ValidateTransition(req.Status, to); err != nil {
+		return err
+	}
+
+	// Update the request
+	now := time.Now().UTC()
+	if req.Status == "" && to == db.StatusPending && req.CreatedAt.IsZero() {
+		req.CreatedAt = now
+	}
+	req.Status = to
+
+	if to == db.StatusApproved && req.ApprovalExpiresAt == nil {
+		expiresAt := now.Add(sm.ApprovalTTL(req.RiskTier))
+		req.ApprovalExpiresAt = &expiresAt
+	}
+
+	// Set resolved timestamp for terminal states
+	if TerminalStates[to] {
+		req.ResolvedAt = &now
+	}
+
+	return nil
 }
 
 // CanTransition checks if a transition is valid.
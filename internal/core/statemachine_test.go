@@ -15,12 +15,18 @@ func TestCanTransition(t *testing.T) {
 		want bool
 	}{
 		{"new->pending", "", db.StatusPending, true},
+		{"new->queued", "", db.StatusQueued, true},
 		{"new->approved (invalid)", "", db.StatusApproved, false},
 
+		{"queued->pending", db.StatusQueued, db.StatusPending, true},
+		{"queued->cancelled", db.StatusQueued, db.StatusCancelled, true},
+		{"queued->approved (invalid)", db.StatusQueued, db.StatusApproved, false},
+
 		{"pending->approved", db.StatusPending, db.StatusApproved, true},
 		{"pending->rejected", db.StatusPending, db.StatusRejected, true},
 		{"pending->cancelled", db.StatusPending, db.StatusCancelled, true},
 		{"pending->timeout", db.StatusPending, db.StatusTimeout, true},
+		{"pending->escalated", db.StatusPending, db.StatusEscalated, true},
 		{"pending->executing (invalid)", db.StatusPending, db.StatusExecuting, false},
 
 		{"timeout->escalated", db.StatusTimeout, db.StatusEscalated, true},
@@ -33,6 +39,11 @@ func TestCanTransition(t *testing.T) {
 		{"approved->executing", db.StatusApproved, db.StatusExecuting, true},
 		{"approved->cancelled", db.StatusApproved, db.StatusCancelled, true},
 		{"approved->executed (invalid)", db.StatusApproved, db.StatusExecuted, false},
+		{"approved->approval_expired", db.StatusApproved, db.StatusApprovalExpired, true},
+
+		{"approval_expired->pending", db.StatusApprovalExpired, db.StatusPending, true},
+		{"approval_expired->cancelled", db.StatusApprovalExpired, db.StatusCancelled, true},
+		{"approval_expired->executing (invalid)", db.StatusApprovalExpired, db.StatusExecuting, false},
 
 		{"executing->executed", db.StatusExecuting, db.StatusExecuted, true},
 		{"executing->execution_failed", db.StatusExecuting, db.StatusExecutionFailed, true},
@@ -256,12 +267,14 @@ func TestGetValidTransitions(t *testing.T) {
 		from db.RequestStatus
 		want []db.RequestStatus
 	}{
-		{"empty->pending", "", []db.RequestStatus{db.StatusPending}},
-		{"pending", db.StatusPending, []db.RequestStatus{db.StatusApproved, db.StatusRejected, db.StatusCancelled, db.StatusTimeout}},
-		{"approved", db.StatusApproved, []db.RequestStatus{db.StatusExecuting, db.StatusCancelled}},
+		{"empty->pending", "", []db.RequestStatus{db.StatusPending, db.StatusQueued}},
+		{"queued", db.StatusQueued, []db.RequestStatus{db.StatusPending, db.StatusCancelled}},
+		{"pending", db.StatusPending, []db.RequestStatus{db.StatusApproved, db.StatusRejected, db.StatusCancelled, db.StatusTimeout, db.StatusEscalated}},
+		{"approved", db.StatusApproved, []db.RequestStatus{db.StatusExecuting, db.StatusCancelled, db.StatusApprovalExpired}},
+		{"approval_expired", db.StatusApprovalExpired, []db.RequestStatus{db.StatusPending, db.StatusCancelled}},
 		{"executing", db.StatusExecuting, []db.RequestStatus{db.StatusExecuted, db.StatusExecutionFailed, db.StatusTimedOut, db.StatusApproved}},
 		{"timeout", db.StatusTimeout, []db.RequestStatus{db.StatusEscalated}},
-		{"escalated", db.StatusEscalated, []db.RequestStatus{db.StatusApproved, db.StatusRejected}},
+		{"escalated", db.StatusEscalated, []db.RequestStatus{db.StatusApproved, db.StatusRejected, db.StatusPending}},
 		{"terminal (executed)", db.StatusExecuted, nil},
 		{"terminal (rejected)", db.StatusRejected, nil},
 	}
@@ -407,6 +420,7 @@ func TestCanCancel(t *testing.T) {
 		status db.RequestStatus
 		want   bool
 	}{
+		{db.StatusQueued, true},
 		{db.StatusPending, true},
 		{db.StatusApproved, true},
 		{db.StatusExecuting, false},
@@ -496,6 +510,30 @@ func TestStateMachine(t *testing.T) {
 		}
 	})
 
+	t.Run("CustomApprovalTTL", func(t *testing.T) {
+		sm := NewStateMachineWithApprovalTTLs(map[db.RiskTier]time.Duration{
+			db.RiskTierCritical: 2 * time.Minute,
+		})
+		req := &db.Request{Status: db.StatusPending, RiskTier: db.RiskTierCritical}
+		before := time.Now().UTC()
+		if err := sm.Transition(req, db.StatusApproved); err != nil {
+			t.Fatalf("Transition() error = %v", err)
+		}
+		after := time.Now().UTC()
+		if req.ApprovalExpiresAt == nil ||
+			req.ApprovalExpiresAt.Before(before.Add(2*time.Minute)) || req.ApprovalExpiresAt.After(after.Add(2*time.Minute)) {
+			t.Fatalf("ApprovalExpiresAt = %v, want 2m after approval", req.ApprovalExpiresAt)
+		}
+
+		// Tiers without a TTL keep the default.
+		if got := sm.ApprovalTTL(db.RiskTierDangerous); got != defaultApprovalTTL {
+			t.Errorf("ApprovalTTL(dangerous) = %s, want %s", got, defaultApprovalTTL)
+		}
+		if got := NewStateMachine().ApprovalTTL(db.RiskTierCritical); got != defaultApprovalTTLCritical {
+			t.Errorf("default ApprovalTTL(critical) = %s, want %s", got, defaultApprovalTTLCritical)
+		}
+	})
+
 	t.Run("CanTransition", func(t *testing.T) {
 		sm := NewStateMachine()
 		if !sm.CanTransition(db.StatusPending, db.StatusApproved) {
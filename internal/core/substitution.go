@@ -0,0 +1,221 @@
+// Package core implements classification of command substitutions.
+package core
+
+import "strings"
+
+// substitutionInnerPattern is the MatchedPattern of a command raised by a
+// command run inside one of its substitutions, as in "echo $(rm -rf /)".
+const substitutionInnerPattern = "substitution_inner_command"
+
+// runtimeTargetsRationale explains the tier raise for MatchResult.RuntimeTargets.
+const runtimeTargetsRationale = "targets derived from runtime substitution"
+
+// subshell is the body of a command run in a subshell: a $(...) or `...`
+// substitution, or a ( ... ) group.
+type subshell struct {
+	body  string
+	group bool
+}
+
+// subshellScan is the result of scanSubshells.
+type subshellScan struct {
+	// subshells are the top-level subshells, in order.
+	subshells []subshell
+	// flat is the command with the parentheses of its groups blanked, so
+	// grouped commands split and classify like any others.
+	flat string
+	// ambiguous reports nesting that cannot be read reliably: an unclosed
+	// subshell, an unmatched ')', or a leading "((" that may be arithmetic.
+	ambiguous bool
+}
+
+// scanSubshells finds the subshells of cmd. Single-quoted text is literal
+// and skipped; arithmetic $((...)) is not a substitution. Parentheses that
+// do not open a group, as in f() or arr=(...), are scanned only for the
+// substitutions inside them. An unclosed subshell runs to the end of cmd.
+func scanSubshells(cmd string) subshellScan {
+	var scan subshellScan
+	flat := []byte(cmd)
+	inSingle, inDouble := false, false
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case c == '\\' && !inSingle:
+			i++
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case inSingle:
+		case c == '"':
+			inDouble = !inDouble
+		case c == '$' && strings.HasPrefix(cmd[i+1:], "("):
+			end := closingParen(cmd, i+2)
+			if end == len(cmd) {
+				scan.ambiguous = true
+			}
+			if !strings.HasPrefix(cmd[i+1:], "((") {
+				scan.subshells = append(scan.subshells, subshell{body: cmd[i+2 : end]})
+			}
+			i = end
+		case c == '`':
+			end := strings.IndexByte(cmd[i+1:], '`')
+			if end < 0 {
+				scan.subshells = append(scan.subshells, subshell{body: cmd[i+1:]})
+				scan.ambiguous = true
+				return scan.withFlat(flat)
+			}
+			scan.subshells = append(scan.subshells, subshell{body: cmd[i+1 : i+1+end]})
+			i += end + 1
+		case inDouble:
+		case c == '(':
+			end := closingParen(cmd, i+1)
+			inner := scanSubshells(cmd[i+1 : end])
+			scan.ambiguous = scan.ambiguous || inner.ambiguous || end == len(cmd)
+			if !startsCommand(cmd[:i]) {
+				scan.subshells = append(scan.subshells, inner.subshells...)
+				i = end
+				break
+			}
+			if strings.HasPrefix(cmd[i+1:], "(") {
+				scan.ambiguous = true
+			}
+			scan.subshells = append(scan.subshells, subshell{body: cmd[i+1 : end], group: true})
+			flat[i] = ' '
+			copy(flat[i+1:end], inner.flat)
+			if end < len(cmd) {
+				flat[end] = ' '
+			}
+			i = end
+		case c == ')':
+			scan.ambiguous = true
+		}
+	}
+	return scan.withFlat(flat)
+}
+
+func (s subshellScan) withFlat(flat []byte) subshellScan {
+	s.flat = string(flat)
+	return s
+}
+
+// startsCommand reports whether a '(' after prefix opens a group: it comes
+// first, or after a command separator or a keyword that precedes a command.
+func startsCommand(prefix string) bool {
+	prefix = strings.TrimRight(prefix, " \t")
+	if prefix == "" || strings.ContainsAny(prefix[len(prefix)-1:], ";&|(\n") {
+		return true
+	}
+	words := strings.Fields(prefix)
+	switch words[len(words)-1] {
+	case "then", "do", "else", "elif", "if", "while", "until", "!", "{":
+		return true
+	}
+	return false
+}
+
+// commandSubstitutions returns the bodies of cmd's $(...) and `...`
+// substitutions that are not nested in another substitution, including
+// those inside groups. An unclosed substitution runs to the end of cmd.
+func commandSubstitutions(cmd string) []string {
+	var bodies []string
+	for _, sub := range scanSubshells(cmd).subshells {
+		if sub.group {
+			bodies = append(bodies, commandSubstitutions(sub.body)...)
+			continue
+		}
+		bodies = append(bodies, sub.body)
+	}
+	return bodies
+}
+
+// closingParen returns the index of the ')' closing a '(' just before
+// start, or len(s) if there is none.
+func closingParen(s string, start int) int {
+	depth := 1
+	inSingle, inDouble := false, false
+	for i := start; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && !inSingle:
+			i++
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case inSingle:
+		case c == '"':
+			inDouble = !inDouble
+		case inDouble:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// HasRuntimeTargets reports whether cmd takes arguments from a command
+// substitution, so the targets of whatever it runs are only known when the
+// shell expands them. Requests for such commands are never resolved
+// automatically: not by caution auto-approval, timeout auto-approval,
+// delegations or a no-op dry run.
+func HasRuntimeTargets(cmd string) bool {
+	return len(commandSubstitutions(cmd)) > 0
+}
+
+// applySubstitutionInspection classifies the commands cmd runs inside
+// substitutions and groups, raising res to the highest of their tiers, and
+// raises res one more tier when a command needing approval takes its
+// arguments from a substitution (MatchResult.RuntimeTargets).
+func (e *PatternEngine) applySubstitutionInspection(res *MatchResult, cmd, cwd string) *MatchResult {
+	subshells := scanSubshells(cmd).subshells
+	if len(subshells) == 0 {
+		return res
+	}
+	runtimeTargets := HasRuntimeTargets(cmd) && substitutedMatch(res, cmd)
+
+	for _, sub := range subshells {
+		inner := e.ClassifyCommand(sub.body, cwd)
+		if inner.IsSafe || inner.Tier == "" {
+			continue
+		}
+		res.RuntimeTargets = res.RuntimeTargets || inner.RuntimeTargets
+		if tierRank(inner.Tier) > tierRank(res.Tier) || res.IsSafe {
+			res.Tier = inner.Tier
+			res.MatchedPattern = substitutionInnerPattern
+			res.MinApprovals = tierApprovals(inner.Tier)
+			res.NeedsApproval = true
+			res.IsSafe = false
+		}
+	}
+
+	if runtimeTargets {
+		res.RuntimeTargets = true
+		res.Tier = upgradeTier(res.Tier)
+		res.MinApprovals = tierApprovals(res.Tier)
+		res.NeedsApproval = true
+		res.IsSafe = false
+	}
+	return res
+}
+
+// substitutedMatch reports whether the command that gave res its tier
+// takes arguments from a substitution: cmd itself for a simple command, a
+// matched segment for a compound one.
+func substitutedMatch(res *MatchResult, cmd string) bool {
+	if res.IsSafe || res.Tier == "" || res.Tier == RiskTier(RiskSafe) {
+		return false
+	}
+	if len(res.MatchedSegments) == 0 {
+		return true
+	}
+	for _, seg := range res.MatchedSegments {
+		if seg.Tier == RiskTier(RiskSafe) || seg.Tier == "" {
+			continue
+		}
+		if len(commandSubstitutions(seg.Segment)) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandSubstitutions(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string
+	}{
+		{`rm -rf "$(cat targets.txt)"`, []string{"cat targets.txt"}},
+		{"rm -rf `ls build`", []string{"ls build"}},
+		{`echo $(dirname $(which go))`, []string{"dirname $(which go)"}},
+		{`echo $(printf ')')`, []string{"printf ')'"}},
+		{`rm -rf $(find . -name x`, []string{"find . -name x"}},
+		{`echo '$(rm -rf /)'`, nil},
+		{`echo \$(date)`, nil},
+		{`echo $((1 + 2))`, nil},
+		{`(cd /x && rm -rf $(cat list))`, []string{"cat list"}},
+		{`f() { echo; }`, nil},
+		{`rm -rf ./x`, nil},
+	}
+	for _, tt := range tests {
+		if got := commandSubstitutions(tt.cmd); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("commandSubstitutions(%q) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyCommand_Substitution(t *testing.T) {
+	engine := NewPatternEngine()
+	cwd := "/tmp/project"
+
+	tests := []struct {
+		name    string
+		cmd     string
+		tier    RiskTier // "" means no approval needed
+		runtime bool
+	}{
+		{"rm -rf with substituted targets", "rm -rf $(cmd)", RiskTierCritical, true},
+		{"quoted substitution", `rm -rf "$(cat targets.txt)"`, RiskTierCritical, true},
+		{"backticks", "rm `ls *.tmp`", RiskTierDangerous, true},
+		{"literal target", "rm -rf ./x", RiskTierDangerous, false},
+		{"single-quoted text is literal", `rm -rf './$(x)'`, RiskTierDangerous, false},
+		{"dangerous inner command", "echo $(rm -rf ./build)", RiskTierDangerous, false},
+		{"substitution into an unmatched command", "ls $(pwd)", "", false},
+		{"only the matched segment counts", "cd $(git rev-parse --show-toplevel) && rm -rf ./x", RiskTierDangerous, false},
+		{"substituted segment of a compound", "cd /tmp && rm -rf $(cat list)", RiskTierCritical, true},
+		{"group", "(cd /x && rm -rf ./y)", RiskTierDangerous, false},
+		{"critical command in a group", "(kubectl delete namespace staging)", RiskTierCritical, false},
+		{"safe group", "(cd /x && ls)", "", false},
+		{"substitution in a group", "(echo $(rm -rf ./build))", RiskTierDangerous, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := engine.ClassifyCommand(tt.cmd, cwd)
+			if tt.tier == "" {
+				if res.NeedsApproval {
+					t.Fatalf("expected no approval, got tier %s (%s)", res.Tier, res.MatchedPattern)
+				}
+				return
+			}
+			if !res.NeedsApproval || res.Tier != tt.tier {
+				t.Fatalf("tier = %q (needs approval %v, pattern %q), want %q", res.Tier, res.NeedsApproval, res.MatchedPattern, tt.tier)
+			}
+			if res.RuntimeTargets != tt.runtime {
+				t.Errorf("RuntimeTargets = %v, want %v", res.RuntimeTargets, tt.runtime)
+			}
+		})
+	}
+}
+
+func TestDescribeClassification_Substitution(t *testing.T) {
+	engine := NewPatternEngine()
+	got := DescribeClassification(engine.ClassifyCommand("rm -rf $(cat targets.txt)", "/tmp/project"))
+	if !strings.Contains(got, "upgraded to critical: targets derived from runtime substitution") {
+		t.Errorf("DescribeClassification() = %q", got)
+	}
+	if strings.Contains(got, "targets.txt") {
+		t.Errorf("DescribeClassification() = %q, must not include operands", got)
+	}
+
+	got = DescribeClassification(engine.ClassifyCommand("echo $(rm -rf ./build)", "/tmp/project"))
+	if !strings.Contains(got, "command substitution runs a dangerous command") {
+		t.Errorf("DescribeClassification() = %q", got)
+	}
+}
+
+func TestHasRuntimeTargets(t *testing.T) {
+	if !HasRuntimeTargets("rm -rf $(cmd)") {
+		t.Error("expected rm -rf $(cmd) to have runtime targets")
+	}
+	if HasRuntimeTargets("rm -rf ./x") || HasRuntimeTargets(`rm -rf '$(x)'`) {
+		t.Error("expected literal targets not to be runtime targets")
+	}
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// SweepResult lists the requests one sweep moved.
+type SweepResult struct {
+	// TimedOut are pending requests moved to timeout.
+	TimedOut []*db.Request
+	// ApprovalsExpired are approved requests moved to approval_expired
+	// because their approval lapsed before they were executed.
+	ApprovalsExpired []*db.Request
+	// Escalated are pending requests moved to escalated because they
+	// outlived their escalate-after age (see
+	// ReviewService.EscalateStaleRequests).
+	Escalated []*db.Request
+	// Admitted are queued requests moved to pending because their session
+	// had room under its rate limits again (see
+	// RequestCreator.AdmitProjectQueued).
+	Admitted []*db.Request
+	// DelegationsExpired are delegations removed because they expired.
+	DelegationsExpired []*db.Delegation
+}
+
+// SweepExpiredRequests enforces request deadlines for a project as of now:
+// pending requests past ExpiresAt move to timeout, and approved requests past
+// ApprovalExpiresAt move to approval_expired so a stale approval cannot be
+// executed later; the requestor can re-request review. Deadlines are
+// compared as instants, so the zone they were stored in does not matter, and
+// a request is only swept once now is strictly after its deadline (see
+// CheckExpiryAt). Each change is conditional on the status
+// that was read, so a request reviewed, cancelled or executed meanwhile is
+// left alone. Delegations that expired by now are removed.
+func SweepExpiredRequests(database *db.DB, projectPath string, now time.Time) (*SweepResult, error) {
+	result := &SweepResult{}
+
+	pending, err := database.ListRequestsByStatus(db.StatusPending, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("listing pending requests: %w", err)
+	}
+	for _, req := range pending {
+		to, expired := CheckExpiryAt(req, now)
+		if !expired {
+			continue
+		}
+		moved, err := sweepTransition(database, req, to)
+		if err != nil {
+			return result, err
+		}
+		if moved {
+			result.TimedOut = append(result.TimedOut, req)
+		}
+	}
+
+	approved, err := database.ListRequestsByStatus(db.StatusApproved, projectPath)
+	if err != nil {
+		return result, fmt.Errorf("listing approved requests: %w", err)
+	}
+	for _, req := range approved {
+		if !CheckApprovalExpiryAt(req, now) {
+			continue
+		}
+		moved, err := sweepTransition(database, req, db.StatusApprovalExpired)
+		if err != nil {
+			return result, err
+		}
+		if moved {
+			result.ApprovalsExpired = append(result.ApprovalsExpired, req)
+		}
+	}
+
+	if result.DelegationsExpired, err = database.DeleteExpiredDelegations(projectPath, now); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// sweepTransition moves req to status if it is still in the status it was
+// read with, reporting whether it did.
+func sweepTransition(database *db.DB, req *db.Request, to db.RequestStatus) (bool, error) {
+	from := req.Status
+	if err := Transition(req, to); err != nil {
+		return false, err
+	}
+	err := database.Transaction(func(tx *sql.Tx) error {
+		return database.UpdateRequestStatusTx(tx, req.ID, to, from)
+	})
+	if errors.Is(err, db.ErrInvalidTransition) {
+		req.Status = from
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("sweeping request %s: %w", req.ID, err)
+	}
+	return true, nil
+}
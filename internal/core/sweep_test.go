@@ -0,0 +1,142 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// sweepFixture creates a pending request expiring at deadline.
+func sweepFixture(t *testing.T, database *db.DB, sess *db.Session, deadline time.Time, opts ...testutil.RequestOption) *db.Request {
+	t.Helper()
+	return testutil.MakeRequest(t, database, sess, append([]testutil.RequestOption{testutil.WithExpiresAt(deadline)}, opts...)...)
+}
+
+func withApprovalExpiresAt(at time.Time) testutil.RequestOption {
+	return func(r *db.Request) { r.ApprovalExpiresAt = &at }
+}
+
+func TestSweepExpiredRequests(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	sess := testutil.MakeSession(t, database)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	stale := sweepFixture(t, database, sess, now.Add(-time.Minute))
+	fresh := sweepFixture(t, database, sess, now.Add(time.Minute))
+	staleApproval := sweepFixture(t, database, sess, now.Add(time.Hour), withApprovalExpiresAt(now.Add(-time.Second)))
+	freshApproval := sweepFixture(t, database, sess, now.Add(time.Hour), withApprovalExpiresAt(now.Add(time.Minute)))
+	for _, r := range []*db.Request{staleApproval, freshApproval} {
+		if err := database.UpdateRequestStatus(r.ID, db.StatusApproved); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := SweepExpiredRequests(database, sess.ProjectPath, now)
+	if err != nil {
+		t.Fatalf("SweepExpiredRequests: %v", err)
+	}
+	if len(result.TimedOut) != 1 || result.TimedOut[0].ID != stale.ID {
+		t.Errorf("expected only the stale request to time out, got %+v", result.TimedOut)
+	}
+	if len(result.ApprovalsExpired) != 1 || result.ApprovalsExpired[0].ID != staleApproval.ID {
+		t.Errorf("expected only the stale approval to expire, got %+v", result.ApprovalsExpired)
+	}
+
+	want := map[string]db.RequestStatus{
+		stale.ID:         db.StatusTimeout,
+		fresh.ID:         db.StatusPending,
+		staleApproval.ID: db.StatusApprovalExpired,
+		freshApproval.ID: db.StatusApproved,
+	}
+	for id, status := range want {
+		got, _ := database.GetRequest(id)
+		if got.Status != status {
+			t.Errorf("request %s: status %s, want %s", id, got.Status, status)
+		}
+	}
+	if got, _ := database.GetRequest(staleApproval.ID); got.ResolvedAt != nil {
+		t.Error("expected the expired approval to stay open for a re-request")
+	}
+
+	// A second sweep finds nothing left to do.
+	again, err := SweepExpiredRequests(database, sess.ProjectPath, now)
+	if err != nil || len(again.TimedOut)+len(again.ApprovalsExpired) != 0 {
+		t.Errorf("expected an idempotent sweep, got %+v, %v", again, err)
+	}
+}
+
+func TestSweepExpiredRequests_DeadlineBoundary(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	sess := testutil.MakeSession(t, database)
+	deadline := time.Now().UTC().Truncate(time.Second).Add(time.Minute)
+	req := sweepFixture(t, database, sess, deadline, withApprovalExpiresAt(deadline.Add(30*time.Minute)))
+
+	// Exactly at the deadline the request is still live.
+	result, err := SweepExpiredRequests(database, sess.ProjectPath, deadline)
+	if err != nil || len(result.TimedOut) != 0 {
+		t.Fatalf("expected no sweep at the deadline, got %+v, %v", result, err)
+	}
+
+	// Reviewed at the deadline: the approval stands and a later sweep
+	// leaves it alone until the approval itself expires.
+	if err := database.UpdateRequestStatus(req.ID, db.StatusApproved); err != nil {
+		t.Fatal(err)
+	}
+	result, err = SweepExpiredRequests(database, sess.ProjectPath, deadline.Add(time.Second))
+	if err != nil || len(result.TimedOut)+len(result.ApprovalsExpired) != 0 {
+		t.Fatalf("expected the approval to stand, got %+v, %v", result, err)
+	}
+	if got, _ := database.GetRequest(req.ID); got.Status != db.StatusApproved {
+		t.Fatalf("expected approved, got %s", got.Status)
+	}
+}
+
+func TestSweepExpiredRequests_ReviewedAfterRead(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	sess := testutil.MakeSession(t, database)
+	now := time.Now().UTC().Truncate(time.Second)
+	req := sweepFixture(t, database, sess, now.Add(-time.Second))
+
+	// The sweep read the request as pending, then a review landed.
+	read, err := database.GetRequest(req.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.UpdateRequestStatus(req.ID, db.StatusApproved); err != nil {
+		t.Fatal(err)
+	}
+	moved, err := sweepTransition(database, read, db.StatusTimeout)
+	if err != nil || moved {
+		t.Fatalf("expected the concurrent review to win, got moved=%v, %v", moved, err)
+	}
+	if got, _ := database.GetRequest(req.ID); got.Status != db.StatusApproved {
+		t.Errorf("expected approved, got %s", got.Status)
+	}
+}
+
+func TestSweepExpiredRequests_ClockSkew(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	sess := testutil.MakeSession(t, database)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// A requestor whose clock runs in another zone sets the same instant.
+	east := time.FixedZone("UTC+5", 5*60*60)
+	req := sweepFixture(t, database, sess, now.Add(time.Minute).In(east))
+
+	// A sweeper clock behind the deadline, even when expressed in a zone
+	// whose wall time is later, does not expire it early.
+	west := time.FixedZone("UTC-8", -8*60*60)
+	if result, err := SweepExpiredRequests(database, sess.ProjectPath, now.In(east)); err != nil || len(result.TimedOut) != 0 {
+		t.Fatalf("expected no sweep before the deadline, got %+v, %v", result, err)
+	}
+	if result, err := SweepExpiredRequests(database, sess.ProjectPath, now.Add(59*time.Second).In(west)); err != nil || len(result.TimedOut) != 0 {
+		t.Fatalf("expected no sweep a second before the deadline, got %+v, %v", result, err)
+	}
+
+	result, err := SweepExpiredRequests(database, sess.ProjectPath, now.Add(61*time.Second).In(west))
+	if err != nil || len(result.TimedOut) != 1 || result.TimedOut[0].ID != req.ID {
+		t.Fatalf("expected the request to time out past the deadline, got %+v, %v", result, err)
+	}
+}
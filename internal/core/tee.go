@@ -0,0 +1,200 @@
+// Package core implements detection of privileged writes through tee.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-shellwords"
+)
+
+// TeeWrite is a file a pipeline writes through tee, as in
+// "echo x | sudo tee /etc/hosts". Such writes bypass redirect-based checks,
+// so they are recorded during normalization and tiered separately.
+type TeeWrite struct {
+	// Path is the target as written in the command.
+	Path string
+	// Append is set for tee -a, which adds to the file instead of
+	// truncating it.
+	Append bool
+	// Sudo is set when tee runs under sudo or doas.
+	Sudo bool
+}
+
+// MatchedPattern values for commands raised by a tee write.
+const (
+	teePrivilegedPattern           = "tee_privileged_path"
+	teePrivilegedAppendPattern     = "tee_privileged_path_append"
+	teeOutsideProjectPattern       = "tee_outside_project"
+	teeOutsideProjectAppendPattern = "tee_outside_project_append"
+	teeSudoPattern                 = "tee_sudo"
+)
+
+// teeRationales explain each tee pattern for DescribeClassification.
+var teeRationales = map[string]string{
+	teePrivilegedPattern:           "pipes into tee, truncating a file in a system directory",
+	teePrivilegedAppendPattern:     "pipes into tee -a, appending to a file in a system directory",
+	teeOutsideProjectPattern:       "pipes into tee, truncating a file outside the working directory",
+	teeOutsideProjectAppendPattern: "pipes into tee -a, appending to a file outside the working directory",
+	teeSudoPattern:                 "pipes into tee running as root",
+}
+
+// privilegedDirs are system directories whose files need root to write.
+var privilegedDirs = []string{
+	"/bin", "/boot", "/dev", "/etc", "/lib", "/lib32", "/lib64", "/opt",
+	"/proc", "/root", "/sbin", "/srv", "/sys", "/usr", "/var",
+}
+
+// harmlessTeeTargets are device files tee commonly writes to without effect.
+var harmlessTeeTargets = map[string]bool{
+	"/dev/null": true, "/dev/stdout": true, "/dev/stderr": true, "/dev/tty": true,
+}
+
+// sudoArgFlags are sudo options that take a separate argument.
+var sudoArgFlags = map[string]bool{
+	"-u": true, "-g": true, "-C": true, "-D": true, "-h": true,
+	"-p": true, "-r": true, "-t": true, "-U": true,
+}
+
+// redirectTokenPattern matches a shell redirection token; redirectOpPattern
+// matches one whose target is the next token.
+var (
+	redirectTokenPattern = regexp.MustCompile(`^[0-9&]*[<>]`)
+	redirectOpPattern    = regexp.MustCompile(`^[0-9&]*(>>?|<|>\||[<>]&)$`)
+)
+
+// parseTeeWrites returns the files a single pipeline stage writes if it is
+// a tee invocation, possibly under sudo, doas or another wrapper.
+func parseTeeWrites(stage string) []TeeWrite {
+	tokens, err := shellwords.NewParser().Parse(stage)
+	if err != nil {
+		tokens = strings.Fields(stage)
+	}
+
+	sudo := false
+	i := 0
+wrappers:
+	for i < len(tokens) {
+		tok := tokens[i]
+		switch {
+		case isEnvAssignment(tok):
+			i++
+		case tok == "sudo" || tok == "doas":
+			sudo = true
+			i++
+			for i < len(tokens) && strings.HasPrefix(tokens[i], "-") {
+				if sudoArgFlags[tokens[i]] {
+					i++
+				}
+				i++
+			}
+		case isWrapper(tok):
+			i++
+		default:
+			break wrappers
+		}
+	}
+	if i >= len(tokens) || filepath.Base(tokens[i]) != "tee" {
+		return nil
+	}
+
+	var paths []string
+	appendMode := false
+	options := true
+	args := tokens[i+1:]
+	for j := 0; j < len(args); j++ {
+		tok := args[j]
+		switch {
+		case redirectTokenPattern.MatchString(tok):
+			if redirectOpPattern.MatchString(tok) {
+				j++ // the redirection's target
+			}
+		case options && tok == "--":
+			options = false
+		case options && tok == "--append":
+			appendMode = true
+		case options && strings.HasPrefix(tok, "--"):
+		case options && strings.HasPrefix(tok, "-") && tok != "-":
+			if strings.Contains(tok, "a") {
+				appendMode = true
+			}
+		default:
+			paths = append(paths, tok)
+		}
+	}
+
+	writes := make([]TeeWrite, 0, len(paths))
+	for _, p := range paths {
+		writes = append(writes, TeeWrite{Path: p, Append: appendMode, Sudo: sudo})
+	}
+	return writes
+}
+
+// classifyTeeWrite returns the tier and pattern for a tee write: truncating
+// a file in a system directory is CRITICAL and appending to one DANGEROUS;
+// outside the working directory (other than temp directories) they are
+// DANGEROUS and CAUTION; any other write as root is CAUTION.
+func classifyTeeWrite(w TeeWrite, cwd string) (RiskTier, string) {
+	home, _ := os.UserHomeDir()
+	path := w.Path
+	if home != "" && (path == "~" || strings.HasPrefix(path, "~/")) {
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	if !filepath.IsAbs(path) && cwd != "" {
+		path = filepath.Join(cwd, path)
+	}
+	path = filepath.Clean(path)
+
+	if harmlessTeeTargets[path] || strings.HasPrefix(path, "/dev/fd/") {
+		return "", ""
+	}
+	inside := func(dir string) bool {
+		return dir != "" && (path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/"))
+	}
+	temp := inside("/tmp") || inside("/var/tmp") || inside(filepath.Clean(os.TempDir()))
+
+	switch {
+	case filepath.IsAbs(path) && !temp && (cwd == "" || !inside(filepath.Clean(cwd))) && underPrivilegedDir(path):
+		if w.Append {
+			return RiskTierDangerous, teePrivilegedAppendPattern
+		}
+		return RiskTierCritical, teePrivilegedPattern
+	case cwd != "" && filepath.IsAbs(path) && !temp && !inside(filepath.Clean(cwd)):
+		if w.Append {
+			return RiskTierCaution, teeOutsideProjectAppendPattern
+		}
+		return RiskTierDangerous, teeOutsideProjectPattern
+	case w.Sudo:
+		return RiskTierCaution, teeSudoPattern
+	}
+	return "", ""
+}
+
+func underPrivilegedDir(path string) bool {
+	for _, dir := range privilegedDirs {
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTeeInspection raises res to the highest tier of cmd's tee writes.
+func applyTeeInspection(res *MatchResult, cmd, cwd string) *MatchResult {
+	for _, w := range NormalizeCommand(cmd).TeeWrites {
+		tier, pattern := classifyTeeWrite(w, cwd)
+		if tier == "" {
+			continue
+		}
+		if tierRank(tier) > tierRank(res.Tier) || res.IsSafe {
+			res.Tier = tier
+			res.MatchedPattern = pattern
+			res.MinApprovals = tierApprovals(tier)
+			res.NeedsApproval = true
+			res.IsSafe = false
+		}
+	}
+	return res
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTeeWrites(t *testing.T) {
+	tests := []struct {
+		stage string
+		want  []TeeWrite
+	}{
+		{"sudo tee /etc/hosts", []TeeWrite{{Path: "/etc/hosts", Sudo: true}}},
+		{"sudo -u root tee -a /etc/hosts", []TeeWrite{{Path: "/etc/hosts", Append: true, Sudo: true}}},
+		{"tee --append a.txt b.txt", []TeeWrite{{Path: "a.txt", Append: true}, {Path: "b.txt", Append: true}}},
+		{"tee -ai log.txt", []TeeWrite{{Path: "log.txt", Append: true}}},
+		{"sudo tee /etc/motd > /dev/null", []TeeWrite{{Path: "/etc/motd", Sudo: true}}},
+		{"tee out.txt >/dev/null 2>&1", []TeeWrite{{Path: "out.txt"}}},
+		{"LC_ALL=C /usr/bin/tee -- -a", []TeeWrite{{Path: "-a"}}},
+		{"echo tee /etc/hosts", nil},
+		{"tee", []TeeWrite{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.stage, func(t *testing.T) {
+			if got := parseTeeWrites(tt.stage); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTeeWrites(%q) = %+v, want %+v", tt.stage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyCommand_Tee(t *testing.T) {
+	engine := NewPatternEngine()
+	cwd := "/home/dev/project"
+
+	tests := []struct {
+		name    string
+		cmd     string
+		tier    RiskTier // "" means no approval needed
+		pattern string
+	}{
+		{"sudo tee of /etc/hosts", "echo x | sudo tee /etc/hosts", RiskTierCritical, teePrivilegedPattern},
+		{"tee -a of /etc/hosts", "echo x | sudo tee -a /etc/hosts", RiskTierDangerous, teePrivilegedAppendPattern},
+		{"tee into a system directory without sudo", "cat conf | tee /usr/local/etc/app.conf >/dev/null", RiskTierCritical, teePrivilegedPattern},
+		{"tee outside the project", "echo x | tee /home/dev/.bashrc", RiskTierDangerous, teeOutsideProjectPattern},
+		{"tee -a outside the project", "echo x | tee -a ../other/notes.txt", RiskTierCaution, teeOutsideProjectAppendPattern},
+		{"sudo tee inside the project", "echo x | sudo tee build/out.txt", RiskTierCaution, teeSudoPattern},
+		{"tee of a local file", "echo x | tee ./local.txt", "", ""},
+		{"tee to a temp file", "make 2>&1 | tee /tmp/build.log", "", ""},
+		{"tee to /dev/null", "echo x | sudo -n true | tee /dev/null", "", ""},
+		{"tee raises a safe match", "rm old.log; echo x | tee /etc/cron.d/job", RiskTierCritical, teePrivilegedPattern},
+		{"higher pattern tier is kept", "rm -rf /etc/app; echo x | sudo tee -a /etc/hosts", RiskTierCritical, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := engine.ClassifyCommand(tt.cmd, cwd)
+			if tt.tier == "" {
+				if res.NeedsApproval {
+					t.Fatalf("expected no approval, got tier %s (%s)", res.Tier, res.MatchedPattern)
+				}
+				return
+			}
+			if !res.NeedsApproval || res.Tier != tt.tier {
+				t.Fatalf("tier = %q (needs approval %v, pattern %q), want %q", res.Tier, res.NeedsApproval, res.MatchedPattern, tt.tier)
+			}
+			if tt.pattern != "" && res.MatchedPattern != tt.pattern {
+				t.Errorf("pattern = %q, want %q", res.MatchedPattern, tt.pattern)
+			}
+		})
+	}
+}
+
+func TestDescribeClassification_Tee(t *testing.T) {
+	res := NewPatternEngine().ClassifyCommand("echo '127.0.0.1 db' | sudo tee -a /etc/hosts", "/home/dev/project")
+	got := DescribeClassification(res)
+	if !strings.Contains(got, "tee -a, appending to a file in a system directory") {
+		t.Errorf("DescribeClassification() = %q", got)
+	}
+	if strings.Contains(got, "/etc/hosts") {
+		t.Errorf("DescribeClassification() = %q, must not include operands", got)
+	}
+}
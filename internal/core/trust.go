@@ -0,0 +1,269 @@
+// Package core implements trusted scripts: vetted scripts, pinned by content
+// hash, whose invocation is reviewed one tier lighter.
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/mattn/go-shellwords"
+)
+
+// ErrTrustedScriptChanged is returned at execution when a request's tier was
+// lowered by a trusted script that has since been edited or revoked.
+var ErrTrustedScriptChanged = errors.New("trusted script no longer matches its trust entry")
+
+// TrustStatus is the outcome of checking a trust entry against the script.
+type TrustStatus string
+
+const (
+	// TrustVerified means the script still has the vetted contents.
+	TrustVerified TrustStatus = "verified"
+	// TrustModified means the script was edited since it was trusted.
+	TrustModified TrustStatus = "modified"
+	// TrustMissing means the script no longer exists.
+	TrustMissing TrustStatus = "missing"
+	// TrustBadSignature means the entry's signature does not verify.
+	TrustBadSignature TrustStatus = "bad_signature"
+	// TrustRevoked means the entry was revoked.
+	TrustRevoked TrustStatus = "revoked"
+)
+
+// TrustVerification is a trust entry and whether it still holds.
+type TrustVerification struct {
+	Entry         *db.TrustedScript `json:"entry"`
+	Status        TrustStatus       `json:"status"`
+	CurrentSHA256 string            `json:"current_sha256,omitempty"`
+	Detail        string            `json:"detail,omitempty"`
+}
+
+// Holds reports whether the trust entry still applies.
+func (v *TrustVerification) Holds() bool {
+	return v != nil && v.Status == TrustVerified
+}
+
+// scriptInterpreters run the script named by their first operand.
+var scriptInterpreters = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true,
+	"python": true, "python3": true, "ruby": true, "perl": true, "node": true,
+}
+
+// HashScript returns the hex SHA-256 of a script's contents.
+func HashScript(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ResolveTrustedScriptPath resolves path (relative to cwd) to the slash
+// separated path relative to projectPath that trust entries are keyed by.
+// Symlinks are followed, and the script must be a regular file inside the
+// project.
+func ResolveTrustedScriptPath(projectPath, cwd, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", path)
+	}
+	root, err := filepath.EvalSymlinks(projectPath)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the project %s", path, projectPath)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// scriptOperand returns the script a simple command runs: its program when
+// given as a path (./deploy.sh, ops/deploy.sh) or the first operand of a
+// script interpreter (bash ops/deploy.sh). Programs looked up on PATH and
+// inline code (bash -c, python -m) are not scripts.
+func scriptOperand(primary string) string {
+	tokens, err := shellwords.NewParser().Parse(primary)
+	if err != nil || len(tokens) == 0 {
+		return ""
+	}
+	if strings.Contains(tokens[0], "/") {
+		return tokens[0]
+	}
+	if !scriptInterpreters[tokens[0]] {
+		return ""
+	}
+	for _, tok := range tokens[1:] {
+		switch {
+		case tok == "-c" || tok == "-m" || tok == "-e" || tok == "--eval":
+			return ""
+		case strings.HasPrefix(tok, "-"):
+			continue
+		default:
+			return tok
+		}
+	}
+	return ""
+}
+
+// TrustedScriptFor returns the verification of the trust entry for the
+// script cmd runs, or nil when cmd is not a single command running a trusted
+// script inside projectPath.
+func TrustedScriptFor(database *db.DB, projectPath, cmd, cwd string) (*TrustVerification, error) {
+	normalized := NormalizeCommand(cmd)
+	if normalized.IsCompound && len(normalized.Segments) > 1 {
+		return nil, nil
+	}
+	script := scriptOperand(normalized.Primary)
+	if script == "" || projectPath == "" {
+		return nil, nil
+	}
+	rel, err := ResolveTrustedScriptPath(projectPath, cwd, script)
+	if err != nil {
+		return nil, nil
+	}
+	entry, err := database.ActiveTrustedScript(projectPath, rel)
+	if errors.Is(err, db.ErrTrustedScriptNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v := VerifyTrustedScript(database, entry)
+	return &v, nil
+}
+
+// VerifyTrustedScript checks a trust entry's signature and whether the
+// script still has the vetted contents.
+func VerifyTrustedScript(database *db.DB, entry *db.TrustedScript) TrustVerification {
+	v := TrustVerification{Entry: entry}
+	if !entry.Active() {
+		v.Status = TrustRevoked
+		return v
+	}
+	sess, err := database.GetSession(entry.SessionID)
+	if err != nil || !db.VerifyTrustSignature(sess.SessionKey, entry) {
+		v.Status = TrustBadSignature
+		v.Detail = "signature does not verify against the trusting session"
+		return v
+	}
+	sum, err := HashScript(filepath.Join(entry.ProjectPath, filepath.FromSlash(entry.Path)))
+	if err != nil {
+		v.Status = TrustMissing
+		v.Detail = err.Error()
+		return v
+	}
+	v.CurrentSHA256 = sum
+	if sum != entry.SHA256 {
+		v.Status = TrustModified
+		v.Detail = "script changed since it was trusted"
+		return v
+	}
+	v.Status = TrustVerified
+	return v
+}
+
+// TrustTable verifies each of a project's trust entries, ordered by path.
+// Revoked entries are included only when asked.
+func TrustTable(database *db.DB, projectPath string, includeRevoked bool) ([]TrustVerification, error) {
+	entries, err := database.ListTrustedScripts(projectPath, includeRevoked)
+	if err != nil {
+		return nil, err
+	}
+	checks := make([]TrustVerification, 0, len(entries))
+	for _, entry := range entries {
+		checks = append(checks, VerifyTrustedScript(database, entry))
+	}
+	return checks, nil
+}
+
+// lowerTier returns the tier one step below t.
+func lowerTier(t RiskTier) RiskTier {
+	switch t {
+	case RiskTierCritical:
+		return RiskTierDangerous
+	case RiskTierDangerous:
+		return RiskTierCaution
+	default:
+		return RiskTier(RiskSafe)
+	}
+}
+
+// ApplyTrustedScript lowers res one tier, but not below floor, when v holds,
+// and returns a note for the request's tier reason naming the trust entry.
+// A trust entry that no longer holds leaves res unchanged and is reported in
+// the note. res is returned unchanged with an empty note when v is nil.
+func ApplyTrustedScript(res *MatchResult, v *TrustVerification, floor RiskTier) (*MatchResult, string) {
+	if v == nil || res == nil || !res.NeedsApproval || res.MatchedPattern == SelfProtectionPattern {
+		return res, ""
+	}
+	entry := v.Entry
+	if !v.Holds() {
+		return res, fmt.Sprintf("trusted script %s not applied: trust %s is %s", entry.Path, shortTrustID(entry.ID), v.Status)
+	}
+	target := lowerTier(res.Tier)
+	if tierRank(target) < tierRank(floor) {
+		target = floor
+	}
+	if tierRank(target) >= tierRank(res.Tier) {
+		return res, fmt.Sprintf("runs trusted script %s (trust %s); already at the %s floor", entry.Path, shortTrustID(entry.ID), floor)
+	}
+
+	lowered := *res
+	lowered.Tier = target
+	lowered.MinApprovals = tierApprovals(target)
+	lowered.NeedsApproval = target != RiskTier(RiskSafe)
+	lowered.IsSafe = target == RiskTier(RiskSafe)
+	return &lowered, fmt.Sprintf("lowered from %s to %s: runs trusted script %s (trust %s by %s)",
+		res.Tier, target, entry.Path, shortTrustID(entry.ID), entry.AgentName)
+}
+
+// VerifyTrustedScriptUse checks, before execution, that the trust entry that
+// lowered request's tier still holds for the contents seen at creation. It
+// returns the recorded use (nil if the tier was not lowered).
+func VerifyTrustedScriptUse(database *db.DB, request *db.Request) (*db.TrustedScriptUse, error) {
+	use, err := database.GetTrustedScriptUse(request.ID)
+	if err != nil || use == nil {
+		return nil, err
+	}
+	entry, err := database.GetTrustedScript(use.TrustID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTrustedScriptChanged, err)
+	}
+	v := VerifyTrustedScript(database, entry)
+	if !v.Holds() {
+		return nil, fmt.Errorf("%w: %s is %s", ErrTrustedScriptChanged, entry.Path, v.Status)
+	}
+	if v.CurrentSHA256 != use.SHA256 {
+		return nil, fmt.Errorf("%w: %s changed since the request was created", ErrTrustedScriptChanged, entry.Path)
+	}
+	return use, nil
+}
+
+func shortTrustID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
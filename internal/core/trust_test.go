@@ -0,0 +1,234 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// writeTrustedScript writes rel under project and records a signed trust
+// entry for its current contents.
+func writeTrustedScript(t *testing.T, database *db.DB, session *db.Session, project, rel, body string) *db.TrustedScript {
+	t.Helper()
+	path := filepath.Join(project, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := HashScript(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	entry := &db.TrustedScript{
+		ProjectPath: project,
+		Path:        rel,
+		SHA256:      sum,
+		SessionID:   session.ID,
+		AgentName:   session.AgentName,
+		Signature:   db.ComputeTrustSignature(session.SessionKey, project, rel, sum, now),
+		CreatedAt:   now,
+	}
+	if err := database.CreateTrustedScript(entry); err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+func TestScriptOperand(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{"./ops/restart.sh --force", "./ops/restart.sh"},
+		{"ops/restart.sh", "ops/restart.sh"},
+		{"bash ops/restart.sh staging", "ops/restart.sh"},
+		{"bash -x ops/restart.sh", "ops/restart.sh"},
+		{"python3 tools/migrate.py", "tools/migrate.py"},
+		{"bash -c 'rm -rf /tmp/x'", ""},
+		{"python -m http.server", ""},
+		{"restart.sh", ""},
+		{"rm -rf ./build", ""},
+		{"bash", ""},
+	}
+	for _, tt := range tests {
+		if got := scriptOperand(tt.cmd); got != tt.want {
+			t.Errorf("scriptOperand(%q) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestResolveTrustedScriptPath(t *testing.T) {
+	project := t.TempDir()
+	outside := t.TempDir()
+	for _, p := range []string{filepath.Join(project, "ops", "restart.sh"), filepath.Join(outside, "evil.sh")} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(outside, "evil.sh"), filepath.Join(project, "ops", "link.sh")); err != nil {
+		t.Fatal(err)
+	}
+
+	rel, err := ResolveTrustedScriptPath(project, filepath.Join(project, "ops"), "./restart.sh")
+	if err != nil || rel != "ops/restart.sh" {
+		t.Errorf("expected ops/restart.sh, got %q, %v", rel, err)
+	}
+	if _, err := ResolveTrustedScriptPath(project, project, "ops/link.sh"); err == nil || !strings.Contains(err.Error(), "outside the project") {
+		t.Errorf("expected a symlink out of the project to be rejected, got %v", err)
+	}
+	if _, err := ResolveTrustedScriptPath(project, project, "ops"); err == nil {
+		t.Error("expected a directory to be rejected")
+	}
+	if _, err := ResolveTrustedScriptPath(project, project, "missing.sh"); err == nil {
+		t.Error("expected a missing script to be rejected")
+	}
+}
+
+func TestApplyTrustedScript(t *testing.T) {
+	entry := &db.TrustedScript{ID: "0123456789", Path: "ops/restart.sh", AgentName: "GreenLake"}
+	verified := &TrustVerification{Entry: entry, Status: TrustVerified}
+	modified := &TrustVerification{Entry: entry, Status: TrustModified}
+	result := func(tier RiskTier) *MatchResult {
+		return &MatchResult{Tier: tier, NeedsApproval: true, MinApprovals: tierApprovals(tier)}
+	}
+
+	tests := []struct {
+		name     string
+		res      *MatchResult
+		v        *TrustVerification
+		floor    RiskTier
+		wantTier RiskTier
+		wantNote string
+	}{
+		{"dangerous to caution", result(RiskTierDangerous), verified, RiskTierCaution, RiskTierCaution, "lowered from dangerous to caution: runs trusted script ops/restart.sh (trust 01234567 by GreenLake)"},
+		{"critical to dangerous", result(RiskTierCritical), verified, RiskTierCaution, RiskTierDangerous, "lowered from critical to dangerous"},
+		{"caution stays at the floor", result(RiskTierCaution), verified, RiskTierCaution, RiskTierCaution, "already at the caution floor"},
+		{"safe floor", result(RiskTierCaution), verified, RiskTier(RiskSafe), RiskTier(RiskSafe), "lowered from caution to safe"},
+		{"dangerous floor", result(RiskTierCritical), verified, RiskTierDangerous, RiskTierDangerous, "lowered from critical to dangerous"},
+		{"modified script", result(RiskTierDangerous), modified, RiskTierCaution, RiskTierDangerous, "not applied: trust 01234567 is modified"},
+		{"no trust entry", result(RiskTierDangerous), nil, RiskTierCaution, RiskTierDangerous, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, note := ApplyTrustedScript(tt.res, tt.v, tt.floor)
+			if res.Tier != tt.wantTier {
+				t.Errorf("tier = %s, want %s", res.Tier, tt.wantTier)
+			}
+			if !strings.Contains(note, tt.wantNote) || (tt.wantNote == "") != (note == "") {
+				t.Errorf("note = %q, want it to contain %q", note, tt.wantNote)
+			}
+			if res.MinApprovals != tierApprovals(res.Tier) || res.NeedsApproval != (res.Tier != RiskTier(RiskSafe)) {
+				t.Errorf("inconsistent result %+v", res)
+			}
+		})
+	}
+}
+
+func TestCreateRequest_TrustedScript(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	project := t.TempDir()
+	session := testutil.MakeSession(t, database, testutil.SessionWithAgentName("agent1"), testutil.WithProject(project))
+	entry := writeTrustedScript(t, database, session, project, "ops/restart.sh", "#!/bin/sh\nsystemctl restart app\n")
+
+	rules := []RiskOverrideRule{{Glob: "bash ops/*", Tier: RiskTierDangerous}}
+	cfg := DefaultRequestCreatorConfig()
+	cfg.RiskOverrides = rules
+	creator := NewRequestCreator(database, nil, nil, cfg)
+	create := func() *CreateRequestResult {
+		t.Helper()
+		result, err := creator.CreateRequest(CreateRequestOptions{
+			SessionID:     session.ID,
+			Command:       "bash ops/restart.sh",
+			Cwd:           project,
+			Justification: Justification{Reason: "restart"},
+		})
+		if err != nil {
+			t.Fatalf("CreateRequest: %v", err)
+		}
+		if result.Request == nil {
+			t.Fatalf("expected a request, got %+v", result)
+		}
+		return result
+	}
+
+	result := create()
+	if !result.Trust.Holds() || result.Request.RiskTier != RiskTierCaution {
+		t.Fatalf("expected the trusted script to lower the tier to caution, got %s (%+v)", result.Request.RiskTier, result.Trust)
+	}
+	stored, err := database.GetRequest(result.Request.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "lowered from dangerous to caution: runs trusted script ops/restart.sh"; !strings.Contains(stored.TierReason, want) {
+		t.Errorf("tier reason %q does not name the trust entry", stored.TierReason)
+	}
+	use, err := database.GetTrustedScriptUse(result.Request.ID)
+	if err != nil || use == nil || use.TrustID != entry.ID {
+		t.Fatalf("expected the trust use to be recorded, got %+v, %v", use, err)
+	}
+
+	if err := database.UpdateRequestStatus(result.Request.ID, db.StatusApproved); err != nil {
+		t.Fatal(err)
+	}
+	exec := NewExecutor(database, nil).WithRiskOverrides(rules)
+	if ok, reason := exec.CanExecute(result.Request.ID); !ok {
+		t.Fatalf("expected the lowered request to be executable, got %q", reason)
+	}
+
+	// Editing the script voids the trust for the approved request and for
+	// new requests.
+	if err := os.WriteFile(filepath.Join(project, "ops", "restart.sh"), []byte("#!/bin/sh\nrm -rf /\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if ok, reason := exec.CanExecute(result.Request.ID); ok || !strings.Contains(reason, ErrTrustedScriptChanged.Error()) {
+		t.Errorf("expected execution to be refused after the script changed, got %v %q", ok, reason)
+	}
+	voided := create()
+	if voided.Trust == nil || voided.Trust.Status != TrustModified || voided.Request.RiskTier != RiskTierDangerous {
+		t.Errorf("expected a modified script to keep the dangerous tier, got %s (%+v)", voided.Request.RiskTier, voided.Trust)
+	}
+	if use, _ := database.GetTrustedScriptUse(voided.Request.ID); use != nil {
+		t.Errorf("expected no trust use for the voided request, got %+v", use)
+	}
+}
+
+func TestTrustTable(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	project := t.TempDir()
+	session := testutil.MakeSession(t, database, testutil.WithProject(project))
+	writeTrustedScript(t, database, session, project, "a.sh", "echo a\n")
+	writeTrustedScript(t, database, session, project, "b.sh", "echo b\n")
+	forged := writeTrustedScript(t, database, session, project, "c.sh", "echo c\n")
+	if err := os.Remove(filepath.Join(project, "b.sh")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec(`UPDATE trusted_scripts SET signature = 'forged' WHERE id = ?`, forged.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	checks, err := TrustTable(database, project, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]TrustStatus{}
+	for _, c := range checks {
+		got[c.Entry.Path] = c.Status
+	}
+	want := map[string]TrustStatus{"a.sh": TrustVerified, "b.sh": TrustMissing, "c.sh": TrustBadSignature}
+	for path, status := range want {
+		if got[path] != status {
+			t.Errorf("%s: status %q, want %q", path, got[path], status)
+		}
+	}
+}
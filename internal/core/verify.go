@@ -0,0 +1,115 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// ReviewVerification is the result of checking one review of a request.
+type ReviewVerification struct {
+	BundleSignature
+	// CommandHash is the command hash the review signed over; empty for
+	// reviews signed before command hashes were.
+	CommandHash string `json:"command_hash,omitempty"`
+	// HashMatches reports whether CommandHash is the request's hash. It is
+	// true for reviews without one.
+	HashMatches bool `json:"hash_matches"`
+}
+
+// CommandVerification is the outcome of VerifyRequestCommand.
+type CommandVerification struct {
+	RequestID string `json:"request_id"`
+	// StoredHash is the hash recorded when the request was created.
+	StoredHash string `json:"stored_hash"`
+	// ComputedHash is the hash of the command as it stands now.
+	ComputedHash string               `json:"computed_hash"`
+	HashMatches  bool                 `json:"hash_matches"`
+	Reviews      []ReviewVerification `json:"reviews,omitempty"`
+	Problems     []string             `json:"problems,omitempty"`
+}
+
+// OK reports whether the request verified without problems.
+func (v *CommandVerification) OK() bool {
+	return len(v.Problems) == 0
+}
+
+// VerifyRequestCommand checks that request's command still matches the hash
+// recorded when it was created, that each of reviews signed over that hash,
+// and that each review signature verifies against its reviewer's session key.
+func VerifyRequestCommand(database *db.DB, request *db.Request, reviews []*db.Review) *CommandVerification {
+	v := &CommandVerification{
+		RequestID:    request.ID,
+		StoredHash:   request.Command.Hash,
+		ComputedHash: db.ComputeCommandHash(request.Command),
+		HashMatches:  db.CommandHashMatches(request.Command),
+	}
+	if !v.HashMatches {
+		v.Problems = append(v.Problems, fmt.Sprintf("command hashes to %s, but %s was recorded at creation", v.ComputedHash, v.StoredHash))
+	}
+
+	signatures := CheckReviewSignatures(database, reviews)
+	for i, r := range reviews {
+		rv := ReviewVerification{
+			BundleSignature: signatures[i],
+			CommandHash:     r.CommandHash,
+			HashMatches:     r.CommandHash == "" || r.CommandHash == request.Command.Hash,
+		}
+		if !rv.HashMatches {
+			v.Problems = append(v.Problems, fmt.Sprintf("%s by %s signed command hash %s, not %s",
+				r.Decision, r.ReviewerAgent, r.CommandHash, request.Command.Hash))
+		}
+		if rv.Result != SignatureValid {
+			v.Problems = append(v.Problems, fmt.Sprintf("%s by %s: signature is %s", r.Decision, r.ReviewerAgent, rv.Result))
+		}
+		v.Reviews = append(v.Reviews, rv)
+	}
+	return v
+}
+
+// verifyApprovedCommand refuses to run a request whose command no longer
+// matches the hash its approvers signed over, recording an audit event.
+// Only approvals created before reviews signed command hashes may lack one;
+// a later approval without a hash is refused.
+func verifyApprovedCommand(database *db.DB, request *db.Request) error {
+	reviews, err := database.ListReviewsForRequest(request.ID)
+	if err != nil {
+		return fmt.Errorf("listing reviews: %w", err)
+	}
+	hashedSince, err := database.MigrationAppliedAt(db.ReviewCommandHashVersion)
+	if err != nil {
+		return err
+	}
+	var approvals []*db.Review
+	var unhashed []string
+	for _, r := range reviews {
+		if r.Decision != db.DecisionApprove {
+			continue
+		}
+		if r.CommandHash == "" {
+			if !hashedSince.IsZero() && r.CreatedAt.Before(hashedSince) {
+				continue
+			}
+			unhashed = append(unhashed, fmt.Sprintf("approve by %s signed no command hash", r.ReviewerAgent))
+			continue
+		}
+		approvals = append(approvals, r)
+	}
+	v := VerifyRequestCommand(database, request, approvals)
+	v.Problems = append(unhashed, v.Problems...)
+	if v.OK() {
+		return nil
+	}
+
+	detail := strings.Join(v.Problems, "; ")
+	// Best effort: the refusal stands even if the event cannot be recorded.
+	_ = database.RecordAuditEvent(&db.AuditEvent{
+		RequestID:   request.ID,
+		ProjectPath: request.ProjectPath,
+		Event:       db.AuditEventCommandHashMismatch,
+		Detail:      detail,
+	})
+	return fmt.Errorf("%w: %s; the command may have been altered after approval and will not be executed",
+		ErrCommandHashMismatch, detail)
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// approvedRequest creates a request for echo and approves it through the
+// review service, so the approval signs over the command hash.
+func approvedRequest(t *testing.T, database *db.DB) *db.Request {
+	t.Helper()
+	dir := t.TempDir()
+	requestor := testutil.MakeSession(t, database, testutil.WithProject(dir))
+	reviewer := testutil.MakeSession(t, database, testutil.WithProject(dir))
+	req := testutil.MakeRequest(t, database, requestor, func(r *db.Request) {
+		r.RiskTier = db.RiskTierCaution
+		r.Command = db.CommandSpec{Raw: "echo hello", Cwd: dir, Argv: []string{"echo", "hello"}}
+	})
+	result, err := NewReviewService(database, DefaultReviewConfig()).SubmitReview(ReviewOptions{
+		SessionID:  reviewer.ID,
+		SessionKey: reviewer.SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+	})
+	if err != nil || result.NewRequestStatus != db.StatusApproved {
+		t.Fatalf("SubmitReview = %+v, %v", result, err)
+	}
+	if result.Review.CommandHash != req.Command.Hash {
+		t.Fatalf("review signed hash %q, want %q", result.Review.CommandHash, req.Command.Hash)
+	}
+	return req
+}
+
+func TestExecute_RefusesCommandMutatedAfterApproval(t *testing.T) {
+	mutations := []struct {
+		name   string
+		column string
+		value  any
+		mutate func(*db.CommandSpec)
+	}{
+		{"raw", "command_raw", "echo goodbye", func(c *db.CommandSpec) { c.Raw = "echo goodbye" }},
+		{"cwd", "command_cwd", "/", func(c *db.CommandSpec) { c.Cwd = "/" }},
+		{"argv", "command_argv_json", `["echo","goodbye"]`, func(c *db.CommandSpec) { c.Argv = []string{"echo", "goodbye"} }},
+		{"shell", "command_shell", 1, func(c *db.CommandSpec) { c.Shell = true }},
+	}
+
+	for _, m := range mutations {
+		// A tamperer may leave the stored hash alone, or rewrite it to match;
+		// the approval's signed hash catches the second.
+		for _, rewriteHash := range []bool{false, true} {
+			name := m.name
+			if rewriteHash {
+				name += " with rewritten hash"
+			}
+			t.Run(name, func(t *testing.T) {
+				database := testutil.NewTestDB(t)
+				req := approvedRequest(t, database)
+
+				if _, err := database.Exec(`UPDATE requests SET `+m.column+` = ? WHERE id = ?`, m.value, req.ID); err != nil {
+					t.Fatal(err)
+				}
+				if rewriteHash {
+					mutated := req.Command
+					m.mutate(&mutated)
+					if _, err := database.Exec(`UPDATE requests SET command_hash = ? WHERE id = ?`, db.ComputeCommandHash(mutated), req.ID); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				_, err := NewExecutor(database, nil).ExecuteApprovedRequest(context.Background(), ExecuteOptions{
+					RequestID: req.ID,
+					SessionID: req.RequestorSessionID,
+					LogDir:    t.TempDir(),
+				})
+				if !errors.Is(err, ErrCommandHashMismatch) {
+					t.Fatalf("expected ErrCommandHashMismatch, got %v", err)
+				}
+
+				events, err := database.ListAuditEvents(req.ID)
+				if err != nil || len(events) != 1 || events[0].Event != db.AuditEventCommandHashMismatch {
+					t.Fatalf("expected a command_hash_mismatch audit event, got %+v, %v", events, err)
+				}
+				if got, _ := database.GetRequest(req.ID); got.Status != db.StatusApproved {
+					t.Errorf("expected the request to stay approved, got %s", got.Status)
+				}
+			})
+		}
+	}
+}
+
+func TestVerifyRequestCommand(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	req := approvedRequest(t, database)
+
+	request, reviews, err := database.GetRequestWithReviews(req.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := VerifyRequestCommand(database, request, reviews)
+	if !v.OK() || !v.HashMatches || len(v.Reviews) != 1 || v.Reviews[0].Result != SignatureValid {
+		t.Fatalf("expected an untouched request to verify, got %+v", v)
+	}
+
+	// Rewriting the review's signed hash breaks its signature.
+	argv, _ := json.Marshal([]string{"rm", "-rf", "/"})
+	tampered := db.ComputeCommandHash(db.CommandSpec{Raw: "rm -rf /", Cwd: request.Command.Cwd, Argv: []string{"rm", "-rf", "/"}})
+	if _, err := database.Exec(`UPDATE requests SET command_raw = ?, command_argv_json = ?, command_hash = ? WHERE id = ?`,
+		"rm -rf /", string(argv), tampered, req.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec(`UPDATE reviews SET command_hash = ? WHERE request_id = ?`, tampered, req.ID); err != nil {
+		t.Fatal(err)
+	}
+	request, reviews, _ = database.GetRequestWithReviews(req.ID)
+	v = VerifyRequestCommand(database, request, reviews)
+	if v.OK() || v.Reviews[0].Result != SignatureInvalid || !strings.Contains(strings.Join(v.Problems, "\n"), "signature is invalid") {
+		t.Errorf("expected the rewritten review to fail its signature, got %+v", v)
+	}
+}
+
+func TestVerifyApprovedCommand_UnhashedApprovals(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	req := approvedRequest(t, database)
+	if _, err := database.Exec(`UPDATE reviews SET command_hash = '' WHERE request_id = ?`, req.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The approval was made after reviews started signing the hash.
+	if err := verifyApprovedCommand(database, req); !errors.Is(err, ErrCommandHashMismatch) || !strings.Contains(err.Error(), "signed no command hash") {
+		t.Fatalf("expected an unhashed approval refused, got %v", err)
+	}
+
+	// Moving the migration past the approval makes it one from before hashes
+	// were signed.
+	later := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	if _, err := database.Exec(`UPDATE schema_migrations SET applied_at = ? WHERE version = ?`, later, db.ReviewCommandHashVersion); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyApprovedCommand(database, req); err != nil {
+		t.Fatalf("expected a pre-migration approval to pass, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// changeRecordRetryBase is the delay after the first failed delivery; it
+	// doubles with each further failure up to changeRecordRetryMax.
+	changeRecordRetryBase = 30 * time.Second
+	changeRecordRetryMax  = time.Hour
+	// changeRecordBatch caps deliveries per pass so a long backlog does not
+	// hold the database.
+	changeRecordBatch = 50
+)
+
+// ChangeRecordPoster delivers an encoded change record.
+type ChangeRecordPoster interface {
+	Post(ctx context.Context, url string, body []byte) error
+}
+
+// DeliveryResult counts the outcome of one delivery pass.
+type DeliveryResult struct {
+	Delivered int `json:"delivered"`
+	Failed    int `json:"failed"`
+}
+
+// changeRecordRetryDelay returns how long to wait after the given number of
+// failed attempts.
+func changeRecordRetryDelay(attempts int) time.Duration {
+	delay := changeRecordRetryBase
+	for i := 1; i < attempts && delay < changeRecordRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > changeRecordRetryMax {
+		delay = changeRecordRetryMax
+	}
+	return delay
+}
+
+// DeliverChangeRecords posts queued change records that are due and records
+// each outcome. A record stays queued until its receiver answers 2xx, so
+// delivery is at least once.
+func DeliverChangeRecords(ctx context.Context, database *db.DB, poster ChangeRecordPoster, now time.Time) (DeliveryResult, error) {
+	return deliverNotifications(ctx, database, db.NotificationKindChangeRecord, poster, now)
+}
+
+// deliverNotifications posts the due notifications of one kind, marking each
+// delivered or scheduling its retry.
+func deliverNotifications(ctx context.Context, database *db.DB, kind string, poster ChangeRecordPoster, now time.Time) (DeliveryResult, error) {
+	var result DeliveryResult
+	due, err := database.ListDueNotifications(kind, now, changeRecordBatch)
+	if err != nil {
+		return result, err
+	}
+	for _, n := range due {
+		postCtx, cancel := context.WithTimeout(ctx, WebhookTimeout)
+		postErr := poster.Post(postCtx, n.URL, []byte(n.Payload))
+		cancel()
+		if postErr != nil {
+			result.Failed++
+			next := now.Add(changeRecordRetryDelay(n.Attempts + 1))
+			if err := database.MarkNotificationFailed(n.ID, postErr.Error(), next); err != nil {
+				return result, err
+			}
+			continue
+		}
+		result.Delivered++
+		if err := database.MarkNotificationDelivered(n.ID, now); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// ChangeRecordDispatcher delivers a project's change records from the
+// daemon. Each pass also queues records for requests executed since the
+// dispatcher started that have none, such as executions completed through
+// the daemon rather than by slb itself.
+type ChangeRecordDispatcher struct {
+	db          *db.DB
+	projectPath string
+	cfg         config.IntegrationsConfig
+	logger      *log.Logger
+	poster      ChangeRecordPoster
+	now         func() time.Time
+	since       time.Time
+}
+
+// NewChangeRecordDispatcher creates a dispatcher over a writable project
+// database. It does nothing unless cfg.ChangeRecordURL is set.
+func NewChangeRecordDispatcher(database *db.DB, projectPath string, cfg config.IntegrationsConfig, logger *log.Logger) *ChangeRecordDispatcher {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &ChangeRecordDispatcher{
+		db:          database,
+		projectPath: projectPath,
+		cfg:         cfg,
+		logger:      logger,
+		poster:      NewDefaultWebhookNotifier(),
+		now:         time.Now,
+		since:       time.Now().UTC(),
+	}
+}
+
+// WithPoster sets a custom poster (for testing).
+func (d *ChangeRecordDispatcher) WithPoster(p ChangeRecordPoster) *ChangeRecordDispatcher {
+	d.poster = p
+	return d
+}
+
+// Run delivers change records every interval until ctx is done.
+func (d *ChangeRecordDispatcher) Run(ctx context.Context, interval time.Duration) {
+	if d == nil || d.cfg.ChangeRecordURL == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	// Deliver anything left over from before a restart straight away.
+	_ = d.Check(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = d.Check(ctx)
+		}
+	}
+}
+
+// Check runs one pass: queue missing records, then deliver due ones.
+func (d *ChangeRecordDispatcher) Check(ctx context.Context) error {
+	if d == nil || d.cfg.ChangeRecordURL == "" {
+		return nil
+	}
+	now := d.now().UTC()
+
+	if _, err := core.EnqueueMissingChangeRecords(d.db, d.projectPath, d.since, d.cfg.ChangeRecordURL, d.cfg.ChangeRecordTiers, now); err != nil {
+		d.logger.Warn("queueing change records failed", "project", d.projectPath, "error", err)
+	}
+
+	result, err := DeliverChangeRecords(ctx, d.db, d.poster, now)
+	if err != nil {
+		d.logger.Warn("delivering change records failed", "project", d.projectPath, "error", err)
+		return err
+	}
+	if result.Failed > 0 {
+		d.logger.Warn("change record delivery failed; will retry",
+			"project", d.projectPath,
+			"failed", result.Failed,
+			"delivered", result.Delivered)
+	} else if result.Delivered > 0 {
+		d.logger.Debug("change records delivered", "project", d.projectPath, "delivered", result.Delivered)
+	}
+	return nil
+}
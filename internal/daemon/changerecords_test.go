@@ -0,0 +1,112 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+type fakePoster struct {
+	err    error
+	bodies []string
+}
+
+func (p *fakePoster) Post(_ context.Context, _ string, body []byte) error {
+	p.bodies = append(p.bodies, string(body))
+	return p.err
+}
+
+func TestChangeRecordRetryDelay(t *testing.T) {
+	tests := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		4:  4 * time.Minute,
+		20: time.Hour,
+	}
+	for attempts, want := range tests {
+		if got := changeRecordRetryDelay(attempts); got != want {
+			t.Errorf("changeRecordRetryDelay(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestDeliverChangeRecords_RetriesUntilAccepted(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	now := time.Now().UTC()
+	n := &db.UndeliveredNotification{
+		Kind:      db.NotificationKindChangeRecord,
+		RequestID: "req-1",
+		URL:       "https://cmdb.example.com/hook",
+		Payload:   `{"event":"request_executed"}`,
+		CreatedAt: now,
+	}
+	if _, err := database.EnqueueNotification(n); err != nil {
+		t.Fatal(err)
+	}
+
+	poster := &fakePoster{err: errors.New("connection refused")}
+	result, err := DeliverChangeRecords(context.Background(), database, poster, now)
+	if err != nil || result.Failed != 1 || result.Delivered != 0 {
+		t.Fatalf("expected one failure, got %+v, %v", result, err)
+	}
+	// Not retried before its backoff elapses.
+	if result, _ := DeliverChangeRecords(context.Background(), database, poster, now.Add(time.Second)); result.Failed+result.Delivered != 0 {
+		t.Fatalf("expected no attempt during backoff, got %+v", result)
+	}
+
+	poster.err = nil
+	result, err = DeliverChangeRecords(context.Background(), database, poster, now.Add(changeRecordRetryBase))
+	if err != nil || result.Delivered != 1 {
+		t.Fatalf("expected delivery after backoff, got %+v, %v", result, err)
+	}
+	if len(poster.bodies) != 2 || poster.bodies[1] != n.Payload {
+		t.Errorf("expected the stored payload to be posted twice, got %q", poster.bodies)
+	}
+	stored, _ := database.GetNotification(db.NotificationKindChangeRecord, "req-1")
+	if stored == nil || stored.DeliveredAt == nil || stored.Attempts != 2 {
+		t.Errorf("expected a delivered record after two attempts, got %+v", stored)
+	}
+}
+
+func TestChangeRecordDispatcher_PostsToWebhook(t *testing.T) {
+	var got []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	database := testutil.NewTestDB(t)
+	cfg := config.IntegrationsConfig{ChangeRecordURL: server.URL, ChangeRecordTiers: []string{"critical"}}
+	if _, err := database.EnqueueNotification(&db.UndeliveredNotification{
+		Kind:      db.NotificationKindChangeRecord,
+		RequestID: "req-queued-before-restart",
+		URL:       server.URL,
+		Payload:   `{"request_id":"req-queued-before-restart"}`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewChangeRecordDispatcher(database, t.TempDir(), cfg, nil)
+	if err := d.Check(context.Background()); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if string(got) != `{"request_id":"req-queued-before-restart"}` {
+		t.Errorf("unexpected body %q", got)
+	}
+	stored, _ := database.GetNotification(db.NotificationKindChangeRecord, "req-queued-before-restart")
+	if stored == nil || stored.DeliveredAt == nil {
+		t.Errorf("expected the record to be delivered, got %+v", stored)
+	}
+}
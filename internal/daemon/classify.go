@@ -0,0 +1,174 @@
+// Package daemon provides batch command classification over IPC.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+)
+
+// MaxClassifyBatch is the most commands one classify call accepts, keeping
+// requests and responses within the IPC line limit.
+const MaxClassifyBatch = 1000
+
+// ClassifyItem is one command to classify.
+type ClassifyItem struct {
+	Command string `json:"command"`
+	Cwd     string `json:"cwd,omitempty"`
+}
+
+// ClassifyParams are parameters for the classify method.
+type ClassifyParams struct {
+	Commands []ClassifyItem `json:"commands"`
+}
+
+// ClassifySegment is a matched segment of a compound command.
+type ClassifySegment struct {
+	Segment        string `json:"segment"`
+	Tier           string `json:"tier"`
+	MatchedPattern string `json:"matched_pattern"`
+}
+
+// ClassifyVerdict is the classification of one command.
+type ClassifyVerdict struct {
+	Command         string            `json:"command"`
+	Tier            string            `json:"tier,omitempty"`
+	MatchedPattern  string            `json:"matched_pattern,omitempty"`
+	MinApprovals    int               `json:"min_approvals"`
+	NeedsApproval   bool              `json:"needs_approval"`
+	IsSafe          bool              `json:"is_safe"`
+	ParseError      bool              `json:"parse_error,omitempty"`
+	MatchedSegments []ClassifySegment `json:"matched_segments,omitempty"`
+}
+
+// ClassifyResult is the result of the classify method: one verdict per
+// command, in request order.
+type ClassifyResult struct {
+	Verdicts []ClassifyVerdict `json:"verdicts"`
+}
+
+// NewClassifyVerdict converts a classification into its wire form.
+func NewClassifyVerdict(command string, m *core.MatchResult) ClassifyVerdict {
+	v := ClassifyVerdict{
+		Command:        command,
+		Tier:           string(m.Tier),
+		MatchedPattern: m.MatchedPattern,
+		MinApprovals:   m.MinApprovals,
+		NeedsApproval:  m.NeedsApproval,
+		IsSafe:         m.IsSafe,
+		ParseError:     m.ParseError,
+	}
+	for _, seg := range m.MatchedSegments {
+		v.MatchedSegments = append(v.MatchedSegments, ClassifySegment{
+			Segment:        seg.Segment,
+			Tier:           string(seg.Tier),
+			MatchedPattern: seg.MatchedPattern,
+		})
+	}
+	return v
+}
+
+// MatchResult converts a verdict back into the classification it came from.
+func (v ClassifyVerdict) MatchResult() *core.MatchResult {
+	m := &core.MatchResult{
+		Tier:           core.RiskTier(v.Tier),
+		MatchedPattern: v.MatchedPattern,
+		MinApprovals:   v.MinApprovals,
+		NeedsApproval:  v.NeedsApproval,
+		IsSafe:         v.IsSafe,
+		ParseError:     v.ParseError,
+	}
+	for _, seg := range v.MatchedSegments {
+		m.MatchedSegments = append(m.MatchedSegments, core.SegmentMatch{
+			Segment:        seg.Segment,
+			Tier:           core.RiskTier(seg.Tier),
+			MatchedPattern: seg.MatchedPattern,
+		})
+	}
+	return m
+}
+
+// handleClassify classifies a batch of commands with the daemon's compiled
+// pattern engine, sparing callers process startup and pattern compilation.
+func (s *IPCServer) handleClassify(req RPCRequest) *RPCResponse {
+	var params ClassifyParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return &RPCResponse{
+			Error: &Error{Code: ErrCodeInvalidParams, Message: "invalid params: " + err.Error()},
+			ID:    req.ID,
+		}
+	}
+
+	if len(params.Commands) == 0 {
+		return &RPCResponse{
+			Error: &Error{Code: ErrCodeInvalidParams, Message: "commands is required"},
+			ID:    req.ID,
+		}
+	}
+	if len(params.Commands) > MaxClassifyBatch {
+		return &RPCResponse{
+			Error: &Error{Code: ErrCodeInvalidParams, Message: fmt.Sprintf("at most %d commands per call", MaxClassifyBatch)},
+			ID:    req.ID,
+		}
+	}
+
+	engine := core.GetDefaultEngine()
+	result := ClassifyResult{Verdicts: make([]ClassifyVerdict, len(params.Commands))}
+	for i, item := range params.Commands {
+		if item.Command == "" {
+			return &RPCResponse{
+				Error: &Error{Code: ErrCodeInvalidParams, Message: fmt.Sprintf("commands[%d]: command is required", i)},
+				ID:    req.ID,
+			}
+		}
+		result.Verdicts[i] = NewClassifyVerdict(item.Command, engine.ClassifyCommand(item.Command, item.Cwd))
+	}
+
+	return &RPCResponse{
+		Result: result,
+		ID:     req.ID,
+	}
+}
+
+// Classify asks the daemon to classify commands with its compiled patterns.
+// Verdicts are returned in the order of items. The context's deadline, if
+// any, also bounds the round trip.
+func (c *IPCClient) Classify(ctx context.Context, items []ClassifyItem) ([]ClassifyVerdict, error) {
+	if err := c.Connect(ctx); err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		c.mu.Lock()
+		err := c.conn.SetDeadline(deadline)
+		c.mu.Unlock()
+		if err != nil {
+			return nil, fmt.Errorf("set deadline: %w", err)
+		}
+	}
+
+	resp, err := c.call("classify", ClassifyParams{Commands: items})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("classify error: %s", resp.Error.Message)
+	}
+
+	data, err := json.Marshal(resp.Result)
+	if err != nil {
+		return nil, fmt.Errorf("marshal result: %w", err)
+	}
+
+	var result ClassifyResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal classify result: %w", err)
+	}
+	if len(result.Verdicts) != len(items) {
+		return nil, fmt.Errorf("classify returned %d verdicts for %d commands", len(result.Verdicts), len(items))
+	}
+
+	return result.Verdicts, nil
+}
@@ -0,0 +1,155 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/charmbracelet/log"
+)
+
+// classifyBatch is a mix of safe, caution, dangerous, critical, compound and
+// unmatched commands.
+var classifyBatch = []ClassifyItem{
+	{Command: "ls -la", Cwd: "/tmp"},
+	{Command: "rm build.log"},
+	{Command: "rm ./notes.txt"},
+	{Command: "git reset --hard HEAD~1", Cwd: "/repo"},
+	{Command: "rm -rf /etc/nginx"},
+	{Command: "echo ok && kubectl delete namespace prod"},
+	{Command: "psql -c 'DROP TABLE users'"},
+	{Command: "make test"},
+}
+
+// startClassifyServer starts an IPC server and returns a connected client.
+func startClassifyServer(tb testing.TB) *IPCClient {
+	tb.Helper()
+
+	socketPath := filepath.Join(shortSocketDir(tb), "c.sock")
+	srv, err := NewIPCServer(socketPath, log.New(io.Discard))
+	if err != nil {
+		tb.Fatalf("NewIPCServer: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _ = srv.Start(ctx) }()
+
+	client := NewIPCClient(socketPath)
+	tb.Cleanup(func() {
+		_ = client.Close()
+		cancel()
+		_ = srv.Stop()
+	})
+
+	connectCtx, connectCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer connectCancel()
+	if err := client.Ping(connectCtx); err != nil {
+		tb.Fatalf("Ping: %v", err)
+	}
+	return client
+}
+
+func TestIPCClient_Classify(t *testing.T) {
+	client := startClassifyServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	verdicts, err := client.Classify(ctx, classifyBatch)
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+
+	for i, item := range classifyBatch {
+		want := core.Classify(item.Command, item.Cwd)
+		got := verdicts[i]
+		if got.Command != item.Command {
+			t.Errorf("verdicts[%d].Command = %q, want %q (order not preserved)", i, got.Command, item.Command)
+		}
+		if !reflect.DeepEqual(got.MatchResult(), want) {
+			t.Errorf("verdicts[%d] for %q = %+v, want %+v", i, item.Command, got.MatchResult(), want)
+		}
+	}
+}
+
+func TestIPCClient_Classify_InvalidBatch(t *testing.T) {
+	client := startClassifyServer(t)
+
+	tests := []struct {
+		name    string
+		items   []ClassifyItem
+		wantErr string
+	}{
+		{"empty batch", nil, "commands is required"},
+		{"empty command", []ClassifyItem{{Command: "ls"}, {Command: ""}}, "commands[1]: command is required"},
+		{"too many", make([]ClassifyItem, MaxClassifyBatch+1), "at most"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			_, err := client.Classify(ctx, tt.items)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Classify() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// benchmarkBatch returns n commands cycling through classifyBatch.
+func benchmarkBatch(n int) []ClassifyItem {
+	items := make([]ClassifyItem, n)
+	for i := range items {
+		items[i] = classifyBatch[i%len(classifyBatch)]
+	}
+	return items
+}
+
+// BenchmarkClassifyBatch100_ColdStart classifies 100 commands the way a
+// wrapper calling the CLI per command does: one slb process each.
+func BenchmarkClassifyBatch100_ColdStart(b *testing.B) {
+	bin := filepath.Join(b.TempDir(), "slb")
+	if out, err := exec.Command("go", "build", "-o", bin, "github.com/Dicklesworthstone/slb/cmd/slb").CombinedOutput(); err != nil {
+		b.Skipf("building slb: %v\n%s", err, out)
+	}
+	items := benchmarkBatch(100)
+	for b.Loop() {
+		for _, item := range items {
+			if out, err := exec.Command(bin, "check", "--local", "-j", item.Command).CombinedOutput(); err != nil {
+				b.Fatalf("slb check %q: %v\n%s", item.Command, err, out)
+			}
+		}
+	}
+}
+
+// BenchmarkClassifyBatch100_InProcess compiles a pattern engine and
+// classifies 100 commands with it, the work each cold start repeats.
+func BenchmarkClassifyBatch100_InProcess(b *testing.B) {
+	items := benchmarkBatch(100)
+	b.ReportAllocs()
+	for b.Loop() {
+		engine := core.NewPatternEngine()
+		for _, item := range items {
+			engine.ClassifyCommand(item.Command, item.Cwd)
+		}
+	}
+}
+
+// BenchmarkClassifyBatch100_Server classifies the same 100 commands in one
+// classify call to a running daemon.
+func BenchmarkClassifyBatch100_Server(b *testing.B) {
+	client := startClassifyServer(b)
+	items := benchmarkBatch(100)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := client.Classify(ctx, items); err != nil {
+			b.Fatalf("Classify: %v", err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package daemon provides startup connectivity checks for configured integrations.
+package daemon
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/integrations"
+	"github.com/charmbracelet/log"
+)
+
+// ConfiguredIntegrations returns a connectivity check for each integration
+// enabled in cfg.
+func ConfiguredIntegrations(projectPath string, cfg config.Config) []integrations.Pinger {
+	var pingers []integrations.Pinger
+	if cfg.Integrations.AgentMailEnabled {
+		pingers = append(pingers, integrations.NewAgentMailClient(projectPath, cfg.Integrations.AgentMailThread, ""))
+	}
+	if url := strings.TrimSpace(cfg.Notifications.WebhookURL); url != "" {
+		pingers = append(pingers, NewWebhookIntegration(url))
+	}
+	return pingers
+}
+
+// logIntegrationStatus pings each integration and logs the outcome. An
+// unreachable integration is a warning only; notifications to it will fail
+// but the daemon keeps serving.
+func logIntegrationStatus(ctx context.Context, logger *log.Logger, pingers []integrations.Pinger) []integrations.PingResult {
+	results := integrations.CheckConnectivity(ctx, pingers, 0)
+	for _, r := range results {
+		if r.Reachable {
+			logger.Info("integration reachable", "integration", r.Name, "latency_ms", r.LatencyMS)
+		} else {
+			logger.Warn("integration unreachable", "integration", r.Name, "error", r.Error)
+		}
+	}
+	return results
+}
@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/integrations"
+	"github.com/charmbracelet/log"
+)
+
+// stubIntegration is an integration with a canned connectivity outcome.
+type stubIntegration struct {
+	name string
+	err  error
+	hang bool
+}
+
+func (s stubIntegration) Name() string { return s.name }
+
+func (s stubIntegration) Ping(ctx context.Context) error {
+	if s.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return s.err
+}
+
+// syncBuffer is a log sink safe to read while the daemon writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogIntegrationStatus(t *testing.T) {
+	var out syncBuffer
+	results := logIntegrationStatus(context.Background(), log.New(&out), []integrations.Pinger{
+		stubIntegration{name: "mail"},
+		stubIntegration{name: "hook", err: errors.New("connection refused")},
+	})
+	if len(results) != 2 || !results[0].Reachable || results[1].Reachable {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	logs := out.String()
+	if !strings.Contains(logs, "integration reachable") || !strings.Contains(logs, "integration unreachable") || !strings.Contains(logs, "connection refused") {
+		t.Errorf("expected both outcomes logged, got %q", logs)
+	}
+}
+
+func TestRunDaemon_UnreachableIntegrationDoesNotBlockStartup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket tests not supported on windows")
+	}
+
+	project := shortSocketDir(t)
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(project); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(origWD) })
+
+	socketPath := filepath.Join(project, "daemon.sock")
+	var logs syncBuffer
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- RunDaemon(ctx, ServerOptions{
+			SocketPath: socketPath,
+			PIDFile:    filepath.Join(project, "daemon.pid"),
+			Logger:     log.New(&logs),
+			Integrations: []integrations.Pinger{
+				stubIntegration{name: "down", err: errors.New("connection refused")},
+				stubIntegration{name: "stuck", hang: true},
+			},
+		})
+	}()
+
+	// The daemon must answer while the stuck integration is still pending.
+	var pingErr error
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		pctx, pcancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		client := NewIPCClient(socketPath)
+		pingErr = client.Ping(pctx)
+		_ = client.Close()
+		pcancel()
+		if pingErr == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if pingErr != nil {
+		t.Fatalf("daemon did not start: %v", pingErr)
+	}
+
+	// Results are logged once the stuck check times out.
+	deadline = time.Now().Add(integrations.DefaultPingTimeout + 2*time.Second)
+	for !strings.Contains(logs.String(), "integration unreachable") && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), "integration=down") {
+		t.Errorf("expected the failing integration to be logged, got %q", logs.String())
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("RunDaemon: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("daemon did not stop in time")
+	}
+}
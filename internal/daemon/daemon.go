@@ -6,6 +6,7 @@ package daemon
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"os"
 	"os/exec"
@@ -17,7 +18,9 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/integrations"
 	"github.com/Dicklesworthstone/slb/internal/utils"
 	"github.com/charmbracelet/log"
 )
@@ -29,6 +32,12 @@ type ServerOptions struct {
 	SocketPath string
 	PIDFile    string
 	Logger     *log.Logger
+	// Integrations overrides the integrations checked at startup (for
+	// testing); nil checks those enabled in the project config.
+	Integrations []integrations.Pinger
+	// HTTPAddr, when set, also serves the HTTP API for remote reviewers
+	// on this address (see HTTPServer). The Unix socket is always served.
+	HTTPAddr string
 }
 
 // DefaultServerOptions returns defaults aligned with the daemon client.
@@ -164,6 +173,38 @@ func RunDaemon(ctx context.Context, opts ServerOptions) error {
 	notifications := NewNotificationManager(projectPath, cfg.Notifications, logger, nil)
 	go notifications.Run(signalCtx, 10*time.Second)
 
+	// Check integrations in the background so a slow or unreachable one
+	// never delays startup.
+	pingers := opts.Integrations
+	if pingers == nil {
+		pingers = ConfiguredIntegrations(projectPath, cfg)
+	}
+	go logIntegrationStatus(signalCtx, logger, pingers)
+
+	// The timeout reaper expires stale requests and emits sla_breach events.
+	// Each registered project (the daemon's own and any workspace members)
+	// gets its own reaper over its own state database.
+	ipcServer.SetProjectRegistrar(func(path string) error {
+		_, err := startProjectReaper(signalCtx, path, ipcServer, logger)
+		return err
+	})
+	// Registration over IPC needs an active session from the registered
+	// project or from a served project whose workspace contains it.
+	ipcServer.SetProjectAuthorizer(func(p RegisterProjectParams) error {
+		return authorizeProjectRegistration(ipcServer.Projects(), p)
+	})
+	projects := []string{projectPath}
+	if ws, err := config.FindWorkspace(projectPath); err != nil {
+		logger.Warn("failed to load workspace", "error", err)
+	} else if ws != nil {
+		projects = append([]string{ws.Root}, ws.Members...)
+	}
+	for _, p := range projects {
+		if _, err := ipcServer.RegisterProject(p); err != nil {
+			logger.Warn("timeout reaper disabled", "project", p, "error", err)
+		}
+	}
+
 	servers := []*IPCServer{ipcServer}
 	if strings.TrimSpace(cfg.Daemon.TCPAddr) != "" {
 		tcpSrv, err := NewTCPServer(TCPServerOptions{
@@ -193,18 +234,39 @@ func RunDaemon(ctx context.Context, opts ServerOptions) error {
 		if err != nil {
 			logger.Warn("tcp listener disabled", "error", err)
 		} else {
+			tcpSrv.ShareProjects(ipcServer)
 			servers = append(servers, tcpSrv)
 			logger.Info("tcp listener started", "addr", cfg.Daemon.TCPAddr, "require_auth", cfg.Daemon.TCPRequireAuth)
 		}
 	}
 
-	errCh := make(chan error, len(servers))
+	var httpSrv *HTTPServer
+	if strings.TrimSpace(opts.HTTPAddr) != "" {
+		httpSrv, err = NewHTTPServer(HTTPServerOptions{Addr: opts.HTTPAddr, Events: ipcServer}, logger)
+		if err != nil {
+			for _, srv := range servers {
+				_ = srv.Stop()
+			}
+			return err
+		}
+		logger.Info("http api started", "addr", httpSrv.Addr())
+	}
+
+	errCh := make(chan error, len(servers)+1)
 	for _, srv := range servers {
 		srv := srv
 		go func() {
 			errCh <- srv.Start(signalCtx)
 		}()
 	}
+	if httpSrv != nil {
+		go func() {
+			if err := httpSrv.Start(signalCtx); err != nil {
+				errCh <- fmt.Errorf("http api: %w", err)
+			}
+		}()
+		defer httpSrv.Stop()
+	}
 
 	select {
 	case <-signalCtx.Done():
@@ -233,6 +295,166 @@ func RunDaemon(ctx context.Context, opts ServerOptions) error {
 	}
 }
 
+// authorizeProjectRegistration checks a register_project call. The session
+// must be active and its key must match, either in the registered project's
+// own database or in a served project whose workspace lists it as the root or
+// a member.
+func authorizeProjectRegistration(served []string, p RegisterProjectParams) error {
+	target := filepath.Clean(p.ProjectPath)
+	candidates := append([]string{target}, served...)
+	for _, project := range candidates {
+		sess, err := activeProjectSession(project, p.SessionID)
+		if err != nil {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(p.SessionKey), []byte(sess.SessionKey)) != 1 {
+			return core.ErrSessionKeyMismatch
+		}
+		if project == target {
+			return nil
+		}
+		ws, err := config.FindWorkspace(project)
+		if err != nil || ws == nil {
+			continue
+		}
+		for _, member := range append([]string{ws.Root}, ws.Members...) {
+			if filepath.Clean(member) == target {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("session %s is not active in %s or its workspace", p.SessionID, target)
+}
+
+// activeProjectSession loads an active session from a project's state database.
+func activeProjectSession(projectPath, sessionID string) (*db.Session, error) {
+	dbConn, err := db.OpenWithOptions(filepath.Join(projectPath, ".slb", "state.db"), db.OpenOptions{
+		CreateIfNotExists: false,
+		InitSchema:        false,
+		ReadOnly:          true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer dbConn.Close()
+	sess, err := dbConn.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !sess.IsActive() {
+		return nil, core.ErrSessionInactive
+	}
+	return sess, nil
+}
+
+// startProjectReaper starts the monitoring reaper for one project, wiring SLA
+// breaches to IPC subscribers and the project's webhook, the project's
+// request sweeper, and its change-record dispatcher when one is configured.
+// The sweeper moves expired requests to timeout or cancels stale approvals,
+// but timeout_action is never applied. The reaper stops and its database
+// closes when ctx is done.
+func startProjectReaper(ctx context.Context, projectPath string, ipcServer *IPCServer, logger *log.Logger) (*TimeoutHandler, error) {
+	cfg := config.DefaultConfig()
+	if loaded, err := config.Load(config.LoadOptions{ProjectDir: projectPath}); err != nil {
+		logger.Warn("failed to load config; using defaults", "project", projectPath, "error", err)
+	} else {
+		cfg = loaded
+	}
+
+	reaperDB, err := db.OpenWithOptions(filepath.Join(projectPath, ".slb", "state.db"), db.OpenOptions{
+		CreateIfNotExists: false,
+		InitSchema:        false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := NewNotificationManager(projectPath, cfg.Notifications, logger, nil)
+	timeoutCfg := TimeoutConfigFromConfig(cfg)
+	timeoutCfg.Logger = logger
+	timeoutCfg.OnSLABreach = func(b SLABreach) {
+		cmd := b.Request.Command.DisplayRedacted
+		if cmd == "" {
+			cmd = b.Request.Command.Raw
+		}
+		ipcServer.BroadcastEvent(string(WebhookEventSLABreach), map[string]any{
+			"request_id":      b.Request.ID,
+			"project_path":    b.Request.ProjectPath,
+			"risk_tier":       string(b.Request.RiskTier),
+			"command":         cmd,
+			"requestor":       b.Request.RequestorAgent,
+			"pending_seconds": int64(b.Pending.Seconds()),
+			"sla_seconds":     int64(b.SLA.Seconds()),
+		})
+		_ = notifications.SendWebhook(ctx, WebhookEventSLABreach, b.Request)
+	}
+	timeoutCfg.ProjectPath = projectPath
+	timeoutCfg.PolicyHash = func() (string, error) {
+		current, err := config.Load(config.LoadOptions{ProjectDir: projectPath})
+		if err != nil {
+			return "", err
+		}
+		return config.PolicyHash(current), nil
+	}
+	timeoutCfg.OnAttestationDue = func(c db.AttestationCheck) {
+		data := map[string]any{
+			"project_path": projectPath,
+			"state":        string(c.State),
+			"policy_hash":  c.PolicyHash,
+			"refusing":     c.Refuse,
+		}
+		if !c.DueAt.IsZero() {
+			data["due_at"] = c.DueAt.Format(time.RFC3339)
+		}
+		ipcServer.BroadcastEvent(PolicyAttestationDueEvent, data)
+	}
+	timeoutCfg.OnReviewerPattern = func(s core.ReviewerStats) {
+		ipcServer.BroadcastEvent(core.ReviewerPatternWarningEvent, map[string]any{
+			"agent":                   s.Agent,
+			"warnings":                s.Warnings,
+			"median_approval_seconds": s.MedianApprovalSeconds,
+			"empty_approval_percent":  s.EmptyApprovalPercent,
+			"current_approval_streak": s.CurrentStreak,
+			"excluded_from_critical":  s.ExcludedFromCritical,
+		})
+	}
+	reaper := NewTimeoutHandler(reaperDB, timeoutCfg)
+	if err := reaper.Start(ctx); err != nil {
+		reaperDB.Close()
+		return nil, err
+	}
+	sweeper := NewRequestSweeper(reaperDB, projectPath, ipcServer, logger)
+	escalation := EscalationPolicyFromConfig(cfg)
+	escalation.Notify = func(req *db.Request) {
+		if !NotifiesStatus(cfg.Notifications.NotifyStatuses, db.StatusEscalated) {
+			return
+		}
+		if cfg.Notifications.DesktopEnabled {
+			_ = SendDesktopNotification(
+				fmt.Sprintf("SLB: Request Escalated (%s)", req.RiskTier),
+				fmt.Sprintf("Request %s is still pending.\nCommand: %s\nAgent: %s", shortID(req.ID), webhookCommand(req), req.RequestorAgent))
+		}
+		_ = notifications.SendWebhook(ctx, WebhookEventRequestEscalated, req)
+	}
+	sweeper.SetEscalation(escalation)
+	sweeper.SetReviewConfig(ReviewConfigFromConfig(cfg))
+	sweeper.SetSessionIdle(cfg.Agents.SessionIdleTimeout())
+	sweeper.SetQueueAdmission(core.NewRequestCreator(reaperDB, core.NewRateLimiter(reaperDB, RateLimitConfigFromConfig(cfg)), nil, nil))
+	go sweeper.Run(ctx, timeoutCfg.CheckInterval)
+	if cfg.Integrations.ChangeRecordURL != "" {
+		go NewChangeRecordDispatcher(reaperDB, projectPath, cfg.Integrations, logger).Run(ctx, 10*time.Second)
+	}
+	if len(cfg.Notifications.WebhookURLs) > 0 {
+		go NewLifecycleWebhookDispatcher(reaperDB, projectPath, cfg.Notifications, logger).Run(ctx, 5*time.Second)
+	}
+	go func() {
+		<-ctx.Done()
+		reaper.Stop()
+		reaperDB.Close()
+	}()
+	return reaper, nil
+}
+
 func normalizeServerOptions(opts ServerOptions) ServerOptions {
 	if strings.TrimSpace(opts.SocketPath) == "" {
 		opts.SocketPath = DefaultSocketPath()
@@ -10,6 +10,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
 	"github.com/charmbracelet/log"
 )
 
@@ -421,3 +424,46 @@ func TestStartDaemonWithOptions_AlreadyRunning(t *testing.T) {
 		t.Error("expected error when daemon already running")
 	}
 }
+
+func TestAuthorizeProjectRegistration(t *testing.T) {
+	root := testutil.NewHarness(t)
+	member := filepath.Join(root.ProjectDir, "api")
+	if err := os.MkdirAll(member, 0750); err != nil {
+		t.Fatalf("mkdir member: %v", err)
+	}
+	if err := config.WriteWorkspace(&config.Workspace{Root: root.ProjectDir, Members: []string{member}}); err != nil {
+		t.Fatalf("WriteWorkspace: %v", err)
+	}
+	outsider := testutil.NewHarness(t)
+
+	sess := testutil.MakeSession(t, root.DB, testutil.WithProject(root.ProjectDir))
+	served := []string{root.ProjectDir}
+	params := func(project, key string) RegisterProjectParams {
+		return RegisterProjectParams{ProjectPath: project, SessionID: sess.ID, SessionKey: key}
+	}
+
+	if err := authorizeProjectRegistration(served, params(member, sess.SessionKey)); err != nil {
+		t.Errorf("workspace member should be authorized: %v", err)
+	}
+	if err := authorizeProjectRegistration(served, params(member, "wrong")); !errors.Is(err, core.ErrSessionKeyMismatch) {
+		t.Errorf("expected key mismatch, got %v", err)
+	}
+	if err := authorizeProjectRegistration(served, params(outsider.ProjectDir, sess.SessionKey)); err == nil {
+		t.Error("project outside the session's workspace should be refused")
+	}
+
+	// A session from the registered project's own database is enough.
+	own := testutil.MakeSession(t, outsider.DB, testutil.WithProject(outsider.ProjectDir))
+	if err := authorizeProjectRegistration(served, RegisterProjectParams{
+		ProjectPath: outsider.ProjectDir, SessionID: own.ID, SessionKey: own.SessionKey,
+	}); err != nil {
+		t.Errorf("session in the registered project should be authorized: %v", err)
+	}
+
+	if err := root.DB.EndSession(sess.ID); err != nil {
+		t.Fatalf("EndSession: %v", err)
+	}
+	if err := authorizeProjectRegistration(served, params(member, sess.SessionKey)); err == nil {
+		t.Error("ended session should be refused")
+	}
+}
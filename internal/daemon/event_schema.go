@@ -0,0 +1,127 @@
+package daemon
+
+import "fmt"
+
+// Event schema versions for the NDJSON stream written by 'slb watch'.
+//
+// Compatibility policy: once released, a schema version's shape is frozen.
+// Its fields keep their names, types and meanings, and no field is added to
+// or removed from it. Changes to the shape ship as a new version, which
+// consumers opt into with --event-schema; older versions stay available and
+// are produced by translating from the current RequestStreamEvent. The
+// default stays at EventSchemaV1 so existing consumers see no change.
+const (
+	// EventSchemaV1 is the original shape: no schema_version and no
+	// expired_at field.
+	EventSchemaV1 = 1
+	// EventSchemaV2 adds schema_version to every event and expired_at to
+	// request_timeout and request_approval_expired events.
+	EventSchemaV2 = 2
+	// EventSchemaV3 adds has_preview to request_pending events.
+	EventSchemaV3 = 3
+
+	// CurrentEventSchema is the newest version, the shape RequestStreamEvent
+	// carries internally.
+	CurrentEventSchema = EventSchemaV3
+	// DefaultEventSchema is the version watch emits without --event-schema.
+	DefaultEventSchema = EventSchemaV1
+)
+
+// requestStreamEventV1 is the frozen EventSchemaV1 shape.
+type requestStreamEventV1 struct {
+	Event      string `json:"event"`
+	RequestID  string `json:"request_id,omitempty"`
+	Project    string `json:"project,omitempty"`
+	RiskTier   string `json:"risk_tier,omitempty"`
+	Command    string `json:"command,omitempty"`
+	Requestor  string `json:"requestor,omitempty"`
+	ApprovedBy string `json:"approved_by,omitempty"`
+	RejectedBy string `json:"rejected_by,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	CreatedAt  string `json:"created_at,omitempty"`
+	ExecutedAt string `json:"executed_at,omitempty"`
+}
+
+// requestStreamEventV2 is the frozen EventSchemaV2 shape.
+type requestStreamEventV2 struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	Event         string `json:"event"`
+	RequestID     string `json:"request_id,omitempty"`
+	Project       string `json:"project,omitempty"`
+	RiskTier      string `json:"risk_tier,omitempty"`
+	Command       string `json:"command,omitempty"`
+	Requestor     string `json:"requestor,omitempty"`
+	ApprovedBy    string `json:"approved_by,omitempty"`
+	RejectedBy    string `json:"rejected_by,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	ExitCode      *int   `json:"exit_code,omitempty"`
+	CreatedAt     string `json:"created_at,omitempty"`
+	ExecutedAt    string `json:"executed_at,omitempty"`
+	ExpiredAt     string `json:"expired_at,omitempty"`
+}
+
+// ValidateEventSchema reports an error for a version this build cannot emit.
+func ValidateEventSchema(version int) error {
+	if version < EventSchemaV1 || version > CurrentEventSchema {
+		return fmt.Errorf("unknown event schema version %d (supported: %d-%d)", version, EventSchemaV1, CurrentEventSchema)
+	}
+	return nil
+}
+
+// Versioned returns e in the wire shape of the given schema version, ready
+// for JSON encoding.
+func (e RequestStreamEvent) Versioned(version int) (any, error) {
+	if err := ValidateEventSchema(version); err != nil {
+		return nil, err
+	}
+	switch version {
+	case EventSchemaV1:
+		return requestStreamEventV1{
+			Event:      e.Event,
+			RequestID:  e.RequestID,
+			Project:    e.Project,
+			RiskTier:   e.RiskTier,
+			Command:    e.Command,
+			Requestor:  e.Requestor,
+			ApprovedBy: e.ApprovedBy,
+			RejectedBy: e.RejectedBy,
+			Reason:     e.Reason,
+			ExitCode:   e.ExitCode,
+			CreatedAt:  e.CreatedAt,
+			ExecutedAt: e.ExecutedAt,
+		}, nil
+	case EventSchemaV2:
+		return requestStreamEventV2{
+			SchemaVersion: version,
+			Event:         e.Event,
+			RequestID:     e.RequestID,
+			Project:       e.Project,
+			RiskTier:      e.RiskTier,
+			Command:       e.Command,
+			Requestor:     e.Requestor,
+			ApprovedBy:    e.ApprovedBy,
+			RejectedBy:    e.RejectedBy,
+			Reason:        e.Reason,
+			ExitCode:      e.ExitCode,
+			CreatedAt:     e.CreatedAt,
+			ExecutedAt:    e.ExecutedAt,
+			ExpiredAt:     e.ExpiredAt,
+		}, nil
+	}
+	e.SchemaVersion = version
+	return e, nil
+}
+
+// VersionedMap stamps an ad hoc watch event (auto_approve_error and the like)
+// for the given schema version: from EventSchemaV2 on it carries
+// schema_version like every RequestStreamEvent.
+func VersionedMap(event map[string]any, version int) (map[string]any, error) {
+	if err := ValidateEventSchema(version); err != nil {
+		return nil, err
+	}
+	if version >= EventSchemaV2 {
+		event["schema_version"] = version
+	}
+	return event, nil
+}
@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func marshalVersioned(t *testing.T, e RequestStreamEvent, version int) map[string]any {
+	t.Helper()
+	wire, err := e.Versioned(version)
+	if err != nil {
+		t.Fatalf("Versioned(%d): %v", version, err)
+	}
+	data, err := json.Marshal(wire)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRequestStreamEvent_VersionedShapes(t *testing.T) {
+	e := *ToRequestStreamEvent(Event{
+		Type: "request_timeout",
+		Time: 1700000000,
+		Payload: map[string]any{
+			"request_id": "req-1",
+			"risk_tier":  "dangerous",
+			"expired_at": "2023-11-14T22:13:20Z",
+		},
+	})
+	if e.SchemaVersion != CurrentEventSchema {
+		t.Fatalf("expected the internal event at schema %d, got %d", CurrentEventSchema, e.SchemaVersion)
+	}
+
+	v1 := marshalVersioned(t, e, EventSchemaV1)
+	for _, key := range []string{"schema_version", "expired_at"} {
+		if _, ok := v1[key]; ok {
+			t.Errorf("v1 must not carry %q: %v", key, v1)
+		}
+	}
+
+	v2 := marshalVersioned(t, e, EventSchemaV2)
+	if v2["schema_version"] != float64(EventSchemaV2) {
+		t.Errorf("expected schema_version 2, got %v", v2["schema_version"])
+	}
+	if v2["expired_at"] != "2023-11-14T22:13:20Z" {
+		t.Errorf("expected expired_at in v2, got %v", v2["expired_at"])
+	}
+
+	// Both versions agree on every v1 field.
+	for key, want := range v1 {
+		if v2[key] != want {
+			t.Errorf("field %q: v1 %v, v2 %v", key, want, v2[key])
+		}
+	}
+}
+
+func TestRequestStreamEvent_VersionedUnknown(t *testing.T) {
+	for _, version := range []int{0, -1, CurrentEventSchema + 1} {
+		if _, err := (RequestStreamEvent{Event: "request_pending"}).Versioned(version); err == nil ||
+			!strings.Contains(err.Error(), "unknown event schema version") {
+			t.Errorf("version %d: expected an unknown version error, got %v", version, err)
+		}
+		if _, err := VersionedMap(map[string]any{"event": "auto_approve_error"}, version); err == nil {
+			t.Errorf("version %d: expected VersionedMap to reject it", version)
+		}
+	}
+}
+
+func TestVersionedMap(t *testing.T) {
+	v1, _ := VersionedMap(map[string]any{"event": "auto_execute_error"}, EventSchemaV1)
+	if _, ok := v1["schema_version"]; ok {
+		t.Errorf("v1 must not carry schema_version: %v", v1)
+	}
+	v2, _ := VersionedMap(map[string]any{"event": "auto_execute_error"}, EventSchemaV2)
+	if v2["schema_version"] != EventSchemaV2 {
+		t.Errorf("expected schema_version 2, got %v", v2)
+	}
+}
+
+func TestRequestStreamEvent_HasPreviewOnlyInV3(t *testing.T) {
+	e := *ToRequestStreamEvent(Event{
+		Type:    "request_pending",
+		Time:    1700000000,
+		Payload: map[string]any{"request_id": "req-1", "has_preview": false},
+	})
+	if e.HasPreview == nil || *e.HasPreview {
+		t.Fatalf("expected has_preview false from the payload, got %v", e.HasPreview)
+	}
+
+	v3 := marshalVersioned(t, e, EventSchemaV3)
+	if v3["has_preview"] != false || v3["schema_version"] != float64(EventSchemaV3) {
+		t.Errorf("expected has_preview false at schema 3, got %v", v3)
+	}
+	for _, version := range []int{EventSchemaV1, EventSchemaV2} {
+		if _, ok := marshalVersioned(t, e, version)["has_preview"]; ok {
+			t.Errorf("v%d must not carry has_preview", version)
+		}
+	}
+	if v2 := marshalVersioned(t, e, EventSchemaV2); v2["schema_version"] != float64(EventSchemaV2) {
+		t.Errorf("expected schema_version 2, got %v", v2["schema_version"])
+	}
+}
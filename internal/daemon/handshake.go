@@ -0,0 +1,168 @@
+// Package daemon implements the IPC version and feature handshake.
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// ProtocolVersion is the major version of the IPC protocol. Peers with
+// different majors cannot talk; anything added within a major is announced
+// as a feature so older peers can be detected and worked around.
+const ProtocolVersion = 1
+
+// Version is the slb version reported in the handshake. The CLI sets it from
+// its build information.
+var Version = "dev"
+
+// Features a daemon may support, negotiated in the hello handshake.
+const (
+	// FeatureFilters means subscribe honors SubscribeParams filters.
+	FeatureFilters = "filters"
+	// FeatureReplay means subscribe can replay recent events.
+	FeatureReplay = "replay"
+	// FeaturePublish means notify broadcasts events to subscribers.
+	FeaturePublish = "publish"
+)
+
+// ErrCodeIncompatible is returned by hello when the peers' protocol majors
+// differ. The error data is the daemon's HelloResult.
+const ErrCodeIncompatible = -32002
+
+// SupportedFeatures returns the features this build of the daemon supports.
+func SupportedFeatures() []string {
+	return []string{FeatureFilters, FeatureReplay, FeaturePublish}
+}
+
+// legacyFeatures are what a daemon that predates the handshake supports.
+var legacyFeatures = []string{FeaturePublish}
+
+// HelloParams are parameters for the hello method, sent by the client on connect.
+type HelloParams struct {
+	Version  string   `json:"version"`
+	Protocol int      `json:"protocol"`
+	Features []string `json:"features,omitempty"`
+}
+
+// HelloResult describes the daemon side of the handshake.
+type HelloResult struct {
+	Version  string   `json:"version"`
+	Protocol int      `json:"protocol"`
+	Features []string `json:"features"`
+}
+
+// PeerInfo describes the daemon a client negotiated with.
+type PeerInfo struct {
+	Version  string   `json:"version"`
+	Protocol int      `json:"protocol"`
+	Features []string `json:"features"`
+	// Legacy is set for daemons that predate the handshake.
+	Legacy bool `json:"legacy,omitempty"`
+}
+
+// Has reports whether the daemon supports feature.
+func (p *PeerInfo) Has(feature string) bool {
+	return p != nil && slices.Contains(p.Features, feature)
+}
+
+// legacyPeer is assumed for daemons that do not know the hello method.
+func legacyPeer() *PeerInfo {
+	return &PeerInfo{Version: "unknown", Protocol: ProtocolVersion, Features: legacyFeatures, Legacy: true}
+}
+
+// IncompatibleProtocolError reports a daemon whose protocol major differs
+// from this client's.
+type IncompatibleProtocolError struct {
+	ClientProtocol int
+	DaemonProtocol int
+	DaemonVersion  string
+}
+
+func (e *IncompatibleProtocolError) Error() string {
+	if e.DaemonProtocol < e.ClientProtocol {
+		return fmt.Sprintf("daemon %s speaks IPC protocol v%d but this slb (%s) needs v%d: upgrade the daemon (slb daemon stop && slb daemon start)",
+			e.DaemonVersion, e.DaemonProtocol, Version, e.ClientProtocol)
+	}
+	return fmt.Sprintf("daemon %s speaks IPC protocol v%d but this slb (%s) only speaks v%d: upgrade slb",
+		e.DaemonVersion, e.DaemonProtocol, Version, e.ClientProtocol)
+}
+
+// handleHello answers the version and feature handshake. Clients with a
+// different protocol major are refused with ErrCodeIncompatible; the daemon
+// does not otherwise track what a connection negotiated, since every later
+// feature is opt-in per call.
+func (s *IPCServer) handleHello(req RPCRequest) *RPCResponse {
+	var params HelloParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return &RPCResponse{
+				Error: &Error{Code: ErrCodeInvalidParams, Message: "invalid params: " + err.Error()},
+				ID:    req.ID,
+			}
+		}
+	}
+
+	result := HelloResult{
+		Version:  Version,
+		Protocol: ProtocolVersion,
+		Features: SupportedFeatures(),
+	}
+	if params.Protocol != ProtocolVersion {
+		return &RPCResponse{
+			Error: &Error{
+				Code: ErrCodeIncompatible,
+				Message: fmt.Sprintf("incompatible protocol: client %s speaks v%d, daemon %s speaks v%d",
+					params.Version, params.Protocol, Version, ProtocolVersion),
+				Data: result,
+			},
+			ID: req.ID,
+		}
+	}
+	return &RPCResponse{Result: result, ID: req.ID}
+}
+
+// peerFromHello interprets the daemon's reply to hello. A daemon that does
+// not know the method predates the handshake and is treated as legacy.
+func peerFromHello(resp *RPCResponse) (*PeerInfo, error) {
+	if resp.Error != nil {
+		switch resp.Error.Code {
+		case ErrCodeMethodNotFound:
+			return legacyPeer(), nil
+		case ErrCodeIncompatible:
+			var daemon HelloResult
+			if err := remarshal(resp.Error.Data, &daemon); err != nil {
+				return nil, fmt.Errorf("hello error: %s", resp.Error.Message)
+			}
+			return nil, &IncompatibleProtocolError{
+				ClientProtocol: ProtocolVersion,
+				DaemonProtocol: daemon.Protocol,
+				DaemonVersion:  daemon.Version,
+			}
+		default:
+			return nil, fmt.Errorf("hello error: %s", resp.Error.Message)
+		}
+	}
+
+	var result HelloResult
+	if err := remarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal hello: %w", err)
+	}
+	if result.Protocol != ProtocolVersion {
+		return nil, &IncompatibleProtocolError{
+			ClientProtocol: ProtocolVersion,
+			DaemonProtocol: result.Protocol,
+			DaemonVersion:  result.Version,
+		}
+	}
+	return &PeerInfo{Version: result.Version, Protocol: result.Protocol, Features: result.Features}, nil
+}
+
+// remarshal converts a decoded JSON value (e.g. RPCResponse.Result) into v.
+func remarshal(in any, v any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,381 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// daemonFixture is a recorded set of daemon replies (testdata/ipc/*_daemon.json).
+type daemonFixture struct {
+	Responses map[string]json.RawMessage `json:"responses"`
+	Events    []json.RawMessage          `json:"events"`
+}
+
+// fakeDaemon replays a daemonFixture over a unix socket and records the
+// requests it receives.
+type fakeDaemon struct {
+	socketPath string
+	mu         sync.Mutex
+	requests   []RPCRequest
+}
+
+func startFakeDaemon(t *testing.T, fixture string) *fakeDaemon {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "ipc", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var fx daemonFixture
+	if err := json.Unmarshal(data, &fx); err != nil {
+		t.Fatalf("parse fixture: %v", err)
+	}
+
+	fd := &fakeDaemon{socketPath: filepath.Join(shortSocketDir(t), "f.sock")}
+	ln, err := net.Listen("unix", fd.socketPath)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go fd.serve(conn, fx)
+		}
+	}()
+	return fd
+}
+
+func (fd *fakeDaemon) serve(conn net.Conn, fx daemonFixture) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var req RPCRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			return
+		}
+		fd.mu.Lock()
+		fd.requests = append(fd.requests, req)
+		fd.mu.Unlock()
+
+		var resp map[string]any
+		if raw, ok := fx.Responses[req.Method]; ok {
+			_ = json.Unmarshal(raw, &resp)
+		} else {
+			resp = map[string]any{"error": map[string]any{"code": ErrCodeMethodNotFound, "message": "method not found: " + req.Method}}
+		}
+		resp["id"] = req.ID
+		line, _ := json.Marshal(resp)
+		if _, err := conn.Write(append(line, '\n')); err != nil {
+			return
+		}
+		if req.Method == "subscribe" {
+			for _, event := range fx.Events {
+				_, _ = conn.Write(append(append([]byte{}, event...), '\n'))
+			}
+		}
+	}
+}
+
+func (fd *fakeDaemon) request(method string) (RPCRequest, bool) {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	for _, req := range fd.requests {
+		if req.Method == method {
+			return req, true
+		}
+	}
+	return RPCRequest{}, false
+}
+
+func collectEvents(t *testing.T, events <-chan Event, n int) []Event {
+	t.Helper()
+	var got []Event
+	timeout := time.After(2 * time.Second)
+	for len(got) < n {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return got
+			}
+			got = append(got, e)
+		case <-timeout:
+			t.Fatalf("timed out after %d of %d events", len(got), n)
+		}
+	}
+	return got
+}
+
+// New client, legacy daemon: the client works without the handshake and
+// filters in-process instead of relying on the daemon.
+func TestCompat_NewClientLegacyDaemon(t *testing.T) {
+	fd := startFakeDaemon(t, "legacy_daemon.json")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := NewIPCClient(fd.socketPath)
+	var notices bytes.Buffer
+	client.SetNoticeWriter(&notices)
+	defer client.Close()
+
+	peer, err := client.Handshake(ctx)
+	if err != nil {
+		t.Fatalf("Handshake: %v", err)
+	}
+	if !peer.Legacy || !reflect.DeepEqual(peer.Features, []string{FeaturePublish}) {
+		t.Fatalf("peer = %+v, want a legacy peer with publish only", peer)
+	}
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if info, err := client.Status(ctx); err != nil || info.PendingCount != 2 {
+		t.Fatalf("Status = %+v, %v", info, err)
+	}
+	if err := client.Notify(ctx, "request_pending", map[string]any{"request_id": "req-9"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	events, err := client.SubscribeWithOptions(ctx, SubscribeParams{
+		Types:    []string{"request_pending"},
+		Projects: []string{"/srv/app"},
+		Replay:   10,
+	})
+	if err != nil {
+		t.Fatalf("SubscribeWithOptions: %v", err)
+	}
+	got := collectEvents(t, events, 1)
+	if got[0].Type != "request_pending" || eventProject(got[0]) != "/srv/app" {
+		t.Errorf("event = %+v, want the /srv/app pending event", got[0])
+	}
+	select {
+	case e, ok := <-events:
+		if ok {
+			t.Errorf("unexpected event past the filter: %+v", e)
+		}
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if req, _ := fd.request("subscribe"); len(req.Params) != 0 {
+		t.Errorf("subscribe sent params %s to a legacy daemon", req.Params)
+	}
+	lines := strings.Split(strings.TrimSpace(notices.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "filtering in slb") || !strings.Contains(lines[1], "new events only") {
+		t.Errorf("notices = %q, want one line each for filters and replay", notices.String())
+	}
+}
+
+// New client, daemon on another protocol major: every call fails with an
+// error naming the side to upgrade.
+func TestCompat_NewClientIncompatibleDaemon(t *testing.T) {
+	tests := []struct {
+		fixture string
+		daemon  int
+		want    string
+	}{
+		{"future_daemon.json", 2, "upgrade slb"},
+		{"protocol0_daemon.json", 0, "upgrade the daemon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			fd := startFakeDaemon(t, tt.fixture)
+			client := NewIPCClient(fd.socketPath)
+			defer client.Close()
+
+			err := client.Ping(context.Background())
+			var incompatible *IncompatibleProtocolError
+			if !errors.As(err, &incompatible) {
+				t.Fatalf("Ping error = %v, want IncompatibleProtocolError", err)
+			}
+			if incompatible.DaemonProtocol != tt.daemon || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want daemon protocol %d and %q", err, tt.daemon, tt.want)
+			}
+			if _, ok := fd.request("ping"); ok {
+				t.Error("ping was sent after the handshake failed")
+			}
+		})
+	}
+}
+
+func startHandshakeServer(t *testing.T) *IPCServer {
+	t.Helper()
+	srv, err := NewIPCServer(filepath.Join(shortSocketDir(t), "h.sock"), newTestLogger())
+	if err != nil {
+		t.Fatalf("NewIPCServer: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _ = srv.Start(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		_ = srv.Stop()
+	})
+	time.Sleep(50 * time.Millisecond)
+	return srv
+}
+
+func readResponse(t *testing.T, scanner *bufio.Scanner) RPCResponse {
+	t.Helper()
+	if !scanner.Scan() {
+		t.Fatalf("no response: %v", scanner.Err())
+	}
+	var resp RPCResponse
+	if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal %q: %v", scanner.Text(), err)
+	}
+	return resp
+}
+
+// Legacy client, new daemon: recorded requests from a client that never
+// says hello are served as before, and subscriptions stay unfiltered.
+func TestCompat_LegacyClientNewServer(t *testing.T) {
+	srv := startHandshakeServer(t)
+
+	data, err := os.ReadFile(filepath.Join("testdata", "ipc", "legacy_client.jsonl"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var calls, subscribe []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if strings.Contains(line, `"subscribe"`) {
+			subscribe = append(subscribe, line)
+		} else {
+			calls = append(calls, line)
+		}
+	}
+
+	sub, err := net.Dial("unix", srv.socketPath)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer sub.Close()
+	subScanner := bufio.NewScanner(sub)
+	for _, line := range subscribe {
+		if _, err := sub.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if resp := readResponse(t, subScanner); resp.Error != nil {
+			t.Fatalf("%s: %+v", line, resp.Error)
+		}
+	}
+
+	conn, err := net.Dial("unix", srv.socketPath)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for _, line := range calls {
+		if _, err := conn.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if resp := readResponse(t, scanner); resp.Error != nil {
+			t.Fatalf("%s: %+v", line, resp.Error)
+		}
+	}
+
+	_ = sub.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if !subScanner.Scan() {
+		t.Fatalf("no event for the legacy subscriber: %v", subScanner.Err())
+	}
+	var msg struct {
+		Event Event `json:"event"`
+	}
+	if err := json.Unmarshal(subScanner.Bytes(), &msg); err != nil || msg.Event.Type != "request_pending" {
+		t.Fatalf("event = %s (%v)", subScanner.Text(), err)
+	}
+}
+
+// A client on another protocol major is refused with the daemon's version.
+func TestCompat_FutureClientNewServer(t *testing.T) {
+	srv := startHandshakeServer(t)
+	conn, err := net.Dial("unix", srv.socketPath)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(`{"method":"hello","params":{"version":"2.0.0","protocol":2,"features":["filters","interactive_review"]},"id":1}` + "\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	resp := readResponse(t, bufio.NewScanner(conn))
+	if resp.Error == nil || resp.Error.Code != ErrCodeIncompatible {
+		t.Fatalf("response = %+v, want ErrCodeIncompatible", resp)
+	}
+	var daemon HelloResult
+	if err := remarshal(resp.Error.Data, &daemon); err != nil || daemon.Protocol != ProtocolVersion {
+		t.Errorf("error data = %+v (%v), want the daemon's hello result", resp.Error.Data, err)
+	}
+}
+
+// New client, new daemon: all features are negotiated and applied by the
+// daemon, including replay of events sent before subscribing.
+func TestCompat_NewClientNewServer(t *testing.T) {
+	srv := startHandshakeServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	publisher := NewIPCClient(srv.socketPath)
+	defer publisher.Close()
+	for _, e := range []struct{ typ, project string }{
+		{"request_pending", "/srv/app"},
+		{"request_approved", "/srv/app"},
+		{"request_pending", "/srv/other"},
+	} {
+		if err := publisher.Notify(ctx, e.typ, map[string]any{"project_path": e.project}); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+
+	client := NewIPCClient(srv.socketPath)
+	var notices bytes.Buffer
+	client.SetNoticeWriter(&notices)
+	defer client.Close()
+
+	peer, err := client.Handshake(ctx)
+	if err != nil {
+		t.Fatalf("Handshake: %v", err)
+	}
+	if peer.Legacy || !reflect.DeepEqual(peer.Features, SupportedFeatures()) {
+		t.Fatalf("peer = %+v, want every supported feature", peer)
+	}
+
+	events, err := client.SubscribeWithOptions(ctx, SubscribeParams{
+		Types:    []string{"request_pending"},
+		Projects: []string{"/srv/app"},
+		Replay:   10,
+	})
+	if err != nil {
+		t.Fatalf("SubscribeWithOptions: %v", err)
+	}
+	got := collectEvents(t, events, 1)
+	if got[0].Type != "request_pending" || eventProject(got[0]) != "/srv/app" {
+		t.Errorf("replayed event = %+v, want the /srv/app pending event", got[0])
+	}
+
+	if err := publisher.Notify(ctx, "request_pending", map[string]any{"project_path": "/srv/other"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if err := publisher.Notify(ctx, "request_pending", map[string]any{"project_path": "/srv/app", "request_id": "live"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	got = collectEvents(t, events, 1)
+	if p, _ := got[0].Payload.(map[string]any); p["request_id"] != "live" {
+		t.Errorf("live event = %+v, want only the matching one", got[0])
+	}
+	if notices.Len() != 0 {
+		t.Errorf("unexpected notices: %q", notices.String())
+	}
+}
@@ -0,0 +1,445 @@
+package daemon
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/charmbracelet/log"
+)
+
+// httpKeepAlive is how often the events stream sends an SSE comment so
+// proxies do not close an idle connection.
+const httpKeepAlive = 15 * time.Second
+
+// HTTPToken returns the bearer token for a session on the HTTP API:
+// "<session_id>.<hex HMAC-SHA256(session_key, "slb-http-api:" + session_id)>".
+// The key itself never crosses the network.
+func HTTPToken(sessionID, sessionKey string) string {
+	key, _ := hex.DecodeString(sessionKey)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("slb-http-api:" + sessionID))
+	return sessionID + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+// HTTPServerOptions configures the HTTP API listener for remote reviewers.
+type HTTPServerOptions struct {
+	Addr string
+	// Events is the IPC server whose events /events mirrors; its registered
+	// projects are the projects the API serves.
+	Events *IPCServer
+}
+
+// HTTPServer serves a small JSON API over a project's requests for reviewers
+// on other machines. Every call needs a bearer token (see HTTPToken) for an
+// active session in one of the served projects, and only sees that project.
+type HTTPServer struct {
+	addr     string
+	listener net.Listener
+	server   *http.Server
+	events   *IPCServer
+	logger   *log.Logger
+}
+
+// apiSession is the authenticated caller of an HTTP API call.
+type apiSession struct {
+	project string
+	session *db.Session
+}
+
+// NewHTTPServer starts listening on opts.Addr; Start serves requests.
+func NewHTTPServer(opts HTTPServerOptions, logger *log.Logger) (*HTTPServer, error) {
+	addr := strings.TrimSpace(opts.Addr)
+	if addr == "" {
+		return nil, fmt.Errorf("http addr is required")
+	}
+	if opts.Events == nil {
+		return nil, fmt.Errorf("http server needs an event source")
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen http %s: %w", addr, err)
+	}
+	s := &HTTPServer{
+		addr:     ln.Addr().String(),
+		listener: ln,
+		events:   opts.Events,
+		logger:   logger,
+	}
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *HTTPServer) Addr() string {
+	return s.addr
+}
+
+// Start serves requests until Stop is called or ctx is done.
+func (s *HTTPServer) Start(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		_ = s.Stop()
+	}()
+	if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Stop closes the listener and any open connections, including event streams.
+func (s *HTTPServer) Stop() error {
+	return s.server.Close()
+}
+
+// Handler returns the API's routes.
+func (s *HTTPServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /requests", s.authed(s.handleListRequests))
+	mux.HandleFunc("GET /requests/{id}", s.authed(s.handleGetRequest))
+	mux.HandleFunc("POST /requests/{id}/reviews", s.authed(s.handleCreateReview))
+	mux.HandleFunc("GET /events", s.authed(s.handleEvents))
+	mux.HandleFunc("GET /metrics", s.authed(s.handleMetrics))
+	return mux
+}
+
+// authed wraps a handler with bearer token authentication.
+func (s *HTTPServer) authed(next func(http.ResponseWriter, *http.Request, apiSession)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			writeHTTPError(w, http.StatusUnauthorized, "bearer token required")
+			return
+		}
+		caller, err := s.authenticate(strings.TrimSpace(token))
+		if err != nil {
+			writeHTTPError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		next(w, r, caller)
+	}
+}
+
+// authenticate finds the active session a token belongs to among the served
+// projects and checks the token against its key.
+func (s *HTTPServer) authenticate(token string) (apiSession, error) {
+	sessionID, _, ok := strings.Cut(token, ".")
+	if !ok || sessionID == "" {
+		return apiSession{}, fmt.Errorf("malformed bearer token")
+	}
+	for _, project := range s.events.Projects() {
+		sess, err := activeProjectSession(project, sessionID)
+		if err != nil {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(HTTPToken(sess.ID, sess.SessionKey))) != 1 {
+			return apiSession{}, fmt.Errorf("invalid bearer token")
+		}
+		return apiSession{project: project, session: sess}, nil
+	}
+	return apiSession{}, fmt.Errorf("invalid bearer token")
+}
+
+// openProjectDB opens the caller's project database.
+func openProjectDB(project string, readOnly bool) (*db.DB, error) {
+	return db.OpenWithOptions(filepath.Join(project, ".slb", "state.db"), db.OpenOptions{
+		CreateIfNotExists: false,
+		InitSchema:        false,
+		ReadOnly:          readOnly,
+	})
+}
+
+func (s *HTTPServer) handleListRequests(w http.ResponseWriter, r *http.Request, caller apiSession) {
+	dbConn, err := openProjectDB(caller.project, true)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer dbConn.Close()
+
+	var requests []*db.Request
+	if status := r.URL.Query().Get("status"); status != "" {
+		requests, err = dbConn.ListRequestsByStatus(db.RequestStatus(status), caller.project)
+	} else {
+		requests, err = dbConn.ListAllRequests(caller.project)
+	}
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if requests == nil {
+		requests = []*db.Request{}
+	}
+	writeHTTPJSON(w, http.StatusOK, requests)
+}
+
+func (s *HTTPServer) handleGetRequest(w http.ResponseWriter, r *http.Request, caller apiSession) {
+	dbConn, err := openProjectDB(caller.project, true)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer dbConn.Close()
+
+	request, reviews, err := dbConn.GetRequestWithReviews(r.PathValue("id"))
+	if err != nil {
+		writeHTTPError(w, httpStatusFor(err), err.Error())
+		return
+	}
+	// The caller sees the reviewers of an unresolved request only as
+	// general.anonymize_reviewers allows.
+	cfg, err := config.Load(config.LoadOptions{ProjectDir: caller.project, PolicyDir: request.Command.Cwd})
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("loading config: %v", err))
+		return
+	}
+	reviewSvc := core.NewReviewService(dbConn, ReviewConfigFromConfig(cfg))
+	reviewSvc.SetViewer(core.ReviewViewer{SessionID: caller.session.ID, AgentName: caller.session.AgentName})
+	reviews, _ = reviewSvc.ReviewsForViewer(request, reviews)
+	if reviews == nil {
+		reviews = []*db.Review{}
+	}
+	writeHTTPJSON(w, http.StatusOK, map[string]any{
+		"request": request,
+		"reviews": reviews,
+	})
+}
+
+// handleMetrics reports usage metrics for the caller's project; for now,
+// rollback captures and restores (as 'slb stats --rollbacks').
+func (s *HTTPServer) handleMetrics(w http.ResponseWriter, r *http.Request, caller apiSession) {
+	dbConn, err := openProjectDB(caller.project, true)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer dbConn.Close()
+
+	events, err := dbConn.ListRollbackEvents(caller.project)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeHTTPJSON(w, http.StatusOK, map[string]any{
+		"project":   caller.project,
+		"rollbacks": core.SummarizeRollbackEvents(events),
+	})
+}
+
+// HTTPReviewBody is the body of POST /requests/{id}/reviews. The signature is
+// db.ComputeReviewSignature over the request ID, the request's command hash
+// (see GET /requests/{id}), the decision and the RFC 3339 UTC timestamp,
+// computed by the client with its session key.
+// AcknowledgeUnviewed is 'slb approve --acknowledge-unviewed'.
+type HTTPReviewBody struct {
+	SessionID           string            `json:"session_id"`
+	Decision            db.Decision       `json:"decision"`
+	Comments            string            `json:"comments,omitempty"`
+	Responses           db.ReviewResponse `json:"responses,omitempty"`
+	Segments            []int             `json:"segments,omitempty"`
+	AcknowledgeUnviewed bool              `json:"acknowledge_unviewed,omitempty"`
+	Signature           string            `json:"signature"`
+	SignatureTimestamp  time.Time         `json:"signature_timestamp"`
+}
+
+func (s *HTTPServer) handleCreateReview(w http.ResponseWriter, r *http.Request, caller apiSession) {
+	var body HTTPReviewBody
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&body); err != nil {
+		writeHTTPError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+	if body.SessionID != caller.session.ID {
+		writeHTTPError(w, http.StatusForbidden, "session_id does not match the bearer token")
+		return
+	}
+	if body.Signature == "" || body.SignatureTimestamp.IsZero() {
+		writeHTTPError(w, http.StatusBadRequest, "signature and signature_timestamp are required")
+		return
+	}
+
+	dbConn, err := openProjectDB(caller.project, false)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer dbConn.Close()
+
+	requestID := r.PathValue("id")
+	request, err := dbConn.GetRequest(requestID)
+	if err != nil {
+		writeHTTPError(w, httpStatusFor(err), err.Error())
+		return
+	}
+
+	// A malformed project policy fails the review rather than falling back
+	// to the looser project settings.
+	cfg, err := config.Load(config.LoadOptions{ProjectDir: caller.project, PolicyDir: request.Command.Cwd})
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("loading config: %v", err))
+		return
+	}
+	reviewSvc := core.NewReviewService(dbConn, ReviewConfigFromConfig(cfg))
+
+	// Approvals pass the same evidence gate as 'slb approve': the views the
+	// reviewer session recorded locally and any it reports in the body.
+	evidence := body.Responses.EvidenceViewed
+	var unviewed []string
+	if body.Decision == db.DecisionApprove {
+		local, err := core.LoadEvidenceViews(request.ProjectPath, requestID, caller.session.ID)
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, fmt.Sprintf("loading evidence views: %v", err))
+			return
+		}
+		evidence = core.MergeEvidenceViews(local, evidence)
+		if unviewed, err = reviewSvc.CheckUnviewedEvidence(request, evidence, body.AcknowledgeUnviewed); err != nil {
+			writeHTTPError(w, httpStatusFor(err), err.Error())
+			return
+		}
+	}
+	responses := body.Responses
+	responses.EvidenceViewed = evidence
+
+	result, err := reviewSvc.SubmitReview(core.ReviewOptions{
+		SessionID:          caller.session.ID,
+		SessionKey:         caller.session.SessionKey,
+		RequestID:          requestID,
+		Decision:           body.Decision,
+		Responses:          responses,
+		Comments:           body.Comments,
+		Segments:           body.Segments,
+		Signature:          body.Signature,
+		SignatureTimestamp: body.SignatureTimestamp,
+	})
+	if err != nil {
+		writeHTTPError(w, httpStatusFor(err), err.Error())
+		return
+	}
+	_ = core.ClearEvidenceViews(request.ProjectPath, requestID, caller.session.ID)
+	if eventType, payload, ok := ReviewOutcomeEvent(request, result); ok {
+		s.events.BroadcastEvent(eventType, payload)
+	}
+
+	resp := map[string]any{
+		"review_id":              result.Review.ID,
+		"request_id":             requestID,
+		"decision":               result.Review.Decision,
+		"approvals":              result.Approvals,
+		"rejections":             result.Rejections,
+		"request_status_changed": result.RequestStatusChanged,
+	}
+	if result.RequestStatusChanged {
+		resp["new_request_status"] = result.NewRequestStatus
+		resp["resolution_applied"] = result.ResolutionApplied
+	}
+	if len(unviewed) > 0 {
+		resp["unviewed_evidence"] = unviewed
+	}
+	writeHTTPJSON(w, http.StatusCreated, resp)
+}
+
+// handleEvents streams daemon events for the caller's project as
+// server-sent events: "event: <type>" and "data: <event JSON>". The type
+// and replay query parameters match subscribe's types and replay.
+func (s *HTTPServer) handleEvents(w http.ResponseWriter, r *http.Request, caller apiSession) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeHTTPError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	params := SubscribeParams{
+		Types:    r.URL.Query()["type"],
+		Projects: []string{caller.project},
+	}
+	if replay := r.URL.Query().Get("replay"); replay != "" {
+		n, err := strconv.Atoi(replay)
+		if err != nil || n < 0 {
+			writeHTTPError(w, http.StatusBadRequest, "replay must be a non-negative integer")
+			return
+		}
+		params.Replay = n
+	}
+
+	events, cancel := s.events.Subscribe(params)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(httpKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				s.logger.Debug("marshal event failed", "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// httpStatusFor maps review and lookup errors to HTTP statuses.
+func httpStatusFor(err error) int {
+	switch {
+	case errors.Is(err, db.ErrRequestNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, core.ErrInvalidSignature), errors.Is(err, core.ErrSessionKeyMismatch),
+		errors.Is(err, core.ErrSessionInactive), errors.Is(err, core.ErrSessionStale):
+		return http.StatusUnauthorized
+	case errors.Is(err, core.ErrSelfReview), errors.Is(err, core.ErrRequireDiffModel),
+		errors.Is(err, core.ErrRequireDiffHost), errors.Is(err, core.ErrRequireDiffProgram),
+		errors.Is(err, core.ErrUnviewedEvidence):
+		return http.StatusForbidden
+	case errors.Is(err, core.ErrRequestNotPending), errors.Is(err, core.ErrAlreadyReviewed):
+		return http.StatusConflict
+	case errors.Is(err, core.ErrInvalidDecision), errors.Is(err, core.ErrInvalidSegments):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeHTTPJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, message string) {
+	writeHTTPJSON(w, status, map[string]string{"error": message})
+}
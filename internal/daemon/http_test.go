@@ -0,0 +1,295 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+// httpAPIFixture serves the HTTP API over a harness project with one pending
+// request and a reviewer session.
+type httpAPIFixture struct {
+	server   *httptest.Server
+	request  *db.Request
+	reviewer *db.Session
+}
+
+func newHTTPAPIFixture(t *testing.T) *httpAPIFixture {
+	t.Helper()
+	h := testutil.NewHarness(t)
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Requestor"))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir), testutil.WithAgent("Reviewer"))
+	req := testutil.MakeRequest(t, h.DB, requestor)
+
+	ipc, err := NewIPCServer(filepath.Join(shortSocketDir(t), "s.sock"), nil)
+	if err != nil {
+		t.Fatalf("NewIPCServer: %v", err)
+	}
+	if _, err := ipc.RegisterProject(h.ProjectDir); err != nil {
+		t.Fatal(err)
+	}
+	api, err := NewHTTPServer(HTTPServerOptions{Addr: "127.0.0.1:0", Events: ipc}, nil)
+	if err != nil {
+		t.Fatalf("NewHTTPServer: %v", err)
+	}
+	t.Cleanup(func() { _ = api.Stop() })
+	server := httptest.NewServer(api.Handler())
+	t.Cleanup(server.Close)
+	return &httpAPIFixture{server: server, request: req, reviewer: reviewer}
+}
+
+func (f *httpAPIFixture) do(t *testing.T, method, path, token string, body any) *http.Response {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	r, err := http.NewRequest(method, f.server.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func (f *httpAPIFixture) signedReview(sessionID string) HTTPReviewBody {
+	ts := time.Now().UTC().Truncate(time.Second)
+	return HTTPReviewBody{
+		SessionID:          sessionID,
+		Decision:           db.DecisionApprove,
+		Signature:          db.ComputeReviewSignature(f.reviewer.SessionKey, f.request.ID, f.request.Command.Hash, db.DecisionApprove, ts),
+		SignatureTimestamp: ts,
+	}
+}
+
+func TestHTTPServer_RequiresToken(t *testing.T) {
+	f := newHTTPAPIFixture(t)
+	for _, token := range []string{"", "garbage", f.reviewer.ID + ".deadbeef", HTTPToken("sess-unknown", f.reviewer.SessionKey)} {
+		if resp := f.do(t, http.MethodGet, "/requests", token, nil); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, resp.StatusCode)
+		}
+	}
+}
+
+func TestHTTPServer_ListAndGetRequests(t *testing.T) {
+	f := newHTTPAPIFixture(t)
+	token := HTTPToken(f.reviewer.ID, f.reviewer.SessionKey)
+
+	resp := f.do(t, http.MethodGet, "/requests?status=pending", token, nil)
+	var list []db.Request
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("list: status %d, %v", resp.StatusCode, err)
+	}
+	if len(list) != 1 || list[0].ID != f.request.ID {
+		t.Errorf("expected the pending request, got %+v", list)
+	}
+
+	resp = f.do(t, http.MethodGet, "/requests/"+f.request.ID, token, nil)
+	var detail struct {
+		Request db.Request   `json:"request"`
+		Reviews []*db.Review `json:"reviews"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("get: status %d, %v", resp.StatusCode, err)
+	}
+	if detail.Request.ID != f.request.ID || detail.Reviews == nil {
+		t.Errorf("unexpected detail %+v", detail)
+	}
+
+	if resp := f.do(t, http.MethodGet, "/requests/req-missing", token, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing request, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPServer_GetRequestAnonymizesReviewers(t *testing.T) {
+	t.Setenv("SLB_ANONYMIZE_REVIEWERS", "true")
+	f := newHTTPAPIFixture(t)
+	dbConn, err := openProjectDB(f.request.ProjectPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestor, err := dbConn.GetSession(f.request.RequestorSessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dbConn.CreateReview(&db.Review{
+		RequestID: f.request.ID, ReviewerSessionID: f.reviewer.ID, ReviewerAgent: f.reviewer.AgentName, Decision: db.DecisionApprove,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	dbConn.Close()
+
+	reviewerSeenBy := func(sess *db.Session) string {
+		t.Helper()
+		resp := f.do(t, http.MethodGet, "/requests/"+f.request.ID, HTTPToken(sess.ID, sess.SessionKey), nil)
+		var detail struct {
+			Reviews []*db.Review `json:"reviews"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil || len(detail.Reviews) != 1 {
+			t.Fatalf("get: status %d, %+v, %v", resp.StatusCode, detail, err)
+		}
+		return detail.Reviews[0].ReviewerAgent
+	}
+	if got := reviewerSeenBy(requestor); got != "reviewer A" {
+		t.Errorf("requestor sees reviewer %q while pending, want a pseudonym", got)
+	}
+	if got := reviewerSeenBy(f.reviewer); got != f.reviewer.AgentName {
+		t.Errorf("reviewer sees %q, want the real name", got)
+	}
+}
+
+func TestHTTPServer_Metrics(t *testing.T) {
+	f := newHTTPAPIFixture(t)
+	dbConn, err := openProjectDB(f.request.ProjectPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, succeeded := range []bool{true, false} {
+		if err := dbConn.RecordRollbackEvent(&db.RollbackEvent{
+			RequestID: f.request.ID, ProjectPath: f.request.ProjectPath, Event: db.RollbackEventRestore,
+			Kind: "git", Succeeded: succeeded,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dbConn.Close()
+
+	resp := f.do(t, http.MethodGet, "/metrics", HTTPToken(f.reviewer.ID, f.reviewer.SessionKey), nil)
+	var metrics struct {
+		Rollbacks core.RollbackStats `json:"rollbacks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("metrics: status %d, %v", resp.StatusCode, err)
+	}
+	if metrics.Rollbacks.RestoreAttempts != 2 || metrics.Rollbacks.SuccessRate != 0.5 {
+		t.Errorf("rollback metrics = %+v", metrics.Rollbacks)
+	}
+}
+
+func TestHTTPServer_CreateReview(t *testing.T) {
+	f := newHTTPAPIFixture(t)
+	token := HTTPToken(f.reviewer.ID, f.reviewer.SessionKey)
+	path := "/requests/" + f.request.ID + "/reviews"
+
+	if resp := f.do(t, http.MethodPost, path, token, f.signedReview("sess-other")); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for a mismatched session_id, got %d", resp.StatusCode)
+	}
+	unsigned := f.signedReview(f.reviewer.ID)
+	unsigned.Signature = ""
+	if resp := f.do(t, http.MethodPost, path, token, unsigned); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 without a signature, got %d", resp.StatusCode)
+	}
+	forged := f.signedReview(f.reviewer.ID)
+	forged.Decision = db.DecisionReject
+	if resp := f.do(t, http.MethodPost, path, token, forged); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a signature over another decision, got %d", resp.StatusCode)
+	}
+
+	resp := f.do(t, http.MethodPost, path, token, f.signedReview(f.reviewer.ID))
+	var out map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("review: status %d, %+v, %v", resp.StatusCode, out, err)
+	}
+	if out["new_request_status"] != string(db.StatusApproved) {
+		t.Errorf("expected the request to be approved, got %+v", out)
+	}
+	if resp := f.do(t, http.MethodPost, path, token, f.signedReview(f.reviewer.ID)); resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 for a second review, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPServer_CreateReviewUnviewedEvidence(t *testing.T) {
+	t.Setenv("SLB_UNVIEWED_EVIDENCE_ACTION", core.UnviewedEvidenceBlockCritical)
+	f := newHTTPAPIFixture(t)
+	token := HTTPToken(f.reviewer.ID, f.reviewer.SessionKey)
+
+	dbConn, err := openProjectDB(f.request.ProjectPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestor, err := dbConn.GetSession(f.request.RequestorSessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.request = testutil.MakeRequest(t, dbConn, requestor,
+		testutil.WithRisk(db.RiskTierCritical), testutil.WithMinApprovals(1), testutil.WithDryRun("ls", "a"))
+	dbConn.Close()
+	path := "/requests/" + f.request.ID + "/reviews"
+
+	if resp := f.do(t, http.MethodPost, path, token, f.signedReview(f.reviewer.ID)); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for an unviewed CRITICAL approval, got %d", resp.StatusCode)
+	}
+
+	acked := f.signedReview(f.reviewer.ID)
+	acked.AcknowledgeUnviewed = true
+	resp := f.do(t, http.MethodPost, path, token, acked)
+	var out map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("acknowledged review: status %d, %+v, %v", resp.StatusCode, out, err)
+	}
+}
+
+func TestHTTPServer_EventsStream(t *testing.T) {
+	f := newHTTPAPIFixture(t)
+	token := HTTPToken(f.reviewer.ID, f.reviewer.SessionKey)
+
+	resp := f.do(t, http.MethodGet, "/events?type=request_approved", token, nil)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("events: status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	if r := f.do(t, http.MethodPost, "/requests/"+f.request.ID+"/reviews", token, f.signedReview(f.reviewer.ID)); r.StatusCode != http.StatusCreated {
+		t.Fatalf("review: status %d", r.StatusCode)
+	}
+
+	lines := make(chan string, 16)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	var event string
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("stream closed before the event arrived")
+			}
+			if name, found := strings.CutPrefix(line, "event: "); found {
+				event = name
+				continue
+			}
+			if data, found := strings.CutPrefix(line, "data: "); found {
+				if event != "request_approved" || !strings.Contains(data, f.request.ID) {
+					t.Fatalf("unexpected event %q: %s", event, data)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the request_approved event")
+		}
+	}
+}
@@ -9,10 +9,14 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"slices"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/charmbracelet/log"
 )
 
@@ -36,6 +40,7 @@ type (
 	Error struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
+		Data    any    `json:"data,omitempty"`
 	}
 )
 
@@ -46,6 +51,10 @@ const (
 	ErrCodeMethodNotFound = -32601
 	ErrCodeInvalidParams  = -32602
 	ErrCodeInternal       = -32603
+
+	// ErrCodeUnauthorized is returned when a method's session credentials
+	// are missing or do not validate.
+	ErrCodeUnauthorized = -32001
 )
 
 type lockedConn struct {
@@ -72,6 +81,7 @@ func newIPCServer(listener net.Listener, addr string, logger *log.Logger, cleanu
 		logger:      logger,
 		startTime:   time.Now(),
 		subscribers: make(map[int64]*subscriber),
+		registry:    newProjectRegistry(),
 		startDone:   startDone,
 		ctx:         ctx,
 		cancel:      cancel,
@@ -93,10 +103,13 @@ type IPCServer struct {
 	activeConns  atomic.Int32
 	pendingCount atomic.Int32
 
-	// Subscriber management.
+	// Subscriber management. recent holds the last replayBufferSize
+	// events for subscribers that ask for a replay; it is guarded by
+	// subscribersMu so a replay and live delivery never overlap.
 	subscribers   map[int64]*subscriber
 	subscribersMu sync.RWMutex
 	nextSubID     atomic.Int64
+	recent        []Event
 
 	// Shutdown coordination.
 	ctx       context.Context
@@ -107,16 +120,37 @@ type IPCServer struct {
 
 	// Optional verifier for execution gate checks.
 	verifier *Verifier
+
+	// Registered projects (workspace members), shared by a daemon's listeners.
+	registry *projectRegistry
+}
+
+// projectRegistry is the set of projects a daemon serves, with the hook run
+// on registration and the check that authorizes register_project calls.
+type projectRegistry struct {
+	mu        sync.Mutex
+	projects  map[string]bool
+	registrar func(projectPath string) error
+	authorize func(params RegisterProjectParams) error
+}
+
+func newProjectRegistry() *projectRegistry {
+	return &projectRegistry{projects: make(map[string]bool)}
 }
 
 // subscriber tracks an event subscription.
 type subscriber struct {
 	id     int64
 	conn   net.Conn
+	filter SubscribeParams
 	events chan Event
 	done   chan struct{}
 }
 
+// replayBufferSize is how many recent events the server keeps for replay.
+// It matches the subscriber channel buffer so a full replay always fits.
+const replayBufferSize = 100
+
 // Event represents a daemon event sent to subscribers.
 type Event struct {
 	Type    string `json:"type"`
@@ -314,6 +348,8 @@ func (s *IPCServer) handleRequest(conn net.Conn, data []byte) *RPCResponse {
 	}
 
 	switch req.Method {
+	case "hello":
+		return s.handleHello(req)
 	case "ping":
 		return s.handlePing(req)
 	case "status":
@@ -328,6 +364,10 @@ func (s *IPCServer) handleRequest(conn net.Conn, data []byte) *RPCResponse {
 		return s.handleHookQuery(req)
 	case "hook_health":
 		return s.handleHookHealth(req)
+	case "classify":
+		return s.handleClassify(req)
+	case "register_project":
+		return s.handleRegisterProject(req)
 	default:
 		return &RPCResponse{
 			Error: &Error{Code: ErrCodeMethodNotFound, Message: "method not found: " + req.Method},
@@ -356,6 +396,7 @@ func (s *IPCServer) handleStatus(req RPCRequest) *RPCResponse {
 			"pending_count":   s.pendingCount.Load(),
 			"active_sessions": s.activeConns.Load(),
 			"subscribers":     subCount,
+			"projects":        s.Projects(),
 		},
 		ID: req.ID,
 	}
@@ -398,20 +439,61 @@ func (s *IPCServer) handleNotify(req RPCRequest) *RPCResponse {
 	}
 }
 
+// SubscribeParams are the optional parameters of the subscribe method
+// (FeatureFilters, FeatureReplay). Daemons without those features ignore
+// them, so clients check the negotiated features first.
+type SubscribeParams struct {
+	// Types limits the subscription to these event types.
+	Types []string `json:"types,omitempty"`
+	// Projects limits the subscription to events whose payload project_path
+	// is one of these.
+	Projects []string `json:"projects,omitempty"`
+	// Replay asks for up to this many recent matching events first.
+	Replay int `json:"replay,omitempty"`
+}
+
+// Filtered reports whether p restricts which events are delivered.
+func (p SubscribeParams) Filtered() bool {
+	return len(p.Types) > 0 || len(p.Projects) > 0
+}
+
+// Matches reports whether event passes p's filters.
+func (p SubscribeParams) Matches(event Event) bool {
+	if len(p.Types) > 0 && !slices.Contains(p.Types, event.Type) {
+		return false
+	}
+	if len(p.Projects) > 0 && !slices.Contains(p.Projects, eventProject(event)) {
+		return false
+	}
+	return true
+}
+
+// eventProject returns the project_path an event's payload carries, if any.
+func eventProject(event Event) string {
+	switch payload := event.Payload.(type) {
+	case map[string]any:
+		project, _ := payload["project_path"].(string)
+		return project
+	case map[string]string:
+		return payload["project_path"]
+	}
+	return ""
+}
+
 // handleSubscribe sets up event streaming for the connection.
 func (s *IPCServer) handleSubscribe(req RPCRequest, conn net.Conn) *RPCResponse {
-	id := s.nextSubID.Add(1)
-
-	sub := &subscriber{
-		id:     id,
-		conn:   conn,
-		events: make(chan Event, 100),
-		done:   make(chan struct{}),
+	var params SubscribeParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return &RPCResponse{
+				Error: &Error{Code: ErrCodeInvalidParams, Message: "invalid params: " + err.Error()},
+				ID:    req.ID,
+			}
+		}
 	}
 
-	s.subscribersMu.Lock()
-	s.subscribers[id] = sub
-	s.subscribersMu.Unlock()
+	sub := s.addSubscriber(conn, params)
+	id := sub.id
 
 	// Send initial response.
 	resp := &RPCResponse{
@@ -432,6 +514,44 @@ func (s *IPCServer) handleSubscribe(req RPCRequest, conn net.Conn) *RPCResponse
 	return nil // Response already sent.
 }
 
+// addSubscriber registers a subscriber, queueing its replay before live
+// events can arrive.
+func (s *IPCServer) addSubscriber(conn net.Conn, params SubscribeParams) *subscriber {
+	sub := &subscriber{
+		id:     s.nextSubID.Add(1),
+		conn:   conn,
+		filter: params,
+		events: make(chan Event, replayBufferSize),
+		done:   make(chan struct{}),
+	}
+
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+	if params.Replay > 0 {
+		var replay []Event
+		for _, event := range s.recent {
+			if params.Matches(event) {
+				replay = append(replay, event)
+			}
+		}
+		if len(replay) > params.Replay {
+			replay = replay[len(replay)-params.Replay:]
+		}
+		for _, event := range replay {
+			sub.events <- event
+		}
+	}
+	s.subscribers[sub.id] = sub
+	return sub
+}
+
+// Subscribe delivers the events subscribe would stream over IPC to an
+// in-process consumer such as the HTTP API. Call cancel to unsubscribe.
+func (s *IPCServer) Subscribe(params SubscribeParams) (events <-chan Event, cancel func()) {
+	sub := s.addSubscriber(nil, params)
+	return sub.events, func() { s.removeSubscriber(sub.id) }
+}
+
 // streamEvents sends events to a subscriber until done.
 func (s *IPCServer) streamEvents(sub *subscriber) {
 	defer s.removeSubscriber(sub.id)
@@ -458,12 +578,21 @@ func (s *IPCServer) streamEvents(sub *subscriber) {
 	}
 }
 
-// broadcast sends an event to all subscribers.
+// broadcast sends an event to all subscribers whose filters match and
+// keeps it for replay.
 func (s *IPCServer) broadcast(event Event) {
-	s.subscribersMu.RLock()
-	defer s.subscribersMu.RUnlock()
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+
+	s.recent = append(s.recent, event)
+	if len(s.recent) > replayBufferSize {
+		s.recent = s.recent[len(s.recent)-replayBufferSize:]
+	}
 
 	for _, sub := range s.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
 		select {
 		case sub.events <- event:
 		default:
@@ -504,6 +633,203 @@ func (s *IPCServer) BroadcastEvent(eventType string, payload any) {
 	})
 }
 
+// ReviewOutcomeEvent returns the daemon event for a review that decided its
+// request, carrying the project path so subscribers open the right database.
+// ok is false when the review left the request undecided.
+func ReviewOutcomeEvent(request *db.Request, result *core.ReviewResult) (eventType string, payload map[string]any, ok bool) {
+	if result == nil || !result.RequestStatusChanged {
+		return "", nil, false
+	}
+	switch result.NewRequestStatus {
+	case db.StatusApproved:
+		eventType = "request_approved"
+	case db.StatusRejected:
+		eventType = "request_rejected"
+	default:
+		return "", nil, false
+	}
+	command := request.Command.Raw
+	if request.Command.DisplayRedacted != "" {
+		command = request.Command.DisplayRedacted
+	}
+	payload = map[string]any{
+		"request_id":   request.ID,
+		"risk_tier":    string(request.RiskTier),
+		"command":      command,
+		"requestor":    request.RequestorAgent,
+		"project_path": request.ProjectPath,
+	}
+	if eventType == "request_approved" {
+		payload["approved_by"] = result.Review.ReviewerAgent
+	} else {
+		payload["rejected_by"] = result.Review.ReviewerAgent
+	}
+	return eventType, payload, true
+}
+
+// RerequestEvent returns the request_rerequested event for a request sent
+// back for review after its approval expired, naming the reviewers asked to
+// re-confirm.
+func RerequestEvent(request *db.Request, priorApprovals []*db.Review) map[string]any {
+	command := request.Command.Raw
+	if request.Command.DisplayRedacted != "" {
+		command = request.Command.DisplayRedacted
+	}
+	approvers := make([]string, 0, len(priorApprovals))
+	for _, r := range priorApprovals {
+		approvers = append(approvers, r.ReviewerAgent)
+	}
+	return map[string]any{
+		"request_id":      request.ID,
+		"risk_tier":       string(request.RiskTier),
+		"command":         command,
+		"requestor":       request.RequestorAgent,
+		"project_path":    request.ProjectPath,
+		"prior_approvers": approvers,
+	}
+}
+
+// CancelledEvent returns the request_cancelled event for a request its
+// requestor, or an operator, withdrew before execution.
+func CancelledEvent(request *db.Request) map[string]any {
+	command := request.Command.Raw
+	if request.Command.DisplayRedacted != "" {
+		command = request.Command.DisplayRedacted
+	}
+	return map[string]any{
+		"request_id":   request.ID,
+		"risk_tier":    string(request.RiskTier),
+		"command":      command,
+		"requestor":    request.RequestorAgent,
+		"project_path": request.ProjectPath,
+		"reason":       request.CancelReason,
+		"cancelled_by": request.CancelledBy,
+	}
+}
+
+// ExecutionQueuedEvent returns the request_execution_queued event for an
+// approved request waiting for one of its project's execution slots.
+func ExecutionQueuedEvent(status *core.ExecutionQueueStatus) map[string]any {
+	return map[string]any{
+		"request_id":     status.RequestID,
+		"project_path":   status.ProjectPath,
+		"position":       status.Position,
+		"queue_length":   status.QueueLength,
+		"running":        status.Running,
+		"max_concurrent": status.MaxConcurrent,
+		"priority":       status.Priority,
+	}
+}
+
+// RegisterProjectParams are parameters for the register_project method. The
+// session must be active in a project the daemon already serves.
+type RegisterProjectParams struct {
+	ProjectPath string `json:"project_path"`
+	SessionID   string `json:"session_id"`
+	SessionKey  string `json:"session_key"`
+}
+
+// handleRegisterProject adds a project to the set this daemon serves.
+func (s *IPCServer) handleRegisterProject(req RPCRequest) *RPCResponse {
+	var params RegisterProjectParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return &RPCResponse{
+			Error: &Error{Code: ErrCodeInvalidParams, Message: "invalid params: " + err.Error()},
+			ID:    req.ID,
+		}
+	}
+	if params.ProjectPath == "" {
+		return &RPCResponse{
+			Error: &Error{Code: ErrCodeInvalidParams, Message: "project_path is required"},
+			ID:    req.ID,
+		}
+	}
+	if params.SessionID == "" || params.SessionKey == "" {
+		return &RPCResponse{
+			Error: &Error{Code: ErrCodeUnauthorized, Message: "session_id and session_key are required"},
+			ID:    req.ID,
+		}
+	}
+
+	s.registry.mu.Lock()
+	authorize := s.registry.authorize
+	s.registry.mu.Unlock()
+	if authorize == nil {
+		return &RPCResponse{
+			Error: &Error{Code: ErrCodeUnauthorized, Message: "project registration is not enabled"},
+			ID:    req.ID,
+		}
+	}
+	if err := authorize(params); err != nil {
+		return &RPCResponse{
+			Error: &Error{Code: ErrCodeUnauthorized, Message: "unauthorized: " + err.Error()},
+			ID:    req.ID,
+		}
+	}
+
+	added, err := s.RegisterProject(params.ProjectPath)
+	if err != nil {
+		return &RPCResponse{
+			Error: &Error{Code: ErrCodeInternal, Message: err.Error()},
+			ID:    req.ID,
+		}
+	}
+	return &RPCResponse{
+		Result: map[string]any{"registered": true, "added": added},
+		ID:     req.ID,
+	}
+}
+
+// SetProjectRegistrar sets the hook run once for each newly registered project.
+func (s *IPCServer) SetProjectRegistrar(fn func(projectPath string) error) {
+	s.registry.mu.Lock()
+	defer s.registry.mu.Unlock()
+	s.registry.registrar = fn
+}
+
+// SetProjectAuthorizer sets the check run on every register_project call.
+// Without one, the method refuses all registrations.
+func (s *IPCServer) SetProjectAuthorizer(fn func(params RegisterProjectParams) error) {
+	s.registry.mu.Lock()
+	defer s.registry.mu.Unlock()
+	s.registry.authorize = fn
+}
+
+// ShareProjects makes s use other's project registry, so a project registered
+// over either listener gets one reaper and shows in both status replies.
+func (s *IPCServer) ShareProjects(other *IPCServer) {
+	s.registry = other.registry
+}
+
+// RegisterProject records a project as served by this daemon. It reports
+// whether the project was newly added; re-registering is a no-op.
+func (s *IPCServer) RegisterProject(projectPath string) (bool, error) {
+	s.registry.mu.Lock()
+	defer s.registry.mu.Unlock()
+	if s.registry.projects[projectPath] {
+		return false, nil
+	}
+	if s.registry.registrar != nil {
+		if err := s.registry.registrar(projectPath); err != nil {
+			return false, fmt.Errorf("registering project %s: %w", projectPath, err)
+		}
+	}
+	s.registry.projects[projectPath] = true
+	return true, nil
+}
+
+// Projects returns the registered projects, sorted.
+func (s *IPCServer) Projects() []string {
+	s.registry.mu.Lock()
+	defer s.registry.mu.Unlock()
+	out := make([]string, 0, len(s.registry.projects))
+	for p := range s.registry.projects {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
 // SetVerifier configures the execution verifier for gate checks.
 func (s *IPCServer) SetVerifier(v *Verifier) {
 	s.verifier = v
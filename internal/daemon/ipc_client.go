@@ -6,12 +6,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
 )
 
 // IPCClient provides methods to communicate with the daemon via IPC.
@@ -19,18 +23,54 @@ type IPCClient struct {
 	socketPath string
 	conn       net.Conn
 	scanner    *bufio.Scanner
+	peer       *PeerInfo
 	mu         sync.Mutex
 	nextID     atomic.Int64
+
+	// notices receives one-line notices when the daemon lacks a feature
+	// and the client degrades; each is printed once per client.
+	notices io.Writer
+	noticed map[string]bool
 }
 
 // NewIPCClient creates a new IPC client.
 func NewIPCClient(socketPath string) *IPCClient {
 	return &IPCClient{
 		socketPath: socketPath,
+		notices:    os.Stderr,
+		noticed:    make(map[string]bool),
+	}
+}
+
+// SetNoticeWriter sets where degradation notices are written (stderr by
+// default); nil discards them.
+func (c *IPCClient) SetNoticeWriter(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notices = w
+}
+
+// Peer returns what the handshake learned about the daemon, or nil before
+// Handshake.
+func (c *IPCClient) Peer() *PeerInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.peer
+}
+
+// notice writes a one-line degradation notice once per key.
+func (c *IPCClient) notice(key, format string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.notices == nil || c.noticed[key] {
+		return
 	}
+	c.noticed[key] = true
+	fmt.Fprintf(c.notices, "Notice: "+format+"\n", args...)
 }
 
-// Connect establishes a connection to the daemon IPC socket.
+// Connect establishes a connection to the daemon IPC socket. The version
+// and feature handshake happens on the first call (see Handshake).
 func (c *IPCClient) Connect(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -75,6 +115,76 @@ func (c *IPCClient) Connect(ctx context.Context) error {
 	return nil
 }
 
+// Handshake connects if needed and exchanges versions and features with the
+// daemon, once per connection. A daemon whose protocol major differs is an
+// IncompatibleProtocolError; one that predates the handshake is reported as
+// a legacy peer.
+func (c *IPCClient) Handshake(ctx context.Context) (*PeerInfo, error) {
+	if err := c.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if c.peer != nil {
+		return c.peer, nil
+	}
+	peer, err := c.hello(ctx)
+	if err != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+		c.scanner = nil
+		return nil, err
+	}
+	c.peer = peer
+	return peer, nil
+}
+
+// handshakeTimeout bounds the hello exchange when ctx has no deadline.
+const handshakeTimeout = 2 * time.Second
+
+// hello sends the handshake request and interprets the reply. The caller
+// holds c.mu.
+func (c *IPCClient) hello(ctx context.Context) (*PeerInfo, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(handshakeTimeout)
+	}
+	_ = c.conn.SetDeadline(deadline)
+	defer func() { _ = c.conn.SetDeadline(time.Time{}) }()
+
+	params, err := json.Marshal(HelloParams{
+		Version:  Version,
+		Protocol: ProtocolVersion,
+		Features: SupportedFeatures(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal hello: %w", err)
+	}
+	data, err := json.Marshal(RPCRequest{Method: "hello", Params: params, ID: c.nextID.Add(1)})
+	if err != nil {
+		return nil, fmt.Errorf("marshal hello: %w", err)
+	}
+	if _, err := c.conn.Write(append(data, '\n')); err != nil {
+		return nil, fmt.Errorf("write hello: %w", err)
+	}
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read hello: %w", err)
+		}
+		return nil, fmt.Errorf("connection closed")
+	}
+	var resp RPCResponse
+	if err := json.Unmarshal(c.scanner.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal hello: %w", err)
+	}
+	return peerFromHello(&resp)
+}
+
 // Close closes the connection to the daemon.
 func (c *IPCClient) Close() error {
 	c.mu.Lock()
@@ -87,6 +197,7 @@ func (c *IPCClient) Close() error {
 	err := c.conn.Close()
 	c.conn = nil
 	c.scanner = nil
+	c.peer = nil
 	return err
 }
 
@@ -143,7 +254,7 @@ func (c *IPCClient) call(method string, params any) (*RPCResponse, error) {
 
 // Ping sends a ping to the daemon and verifies it's responsive.
 func (c *IPCClient) Ping(ctx context.Context) error {
-	if err := c.Connect(ctx); err != nil {
+	if _, err := c.Handshake(ctx); err != nil {
 		return err
 	}
 
@@ -161,15 +272,16 @@ func (c *IPCClient) Ping(ctx context.Context) error {
 
 // DaemonStatusInfo contains daemon status information.
 type DaemonStatusInfo struct {
-	UptimeSeconds  int64 `json:"uptime_seconds"`
-	PendingCount   int32 `json:"pending_count"`
-	ActiveSessions int32 `json:"active_sessions"`
-	Subscribers    int   `json:"subscribers"`
+	UptimeSeconds  int64    `json:"uptime_seconds"`
+	PendingCount   int32    `json:"pending_count"`
+	ActiveSessions int32    `json:"active_sessions"`
+	Subscribers    int      `json:"subscribers"`
+	Projects       []string `json:"projects,omitempty"`
 }
 
 // Status returns the daemon's status information.
 func (c *IPCClient) Status(ctx context.Context) (*DaemonStatusInfo, error) {
-	if err := c.Connect(ctx); err != nil {
+	if _, err := c.Handshake(ctx); err != nil {
 		return nil, err
 	}
 
@@ -196,11 +308,32 @@ func (c *IPCClient) Status(ctx context.Context) (*DaemonStatusInfo, error) {
 	return &info, nil
 }
 
+// PublishReviewOutcome tells a running daemon that a review decided its
+// request so `slb watch` subscribers see it. It is best effort: the review is
+// already committed and watchers fall back to polling without a daemon.
+// Handshake notices go to notices (nil discards them).
+func PublishReviewOutcome(request *db.Request, result *core.ReviewResult, notices io.Writer) {
+	eventType, payload, ok := ReviewOutcomeEvent(request, result)
+	if !ok || !NewClient().IsDaemonRunning() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client := NewIPCClient(DefaultSocketPath())
+	client.SetNoticeWriter(notices)
+	defer client.Close()
+	_ = client.Notify(ctx, eventType, payload)
+}
+
 // Notify sends a notification to the daemon for broadcasting.
 func (c *IPCClient) Notify(ctx context.Context, eventType string, payload any) error {
-	if err := c.Connect(ctx); err != nil {
+	peer, err := c.Handshake(ctx)
+	if err != nil {
 		return err
 	}
+	if !peer.Has(FeaturePublish) {
+		return fmt.Errorf("daemon %s does not accept published events", peer.Version)
+	}
 
 	resp, err := c.call("notify", NotifyParams{
 		Type:    eventType,
@@ -217,6 +350,30 @@ func (c *IPCClient) Notify(ctx context.Context, eventType string, payload any) e
 	return nil
 }
 
+// RegisterProject asks the daemon to serve an additional project (e.g. a
+// workspace member), authenticating with an active session. Registering an
+// already-served project is a no-op.
+func (c *IPCClient) RegisterProject(ctx context.Context, projectPath, sessionID, sessionKey string) error {
+	if _, err := c.Handshake(ctx); err != nil {
+		return err
+	}
+
+	resp, err := c.call("register_project", RegisterProjectParams{
+		ProjectPath: projectPath,
+		SessionID:   sessionID,
+		SessionKey:  sessionKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return fmt.Errorf("register_project error: %s", resp.Error.Message)
+	}
+
+	return nil
+}
+
 // SubscriptionInfo contains subscription information.
 type SubscriptionInfo struct {
 	Subscribed     bool  `json:"subscribed"`
@@ -226,10 +383,45 @@ type SubscriptionInfo struct {
 // Subscribe subscribes to daemon events. Returns a channel that receives events.
 // The caller should read from the channel and call Close when done.
 func (c *IPCClient) Subscribe(ctx context.Context) (<-chan Event, error) {
-	if err := c.Connect(ctx); err != nil {
+	return c.SubscribeWithOptions(ctx, SubscribeParams{})
+}
+
+// SubscribeWithOptions subscribes to the events matching opts. Filters the
+// daemon cannot apply are applied here instead, and a replay it cannot serve
+// is skipped; either prints a one-line notice.
+func (c *IPCClient) SubscribeWithOptions(ctx context.Context, opts SubscribeParams) (<-chan Event, error) {
+	peer, err := c.Handshake(ctx)
+	if err != nil {
 		return nil, err
 	}
 
+	var send SubscribeParams
+	var clientFilter SubscribeParams
+	if opts.Filtered() {
+		if peer.Has(FeatureFilters) {
+			send.Types, send.Projects = opts.Types, opts.Projects
+		} else {
+			clientFilter.Types, clientFilter.Projects = opts.Types, opts.Projects
+			c.notice(FeatureFilters, "daemon %s does not filter events; filtering in slb instead", peer.Version)
+		}
+	}
+	if opts.Replay > 0 {
+		if peer.Has(FeatureReplay) {
+			send.Replay = opts.Replay
+		} else {
+			c.notice(FeatureReplay, "daemon %s cannot replay recent events; showing new events only", peer.Version)
+		}
+	}
+
+	var paramsJSON json.RawMessage
+	if send.Filtered() || send.Replay > 0 {
+		p, err := json.Marshal(send)
+		if err != nil {
+			return nil, fmt.Errorf("marshal params: %w", err)
+		}
+		paramsJSON = p
+	}
+
 	// Subscribe is designed for long-lived event streaming.
 	// Avoid issuing other RPC calls on this client while subscribed.
 
@@ -238,6 +430,7 @@ func (c *IPCClient) Subscribe(ctx context.Context) (<-chan Event, error) {
 	id := c.nextID.Add(1)
 	req := RPCRequest{
 		Method: "subscribe",
+		Params: paramsJSON,
 		ID:     id,
 	}
 
@@ -312,6 +505,9 @@ func (c *IPCClient) Subscribe(ctx context.Context) (<-chan Event, error) {
 			if err := json.Unmarshal(line, &eventMsg); err != nil {
 				continue
 			}
+			if !clientFilter.Matches(eventMsg.Event) {
+				continue
+			}
 
 			select {
 			case events <- eventMsg.Event:
@@ -325,26 +521,34 @@ func (c *IPCClient) Subscribe(ctx context.Context) (<-chan Event, error) {
 	return events, nil
 }
 
-// RequestStreamEvent is a structured event for the watch command output.
+// RequestStreamEvent is a structured event for the watch command output. It
+// carries the CurrentEventSchema shape; use Versioned to emit an older one.
 type RequestStreamEvent struct {
-	Event      string `json:"event"`
-	RequestID  string `json:"request_id,omitempty"`
-	RiskTier   string `json:"risk_tier,omitempty"`
-	Command    string `json:"command,omitempty"`
-	Requestor  string `json:"requestor,omitempty"`
-	ApprovedBy string `json:"approved_by,omitempty"`
-	RejectedBy string `json:"rejected_by,omitempty"`
-	Reason     string `json:"reason,omitempty"`
-	ExitCode   *int   `json:"exit_code,omitempty"`
-	CreatedAt  string `json:"created_at,omitempty"`
-	ExecutedAt string `json:"executed_at,omitempty"`
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	Event         string `json:"event"`
+	RequestID     string `json:"request_id,omitempty"`
+	Project       string `json:"project,omitempty"`
+	RiskTier      string `json:"risk_tier,omitempty"`
+	Command       string `json:"command,omitempty"`
+	Requestor     string `json:"requestor,omitempty"`
+	ApprovedBy    string `json:"approved_by,omitempty"`
+	RejectedBy    string `json:"rejected_by,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	ExitCode      *int   `json:"exit_code,omitempty"`
+	CreatedAt     string `json:"created_at,omitempty"`
+	ExecutedAt    string `json:"executed_at,omitempty"`
+	ExpiredAt     string `json:"expired_at,omitempty"`
+	// HasPreview reports on request_pending events whether the request
+	// carries a dry-run preview attachment (EventSchemaV3).
+	HasPreview *bool `json:"has_preview,omitempty"`
 }
 
 // ToRequestStreamEvent converts a daemon Event to a RequestStreamEvent.
 func ToRequestStreamEvent(e Event) *RequestStreamEvent {
 	we := &RequestStreamEvent{
-		Event:     e.Type,
-		CreatedAt: time.Unix(e.Time, 0).Format(time.RFC3339),
+		SchemaVersion: CurrentEventSchema,
+		Event:         e.Type,
+		CreatedAt:     time.Unix(e.Time, 0).Format(time.RFC3339),
 	}
 
 	// Extract common fields from payload
@@ -352,6 +556,9 @@ func ToRequestStreamEvent(e Event) *RequestStreamEvent {
 		if v, ok := payload["request_id"].(string); ok {
 			we.RequestID = v
 		}
+		if v, ok := payload["project_path"].(string); ok {
+			we.Project = v
+		}
 		if v, ok := payload["risk_tier"].(string); ok {
 			we.RiskTier = v
 		}
@@ -374,6 +581,12 @@ func ToRequestStreamEvent(e Event) *RequestStreamEvent {
 			code := int(v)
 			we.ExitCode = &code
 		}
+		if v, ok := payload["expired_at"].(string); ok {
+			we.ExpiredAt = v
+		}
+		if v, ok := payload["has_preview"].(bool); ok {
+			we.HasPreview = &v
+		}
 	}
 
 	return we
@@ -3,6 +3,7 @@ package daemon
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net"
 	"os"
@@ -191,6 +192,117 @@ func TestIPCClient_Notify_NotConnected(t *testing.T) {
 	}
 }
 
+func TestIPCClient_RegisterProject_Success(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket tests not supported on windows")
+	}
+
+	socketPath := filepath.Join(shortSocketDir(t), "t.sock")
+	srv, err := NewIPCServer(socketPath, log.New(io.Discard))
+	if err != nil {
+		t.Fatalf("NewIPCServer: %v", err)
+	}
+	var calls []string
+	srv.SetProjectRegistrar(func(projectPath string) error {
+		calls = append(calls, projectPath)
+		return nil
+	})
+	srv.SetProjectAuthorizer(func(p RegisterProjectParams) error {
+		if p.SessionKey != "key" {
+			return errors.New("bad key")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = srv.Start(ctx) }()
+
+	time.Sleep(50 * time.Millisecond)
+
+	client := NewIPCClient(socketPath)
+	for _, p := range []string{"/repo/web", "/repo/api", "/repo/web"} {
+		if err := client.RegisterProject(ctx, p, "sess", "key"); err != nil {
+			t.Fatalf("RegisterProject(%s) failed: %v", p, err)
+		}
+	}
+	if len(calls) != 2 {
+		t.Errorf("registrar called %d times, want 2 (re-registering is a no-op): %v", len(calls), calls)
+	}
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if want := []string{"/repo/api", "/repo/web"}; len(status.Projects) != 2 || status.Projects[0] != want[0] || status.Projects[1] != want[1] {
+		t.Errorf("status projects = %v, want %v", status.Projects, want)
+	}
+
+	if err := client.RegisterProject(ctx, "", "sess", "key"); err == nil {
+		t.Error("expected error for empty project path")
+	}
+	if err := client.RegisterProject(ctx, "/repo/ops", "", ""); err == nil {
+		t.Error("expected error without session credentials")
+	}
+	if err := client.RegisterProject(ctx, "/repo/ops", "sess", "wrong"); err == nil {
+		t.Error("expected error for an unauthorized session")
+	}
+	if got := srv.Projects(); len(got) != 2 {
+		t.Errorf("refused registrations should not be recorded, got %v", got)
+	}
+
+	_ = client.Close()
+	_ = srv.Stop()
+}
+
+func TestIPCServer_RegisterProject_RegistrarError(t *testing.T) {
+	srv := &IPCServer{registry: newProjectRegistry()}
+	srv.SetProjectRegistrar(func(string) error { return os.ErrNotExist })
+
+	if _, err := srv.RegisterProject("/repo/api"); err == nil {
+		t.Fatal("expected registrar error")
+	}
+	if got := srv.Projects(); len(got) != 0 {
+		t.Fatalf("failed registration should not be recorded, got %v", got)
+	}
+}
+
+func TestIPCServer_RegisterProject_RequiresAuthorizer(t *testing.T) {
+	srv := &IPCServer{registry: newProjectRegistry()}
+	params, _ := json.Marshal(RegisterProjectParams{ProjectPath: "/repo/api", SessionID: "s", SessionKey: "k"})
+	resp := srv.handleRegisterProject(RPCRequest{Method: "register_project", Params: params, ID: 1})
+	if resp.Error == nil || resp.Error.Code != ErrCodeUnauthorized {
+		t.Fatalf("expected unauthorized error without an authorizer, got %+v", resp)
+	}
+	if got := srv.Projects(); len(got) != 0 {
+		t.Fatalf("refused registration should not be recorded, got %v", got)
+	}
+}
+
+func TestIPCServer_ShareProjects(t *testing.T) {
+	unix := &IPCServer{registry: newProjectRegistry()}
+	tcp := &IPCServer{registry: newProjectRegistry()}
+	var calls int
+	unix.SetProjectRegistrar(func(string) error {
+		calls++
+		return nil
+	})
+	tcp.ShareProjects(unix)
+
+	if _, err := tcp.RegisterProject("/repo/api"); err != nil {
+		t.Fatalf("RegisterProject over tcp: %v", err)
+	}
+	if added, err := unix.RegisterProject("/repo/api"); err != nil || added {
+		t.Fatalf("re-registering over unix socket = %v, %v; want no-op", added, err)
+	}
+	if calls != 1 {
+		t.Errorf("registrar called %d times, want 1", calls)
+	}
+	if got := unix.Projects(); len(got) != 1 || got[0] != "/repo/api" {
+		t.Errorf("unix projects = %v, want [/repo/api]", got)
+	}
+}
+
 func TestIPCClient_Subscribe_NotConnected(t *testing.T) {
 	client := NewIPCClient("/nonexistent/test.sock")
 	ctx := context.Background()
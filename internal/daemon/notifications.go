@@ -35,6 +35,20 @@ const (
 	WebhookEventRequestTimeout WebhookEvent = "request_timeout"
 	// WebhookEventRequestEscalated is sent when a request is escalated.
 	WebhookEventRequestEscalated WebhookEvent = "request_escalated"
+	// WebhookEventSLABreach is sent when a request stays pending beyond its tier's SLA.
+	WebhookEventSLABreach WebhookEvent = "sla_breach"
+
+	// Lifecycle events, sent to notifications.webhook_urls.
+
+	// WebhookEventRequestPending is sent when a request starts waiting for review.
+	WebhookEventRequestPending WebhookEvent = "request_pending"
+	// WebhookEventRequestApproved is sent when a request gets its approvals.
+	WebhookEventRequestApproved WebhookEvent = "request_approved"
+	// WebhookEventRequestRejected is sent when a request is rejected.
+	WebhookEventRequestRejected WebhookEvent = "request_rejected"
+	// WebhookEventRequestExecuted is sent when a request's command finishes,
+	// whatever its outcome.
+	WebhookEventRequestExecuted WebhookEvent = "request_executed"
 )
 
 // WebhookPayload is the JSON payload sent to webhook URLs.
@@ -46,6 +60,8 @@ type WebhookPayload struct {
 	Requestor string       `json:"requestor"`
 	Timestamp string       `json:"timestamp"`
 	Project   string       `json:"project,omitempty"`
+	Status    string       `json:"status,omitempty"`
+	ExitCode  *int         `json:"exit_code,omitempty"`
 }
 
 // WebhookNotifier handles webhook notifications.
@@ -99,7 +115,12 @@ func (w *DefaultWebhookNotifier) Send(ctx context.Context, url string, payload W
 	if err != nil {
 		return fmt.Errorf("marshaling webhook payload: %w", err)
 	}
+	return w.Post(ctx, url, body)
+}
 
+// Post sends an already encoded JSON body to url, failing unless the server
+// answers with a 2xx status.
+func (w *DefaultWebhookNotifier) Post(ctx context.Context, url string, body []byte) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("creating webhook request: %w", err)
@@ -121,6 +142,47 @@ func (w *DefaultWebhookNotifier) Send(ctx context.Context, url string, payload W
 	return nil
 }
 
+// Ping checks that a webhook URL's server answers. Any HTTP response short
+// of a server error counts as reachable: endpoints commonly reject HEAD.
+func (w *DefaultWebhookNotifier) Ping(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("User-Agent", "SLB-Webhook/1.0")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("reaching webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WebhookIntegration checks the configured webhook URL; it implements
+// integrations.Pinger.
+type WebhookIntegration struct {
+	URL      string
+	notifier *DefaultWebhookNotifier
+}
+
+// NewWebhookIntegration creates a connectivity check for a webhook URL.
+func NewWebhookIntegration(url string) *WebhookIntegration {
+	return &WebhookIntegration{URL: url, notifier: NewDefaultWebhookNotifier()}
+}
+
+// Name implements integrations.Pinger.
+func (w *WebhookIntegration) Name() string { return "webhook" }
+
+// Ping implements integrations.Pinger.
+func (w *WebhookIntegration) Ping(ctx context.Context) error {
+	return w.notifier.Ping(ctx, w.URL)
+}
+
 func NewNotificationManager(projectPath string, cfg config.NotificationsConfig, logger *log.Logger, notifier DesktopNotifier) *NotificationManager {
 	if logger == nil {
 		logger = log.Default()
@@ -297,12 +359,9 @@ func (m *NotificationManager) Check(ctx context.Context) error {
 	return nil
 }
 
-// SendWebhook sends a webhook notification for a specific event (can be called directly).
-func (m *NotificationManager) SendWebhook(ctx context.Context, event WebhookEvent, req *db.Request) error {
-	if m == nil || m.webhook == nil || m.cfg.WebhookURL == "" {
-		return nil
-	}
-
+// webhookCommand returns the command to show in a webhook: redacted when
+// the request has sensitive parts, and cut to 140 bytes.
+func webhookCommand(req *db.Request) string {
 	cmd := req.Command.DisplayRedacted
 	if cmd == "" {
 		cmd = req.Command.Raw
@@ -311,11 +370,19 @@ func (m *NotificationManager) SendWebhook(ctx context.Context, event WebhookEven
 	if len(cmd) > 140 {
 		cmd = cmd[:140] + "…"
 	}
+	return cmd
+}
+
+// SendWebhook sends a webhook notification for a specific event (can be called directly).
+func (m *NotificationManager) SendWebhook(ctx context.Context, event WebhookEvent, req *db.Request) error {
+	if m == nil || m.webhook == nil || m.cfg.WebhookURL == "" {
+		return nil
+	}
 
 	payload := WebhookPayload{
 		Event:     event,
 		RequestID: req.ID,
-		Command:   cmd,
+		Command:   webhookCommand(req),
 		Tier:      string(req.RiskTier),
 		Requestor: req.RequestorAgent,
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
@@ -550,3 +550,41 @@ func TestNotificationManagerCheckWithNegativeDelay(t *testing.T) {
 	// Should not panic
 	_ = manager.Check(context.Background())
 }
+
+func TestWebhookIntegrationPing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("expected HEAD, got %s", r.Method)
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookIntegration(server.URL).Ping(context.Background()); err != nil {
+		t.Errorf("expected reachable webhook, got %v", err)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	if err := NewWebhookIntegration(failing.URL).Ping(context.Background()); err == nil {
+		t.Error("expected server error to be unreachable")
+	}
+}
+
+func TestConfiguredIntegrations(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Integrations.AgentMailEnabled = false
+	cfg.Notifications.WebhookURL = ""
+	if got := ConfiguredIntegrations(t.TempDir(), cfg); len(got) != 0 {
+		t.Fatalf("expected no integrations, got %d", len(got))
+	}
+
+	cfg.Integrations.AgentMailEnabled = true
+	cfg.Notifications.WebhookURL = "http://127.0.0.1:1/hook"
+	got := ConfiguredIntegrations(t.TempDir(), cfg)
+	if len(got) != 2 || got[0].Name() != "agent_mail" || got[1].Name() != "webhook" {
+		t.Fatalf("unexpected integrations: %v", got)
+	}
+}
@@ -0,0 +1,326 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/charmbracelet/log"
+)
+
+// ApprovalExpiredEvent is the event the sweeper emits when it parks a stale
+// approval in approval_expired.
+const ApprovalExpiredEvent = "request_approval_expired"
+
+// RequestSweeper enforces a project's request deadlines from the daemon (see
+// core.SweepExpiredRequests) and broadcasts each change to IPC subscribers:
+// request_timeout for pending requests that expired, and
+// request_approval_expired for approvals that went stale. It also releases
+// execution slots left behind by executors that died (see
+// core.RecoverStaleExecutionClaims) and, when configured, escalates requests
+// left pending too long (see core.ReviewService.EscalateStaleRequests),
+// broadcasting request_escalated. Given a request creator, it also admits
+// rate-limited requests from their queues as capacity frees up,
+// broadcasting request_pending for each and applying approval delegations
+// that cover it, under the review config given to SetReviewConfig. Delegations past their TTL are removed, and, given an idle
+// window, sessions not seen within it are ended.
+type RequestSweeper struct {
+	db          *db.DB
+	projectPath string
+	events      *IPCServer
+	logger      *log.Logger
+	now         func() time.Time
+	escalation  EscalationPolicy
+	review      core.ReviewConfig
+	admission   *core.RequestCreator
+	sessionIdle time.Duration
+}
+
+// EscalationPolicy configures the sweeper's time-boxed escalation of
+// pending requests.
+type EscalationPolicy struct {
+	// After is how long a request may stay pending before it is
+	// escalated. Zero disables escalation for tiers not in Tiers.
+	After time.Duration
+	// Tiers overrides After for the listed risk tiers.
+	Tiers map[db.RiskTier]time.Duration
+	// Notify is called for each escalated request, e.g. to alert the
+	// escalation webhook.
+	Notify func(*db.Request)
+}
+
+// Enabled reports whether any request can be escalated.
+func (p EscalationPolicy) Enabled() bool {
+	if p.After > 0 {
+		return true
+	}
+	for _, after := range p.Tiers {
+		if after > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// EscalationPolicyFromConfig reads general.escalate_after_minutes and each
+// tier's patterns.<tier>.escalate_after_minutes.
+func EscalationPolicyFromConfig(cfg config.Config) EscalationPolicy {
+	tiers := make(map[db.RiskTier]time.Duration)
+	for tier, mins := range map[db.RiskTier]int{
+		db.RiskTierCritical:  cfg.Patterns.Critical.EscalateAfterMinutes,
+		db.RiskTierDangerous: cfg.Patterns.Dangerous.EscalateAfterMinutes,
+		db.RiskTierCaution:   cfg.Patterns.Caution.EscalateAfterMinutes,
+	} {
+		if mins > 0 {
+			tiers[tier] = time.Duration(mins) * time.Minute
+		}
+	}
+	return EscalationPolicy{
+		After: time.Duration(cfg.General.EscalateAfterMinutes) * time.Minute,
+		Tiers: tiers,
+	}
+}
+
+// ReviewConfigFromConfig converts the review settings a project's config
+// sets. Every path that records reviews (the CLI, the TUI, the HTTP API and
+// the sweeper) builds its review service from it.
+func ReviewConfigFromConfig(cfg config.Config) core.ReviewConfig {
+	rc := core.DefaultReviewConfig()
+	rc.ConflictResolution = core.ConflictResolution(cfg.General.ConflictResolution)
+	rc.TrustedSelfApprove = cfg.Agents.TrustedSelfApprove
+	rc.TrustedSelfApproveDelay = time.Duration(cfg.Agents.TrustedSelfApproveDelaySecs) * time.Second
+	rc.DifferentModelTimeout = time.Duration(cfg.General.DifferentModelTimeoutSecs) * time.Second
+	escalation := EscalationPolicyFromConfig(cfg)
+	rc.EscalateAfter = escalation.After
+	rc.EscalateAfterTiers = escalation.Tiers
+	rc.ReviewerThresholds = ReviewerThresholdsFromConfig(cfg)
+	rc.ReviewerWeights = cfg.Agents.ReviewerWeightMap()
+	rc.AgentRoles = cfg.Agents.ReviewerRoleMap()
+	rc.SessionIdleTimeout = cfg.Agents.SessionIdleTimeout()
+	rc.UnviewedEvidenceAction = cfg.General.UnviewedEvidenceAction
+	rc.Anonymity = core.ReviewerAnonymity{
+		Enabled:  cfg.General.AnonymizeReviewers,
+		Auditors: cfg.General.ReviewAuditors,
+	}
+	return rc
+}
+
+// ReviewerThresholdsFromConfig converts the agents.reviewer_* settings.
+func ReviewerThresholdsFromConfig(cfg config.Config) core.ReviewerThresholds {
+	return core.ReviewerThresholds{
+		FastApproval:         time.Duration(cfg.Agents.ReviewerFastApprovalSecs) * time.Second,
+		EmptyResponsePercent: cfg.Agents.ReviewerEmptyResponsePercent,
+		ApprovalStreak:       cfg.Agents.ReviewerApprovalStreak,
+		MinReviews:           cfg.Agents.ReviewerMinReviews,
+		Window:               time.Duration(cfg.Agents.ReviewerWindowDays) * 24 * time.Hour,
+		ExcludeCritical:      cfg.Agents.ReviewerPatternAction == "exclude_critical",
+	}
+}
+
+// RateLimitConfigFromConfig converts the rate_limits settings. An unknown
+// rate_limit_action falls back to reject.
+func RateLimitConfigFromConfig(cfg config.Config) core.RateLimitConfig {
+	action := core.RateLimitAction(cfg.RateLimits.RateLimitAction)
+	switch action {
+	case core.RateLimitActionReject, core.RateLimitActionQueue, core.RateLimitActionWarn:
+	default:
+		action = core.RateLimitActionReject
+	}
+	return core.RateLimitConfig{
+		MaxPendingPerSession: cfg.RateLimits.MaxPendingPerSession,
+		MaxRequestsPerMinute: cfg.RateLimits.MaxRequestsPerMinute,
+		Action:               action,
+	}
+}
+
+// NewRequestSweeper creates a sweeper over a writable project database.
+// events may be nil, in which case nothing is broadcast.
+func NewRequestSweeper(database *db.DB, projectPath string, events *IPCServer, logger *log.Logger) *RequestSweeper {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &RequestSweeper{
+		db:          database,
+		projectPath: projectPath,
+		events:      events,
+		logger:      logger,
+		now:         time.Now,
+		review:      core.DefaultReviewConfig(),
+	}
+}
+
+// SetReviewConfig sets the review config delegations are applied and
+// requests escalated under, which should be the project's (see
+// ReviewConfigFromConfig).
+func (s *RequestSweeper) SetReviewConfig(cfg core.ReviewConfig) {
+	s.review = cfg
+}
+
+// SetEscalation makes each sweep escalate requests that have been pending
+// without a rejection for longer than policy allows.
+func (s *RequestSweeper) SetEscalation(policy EscalationPolicy) {
+	s.escalation = policy
+}
+
+// SetQueueAdmission makes each sweep admit the project's queued requests,
+// oldest first per session, within creator's rate limits.
+func (s *RequestSweeper) SetQueueAdmission(creator *core.RequestCreator) {
+	s.admission = creator
+}
+
+// SetSessionIdle makes each sweep end the project's sessions that have not
+// been seen for longer than idle, so agents that died without ending their
+// session stop counting as reviewers. Zero disables it.
+func (s *RequestSweeper) SetSessionIdle(idle time.Duration) {
+	s.sessionIdle = idle
+}
+
+// Run sweeps every interval until ctx is done.
+func (s *RequestSweeper) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+	_, _ = s.Check()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = s.Check()
+		}
+	}
+}
+
+// Check runs one sweep and broadcasts its changes.
+func (s *RequestSweeper) Check() (*core.SweepResult, error) {
+	result, err := core.SweepExpiredRequests(s.db, s.projectPath, s.now().UTC())
+	if err != nil {
+		s.logger.Warn("sweeping expired requests failed", "project", s.projectPath, "error", err)
+	}
+	if result == nil {
+		return nil, err
+	}
+	for _, req := range result.TimedOut {
+		s.logger.Info("request timed out", "request_id", req.ID, "tier", req.RiskTier)
+		s.broadcast("request_timeout", req, map[string]any{"expired_at": formatDeadline(req.ExpiresAt)})
+	}
+	for _, req := range result.ApprovalsExpired {
+		s.logger.Info("approval expired; awaiting re-request", "request_id", req.ID, "tier", req.RiskTier)
+		s.broadcast(ApprovalExpiredEvent, req, map[string]any{"expired_at": formatDeadline(req.ApprovalExpiresAt)})
+	}
+	for _, d := range result.DelegationsExpired {
+		s.logger.Info("delegation expired", "delegation_id", d.ID, "pattern", d.Pattern, "agent", d.AgentName)
+	}
+
+	recovered, claimErr := core.RecoverStaleExecutionClaims(s.db, s.projectPath, s.now().UTC())
+	if claimErr != nil {
+		s.logger.Warn("recovering stale execution slots failed", "project", s.projectPath, "error", claimErr)
+		if err == nil {
+			err = claimErr
+		}
+	}
+	for _, c := range recovered {
+		s.logger.Info("released stale execution slot", "request_id", c.RequestID, "held", c.Held(), "pid", c.PID, "host", c.Hostname)
+	}
+
+	if s.sessionIdle > 0 {
+		gc, gcErr := core.GarbageCollectStaleSessions(s.db, core.SessionGCOptions{
+			ProjectPath: s.projectPath,
+			Threshold:   s.sessionIdle,
+		})
+		if gcErr != nil {
+			s.logger.Warn("ending idle sessions failed", "project", s.projectPath, "error", gcErr)
+			if err == nil {
+				err = gcErr
+			}
+		} else {
+			for _, sess := range gc.Sessions {
+				s.logger.Info("ended idle session", "session_id", sess.ID, "agent", sess.AgentName, "last_seen", sess.LastActiveAt.Format(time.RFC3339))
+			}
+		}
+	}
+
+	// Admit after the timeouts above, which may have freed pending slots.
+	if s.admission != nil {
+		admitted, admitErr := s.admission.AdmitProjectQueued(s.projectPath)
+		if admitErr != nil {
+			s.logger.Warn("admitting queued requests failed", "project", s.projectPath, "error", admitErr)
+			if err == nil {
+				err = admitErr
+			}
+		}
+		result.Admitted = admitted
+		for _, req := range admitted {
+			s.logger.Info("queued request admitted", "request_id", req.ID, "tier", req.RiskTier)
+			s.broadcast(string(WebhookEventRequestPending), req, map[string]any{"admitted_from_queue": true})
+			delegated, delErr := core.NewReviewService(s.db, s.review).ApplyDelegations(req)
+			if delErr != nil {
+				s.logger.Warn("applying delegations failed", "request_id", req.ID, "error", delErr)
+			}
+			for _, r := range delegated {
+				s.logger.Info("approved under delegation", "request_id", req.ID, "delegation_id", r.Review.DelegationID, "reviewer", r.Review.ReviewerAgent)
+				if eventType, payload, ok := ReviewOutcomeEvent(req, r); ok && s.events != nil {
+					s.events.BroadcastEvent(eventType, payload)
+				}
+			}
+		}
+	}
+
+	if s.escalation.Enabled() {
+		reviewCfg := s.review
+		reviewCfg.EscalateAfter = s.escalation.After
+		reviewCfg.EscalateAfterTiers = s.escalation.Tiers
+		now := s.now().UTC()
+		escalated, escErr := core.NewReviewService(s.db, reviewCfg).EscalateStaleRequests(now)
+		if escErr != nil {
+			s.logger.Warn("escalating stale requests failed", "project", s.projectPath, "error", escErr)
+			if err == nil {
+				err = escErr
+			}
+		}
+		result.Escalated = escalated
+		for _, req := range escalated {
+			pending := now.Sub(req.CreatedAt)
+			s.logger.Warn("request escalated after pending too long - human intervention required",
+				"request_id", req.ID, "tier", req.RiskTier, "pending", pending.Round(time.Second))
+			s.broadcast(string(WebhookEventRequestEscalated), req, map[string]any{"pending_seconds": int64(pending.Seconds())})
+			if s.escalation.Notify != nil {
+				s.escalation.Notify(req)
+			}
+		}
+	}
+	return result, err
+}
+
+func (s *RequestSweeper) broadcast(eventType string, req *db.Request, extra map[string]any) {
+	if s.events == nil {
+		return
+	}
+	command := req.Command.Raw
+	if req.Command.DisplayRedacted != "" {
+		command = req.Command.DisplayRedacted
+	}
+	payload := map[string]any{
+		"request_id":   req.ID,
+		"project_path": req.ProjectPath,
+		"risk_tier":    string(req.RiskTier),
+		"command":      command,
+		"requestor":    req.RequestorAgent,
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	s.events.BroadcastEvent(eventType, payload)
+}
+
+func formatDeadline(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
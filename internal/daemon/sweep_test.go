@@ -0,0 +1,288 @@
+package daemon
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestRequestSweeper_BroadcastsChanges(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	sess := testutil.MakeSession(t, database)
+	expired := time.Now().Add(-time.Minute)
+	pending := testutil.MakeRequest(t, database, sess, testutil.WithExpiresAt(expired))
+	approved := testutil.MakeRequest(t, database, sess, func(r *db.Request) { r.ApprovalExpiresAt = &expired })
+	if err := database.UpdateRequestStatus(approved.ID, db.StatusApproved); err != nil {
+		t.Fatal(err)
+	}
+
+	ipc, err := NewIPCServer(filepath.Join(shortSocketDir(t), "s.sock"), nil)
+	if err != nil {
+		t.Fatalf("NewIPCServer: %v", err)
+	}
+	events, cancel := ipc.Subscribe(SubscribeParams{})
+	defer cancel()
+
+	result, err := NewRequestSweeper(database, sess.ProjectPath, ipc, nil).Check()
+	if err != nil || len(result.TimedOut) != 1 || len(result.ApprovalsExpired) != 1 {
+		t.Fatalf("unexpected sweep %+v, %v", result, err)
+	}
+
+	got := map[string]map[string]any{}
+	for len(got) < 2 {
+		select {
+		case event := <-events:
+			payload, _ := event.Payload.(map[string]any)
+			got[event.Type] = payload
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for events, got %v", got)
+		}
+	}
+	if p := got["request_timeout"]; p == nil || p["request_id"] != pending.ID {
+		t.Errorf("expected a request_timeout event for %s, got %v", pending.ID, p)
+	}
+	if p := got[ApprovalExpiredEvent]; p == nil || p["request_id"] != approved.ID || p["expired_at"] == "" {
+		t.Errorf("expected a %s event for %s, got %v", ApprovalExpiredEvent, approved.ID, p)
+	}
+}
+
+func TestRequestSweeper_AdmitsQueuedRequests(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	sess := testutil.MakeSession(t, database)
+	pending := testutil.MakeRequest(t, database, sess)
+	first := testutil.MakeRequest(t, database, sess, testutil.WithStatus(db.StatusQueued))
+	second := testutil.MakeRequest(t, database, sess, testutil.WithStatus(db.StatusQueued))
+
+	ipc, err := NewIPCServer(filepath.Join(shortSocketDir(t), "s.sock"), nil)
+	if err != nil {
+		t.Fatalf("NewIPCServer: %v", err)
+	}
+	events, cancel := ipc.Subscribe(SubscribeParams{})
+	defer cancel()
+
+	sweeper := NewRequestSweeper(database, sess.ProjectPath, ipc, nil)
+	rl := core.NewRateLimiter(database, core.RateLimitConfig{MaxPendingPerSession: 1, MaxRequestsPerMinute: 100, Action: core.RateLimitActionQueue})
+	sweeper.SetQueueAdmission(core.NewRequestCreator(database, rl, nil, nil))
+
+	if result, err := sweeper.Check(); err != nil || len(result.Admitted) != 0 {
+		t.Fatalf("expected nothing admitted while the session is full, got %+v, %v", result, err)
+	}
+
+	if err := database.UpdateRequestStatus(pending.ID, db.StatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	result, err := sweeper.Check()
+	if err != nil || len(result.Admitted) != 1 || result.Admitted[0].ID != first.ID {
+		t.Fatalf("expected the oldest queued request to be admitted, got %+v, %v", result, err)
+	}
+	if got, _ := database.GetRequest(second.ID); got.Status != db.StatusQueued {
+		t.Errorf("expected the second request to stay queued, got %s", got.Status)
+	}
+
+	select {
+	case event := <-events:
+		payload, _ := event.Payload.(map[string]any)
+		if event.Type != "request_pending" || payload["request_id"] != first.ID || payload["admitted_from_queue"] != true {
+			t.Errorf("expected request_pending for %s, got %s %v", first.ID, event.Type, payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for request_pending")
+	}
+}
+
+func TestRequestSweeper_AppliesDelegationsUnderReviewConfig(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	requestor := testutil.MakeSession(t, database)
+	reviewer := testutil.MakeSession(t, database, testutil.WithProject(requestor.ProjectPath))
+	d, err := core.NewDelegation(reviewer, reviewer.ProjectPath, "terraform apply -target=module.cache*", db.RiskTierDangerous, time.Hour, "", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.CreateDelegation(d); err != nil {
+		t.Fatal(err)
+	}
+	// Two approvals are needed, but the project weighs the reviewer's as two.
+	req := testutil.MakeRequest(t, database, requestor,
+		testutil.WithCommand("terraform apply -target=module.cache_redis", requestor.ProjectPath, true),
+		testutil.WithStatus(db.StatusQueued), func(r *db.Request) { r.MinApprovals = 2 })
+
+	ipc, err := NewIPCServer(filepath.Join(shortSocketDir(t), "s.sock"), nil)
+	if err != nil {
+		t.Fatalf("NewIPCServer: %v", err)
+	}
+	events, cancel := ipc.Subscribe(SubscribeParams{})
+	defer cancel()
+
+	sweeper := NewRequestSweeper(database, requestor.ProjectPath, ipc, nil)
+	rl := core.NewRateLimiter(database, core.RateLimitConfig{MaxPendingPerSession: 5, MaxRequestsPerMinute: 100, Action: core.RateLimitActionQueue})
+	sweeper.SetQueueAdmission(core.NewRequestCreator(database, rl, nil, nil))
+	reviewCfg := core.DefaultReviewConfig()
+	reviewCfg.ConflictResolution = core.ConflictWeightedQuorum
+	reviewCfg.ReviewerWeights = map[string]int{reviewer.AgentName: 2}
+	sweeper.SetReviewConfig(reviewCfg)
+
+	if result, err := sweeper.Check(); err != nil || len(result.Admitted) != 1 {
+		t.Fatalf("expected the request admitted, got %+v, %v", result, err)
+	}
+	if got, _ := database.GetRequest(req.ID); got.Status != db.StatusApproved {
+		t.Fatalf("expected the weighted delegated approval to approve, got %s", got.Status)
+	}
+
+	got := map[string]map[string]any{}
+	for len(got) < 2 {
+		select {
+		case event := <-events:
+			payload, _ := event.Payload.(map[string]any)
+			got[event.Type] = payload
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for events, got %v", got)
+		}
+	}
+	if p := got["request_approved"]; p == nil || p["request_id"] != req.ID || p["approved_by"] != reviewer.AgentName {
+		t.Errorf("expected request_approved for %s by %s, got %v", req.ID, reviewer.AgentName, p)
+	}
+}
+
+func TestRequestSweeper_EscalatesStaleRequests(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	sess := testutil.MakeSession(t, database)
+	later := testutil.WithExpiresAt(time.Now().Add(24 * time.Hour))
+	dangerous := testutil.MakeRequest(t, database, sess, later, testutil.WithRisk(db.RiskTierDangerous))
+	critical := testutil.MakeRequest(t, database, sess, later, testutil.WithRisk(db.RiskTierCritical))
+	caution := testutil.MakeRequest(t, database, sess, later, testutil.WithRisk(db.RiskTierCaution))
+
+	ipc, err := NewIPCServer(filepath.Join(shortSocketDir(t), "s.sock"), nil)
+	if err != nil {
+		t.Fatalf("NewIPCServer: %v", err)
+	}
+	events, cancel := ipc.Subscribe(SubscribeParams{})
+	defer cancel()
+
+	sweeper := NewRequestSweeper(database, sess.ProjectPath, ipc, nil)
+	sweeper.now = func() time.Time { return dangerous.CreatedAt.Add(45 * time.Minute) }
+	if result, err := sweeper.Check(); err != nil || len(result.Escalated) != 0 {
+		t.Fatalf("expected no escalation by default, got %+v, %v", result, err)
+	}
+
+	// DANGEROUS escalates after 30 minutes, CRITICAL after the general
+	// hour, and CAUTION not at all.
+	var notified []string
+	sweeper.SetEscalation(EscalationPolicy{
+		After:  time.Hour,
+		Tiers:  map[db.RiskTier]time.Duration{db.RiskTierDangerous: 30 * time.Minute},
+		Notify: func(r *db.Request) { notified = append(notified, r.ID) },
+	})
+	result, err := sweeper.Check()
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(result.Escalated) != 1 || result.Escalated[0].ID != dangerous.ID {
+		t.Fatalf("expected only the DANGEROUS request escalated, got %+v", result.Escalated)
+	}
+	if len(notified) != 1 || notified[0] != dangerous.ID {
+		t.Errorf("expected the escalation target notified of %s, got %v", dangerous.ID, notified)
+	}
+	select {
+	case event := <-events:
+		payload, _ := event.Payload.(map[string]any)
+		if event.Type != "request_escalated" || payload["request_id"] != dangerous.ID {
+			t.Errorf("unexpected event %s %v", event.Type, payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for request_escalated")
+	}
+
+	want := map[string]db.RequestStatus{
+		dangerous.ID: db.StatusEscalated,
+		critical.ID:  db.StatusPending,
+		caution.ID:   db.StatusPending,
+	}
+	for id, status := range want {
+		if got, _ := database.GetRequest(id); got.Status != status {
+			t.Errorf("request %s: status %s, want %s", id, got.Status, status)
+		}
+	}
+
+	sweeper.now = func() time.Time { return dangerous.CreatedAt.Add(2 * time.Hour) }
+	if result, err := sweeper.Check(); err != nil || len(result.Escalated) != 2 {
+		t.Fatalf("expected CRITICAL and CAUTION to escalate after the general hour, got %+v, %v", result, err)
+	}
+}
+
+func TestRequestSweeper_EndsIdleSessions(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	live := testutil.MakeSession(t, database, testutil.SessionWithAgentName("Live"))
+	idle := testutil.MakeSession(t, database, testutil.SessionWithAgentName("Idle"), testutil.SessionWithProject(live.ProjectPath))
+	lastSeen := time.Now().UTC().Add(-2 * time.Hour).Format(time.RFC3339)
+	if _, err := database.Exec(`UPDATE sessions SET last_active_at = ? WHERE id = ?`, lastSeen, idle.ID); err != nil {
+		t.Fatalf("backdating session: %v", err)
+	}
+
+	sweeper := NewRequestSweeper(database, live.ProjectPath, nil, nil)
+	if _, err := sweeper.Check(); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if got, _ := database.GetSession(idle.ID); !got.IsActive() {
+		t.Fatal("expected no session ended without an idle window")
+	}
+
+	sweeper.SetSessionIdle(time.Hour)
+	if _, err := sweeper.Check(); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if got, _ := database.GetSession(idle.ID); got.IsActive() {
+		t.Error("expected the idle session to be ended")
+	}
+	if got, _ := database.GetSession(live.ID); !got.IsActive() {
+		t.Error("expected the live session to stay active")
+	}
+}
+
+func TestReviewConfigFromConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.General.ConflictResolution = "weighted_quorum"
+	cfg.General.UnviewedEvidenceAction = core.UnviewedEvidenceBlockCritical
+	cfg.General.EscalateAfterMinutes = 30
+	cfg.Agents.ReviewerPatternAction = "exclude_critical"
+
+	rc := ReviewConfigFromConfig(cfg)
+	if rc.ConflictResolution != core.ConflictWeightedQuorum || rc.UnviewedEvidenceAction != core.UnviewedEvidenceBlockCritical {
+		t.Errorf("unexpected review config %+v", rc)
+	}
+	if rc.EscalateAfter != 30*time.Minute || !rc.ReviewerThresholds.ExcludeCritical {
+		t.Errorf("escalation or thresholds not converted: %+v", rc)
+	}
+	if rc.ReviewerThresholds != TimeoutConfigFromConfig(cfg).ReviewerThresholds {
+		t.Error("review and timeout configs disagree on the reviewer thresholds")
+	}
+}
+
+func TestRateLimitConfigFromConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RateLimits.MaxPendingPerSession = 2
+	cfg.RateLimits.RateLimitAction = "queue"
+	if rl := RateLimitConfigFromConfig(cfg); rl.Action != core.RateLimitActionQueue || rl.MaxPendingPerSession != 2 {
+		t.Errorf("unexpected rate limit config %+v", rl)
+	}
+	cfg.RateLimits.RateLimitAction = "sometimes"
+	if rl := RateLimitConfigFromConfig(cfg); rl.Action != core.RateLimitActionReject {
+		t.Errorf("expected an unknown action to fall back to reject, got %q", rl.Action)
+	}
+}
+
+func TestEscalationPolicyFromConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if EscalationPolicyFromConfig(cfg).Enabled() {
+		t.Fatal("expected escalation disabled by default")
+	}
+	cfg.Patterns.Dangerous.EscalateAfterMinutes = 20
+	policy := EscalationPolicyFromConfig(cfg)
+	if !policy.Enabled() || policy.After != 0 || policy.Tiers[db.RiskTierDangerous] != 20*time.Minute {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+}
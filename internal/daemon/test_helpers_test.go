@@ -11,7 +11,7 @@ import (
 // shortSocketDir creates a temp directory with a short path for Unix socket tests.
 // macOS has a 104-byte limit on Unix socket paths, and t.TempDir() includes the
 // full test name which can easily exceed this limit.
-func shortSocketDir(t *testing.T) string {
+func shortSocketDir(t testing.TB) string {
 	t.Helper()
 
 	// Generate a short random suffix
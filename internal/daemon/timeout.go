@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/charmbracelet/log"
 )
@@ -27,6 +28,10 @@ const (
 	TimeoutActionAutoApproveWarn TimeoutAction = "auto_approve_warn"
 )
 
+// PolicyAttestationDueEvent is broadcast to IPC subscribers when the
+// project's auto-approve policy needs re-attestation.
+const PolicyAttestationDueEvent = "policy_attestation_due"
+
 // DefaultCheckInterval is the default interval for checking expired requests.
 const DefaultCheckInterval = 10 * time.Second
 
@@ -36,23 +41,69 @@ type TimeoutHandlerConfig struct {
 	CheckInterval time.Duration
 	// Action determines what happens when a request times out.
 	Action TimeoutAction
+	// HandleExpired applies Action to expired requests on each scan. The
+	// daemon's project reapers leave it off and only run the monitoring
+	// checks (SLA, attestation, reviewer patterns), so enabling them never
+	// changes request state.
+	HandleExpired bool
 	// DesktopNotify enables desktop notifications on escalation.
 	DesktopNotify bool
+	// NotifyStatuses limits those notifications to the listed statuses
+	// (see NotifiesStatus).
+	NotifyStatuses []string
+	// SLAs maps risk tiers to the longest a request may stay pending before
+	// an sla_breach event is emitted. Tiers without a positive SLA are not tracked.
+	SLAs map[db.RiskTier]time.Duration
+	// OnSLABreach is called once per request that breaches its tier's SLA.
+	OnSLABreach func(SLABreach)
+	// ProjectPath identifies the project whose policy attestation is
+	// checked on each scan.
+	ProjectPath string
+	// PolicyHash returns the current hash of the project's effective
+	// auto-approve settings. It is called on every scan so edits to any
+	// config layer are noticed without restarting the daemon.
+	PolicyHash func() (string, error)
+	// AttestationCadence is how often the policy must be re-attested.
+	// Zero disables attestation warnings.
+	AttestationCadence time.Duration
+	// AttestationGrace is how long past due before auto-approval is refused.
+	AttestationGrace time.Duration
+	// OnAttestationDue is called when the policy attestation becomes due,
+	// invalid or missing (and again whenever that status changes).
+	OnAttestationDue func(db.AttestationCheck)
+	// ReviewerThresholds flag rubber-stamp reviewers on each scan; disabled
+	// when no threshold is set.
+	ReviewerThresholds core.ReviewerThresholds
+	// OnReviewerPattern is called when a reviewer becomes flagged (and again
+	// whenever its warnings change).
+	OnReviewerPattern func(core.ReviewerStats)
 	// Logger for timeout events.
 	Logger *log.Logger
 }
 
+// SLABreach describes a pending request that has exceeded its tier's SLA.
+type SLABreach struct {
+	Request *db.Request
+	// Pending is how long the request has been pending.
+	Pending time.Duration
+	// SLA is the threshold that was exceeded.
+	SLA time.Duration
+}
+
 // DefaultTimeoutConfig returns the default timeout handler configuration.
 func DefaultTimeoutConfig() TimeoutHandlerConfig {
 	return TimeoutHandlerConfig{
 		CheckInterval: DefaultCheckInterval,
 		Action:        TimeoutActionEscalate,
+		HandleExpired: true,
 		DesktopNotify: true,
 		Logger:        nil,
 	}
 }
 
 // TimeoutConfigFromConfig creates a TimeoutHandlerConfig from the app config.
+// Expiry handling is left off; callers that want Action applied must set
+// HandleExpired explicitly.
 func TimeoutConfigFromConfig(cfg config.Config) TimeoutHandlerConfig {
 	action := TimeoutAction(cfg.General.TimeoutAction)
 	switch action {
@@ -62,11 +113,27 @@ func TimeoutConfigFromConfig(cfg config.Config) TimeoutHandlerConfig {
 		action = TimeoutActionEscalate
 	}
 
+	slas := make(map[db.RiskTier]time.Duration)
+	for tier, secs := range map[db.RiskTier]int{
+		db.RiskTierCritical:  cfg.Patterns.Critical.SLASeconds,
+		db.RiskTierDangerous: cfg.Patterns.Dangerous.SLASeconds,
+		db.RiskTierCaution:   cfg.Patterns.Caution.SLASeconds,
+	} {
+		if secs > 0 {
+			slas[tier] = time.Duration(secs) * time.Second
+		}
+	}
+
 	return TimeoutHandlerConfig{
-		CheckInterval: DefaultCheckInterval,
-		Action:        action,
-		DesktopNotify: cfg.Notifications.DesktopEnabled,
-		Logger:        nil,
+		CheckInterval:      DefaultCheckInterval,
+		Action:             action,
+		DesktopNotify:      cfg.Notifications.DesktopEnabled,
+		NotifyStatuses:     cfg.Notifications.NotifyStatuses,
+		SLAs:               slas,
+		AttestationCadence: time.Duration(cfg.General.PolicyAttestationDays) * 24 * time.Hour,
+		AttestationGrace:   time.Duration(cfg.General.PolicyAttestationGraceDays) * 24 * time.Hour,
+		ReviewerThresholds: ReviewerThresholdsFromConfig(cfg),
+		Logger:             nil,
 	}
 }
 
@@ -79,6 +146,13 @@ type TimeoutHandler struct {
 	mu      sync.Mutex
 	running bool
 	stopCh  chan struct{}
+	// slaNotified caches the pending requests whose breach was announced;
+	// requests.sla_notified_at is the record that survives restarts.
+	slaNotified map[string]bool
+	// attestationNotified is the last attestation status warned about.
+	attestationNotified string
+	// reviewerNotified maps flagged reviewers to the warnings last reported.
+	reviewerNotified map[string]string
 }
 
 // NewTimeoutHandler creates a new timeout handler.
@@ -89,9 +163,11 @@ func NewTimeoutHandler(database *db.DB, cfg TimeoutHandlerConfig) *TimeoutHandle
 	}
 
 	return &TimeoutHandler{
-		db:     database,
-		config: cfg,
-		logger: logger,
+		db:               database,
+		config:           cfg,
+		logger:           logger,
+		slaNotified:      make(map[string]bool),
+		reviewerNotified: make(map[string]string),
 	}
 }
 
@@ -139,7 +215,7 @@ func (h *TimeoutHandler) run(ctx context.Context) {
 	defer ticker.Stop()
 
 	// Do an initial check immediately
-	h.checkAndHandleExpired()
+	h.scan()
 
 	for {
 		select {
@@ -151,11 +227,22 @@ func (h *TimeoutHandler) run(ctx context.Context) {
 		case <-h.stopCh:
 			return
 		case <-ticker.C:
-			h.checkAndHandleExpired()
+			h.scan()
 		}
 	}
 }
 
+// scan is one reaper pass: expire timed-out requests when enabled, then run
+// the monitoring checks.
+func (h *TimeoutHandler) scan() {
+	if h.config.HandleExpired {
+		h.checkAndHandleExpired()
+	}
+	h.checkSLABreaches(time.Now())
+	h.checkPolicyAttestation(time.Now())
+	h.checkReviewerPatterns()
+}
+
 // checkAndHandleExpired finds and processes all expired requests.
 func (h *TimeoutHandler) checkAndHandleExpired() {
 	expired, err := h.db.FindExpiredRequests()
@@ -173,6 +260,176 @@ func (h *TimeoutHandler) checkAndHandleExpired() {
 	}
 }
 
+// checkSLABreaches emits an sla_breach for each pending request newly past its tier's SLA.
+func (h *TimeoutHandler) checkSLABreaches(now time.Time) {
+	if len(h.config.SLAs) == 0 {
+		return
+	}
+
+	pending, err := h.db.ListPendingRequestsAllProjects()
+	if err != nil {
+		h.logger.Error("failed to list pending requests", "error", err)
+		return
+	}
+
+	h.pruneSLANotified(pending)
+	for _, breach := range FindSLABreaches(pending, h.config.SLAs, now) {
+		if !h.markSLABreach(breach.Request.ID, now) {
+			continue
+		}
+
+		h.logger.Warn("request pending beyond SLA",
+			"request_id", breach.Request.ID,
+			"tier", breach.Request.RiskTier,
+			"pending", breach.Pending.Round(time.Second),
+			"sla", breach.SLA)
+
+		if h.config.OnSLABreach != nil {
+			h.config.OnSLABreach(breach)
+		}
+	}
+}
+
+// markSLABreach records that a breach was emitted, returning false if it
+// already was, by this daemon or one before it. A breach that cannot be
+// recorded is not emitted, so it is retried on the next scan.
+func (h *TimeoutHandler) markSLABreach(requestID string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.slaNotified[requestID] {
+		return false
+	}
+	marked, err := h.db.MarkSLANotified(requestID, now)
+	if err != nil {
+		h.logger.Error("failed to record sla breach", "request_id", requestID, "error", err)
+		return false
+	}
+	h.slaNotified[requestID] = true
+	return marked
+}
+
+// pruneSLANotified forgets the breaches of requests no longer pending.
+func (h *TimeoutHandler) pruneSLANotified(pending []*db.Request) {
+	still := make(map[string]bool, len(pending))
+	for _, req := range pending {
+		still[req.ID] = true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id := range h.slaNotified {
+		if !still[id] {
+			delete(h.slaNotified, id)
+		}
+	}
+}
+
+// checkPolicyAttestation warns when the project's auto-approve policy is due
+// for re-attestation or has changed since it was attested.
+func (h *TimeoutHandler) checkPolicyAttestation(now time.Time) {
+	if h.config.AttestationCadence <= 0 || h.config.PolicyHash == nil {
+		return
+	}
+
+	hash, err := h.config.PolicyHash()
+	if err != nil {
+		h.logger.Error("failed to hash auto-approve policy", "error", err)
+		return
+	}
+	check, err := h.db.CheckPolicyAttestation(h.config.ProjectPath, hash,
+		h.config.AttestationCadence, h.config.AttestationGrace, now)
+	if err != nil {
+		h.logger.Error("failed to check policy attestation", "error", err)
+		return
+	}
+	if !check.NeedsAttention() {
+		h.markAttestation("")
+		return
+	}
+	if !h.markAttestation(fmt.Sprintf("%s:%s:%t", check.State, check.PolicyHash, check.Refuse)) {
+		return
+	}
+
+	h.logger.Warn("auto-approve policy needs re-attestation",
+		"project", h.config.ProjectPath,
+		"state", check.State,
+		"refusing", check.Refuse)
+
+	if h.config.OnAttestationDue != nil {
+		h.config.OnAttestationDue(check)
+	}
+}
+
+// markAttestation records the attestation status last warned about,
+// returning false if it is unchanged.
+func (h *TimeoutHandler) markAttestation(key string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.attestationNotified == key {
+		return false
+	}
+	h.attestationNotified = key
+	return true
+}
+
+// checkReviewerPatterns warns about reviewers whose approvals exceed the
+// configured fatigue thresholds.
+func (h *TimeoutHandler) checkReviewerPatterns() {
+	if !h.config.ReviewerThresholds.Enabled() {
+		return
+	}
+
+	stats, err := core.ReviewerPatternReport(h.db, h.config.ReviewerThresholds)
+	if err != nil {
+		h.logger.Error("failed to compute reviewer patterns", "error", err)
+		return
+	}
+	for _, s := range stats {
+		if !h.markReviewerPattern(s.Agent, strings.Join(s.Warnings, ",")) || !s.Flagged() {
+			continue
+		}
+
+		h.logger.Warn("reviewer matches rubber-stamp approval patterns",
+			"reviewer", s.Agent,
+			"warnings", s.Warnings,
+			"excluded_from_critical", s.ExcludedFromCritical)
+
+		if h.config.OnReviewerPattern != nil {
+			h.config.OnReviewerPattern(s)
+		}
+	}
+}
+
+// markReviewerPattern records the warnings last reported for a reviewer,
+// returning false if they are unchanged.
+func (h *TimeoutHandler) markReviewerPattern(agent, warnings string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.reviewerNotified[agent] == warnings {
+		return false
+	}
+	h.reviewerNotified[agent] = warnings
+	return true
+}
+
+// FindSLABreaches returns the pending requests that have been pending longer
+// than the SLA for their tier at now. Requests in tiers without an SLA are skipped.
+func FindSLABreaches(requests []*db.Request, slas map[db.RiskTier]time.Duration, now time.Time) []SLABreach {
+	var breaches []SLABreach
+	for _, req := range requests {
+		if req == nil || req.Status != db.StatusPending {
+			continue
+		}
+		sla, ok := slas[req.RiskTier]
+		if !ok || sla <= 0 {
+			continue
+		}
+		if pending := now.Sub(req.CreatedAt); pending > sla {
+			breaches = append(breaches, SLABreach{Request: req, Pending: pending, SLA: sla})
+		}
+	}
+	return breaches
+}
+
 // HandleExpiredRequest processes a single expired request according to the configured action.
 func (h *TimeoutHandler) HandleExpiredRequest(req *db.Request) error {
 	h.logger.Info("handling expired request",
@@ -205,7 +462,7 @@ func (h *TimeoutHandler) handleEscalate(req *db.Request) error {
 		"tier", req.RiskTier)
 
 	// Send desktop notification if enabled
-	if h.config.DesktopNotify {
+	if h.config.DesktopNotify && NotifiesStatus(h.config.NotifyStatuses, db.StatusEscalated) {
 		h.sendDesktopNotification(req)
 	}
 
@@ -249,6 +506,13 @@ func (h *TimeoutHandler) handleAutoApproveWarn(req *db.Request) error {
 			"tier", req.RiskTier)
 		return h.handleEscalate(req)
 	}
+	// Nor one whose targets are only known when the shell expands them
+	if core.HasRuntimeTargets(req.Command.Raw) {
+		h.logger.Warn("refusing to auto-approve request with runtime-derived targets, escalating instead",
+			"request_id", req.ID,
+			"tier", req.RiskTier)
+		return h.handleEscalate(req)
+	}
 
 	// For CAUTION tier, we can auto-approve with warning
 	if err := h.db.UpdateRequestStatus(req.ID, db.StatusApproved); err != nil {
@@ -261,7 +525,7 @@ func (h *TimeoutHandler) handleAutoApproveWarn(req *db.Request) error {
 		"agent", req.RequestorAgent)
 
 	// Send warning notification
-	if h.config.DesktopNotify {
+	if h.config.DesktopNotify && NotifiesStatus(h.config.NotifyStatuses, db.StatusApproved) {
 		h.sendAutoApproveWarning(req)
 	}
 
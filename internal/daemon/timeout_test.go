@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/testutil"
 )
@@ -309,6 +311,7 @@ func TestTimeoutHandler_ChecksExpiredRequests(t *testing.T) {
 	cfg := TimeoutHandlerConfig{
 		CheckInterval: 50 * time.Millisecond,
 		Action:        TimeoutActionEscalate,
+		HandleExpired: true,
 		DesktopNotify: false,
 		Logger:        nil,
 	}
@@ -496,3 +499,297 @@ func TestTimeoutHandler_WithNotifications(t *testing.T) {
 		t.Fatalf("HandleExpiredRequest failed: %v", err)
 	}
 }
+
+func TestFindSLABreaches(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	slas := map[db.RiskTier]time.Duration{
+		db.RiskTierCritical:  5 * time.Minute,
+		db.RiskTierDangerous: 15 * time.Minute,
+	}
+	mk := func(id string, tier db.RiskTier, status db.RequestStatus, age time.Duration) *db.Request {
+		return &db.Request{ID: id, RiskTier: tier, Status: status, CreatedAt: now.Add(-age)}
+	}
+
+	requests := []*db.Request{
+		mk("critical-old", db.RiskTierCritical, db.StatusPending, 6*time.Minute),
+		mk("critical-new", db.RiskTierCritical, db.StatusPending, 4*time.Minute),
+		mk("critical-exact", db.RiskTierCritical, db.StatusPending, 5*time.Minute),
+		mk("dangerous-old", db.RiskTierDangerous, db.StatusPending, time.Hour),
+		mk("dangerous-new", db.RiskTierDangerous, db.StatusPending, 10*time.Minute),
+		mk("caution-ancient", db.RiskTierCaution, db.StatusPending, 24*time.Hour),
+		mk("critical-approved", db.RiskTierCritical, db.StatusApproved, time.Hour),
+		nil,
+	}
+
+	breaches := FindSLABreaches(requests, slas, now)
+
+	var ids []string
+	for _, b := range breaches {
+		ids = append(ids, b.Request.ID)
+	}
+	if got, want := strings.Join(ids, ","), "critical-old,dangerous-old"; got != want {
+		t.Fatalf("breaches = %s, want %s", got, want)
+	}
+	if breaches[0].Pending != 6*time.Minute || breaches[0].SLA != 5*time.Minute {
+		t.Errorf("unexpected breach detail: %+v", breaches[0])
+	}
+
+	if got := FindSLABreaches(requests, nil, now); len(got) != 0 {
+		t.Errorf("expected no breaches without SLAs, got %d", len(got))
+	}
+}
+
+func TestTimeoutHandler_ProjectReaperLeavesExpiredRequests(t *testing.T) {
+	database := testutil.TempDB(t)
+
+	session := &db.Session{
+		ID:          "sess-6",
+		AgentName:   "TestAgent",
+		Program:     "test",
+		Model:       "test-model",
+		ProjectPath: "/test/project",
+	}
+	if err := database.CreateSession(session); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	expiredAt := time.Now().Add(-1 * time.Hour)
+	req := &db.Request{
+		ID:                 "req-expired-6",
+		ProjectPath:        "/test/project",
+		Command:            db.CommandSpec{Raw: "echo test", Cwd: "/", Shell: true},
+		RiskTier:           db.RiskTierCaution,
+		RequestorSessionID: "sess-6",
+		RequestorAgent:     "TestAgent",
+		RequestorModel:     "test-model",
+		Justification:      db.Justification{Reason: "test"},
+		Status:             db.StatusPending,
+		MinApprovals:       1,
+		ExpiresAt:          &expiredAt,
+	}
+	if err := database.CreateRequest(req); err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.General.TimeoutAction = string(TimeoutActionAutoApproveWarn)
+	tc := TimeoutConfigFromConfig(cfg)
+	if tc.HandleExpired {
+		t.Fatal("config-derived reaper must not handle expired requests")
+	}
+	tc.DesktopNotify = false
+	NewTimeoutHandler(database, tc).scan()
+
+	updated, err := database.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("failed to get request: %v", err)
+	}
+	if updated.Status != db.StatusPending {
+		t.Errorf("expected request to stay PENDING, got %s", updated.Status)
+	}
+}
+
+func TestTimeoutConfigFromConfig_SLAs(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Patterns.Caution.SLASeconds = 60
+
+	tc := TimeoutConfigFromConfig(cfg)
+	if tc.SLAs[db.RiskTierCritical] != 5*time.Minute {
+		t.Errorf("critical SLA = %s, want 5m", tc.SLAs[db.RiskTierCritical])
+	}
+	if tc.SLAs[db.RiskTierDangerous] != 15*time.Minute {
+		t.Errorf("dangerous SLA = %s, want 15m", tc.SLAs[db.RiskTierDangerous])
+	}
+	if tc.SLAs[db.RiskTierCaution] != time.Minute {
+		t.Errorf("caution SLA = %s, want 1m", tc.SLAs[db.RiskTierCaution])
+	}
+	if len(tc.SLAs) != 3 {
+		t.Errorf("expected 3 tiers with SLAs, got %d", len(tc.SLAs))
+	}
+}
+
+func TestTimeoutHandler_SLABreachEmittedOnce(t *testing.T) {
+	database := testutil.TempDB(t)
+
+	session := &db.Session{
+		ID:          "sess-sla",
+		AgentName:   "TestAgent",
+		Program:     "test",
+		Model:       "test-model",
+		ProjectPath: "/test/project",
+	}
+	if err := database.CreateSession(session); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	req := &db.Request{
+		ID:                 "req-sla",
+		ProjectPath:        "/test/project",
+		Command:            db.CommandSpec{Raw: "kubectl delete ns prod", Cwd: "/", Shell: true},
+		RiskTier:           db.RiskTierCritical,
+		RequestorSessionID: "sess-sla",
+		RequestorAgent:     "TestAgent",
+		RequestorModel:     "test-model",
+		Justification:      db.Justification{Reason: "test"},
+		Status:             db.StatusPending,
+		MinApprovals:       2,
+		ExpiresAt:          &expiresAt,
+	}
+	if err := database.CreateRequest(req); err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	var breaches []SLABreach
+	config := TimeoutHandlerConfig{
+		CheckInterval: time.Second,
+		Action:        TimeoutActionEscalate,
+		SLAs:          map[db.RiskTier]time.Duration{db.RiskTierCritical: 5 * time.Minute},
+		OnSLABreach:   func(b SLABreach) { breaches = append(breaches, b) },
+	}
+	handler := NewTimeoutHandler(database, config)
+
+	handler.checkSLABreaches(time.Now())
+	if len(breaches) != 0 {
+		t.Fatalf("expected no breach before SLA elapsed, got %d", len(breaches))
+	}
+
+	later := time.Now().Add(10 * time.Minute)
+	handler.checkSLABreaches(later)
+	handler.checkSLABreaches(later.Add(time.Minute))
+	if len(breaches) != 1 {
+		t.Fatalf("expected exactly one breach, got %d", len(breaches))
+	}
+	if breaches[0].Request.ID != req.ID {
+		t.Errorf("breach request = %s, want %s", breaches[0].Request.ID, req.ID)
+	}
+
+	// A restarted daemon does not announce the breach again.
+	NewTimeoutHandler(database, config).checkSLABreaches(later.Add(2 * time.Minute))
+	if len(breaches) != 1 {
+		t.Errorf("expected no breach after a restart, got %d", len(breaches))
+	}
+
+	// Requests that leave pending are forgotten.
+	if err := database.UpdateRequestStatus(req.ID, db.StatusApproved); err != nil {
+		t.Fatalf("UpdateRequestStatus failed: %v", err)
+	}
+	handler.checkSLABreaches(later.Add(3 * time.Minute))
+	if len(handler.slaNotified) != 0 {
+		t.Errorf("expected resolved requests pruned, got %v", handler.slaNotified)
+	}
+}
+
+func TestTimeoutHandler_PolicyAttestationWarnings(t *testing.T) {
+	database := testutil.TempDB(t)
+	hash := "policy-hash-1"
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := database.CreatePolicyAttestation(&db.PolicyAttestation{
+		ProjectPath: "/test/project",
+		PolicyHash:  hash,
+		SessionID:   "sess-human",
+		AgentName:   "Human",
+		Signature:   "sig",
+		CreatedAt:   now.Add(-10 * 24 * time.Hour),
+	}); err != nil {
+		t.Fatalf("CreatePolicyAttestation failed: %v", err)
+	}
+
+	var checks []db.AttestationCheck
+	handler := NewTimeoutHandler(database, TimeoutHandlerConfig{
+		CheckInterval:      time.Second,
+		ProjectPath:        "/test/project",
+		PolicyHash:         func() (string, error) { return hash, nil },
+		AttestationCadence: 30 * 24 * time.Hour,
+		AttestationGrace:   7 * 24 * time.Hour,
+		OnAttestationDue:   func(c db.AttestationCheck) { checks = append(checks, c) },
+	})
+
+	handler.checkPolicyAttestation(now)
+	if len(checks) != 0 {
+		t.Fatalf("expected no warning while attestation is current, got %+v", checks)
+	}
+
+	due := now.Add(25 * 24 * time.Hour)
+	handler.checkPolicyAttestation(due)
+	handler.checkPolicyAttestation(due.Add(time.Hour))
+	if len(checks) != 1 || checks[0].State != db.AttestationDue || checks[0].Refuse {
+		t.Fatalf("expected one due warning, got %+v", checks)
+	}
+
+	handler.checkPolicyAttestation(due.Add(10 * 24 * time.Hour))
+	if len(checks) != 2 || !checks[1].Refuse {
+		t.Fatalf("expected a second warning once past grace, got %+v", checks)
+	}
+
+	hash = "policy-hash-2"
+	handler.checkPolicyAttestation(now)
+	if len(checks) != 3 || checks[2].State != db.AttestationInvalid {
+		t.Fatalf("expected invalid warning after policy edit, got %+v", checks)
+	}
+}
+
+func TestTimeoutConfigFromConfig_Attestation(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if tc := TimeoutConfigFromConfig(cfg); tc.AttestationCadence != 0 {
+		t.Errorf("attestation should be disabled by default, got %s", tc.AttestationCadence)
+	}
+	cfg.General.PolicyAttestationDays = 30
+	cfg.General.PolicyAttestationGraceDays = 7
+	tc := TimeoutConfigFromConfig(cfg)
+	if tc.AttestationCadence != 30*24*time.Hour || tc.AttestationGrace != 7*24*time.Hour {
+		t.Errorf("cadence %s grace %s", tc.AttestationCadence, tc.AttestationGrace)
+	}
+}
+
+func TestTimeoutHandler_ReviewerPatternWarnings(t *testing.T) {
+	database := testutil.TempDB(t)
+	requestor := testutil.MakeSession(t, database, testutil.WithAgent("Requestor"))
+	stamp := testutil.MakeSession(t, database, testutil.WithAgent("Stamp"))
+	approve := func() {
+		t.Helper()
+		req := testutil.MakeRequest(t, database, requestor)
+		if err := database.CreateReview(&db.Review{
+			RequestID:         req.ID,
+			ReviewerSessionID: stamp.ID,
+			ReviewerAgent:     stamp.AgentName,
+			Decision:          db.DecisionApprove,
+		}); err != nil {
+			t.Fatalf("CreateReview failed: %v", err)
+		}
+	}
+
+	var flagged []core.ReviewerStats
+	handler := NewTimeoutHandler(database, TimeoutHandlerConfig{
+		CheckInterval:      time.Second,
+		ReviewerThresholds: core.ReviewerThresholds{ApprovalStreak: 2, MinReviews: 2},
+		OnReviewerPattern:  func(s core.ReviewerStats) { flagged = append(flagged, s) },
+	})
+
+	approve()
+	handler.checkReviewerPatterns()
+	if len(flagged) != 0 {
+		t.Fatalf("expected no warning below threshold, got %+v", flagged)
+	}
+
+	approve()
+	handler.checkReviewerPatterns()
+	handler.checkReviewerPatterns()
+	if len(flagged) != 1 || flagged[0].Agent != "Stamp" || flagged[0].Warnings[0] != core.ReviewerWarningApprovalStreak {
+		t.Fatalf("expected one streak warning, got %+v", flagged)
+	}
+}
+
+func TestTimeoutConfigFromConfig_ReviewerThresholds(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if tc := TimeoutConfigFromConfig(cfg); tc.ReviewerThresholds.Enabled() {
+		t.Errorf("reviewer checks should be disabled by default, got %+v", tc.ReviewerThresholds)
+	}
+	cfg.Agents.ReviewerFastApprovalSecs = 2
+	cfg.Agents.ReviewerPatternAction = "exclude_critical"
+	tc := TimeoutConfigFromConfig(cfg)
+	if tc.ReviewerThresholds.FastApproval != 2*time.Second || !tc.ReviewerThresholds.ExcludeCritical || tc.ReviewerThresholds.MinReviews != 10 {
+		t.Errorf("thresholds = %+v", tc.ReviewerThresholds)
+	}
+}
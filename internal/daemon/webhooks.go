@@ -0,0 +1,281 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/charmbracelet/log"
+)
+
+// Webhook templates for notifications.webhook_template.
+const (
+	// WebhookTemplateGeneric posts the WebhookPayload as JSON.
+	WebhookTemplateGeneric = "generic"
+	// WebhookTemplateSlack posts a Slack incoming-webhook message. Its text
+	// field is also understood by Mattermost and Discord's Slack endpoint.
+	WebhookTemplateSlack = "slack"
+)
+
+// lifecycleEvents returns the lifecycle events a request in status has gone
+// through, in order. A request seen only after it moved on still gets the
+// events it skipped past.
+func lifecycleEvents(status db.RequestStatus) []WebhookEvent {
+	switch status {
+	case db.StatusPending, db.StatusEscalated, db.StatusTimeout, db.StatusCancelled:
+		return []WebhookEvent{WebhookEventRequestPending}
+	case db.StatusApproved, db.StatusExecuting, db.StatusApprovalExpired:
+		return []WebhookEvent{WebhookEventRequestPending, WebhookEventRequestApproved}
+	case db.StatusRejected:
+		return []WebhookEvent{WebhookEventRequestPending, WebhookEventRequestRejected}
+	case db.StatusExecuted, db.StatusExecutionFailed, db.StatusTimedOut:
+		return []WebhookEvent{WebhookEventRequestPending, WebhookEventRequestApproved, WebhookEventRequestExecuted}
+	default:
+		return nil
+	}
+}
+
+// NotifiesStatus reports whether a notification about a request reaching
+// status should be sent under notifications.notify_statuses. An empty list
+// notifies every status; queued and pending requests are not resolved and
+// are always notified.
+func NotifiesStatus(notifyStatuses []string, status db.RequestStatus) bool {
+	if len(notifyStatuses) == 0 || status == db.StatusPending || status == db.StatusQueued {
+		return true
+	}
+	for _, s := range notifyStatuses {
+		if db.RequestStatus(s) == status {
+			return true
+		}
+	}
+	return false
+}
+
+// lifecycleEventStatus returns the status a lifecycle event reports for a
+// request now in current: request_executed reports how execution ended.
+func lifecycleEventStatus(event WebhookEvent, current db.RequestStatus) db.RequestStatus {
+	switch event {
+	case WebhookEventRequestApproved:
+		return db.StatusApproved
+	case WebhookEventRequestRejected:
+		return db.StatusRejected
+	case WebhookEventRequestExecuted:
+		return current
+	default:
+		return db.StatusPending
+	}
+}
+
+// LifecyclePayload builds the webhook payload for a request event.
+func LifecyclePayload(event WebhookEvent, req *db.Request, now time.Time) WebhookPayload {
+	payload := WebhookPayload{
+		Event:     event,
+		RequestID: req.ID,
+		Command:   webhookCommand(req),
+		Tier:      string(req.RiskTier),
+		Requestor: req.RequestorAgent,
+		Timestamp: now.UTC().Format(time.RFC3339),
+		Project:   req.ProjectPath,
+		Status:    string(req.Status),
+	}
+	if event == WebhookEventRequestExecuted && req.Execution != nil {
+		payload.ExitCode = req.Execution.ExitCode
+	}
+	return payload
+}
+
+// EncodeWebhookPayload renders a payload with the named template.
+func EncodeWebhookPayload(payload WebhookPayload, template string) ([]byte, error) {
+	switch template {
+	case "", WebhookTemplateGeneric:
+		return json.Marshal(payload)
+	case WebhookTemplateSlack:
+		return json.Marshal(slackMessage(payload))
+	default:
+		return nil, fmt.Errorf("unknown webhook template %q", template)
+	}
+}
+
+// webhookSummary is a one-line description of a payload.
+func webhookSummary(p WebhookPayload) string {
+	verb := strings.TrimPrefix(string(p.Event), "request_")
+	verb = strings.ReplaceAll(verb, "_", " ")
+	summary := fmt.Sprintf("SLB request %s %s", p.RequestID, verb)
+	if p.Tier != "" {
+		summary += fmt.Sprintf(" (%s)", strings.ToUpper(p.Tier))
+	}
+	if p.ExitCode != nil {
+		summary += fmt.Sprintf(", exit code %d", *p.ExitCode)
+	}
+	return summary
+}
+
+func slackMessage(p WebhookPayload) map[string]any {
+	summary := webhookSummary(p)
+	footer := []string{}
+	if p.Requestor != "" {
+		footer = append(footer, "requested by "+p.Requestor)
+	}
+	if p.Project != "" {
+		footer = append(footer, p.Project)
+	}
+	blocks := []any{
+		map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": fmt.Sprintf("*%s*\n```%s```", summary, p.Command)},
+		},
+	}
+	if len(footer) > 0 {
+		blocks = append(blocks, map[string]any{
+			"type":     "context",
+			"elements": []any{map[string]any{"type": "mrkdwn", "text": strings.Join(footer, " · ")}},
+		})
+	}
+	return map[string]any{"text": summary, "blocks": blocks}
+}
+
+// LifecycleWebhookDispatcher sends request lifecycle events to the
+// configured webhook URLs from the daemon. Each pass queues the events of
+// requests created since the dispatcher started in the notification outbox,
+// then delivers the due ones, so a slow or failing receiver never holds up
+// the request itself. Failed attempts stay on the outbox row, where
+// slb show reports them.
+type LifecycleWebhookDispatcher struct {
+	db          *db.DB
+	projectPath string
+	cfg         config.NotificationsConfig
+	logger      *log.Logger
+	poster      ChangeRecordPoster
+	now         func() time.Time
+	since       time.Time
+	seen        map[string]db.RequestStatus
+}
+
+// NewLifecycleWebhookDispatcher creates a dispatcher over a writable project
+// database. It does nothing unless cfg.WebhookURLs is set.
+func NewLifecycleWebhookDispatcher(database *db.DB, projectPath string, cfg config.NotificationsConfig, logger *log.Logger) *LifecycleWebhookDispatcher {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LifecycleWebhookDispatcher{
+		db:          database,
+		projectPath: projectPath,
+		cfg:         cfg,
+		logger:      logger,
+		poster:      NewDefaultWebhookNotifier(),
+		now:         time.Now,
+		since:       time.Now().UTC(),
+		seen:        make(map[string]db.RequestStatus),
+	}
+}
+
+// WithPoster sets a custom poster (for testing).
+func (d *LifecycleWebhookDispatcher) WithPoster(p ChangeRecordPoster) *LifecycleWebhookDispatcher {
+	d.poster = p
+	return d
+}
+
+// Run sends lifecycle webhooks every interval until ctx is done.
+func (d *LifecycleWebhookDispatcher) Run(ctx context.Context, interval time.Duration) {
+	if d == nil || len(d.cfg.WebhookURLs) == 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	_ = d.Check(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = d.Check(ctx)
+		}
+	}
+}
+
+// Check runs one pass: queue new events, then deliver due ones.
+func (d *LifecycleWebhookDispatcher) Check(ctx context.Context) error {
+	if d == nil || len(d.cfg.WebhookURLs) == 0 {
+		return nil
+	}
+	now := d.now().UTC()
+
+	if err := d.enqueue(now); err != nil {
+		d.logger.Warn("queueing lifecycle webhooks failed", "project", d.projectPath, "error", err)
+	}
+
+	var total DeliveryResult
+	for _, event := range d.cfg.WebhookEvents {
+		result, err := deliverNotifications(ctx, d.db, db.WebhookNotificationKind(event), d.poster, now)
+		total.Delivered += result.Delivered
+		total.Failed += result.Failed
+		if err != nil {
+			d.logger.Warn("delivering lifecycle webhooks failed", "project", d.projectPath, "error", err)
+			return err
+		}
+	}
+	if total.Failed > 0 {
+		d.logger.Warn("lifecycle webhook delivery failed; will retry",
+			"project", d.projectPath,
+			"failed", total.Failed,
+			"delivered", total.Delivered)
+	} else if total.Delivered > 0 {
+		d.logger.Debug("lifecycle webhooks delivered", "project", d.projectPath, "delivered", total.Delivered)
+	}
+	return nil
+}
+
+// enqueue queues one notification per enabled event and URL for requests
+// whose status changed since the last pass.
+func (d *LifecycleWebhookDispatcher) enqueue(now time.Time) error {
+	requests, err := d.db.ListRequestsCreatedSince(d.projectPath, d.since)
+	if err != nil {
+		return err
+	}
+	for _, req := range requests {
+		if d.seen[req.ID] == req.Status {
+			continue
+		}
+		for _, event := range lifecycleEvents(req.Status) {
+			if !d.eventEnabled(event) || !NotifiesStatus(d.cfg.NotifyStatuses, lifecycleEventStatus(event, req.Status)) {
+				continue
+			}
+			body, err := EncodeWebhookPayload(LifecyclePayload(event, req, now), d.cfg.WebhookTemplate)
+			if err != nil {
+				return err
+			}
+			for _, url := range d.cfg.WebhookURLs {
+				if _, err := d.db.EnqueueNotification(&db.UndeliveredNotification{
+					Kind:      db.WebhookNotificationKind(string(event)),
+					RequestID: req.ID,
+					URL:       url,
+					Payload:   string(body),
+					CreatedAt: now,
+				}); err != nil {
+					return fmt.Errorf("queueing %s webhook for %s: %w", event, req.ID, err)
+				}
+			}
+		}
+		d.seen[req.ID] = req.Status
+	}
+	return nil
+}
+
+func (d *LifecycleWebhookDispatcher) eventEnabled(event WebhookEvent) bool {
+	for _, e := range d.cfg.WebhookEvents {
+		if e == string(event) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/slb/internal/config"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func TestLifecycleEvents(t *testing.T) {
+	tests := map[db.RequestStatus][]WebhookEvent{
+		db.StatusQueued:   nil,
+		db.StatusPending:  {WebhookEventRequestPending},
+		db.StatusApproved: {WebhookEventRequestPending, WebhookEventRequestApproved},
+		db.StatusRejected: {WebhookEventRequestPending, WebhookEventRequestRejected},
+		db.StatusExecutionFailed: {
+			WebhookEventRequestPending, WebhookEventRequestApproved, WebhookEventRequestExecuted,
+		},
+	}
+	for status, want := range tests {
+		got := lifecycleEvents(status)
+		if len(got) != len(want) {
+			t.Errorf("lifecycleEvents(%s) = %v, want %v", status, got, want)
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("lifecycleEvents(%s) = %v, want %v", status, got, want)
+			}
+		}
+	}
+}
+
+func TestEncodeWebhookPayload(t *testing.T) {
+	exit := 2
+	payload := WebhookPayload{
+		Event:     WebhookEventRequestExecuted,
+		RequestID: "req-1",
+		Command:   "rm -rf ./build",
+		Tier:      "dangerous",
+		Requestor: "BlueLake",
+		Project:   "/work/app",
+		ExitCode:  &exit,
+	}
+
+	generic, err := EncodeWebhookPayload(payload, WebhookTemplateGeneric)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded WebhookPayload
+	if err := json.Unmarshal(generic, &decoded); err != nil || decoded.RequestID != "req-1" || decoded.ExitCode == nil || *decoded.ExitCode != 2 {
+		t.Errorf("unexpected generic payload %s, %v", generic, err)
+	}
+
+	slack, err := EncodeWebhookPayload(payload, WebhookTemplateSlack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg struct {
+		Text   string           `json:"text"`
+		Blocks []map[string]any `json:"blocks"`
+	}
+	if err := json.Unmarshal(slack, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Text != "SLB request req-1 executed (DANGEROUS), exit code 2" || len(msg.Blocks) != 2 {
+		t.Errorf("unexpected slack message %s", slack)
+	}
+
+	if _, err := EncodeWebhookPayload(payload, "teams"); err == nil {
+		t.Error("expected an unknown template to fail")
+	}
+}
+
+func TestLifecycleWebhookDispatcher_QueuesAndRetries(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	sess := testutil.MakeSession(t, database)
+	req := testutil.MakeRequest(t, database, sess)
+
+	cfg := config.NotificationsConfig{
+		WebhookURLs:     []string{"https://a.example.com/hook", "https://b.example.com/hook"},
+		WebhookEvents:   []string{"request_pending", "request_rejected"},
+		WebhookTemplate: WebhookTemplateGeneric,
+	}
+	poster := &fakePoster{err: errors.New("status 502")}
+	d := NewLifecycleWebhookDispatcher(database, sess.ProjectPath, cfg, nil).WithPoster(poster)
+	d.since = req.CreatedAt.Add(-time.Minute)
+	now := time.Now().UTC()
+	d.now = func() time.Time { return now }
+
+	if err := d.Check(context.Background()); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(poster.bodies) != 2 || !strings.Contains(poster.bodies[0], `"event":"request_pending"`) {
+		t.Fatalf("expected a pending event per URL, got %q", poster.bodies)
+	}
+	list, _ := database.ListNotificationsForRequest(req.ID)
+	if len(list) != 2 || list[0].Attempts != 1 || list[0].LastError != "status 502" {
+		t.Fatalf("expected the failures on the request's notifications, got %+v", list)
+	}
+
+	// Approved is not a configured event, so only the rejection follows.
+	if err := database.UpdateRequestStatus(req.ID, db.StatusRejected); err != nil {
+		t.Fatal(err)
+	}
+	poster.err = nil
+	poster.bodies = nil
+	now = now.Add(changeRecordRetryBase)
+	if err := d.Check(context.Background()); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(poster.bodies) != 4 {
+		t.Fatalf("expected the retried and new events to be posted, got %q", poster.bodies)
+	}
+	list, _ = database.ListNotificationsForRequest(req.ID)
+	if len(list) != 4 {
+		t.Fatalf("expected pending and rejected per URL, got %d", len(list))
+	}
+	for _, n := range list {
+		if n.DeliveredAt == nil {
+			t.Errorf("expected %s to %s to be delivered", n.Kind, n.URL)
+		}
+	}
+
+	poster.bodies = nil
+	if err := d.Check(context.Background()); err != nil || len(poster.bodies) != 0 {
+		t.Errorf("expected nothing further to send, got %q, %v", poster.bodies, err)
+	}
+}
+
+func TestNotifiesStatus(t *testing.T) {
+	filter := []string{"rejected", "execution_failed"}
+	cases := []struct {
+		statuses []string
+		status   db.RequestStatus
+		want     bool
+	}{
+		{nil, db.StatusExecuted, true},
+		{filter, db.StatusRejected, true},
+		{filter, db.StatusExecutionFailed, true},
+		{filter, db.StatusExecuted, false},
+		{filter, db.StatusApproved, false},
+		{filter, db.StatusPending, true},
+		{filter, db.StatusQueued, true},
+	}
+	for _, tc := range cases {
+		if got := NotifiesStatus(tc.statuses, tc.status); got != tc.want {
+			t.Errorf("NotifiesStatus(%v, %s) = %v, want %v", tc.statuses, tc.status, got, tc.want)
+		}
+	}
+}
+
+func TestLifecycleWebhookDispatcher_NotifyStatuses(t *testing.T) {
+	database := testutil.NewTestDB(t)
+	sess := testutil.MakeSession(t, database)
+	rejected := testutil.MakeRequest(t, database, sess)
+	executed := testutil.MakeRequest(t, database, sess)
+	failed := testutil.MakeRequest(t, database, sess)
+
+	cfg := config.NotificationsConfig{
+		WebhookURLs:     []string{"https://a.example.com/hook"},
+		WebhookEvents:   []string{"request_rejected", "request_executed"},
+		WebhookTemplate: WebhookTemplateGeneric,
+		NotifyStatuses:  []string{"rejected", "execution_failed"},
+	}
+	poster := &fakePoster{}
+	d := NewLifecycleWebhookDispatcher(database, sess.ProjectPath, cfg, nil).WithPoster(poster)
+	d.since = rejected.CreatedAt.Add(-time.Minute)
+
+	for id, status := range map[string]db.RequestStatus{
+		rejected.ID: db.StatusRejected,
+		executed.ID: db.StatusExecuted,
+		failed.ID:   db.StatusExecutionFailed,
+	} {
+		if _, err := database.Exec(`UPDATE requests SET status = ? WHERE id = ?`, string(status), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Check(context.Background()); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	if list, _ := database.ListNotificationsForRequest(executed.ID); len(list) != 0 {
+		t.Errorf("expected no notification for the executed request, got %+v", list)
+	}
+	for _, req := range []*db.Request{rejected, failed} {
+		if list, _ := database.ListNotificationsForRequest(req.ID); len(list) != 1 {
+			t.Errorf("expected one notification for %s, got %+v", req.ID, list)
+		}
+	}
+	if len(poster.bodies) != 2 {
+		t.Errorf("expected the rejected and failed events posted, got %q", poster.bodies)
+	}
+}
@@ -0,0 +1,220 @@
+// Package db provides policy attestation storage and evaluation.
+package db
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrAttestationNotFound indicates a project has no policy attestation.
+var ErrAttestationNotFound = errors.New("policy attestation not found")
+
+// PolicyAttestation records a reviewer's signed statement that a project's
+// effective auto-approve policy is still appropriate.
+type PolicyAttestation struct {
+	ID          string `json:"id"`
+	ProjectPath string `json:"project_path"`
+	// PolicyPath is the project config file at attestation time.
+	PolicyPath string `json:"policy_path"`
+	// PolicyHash is the hash of the effective auto-approve settings
+	// (see config.PolicyHash) at attestation time.
+	PolicyHash string `json:"policy_hash"`
+	SessionID  string `json:"session_id"`
+	AgentName  string `json:"agent_name"`
+	Model      string `json:"model,omitempty"`
+	// Signature = HMAC-SHA256(sessionKey, projectPath + policyHash + timestamp).
+	Signature string    `json:"signature"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AttestationState describes how a project's latest attestation relates to
+// its current policy file.
+type AttestationState string
+
+const (
+	// AttestationDisabled means no review cadence is configured.
+	AttestationDisabled AttestationState = "disabled"
+	// AttestationCurrent means the policy was attested within the cadence.
+	AttestationCurrent AttestationState = "current"
+	// AttestationDue means the latest attestation is older than the cadence.
+	AttestationDue AttestationState = "due"
+	// AttestationInvalid means the policy changed since it was attested.
+	AttestationInvalid AttestationState = "invalid"
+	// AttestationMissing means the policy has never been attested.
+	AttestationMissing AttestationState = "missing"
+)
+
+// AttestationCheck is the result of evaluating a project's attestation.
+type AttestationCheck struct {
+	State AttestationState `json:"state"`
+	// PolicyHash is the current hash of the policy.
+	PolicyHash string `json:"policy_hash"`
+	// Latest is the most recent attestation, if any.
+	Latest *PolicyAttestation `json:"latest,omitempty"`
+	// DueAt is when a new attestation became (or becomes) due.
+	DueAt time.Time `json:"due_at,omitempty"`
+	// Refuse is set once the grace period after DueAt has elapsed.
+	Refuse bool `json:"refuse"`
+}
+
+// NeedsAttention reports whether the attestation is due, invalid or missing.
+func (c AttestationCheck) NeedsAttention() bool {
+	return c.State == AttestationDue || c.State == AttestationInvalid || c.State == AttestationMissing
+}
+
+// CreatePolicyAttestation inserts an attestation, generating ID and timestamp if missing.
+func (db *DB) CreatePolicyAttestation(a *PolicyAttestation) error {
+	if a.ProjectPath == "" {
+		return fmt.Errorf("project_path is required")
+	}
+	if a.PolicyHash == "" {
+		return fmt.Errorf("policy_hash is required")
+	}
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO policy_attestations (
+			id, project_path, policy_path, policy_hash, session_id,
+			agent_name, model, signature, comment, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		a.ID, a.ProjectPath, a.PolicyPath, a.PolicyHash, a.SessionID,
+		a.AgentName, nullString(a.Model), a.Signature, nullString(a.Comment),
+		a.CreatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("creating policy attestation: %w", err)
+	}
+	return nil
+}
+
+// ListPolicyAttestations returns a project's attestations, newest first.
+func (db *DB) ListPolicyAttestations(projectPath string) ([]*PolicyAttestation, error) {
+	rows, err := db.Query(`
+		SELECT id, project_path, policy_path, policy_hash, session_id,
+		       agent_name, model, signature, comment, created_at
+		FROM policy_attestations
+		WHERE project_path = ?
+		ORDER BY created_at DESC, rowid DESC
+	`, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("listing policy attestations: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*PolicyAttestation
+	for rows.Next() {
+		a := &PolicyAttestation{}
+		var model, comment sql.NullString
+		var created string
+		if err := rows.Scan(&a.ID, &a.ProjectPath, &a.PolicyPath, &a.PolicyHash, &a.SessionID,
+			&a.AgentName, &model, &a.Signature, &comment, &created); err != nil {
+			return nil, fmt.Errorf("scanning policy attestations: %w", err)
+		}
+		a.Model = model.String
+		a.Comment = comment.String
+		a.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		list = append(list, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// LatestPolicyAttestation returns a project's most recent attestation.
+// Returns ErrAttestationNotFound if the project has none.
+func (db *DB) LatestPolicyAttestation(projectPath string) (*PolicyAttestation, error) {
+	list, err := db.ListPolicyAttestations(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrAttestationNotFound
+	}
+	return list[0], nil
+}
+
+// CheckPolicyAttestation evaluates the project's latest attestation against
+// the current policy hash.
+func (db *DB) CheckPolicyAttestation(projectPath, policyHash string, cadence, grace time.Duration, now time.Time) (AttestationCheck, error) {
+	latest, err := db.LatestPolicyAttestation(projectPath)
+	if err != nil && !errors.Is(err, ErrAttestationNotFound) {
+		return AttestationCheck{}, err
+	}
+	return EvaluatePolicyAttestation(latest, policyHash, cadence, grace, now), nil
+}
+
+// EvaluatePolicyAttestation decides whether latest still covers the policy
+// with hash currentHash. An attestation is due cadence after it was made;
+// one whose hash no longer matches is due immediately. Refuse is set once
+// grace has elapsed past the due date (a zero grace never refuses). A policy
+// that was never attested is treated as long overdue: with a grace period
+// configured it is refused straight away.
+func EvaluatePolicyAttestation(latest *PolicyAttestation, currentHash string, cadence, grace time.Duration, now time.Time) AttestationCheck {
+	check := AttestationCheck{State: AttestationDisabled, PolicyHash: currentHash, Latest: latest}
+	if cadence <= 0 {
+		return check
+	}
+	if latest == nil {
+		check.State = AttestationMissing
+		check.Refuse = grace > 0
+		return check
+	}
+
+	check.DueAt = latest.CreatedAt.Add(cadence)
+	switch {
+	case latest.PolicyHash != currentHash:
+		check.State = AttestationInvalid
+		check.DueAt = latest.CreatedAt
+	case now.Before(check.DueAt):
+		check.State = AttestationCurrent
+		return check
+	default:
+		check.State = AttestationDue
+	}
+	check.Refuse = grace > 0 && now.After(check.DueAt.Add(grace))
+	return check
+}
+
+// ReviewerAttestationScope is the attestation scope (stored as the project
+// path) used to clear a reviewer flagged for rubber-stamp approvals.
+func ReviewerAttestationScope(agent string) string {
+	return "reviewer:" + agent
+}
+
+// ReviewerAttestationHash is the hash a reviewer attestation covers. It binds
+// the attestation to the reviewer's scope so it cannot be replayed for another.
+func ReviewerAttestationHash(agent string) string {
+	sum := sha256.Sum256([]byte(ReviewerAttestationScope(agent)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ComputeAttestationSignature computes an HMAC signature for an attestation.
+// Signature = HMAC-SHA256(sessionKey, projectPath + policyHash + timestamp)
+func ComputeAttestationSignature(sessionKey, projectPath, policyHash string, timestamp time.Time) string {
+	data := projectPath + policyHash + timestamp.Format(time.RFC3339)
+	key, _ := hex.DecodeString(sessionKey)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyAttestationSignature verifies an HMAC signature for an attestation.
+func VerifyAttestationSignature(sessionKey string, a *PolicyAttestation) bool {
+	expected := ComputeAttestationSignature(sessionKey, a.ProjectPath, a.PolicyHash, a.CreatedAt)
+	return hmac.Equal([]byte(expected), []byte(a.Signature))
+}
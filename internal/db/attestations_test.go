@@ -0,0 +1,110 @@
+// Package db tests for policy attestations.
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPolicyAttestation_CreateListLatest(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if _, err := db.LatestPolicyAttestation("/test/project"); !errors.Is(err, ErrAttestationNotFound) {
+		t.Fatalf("expected ErrAttestationNotFound, got %v", err)
+	}
+
+	sess := &Session{AgentName: "Human", Model: "human", ProjectPath: "/test/project"}
+	if err := db.CreateSession(sess); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	base := time.Now().UTC().Truncate(time.Second)
+	for i, hash := range []string{"aaa", "bbb"} {
+		ts := base.Add(time.Duration(i) * time.Hour)
+		a := &PolicyAttestation{
+			ProjectPath: "/test/project",
+			PolicyPath:  "/test/project/.slb/config.toml",
+			PolicyHash:  hash,
+			SessionID:   sess.ID,
+			AgentName:   sess.AgentName,
+			Model:       sess.Model,
+			Signature:   ComputeAttestationSignature(sess.SessionKey, "/test/project", hash, ts),
+			Comment:     "still fine",
+			CreatedAt:   ts,
+		}
+		if err := db.CreatePolicyAttestation(a); err != nil {
+			t.Fatalf("CreatePolicyAttestation failed: %v", err)
+		}
+		if a.ID == "" {
+			t.Fatal("expected ID to be generated")
+		}
+	}
+
+	list, err := db.ListPolicyAttestations("/test/project")
+	if err != nil {
+		t.Fatalf("ListPolicyAttestations failed: %v", err)
+	}
+	if len(list) != 2 || list[0].PolicyHash != "bbb" || list[1].PolicyHash != "aaa" {
+		t.Fatalf("expected newest first, got %+v", list)
+	}
+	if list[0].Comment != "still fine" || list[0].Model != "human" {
+		t.Errorf("round trip lost fields: %+v", list[0])
+	}
+	if !VerifyAttestationSignature(sess.SessionKey, list[0]) {
+		t.Error("expected stored signature to verify")
+	}
+	tampered := *list[0]
+	tampered.PolicyHash = "ccc"
+	if VerifyAttestationSignature(sess.SessionKey, &tampered) {
+		t.Error("expected signature over a different hash to fail")
+	}
+
+	latest, err := db.LatestPolicyAttestation("/test/project")
+	if err != nil || latest.PolicyHash != "bbb" {
+		t.Fatalf("LatestPolicyAttestation = %+v, %v", latest, err)
+	}
+	if other, _ := db.ListPolicyAttestations("/other"); len(other) != 0 {
+		t.Errorf("attestations leaked across projects: %+v", other)
+	}
+
+	if err := db.CreatePolicyAttestation(&PolicyAttestation{ProjectPath: "/test/project"}); err == nil {
+		t.Error("expected error without policy hash")
+	}
+}
+
+func TestEvaluatePolicyAttestation(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	attested := func(ago time.Duration, hash string) *PolicyAttestation {
+		return &PolicyAttestation{PolicyHash: hash, CreatedAt: now.Add(-ago)}
+	}
+
+	tests := []struct {
+		name       string
+		latest     *PolicyAttestation
+		cadence    time.Duration
+		grace      time.Duration
+		wantState  AttestationState
+		wantRefuse bool
+	}{
+		{"disabled", nil, 0, 0, AttestationDisabled, false},
+		{"missing refuses with grace", nil, 30 * day, day, AttestationMissing, true},
+		{"missing without grace", nil, 30 * day, 0, AttestationMissing, false},
+		{"current", attested(10*day, "h"), 30 * day, day, AttestationCurrent, false},
+		{"due within grace", attested(32*day, "h"), 30 * day, 7 * day, AttestationDue, false},
+		{"due past grace", attested(40*day, "h"), 30 * day, 7 * day, AttestationDue, true},
+		{"due without grace", attested(400*day, "h"), 30 * day, 0, AttestationDue, false},
+		{"hash changed", attested(2*day, "old"), 30 * day, 7 * day, AttestationInvalid, false},
+		{"hash changed past grace", attested(8*day, "old"), 30 * day, 7 * day, AttestationInvalid, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := EvaluatePolicyAttestation(tc.latest, "h", tc.cadence, tc.grace, now)
+			if got.State != tc.wantState || got.Refuse != tc.wantRefuse {
+				t.Errorf("got state %s refuse %v, want %s refuse %v", got.State, got.Refuse, tc.wantState, tc.wantRefuse)
+			}
+		})
+	}
+}
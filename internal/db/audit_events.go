@@ -0,0 +1,76 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Audit event types.
+const (
+	// AuditEventCommandHashMismatch records a refusal to execute a command
+	// that no longer matches the hash its reviewers approved.
+	AuditEventCommandHashMismatch = "command_hash_mismatch"
+)
+
+// AuditEvent is a security-relevant event on a request, kept for auditors.
+type AuditEvent struct {
+	ID          int64     `json:"id"`
+	RequestID   string    `json:"request_id"`
+	ProjectPath string    `json:"project_path"`
+	Event       string    `json:"event"`
+	Detail      string    `json:"detail,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RecordAuditEvent inserts an audit event.
+func (db *DB) RecordAuditEvent(e *AuditEvent) error {
+	if e.RequestID == "" || e.Event == "" {
+		return fmt.Errorf("request_id and event are required")
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	res, err := db.Exec(`
+		INSERT INTO audit_events (request_id, project_path, event, detail, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, e.RequestID, e.ProjectPath, e.Event, e.Detail, e.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("recording audit event: %w", err)
+	}
+	if e.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("getting audit event id: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEvents returns a request's audit events, oldest first.
+func (db *DB) ListAuditEvents(requestID string) ([]*AuditEvent, error) {
+	rows, err := db.Query(`
+		SELECT id, request_id, project_path, event, detail, created_at
+		FROM audit_events WHERE request_id = ?
+		ORDER BY created_at, id
+	`, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit events: %w", err)
+	}
+	return scanAuditEvents(rows)
+}
+
+func scanAuditEvents(rows *sql.Rows) ([]*AuditEvent, error) {
+	defer rows.Close()
+	var events []*AuditEvent
+	for rows.Next() {
+		e := &AuditEvent{}
+		var createdAt string
+		if err := rows.Scan(&e.ID, &e.RequestID, &e.ProjectPath, &e.Event, &e.Detail, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning audit event: %w", err)
+		}
+		e.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating audit events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditEvents_RecordAndList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	for _, e := range []*AuditEvent{
+		{RequestID: "req-1", ProjectPath: "/p/a", Event: AuditEventCommandHashMismatch, Detail: "second", CreatedAt: base.Add(time.Minute)},
+		{RequestID: "req-1", ProjectPath: "/p/a", Event: AuditEventCommandHashMismatch, Detail: "first", CreatedAt: base},
+		{RequestID: "req-2", ProjectPath: "/p/b", Event: AuditEventCommandHashMismatch, CreatedAt: base},
+	} {
+		if err := db.RecordAuditEvent(e); err != nil {
+			t.Fatalf("RecordAuditEvent failed: %v", err)
+		}
+	}
+	if err := db.RecordAuditEvent(&AuditEvent{RequestID: "req-3"}); err == nil {
+		t.Error("expected an error without an event")
+	}
+
+	got, err := db.ListAuditEvents("req-1")
+	if err != nil {
+		t.Fatalf("ListAuditEvents failed: %v", err)
+	}
+	if len(got) != 2 || got[0].Detail != "first" || got[1].Detail != "second" || !got[0].CreatedAt.Equal(base) {
+		t.Errorf("events = %+v", got)
+	}
+}
@@ -0,0 +1,62 @@
+// Package db provides replay protection for inbound review callbacks.
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCallbackProcessed is returned when recording a callback ID that has
+// already produced a review.
+var ErrCallbackProcessed = errors.New("callback already processed")
+
+// ReviewCallback records that an inbound approval callback (a chat button
+// press or webhook delivery) produced a review, so repeated deliveries of
+// the same callback are recognised instead of submitted again.
+type ReviewCallback struct {
+	// CallbackID is the provider's identifier for the callback.
+	CallbackID  string    `json:"callback_id"`
+	RequestID   string    `json:"request_id"`
+	ReviewID    string    `json:"review_id"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// RecordReviewCallbackTx records a processed callback in the transaction that
+// creates its review. It returns ErrCallbackProcessed if the ID is taken.
+func (db *DB) RecordReviewCallbackTx(tx *sql.Tx, cb *ReviewCallback) error {
+	if cb.ProcessedAt.IsZero() {
+		cb.ProcessedAt = time.Now().UTC()
+	}
+	_, err := tx.Exec(`
+		INSERT INTO review_callbacks (callback_id, request_id, review_id, processed_at)
+		VALUES (?, ?, ?, ?)
+	`, cb.CallbackID, cb.RequestID, cb.ReviewID, cb.ProcessedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return ErrCallbackProcessed
+		}
+		return fmt.Errorf("recording review callback: %w", err)
+	}
+	return nil
+}
+
+// GetReviewCallback returns the record for a processed callback, or nil if
+// the callback ID has not been processed.
+func (db *DB) GetReviewCallback(callbackID string) (*ReviewCallback, error) {
+	cb := &ReviewCallback{}
+	var processedAt string
+	err := db.QueryRow(`
+		SELECT callback_id, request_id, review_id, processed_at
+		FROM review_callbacks WHERE callback_id = ?
+	`, callbackID).Scan(&cb.CallbackID, &cb.RequestID, &cb.ReviewID, &processedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying review callback: %w", err)
+	}
+	cb.ProcessedAt, _ = time.Parse(time.RFC3339, processedAt)
+	return cb, nil
+}
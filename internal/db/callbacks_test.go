@@ -0,0 +1,59 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReviewCallbacks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, req := createTestRequest(t, db)
+	reviewer := &Session{AgentName: "BlueDog", Program: "codex-cli", Model: "gpt-5", ProjectPath: "/test/project"}
+	if err := db.CreateSession(reviewer); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	cb, err := db.GetReviewCallback("delivery-1")
+	if err != nil {
+		t.Fatalf("GetReviewCallback failed: %v", err)
+	}
+	if cb != nil {
+		t.Fatalf("expected no record for an unprocessed callback, got %+v", cb)
+	}
+
+	review := &Review{
+		RequestID:          req.ID,
+		ReviewerSessionID:  reviewer.ID,
+		ReviewerAgent:      reviewer.AgentName,
+		ReviewerModel:      reviewer.Model,
+		Decision:           DecisionApprove,
+		Signature:          "sig",
+		SignatureTimestamp: time.Now().UTC(),
+	}
+	record := func() error {
+		return db.Transaction(func(tx *sql.Tx) error {
+			return db.RecordReviewCallbackTx(tx, &ReviewCallback{CallbackID: "delivery-1", RequestID: req.ID, ReviewID: review.ID})
+		})
+	}
+	if err := db.CreateReview(review); err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+	if err := record(); err != nil {
+		t.Fatalf("RecordReviewCallbackTx failed: %v", err)
+	}
+	if err := record(); !errors.Is(err, ErrCallbackProcessed) {
+		t.Fatalf("second RecordReviewCallbackTx error = %v, want ErrCallbackProcessed", err)
+	}
+
+	cb, err = db.GetReviewCallback("delivery-1")
+	if err != nil {
+		t.Fatalf("GetReviewCallback failed: %v", err)
+	}
+	if cb == nil || cb.ReviewID != review.ID || cb.RequestID != req.ID || cb.ProcessedAt.IsZero() {
+		t.Errorf("GetReviewCallback = %+v, want review %s on request %s", cb, review.ID, req.ID)
+	}
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrCampaignNotFound is returned when a campaign doesn't exist.
+var ErrCampaignNotFound = errors.New("campaign not found")
+
+// Campaign groups related requests, such as the steps of one migration, so
+// their progress can be followed together.
+type Campaign struct {
+	ID          string `json:"id"`
+	ProjectPath string `json:"project_path"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// CreatedBySessionID is the session that created the campaign, if any.
+	CreatedBySessionID string    `json:"created_by_session_id,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// CreateCampaign inserts a campaign, generating its ID and creation time if
+// missing.
+func (db *DB) CreateCampaign(c *Campaign) error {
+	if c.ProjectPath == "" || c.Name == "" {
+		return fmt.Errorf("project_path and name are required")
+	}
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now().UTC()
+	}
+	_, err := db.Exec(`
+		INSERT INTO campaigns (id, project_path, name, description, created_by_session_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, c.ID, c.ProjectPath, c.Name, nullString(c.Description), nullString(c.CreatedBySessionID),
+		c.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("creating campaign: %w", err)
+	}
+	return nil
+}
+
+// GetCampaign retrieves a campaign by ID.
+func (db *DB) GetCampaign(id string) (*Campaign, error) {
+	row := db.QueryRow(`
+		SELECT id, project_path, name, description, created_by_session_id, created_at
+		FROM campaigns WHERE id = ?
+	`, id)
+	c, err := scanCampaign(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	return c, err
+}
+
+// ListCampaigns returns a project's campaigns, newest first.
+func (db *DB) ListCampaigns(projectPath string) ([]*Campaign, error) {
+	rows, err := db.Query(`
+		SELECT id, project_path, name, description, created_by_session_id, created_at
+		FROM campaigns WHERE project_path = ?
+		ORDER BY created_at DESC, id
+	`, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("listing campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	var campaigns []*Campaign
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, c)
+	}
+	return campaigns, rows.Err()
+}
+
+// ListCampaignRequests returns a campaign's requests, oldest first.
+func (db *DB) ListCampaignRequests(campaignID string) ([]*Request, error) {
+	rows, err := db.Query(`
+		SELECT id, project_path,
+			command_raw, command_argv_json, command_cwd, command_shell, command_hash,
+			command_display_redacted, command_contains_sensitive,
+			risk_tier, requestor_session_id, requestor_agent, requestor_model,
+			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
+			execution_log_path, execution_exit_code, execution_duration_ms,
+			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
+			created_at, resolved_at, expires_at, approval_expires_at
+		FROM requests
+		WHERE campaign_id = ?
+		ORDER BY created_at, id
+	`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("listing campaign requests: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRequests(rows)
+}
+
+func scanCampaign(row interface{ Scan(...any) error }) (*Campaign, error) {
+	c := &Campaign{}
+	var description, createdBy sql.NullString
+	var createdAt string
+	if err := row.Scan(&c.ID, &c.ProjectPath, &c.Name, &description, &createdBy, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scanning campaign: %w", err)
+	}
+	c.Description = description.String
+	c.CreatedBySessionID = createdBy.String
+	c.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	return c, nil
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCampaigns_CreateAndAssociate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	sess, _ := createTestRequest(t, db)
+	c := &Campaign{ProjectPath: "/test/project", Name: "orders-v2 migration", Description: "ten steps", CreatedBySessionID: sess.ID}
+	if err := db.CreateCampaign(c); err != nil {
+		t.Fatalf("CreateCampaign failed: %v", err)
+	}
+	if c.ID == "" || c.CreatedAt.IsZero() {
+		t.Fatalf("expected an ID and creation time, got %+v", c)
+	}
+	if err := db.CreateCampaign(&Campaign{ProjectPath: "/test/project"}); err == nil {
+		t.Error("expected an error without a name")
+	}
+
+	got, err := db.GetCampaign(c.ID)
+	if err != nil || got.Name != c.Name || got.Description != "ten steps" || got.CreatedBySessionID != sess.ID {
+		t.Fatalf("GetCampaign = %+v, %v", got, err)
+	}
+	if _, err := db.GetCampaign("missing"); !errors.Is(err, ErrCampaignNotFound) {
+		t.Errorf("GetCampaign(missing) error = %v, want ErrCampaignNotFound", err)
+	}
+
+	for _, raw := range []string{"psql -f 001.sql", "psql -f 002.sql"} {
+		r := &Request{
+			ProjectPath:        "/test/project",
+			RequestorSessionID: sess.ID,
+			RequestorAgent:     sess.AgentName,
+			RequestorModel:     sess.Model,
+			RiskTier:           RiskTierDangerous,
+			MinApprovals:       1,
+			Command:            CommandSpec{Raw: raw, Cwd: "/test/project"},
+			Justification:      Justification{Reason: "migrate"},
+			CampaignID:         c.ID,
+			CreatedAt:          time.Now().UTC(),
+		}
+		if err := db.CreateRequest(r); err != nil {
+			t.Fatalf("CreateRequest failed: %v", err)
+		}
+	}
+
+	members, err := db.ListCampaignRequests(c.ID)
+	if err != nil || len(members) != 2 {
+		t.Fatalf("ListCampaignRequests = %d requests, %v; want 2", len(members), err)
+	}
+	if members[0].CampaignID != c.ID {
+		t.Errorf("expected members to carry the campaign ID, got %q", members[0].CampaignID)
+	}
+	member, err := db.GetRequest(members[1].ID)
+	if err != nil || member.CampaignID != c.ID {
+		t.Errorf("GetRequest campaign = %q, %v", member.CampaignID, err)
+	}
+
+	campaigns, err := db.ListCampaigns("/test/project")
+	if err != nil || len(campaigns) != 1 || campaigns[0].ID != c.ID {
+		t.Errorf("ListCampaigns = %+v, %v", campaigns, err)
+	}
+}
+
+func TestCampaigns_UnknownCampaignRejected(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	sess, _ := createTestRequest(t, db)
+	r := &Request{
+		ProjectPath:        "/test/project",
+		RequestorSessionID: sess.ID,
+		RequestorAgent:     sess.AgentName,
+		RequestorModel:     sess.Model,
+		RiskTier:           RiskTierDangerous,
+		MinApprovals:       1,
+		Command:            CommandSpec{Raw: "psql -f 001.sql", Cwd: "/test/project"},
+		Justification:      Justification{Reason: "migrate"},
+		CampaignID:         "no-such-campaign",
+	}
+	if err := db.CreateRequest(r); err == nil {
+		t.Error("expected the campaign foreign key to reject an unknown campaign")
+	}
+}
@@ -0,0 +1,180 @@
+// Package db provides command canonicalization for hashing and matching.
+package db
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// canonicalFlagSpec describes the boolean short flags of a tool whose
+// relative order never changes behavior. Flags that take values or override
+// each other (rm -i/-f, mv -i/-n, ...) are deliberately absent: any flag not
+// listed leaves the command untouched.
+type canonicalFlagSpec struct {
+	// order lists the known flags in their canonical order.
+	order string
+	// aliases maps equivalent spellings (short or long) to a flag in order.
+	aliases map[string]byte
+}
+
+var canonicalFlagSpecs = map[string]canonicalFlagSpec{
+	"rm": {
+		order: "rfdv",
+		aliases: map[string]byte{
+			"R": 'r', "--recursive": 'r', "--force": 'f', "--dir": 'd', "--verbose": 'v',
+		},
+	},
+	"cp": {
+		order: "rapfv",
+		aliases: map[string]byte{
+			"R": 'r', "--recursive": 'r', "--archive": 'a', "--force": 'f', "--verbose": 'v',
+		},
+	},
+	"mv": {
+		order:   "fv",
+		aliases: map[string]byte{"--force": 'f', "--verbose": 'v'},
+	},
+	"mkdir": {
+		order:   "pv",
+		aliases: map[string]byte{"--parents": 'p', "--verbose": 'v'},
+	},
+	"chown": {
+		order:   "Rhfv",
+		aliases: map[string]byte{"--recursive": 'R', "--verbose": 'v'},
+	},
+}
+
+// canonicalFlags returns the canonical "-xyz" form of the leading option
+// tokens of tool. ok is false when the tool is unknown or any option is not
+// understood, in which case the command must be left as written.
+func canonicalFlags(tool string, opts []string) (string, bool) {
+	spec, known := canonicalFlagSpecs[filepath.Base(tool)]
+	if !known || len(opts) == 0 {
+		return "", false
+	}
+
+	set := make(map[byte]bool)
+	for _, opt := range opts {
+		if strings.HasPrefix(opt, "--") {
+			flag, ok := spec.aliases[opt]
+			if !ok {
+				return "", false
+			}
+			set[flag] = true
+			continue
+		}
+		for i := 1; i < len(opt); i++ {
+			flag := opt[i]
+			if alias, ok := spec.aliases[string(flag)]; ok {
+				flag = alias
+			}
+			if strings.IndexByte(spec.order, flag) < 0 {
+				return "", false
+			}
+			set[flag] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteByte('-')
+	for i := 0; i < len(spec.order); i++ {
+		if set[spec.order[i]] {
+			b.WriteByte(spec.order[i])
+		}
+	}
+	return b.String(), true
+}
+
+// isOptionToken reports whether tok is a short option group or long option
+// (not "-" or the "--" end-of-options marker).
+func isOptionToken(tok string) bool {
+	return len(tok) > 1 && tok[0] == '-' && tok != "--"
+}
+
+// CanonicalizeArgv rewrites the leading flags of a known tool into canonical
+// form, so that e.g. "rm -fr x", "rm -r -f x" and "rm --recursive --force x"
+// all become "rm -rf x". Operands and anything after "--" keep their order.
+// Unknown tools or flags return argv unchanged.
+func CanonicalizeArgv(argv []string) []string {
+	if len(argv) < 2 {
+		return argv
+	}
+	end := 1
+	for end < len(argv) && isOptionToken(argv[end]) {
+		end++
+	}
+	flags, ok := canonicalFlags(argv[0], argv[1:end])
+	if !ok {
+		return argv
+	}
+
+	out := make([]string, 0, len(argv)-end+2)
+	out = append(out, argv[0], flags)
+	return append(out, argv[end:]...)
+}
+
+// CanonicalizeCommandLine is CanonicalizeArgv for a shell command line. Only
+// the command word and leading plain option words are rewritten; the rest of
+// the line is kept byte-for-byte so quoting and expansions are preserved.
+func CanonicalizeCommandLine(raw string) string {
+	var words []string
+	rest := ""
+	pos := 0
+	for {
+		for pos < len(raw) && isCommandLineSpace(raw[pos]) {
+			pos++
+		}
+		if pos >= len(raw) {
+			break
+		}
+		start := pos
+		for pos < len(raw) && !isCommandLineSpace(raw[pos]) {
+			pos++
+		}
+		word := raw[start:pos]
+		if !isPlainWord(word) || (len(words) > 0 && !isOptionToken(word)) {
+			rest = raw[start:]
+			break
+		}
+		words = append(words, word)
+	}
+	if len(words) < 2 {
+		return raw
+	}
+
+	flags, ok := canonicalFlags(words[0], words[1:])
+	if !ok {
+		return raw
+	}
+	canonical := words[0] + " " + flags
+	if rest != "" {
+		canonical += " " + rest
+	}
+	return canonical
+}
+
+func isCommandLineSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isPlainWord reports whether word has no shell quoting, expansion or
+// operator characters, so it means the same with or without re-spacing.
+func isPlainWord(word string) bool {
+	for i := 0; i < len(word); i++ {
+		c := word[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == '/':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// CanonicalCommandSpec returns cmd with Raw and Argv canonicalized.
+func CanonicalCommandSpec(cmd CommandSpec) CommandSpec {
+	cmd.Raw = CanonicalizeCommandLine(cmd.Raw)
+	cmd.Argv = CanonicalizeArgv(cmd.Argv)
+	return cmd
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalizeCommandLine(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"rm combined", "rm -rf x", "rm -rf x"},
+		{"rm reversed", "rm -fr x", "rm -rf x"},
+		{"rm split", "rm -r -f x", "rm -rf x"},
+		{"rm long", "rm --recursive --force x", "rm -rf x"},
+		{"rm capital R", "rm -Rf x", "rm -rf x"},
+		{"rm duplicate", "rm -r -r x", "rm -r x"},
+		{"rm extra spacing", "rm  -f\t-r   x", "rm -rf x"},
+		{"operands keep quoting", `rm -fr "my dir" $HOME/*`, `rm -rf "my dir" $HOME/*`},
+		{"after end of options", "rm -f -- -r", "rm -f -- -r"},
+		{"trailing flags untouched", "rm x -fr", "rm x -fr"},
+		{"mkdir", "mkdir -vp a/b", "mkdir -pv a/b"},
+		{"cp operand order kept", "cp -fR src dst", "cp -rf src dst"},
+		{"chown", "chown -v -R user /srv", "chown -Rv user /srv"},
+
+		// Conservative: leave anything not fully understood alone.
+		{"rm -i conflicts with -f", "rm -if x", "rm -if x"},
+		{"unknown long flag", "rm --one-file-system -rf x", "rm --one-file-system -rf x"},
+		{"flag with value", "cp -t dst -r src", "cp -t dst -r src"},
+		{"unknown tool", "tar -xzf a.tgz", "tar -xzf a.tgz"},
+		{"chmod not canonicalized", "chmod -x -R f", "chmod -x -R f"},
+		{"wrapper", "sudo rm -fr x", "sudo rm -fr x"},
+		{"env assignment", "FOO=1 rm -fr x", "FOO=1 rm -fr x"},
+		{"operator in flags", "rm -fr;ls", "rm -fr;ls"},
+		{"no flags", "rm x", "rm x"},
+		{"empty", "", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CanonicalizeCommandLine(tc.raw); got != tc.want {
+				t.Errorf("CanonicalizeCommandLine(%q) = %q, want %q", tc.raw, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeArgv(t *testing.T) {
+	tests := []struct {
+		argv []string
+		want []string
+	}{
+		{[]string{"rm", "-fr", "x"}, []string{"rm", "-rf", "x"}},
+		{[]string{"/bin/rm", "-r", "-f", "my dir"}, []string{"/bin/rm", "-rf", "my dir"}},
+		{[]string{"rm", "--force", "--", "-r"}, []string{"rm", "-f", "--", "-r"}},
+		{[]string{"rm", "-i", "x"}, []string{"rm", "-i", "x"}},
+		{[]string{"rm"}, []string{"rm"}},
+		{nil, nil},
+	}
+	for _, tc := range tests {
+		if got := CanonicalizeArgv(tc.argv); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("CanonicalizeArgv(%q) = %q, want %q", tc.argv, got, tc.want)
+		}
+	}
+
+	argv := []string{"rm", "-fr", "x"}
+	CanonicalizeArgv(argv)
+	if argv[1] != "-fr" {
+		t.Errorf("CanonicalizeArgv mutated its input: %q", argv)
+	}
+}
+
+func TestComputeCommandHash_EquivalentFlagOrderings(t *testing.T) {
+	variants := []CommandSpec{
+		{Raw: "rm -rf x", Argv: []string{"rm", "-rf", "x"}, Cwd: "/tmp"},
+		{Raw: "rm -fr x", Argv: []string{"rm", "-fr", "x"}, Cwd: "/tmp"},
+		{Raw: "rm -r -f x", Argv: []string{"rm", "-r", "-f", "x"}, Cwd: "/tmp"},
+	}
+	want := ComputeCommandHash(variants[0])
+	for _, v := range variants {
+		if canon := CanonicalCommandSpec(v); canon.Raw != "rm -rf x" {
+			t.Errorf("CanonicalCommandSpec(%q).Raw = %q, want %q", v.Raw, canon.Raw, "rm -rf x")
+		}
+		if got := ComputeCommandHash(v); got != want {
+			t.Errorf("ComputeCommandHash(%q) = %s, want %s", v.Raw, got, want)
+		}
+	}
+
+	different := CommandSpec{Raw: "rm -r x", Argv: []string{"rm", "-r", "x"}, Cwd: "/tmp"}
+	if ComputeCommandHash(different) == want {
+		t.Error("rm -r x must not hash like rm -rf x")
+	}
+	quoted := CommandSpec{Raw: `rm -rf "x y"`, Cwd: "/tmp"}
+	unquoted := CommandSpec{Raw: "rm -rf x y", Cwd: "/tmp"}
+	if ComputeCommandHash(quoted) == ComputeCommandHash(unquoted) {
+		t.Error("operand quoting must be preserved in the hash")
+	}
+}
+
+func TestCommandHashMatches(t *testing.T) {
+	cmd := CommandSpec{Raw: "rm -fr x", Cwd: "/tmp"}
+
+	cmd.Hash = ComputeCommandHash(cmd)
+	if !CommandHashMatches(cmd) {
+		t.Error("canonical hash should match")
+	}
+
+	// Requests stored before canonicalization carry the as-written hash.
+	cmd.Hash = hashCommandSpec(cmd)
+	if !CommandHashMatches(cmd) {
+		t.Error("pre-canonicalization hash should still match")
+	}
+
+	cmd.Raw = "rm -fr y"
+	if CommandHashMatches(cmd) {
+		t.Error("hash must not match a modified command")
+	}
+}
@@ -5,10 +5,12 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	_ "modernc.org/sqlite" // Pure Go SQLite driver
 )
@@ -152,6 +154,27 @@ func (db *DB) GetSchemaVersion() (int, error) {
 	return currentVersion(db.conn)
 }
 
+// MigrationAppliedAt returns when the migration version was applied to this
+// database, or the zero time if it has no record of it.
+func (db *DB) MigrationAppliedAt(version int) (time.Time, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var appliedAt string
+	err := db.conn.QueryRow(`SELECT applied_at FROM schema_migrations WHERE version = ?`, version).Scan(&appliedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading migration %d: %w", version, err)
+	}
+	t, err := time.Parse(time.RFC3339, appliedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing migration %d applied_at: %w", version, err)
+	}
+	return t, nil
+}
+
 // ValidateSchema ensures the database is at the expected schema version.
 func (db *DB) ValidateSchema() error {
 	version, err := db.GetSchemaVersion()
@@ -122,6 +122,22 @@ func TestValidateSchema_Mismatch(t *testing.T) {
 	}
 }
 
+func TestMigrationAppliedAt(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	applied, err := db.MigrationAppliedAt(ReviewCommandHashVersion)
+	if err != nil || applied.IsZero() || applied.After(time.Now()) {
+		t.Fatalf("MigrationAppliedAt(%d) = %v, %v", ReviewCommandHashVersion, applied, err)
+	}
+	if applied, err := db.MigrationAppliedAt(999); err != nil || !applied.IsZero() {
+		t.Errorf("expected the zero time for an unapplied migration, got %v, %v", applied, err)
+	}
+}
+
 func TestApplyMigrations_Idempotent(t *testing.T) {
 	tmpDir := t.TempDir()
 	db, err := Open(filepath.Join(tmpDir, "test.db"))
@@ -0,0 +1,194 @@
+// Package db provides storage for approval delegations.
+package db
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrDelegationNotFound indicates no delegation matched.
+var ErrDelegationNotFound = errors.New("delegation not found")
+
+// Delegation records a reviewer's signed, time-boxed standing approval:
+// requests whose primary command matches Pattern, at MaxTier or below, are
+// approved on the reviewer's behalf until ExpiresAt.
+type Delegation struct {
+	ID          string `json:"id"`
+	ProjectPath string `json:"project_path"`
+	// Pattern is a glob over the normalized primary command.
+	Pattern   string   `json:"pattern"`
+	MaxTier   RiskTier `json:"max_tier"`
+	SessionID string   `json:"session_id"`
+	AgentName string   `json:"agent_name"`
+	Model     string   `json:"model,omitempty"`
+	// Signature = HMAC-SHA256(sessionKey, projectPath + pattern + maxTier + expiresAt + createdAt).
+	Signature string     `json:"signature"`
+	Comment   string     `json:"comment,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy string     `json:"revoked_by,omitempty"`
+}
+
+// ActiveAt reports whether the delegation is unrevoked and unexpired at now.
+func (d *Delegation) ActiveAt(now time.Time) bool {
+	return d.RevokedAt == nil && now.Before(d.ExpiresAt)
+}
+
+// CreateDelegation inserts a delegation, generating ID and timestamp if
+// missing.
+func (db *DB) CreateDelegation(d *Delegation) error {
+	if d.ProjectPath == "" || d.Pattern == "" {
+		return fmt.Errorf("project_path and pattern are required")
+	}
+	if d.ExpiresAt.IsZero() {
+		return fmt.Errorf("expires_at is required")
+	}
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now().UTC()
+	}
+	_, err := db.Exec(`
+		INSERT INTO delegations (
+			id, project_path, pattern, max_tier, session_id,
+			agent_name, model, signature, comment, created_at, expires_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		d.ID, d.ProjectPath, d.Pattern, string(d.MaxTier), d.SessionID,
+		d.AgentName, nullString(d.Model), d.Signature, nullString(d.Comment),
+		d.CreatedAt.UTC().Format(time.RFC3339), d.ExpiresAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("creating delegation: %w", err)
+	}
+	return nil
+}
+
+const delegationColumns = `id, project_path, pattern, max_tier, session_id,
+	agent_name, model, signature, comment, created_at, expires_at, revoked_at, revoked_by`
+
+func scanDelegation(row interface{ Scan(...any) error }) (*Delegation, error) {
+	d := &Delegation{}
+	var tier, created, expires string
+	var model, comment, revokedAt, revokedBy sql.NullString
+	if err := row.Scan(&d.ID, &d.ProjectPath, &d.Pattern, &tier, &d.SessionID,
+		&d.AgentName, &model, &d.Signature, &comment, &created, &expires, &revokedAt, &revokedBy); err != nil {
+		return nil, err
+	}
+	d.MaxTier = RiskTier(tier)
+	d.Model = model.String
+	d.Comment = comment.String
+	d.CreatedAt, _ = time.Parse(time.RFC3339, created)
+	d.ExpiresAt, _ = time.Parse(time.RFC3339, expires)
+	d.RevokedAt = parseTimePtr(revokedAt)
+	d.RevokedBy = revokedBy.String
+	return d, nil
+}
+
+func scanDelegations(rows *sql.Rows) ([]*Delegation, error) {
+	defer rows.Close()
+	var list []*Delegation
+	for rows.Next() {
+		d, err := scanDelegation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning delegations: %w", err)
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// ListDelegations returns a project's delegations, oldest first. Revoked and
+// expired ones are included only when asked.
+func (db *DB) ListDelegations(projectPath string, includeInactive bool, now time.Time) ([]*Delegation, error) {
+	query := `SELECT ` + delegationColumns + ` FROM delegations WHERE project_path = ?`
+	args := []any{projectPath}
+	if !includeInactive {
+		query += ` AND revoked_at IS NULL AND expires_at > ?`
+		args = append(args, now.UTC().Format(time.RFC3339))
+	}
+	query += ` ORDER BY created_at, rowid`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing delegations: %w", err)
+	}
+	return scanDelegations(rows)
+}
+
+// GetDelegation returns a delegation by ID.
+// Returns ErrDelegationNotFound if it does not exist.
+func (db *DB) GetDelegation(id string) (*Delegation, error) {
+	d, err := scanDelegation(db.QueryRow(`SELECT `+delegationColumns+` FROM delegations WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDelegationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting delegation: %w", err)
+	}
+	return d, nil
+}
+
+// RevokeDelegation revokes an active delegation on behalf of revokedBy.
+func (db *DB) RevokeDelegation(id, revokedBy string, at time.Time) error {
+	res, err := db.Exec(`
+		UPDATE delegations SET revoked_at = ?, revoked_by = ?
+		WHERE id = ? AND revoked_at IS NULL
+	`, at.UTC().Format(time.RFC3339), revokedBy, id)
+	if err != nil {
+		return fmt.Errorf("revoking delegation: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrDelegationNotFound
+	}
+	return nil
+}
+
+// DeleteExpiredDelegations removes a project's delegations that expired by
+// now, revoked or not, and returns them. Reviews recorded under them keep
+// the delegation ID.
+func (db *DB) DeleteExpiredDelegations(projectPath string, now time.Time) ([]*Delegation, error) {
+	var expired []*Delegation
+	err := db.Transaction(func(tx *sql.Tx) error {
+		rows, err := tx.Query(`SELECT `+delegationColumns+` FROM delegations
+			WHERE project_path = ? AND expires_at <= ? ORDER BY created_at, rowid`,
+			projectPath, now.UTC().Format(time.RFC3339))
+		if err != nil {
+			return fmt.Errorf("listing expired delegations: %w", err)
+		}
+		if expired, err = scanDelegations(rows); err != nil {
+			return err
+		}
+		for _, d := range expired {
+			if _, err := tx.Exec(`DELETE FROM delegations WHERE id = ?`, d.ID); err != nil {
+				return fmt.Errorf("deleting delegation: %w", err)
+			}
+		}
+		return nil
+	})
+	return expired, err
+}
+
+// ComputeDelegationSignature computes an HMAC signature for a delegation.
+// Signature = HMAC-SHA256(sessionKey, projectPath + pattern + maxTier + expiresAt + createdAt)
+func ComputeDelegationSignature(sessionKey, projectPath, pattern string, maxTier RiskTier, expiresAt, createdAt time.Time) string {
+	data := projectPath + pattern + string(maxTier) + expiresAt.UTC().Format(time.RFC3339) + createdAt.UTC().Format(time.RFC3339)
+	key, _ := hex.DecodeString(sessionKey)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyDelegationSignature verifies an HMAC signature for a delegation.
+func VerifyDelegationSignature(sessionKey string, d *Delegation) bool {
+	expected := ComputeDelegationSignature(sessionKey, d.ProjectPath, d.Pattern, d.MaxTier, d.ExpiresAt, d.CreatedAt)
+	return hmac.Equal([]byte(expected), []byte(d.Signature))
+}
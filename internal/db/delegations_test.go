@@ -0,0 +1,86 @@
+// Package db tests for approval delegation storage.
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDelegations_CreateListRevokeSweep(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	key := "00112233445566778899aabbccddeeff"
+	active := &Delegation{
+		ProjectPath: "/test/project",
+		Pattern:     "terraform apply -target=module.cache*",
+		MaxTier:     RiskTierDangerous,
+		SessionID:   "sess-1",
+		AgentName:   "GreenLake",
+		Comment:     "cache rollout",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(4 * time.Hour),
+	}
+	active.Signature = ComputeDelegationSignature(key, active.ProjectPath, active.Pattern, active.MaxTier, active.ExpiresAt, active.CreatedAt)
+	if err := db.CreateDelegation(active); err != nil {
+		t.Fatalf("CreateDelegation failed: %v", err)
+	}
+	expired := &Delegation{
+		ProjectPath: "/test/project",
+		Pattern:     "make deploy",
+		MaxTier:     RiskTierCaution,
+		SessionID:   "sess-1",
+		AgentName:   "GreenLake",
+		CreatedAt:   now.Add(-2 * time.Hour),
+		ExpiresAt:   now.Add(-time.Hour),
+	}
+	if err := db.CreateDelegation(expired); err != nil {
+		t.Fatalf("CreateDelegation (expired) failed: %v", err)
+	}
+
+	got, err := db.GetDelegation(active.ID)
+	if err != nil {
+		t.Fatalf("GetDelegation failed: %v", err)
+	}
+	if got.Pattern != active.Pattern || got.Comment != "cache rollout" || !got.ActiveAt(now) {
+		t.Errorf("unexpected delegation %+v", got)
+	}
+	if !VerifyDelegationSignature(key, got) {
+		t.Error("expected the stored delegation's signature to verify")
+	}
+	got.Pattern = "terraform apply*"
+	if VerifyDelegationSignature(key, got) {
+		t.Error("expected a widened pattern to fail signature verification")
+	}
+
+	list, err := db.ListDelegations("/test/project", false, now)
+	if err != nil || len(list) != 1 || list[0].ID != active.ID {
+		t.Fatalf("expected only the active delegation, got %v, %v", list, err)
+	}
+	if all, _ := db.ListDelegations("/test/project", true, now); len(all) != 2 {
+		t.Errorf("expected both delegations with includeInactive, got %d", len(all))
+	}
+
+	if err := db.RevokeDelegation(active.ID, "BlueDog", now); err != nil {
+		t.Fatalf("RevokeDelegation failed: %v", err)
+	}
+	if err := db.RevokeDelegation(active.ID, "BlueDog", now); !errors.Is(err, ErrDelegationNotFound) {
+		t.Errorf("expected ErrDelegationNotFound revoking twice, got %v", err)
+	}
+	if got, _ := db.GetDelegation(active.ID); got.ActiveAt(now) || got.RevokedBy != "BlueDog" {
+		t.Errorf("expected the delegation revoked by BlueDog, got %+v", got)
+	}
+
+	swept, err := db.DeleteExpiredDelegations("/test/project", now)
+	if err != nil || len(swept) != 1 || swept[0].ID != expired.ID {
+		t.Fatalf("expected the expired delegation swept, got %v, %v", swept, err)
+	}
+	if _, err := db.GetDelegation(expired.ID); !errors.Is(err, ErrDelegationNotFound) {
+		t.Errorf("expected the swept delegation gone, got %v", err)
+	}
+	if _, err := db.GetDelegation(active.ID); err != nil {
+		t.Errorf("expected the unexpired revoked delegation kept, got %v", err)
+	}
+}
@@ -43,12 +43,19 @@ func (t RiskTier) MinApprovals() int {
 type RequestStatus string
 
 const (
+	// StatusQueued means the request is held by the rate limiter until its
+	// session has capacity; it becomes pending when admitted.
+	StatusQueued RequestStatus = "queued"
 	// StatusPending means the request is waiting for approval.
 	StatusPending RequestStatus = "pending"
 	// StatusApproved means the request has been approved but not executed.
 	StatusApproved RequestStatus = "approved"
 	// StatusRejected means the request has been rejected.
 	StatusRejected RequestStatus = "rejected"
+	// StatusApprovalExpired means the approval lapsed before the command was
+	// executed. The request keeps its reviews; the requestor can send it
+	// back to pending for a new review round (slb rerequest).
+	StatusApprovalExpired RequestStatus = "approval_expired"
 	// StatusExecuting means the command is currently being executed.
 	StatusExecuting RequestStatus = "executing"
 	// StatusExecuted means the command was executed successfully.
@@ -68,9 +75,9 @@ const (
 // Valid returns true if the status is a valid request status.
 func (s RequestStatus) Valid() bool {
 	switch s {
-	case StatusPending, StatusApproved, StatusRejected, StatusExecuting, StatusExecuted,
+	case StatusQueued, StatusPending, StatusApproved, StatusRejected, StatusExecuting, StatusExecuted,
 		StatusExecutionFailed, StatusCancelled, StatusTimeout, StatusTimedOut,
-		StatusEscalated:
+		StatusEscalated, StatusApprovalExpired:
 		return true
 	default:
 		return false
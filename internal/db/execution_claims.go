@@ -0,0 +1,200 @@
+// Package db provides storage for per-project execution slots and the
+// execution queue.
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ExecutionClaim is an approved request's claim on one of its project's
+// execution slots. A claim with AcquiredAt set holds a slot; one without is
+// waiting in the project's execution queue.
+type ExecutionClaim struct {
+	RequestID   string `json:"request_id"`
+	ProjectPath string `json:"project_path"`
+	// Hostname and PID identify the executor process, so a claim it left
+	// behind when it died can be recovered.
+	Hostname string `json:"hostname,omitempty"`
+	PID      int    `json:"pid,omitempty"`
+	// Priority is the request's execution priority; higher values are
+	// admitted first, then earlier approvals.
+	Priority   int       `json:"priority"`
+	ApprovedAt time.Time `json:"approved_at"`
+	QueuedAt   time.Time `json:"queued_at"`
+	// AcquiredAt is when the claim took a slot; nil while it waits.
+	AcquiredAt *time.Time `json:"acquired_at,omitempty"`
+	// LeaseExpiresAt bounds how long a held slot is honored when its
+	// executor cannot be checked directly, such as one on another host.
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+}
+
+// Held reports whether the claim holds a slot.
+func (c *ExecutionClaim) Held() bool {
+	return c.AcquiredAt != nil
+}
+
+// EnqueueExecution records a claim waiting for an execution slot. A request
+// that is already waiting keeps its place and is taken over by the claim's
+// executor, whose lease is renewed; one already holding a slot is left
+// unchanged.
+func (db *DB) EnqueueExecution(c *ExecutionClaim) error {
+	if c.RequestID == "" || c.ProjectPath == "" {
+		return fmt.Errorf("request_id and project_path are required")
+	}
+	if c.QueuedAt.IsZero() {
+		c.QueuedAt = time.Now().UTC()
+	}
+	if c.ApprovedAt.IsZero() {
+		c.ApprovedAt = c.QueuedAt
+	}
+	if _, err := db.Exec(`
+		INSERT INTO execution_claims (request_id, project_path, hostname, pid, approved_at, queued_at, lease_expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(request_id) DO UPDATE SET
+			hostname = excluded.hostname, pid = excluded.pid, lease_expires_at = excluded.lease_expires_at
+		WHERE execution_claims.acquired_at IS NULL
+	`, c.RequestID, c.ProjectPath, c.Hostname, c.PID,
+		c.ApprovedAt.UTC().Format(time.RFC3339), c.QueuedAt.UTC().Format(time.RFC3339),
+		formatTimePtr(c.LeaseExpiresAt)); err != nil {
+		return fmt.Errorf("enqueueing execution: %w", err)
+	}
+	return nil
+}
+
+// AcquireExecutionSlot moves a waiting claim into a slot if its project
+// holds fewer than limit slots counting the waiters queued ahead of it, so
+// slots are handed out in queue order. It reports whether the claim now
+// holds a slot. The check and the update are one statement, so concurrent
+// executors cannot overfill the project.
+func (db *DB) AcquireExecutionSlot(requestID string, limit int, now, leaseExpiresAt time.Time) (bool, error) {
+	res, err := db.Exec(`
+		UPDATE execution_claims SET acquired_at = ?, lease_expires_at = ?
+		WHERE request_id = ? AND acquired_at IS NULL AND (
+			SELECT COUNT(*) FROM execution_claims h
+			WHERE h.project_path = execution_claims.project_path AND h.acquired_at IS NOT NULL
+		) + (
+			SELECT COUNT(*)
+			FROM execution_claims w
+			JOIN requests wr ON wr.id = w.request_id
+			JOIN requests mr ON mr.id = execution_claims.request_id
+			WHERE w.project_path = execution_claims.project_path
+			  AND w.acquired_at IS NULL
+			  AND (-wr.execution_priority, w.approved_at, w.queued_at, w.request_id)
+			    < (-mr.execution_priority, execution_claims.approved_at, execution_claims.queued_at, execution_claims.request_id)
+		) < ?
+	`, now.UTC().Format(time.RFC3339), leaseExpiresAt.UTC().Format(time.RFC3339), requestID, limit)
+	if err != nil {
+		return false, fmt.Errorf("acquiring execution slot: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("acquiring execution slot: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ReleaseExecutionClaim removes a request's claim, freeing its slot or its
+// place in the queue. Releasing a request without a claim is a no-op.
+func (db *DB) ReleaseExecutionClaim(requestID string) error {
+	if _, err := db.Exec(`DELETE FROM execution_claims WHERE request_id = ?`, requestID); err != nil {
+		return fmt.Errorf("releasing execution claim: %w", err)
+	}
+	return nil
+}
+
+// GetExecutionClaim returns a request's claim, or nil if it has none.
+func (db *DB) GetExecutionClaim(requestID string) (*ExecutionClaim, error) {
+	rows, err := db.Query(executionClaimSelect+` WHERE c.request_id = ?`, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("querying execution claim: %w", err)
+	}
+	claims, err := scanExecutionClaims(rows)
+	if err != nil || len(claims) == 0 {
+		return nil, err
+	}
+	return claims[0], nil
+}
+
+// ListExecutionClaims returns a project's claims, or every project's when
+// projectPath is empty: held slots first, oldest first, then the queue in
+// admission order (priority, then approval time, then queue time).
+func (db *DB) ListExecutionClaims(projectPath string) ([]*ExecutionClaim, error) {
+	query := executionClaimSelect
+	var args []any
+	if projectPath != "" {
+		query += ` WHERE c.project_path = ?`
+		args = append(args, projectPath)
+	}
+	query += `
+		ORDER BY c.project_path, c.acquired_at IS NULL, c.acquired_at,
+			r.execution_priority DESC, c.approved_at, c.queued_at, c.request_id`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying execution claims: %w", err)
+	}
+	return scanExecutionClaims(rows)
+}
+
+// SetExecutionPriority sets the priority a request's execution claim is
+// queued with; higher values are admitted first.
+func (db *DB) SetExecutionPriority(requestID string, priority int) error {
+	return db.Transaction(func(tx *sql.Tx) error {
+		return db.SetExecutionPriorityTx(tx, requestID, priority)
+	})
+}
+
+// SetExecutionPriorityTx is SetExecutionPriority within a transaction.
+func (db *DB) SetExecutionPriorityTx(tx *sql.Tx, requestID string, priority int) error {
+	res, err := tx.Exec(`UPDATE requests SET execution_priority = ? WHERE id = ?`, priority, requestID)
+	if err != nil {
+		return fmt.Errorf("setting execution priority: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRequestNotFound
+	}
+	return nil
+}
+
+// GetExecutionPriority returns a request's execution priority.
+func (db *DB) GetExecutionPriority(requestID string) (int, error) {
+	var priority int
+	err := db.QueryRow(`SELECT execution_priority FROM requests WHERE id = ?`, requestID).Scan(&priority)
+	if err == sql.ErrNoRows {
+		return 0, ErrRequestNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("querying execution priority: %w", err)
+	}
+	return priority, nil
+}
+
+const executionClaimSelect = `
+	SELECT c.request_id, c.project_path, c.hostname, c.pid, r.execution_priority,
+		c.approved_at, c.queued_at, c.acquired_at, c.lease_expires_at
+	FROM execution_claims c
+	JOIN requests r ON r.id = c.request_id`
+
+func scanExecutionClaims(rows *sql.Rows) ([]*ExecutionClaim, error) {
+	defer rows.Close()
+	var claims []*ExecutionClaim
+	for rows.Next() {
+		c := &ExecutionClaim{}
+		var approvedAt, queuedAt string
+		var acquiredAt, leaseExpiresAt sql.NullString
+		if err := rows.Scan(&c.RequestID, &c.ProjectPath, &c.Hostname, &c.PID, &c.Priority,
+			&approvedAt, &queuedAt, &acquiredAt, &leaseExpiresAt); err != nil {
+			return nil, fmt.Errorf("scanning execution claim: %w", err)
+		}
+		c.ApprovedAt, _ = time.Parse(time.RFC3339, approvedAt)
+		c.QueuedAt, _ = time.Parse(time.RFC3339, queuedAt)
+		c.AcquiredAt = parseTimePtr(acquiredAt)
+		c.LeaseExpiresAt = parseTimePtr(leaseExpiresAt)
+		claims = append(claims, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating execution claims: %w", err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExecutionClaims_SlotsGoInQueueOrder(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	var ids []string
+	for i := 0; i < 3; i++ {
+		_, req := createTestRequest(t, db)
+		ids = append(ids, req.ID)
+		// Approved a minute apart, enqueued in reverse order.
+		claim := &ExecutionClaim{
+			RequestID:   req.ID,
+			ProjectPath: req.ProjectPath,
+			PID:         100 + i,
+			ApprovedAt:  base.Add(time.Duration(i) * time.Minute),
+			QueuedAt:    base.Add(time.Duration(10-i) * time.Minute),
+		}
+		if err := db.EnqueueExecution(claim); err != nil {
+			t.Fatalf("EnqueueExecution failed: %v", err)
+		}
+	}
+
+	now := base.Add(time.Hour)
+	lease := now.Add(time.Hour)
+	// The last approval cannot jump the queue.
+	if ok, err := db.AcquireExecutionSlot(ids[2], 1, now, lease); err != nil || ok {
+		t.Fatalf("AcquireExecutionSlot(third) = %v, %v; want false", ok, err)
+	}
+	// An expedited request goes first.
+	if err := db.SetExecutionPriority(ids[2], 1); err != nil {
+		t.Fatalf("SetExecutionPriority failed: %v", err)
+	}
+	if ok, err := db.AcquireExecutionSlot(ids[0], 1, now, lease); err != nil || ok {
+		t.Fatalf("AcquireExecutionSlot(first) = %v, %v; want false behind expedited", ok, err)
+	}
+	if ok, err := db.AcquireExecutionSlot(ids[2], 1, now, lease); err != nil || !ok {
+		t.Fatalf("AcquireExecutionSlot(expedited) = %v, %v; want true", ok, err)
+	}
+	// The limit is reached.
+	if ok, err := db.AcquireExecutionSlot(ids[0], 1, now, lease); err != nil || ok {
+		t.Fatalf("AcquireExecutionSlot(first) = %v, %v; want false while slot held", ok, err)
+	}
+	// A second slot goes to the earliest approval.
+	if ok, err := db.AcquireExecutionSlot(ids[1], 2, now, lease); err != nil || ok {
+		t.Fatalf("AcquireExecutionSlot(second) = %v, %v; want false behind first", ok, err)
+	}
+	if ok, err := db.AcquireExecutionSlot(ids[0], 2, now, lease); err != nil || !ok {
+		t.Fatalf("AcquireExecutionSlot(first) = %v, %v; want true", ok, err)
+	}
+
+	claims, err := db.ListExecutionClaims("/test/project")
+	if err != nil {
+		t.Fatalf("ListExecutionClaims failed: %v", err)
+	}
+	if len(claims) != 3 {
+		t.Fatalf("expected 3 claims, got %d", len(claims))
+	}
+	if !claims[0].Held() || !claims[1].Held() || claims[2].Held() {
+		t.Fatalf("expected two held claims then one waiting, got %+v", claims)
+	}
+	if claims[2].RequestID != ids[1] || claims[2].PID != 101 {
+		t.Errorf("waiting claim = %+v", claims[2])
+	}
+	if claims[0].LeaseExpiresAt == nil || !claims[0].LeaseExpiresAt.Equal(lease) {
+		t.Errorf("held claim lease = %v, want %v", claims[0].LeaseExpiresAt, lease)
+	}
+
+	// Releasing frees the slot for the next in line.
+	if err := db.ReleaseExecutionClaim(ids[2]); err != nil {
+		t.Fatalf("ReleaseExecutionClaim failed: %v", err)
+	}
+	if ok, err := db.AcquireExecutionSlot(ids[1], 2, now, lease); err != nil || !ok {
+		t.Fatalf("AcquireExecutionSlot(second) = %v, %v; want true after release", ok, err)
+	}
+	if claim, err := db.GetExecutionClaim(ids[2]); err != nil || claim != nil {
+		t.Errorf("GetExecutionClaim(released) = %+v, %v; want nil", claim, err)
+	}
+}
+
+func TestEnqueueExecution_KeepsPlaceAndHeldSlot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, req := createTestRequest(t, db)
+	queuedAt := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	claim := &ExecutionClaim{RequestID: req.ID, ProjectPath: req.ProjectPath, PID: 1, QueuedAt: queuedAt}
+	if err := db.EnqueueExecution(claim); err != nil {
+		t.Fatalf("EnqueueExecution failed: %v", err)
+	}
+
+	// A retry by another executor takes the claim over without losing its place.
+	retry := &ExecutionClaim{RequestID: req.ID, ProjectPath: req.ProjectPath, PID: 2, QueuedAt: queuedAt.Add(time.Hour)}
+	if err := db.EnqueueExecution(retry); err != nil {
+		t.Fatalf("EnqueueExecution(retry) failed: %v", err)
+	}
+	got, err := db.GetExecutionClaim(req.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetExecutionClaim = %+v, %v", got, err)
+	}
+	if got.PID != 2 || !got.QueuedAt.Equal(queuedAt) {
+		t.Errorf("claim after retry = %+v; want pid 2 queued at %v", got, queuedAt)
+	}
+
+	// A held slot is not taken over.
+	now := queuedAt.Add(time.Minute)
+	if ok, err := db.AcquireExecutionSlot(req.ID, 1, now, now.Add(time.Hour)); err != nil || !ok {
+		t.Fatalf("AcquireExecutionSlot = %v, %v", ok, err)
+	}
+	if err := db.EnqueueExecution(&ExecutionClaim{RequestID: req.ID, ProjectPath: req.ProjectPath, PID: 3}); err != nil {
+		t.Fatalf("EnqueueExecution(held) failed: %v", err)
+	}
+	if got, _ := db.GetExecutionClaim(req.ID); got == nil || got.PID != 2 || !got.Held() {
+		t.Errorf("held claim = %+v; want pid 2 still holding", got)
+	}
+
+	if err := db.SetExecutionPriority("missing", 1); err != ErrRequestNotFound {
+		t.Errorf("SetExecutionPriority(missing) = %v, want ErrRequestNotFound", err)
+	}
+}
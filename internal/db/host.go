@@ -0,0 +1,41 @@
+// Package db provides host identification for sessions.
+package db
+
+import (
+	"os"
+	"strings"
+)
+
+// machineIDPaths are read in order for a stable machine identifier.
+var machineIDPaths = []string{"/etc/machine-id", "/var/lib/dbus/machine-id"}
+
+// LocalHostIdentity returns this machine's hostname and, where the OS
+// provides one, its machine ID. Either may be empty.
+func LocalHostIdentity() (hostname, machineID string) {
+	hostname, _ = os.Hostname()
+	for _, p := range machineIDPaths {
+		if b, err := os.ReadFile(p); err == nil {
+			if id := strings.TrimSpace(string(b)); id != "" {
+				return hostname, id
+			}
+		}
+	}
+	return hostname, ""
+}
+
+// OnDifferentHost reports whether s and other provably run on different
+// machines: by machine ID when both have one, otherwise by hostname. A
+// session with no host recorded is never on a different host, so host
+// diversity cannot be satisfied by missing data.
+func (s *Session) OnDifferentHost(other *Session) bool {
+	if s == nil || other == nil {
+		return false
+	}
+	if s.MachineID != "" && other.MachineID != "" {
+		return s.MachineID != other.MachineID
+	}
+	if s.Hostname != "" && other.Hostname != "" {
+		return !strings.EqualFold(s.Hostname, other.Hostname)
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidLabel is returned for label keys or values outside the allowed charset.
+var ErrInvalidLabel = errors.New("invalid label")
+
+// Label keys are lowercase (e.g. "env", "team", "app.kubernetes.io/name");
+// values are short tokens without spaces, commas, colons or "=".
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]{0,62}$`)
+	labelValuePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,63}$`)
+)
+
+// ValidateLabels checks every label key and value against the allowed charset.
+func ValidateLabels(labels map[string]string) error {
+	for _, k := range sortedLabelKeys(labels) {
+		if !labelKeyPattern.MatchString(k) {
+			return fmt.Errorf("%w: key %q (want lowercase letters, digits, '.', '_', '/', '-')", ErrInvalidLabel, k)
+		}
+		if v := labels[k]; !labelValuePattern.MatchString(v) {
+			return fmt.Errorf("%w: value %q for %s (want 1-63 letters, digits, '.', '_', '-')", ErrInvalidLabel, v, k)
+		}
+	}
+	return nil
+}
+
+// ParseLabels parses "key=value" pairs into a validated label map. Each
+// pair may itself hold several comma-separated pairs ("env=prod,team=web").
+func ParseLabels(pairs []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, pair := range pairs {
+		for _, part := range strings.Split(pair, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(part, "=")
+			if !ok {
+				return nil, fmt.Errorf("%w: %q is not key=value", ErrInvalidLabel, part)
+			}
+			labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	if err := ValidateLabels(labels); err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	return labels, nil
+}
+
+// MatchLabels reports whether labels contain every key/value in selector.
+// An empty selector matches everything.
+func MatchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatLabels renders labels as "key=value" pairs sorted by key.
+func FormatLabels(labels map[string]string) string {
+	parts := make([]string, 0, len(labels))
+	for _, k := range sortedLabelKeys(labels) {
+		parts = append(parts, k+"="+labels[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+func sortedLabelKeys(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateLabels(t *testing.T) {
+	valid := map[string]string{"env": "prod", "team": "web-2", "app.kubernetes.io/name": "api_v1.2"}
+	if err := ValidateLabels(valid); err != nil {
+		t.Fatalf("ValidateLabels(%v) = %v", valid, err)
+	}
+
+	invalid := []map[string]string{
+		{"Env": "prod"},
+		{"-env": "prod"},
+		{"env name": "prod"},
+		{"env:x": "prod"},
+		{"": "prod"},
+		{"env": ""},
+		{"env": "prod,eu"},
+		{"env": "a=b"},
+		{"env": "pro d"},
+		{"env": "prod:eu"},
+	}
+	for _, labels := range invalid {
+		if err := ValidateLabels(labels); !errors.Is(err, ErrInvalidLabel) {
+			t.Errorf("ValidateLabels(%v) = %v, want ErrInvalidLabel", labels, err)
+		}
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	got, err := ParseLabels([]string{"env=prod,team=web", " region = eu-1 "})
+	if err != nil {
+		t.Fatalf("ParseLabels: %v", err)
+	}
+	want := map[string]string{"env": "prod", "team": "web", "region": "eu-1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLabels = %v, want %v", got, want)
+	}
+
+	if got, err := ParseLabels(nil); err != nil || got != nil {
+		t.Errorf("ParseLabels(nil) = %v, %v; want nil, nil", got, err)
+	}
+	for _, bad := range []string{"env", "env=", "ENV=prod"} {
+		if _, err := ParseLabels([]string{bad}); !errors.Is(err, ErrInvalidLabel) {
+			t.Errorf("ParseLabels(%q) = %v, want ErrInvalidLabel", bad, err)
+		}
+	}
+}
+
+func TestMatchAndFormatLabels(t *testing.T) {
+	labels := map[string]string{"team": "web", "env": "prod"}
+	if !MatchLabels(labels, nil) {
+		t.Error("empty selector should match")
+	}
+	if !MatchLabels(labels, map[string]string{"env": "prod"}) {
+		t.Error("env=prod should match")
+	}
+	if MatchLabels(labels, map[string]string{"env": "staging"}) {
+		t.Error("env=staging should not match")
+	}
+	if MatchLabels(nil, map[string]string{"env": "prod"}) {
+		t.Error("unlabeled request should not match a selector")
+	}
+	if got := FormatLabels(labels); got != "env=prod,team=web" {
+		t.Errorf("FormatLabels = %q", got)
+	}
+}
+
+func TestRequestLabelsRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, plain := createTestRequest(t, db)
+	got, err := db.GetRequest(plain.ID)
+	if err != nil {
+		t.Fatalf("GetRequest failed: %v", err)
+	}
+	if got.Labels != nil {
+		t.Errorf("expected no labels, got %v", got.Labels)
+	}
+
+	sess, _ := createTestRequest(t, db)
+	r := &Request{
+		ProjectPath:        "/test/project",
+		RequestorSessionID: sess.ID,
+		RequestorAgent:     sess.AgentName,
+		RiskTier:           RiskTierDangerous,
+		MinApprovals:       1,
+		Command:            CommandSpec{Raw: "kubectl delete ns web", Cwd: "/test/project"},
+		Labels:             map[string]string{"env": "prod", "team": "web"},
+	}
+	if err := db.CreateRequest(r); err != nil {
+		t.Fatalf("CreateRequest failed: %v", err)
+	}
+	got, err = db.GetRequest(r.ID)
+	if err != nil {
+		t.Fatalf("GetRequest failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Labels, r.Labels) {
+		t.Errorf("Labels = %v, want %v", got.Labels, r.Labels)
+	}
+
+	pending, err := db.ListPendingRequests("/test/project")
+	if err != nil {
+		t.Fatalf("ListPendingRequests failed: %v", err)
+	}
+	found := false
+	for _, p := range pending {
+		if p.ID == r.ID {
+			found = reflect.DeepEqual(p.Labels, r.Labels)
+		}
+	}
+	if !found {
+		t.Error("expected labels to survive list scans")
+	}
+}
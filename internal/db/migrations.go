@@ -186,10 +186,443 @@ ALTER TABLE execution_outcomes ADD COLUMN caused_problems INTEGER NOT NULL DEFAU
 ALTER TABLE execution_outcomes ADD COLUMN problem_description TEXT;
 ALTER TABLE execution_outcomes ADD COLUMN human_rating INTEGER;
 ALTER TABLE execution_outcomes ADD COLUMN human_notes TEXT;
+`,
+	},
+	{
+		Version: 4,
+		Name:    "requests_context_pinning",
+		Up: `
+-- Cluster/cloud context captured at request time and how it was applied.
+ALTER TABLE requests ADD COLUMN pinned_context_json TEXT;
+ALTER TABLE requests ADD COLUMN execution_context_pinning TEXT;
+`,
+	},
+	{
+		Version: 5,
+		Name:    "request_tokens_index",
+		Up: `
+-- Hashed command tokens per request for similarity search.
+CREATE TABLE IF NOT EXISTS request_tokens (
+  request_id TEXT NOT NULL REFERENCES requests(id) ON DELETE CASCADE,
+  token TEXT NOT NULL,
+  PRIMARY KEY (request_id, token)
+) WITHOUT ROWID;
+CREATE INDEX IF NOT EXISTS idx_request_tokens_token ON request_tokens(token);
+`,
+	},
+	{
+		Version: 6,
+		Name:    "requests_normalization",
+		Up: `
+-- Normalization results computed once at request creation.
+ALTER TABLE requests ADD COLUMN command_normalized_json TEXT;
+ALTER TABLE requests ADD COLUMN command_summary TEXT;
+ALTER TABLE requests ADD COLUMN tier_reason TEXT;
+`,
+	},
+	{
+		Version: 7,
+		Name:    "policy_attestations",
+		Up: `
+-- Signed attestations that a project's auto-approve policy is still appropriate.
+CREATE TABLE IF NOT EXISTS policy_attestations (
+  id TEXT PRIMARY KEY,
+  project_path TEXT NOT NULL,
+  policy_path TEXT NOT NULL,
+  policy_hash TEXT NOT NULL,
+  session_id TEXT NOT NULL,
+  agent_name TEXT NOT NULL,
+  model TEXT,
+  signature TEXT NOT NULL,
+  comment TEXT,
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_policy_attestations_project
+  ON policy_attestations(project_path, created_at);
+`,
+	},
+	{
+		Version: 8,
+		Name:    "segment_reviews",
+		Up: `
+-- Per-segment review decisions and segmented execution of compound commands.
+ALTER TABLE reviews ADD COLUMN segments_json TEXT;
+ALTER TABLE requests ADD COLUMN approved_segments_json TEXT;
+ALTER TABLE requests ADD COLUMN execution_segments_json TEXT;
+`,
+	},
+	{
+		Version: 9,
+		Name:    "request_labels",
+		Up: `
+-- Arbitrary key/value labels for filtering and notification routing.
+ALTER TABLE requests ADD COLUMN labels_json TEXT;
+`,
+	},
+	{
+		Version: 10,
+		Name:    "request_migrations",
+		Up: `
+-- Migration files bound to migration-runner commands.
+ALTER TABLE requests ADD COLUMN migrations_json TEXT;
+`,
+	},
+	{
+		Version: 11,
+		Name:    "request_queue",
+		Up: `
+-- Requests held by the rate limiter (action "queue"), admitted in seq order.
+CREATE TABLE IF NOT EXISTS request_queue (
+  seq INTEGER PRIMARY KEY AUTOINCREMENT,
+  request_id TEXT NOT NULL UNIQUE REFERENCES requests(id) ON DELETE CASCADE,
+  session_id TEXT NOT NULL,
+  queued_at TEXT NOT NULL,
+  admitted_at TEXT,
+  cancelled_at TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_request_queue_session ON request_queue(session_id, seq);
+`,
+	},
+	{
+		Version: 12,
+		Name:    "session_hosts",
+		Up: `
+-- Host identity on sessions, for tiers that require reviewers on another host.
+ALTER TABLE sessions ADD COLUMN hostname TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN machine_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE requests ADD COLUMN require_different_host INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		Version: 13,
+		Name:    "review_callbacks",
+		Up: `
+-- Inbound approval callbacks already turned into reviews, for replay protection.
+CREATE TABLE IF NOT EXISTS review_callbacks (
+  callback_id TEXT PRIMARY KEY,
+  request_id TEXT NOT NULL REFERENCES requests(id) ON DELETE CASCADE,
+  review_id TEXT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+  processed_at TEXT NOT NULL
+);
+`,
+	},
+	{
+		Version: 14,
+		Name:    "request_timeouts",
+		Up: `
+-- Requestor wait timeout after per-tier bounds, and the value asked for.
+ALTER TABLE requests ADD COLUMN timeout_secs INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE requests ADD COLUMN timeout_requested_secs INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		Version: 15,
+		Name:    "trusted_scripts",
+		Up: `
+-- Scripts a reviewer vetted, pinned by content hash, and the requests whose
+-- tier they lowered.
+CREATE TABLE IF NOT EXISTS trusted_scripts (
+  id TEXT PRIMARY KEY,
+  project_path TEXT NOT NULL,
+  path TEXT NOT NULL,
+  sha256 TEXT NOT NULL,
+  session_id TEXT NOT NULL,
+  agent_name TEXT NOT NULL,
+  model TEXT,
+  signature TEXT NOT NULL,
+  comment TEXT,
+  created_at TEXT NOT NULL,
+  revoked_at TEXT,
+  revoked_by TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_trusted_scripts_project
+  ON trusted_scripts(project_path, path);
+CREATE TABLE IF NOT EXISTS trusted_script_uses (
+  request_id TEXT PRIMARY KEY REFERENCES requests(id) ON DELETE CASCADE,
+  trust_id TEXT NOT NULL REFERENCES trusted_scripts(id),
+  sha256 TEXT NOT NULL,
+  from_tier TEXT NOT NULL,
+  to_tier TEXT NOT NULL
+);
+`,
+	},
+	{
+		Version: 16,
+		Name:    "undelivered_notifications",
+		Up: `
+-- Outbound integration payloads awaiting delivery, kept until the receiver
+-- accepts them so retries survive a daemon restart.
+CREATE TABLE IF NOT EXISTS undelivered_notifications (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  kind TEXT NOT NULL,
+  request_id TEXT NOT NULL,
+  url TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  next_attempt_at TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  delivered_at TEXT,
+  UNIQUE(kind, request_id)
+);
+CREATE INDEX IF NOT EXISTS idx_undelivered_notifications_due
+  ON undelivered_notifications(delivered_at, next_attempt_at);
+`,
+	},
+	{
+		Version: 17,
+		Name:    "rollback_pending",
+		Up: `
+-- Set while execution waits for a rollback capture slot.
+ALTER TABLE requests ADD COLUMN rollback_pending INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		Version: 18,
+		Name:    "review_rounds",
+		Up: `
+-- A request whose approval expired can go back to pending for a new review
+-- round. Reviews record their round so earlier ones stay on record without
+-- counting, and a reviewer may review once per round.
+ALTER TABLE requests ADD COLUMN review_round INTEGER NOT NULL DEFAULT 0;
+-- reviews is rebuilt with review_round (see rebuildReviewsWithRounds).
+`,
+	},
+	{
+		Version: 19,
+		Name:    "execution_slots",
+		Up: `
+-- Per-project execution slots. A row with acquired_at set holds a slot; one
+-- without is waiting in the project's execution queue. Rows are deleted when
+-- the executor finishes or its claim is recovered as stale.
+CREATE TABLE IF NOT EXISTS execution_claims (
+  request_id TEXT PRIMARY KEY REFERENCES requests(id) ON DELETE CASCADE,
+  project_path TEXT NOT NULL,
+  hostname TEXT NOT NULL DEFAULT '',
+  pid INTEGER NOT NULL DEFAULT 0,
+  approved_at TEXT NOT NULL,
+  queued_at TEXT NOT NULL,
+  acquired_at TEXT,
+  lease_expires_at TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_execution_claims_project
+  ON execution_claims(project_path);
+-- requests.execution_priority is added here too; reviewers raise it to move
+-- a request ahead in the execution queue.
+`,
+	},
+	{
+		Version: 20,
+		Name:    "rollback_events",
+		Up: `
+-- Rollback captures and restore attempts, kept for rollback usage stats.
+-- Rows outlive their request so the history is not lost when requests are
+-- pruned.
+CREATE TABLE IF NOT EXISTS rollback_events (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  request_id TEXT NOT NULL,
+  project_path TEXT NOT NULL,
+  event TEXT NOT NULL,
+  kind TEXT NOT NULL DEFAULT '',
+  succeeded INTEGER NOT NULL DEFAULT 0,
+  failure_category TEXT NOT NULL DEFAULT '',
+  error TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_rollback_events_project
+  ON rollback_events(project_path, created_at);
+`,
+	},
+	{
+		Version: 21,
+		Name:    "notification_urls",
+		Up: `
+-- undelivered_notifications is rebuilt with url in its unique key, so a
+-- request's lifecycle webhook can be queued once per configured URL.
+CREATE TABLE undelivered_notifications_new (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  kind TEXT NOT NULL,
+  request_id TEXT NOT NULL,
+  url TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  next_attempt_at TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  delivered_at TEXT,
+  UNIQUE(kind, request_id, url)
+);
+INSERT INTO undelivered_notifications_new SELECT
+  id, kind, request_id, url, payload, attempts, last_error,
+  next_attempt_at, created_at, delivered_at
+FROM undelivered_notifications;
+DROP TABLE undelivered_notifications;
+ALTER TABLE undelivered_notifications_new RENAME TO undelivered_notifications;
+CREATE INDEX IF NOT EXISTS idx_undelivered_notifications_due
+  ON undelivered_notifications(delivered_at, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_undelivered_notifications_request
+  ON undelivered_notifications(request_id);
+`,
+	},
+	{
+		Version: 22,
+		Name:    "campaigns",
+		Up: `
+-- A campaign groups related requests, such as the steps of one migration.
+CREATE TABLE IF NOT EXISTS campaigns (
+  id TEXT PRIMARY KEY,
+  project_path TEXT NOT NULL,
+  name TEXT NOT NULL,
+  description TEXT,
+  created_by_session_id TEXT,
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_campaigns_project ON campaigns(project_path, created_at);
+-- requests.campaign_id is added here too, with its index.
+`,
+	},
+	{
+		Version: 23,
+		Name:    "requestor_program",
+		Up: `
+-- The requestor's program, for tiers that require a reviewer running a
+-- different program. Existing requests take it from their session.
+ALTER TABLE requests ADD COLUMN requestor_program TEXT NOT NULL DEFAULT '';
+ALTER TABLE requests ADD COLUMN require_different_program INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		Version: 24,
+		Name:    "required_roles",
+		Up: `
+-- Reviewer roles that must each contribute an approval, as a JSON array.
+ALTER TABLE requests ADD COLUMN required_roles_json TEXT;
+`,
+	},
+	{
+		Version: 25,
+		Name:    "command_hash_audit",
+		Up: `
+-- Security-relevant events, such as a command that no longer matches the
+-- hash its reviewers approved. Rows outlive their request.
+CREATE TABLE IF NOT EXISTS audit_events (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  request_id TEXT NOT NULL,
+  project_path TEXT NOT NULL,
+  event TEXT NOT NULL,
+  detail TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_request
+  ON audit_events(request_id, created_at);
+-- reviews.command_hash is added here too: the command hash each review
+-- signed over.
+`,
+	},
+	{
+		Version: 26,
+		Name:    "delegations",
+		Up: `
+-- Time-boxed standing approvals: a reviewer's signed statement that requests
+-- matching a command pattern, up to a risk tier, may be approved on their
+-- behalf until expires_at.
+CREATE TABLE IF NOT EXISTS delegations (
+  id TEXT PRIMARY KEY,
+  project_path TEXT NOT NULL,
+  pattern TEXT NOT NULL,
+  max_tier TEXT NOT NULL,
+  session_id TEXT NOT NULL,
+  agent_name TEXT NOT NULL,
+  model TEXT,
+  signature TEXT NOT NULL,
+  comment TEXT,
+  created_at TEXT NOT NULL,
+  expires_at TEXT NOT NULL,
+  revoked_at TEXT,
+  revoked_by TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_delegations_project
+  ON delegations(project_path, expires_at);
+-- reviews.delegation_id is added here too: the delegation a review was
+-- recorded under, empty for reviews given by hand.
+`,
+	},
+	{
+		Version: 27,
+		Name:    "cancel_reason",
+		Up: `
+-- Why a request was cancelled and by which agent.
+ALTER TABLE requests ADD COLUMN cancel_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE requests ADD COLUMN cancelled_by TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		Version: 28,
+		Name:    "execution_streams",
+		Up: `
+-- An execution's stdout and stderr, each truncated on its own, and whether
+-- each was cut short.
+ALTER TABLE requests ADD COLUMN execution_stdout TEXT NOT NULL DEFAULT '';
+ALTER TABLE requests ADD COLUMN execution_stderr TEXT NOT NULL DEFAULT '';
+ALTER TABLE requests ADD COLUMN execution_stdout_truncated INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE requests ADD COLUMN execution_stderr_truncated INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		Version: 29,
+		Name:    "requests_project_created_index",
+		Up: `
+-- Time-bounded history of one project (ListRequestsBetween) seeks and
+-- orders by this index instead of scanning the project's requests.
+CREATE INDEX IF NOT EXISTS idx_requests_project_created ON requests(project_path, created_at);
+`,
+	},
+	{
+		Version: 30,
+		Name:    "sla_notified",
+		Up: `
+-- When the daemon announced that a pending request breached its tier's SLA,
+-- so a restarted daemon does not announce it again.
+ALTER TABLE requests ADD COLUMN sla_notified_at TEXT;
 `,
 	},
 }
 
+// rebuildReviewsWithRounds recreates reviews with review_round in its unique
+// key, so a reviewer may review once per round. Dropping the old table
+// cascades to review_callbacks, so those rows are carried across.
+const rebuildReviewsWithRounds = `
+CREATE TEMP TABLE review_callbacks_backup AS SELECT * FROM review_callbacks;
+CREATE TABLE reviews_new (
+  id TEXT PRIMARY KEY,
+  request_id TEXT NOT NULL REFERENCES requests(id) ON DELETE CASCADE,
+  reviewer_session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+  reviewer_agent TEXT NOT NULL,
+  reviewer_model TEXT NOT NULL,
+  decision TEXT NOT NULL,
+  signature TEXT NOT NULL,
+  signature_timestamp TEXT NOT NULL,
+  responses_json TEXT,
+  comments TEXT,
+  created_at TEXT NOT NULL,
+  segments_json TEXT,
+  review_round INTEGER NOT NULL DEFAULT 0,
+  UNIQUE(request_id, reviewer_session_id, review_round)
+);
+INSERT INTO reviews_new (
+  id, request_id, reviewer_session_id, reviewer_agent, reviewer_model, decision,
+  signature, signature_timestamp, responses_json, comments, created_at, segments_json
+)
+SELECT
+  id, request_id, reviewer_session_id, reviewer_agent, reviewer_model, decision,
+  signature, signature_timestamp, responses_json, comments, created_at, segments_json
+FROM reviews;
+DROP TABLE reviews;
+ALTER TABLE reviews_new RENAME TO reviews;
+INSERT OR IGNORE INTO review_callbacks SELECT * FROM review_callbacks_backup;
+DROP TABLE review_callbacks_backup;
+`
+
 // ApplyMigrations applies any pending migrations in order.
 func (db *DB) ApplyMigrations(ctx context.Context) error {
 	db.mu.Lock()
@@ -238,6 +671,177 @@ func (db *DB) ApplyMigrations(ctx context.Context) error {
 					return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
 				}
 			}
+		case 4:
+			for _, col := range []string{"pinned_context_json", "execution_context_pinning"} {
+				if err := addColumnIfMissing(ctx, tx, "requests", col, "TEXT"); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+				}
+			}
+		case 5:
+			if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+			if err := backfillRequestTokens(ctx, tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 6:
+			for _, col := range []string{"command_normalized_json", "command_summary", "tier_reason"} {
+				if err := addColumnIfMissing(ctx, tx, "requests", col, "TEXT"); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+				}
+			}
+		case 8:
+			cols := []struct{ table, name string }{
+				{"reviews", "segments_json"},
+				{"requests", "approved_segments_json"},
+				{"requests", "execution_segments_json"},
+			}
+			for _, col := range cols {
+				if err := addColumnIfMissing(ctx, tx, col.table, col.name, "TEXT"); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+				}
+			}
+		case 9:
+			if err := addColumnIfMissing(ctx, tx, "requests", "labels_json", "TEXT"); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 10:
+			if err := addColumnIfMissing(ctx, tx, "requests", "migrations_json", "TEXT"); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 12:
+			cols := []struct{ table, name, def string }{
+				{"sessions", "hostname", "TEXT NOT NULL DEFAULT ''"},
+				{"sessions", "machine_id", "TEXT NOT NULL DEFAULT ''"},
+				{"requests", "require_different_host", "INTEGER NOT NULL DEFAULT 0"},
+			}
+			for _, col := range cols {
+				if err := addColumnIfMissing(ctx, tx, col.table, col.name, col.def); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+				}
+			}
+		case 14:
+			for _, col := range []string{"timeout_secs", "timeout_requested_secs"} {
+				if err := addColumnIfMissing(ctx, tx, "requests", col, "INTEGER NOT NULL DEFAULT 0"); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+				}
+			}
+		case 17:
+			if err := addColumnIfMissing(ctx, tx, "requests", "rollback_pending", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 18:
+			if err := addColumnIfMissing(ctx, tx, "requests", "review_round", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+			rounds, err := hasColumn(ctx, tx, "reviews", "review_round")
+			if err == nil && !rounds {
+				_, err = tx.ExecContext(ctx, rebuildReviewsWithRounds)
+			}
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 19:
+			if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+			if err := addColumnIfMissing(ctx, tx, "requests", "execution_priority", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 22:
+			if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+			if err := addColumnIfMissing(ctx, tx, "requests", "campaign_id", "TEXT REFERENCES campaigns(id)"); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+			if _, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_requests_campaign ON requests(campaign_id)`); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 23:
+			cols := []struct{ name, def string }{
+				{"requestor_program", "TEXT NOT NULL DEFAULT ''"},
+				{"require_different_program", "INTEGER NOT NULL DEFAULT 0"},
+			}
+			for _, col := range cols {
+				if err := addColumnIfMissing(ctx, tx, "requests", col.name, col.def); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+				}
+			}
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE requests SET requestor_program = COALESCE(
+					(SELECT program FROM sessions WHERE sessions.id = requests.requestor_session_id), '')
+				WHERE requestor_program = ''
+			`); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 24:
+			if err := addColumnIfMissing(ctx, tx, "requests", "required_roles_json", "TEXT"); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 25:
+			if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+			if err := addColumnIfMissing(ctx, tx, "reviews", "command_hash", "TEXT NOT NULL DEFAULT ''"); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 26:
+			if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+			if err := addColumnIfMissing(ctx, tx, "reviews", "delegation_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		case 27:
+			for _, col := range []string{"cancel_reason", "cancelled_by"} {
+				if err := addColumnIfMissing(ctx, tx, "requests", col, "TEXT NOT NULL DEFAULT ''"); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+				}
+			}
+		case 28:
+			cols := []struct{ name, def string }{
+				{"execution_stdout", "TEXT NOT NULL DEFAULT ''"},
+				{"execution_stderr", "TEXT NOT NULL DEFAULT ''"},
+				{"execution_stdout_truncated", "INTEGER NOT NULL DEFAULT 0"},
+				{"execution_stderr_truncated", "INTEGER NOT NULL DEFAULT 0"},
+			}
+			for _, col := range cols {
+				if err := addColumnIfMissing(ctx, tx, "requests", col.name, col.def); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+				}
+			}
+		case 30:
+			if err := addColumnIfMissing(ctx, tx, "requests", "sla_notified_at", "TEXT"); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
 		default:
 			if _, err := tx.ExecContext(ctx, m.Up); err != nil {
 				tx.Rollback()
@@ -280,9 +884,23 @@ func currentVersion(conn *sql.DB) (int, error) {
 }
 
 func addColumnIfMissing(ctx context.Context, tx *sql.Tx, table, column, colType string) error {
+	exists, err := hasColumn(ctx, tx, table, column)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, colType))
+	if err != nil {
+		return fmt.Errorf("add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// hasColumn reports whether table has column.
+func hasColumn(ctx context.Context, tx *sql.Tx, table, column string) (bool, error) {
 	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`PRAGMA table_info(%s)`, table))
 	if err != nil {
-		return fmt.Errorf("pragma table_info: %w", err)
+		return false, fmt.Errorf("pragma table_info: %w", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
@@ -292,19 +910,14 @@ func addColumnIfMissing(ctx context.Context, tx *sql.Tx, table, column, colType
 		var dfltValue sql.NullString
 		var pk int
 		if err := rows.Scan(&cid, &colName, &ctype, &notnull, &dfltValue, &pk); err != nil {
-			return fmt.Errorf("scan pragma table_info: %w", err)
+			return false, fmt.Errorf("scan pragma table_info: %w", err)
 		}
 		if colName == column {
-			return nil // already exists
+			return true, nil
 		}
 	}
 	if rows.Err() != nil {
-		return fmt.Errorf("iterating table_info: %w", rows.Err())
-	}
-
-	_, err = tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, colType))
-	if err != nil {
-		return fmt.Errorf("add column %s.%s: %w", table, column, err)
+		return false, fmt.Errorf("iterating table_info: %w", rows.Err())
 	}
-	return nil
+	return false, nil
 }
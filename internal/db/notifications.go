@@ -0,0 +1,172 @@
+// Package db provides the store of undelivered integration notifications.
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// NotificationKindChangeRecord marks a change record for an executed request.
+const NotificationKindChangeRecord = "change_record"
+
+// notificationKindWebhookPrefix starts the kind of a lifecycle webhook.
+const notificationKindWebhookPrefix = "webhook:"
+
+// WebhookNotificationKind returns the kind of a lifecycle webhook for event,
+// such as "webhook:request_approved".
+func WebhookNotificationKind(event string) string {
+	return notificationKindWebhookPrefix + event
+}
+
+// UndeliveredNotification is an outbound integration payload kept until the
+// receiver accepts it. There is at most one per kind, request and URL.
+type UndeliveredNotification struct {
+	ID        int64  `json:"id"`
+	Kind      string `json:"kind"`
+	RequestID string `json:"request_id"`
+	URL       string `json:"url"`
+	// Payload is the JSON body to deliver.
+	Payload       string     `json:"payload"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+// EnqueueNotification stores a notification for delivery. It reports false
+// without changing anything if one of the same kind and URL already exists
+// for the request, delivered or not.
+func (db *DB) EnqueueNotification(n *UndeliveredNotification) (bool, error) {
+	if n.Kind == "" || n.RequestID == "" || n.URL == "" {
+		return false, fmt.Errorf("kind, request_id and url are required")
+	}
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now().UTC()
+	}
+	if n.NextAttemptAt.IsZero() {
+		n.NextAttemptAt = n.CreatedAt
+	}
+	res, err := db.Exec(`
+		INSERT OR IGNORE INTO undelivered_notifications (
+			kind, request_id, url, payload, next_attempt_at, created_at
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`, n.Kind, n.RequestID, n.URL, n.Payload,
+		n.NextAttemptAt.UTC().Format(time.RFC3339), n.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return false, fmt.Errorf("enqueueing notification: %w", err)
+	}
+	if count, _ := res.RowsAffected(); count == 0 {
+		return false, nil
+	}
+	n.ID, _ = res.LastInsertId()
+	return true, nil
+}
+
+const notificationColumns = `id, kind, request_id, url, payload, attempts,
+	last_error, next_attempt_at, created_at, delivered_at`
+
+func scanNotification(row interface{ Scan(...any) error }) (*UndeliveredNotification, error) {
+	n := &UndeliveredNotification{}
+	var lastError, deliveredAt sql.NullString
+	var nextAttempt, created string
+	if err := row.Scan(&n.ID, &n.Kind, &n.RequestID, &n.URL, &n.Payload, &n.Attempts,
+		&lastError, &nextAttempt, &created, &deliveredAt); err != nil {
+		return nil, err
+	}
+	n.LastError = lastError.String
+	n.NextAttemptAt, _ = time.Parse(time.RFC3339, nextAttempt)
+	n.CreatedAt, _ = time.Parse(time.RFC3339, created)
+	n.DeliveredAt = parseTimePtr(deliveredAt)
+	return n, nil
+}
+
+// ListDueNotifications returns undelivered notifications of a kind whose next
+// attempt is at or before now, oldest first. A limit of 0 returns them all.
+func (db *DB) ListDueNotifications(kind string, now time.Time, limit int) ([]*UndeliveredNotification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM undelivered_notifications
+		WHERE kind = ? AND delivered_at IS NULL AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id`
+	args := []any{kind, now.UTC().Format(time.RFC3339)}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing due notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*UndeliveredNotification
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning notifications: %w", err)
+		}
+		list = append(list, n)
+	}
+	return list, rows.Err()
+}
+
+// GetNotification returns the notification of a kind for a request, or nil
+// if none was enqueued.
+func (db *DB) GetNotification(kind, requestID string) (*UndeliveredNotification, error) {
+	n, err := scanNotification(db.QueryRow(`SELECT `+notificationColumns+`
+		FROM undelivered_notifications WHERE kind = ? AND request_id = ?`, kind, requestID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting notification: %w", err)
+	}
+	return n, nil
+}
+
+// ListNotificationsForRequest returns every notification enqueued for a
+// request, delivered or not, oldest first.
+func (db *DB) ListNotificationsForRequest(requestID string) ([]*UndeliveredNotification, error) {
+	rows, err := db.Query(`SELECT `+notificationColumns+` FROM undelivered_notifications
+		WHERE request_id = ? ORDER BY created_at, id`, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("listing request notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*UndeliveredNotification
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning notifications: %w", err)
+		}
+		list = append(list, n)
+	}
+	return list, rows.Err()
+}
+
+// MarkNotificationDelivered records that the receiver accepted a notification.
+func (db *DB) MarkNotificationDelivered(id int64, at time.Time) error {
+	_, err := db.Exec(`
+		UPDATE undelivered_notifications
+		SET delivered_at = ?, attempts = attempts + 1, last_error = NULL
+		WHERE id = ?
+	`, at.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("marking notification delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkNotificationFailed records a failed delivery attempt and when to retry.
+func (db *DB) MarkNotificationFailed(id int64, deliveryErr string, next time.Time) error {
+	_, err := db.Exec(`
+		UPDATE undelivered_notifications
+		SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?
+		WHERE id = ?
+	`, deliveryErr, next.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("marking notification failed: %w", err)
+	}
+	return nil
+}
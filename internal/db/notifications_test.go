@@ -0,0 +1,139 @@
+// Package db tests for the undelivered notifications store.
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUndeliveredNotifications_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	n := &UndeliveredNotification{
+		Kind:      NotificationKindChangeRecord,
+		RequestID: "req-1",
+		URL:       "https://cmdb.example.com/hook",
+		Payload:   `{"event":"request_executed"}`,
+		CreatedAt: now,
+	}
+	created, err := db.EnqueueNotification(n)
+	if err != nil || !created || n.ID == 0 {
+		t.Fatalf("EnqueueNotification = %v, %v (id %d)", created, err, n.ID)
+	}
+	dup := *n
+	dup.ID = 0
+	if created, err := db.EnqueueNotification(&dup); err != nil || created {
+		t.Fatalf("expected a second record for the request to be ignored, got %v, %v", created, err)
+	}
+
+	due, err := db.ListDueNotifications(NotificationKindChangeRecord, now, 0)
+	if err != nil || len(due) != 1 || due[0].Payload != n.Payload {
+		t.Fatalf("expected the record to be due, got %+v, %v", due, err)
+	}
+
+	retry := now.Add(time.Minute)
+	if err := db.MarkNotificationFailed(n.ID, "status 503", retry); err != nil {
+		t.Fatalf("MarkNotificationFailed: %v", err)
+	}
+	if due, _ := db.ListDueNotifications(NotificationKindChangeRecord, now, 0); len(due) != 0 {
+		t.Fatalf("expected nothing due before the retry time, got %+v", due)
+	}
+	due, _ = db.ListDueNotifications(NotificationKindChangeRecord, retry, 0)
+	if len(due) != 1 || due[0].Attempts != 1 || due[0].LastError != "status 503" {
+		t.Fatalf("expected the failed attempt to be recorded, got %+v", due)
+	}
+
+	if err := db.MarkNotificationDelivered(n.ID, retry); err != nil {
+		t.Fatalf("MarkNotificationDelivered: %v", err)
+	}
+	if due, _ := db.ListDueNotifications(NotificationKindChangeRecord, retry.Add(time.Hour), 0); len(due) != 0 {
+		t.Fatalf("expected a delivered record not to be due, got %+v", due)
+	}
+	got, err := db.GetNotification(NotificationKindChangeRecord, "req-1")
+	if err != nil || got == nil || got.DeliveredAt == nil || got.Attempts != 2 || got.LastError != "" {
+		t.Fatalf("unexpected delivered record %+v, %v", got, err)
+	}
+	if missing, err := db.GetNotification(NotificationKindChangeRecord, "req-2"); err != nil || missing != nil {
+		t.Errorf("expected no record for req-2, got %+v, %v", missing, err)
+	}
+}
+
+func TestListExecutedRequestsSince(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, req := createTestRequest(t, db)
+	since := time.Now().UTC().Add(-time.Minute)
+	if list, err := db.ListExecutedRequestsSince(req.ProjectPath, since); err != nil || len(list) != 0 {
+		t.Fatalf("expected no executed requests, got %d, %v", len(list), err)
+	}
+
+	for _, s := range []RequestStatus{StatusApproved, StatusExecuting, StatusExecuted} {
+		if err := db.UpdateRequestStatus(req.ID, s); err != nil {
+			t.Fatalf("UpdateRequestStatus(%s): %v", s, err)
+		}
+	}
+	executedAt := time.Now().UTC()
+	if err := db.UpdateRequestExecution(req.ID, &Execution{ExecutedAt: &executedAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := db.ListExecutedRequestsSince(req.ProjectPath, since)
+	if err != nil || len(list) != 1 || list[0].ID != req.ID {
+		t.Fatalf("expected the executed request, got %d, %v", len(list), err)
+	}
+	if list, _ := db.ListExecutedRequestsSince(req.ProjectPath, executedAt.Add(time.Hour)); len(list) != 0 {
+		t.Errorf("expected a later since to exclude the request, got %d", len(list))
+	}
+}
+
+func TestNotifications_PerURL(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	kind := WebhookNotificationKind("request_pending")
+	if kind != "webhook:request_pending" {
+		t.Fatalf("WebhookNotificationKind = %q", kind)
+	}
+	for _, url := range []string{"https://a.example.com/hook", "https://b.example.com/hook"} {
+		n := &UndeliveredNotification{Kind: kind, RequestID: "req-1", URL: url, Payload: "{}", CreatedAt: now}
+		if created, err := db.EnqueueNotification(n); err != nil || !created {
+			t.Fatalf("EnqueueNotification(%s) = %v, %v", url, created, err)
+		}
+	}
+	other := &UndeliveredNotification{Kind: kind, RequestID: "req-2", URL: "https://a.example.com/hook", Payload: "{}", CreatedAt: now}
+	if _, err := db.EnqueueNotification(other); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := db.ListNotificationsForRequest("req-1")
+	if err != nil || len(list) != 2 {
+		t.Fatalf("expected one notification per URL, got %d, %v", len(list), err)
+	}
+	if list[0].URL != "https://a.example.com/hook" || list[1].URL != "https://b.example.com/hook" {
+		t.Errorf("unexpected order %s, %s", list[0].URL, list[1].URL)
+	}
+	if list, err := db.ListNotificationsForRequest("req-3"); err != nil || len(list) != 0 {
+		t.Errorf("expected no notifications for req-3, got %d, %v", len(list), err)
+	}
+}
+
+func TestListRequestsCreatedSince(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, req := createTestRequest(t, db)
+	list, err := db.ListRequestsCreatedSince(req.ProjectPath, req.CreatedAt.Add(-time.Minute))
+	if err != nil || len(list) != 1 || list[0].ID != req.ID {
+		t.Fatalf("expected the new request, got %d, %v", len(list), err)
+	}
+	if list, _ := db.ListRequestsCreatedSince(req.ProjectPath, req.CreatedAt.Add(time.Hour)); len(list) != 0 {
+		t.Errorf("expected a later since to exclude the request, got %d", len(list))
+	}
+	if list, _ := db.ListRequestsCreatedSince("/elsewhere", req.CreatedAt.Add(-time.Minute)); len(list) != 0 {
+		t.Errorf("expected another project to be excluded, got %d", len(list))
+	}
+}
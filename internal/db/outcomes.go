@@ -2,8 +2,10 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -350,6 +352,61 @@ func (db *DB) GetTimeToApprovalStats() (*TimeToApprovalStats, error) {
 	return stats, nil
 }
 
+// EvidenceViewStats summarizes the evidence reviewers opened before deciding.
+type EvidenceViewStats struct {
+	ReviewCount       int            `json:"review_count"`
+	ReviewsWithViews  int            `json:"reviews_with_views"`
+	ViewsBySection    map[string]int `json:"views_by_section"`
+	AvgViewDurationMs float64        `json:"avg_view_duration_ms"`
+}
+
+// GetEvidenceViewStats aggregates the evidence_viewed field of all review responses.
+// Attachment sections are grouped under "attachment" regardless of index.
+func (db *DB) GetEvidenceViewStats() (*EvidenceViewStats, error) {
+	stats := &EvidenceViewStats{ViewsBySection: map[string]int{}}
+
+	rows, err := db.Query(`SELECT responses_json FROM reviews`)
+	if err != nil {
+		return nil, fmt.Errorf("querying review responses: %w", err)
+	}
+	defer rows.Close()
+
+	var views int
+	var totalMs int64
+	for rows.Next() {
+		var raw sql.NullString
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("scanning review responses: %w", err)
+		}
+		stats.ReviewCount++
+		if !raw.Valid || raw.String == "" {
+			continue
+		}
+		var resp ReviewResponse
+		if err := json.Unmarshal([]byte(raw.String), &resp); err != nil {
+			continue
+		}
+		if len(resp.EvidenceViewed) == 0 {
+			continue
+		}
+		stats.ReviewsWithViews++
+		for _, v := range resp.EvidenceViewed {
+			section, _, _ := strings.Cut(v.Section, ":")
+			stats.ViewsBySection[section]++
+			totalMs += v.DurationMs
+			views++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if views > 0 {
+		stats.AvgViewDurationMs = float64(totalMs) / float64(views)
+	}
+	return stats, nil
+}
+
 func scanOutcomeRow(row *sql.Row) (*ExecutionOutcome, error) {
 	o := &ExecutionOutcome{}
 	var result, notes, problemDesc, humanNotes sql.NullString
@@ -389,7 +389,7 @@ func TestGetTimeToApprovalStats(t *testing.T) {
 		db.CreateSession(reviewerSess)
 
 		now := time.Now().UTC()
-		signature := ComputeReviewSignature(reviewerSess.SessionKey, req.ID, DecisionApprove, now)
+		signature := ComputeReviewSignature(reviewerSess.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now)
 
 		review := &Review{
 			RequestID:          req.ID,
@@ -537,7 +537,7 @@ func TestGetTimeToApprovalStats_EvenMedian(t *testing.T) {
 	if err := db.CreateSession(reviewer1); err != nil {
 		t.Fatalf("CreateSession reviewer1 failed: %v", err)
 	}
-	sig1 := ComputeReviewSignature(reviewer1.SessionKey, req1.ID, DecisionApprove, base)
+	sig1 := ComputeReviewSignature(reviewer1.SessionKey, req1.ID, req1.Command.Hash, DecisionApprove, base)
 	if err := db.CreateReview(&Review{
 		RequestID:          req1.ID,
 		ReviewerSessionID:  reviewer1.ID,
@@ -558,7 +558,7 @@ func TestGetTimeToApprovalStats_EvenMedian(t *testing.T) {
 	if err := db.CreateSession(reviewer2); err != nil {
 		t.Fatalf("CreateSession reviewer2 failed: %v", err)
 	}
-	sig2 := ComputeReviewSignature(reviewer2.SessionKey, req2.ID, DecisionApprove, base)
+	sig2 := ComputeReviewSignature(reviewer2.SessionKey, req2.ID, req2.Command.Hash, DecisionApprove, base)
 	if err := db.CreateReview(&Review{
 		RequestID:          req2.ID,
 		ReviewerSessionID:  reviewer2.ID,
@@ -604,3 +604,57 @@ func TestGetTimeToApprovalStats_EvenMedian(t *testing.T) {
 		t.Fatalf("expected min<=median<=max, got min=%.3f median=%.3f max=%.3f", stats.MinMinutes, stats.MedianMinutes, stats.MaxMinutes)
 	}
 }
+
+func TestGetEvidenceViewStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	responses := []ReviewResponse{
+		{},
+		{EvidenceViewed: []EvidenceView{
+			{Section: "dry_run", DurationMs: 1000},
+			{Section: "attachment:0", DurationMs: 3000},
+		}},
+		{EvidenceViewed: []EvidenceView{
+			{Section: "attachment:2", DurationMs: 2000},
+		}},
+	}
+	for i, resp := range responses {
+		_, req := createTestRequest(t, db)
+		reviewer := &Session{AgentName: "EvidenceReviewer" + string(rune('A'+i)), Program: "codex-cli", Model: "gpt-5", ProjectPath: "/test/project"}
+		if err := db.CreateSession(reviewer); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		now := time.Now().UTC()
+		if err := db.CreateReview(&Review{
+			RequestID:          req.ID,
+			ReviewerSessionID:  reviewer.ID,
+			ReviewerAgent:      reviewer.AgentName,
+			ReviewerModel:      reviewer.Model,
+			Decision:           DecisionApprove,
+			CommandHash:        req.Command.Hash,
+			Signature:          ComputeReviewSignature(reviewer.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now),
+			SignatureTimestamp: now,
+			Responses:          resp,
+		}); err != nil {
+			t.Fatalf("CreateReview failed: %v", err)
+		}
+	}
+
+	stats, err := db.GetEvidenceViewStats()
+	if err != nil {
+		t.Fatalf("GetEvidenceViewStats failed: %v", err)
+	}
+	if stats.ReviewCount != 3 {
+		t.Errorf("Expected 3 reviews, got %d", stats.ReviewCount)
+	}
+	if stats.ReviewsWithViews != 2 {
+		t.Errorf("Expected 2 reviews with views, got %d", stats.ReviewsWithViews)
+	}
+	if stats.ViewsBySection["dry_run"] != 1 || stats.ViewsBySection["attachment"] != 2 {
+		t.Errorf("Unexpected views by section: %v", stats.ViewsBySection)
+	}
+	if stats.AvgViewDurationMs != 2000 {
+		t.Errorf("Expected avg duration 2000ms, got %.1f", stats.AvgViewDurationMs)
+	}
+}
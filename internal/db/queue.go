@@ -0,0 +1,252 @@
+// Package db provides persistent storage for the rate limiter's request queue.
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// QueueEntry is a request's place in its session's rate-limit queue. Rows
+// outlive admission and cancellation so the transition stays on record.
+type QueueEntry struct {
+	// Seq orders the queue; lower values are admitted first.
+	Seq         int64      `json:"seq"`
+	RequestID   string     `json:"request_id"`
+	SessionID   string     `json:"session_id"`
+	QueuedAt    time.Time  `json:"queued_at"`
+	AdmittedAt  *time.Time `json:"admitted_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+}
+
+// enqueueRequestTx appends a newly created queued request to its session's queue.
+func enqueueRequestTx(tx *sql.Tx, requestID, sessionID string, queuedAt time.Time) error {
+	if _, err := tx.Exec(`
+		INSERT INTO request_queue (request_id, session_id, queued_at) VALUES (?, ?, ?)
+	`, requestID, sessionID, queuedAt.UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("enqueueing request: %w", err)
+	}
+	return nil
+}
+
+// dequeueRequestTx records a queued request leaving the queue: admitted when
+// it moves to pending, cancelled otherwise.
+func dequeueRequestTx(tx *sql.Tx, requestID string, status RequestStatus, at string) error {
+	column := "cancelled_at"
+	if status == StatusPending {
+		column = "admitted_at"
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`
+		UPDATE request_queue SET %s = ? WHERE request_id = ?
+	`, column), at, requestID); err != nil {
+		return fmt.Errorf("dequeueing request: %w", err)
+	}
+	return nil
+}
+
+// ListQueue returns the requests still waiting in a session's queue, head first.
+func (db *DB) ListQueue(sessionID string) ([]*QueueEntry, error) {
+	rows, err := db.Query(`
+		SELECT q.seq, q.request_id, q.session_id, q.queued_at, q.admitted_at, q.cancelled_at
+		FROM request_queue q
+		JOIN requests r ON r.id = q.request_id
+		WHERE q.session_id = ? AND r.status = ?
+		ORDER BY q.seq
+	`, sessionID, string(StatusQueued))
+	if err != nil {
+		return nil, fmt.Errorf("querying request queue: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*QueueEntry
+	for rows.Next() {
+		e := &QueueEntry{}
+		var queuedAt string
+		var admittedAt, cancelledAt sql.NullString
+		if err := rows.Scan(&e.Seq, &e.RequestID, &e.SessionID, &queuedAt, &admittedAt, &cancelledAt); err != nil {
+			return nil, fmt.Errorf("scanning queue entry: %w", err)
+		}
+		e.QueuedAt, _ = time.Parse(time.RFC3339, queuedAt)
+		e.AdmittedAt = parseTimePtr(admittedAt)
+		e.CancelledAt = parseTimePtr(cancelledAt)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating queue entries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetQueueEntry returns the queue record for a request, or nil if the request
+// was never queued.
+func (db *DB) GetQueueEntry(requestID string) (*QueueEntry, error) {
+	e := &QueueEntry{}
+	var queuedAt string
+	var admittedAt, cancelledAt sql.NullString
+	err := db.QueryRow(`
+		SELECT seq, request_id, session_id, queued_at, admitted_at, cancelled_at
+		FROM request_queue WHERE request_id = ?
+	`, requestID).Scan(&e.Seq, &e.RequestID, &e.SessionID, &queuedAt, &admittedAt, &cancelledAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying queue entry: %w", err)
+	}
+	e.QueuedAt, _ = time.Parse(time.RFC3339, queuedAt)
+	e.AdmittedAt = parseTimePtr(admittedAt)
+	e.CancelledAt = parseTimePtr(cancelledAt)
+	return e, nil
+}
+
+// ListQueuedRequestsMatching returns the queued requests across all
+// projects that match filter, in admission order.
+func (db *DB) ListQueuedRequestsMatching(filter PendingFilter) ([]*Request, error) {
+	where, args := filter.where()
+	rows, err := db.Query(`
+		SELECT id, project_path,
+			command_raw, command_argv_json, command_cwd, command_shell, command_hash,
+			command_display_redacted, command_contains_sensitive,
+			risk_tier, requestor_session_id, requestor_agent, requestor_model,
+			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
+			execution_log_path, execution_exit_code, execution_duration_ms,
+			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
+			created_at, resolved_at, expires_at, approval_expires_at
+		FROM requests
+		JOIN request_queue ON request_queue.request_id = requests.id
+		WHERE status = ?`+where+`
+		ORDER BY request_queue.seq
+	`, append([]any{string(StatusQueued)}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("querying queued requests: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRequests(rows)
+}
+
+// ListQueuedSessions returns the sessions that have requests waiting in the queue.
+func (db *DB) ListQueuedSessions() ([]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT q.session_id
+		FROM request_queue q
+		JOIN requests r ON r.id = q.request_id
+		WHERE r.status = ?
+		ORDER BY q.session_id
+	`, string(StatusQueued))
+	if err != nil {
+		return nil, fmt.Errorf("querying queued sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning queued session: %w", err)
+		}
+		sessions = append(sessions, id)
+	}
+	return sessions, rows.Err()
+}
+
+// ListQueuedRequests returns a project's queued requests in admission order
+// across all sessions. An empty projectPath lists every project.
+func (db *DB) ListQueuedRequests(projectPath string) ([]*Request, error) {
+	rows, err := db.Query(`
+		SELECT id, project_path,
+			command_raw, command_argv_json, command_cwd, command_shell, command_hash,
+			command_display_redacted, command_contains_sensitive,
+			risk_tier, requestor_session_id, requestor_agent, requestor_model,
+			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
+			execution_log_path, execution_exit_code, execution_duration_ms,
+			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
+			created_at, resolved_at, expires_at, approval_expires_at
+		FROM requests
+		JOIN request_queue ON request_queue.request_id = requests.id
+		WHERE status = ? AND (? = '' OR project_path = ?)
+		ORDER BY request_queue.seq
+	`, string(StatusQueued), projectPath, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("querying queued requests: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRequests(rows)
+}
+
+// AdmitQueuedRequest moves a queued request to pending, recording the
+// admission. Its approval timeout restarts from now, keeping the length the
+// requestor asked for.
+func (db *DB) AdmitQueuedRequest(id string, now time.Time) error {
+	return db.Transaction(func(tx *sql.Tx) error {
+		r, err := db.GetRequestTx(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := db.UpdateRequestStatusTx(tx, id, StatusPending, r.Status); err != nil {
+			return err
+		}
+		if r.ExpiresAt == nil {
+			return nil
+		}
+		expiresAt := now.UTC().Add(r.ExpiresAt.Sub(r.CreatedAt))
+		if _, err := tx.Exec(`UPDATE requests SET expires_at = ? WHERE id = ?`, expiresAt.Format(time.RFC3339), id); err != nil {
+			return fmt.Errorf("resetting expiry on admission: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListAdmissionTimesSince returns, oldest first, when each request counted by
+// CountRequestsSince entered review.
+func (db *DB) ListAdmissionTimesSince(sessionID string, since time.Time) ([]time.Time, error) {
+	rows, err := db.Query(`
+		SELECT COALESCE(q.admitted_at, r.created_at) AS entered FROM requests r
+		LEFT JOIN request_queue q ON q.request_id = r.id
+		WHERE r.requestor_session_id = ?
+			AND (q.request_id IS NULL OR q.admitted_at IS NOT NULL)
+			AND COALESCE(q.admitted_at, r.created_at) >= ?
+		ORDER BY entered
+	`, sessionID, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("querying admission times: %w", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("scanning admission time: %w", err)
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, fmt.Errorf("parsing admission time: %w", err)
+		}
+		times = append(times, t.UTC())
+	}
+	return times, rows.Err()
+}
+
+// parseTimePtr parses a nullable RFC3339 column.
+func parseTimePtr(s sql.NullString) *time.Time {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -2,6 +2,7 @@
 package db
 
 import (
+	"context"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
@@ -51,28 +52,41 @@ func (db *DB) CreateRequest(r *Request) error {
 	argvJSON, _ := json.Marshal(r.Command.Argv)
 	attachmentsJSON, _ := json.Marshal(r.Attachments)
 
-	_, err := db.Exec(`
+	tokens := SimilarityTokens(requestIndexSegments(r))
+
+	err := db.Transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
 		INSERT INTO requests (
 			id, project_path,
 			command_raw, command_argv_json, command_cwd, command_shell, command_hash,
 			command_display_redacted, command_contains_sensitive,
 			risk_tier, requestor_session_id, requestor_agent, requestor_model,
 			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
-			dry_run_command, dry_run_output, attachments_json,
-			status, min_approvals, require_different_model,
-			created_at, expires_at, approval_expires_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
+			campaign_id, requestor_program, require_different_program, required_roles_json, created_at, expires_at, approval_expires_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
-		r.ID, r.ProjectPath,
-		r.Command.Raw, string(argvJSON), r.Command.Cwd, boolToInt(r.Command.Shell), r.Command.Hash,
-		nullString(r.Command.DisplayRedacted), boolToInt(r.Command.ContainsSensitive),
-		string(r.RiskTier), r.RequestorSessionID, r.RequestorAgent, r.RequestorModel,
-		r.Justification.Reason, nullString(r.Justification.ExpectedEffect), nullString(r.Justification.Goal), nullString(r.Justification.SafetyArgument),
-		nullDryRunCommand(r.DryRun), nullDryRunOutput(r.DryRun), string(attachmentsJSON),
-		string(r.Status), r.MinApprovals, boolToInt(r.RequireDifferentModel),
-		r.CreatedAt.Format(time.RFC3339), formatTimePtr(r.ExpiresAt), formatTimePtr(r.ApprovalExpiresAt),
-	)
-
+			r.ID, r.ProjectPath,
+			r.Command.Raw, string(argvJSON), r.Command.Cwd, boolToInt(r.Command.Shell), r.Command.Hash,
+			nullString(r.Command.DisplayRedacted), boolToInt(r.Command.ContainsSensitive),
+			string(r.RiskTier), r.RequestorSessionID, r.RequestorAgent, r.RequestorModel,
+			r.Justification.Reason, nullString(r.Justification.ExpectedEffect), nullString(r.Justification.Goal), nullString(r.Justification.SafetyArgument),
+			nullDryRunCommand(r.DryRun), nullDryRunOutput(r.DryRun), string(attachmentsJSON), nullPinnedContext(r.PinnedContext),
+			nullStringSlice(r.Command.NormalizedSegments), nullString(r.Command.Summary), nullString(r.TierReason), nullLabels(r.Labels), nullMigrationSet(r.Migrations),
+			string(r.Status), r.MinApprovals, boolToInt(r.RequireDifferentModel), boolToInt(r.RequireDifferentHost), r.TimeoutSecs, r.TimeoutRequestedSecs,
+			nullString(r.CampaignID), r.RequestorProgram, boolToInt(r.RequireDifferentProgram), nullStringSlice(r.RequiredRoles), r.CreatedAt.Format(time.RFC3339), formatTimePtr(r.ExpiresAt), formatTimePtr(r.ApprovalExpiresAt),
+		); err != nil {
+			return err
+		}
+		if r.Status == StatusQueued {
+			if err := enqueueRequestTx(tx, r.ID, r.RequestorSessionID, now); err != nil {
+				return err
+			}
+		}
+		return indexRequestTokens(context.Background(), tx, r.ID, tokens)
+	})
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
@@ -88,11 +102,13 @@ func (db *DB) GetRequestTx(tx *sql.Tx, id string) (*Request, error) {
 			command_display_redacted, command_contains_sensitive,
 			risk_tier, requestor_session_id, requestor_agent, requestor_model,
 			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
-			dry_run_command, dry_run_output, attachments_json,
-			status, min_approvals, require_different_model,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
 			execution_log_path, execution_exit_code, execution_duration_ms,
 			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
-			rollback_path, rollback_rolled_back_at,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
 			created_at, resolved_at, expires_at, approval_expires_at
 		FROM requests WHERE id = ?
 	`, id)
@@ -108,11 +124,13 @@ func (db *DB) GetRequest(id string) (*Request, error) {
 			command_display_redacted, command_contains_sensitive,
 			risk_tier, requestor_session_id, requestor_agent, requestor_model,
 			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
-			dry_run_command, dry_run_output, attachments_json,
-			status, min_approvals, require_different_model,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
 			execution_log_path, execution_exit_code, execution_duration_ms,
 			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
-			rollback_path, rollback_rolled_back_at,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
 			created_at, resolved_at, expires_at, approval_expires_at
 		FROM requests WHERE id = ?
 	`, id)
@@ -120,7 +138,9 @@ func (db *DB) GetRequest(id string) (*Request, error) {
 	return scanRequest(row)
 }
 
-// GetRequestWithReviews retrieves a request and its associated reviews.
+// GetRequestWithReviews retrieves a request and its reviews from every review
+// round, oldest round first; only those whose Round equals the request's
+// ReviewRound count toward its quorum.
 func (db *DB) GetRequestWithReviews(id string) (*Request, []*Review, error) {
 	r, err := db.GetRequest(id)
 	if err != nil {
@@ -129,9 +149,9 @@ func (db *DB) GetRequestWithReviews(id string) (*Request, []*Review, error) {
 
 	rows, err := db.Query(`
 		SELECT id, request_id, reviewer_session_id, reviewer_agent, reviewer_model,
-			decision, signature, signature_timestamp, responses_json, comments, created_at
+			decision, segments_json, signature, signature_timestamp, responses_json, comments, created_at, review_round, command_hash, delegation_id
 		FROM reviews WHERE request_id = ?
-		ORDER BY created_at ASC
+		ORDER BY review_round ASC, created_at ASC
 	`, id)
 	if err != nil {
 		return nil, nil, fmt.Errorf("querying reviews: %w", err)
@@ -170,11 +190,13 @@ func (db *DB) ListPendingRequestsByProjects(projectPaths []string) ([]*Request,
 			command_display_redacted, command_contains_sensitive,
 			risk_tier, requestor_session_id, requestor_agent, requestor_model,
 			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
-			dry_run_command, dry_run_output, attachments_json,
-			status, min_approvals, require_different_model,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
 			execution_log_path, execution_exit_code, execution_duration_ms,
 			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
-			rollback_path, rollback_rolled_back_at,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
 			created_at, resolved_at, expires_at, approval_expires_at
 		FROM requests
 		WHERE project_path IN (%s) AND status = ?
@@ -198,11 +220,13 @@ func (db *DB) ListPendingRequestsAllProjects() ([]*Request, error) {
 			command_display_redacted, command_contains_sensitive,
 			risk_tier, requestor_session_id, requestor_agent, requestor_model,
 			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
-			dry_run_command, dry_run_output, attachments_json,
-			status, min_approvals, require_different_model,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
 			execution_log_path, execution_exit_code, execution_duration_ms,
 			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
-			rollback_path, rollback_rolled_back_at,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
 			created_at, resolved_at, expires_at, approval_expires_at
 		FROM requests WHERE status = ?
 		ORDER BY created_at DESC
@@ -223,11 +247,13 @@ func (db *DB) ListRequestsByStatus(status RequestStatus, projectPath string) ([]
 			command_display_redacted, command_contains_sensitive,
 			risk_tier, requestor_session_id, requestor_agent, requestor_model,
 			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
-			dry_run_command, dry_run_output, attachments_json,
-			status, min_approvals, require_different_model,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
 			execution_log_path, execution_exit_code, execution_duration_ms,
 			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
-			rollback_path, rollback_rolled_back_at,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
 			created_at, resolved_at, expires_at, approval_expires_at
 		FROM requests WHERE status = ? AND project_path = ?
 		ORDER BY created_at DESC
@@ -242,29 +268,288 @@ func (db *DB) ListRequestsByStatus(status RequestStatus, projectPath string) ([]
 
 // ListAllRequests returns all requests for a project, ordered by creation time descending.
 func (db *DB) ListAllRequests(projectPath string) ([]*Request, error) {
+	return db.ListRequestsBetween(projectPath, time.Time{}, time.Time{})
+}
+
+// ListRequestsBetween returns a project's requests created at or after since
+// and before until, newest first. A zero bound is open. The bounds are
+// applied in SQL through idx_requests_project_created, so a narrow window of
+// a long history reads only the rows inside it.
+func (db *DB) ListRequestsBetween(projectPath string, since, until time.Time) ([]*Request, error) {
+	where := "project_path = ?"
+	args := []any{projectPath}
+	if !since.IsZero() {
+		where += " AND created_at >= ?"
+		args = append(args, since.UTC().Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		where += " AND created_at < ?"
+		args = append(args, until.UTC().Format(time.RFC3339))
+	}
 	rows, err := db.Query(`
 		SELECT id, project_path,
 			command_raw, command_argv_json, command_cwd, command_shell, command_hash,
 			command_display_redacted, command_contains_sensitive,
 			risk_tier, requestor_session_id, requestor_agent, requestor_model,
 			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
-			dry_run_command, dry_run_output, attachments_json,
-			status, min_approvals, require_different_model,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
 			execution_log_path, execution_exit_code, execution_duration_ms,
 			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
-			rollback_path, rollback_rolled_back_at,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
 			created_at, resolved_at, expires_at, approval_expires_at
-		FROM requests WHERE project_path = ?
+		FROM requests WHERE `+where+`
 		ORDER BY created_at DESC
-	`, projectPath)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying requests: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRequests(rows)
+}
+
+// ListExecutedRequestsSince returns a project's requests that finished
+// executing (whatever the outcome) at or after since, oldest first.
+func (db *DB) ListExecutedRequestsSince(projectPath string, since time.Time) ([]*Request, error) {
+	rows, err := db.Query(`
+		SELECT id, project_path,
+			command_raw, command_argv_json, command_cwd, command_shell, command_hash,
+			command_display_redacted, command_contains_sensitive,
+			risk_tier, requestor_session_id, requestor_agent, requestor_model,
+			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
+			execution_log_path, execution_exit_code, execution_duration_ms,
+			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
+			created_at, resolved_at, expires_at, approval_expires_at
+		FROM requests
+		WHERE project_path = ? AND status IN (?, ?, ?) AND execution_executed_at >= ?
+		ORDER BY execution_executed_at, created_at
+	`, projectPath, string(StatusExecuted), string(StatusExecutionFailed), string(StatusTimedOut),
+		since.UTC().Format(time.RFC3339))
 	if err != nil {
-		return nil, fmt.Errorf("querying all requests: %w", err)
+		return nil, fmt.Errorf("querying executed requests: %w", err)
 	}
 	defer rows.Close()
 
 	return scanRequests(rows)
 }
 
+// ListRequestsCreatedSince returns a project's requests created at or after
+// since, oldest first.
+func (db *DB) ListRequestsCreatedSince(projectPath string, since time.Time) ([]*Request, error) {
+	rows, err := db.Query(`
+		SELECT id, project_path,
+			command_raw, command_argv_json, command_cwd, command_shell, command_hash,
+			command_display_redacted, command_contains_sensitive,
+			risk_tier, requestor_session_id, requestor_agent, requestor_model,
+			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
+			execution_log_path, execution_exit_code, execution_duration_ms,
+			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
+			created_at, resolved_at, expires_at, approval_expires_at
+		FROM requests
+		WHERE project_path = ? AND created_at >= ?
+		ORDER BY created_at
+	`, projectPath, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("querying requests created since: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRequests(rows)
+}
+
+// PendingFilter narrows ListPendingRequestsMatching and
+// ListQueuedRequestsMatching. Zero fields match every request.
+type PendingFilter struct {
+	// ProjectPaths keeps requests from any of these projects.
+	ProjectPaths []string
+	// Tiers keeps requests with any of these risk tiers.
+	Tiers []RiskTier
+	// Requestor keeps requests from this agent, matched case-insensitively.
+	Requestor string
+}
+
+// where returns the filter's SQL conditions, prefixed with "AND", and their
+// arguments.
+func (f PendingFilter) where() (string, []any) {
+	var clauses []string
+	var args []any
+	in := func(column string, values []string) {
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			placeholders[i] = "?"
+			args = append(args, v)
+		}
+		clauses = append(clauses, fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ",")))
+	}
+	if len(f.ProjectPaths) > 0 {
+		in("project_path", f.ProjectPaths)
+	}
+	if len(f.Tiers) > 0 {
+		tiers := make([]string, len(f.Tiers))
+		for i, t := range f.Tiers {
+			tiers[i] = string(t)
+		}
+		in("risk_tier", tiers)
+	}
+	if f.Requestor != "" {
+		clauses = append(clauses, "requestor_agent = ? COLLATE NOCASE")
+		args = append(args, f.Requestor)
+	}
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return " AND " + strings.Join(clauses, " AND "), args
+}
+
+// ListPendingRequestsMatching returns the pending requests across all
+// projects that match filter, newest first.
+func (db *DB) ListPendingRequestsMatching(filter PendingFilter) ([]*Request, error) {
+	where, args := filter.where()
+	rows, err := db.Query(`
+		SELECT id, project_path,
+			command_raw, command_argv_json, command_cwd, command_shell, command_hash,
+			command_display_redacted, command_contains_sensitive,
+			risk_tier, requestor_session_id, requestor_agent, requestor_model,
+			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
+			execution_log_path, execution_exit_code, execution_duration_ms,
+			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
+			created_at, resolved_at, expires_at, approval_expires_at
+		FROM requests WHERE status = ?`+where+`
+		ORDER BY created_at DESC
+	`, append([]any{string(StatusPending)}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending requests: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRequests(rows)
+}
+
+// RequestFilter selects requests for ListRequestsPage. Zero fields match
+// every request.
+type RequestFilter struct {
+	ProjectPath string
+	Status      RequestStatus
+	Tier        RiskTier
+	// Since keeps requests created at or after it.
+	Since time.Time
+	// Until keeps requests created before it.
+	Until time.Time
+}
+
+// ListRequestsPage returns up to limit requests matching filter, oldest
+// first, starting after the request after (nil starts at the beginning).
+// Pass the last request of one page as after to fetch the next.
+func (db *DB) ListRequestsPage(filter RequestFilter, after *Request, limit int) ([]*Request, error) {
+	var where []string
+	var args []any
+	if filter.ProjectPath != "" {
+		where = append(where, "project_path = ?")
+		args = append(args, filter.ProjectPath)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, string(filter.Status))
+	}
+	if filter.Tier != "" {
+		where = append(where, "risk_tier = ?")
+		args = append(args, string(filter.Tier))
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since.UTC().Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, filter.Until.UTC().Format(time.RFC3339))
+	}
+	if after != nil {
+		created := after.CreatedAt.UTC().Format(time.RFC3339)
+		where = append(where, "(created_at > ? OR (created_at = ? AND id > ?))")
+		args = append(args, created, created, after.ID)
+	}
+	query := `
+		SELECT id, project_path,
+			command_raw, command_argv_json, command_cwd, command_shell, command_hash,
+			command_display_redacted, command_contains_sensitive,
+			risk_tier, requestor_session_id, requestor_agent, requestor_model,
+			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
+			execution_log_path, execution_exit_code, execution_duration_ms,
+			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
+			created_at, resolved_at, expires_at, approval_expires_at
+		FROM requests`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at, id LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying requests page: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRequests(rows)
+}
+
+// LatestRejectionSince returns the session's most recent request for the
+// command hash that was rejected at or after since, or nil if there is none.
+func (db *DB) LatestRejectionSince(sessionID, commandHash string, since time.Time) (*Request, error) {
+	rows, err := db.Query(`
+		SELECT id, project_path,
+			command_raw, command_argv_json, command_cwd, command_shell, command_hash,
+			command_display_redacted, command_contains_sensitive,
+			risk_tier, requestor_session_id, requestor_agent, requestor_model,
+			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
+			execution_log_path, execution_exit_code, execution_duration_ms,
+			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
+			created_at, resolved_at, expires_at, approval_expires_at
+		FROM requests
+		WHERE requestor_session_id = ? AND command_hash = ? AND status = ? AND resolved_at >= ?
+		ORDER BY resolved_at DESC
+		LIMIT 1
+	`, sessionID, commandHash, string(StatusRejected), since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("querying rejected requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests, err := scanRequests(rows)
+	if err != nil || len(requests) == 0 {
+		return nil, err
+	}
+	return requests[0], nil
+}
+
 // UpdateRequestStatusTx updates a request's status within a transaction.
 func (db *DB) UpdateRequestStatusTx(tx *sql.Tx, id string, status RequestStatus, currentStatus RequestStatus) error {
 	// Validate transition using state machine
@@ -295,6 +580,97 @@ func (db *DB) UpdateRequestStatusTx(tx *sql.Tx, id string, status RequestStatus,
 		return fmt.Errorf("%w: concurrent update detected or request not found", ErrInvalidTransition)
 	}
 
+	if currentStatus == StatusQueued {
+		return dequeueRequestTx(tx, id, status, now)
+	}
+	return nil
+}
+
+// CancelRequest cancels a request that is still in status from, recording
+// who cancelled it and why. A lapsed or unused approval is cleared with it,
+// and a queued request leaves the queue in the same transaction. It fails
+// with ErrInvalidTransition if from cannot be cancelled or the request
+// moved on concurrently.
+func (db *DB) CancelRequest(id string, from RequestStatus, cancelledBy, reason string) error {
+	if !canTransition(from, StatusCancelled) {
+		return fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, from, StatusCancelled)
+	}
+	return db.Transaction(func(tx *sql.Tx) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		result, err := tx.Exec(`
+			UPDATE requests SET
+				status = ?, resolved_at = ?, approval_expires_at = NULL,
+				cancel_reason = ?, cancelled_by = ?
+			WHERE id = ? AND status = ?
+		`, string(StatusCancelled), now, reason, cancelledBy, id, string(from))
+		if err != nil {
+			return fmt.Errorf("cancelling request: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("getting rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("%w: concurrent update detected or request not found", ErrInvalidTransition)
+		}
+		if from == StatusQueued {
+			return dequeueRequestTx(tx, id, StatusCancelled, now)
+		}
+		return nil
+	})
+}
+
+// UpdateApprovedSegmentsTx records which segments of a compound command were
+// cleared for execution by a partial approval.
+func (db *DB) UpdateApprovedSegmentsTx(tx *sql.Tx, id string, segments []int) error {
+	if _, err := tx.Exec(`UPDATE requests SET approved_segments_json = ? WHERE id = ?`, nullIntSlice(segments), id); err != nil {
+		return fmt.Errorf("updating approved segments: %w", err)
+	}
+	return nil
+}
+
+// MarkSLANotified records that a request's SLA breach was announced at at. It
+// reports false, changing nothing, if the breach was already announced, so
+// each breach is announced once even across daemon restarts.
+func (db *DB) MarkSLANotified(id string, at time.Time) (bool, error) {
+	result, err := db.Exec(`UPDATE requests SET sla_notified_at = ? WHERE id = ? AND sla_notified_at IS NULL`,
+		at.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return false, fmt.Errorf("marking sla notified: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("marking sla notified: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ReopenExpiredApproval sends a request whose approval expired back to pending
+// for a new review round. The round is advanced so earlier reviews stop
+// counting, the stale approval and approved segments are cleared, and
+// expiresAt becomes the new review deadline. It fails with
+// ErrInvalidTransition unless the request is approval_expired.
+func (db *DB) ReopenExpiredApproval(id string, expiresAt *time.Time) error {
+	result, err := db.Exec(`
+		UPDATE requests SET
+			status = ?, review_round = review_round + 1,
+			approval_expires_at = NULL, approved_segments_json = NULL,
+			resolved_at = NULL, expires_at = ?
+		WHERE id = ? AND status = ?
+	`, string(StatusPending), formatTimePtr(expiresAt), id, string(StatusApprovalExpired))
+	if err != nil {
+		return fmt.Errorf("reopening request: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		if _, err := db.GetRequest(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: request is not approval_expired", ErrInvalidTransition)
+	}
 	return nil
 }
 
@@ -311,6 +687,13 @@ func (db *DB) UpdateRequestStatus(id string, status RequestStatus) error {
 		return fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, r.Status, status)
 	}
 
+	// Leaving the queue also updates the queue row, so both go in one transaction.
+	if r.Status == StatusQueued {
+		return db.Transaction(func(tx *sql.Tx) error {
+			return db.UpdateRequestStatusTx(tx, id, status, r.Status)
+		})
+	}
+
 	// Build update query
 	now := time.Now().UTC().Format(time.RFC3339)
 	var resolvedAt sql.NullString
@@ -354,18 +737,25 @@ func canTransition(from, to RequestStatus) bool {
 	}
 
 	switch from {
+	case StatusQueued:
+		return to == StatusPending || to == StatusCancelled
 	case StatusPending:
-		return to == StatusApproved || to == StatusRejected || to == StatusCancelled || to == StatusTimeout
+		// Escalated straight from pending once past the escalate-after age
+		return to == StatusApproved || to == StatusRejected || to == StatusCancelled || to == StatusTimeout || to == StatusEscalated
 	case StatusApproved:
-		return to == StatusExecuting || to == StatusCancelled
+		return to == StatusExecuting || to == StatusCancelled || to == StatusApprovalExpired
+	case StatusApprovalExpired:
+		// Re-requested for a new review round, or abandoned
+		return to == StatusPending || to == StatusCancelled
 	case StatusExecuting:
 		// Note: StatusApproved allows reverting execution when setup fails before command starts
 		return to == StatusExecuted || to == StatusExecutionFailed || to == StatusTimedOut || to == StatusApproved
 	case StatusTimeout:
 		return to == StatusEscalated
 	case StatusEscalated:
-		// Human intervention: can approve or reject after escalation
-		return to == StatusApproved || to == StatusRejected
+		// Human intervention: can approve or reject after escalation, or
+		// back to pending once a withdrawn review undoes a tie
+		return to == StatusApproved || to == StatusRejected || to == StatusPending
 	default:
 		return false
 	}
@@ -381,7 +771,13 @@ func (db *DB) UpdateRequestExecution(id string, exec *Execution) error {
 			execution_executed_at = ?,
 			execution_executed_by_session_id = ?,
 			execution_executed_by_agent = ?,
-			execution_executed_by_model = ?
+			execution_executed_by_model = ?,
+			execution_context_pinning = ?,
+			execution_segments_json = ?,
+			execution_stdout = ?,
+			execution_stderr = ?,
+			execution_stdout_truncated = ?,
+			execution_stderr_truncated = ?
 		WHERE id = ?
 	`,
 		nullString(exec.LogPath),
@@ -391,6 +787,12 @@ func (db *DB) UpdateRequestExecution(id string, exec *Execution) error {
 		nullString(exec.ExecutedBySessionID),
 		nullString(exec.ExecutedByAgent),
 		nullString(exec.ExecutedByModel),
+		nullString(exec.ContextPinning),
+		nullSegmentExecutions(exec.Segments),
+		exec.Stdout,
+		exec.Stderr,
+		boolToInt(exec.StdoutTruncated),
+		boolToInt(exec.StderrTruncated),
 		id,
 	)
 	if err != nil {
@@ -399,10 +801,11 @@ func (db *DB) UpdateRequestExecution(id string, exec *Execution) error {
 	return nil
 }
 
-// UpdateRequestRollbackPath records the rollback capture directory path for a request.
+// UpdateRequestRollbackPath records the rollback capture directory path for
+// a request and clears its rollback pending flag.
 func (db *DB) UpdateRequestRollbackPath(id, rollbackPath string) error {
 	_, err := db.Exec(`
-		UPDATE requests SET rollback_path = ?
+		UPDATE requests SET rollback_path = ?, rollback_pending = 0
 		WHERE id = ?
 	`, nullString(rollbackPath), id)
 	if err != nil {
@@ -411,6 +814,19 @@ func (db *DB) UpdateRequestRollbackPath(id, rollbackPath string) error {
 	return nil
 }
 
+// UpdateRequestRollbackPending flags a request whose execution is waiting
+// for a rollback capture slot, or clears the flag.
+func (db *DB) UpdateRequestRollbackPending(id string, pending bool) error {
+	_, err := db.Exec(`
+		UPDATE requests SET rollback_pending = ?
+		WHERE id = ?
+	`, boolToInt(pending), id)
+	if err != nil {
+		return fmt.Errorf("updating request rollback pending: %w", err)
+	}
+	return nil
+}
+
 // UpdateRequestRolledBackAt records when a rollback was performed for a request.
 func (db *DB) UpdateRequestRolledBackAt(id string, rolledBackAt time.Time) error {
 	_, err := db.Exec(`
@@ -436,15 +852,21 @@ func (db *DB) CountPendingBySession(sessionID string) (int, error) {
 	return count, nil
 }
 
-// CountRequestsSince counts requests created at or after the given time for a session.
-// This is intended for per-minute rate limiting.
+// CountRequestsSince counts requests that entered review at or after the given
+// time for a session. This is intended for per-minute rate limiting.
+//
+// A queued request counts from its admission, not its creation; requests still
+// queued, or cancelled before admission, do not count.
 //
 // NOTE: created_at is stored as RFC3339 text, so we compare against RFC3339 strings.
 func (db *DB) CountRequestsSince(sessionID string, since time.Time) (int, error) {
 	var count int
 	err := db.QueryRow(`
-		SELECT COUNT(*) FROM requests
-		WHERE requestor_session_id = ? AND created_at >= ?
+		SELECT COUNT(*) FROM requests r
+		LEFT JOIN request_queue q ON q.request_id = r.id
+		WHERE r.requestor_session_id = ?
+			AND (q.request_id IS NULL OR q.admitted_at IS NOT NULL)
+			AND COALESCE(q.admitted_at, r.created_at) >= ?
 	`, sessionID, since.UTC().Format(time.RFC3339)).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("counting requests since: %w", err)
@@ -452,13 +874,16 @@ func (db *DB) CountRequestsSince(sessionID string, since time.Time) (int, error)
 	return count, nil
 }
 
-// OldestRequestCreatedAtSince returns the oldest created_at timestamp (if any) for requests
-// at or after the given time for a session.
+// OldestRequestCreatedAtSince returns the oldest timestamp (if any) at which a
+// request counted by CountRequestsSince entered review.
 func (db *DB) OldestRequestCreatedAtSince(sessionID string, since time.Time) (*time.Time, error) {
 	var oldest sql.NullString
 	err := db.QueryRow(`
-		SELECT MIN(created_at) FROM requests
-		WHERE requestor_session_id = ? AND created_at >= ?
+		SELECT MIN(COALESCE(q.admitted_at, r.created_at)) FROM requests r
+		LEFT JOIN request_queue q ON q.request_id = r.id
+		WHERE r.requestor_session_id = ?
+			AND (q.request_id IS NULL OR q.admitted_at IS NOT NULL)
+			AND COALESCE(q.admitted_at, r.created_at) >= ?
 	`, sessionID, since.UTC().Format(time.RFC3339)).Scan(&oldest)
 	if err != nil {
 		return nil, fmt.Errorf("querying oldest request created_at: %w", err)
@@ -489,11 +914,13 @@ func (db *DB) SearchRequests(query string) ([]*Request, error) {
 			r.command_display_redacted, r.command_contains_sensitive,
 			r.risk_tier, r.requestor_session_id, r.requestor_agent, r.requestor_model,
 			r.justification_reason, r.justification_expected_effect, r.justification_goal, r.justification_safety_argument,
-			r.dry_run_command, r.dry_run_output, r.attachments_json,
-			r.status, r.min_approvals, r.require_different_model,
+			r.dry_run_command, r.dry_run_output, r.attachments_json, r.pinned_context_json,
+			r.command_normalized_json, r.command_summary, r.tier_reason, r.labels_json, r.migrations_json,
+			r.status, r.min_approvals, r.require_different_model, r.require_different_host, r.timeout_secs, r.timeout_requested_secs,
 			r.execution_log_path, r.execution_exit_code, r.execution_duration_ms,
 			r.execution_executed_at, r.execution_executed_by_session_id, r.execution_executed_by_agent, r.execution_executed_by_model,
-			r.rollback_path, r.rollback_rolled_back_at,
+			r.execution_context_pinning, r.execution_segments_json, r.approved_segments_json,
+			r.rollback_path, r.rollback_rolled_back_at, r.rollback_pending, r.review_round, r.campaign_id, r.requestor_program, r.require_different_program, r.required_roles_json, r.cancel_reason, r.cancelled_by, r.execution_stdout, r.execution_stderr, r.execution_stdout_truncated, r.execution_stderr_truncated,
 			r.created_at, r.resolved_at, r.expires_at, r.approval_expires_at
 		FROM requests r
 		JOIN requests_fts fts ON r.rowid = fts.rowid
@@ -518,11 +945,13 @@ func (db *DB) FindExpiredRequests() ([]*Request, error) {
 			command_display_redacted, command_contains_sensitive,
 			risk_tier, requestor_session_id, requestor_agent, requestor_model,
 			justification_reason, justification_expected_effect, justification_goal, justification_safety_argument,
-			dry_run_command, dry_run_output, attachments_json,
-			status, min_approvals, require_different_model,
+			dry_run_command, dry_run_output, attachments_json, pinned_context_json,
+			command_normalized_json, command_summary, tier_reason, labels_json, migrations_json,
+			status, min_approvals, require_different_model, require_different_host, timeout_secs, timeout_requested_secs,
 			execution_log_path, execution_exit_code, execution_duration_ms,
 			execution_executed_at, execution_executed_by_session_id, execution_executed_by_agent, execution_executed_by_model,
-			rollback_path, rollback_rolled_back_at,
+			execution_context_pinning, execution_segments_json, approved_segments_json,
+			rollback_path, rollback_rolled_back_at, rollback_pending, review_round, campaign_id, requestor_program, require_different_program, required_roles_json, cancel_reason, cancelled_by, execution_stdout, execution_stderr, execution_stdout_truncated, execution_stderr_truncated,
 			created_at, resolved_at, expires_at, approval_expires_at
 		FROM requests
 		WHERE status = ? AND expires_at IS NOT NULL AND expires_at < ?
@@ -536,15 +965,31 @@ func (db *DB) FindExpiredRequests() ([]*Request, error) {
 	return scanRequests(rows)
 }
 
-// ComputeCommandHash computes the hash for a command spec.
-// Hash = sha256(raw + "\n" + cwd + "\n" + json(argv) + "\n" + shell_bool)
+// ComputeCommandHash computes the hash for a command spec. The spec is
+// canonicalized first (see CanonicalCommandSpec), so equivalent flag
+// orderings such as "rm -rf x" and "rm -fr x" hash identically.
+// Hash = sha256(raw + "\n" + cwd + "\n" + json(argv) + "\n" + shell_bool),
+// with "\n" + migrations_digest appended when migrations are bound.
 func ComputeCommandHash(cmd CommandSpec) string {
+	return hashCommandSpec(CanonicalCommandSpec(cmd))
+}
+
+// CommandHashMatches reports whether cmd.Hash matches the command. Hashes
+// recorded before canonicalization was introduced remain valid.
+func CommandHashMatches(cmd CommandSpec) bool {
+	return cmd.Hash == ComputeCommandHash(cmd) || cmd.Hash == hashCommandSpec(cmd)
+}
+
+func hashCommandSpec(cmd CommandSpec) string {
 	argvJSON, _ := json.Marshal(cmd.Argv)
 	shellStr := "false"
 	if cmd.Shell {
 		shellStr = "true"
 	}
 	data := cmd.Raw + "\n" + cmd.Cwd + "\n" + string(argvJSON) + "\n" + shellStr
+	if cmd.MigrationsDigest != "" {
+		data += "\n" + cmd.MigrationsDigest
+	}
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:])
 }
@@ -553,17 +998,22 @@ func ComputeCommandHash(cmd CommandSpec) string {
 func scanRequest(row *sql.Row) (*Request, error) {
 	r := &Request{}
 	var (
-		argvJSON, attachmentsJSON                           sql.NullString
-		cmdDisplayRedacted                                  sql.NullString
-		justExpEffect, justGoal, justSafety                 sql.NullString
-		dryRunCmd, dryRunOutput                             sql.NullString
-		execLogPath, execExitCode, execDurationMs           sql.NullString
-		execAt, execBySessionID, execByAgent, execByModel   sql.NullString
-		rollbackPath, rollbackAt                            sql.NullString
-		createdAt, resolvedAt, expiresAt, approvalExpiresAt sql.NullString
-		riskTier, status                                    string
-		minApprovals                                        int
-		requireDiffModel, cmdShell, containsSensitive       int
+		argvJSON, attachmentsJSON, pinnedContextJSON                   sql.NullString
+		cmdDisplayRedacted, cmdSummary, tierReason, normalizedJSON     sql.NullString
+		labelsJSON, migrationsJSON, requiredRolesJSON                  sql.NullString
+		justExpEffect, justGoal, justSafety                            sql.NullString
+		dryRunCmd, dryRunOutput                                        sql.NullString
+		execLogPath, execExitCode, execDurationMs                      sql.NullString
+		execAt, execBySessionID, execByAgent, execByModel              sql.NullString
+		execContextPinning, execSegmentsJSON, approvedSegmentsJSON     sql.NullString
+		rollbackPath, rollbackAt, campaignID                           sql.NullString
+		createdAt, resolvedAt, expiresAt, approvalExpiresAt            sql.NullString
+		riskTier, status                                               string
+		minApprovals, rollbackPending                                  int
+		requireDiffModel, requireDiffHost, cmdShell, containsSensitive int
+		requireDiffProgram                                             int
+		execStdout, execStderr                                         string
+		execStdoutTruncated, execStderrTruncated                       int
 	)
 
 	err := row.Scan(
@@ -572,11 +1022,13 @@ func scanRequest(row *sql.Row) (*Request, error) {
 		&cmdDisplayRedacted, &containsSensitive,
 		&riskTier, &r.RequestorSessionID, &r.RequestorAgent, &r.RequestorModel,
 		&r.Justification.Reason, &justExpEffect, &justGoal, &justSafety,
-		&dryRunCmd, &dryRunOutput, &attachmentsJSON,
-		&status, &minApprovals, &requireDiffModel,
+		&dryRunCmd, &dryRunOutput, &attachmentsJSON, &pinnedContextJSON,
+		&normalizedJSON, &cmdSummary, &tierReason, &labelsJSON, &migrationsJSON,
+		&status, &minApprovals, &requireDiffModel, &requireDiffHost, &r.TimeoutSecs, &r.TimeoutRequestedSecs,
 		&execLogPath, &execExitCode, &execDurationMs,
 		&execAt, &execBySessionID, &execByAgent, &execByModel,
-		&rollbackPath, &rollbackAt,
+		&execContextPinning, &execSegmentsJSON, &approvedSegmentsJSON,
+		&rollbackPath, &rollbackAt, &rollbackPending, &r.ReviewRound, &campaignID, &r.RequestorProgram, &requireDiffProgram, &requiredRolesJSON, &r.CancelReason, &r.CancelledBy, &execStdout, &execStderr, &execStdoutTruncated, &execStderrTruncated,
 		&createdAt, &resolvedAt, &expiresAt, &approvalExpiresAt,
 	)
 	if err != nil {
@@ -590,6 +1042,8 @@ func scanRequest(row *sql.Row) (*Request, error) {
 	r.Command.Shell = cmdShell == 1
 	r.Command.ContainsSensitive = containsSensitive == 1
 	r.RequireDifferentModel = requireDiffModel == 1
+	r.RequireDifferentHost = requireDiffHost == 1
+	r.RequireDifferentProgram = requireDiffProgram == 1
 	r.RiskTier = RiskTier(riskTier)
 	r.Status = RequestStatus(status)
 	r.MinApprovals = minApprovals
@@ -597,12 +1051,36 @@ func scanRequest(row *sql.Row) (*Request, error) {
 	if cmdDisplayRedacted.Valid {
 		r.Command.DisplayRedacted = cmdDisplayRedacted.String
 	}
+	if normalizedJSON.Valid && normalizedJSON.String != "" {
+		json.Unmarshal([]byte(normalizedJSON.String), &r.Command.NormalizedSegments)
+	}
+	r.Command.Summary = cmdSummary.String
+	r.TierReason = tierReason.String
+	if labelsJSON.Valid && labelsJSON.String != "" {
+		json.Unmarshal([]byte(labelsJSON.String), &r.Labels)
+	}
+	if requiredRolesJSON.Valid && requiredRolesJSON.String != "" {
+		json.Unmarshal([]byte(requiredRolesJSON.String), &r.RequiredRoles)
+	}
+	if migrationsJSON.Valid && migrationsJSON.String != "" {
+		var set MigrationSet
+		if json.Unmarshal([]byte(migrationsJSON.String), &set) == nil {
+			r.Migrations = &set
+			r.Command.MigrationsDigest = set.Digest
+		}
+	}
 	if argvJSON.Valid {
 		json.Unmarshal([]byte(argvJSON.String), &r.Command.Argv)
 	}
 	if attachmentsJSON.Valid && attachmentsJSON.String != "null" {
 		json.Unmarshal([]byte(attachmentsJSON.String), &r.Attachments)
 	}
+	if pinnedContextJSON.Valid && pinnedContextJSON.String != "" {
+		var pinned PinnedContext
+		if json.Unmarshal([]byte(pinnedContextJSON.String), &pinned) == nil {
+			r.PinnedContext = &pinned
+		}
+	}
 	if justExpEffect.Valid {
 		r.Justification.ExpectedEffect = justExpEffect.String
 	}
@@ -647,12 +1125,26 @@ func scanRequest(row *sql.Row) (*Request, error) {
 		if execByModel.Valid {
 			r.Execution.ExecutedByModel = execByModel.String
 		}
+		if execContextPinning.Valid {
+			r.Execution.ContextPinning = execContextPinning.String
+		}
+		if execSegmentsJSON.Valid && execSegmentsJSON.String != "" {
+			json.Unmarshal([]byte(execSegmentsJSON.String), &r.Execution.Segments)
+		}
+		r.Execution.Stdout, r.Execution.Stderr = execStdout, execStderr
+		r.Execution.StdoutTruncated = execStdoutTruncated != 0
+		r.Execution.StderrTruncated = execStderrTruncated != 0
+	}
+	if approvedSegmentsJSON.Valid && approvedSegmentsJSON.String != "" {
+		json.Unmarshal([]byte(approvedSegmentsJSON.String), &r.ApprovedSegments)
 	}
+	r.CampaignID = campaignID.String
 
 	// Rollback info
-	if rollbackPath.Valid || rollbackAt.Valid {
+	if rollbackPath.Valid || rollbackAt.Valid || rollbackPending != 0 {
 		r.Rollback = &Rollback{
-			Path: rollbackPath.String,
+			Path:    rollbackPath.String,
+			Pending: rollbackPending != 0,
 		}
 		if rollbackAt.Valid {
 			t, _ := time.Parse(time.RFC3339, rollbackAt.String)
@@ -686,17 +1178,22 @@ func scanRequests(rows *sql.Rows) ([]*Request, error) {
 	for rows.Next() {
 		r := &Request{}
 		var (
-			argvJSON, attachmentsJSON                           sql.NullString
-			cmdDisplayRedacted                                  sql.NullString
-			justExpEffect, justGoal, justSafety                 sql.NullString
-			dryRunCmd, dryRunOutput                             sql.NullString
-			execLogPath, execExitCode, execDurationMs           sql.NullString
-			execAt, execBySessionID, execByAgent, execByModel   sql.NullString
-			rollbackPath, rollbackAt                            sql.NullString
-			createdAt, resolvedAt, expiresAt, approvalExpiresAt sql.NullString
-			riskTier, status                                    string
-			minApprovals                                        int
-			requireDiffModel, cmdShell, containsSensitive       int
+			argvJSON, attachmentsJSON, pinnedContextJSON                   sql.NullString
+			cmdDisplayRedacted, cmdSummary, tierReason, normalizedJSON     sql.NullString
+			labelsJSON, migrationsJSON, requiredRolesJSON                  sql.NullString
+			justExpEffect, justGoal, justSafety                            sql.NullString
+			dryRunCmd, dryRunOutput                                        sql.NullString
+			execLogPath, execExitCode, execDurationMs                      sql.NullString
+			execAt, execBySessionID, execByAgent, execByModel              sql.NullString
+			execContextPinning, execSegmentsJSON, approvedSegmentsJSON     sql.NullString
+			rollbackPath, rollbackAt, campaignID                           sql.NullString
+			createdAt, resolvedAt, expiresAt, approvalExpiresAt            sql.NullString
+			riskTier, status                                               string
+			minApprovals, rollbackPending                                  int
+			requireDiffModel, requireDiffHost, cmdShell, containsSensitive int
+			requireDiffProgram                                             int
+			execStdout, execStderr                                         string
+			execStdoutTruncated, execStderrTruncated                       int
 		)
 
 		err := rows.Scan(
@@ -705,11 +1202,13 @@ func scanRequests(rows *sql.Rows) ([]*Request, error) {
 			&cmdDisplayRedacted, &containsSensitive,
 			&riskTier, &r.RequestorSessionID, &r.RequestorAgent, &r.RequestorModel,
 			&r.Justification.Reason, &justExpEffect, &justGoal, &justSafety,
-			&dryRunCmd, &dryRunOutput, &attachmentsJSON,
-			&status, &minApprovals, &requireDiffModel,
+			&dryRunCmd, &dryRunOutput, &attachmentsJSON, &pinnedContextJSON,
+			&normalizedJSON, &cmdSummary, &tierReason, &labelsJSON, &migrationsJSON,
+			&status, &minApprovals, &requireDiffModel, &requireDiffHost, &r.TimeoutSecs, &r.TimeoutRequestedSecs,
 			&execLogPath, &execExitCode, &execDurationMs,
 			&execAt, &execBySessionID, &execByAgent, &execByModel,
-			&rollbackPath, &rollbackAt,
+			&execContextPinning, &execSegmentsJSON, &approvedSegmentsJSON,
+			&rollbackPath, &rollbackAt, &rollbackPending, &r.ReviewRound, &campaignID, &r.RequestorProgram, &requireDiffProgram, &requiredRolesJSON, &r.CancelReason, &r.CancelledBy, &execStdout, &execStderr, &execStdoutTruncated, &execStderrTruncated,
 			&createdAt, &resolvedAt, &expiresAt, &approvalExpiresAt,
 		)
 		if err != nil {
@@ -720,6 +1219,8 @@ func scanRequests(rows *sql.Rows) ([]*Request, error) {
 		r.Command.Shell = cmdShell == 1
 		r.Command.ContainsSensitive = containsSensitive == 1
 		r.RequireDifferentModel = requireDiffModel == 1
+		r.RequireDifferentHost = requireDiffHost == 1
+		r.RequireDifferentProgram = requireDiffProgram == 1
 		r.RiskTier = RiskTier(riskTier)
 		r.Status = RequestStatus(status)
 		r.MinApprovals = minApprovals
@@ -727,12 +1228,36 @@ func scanRequests(rows *sql.Rows) ([]*Request, error) {
 		if cmdDisplayRedacted.Valid {
 			r.Command.DisplayRedacted = cmdDisplayRedacted.String
 		}
+		if normalizedJSON.Valid && normalizedJSON.String != "" {
+			json.Unmarshal([]byte(normalizedJSON.String), &r.Command.NormalizedSegments)
+		}
+		r.Command.Summary = cmdSummary.String
+		r.TierReason = tierReason.String
+		if labelsJSON.Valid && labelsJSON.String != "" {
+			json.Unmarshal([]byte(labelsJSON.String), &r.Labels)
+		}
+		if requiredRolesJSON.Valid && requiredRolesJSON.String != "" {
+			json.Unmarshal([]byte(requiredRolesJSON.String), &r.RequiredRoles)
+		}
+		if migrationsJSON.Valid && migrationsJSON.String != "" {
+			var set MigrationSet
+			if json.Unmarshal([]byte(migrationsJSON.String), &set) == nil {
+				r.Migrations = &set
+				r.Command.MigrationsDigest = set.Digest
+			}
+		}
 		if argvJSON.Valid {
 			json.Unmarshal([]byte(argvJSON.String), &r.Command.Argv)
 		}
 		if attachmentsJSON.Valid && attachmentsJSON.String != "null" {
 			json.Unmarshal([]byte(attachmentsJSON.String), &r.Attachments)
 		}
+		if pinnedContextJSON.Valid && pinnedContextJSON.String != "" {
+			var pinned PinnedContext
+			if json.Unmarshal([]byte(pinnedContextJSON.String), &pinned) == nil {
+				r.PinnedContext = &pinned
+			}
+		}
 		if justExpEffect.Valid {
 			r.Justification.ExpectedEffect = justExpEffect.String
 		}
@@ -777,12 +1302,26 @@ func scanRequests(rows *sql.Rows) ([]*Request, error) {
 			if execByModel.Valid {
 				r.Execution.ExecutedByModel = execByModel.String
 			}
+			if execContextPinning.Valid {
+				r.Execution.ContextPinning = execContextPinning.String
+			}
+			if execSegmentsJSON.Valid && execSegmentsJSON.String != "" {
+				json.Unmarshal([]byte(execSegmentsJSON.String), &r.Execution.Segments)
+			}
+			r.Execution.Stdout, r.Execution.Stderr = execStdout, execStderr
+			r.Execution.StdoutTruncated = execStdoutTruncated != 0
+			r.Execution.StderrTruncated = execStderrTruncated != 0
+		}
+		if approvedSegmentsJSON.Valid && approvedSegmentsJSON.String != "" {
+			json.Unmarshal([]byte(approvedSegmentsJSON.String), &r.ApprovedSegments)
 		}
+		r.CampaignID = campaignID.String
 
 		// Rollback info
-		if rollbackPath.Valid || rollbackAt.Valid {
+		if rollbackPath.Valid || rollbackAt.Valid || rollbackPending != 0 {
 			r.Rollback = &Rollback{
-				Path: rollbackPath.String,
+				Path:    rollbackPath.String,
+				Pending: rollbackPending != 0,
 			}
 			if rollbackAt.Valid {
 				t, _ := time.Parse(time.RFC3339, rollbackAt.String)
@@ -846,3 +1385,57 @@ func nullDryRunOutput(dr *DryRunResult) sql.NullString {
 	}
 	return nullString(dr.Output)
 }
+
+func nullStringSlice(v []string) sql.NullString {
+	if len(v) == 0 {
+		return sql.NullString{}
+	}
+	data, _ := json.Marshal(v)
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+func nullLabels(v map[string]string) sql.NullString {
+	if len(v) == 0 {
+		return sql.NullString{}
+	}
+	data, _ := json.Marshal(v)
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+func nullIntSlice(v []int) sql.NullString {
+	if len(v) == 0 {
+		return sql.NullString{}
+	}
+	data, _ := json.Marshal(v)
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+func nullSegmentExecutions(v []SegmentExecution) sql.NullString {
+	if len(v) == 0 {
+		return sql.NullString{}
+	}
+	data, _ := json.Marshal(v)
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+func nullMigrationSet(set *MigrationSet) sql.NullString {
+	if set == nil {
+		return sql.NullString{}
+	}
+	b, err := json.Marshal(set)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(b), Valid: true}
+}
+
+func nullPinnedContext(pc *PinnedContext) sql.NullString {
+	if pc == nil {
+		return sql.NullString{}
+	}
+	b, err := json.Marshal(pc)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(b), Valid: true}
+}
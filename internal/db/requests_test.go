@@ -4,6 +4,10 @@ package db
 import (
 	"encoding/json"
 	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -90,6 +94,39 @@ func TestGetRequest(t *testing.T) {
 	}
 }
 
+func TestGetRequest_NormalizationRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	req := createCommandRequest(t, db, "sudo rm -rf ./build && ls", "rm -rf ./build", "ls")
+	req.Command.Summary = "rm -rf; ls"
+	if _, err := db.Exec(`UPDATE requests SET command_summary = ?, tier_reason = ? WHERE id = ?`,
+		req.Command.Summary, "matched dangerous pattern \"rm\"", req.ID); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	got, err := db.GetRequest(req.ID)
+	if err != nil {
+		t.Fatalf("GetRequest failed: %v", err)
+	}
+	if want := []string{"rm -rf ./build", "ls"}; !reflect.DeepEqual(got.Command.NormalizedSegments, want) {
+		t.Errorf("NormalizedSegments = %q, want %q", got.Command.NormalizedSegments, want)
+	}
+	if got.Command.Summary != "rm -rf; ls" || got.TierReason != `matched dangerous pattern "rm"` {
+		t.Errorf("summary/tier reason = %q / %q", got.Command.Summary, got.TierReason)
+	}
+
+	// Requests without stored normalization scan as empty values.
+	_, plain := createTestRequest(t, db)
+	got, err = db.GetRequest(plain.ID)
+	if err != nil {
+		t.Fatalf("GetRequest failed: %v", err)
+	}
+	if got.Command.NormalizedSegments != nil || got.Command.Summary != "" || got.TierReason != "" {
+		t.Errorf("expected empty normalization, got %+v / %q", got.Command, got.TierReason)
+	}
+}
+
 func TestGetRequestNotFound(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -166,6 +203,24 @@ func TestUpdateRequestStatus(t *testing.T) {
 	}
 }
 
+func TestMarkSLANotified(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, r := createTestRequest(t, db)
+
+	marked, err := db.MarkSLANotified(r.ID, time.Now())
+	if err != nil || !marked {
+		t.Fatalf("first MarkSLANotified = %v, %v; want true", marked, err)
+	}
+	if marked, err = db.MarkSLANotified(r.ID, time.Now()); err != nil || marked {
+		t.Errorf("second MarkSLANotified = %v, %v; want false", marked, err)
+	}
+	if marked, err = db.MarkSLANotified("missing", time.Now()); err != nil || marked {
+		t.Errorf("MarkSLANotified(missing) = %v, %v; want false", marked, err)
+	}
+}
+
 func TestUpdateRequestStatusInvalid(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -534,6 +589,80 @@ func TestListPendingRequestsByProjects(t *testing.T) {
 	}
 }
 
+func TestListRequestsMatching(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	create := func(agent, project string, tier RiskTier, status RequestStatus) *Request {
+		t.Helper()
+		sess := &Session{AgentName: agent, Program: "codex-cli", Model: "gpt-5", ProjectPath: project}
+		if err := db.CreateSession(sess); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		r := &Request{
+			ProjectPath:        project,
+			RequestorSessionID: sess.ID,
+			RequestorAgent:     agent,
+			RequestorModel:     sess.Model,
+			RiskTier:           tier,
+			Status:             status,
+			MinApprovals:       1,
+			Command:            CommandSpec{Raw: "rm -rf ./build", Cwd: project},
+			Justification:      Justification{Reason: "test"},
+		}
+		if err := db.CreateRequest(r); err != nil {
+			t.Fatalf("CreateRequest failed: %v", err)
+		}
+		return r
+	}
+	apiCritical := create("BlueLake", "/test/api", RiskTierCritical, StatusPending)
+	apiCaution := create("GreenCastle", "/test/api", RiskTierCaution, StatusPending)
+	webDangerous := create("BlueLake", "/test/web", RiskTierDangerous, StatusPending)
+	apiQueued := create("bluelake", "/test/api", RiskTierDangerous, StatusQueued)
+
+	ids := func(reqs []*Request) []string {
+		var out []string
+		for _, r := range reqs {
+			out = append(out, r.ID)
+		}
+		sort.Strings(out)
+		return out
+	}
+	want := func(reqs ...*Request) []string { return ids(reqs) }
+
+	tests := []struct {
+		name        string
+		filter      PendingFilter
+		wantPending []string
+		wantQueued  []string
+	}{
+		{"no filter", PendingFilter{}, want(apiCritical, apiCaution, webDangerous), want(apiQueued)},
+		{"project", PendingFilter{ProjectPaths: []string{"/test/api"}}, want(apiCritical, apiCaution), want(apiQueued)},
+		{"projects", PendingFilter{ProjectPaths: []string{"/test/api", "/test/web"}}, want(apiCritical, apiCaution, webDangerous), want(apiQueued)},
+		{"tiers", PendingFilter{Tiers: []RiskTier{RiskTierDangerous, RiskTierCritical}}, want(apiCritical, webDangerous), want(apiQueued)},
+		{"requestor ignores case", PendingFilter{Requestor: "BLUELAKE"}, want(apiCritical, webDangerous), want(apiQueued)},
+		{"combined", PendingFilter{ProjectPaths: []string{"/test/web"}, Tiers: []RiskTier{RiskTierDangerous}, Requestor: "BlueLake"}, want(webDangerous), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending, err := db.ListPendingRequestsMatching(tt.filter)
+			if err != nil {
+				t.Fatalf("ListPendingRequestsMatching failed: %v", err)
+			}
+			if got := ids(pending); !reflect.DeepEqual(got, tt.wantPending) {
+				t.Errorf("pending = %v, want %v", got, tt.wantPending)
+			}
+			queued, err := db.ListQueuedRequestsMatching(tt.filter)
+			if err != nil {
+				t.Fatalf("ListQueuedRequestsMatching failed: %v", err)
+			}
+			if got := ids(queued); !reflect.DeepEqual(got, tt.wantQueued) {
+				t.Errorf("queued = %v, want %v", got, tt.wantQueued)
+			}
+		})
+	}
+}
+
 func TestUpdateRequestExecutionAndRollback(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -551,6 +680,10 @@ func TestUpdateRequestExecutionAndRollback(t *testing.T) {
 		LogPath:             "/tmp/slb.log",
 		ExitCode:            &exitCode,
 		DurationMs:          &durationMs,
+		ContextPinning:      "pinned kubectl context=prod namespace=web via --context=prod",
+		Stdout:              "deleted 3 files\n",
+		Stderr:              "warning: build/cache busy\n",
+		StderrTruncated:     true,
 	}
 	if err := db.UpdateRequestExecution(r.ID, exec); err != nil {
 		t.Fatalf("UpdateRequestExecution failed: %v", err)
@@ -580,6 +713,12 @@ func TestUpdateRequestExecutionAndRollback(t *testing.T) {
 	if retrieved.Execution.DurationMs == nil || *retrieved.Execution.DurationMs != 1234 {
 		t.Fatalf("DurationMs=%v want 1234", retrieved.Execution.DurationMs)
 	}
+	if retrieved.Execution.Stdout != exec.Stdout || retrieved.Execution.Stderr != exec.Stderr {
+		t.Fatalf("Stdout=%q Stderr=%q want %q and %q", retrieved.Execution.Stdout, retrieved.Execution.Stderr, exec.Stdout, exec.Stderr)
+	}
+	if retrieved.Execution.StdoutTruncated || !retrieved.Execution.StderrTruncated {
+		t.Fatalf("StdoutTruncated=%v StderrTruncated=%v want false and true", retrieved.Execution.StdoutTruncated, retrieved.Execution.StderrTruncated)
+	}
 	if retrieved.Execution.ExecutedAt == nil || !retrieved.Execution.ExecutedAt.Equal(execAt) {
 		t.Fatalf("ExecutedAt=%v want %v", retrieved.Execution.ExecutedAt, execAt)
 	}
@@ -592,6 +731,9 @@ func TestUpdateRequestExecutionAndRollback(t *testing.T) {
 	if retrieved.Execution.ExecutedByModel != "gpt-5" {
 		t.Fatalf("ExecutedByModel=%q want %q", retrieved.Execution.ExecutedByModel, "gpt-5")
 	}
+	if retrieved.Execution.ContextPinning != exec.ContextPinning {
+		t.Fatalf("ContextPinning=%q want %q", retrieved.Execution.ContextPinning, exec.ContextPinning)
+	}
 
 	if retrieved.Rollback == nil {
 		t.Fatalf("expected rollback info to be present")
@@ -909,3 +1051,268 @@ func createTestRequest(t *testing.T, db *DB) (*Session, *Request) {
 
 	return sess, r
 }
+
+func TestCreateRequest_PinnedContextRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	sess := &Session{AgentName: "Pinner", Program: "claude-code", Model: "opus", ProjectPath: "/test/project"}
+	if err := db.CreateSession(sess); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	pinned := &PinnedContext{Family: ContextFamilyKubectl, KubeContext: "prod", KubeNamespace: "web"}
+	r := &Request{
+		ProjectPath:        "/test/project",
+		Command:            CommandSpec{Raw: "kubectl delete pod x", Cwd: "/test/project"},
+		RiskTier:           RiskTierDangerous,
+		RequestorSessionID: sess.ID,
+		RequestorAgent:     sess.AgentName,
+		RequestorModel:     sess.Model,
+		Justification:      Justification{Reason: "cleanup"},
+		MinApprovals:       1,
+		PinnedContext:      pinned,
+	}
+	if err := db.CreateRequest(r); err != nil {
+		t.Fatalf("CreateRequest failed: %v", err)
+	}
+
+	got, err := db.GetRequest(r.ID)
+	if err != nil {
+		t.Fatalf("GetRequest failed: %v", err)
+	}
+	if got.PinnedContext == nil || *got.PinnedContext != *pinned {
+		t.Fatalf("PinnedContext=%+v want %+v", got.PinnedContext, pinned)
+	}
+
+	pending, err := db.ListPendingRequests("/test/project")
+	if err != nil {
+		t.Fatalf("ListPendingRequests failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].PinnedContext == nil || pending[0].PinnedContext.KubeContext != "prod" {
+		t.Fatalf("expected pinned context from list scan, got %+v", pending)
+	}
+
+	_, plain := createTestRequest(t, db)
+	got, err = db.GetRequest(plain.ID)
+	if err != nil {
+		t.Fatalf("GetRequest failed: %v", err)
+	}
+	if got.PinnedContext != nil {
+		t.Fatalf("expected no pinned context, got %+v", got.PinnedContext)
+	}
+}
+
+func TestListRequestsPage(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	sess := &Session{AgentName: "Agent1", Program: "claude-code", Model: "opus-4.5", ProjectPath: "/test/project1"}
+	if err := db.CreateSession(sess); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	create := func(project string, tier RiskTier) *Request {
+		r := &Request{
+			ProjectPath:        project,
+			RequestorSessionID: sess.ID,
+			RequestorAgent:     sess.AgentName,
+			RiskTier:           tier,
+			MinApprovals:       1,
+			Command:            CommandSpec{Raw: "rm -rf ./build", Cwd: "/tmp"},
+			Justification:      Justification{Reason: "test"},
+		}
+		if err := db.CreateRequest(r); err != nil {
+			t.Fatalf("CreateRequest failed: %v", err)
+		}
+		return r
+	}
+	for i := 0; i < 5; i++ {
+		create("/test/project1", RiskTierDangerous)
+	}
+	create("/test/project1", RiskTierCritical)
+	create("/test/project2", RiskTierDangerous)
+
+	// Paging through the project visits each request once.
+	seen := map[string]bool{}
+	var after *Request
+	for pages := 0; ; pages++ {
+		page, err := db.ListRequestsPage(RequestFilter{ProjectPath: "/test/project1"}, after, 2)
+		if err != nil {
+			t.Fatalf("ListRequestsPage failed: %v", err)
+		}
+		for _, r := range page {
+			if seen[r.ID] {
+				t.Fatalf("request %s returned twice", r.ID)
+			}
+			seen[r.ID] = true
+		}
+		if len(page) < 2 {
+			break
+		}
+		after = page[len(page)-1]
+		if pages > 10 {
+			t.Fatal("pagination did not terminate")
+		}
+	}
+	if len(seen) != 6 {
+		t.Errorf("expected 6 requests in project1, got %d", len(seen))
+	}
+
+	critical, err := db.ListRequestsPage(RequestFilter{Tier: RiskTierCritical}, nil, 10)
+	if err != nil || len(critical) != 1 {
+		t.Errorf("tier filter: got %d, %v", len(critical), err)
+	}
+	future, err := db.ListRequestsPage(RequestFilter{Since: time.Now().Add(time.Hour)}, nil, 10)
+	if err != nil || len(future) != 0 {
+		t.Errorf("since filter: got %d, %v", len(future), err)
+	}
+	pending, err := db.ListRequestsPage(RequestFilter{Status: StatusPending}, nil, 10)
+	if err != nil || len(pending) != 7 {
+		t.Errorf("status filter: got %d, %v", len(pending), err)
+	}
+}
+
+// createAgedRequests creates one request per age in project, each created
+// that long before now, and returns them in the same order.
+func createAgedRequests(tb testing.TB, db *DB, project string, now time.Time, ages ...time.Duration) []*Request {
+	tb.Helper()
+	sess := &Session{AgentName: "Agent-" + filepath.Base(project), Program: "claude-code", Model: "opus-4.5", ProjectPath: project}
+	if err := db.CreateSession(sess); err != nil {
+		tb.Fatalf("CreateSession failed: %v", err)
+	}
+	reqs := make([]*Request, 0, len(ages))
+	for _, age := range ages {
+		r := &Request{
+			ProjectPath:        project,
+			RequestorSessionID: sess.ID,
+			RequestorAgent:     sess.AgentName,
+			RiskTier:           RiskTierDangerous,
+			MinApprovals:       1,
+			Command:            CommandSpec{Raw: "rm -rf ./build", Cwd: "/tmp"},
+			Justification:      Justification{Reason: "test"},
+		}
+		if err := db.CreateRequest(r); err != nil {
+			tb.Fatalf("CreateRequest failed: %v", err)
+		}
+		r.CreatedAt = now.Add(-age).UTC().Truncate(time.Second)
+		if _, err := db.Exec(`UPDATE requests SET created_at = ? WHERE id = ?`, r.CreatedAt.Format(time.RFC3339), r.ID); err != nil {
+			tb.Fatalf("backdating request: %v", err)
+		}
+		reqs = append(reqs, r)
+	}
+	return reqs
+}
+
+func TestListRequestsBetween(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	reqs := createAgedRequests(t, db, "/test/project1", now, time.Hour, 12*time.Hour, 48*time.Hour, 10*24*time.Hour)
+	createAgedRequests(t, db, "/test/project2", now, time.Hour)
+
+	ids := func(list []*Request) []string {
+		out := make([]string, len(list))
+		for i, r := range list {
+			out[i] = r.ID
+		}
+		return out
+	}
+	tests := []struct {
+		name         string
+		since, until time.Time
+		want         []*Request
+	}{
+		{"unbounded", time.Time{}, time.Time{}, reqs},
+		{"last 24h", now.Add(-24 * time.Hour), time.Time{}, reqs[:2]},
+		{"until excludes newer", time.Time{}, now.Add(-24 * time.Hour), reqs[2:]},
+		{"window", now.Add(-7 * 24 * time.Hour), now.Add(-6 * time.Hour), reqs[1:3]},
+		{"since is inclusive", reqs[1].CreatedAt, reqs[0].CreatedAt, reqs[1:2]},
+		{"empty window", now.Add(time.Hour), time.Time{}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := db.ListRequestsBetween("/test/project1", tc.since, tc.until)
+			if err != nil {
+				t.Fatalf("ListRequestsBetween failed: %v", err)
+			}
+			// Newest first, only the project's requests inside the window.
+			if want := ids(tc.want); !reflect.DeepEqual(ids(got), want) {
+				t.Errorf("got %v, want %v", ids(got), want)
+			}
+		})
+	}
+
+	page, err := db.ListRequestsPage(RequestFilter{ProjectPath: "/test/project1", Until: now.Add(-24 * time.Hour)}, nil, 10)
+	if err != nil || len(page) != 2 {
+		t.Errorf("until filter: got %d, %v", len(page), err)
+	}
+}
+
+func TestListRequestsBetween_UsesIndex(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	rows, err := db.Query(`EXPLAIN QUERY PLAN
+		SELECT id FROM requests WHERE project_path = ? AND created_at >= ? AND created_at < ?
+		ORDER BY created_at DESC`, "/test/project1", "2026-01-01T00:00:00Z", "2026-02-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("EXPLAIN QUERY PLAN failed: %v", err)
+	}
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		var id, parent, notused int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notused, &detail); err != nil {
+			t.Fatalf("scanning plan: %v", err)
+		}
+		plan = append(plan, detail)
+	}
+	joined := strings.Join(plan, "; ")
+	if !strings.Contains(joined, "idx_requests_project_created") || strings.Contains(joined, "TEMP B-TREE") {
+		t.Errorf("expected a seek on idx_requests_project_created without a sort, got plan %q", joined)
+	}
+}
+
+func BenchmarkListRequestsBetween(b *testing.B) {
+	db, err := Open(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	ages := make([]time.Duration, 2000)
+	for i := range ages {
+		ages[i] = time.Duration(i)*time.Hour + 30*time.Minute
+	}
+	createAgedRequests(b, db, "/bench/project", now, ages...)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		got, err := db.ListRequestsBetween("/bench/project", now.Add(-24*time.Hour), time.Time{})
+		if err != nil || len(got) != 24 {
+			b.Fatalf("got %d requests, %v", len(got), err)
+		}
+	}
+}
+
+func TestUpdateRequestStatus_PendingToEscalated(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, r := createTestRequest(t, db)
+	if err := db.UpdateRequestStatus(r.ID, StatusEscalated); err != nil {
+		t.Fatalf("UpdateRequestStatus(escalated) from pending failed: %v", err)
+	}
+	if err := db.UpdateRequestStatus(r.ID, StatusPending); err != nil {
+		t.Fatalf("UpdateRequestStatus(pending) from escalated failed: %v", err)
+	}
+	if err := db.UpdateRequestStatus(r.ID, StatusEscalated); err != nil {
+		t.Fatalf("UpdateRequestStatus(escalated) failed: %v", err)
+	}
+	if err := db.UpdateRequestStatus(r.ID, StatusExecuting); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected escalated->executing to be refused, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +23,10 @@ var ErrSelfReview = errors.New("cannot review your own request")
 // ErrInvalidSignature indicates the review signature is invalid.
 var ErrInvalidSignature = errors.New("invalid review signature")
 
+// currentRound limits a query on reviews to its request's current review
+// round; reviews from earlier rounds are kept but no longer count.
+const currentRound = "review_round = (SELECT review_round FROM requests WHERE requests.id = reviews.request_id)"
+
 // CreateReviewTx inserts a review within a transaction.
 func (db *DB) CreateReviewTx(tx *sql.Tx, r *Review) error {
 	if r.ID == "" {
@@ -37,17 +42,19 @@ func (db *DB) CreateReviewTx(tx *sql.Tx, r *Review) error {
 
 	respJSON, _ := json.Marshal(r.Responses)
 
-	_, err := tx.Exec(`
+	err := tx.QueryRow(`
 		INSERT INTO reviews (
 			id, request_id, reviewer_session_id, reviewer_agent, reviewer_model,
-			decision, signature, signature_timestamp,
-			responses_json, comments, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			decision, segments_json, signature, signature_timestamp,
+			responses_json, comments, created_at, command_hash, delegation_id, review_round
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+			COALESCE((SELECT review_round FROM requests WHERE id = ?), 0))
+		RETURNING review_round
 	`,
 		r.ID, r.RequestID, r.ReviewerSessionID, r.ReviewerAgent, r.ReviewerModel,
-		string(r.Decision), r.Signature, r.SignatureTimestamp.Format(time.RFC3339),
-		nullString(string(respJSON)), nullString(r.Comments), r.CreatedAt.Format(time.RFC3339),
-	)
+		string(r.Decision), nullIntSlice(r.Segments), r.Signature, r.SignatureTimestamp.Format(time.RFC3339),
+		nullString(string(respJSON)), nullString(r.Comments), r.CreatedAt.Format(time.RFC3339), r.CommandHash, r.DelegationID, r.RequestID,
+	).Scan(&r.Round)
 	if err != nil {
 		if isUniqueConstraintError(err) {
 			return ErrReviewExists
@@ -79,17 +86,19 @@ func (db *DB) CreateReview(r *Review) error {
 
 	respJSON, _ := json.Marshal(r.Responses)
 
-	_, err := db.Exec(`
+	err := db.QueryRow(`
 		INSERT INTO reviews (
 			id, request_id, reviewer_session_id, reviewer_agent, reviewer_model,
-			decision, signature, signature_timestamp,
-			responses_json, comments, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			decision, segments_json, signature, signature_timestamp,
+			responses_json, comments, created_at, command_hash, delegation_id, review_round
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+			COALESCE((SELECT review_round FROM requests WHERE id = ?), 0))
+		RETURNING review_round
 	`,
 		r.ID, r.RequestID, r.ReviewerSessionID, r.ReviewerAgent, r.ReviewerModel,
-		string(r.Decision), r.Signature, r.SignatureTimestamp.Format(time.RFC3339),
-		nullString(string(respJSON)), nullString(r.Comments), r.CreatedAt.Format(time.RFC3339),
-	)
+		string(r.Decision), nullIntSlice(r.Segments), r.Signature, r.SignatureTimestamp.Format(time.RFC3339),
+		nullString(string(respJSON)), nullString(r.Comments), r.CreatedAt.Format(time.RFC3339), r.CommandHash, r.DelegationID, r.RequestID,
+	).Scan(&r.Round)
 	if err != nil {
 		if isUniqueConstraintError(err) {
 			return ErrReviewExists
@@ -103,18 +112,34 @@ func (db *DB) CreateReview(r *Review) error {
 func (db *DB) GetReview(id string) (*Review, error) {
 	row := db.QueryRow(`
 		SELECT id, request_id, reviewer_session_id, reviewer_agent, reviewer_model,
-		       decision, signature, signature_timestamp, responses_json, comments, created_at
+		       decision, segments_json, signature, signature_timestamp, responses_json, comments, created_at, review_round, command_hash, delegation_id
 		FROM reviews WHERE id = ?
 	`, id)
 	return scanReviewRow(row)
 }
 
-// ListReviewsForRequest returns all reviews for a request ordered by created_at.
+// ListReviewsForRequest returns the reviews of a request's current review
+// round, the ones that count toward its quorum, ordered by created_at.
 func (db *DB) ListReviewsForRequest(requestID string) ([]*Review, error) {
 	rows, err := db.Query(`
 		SELECT id, request_id, reviewer_session_id, reviewer_agent, reviewer_model,
-		       decision, signature, signature_timestamp, responses_json, comments, created_at
-		FROM reviews WHERE request_id = ?
+		       decision, segments_json, signature, signature_timestamp, responses_json, comments, created_at, review_round, command_hash, delegation_id
+		FROM reviews WHERE request_id = ? AND `+currentRound+`
+		ORDER BY created_at ASC
+	`, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("listing reviews: %w", err)
+	}
+	defer rows.Close()
+	return scanReviewList(rows)
+}
+
+// ListReviewsForRequestTx is ListReviewsForRequest within a transaction.
+func (db *DB) ListReviewsForRequestTx(tx *sql.Tx, requestID string) ([]*Review, error) {
+	rows, err := tx.Query(`
+		SELECT id, request_id, reviewer_session_id, reviewer_agent, reviewer_model,
+		       decision, segments_json, signature, signature_timestamp, responses_json, comments, created_at, review_round, command_hash, delegation_id
+		FROM reviews WHERE request_id = ? AND `+currentRound+`
 		ORDER BY created_at ASC
 	`, requestID)
 	if err != nil {
@@ -124,6 +149,97 @@ func (db *DB) ListReviewsForRequest(requestID string) ([]*Review, error) {
 	return scanReviewList(rows)
 }
 
+// ReviewActivity pairs a review with its request's creation time and tier,
+// for reviewer analytics.
+type ReviewActivity struct {
+	Review           *Review
+	RequestCreatedAt time.Time
+	RequestRiskTier  RiskTier
+}
+
+// ReviewActivityFilter narrows ListReviewActivity. Zero values match all.
+type ReviewActivityFilter struct {
+	// Agents limits results to reviews by these reviewer agents.
+	Agents []string
+	// Since limits results to reviews created after this time.
+	Since time.Time
+}
+
+// where returns the SQL condition and arguments for the filter, applied to
+// the reviews table under alias.
+func (f ReviewActivityFilter) where(alias string) (string, []any) {
+	conds := []string{"1 = 1"}
+	var args []any
+	if len(f.Agents) > 0 {
+		conds = append(conds, alias+".reviewer_agent IN (?"+strings.Repeat(", ?", len(f.Agents)-1)+")")
+		for _, agent := range f.Agents {
+			args = append(args, agent)
+		}
+	}
+	if !f.Since.IsZero() {
+		conds = append(conds, "julianday("+alias+".created_at) > julianday(?)")
+		args = append(args, f.Since.UTC().Format(time.RFC3339))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// ListReviewActivity returns the reviews matching filter with their request's
+// creation time and risk tier, oldest review first.
+func (db *DB) ListReviewActivity(filter ReviewActivityFilter) ([]ReviewActivity, error) {
+	cond, args := filter.where("rv")
+	rows, err := db.Query(`
+		SELECT id, request_id, reviewer_session_id, reviewer_agent, reviewer_model,
+		       decision, segments_json, signature, signature_timestamp, responses_json, comments, created_at, review_round, command_hash, delegation_id
+		FROM reviews rv
+		WHERE `+cond+`
+		ORDER BY created_at ASC, rowid ASC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing reviews: %w", err)
+	}
+	reviews, err := scanReviewList(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	type requestInfo struct {
+		createdAt time.Time
+		tier      RiskTier
+	}
+	requests := make(map[string]requestInfo)
+	reqRows, err := db.Query(`
+		SELECT DISTINCT rq.id, rq.created_at, rq.risk_tier
+		FROM requests rq JOIN reviews rv ON rv.request_id = rq.id
+		WHERE `+cond, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing reviewed requests: %w", err)
+	}
+	defer reqRows.Close()
+	for reqRows.Next() {
+		var id, created, tier string
+		if err := reqRows.Scan(&id, &created, &tier); err != nil {
+			return nil, fmt.Errorf("scanning reviewed requests: %w", err)
+		}
+		createdAt, _ := time.Parse(time.RFC3339, created)
+		requests[id] = requestInfo{createdAt: createdAt, tier: RiskTier(tier)}
+	}
+	if err := reqRows.Err(); err != nil {
+		return nil, err
+	}
+
+	activity := make([]ReviewActivity, 0, len(reviews))
+	for _, r := range reviews {
+		info := requests[r.RequestID]
+		activity = append(activity, ReviewActivity{
+			Review:           r,
+			RequestCreatedAt: info.createdAt,
+			RequestRiskTier:  info.tier,
+		})
+	}
+	return activity, nil
+}
+
 // CountReviewsByDecisionTx returns counts of approvals and rejections for a request within a transaction.
 func (db *DB) CountReviewsByDecisionTx(tx *sql.Tx, requestID string) (int, int, error) {
 	var approvals, rejections sql.NullInt64
@@ -131,7 +247,7 @@ func (db *DB) CountReviewsByDecisionTx(tx *sql.Tx, requestID string) (int, int,
 		SELECT
 		  SUM(CASE WHEN decision = 'approve' THEN 1 ELSE 0 END),
 		  SUM(CASE WHEN decision = 'reject' THEN 1 ELSE 0 END)
-		FROM reviews WHERE request_id = ?
+		FROM reviews WHERE request_id = ? AND `+currentRound+`
 	`, requestID).Scan(&approvals, &rejections)
 	if err != nil {
 		return 0, 0, fmt.Errorf("counting reviews: %w", err)
@@ -146,7 +262,7 @@ func (db *DB) CountReviewsByDecision(requestID string) (int, int, error) {
 		SELECT
 		  SUM(CASE WHEN decision = 'approve' THEN 1 ELSE 0 END),
 		  SUM(CASE WHEN decision = 'reject' THEN 1 ELSE 0 END)
-		FROM reviews WHERE request_id = ?
+		FROM reviews WHERE request_id = ? AND `+currentRound+`
 	`, requestID).Scan(&approvals, &rejections)
 	if err != nil {
 		return 0, 0, fmt.Errorf("counting reviews: %w", err)
@@ -158,7 +274,7 @@ func (db *DB) CountReviewsByDecision(requestID string) (int, int, error) {
 func (db *DB) HasReviewerAlreadyReviewedTx(tx *sql.Tx, requestID, sessionID string) (bool, error) {
 	var count int
 	err := tx.QueryRow(`
-		SELECT COUNT(*) FROM reviews WHERE request_id = ? AND reviewer_session_id = ?
+		SELECT COUNT(*) FROM reviews WHERE request_id = ? AND reviewer_session_id = ? AND `+currentRound+`
 	`, requestID, sessionID).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("checking duplicate review: %w", err)
@@ -170,7 +286,7 @@ func (db *DB) HasReviewerAlreadyReviewedTx(tx *sql.Tx, requestID, sessionID stri
 func (db *DB) HasReviewerAlreadyReviewed(requestID, sessionID string) (bool, error) {
 	var count int
 	err := db.QueryRow(`
-		SELECT COUNT(*) FROM reviews WHERE request_id = ? AND reviewer_session_id = ?
+		SELECT COUNT(*) FROM reviews WHERE request_id = ? AND reviewer_session_id = ? AND `+currentRound+`
 	`, requestID, sessionID).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("checking duplicate review: %w", err)
@@ -178,6 +294,22 @@ func (db *DB) HasReviewerAlreadyReviewed(requestID, sessionID string) (bool, err
 	return count > 0, nil
 }
 
+// DeleteReviewTx removes a session's review of a request from the current
+// review round within a transaction. It returns ErrReviewNotFound if the
+// session has no such review.
+func (db *DB) DeleteReviewTx(tx *sql.Tx, requestID, sessionID string) error {
+	result, err := tx.Exec(`
+		DELETE FROM reviews WHERE request_id = ? AND reviewer_session_id = ? AND `+currentRound+`
+	`, requestID, sessionID)
+	if err != nil {
+		return fmt.Errorf("deleting review: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrReviewNotFound
+	}
+	return nil
+}
+
 // IsRequestorSameAsReviewer checks if the reviewer session is the same as requestor session.
 func (db *DB) IsRequestorSameAsReviewer(requestID, reviewerSessionID string) (bool, error) {
 	var reqSessionID string
@@ -196,10 +328,10 @@ func scanReviewRow(row *sql.Row) (*Review, error) {
 	var decision string
 	var sigTs, created string
 	var responsesJSON sql.NullString
-	var comments sql.NullString
+	var comments, segmentsJSON sql.NullString
 
 	err := row.Scan(&r.ID, &r.RequestID, &r.ReviewerSessionID, &r.ReviewerAgent, &r.ReviewerModel,
-		&decision, &r.Signature, &sigTs, &responsesJSON, &comments, &created)
+		&decision, &segmentsJSON, &r.Signature, &sigTs, &responsesJSON, &comments, &created, &r.Round, &r.CommandHash, &r.DelegationID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrReviewNotFound
@@ -217,6 +349,9 @@ func scanReviewRow(row *sql.Row) (*Review, error) {
 	if comments.Valid {
 		r.Comments = comments.String
 	}
+	if segmentsJSON.Valid && segmentsJSON.String != "" {
+		_ = json.Unmarshal([]byte(segmentsJSON.String), &r.Segments)
+	}
 
 	return r, nil
 }
@@ -228,10 +363,10 @@ func scanReviewList(rows *sql.Rows) ([]*Review, error) {
 		var decision string
 		var sigTs, created string
 		var responsesJSON sql.NullString
-		var comments sql.NullString
+		var comments, segmentsJSON sql.NullString
 
 		if err := rows.Scan(&r.ID, &r.RequestID, &r.ReviewerSessionID, &r.ReviewerAgent, &r.ReviewerModel,
-			&decision, &r.Signature, &sigTs, &responsesJSON, &comments, &created); err != nil {
+			&decision, &segmentsJSON, &r.Signature, &sigTs, &responsesJSON, &comments, &created, &r.Round, &r.CommandHash, &r.DelegationID); err != nil {
 			return nil, fmt.Errorf("scanning reviews: %w", err)
 		}
 
@@ -244,6 +379,9 @@ func scanReviewList(rows *sql.Rows) ([]*Review, error) {
 		if comments.Valid {
 			r.Comments = comments.String
 		}
+		if segmentsJSON.Valid && segmentsJSON.String != "" {
+			_ = json.Unmarshal([]byte(segmentsJSON.String), &r.Segments)
+		}
 
 		list = append(list, r)
 	}
@@ -254,9 +392,12 @@ func scanReviewList(rows *sql.Rows) ([]*Review, error) {
 }
 
 // ComputeReviewSignature computes an HMAC signature for a review.
-// Signature = HMAC-SHA256(sessionKey, requestID + decision + timestamp)
-func ComputeReviewSignature(sessionKey, requestID string, decision Decision, timestamp time.Time) string {
-	data := requestID + string(decision) + timestamp.Format(time.RFC3339)
+// Signature = HMAC-SHA256(sessionKey, requestID + commandHash + decision + timestamp)
+// commandHash is the hash of the command being reviewed, so a signature
+// does not carry over to an altered command. Reviews signed before command
+// hashes were bound have an empty commandHash.
+func ComputeReviewSignature(sessionKey, requestID, commandHash string, decision Decision, timestamp time.Time) string {
+	data := requestID + commandHash + string(decision) + timestamp.Format(time.RFC3339)
 	key, _ := hex.DecodeString(sessionKey)
 	mac := hmac.New(sha256.New, key)
 	mac.Write([]byte(data))
@@ -264,8 +405,8 @@ func ComputeReviewSignature(sessionKey, requestID string, decision Decision, tim
 }
 
 // VerifyReviewSignature verifies an HMAC signature for a review.
-func VerifyReviewSignature(sessionKey, requestID string, decision Decision, timestamp time.Time, signature string) bool {
-	expected := ComputeReviewSignature(sessionKey, requestID, decision, timestamp)
+func VerifyReviewSignature(sessionKey, requestID, commandHash string, decision Decision, timestamp time.Time, signature string) bool {
+	expected := ComputeReviewSignature(sessionKey, requestID, commandHash, decision, timestamp)
 	return hmac.Equal([]byte(expected), []byte(signature))
 }
 
@@ -274,7 +415,7 @@ func (db *DB) HasDifferentModelApproval(requestID, excludeModel string) (bool, e
 	var count int
 	err := db.QueryRow(`
 		SELECT COUNT(*) FROM reviews
-		WHERE request_id = ? AND decision = ? AND reviewer_model != ?
+		WHERE request_id = ? AND decision = ? AND reviewer_model != ? AND `+currentRound+`
 	`, requestID, string(DecisionApprove), excludeModel).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("checking different model approval: %w", err)
@@ -282,6 +423,39 @@ func (db *DB) HasDifferentModelApproval(requestID, excludeModel string) (bool, e
 	return count > 0, nil
 }
 
+// CountDifferentHostApprovals counts approvals from reviewers whose sessions
+// provably run on a different host than the requestor's session.
+func (db *DB) CountDifferentHostApprovals(req *Request) (int, error) {
+	requestor, err := db.GetSession(req.RequestorSessionID)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("getting requestor session: %w", err)
+	}
+	reviews, err := db.ListReviewsForRequest(req.ID)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, r := range reviews {
+		if r.Decision != DecisionApprove {
+			continue
+		}
+		reviewer, err := db.GetSession(r.ReviewerSessionID)
+		if err != nil {
+			if errors.Is(err, ErrSessionNotFound) {
+				continue
+			}
+			return 0, fmt.Errorf("getting reviewer session: %w", err)
+		}
+		if requestor.OnDifferentHost(reviewer) {
+			count++
+		}
+	}
+	return count, nil
+}
+
 // CheckRequestApprovalStatus checks if a request has met its approval requirements.
 // Returns (approved, rejected, error).
 func (db *DB) CheckRequestApprovalStatus(requestID string) (approved bool, rejected bool, err error) {
@@ -300,6 +474,15 @@ func (db *DB) CheckRequestApprovalStatus(requestID string) (approved bool, rejec
 	}
 
 	if approvalCount >= req.MinApprovals {
+		if req.RequireDifferentHost {
+			differentHost, err := db.CountDifferentHostApprovals(req)
+			if err != nil {
+				return false, false, err
+			}
+			if differentHost < req.MinApprovals {
+				return false, false, nil
+			}
+		}
 		if req.RequireDifferentModel {
 			hasDiffModel, err := db.HasDifferentModelApproval(requestID, req.RequestorModel)
 			if err != nil {
@@ -335,8 +518,14 @@ func (db *DB) CreateReviewWithValidation(r *Review, sessionKey string) error {
 		return ErrSelfReview
 	}
 
-	// Verify signature
-	if !VerifyReviewSignature(sessionKey, r.RequestID, r.Decision, r.SignatureTimestamp, r.Signature) {
+	// Verify signature, which covers the command hash
+	if r.CommandHash == "" {
+		r.CommandHash = req.Command.Hash
+	}
+	if r.CommandHash != req.Command.Hash {
+		return ErrInvalidSignature
+	}
+	if !VerifyReviewSignature(sessionKey, r.RequestID, r.CommandHash, r.Decision, r.SignatureTimestamp, r.Signature) {
 		return ErrInvalidSignature
 	}
 
@@ -2,6 +2,8 @@
 package db
 
 import (
+	"database/sql"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -26,7 +28,7 @@ func TestCreateReview(t *testing.T) {
 
 	// Create a review
 	now := time.Now().UTC()
-	signature := ComputeReviewSignature(reviewerSess.SessionKey, req.ID, DecisionApprove, now)
+	signature := ComputeReviewSignature(reviewerSess.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now)
 
 	review := &Review{
 		RequestID:          req.ID,
@@ -75,7 +77,7 @@ func TestCreateReviewDuplicate(t *testing.T) {
 
 	// Create first review
 	now := time.Now().UTC()
-	signature := ComputeReviewSignature(reviewerSess.SessionKey, req.ID, DecisionApprove, now)
+	signature := ComputeReviewSignature(reviewerSess.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now)
 
 	review1 := &Review{
 		RequestID:          req.ID,
@@ -106,6 +108,69 @@ func TestCreateReviewDuplicate(t *testing.T) {
 	}
 }
 
+func TestReviewRounds(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, req := createTestRequest(t, db)
+	reviewerSess := &Session{
+		AgentName:   "BlueDog",
+		Program:     "codex-cli",
+		Model:       "gpt-5",
+		ProjectPath: "/test/project",
+	}
+	if err := db.CreateSession(reviewerSess); err != nil {
+		t.Fatalf("CreateSession for reviewer failed: %v", err)
+	}
+	review := func() *Review {
+		return &Review{
+			RequestID:         req.ID,
+			ReviewerSessionID: reviewerSess.ID,
+			ReviewerAgent:     reviewerSess.AgentName,
+			ReviewerModel:     reviewerSess.Model,
+			Decision:          DecisionApprove,
+		}
+	}
+
+	first := review()
+	if err := db.CreateReview(first); err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+	for _, status := range []RequestStatus{StatusApproved, StatusApprovalExpired} {
+		if err := db.UpdateRequestStatus(req.ID, status); err != nil {
+			t.Fatalf("UpdateRequestStatus(%s) failed: %v", status, err)
+		}
+	}
+	if err := db.ReopenExpiredApproval(req.ID, nil); err != nil {
+		t.Fatalf("ReopenExpiredApproval failed: %v", err)
+	}
+	if err := db.ReopenExpiredApproval(req.ID, nil); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition reopening a pending request, got: %v", err)
+	}
+
+	// The same reviewer may review again in the new round.
+	second := review()
+	if err := db.CreateReview(second); err != nil {
+		t.Fatalf("CreateReview in round 1 failed: %v", err)
+	}
+	if first.Round != 0 || second.Round != 1 {
+		t.Errorf("Expected rounds 0 and 1, got %d and %d", first.Round, second.Round)
+	}
+
+	current, err := db.ListReviewsForRequest(req.ID)
+	if err != nil || len(current) != 1 || current[0].ID != second.ID {
+		t.Errorf("Expected only the round 1 review to be current, got %v, %v", current, err)
+	}
+	approvals, _, err := db.CountReviewsByDecision(req.ID)
+	if err != nil || approvals != 1 {
+		t.Errorf("Expected 1 current approval, got %d, %v", approvals, err)
+	}
+	_, all, err := db.GetRequestWithReviews(req.ID)
+	if err != nil || len(all) != 2 || all[0].ID != first.ID {
+		t.Errorf("Expected both rounds in order, got %v, %v", all, err)
+	}
+}
+
 func TestGetReview(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -122,7 +187,7 @@ func TestGetReview(t *testing.T) {
 	db.CreateSession(reviewerSess)
 
 	now := time.Now().UTC()
-	signature := ComputeReviewSignature(reviewerSess.SessionKey, req.ID, DecisionApprove, now)
+	signature := ComputeReviewSignature(reviewerSess.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now)
 
 	original := &Review{
 		RequestID:          req.ID,
@@ -180,7 +245,7 @@ func TestListReviewsForRequest(t *testing.T) {
 		db.CreateSession(sess)
 
 		now := time.Now().UTC().Add(time.Duration(i) * time.Second)
-		signature := ComputeReviewSignature(sess.SessionKey, req.ID, DecisionApprove, now)
+		signature := ComputeReviewSignature(sess.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now)
 
 		review := &Review{
 			RequestID:          req.ID,
@@ -222,7 +287,7 @@ func TestCountReviewsByDecision(t *testing.T) {
 		db.CreateSession(sess)
 
 		now := time.Now().UTC()
-		signature := ComputeReviewSignature(sess.SessionKey, req.ID, decision, now)
+		signature := ComputeReviewSignature(sess.SessionKey, req.ID, req.Command.Hash, decision, now)
 
 		review := &Review{
 			RequestID:          req.ID,
@@ -249,36 +314,80 @@ func TestCountReviewsByDecision(t *testing.T) {
 	}
 }
 
+func TestDeleteReviewTx(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, req := createTestRequest(t, db)
+	sess := &Session{AgentName: "AgentA", Program: "codex-cli", Model: "gpt-5", ProjectPath: "/test/project"}
+	if err := db.CreateSession(sess); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if err := db.CreateReview(&Review{
+		RequestID:         req.ID,
+		ReviewerSessionID: sess.ID,
+		ReviewerAgent:     sess.AgentName,
+		ReviewerModel:     sess.Model,
+		Decision:          DecisionApprove,
+		Signature:         "sig",
+	}); err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+
+	err := db.Transaction(func(tx *sql.Tx) error {
+		return db.DeleteReviewTx(tx, req.ID, sess.ID)
+	})
+	if err != nil {
+		t.Fatalf("DeleteReviewTx failed: %v", err)
+	}
+	if approvals, _, _ := db.CountReviewsByDecision(req.ID); approvals != 0 {
+		t.Errorf("Expected the approval to be gone, got %d", approvals)
+	}
+	err = db.Transaction(func(tx *sql.Tx) error {
+		return db.DeleteReviewTx(tx, req.ID, sess.ID)
+	})
+	if !errors.Is(err, ErrReviewNotFound) {
+		t.Errorf("Expected ErrReviewNotFound, got %v", err)
+	}
+}
+
 func TestComputeReviewSignature(t *testing.T) {
 	sessionKey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 	requestID := "test-request-id"
+	commandHash := ComputeCommandHash(CommandSpec{Raw: "rm -rf ./build", Cwd: "/tmp", Shell: true})
 	decision := DecisionApprove
 	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	sig1 := ComputeReviewSignature(sessionKey, requestID, decision, timestamp)
+	sig1 := ComputeReviewSignature(sessionKey, requestID, commandHash, decision, timestamp)
 	if sig1 == "" {
 		t.Error("Expected non-empty signature")
 	}
 
 	// Same inputs should produce same signature
-	sig2 := ComputeReviewSignature(sessionKey, requestID, decision, timestamp)
+	sig2 := ComputeReviewSignature(sessionKey, requestID, commandHash, decision, timestamp)
 	if sig1 != sig2 {
 		t.Error("Expected same signature for same inputs")
 	}
 
 	// Different decision should produce different signature
-	sig3 := ComputeReviewSignature(sessionKey, requestID, DecisionReject, timestamp)
+	sig3 := ComputeReviewSignature(sessionKey, requestID, commandHash, DecisionReject, timestamp)
 	if sig1 == sig3 {
 		t.Error("Expected different signature for different decision")
 	}
 
+	// Different command hash should produce different signature
+	sig4 := ComputeReviewSignature(sessionKey, requestID, ComputeCommandHash(CommandSpec{Raw: "rm -rf /", Cwd: "/tmp", Shell: true}), decision, timestamp)
+	if sig1 == sig4 {
+		t.Error("Expected different signature for different command hash")
+	}
+
 	// Verify signature
-	if !VerifyReviewSignature(sessionKey, requestID, decision, timestamp, sig1) {
+	if !VerifyReviewSignature(sessionKey, requestID, commandHash, decision, timestamp, sig1) {
 		t.Error("Expected signature to verify")
 	}
 
 	// Wrong signature should fail
-	if VerifyReviewSignature(sessionKey, requestID, decision, timestamp, "wrong-signature") {
+	if VerifyReviewSignature(sessionKey, requestID, commandHash, decision, timestamp, "wrong-signature") {
 		t.Error("Expected wrong signature to fail verification")
 	}
 }
@@ -301,7 +410,7 @@ func TestHasDifferentModelApproval(t *testing.T) {
 		db.CreateSession(sess)
 
 		now := time.Now().UTC()
-		signature := ComputeReviewSignature(sess.SessionKey, req.ID, DecisionApprove, now)
+		signature := ComputeReviewSignature(sess.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now)
 
 		review := &Review{
 			RequestID:          req.ID,
@@ -359,7 +468,7 @@ func TestCheckRequestApprovalStatus(t *testing.T) {
 	db.CreateSession(sess1)
 
 	now := time.Now().UTC()
-	signature := ComputeReviewSignature(sess1.SessionKey, req.ID, DecisionApprove, now)
+	signature := ComputeReviewSignature(sess1.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now)
 
 	review := &Review{
 		RequestID:          req.ID,
@@ -443,7 +552,7 @@ func TestCreateReview_ForeignKeyError(t *testing.T) {
 	}
 
 	now := time.Now().UTC()
-	sig := ComputeReviewSignature(reviewerSess.SessionKey, "missing-request", DecisionApprove, now)
+	sig := ComputeReviewSignature(reviewerSess.SessionKey, "missing-request", "", DecisionApprove, now)
 	review := &Review{
 		RequestID:          "missing-request",
 		ReviewerSessionID:  reviewerSess.ID,
@@ -475,7 +584,7 @@ func TestScanReviewList_BadRows(t *testing.T) {
 	}
 
 	now := time.Now().UTC()
-	sig := ComputeReviewSignature(reviewerSess.SessionKey, req.ID, DecisionApprove, now)
+	sig := ComputeReviewSignature(reviewerSess.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now)
 	if err := db.CreateReview(&Review{
 		RequestID:          req.ID,
 		ReviewerSessionID:  reviewerSess.ID,
@@ -515,7 +624,7 @@ func TestCreateReviewWithValidation_ApproveRejectAndValidationErrors(t *testing.
 		t.Fatalf("CreateSession reviewer failed: %v", err)
 	}
 	now := time.Now().UTC()
-	sig := ComputeReviewSignature(reviewer.SessionKey, req.ID, DecisionApprove, now)
+	sig := ComputeReviewSignature(reviewer.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now)
 	review := &Review{
 		RequestID:          req.ID,
 		ReviewerSessionID:  reviewer.ID,
@@ -543,7 +652,7 @@ func TestCreateReviewWithValidation_ApproveRejectAndValidationErrors(t *testing.
 		t.Fatalf("CreateSession reviewer2 failed: %v", err)
 	}
 	now2 := time.Now().UTC()
-	sig2 := ComputeReviewSignature(reviewer2.SessionKey, req2.ID, DecisionReject, now2)
+	sig2 := ComputeReviewSignature(reviewer2.SessionKey, req2.ID, req2.Command.Hash, DecisionReject, now2)
 	review2 := &Review{
 		RequestID:          req2.ID,
 		ReviewerSessionID:  reviewer2.ID,
@@ -608,7 +717,7 @@ func TestCreateReviewWithValidation_ApproveRejectAndValidationErrors(t *testing.
 		t.Fatalf("CreateSession reviewer5 failed: %v", err)
 	}
 	now5 := time.Now().UTC()
-	sig5 := ComputeReviewSignature(reviewer5.SessionKey, req5.ID, DecisionApprove, now5)
+	sig5 := ComputeReviewSignature(reviewer5.SessionKey, req5.ID, req5.Command.Hash, DecisionApprove, now5)
 	notPending := &Review{
 		RequestID:          req5.ID,
 		ReviewerSessionID:  reviewer5.ID,
@@ -655,7 +764,7 @@ func TestCreateReviewWithValidation_RequireDifferentModel(t *testing.T) {
 	}
 
 	ts1 := time.Now().UTC()
-	sig1 := ComputeReviewSignature(sameModel.SessionKey, req.ID, DecisionApprove, ts1)
+	sig1 := ComputeReviewSignature(sameModel.SessionKey, req.ID, req.Command.Hash, DecisionApprove, ts1)
 	r1 := &Review{
 		RequestID:          req.ID,
 		ReviewerSessionID:  sameModel.ID,
@@ -682,7 +791,7 @@ func TestCreateReviewWithValidation_RequireDifferentModel(t *testing.T) {
 	}
 
 	ts2 := time.Now().UTC()
-	sig2 := ComputeReviewSignature(diffModel.SessionKey, req.ID, DecisionApprove, ts2)
+	sig2 := ComputeReviewSignature(diffModel.SessionKey, req.ID, req.Command.Hash, DecisionApprove, ts2)
 	r2 := &Review{
 		RequestID:          req.ID,
 		ReviewerSessionID:  diffModel.ID,
@@ -703,3 +812,157 @@ func TestCreateReviewWithValidation_RequireDifferentModel(t *testing.T) {
 		t.Fatalf("Status=%s want %s", approved.Status, StatusApproved)
 	}
 }
+
+func TestListReviewActivity(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, first := createTestRequest(t, db)
+	_, second := createTestRequest(t, db)
+
+	reviewer := &Session{AgentName: "BlueDog", Program: "codex-cli", Model: "gpt-5", ProjectPath: "/test/project"}
+	if err := db.CreateSession(reviewer); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	base := time.Now().UTC().Truncate(time.Second)
+	for i, req := range []*Request{second, first} {
+		review := &Review{
+			RequestID:         req.ID,
+			ReviewerSessionID: reviewer.ID,
+			ReviewerAgent:     reviewer.AgentName,
+			Decision:          DecisionApprove,
+			CreatedAt:         base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := db.CreateReview(review); err != nil {
+			t.Fatalf("CreateReview failed: %v", err)
+		}
+	}
+
+	activity, err := db.ListReviewActivity(ReviewActivityFilter{})
+	if err != nil {
+		t.Fatalf("ListReviewActivity failed: %v", err)
+	}
+	if len(activity) != 2 {
+		t.Fatalf("expected 2 reviews, got %d", len(activity))
+	}
+	if activity[0].Review.RequestID != second.ID || activity[1].Review.RequestID != first.ID {
+		t.Errorf("expected oldest review first, got %s then %s", activity[0].Review.RequestID, activity[1].Review.RequestID)
+	}
+	for _, a := range activity {
+		if a.RequestCreatedAt.IsZero() || a.RequestRiskTier != RiskTierDangerous {
+			t.Errorf("missing request info: %+v", a)
+		}
+	}
+}
+
+func TestListReviewActivity_Filter(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, first := createTestRequest(t, db)
+	_, second := createTestRequest(t, db)
+
+	base := time.Now().UTC().Truncate(time.Second)
+	for i, agent := range []string{"BlueDog", "RedCat"} {
+		reviewer := &Session{AgentName: agent, Program: "codex-cli", Model: "gpt-5", ProjectPath: "/test/project"}
+		if err := db.CreateSession(reviewer); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		for j, req := range []*Request{first, second} {
+			review := &Review{
+				RequestID:         req.ID,
+				ReviewerSessionID: reviewer.ID,
+				ReviewerAgent:     agent,
+				Decision:          DecisionApprove,
+				CreatedAt:         base.Add(-time.Duration(2*i+j) * time.Hour),
+			}
+			if err := db.CreateReview(review); err != nil {
+				t.Fatalf("CreateReview failed: %v", err)
+			}
+		}
+	}
+
+	activity, err := db.ListReviewActivity(ReviewActivityFilter{Agents: []string{"RedCat"}})
+	if err != nil {
+		t.Fatalf("ListReviewActivity failed: %v", err)
+	}
+	if len(activity) != 2 {
+		t.Fatalf("expected RedCat's 2 reviews, got %d", len(activity))
+	}
+	for _, a := range activity {
+		if a.Review.ReviewerAgent != "RedCat" || a.RequestCreatedAt.IsZero() {
+			t.Errorf("unexpected activity: %+v", a)
+		}
+	}
+
+	activity, err = db.ListReviewActivity(ReviewActivityFilter{Since: base.Add(-90 * time.Minute)})
+	if err != nil {
+		t.Fatalf("ListReviewActivity failed: %v", err)
+	}
+	if len(activity) != 2 || activity[0].Review.ReviewerAgent != "BlueDog" || activity[1].Review.ReviewerAgent != "BlueDog" {
+		t.Errorf("expected BlueDog's two recent reviews, got %d", len(activity))
+	}
+}
+
+func TestCheckRequestApprovalStatus_RequireDifferentHost(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	requestor := &Session{AgentName: "GreenLake", Program: "claude-code", Model: "opus-4.5", ProjectPath: "/test/project", Hostname: "alpha", MachineID: "m-alpha"}
+	if err := db.CreateSession(requestor); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	req := &Request{
+		ProjectPath:          "/test/project",
+		RequestorSessionID:   requestor.ID,
+		RequestorAgent:       requestor.AgentName,
+		RequestorModel:       requestor.Model,
+		RiskTier:             RiskTierDangerous,
+		MinApprovals:         1,
+		RequireDifferentHost: true,
+		Command:              CommandSpec{Raw: "rm -rf ./build", Cwd: "/test/project"},
+		Justification:        Justification{Reason: "Clean build directory"},
+	}
+	if err := db.CreateRequest(req); err != nil {
+		t.Fatalf("CreateRequest failed: %v", err)
+	}
+
+	approve := func(agent, hostname, machineID string) {
+		t.Helper()
+		sess := &Session{AgentName: agent, Program: "codex-cli", Model: "gpt-5", ProjectPath: "/test/project", Hostname: hostname, MachineID: machineID}
+		if err := db.CreateSession(sess); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		now := time.Now().UTC()
+		if err := db.CreateReview(&Review{
+			RequestID:          req.ID,
+			ReviewerSessionID:  sess.ID,
+			ReviewerAgent:      sess.AgentName,
+			ReviewerModel:      sess.Model,
+			Decision:           DecisionApprove,
+			CommandHash:        req.Command.Hash,
+			Signature:          ComputeReviewSignature(sess.SessionKey, req.ID, req.Command.Hash, DecisionApprove, now),
+			SignatureTimestamp: now,
+		}); err != nil {
+			t.Fatalf("CreateReview failed: %v", err)
+		}
+	}
+
+	approve("BlueDog", "alpha", "m-alpha")
+	approved, _, err := db.CheckRequestApprovalStatus(req.ID)
+	if err != nil {
+		t.Fatalf("CheckRequestApprovalStatus failed: %v", err)
+	}
+	if approved {
+		t.Error("Expected same-host approval not to satisfy the request")
+	}
+
+	approve("RedCat", "beta", "m-beta")
+	approved, _, err = db.CheckRequestApprovalStatus(req.ID)
+	if err != nil {
+		t.Fatalf("CheckRequestApprovalStatus failed: %v", err)
+	}
+	if !approved {
+		t.Error("Expected approval from a different host to satisfy the request")
+	}
+}
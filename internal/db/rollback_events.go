@@ -0,0 +1,96 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Rollback event types.
+const (
+	// RollbackEventCapture records state captured before a request ran.
+	RollbackEventCapture = "capture"
+	// RollbackEventRestore records an attempt to restore a capture.
+	RollbackEventRestore = "restore"
+)
+
+// RollbackEvent is a rollback capture or restore attempt, recorded for
+// rollback usage stats.
+type RollbackEvent struct {
+	ID          int64  `json:"id"`
+	RequestID   string `json:"request_id"`
+	ProjectPath string `json:"project_path"`
+	// Event is RollbackEventCapture or RollbackEventRestore.
+	Event string `json:"event"`
+	// Kind is the capture's kind (filesystem, git, kubernetes, ...), when
+	// known.
+	Kind      string `json:"kind,omitempty"`
+	Succeeded bool   `json:"succeeded"`
+	// FailureCategory classifies a failed restore (see
+	// core.ClassifyRestoreFailure).
+	FailureCategory string    `json:"failure_category,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// RecordRollbackEvent inserts a rollback event.
+func (db *DB) RecordRollbackEvent(e *RollbackEvent) error {
+	if e.RequestID == "" || e.ProjectPath == "" || e.Event == "" {
+		return fmt.Errorf("request_id, project_path and event are required")
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	res, err := db.Exec(`
+		INSERT INTO rollback_events (request_id, project_path, event, kind, succeeded, failure_category, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, e.RequestID, e.ProjectPath, e.Event, e.Kind, boolToInt(e.Succeeded), e.FailureCategory, e.Error,
+		e.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("recording rollback event: %w", err)
+	}
+	if e.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("getting rollback event id: %w", err)
+	}
+	return nil
+}
+
+// ListRollbackEvents returns a project's rollback events, or every
+// project's when projectPath is empty, oldest first.
+func (db *DB) ListRollbackEvents(projectPath string) ([]*RollbackEvent, error) {
+	query := `
+		SELECT id, request_id, project_path, event, kind, succeeded, failure_category, error, created_at
+		FROM rollback_events`
+	var args []any
+	if projectPath != "" {
+		query += ` WHERE project_path = ?`
+		args = append(args, projectPath)
+	}
+	query += ` ORDER BY created_at, id`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying rollback events: %w", err)
+	}
+	return scanRollbackEvents(rows)
+}
+
+func scanRollbackEvents(rows *sql.Rows) ([]*RollbackEvent, error) {
+	defer rows.Close()
+	var events []*RollbackEvent
+	for rows.Next() {
+		e := &RollbackEvent{}
+		var succeeded int
+		var createdAt string
+		if err := rows.Scan(&e.ID, &e.RequestID, &e.ProjectPath, &e.Event, &e.Kind, &succeeded,
+			&e.FailureCategory, &e.Error, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning rollback event: %w", err)
+		}
+		e.Succeeded = succeeded != 0
+		e.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating rollback events: %w", err)
+	}
+	return events, nil
+}
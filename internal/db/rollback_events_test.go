@@ -0,0 +1,43 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollbackEvents_RecordAndList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	events := []*RollbackEvent{
+		{RequestID: "req-1", ProjectPath: "/p/a", Event: RollbackEventCapture, Kind: "git", Succeeded: true, CreatedAt: base},
+		{RequestID: "req-1", ProjectPath: "/p/a", Event: RollbackEventRestore, Kind: "git",
+			FailureCategory: "source_gone", Error: "gone", CreatedAt: base.Add(time.Minute)},
+		{RequestID: "req-2", ProjectPath: "/p/b", Event: RollbackEventCapture, Kind: "filesystem", Succeeded: true, CreatedAt: base},
+	}
+	for _, e := range events {
+		if err := db.RecordRollbackEvent(e); err != nil {
+			t.Fatalf("RecordRollbackEvent failed: %v", err)
+		}
+	}
+	if err := db.RecordRollbackEvent(&RollbackEvent{RequestID: "req-3"}); err == nil {
+		t.Error("expected an error without project_path and event")
+	}
+
+	got, err := db.ListRollbackEvents("/p/a")
+	if err != nil {
+		t.Fatalf("ListRollbackEvents failed: %v", err)
+	}
+	if len(got) != 2 || got[0].Event != RollbackEventCapture || !got[0].Succeeded {
+		t.Fatalf("events = %+v", got)
+	}
+	if r := got[1]; r.Succeeded || r.FailureCategory != "source_gone" || r.Error != "gone" || !r.CreatedAt.Equal(base.Add(time.Minute)) {
+		t.Errorf("restore event = %+v", r)
+	}
+
+	all, err := db.ListRollbackEvents("")
+	if err != nil || len(all) != 3 {
+		t.Errorf("ListRollbackEvents(all) = %d events, %v; want 3", len(all), err)
+	}
+}
@@ -1,4 +1,8 @@
 package db
 
 // SchemaVersion is the latest schema migration version.
-const SchemaVersion = 3
+const SchemaVersion = 30
+
+// ReviewCommandHashVersion is the migration that added reviews.command_hash.
+// Reviews created before it was applied signed no command hash.
+const ReviewCommandHashVersion = 25
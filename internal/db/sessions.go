@@ -44,6 +44,11 @@ func (db *DB) CreateSession(s *Session) error {
 		s.SessionKey = hex.EncodeToString(key)
 	}
 
+	// Record the local host unless the caller named one
+	if s.Hostname == "" && s.MachineID == "" {
+		s.Hostname, s.MachineID = LocalHostIdentity()
+	}
+
 	// Set timestamps
 	now := time.Now().UTC()
 	s.StartedAt = now
@@ -52,9 +57,9 @@ func (db *DB) CreateSession(s *Session) error {
 
 	// Insert into database
 	_, err := db.Exec(`
-		INSERT INTO sessions (id, agent_name, program, model, project_path, session_key, started_at, last_active_at, ended_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULL)
-	`, s.ID, s.AgentName, s.Program, s.Model, s.ProjectPath, s.SessionKey, s.StartedAt.Format(time.RFC3339), s.LastActiveAt.Format(time.RFC3339))
+		INSERT INTO sessions (id, agent_name, program, model, project_path, hostname, machine_id, session_key, started_at, last_active_at, ended_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULL)
+	`, s.ID, s.AgentName, s.Program, s.Model, s.ProjectPath, s.Hostname, s.MachineID, s.SessionKey, s.StartedAt.Format(time.RFC3339), s.LastActiveAt.Format(time.RFC3339))
 
 	if err != nil {
 		// Check for unique constraint violation (active session already exists)
@@ -90,7 +95,7 @@ func (db *DB) UpdateSessionModel(id, newModel string) error {
 // GetSession retrieves a session by ID.
 func (db *DB) GetSession(id string) (*Session, error) {
 	row := db.QueryRow(`
-		SELECT id, agent_name, program, model, project_path, session_key, started_at, last_active_at, ended_at
+		SELECT id, agent_name, program, model, project_path, hostname, machine_id, session_key, started_at, last_active_at, ended_at
 		FROM sessions WHERE id = ?
 	`, id)
 
@@ -101,7 +106,7 @@ func (db *DB) GetSession(id string) (*Session, error) {
 // Returns ErrSessionNotFound if no active session exists.
 func (db *DB) GetActiveSession(agentName, projectPath string) (*Session, error) {
 	row := db.QueryRow(`
-		SELECT id, agent_name, program, model, project_path, session_key, started_at, last_active_at, ended_at
+		SELECT id, agent_name, program, model, project_path, hostname, machine_id, session_key, started_at, last_active_at, ended_at
 		FROM sessions
 		WHERE agent_name = ? AND project_path = ? AND ended_at IS NULL
 	`, agentName, projectPath)
@@ -112,7 +117,7 @@ func (db *DB) GetActiveSession(agentName, projectPath string) (*Session, error)
 // ListActiveSessions returns all active sessions for a project.
 func (db *DB) ListActiveSessions(projectPath string) ([]*Session, error) {
 	rows, err := db.Query(`
-		SELECT id, agent_name, program, model, project_path, session_key, started_at, last_active_at, ended_at
+		SELECT id, agent_name, program, model, project_path, hostname, machine_id, session_key, started_at, last_active_at, ended_at
 		FROM sessions
 		WHERE project_path = ? AND ended_at IS NULL
 		ORDER BY last_active_at DESC
@@ -125,10 +130,26 @@ func (db *DB) ListActiveSessions(projectPath string) ([]*Session, error) {
 	return scanSessions(rows)
 }
 
+// ListSessions returns all sessions for a project, ended ones included.
+func (db *DB) ListSessions(projectPath string) ([]*Session, error) {
+	rows, err := db.Query(`
+		SELECT id, agent_name, program, model, project_path, hostname, machine_id, session_key, started_at, last_active_at, ended_at
+		FROM sessions
+		WHERE project_path = ?
+		ORDER BY last_active_at DESC
+	`, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("querying sessions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSessions(rows)
+}
+
 // ListAllActiveSessions returns all active sessions across all projects.
 func (db *DB) ListAllActiveSessions() ([]*Session, error) {
 	rows, err := db.Query(`
-		SELECT id, agent_name, program, model, project_path, session_key, started_at, last_active_at, ended_at
+		SELECT id, agent_name, program, model, project_path, hostname, machine_id, session_key, started_at, last_active_at, ended_at
 		FROM sessions
 		WHERE ended_at IS NULL
 		ORDER BY last_active_at DESC
@@ -238,7 +259,7 @@ func (db *DB) ResetSessionRateLimits(id string, now time.Time) (time.Time, error
 func (db *DB) FindStaleSessions(threshold time.Duration) ([]*Session, error) {
 	cutoff := time.Now().UTC().Add(-threshold).Format(time.RFC3339)
 	rows, err := db.Query(`
-		SELECT id, agent_name, program, model, project_path, session_key, started_at, last_active_at, ended_at
+		SELECT id, agent_name, program, model, project_path, hostname, machine_id, session_key, started_at, last_active_at, ended_at
 		FROM sessions
 		WHERE ended_at IS NULL AND last_active_at < ?
 		ORDER BY last_active_at ASC
@@ -255,7 +276,7 @@ func (db *DB) FindStaleSessions(threshold time.Duration) ([]*Session, error) {
 // that have a different model than the specified one.
 func (db *DB) ListActiveSessionsWithDifferentModel(projectPath, excludeModel string) ([]*Session, error) {
 	rows, err := db.Query(`
-		SELECT id, agent_name, program, model, project_path, session_key, started_at, last_active_at, ended_at
+		SELECT id, agent_name, program, model, project_path, hostname, machine_id, session_key, started_at, last_active_at, ended_at
 		FROM sessions
 		WHERE project_path = ? AND ended_at IS NULL AND model != ?
 		ORDER BY last_active_at DESC
@@ -329,7 +350,7 @@ func scanSession(row *sql.Row) (*Session, error) {
 	var startedAt, lastActiveAt string
 	var endedAt sql.NullString
 
-	err := row.Scan(&s.ID, &s.AgentName, &s.Program, &s.Model, &s.ProjectPath, &s.SessionKey, &startedAt, &lastActiveAt, &endedAt)
+	err := row.Scan(&s.ID, &s.AgentName, &s.Program, &s.Model, &s.ProjectPath, &s.Hostname, &s.MachineID, &s.SessionKey, &startedAt, &lastActiveAt, &endedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrSessionNotFound
@@ -367,7 +388,7 @@ func scanSessions(rows *sql.Rows) ([]*Session, error) {
 		var startedAt, lastActiveAt string
 		var endedAt sql.NullString
 
-		err := rows.Scan(&s.ID, &s.AgentName, &s.Program, &s.Model, &s.ProjectPath, &s.SessionKey, &startedAt, &lastActiveAt, &endedAt)
+		err := rows.Scan(&s.ID, &s.AgentName, &s.Program, &s.Model, &s.ProjectPath, &s.Hostname, &s.MachineID, &s.SessionKey, &startedAt, &lastActiveAt, &endedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scanning session row: %w", err)
 		}
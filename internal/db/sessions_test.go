@@ -700,3 +700,54 @@ func setupTestDB(t *testing.T) *DB {
 
 	return db
 }
+
+func TestCreateSessionRecordsHost(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	local := &Session{AgentName: "GreenLake", Program: "claude-code", Model: "opus-4.5", ProjectPath: "/test/project"}
+	if err := db.CreateSession(local); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	wantHost, wantMachine := LocalHostIdentity()
+	if local.Hostname != wantHost || local.MachineID != wantMachine {
+		t.Errorf("host = (%q, %q), want local (%q, %q)", local.Hostname, local.MachineID, wantHost, wantMachine)
+	}
+
+	remote := &Session{AgentName: "BlueDog", Program: "codex-cli", Model: "gpt-5", ProjectPath: "/test/project", Hostname: "build-02", MachineID: "m-02"}
+	if err := db.CreateSession(remote); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	got, err := db.GetSession(remote.ID)
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	if got.Hostname != "build-02" || got.MachineID != "m-02" {
+		t.Errorf("stored host = (%q, %q), want (build-02, m-02)", got.Hostname, got.MachineID)
+	}
+}
+
+func TestSessionOnDifferentHost(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *Session
+		want bool
+	}{
+		{"nil session", &Session{Hostname: "a"}, nil, false},
+		{"same machine ID, different hostname", &Session{Hostname: "a", MachineID: "m1"}, &Session{Hostname: "b", MachineID: "m1"}, false},
+		{"different machine ID, same hostname", &Session{Hostname: "a", MachineID: "m1"}, &Session{Hostname: "a", MachineID: "m2"}, true},
+		{"hostname fallback differs", &Session{Hostname: "a", MachineID: "m1"}, &Session{Hostname: "b"}, true},
+		{"hostname fallback is case-insensitive", &Session{Hostname: "Build-01"}, &Session{Hostname: "build-01"}, false},
+		{"nothing recorded", &Session{Hostname: "a"}, &Session{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.OnDifferentHost(tt.b); got != tt.want {
+				t.Errorf("OnDifferentHost() = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.OnDifferentHost(tt.a); got != tt.want {
+				t.Errorf("reverse OnDifferentHost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
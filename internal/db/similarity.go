@@ -0,0 +1,257 @@
+// Package db provides the command token index used for similarity search.
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultSimilarLimit is the default number of similar requests returned.
+const DefaultSimilarLimit = 5
+
+// DefaultSimilarMinScore is the default minimum Jaccard similarity.
+const DefaultSimilarMinScore = 0.3
+
+// SimilarOptions controls FindSimilarRequests.
+type SimilarOptions struct {
+	// ExcludeID omits a request (typically the one being compared).
+	ExcludeID string
+	// Limit caps the number of results (DefaultSimilarLimit if <= 0).
+	Limit int
+	// MinScore drops matches below this Jaccard similarity.
+	MinScore float64
+	// BeforeID, if set, keeps only requests created before that request;
+	// insertion order breaks ties within the same second.
+	BeforeID string
+}
+
+// SimilarRequest is a historical request ranked by command similarity.
+type SimilarRequest struct {
+	Request *Request `json:"request"`
+	// Score is the Jaccard similarity of the two token sets (0..1).
+	Score float64 `json:"score"`
+	// SharedTokens is the size of the token intersection.
+	SharedTokens int `json:"shared_tokens"`
+}
+
+// Rough compound/pipe separators for commands indexed without normalized
+// segments (fixtures and the migration backfill).
+var segmentSeparators = regexp.MustCompile(`\s*(?:;|&&|\|\||\||&)\s*`)
+
+// fallbackSegments splits a raw command into segments without shell parsing.
+func fallbackSegments(raw string) []string {
+	var segments []string
+	for _, seg := range segmentSeparators.Split(raw, -1) {
+		if seg = strings.TrimSpace(seg); seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+// SimilarityTokens returns the sorted, de-duplicated index tokens for a
+// command's normalized segments. The command word becomes "cmd:<base>",
+// short flag groups are split into one token per flag (so -rf and -fr match
+// -r -f), and operands are kept as written with quotes removed.
+//
+// Tokens are stored as truncated, unsalted SHA-256 digests to keep the index
+// compact. That is not a confidentiality measure: short tokens can be
+// recovered by hashing guesses, and requests.command_raw holds the command in
+// the clear anyway.
+func SimilarityTokens(segments []string) []string {
+	set := make(map[string]bool)
+	for _, seg := range segments {
+		words := strings.Fields(seg)
+		for i, w := range words {
+			words[i] = strings.Trim(w, `"'`)
+		}
+		words = CanonicalizeArgv(words)
+		for i, w := range words {
+			switch {
+			case w == "":
+			case i == 0:
+				set["cmd:"+filepath.Base(w)] = true
+			case strings.HasPrefix(w, "--") || !isOptionToken(w):
+				set[w] = true
+			default:
+				for _, f := range w[1:] {
+					set["-"+string(f)] = true
+				}
+			}
+		}
+	}
+
+	tokens := make([]string, 0, len(set))
+	for tok := range set {
+		tokens = append(tokens, hashToken(tok))
+	}
+	sort.Strings(tokens)
+	return tokens
+}
+
+func hashToken(tok string) string {
+	sum := sha256.Sum256([]byte(tok))
+	return hex.EncodeToString(sum[:8])
+}
+
+// indexRequestTokens replaces the token index rows for a request.
+func indexRequestTokens(ctx context.Context, tx *sql.Tx, requestID string, tokens []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM request_tokens WHERE request_id = ?`, requestID); err != nil {
+		return fmt.Errorf("clearing request tokens: %w", err)
+	}
+	for _, tok := range tokens {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO request_tokens (request_id, token) VALUES (?, ?)`, requestID, tok); err != nil {
+			return fmt.Errorf("indexing request tokens: %w", err)
+		}
+	}
+	return nil
+}
+
+// requestIndexSegments returns the segments a request is indexed by.
+func requestIndexSegments(r *Request) []string {
+	if len(r.Command.NormalizedSegments) > 0 {
+		return r.Command.NormalizedSegments
+	}
+	return fallbackSegments(r.Command.Raw)
+}
+
+// backfillRequestTokens indexes requests created before the token index.
+func backfillRequestTokens(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, command_raw FROM requests
+		WHERE id NOT IN (SELECT DISTINCT request_id FROM request_tokens)
+	`)
+	if err != nil {
+		return fmt.Errorf("listing requests to index: %w", err)
+	}
+	type pending struct{ id, raw string }
+	var todo []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.raw); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning request: %w", err)
+		}
+		todo = append(todo, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range todo {
+		if err := indexRequestTokens(ctx, tx, p.id, SimilarityTokens(fallbackSegments(p.raw))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequestTokens returns the indexed tokens for a request.
+func (db *DB) RequestTokens(requestID string) ([]string, error) {
+	rows, err := db.Query(`SELECT token FROM request_tokens WHERE request_id = ? ORDER BY token`, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("getting request tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var tok string
+		if err := rows.Scan(&tok); err != nil {
+			return nil, fmt.Errorf("scanning request token: %w", err)
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, rows.Err()
+}
+
+// FindSimilarRequests ranks indexed requests by Jaccard similarity to tokens,
+// most similar first (newest first on ties).
+func (db *DB) FindSimilarRequests(tokens []string, opts SimilarOptions) ([]*SimilarRequest, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultSimilarLimit
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(tokens)), ",")
+	args := make([]any, 0, len(tokens)+1)
+	for _, tok := range tokens {
+		args = append(args, tok)
+	}
+	args = append(args, opts.ExcludeID)
+	before := ""
+	if opts.BeforeID != "" {
+		before = ` AND EXISTS (
+			SELECT 1 FROM requests ref WHERE ref.id = ? AND (
+				julianday(r.created_at) < julianday(ref.created_at) OR
+				(julianday(r.created_at) = julianday(ref.created_at) AND r.rowid < ref.rowid)))`
+		args = append(args, opts.BeforeID)
+	}
+
+	rows, err := db.Query(`
+		SELECT t.request_id, COUNT(*) AS shared,
+		       (SELECT COUNT(*) FROM request_tokens a WHERE a.request_id = t.request_id) AS total,
+		       r.created_at
+		FROM request_tokens t
+		JOIN requests r ON r.id = t.request_id
+		WHERE t.token IN (`+placeholders+`) AND t.request_id != ?`+before+`
+		GROUP BY t.request_id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("finding similar requests: %w", err)
+	}
+
+	type candidate struct {
+		id        string
+		shared    int
+		score     float64
+		createdAt string
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		var total int
+		if err := rows.Scan(&c.id, &c.shared, &total, &c.createdAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning similar request: %w", err)
+		}
+		c.score = float64(c.shared) / float64(len(tokens)+total-c.shared)
+		if c.score >= opts.MinScore {
+			candidates = append(candidates, c)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].createdAt > candidates[j].createdAt
+	})
+	if len(candidates) > opts.Limit {
+		candidates = candidates[:opts.Limit]
+	}
+
+	results := make([]*SimilarRequest, 0, len(candidates))
+	for _, c := range candidates {
+		req, err := db.GetRequest(c.id)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, &SimilarRequest{Request: req, Score: c.score, SharedTokens: c.shared})
+	}
+	return results, nil
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// createCommandRequest creates a request for raw in its own session.
+func createCommandRequest(t *testing.T, db *DB, raw string, segments ...string) *Request {
+	t.Helper()
+	sess := &Session{
+		AgentName:   "Agent-" + time.Now().Format("150405.000000"),
+		Program:     "claude-code",
+		Model:       "opus-4.5",
+		ProjectPath: "/test/project",
+	}
+	if err := db.CreateSession(sess); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	req := &Request{
+		ProjectPath:        sess.ProjectPath,
+		RequestorSessionID: sess.ID,
+		RequestorAgent:     sess.AgentName,
+		RequestorModel:     sess.Model,
+		RiskTier:           RiskTierDangerous,
+		MinApprovals:       1,
+		Command:            CommandSpec{Raw: raw, Cwd: "/test/project", NormalizedSegments: segments},
+		Justification:      Justification{Reason: "test"},
+	}
+	if err := db.CreateRequest(req); err != nil {
+		t.Fatalf("CreateRequest failed: %v", err)
+	}
+	return req
+}
+
+func TestSimilarityTokens(t *testing.T) {
+	same := [][]string{
+		{"rm -rf ./build"},
+		{"rm -fr ./build"},
+		{"rm -r -f ./build"},
+		{"/bin/rm --recursive --force './build'"},
+	}
+	want := SimilarityTokens(same[0])
+	if len(want) != 4 { // cmd:rm, -r, -f, ./build
+		t.Fatalf("expected 4 tokens, got %d", len(want))
+	}
+	for _, segs := range same[1:] {
+		if got := SimilarityTokens(segs); !reflect.DeepEqual(got, want) {
+			t.Errorf("SimilarityTokens(%q) = %v, want %v", segs, got, want)
+		}
+	}
+
+	if got := SimilarityTokens([]string{"rm -rf ./dist"}); reflect.DeepEqual(got, want) {
+		t.Error("different operand should change tokens")
+	}
+	if got := SimilarityTokens(nil); len(got) != 0 {
+		t.Errorf("expected no tokens for nil segments, got %v", got)
+	}
+}
+
+func TestSimilarityTokens_HashedNotPlaintext(t *testing.T) {
+	for _, tok := range SimilarityTokens([]string{"mysql -psecret123 prod"}) {
+		if tok == "-psecret123" || tok == "secret123" || tok == "prod" {
+			t.Fatalf("token stored in plaintext: %q", tok)
+		}
+	}
+}
+
+func TestFallbackSegments(t *testing.T) {
+	got := fallbackSegments("cd /srv && rm -rf cache; ls | wc -l")
+	want := []string{"cd /srv", "rm -rf cache", "ls", "wc -l"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fallbackSegments = %q, want %q", got, want)
+	}
+}
+
+func TestCreateRequest_IndexesTokens(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	req := createCommandRequest(t, db, "sudo rm -rf /var/cache", "rm -rf /var/cache")
+	got, err := db.RequestTokens(req.ID)
+	if err != nil {
+		t.Fatalf("RequestTokens failed: %v", err)
+	}
+	// Normalized segments win over the raw command (sudo is not indexed).
+	if want := SimilarityTokens([]string{"rm -rf /var/cache"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("RequestTokens = %v, want %v", got, want)
+	}
+
+	// Without segments the raw command is split.
+	plain := createCommandRequest(t, db, "rm -rf /tmp/x && ls")
+	got, _ = db.RequestTokens(plain.ID)
+	if want := SimilarityTokens([]string{"rm -rf /tmp/x", "ls"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("fallback RequestTokens = %v, want %v", got, want)
+	}
+}
+
+func TestFindSimilarRequests_Ranking(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	exact := createCommandRequest(t, db, "rm -fr ./build")
+	near := createCommandRequest(t, db, "rm -rf ./dist")
+	createCommandRequest(t, db, "kubectl delete pod web-1")
+
+	tokens := SimilarityTokens([]string{"rm -rf ./build"})
+	results, err := db.FindSimilarRequests(tokens, SimilarOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("FindSimilarRequests failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(results))
+	}
+	if results[0].Request.ID != exact.ID || results[0].Score != 1 {
+		t.Errorf("top match = %s (%.2f), want %s (1.00)", results[0].Request.ID, results[0].Score, exact.ID)
+	}
+	// {cmd:rm,-r,-f,./build} vs {cmd:rm,-r,-f,./dist}: 3 shared of 5.
+	if results[1].Request.ID != near.ID || results[1].SharedTokens != 3 || results[1].Score != 0.6 {
+		t.Errorf("second match = %s shared=%d score=%.2f", results[1].Request.ID, results[1].SharedTokens, results[1].Score)
+	}
+
+	results, _ = db.FindSimilarRequests(tokens, SimilarOptions{ExcludeID: exact.ID, MinScore: 0.7})
+	if len(results) != 0 {
+		t.Errorf("expected no matches above 0.7 excluding the exact one, got %d", len(results))
+	}
+
+	results, _ = db.FindSimilarRequests(tokens, SimilarOptions{Limit: 1})
+	if len(results) != 1 || results[0].Request.ID != exact.ID {
+		t.Errorf("Limit 1 should return only the best match")
+	}
+
+	if results, err := db.FindSimilarRequests(nil, SimilarOptions{}); err != nil || results != nil {
+		t.Errorf("no tokens should return nil, nil; got %v, %v", results, err)
+	}
+}
+
+func TestApplyMigrations_BackfillsRequestTokens(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	req := createCommandRequest(t, db, "rm -rf ./build")
+	// Simulate a request created before the token index existed.
+	if _, err := db.Exec(`DELETE FROM request_tokens`); err != nil {
+		t.Fatalf("clearing tokens: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE version >= 5`); err != nil {
+		t.Fatalf("resetting migration: %v", err)
+	}
+
+	if err := db.ApplyMigrations(context.Background()); err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+	got, _ := db.RequestTokens(req.ID)
+	if want := SimilarityTokens([]string{"rm -rf ./build"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("backfilled tokens = %v, want %v", got, want)
+	}
+}
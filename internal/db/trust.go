@@ -0,0 +1,231 @@
+// Package db provides storage for trusted scripts.
+package db
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrTrustedScriptNotFound indicates no trust entry matched.
+var ErrTrustedScriptNotFound = errors.New("trusted script not found")
+
+// TrustedScript records a reviewer's signed statement that a script, pinned
+// by the SHA-256 of its contents, has been vetted.
+type TrustedScript struct {
+	ID          string `json:"id"`
+	ProjectPath string `json:"project_path"`
+	// Path is the script relative to the project root, with slashes.
+	Path string `json:"path"`
+	// SHA256 is the hex digest of the contents that were vetted.
+	SHA256    string `json:"sha256"`
+	SessionID string `json:"session_id"`
+	AgentName string `json:"agent_name"`
+	Model     string `json:"model,omitempty"`
+	// Signature = HMAC-SHA256(sessionKey, projectPath + path + sha256 + timestamp).
+	Signature string     `json:"signature"`
+	Comment   string     `json:"comment,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	// RevokedBy is the agent that revoked the entry, or the ID of the entry
+	// that superseded it.
+	RevokedBy string `json:"revoked_by,omitempty"`
+}
+
+// Active reports whether the entry has not been revoked.
+func (t *TrustedScript) Active() bool {
+	return t.RevokedAt == nil
+}
+
+// TrustedScriptUse records that a request's tier was lowered because its
+// command ran a trusted script.
+type TrustedScriptUse struct {
+	RequestID string `json:"request_id"`
+	TrustID   string `json:"trust_id"`
+	// SHA256 is the script's digest when the request was created.
+	SHA256   string   `json:"sha256"`
+	FromTier RiskTier `json:"from_tier"`
+	ToTier   RiskTier `json:"to_tier"`
+}
+
+// CreateTrustedScript inserts a trust entry, generating ID and timestamp if
+// missing. Any active entry for the same script is revoked as superseded.
+func (db *DB) CreateTrustedScript(t *TrustedScript) error {
+	if t.ProjectPath == "" || t.Path == "" {
+		return fmt.Errorf("project_path and path are required")
+	}
+	if t.SHA256 == "" {
+		return fmt.Errorf("sha256 is required")
+	}
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+
+	return db.Transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			UPDATE trusted_scripts SET revoked_at = ?, revoked_by = ?
+			WHERE project_path = ? AND path = ? AND revoked_at IS NULL
+		`, t.CreatedAt.Format(time.RFC3339), t.ID, t.ProjectPath, t.Path); err != nil {
+			return fmt.Errorf("superseding trusted script: %w", err)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO trusted_scripts (
+				id, project_path, path, sha256, session_id,
+				agent_name, model, signature, comment, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			t.ID, t.ProjectPath, t.Path, t.SHA256, t.SessionID,
+			t.AgentName, nullString(t.Model), t.Signature, nullString(t.Comment),
+			t.CreatedAt.Format(time.RFC3339),
+		); err != nil {
+			return fmt.Errorf("creating trusted script: %w", err)
+		}
+		return nil
+	})
+}
+
+const trustedScriptColumns = `id, project_path, path, sha256, session_id,
+	agent_name, model, signature, comment, created_at, revoked_at, revoked_by`
+
+func scanTrustedScript(row interface{ Scan(...any) error }) (*TrustedScript, error) {
+	t := &TrustedScript{}
+	var model, comment, revokedAt, revokedBy sql.NullString
+	var created string
+	if err := row.Scan(&t.ID, &t.ProjectPath, &t.Path, &t.SHA256, &t.SessionID,
+		&t.AgentName, &model, &t.Signature, &comment, &created, &revokedAt, &revokedBy); err != nil {
+		return nil, err
+	}
+	t.Model = model.String
+	t.Comment = comment.String
+	t.CreatedAt, _ = time.Parse(time.RFC3339, created)
+	t.RevokedAt = parseTimePtr(revokedAt)
+	t.RevokedBy = revokedBy.String
+	return t, nil
+}
+
+// ListTrustedScripts returns a project's trust entries ordered by path,
+// newest first per path. Revoked entries are included only when asked.
+func (db *DB) ListTrustedScripts(projectPath string, includeRevoked bool) ([]*TrustedScript, error) {
+	query := `SELECT ` + trustedScriptColumns + ` FROM trusted_scripts WHERE project_path = ?`
+	if !includeRevoked {
+		query += ` AND revoked_at IS NULL`
+	}
+	query += ` ORDER BY path, created_at DESC, rowid DESC`
+	rows, err := db.Query(query, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("listing trusted scripts: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*TrustedScript
+	for rows.Next() {
+		t, err := scanTrustedScript(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning trusted scripts: %w", err)
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// GetTrustedScript returns a trust entry by ID.
+// Returns ErrTrustedScriptNotFound if it does not exist.
+func (db *DB) GetTrustedScript(id string) (*TrustedScript, error) {
+	t, err := scanTrustedScript(db.QueryRow(`SELECT `+trustedScriptColumns+` FROM trusted_scripts WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTrustedScriptNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting trusted script: %w", err)
+	}
+	return t, nil
+}
+
+// ActiveTrustedScript returns the active trust entry for a script.
+// Returns ErrTrustedScriptNotFound if the script is not trusted.
+func (db *DB) ActiveTrustedScript(projectPath, path string) (*TrustedScript, error) {
+	t, err := scanTrustedScript(db.QueryRow(`
+		SELECT `+trustedScriptColumns+` FROM trusted_scripts
+		WHERE project_path = ? AND path = ? AND revoked_at IS NULL
+		ORDER BY created_at DESC, rowid DESC LIMIT 1
+	`, projectPath, path))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTrustedScriptNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting trusted script: %w", err)
+	}
+	return t, nil
+}
+
+// RevokeTrustedScript revokes an active trust entry on behalf of revokedBy.
+func (db *DB) RevokeTrustedScript(id, revokedBy string, at time.Time) error {
+	res, err := db.Exec(`
+		UPDATE trusted_scripts SET revoked_at = ?, revoked_by = ?
+		WHERE id = ? AND revoked_at IS NULL
+	`, at.UTC().Format(time.RFC3339), revokedBy, id)
+	if err != nil {
+		return fmt.Errorf("revoking trusted script: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTrustedScriptNotFound
+	}
+	return nil
+}
+
+// RecordTrustedScriptUse records the trust entry that lowered a request's tier.
+func (db *DB) RecordTrustedScriptUse(u *TrustedScriptUse) error {
+	_, err := db.Exec(`
+		INSERT INTO trusted_script_uses (request_id, trust_id, sha256, from_tier, to_tier)
+		VALUES (?, ?, ?, ?, ?)
+	`, u.RequestID, u.TrustID, u.SHA256, string(u.FromTier), string(u.ToTier))
+	if err != nil {
+		return fmt.Errorf("recording trusted script use: %w", err)
+	}
+	return nil
+}
+
+// GetTrustedScriptUse returns the trust entry use recorded for a request,
+// or nil if its tier was not lowered by a trusted script.
+func (db *DB) GetTrustedScriptUse(requestID string) (*TrustedScriptUse, error) {
+	u := &TrustedScriptUse{}
+	var from, to string
+	err := db.QueryRow(`
+		SELECT request_id, trust_id, sha256, from_tier, to_tier
+		FROM trusted_script_uses WHERE request_id = ?
+	`, requestID).Scan(&u.RequestID, &u.TrustID, &u.SHA256, &from, &to)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting trusted script use: %w", err)
+	}
+	u.FromTier = RiskTier(from)
+	u.ToTier = RiskTier(to)
+	return u, nil
+}
+
+// ComputeTrustSignature computes an HMAC signature for a trust entry.
+// Signature = HMAC-SHA256(sessionKey, projectPath + path + sha256 + timestamp)
+func ComputeTrustSignature(sessionKey, projectPath, path, sha string, timestamp time.Time) string {
+	data := projectPath + path + sha + timestamp.Format(time.RFC3339)
+	key, _ := hex.DecodeString(sessionKey)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyTrustSignature verifies an HMAC signature for a trust entry.
+func VerifyTrustSignature(sessionKey string, t *TrustedScript) bool {
+	expected := ComputeTrustSignature(sessionKey, t.ProjectPath, t.Path, t.SHA256, t.CreatedAt)
+	return hmac.Equal([]byte(expected), []byte(t.Signature))
+}
@@ -0,0 +1,135 @@
+// Package db tests for trusted script storage.
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTrustedScripts_CreateSupersedeRevoke(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	first := &TrustedScript{
+		ProjectPath: "/test/project",
+		Path:        "ops/restart.sh",
+		SHA256:      "aaaa",
+		SessionID:   "sess-1",
+		AgentName:   "GreenLake",
+		Comment:     "vetted",
+	}
+	if err := db.CreateTrustedScript(first); err != nil {
+		t.Fatalf("CreateTrustedScript failed: %v", err)
+	}
+	if first.ID == "" || first.CreatedAt.IsZero() {
+		t.Fatalf("expected ID and CreatedAt to be set, got %+v", first)
+	}
+
+	active, err := db.ActiveTrustedScript("/test/project", "ops/restart.sh")
+	if err != nil {
+		t.Fatalf("ActiveTrustedScript failed: %v", err)
+	}
+	if active.ID != first.ID || active.Comment != "vetted" || !active.Active() {
+		t.Errorf("unexpected active entry %+v", active)
+	}
+
+	second := &TrustedScript{
+		ProjectPath: "/test/project",
+		Path:        "ops/restart.sh",
+		SHA256:      "bbbb",
+		SessionID:   "sess-1",
+		AgentName:   "GreenLake",
+	}
+	if err := db.CreateTrustedScript(second); err != nil {
+		t.Fatalf("CreateTrustedScript (second) failed: %v", err)
+	}
+	superseded, err := db.GetTrustedScript(first.ID)
+	if err != nil {
+		t.Fatalf("GetTrustedScript failed: %v", err)
+	}
+	if superseded.Active() || superseded.RevokedBy != second.ID {
+		t.Errorf("expected first entry superseded by %s, got %+v", second.ID, superseded)
+	}
+
+	list, err := db.ListTrustedScripts("/test/project", false)
+	if err != nil {
+		t.Fatalf("ListTrustedScripts failed: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != second.ID {
+		t.Errorf("expected only the second entry to be active, got %d entries", len(list))
+	}
+	all, err := db.ListTrustedScripts("/test/project", true)
+	if err != nil {
+		t.Fatalf("ListTrustedScripts(all) failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("expected 2 entries including revoked, got %d", len(all))
+	}
+
+	if err := db.RevokeTrustedScript(second.ID, "BlueDog", time.Now()); err != nil {
+		t.Fatalf("RevokeTrustedScript failed: %v", err)
+	}
+	if _, err := db.ActiveTrustedScript("/test/project", "ops/restart.sh"); !errors.Is(err, ErrTrustedScriptNotFound) {
+		t.Errorf("expected ErrTrustedScriptNotFound after revoke, got %v", err)
+	}
+	if err := db.RevokeTrustedScript(second.ID, "BlueDog", time.Now()); !errors.Is(err, ErrTrustedScriptNotFound) {
+		t.Errorf("expected revoking twice to fail, got %v", err)
+	}
+}
+
+func TestTrustedScripts_Validation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if err := db.CreateTrustedScript(&TrustedScript{ProjectPath: "/p", SHA256: "aa"}); err == nil {
+		t.Error("expected an error without a path")
+	}
+	if err := db.CreateTrustedScript(&TrustedScript{ProjectPath: "/p", Path: "x.sh"}); err == nil {
+		t.Error("expected an error without a hash")
+	}
+	if _, err := db.GetTrustedScript("missing"); !errors.Is(err, ErrTrustedScriptNotFound) {
+		t.Errorf("expected ErrTrustedScriptNotFound, got %v", err)
+	}
+}
+
+func TestTrustedScriptUse(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, req := createTestRequest(t, db)
+	if use, err := db.GetTrustedScriptUse(req.ID); use != nil || err != nil {
+		t.Fatalf("expected no use before recording, got %+v, %v", use, err)
+	}
+
+	entry := &TrustedScript{ProjectPath: "/test/project", Path: "x.sh", SHA256: "aaaa", SessionID: "s", AgentName: "a"}
+	if err := db.CreateTrustedScript(entry); err != nil {
+		t.Fatalf("CreateTrustedScript failed: %v", err)
+	}
+	want := &TrustedScriptUse{RequestID: req.ID, TrustID: entry.ID, SHA256: "aaaa", FromTier: RiskTierDangerous, ToTier: RiskTierCaution}
+	if err := db.RecordTrustedScriptUse(want); err != nil {
+		t.Fatalf("RecordTrustedScriptUse failed: %v", err)
+	}
+	got, err := db.GetTrustedScriptUse(req.ID)
+	if err != nil {
+		t.Fatalf("GetTrustedScriptUse failed: %v", err)
+	}
+	if *got != *want {
+		t.Errorf("GetTrustedScriptUse = %+v, want %+v", got, want)
+	}
+}
+
+func TestTrustSignature(t *testing.T) {
+	key := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry := &TrustedScript{ProjectPath: "/p", Path: "x.sh", SHA256: "aaaa", CreatedAt: at}
+	entry.Signature = ComputeTrustSignature(key, entry.ProjectPath, entry.Path, entry.SHA256, at)
+
+	if !VerifyTrustSignature(key, entry) {
+		t.Fatal("expected signature to verify")
+	}
+	entry.SHA256 = "bbbb"
+	if VerifyTrustSignature(key, entry) {
+		t.Error("expected signature to fail after the hash changed")
+	}
+}
@@ -18,21 +18,46 @@ type Session struct {
 	Model string `json:"model"`
 	// ProjectPath is the absolute path to the project.
 	ProjectPath string `json:"project_path"`
+	// Hostname is the host the session was started on.
+	Hostname string `json:"hostname,omitempty"`
+	// MachineID identifies the machine more reliably than Hostname where
+	// the OS provides one (e.g. /etc/machine-id); empty otherwise.
+	MachineID string `json:"machine_id,omitempty"`
 	// SessionKey is the HMAC key for signing (not serialized in JSON).
 	SessionKey string `json:"-"`
 	// StartedAt is when the session was started.
 	StartedAt time.Time `json:"started_at"`
-	// LastActiveAt is when the session was last active.
+	// LastActiveAt is when the session was last seen: started, resumed,
+	// heartbeated, or used to submit a request or review.
 	LastActiveAt time.Time `json:"last_active_at"`
 	// EndedAt is when the session ended (nil if still active).
 	EndedAt *time.Time `json:"ended_at,omitempty"`
 }
 
+// HumanProgram is the session program that marks a session as driven by a
+// human at a terminal. Policy attestations require such a session.
+const HumanProgram = "human"
+
+// IsHuman reports whether the session is marked as human-driven.
+func (s *Session) IsHuman() bool {
+	return s.Program == HumanProgram
+}
+
 // IsActive returns true if the session is still active.
 func (s *Session) IsActive() bool {
 	return s.EndedAt == nil
 }
 
+// IsLive reports whether the session is active and, when idle is positive,
+// was last seen within idle of now. The daemon ends sessions that stay idle
+// longer than that; IsLive also covers the time between its sweeps.
+func (s *Session) IsLive(idle time.Duration, now time.Time) bool {
+	if !s.IsActive() {
+		return false
+	}
+	return idle <= 0 || now.Sub(s.LastActiveAt) <= idle
+}
+
 // CommandSpec represents the command to be executed.
 type CommandSpec struct {
 	// Raw is exactly what the agent requested.
@@ -49,6 +74,15 @@ type CommandSpec struct {
 	DisplayRedacted string `json:"display_redacted,omitempty"`
 	// ContainsSensitive indicates if the command contains sensitive data.
 	ContainsSensitive bool `json:"contains_sensitive"`
+	// NormalizedSegments are the normalized command segments computed once at
+	// creation; they drive the similarity index and display.
+	NormalizedSegments []string `json:"normalized_segments,omitempty"`
+	// Summary is a short, redaction-safe description of the command
+	// (e.g. "cd; rm -rf"), computed at creation.
+	Summary string `json:"summary,omitempty"`
+	// MigrationsDigest binds the migration files a migration runner will
+	// apply into Hash (see MigrationSet). Empty for other commands.
+	MigrationsDigest string `json:"migrations_digest,omitempty"`
 }
 
 // Justification provides the reasoning for a command request.
@@ -97,6 +131,83 @@ type Execution struct {
 	ExitCode *int `json:"exit_code,omitempty"`
 	// DurationMs is the execution duration in milliseconds.
 	DurationMs *int64 `json:"duration_ms,omitempty"`
+	// Stdout and Stderr are the command's output streams, each truncated
+	// on its own; LogPath holds the full interleaved output.
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+	// StdoutTruncated and StderrTruncated report whether that stream was
+	// cut short.
+	StdoutTruncated bool `json:"stdout_truncated,omitempty"`
+	StderrTruncated bool `json:"stderr_truncated,omitempty"`
+	// ContextPinning records how the pinned context was applied (e.g. the
+	// injected flags/env), or why the command ran unpinned.
+	ContextPinning string `json:"context_pinning,omitempty"`
+	// Segments holds per-segment results when only some segments of a
+	// compound command were approved and run as separate executions.
+	Segments []SegmentExecution `json:"segments,omitempty"`
+}
+
+// SegmentExecution is the result of running one segment of a partially
+// approved compound command.
+type SegmentExecution struct {
+	// Index is the 1-based segment number.
+	Index int `json:"index"`
+	// Command is the segment that was run.
+	Command string `json:"command"`
+	// ExitCode is the segment's exit code (nil if it did not run to completion).
+	ExitCode *int `json:"exit_code,omitempty"`
+	// DurationMs is the segment's execution duration in milliseconds.
+	DurationMs int64 `json:"duration_ms"`
+	// Skipped is set when an earlier segment failed and this one was not run.
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// Pinned context command families.
+const (
+	ContextFamilyKubectl = "kubectl"
+	ContextFamilyAWS     = "aws"
+	ContextFamilyGCloud  = "gcloud"
+)
+
+// PinnedContext is the cluster/cloud context a command was approved against.
+type PinnedContext struct {
+	// Family is the command family (kubectl, aws, gcloud).
+	Family string `json:"family"`
+	// KubeContext is the kubectl context.
+	KubeContext string `json:"kube_context,omitempty"`
+	// KubeNamespace is the kubectl namespace.
+	KubeNamespace string `json:"kube_namespace,omitempty"`
+	// AWSProfile is the AWS CLI profile.
+	AWSProfile string `json:"aws_profile,omitempty"`
+	// GCloudConfig is the active gcloud named configuration.
+	GCloudConfig string `json:"gcloud_config,omitempty"`
+	// GCloudProject is the gcloud project.
+	GCloudProject string `json:"gcloud_project,omitempty"`
+}
+
+// MigrationSet is the set of migration files a migration-runner command
+// (alembic upgrade, flyway migrate, migrate up) was approved against.
+type MigrationSet struct {
+	// Runner is the migration tool (alembic, flyway, golang-migrate).
+	Runner string `json:"runner"`
+	// Patterns are the globs, relative to the command's cwd, that located the files.
+	Patterns []string `json:"patterns"`
+	// Files are the matched files in path order.
+	Files []MigrationFile `json:"files"`
+	// Digest covers Patterns and every file's path and hash.
+	Digest string `json:"digest"`
+}
+
+// MigrationFile is one migration file bound to a request.
+type MigrationFile struct {
+	// Path is relative to the command's cwd.
+	Path string `json:"path"`
+	// SHA256 is the hex digest of the file contents.
+	SHA256 string `json:"sha256"`
+	// Size is the file size in bytes.
+	Size int64 `json:"size"`
+	// Destructive lists the destructive-SQL patterns the file matched.
+	Destructive []string `json:"destructive,omitempty"`
 }
 
 // Rollback contains information about rollback state.
@@ -105,6 +216,9 @@ type Rollback struct {
 	Path string `json:"path,omitempty"`
 	// RolledBackAt is when the rollback was performed.
 	RolledBackAt *time.Time `json:"rolled_back_at,omitempty"`
+	// Pending is set while execution is deferred because the rollback
+	// capture limit was reached.
+	Pending bool `json:"pending,omitempty"`
 }
 
 // Request represents a command request submitted for approval.
@@ -117,6 +231,15 @@ type Request struct {
 	Command CommandSpec `json:"command"`
 	// RiskTier is the risk classification.
 	RiskTier RiskTier `json:"risk_tier"`
+	// TierReason explains why the command was classified at RiskTier.
+	TierReason string `json:"tier_reason,omitempty"`
+	// Labels are arbitrary key/value annotations (e.g. env=prod) used for
+	// history filtering and notification routing.
+	Labels map[string]string `json:"labels,omitempty"`
+	// ApprovedSegments lists the 1-based segments of a compound command
+	// cleared for execution by a partial approval. Empty means the whole
+	// command was approved.
+	ApprovedSegments []int `json:"approved_segments,omitempty"`
 
 	// Requestor is the session ID that submitted the request.
 	RequestorSessionID string `json:"requestor_session_id"`
@@ -124,6 +247,9 @@ type Request struct {
 	RequestorAgent string `json:"requestor_agent"`
 	// RequestorModel is the model that submitted the request.
 	RequestorModel string `json:"requestor_model"`
+	// RequestorProgram is the program of the session that submitted the
+	// request.
+	RequestorProgram string `json:"requestor_program,omitempty"`
 
 	// Justification is the reasoning for the request.
 	Justification Justification `json:"justification"`
@@ -134,12 +260,43 @@ type Request struct {
 	// Attachments contains additional context.
 	Attachments []Attachment `json:"attachments,omitempty"`
 
+	// PinnedContext is the cluster/cloud context captured at request time.
+	PinnedContext *PinnedContext `json:"pinned_context,omitempty"`
+
+	// Migrations are the migration files bound to a migration-runner command.
+	Migrations *MigrationSet `json:"migrations,omitempty"`
+
 	// Status is the current request status.
 	Status RequestStatus `json:"status"`
 	// MinApprovals is the minimum approvals required.
 	MinApprovals int `json:"min_approvals"`
 	// RequireDifferentModel requires a different model for approval.
 	RequireDifferentModel bool `json:"require_different_model"`
+	// RequireDifferentHost only counts approvals from sessions on a
+	// different host than the requestor's.
+	RequireDifferentHost bool `json:"require_different_host,omitempty"`
+	// RequireDifferentProgram only accepts approvals from sessions running
+	// a different program than the requestor's, whatever their model.
+	RequireDifferentProgram bool `json:"require_different_program,omitempty"`
+	// RequiredRoles are reviewer roles that must each contribute at least
+	// one approval, on top of MinApprovals.
+	RequiredRoles []string `json:"required_roles,omitempty"`
+	// TimeoutSecs is how long the requestor waits for a decision, after
+	// the tier's timeout bounds were applied (0 if not given).
+	TimeoutSecs int `json:"timeout_secs,omitempty"`
+	// TimeoutRequestedSecs is the timeout the requestor asked for; it
+	// differs from TimeoutSecs when the bounds clamped it.
+	TimeoutRequestedSecs int `json:"timeout_requested_secs,omitempty"`
+	// ReviewRound counts how often the request went back to pending after
+	// its approval expired. Only reviews from the current round count
+	// toward the quorum.
+	ReviewRound int `json:"review_round,omitempty"`
+	// CampaignID is the campaign the request belongs to, if any.
+	CampaignID string `json:"campaign_id,omitempty"`
+	// CancelReason is why the request was cancelled, as given by
+	// CancelledBy, the agent that cancelled it.
+	CancelReason string `json:"cancel_reason,omitempty"`
+	CancelledBy  string `json:"cancelled_by,omitempty"`
 
 	// Execution contains execution information.
 	Execution *Execution `json:"execution,omitempty"`
@@ -156,6 +313,12 @@ type Request struct {
 	ApprovalExpiresAt *time.Time `json:"approval_expires_at,omitempty"`
 }
 
+// TimeoutClamped reports whether the requestor's timeout was clamped to the
+// tier's bounds.
+func (r *Request) TimeoutClamped() bool {
+	return r.TimeoutSecs != r.TimeoutRequestedSecs
+}
+
 // IsExpired returns true if the request has expired.
 func (r *Request) IsExpired() bool {
 	if r.ExpiresAt == nil {
@@ -186,6 +349,19 @@ type ReviewResponse struct {
 	GoalResponse string `json:"goal_response,omitempty"`
 	// SafetyResponse is the response to the safety_argument field.
 	SafetyResponse string `json:"safety_response,omitempty"`
+	// EvidenceViewed records which evidence the reviewer opened before deciding.
+	EvidenceViewed []EvidenceView `json:"evidence_viewed,omitempty"`
+}
+
+// EvidenceView records a reviewer's interaction with one piece of request evidence.
+// Only interaction metadata is kept; nothing typed by the reviewer is recorded.
+type EvidenceView struct {
+	// Section identifies the evidence (e.g. "dry_run", "attachment:0").
+	Section string `json:"section"`
+	// OpenedAt is when the section was first opened.
+	OpenedAt time.Time `json:"opened_at"`
+	// DurationMs is the cumulative time the section was on screen.
+	DurationMs int64 `json:"duration_ms"`
 }
 
 // Review represents an approval or rejection of a request.
@@ -204,8 +380,17 @@ type Review struct {
 
 	// Decision is approve or reject.
 	Decision Decision `json:"decision"`
-	// Signature is HMAC(session_key, request_id + decision + timestamp).
+	// Segments, when set, limits Decision to these 1-based segments of a
+	// compound command; the remaining segments get the opposite decision.
+	Segments []int `json:"segments,omitempty"`
+	// Signature is HMAC(session_key, request_id + command_hash + decision + timestamp).
 	Signature string `json:"signature"`
+	// CommandHash is the hash of the command the reviewer signed over. It is
+	// empty for reviews given before command hashes were signed.
+	CommandHash string `json:"command_hash,omitempty"`
+	// DelegationID is the delegation the review was recorded under on the
+	// reviewer's behalf; empty for reviews given by hand.
+	DelegationID string `json:"delegation_id,omitempty"`
 	// SignatureTimestamp is included in the signature to prevent replay.
 	SignatureTimestamp time.Time `json:"signature_timestamp"`
 
@@ -213,6 +398,9 @@ type Review struct {
 	Responses ReviewResponse `json:"responses,omitempty"`
 	// Comments contains additional comments.
 	Comments string `json:"comments,omitempty"`
+	// Round is the request's review round the review was given in; reviews
+	// from earlier rounds stay on record but no longer count.
+	Round int `json:"round,omitempty"`
 
 	// CreatedAt is when the review was created.
 	CreatedAt time.Time `json:"created_at"`
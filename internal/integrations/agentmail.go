@@ -24,6 +24,35 @@ type AgentMailClient struct {
 	projectKey string
 	threadID   string
 	sender     string
+	routes     []LabelRoute
+}
+
+// LabelRoute sends notifications for requests whose labels match Selector
+// to Thread instead of the default thread.
+type LabelRoute struct {
+	Selector map[string]string
+	Thread   string
+}
+
+// ParseLabelRoutes parses routing rules of the form "env=prod,team=web:Thread".
+// Rules are tried in order; the first whose selector matches wins.
+func ParseLabelRoutes(rules []string) ([]LabelRoute, error) {
+	routes := make([]LabelRoute, 0, len(rules))
+	for _, rule := range rules {
+		idx := strings.LastIndex(rule, ":")
+		if idx < 0 || strings.TrimSpace(rule[idx+1:]) == "" {
+			return nil, fmt.Errorf("route %q: want labels:thread", rule)
+		}
+		selector, err := db.ParseLabels([]string{rule[:idx]})
+		if err != nil {
+			return nil, fmt.Errorf("route %q: %w", rule, err)
+		}
+		if len(selector) == 0 {
+			return nil, fmt.Errorf("route %q: no labels", rule)
+		}
+		routes = append(routes, LabelRoute{Selector: selector, Thread: strings.TrimSpace(rule[idx+1:])})
+	}
+	return routes, nil
 }
 
 // NewAgentMailClient constructs a client.
@@ -41,18 +70,39 @@ func NewAgentMailClient(projectKey, threadID, sender string) *AgentMailClient {
 	}
 }
 
+// WithRoutes sets label-based thread routing rules.
+func (c *AgentMailClient) WithRoutes(routes []LabelRoute) *AgentMailClient {
+	c.routes = routes
+	return c
+}
+
+// threadFor returns the thread for a request: the first matching route's
+// thread, or the default thread.
+func (c *AgentMailClient) threadFor(req *db.Request) string {
+	for _, route := range c.routes {
+		if db.MatchLabels(req.Labels, route.Selector) {
+			return route.Thread
+		}
+	}
+	return c.threadID
+}
+
 // NotifyNewRequest sends a notification when a request is created.
 func (c *AgentMailClient) NotifyNewRequest(req *db.Request) error {
 	subject := fmt.Sprintf("[SLB] %s: %s", strings.ToUpper(string(req.RiskTier)), truncate(req.Command.Raw, 60))
-	body := fmt.Sprintf("## Command Approval Request\n\n**ID**: %s\n**Risk**: %s\n**Command**: `%s`\n\n### Justification\n- Reason: %s\n- Expected: %s\n- Goal: %s\n- Safety: %s\n\n---\nTo review: `slb review %s`\nTo approve: `slb approve %s --session-id <your-session> --session-key <key>`\nTo reject: `slb reject %s --session-id <your-session> --session-key <key>`\n",
-		req.ID, req.RiskTier, safeDisplay(req),
+	labels := ""
+	if len(req.Labels) > 0 {
+		labels = fmt.Sprintf("**Labels**: %s\n", db.FormatLabels(req.Labels))
+	}
+	body := fmt.Sprintf("## Command Approval Request\n\n**ID**: %s\n**Risk**: %s\n%s**Command**: `%s`\n\n### Justification\n- Reason: %s\n- Expected: %s\n- Goal: %s\n- Safety: %s\n\n---\nTo review: `slb review %s`\nTo approve: `slb approve %s --session-id <your-session> --session-key <key>`\nTo reject: `slb reject %s --session-id <your-session> --session-key <key>`\n",
+		req.ID, req.RiskTier, labels, safeDisplay(req),
 		req.Justification.Reason,
 		req.Justification.ExpectedEffect,
 		req.Justification.Goal,
 		req.Justification.SafetyArgument,
 		req.ID, req.ID, req.ID,
 	)
-	return c.send(subject, body, importanceForTier(req.RiskTier))
+	return c.send(c.threadFor(req), subject, body, importanceForTier(req.RiskTier))
 }
 
 // NotifyRequestApproved sends a notification on approval.
@@ -60,7 +110,7 @@ func (c *AgentMailClient) NotifyRequestApproved(req *db.Request, review *db.Revi
 	subject := fmt.Sprintf("[SLB] APPROVED: %s", truncate(req.Command.Raw, 60))
 	body := fmt.Sprintf("Request %s approved by %s (%s) at %s\n\nCommand: `%s`\n",
 		req.ID, review.ReviewerAgent, review.ReviewerModel, review.CreatedAt.Format(time.RFC3339), safeDisplay(req))
-	return c.send(subject, body, ImportanceNormal)
+	return c.send(c.threadFor(req), subject, body, ImportanceNormal)
 }
 
 // NotifyRequestRejected sends a notification on rejection.
@@ -68,7 +118,7 @@ func (c *AgentMailClient) NotifyRequestRejected(req *db.Request, review *db.Revi
 	subject := fmt.Sprintf("[SLB] REJECTED: %s", truncate(req.Command.Raw, 60))
 	body := fmt.Sprintf("Request %s rejected by %s (%s) at %s\n\nComments: %s\nCommand: `%s`\n",
 		req.ID, review.ReviewerAgent, review.ReviewerModel, review.CreatedAt.Format(time.RFC3339), review.Comments, safeDisplay(req))
-	return c.send(subject, body, ImportanceNormal)
+	return c.send(c.threadFor(req), subject, body, ImportanceNormal)
 }
 
 // NotifyRequestExecuted sends a notification on execution completion.
@@ -88,7 +138,26 @@ func (c *AgentMailClient) NotifyRequestExecuted(req *db.Request, exec *db.Execut
 	}
 	body := fmt.Sprintf("Request %s executed by %s (%s) at %s\nExit code: %d\nLog: %s\nCommand: `%s`\n",
 		req.ID, byAgent, byModel, execTime, exitCode, logPath, safeDisplay(req))
-	return c.send(subject, body, ImportanceLow)
+	return c.send(c.threadFor(req), subject, body, ImportanceLow)
+}
+
+// NotifyReconfirmRequested sends each prior approver of a re-requested
+// request a message of its own asking them to re-confirm it.
+func (c *AgentMailClient) NotifyReconfirmRequested(req *db.Request, priorApprovals []*db.Review) error {
+	subject := fmt.Sprintf("[SLB] RE-CONFIRM: %s", truncate(req.Command.Raw, 60))
+	var errs []string
+	for _, review := range priorApprovals {
+		ago := time.Since(review.CreatedAt).Round(time.Minute)
+		body := fmt.Sprintf("You approved request %s %s ago. The approval expired before it was executed, and the requestor asked for it again.\n\nThe command is unchanged (hash %s verified).\nCommand: `%s`\n\n---\nTo re-confirm: `slb approve %s --session-id <your-session> --session-key <key>`\nTo reject: `slb reject %s --session-id <your-session> --session-key <key>`\n",
+			req.ID, ago, req.Command.Hash, safeDisplay(req), req.ID, req.ID)
+		if err := c.sendTo(review.ReviewerAgent, c.threadFor(req), subject, body, importanceForTier(req.RiskTier)); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
 }
 
 // RequestNotifier defines notification hooks for request lifecycle.
@@ -97,6 +166,10 @@ type RequestNotifier interface {
 	NotifyRequestApproved(req *db.Request, review *db.Review) error
 	NotifyRequestRejected(req *db.Request, review *db.Review) error
 	NotifyRequestExecuted(req *db.Request, exec *db.Execution, exitCode int) error
+	// NotifyReconfirmRequested asks the approvers of a request whose
+	// approval expired to re-confirm it; priorApprovals are their reviews
+	// from the round that expired.
+	NotifyReconfirmRequested(req *db.Request, priorApprovals []*db.Review) error
 }
 
 // NoopNotifier implements RequestNotifier and does nothing.
@@ -109,6 +182,9 @@ func (n NoopNotifier) NotifyRequestApproved(req *db.Request, review *db.Review)
 func (n NoopNotifier) NotifyRequestRejected(req *db.Request, review *db.Review) error {
 	return nil
 }
+func (n NoopNotifier) NotifyReconfirmRequested(req *db.Request, priorApprovals []*db.Review) error {
+	return nil
+}
 func (n NoopNotifier) NotifyRequestExecuted(req *db.Request, exec *db.Execution, exitCode int) error {
 	return nil
 }
@@ -143,16 +219,21 @@ func truncate(s string, max int) string {
 }
 
 // send uses the Agent Mail CLI if present; otherwise returns nil (best effort).
-func (c *AgentMailClient) send(subject, body, importance string) error {
+func (c *AgentMailClient) send(thread, subject, body, importance string) error {
+	return c.sendTo("SLB-Broadcast", thread, subject, body, importance)
+}
+
+// sendTo is send addressed to a single recipient.
+func (c *AgentMailClient) sendTo(to, thread, subject, body, importance string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "mcp-agent-mail", "send",
 		"--project", c.projectKey,
 		"--from", c.sender,
-		"--to", "SLB-Broadcast",
+		"--to", to,
 		"--subject", subject,
-		"--thread", c.threadID,
+		"--thread", thread,
 		"--importance", importance,
 		"--body-md", body,
 	)
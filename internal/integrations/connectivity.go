@@ -0,0 +1,101 @@
+// Package integrations implements connectivity checks for external integrations.
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// DefaultPingTimeout bounds a single integration's connectivity check.
+const DefaultPingTimeout = 3 * time.Second
+
+// agentMailBinary is the CLI AgentMailClient sends through.
+var agentMailBinary = "mcp-agent-mail"
+
+// Pinger is implemented by integrations that can report whether they are
+// reachable.
+type Pinger interface {
+	// Name identifies the integration in reports (e.g. "agent_mail").
+	Name() string
+	// Ping returns nil if the integration is reachable.
+	Ping(ctx context.Context) error
+}
+
+// PingResult is the outcome of one integration's connectivity check.
+type PingResult struct {
+	Name      string `json:"name"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// CheckConnectivity pings every integration concurrently, each bounded by
+// timeout (DefaultPingTimeout if <= 0), and returns results in input order.
+// It never fails: an integration that errors, hangs or panics is reported
+// unreachable.
+func CheckConnectivity(ctx context.Context, pingers []Pinger, timeout time.Duration) []PingResult {
+	if timeout <= 0 {
+		timeout = DefaultPingTimeout
+	}
+	results := make([]PingResult, len(pingers))
+	var wg sync.WaitGroup
+	for i, p := range pingers {
+		wg.Add(1)
+		go func(i int, p Pinger) {
+			defer wg.Done()
+			results[i] = ping(ctx, p, timeout)
+		}(i, p)
+	}
+	wg.Wait()
+	return results
+}
+
+// ping runs a single check, giving up at the timeout even if Ping ignores
+// its context.
+func ping(ctx context.Context, p Pinger, timeout time.Duration) PingResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("ping panicked: %v", r)
+			}
+		}()
+		done <- p.Ping(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("no response within %s", timeout)
+	}
+
+	result := PingResult{
+		Name:      p.Name(),
+		Reachable: err == nil,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Name implements Pinger.
+func (c *AgentMailClient) Name() string { return "agent_mail" }
+
+// Ping reports whether the Agent Mail CLI is installed. Sends silently do
+// nothing when it is missing, so this is the failure worth surfacing.
+func (c *AgentMailClient) Ping(ctx context.Context) error {
+	if _, err := exec.LookPath(agentMailBinary); err != nil {
+		return fmt.Errorf("%s not found on PATH", agentMailBinary)
+	}
+	return ctx.Err()
+}
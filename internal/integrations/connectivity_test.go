@@ -0,0 +1,61 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubPinger is an integration with a canned connectivity outcome.
+type stubPinger struct {
+	name string
+	err  error
+	hang bool
+}
+
+func (s stubPinger) Name() string { return s.name }
+
+func (s stubPinger) Ping(ctx context.Context) error {
+	if s.hang {
+		select {} // ignores ctx, like a stuck client
+	}
+	return s.err
+}
+
+func TestCheckConnectivity_ReportsEachIntegration(t *testing.T) {
+	pingers := []Pinger{
+		stubPinger{name: "up"},
+		stubPinger{name: "down", err: errors.New("connection refused")},
+		stubPinger{name: "stuck", hang: true},
+	}
+	results := CheckConnectivity(context.Background(), pingers, 50*time.Millisecond)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if r := results[0]; r.Name != "up" || !r.Reachable || r.Error != "" {
+		t.Errorf("up: %+v", r)
+	}
+	if r := results[1]; r.Name != "down" || r.Reachable || r.Error != "connection refused" {
+		t.Errorf("down: %+v", r)
+	}
+	if r := results[2]; r.Name != "stuck" || r.Reachable || r.Error == "" {
+		t.Errorf("stuck: %+v", r)
+	}
+}
+
+func TestAgentMailClient_Ping(t *testing.T) {
+	client := NewAgentMailClient("proj", "", "")
+
+	orig := agentMailBinary
+	t.Cleanup(func() { agentMailBinary = orig })
+
+	agentMailBinary = "slb-test-missing-agent-mail"
+	if err := client.Ping(context.Background()); err == nil {
+		t.Error("expected missing CLI to be unreachable")
+	}
+	agentMailBinary = "sh"
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("expected CLI on PATH to be reachable, got %v", err)
+	}
+}
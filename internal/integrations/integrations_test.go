@@ -317,7 +317,7 @@ func TestAgentMailClient_SendFailureSurface(t *testing.T) {
 	t.Setenv("PATH", dir)
 
 	c := NewAgentMailClient("/proj", "thread", "sender")
-	if err := c.send("subj", "body", ImportanceNormal); err == nil {
+	if err := c.send("thread", "subj", "body", ImportanceNormal); err == nil {
 		t.Fatalf("expected error")
 	}
 }
@@ -334,7 +334,7 @@ func TestAgentMailClient_SendNotFoundMessageIsIgnored(t *testing.T) {
 	t.Setenv("PATH", dir)
 
 	c := NewAgentMailClient("/proj", "thread", "sender")
-	if err := c.send("subj", "body", ImportanceNormal); err != nil {
+	if err := c.send("thread", "subj", "body", ImportanceNormal); err != nil {
 		t.Fatalf("expected nil for not found errors, got: %v", err)
 	}
 }
@@ -349,7 +349,36 @@ func TestAgentMailClient_SendSucceedsWhenCommandSucceeds(t *testing.T) {
 	t.Setenv("PATH", dir)
 
 	c := NewAgentMailClient("/proj", "thread", "sender")
-	if err := c.send("subj", "body", ImportanceNormal); err != nil {
+	if err := c.send("thread", "subj", "body", ImportanceNormal); err != nil {
 		t.Fatalf("expected nil, got: %v", err)
 	}
 }
+
+func TestParseLabelRoutesAndThreadFor(t *testing.T) {
+	routes, err := ParseLabelRoutes([]string{"env=prod,team=payments:Payments-Prod", "env=prod:Prod-Reviews"})
+	if err != nil {
+		t.Fatalf("ParseLabelRoutes: %v", err)
+	}
+	c := NewAgentMailClient("/proj", "", "").WithRoutes(routes)
+
+	cases := []struct {
+		labels map[string]string
+		want   string
+	}{
+		{map[string]string{"env": "prod", "team": "payments"}, "Payments-Prod"},
+		{map[string]string{"env": "prod", "team": "web"}, "Prod-Reviews"},
+		{map[string]string{"env": "staging"}, "SLB-Reviews"},
+		{nil, "SLB-Reviews"},
+	}
+	for _, tc := range cases {
+		if got := c.threadFor(&db.Request{Labels: tc.labels}); got != tc.want {
+			t.Errorf("threadFor(%v) = %q, want %q", tc.labels, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"env=prod", "env=prod:", "Env=prod:T", ":T"} {
+		if _, err := ParseLabelRoutes([]string{bad}); err == nil {
+			t.Errorf("ParseLabelRoutes(%q) expected error", bad)
+		}
+	}
+}
@@ -85,6 +85,15 @@ func WithModel(m string) SessionOption {
 	return func(s *db.Session) { s.Model = m }
 }
 
+// WithHost sets the session's hostname and machine ID, standing in for a
+// session started on another machine.
+func WithHost(hostname, machineID string) SessionOption {
+	return func(s *db.Session) {
+		s.Hostname = hostname
+		s.MachineID = machineID
+	}
+}
+
 // SessionWithProject sets project path.
 func SessionWithProject(path string) SessionOption {
 	return func(s *db.Session) { s.ProjectPath = path }
@@ -131,6 +140,11 @@ func WithDryRun(cmd, output string) RequestOption {
 	}
 }
 
+// WithRequireDifferentHost sets the require different host flag.
+func WithRequireDifferentHost(required bool) RequestOption {
+	return func(r *db.Request) { r.RequireDifferentHost = required }
+}
+
 // WithRequireDifferentModel sets the require different model flag.
 func WithRequireDifferentModel(required bool) RequestOption {
 	return func(r *db.Request) { r.RequireDifferentModel = required }
@@ -146,6 +160,21 @@ func WithMinApprovals(n int) RequestOption {
 	return func(r *db.Request) { r.MinApprovals = n }
 }
 
+// WithLabels sets request labels.
+func WithLabels(labels map[string]string) RequestOption {
+	return func(r *db.Request) { r.Labels = labels }
+}
+
+// WithCampaign adds the request to a campaign.
+func WithCampaign(campaignID string) RequestOption {
+	return func(r *db.Request) { r.CampaignID = campaignID }
+}
+
+// WithAttachments sets request attachments.
+func WithAttachments(attachments ...db.Attachment) RequestOption {
+	return func(r *db.Request) { r.Attachments = attachments }
+}
+
 // randHex returns a cryptographically random hex string for unique test IDs.
 func randHex(n int) string {
 	b := make([]byte, (n+1)/2) // Each byte produces 2 hex chars
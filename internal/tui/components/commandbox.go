@@ -2,6 +2,7 @@
 package components
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/Dicklesworthstone/slb/internal/tui/theme"
@@ -57,15 +58,20 @@ func (c *CommandBox) Render() string {
 
 	displayCmd = utils.SanitizeInput(displayCmd)
 
-	// Truncate if needed
-	if c.MaxWidth > 0 && len(displayCmd) > c.MaxWidth {
-		if c.MaxWidth > 3 {
-			displayCmd = displayCmd[:c.MaxWidth-3] + "..."
-		} else {
-			// For very small max widths, just truncate without ellipsis
-			displayCmd = displayCmd[:c.MaxWidth]
+	// Truncate each line if needed; multi-line commands (here-docs) keep
+	// their line breaks
+	lines := strings.Split(displayCmd, "\n")
+	for i, line := range lines {
+		if c.MaxWidth > 0 && len(line) > c.MaxWidth {
+			if c.MaxWidth > 3 {
+				lines[i] = line[:c.MaxWidth-3] + "..."
+			} else {
+				// For very small max widths, just truncate without ellipsis
+				lines[i] = line[:c.MaxWidth]
+			}
 		}
 	}
+	displayCmd = strings.Join(lines, "\n")
 
 	// Command style
 	cmdStyle := lipgloss.NewStyle().
@@ -81,6 +87,15 @@ func (c *CommandBox) Render() string {
 
 	content := cmdStyle.Render(displayCmd)
 
+	// Badge the line count of multi-line commands
+	if len(lines) > 1 {
+		badgeStyle := lipgloss.NewStyle().
+			Foreground(t.Base).
+			Background(t.Overlay0).
+			Padding(0, 1)
+		content = badgeStyle.Render(fmt.Sprintf("%d lines", len(lines))) + "\n" + content
+	}
+
 	// Add hint if enabled
 	if c.ShowHint {
 		hintStyle := lipgloss.NewStyle().
@@ -102,7 +117,7 @@ func (c *CommandBox) RenderCompact() string {
 		displayCmd = c.Redacted
 	}
 
-	displayCmd = utils.SanitizeInput(displayCmd)
+	displayCmd = CollapseLines(utils.SanitizeInput(displayCmd))
 
 	// Truncate more aggressively for compact view
 	maxLen := 40
@@ -135,6 +150,11 @@ func (c *CommandBox) RenderFull() string {
 	cmdStyle := lipgloss.NewStyle().
 		Foreground(t.Green)
 
+	if n := LineCount(displayCmd); n > 1 {
+		lines = append(lines, lipgloss.NewStyle().
+			Foreground(t.Subtext).
+			Render(fmt.Sprintf("%d lines", n)))
+	}
 	lines = append(lines, cmdStyle.Render(displayCmd))
 
 	// Show original vs redacted if different
@@ -155,3 +175,20 @@ func (c *CommandBox) RenderFull() string {
 
 	return boxStyle.Render(content)
 }
+
+// LineCount returns the number of lines in cmd (1 for a single-line command).
+func LineCount(cmd string) int {
+	return strings.Count(strings.TrimRight(cmd, "\n"), "\n") + 1
+}
+
+// CollapseLines renders a multi-line command on one line for list views:
+// its first line followed by a "[+N lines]" marker. Single-line commands are
+// returned unchanged.
+func CollapseLines(cmd string) string {
+	cmd = strings.TrimRight(cmd, "\n")
+	first, _, found := strings.Cut(cmd, "\n")
+	if !found {
+		return cmd
+	}
+	return fmt.Sprintf("%s [+%d lines]", strings.TrimRight(first, " \t"), LineCount(cmd)-1)
+}
@@ -877,6 +877,36 @@ func TestCommandBoxRenderFullWithDifferentRedacted(t *testing.T) {
 	}
 }
 
+func TestCommandBoxRenderMultiLine(t *testing.T) {
+	box := NewCommandBox("kubectl apply -f - <<EOF\nkind: ConfigMap\nEOF").WithMaxWidth(80)
+	result := box.Render()
+	if !strings.Contains(result, "3 lines") {
+		t.Error("Render should badge the line count of a multi-line command")
+	}
+	if !strings.Contains(result, "kind: ConfigMap") {
+		t.Error("Render should keep every line of the command")
+	}
+}
+
+func TestCollapseLines(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"ls -la", "ls -la"},
+		{"ls -la\n", "ls -la"},
+		{"cat <<EOF > f\nhello\nEOF", "cat <<EOF > f [+2 lines]"},
+	}
+	for _, tt := range tests {
+		if got := CollapseLines(tt.in); got != tt.want {
+			t.Errorf("CollapseLines(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if n := LineCount("a\nb\nc"); n != 3 {
+		t.Errorf("LineCount = %d, want 3", n)
+	}
+}
+
 // ============== Spinner Tests ==============
 
 func TestNewSpinner(t *testing.T) {
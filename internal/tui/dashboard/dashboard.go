@@ -62,6 +62,9 @@ type Model struct {
 
 	pendingSel int
 	pendingOff int
+	// pendingExpanded is the ID of the pending request whose multi-line
+	// command is shown in full; others show their first line only.
+	pendingExpanded string
 
 	activitySel int
 	activityOff int
@@ -134,6 +137,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "down", "j":
 			m.moveSelection(1)
 			return m, nil
+		case "e":
+			if m.focus == focusPending && m.pendingSel < len(m.pending) {
+				id := m.pending[m.pendingSel].ID
+				if m.pendingExpanded == id {
+					m.pendingExpanded = ""
+				} else {
+					m.pendingExpanded = id
+				}
+			}
+			return m, nil
 		case "m":
 			if m.OnPatterns != nil {
 				m.OnPatterns()
@@ -219,7 +232,7 @@ func (m Model) renderHeader() string {
 func (m Model) renderFooter() string {
 	th := theme.Current
 
-	hint := lipgloss.NewStyle().Foreground(th.Subtext).Render("[tab] focus  [↑/↓] navigate  [m] patterns  [h] history  [q] quit")
+	hint := lipgloss.NewStyle().Foreground(th.Subtext).Render("[tab] focus  [↑/↓] navigate  [e] expand  [m] patterns  [h] history  [q] quit")
 
 	right := ""
 	if !m.lastRefresh.IsZero() {
@@ -293,7 +306,13 @@ func (m Model) renderPendingPanel(width, height int) string {
 		r := m.pending[i]
 		emoji := theme.TierEmoji(r.Tier)
 		age := formatTimeAgo(r.CreatedAt)
-		label := fmt.Sprintf("%s %s  •  %s  •  %s", emoji, r.Command, r.Requestor, age)
+		expanded := r.ID == m.pendingExpanded
+		cmdLines := strings.Split(r.Command, "\n")
+		cmd := components.CollapseLines(r.Command)
+		if expanded {
+			cmd = cmdLines[0]
+		}
+		label := fmt.Sprintf("%s %s  •  %s  •  %s", emoji, cmd, r.Requestor, age)
 		label = truncateRunes(label, width-4)
 
 		style := lineStyle
@@ -301,6 +320,11 @@ func (m Model) renderPendingPanel(width, height int) string {
 			style = selectedStyle
 		}
 		lines = append(lines, style.Render(label))
+		if expanded {
+			for _, l := range cmdLines[1:] {
+				lines = append(lines, style.Render(truncateRunes("   "+l, width-4)))
+			}
+		}
 	}
 
 	if len(m.pending) == 0 {
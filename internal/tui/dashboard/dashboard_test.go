@@ -1027,3 +1027,34 @@ func TestLoadDataWithDisplayRedacted(t *testing.T) {
 		t.Errorf("expected command to be 'redacted cmd', got %q", pending[0].Command)
 	}
 }
+
+func TestPendingPanelExpandMultiLine(t *testing.T) {
+	m := New("")
+	m.width = 120
+	m.height = 24
+	m.ready = true
+	m.focus = focusPending
+	m.pending = []requestRow{
+		{ID: "req-1", Tier: "dangerous", Command: "bash <<EOF\nmake clean\nmake deploy\nEOF", Requestor: "Agent1", CreatedAt: time.Now()},
+	}
+
+	panel := m.renderPendingPanel(80, 12)
+	if !strings.Contains(panel, "[+3 lines]") || strings.Contains(panel, "make deploy") {
+		t.Errorf("expected collapsed command, got:\n%s", panel)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m = updated.(Model)
+	if m.pendingExpanded != "req-1" {
+		t.Fatalf("expected req-1 to be expanded, got %q", m.pendingExpanded)
+	}
+	panel = m.renderPendingPanel(80, 12)
+	if !strings.Contains(panel, "make deploy") {
+		t.Errorf("expected expanded command, got:\n%s", panel)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	if updated.(Model).pendingExpanded != "" {
+		t.Error("expected a second press to collapse the command")
+	}
+}
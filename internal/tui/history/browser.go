@@ -13,6 +13,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/tui/components"
 	"github.com/Dicklesworthstone/slb/internal/tui/theme"
@@ -36,7 +37,12 @@ type BrowserKeyMap struct {
 	Down         key.Binding
 	FilterTier   key.Binding
 	FilterStatus key.Binding
-	Export       key.Binding
+	// FilterCampaign cycles through the project's campaigns.
+	FilterCampaign key.Binding
+	// FilterSince cycles through the time windows.
+	FilterSince key.Binding
+	Export      key.Binding
+	Collapse    key.Binding
 }
 
 // DefaultBrowserKeyMap returns the default keybindings.
@@ -86,10 +92,22 @@ func DefaultBrowserKeyMap() BrowserKeyMap {
 			key.WithKeys("s"),
 			key.WithHelp("s", "status filter"),
 		),
+		FilterCampaign: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g", "campaign filter"),
+		),
+		FilterSince: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "time window"),
+		),
 		Export: key.NewBinding(
 			key.WithKeys("e"),
 			key.WithHelp("e", "export"),
 		),
+		Collapse: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "collapse duplicates"),
+		),
 	}
 }
 
@@ -97,11 +115,63 @@ func DefaultBrowserKeyMap() BrowserKeyMap {
 type HistoryRow struct {
 	ID        string
 	Command   string
+	Summary   string // stored at request creation; shown beside Command
 	Agent     string
 	Status    db.RequestStatus
 	Tier      db.RiskTier
 	CreatedAt time.Time
 	Request   *db.Request
+	// Delegated marks requests with a review given under an approval
+	// delegation rather than by hand.
+	Delegated bool
+
+	// Count is the number of requests represented by this row when duplicates
+	// are collapsed (0 or 1 for a plain row).
+	Count int
+	// Duplicates holds the collapsed rows, newest first, when Count > 1.
+	Duplicates []HistoryRow
+}
+
+// CollapseDuplicates groups consecutive rows that share a command hash into a
+// single row carrying the group size and the newest member's status.
+// Non-adjacent repeats are left as separate rows so ordering is preserved.
+func CollapseDuplicates(rows []HistoryRow) []HistoryRow {
+	result := make([]HistoryRow, 0, len(rows))
+	for i := 0; i < len(rows); {
+		j := i + 1
+		key := commandKey(rows[i])
+		for j < len(rows) && commandKey(rows[j]) == key {
+			j++
+		}
+		if j-i == 1 {
+			result = append(result, rows[i])
+			i = j
+			continue
+		}
+
+		members := make([]HistoryRow, j-i)
+		copy(members, rows[i:j])
+		latest := members[0]
+		for _, r := range members[1:] {
+			if r.CreatedAt.After(latest.CreatedAt) {
+				latest = r
+			}
+		}
+		latest.Count = len(members)
+		latest.Duplicates = members
+		result = append(result, latest)
+		i = j
+	}
+	return result
+}
+
+// commandKey returns the grouping key for a row: the stored command hash when
+// available, otherwise the displayed command text.
+func commandKey(row HistoryRow) string {
+	if row.Request != nil && row.Request.Command.Hash != "" {
+		return row.Request.Command.Hash
+	}
+	return row.Command
 }
 
 // Model is the Bubble Tea model for the history browser.
@@ -131,12 +201,20 @@ type Model struct {
 	searchQuery string
 
 	// Filters
-	filters Filters
+	filters   Filters
+	campaigns []*db.Campaign // the project's campaigns, for the campaign filter
+
+	// Duplicate collapsing
+	collapse bool
+	expanded map[string]bool
 
 	// Callbacks
 	OnBack   func()
 	OnSelect func(requestID string)
 
+	// exportNote reports the last export in the footer.
+	exportNote string
+
 	// Error state
 	lastErr     error
 	lastRefresh time.Time
@@ -149,10 +227,18 @@ type refreshMsg struct{}
 type dataMsg struct {
 	rows        []HistoryRow
 	totalCount  int
+	campaigns   []*db.Campaign
 	err         error
 	refreshedAt time.Time
 }
 
+// exportMsg reports the result of an export.
+type exportMsg struct {
+	path  string
+	count int
+	err   error
+}
+
 // New creates a new history browser model.
 func New(projectPath string) Model {
 	if projectPath == "" {
@@ -172,6 +258,7 @@ func New(projectPath string) Model {
 		searchInput: ti,
 		filters:     NewFilters(),
 		page:        0,
+		expanded:    make(map[string]bool),
 	}
 }
 
@@ -198,6 +285,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dataMsg:
 		m.rows = msg.rows
 		m.totalCount = msg.totalCount
+		m.campaigns = msg.campaigns
 		m.lastErr = msg.err
 		m.lastRefresh = msg.refreshedAt
 		m.pageCount = (m.totalCount + pageSize - 1) / pageSize
@@ -205,8 +293,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.pageCount = 1
 		}
 		// Clamp selection
-		if m.selectedIdx >= len(m.rows) {
-			m.selectedIdx = max(0, len(m.rows)-1)
+		if visible := len(m.visibleRows()); m.selectedIdx >= visible {
+			m.selectedIdx = max(0, visible-1)
+		}
+		return m, nil
+
+	case exportMsg:
+		if msg.err != nil {
+			m.exportNote = "Export failed: " + msg.err.Error()
+		} else {
+			m.exportNote = fmt.Sprintf("Exported %d to %s", msg.count, msg.path)
 		}
 		return m, nil
 
@@ -262,7 +358,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case key.Matches(msg, m.keyMap.Down):
-			if m.selectedIdx < len(m.rows)-1 {
+			if m.selectedIdx < len(m.visibleRows())-1 {
 				m.selectedIdx++
 			}
 			return m, nil
@@ -284,13 +380,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case key.Matches(msg, m.keyMap.Select):
-			if len(m.rows) > 0 && m.selectedIdx < len(m.rows) {
+			rows := m.visibleRows()
+			if len(rows) > 0 && m.selectedIdx < len(rows) {
+				row := rows[m.selectedIdx]
+				if row.Count > 1 {
+					// Expand the group in place instead of opening a request.
+					if m.expanded == nil {
+						m.expanded = make(map[string]bool)
+					}
+					m.expanded[row.ID] = true
+					return m, nil
+				}
 				if m.OnSelect != nil {
-					m.OnSelect(m.rows[m.selectedIdx].ID)
+					m.OnSelect(row.ID)
 				}
 			}
 			return m, nil
 
+		case key.Matches(msg, m.keyMap.Collapse):
+			m.collapse = !m.collapse
+			m.expanded = make(map[string]bool)
+			m.selectedIdx = 0
+			return m, nil
+
 		case key.Matches(msg, m.keyMap.FilterTier):
 			m.filters.CycleTier()
 			m.page = 0
@@ -302,6 +414,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.page = 0
 			m.selectedIdx = 0
 			return m, loadDataCmd(m.projectPath, m.searchQuery, m.filters, m.page)
+
+		case key.Matches(msg, m.keyMap.Export):
+			return m, exportCmd(m.projectPath, m.filters)
+
+		case key.Matches(msg, m.keyMap.FilterCampaign):
+			m.filters.CycleCampaign(m.campaigns)
+			m.page = 0
+			m.selectedIdx = 0
+			return m, loadDataCmd(m.projectPath, m.searchQuery, m.filters, m.page)
+
+		case key.Matches(msg, m.keyMap.FilterSince):
+			m.filters.CycleSince()
+			m.page = 0
+			m.selectedIdx = 0
+			return m, loadDataCmd(m.projectPath, m.searchQuery, m.filters, m.page)
 		}
 	}
 
@@ -380,8 +507,10 @@ func (m Model) renderSearchBar() string {
 	// Filter badges
 	tierBadge := m.filters.RenderTierBadge()
 	statusBadge := m.filters.RenderStatusBadge()
+	campaignBadge := m.filters.RenderCampaignBadge()
+	sinceBadge := m.filters.RenderSinceBadge()
 
-	filterSection := lipgloss.JoinHorizontal(lipgloss.Center, tierBadge, "  ", statusBadge)
+	filterSection := lipgloss.JoinHorizontal(lipgloss.Center, tierBadge, "  ", statusBadge, "  ", campaignBadge, "  ", sinceBadge)
 
 	return lipgloss.NewStyle().
 		Padding(1, 1).
@@ -397,27 +526,38 @@ func (m Model) renderTable() string {
 
 	columns := []components.Column{
 		{Header: "ID", Width: 10},
-		{Header: "Command", MinWidth: 20, MaxWidth: 50},
+		{Header: "Command", MinWidth: 20, MaxWidth: 44},
+		{Header: "Summary", MinWidth: 10, MaxWidth: 20},
 		{Header: "Agent", Width: 12},
-		{Header: "Status", Width: 10},
+		{Header: "Status", Width: 12},
 		{Header: "When", Width: 10},
 	}
 
+	visible := m.visibleRows()
+
 	var rows [][]string
-	for _, row := range m.rows {
-		cmd := row.Command
-		if len(cmd) > 47 {
-			cmd = cmd[:47] + "..."
+	for _, row := range visible {
+		cmd := components.CollapseLines(row.Command)
+		if row.Count > 1 {
+			cmd = fmt.Sprintf("(×%d) %s", row.Count, cmd)
+		}
+		if len(cmd) > 41 {
+			cmd = cmd[:41] + "..."
 		}
 
 		statusIcon := statusIcon(row.Status)
+		status := statusIcon + " " + statusShort(row.Status)
+		if row.Delegated {
+			status += " ⇄"
+		}
 		when := formatTimeAgo(row.CreatedAt)
 
 		rows = append(rows, []string{
 			shortID(row.ID),
 			cmd,
+			row.Summary,
 			row.Agent,
-			statusIcon + " " + statusShort(row.Status),
+			status,
 			when,
 		})
 	}
@@ -430,7 +570,7 @@ func (m Model) renderTable() string {
 	tableView := table.Render()
 
 	// Add empty state if no results
-	if len(m.rows) == 0 {
+	if len(visible) == 0 {
 		emptyStyle := lipgloss.NewStyle().
 			Foreground(th.Subtext).
 			Align(lipgloss.Center).
@@ -458,6 +598,10 @@ func (m Model) renderFooter() string {
 		"[/] search",
 		"[t] tier",
 		"[s] status",
+		"[g] campaign",
+		"[d] time",
+		"[c] collapse",
+		"[e] export",
 		"[←→] page",
 		"[enter] view",
 		"[esc] back",
@@ -471,6 +615,9 @@ func (m Model) renderFooter() string {
 	if m.totalCount > 0 {
 		stats = fmt.Sprintf("%d results", m.totalCount)
 	}
+	if m.exportNote != "" {
+		stats = m.exportNote
+	}
 	if m.lastErr != nil {
 		stats = "Error: " + m.lastErr.Error()
 	}
@@ -487,6 +634,24 @@ func (m Model) renderFooter() string {
 		Render(lipgloss.JoinHorizontal(lipgloss.Top, hint, spacer, statsStyled))
 }
 
+// visibleRows returns the rows as displayed, applying duplicate collapsing and
+// replacing expanded groups with their members.
+func (m Model) visibleRows() []HistoryRow {
+	if !m.collapse {
+		return m.rows
+	}
+	grouped := CollapseDuplicates(m.rows)
+	visible := make([]HistoryRow, 0, len(grouped))
+	for _, row := range grouped {
+		if row.Count > 1 && m.expanded[row.ID] {
+			visible = append(visible, row.Duplicates...)
+			continue
+		}
+		visible = append(visible, row)
+	}
+	return visible
+}
+
 // Helper functions
 
 func tickCmd() tea.Cmd {
@@ -498,9 +663,14 @@ func tickCmd() tea.Cmd {
 func loadDataCmd(projectPath, query string, filters Filters, page int) tea.Cmd {
 	return func() tea.Msg {
 		rows, total, err := loadHistoryData(projectPath, query, filters, page)
+		var campaigns []*db.Campaign
+		if err == nil {
+			campaigns, err = loadCampaigns(projectPath)
+		}
 		return dataMsg{
 			rows:        rows,
 			totalCount:  total,
+			campaigns:   campaigns,
 			err:         err,
 			refreshedAt: time.Now().UTC(),
 		}
@@ -519,12 +689,13 @@ func loadHistoryData(projectPath, query string, filters Filters, page int) ([]Hi
 	}
 	defer dbConn.Close()
 
-	// Build search query
+	// Build search query. Without one, the time window is applied in SQL.
+	since := filters.Since(time.Now())
 	var requests []*db.Request
 	if query != "" {
 		requests, err = dbConn.SearchRequests(query)
 	} else {
-		requests, err = dbConn.ListAllRequests(projectPath)
+		requests, err = dbConn.ListRequestsBetween(projectPath, since, time.Time{})
 	}
 	if err != nil {
 		return nil, 0, err
@@ -539,6 +710,12 @@ func loadHistoryData(projectPath, query string, filters Filters, page int) ([]Hi
 		if filters.StatusFilter != "" && string(r.Status) != filters.StatusFilter {
 			continue
 		}
+		if filters.CampaignFilter != "" && r.CampaignID != filters.CampaignFilter {
+			continue
+		}
+		if !since.IsZero() && r.CreatedAt.Before(since) {
+			continue
+		}
 		filtered = append(filtered, r)
 	}
 
@@ -564,17 +741,75 @@ func loadHistoryData(projectPath, query string, filters Filters, page int) ([]Hi
 		rows = append(rows, HistoryRow{
 			ID:        r.ID,
 			Command:   cmd,
+			Summary:   r.Command.Summary,
 			Agent:     r.RequestorAgent,
 			Status:    r.Status,
 			Tier:      r.RiskTier,
 			CreatedAt: r.CreatedAt,
 			Request:   r,
+			Delegated: hasDelegatedReview(dbConn, r.ID),
 		})
 	}
 
 	return rows, total, nil
 }
 
+// hasDelegatedReview reports whether any review of the request was given
+// under an approval delegation.
+func hasDelegatedReview(dbConn *db.DB, requestID string) bool {
+	reviews, err := dbConn.ListReviewsForRequest(requestID)
+	if err != nil {
+		return false
+	}
+	for _, r := range reviews {
+		if r.DelegationID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// exportCmd exports the project's history, narrowed by the tier, status and
+// time window filters, as JSONL to a timestamped file in the project's .slb directory.
+func exportCmd(projectPath string, filters Filters) tea.Cmd {
+	return func() tea.Msg {
+		dbPath := filepath.Join(projectPath, ".slb", "state.db")
+		dbConn, err := db.OpenWithOptions(dbPath, db.OpenOptions{
+			CreateIfNotExists: false,
+			InitSchema:        false,
+			ReadOnly:          true,
+		})
+		if err != nil {
+			return exportMsg{err: err}
+		}
+		defer dbConn.Close()
+
+		path, n, err := core.ExportHistoryFile(dbConn, projectPath, core.ExportFormatJSONL, db.RequestFilter{
+			ProjectPath: projectPath,
+			Status:      db.RequestStatus(filters.StatusFilter),
+			Tier:        db.RiskTier(filters.TierFilter),
+			Since:       filters.Since(time.Now()),
+		}, time.Now())
+		return exportMsg{path: path, count: n, err: err}
+	}
+}
+
+// loadCampaigns lists the project's campaigns, newest first.
+func loadCampaigns(projectPath string) ([]*db.Campaign, error) {
+	dbPath := filepath.Join(projectPath, ".slb", "state.db")
+	dbConn, err := db.OpenWithOptions(dbPath, db.OpenOptions{
+		CreateIfNotExists: false,
+		InitSchema:        false,
+		ReadOnly:          true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer dbConn.Close()
+
+	return dbConn.ListCampaigns(projectPath)
+}
+
 func shortID(id string) string {
 	if len(id) <= 8 {
 		return id
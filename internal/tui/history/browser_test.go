@@ -67,6 +67,9 @@ func TestDefaultBrowserKeyMap(t *testing.T) {
 	if len(km.Export.Keys()) == 0 {
 		t.Error("Export binding should have keys")
 	}
+	if len(km.Collapse.Keys()) == 0 {
+		t.Error("Collapse binding should have keys")
+	}
 }
 
 func TestBrowserModelInit(t *testing.T) {
@@ -487,6 +490,30 @@ func TestBrowserModelViewWithData(t *testing.T) {
 	}
 }
 
+func TestBrowserModelViewShowsSummaryBesideCommand(t *testing.T) {
+	m := New("")
+	m.ready = true
+	m.width = 120
+	m.height = 24
+	m.pageCount = 1
+	m.rows = []HistoryRow{
+		{ID: "REQ-001", Command: "kubectl delete namespace staging-environment-with-a-long-name", Summary: "kubectl delete", Agent: "TestAgent", Status: db.StatusPending, Tier: db.RiskTierCritical, CreatedAt: time.Now()},
+		{ID: "REQ-002", Command: "rm -rf ./build", Summary: "rm -rf", Agent: "TestAgent", Status: db.StatusPending, Tier: db.RiskTierDangerous, CreatedAt: time.Now()},
+	}
+	m.totalCount = 2
+
+	view := m.View()
+	if !strings.Contains(view, "kubectl delete namespace staging-") {
+		t.Errorf("long command should keep its truncated raw text:\n%s", view)
+	}
+	if !strings.Contains(view, "rm -rf ./build") {
+		t.Errorf("short command should render in full:\n%s", view)
+	}
+	if !strings.Contains(view, "Summary") || !strings.Contains(view, "... kubectl delete") {
+		t.Errorf("summary should render in its own column:\n%s", view)
+	}
+}
+
 func TestBrowserModelViewEmpty(t *testing.T) {
 	m := New("")
 	m.ready = true
@@ -706,6 +733,103 @@ func TestHistoryRow(t *testing.T) {
 	}
 }
 
+func TestCollapseDuplicatesGroupsIdentical(t *testing.T) {
+	now := time.Now()
+	rows := []HistoryRow{
+		{ID: "c", Command: "rm -rf ./build", Status: db.StatusPending, CreatedAt: now},
+		{ID: "b", Command: "rm -rf ./build", Status: db.StatusRejected, CreatedAt: now.Add(-time.Minute)},
+		{ID: "a", Command: "rm -rf ./build", Status: db.StatusExecuted, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+
+	got := CollapseDuplicates(rows)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 group, got %d", len(got))
+	}
+	if got[0].Count != 3 {
+		t.Errorf("expected count 3, got %d", got[0].Count)
+	}
+	if got[0].ID != "c" || got[0].Status != db.StatusPending {
+		t.Errorf("expected latest row c/pending, got %s/%s", got[0].ID, got[0].Status)
+	}
+	if len(got[0].Duplicates) != 3 {
+		t.Errorf("expected 3 duplicates, got %d", len(got[0].Duplicates))
+	}
+}
+
+func TestCollapseDuplicatesInterleaved(t *testing.T) {
+	rows := []HistoryRow{
+		{ID: "1", Command: "git reset --hard"},
+		{ID: "2", Command: "rm -rf ./build"},
+		{ID: "3", Command: "git reset --hard"},
+		{ID: "4", Command: "rm -rf ./build"},
+	}
+
+	got := CollapseDuplicates(rows)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(got))
+	}
+	for i, row := range got {
+		if row.Count > 1 {
+			t.Errorf("row %d should not be collapsed (count %d)", i, row.Count)
+		}
+		if row.ID != rows[i].ID {
+			t.Errorf("row %d: expected ID %s, got %s", i, rows[i].ID, row.ID)
+		}
+	}
+}
+
+func TestCollapseDuplicatesUsesCommandHash(t *testing.T) {
+	rows := []HistoryRow{
+		{ID: "1", Command: "[REDACTED]", Request: &db.Request{Command: db.CommandSpec{Hash: "aaa"}}},
+		{ID: "2", Command: "[REDACTED]", Request: &db.Request{Command: db.CommandSpec{Hash: "bbb"}}},
+	}
+
+	if got := CollapseDuplicates(rows); len(got) != 2 {
+		t.Errorf("rows with different hashes should not collapse, got %d rows", len(got))
+	}
+}
+
+func TestBrowserModelCollapseToggleAndExpand(t *testing.T) {
+	m := New("")
+	m.rows = []HistoryRow{
+		{ID: "2", Command: "rm -rf ./build"},
+		{ID: "1", Command: "rm -rf ./build"},
+		{ID: "0", Command: "git clean -fd"},
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	model := updated.(Model)
+	if !model.collapse {
+		t.Fatal("collapse should be enabled after pressing c")
+	}
+	if got := len(model.visibleRows()); got != 2 {
+		t.Fatalf("expected 2 visible rows when collapsed, got %d", got)
+	}
+
+	selected := ""
+	model.OnSelect = func(id string) { selected = id }
+
+	// Selecting a group expands it rather than opening a request.
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if selected != "" {
+		t.Errorf("selecting a group should not call OnSelect, got %q", selected)
+	}
+	if got := len(model.visibleRows()); got != 3 {
+		t.Fatalf("expected 3 visible rows after expanding, got %d", got)
+	}
+
+	// Toggling off restores the flat view.
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	model = updated.(Model)
+	if model.collapse {
+		t.Error("collapse should be disabled after pressing c again")
+	}
+	if got := len(model.visibleRows()); got != 3 {
+		t.Errorf("expected 3 visible rows when not collapsed, got %d", got)
+	}
+}
+
 func TestRenderHeader(t *testing.T) {
 	m := New("")
 	m.width = 80
@@ -869,6 +993,55 @@ func TestLoadHistoryData(t *testing.T) {
 	}
 }
 
+func TestLoadHistoryDataFlagsDelegatedReviews(t *testing.T) {
+	h := newTestHarness(t)
+
+	sess := createTestSession(t, h.db, h.projectPath)
+	reviewer := &db.Session{ID: "sess-" + randHex(6), AgentName: "Reviewer", Program: "test", Model: "test-model", ProjectPath: h.projectPath}
+	if err := h.db.CreateSession(reviewer); err != nil {
+		t.Fatal(err)
+	}
+	delegated := createTestRequest(t, h.db, sess, "make deploy", db.RiskTierCaution, db.StatusApproved)
+	manual := createTestRequest(t, h.db, sess, "make test", db.RiskTierCaution, db.StatusApproved)
+	for _, tc := range []struct {
+		req          *db.Request
+		delegationID string
+	}{{delegated, "deleg-1"}, {manual, ""}} {
+		if err := h.db.CreateReview(&db.Review{
+			RequestID:         tc.req.ID,
+			ReviewerSessionID: reviewer.ID,
+			ReviewerAgent:     reviewer.AgentName,
+			ReviewerModel:     reviewer.Model,
+			Decision:          db.DecisionApprove,
+			Signature:         "sig",
+			DelegationID:      tc.delegationID,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, _, err := loadHistoryData(h.projectPath, "", Filters{}, 0)
+	if err != nil {
+		t.Fatalf("loadHistoryData failed: %v", err)
+	}
+	for _, row := range rows {
+		if want := row.ID == delegated.ID; row.Delegated != want {
+			t.Errorf("row %s (%s): Delegated = %v, want %v", row.ID, row.Command, row.Delegated, want)
+		}
+	}
+
+	m := New("")
+	m.ready = true
+	m.width = 120
+	m.height = 24
+	m.pageCount = 1
+	m.rows = rows
+	m.totalCount = len(rows)
+	if view := m.View(); !strings.Contains(view, "⇄") {
+		t.Errorf("expected the delegated row flagged:\n%s", view)
+	}
+}
+
 func TestLoadHistoryDataWithSearch(t *testing.T) {
 	h := newTestHarness(t)
 
@@ -933,6 +1106,44 @@ func TestLoadHistoryDataWithStatusFilter(t *testing.T) {
 	}
 }
 
+func TestLoadHistoryDataWithCampaignFilter(t *testing.T) {
+	h := newTestHarness(t)
+
+	sess := createTestSession(t, h.db, h.projectPath)
+	campaign := &db.Campaign{ProjectPath: h.projectPath, Name: "orders-v2"}
+	if err := h.db.CreateCampaign(campaign); err != nil {
+		t.Fatal(err)
+	}
+	member := createTestRequest(t, h.db, sess, "psql -f 001.sql", db.RiskTierDangerous, db.StatusPending)
+	if _, err := h.db.Exec(`UPDATE requests SET campaign_id = ? WHERE id = ?`, campaign.ID, member.ID); err != nil {
+		t.Fatal(err)
+	}
+	createTestRequest(t, h.db, sess, "rm -rf ./build", db.RiskTierDangerous, db.StatusPending)
+
+	rows, total, err := loadHistoryData(h.projectPath, "", Filters{CampaignFilter: campaign.ID}, 0)
+	if err != nil {
+		t.Fatalf("loadHistoryData with campaign filter failed: %v", err)
+	}
+	if total != 1 || len(rows) != 1 || rows[0].ID != member.ID {
+		t.Errorf("expected only the campaign member, got %d rows (total %d)", len(rows), total)
+	}
+
+	campaigns, err := loadCampaigns(h.projectPath)
+	if err != nil || len(campaigns) != 1 || campaigns[0].ID != campaign.ID {
+		t.Errorf("loadCampaigns = %v, %v", campaigns, err)
+	}
+
+	m := New(h.projectPath)
+	updated, _ := m.Update(dataMsg{campaigns: campaigns})
+	updated, cmd := updated.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	if got := updated.(Model).filters.CampaignFilter; got != campaign.ID {
+		t.Errorf("'g' should filter by the campaign, got %q", got)
+	}
+	if cmd == nil {
+		t.Error("should return data load command")
+	}
+}
+
 func TestLoadHistoryDataPagination(t *testing.T) {
 	h := newTestHarness(t)
 
@@ -1107,3 +1318,34 @@ func randHex(n int) string {
 	}
 	return hex.EncodeToString(b)[:n]
 }
+
+func TestExportKeyWritesFile(t *testing.T) {
+	h := newTestHarness(t)
+
+	sess := createTestSession(t, h.db, h.projectPath)
+	createTestRequest(t, h.db, sess, "rm -rf ./build", db.RiskTierDangerous, db.StatusExecuted)
+	createTestRequest(t, h.db, sess, "git push --force", db.RiskTierCritical, db.StatusPending)
+
+	m := New(h.projectPath)
+	m.filters.TierFilter = string(db.RiskTierCritical)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	if cmd == nil {
+		t.Fatal("expected the export key to start an export")
+	}
+	msg, ok := cmd().(exportMsg)
+	if !ok || msg.err != nil || msg.count != 1 {
+		t.Fatalf("unexpected export result %+v", msg)
+	}
+	if !strings.HasPrefix(msg.path, h.projectPath+"/.slb/history-") || !strings.HasSuffix(msg.path, ".jsonl") {
+		t.Errorf("unexpected export path %s", msg.path)
+	}
+	data, err := os.ReadFile(msg.path)
+	if err != nil || !strings.Contains(string(data), "git push --force") || strings.Contains(string(data), "rm -rf") {
+		t.Errorf("expected only the critical request in the export, got %q, %v", data, err)
+	}
+
+	updated, _ := m.Update(msg)
+	if note := updated.(Model).exportNote; !strings.Contains(note, msg.path) {
+		t.Errorf("expected the export path in the footer note, got %q", note)
+	}
+}
@@ -2,6 +2,9 @@
 package history
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/Dicklesworthstone/slb/internal/db"
@@ -29,12 +32,29 @@ var StatusOptions = []string{
 	string(db.StatusCancelled),
 }
 
+// SinceOptions are the available time window filter options: how far back
+// from now requests are shown.
+var SinceOptions = []time.Duration{
+	0, // All time
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+}
+
 // Filters represents the current filter state.
 type Filters struct {
 	TierFilter   string
 	StatusFilter string
-	tierIdx      int
-	statusIdx    int
+	// SinceFilter keeps requests created within this long of now; zero
+	// keeps all of them.
+	SinceFilter time.Duration
+	// CampaignFilter is the ID of the campaign to show, and CampaignName its
+	// name for the badge.
+	CampaignFilter string
+	CampaignName   string
+	tierIdx        int
+	statusIdx      int
+	sinceIdx       int
 }
 
 // NewFilters creates a new filter state with no filters applied.
@@ -59,6 +79,42 @@ func (f *Filters) CycleStatus() {
 	f.StatusFilter = StatusOptions[f.statusIdx]
 }
 
+// CycleSince cycles through time window filter options.
+func (f *Filters) CycleSince() {
+	f.sinceIdx = (f.sinceIdx + 1) % len(SinceOptions)
+	f.SinceFilter = SinceOptions[f.sinceIdx]
+}
+
+// Since returns the earliest creation time the time window keeps, or the
+// zero time when there is no window.
+func (f *Filters) Since(now time.Time) time.Time {
+	if f.SinceFilter <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-f.SinceFilter)
+}
+
+// CycleCampaign cycles through the given campaigns, then back to all
+// requests.
+func (f *Filters) CycleCampaign(campaigns []*db.Campaign) {
+	next := 0
+	if f.CampaignFilter != "" {
+		for i, c := range campaigns {
+			if c.ID == f.CampaignFilter {
+				next = i + 1
+				break
+			}
+		}
+	}
+	if next >= len(campaigns) {
+		f.CampaignFilter = ""
+		f.CampaignName = ""
+		return
+	}
+	f.CampaignFilter = campaigns[next].ID
+	f.CampaignName = campaigns[next].Name
+}
+
 // SetTier sets the tier filter.
 func (f *Filters) SetTier(tier string) {
 	f.TierFilter = tier
@@ -87,13 +143,17 @@ func (f *Filters) SetStatus(status string) {
 func (f *Filters) Clear() {
 	f.TierFilter = ""
 	f.StatusFilter = ""
+	f.CampaignFilter = ""
+	f.CampaignName = ""
+	f.SinceFilter = 0
 	f.tierIdx = 0
 	f.statusIdx = 0
+	f.sinceIdx = 0
 }
 
 // HasFilters returns true if any filter is active.
 func (f *Filters) HasFilters() bool {
-	return f.TierFilter != "" || f.StatusFilter != ""
+	return f.TierFilter != "" || f.StatusFilter != "" || f.CampaignFilter != "" || f.SinceFilter > 0
 }
 
 // RenderTierBadge renders the tier filter as a badge.
@@ -164,6 +224,61 @@ func (f *Filters) RenderStatusBadge() string {
 		Render(label)
 }
 
+// RenderCampaignBadge renders the campaign filter as a badge.
+func (f *Filters) RenderCampaignBadge() string {
+	th := theme.Current
+
+	label := "All Campaigns"
+	bg := th.Surface0
+	fg := th.Subtext
+
+	if f.CampaignFilter != "" {
+		label = f.CampaignName
+		if label == "" {
+			label = shortID(f.CampaignFilter)
+		}
+		bg = th.Mauve
+		fg = th.Base
+	}
+
+	return lipgloss.NewStyle().
+		Background(bg).
+		Foreground(fg).
+		Padding(0, 1).
+		Bold(f.CampaignFilter != "").
+		Render(label)
+}
+
+// RenderSinceBadge renders the time window filter as a badge.
+func (f *Filters) RenderSinceBadge() string {
+	th := theme.Current
+
+	label := "All Time"
+	bg := th.Surface0
+	fg := th.Subtext
+
+	if f.SinceFilter > 0 {
+		label = "Last " + sinceLabel(f.SinceFilter)
+		bg = th.Blue
+		fg = th.Base
+	}
+
+	return lipgloss.NewStyle().
+		Background(bg).
+		Foreground(fg).
+		Padding(0, 1).
+		Bold(f.SinceFilter > 0).
+		Render(label)
+}
+
+// sinceLabel formats a time window in whole days, or hours below a day.
+func sinceLabel(d time.Duration) string {
+	if d > 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return fmt.Sprintf("%dh", d/time.Hour)
+}
+
 // statusLabel returns a human-readable label for a status.
 func statusLabel(s db.RequestStatus) string {
 	switch s {
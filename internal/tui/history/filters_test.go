@@ -3,6 +3,7 @@ package history
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Dicklesworthstone/slb/internal/db"
 )
@@ -155,6 +156,76 @@ func TestHasFilters(t *testing.T) {
 	}
 }
 
+func TestCycleCampaign(t *testing.T) {
+	campaigns := []*db.Campaign{{ID: "c2", Name: "second"}, {ID: "c1", Name: "first"}}
+	f := NewFilters()
+
+	f.CycleCampaign(campaigns)
+	if f.CampaignFilter != "c2" || f.CampaignName != "second" || !f.HasFilters() {
+		t.Errorf("first cycle: got %q (%q)", f.CampaignFilter, f.CampaignName)
+	}
+	f.CycleCampaign(campaigns)
+	if f.CampaignFilter != "c1" {
+		t.Errorf("second cycle: got %q, want c1", f.CampaignFilter)
+	}
+	f.CycleCampaign(campaigns)
+	if f.CampaignFilter != "" || f.CampaignName != "" {
+		t.Errorf("expected the cycle to return to all campaigns, got %q", f.CampaignFilter)
+	}
+
+	f.CycleCampaign(nil)
+	if f.CampaignFilter != "" {
+		t.Errorf("expected no filter without campaigns, got %q", f.CampaignFilter)
+	}
+
+	f.CycleCampaign(campaigns)
+	if badge := f.RenderCampaignBadge(); !strings.Contains(badge, "second") {
+		t.Errorf("badge should name the campaign, got %q", badge)
+	}
+	f.Clear()
+	if f.CampaignFilter != "" || f.HasFilters() {
+		t.Error("Clear should drop the campaign filter")
+	}
+	if badge := f.RenderCampaignBadge(); !strings.Contains(badge, "All Campaigns") {
+		t.Errorf("unfiltered badge = %q", badge)
+	}
+}
+
+func TestCycleSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := NewFilters()
+	if !f.Since(now).IsZero() {
+		t.Errorf("default window should be unbounded, got %v", f.Since(now))
+	}
+
+	f.CycleSince()
+	if f.SinceFilter != 24*time.Hour || !f.HasFilters() {
+		t.Errorf("first cycle: got %v", f.SinceFilter)
+	}
+	if want := now.Add(-24 * time.Hour); !f.Since(now).Equal(want) {
+		t.Errorf("Since = %v, want %v", f.Since(now), want)
+	}
+	if badge := f.RenderSinceBadge(); !strings.Contains(badge, "Last 24h") {
+		t.Errorf("badge = %q", badge)
+	}
+
+	f.CycleSince()
+	f.CycleSince()
+	if badge := f.RenderSinceBadge(); !strings.Contains(badge, "Last 30d") {
+		t.Errorf("badge = %q", badge)
+	}
+	f.CycleSince()
+	if f.SinceFilter != 0 || !strings.Contains(f.RenderSinceBadge(), "All Time") {
+		t.Errorf("expected the cycle to return to all time, got %v", f.SinceFilter)
+	}
+
+	f.CycleSince()
+	f.Clear()
+	if f.SinceFilter != 0 || f.HasFilters() {
+		t.Error("Clear should drop the time window")
+	}
+}
+
 func TestRenderTierBadge(t *testing.T) {
 	tests := []struct {
 		tier     string
@@ -0,0 +1,809 @@
+// Package queue provides the interactive approval queue: the project's pending
+// requests, most urgent first, with a detail pane and approve/reject keys.
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/tui/components"
+	"github.com/Dicklesworthstone/slb/internal/tui/theme"
+)
+
+const (
+	defaultRefreshInterval = 5 * time.Second
+	// confirmWord must be typed to approve a critical request.
+	confirmWord = "yes"
+	// dryRunLines caps the dry-run output shown in the detail pane.
+	dryRunLines = 8
+)
+
+// KeyMap defines keybindings for the approval queue.
+type KeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Approve key.Binding
+	Reject  key.Binding
+	Refresh key.Binding
+	Submit  key.Binding
+	Cancel  key.Binding
+	Quit    key.Binding
+}
+
+// DefaultKeyMap returns the default keybindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓", "down"),
+		),
+		Approve: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "approve"),
+		),
+		Reject: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "reject"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("R", "ctrl+r"),
+			key.WithHelp("R", "refresh"),
+		),
+		Submit: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "submit"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "ctrl+c"),
+			key.WithHelp("q", "quit"),
+		),
+	}
+}
+
+// Options configures the approval queue.
+type Options struct {
+	ProjectPath string
+	// SessionID and SessionKey identify the reviewing session. Without them
+	// the queue is read-only.
+	SessionID  string
+	SessionKey string
+	// ReviewConfig returns the review settings for a request, including its
+	// unviewed-evidence action and reviewer anonymity. Nil uses
+	// core.DefaultReviewConfig.
+	ReviewConfig func(req *db.Request) (core.ReviewConfig, error)
+	// RefreshInterval is the polling interval; zero means five seconds.
+	RefreshInterval time.Duration
+	// Events, when set, delivers daemon events; each one reloads the queue
+	// without waiting for the next poll.
+	Events <-chan daemon.Event
+}
+
+// Item is a pending request with its review counts.
+type Item struct {
+	Request *db.Request
+	Status  *core.ReviewStatus
+}
+
+// inputMode is what the text input, if shown, is collecting.
+type inputMode int
+
+const (
+	modeBrowse inputMode = iota
+	modeConfirm
+	modeReject
+)
+
+// Model is the Bubble Tea model for the approval queue.
+type Model struct {
+	opts   Options
+	keyMap KeyMap
+
+	// View state
+	ready  bool
+	width  int
+	height int
+
+	// Data
+	items       []Item
+	selectedIdx int
+
+	// Input for the critical-tier confirmation and the rejection reason
+	mode  inputMode
+	input textinput.Model
+
+	// notice reports the outcome of the last review inline; noticeErr marks
+	// a failed one.
+	notice    string
+	noticeErr bool
+
+	// Error state
+	lastErr     error
+	lastRefresh time.Time
+}
+
+// refreshMsg triggers a data refresh.
+type refreshMsg struct{}
+
+// eventMsg reports a daemon event; closed is set once the stream ends.
+type eventMsg struct {
+	closed bool
+}
+
+// dataMsg contains loaded data.
+type dataMsg struct {
+	items       []Item
+	err         error
+	refreshedAt time.Time
+}
+
+// reviewMsg reports the outcome of a submitted review.
+type reviewMsg struct {
+	requestID string
+	decision  db.Decision
+	result    *core.ReviewResult
+	// unviewed is the evidence an approval was submitted without.
+	unviewed []string
+	err      error
+}
+
+// New creates a new approval queue model.
+func New(opts Options) Model {
+	if opts.ProjectPath == "" {
+		if pwd, err := os.Getwd(); err == nil {
+			opts.ProjectPath = pwd
+		}
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = defaultRefreshInterval
+	}
+
+	ti := textinput.New()
+	ti.CharLimit = 500
+	ti.Width = 50
+
+	return Model{
+		opts:   opts,
+		keyMap: DefaultKeyMap(),
+		input:  ti,
+	}
+}
+
+// Init initializes the model.
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(loadDataCmd(m.opts), m.tickCmd(), waitForEvent(m.opts.Events))
+}
+
+// Update handles messages.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+		m.input.Width = min(60, max(10, m.width-20))
+		return m, nil
+
+	case refreshMsg:
+		return m, tea.Batch(loadDataCmd(m.opts), m.tickCmd())
+
+	case eventMsg:
+		if msg.closed {
+			// The daemon went away; polling carries on.
+			return m, nil
+		}
+		return m, tea.Batch(loadDataCmd(m.opts), waitForEvent(m.opts.Events))
+
+	case dataMsg:
+		m.lastErr = msg.err
+		m.lastRefresh = msg.refreshedAt
+		if msg.err == nil {
+			m.items = msg.items
+		}
+		if m.selectedIdx >= len(m.items) {
+			m.selectedIdx = max(0, len(m.items)-1)
+		}
+		return m, nil
+
+	case reviewMsg:
+		m.notice, m.noticeErr = describeReview(msg), msg.err != nil
+		return m, loadDataCmd(m.opts)
+
+	case tea.KeyMsg:
+		if m.mode != modeBrowse {
+			return m.updateInput(msg)
+		}
+
+		switch {
+		case key.Matches(msg, m.keyMap.Quit):
+			return m, tea.Quit
+
+		case key.Matches(msg, m.keyMap.Up):
+			if m.selectedIdx > 0 {
+				m.selectedIdx--
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keyMap.Down):
+			if m.selectedIdx < len(m.items)-1 {
+				m.selectedIdx++
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keyMap.Refresh):
+			return m, loadDataCmd(m.opts)
+
+		case key.Matches(msg, m.keyMap.Approve):
+			req := m.selected()
+			if req == nil || !m.canReview() {
+				return m, nil
+			}
+			if req.RiskTier == db.RiskTierCritical {
+				return m.startInput(modeConfirm, fmt.Sprintf("type %q to approve this CRITICAL request", confirmWord))
+			}
+			return m, m.submitCmd(req, db.DecisionApprove, "")
+
+		case key.Matches(msg, m.keyMap.Reject):
+			if m.selected() == nil || !m.canReview() {
+				return m, nil
+			}
+			return m.startInput(modeReject, "reason for rejecting")
+		}
+	}
+
+	return m, nil
+}
+
+// updateInput handles keys while the confirmation or reason input is shown.
+func (m Model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Cancel):
+		m.stopInput()
+		return m, nil
+
+	case key.Matches(msg, m.keyMap.Submit):
+		mode, value := m.mode, strings.TrimSpace(m.input.Value())
+		m.stopInput()
+		req := m.selected()
+		if req == nil {
+			return m, nil
+		}
+		if mode == modeConfirm {
+			if !strings.EqualFold(value, confirmWord) {
+				m.notice, m.noticeErr = fmt.Sprintf("Approval of %s cancelled: type %q to confirm", shortID(req.ID), confirmWord), true
+				return m, nil
+			}
+			return m, m.submitCmd(req, db.DecisionApprove, "")
+		}
+		return m, m.submitCmd(req, db.DecisionReject, value)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m Model) startInput(mode inputMode, placeholder string) (tea.Model, tea.Cmd) {
+	m.mode = mode
+	m.input.SetValue("")
+	m.input.Placeholder = placeholder
+	m.input.Focus()
+	return m, textinput.Blink
+}
+
+func (m *Model) stopInput() {
+	m.mode = modeBrowse
+	m.input.SetValue("")
+	m.input.Blur()
+}
+
+// canReview reports whether the queue has a session to review with, setting
+// the notice when it does not.
+func (m *Model) canReview() bool {
+	if m.opts.SessionID != "" && m.opts.SessionKey != "" {
+		return true
+	}
+	m.notice, m.noticeErr = "Reviewing needs --session-id and --session-key", true
+	return false
+}
+
+// selected returns the selected request, or nil when the queue is empty.
+func (m Model) selected() *db.Request {
+	if m.selectedIdx < 0 || m.selectedIdx >= len(m.items) {
+		return nil
+	}
+	return m.items[m.selectedIdx].Request
+}
+
+// View renders the model.
+func (m Model) View() string {
+	if !m.ready {
+		return "Loading..."
+	}
+
+	th := theme.Current
+
+	bodyHeight := max(5, m.height-4)
+	listWidth := max(30, m.width*2/5)
+	detailWidth := max(20, m.width-listWidth-4)
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(listWidth).Height(bodyHeight).Padding(0, 1).Render(m.renderList(listWidth-2)),
+		lipgloss.NewStyle().Width(detailWidth).Height(bodyHeight).Padding(0, 1).
+			Border(lipgloss.NormalBorder(), false, false, false, true).
+			BorderForeground(th.Overlay0).
+			Render(m.renderDetail(detailWidth-3)),
+	)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		m.renderHeader(),
+		body,
+		m.renderStatusLine(),
+		m.renderFooter(),
+	)
+
+	return lipgloss.NewStyle().
+		Background(th.Base).
+		Width(m.width).
+		Height(m.height).
+		Render(content)
+}
+
+func (m Model) renderHeader() string {
+	th := theme.Current
+
+	title := lipgloss.NewStyle().
+		Foreground(th.Mauve).
+		Bold(true).
+		Render("Approval Queue")
+
+	count := lipgloss.NewStyle().
+		Foreground(th.Subtext).
+		Render(fmt.Sprintf("%d pending", len(m.items)))
+
+	spacer := lipgloss.NewStyle().
+		Width(max(0, m.width-lipgloss.Width(title)-lipgloss.Width(count)-4)).
+		Render("")
+
+	return lipgloss.NewStyle().
+		Background(th.Mantle).
+		Padding(0, 1).
+		Width(m.width).
+		Render(lipgloss.JoinHorizontal(lipgloss.Top, title, spacer, count))
+}
+
+func (m Model) renderList(width int) string {
+	th := theme.Current
+
+	if len(m.items) == 0 {
+		return lipgloss.NewStyle().
+			Foreground(th.Subtext).
+			Render("No pending requests")
+	}
+
+	columns := []components.Column{
+		{Header: "Tier", Width: 9},
+		{Header: "ID", Width: 8},
+		{Header: "Command", MinWidth: 12, MaxWidth: 40},
+		{Header: "Age", Width: 8},
+	}
+
+	rows := make([][]string, 0, len(m.items))
+	for _, item := range m.items {
+		r := item.Request
+		rows = append(rows, []string{
+			strings.ToUpper(string(r.RiskTier)),
+			shortID(r.ID),
+			components.CollapseLines(displayCommand(r)),
+			formatAge(r.CreatedAt),
+		})
+	}
+
+	return components.NewTable(columns).
+		WithRows(rows).
+		WithSelection(m.selectedIdx).
+		WithMaxWidth(width).
+		Render()
+}
+
+func (m Model) renderDetail(width int) string {
+	th := theme.Current
+
+	if len(m.items) == 0 {
+		return ""
+	}
+	item := m.items[m.selectedIdx]
+	r := item.Request
+
+	label := lipgloss.NewStyle().Foreground(th.Blue).Bold(true)
+	muted := lipgloss.NewStyle().Foreground(th.Subtext)
+	text := lipgloss.NewStyle().Foreground(th.Text).Width(width)
+
+	var b strings.Builder
+	section := func(title, body string) {
+		if strings.TrimSpace(body) == "" {
+			return
+		}
+		b.WriteString(label.Render(title) + "\n")
+		b.WriteString(text.Render(body) + "\n\n")
+	}
+
+	section("Command", displayCommand(r))
+	requestor := r.RequestorAgent
+	if r.RequestorModel != "" {
+		requestor += " (" + r.RequestorModel + ")"
+	}
+	b.WriteString(muted.Render(fmt.Sprintf("%s · %s · %s", strings.ToUpper(string(r.RiskTier)), requestor, formatAge(r.CreatedAt))) + "\n\n")
+
+	if item.Status != nil {
+		reviews := fmt.Sprintf("Approvals %d/%d · Rejections %d", item.Status.Approvals, item.Status.MinApprovals, item.Status.Rejections)
+		for _, u := range item.Status.UncountedApprovals {
+			reviews += "\n" + u
+		}
+		section("Reviews", reviews)
+	}
+
+	section("Reason", r.Justification.Reason)
+	section("Expected effect", r.Justification.ExpectedEffect)
+	section("Goal", r.Justification.Goal)
+	section("Safety", r.Justification.SafetyArgument)
+	section("Attachments", SummarizeAttachments(r.Attachments))
+	if r.DryRun != nil {
+		section("Dry run", "$ "+r.DryRun.Command+"\n"+truncateLines(r.DryRun.Output, dryRunLines))
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (m Model) renderStatusLine() string {
+	th := theme.Current
+
+	if m.mode != modeBrowse {
+		return lipgloss.NewStyle().
+			Padding(0, 1).
+			Width(m.width).
+			Render(m.input.View())
+	}
+
+	msg, color := m.notice, th.Green
+	if m.noticeErr {
+		color = th.Red
+	}
+	if m.lastErr != nil {
+		msg, color = "Error: "+m.lastErr.Error(), th.Red
+	}
+	return lipgloss.NewStyle().
+		Foreground(color).
+		Padding(0, 1).
+		Width(m.width).
+		Render(msg)
+}
+
+func (m Model) renderFooter() string {
+	th := theme.Current
+
+	keys := []string{"[↑↓] select", "[a] approve", "[r] reject", "[R] refresh", "[q] quit"}
+	if m.mode != modeBrowse {
+		keys = []string{"[enter] submit", "[esc] cancel"}
+	}
+	hint := lipgloss.NewStyle().
+		Foreground(th.Subtext).
+		Render(strings.Join(keys, "  "))
+
+	refreshed := ""
+	if !m.lastRefresh.IsZero() {
+		refreshed = "updated " + m.lastRefresh.Local().Format("15:04:05")
+	}
+	refreshedStyled := lipgloss.NewStyle().Foreground(th.Subtext).Render(refreshed)
+
+	spacer := lipgloss.NewStyle().
+		Width(max(0, m.width-lipgloss.Width(hint)-lipgloss.Width(refreshedStyled)-4)).
+		Render("")
+
+	return lipgloss.NewStyle().
+		Background(th.Mantle).
+		Padding(0, 1).
+		Width(m.width).
+		Render(lipgloss.JoinHorizontal(lipgloss.Top, hint, spacer, refreshedStyled))
+}
+
+// describeReview renders a review outcome for the status line. Refusals such
+// as ErrSelfReview and ErrAlreadyReviewed are reported, not fatal.
+func describeReview(msg reviewMsg) string {
+	id := shortID(msg.requestID)
+	verb := "Approval"
+	if msg.decision == db.DecisionReject {
+		verb = "Rejection"
+	}
+	if msg.err != nil {
+		switch {
+		case errors.Is(msg.err, core.ErrSelfReview):
+			return fmt.Sprintf("%s of %s refused: you cannot review your own request", verb, id)
+		case errors.Is(msg.err, core.ErrAlreadyReviewed):
+			return fmt.Sprintf("%s of %s refused: you have already reviewed it", verb, id)
+		case errors.Is(msg.err, core.ErrUnviewedEvidence):
+			return fmt.Sprintf("%s of %s refused: %v (inspect with 'slb show %s --with-attachments')", verb, id, msg.err, msg.requestID)
+		}
+		return fmt.Sprintf("%s of %s failed: %v", verb, id, msg.err)
+	}
+
+	r := msg.result
+	s := fmt.Sprintf("%s of %s recorded (approvals %d, rejections %d)", verb, id, r.Approvals, r.Rejections)
+	if r.RequestStatusChanged {
+		s += "; request is now " + string(r.NewRequestStatus)
+	}
+	if len(msg.unviewed) > 0 {
+		s += "; evidence not viewed: " + strings.Join(msg.unviewed, ", ")
+	}
+	return s
+}
+
+// Helper functions
+
+func (m Model) tickCmd() tea.Cmd {
+	return tea.Tick(m.opts.RefreshInterval, func(time.Time) tea.Msg {
+		return refreshMsg{}
+	})
+}
+
+// waitForEvent waits for the next daemon event; it is a no-op without a
+// subscription.
+func waitForEvent(events <-chan daemon.Event) tea.Cmd {
+	if events == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		_, ok := <-events
+		return eventMsg{closed: !ok}
+	}
+}
+
+func loadDataCmd(opts Options) tea.Cmd {
+	return func() tea.Msg {
+		items, err := loadQueue(opts)
+		return dataMsg{
+			items:       items,
+			err:         err,
+			refreshedAt: time.Now().UTC(),
+		}
+	}
+}
+
+// loadQueue lists the project's pending requests with their review counts,
+// most urgent first, under each request's review config and as the
+// reviewing session may see them.
+func loadQueue(opts Options) ([]Item, error) {
+	dbPath := filepath.Join(opts.ProjectPath, ".slb", "state.db")
+	dbConn, err := db.OpenWithOptions(dbPath, db.OpenOptions{
+		CreateIfNotExists: false,
+		InitSchema:        false,
+		ReadOnly:          true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer dbConn.Close()
+
+	requests, err := dbConn.ListPendingRequests(opts.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer := core.ResolveReviewViewer(dbConn, opts.SessionID)
+	items := make([]Item, 0, len(requests))
+	for _, r := range requests {
+		cfg, err := reviewConfig(opts, r)
+		if err != nil {
+			return nil, err
+		}
+		svc := core.NewReviewService(dbConn, cfg)
+		svc.SetViewer(viewer)
+		status, err := svc.GetReviewStatus(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, Item{Request: r, Status: status})
+	}
+	sortQueue(items)
+	return items, nil
+}
+
+// sortQueue orders items by tier, critical first, then oldest first.
+func sortQueue(items []Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Request, items[j].Request
+		if ra, rb := tierRank(a.RiskTier), tierRank(b.RiskTier); ra != rb {
+			return ra < rb
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+}
+
+func tierRank(tier db.RiskTier) int {
+	switch tier {
+	case db.RiskTierCritical:
+		return 0
+	case db.RiskTierDangerous:
+		return 1
+	case db.RiskTierCaution:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// submitCmd submits the session's review of req through the review service.
+func (m Model) submitCmd(req *db.Request, decision db.Decision, comments string) tea.Cmd {
+	opts := m.opts
+	return func() tea.Msg {
+		result, unviewed, err := submitReview(opts, req, decision, comments)
+		return reviewMsg{requestID: req.ID, decision: decision, result: result, unviewed: unviewed, err: err}
+	}
+}
+
+// reviewConfig returns the review settings for req.
+func reviewConfig(opts Options, req *db.Request) (core.ReviewConfig, error) {
+	if opts.ReviewConfig == nil {
+		return core.DefaultReviewConfig(), nil
+	}
+	return opts.ReviewConfig(req)
+}
+
+// submitReview records a review as 'slb approve' and 'slb reject' do: the
+// evidence the session viewed is attached, approvals pass the unviewed
+// evidence gate, and a review that decides the request is published to the
+// daemon. It returns the evidence the approval was submitted without.
+func submitReview(opts Options, req *db.Request, decision db.Decision, comments string) (*core.ReviewResult, []string, error) {
+	cfg, err := reviewConfig(opts, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dbPath := filepath.Join(opts.ProjectPath, ".slb", "state.db")
+	dbConn, err := db.OpenWithOptions(dbPath, db.OpenOptions{
+		CreateIfNotExists: false,
+		InitSchema:        false,
+		ReadOnly:          false,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer dbConn.Close()
+
+	// The views recorded by 'slb show' or the request detail view count, and
+	// so does the dry run the detail pane shows.
+	evidence, err := core.LoadEvidenceViews(req.ProjectPath, req.ID, opts.SessionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading evidence views: %w", err)
+	}
+	if req.DryRun != nil && req.DryRun.Output != "" {
+		evidence = core.MergeEvidenceViews(evidence, []db.EvidenceView{core.NewEvidenceView(core.EvidenceSectionDryRun, time.Now(), 0)})
+	}
+
+	svc := core.NewReviewService(dbConn, cfg)
+	var unviewed []string
+	if decision == db.DecisionApprove {
+		if unviewed, err = svc.CheckUnviewedEvidence(req, evidence, false); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	result, err := svc.SubmitReview(core.ReviewOptions{
+		SessionID:  opts.SessionID,
+		SessionKey: opts.SessionKey,
+		RequestID:  req.ID,
+		Decision:   decision,
+		Responses:  db.ReviewResponse{EvidenceViewed: evidence},
+		Comments:   comments,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	_ = core.ClearEvidenceViews(req.ProjectPath, req.ID, opts.SessionID)
+	daemon.PublishReviewOutcome(req, result, nil) // notices would corrupt the alt screen
+	return result, unviewed, nil
+}
+
+// SummarizeAttachments describes attachments by type and total size, e.g.
+// "3 attachments: file ×2, git_diff (4.1 KB)".
+func SummarizeAttachments(attachments []db.Attachment) string {
+	if len(attachments) == 0 {
+		return ""
+	}
+
+	var order []db.AttachmentType
+	counts := make(map[db.AttachmentType]int)
+	size := 0
+	for _, a := range attachments {
+		if counts[a.Type] == 0 {
+			order = append(order, a.Type)
+		}
+		counts[a.Type]++
+		size += len(a.Content)
+	}
+
+	parts := make([]string, 0, len(order))
+	for _, t := range order {
+		if counts[t] > 1 {
+			parts = append(parts, fmt.Sprintf("%s ×%d", t, counts[t]))
+		} else {
+			parts = append(parts, string(t))
+		}
+	}
+
+	noun := "attachments"
+	if len(attachments) == 1 {
+		noun = "attachment"
+	}
+	return fmt.Sprintf("%d %s: %s (%s)", len(attachments), noun, strings.Join(parts, ", "), formatSize(size))
+}
+
+func formatSize(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
+
+func displayCommand(r *db.Request) string {
+	if r.Command.DisplayRedacted != "" {
+		return r.Command.DisplayRedacted
+	}
+	return r.Command.Raw
+}
+
+func truncateLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) <= n {
+		return strings.Join(lines, "\n")
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n… %d more lines", len(lines)-n)
+}
+
+func shortID(id string) string {
+	if len(id) <= 8 {
+		return id
+	}
+	return id[:8]
+}
+
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "?"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,317 @@
+package queue
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+	"github.com/Dicklesworthstone/slb/internal/testutil"
+)
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func enter() tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyEnter}
+}
+
+// update applies msg and returns the resulting model.
+func update(t *testing.T, m Model, msg tea.Msg) (Model, tea.Cmd) {
+	t.Helper()
+	next, cmd := m.Update(msg)
+	return next.(Model), cmd
+}
+
+// typeText types s into the model's input.
+func typeText(t *testing.T, m Model, s string) Model {
+	t.Helper()
+	for _, r := range s {
+		m, _ = update(t, m, runes(string(r)))
+	}
+	return m
+}
+
+func TestDefaultKeyMap(t *testing.T) {
+	km := DefaultKeyMap()
+	for name, b := range map[string][]string{
+		"Up": km.Up.Keys(), "Down": km.Down.Keys(), "Approve": km.Approve.Keys(),
+		"Reject": km.Reject.Keys(), "Submit": km.Submit.Keys(), "Cancel": km.Cancel.Keys(), "Quit": km.Quit.Keys(),
+	} {
+		if len(b) == 0 {
+			t.Errorf("%s binding should have keys", name)
+		}
+	}
+	if km.Approve.Keys()[0] != "a" || km.Reject.Keys()[0] != "r" {
+		t.Errorf("approve/reject bound to %v/%v", km.Approve.Keys(), km.Reject.Keys())
+	}
+}
+
+func TestSortQueue(t *testing.T) {
+	now := time.Now()
+	item := func(id string, tier db.RiskTier, age time.Duration) Item {
+		return Item{Request: &db.Request{ID: id, RiskTier: tier, CreatedAt: now.Add(-age)}}
+	}
+	items := []Item{
+		item("caution-old", db.RiskTierCaution, time.Hour),
+		item("dangerous-new", db.RiskTierDangerous, time.Minute),
+		item("critical-new", db.RiskTierCritical, time.Minute),
+		item("dangerous-old", db.RiskTierDangerous, time.Hour),
+		item("critical-old", db.RiskTierCritical, time.Hour),
+	}
+	sortQueue(items)
+
+	want := []string{"critical-old", "critical-new", "dangerous-old", "dangerous-new", "caution-old"}
+	for i, id := range want {
+		if items[i].Request.ID != id {
+			t.Fatalf("position %d: got %s, want %s", i, items[i].Request.ID, id)
+		}
+	}
+}
+
+func TestLoadQueue(t *testing.T) {
+	h := testutil.NewHarness(t)
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+
+	dangerous := testutil.MakeRequest(t, h.DB, requestor, testutil.WithRisk(db.RiskTierDangerous), testutil.WithMinApprovals(2))
+	critical := testutil.MakeRequest(t, h.DB, requestor, testutil.WithRisk(db.RiskTierCritical))
+	testutil.MakeRequest(t, h.DB, requestor, testutil.WithStatus(db.StatusApproved))
+
+	if _, err := core.NewReviewService(h.DB, core.DefaultReviewConfig()).SubmitReview(core.ReviewOptions{
+		SessionID:  reviewer.ID,
+		SessionKey: reviewer.SessionKey,
+		RequestID:  dangerous.ID,
+		Decision:   db.DecisionApprove,
+	}); err != nil {
+		t.Fatalf("SubmitReview: %v", err)
+	}
+
+	items, err := loadQueue(Options{ProjectPath: h.ProjectDir})
+	if err != nil {
+		t.Fatalf("loadQueue: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected the 2 pending requests, got %d", len(items))
+	}
+	if items[0].Request.ID != critical.ID || items[1].Request.ID != dangerous.ID {
+		t.Errorf("expected critical before dangerous, got %s, %s", items[0].Request.ID, items[1].Request.ID)
+	}
+	if s := items[1].Status; s == nil || s.Approvals != 1 || s.MinApprovals != 2 {
+		t.Errorf("expected 1/2 approvals, got %+v", s)
+	}
+}
+
+// queueModel loads the harness project's queue into a model for reviewer.
+func queueModel(t *testing.T, h *testutil.Harness, reviewer *db.Session) Model {
+	t.Helper()
+	return queueModelWith(t, Options{ProjectPath: h.ProjectDir, SessionID: reviewer.ID, SessionKey: reviewer.SessionKey})
+}
+
+// queueModelWith loads the queue described by opts into a model.
+func queueModelWith(t *testing.T, opts Options) Model {
+	t.Helper()
+	m := New(opts)
+	m, _ = update(t, m, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = update(t, m, loadDataCmd(opts)())
+	if m.lastErr != nil {
+		t.Fatalf("loading queue: %v", m.lastErr)
+	}
+	return m
+}
+
+func TestCriticalApprovalRequiresConfirmation(t *testing.T) {
+	h := testutil.NewHarness(t)
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	req := testutil.MakeRequest(t, h.DB, requestor, testutil.WithRisk(db.RiskTierCritical), testutil.WithMinApprovals(2))
+
+	m := queueModel(t, h, reviewer)
+
+	m, _ = update(t, m, runes("a"))
+	if m.mode != modeConfirm {
+		t.Fatalf("expected approving a critical request to ask for confirmation")
+	}
+	m = typeText(t, m, "y")
+	m, cmd := update(t, m, enter())
+	if cmd != nil || !m.noticeErr || m.mode != modeBrowse {
+		t.Fatalf("expected an unconfirmed approval to be cancelled, notice %q", m.notice)
+	}
+
+	m, _ = update(t, m, runes("a"))
+	m = typeText(t, m, "yes")
+	m, cmd = update(t, m, enter())
+	if cmd == nil {
+		t.Fatal("expected a confirmed approval to be submitted")
+	}
+	m, _ = update(t, m, cmd())
+	if m.noticeErr || !strings.Contains(m.notice, "approvals 1") {
+		t.Errorf("unexpected notice %q", m.notice)
+	}
+
+	if status, err := core.NewReviewService(h.DB, core.DefaultReviewConfig()).GetReviewStatus(req.ID); err != nil || status.Approvals != 1 {
+		t.Errorf("expected the approval to be recorded, got %+v, %v", status, err)
+	}
+}
+
+func TestApprovalEvidenceGate(t *testing.T) {
+	h := testutil.NewHarness(t)
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	diff := db.Attachment{Type: db.AttachmentTypeGitDiff, Content: "diff --git a b"}
+	req := testutil.MakeRequest(t, h.DB, requestor, testutil.WithRisk(db.RiskTierCritical), testutil.WithMinApprovals(2),
+		testutil.WithDryRun("ls", "a"), testutil.WithAttachments(diff))
+
+	m := queueModelWith(t, Options{
+		ProjectPath: h.ProjectDir,
+		SessionID:   reviewer.ID,
+		SessionKey:  reviewer.SessionKey,
+		ReviewConfig: func(*db.Request) (core.ReviewConfig, error) {
+			cfg := core.DefaultReviewConfig()
+			cfg.UnviewedEvidenceAction = core.UnviewedEvidenceBlockCritical
+			return cfg, nil
+		},
+	})
+	approve := func(m Model) reviewMsg {
+		t.Helper()
+		m, _ = update(t, m, runes("a"))
+		m = typeText(t, m, "yes")
+		_, cmd := update(t, m, enter())
+		if cmd == nil {
+			t.Fatal("expected a confirmed approval to be submitted")
+		}
+		return cmd().(reviewMsg)
+	}
+
+	// The dry run is shown in the detail pane; the diff is not.
+	msg := approve(m)
+	if !errors.Is(msg.err, core.ErrUnviewedEvidence) || !strings.Contains(msg.err.Error(), "attachment:0") {
+		t.Fatalf("expected the unviewed diff to block the approval, got %v", msg.err)
+	}
+	if notice := describeReview(msg); !strings.Contains(notice, "slb show") {
+		t.Errorf("expected the notice to say how to view the evidence, got %q", notice)
+	}
+
+	view := core.NewEvidenceView(core.EvidenceAttachmentSection(0), time.Now(), time.Second)
+	if err := core.RecordEvidenceViews(req.ProjectPath, req.ID, reviewer.ID, view); err != nil {
+		t.Fatalf("RecordEvidenceViews: %v", err)
+	}
+	if msg := approve(m); msg.err != nil || len(msg.unviewed) != 0 {
+		t.Fatalf("expected the approval after viewing the diff, got %v (unviewed %v)", msg.err, msg.unviewed)
+	}
+	reviews, err := h.DB.ListReviewsForRequest(req.ID)
+	if err != nil || len(reviews) != 1 || len(reviews[0].Responses.EvidenceViewed) != 2 {
+		t.Errorf("expected the review to attach both views, got %+v, %v", reviews, err)
+	}
+}
+
+func TestRejectWithReason(t *testing.T) {
+	h := testutil.NewHarness(t)
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	reviewer := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	req := testutil.MakeRequest(t, h.DB, requestor)
+
+	m := queueModel(t, h, reviewer)
+	m, _ = update(t, m, runes("r"))
+	if m.mode != modeReject {
+		t.Fatal("expected rejecting to ask for a reason")
+	}
+	m = typeText(t, m, "too broad")
+	m, cmd := update(t, m, enter())
+	if cmd == nil {
+		t.Fatal("expected the rejection to be submitted")
+	}
+	m, _ = update(t, m, cmd())
+	if m.noticeErr {
+		t.Fatalf("unexpected notice %q", m.notice)
+	}
+
+	reviews, err := h.DB.ListReviewsForRequest(req.ID)
+	if err != nil || len(reviews) != 1 || reviews[0].Decision != db.DecisionReject || reviews[0].Comments != "too broad" {
+		t.Errorf("expected a rejection with the reason, got %+v, %v", reviews, err)
+	}
+}
+
+func TestReviewErrorsShownInline(t *testing.T) {
+	h := testutil.NewHarness(t)
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	testutil.MakeRequest(t, h.DB, requestor)
+
+	// The requestor reviewing its own request is refused, not fatal.
+	m := queueModel(t, h, requestor)
+	m, cmd := update(t, m, runes("a"))
+	if cmd == nil {
+		t.Fatal("expected a non-critical approval to be submitted immediately")
+	}
+	msg := cmd().(reviewMsg)
+	if !errors.Is(msg.err, core.ErrSelfReview) {
+		t.Fatalf("expected ErrSelfReview, got %v", msg.err)
+	}
+	m, _ = update(t, m, msg)
+	if !m.noticeErr || !strings.Contains(m.notice, "your own request") {
+		t.Errorf("expected an inline self-review notice, got %q", m.notice)
+	}
+	if !strings.Contains(m.View(), "your own request") {
+		t.Error("expected the notice in the view")
+	}
+
+	already := describeReview(reviewMsg{requestID: "req-123456789", decision: db.DecisionApprove, err: core.ErrAlreadyReviewed})
+	if !strings.Contains(already, "already reviewed") {
+		t.Errorf("unexpected already-reviewed notice %q", already)
+	}
+
+	warned := describeReview(reviewMsg{requestID: "req-123456789", decision: db.DecisionApprove, result: &core.ReviewResult{}, unviewed: []string{"attachment:0"}})
+	if !strings.Contains(warned, "evidence not viewed: attachment:0") {
+		t.Errorf("unexpected unviewed-evidence notice %q", warned)
+	}
+}
+
+func TestReviewWithoutSession(t *testing.T) {
+	h := testutil.NewHarness(t)
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	testutil.MakeRequest(t, h.DB, requestor)
+
+	m := queueModel(t, h, &db.Session{})
+	m, cmd := update(t, m, runes("a"))
+	if cmd != nil || !m.noticeErr || !strings.Contains(m.notice, "--session-id") {
+		t.Errorf("expected a read-only queue to explain how to review, got %q", m.notice)
+	}
+}
+
+func TestDetailShowsJustificationAndCounts(t *testing.T) {
+	h := testutil.NewHarness(t)
+	requestor := testutil.MakeSession(t, h.DB, testutil.WithProject(h.ProjectDir))
+	testutil.MakeRequest(t, h.DB, requestor,
+		testutil.WithJustification("stale cache", "cache rebuilt", "faster builds", "regenerated on demand"),
+		testutil.WithDryRun("ls ./cache", "a\nb"),
+		testutil.WithAttachments(db.Attachment{Type: db.AttachmentTypeFile, Content: "x"}),
+		testutil.WithMinApprovals(2),
+	)
+
+	m := queueModel(t, h, requestor)
+	view := m.renderDetail(80)
+	for _, want := range []string{"stale cache", "cache rebuilt", "faster builds", "regenerated on demand", "ls ./cache", "1 attachment: file", "Approvals 0/2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail pane missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestSummarizeAttachments(t *testing.T) {
+	if got := SummarizeAttachments(nil); got != "" {
+		t.Errorf("expected no summary without attachments, got %q", got)
+	}
+	got := SummarizeAttachments([]db.Attachment{
+		{Type: db.AttachmentTypeFile, Content: strings.Repeat("x", 1000)},
+		{Type: db.AttachmentTypeGitDiff, Content: strings.Repeat("x", 100)},
+		{Type: db.AttachmentTypeFile, Content: strings.Repeat("x", 100)},
+	})
+	if want := "3 attachments: file ×2, git_diff (1.2 KB)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/tui/components"
 	"github.com/Dicklesworthstone/slb/internal/tui/icons"
@@ -99,6 +100,7 @@ type DetailModel struct {
 	Request  *db.Request
 	Reviews  []db.Review
 	Session  *db.Session // Current session for approval eligibility
+	Similar  string      // Summary of the closest earlier request, if any
 	Width    int
 	Height   int
 	KeyMap   DetailKeyMap
@@ -119,6 +121,12 @@ type DetailModel struct {
 
 	// Copied flag for feedback
 	copied bool
+
+	// Evidence interaction tracking: which sections scrolled into view and
+	// for how long. Only metadata is kept, never keystrokes.
+	sectionLines   map[string]int
+	visibleSince   map[string]time.Time
+	evidenceViewed []db.EvidenceView
 }
 
 // NewDetailModel creates a new request detail model.
@@ -137,6 +145,12 @@ func (m *DetailModel) WithSession(s *db.Session) *DetailModel {
 	return m
 }
 
+// WithSimilar sets the summary of the most similar earlier request.
+func (m *DetailModel) WithSimilar(summary string) *DetailModel {
+	m.Similar = summary
+	return m
+}
+
 // Init initializes the model.
 func (m *DetailModel) Init() tea.Cmd {
 	return nil
@@ -159,6 +173,7 @@ func (m *DetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.Height = msg.Height - 4
 			m.viewport.SetContent(m.renderContent())
 		}
+		m.trackEvidence(time.Now())
 		return m, nil
 
 	case tea.KeyMsg:
@@ -259,6 +274,7 @@ func (m *DetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var vpCmd tea.Cmd
 		m.viewport, vpCmd = m.viewport.Update(msg)
 		cmds = append(cmds, vpCmd)
+		m.trackEvidence(time.Now())
 	}
 
 	return m, tea.Batch(cmds...)
@@ -338,6 +354,17 @@ func (m *DetailModel) renderContent() string {
 	th := theme.Current
 	var sections []string
 
+	divider := lipgloss.NewStyle().
+		Foreground(th.Overlay0).
+		Render(strings.Repeat("─", m.Width-4))
+	sep := "\n" + divider + "\n\n"
+
+	// nextLine returns the content line the next appended section starts on.
+	m.sectionLines = make(map[string]int)
+	nextLine := func() int {
+		return strings.Count(strings.Join(sections, sep)+sep, "\n")
+	}
+
 	// Command box
 	cmdBox := components.NewCommandBox(m.Request.Command.Raw).
 		WithHint(true)
@@ -349,25 +376,46 @@ func (m *DetailModel) renderContent() string {
 	}
 	sections = append(sections, cmdBox.Render())
 
+	// Pinned cluster/cloud context the command will run against
+	if m.Request.PinnedContext != nil {
+		sections = append(sections, m.renderPinnedContext())
+	}
+
 	// Requestor info
 	requestorInfo := m.renderRequestorInfo()
 	sections = append(sections, requestorInfo)
 
+	// Why the command got its tier (stored at creation)
+	if m.Request.TierReason != "" {
+		sections = append(sections, m.renderClassification())
+	}
+
+	// Closest earlier request and how it went
+	if m.Similar != "" {
+		sections = append(sections, m.renderSimilar())
+	}
+
 	// Justification
 	justification := m.renderJustification()
 	if justification != "" {
+		m.sectionLines[core.EvidenceSectionJustification] = nextLine()
 		sections = append(sections, justification)
 	}
 
 	// Dry run output
 	if m.Request.DryRun != nil && m.Request.DryRun.Output != "" {
 		dryRun := m.renderDryRun()
+		m.sectionLines[core.EvidenceSectionDryRun] = nextLine()
 		sections = append(sections, dryRun)
 	}
 
-	// Attachments
+	// Attachments (one line per attachment after the section title)
 	if len(m.Request.Attachments) > 0 {
 		attachments := m.renderAttachments()
+		start := nextLine()
+		for i := range m.Request.Attachments {
+			m.sectionLines[core.EvidenceAttachmentSection(i)] = start + 1 + i
+		}
 		sections = append(sections, attachments)
 	}
 
@@ -382,11 +430,53 @@ func (m *DetailModel) renderContent() string {
 	}
 
 	// Join sections with dividers
-	divider := lipgloss.NewStyle().
-		Foreground(th.Overlay0).
-		Render(strings.Repeat("─", m.Width-4))
+	return strings.Join(sections, sep)
+}
+
+// trackEvidence updates which evidence sections are on screen at now.
+func (m *DetailModel) trackEvidence(now time.Time) {
+	if !m.ready || m.Mode != DetailModeView {
+		m.flushEvidence(now)
+		return
+	}
+	if m.visibleSince == nil {
+		m.visibleSince = make(map[string]time.Time)
+	}
+	top := m.viewport.YOffset
+	bottom := top + m.viewport.Height
+	for section, line := range m.sectionLines {
+		visible := line >= top && line < bottom
+		since, tracked := m.visibleSince[section]
+		switch {
+		case visible && !tracked:
+			m.visibleSince[section] = now
+		case !visible && tracked:
+			m.evidenceViewed = core.MergeEvidenceViews(m.evidenceViewed,
+				[]db.EvidenceView{core.NewEvidenceView(section, since, now.Sub(since))})
+			delete(m.visibleSince, section)
+		}
+	}
+}
 
-	return strings.Join(sections, "\n"+divider+"\n\n")
+// flushEvidence closes out all sections currently on screen.
+func (m *DetailModel) flushEvidence(now time.Time) {
+	for section, since := range m.visibleSince {
+		m.evidenceViewed = core.MergeEvidenceViews(m.evidenceViewed,
+			[]db.EvidenceView{core.NewEvidenceView(section, since, now.Sub(since))})
+		delete(m.visibleSince, section)
+	}
+}
+
+// TakeEvidenceViews returns the evidence views recorded so far and resets
+// the tracker, so repeated calls never double-count time on screen.
+func (m *DetailModel) TakeEvidenceViews(now time.Time) []db.EvidenceView {
+	m.flushEvidence(now)
+	views := m.evidenceViewed
+	m.evidenceViewed = nil
+	if m.ready && m.Mode == DetailModeView {
+		m.trackEvidence(now)
+	}
+	return views
 }
 
 // renderRequestorInfo renders requestor information.
@@ -424,6 +514,53 @@ func (m *DetailModel) renderRequestorInfo() string {
 	return sectionTitle + "\n" + info
 }
 
+// renderClassification renders the stored tier rationale.
+func (m *DetailModel) renderClassification() string {
+	th := theme.Current
+
+	sectionTitle := lipgloss.NewStyle().
+		Foreground(th.Blue).
+		Bold(true).
+		Render("Classification")
+
+	metaStyle := lipgloss.NewStyle().Foreground(th.Subtext)
+	info := metaStyle.Render(m.Request.TierReason)
+	if m.Request.Command.Summary != "" {
+		info = metaStyle.Render("Summary: "+m.Request.Command.Summary) + "\n" + info
+	}
+	return sectionTitle + "\n" + info
+}
+
+// renderSimilar renders the most similar earlier request.
+func (m *DetailModel) renderSimilar() string {
+	th := theme.Current
+
+	sectionTitle := lipgloss.NewStyle().
+		Foreground(th.Blue).
+		Bold(true).
+		Render("Precedent")
+
+	metaStyle := lipgloss.NewStyle().Foreground(th.Subtext)
+	return sectionTitle + "\n" + metaStyle.Render(m.Similar)
+}
+
+// renderPinnedContext renders the context pinned for execution.
+func (m *DetailModel) renderPinnedContext() string {
+	th := theme.Current
+
+	sectionTitle := lipgloss.NewStyle().
+		Foreground(th.Blue).
+		Bold(true).
+		Render("Target Context")
+
+	valueStyle := lipgloss.NewStyle().Foreground(th.Peach).Bold(true)
+	metaStyle := lipgloss.NewStyle().Foreground(th.Subtext)
+
+	return sectionTitle + "\n" +
+		valueStyle.Render(core.DescribePinnedContext(m.Request.PinnedContext)) + "\n" +
+		metaStyle.Render("Execution is pinned to this context")
+}
+
 // renderJustification renders the justification section.
 func (m *DetailModel) renderJustification() string {
 	th := theme.Current
@@ -594,6 +731,9 @@ func (m *DetailModel) renderReviews() string {
 		timeStr := lipgloss.NewStyle().Foreground(th.Subtext).Render(formatTimeAgo(rev.CreatedAt))
 
 		line := fmt.Sprintf("%s %s %s  %s", icon, reviewer, decision, timeStr)
+		if rev.DelegationID != "" {
+			line += "  " + lipgloss.NewStyle().Foreground(th.Mauve).Render("⇄ delegated")
+		}
 		if rev.Comments != "" {
 			line += "\n   " + lipgloss.NewStyle().Foreground(th.Subtext).Italic(true).Render(rev.Comments)
 		}
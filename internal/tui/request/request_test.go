@@ -63,6 +63,44 @@ func TestDetailModelWithSession(t *testing.T) {
 	}
 }
 
+func TestDetailModelWithSimilar(t *testing.T) {
+	req := testRequest()
+	summary := "similar request (90% match) approved 12 days ago, executed successfully"
+
+	m := NewDetailModel(req, nil).WithSimilar(summary)
+	m.Width = 80
+	content := m.renderContent()
+	if !strings.Contains(content, "Precedent") || !strings.Contains(content, summary) {
+		t.Errorf("expected precedent section in content:\n%s", content)
+	}
+
+	plain := NewDetailModel(req, nil)
+	plain.Width = 80
+	if content := plain.renderContent(); strings.Contains(content, "Precedent") {
+		t.Error("precedent section should be omitted without a similar request")
+	}
+}
+
+func TestDetailModelClassification(t *testing.T) {
+	req := testRequest()
+	req.Command.Summary = "rm -rf"
+	req.TierReason = `matched critical pattern "^rm\\s+-rf\\s+/"`
+
+	m := NewDetailModel(req, nil)
+	m.Width = 80
+	content := m.renderContent()
+	for _, want := range []string{"Classification", "Summary: rm -rf", req.TierReason} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in content:\n%s", want, content)
+		}
+	}
+
+	req.TierReason = ""
+	if content := m.renderContent(); strings.Contains(content, "Classification") {
+		t.Error("classification section should be omitted without a stored tier reason")
+	}
+}
+
 func TestDefaultDetailKeyMap(t *testing.T) {
 	km := DefaultDetailKeyMap()
 
@@ -447,6 +485,54 @@ func TestDetailModelViewWithAttachments(t *testing.T) {
 	}
 }
 
+func TestDetailModelTracksVisibleEvidence(t *testing.T) {
+	req := testRequest()
+	req.DryRun = &db.DryRunResult{Command: "ls /tmp/test", Output: "file1.txt"}
+	req.Attachments = []db.Attachment{
+		{Type: db.AttachmentTypeGitDiff, Content: "+added\n-removed"},
+	}
+
+	m := NewDetailModel(req, nil)
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 200})
+
+	later := time.Now().Add(2 * time.Second)
+	views := m.TakeEvidenceViews(later)
+	bySection := make(map[string]db.EvidenceView)
+	for _, v := range views {
+		bySection[v.Section] = v
+	}
+	for _, section := range []string{"justification", "dry_run", "attachment:0"} {
+		v, ok := bySection[section]
+		if !ok {
+			t.Fatalf("expected %s to be recorded as viewed, got %+v", section, views)
+		}
+		if v.DurationMs < 1900 {
+			t.Errorf("%s duration = %dms, want ~2000ms", section, v.DurationMs)
+		}
+	}
+
+	// Taking again must not double-count the time already reported.
+	for _, v := range m.TakeEvidenceViews(later) {
+		if v.DurationMs != 0 {
+			t.Errorf("%s re-reported %dms", v.Section, v.DurationMs)
+		}
+	}
+}
+
+func TestDetailModelEvidenceBelowFoldNotViewed(t *testing.T) {
+	req := testRequest()
+	req.DryRun = &db.DryRunResult{Command: "ls /tmp/test", Output: "file1.txt"}
+
+	m := NewDetailModel(req, nil)
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 6})
+
+	for _, v := range m.TakeEvidenceViews(time.Now()) {
+		if v.Section == "dry_run" {
+			t.Fatalf("dry_run should not be viewed before scrolling, got %+v", v)
+		}
+	}
+}
+
 func TestDetailModelViewWithLongAttachment(t *testing.T) {
 	req := testRequest()
 	// Create attachment with content > 100 characters to test truncation
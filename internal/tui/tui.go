@@ -3,16 +3,21 @@
 package tui
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/daemon"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/internal/tui/dashboard"
 	"github.com/Dicklesworthstone/slb/internal/tui/history"
 	"github.com/Dicklesworthstone/slb/internal/tui/patterns"
+	"github.com/Dicklesworthstone/slb/internal/tui/queue"
 	"github.com/Dicklesworthstone/slb/internal/tui/request"
 	"github.com/Dicklesworthstone/slb/internal/tui/theme"
 )
@@ -35,6 +40,10 @@ type Options struct {
 	RefreshInterval int
 	SessionID       string
 	SessionKey      string
+	// ReviewConfig returns the review settings for a request shown or
+	// reviewed in the approval queue or the detail view. Nil uses
+	// core.DefaultReviewConfig.
+	ReviewConfig func(req *db.Request) (core.ReviewConfig, error)
 }
 
 // DefaultOptions returns the default TUI options.
@@ -282,18 +291,45 @@ func (m *Model) setupDetailCallbacks() {
 		return
 	}
 	m.detail.OnBack = func() tea.Cmd {
+		m.recordDetailEvidence()
 		return func() tea.Msg {
 			return navigateMsg{view: ViewDashboard}
 		}
 	}
 	m.detail.OnApprove = func(requestID string, comments string) tea.Cmd {
+		m.recordDetailEvidence()
 		return m.approveRequest(requestID, comments)
 	}
 	m.detail.OnReject = func(requestID string, reason string) tea.Cmd {
+		m.recordDetailEvidence()
 		return m.rejectRequest(requestID, reason)
 	}
 }
 
+// recordDetailEvidence persists the evidence views gathered by the detail
+// view so that the next review submitted for the request can attach them.
+func (m *Model) recordDetailEvidence() {
+	if m.detail == nil || m.detail.Request == nil {
+		return
+	}
+	req := m.detail.Request
+	_ = core.RecordEvidenceViews(req.ProjectPath, req.ID, m.options.SessionID, m.detail.TakeEvidenceViews(time.Now())...)
+}
+
+// attachEvidenceViews copies the evidence views the reviewing session
+// recorded onto a review and returns the project path the record lives under.
+func attachEvidenceViews(dbConn *db.DB, review *db.Review) string {
+	req, err := dbConn.GetRequest(review.RequestID)
+	if err != nil {
+		return ""
+	}
+	views, err := core.LoadEvidenceViews(req.ProjectPath, req.ID, review.ReviewerSessionID)
+	if err == nil {
+		review.Responses.EvidenceViewed = views
+	}
+	return req.ProjectPath
+}
+
 // setupHistoryCallbacks wires up history browser callbacks.
 func (m *Model) setupHistoryCallbacks() {
 	m.history.OnBack = func() {
@@ -339,6 +375,17 @@ func (m *Model) loadRequestDetail(requestID string) *request.DetailModel {
 
 	reviewPtrs, _ := dbConn.ListReviewsForRequest(requestID)
 
+	// The requestor may only see pseudonyms while the request is pending
+	reviewCfg := core.DefaultReviewConfig()
+	if m.options.ReviewConfig != nil {
+		if reviewCfg, err = m.options.ReviewConfig(req); err != nil {
+			return nil
+		}
+	}
+	reviewSvc := core.NewReviewService(dbConn, reviewCfg)
+	reviewSvc.SetViewer(core.ResolveReviewViewer(dbConn, m.options.SessionID))
+	reviewPtrs, _ = reviewSvc.ReviewsForViewer(req, reviewPtrs)
+
 	// Convert []*db.Review to []db.Review for the detail model
 	reviews := make([]db.Review, len(reviewPtrs))
 	for i, r := range reviewPtrs {
@@ -348,6 +395,9 @@ func (m *Model) loadRequestDetail(requestID string) *request.DetailModel {
 	}
 
 	detail := request.NewDetailModel(req, reviews)
+	if match, err := core.TopSimilarMatch(dbConn, req); err == nil && match != nil {
+		detail.WithSimilar(core.DescribeSimilarMatch(match, time.Now()))
+	}
 	if currentSession != nil {
 		detail.WithSession(currentSession)
 	}
@@ -378,6 +428,12 @@ func (m *Model) approveRequest(requestID string, comments string) tea.Cmd {
 			return nil
 		}
 
+		// The signature covers the hash of the command being reviewed.
+		request, err := dbConn.GetRequest(requestID)
+		if err != nil {
+			return nil
+		}
+
 		now := time.Now().UTC()
 		review := &db.Review{
 			RequestID:          requestID,
@@ -387,14 +443,18 @@ func (m *Model) approveRequest(requestID string, comments string) tea.Cmd {
 			Decision:           db.DecisionApprove,
 			Comments:           comments,
 			SignatureTimestamp: now,
+			CommandHash:        request.Command.Hash,
 		}
 
 		// Compute signature
-		review.Signature = db.ComputeReviewSignature(m.options.SessionKey, requestID, db.DecisionApprove, now)
+		review.Signature = db.ComputeReviewSignature(m.options.SessionKey, requestID, review.CommandHash, db.DecisionApprove, now)
+		projectPath := attachEvidenceViews(dbConn, review)
 
 		if err := dbConn.CreateReviewWithValidation(review, m.options.SessionKey); err != nil {
 			// In a real app we'd send an error msg, but for now just log/ignore or return to dash
 			// Ideally we return an error message tea.Msg
+		} else if len(review.Responses.EvidenceViewed) > 0 {
+			_ = core.ClearEvidenceViews(projectPath, requestID, session.ID)
 		}
 
 		return navigateMsg{view: ViewDashboard}
@@ -424,6 +484,12 @@ func (m *Model) rejectRequest(requestID string, reason string) tea.Cmd {
 			return nil
 		}
 
+		// The signature covers the hash of the command being reviewed.
+		request, err := dbConn.GetRequest(requestID)
+		if err != nil {
+			return nil
+		}
+
 		now := time.Now().UTC()
 		review := &db.Review{
 			RequestID:          requestID,
@@ -433,11 +499,15 @@ func (m *Model) rejectRequest(requestID string, reason string) tea.Cmd {
 			Decision:           db.DecisionReject,
 			Comments:           reason,
 			SignatureTimestamp: now,
+			CommandHash:        request.Command.Hash,
 		}
 
-		review.Signature = db.ComputeReviewSignature(m.options.SessionKey, requestID, db.DecisionReject, now)
+		review.Signature = db.ComputeReviewSignature(m.options.SessionKey, requestID, review.CommandHash, db.DecisionReject, now)
+		projectPath := attachEvidenceViews(dbConn, review)
 
-		_ = dbConn.CreateReviewWithValidation(review, m.options.SessionKey)
+		if err := dbConn.CreateReviewWithValidation(review, m.options.SessionKey); err == nil && len(review.Responses.EvidenceViewed) > 0 {
+			_ = core.ClearEvidenceViews(projectPath, requestID, session.ID)
+		}
 
 		return navigateMsg{view: ViewDashboard}
 	}
@@ -486,3 +556,41 @@ func RunWithOptions(opts Options) error {
 	_, err := p.Run()
 	return err
 }
+
+// RunApproveQueue starts the approval queue. While the daemon is running its
+// events reload the queue as they arrive; otherwise it polls.
+func RunApproveQueue(opts Options) error {
+	if opts.Theme != "" {
+		theme.SetTheme(theme.FlavorName(opts.Theme))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var events <-chan daemon.Event
+	if daemon.NewClient().IsDaemonRunning() {
+		ipcClient := daemon.NewIPCClient(daemon.DefaultSocketPath())
+		ipcClient.SetNoticeWriter(io.Discard) // notices would corrupt the alt screen
+		defer ipcClient.Close()
+		if ch, err := ipcClient.Subscribe(ctx); err == nil {
+			events = ch
+		}
+	}
+
+	m := queue.New(queue.Options{
+		ProjectPath:     opts.ProjectPath,
+		SessionID:       opts.SessionID,
+		SessionKey:      opts.SessionKey,
+		ReviewConfig:    opts.ReviewConfig,
+		RefreshInterval: time.Duration(opts.RefreshInterval) * time.Second,
+		Events:          events,
+	})
+
+	teaOpts := []tea.ProgramOption{tea.WithAltScreen()}
+	if !opts.DisableMouse {
+		teaOpts = append(teaOpts, tea.WithMouseCellMotion())
+	}
+
+	_, err := tea.NewProgram(m, teaOpts...).Run()
+	return err
+}
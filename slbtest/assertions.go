@@ -0,0 +1,59 @@
+package slbtest
+
+// AssertStatus verifies the request's current status.
+func (c *Client) AssertStatus(req *Request, expected RequestStatus) {
+	c.t.Helper()
+
+	current := c.Get(req.ID)
+	if current.Status != expected {
+		c.t.Errorf("request %s: expected status %s, got %s", req.ID, expected, current.Status)
+	}
+}
+
+// AssertTier verifies the request's risk tier.
+func (c *Client) AssertTier(req *Request, expected RiskTier) {
+	c.t.Helper()
+
+	if req.RiskTier != expected {
+		c.t.Errorf("request %s: expected tier %s, got %s", req.ID, expected, req.RiskTier)
+	}
+}
+
+// AssertReviewCount verifies the number of reviews of the request.
+func (c *Client) AssertReviewCount(req *Request, expected int) {
+	c.t.Helper()
+
+	reviews, err := c.db.ListReviewsForRequest(req.ID)
+	if err != nil {
+		c.t.Fatalf("AssertReviewCount: %v", err)
+	}
+	if len(reviews) != expected {
+		c.t.Errorf("request %s: expected %d reviews, got %d", req.ID, expected, len(reviews))
+	}
+}
+
+// AssertApprovalCount verifies the number of approvals of the request.
+func (c *Client) AssertApprovalCount(req *Request, expected int) {
+	c.t.Helper()
+
+	approvals, _, err := c.db.CountReviewsByDecision(req.ID)
+	if err != nil {
+		c.t.Fatalf("AssertApprovalCount: %v", err)
+	}
+	if approvals != expected {
+		c.t.Errorf("request %s: expected %d approvals, got %d", req.ID, expected, approvals)
+	}
+}
+
+// AssertPendingCount verifies the number of pending requests in the project.
+func (c *Client) AssertPendingCount(expected int) {
+	c.t.Helper()
+
+	pending, err := c.db.ListPendingRequests(c.project)
+	if err != nil {
+		c.t.Fatalf("AssertPendingCount: %v", err)
+	}
+	if len(pending) != expected {
+		c.t.Errorf("expected %d pending requests, got %d", expected, len(pending))
+	}
+}
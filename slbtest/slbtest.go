@@ -0,0 +1,185 @@
+// Package slbtest runs a throwaway SLB for the tests of programs that embed
+// it.
+//
+// A Client owns a private SQLite database under t.TempDir() and drives the
+// same request and review services as the slb CLI, with notifications off
+// and no daemon, so tests never touch ~/.slb or a running daemon:
+//
+//	func TestDeployNeedsApproval(t *testing.T) {
+//	    c := slbtest.New(t)
+//	    agent := c.NewSession("BlueLake", "codex-cli", "gpt-5")
+//	    reviewer := c.NewSession("GreenCastle", "claude-code", "opus")
+//
+//	    req := c.Request(agent, "rm -rf ./build", "clean stale artifacts")
+//	    c.AssertStatus(req, slbtest.StatusPending)
+//
+//	    c.Approve(req, reviewer)
+//	    c.AssertStatus(req, slbtest.StatusApproved)
+//	}
+//
+// Failures are reported through t, so helpers return values directly.
+package slbtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/internal/core"
+	"github.com/Dicklesworthstone/slb/internal/db"
+)
+
+// Types used by the helpers, re-exported so callers can name them.
+type (
+	// Session is an agent session.
+	Session = db.Session
+	// Request is a command approval request.
+	Request = db.Request
+	// Review is an approval or rejection of a request.
+	Review = db.Review
+	// RequestStatus is a request's state.
+	RequestStatus = db.RequestStatus
+	// RiskTier is a command's risk classification.
+	RiskTier = db.RiskTier
+)
+
+// Request statuses and risk tiers.
+const (
+	StatusQueued          = db.StatusQueued
+	StatusPending         = db.StatusPending
+	StatusApproved        = db.StatusApproved
+	StatusRejected        = db.StatusRejected
+	StatusExecuting       = db.StatusExecuting
+	StatusExecuted        = db.StatusExecuted
+	StatusExecutionFailed = db.StatusExecutionFailed
+	StatusCancelled       = db.StatusCancelled
+	StatusTimeout         = db.StatusTimeout
+	StatusApprovalExpired = db.StatusApprovalExpired
+
+	RiskTierCritical  = db.RiskTierCritical
+	RiskTierDangerous = db.RiskTierDangerous
+	RiskTierCaution   = db.RiskTierCaution
+)
+
+// Client is an isolated SLB instance bound to a test.
+type Client struct {
+	t       testing.TB
+	db      *db.DB
+	project string
+	creator *core.RequestCreator
+	reviews *core.ReviewService
+}
+
+// Option configures New.
+type Option func(*Client)
+
+// WithProject sets the project path sessions and requests belong to. It
+// defaults to a fresh t.TempDir().
+func WithProject(path string) Option {
+	return func(c *Client) { c.project = path }
+}
+
+// New returns a Client with an empty database, closed when the test ends.
+func New(t testing.TB, opts ...Option) *Client {
+	t.Helper()
+	dir := t.TempDir()
+	c := &Client{t: t, project: dir}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	database, err := db.OpenAndMigrate(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("slbtest: opening database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	c.db = database
+
+	cfg := core.DefaultRequestCreatorConfig()
+	cfg.AgentMailEnabled = false
+	c.creator = core.NewRequestCreator(database, nil, nil, cfg)
+	c.reviews = core.NewReviewService(database, core.DefaultReviewConfig())
+	return c
+}
+
+// DB returns the underlying database for checks the helpers don't cover.
+func (c *Client) DB() *db.DB {
+	return c.db
+}
+
+// ProjectPath returns the project sessions and requests belong to.
+func (c *Client) ProjectPath() string {
+	return c.project
+}
+
+// NewSession starts an agent session in the project.
+func (c *Client) NewSession(agent, program, model string) *Session {
+	c.t.Helper()
+	sess := &db.Session{
+		AgentName:   agent,
+		Program:     program,
+		Model:       model,
+		ProjectPath: c.project,
+	}
+	if err := c.db.CreateSession(sess); err != nil {
+		c.t.Fatalf("slbtest: creating session: %v", err)
+	}
+	return sess
+}
+
+// Request submits command for review as sess would with slb request: it is
+// classified, and its tier decides how many approvals it needs. A command
+// classified as safe needs no request and fails the test.
+func (c *Client) Request(sess *Session, command, reason string) *Request {
+	c.t.Helper()
+	result, err := c.creator.CreateRequest(core.CreateRequestOptions{
+		SessionID:     sess.ID,
+		Command:       command,
+		Cwd:           c.project,
+		Shell:         true,
+		Justification: core.Justification{Reason: reason},
+	})
+	if err != nil {
+		c.t.Fatalf("slbtest: creating request for %q: %v", command, err)
+	}
+	if result.Skipped {
+		c.t.Fatalf("slbtest: no request created for %q: %s", command, result.SkipReason)
+	}
+	return result.Request
+}
+
+// Approve records reviewer's signed approval of req.
+func (c *Client) Approve(req *Request, reviewer *Session) *Review {
+	c.t.Helper()
+	return c.review(req, reviewer, db.DecisionApprove, "")
+}
+
+// Reject records reviewer's signed rejection of req.
+func (c *Client) Reject(req *Request, reviewer *Session, reason string) *Review {
+	c.t.Helper()
+	return c.review(req, reviewer, db.DecisionReject, reason)
+}
+
+func (c *Client) review(req *Request, reviewer *Session, decision db.Decision, comments string) *Review {
+	c.t.Helper()
+	result, err := c.reviews.SubmitReview(core.ReviewOptions{
+		SessionID:  reviewer.ID,
+		SessionKey: reviewer.SessionKey,
+		RequestID:  req.ID,
+		Decision:   decision,
+		Comments:   comments,
+	})
+	if err != nil {
+		c.t.Fatalf("slbtest: %s of %s by %s: %v", decision, req.ID, reviewer.AgentName, err)
+	}
+	return result.Review
+}
+
+// Get returns the request's current state.
+func (c *Client) Get(id string) *Request {
+	c.t.Helper()
+	req, err := c.db.GetRequest(id)
+	if err != nil {
+		c.t.Fatalf("slbtest: getting request %s: %v", id, err)
+	}
+	return req
+}
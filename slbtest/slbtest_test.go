@@ -0,0 +1,50 @@
+package slbtest_test
+
+import (
+	"testing"
+
+	"github.com/Dicklesworthstone/slb/slbtest"
+)
+
+func TestRequestApprovedEndToEnd(t *testing.T) {
+	c := slbtest.New(t)
+	agent := c.NewSession("BlueLake", "codex-cli", "gpt-5")
+	reviewer := c.NewSession("GreenCastle", "claude-code", "opus")
+
+	req := c.Request(agent, "rm -rf ./build", "clean stale build artifacts")
+	c.AssertTier(req, slbtest.RiskTierDangerous)
+	c.AssertStatus(req, slbtest.StatusPending)
+	c.AssertPendingCount(1)
+
+	c.Approve(req, reviewer)
+
+	c.AssertStatus(req, slbtest.StatusApproved)
+	c.AssertApprovalCount(req, 1)
+	c.AssertReviewCount(req, 1)
+	c.AssertPendingCount(0)
+}
+
+func TestRequestRejected(t *testing.T) {
+	c := slbtest.New(t)
+	agent := c.NewSession("BlueLake", "codex-cli", "gpt-5")
+	reviewer := c.NewSession("GreenCastle", "claude-code", "opus")
+
+	req := c.Request(agent, "git push --force origin main", "rewrite history")
+	c.Reject(req, reviewer, "force-pushing main is not allowed")
+
+	c.AssertStatus(req, slbtest.StatusRejected)
+	c.AssertApprovalCount(req, 0)
+	c.AssertReviewCount(req, 1)
+}
+
+func TestClientsAreIsolated(t *testing.T) {
+	a := slbtest.New(t)
+	b := slbtest.New(t, slbtest.WithProject(t.TempDir()))
+	a.Request(a.NewSession("BlueLake", "codex-cli", "gpt-5"), "rm -rf ./build", "cleanup")
+
+	a.AssertPendingCount(1)
+	b.AssertPendingCount(0)
+	if a.DB() == b.DB() || a.ProjectPath() == b.ProjectPath() {
+		t.Error("expected clients to have separate databases and projects")
+	}
+}
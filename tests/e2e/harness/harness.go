@@ -149,9 +149,22 @@ func (env *E2EEnvironment) Elapsed() time.Duration {
 	return time.Since(env.startTime)
 }
 
-// CreateSession creates a session for testing.
+// CreateSession creates a session for testing on the local host.
 func (env *E2EEnvironment) CreateSession(agent, program, model string) *db.Session {
 	env.T.Helper()
+	return env.createSession(agent, program, model, "")
+}
+
+// CreateSessionOnHost creates a session that appears to run on hostname, so
+// host-diversity rules can be tested without several machines. The machine
+// ID is derived from hostname: equal hostnames share a machine.
+func (env *E2EEnvironment) CreateSessionOnHost(agent, program, model, hostname string) *db.Session {
+	env.T.Helper()
+	return env.createSession(agent, program, model, hostname)
+}
+
+func (env *E2EEnvironment) createSession(agent, program, model, hostname string) *db.Session {
+	env.T.Helper()
 
 	sess := &db.Session{
 		ID:          "sess-" + randomID(8),
@@ -160,12 +173,16 @@ func (env *E2EEnvironment) CreateSession(agent, program, model string) *db.Sessi
 		Model:       model,
 		ProjectPath: env.ProjectDir,
 	}
+	if hostname != "" {
+		sess.Hostname = hostname
+		sess.MachineID = "e2e-" + hostname
+	}
 
 	if err := env.DB.CreateSession(sess); err != nil {
 		env.T.Fatalf("CreateSession: %v", err)
 	}
 
-	env.Result("Session created: %s (%s/%s)", sess.ID, program, model)
+	env.Result("Session created: %s (%s/%s) on %s", sess.ID, program, model, sess.Hostname)
 	return sess
 }
 
@@ -2,9 +2,11 @@
 package scenarios
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/Dicklesworthstone/slb/internal/core"
 	"github.com/Dicklesworthstone/slb/internal/db"
 	"github.com/Dicklesworthstone/slb/tests/e2e/harness"
 )
@@ -189,6 +191,59 @@ func TestMultipleApproversRequired(t *testing.T) {
 	env.Logger.Elapsed()
 }
 
+// TestDifferentHostRequired tests that a tier configured to require host
+// diversity only accepts approval from a session on another host.
+func TestDifferentHostRequired(t *testing.T) {
+	env := harness.NewE2EEnvironment(t)
+
+	env.Step("Creating sessions on two fake hosts")
+	requestor := env.CreateSessionOnHost("Requestor", "claude-code", "opus", "build-01")
+	sameHost := env.CreateSessionOnHost("Neighbor", "codex", "gpt-4", "build-01")
+	otherHost := env.CreateSessionOnHost("Remote", "codex", "gpt-4", "build-02")
+
+	env.Step("Submitting a dangerous request with host diversity required")
+	cfg := core.DefaultRequestCreatorConfig()
+	cfg.RequireDifferentHostTiers = []core.RiskTier{core.RiskTierDangerous}
+	created, err := core.NewRequestCreator(env.DB, nil, nil, cfg).CreateRequest(core.CreateRequestOptions{
+		SessionID:     requestor.ID,
+		Command:       "git reset --hard HEAD~3",
+		Cwd:           env.ProjectDir,
+		Justification: core.Justification{Reason: "Drop broken commits"},
+	})
+	env.AssertNoError(err, "creating request")
+	req := created.Request
+	if !req.RequireDifferentHost {
+		t.Fatal("expected request to require a different host")
+	}
+
+	rs := core.NewReviewService(env.DB, core.DefaultReviewConfig())
+
+	env.Step("Same-host session attempts approval")
+	_, err = rs.SubmitReview(core.ReviewOptions{
+		SessionID:  sameHost.ID,
+		SessionKey: sameHost.SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+	})
+	if !errors.Is(err, core.ErrRequireDiffHost) {
+		t.Fatalf("expected ErrRequireDiffHost, got %v", err)
+	}
+	env.AssertRequestStatus(req, db.StatusPending)
+
+	env.Step("Different-host session approves")
+	_, err = rs.SubmitReview(core.ReviewOptions{
+		SessionID:  otherHost.ID,
+		SessionKey: otherHost.SessionKey,
+		RequestID:  req.ID,
+		Decision:   db.DecisionApprove,
+	})
+	env.AssertNoError(err, "approving from another host")
+	env.AssertRequestStatus(req, db.StatusApproved)
+
+	env.DBState()
+	env.Logger.Elapsed()
+}
+
 // TestRequestExpiration tests that expired requests are detected.
 func TestRequestExpiration(t *testing.T) {
 	env := harness.NewE2EEnvironment(t)